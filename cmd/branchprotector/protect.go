@@ -35,6 +35,7 @@ import (
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/logrusutil"
+	"sigs.k8s.io/prow/pkg/moonraker"
 )
 
 const (
@@ -47,6 +48,7 @@ type options struct {
 	confirm                bool
 	verifyRestrictions     bool
 	enableAppsRestrictions bool
+	inRepoConfig           bool
 
 	github           flagutil.GitHubOptions
 	githubEnablement flagutil.GitHubEnablementOptions
@@ -74,6 +76,7 @@ func gatherOptions() options {
 	fs.BoolVar(&o.confirm, "confirm", false, "Mutate github if set")
 	fs.BoolVar(&o.verifyRestrictions, "verify-restrictions", false, "Verify the restrictions section of the request for authorized apps/collaborators/teams")
 	fs.BoolVar(&o.enableAppsRestrictions, "enable-apps-restrictions", false, "Enable feature to enforce apps restrictions in branch protection rules")
+	fs.BoolVar(&o.inRepoConfig, "in-repo-config", false, "Require the contexts of presubmits defined in the .prow.yaml of repos with inrepoconfig enabled, resolved through moonraker if --moonraker-address is set")
 	o.config.AddFlags(fs)
 	o.github.AddCustomizedFlags(fs, flagutil.ThrottlerDefaults(defaultTokens, defaultBurst))
 	o.githubEnablement.AddFlags(fs)
@@ -86,6 +89,19 @@ type requirements struct {
 	Repo    string
 	Branch  string
 	Request *github.BranchProtectionRequest
+	// Ruleset is set instead of Request when the branch is protected by a
+	// ruleset.
+	Ruleset *rulesetRequirements
+}
+
+type rulesetRequirements struct {
+	// ID of the current ruleset, zero if it has to be created.
+	ID int
+	// Ruleset to apply, nil if the current ruleset has to be deleted.
+	Ruleset *github.Ruleset
+	// RemoveProtection removes the classic branch protection once the
+	// ruleset is applied, migrating the branch to rulesets.
+	RemoveProtection bool
 }
 
 // Errors holds a list of errors, including a method to concurrently append.
@@ -133,6 +149,26 @@ func main() {
 		enabled:                o.githubEnablement.EnablementChecker(),
 	}
 
+	if o.inRepoConfig {
+		if o.config.MoonrakerEnabled() {
+			moonrakerClient, err := moonraker.NewInRepoConfigGetter(o.config.MoonrakerAddress, o.config.MoonrakerGRPCAddress, ca)
+			if err != nil {
+				logrus.WithError(err).Fatal("Error getting Moonraker client.")
+			}
+			p.inRepoConfigGetter = moonrakerClient
+		} else {
+			gitClient, err := o.github.GitClientFactory("", &o.config.InRepoConfigCacheDirBase, !o.confirm, false)
+			if err != nil {
+				logrus.WithError(err).Fatal("Error getting Git client.")
+			}
+			ircc, err := config.NewInRepoConfigCache(o.config.InRepoConfigCacheSize, ca, gitClient)
+			if err != nil {
+				logrus.WithError(err).Fatal("Error creating InRepoConfigCache.")
+			}
+			p.inRepoConfigGetter = ircc
+		}
+	}
+
 	go p.configureBranches()
 	p.protect()
 	close(p.updates)
@@ -152,9 +188,16 @@ type client interface {
 	GetBranches(org, repo string, onlyProtected bool) ([]github.Branch, error)
 	GetRepo(owner, name string) (github.FullRepo, error)
 	GetRepos(org string, user bool) ([]github.Repo, error)
+	GetRef(org, repo, ref string) (string, error)
 	ListAppInstallationsForOrg(org string) ([]github.AppInstallation, error)
 	ListCollaborators(org, repo string) ([]github.User, error)
 	ListRepoTeams(org, repo string) ([]github.Team, error)
+	GetTeamBySlug(slug string, org string) (*github.Team, error)
+	ListRepoRulesets(org, repo string) ([]github.Ruleset, error)
+	GetRepoRuleset(org, repo string, id int) (*github.Ruleset, error)
+	CreateRepoRuleset(org, repo string, ruleset github.Ruleset) (*github.Ruleset, error)
+	UpdateRepoRuleset(org, repo string, id int, ruleset github.Ruleset) (*github.Ruleset, error)
+	DeleteRepoRuleset(org, repo string, id int) error
 }
 
 type protector struct {
@@ -167,10 +210,19 @@ type protector struct {
 	verifyRestrictions     bool
 	enableAppsRestrictions bool
 	enabled                func(org, repo string) bool
+	// inRepoConfigGetter resolves the presubmits of repos with inrepoconfig
+	// enabled, only static presubmits are considered if unset
+	inRepoConfigGetter config.InRepoConfigGetter
+	// rulesets caches the rulesets of each org/repo
+	rulesets map[string][]github.Ruleset
 }
 
 func (p *protector) configureBranches() {
 	for u := range p.updates {
+		if u.Ruleset != nil {
+			p.configureRuleset(u)
+			continue
+		}
 		if u.Request == nil {
 			if err := p.client.RemoveBranchProtection(u.Org, u.Repo, u.Branch); err != nil {
 				p.errors.add(fmt.Errorf("remove %s/%s=%s protection failed: %w", u.Org, u.Repo, u.Branch, err))
@@ -185,6 +237,31 @@ func (p *protector) configureBranches() {
 	p.done <- p.errors.errs
 }
 
+// configureRuleset creates, updates or deletes the ruleset of a branch and
+// then removes its classic branch protection if requested.
+func (p *protector) configureRuleset(u requirements) {
+	var err error
+	switch r := u.Ruleset; {
+	case r.Ruleset == nil:
+		if r.ID != 0 {
+			err = p.client.DeleteRepoRuleset(u.Org, u.Repo, r.ID)
+		}
+	case r.ID == 0:
+		_, err = p.client.CreateRepoRuleset(u.Org, u.Repo, *r.Ruleset)
+	default:
+		_, err = p.client.UpdateRepoRuleset(u.Org, u.Repo, r.ID, *r.Ruleset)
+	}
+	if err != nil {
+		p.errors.add(fmt.Errorf("update %s/%s=%s ruleset failed: %w", u.Org, u.Repo, u.Branch, err))
+		return
+	}
+	if u.Ruleset.RemoveProtection {
+		if err := p.client.RemoveBranchProtection(u.Org, u.Repo, u.Branch); err != nil {
+			p.errors.add(fmt.Errorf("remove %s/%s=%s protection failed: %w", u.Org, u.Repo, u.Branch, err))
+		}
+	}
+}
+
 // protect protects branches specified in the presubmit and branch-protection config sections.
 func (p *protector) protect() {
 	bp := p.cfg.BranchProtection
@@ -446,13 +523,34 @@ func (p *protector) UpdateBranch(orgName, repo string, branchName string, branch
 	if branch.Unmanaged != nil && *branch.Unmanaged {
 		return nil
 	}
-	bp, err := p.cfg.GetPolicy(orgName, repo, branchName, branch, p.cfg.GetPresubmitsStatic(orgName+"/"+repo), &protected)
+	presubmits, err := p.presubmits(orgName, repo, branchName)
+	if err != nil {
+		return fmt.Errorf("get presubmits: %w", err)
+	}
+	bp, err := p.cfg.GetPolicy(orgName, repo, branchName, branch, presubmits, &protected)
 	if err != nil {
 		return fmt.Errorf("get policy: %w", err)
 	}
 	if bp == nil || bp.Protect == nil {
 		return nil
 	}
+	current, err := p.currentRuleset(orgName, repo, branchName)
+	if err != nil {
+		return fmt.Errorf("get current ruleset: %w", err)
+	}
+	if bp.RulesetsEnabled() {
+		return p.updateRuleset(orgName, repo, branchName, *bp, current)
+	}
+	if current != nil {
+		// Rulesets were disabled for the branch, go back to classic branch
+		// protection.
+		p.updates <- requirements{
+			Org:     orgName,
+			Repo:    repo,
+			Branch:  branchName,
+			Ruleset: &rulesetRequirements{ID: current.ID},
+		}
+	}
 	if !protected && !*bp.Protect {
 		logrus.Infof("%s/%s=%s: already unprotected", orgName, repo, branchName)
 		return nil
@@ -506,6 +604,89 @@ func (p *protector) UpdateBranch(orgName, repo string, branchName string, branch
 	return nil
 }
 
+// presubmits returns the presubmits of the branch. These include the ones from
+// the .prow.yaml at the tip of the branch if inrepoconfig is enabled for the
+// repo, so that their contexts are required as well.
+func (p *protector) presubmits(org, repo, branch string) ([]config.Presubmit, error) {
+	identifier := org + "/" + repo
+	if p.inRepoConfigGetter == nil || !p.cfg.InRepoConfigEnabled(identifier) {
+		return p.cfg.GetPresubmitsStatic(identifier), nil
+	}
+	baseSHAGetter := func() (string, error) {
+		return p.client.GetRef(org, repo, "heads/"+branch)
+	}
+	return p.inRepoConfigGetter.GetPresubmits(identifier, branch, baseSHAGetter)
+}
+
+// currentRuleset returns the ruleset branchprotector manages for the branch,
+// nil if there is none.
+func (p *protector) currentRuleset(org, repo, branch string) (*github.Ruleset, error) {
+	if p.rulesets == nil {
+		p.rulesets = map[string][]github.Ruleset{}
+	}
+	key := org + "/" + repo
+	rulesets, ok := p.rulesets[key]
+	if !ok {
+		var err error
+		if rulesets, err = p.client.ListRepoRulesets(org, repo); err != nil {
+			return nil, err
+		}
+		p.rulesets[key] = rulesets
+	}
+	for _, r := range rulesets {
+		if r.Name == rulesetName(branch) {
+			// Listing rulesets does not return their rules.
+			return p.client.GetRepoRuleset(org, repo, r.ID)
+		}
+	}
+	return nil, nil
+}
+
+// updateRuleset applies the policy of the branch as a ruleset and migrates
+// the branch away from classic branch protection.
+func (p *protector) updateRuleset(orgName, repo, branchName string, bp config.Policy, current *github.Ruleset) error {
+	var ruleset *github.Ruleset
+	if *bp.Protect {
+		var err error
+		ruleset, err = makeRuleset(branchName, bp, func(slug string) (int, error) {
+			team, err := p.client.GetTeamBySlug(slug, orgName)
+			if err != nil {
+				return 0, err
+			}
+			return team.ID, nil
+		})
+		if err != nil {
+			return fmt.Errorf("make ruleset: %w", err)
+		}
+	}
+
+	currentBP, err := p.client.GetBranchProtection(orgName, repo, url.QueryEscape(branchName))
+	if err != nil {
+		return fmt.Errorf("get current branch protection: %w", err)
+	}
+
+	req := rulesetRequirements{Ruleset: ruleset, RemoveProtection: currentBP != nil}
+	if current != nil {
+		req.ID = current.ID
+	}
+	if equalRulesets(current, ruleset) {
+		if !req.RemoveProtection {
+			logrus.Debugf("%s/%s=%s: current ruleset matches policy, skipping", orgName, repo, branchName)
+			return nil
+		}
+		p.updates <- requirements{Org: orgName, Repo: repo, Branch: branchName}
+		return nil
+	}
+
+	p.updates <- requirements{
+		Org:     orgName,
+		Repo:    repo,
+		Branch:  branchName,
+		Ruleset: &req,
+	}
+	return nil
+}
+
 func equalBranchProtections(state *github.BranchProtection, request *github.BranchProtectionRequest) bool {
 	switch {
 	case state == nil && request == nil:
@@ -89,6 +89,10 @@ type fakeClient struct {
 	appInstallations  []github.AppInstallation
 	collaborators     []github.User
 	teams             []github.Team
+	rulesets          map[string][]github.Ruleset
+	createdRulesets   map[string]github.Ruleset
+	updatedRulesets   map[int]github.Ruleset
+	deletedRulesets   []int
 }
 
 func (c fakeClient) GetRepo(org string, repo string) (github.FullRepo, error) {
@@ -177,6 +181,60 @@ func (c *fakeClient) ListRepoTeams(org, repo string) ([]github.Team, error) {
 	return c.teams, nil
 }
 
+func (c *fakeClient) GetRef(org, repo, ref string) (string, error) {
+	return "sha-of-" + ref, nil
+}
+
+func (c *fakeClient) GetTeamBySlug(slug string, org string) (*github.Team, error) {
+	for _, team := range c.teams {
+		if team.Slug == slug {
+			return &team, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown team: %s", slug)
+}
+
+func (c *fakeClient) ListRepoRulesets(org, repo string) ([]github.Ruleset, error) {
+	var rulesets []github.Ruleset
+	for _, r := range c.rulesets[org+"/"+repo] {
+		rulesets = append(rulesets, github.Ruleset{ID: r.ID, Name: r.Name})
+	}
+	return rulesets, nil
+}
+
+func (c *fakeClient) GetRepoRuleset(org, repo string, id int) (*github.Ruleset, error) {
+	for _, r := range c.rulesets[org+"/"+repo] {
+		if r.ID == id {
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown ruleset: %d", id)
+}
+
+func (c *fakeClient) CreateRepoRuleset(org, repo string, ruleset github.Ruleset) (*github.Ruleset, error) {
+	if repo == "error" {
+		return nil, errors.New("failed to create ruleset")
+	}
+	if c.createdRulesets == nil {
+		c.createdRulesets = map[string]github.Ruleset{}
+	}
+	c.createdRulesets[org+"/"+repo] = ruleset
+	return &ruleset, nil
+}
+
+func (c *fakeClient) UpdateRepoRuleset(org, repo string, id int, ruleset github.Ruleset) (*github.Ruleset, error) {
+	if c.updatedRulesets == nil {
+		c.updatedRulesets = map[int]github.Ruleset{}
+	}
+	c.updatedRulesets[id] = ruleset
+	return &ruleset, nil
+}
+
+func (c *fakeClient) DeleteRepoRuleset(org, repo string, id int) error {
+	c.deletedRulesets = append(c.deletedRulesets, id)
+	return nil
+}
+
 func TestConfigureBranches(t *testing.T) {
 	yes := true
 
@@ -2707,3 +2765,347 @@ func TestAuthorizedTeams(t *testing.T) {
 		})
 	}
 }
+
+func TestProtectRulesets(t *testing.T) {
+	const rulesetConfig = `
+branch-protection:
+  orgs:
+    org:
+      protect: true
+      enforce_admins: true
+      rulesets:
+        enabled: true
+`
+	want := &github.Ruleset{
+		Name:        "prow: master",
+		Target:      github.RulesetTargetBranch,
+		Enforcement: github.RulesetEnforcementActive,
+		Conditions: &github.RulesetConditions{
+			RefName: github.RulesetRefNameCondition{Include: []string{"refs/heads/master"}, Exclude: []string{}},
+		},
+		Rules: []github.RulesetRule{{Type: github.RuleDeletion}, {Type: github.RuleNonFastForward}},
+	}
+	current := *want
+	current.ID = 7
+	outdated := current
+	outdated.Enforcement = github.RulesetEnforcementEvaluate
+
+	cases := []struct {
+		name              string
+		config            string
+		rulesets          []github.Ruleset
+		branchProtections map[string]github.BranchProtection
+		expected          []requirements
+	}{
+		{
+			name:   "ruleset is created",
+			config: rulesetConfig,
+			expected: []requirements{{
+				Org: "org", Repo: "repo", Branch: "master",
+				Ruleset: &rulesetRequirements{Ruleset: want},
+			}},
+		},
+		{
+			name:     "matching ruleset is kept",
+			config:   rulesetConfig,
+			rulesets: []github.Ruleset{current},
+		},
+		{
+			name:     "outdated ruleset is updated",
+			config:   rulesetConfig,
+			rulesets: []github.Ruleset{outdated},
+			expected: []requirements{{
+				Org: "org", Repo: "repo", Branch: "master",
+				Ruleset: &rulesetRequirements{ID: 7, Ruleset: want},
+			}},
+		},
+		{
+			name:              "classic protection is migrated",
+			config:            rulesetConfig,
+			branchProtections: map[string]github.BranchProtection{"org/repo=master": {}},
+			expected: []requirements{{
+				Org: "org", Repo: "repo", Branch: "master",
+				Ruleset: &rulesetRequirements{Ruleset: want, RemoveProtection: true},
+			}},
+		},
+		{
+			name:              "classic protection is removed when ruleset matches",
+			config:            rulesetConfig,
+			rulesets:          []github.Ruleset{current},
+			branchProtections: map[string]github.BranchProtection{"org/repo=master": {}},
+			expected:          []requirements{{Org: "org", Repo: "repo", Branch: "master"}},
+		},
+		{
+			name: "ruleset is deleted when protection is disabled",
+			config: `
+branch-protection:
+  orgs:
+    org:
+      protect: false
+      rulesets:
+        enabled: true
+`,
+			rulesets: []github.Ruleset{current},
+			expected: []requirements{{
+				Org: "org", Repo: "repo", Branch: "master",
+				Ruleset: &rulesetRequirements{ID: 7},
+			}},
+		},
+		{
+			name: "ruleset is deleted when rulesets are disabled",
+			config: `
+branch-protection:
+  orgs:
+    org:
+      protect: true
+      enforce_admins: true
+`,
+			rulesets: []github.Ruleset{current},
+			expected: []requirements{
+				{Org: "org", Repo: "repo", Branch: "master", Ruleset: &rulesetRequirements{ID: 7}},
+				{Org: "org", Repo: "repo", Branch: "master", Request: &github.BranchProtectionRequest{EnforceAdmins: &[]bool{true}[0]}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakeClient{
+				repos:             map[string][]github.Repo{"org": {{Name: "repo", FullName: "org/repo"}}},
+				branches:          map[string][]github.Branch{"org/repo": {{Name: "master"}}},
+				branchProtections: tc.branchProtections,
+				rulesets:          map[string][]github.Ruleset{"org/repo": tc.rulesets},
+			}
+			var cfg config.Config
+			if err := yaml.Unmarshal([]byte(tc.config), &cfg); err != nil {
+				t.Fatalf("failed to parse config: %v", err)
+			}
+			p := protector{
+				client:         &fc,
+				cfg:            &cfg,
+				errors:         Errors{},
+				updates:        make(chan requirements),
+				done:           make(chan []error),
+				completedRepos: make(map[string]bool),
+				enabled:        func(org, repo string) bool { return true },
+			}
+			go func() {
+				p.protect()
+				close(p.updates)
+			}()
+
+			var actual []requirements
+			for r := range p.updates {
+				actual = append(actual, r)
+			}
+			if errs := p.errors.errs; len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("actual updates differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigureRulesets(t *testing.T) {
+	ruleset := &github.Ruleset{Name: "prow: master"}
+	cases := []struct {
+		name            string
+		update          requirements
+		created         map[string]github.Ruleset
+		updated         map[int]github.Ruleset
+		deleted         []int
+		removedClassics map[string]bool
+		errors          int
+	}{
+		{
+			name:    "create",
+			update:  requirements{Org: "org", Repo: "repo", Branch: "master", Ruleset: &rulesetRequirements{Ruleset: ruleset}},
+			created: map[string]github.Ruleset{"org/repo": *ruleset},
+		},
+		{
+			name:    "update",
+			update:  requirements{Org: "org", Repo: "repo", Branch: "master", Ruleset: &rulesetRequirements{ID: 3, Ruleset: ruleset}},
+			updated: map[int]github.Ruleset{3: *ruleset},
+		},
+		{
+			name:    "delete",
+			update:  requirements{Org: "org", Repo: "repo", Branch: "master", Ruleset: &rulesetRequirements{ID: 3}},
+			deleted: []int{3},
+		},
+		{
+			name:            "migrate",
+			update:          requirements{Org: "org", Repo: "repo", Branch: "master", Ruleset: &rulesetRequirements{Ruleset: ruleset, RemoveProtection: true}},
+			created:         map[string]github.Ruleset{"org/repo": *ruleset},
+			removedClassics: map[string]bool{"org/repo=master": true},
+		},
+		{
+			name:   "classic protection is kept if the ruleset fails",
+			update: requirements{Org: "org", Repo: "error", Branch: "master", Ruleset: &rulesetRequirements{Ruleset: ruleset, RemoveProtection: true}},
+			errors: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakeClient{}
+			p := protector{
+				client:  &fc,
+				updates: make(chan requirements),
+				done:    make(chan []error),
+			}
+			go p.configureBranches()
+			p.updates <- tc.update
+			close(p.updates)
+			if errs := <-p.done; len(errs) != tc.errors {
+				t.Errorf("%d errors != expected %d: %v", len(errs), tc.errors, errs)
+			}
+			if diff := cmp.Diff(tc.created, fc.createdRulesets); diff != "" {
+				t.Errorf("created rulesets differ from expected: %s", diff)
+			}
+			if diff := cmp.Diff(tc.updated, fc.updatedRulesets); diff != "" {
+				t.Errorf("updated rulesets differ from expected: %s", diff)
+			}
+			if diff := cmp.Diff(tc.deleted, fc.deletedRulesets); diff != "" {
+				t.Errorf("deleted rulesets differ from expected: %s", diff)
+			}
+			if diff := cmp.Diff(tc.removedClassics, fc.deleted); diff != "" {
+				t.Errorf("removed branch protections differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+type fakeInRepoConfigGetter struct {
+	presubmits map[string][]config.Presubmit
+}
+
+func (g *fakeInRepoConfigGetter) GetInRepoConfig(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) (*config.ProwYAML, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (g *fakeInRepoConfigGetter) GetPresubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
+	sha, err := baseSHAGetter()
+	if err != nil {
+		return nil, err
+	}
+	presubmits, ok := g.presubmits[sha]
+	if !ok {
+		return nil, fmt.Errorf("no .prow.yaml at %s", sha)
+	}
+	return presubmits, nil
+}
+
+func (g *fakeInRepoConfigGetter) GetPostsubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Postsubmit, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestProtectInRepoConfig(t *testing.T) {
+	const cfg = `
+branch-protection:
+  protect: true
+  orgs:
+    org:
+in_repo_config:
+  enabled:
+    org/inrepo: true
+`
+	presubmit := func(name string) config.Presubmit {
+		return config.Presubmit{
+			JobBase:   config.JobBase{Name: name},
+			Reporter:  config.Reporter{Context: name},
+			AlwaysRun: true,
+		}
+	}
+	getter := &fakeInRepoConfigGetter{presubmits: map[string][]config.Presubmit{
+		"sha-of-heads/master": {presubmit("in-repo-job")},
+	}}
+
+	cases := []struct {
+		name     string
+		repo     string
+		branch   string
+		getter   config.InRepoConfigGetter
+		expected []requirements
+		errors   int
+	}{
+		{
+			name:   "contexts of in-repo jobs are required",
+			repo:   "inrepo",
+			branch: "master",
+			getter: getter,
+			expected: []requirements{{
+				Org: "org", Repo: "inrepo", Branch: "master",
+				Request: &github.BranchProtectionRequest{
+					EnforceAdmins:        &[]bool{false}[0],
+					RequiredStatusChecks: &github.RequiredStatusChecks{Contexts: []string{"in-repo-job"}},
+				},
+			}},
+		},
+		{
+			name:   "in-repo jobs are ignored without a getter",
+			repo:   "inrepo",
+			branch: "master",
+			expected: []requirements{{
+				Org: "org", Repo: "inrepo", Branch: "master",
+				Request: &github.BranchProtectionRequest{EnforceAdmins: &[]bool{false}[0]},
+			}},
+		},
+		{
+			name:   "repos without inrepoconfig are not resolved",
+			repo:   "static",
+			branch: "other",
+			getter: getter,
+			expected: []requirements{{
+				Org: "org", Repo: "static", Branch: "other",
+				Request: &github.BranchProtectionRequest{EnforceAdmins: &[]bool{false}[0]},
+			}},
+		},
+		{
+			name:   "branch is not updated if .prow.yaml cannot be resolved",
+			repo:   "inrepo",
+			branch: "other",
+			getter: getter,
+			errors: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakeClient{
+				repos:    map[string][]github.Repo{"org": {{Name: tc.repo, FullName: "org/" + tc.repo}}},
+				branches: map[string][]github.Branch{"org/" + tc.repo: {{Name: tc.branch}}},
+			}
+			var c config.Config
+			if err := yaml.Unmarshal([]byte(cfg), &c); err != nil {
+				t.Fatalf("failed to parse config: %v", err)
+			}
+			p := protector{
+				client:             &fc,
+				cfg:                &c,
+				errors:             Errors{},
+				updates:            make(chan requirements),
+				done:               make(chan []error),
+				completedRepos:     make(map[string]bool),
+				enabled:            func(org, repo string) bool { return true },
+				inRepoConfigGetter: tc.getter,
+			}
+			go func() {
+				p.protect()
+				close(p.updates)
+			}()
+
+			var actual []requirements
+			for r := range p.updates {
+				actual = append(actual, r)
+			}
+			if errs := p.errors.errs; len(errs) != tc.errors {
+				t.Errorf("%d errors != expected %d: %v", len(errs), tc.errors, errs)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("actual updates differ from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	branchprotection "sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// Well-known actor IDs of the repository roles and the org admins in rulesets.
+const (
+	orgAdminActorID = 1
+	maintainRoleID  = 2
+	writeRoleID     = 4
+	adminRoleID     = 5
+)
+
+var repositoryRoleIDs = map[string]int{
+	"maintain": maintainRoleID,
+	"write":    writeRoleID,
+	"admin":    adminRoleID,
+}
+
+// rulesetName returns the name of the ruleset that branchprotector manages
+// for the branch.
+func rulesetName(branch string) string {
+	return "prow: " + branch
+}
+
+// makeRuleset renders a branch protection policy into the ruleset of the
+// branch. Teams in bypass actors are resolved to their IDs with teamID.
+func makeRuleset(branch string, policy branchprotection.Policy, teamID func(slug string) (int, error)) (*github.Ruleset, error) {
+	ruleset := &github.Ruleset{
+		Name:        rulesetName(branch),
+		Target:      github.RulesetTargetBranch,
+		Enforcement: github.RulesetEnforcementActive,
+		Conditions: &github.RulesetConditions{
+			RefName: github.RulesetRefNameCondition{
+				Include: []string{"refs/heads/" + branch},
+				Exclude: []string{},
+			},
+		},
+	}
+	rp := policy.Rulesets
+	if rp.Enforcement != nil {
+		ruleset.Enforcement = *rp.Enforcement
+	}
+
+	if !makeBool(policy.AllowDeletions) {
+		ruleset.Rules = append(ruleset.Rules, github.RulesetRule{Type: github.RuleDeletion})
+	}
+	if !makeBool(policy.AllowForcePushes) {
+		ruleset.Rules = append(ruleset.Rules, github.RulesetRule{Type: github.RuleNonFastForward})
+	}
+	if makeBool(policy.RequiredLinearHistory) {
+		ruleset.Rules = append(ruleset.Rules, github.RulesetRule{Type: github.RuleRequiredLinearHistory})
+	}
+	if makeBool(rp.RequireSignedCommits) {
+		ruleset.Rules = append(ruleset.Rules, github.RulesetRule{Type: github.RuleRequiredSignatures})
+	}
+	if reviews := makeReviews(policy.RequiredPullRequestReviews); reviews != nil {
+		no := false
+		ruleset.Rules = append(ruleset.Rules, github.RulesetRule{
+			Type: github.RulePullRequest,
+			Parameters: &github.RulesetRuleParameters{
+				RequiredApprovingReviewCount:   &reviews.RequiredApprovingReviewCount,
+				DismissStaleReviewsOnPush:      &reviews.DismissStaleReviews,
+				RequireCodeOwnerReview:         &reviews.RequireCodeOwnerReviews,
+				RequireLastPushApproval:        &no,
+				RequiredReviewThreadResolution: &no,
+			},
+		})
+	}
+	// Rulesets reject a required_status_checks rule without any checks.
+	if checks := makeChecks(policy.RequiredStatusChecks); checks != nil && len(checks.Contexts) > 0 {
+		params := &github.RulesetRuleParameters{StrictRequiredStatusChecks: &checks.Strict}
+		for _, context := range checks.Contexts {
+			params.RequiredStatusChecks = append(params.RequiredStatusChecks, github.RulesetStatusCheck{Context: context})
+		}
+		ruleset.Rules = append(ruleset.Rules, github.RulesetRule{Type: github.RuleRequiredStatusChecks, Parameters: params})
+	}
+
+	// Classic branch protection does not apply to admins unless enforce_admins
+	// is set, keep that behavior for rulesets.
+	if !makeBool(policy.Admins) {
+		ruleset.BypassActors = append(ruleset.BypassActors, github.RulesetBypassActor{
+			ActorID:    adminRoleID,
+			ActorType:  github.RulesetActorRepositoryRole,
+			BypassMode: "always",
+		})
+	}
+	for _, actor := range rp.BypassActors {
+		a := github.RulesetBypassActor{BypassMode: actor.Mode}
+		if a.BypassMode == "" {
+			a.BypassMode = "always"
+		}
+		switch {
+		case actor.Team != "":
+			id, err := teamID(actor.Team)
+			if err != nil {
+				return nil, fmt.Errorf("get team %s: %w", actor.Team, err)
+			}
+			a.ActorID, a.ActorType = id, github.RulesetActorTeam
+		case actor.AppID != 0:
+			a.ActorID, a.ActorType = actor.AppID, github.RulesetActorIntegration
+		case actor.OrgAdmins:
+			a.ActorID, a.ActorType = orgAdminActorID, github.RulesetActorOrganizationAdmin
+		default:
+			a.ActorID, a.ActorType = repositoryRoleIDs[actor.RepositoryRole], github.RulesetActorRepositoryRole
+		}
+		ruleset.BypassActors = append(ruleset.BypassActors, a)
+	}
+	return normalizeRuleset(ruleset), nil
+}
+
+// normalizeRuleset returns a copy of the ruleset with the fields that are
+// not managed by branchprotector cleared and its lists sorted, so that it
+// can be compared.
+func normalizeRuleset(r *github.Ruleset) *github.Ruleset {
+	if r == nil {
+		return nil
+	}
+	n := &github.Ruleset{
+		Name:        r.Name,
+		Target:      r.Target,
+		Enforcement: r.Enforcement,
+	}
+	if r.Conditions != nil {
+		n.Conditions = &github.RulesetConditions{
+			RefName: github.RulesetRefNameCondition{
+				Include: append([]string{}, r.Conditions.RefName.Include...),
+				Exclude: append([]string{}, r.Conditions.RefName.Exclude...),
+			},
+		}
+	}
+	seen := map[github.RulesetBypassActor]bool{}
+	for _, a := range r.BypassActors {
+		if !seen[a] {
+			seen[a] = true
+			n.BypassActors = append(n.BypassActors, a)
+		}
+	}
+	sort.Slice(n.BypassActors, func(i, j int) bool {
+		a, b := n.BypassActors[i], n.BypassActors[j]
+		if a.ActorType != b.ActorType {
+			return a.ActorType < b.ActorType
+		}
+		return a.ActorID < b.ActorID
+	})
+	for _, rule := range r.Rules {
+		if rule.Parameters != nil && len(rule.Parameters.RequiredStatusChecks) > 0 {
+			params := *rule.Parameters
+			params.RequiredStatusChecks = append([]github.RulesetStatusCheck{}, params.RequiredStatusChecks...)
+			sort.Slice(params.RequiredStatusChecks, func(i, j int) bool {
+				return params.RequiredStatusChecks[i].Context < params.RequiredStatusChecks[j].Context
+			})
+			rule.Parameters = &params
+		}
+		n.Rules = append(n.Rules, rule)
+	}
+	sort.Slice(n.Rules, func(i, j int) bool { return n.Rules[i].Type < n.Rules[j].Type })
+	return n
+}
+
+// equalRulesets returns whether the current ruleset of a branch matches the
+// requested one.
+func equalRulesets(state, request *github.Ruleset) bool {
+	return reflect.DeepEqual(normalizeRuleset(state), normalizeRuleset(request))
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+func TestMakeRuleset(t *testing.T) {
+	yes := true
+	two := 2
+	evaluate := "evaluate"
+	conditions := &github.RulesetConditions{
+		RefName: github.RulesetRefNameCondition{Include: []string{"refs/heads/main"}, Exclude: []string{}},
+	}
+	adminBypass := github.RulesetBypassActor{ActorID: adminRoleID, ActorType: github.RulesetActorRepositoryRole, BypassMode: "always"}
+	teamID := func(slug string) (int, error) {
+		if slug == "missing" {
+			return 0, errors.New("no such team")
+		}
+		return 42, nil
+	}
+
+	cases := []struct {
+		name     string
+		policy   config.Policy
+		expected *github.Ruleset
+		err      bool
+	}{
+		{
+			name:   "defaults protect against deletion and force pushes",
+			policy: config.Policy{Rulesets: &config.RulesetPolicy{Enabled: &yes}},
+			expected: &github.Ruleset{
+				Name:         "prow: main",
+				Target:       github.RulesetTargetBranch,
+				Enforcement:  github.RulesetEnforcementActive,
+				Conditions:   conditions,
+				BypassActors: []github.RulesetBypassActor{adminBypass},
+				Rules:        []github.RulesetRule{{Type: github.RuleDeletion}, {Type: github.RuleNonFastForward}},
+			},
+		},
+		{
+			name: "full policy",
+			policy: config.Policy{
+				Admins:                &yes,
+				AllowDeletions:        &yes,
+				AllowForcePushes:      &yes,
+				RequiredLinearHistory: &yes,
+				RequiredPullRequestReviews: &config.ReviewPolicy{
+					Approvals:     &two,
+					RequireOwners: &yes,
+				},
+				RequiredStatusChecks: &config.ContextPolicy{
+					Contexts: []string{"unit", "e2e", "unit"},
+					Strict:   &yes,
+				},
+				Rulesets: &config.RulesetPolicy{
+					Enabled:              &yes,
+					Enforcement:          &evaluate,
+					RequireSignedCommits: &yes,
+					BypassActors: []config.RulesetBypassActor{
+						{Team: "release", Mode: "pull_request"},
+						{AppID: 1234},
+						{OrgAdmins: true},
+						{RepositoryRole: "maintain"},
+					},
+				},
+			},
+			expected: &github.Ruleset{
+				Name:        "prow: main",
+				Target:      github.RulesetTargetBranch,
+				Enforcement: github.RulesetEnforcementEvaluate,
+				Conditions:  conditions,
+				BypassActors: []github.RulesetBypassActor{
+					{ActorID: 1234, ActorType: github.RulesetActorIntegration, BypassMode: "always"},
+					{ActorID: orgAdminActorID, ActorType: github.RulesetActorOrganizationAdmin, BypassMode: "always"},
+					{ActorID: maintainRoleID, ActorType: github.RulesetActorRepositoryRole, BypassMode: "always"},
+					{ActorID: 42, ActorType: github.RulesetActorTeam, BypassMode: "pull_request"},
+				},
+				Rules: []github.RulesetRule{
+					{
+						Type: github.RulePullRequest,
+						Parameters: &github.RulesetRuleParameters{
+							RequiredApprovingReviewCount:   &two,
+							DismissStaleReviewsOnPush:      &[]bool{false}[0],
+							RequireCodeOwnerReview:         &yes,
+							RequireLastPushApproval:        &[]bool{false}[0],
+							RequiredReviewThreadResolution: &[]bool{false}[0],
+						},
+					},
+					{Type: github.RuleRequiredLinearHistory},
+					{Type: github.RuleRequiredSignatures},
+					{
+						Type: github.RuleRequiredStatusChecks,
+						Parameters: &github.RulesetRuleParameters{
+							RequiredStatusChecks:       []github.RulesetStatusCheck{{Context: "e2e"}, {Context: "unit"}},
+							StrictRequiredStatusChecks: &yes,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unknown team",
+			policy: config.Policy{Rulesets: &config.RulesetPolicy{
+				Enabled:      &yes,
+				BypassActors: []config.RulesetBypassActor{{Team: "missing"}},
+			}},
+			err: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := makeRuleset("main", tc.policy, teamID)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("ruleset differs from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestEqualRulesets(t *testing.T) {
+	yes := true
+	ruleset := func(id int, contexts ...string) *github.Ruleset {
+		params := &github.RulesetRuleParameters{StrictRequiredStatusChecks: &yes}
+		for _, c := range contexts {
+			params.RequiredStatusChecks = append(params.RequiredStatusChecks, github.RulesetStatusCheck{Context: c})
+		}
+		return &github.Ruleset{
+			ID:          id,
+			Name:        "prow: main",
+			Enforcement: github.RulesetEnforcementActive,
+			Rules: []github.RulesetRule{
+				{Type: github.RuleRequiredStatusChecks, Parameters: params},
+				{Type: github.RuleNonFastForward},
+			},
+		}
+	}
+	reordered := ruleset(0, "e2e", "unit")
+	reordered.Rules[0], reordered.Rules[1] = reordered.Rules[1], reordered.Rules[0]
+
+	cases := []struct {
+		name     string
+		state    *github.Ruleset
+		request  *github.Ruleset
+		expected bool
+	}{
+		{
+			name:     "both nil",
+			expected: true,
+		},
+		{
+			name:    "missing ruleset",
+			request: ruleset(0, "unit"),
+		},
+		{
+			name:  "unwanted ruleset",
+			state: ruleset(1, "unit"),
+		},
+		{
+			name:     "order and ID are ignored",
+			state:    ruleset(1, "unit", "e2e"),
+			request:  reordered,
+			expected: true,
+		},
+		{
+			name:    "different contexts",
+			state:   ruleset(1, "unit"),
+			request: ruleset(0, "unit", "e2e"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := equalRulesets(tc.state, tc.request); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -33,6 +33,8 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/yaml"
@@ -82,6 +84,14 @@ type options struct {
 	expensive              bool
 	includeDefaultWarnings bool
 
+	policies               flagutil.Strings
+	policyViolationsOutput string
+
+	resourceRequestsExempt flagutil.Strings
+	maxResourceRequests    flagutil.Strings
+	// maxRequests holds the parsed maxResourceRequests by cluster.
+	maxRequests map[string]coreapi.ResourceList
+
 	github  flagutil.GitHubOptions
 	storage flagutil.StorageClientOptions
 }
@@ -123,6 +133,11 @@ const (
 	validateLabelWarning                          = "validate-label"
 	requiredJobAnnotationsWarning                 = "required-job-annotations"
 	periodicDefaultCloneWarning                   = "periodic-default-clone-config"
+	unusedPresetsWarning                          = "unused-presets"
+	unusedPluginConfigWarning                     = "unused-plugin-config"
+	shadowedDecorationConfigWarning               = "shadowed-decoration-config"
+	unmatchedTideQueriesWarning                   = "unmatched-tide-queries"
+	resourceRequestsWarning                       = "resource-requests"
 
 	defaultHourlyTokens = 3000
 	defaultAllowedBurst = 100
@@ -160,6 +175,14 @@ var optionalWarnings = []string{
 	// https://github.com/kubernetes/test-infra/pull/21075#issuecomment-862550510
 	unknownFieldsAllWarning,
 	validateGitHubAppInstallationWarning,
+	// The following warnings find configuration that has no effect anymore.
+	// They are optional because of false positives, e.g. presets used only
+	// by jobs in inrepoconfig.
+	unusedPresetsWarning,
+	unusedPluginConfigWarning,
+	shadowedDecorationConfigWarning,
+	unmatchedTideQueriesWarning,
+	resourceRequestsWarning,
 }
 
 var throttlerDefaults = flagutil.ThrottlerDefaults(defaultHourlyTokens, defaultAllowedBurst)
@@ -184,6 +207,27 @@ func (o *options) DefaultAndValidate() error {
 	if o.prowYAMLPath != "" && o.prowYAMLRepoName == "" {
 		return errors.New("--prow-yaml-repo-path requires --prow-yaml-repo-name to be set")
 	}
+	for _, max := range o.maxResourceRequests.Strings() {
+		cluster, request, found := strings.Cut(max, ":")
+		resourceName, quantity, found2 := strings.Cut(request, "=")
+		if !found || !found2 || cluster == "" || resourceName == "" {
+			return fmt.Errorf("--max-resource-requests must be of the form <cluster>:<resource>=<quantity>, got %q", max)
+		}
+		q, err := resource.ParseQuantity(quantity)
+		if err != nil {
+			return fmt.Errorf("invalid quantity in --max-resource-requests %q: %w", max, err)
+		}
+		if o.maxRequests == nil {
+			o.maxRequests = map[string]coreapi.ResourceList{}
+		}
+		if o.maxRequests[cluster] == nil {
+			o.maxRequests[cluster] = coreapi.ResourceList{}
+		}
+		o.maxRequests[cluster][coreapi.ResourceName(resourceName)] = q
+	}
+	if o.policyViolationsOutput != "" && len(o.policies.Strings()) == 0 {
+		return errors.New("--policy-violations-output requires --policy to be set")
+	}
 	for _, warning := range o.warnings.Strings() {
 		found := false
 		for _, registeredWarning := range allWarnings {
@@ -218,6 +262,10 @@ func (o *options) gatherOptions(flag *flag.FlagSet, args []string) error {
 	flag.BoolVar(&o.expensive, "expensive-checks", false, "If set, additional expensive warnings will be enabled")
 	flag.BoolVar(&o.strict, "strict", false, "If set, consider all warnings as errors.")
 	flag.BoolVar(&o.includeDefaultWarnings, "include-default-warnings", false, "If set force inclusion of default warning set. Normally this is inferred based on a lack of '--warnings' flags.")
+	flag.Var(&o.resourceRequestsExempt, "resource-requests-exempt", "Org or org/repo whose jobs are exempt from the resource-requests warning. Use repeatedly to provide a list of exemptions")
+	flag.Var(&o.maxResourceRequests, "max-resource-requests", "Maximum requests of a job on a cluster for the resource-requests warning, in the form <cluster>:<resource>=<quantity>, e.g. default:memory=32Gi. Use repeatedly to provide a list of maximums")
+	flag.Var(&o.policies, "policy", "Rego policy file or directory of policies to evaluate against the config. Policies in a package below 'prow' report violations in their 'deny' set. Use repeatedly to provide a list of policies")
+	flag.StringVar(&o.policyViolationsOutput, "policy-violations-output", "", "If set, the policy violations are written to this file as JSON. Requires --policy to be set.")
 	o.github.AddCustomizedFlags(flag, throttlerDefaults)
 	o.github.AllowAnonymous = true
 	o.config.AddFlags(flag)
@@ -448,6 +496,41 @@ func validate(o options) error {
 		}
 	}
 
+	if o.warningEnabled(unusedPresetsWarning) {
+		if err := validateUnusedPresets(cfg.JobConfig); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if pcfg != nil && o.warningEnabled(unusedPluginConfigWarning) {
+		if err := validateUnusedPluginConfig(cfg, pcfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if o.warningEnabled(shadowedDecorationConfigWarning) {
+		if err := validateShadowedDecorationConfigs(cfg.Plank.DefaultDecorationConfigs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if o.warningEnabled(unmatchedTideQueriesWarning) {
+		if err := validateUnmatchedTideQueries(cfg, pcfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if o.warningEnabled(resourceRequestsWarning) {
+		if err := validateResourceRequests(cfg.JobConfig, sets.New[string](o.resourceRequestsExempt.Strings()...), o.maxRequests); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(o.policies.Strings()) > 0 {
+		violations, err := validatePolicies(o.policies.Strings(), o.policyViolationsOutput, cfg, pcfg)
+		if err != nil {
+			return fmt.Errorf("error evaluating policies: %w", err)
+		}
+		errs = append(errs, violations...)
+	}
+
 	// validate rerun commands match presubmit job triggering regex
 	for _, presubmits := range cfg.JobConfig.PresubmitsStatic {
 		for _, p := range presubmits {
@@ -1553,3 +1636,221 @@ func validateRequiredJobAnnotations(a []string, c config.JobConfig) error {
 	}
 	return utilerrors.NewAggregate(errs)
 }
+
+// forEachStaticJob calls f with the repo, which is empty for periodics
+// without extra refs, and the JobBase of every statically configured job.
+func forEachStaticJob(c config.JobConfig, f func(repo string, job config.JobBase)) {
+	for repo, presubmits := range c.PresubmitsStatic {
+		for _, presubmit := range presubmits {
+			f(repo, presubmit.JobBase)
+		}
+	}
+	for repo, postsubmits := range c.PostsubmitsStatic {
+		for _, postsubmit := range postsubmits {
+			f(repo, postsubmit.JobBase)
+		}
+	}
+	for _, periodic := range c.Periodics {
+		var repo string
+		if len(periodic.ExtraRefs) > 0 {
+			repo = periodic.ExtraRefs[0].Org + "/" + periodic.ExtraRefs[0].Repo
+		}
+		f(repo, periodic.JobBase)
+	}
+}
+
+// validateUnusedPresets warns about presets that apply to no statically
+// configured job because no job has all of their labels.
+func validateUnusedPresets(c config.JobConfig) error {
+	used := make([]bool, len(c.Presets))
+	forEachStaticJob(c, func(_ string, job config.JobBase) {
+		for i, preset := range c.Presets {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			for key, value := range preset.Labels {
+				if job.Labels[key] != value {
+					used[i] = false
+					break
+				}
+			}
+		}
+	})
+	var errs []error
+	for i, preset := range c.Presets {
+		if !used[i] {
+			errs = append(errs, fmt.Errorf("preset %d with labels %v is not used by any job", i, preset.Labels))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// reposWithJobs returns the repos that have statically configured jobs.
+func reposWithJobs(c config.JobConfig) sets.Set[string] {
+	repos := sets.New[string]()
+	forEachStaticJob(c, func(repo string, _ config.JobBase) {
+		if repo != "" {
+			repos.Insert(repo)
+		}
+	})
+	return repos
+}
+
+// validateUnusedPluginConfig warns about repos that have plugins enabled but
+// neither statically configured jobs nor inrepoconfig, which is typical for
+// repos that were deleted or renamed.
+func validateUnusedPluginConfig(cfg *config.Config, pcfg *plugins.Configuration) error {
+	withJobs := reposWithJobs(cfg.JobConfig)
+	var errs []error
+	for _, orgRepo := range sets.List(sets.KeySet(pcfg.Plugins)) {
+		if !strings.Contains(orgRepo, "/") || withJobs.Has(orgRepo) || cfg.InRepoConfigEnabled(orgRepo) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("plugins are configured for %s, which has no jobs", orgRepo))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateShadowedDecorationConfigs warns about default decoration config
+// entries that have no effect because all fields they set are overridden by
+// later entries that apply to every job the entry applies to.
+func validateShadowedDecorationConfigs(entries []*config.DefaultDecorationConfigEntry) error {
+	covers := func(later, earlier string) bool {
+		if later == "" || later == "*" || later == earlier {
+			return true
+		}
+		return !strings.Contains(later, "/") && strings.HasPrefix(earlier, later+"/")
+	}
+	var errs []error
+	for i, entry := range entries {
+		fields, err := decorationConfigFields(entry.Config)
+		if err != nil {
+			return err
+		}
+		if fields.Len() == 0 {
+			continue
+		}
+		overridden := sets.New[string]()
+		for _, later := range entries[i+1:] {
+			if !covers(later.OrgRepo, entry.OrgRepo) || !covers(later.Cluster, entry.Cluster) {
+				continue
+			}
+			laterFields, err := decorationConfigFields(later.Config)
+			if err != nil {
+				return err
+			}
+			overridden = overridden.Union(laterFields)
+		}
+		if overridden.IsSuperset(fields) {
+			errs = append(errs, fmt.Errorf("default decoration config %d for repo %q and cluster %q is shadowed by later entries", i, entry.OrgRepo, entry.Cluster))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// decorationConfigFields returns the paths of the fields set in dc. Nested
+// objects are descended into, as they are merged field by field.
+func decorationConfigFields(dc *v1.DecorationConfig) (sets.Set[string], error) {
+	fields := sets.New[string]()
+	if dc == nil {
+		return fields, nil
+	}
+	raw, err := json.Marshal(dc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal decoration config: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decoration config: %w", err)
+	}
+	var collect func(prefix string, values map[string]interface{})
+	collect = func(prefix string, values map[string]interface{}) {
+		for key, value := range values {
+			if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+				collect(prefix+key+".", nested)
+				continue
+			}
+			fields.Insert(prefix + key)
+		}
+	}
+	collect("", values)
+	return fields, nil
+}
+
+// validateUnmatchedTideQueries warns about orgs and repos in tide queries
+// that are configured nowhere else, neither with jobs, inrepoconfig nor
+// plugins, which is typical for repos that were deleted or renamed.
+func validateUnmatchedTideQueries(cfg *config.Config, pcfg *plugins.Configuration) error {
+	configured := reposWithJobs(cfg.JobConfig)
+	if pcfg != nil {
+		configured.Insert(sets.List(sets.KeySet(pcfg.Plugins))...)
+	}
+	repoConfigured := func(orgRepo string) bool {
+		org, _, _ := strings.Cut(orgRepo, "/")
+		return configured.Has(orgRepo) || configured.Has(org) || cfg.InRepoConfigEnabled(orgRepo)
+	}
+	orgConfigured := func(org string) bool {
+		for orgRepo := range configured {
+			if orgRepo == org || strings.HasPrefix(orgRepo, org+"/") {
+				return true
+			}
+		}
+		return cfg.InRepoConfigEnabled(org)
+	}
+
+	var errs []error
+	for i, query := range cfg.Tide.Queries {
+		for _, org := range query.Orgs {
+			if !orgConfigured(org) {
+				errs = append(errs, fmt.Errorf("tide query %d matches org %s, which has no jobs or plugins configured", i, org))
+			}
+		}
+		for _, repo := range query.Repos {
+			if !repoConfigured(repo) {
+				errs = append(errs, fmt.Errorf("tide query %d matches repo %s, which has no jobs or plugins configured", i, repo))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateResourceRequests requires all jobs run by the kubernetes agent,
+// except those of exempt orgs and repos, to request CPU and memory for all
+// their containers, and warns about jobs whose total requests exceed the
+// maximums of their cluster.
+func validateResourceRequests(c config.JobConfig, exempt sets.Set[string], maxRequests map[string]coreapi.ResourceList) error {
+	var errs []error
+	forEachStaticJob(c, func(repo string, job config.JobBase) {
+		if job.Agent != string(v1.KubernetesAgent) || job.Spec == nil {
+			return
+		}
+		org, _, _ := strings.Cut(repo, "/")
+		if exempt.Has(repo) || exempt.Has(org) {
+			return
+		}
+		total := coreapi.ResourceList{}
+		for _, container := range job.Spec.Containers {
+			for _, name := range []coreapi.ResourceName{coreapi.ResourceCPU, coreapi.ResourceMemory} {
+				if _, ok := container.Resources.Requests[name]; !ok {
+					errs = append(errs, fmt.Errorf("job %s does not request %s for container %q", job.Name, name, container.Name))
+				}
+			}
+			for name, quantity := range container.Resources.Requests {
+				sum := total[name]
+				sum.Add(quantity)
+				total[name] = sum
+			}
+		}
+		cluster := job.Cluster
+		if cluster == "" {
+			cluster = kube.DefaultClusterAlias
+		}
+		for name, max := range maxRequests[cluster] {
+			if requested, ok := total[name]; ok && requested.Cmp(max) > 0 {
+				errs = append(errs, fmt.Errorf("job %s requests %s of %s, more than the maximum of %s on cluster %s", job.Name, requested.String(), name, max.String(), cluster))
+			}
+		}
+	})
+	return utilerrors.NewAggregate(errs)
+}
@@ -25,12 +25,15 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"testing"
 	"testing/fstest"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/diff"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -2480,3 +2483,302 @@ func TestValidateRequiredJobAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluatePolicies(t *testing.T) {
+	const resourcesPolicy = `package prow.resources
+
+deny[{"msg": msg, "job": job.name}] {
+	job := input.config.presubmits[_][_]
+	job.cluster == "trusted"
+	container := job.spec.containers[_]
+	not container.resources.requests
+	msg := sprintf("job %s on the trusted cluster must set resource requests", [job.name])
+}
+`
+	const imagesPolicy = `package prow.images
+
+deny[msg] {
+	job := input.config.periodics[_]
+	image := job.spec.containers[_].image
+	not startswith(image, "gcr.io/approved/")
+	msg := sprintf("periodic %s uses unapproved image %s", [job.name, image])
+}
+`
+	cfg := &config.Config{
+		JobConfig: config.JobConfig{
+			PresubmitsStatic: map[string][]config.Presubmit{
+				"org/repo": {
+					{JobBase: config.JobBase{Name: "unit", Cluster: "trusted", Spec: &v1.PodSpec{Containers: []v1.Container{{Image: "gcr.io/approved/go"}}}}},
+					{JobBase: config.JobBase{Name: "lint", Cluster: "trusted", Spec: &v1.PodSpec{Containers: []v1.Container{{
+						Image:     "gcr.io/approved/go",
+						Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+					}}}}},
+					{JobBase: config.JobBase{Name: "e2e", Cluster: "default", Spec: &v1.PodSpec{Containers: []v1.Container{{Image: "gcr.io/approved/go"}}}}},
+				},
+			},
+			Periodics: []config.Periodic{
+				{JobBase: config.JobBase{Name: "cleanup", Spec: &v1.PodSpec{Containers: []v1.Container{{Image: "docker.io/random/cleanup"}}}}},
+				{JobBase: config.JobBase{Name: "bump", Spec: &v1.PodSpec{Containers: []v1.Container{{Image: "gcr.io/approved/bump"}}}}},
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	for name, policy := range map[string]string{
+		"resources.rego":      resourcesPolicy,
+		"images.rego":         imagesPolicy,
+		"images_test.rego":    "this is not rego",
+		"README.md":           "this is not rego either",
+		"nested/ignored.yaml": "foo: bar",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(policy), 0644); err != nil {
+			t.Fatalf("failed to write policy: %v", err)
+		}
+	}
+	modules, err := loadPolicies([]string{dir})
+	if err != nil {
+		t.Fatalf("failed to load policies: %v", err)
+	}
+	if len(modules) != 2 {
+		t.Fatalf("expected two policies, got %d", len(modules))
+	}
+
+	violations, err := evaluatePolicies(context.Background(), modules, cfg, nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate policies: %v", err)
+	}
+	expected := []policyViolation{
+		{Policy: "images", Message: "periodic cleanup uses unapproved image docker.io/random/cleanup"},
+		{Policy: "resources", Message: "job unit on the trusted cluster must set resource requests", Details: map[string]interface{}{"job": "unit"}},
+	}
+	if diff := cmp.Diff(expected, violations); diff != "" {
+		t.Errorf("violations differ (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadPoliciesErrors(t *testing.T) {
+	if _, err := loadPolicies([]string{filepath.Join(t.TempDir(), "missing.rego")}); err == nil {
+		t.Error("expected an error for a missing policy")
+	}
+	if _, err := loadPolicies([]string{t.TempDir()}); err == nil {
+		t.Error("expected an error for a directory without policies")
+	}
+}
+
+func TestValidatePoliciesOutput(t *testing.T) {
+	dir := t.TempDir()
+	policy := filepath.Join(dir, "policy.rego")
+	if err := os.WriteFile(policy, []byte("package prow.always\n\ndeny[\"always violated\"] { true }\n"), 0644); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	output := filepath.Join(dir, "violations.json")
+	errs, err := validatePolicies([]string{policy}, output, &config.Config{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Error() != "policy always: always violated" {
+		t.Errorf("expected one violation, got %v", errs)
+	}
+	raw, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read violations: %v", err)
+	}
+	if !strings.Contains(string(raw), `"msg": "always violated"`) {
+		t.Errorf("expected the violation in the output, got %s", raw)
+	}
+}
+
+func TestValidateUnusedPresets(t *testing.T) {
+	c := config.JobConfig{
+		Presets: []config.Preset{
+			{Labels: map[string]string{"preset-service-account": "true"}},
+			{Labels: map[string]string{"preset-dind": "true"}},
+			{Labels: map[string]string{"preset-dind": "true", "preset-bazel": "true"}},
+			{},
+		},
+		PresubmitsStatic: map[string][]config.Presubmit{
+			"org/repo": {{JobBase: config.JobBase{Name: "unit", Labels: map[string]string{"preset-service-account": "true"}}}},
+		},
+		Periodics: []config.Periodic{
+			{JobBase: config.JobBase{Name: "e2e", Labels: map[string]string{"preset-dind": "true"}}},
+		},
+	}
+	err := validateUnusedPresets(c)
+	if err == nil {
+		t.Fatal("expected an error for the unused preset")
+	}
+	if errs := err.(utilerrors.Aggregate).Errors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "preset 2 ") {
+		t.Errorf("expected only preset 2 to be unused, got %v", err)
+	}
+}
+
+func TestValidateUnusedPluginConfig(t *testing.T) {
+	cfg := &config.Config{
+		JobConfig: config.JobConfig{
+			PresubmitsStatic: map[string][]config.Presubmit{"org/jobs": {{JobBase: config.JobBase{Name: "unit"}}}},
+			Periodics: []config.Periodic{
+				{JobBase: config.JobBase{Name: "nightly", UtilityConfig: config.UtilityConfig{ExtraRefs: []prowapi.Refs{{Org: "org", Repo: "nightly"}}}}},
+			},
+		},
+		ProwConfig: config.ProwConfig{
+			InRepoConfig: config.InRepoConfig{Enabled: map[string]*bool{"org/inrepo": utilpointer.Bool(true)}},
+		},
+	}
+	pcfg := &plugins.Configuration{
+		Plugins: plugins.Plugins{
+			"org":         {Plugins: []string{"lgtm"}},
+			"org/jobs":    {Plugins: []string{"trigger"}},
+			"org/nightly": {Plugins: []string{"trigger"}},
+			"org/inrepo":  {Plugins: []string{"trigger"}},
+			"org/gone":    {Plugins: []string{"trigger"}},
+		},
+	}
+	err := validateUnusedPluginConfig(cfg, pcfg)
+	if err == nil || err.Error() != "plugins are configured for org/gone, which has no jobs" {
+		t.Errorf("expected only org/gone to be reported, got %v", err)
+	}
+}
+
+func TestValidateShadowedDecorationConfigs(t *testing.T) {
+	timeout := &prowapi.Duration{Duration: time.Hour}
+	testCases := []struct {
+		name     string
+		entries  []*config.DefaultDecorationConfigEntry
+		expected []string
+	}{
+		{
+			name: "later global entry overrides an org entry",
+			entries: []*config.DefaultDecorationConfigEntry{
+				{OrgRepo: "org", Config: &prowapi.DecorationConfig{Timeout: timeout}},
+				{Config: &prowapi.DecorationConfig{Timeout: timeout, GracePeriod: timeout}},
+			},
+			expected: []string{`default decoration config 0 for repo "org" and cluster "" is shadowed by later entries`},
+		},
+		{
+			name: "later entry only overrides some fields",
+			entries: []*config.DefaultDecorationConfigEntry{
+				{OrgRepo: "org", Config: &prowapi.DecorationConfig{Timeout: timeout, GracePeriod: timeout}},
+				{Config: &prowapi.DecorationConfig{Timeout: timeout}},
+			},
+		},
+		{
+			name: "later entry is narrower",
+			entries: []*config.DefaultDecorationConfigEntry{
+				{OrgRepo: "org", Config: &prowapi.DecorationConfig{Timeout: timeout}},
+				{OrgRepo: "org/repo", Config: &prowapi.DecorationConfig{Timeout: timeout}},
+			},
+		},
+		{
+			name: "later entry is for another cluster",
+			entries: []*config.DefaultDecorationConfigEntry{
+				{Cluster: "default", Config: &prowapi.DecorationConfig{Timeout: timeout}},
+				{Cluster: "trusted", Config: &prowapi.DecorationConfig{Timeout: timeout}},
+			},
+		},
+		{
+			name: "nested fields are compared individually",
+			entries: []*config.DefaultDecorationConfigEntry{
+				{Config: &prowapi.DecorationConfig{GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "a", PathStrategy: "explicit"}}},
+				{OrgRepo: "org", Config: &prowapi.DecorationConfig{GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "b"}}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+			if err := validateShadowedDecorationConfigs(tc.entries); err != nil {
+				for _, e := range err.(utilerrors.Aggregate).Errors() {
+					actual = append(actual, e.Error())
+				}
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("errors differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateUnmatchedTideQueries(t *testing.T) {
+	cfg := &config.Config{
+		JobConfig: config.JobConfig{
+			PresubmitsStatic: map[string][]config.Presubmit{"org/jobs": {{JobBase: config.JobBase{Name: "unit"}}}},
+		},
+		ProwConfig: config.ProwConfig{
+			Tide: config.Tide{TideGitHubConfig: config.TideGitHubConfig{Queries: config.TideQueries{
+				{Orgs: []string{"org", "plugins-org", "gone-org"}},
+				{Repos: []string{"org/jobs", "plugins-org/repo", "org/gone"}},
+			}}},
+		},
+	}
+	pcfg := &plugins.Configuration{Plugins: plugins.Plugins{"plugins-org": {Plugins: []string{"lgtm"}}}}
+	var actual []string
+	if err := validateUnmatchedTideQueries(cfg, pcfg); err != nil {
+		for _, e := range err.(utilerrors.Aggregate).Errors() {
+			actual = append(actual, e.Error())
+		}
+	}
+	expected := []string{
+		"tide query 0 matches org gone-org, which has no jobs or plugins configured",
+		"tide query 1 matches repo org/gone, which has no jobs or plugins configured",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("errors differ (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateResourceRequests(t *testing.T) {
+	requests := func(cpu, memory string) v1.ResourceRequirements {
+		r := v1.ResourceRequirements{Requests: v1.ResourceList{}}
+		if cpu != "" {
+			r.Requests[v1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			r.Requests[v1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return r
+	}
+	job := func(name, cluster string, resources ...v1.ResourceRequirements) config.JobBase {
+		spec := &v1.PodSpec{}
+		for i, r := range resources {
+			spec.Containers = append(spec.Containers, v1.Container{Name: fmt.Sprintf("c%d", i), Resources: r})
+		}
+		return config.JobBase{Name: name, Agent: string(prowapi.KubernetesAgent), Cluster: cluster, Spec: spec}
+	}
+	cfg := config.JobConfig{
+		PresubmitsStatic: map[string][]config.Presubmit{
+			"org/repo": {
+				{JobBase: job("complete", "", requests("1", "1Gi"))},
+				{JobBase: job("no-memory", "", requests("1", ""))},
+				{JobBase: job("too-big", "", requests("6", "1Gi"), requests("3", "1Gi"))},
+				{JobBase: job("big-elsewhere", "build", requests("16", "1Gi"))},
+				{JobBase: config.JobBase{Name: "tekton", Agent: string(prowapi.TektonAgent)}},
+			},
+			"org/exempt": {{JobBase: job("exempt-repo", "", requests("", ""))}},
+			"other/repo": {{JobBase: job("exempt-org", "", requests("", ""))}},
+		},
+		Periodics: []config.Periodic{{JobBase: job("no-cpu", "build", requests("", "1Gi"))}},
+	}
+	maxRequests := map[string]v1.ResourceList{
+		"default": {v1.ResourceCPU: resource.MustParse("8")},
+	}
+	var actual []string
+	if err := validateResourceRequests(cfg, sets.New[string]("org/exempt", "other"), maxRequests); err != nil {
+		for _, e := range err.(utilerrors.Aggregate).Errors() {
+			actual = append(actual, e.Error())
+		}
+	}
+	sort.Strings(actual)
+	expected := []string{
+		`job no-cpu does not request cpu for container "c0"`,
+		`job no-memory does not request memory for container "c0"`,
+		"job too-big requests 9 of cpu, more than the maximum of 8 on cluster default",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("errors differ (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/rego"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// policyQuery collects the deny rules of all policies. Policies must be in a
+// package below "prow", e.g. "package prow.jobs", and define a "deny" set
+// whose elements are either messages or objects with a "msg" field.
+const policyQuery = "violation := data.prow[policy].deny[_]"
+
+// policyInput is the input policies are evaluated against.
+type policyInput struct {
+	Config  *config.Config         `json:"config"`
+	Plugins *plugins.Configuration `json:"plugins,omitempty"`
+}
+
+// policyViolation is a violation reported by a policy.
+type policyViolation struct {
+	// Policy is the package of the policy below "prow".
+	Policy string `json:"policy"`
+	// Message describes the violation.
+	Message string `json:"msg"`
+	// Details holds all other fields of violations reported as objects,
+	// e.g. the name of the offending job.
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+func (v policyViolation) Error() string {
+	return fmt.Sprintf("policy %s: %s", v.Policy, v.Message)
+}
+
+// loadPolicies reads the Rego modules at paths, which are either files or
+// directories that are searched for .rego files. Test files are skipped.
+func loadPolicies(paths []string) (map[string]string, error) {
+	modules := map[string]string{}
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || (path != root && (filepath.Ext(path) != ".rego" || strings.HasSuffix(path, "_test.rego"))) {
+				return nil
+			}
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			modules[path] = string(raw)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policies from %s: %w", root, err)
+		}
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no policies found in %v", paths)
+	}
+	return modules, nil
+}
+
+// evaluatePolicies evaluates the Rego modules against the config and returns
+// the violations they report, sorted by policy and message.
+func evaluatePolicies(ctx context.Context, modules map[string]string, cfg *config.Config, pcfg *plugins.Configuration) ([]policyViolation, error) {
+	// Round trip the input through JSON so that policies see the field names
+	// of the YAML config rather than those of the Go structs.
+	raw, err := json.Marshal(policyInput{Config: cfg, Plugins: pcfg})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var input interface{}
+	if err := json.Unmarshal(raw, &input); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	options := []func(*rego.Rego){rego.Query(policyQuery), rego.Input(input)}
+	for name, module := range modules {
+		options = append(options, rego.Module(name, module))
+	}
+	results, err := rego.New(options...).Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate policies: %w", err)
+	}
+
+	var violations []policyViolation
+	for _, result := range results {
+		policy, _ := result.Bindings["policy"].(string)
+		violation := policyViolation{Policy: policy}
+		switch v := result.Bindings["violation"].(type) {
+		case string:
+			violation.Message = v
+		case map[string]interface{}:
+			violation.Message, _ = v["msg"].(string)
+			for key, value := range v {
+				if key == "msg" {
+					continue
+				}
+				if violation.Details == nil {
+					violation.Details = map[string]interface{}{}
+				}
+				violation.Details[key] = value
+			}
+		default:
+			violation.Message = fmt.Sprintf("%v", v)
+		}
+		violations = append(violations, violation)
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].Policy != violations[j].Policy {
+			return violations[i].Policy < violations[j].Policy
+		}
+		return violations[i].Message < violations[j].Message
+	})
+	return violations, nil
+}
+
+// validatePolicies evaluates the policies at paths and returns an error for
+// every violation. If output is set, the violations are also written to it
+// as JSON.
+func validatePolicies(paths []string, output string, cfg *config.Config, pcfg *plugins.Configuration) ([]error, error) {
+	modules, err := loadPolicies(paths)
+	if err != nil {
+		return nil, err
+	}
+	violations, err := evaluatePolicies(context.Background(), modules, cfg, pcfg)
+	if err != nil {
+		return nil, err
+	}
+	if output != "" {
+		raw, err := json.MarshalIndent(violations, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal policy violations: %w", err)
+		}
+		if err := os.WriteFile(output, raw, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write policy violations: %w", err)
+		}
+	}
+	var errs []error
+	for _, v := range violations {
+		errs = append(errs, v)
+	}
+	return errs, nil
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 	"sigs.k8s.io/prow/pkg/resultstore"
 
@@ -33,19 +34,23 @@ import (
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/config/secret"
 	"sigs.k8s.io/prow/pkg/crier"
+	bitbucketreporter "sigs.k8s.io/prow/pkg/crier/reporters/bitbucket"
 	gcsreporter "sigs.k8s.io/prow/pkg/crier/reporters/gcs"
 	k8sgcsreporter "sigs.k8s.io/prow/pkg/crier/reporters/gcs/kubernetes"
 	gerritreporter "sigs.k8s.io/prow/pkg/crier/reporters/gerrit"
 	githubreporter "sigs.k8s.io/prow/pkg/crier/reporters/github"
+	gitlabreporter "sigs.k8s.io/prow/pkg/crier/reporters/gitlab"
 	pubsubreporter "sigs.k8s.io/prow/pkg/crier/reporters/pubsub"
 	resultstorereporter "sigs.k8s.io/prow/pkg/crier/reporters/resultstore"
 	slackreporter "sigs.k8s.io/prow/pkg/crier/reporters/slack"
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
+	gerritclient "sigs.k8s.io/prow/pkg/gerrit/client"
 	"sigs.k8s.io/prow/pkg/interrupts"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/metrics"
 	slackclient "sigs.k8s.io/prow/pkg/slack"
+	"sigs.k8s.io/prow/pkg/tracing"
 )
 
 type options struct {
@@ -54,6 +59,8 @@ type options struct {
 	github           prowflagutil.GitHubOptions
 	githubEnablement prowflagutil.GitHubEnablementOptions
 	gerrit           prowflagutil.GerritOptions
+	gitlab           prowflagutil.GitLabOptions
+	bitbucket        prowflagutil.BitbucketOptions
 
 	config configflagutil.ConfigOptions
 
@@ -64,6 +71,8 @@ type options struct {
 	blobStorageWorkers    int
 	k8sBlobStorageWorkers int
 	resultStoreWorkers    int
+	gitlabWorkers         int
+	bitbucketWorkers      int
 
 	slackTokenFile            string
 	additionalSlackTokenFiles slackclient.HostsFlag
@@ -71,6 +80,7 @@ type options struct {
 	storage prowflagutil.StorageClientOptions
 
 	instrumentationOptions prowflagutil.InstrumentationOptions
+	tracing                prowflagutil.TracingOptions
 
 	k8sReportFraction float64
 
@@ -78,10 +88,13 @@ type options struct {
 	reportAgent string
 
 	resultstoreArtifactsDirOnly bool
+
+	failedReportDumpPath string
+	maxReportAttempts    int
 }
 
 func (o *options) validate() error {
-	if o.gerritWorkers+o.pubsubWorkers+o.githubWorkers+o.slackWorkers+o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers <= 0 {
+	if o.gerritWorkers+o.pubsubWorkers+o.githubWorkers+o.slackWorkers+o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers+o.gitlabWorkers+o.bitbucketWorkers <= 0 {
 		return errors.New("crier need to have at least one report worker to start")
 	}
 
@@ -89,6 +102,10 @@ func (o *options) validate() error {
 		return errors.New("--kubernetes-report-fraction must be a float between 0 and 1")
 	}
 
+	if o.maxReportAttempts < 1 {
+		return errors.New("--max-report-attempts must be at least 1")
+	}
+
 	if o.gerritWorkers > 0 {
 		if o.cookiefilePath == "" {
 			logrus.Info("--cookiefile is not set, using anonymous authentication")
@@ -104,13 +121,31 @@ func (o *options) validate() error {
 		}
 	}
 
+	if o.gitlabWorkers > 0 {
+		if !o.gitlab.Enabled() {
+			return errors.New("--gitlab-endpoint must be set when --gitlab-workers is set")
+		}
+		if err := o.gitlab.Validate(o.dryrun); err != nil {
+			return err
+		}
+	}
+
+	if o.bitbucketWorkers > 0 {
+		if !o.bitbucket.Enabled() {
+			return errors.New("--bitbucket-endpoint or --bitbucket-cloud must be set when --bitbucket-workers is set")
+		}
+		if err := o.bitbucket.Validate(o.dryrun); err != nil {
+			return err
+		}
+	}
+
 	if o.slackWorkers > 0 {
 		if o.slackTokenFile == "" && len(o.additionalSlackTokenFiles) == 0 {
 			return errors.New("one of --slack-token-file or --additional-slack-token-files must be set")
 		}
 	}
 
-	for _, opt := range []interface{ Validate(bool) error }{&o.client, &o.githubEnablement, &o.config} {
+	for _, opt := range []interface{ Validate(bool) error }{&o.client, &o.githubEnablement, &o.config, &o.tracing} {
 		if err := opt.Validate(o.dryrun); err != nil {
 			return err
 		}
@@ -125,6 +160,8 @@ func (o *options) parseArgs(fs *flag.FlagSet, args []string) error {
 	fs.IntVar(&o.pubsubWorkers, "pubsub-workers", 0, "Number of pubsub report workers (0 means disabled)")
 	fs.IntVar(&o.githubWorkers, "github-workers", 0, "Number of github report workers (0 means disabled)")
 	fs.IntVar(&o.slackWorkers, "slack-workers", 0, "Number of Slack report workers (0 means disabled)")
+	fs.IntVar(&o.gitlabWorkers, "gitlab-workers", 0, "Number of GitLab report workers (0 means disabled)")
+	fs.IntVar(&o.bitbucketWorkers, "bitbucket-workers", 0, "Number of Bitbucket report workers (0 means disabled)")
 	fs.Var(&o.additionalSlackTokenFiles, "additional-slack-token-files", "Map of additional slack token files. example: --additional-slack-token-files=foo=/etc/foo-slack-tokens/token, repeat flag for each host")
 	fs.IntVar(&o.blobStorageWorkers, "blob-storage-workers", 0, "Number of blob storage report workers (0 means disabled)")
 	fs.IntVar(&o.k8sBlobStorageWorkers, "kubernetes-blob-storage-workers", 0, "Number of Kubernetes-specific blob storage report workers (0 means disabled)")
@@ -133,6 +170,8 @@ func (o *options) parseArgs(fs *flag.FlagSet, args []string) error {
 	fs.StringVar(&o.reportAgent, "report-agent", "", "Only report specified agent - empty means report to all agents (effective for github and Slack only)")
 	fs.IntVar(&o.resultStoreWorkers, "resultstore-workers", 0, "Number of ResultStore report workers (0 means disabled)")
 	fs.BoolVar(&o.resultstoreArtifactsDirOnly, "resultstore-artifacts-dir-only", false, "Report the artifacts/ dir instead of subtree files (testing)")
+	fs.IntVar(&o.maxReportAttempts, "max-report-attempts", crier.DefaultMaxReportAttempts, "Number of failed attempts after which a report is given up on")
+	fs.StringVar(&o.failedReportDumpPath, "failed-report-dump-path", "", "Storage path (e.g. gs://bucket/crier) under which the reports that are given up on are dumped as JSON, empty means disabled")
 
 	// TODO(krzyzacy): implement dryrun for gerrit/pubsub
 	fs.BoolVar(&o.dryrun, "dry-run", false, "Run in dry-run mode, not doing actual report (effective for github and Slack only)")
@@ -140,9 +179,12 @@ func (o *options) parseArgs(fs *flag.FlagSet, args []string) error {
 	o.config.AddFlags(fs)
 	o.github.AddFlags(fs)
 	o.gerrit.AddFlags(fs)
+	o.gitlab.AddFlags(fs)
+	o.bitbucket.AddFlags(fs)
 	o.client.AddFlags(fs)
 	o.storage.AddFlags(fs)
 	o.instrumentationOptions.AddFlags(fs)
+	o.tracing.AddFlags(fs)
 	o.githubEnablement.AddFlags(fs)
 
 	fs.Parse(args)
@@ -165,7 +207,17 @@ func main() {
 
 	o := parseOptions()
 
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
 	pprof.Instrument(o.instrumentationOptions)
+	shutdownTracing, err := tracing.Setup(context.Background(), "crier", o.tracing)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error setting up tracing.")
+	}
+	interrupts.OnInterrupt(func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.WithError(err).Warn("Failed to flush traces.")
+		}
+	})
 
 	configAgent, err := o.config.ConfigAgent()
 	if err != nil {
@@ -178,6 +230,12 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to get kubeconfig")
 	}
+	infrastructureClient, err := o.client.InfrastructureClusterClient(o.dryrun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Kubernetes client for infrastructure cluster.")
+	}
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(cfg))
+	health.AddReadinessChecks(pjutil.KubernetesHealthCheck(infrastructureClient.Discovery().RESTClient()))
 	mgr, err := manager.New(restCfg, manager.Options{
 		Namespace:          cfg().ProwJobNamespace,
 		MetricsBindAddress: "0",
@@ -195,6 +253,19 @@ func main() {
 		logrus.WithError(err).Fatal("Failed to register kubeconfig change callback")
 	}
 
+	var opener io.Opener
+	if o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers > 0 || o.failedReportDumpPath != "" {
+		opener, err = o.storage.StorageClient(context.Background())
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating opener")
+		}
+	}
+
+	crierOpts := []crier.Option{crier.WithMaxReportAttempts(o.maxReportAttempts)}
+	if o.failedReportDumpPath != "" {
+		crierOpts = append(crierOpts, crier.WithFailedReportDumper(crier.NewStorageFailedReportDumper(opener, o.failedReportDumpPath)))
+	}
+
 	var hasReporter bool
 	if o.slackWorkers > 0 {
 		if cfg().SlackReporterConfigs == nil {
@@ -218,7 +289,7 @@ func main() {
 			}
 		}
 		slackReporter := slackreporter.New(slackConfig, o.dryrun, tokensMap)
-		if err := crier.New(mgr, slackReporter, o.slackWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		if err := crier.New(mgr, slackReporter, o.slackWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct slack reporter controller")
 		}
 	}
@@ -227,20 +298,24 @@ func main() {
 		orgRepoConfigGetter := func() *config.GerritOrgRepoConfigs {
 			return cfg().Gerrit.OrgReposConfig
 		}
-		gerritReporter, err := gerritreporter.NewReporter(orgRepoConfigGetter, o.cookiefilePath, mgr.GetClient(), o.gerrit.MaxQPS, o.gerrit.MaxBurst)
+		gerritOpts, err := gerritclient.OptionsFromFlags(&o.gerrit)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error configuring gerrit client")
+		}
+		gerritReporter, err := gerritreporter.NewReporter(orgRepoConfigGetter, o.cookiefilePath, mgr.GetClient(), o.gerrit.MaxQPS, o.gerrit.MaxBurst, gerritOpts...)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error starting gerrit reporter")
 		}
 
 		hasReporter = true
-		if err := crier.New(mgr, gerritReporter, o.gerritWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		if err := crier.New(mgr, gerritReporter, o.gerritWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct gerrit reporter controller")
 		}
 	}
 
 	if o.pubsubWorkers > 0 {
 		hasReporter = true
-		if err := crier.New(mgr, pubsubreporter.NewReporter(cfg), o.pubsubWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		if err := crier.New(mgr, pubsubreporter.NewReporter(cfg), o.pubsubWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct pubsub reporter controller")
 		}
 	}
@@ -256,26 +331,43 @@ func main() {
 		if err != nil {
 			logrus.WithError(err).Fatal("Error getting GitHub client.")
 		}
+		health.AddReadinessChecks(pjutil.GitHubHealthCheck(githubClient))
 
 		hasReporter = true
 		githubReporter := githubreporter.NewReporter(githubClient, cfg, prowapi.ProwJobAgent(o.reportAgent), mgr.GetCache())
-		if err := crier.New(mgr, githubReporter, o.githubWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		if err := crier.New(mgr, githubReporter, o.githubWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct github reporter controller")
 		}
 	}
 
-	var opener io.Opener
-	if o.blobStorageWorkers+o.k8sBlobStorageWorkers+o.resultStoreWorkers > 0 {
-		opener, err = o.storage.StorageClient(context.Background())
+	if o.gitlabWorkers > 0 {
+		gitlabClient, err := o.gitlab.GitLabClient(o.dryrun)
 		if err != nil {
-			logrus.WithError(err).Fatal("Error creating opener")
+			logrus.WithError(err).Fatal("Error getting GitLab client.")
+		}
+
+		hasReporter = true
+		if err := crier.New(mgr, gitlabreporter.NewReporter(gitlabClient), o.gitlabWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct gitlab reporter controller")
+		}
+	}
+
+	if o.bitbucketWorkers > 0 {
+		bitbucketClient, err := o.bitbucket.BitbucketClient(o.dryrun)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error getting Bitbucket client.")
+		}
+
+		hasReporter = true
+		if err := crier.New(mgr, bitbucketreporter.NewReporter(bitbucketClient), o.bitbucketWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
+			logrus.WithError(err).Fatal("failed to construct bitbucket reporter controller")
 		}
 	}
 
 	if o.blobStorageWorkers > 0 || o.k8sBlobStorageWorkers > 0 {
 		hasReporter = true
 		if o.blobStorageWorkers > 0 {
-			if err := crier.New(mgr, gcsreporter.New(cfg, opener, o.dryrun), o.blobStorageWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+			if err := crier.New(mgr, gcsreporter.New(cfg, opener, o.dryrun), o.blobStorageWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
 				logrus.WithError(err).Fatal("failed to construct gcsreporter controller")
 			}
 		}
@@ -287,7 +379,7 @@ func main() {
 			}
 
 			k8sGcsReporter := k8sgcsreporter.New(cfg, opener, k8sgcsreporter.NewK8sResourceGetter(coreClients), float32(o.k8sReportFraction), o.dryrun)
-			if err := crier.New(mgr, k8sGcsReporter, o.k8sBlobStorageWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+			if err := crier.New(mgr, k8sGcsReporter, o.k8sBlobStorageWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
 				logrus.WithError(err).Fatal("failed to construct k8sgcsreporter controller")
 			}
 		}
@@ -300,7 +392,7 @@ func main() {
 			logrus.WithError(err).Fatal("Error connecting to resultstore")
 		}
 		uploader := resultstore.NewUploader(resultstore.NewClient(conn))
-		if err := crier.New(mgr, resultstorereporter.New(cfg, opener, uploader, o.resultstoreArtifactsDirOnly), o.resultStoreWorkers, o.githubEnablement.EnablementChecker()); err != nil {
+		if err := crier.New(mgr, resultstorereporter.New(cfg, opener, uploader, o.resultstoreArtifactsDirOnly), o.resultStoreWorkers, o.githubEnablement.EnablementChecker(), crierOpts...); err != nil {
 			logrus.WithError(err).Fatal("failed to construct resultstorereporter controller")
 		}
 	}
@@ -311,6 +403,7 @@ func main() {
 
 	// Push metrics to the configured prometheus pushgateway endpoint or serve them
 	metrics.ExposeMetrics("crier", cfg().PushGateway, o.instrumentationOptions.MetricsPort)
+	health.ServeReady()
 
 	interrupts.Run(func(ctx context.Context) {
 		if err := mgr.Start(ctx); err != nil {
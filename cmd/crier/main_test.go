@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	"sigs.k8s.io/prow/pkg/crier"
 	"sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 )
@@ -31,6 +32,8 @@ func TestOptions(t *testing.T) {
 
 	var defaultGitHubOptions flagutil.GitHubOptions
 	defaultGitHubOptions.AddFlags(flag.NewFlagSet("", flag.ContinueOnError))
+	var defaultGerritOptions flagutil.GerritOptions
+	defaultGerritOptions.AddFlags(flag.NewFlagSet("", flag.ContinueOnError))
 
 	cases := []struct {
 		name     string
@@ -61,7 +64,9 @@ func TestOptions(t *testing.T) {
 					InRepoConfigCacheSize:                 200,
 				},
 				github:                 defaultGitHubOptions,
+				gerrit:                 defaultGerritOptions,
 				k8sReportFraction:      1.0,
+				maxReportAttempts:      crier.DefaultMaxReportAttempts,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
@@ -78,7 +83,9 @@ func TestOptions(t *testing.T) {
 					InRepoConfigCacheSize:                 200,
 				},
 				github:                 defaultGitHubOptions,
+				gerrit:                 defaultGerritOptions,
 				k8sReportFraction:      1.0,
+				maxReportAttempts:      crier.DefaultMaxReportAttempts,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
@@ -96,7 +103,9 @@ func TestOptions(t *testing.T) {
 				},
 				pubsubWorkers:          7,
 				github:                 defaultGitHubOptions,
+				gerrit:                 defaultGerritOptions,
 				k8sReportFraction:      1.0,
+				maxReportAttempts:      crier.DefaultMaxReportAttempts,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
@@ -119,7 +128,9 @@ func TestOptions(t *testing.T) {
 					InRepoConfigCacheSize:                 200,
 				},
 				github:                 defaultGitHubOptions,
+				gerrit:                 defaultGerritOptions,
 				k8sReportFraction:      1.0,
+				maxReportAttempts:      crier.DefaultMaxReportAttempts,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
@@ -142,7 +153,9 @@ func TestOptions(t *testing.T) {
 				},
 				dryrun:                 true,
 				github:                 defaultGitHubOptions,
+				gerrit:                 defaultGerritOptions,
 				k8sReportFraction:      1.0,
+				maxReportAttempts:      crier.DefaultMaxReportAttempts,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
@@ -159,7 +172,9 @@ func TestOptions(t *testing.T) {
 					InRepoConfigCacheSize:                 200,
 				},
 				github:                 defaultGitHubOptions,
+				gerrit:                 defaultGerritOptions,
 				k8sReportFraction:      1.0,
+				maxReportAttempts:      crier.DefaultMaxReportAttempts,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
@@ -176,7 +191,9 @@ func TestOptions(t *testing.T) {
 					InRepoConfigCacheSize:                 200,
 				},
 				github:                 defaultGitHubOptions,
+				gerrit:                 defaultGerritOptions,
 				k8sReportFraction:      0.5,
+				maxReportAttempts:      crier.DefaultMaxReportAttempts,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
@@ -201,7 +218,9 @@ func TestOptions(t *testing.T) {
 					InRepoConfigCacheSize:                 200,
 				},
 				github:                 defaultGitHubOptions,
+				gerrit:                 defaultGerritOptions,
 				k8sReportFraction:      1.0,
+				maxReportAttempts:      crier.DefaultMaxReportAttempts,
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
 			},
 		},
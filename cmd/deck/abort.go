@@ -80,13 +80,14 @@ func handleAbort(prowJobClient prowv1.ProwJobInterface, cfg authCfgGetter, goa *
 			}
 			pj.Status.State = prowapi.AbortedState
 			pj.Status.Description = abortDescription
+			pj.SetConditions()
 			jsonPJ, err := json.Marshal(pj)
 			if err != nil {
 				http.Error(w, fmt.Sprintf("Error marshal source job: %v.", err), http.StatusInternalServerError)
 				l.WithError(err).Errorf("Error marshal source job.")
 				return
 			}
-			pj, err := prowJobClient.Patch(ctx, pj.Name, ktypes.MergePatchType, jsonPJ, metav1.PatchOptions{})
+			pj, err := prowJobClient.Patch(ctx, pj.Name, ktypes.MergePatchType, jsonPJ, metav1.PatchOptions{}, "status")
 			if err != nil {
 				http.Error(w, fmt.Sprintf("Could not patch aborted job: %v.", err), http.StatusInternalServerError)
 				l.WithError(err).Errorf("Could not patch aborted job.")
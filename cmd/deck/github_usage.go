@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+// githubUsageWindows are the time windows the usage of the GitHub API can be
+// looked at for, the first one being the default.
+var githubUsageWindows = []string{"1h", "6h", "1d", "7d"}
+
+// githubUsageGroupings are the labels the usage of the GitHub API can be
+// grouped by, the first one being the default.
+var githubUsageGroupings = []struct {
+	Name   string
+	Labels []string
+}{
+	{Name: "org", Labels: []string{"org"}},
+	{Name: "repo", Labels: []string{"org", "repo"}},
+	{Name: "component", Labels: []string{"component", "caller"}},
+	{Name: "all", Labels: []string{"component", "caller", "org", "repo"}},
+}
+
+// githubUsageQuerier queries the usage of the GitHub API that the GitHub
+// clients of all components exported from Prometheus.
+type githubUsageQuerier interface {
+	Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error)
+}
+
+type githubUsageRow struct {
+	Labels   []string
+	Requests float64
+	Points   float64
+	// Share is the percentage of all points consumed in the window.
+	Share float64
+}
+
+type githubUsagePage struct {
+	Window    string
+	Windows   []string
+	Grouping  string
+	Groupings []string
+	Columns   []string
+	Rows      []githubUsageRow
+	Points    float64
+	Error     string
+}
+
+// handleGitHubUsage serves the page that attributes the usage of the GitHub
+// API to orgs, repos and components, to find the ones that exhaust the rate
+// limit of a shared token.
+func handleGitHubUsage(o options, cfg config.Getter, querier githubUsageQuerier, log *logrus.Entry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setHeadersNoCaching(w)
+		page := getGitHubUsage(r.Context(), querier, r.URL.Query().Get("window"), r.URL.Query().Get("by"))
+		if page.Error != "" {
+			log.WithField("url", r.URL.String()).Warn(page.Error)
+		}
+		handleSimpleTemplate(o, cfg, "github-usage.html", page)(w, r)
+	}
+}
+
+func getGitHubUsage(ctx context.Context, querier githubUsageQuerier, window, grouping string) githubUsagePage {
+	page := githubUsagePage{Window: githubUsageWindows[0], Windows: githubUsageWindows, Grouping: githubUsageGroupings[0].Name}
+	for _, w := range githubUsageWindows {
+		if w == window {
+			page.Window = w
+		}
+	}
+	labels := githubUsageGroupings[0].Labels
+	for _, g := range githubUsageGroupings {
+		page.Groupings = append(page.Groupings, g.Name)
+		if g.Name == grouping {
+			page.Grouping, labels = g.Name, g.Labels
+		}
+	}
+	page.Columns = labels
+
+	rows := map[string]*githubUsageRow{}
+	for _, metric := range []string{"github_client_api_rate_limit_points", "github_client_api_requests"} {
+		query := fmt.Sprintf("sum by (%s) (increase(%s[%s]))", strings.Join(labels, ", "), metric, page.Window)
+		value, _, err := querier.Query(ctx, query, time.Now())
+		if err != nil {
+			page.Error = fmt.Sprintf("failed to query the usage of the GitHub API: %v", err)
+			return page
+		}
+		vector, ok := value.(model.Vector)
+		if !ok {
+			page.Error = fmt.Sprintf("unexpected result of type %s for query %q", value.Type(), query)
+			return page
+		}
+		for _, sample := range vector {
+			var values []string
+			for _, label := range labels {
+				values = append(values, string(sample.Metric[model.LabelName(label)]))
+			}
+			key := strings.Join(values, "/")
+			row, ok := rows[key]
+			if !ok {
+				row = &githubUsageRow{Labels: values}
+				rows[key] = row
+			}
+			if metric == "github_client_api_requests" {
+				row.Requests = float64(sample.Value)
+			} else {
+				row.Points = float64(sample.Value)
+				page.Points += row.Points
+			}
+		}
+	}
+
+	for _, row := range rows {
+		if page.Points > 0 {
+			row.Share = 100 * row.Points / page.Points
+		}
+		page.Rows = append(page.Rows, *row)
+	}
+	sort.Slice(page.Rows, func(i, j int) bool {
+		if page.Rows[i].Points != page.Rows[j].Points {
+			return page.Rows[i].Points > page.Rows[j].Points
+		}
+		return strings.Join(page.Rows[i].Labels, "/") < strings.Join(page.Rows[j].Labels, "/")
+	})
+	return page
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+type fakeGitHubUsageQuerier struct {
+	queries []string
+	results map[string]model.Vector
+	err     error
+}
+
+func (f *fakeGitHubUsageQuerier) Query(_ context.Context, query string, _ time.Time, _ ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	f.queries = append(f.queries, query)
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	for metric, result := range f.results {
+		if strings.Contains(query, metric) {
+			return result, nil, nil
+		}
+	}
+	return model.Vector{}, nil, nil
+}
+
+func TestGetGitHubUsage(t *testing.T) {
+	sample := func(org, repo string, value float64) *model.Sample {
+		return &model.Sample{Metric: model.Metric{"org": model.LabelValue(org), "repo": model.LabelValue(repo)}, Value: model.SampleValue(value)}
+	}
+	testcases := []struct {
+		name            string
+		window          string
+		grouping        string
+		err             error
+		expectedQueries []string
+		expected        githubUsagePage
+	}{
+		{
+			name:     "usage by repo",
+			window:   "1d",
+			grouping: "repo",
+			expectedQueries: []string{
+				"sum by (org, repo) (increase(github_client_api_rate_limit_points[1d]))",
+				"sum by (org, repo) (increase(github_client_api_requests[1d]))",
+			},
+			expected: githubUsagePage{
+				Window:    "1d",
+				Windows:   githubUsageWindows,
+				Grouping:  "repo",
+				Groupings: []string{"org", "repo", "component", "all"},
+				Columns:   []string{"org", "repo"},
+				Rows: []githubUsageRow{
+					{Labels: []string{"kubernetes", "test-infra"}, Requests: 400, Points: 300, Share: 75},
+					{Labels: []string{"kubernetes", "kubernetes"}, Requests: 100, Points: 100, Share: 25},
+				},
+				Points: 400,
+			},
+		},
+		{
+			name:     "invalid window and grouping fall back to the defaults",
+			window:   "1y",
+			grouping: "user",
+			err:      errors.New("unreachable"),
+			expectedQueries: []string{
+				"sum by (org) (increase(github_client_api_rate_limit_points[1h]))",
+			},
+			expected: githubUsagePage{
+				Window:    "1h",
+				Windows:   githubUsageWindows,
+				Grouping:  "org",
+				Groupings: []string{"org", "repo", "component", "all"},
+				Columns:   []string{"org"},
+				Error:     "failed to query the usage of the GitHub API: unreachable",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			querier := &fakeGitHubUsageQuerier{
+				err: tc.err,
+				results: map[string]model.Vector{
+					"github_client_api_rate_limit_points": {sample("kubernetes", "kubernetes", 100), sample("kubernetes", "test-infra", 300)},
+					"github_client_api_requests":          {sample("kubernetes", "kubernetes", 100), sample("kubernetes", "test-infra", 400)},
+				},
+			}
+			page := getGitHubUsage(context.Background(), querier, tc.window, tc.grouping)
+			if diff := cmp.Diff(tc.expectedQueries, querier.queries); diff != "" {
+				t.Errorf("queries mismatch. Want(-), got(+):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, page); diff != "" {
+				t.Errorf("page mismatch. Want(-), got(+):\n%s", diff)
+			}
+		})
+	}
+}
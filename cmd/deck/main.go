@@ -43,6 +43,8 @@ import (
 	"github.com/NYTimes/gziphandler"
 	"github.com/gorilla/csrf"
 	"github.com/gorilla/sessions"
+	prometheusapi "github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
@@ -64,6 +66,7 @@ import (
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	pluginsflagutil "sigs.k8s.io/prow/pkg/flagutil/plugins"
+	"sigs.k8s.io/prow/pkg/flakiness"
 	"sigs.k8s.io/prow/pkg/git/v2"
 	prowgithub "sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/githuboauth"
@@ -72,9 +75,11 @@ import (
 	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/metrics"
+	"sigs.k8s.io/prow/pkg/moonraker"
 	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pluginhelp"
 	"sigs.k8s.io/prow/pkg/plugins"
+	"sigs.k8s.io/prow/pkg/prowjobcache"
 	"sigs.k8s.io/prow/pkg/prstatus"
 	"sigs.k8s.io/prow/pkg/simplifypath"
 	"sigs.k8s.io/prow/pkg/spyglass"
@@ -138,6 +143,16 @@ type options struct {
 	controllerManager     prowflagutil.ControllerManagerOptions
 	dryRun                bool
 	tenantIDs             prowflagutil.Strings
+
+	// storageOnly serves Deck from object storage, without access to the
+	// cluster or GitHub.
+	storageOnly         bool
+	prowJobSnapshotPath string
+	tideHistoryPath     string
+
+	// githubUsagePrometheusURL is the Prometheus the usage of the GitHub API
+	// is queried from
+	githubUsagePrometheusURL string
 }
 
 func (o *options) Validate() error {
@@ -159,6 +174,17 @@ func (o *options) Validate() error {
 	if (o.hiddenOnly && o.showHidden) || (o.tenantIDs.Strings() != nil && (o.hiddenOnly || o.showHidden)) {
 		return errors.New("'--hidden-only', '--tenant-id', and '--show-hidden' are mutually exclusive, 'hidden-only' shows only hidden job, '--tenant-id' shows all jobs with matching ID and 'show-hidden' shows both hidden and non-hidden jobs")
 	}
+
+	if o.storageOnly {
+		if o.prowJobSnapshotPath == "" {
+			return errors.New("--prowjob-snapshot-path is required with --storage-only")
+		}
+		if o.pregeneratedData != "" || o.rerunCreatesJob || o.oauthURL != "" || o.tideURL != "" {
+			return errors.New("--storage-only is mutually exclusive with --pregenerated-data, --rerun-creates-job, --oauth-url and --tide-url")
+		}
+	} else if o.prowJobSnapshotPath != "" || o.tideHistoryPath != "" {
+		return errors.New("--prowjob-snapshot-path and --tide-history-path require --storage-only")
+	}
 	return nil
 }
 
@@ -166,6 +192,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	var o options
 	fs.StringVar(&o.tideURL, "tide-url", "", "Path to tide. If empty, do not serve tide data.")
 	fs.StringVar(&o.hookURL, "hook-url", "", "Path to hook plugin help endpoint.")
+	fs.StringVar(&o.githubUsagePrometheusURL, "github-usage-prometheus-url", "", "URL of the Prometheus that scrapes the Prow components, to show which orgs, repos and components consume the rate limit of the GitHub API. If empty, do not serve the GitHub API usage page.")
 	fs.StringVar(&o.oauthURL, "oauth-url", "", "Path to deck user dashboard endpoint.")
 	fs.StringVar(&o.githubOAuthConfigFile, "github-oauth-config-file", "/etc/github/secret", "Path to the file containing the GitHub App Client secret.")
 	fs.StringVar(&o.cookieSecretFile, "cookie-secret", "", "Path to the file containing the cookie secret key.")
@@ -184,6 +211,9 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.BoolVar(&o.allowInsecure, "allow-insecure", false, "Allows insecure requests for CSRF and GitHub oauth.")
 	fs.BoolVar(&o.dryRun, "dry-run", false, "Whether or not to make mutating API calls to GitHub.")
 	fs.Var(&o.tenantIDs, "tenant-id", "The tenantID(s) used by the ProwJobs that should be displayed by this instance of Deck. This flag can be repeated.")
+	fs.BoolVar(&o.storageOnly, "storage-only", false, "Serve job history, spyglass and Tide history only from object storage, without access to the cluster or GitHub. Useful for public read-only mirrors.")
+	fs.StringVar(&o.prowJobSnapshotPath, "prowjob-snapshot-path", "", "Storage path of the ProwJob snapshot written by sinker, required with --storage-only.")
+	fs.StringVar(&o.tideHistoryPath, "tide-history-path", "", "Storage path of the Tide history (the --history-uri of Tide) to serve with --storage-only. If empty, do not serve Tide history.")
 	o.config.AddFlags(fs)
 	o.instrumentation.AddFlags(fs)
 	o.controllerManager.TimeoutListingProwJobsDefault = 30 * time.Second
@@ -225,6 +255,7 @@ var simplifier = simplifypath.NewSimplifier(l("", // shadow element mimicing the
 	l("favicon.ico"),
 	l("github-login",
 		l("redirect")),
+	l("github-usage"),
 	l("github-link"),
 	l("git-provider-link"),
 	l("job-history",
@@ -303,6 +334,7 @@ func main() {
 	// this needs to be in a separate port as we don't start the
 	// main server with the main mux until we're ready
 	health := pjutil.NewHealthOnPort(o.instrumentation.HealthPort)
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(cfg))
 
 	mux := http.NewServeMux()
 	// setup common handlers for local and deployed runs
@@ -318,6 +350,13 @@ func main() {
 	mux.Handle("/tide", gziphandler.GzipHandler(handleSimpleTemplate(o, cfg, "tide.html", nil)))
 	mux.Handle("/tide-history", gziphandler.GzipHandler(handleSimpleTemplate(o, cfg, "tide-history.html", nil)))
 	mux.Handle("/plugins", gziphandler.GzipHandler(handleSimpleTemplate(o, cfg, "plugins.html", nil)))
+	if o.githubUsagePrometheusURL != "" {
+		prometheusClient, err := prometheusapi.NewClient(prometheusapi.Config{Address: o.githubUsagePrometheusURL})
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating the Prometheus client.")
+		}
+		mux.Handle("/github-usage", gziphandler.GzipHandler(handleGitHubUsage(o, cfg, prometheusv1.NewAPI(prometheusClient), logrus.WithField("handler", "/github-usage"))))
+	}
 
 	runLocal := o.pregeneratedData != ""
 
@@ -325,6 +364,7 @@ func main() {
 	var pjListingClient jobs.PJListingClient
 	var githubClient deckGitHubClient
 	var gitClient git.ClientFactory
+	var ircg config.InRepoConfigGetter
 	var podLogClients map[string]jobs.PodLogClient
 	if runLocal {
 		localDataHandler := staticHandlerFromDir(o.pregeneratedData)
@@ -342,6 +382,14 @@ func main() {
 		}
 		fjc.pjs = &pjs
 		pjListingClient = &fjc
+	} else if o.storageOnly {
+		fallbackHandler = http.NotFound
+
+		opener, err := o.storage.StorageClient(context.Background())
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating opener for ProwJob snapshots.")
+		}
+		pjListingClient = &storagePJListingClient{opener: opener, path: o.prowJobSnapshotPath}
 	} else {
 		fallbackHandler = http.NotFound
 
@@ -357,9 +405,9 @@ func main() {
 		if err != nil {
 			logrus.WithError(err).Fatal("Error getting manager.")
 		}
-		// Force a cache for ProwJobs
-		if _, err := mgr.GetCache().GetInformer(interrupts.Context(), &prowapi.ProwJob{}); err != nil {
-			logrus.WithError(err).Fatal("Failed to get prowjob informer")
+		pjCache, err := prowjobcache.New(interrupts.Context(), mgr, cfg().ProwJobNamespace)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create prowjob cache")
 		}
 		go func() {
 			if err := mgr.Start(interrupts.Context()); err != nil {
@@ -383,7 +431,7 @@ func main() {
 			logrus.WithError(err).Fatal("Failed to register kubeconfig change callback")
 		}
 
-		pjListingClient = &pjListingClientWrapper{mgr.GetClient()}
+		pjListingClient = &pjListingClientWrapper{pjCache}
 
 		// We use the GH client to resolve GH teams when determining who is permitted to rerun a job.
 		// When inrepoconfig is enabled, both the GitHubClient and the gitClient are used to resolve
@@ -397,6 +445,12 @@ func main() {
 			if err != nil {
 				logrus.WithError(err).Fatal("Error getting Git client.")
 			}
+			if o.config.MoonrakerEnabled() {
+				ircg, err = moonraker.NewInRepoConfigGetter(o.config.MoonrakerAddress, o.config.MoonrakerGRPCAddress, configAgent)
+				if err != nil {
+					logrus.WithError(err).Fatal("Error getting Moonraker client.")
+				}
+			}
 		} else {
 			if len(cfg().InRepoConfig.Enabled) > 0 {
 				logrus.Info(" --github-token-path not configured. InRepoConfigEnabled, but current configuration won't display full PR history")
@@ -443,11 +497,13 @@ func main() {
 	mux.Handle("/log", gziphandler.GzipHandler(handleLog(ja, logrus.WithField("handler", "/log"))))
 
 	if o.spyglass {
-		initSpyglass(cfg, o, mux, ja, githubClient, gitClient)
+		initSpyglass(cfg, o, mux, ja, githubClient, gitClient, ircg)
 	}
 
 	if runLocal {
 		mux = localOnlyMain(cfg, o, mux)
+	} else if o.storageOnly {
+		mux = storageOnlyMain(cfg, o, mux)
 	} else {
 		mux = prodOnlyMain(cfg, pluginAgent, authCfgGetter, githubClient, o, mux)
 	}
@@ -524,14 +580,19 @@ func (c *podLogClient) GetLogs(name, container string) ([]byte, error) {
 }
 
 type pjListingClientWrapper struct {
-	reader ctrlruntimeclient.Reader
+	cache *prowjobcache.Cache
 }
 
 func (w *pjListingClientWrapper) List(
 	ctx context.Context,
 	pjl *prowapi.ProwJobList,
 	opts ...ctrlruntimeclient.ListOption) error {
-	return w.reader.List(ctx, pjl, opts...)
+	pjs, err := w.cache.List(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	pjl.Items = pjs
+	return nil
 }
 
 // fakePjListingClientWrapper implements pjListingClient for runlocal
@@ -544,6 +605,59 @@ func (fjc *fakePjListingClientWrapper) List(ctx context.Context, pjl *prowapi.Pr
 	return nil
 }
 
+// storagePJListingClient implements pjListingClient by reading the ProwJob
+// snapshot sinker writes to storage.
+type storagePJListingClient struct {
+	opener io.Opener
+	path   string
+}
+
+func (c *storagePJListingClient) List(ctx context.Context, pjl *prowapi.ProwJobList, _ ...ctrlruntimeclient.ListOption) error {
+	pjs, err := pjutil.ReadProwJobSnapshot(ctx, c.opener, c.path)
+	if err != nil {
+		return err
+	}
+	*pjl = *pjs
+	return nil
+}
+
+// storageOnlyMain contains logic only used in storage-only mode, and is
+// mutually exclusive with prodOnlyMain. Nothing that requires access to the
+// cluster or GitHub is served.
+func storageOnlyMain(cfg config.Getter, o options, mux *http.ServeMux) *http.ServeMux {
+	if o.tideHistoryPath != "" {
+		opener, err := o.storage.StorageClient(context.Background())
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating opener for Tide history.")
+		}
+		ta := &tideAgent{
+			log:         logrus.WithField("agent", "tide"),
+			historyPath: o.tideHistoryPath,
+			opener:      opener,
+			updatePeriod: func() time.Duration {
+				return cfg().Deck.TideUpdatePeriod.Duration
+			},
+			hiddenRepos: func() []string {
+				return cfg().Deck.HiddenRepos
+			},
+			hiddenOnly: o.hiddenOnly,
+			showHidden: o.showHidden,
+			tenantIDs:  sets.New[string](o.tenantIDs.Strings()...),
+			cfg:        cfg,
+		}
+		go func() {
+			ta.start()
+			mux.Handle("/tide-history.js", gziphandler.GzipHandler(handleTideHistory(ta, logrus.WithField("handler", "/tide-history.js"))))
+		}()
+	}
+
+	secure := !o.allowInsecure
+	mux.HandleFunc("/github-link", HandleGitHubLink(o.github.HostForOrg, secure))
+	mux.HandleFunc("/git-provider-link", HandleGitProviderLink(o.github.HostForOrg, secure))
+
+	return mux
+}
+
 // prodOnlyMain contains logic only used when running deployed, not locally
 func prodOnlyMain(cfg config.Getter, pluginAgent *plugins.ConfigAgent, authCfgGetter authCfgGetter, githubClient deckGitHubClient, o options, mux *http.ServeMux) *http.ServeMux {
 	prowJobClient, err := o.kubernetes.ProwJobClient(cfg().ProwJobNamespace, false)
@@ -585,8 +699,8 @@ func prodOnlyMain(cfg config.Getter, pluginAgent *plugins.ConfigAgent, authCfgGe
 	secure := !o.allowInsecure
 
 	// Handles link to github
-	mux.HandleFunc("/github-link", HandleGitHubLink(o.github.Host, secure))
-	mux.HandleFunc("/git-provider-link", HandleGitProviderLink(o.github.Host, secure))
+	mux.HandleFunc("/github-link", HandleGitHubLink(o.github.HostForOrg, secure))
+	mux.HandleFunc("/git-provider-link", HandleGitProviderLink(o.github.HostForOrg, secure))
 
 	// Enable Git OAuth feature if oauthURL is provided.
 	var goa *githuboauth.Agent
@@ -675,7 +789,7 @@ func prodOnlyMain(cfg config.Getter, pluginAgent *plugins.ConfigAgent, authCfgGe
 	return mux
 }
 
-func initSpyglass(cfg config.Getter, o options, mux *http.ServeMux, ja *jobs.JobAgent, gitHubClient deckGitHubClient, gitClient git.ClientFactory) {
+func initSpyglass(cfg config.Getter, o options, mux *http.ServeMux, ja *jobs.JobAgent, gitHubClient deckGitHubClient, gitClient git.ClientFactory, ircg config.InRepoConfigGetter) {
 	ctx := context.TODO()
 	opener, err := io.NewOpener(ctx, o.storage.GCSCredentialsFile, o.storage.S3CredentialsFile)
 	if err != nil {
@@ -688,7 +802,8 @@ func initSpyglass(cfg config.Getter, o options, mux *http.ServeMux, ja *jobs.Job
 	mux.Handle("/spyglass/lens/", gziphandler.GzipHandler(http.StripPrefix("/spyglass/lens/", handleArtifactView(o, sg, cfg))))
 	mux.Handle("/view/", gziphandler.GzipHandler(handleRequestJobViews(sg, cfg, o, logrus.WithField("handler", "/view"))))
 	mux.Handle("/job-history/", gziphandler.GzipHandler(handleJobHistory(o, cfg, opener, logrus.WithField("handler", "/job-history"))))
-	mux.Handle("/pr-history/", gziphandler.GzipHandler(handlePRHistory(o, cfg, opener, gitHubClient, gitClient, logrus.WithField("handler", "/pr-history"))))
+	mux.Handle("/pr-history/", gziphandler.GzipHandler(handlePRHistory(o, cfg, opener, gitHubClient, gitClient, ircg, logrus.WithField("handler", "/pr-history"))))
+	mux.Handle("/flakiness.js", gziphandler.GzipHandler(handleFlakiness(flakiness.NewReportCache(opener, cfg), logrus.WithField("handler", "/flakiness.js"))))
 	if err := initLocalLensHandler(cfg, o, sg); err != nil {
 		logrus.WithError(err).Fatal("Failed to initialize local lens handler")
 	}
@@ -901,10 +1016,10 @@ func handleJobHistory(o options, cfg config.Getter, opener io.Opener, log *logru
 // The url must look like this:
 //
 // /pr-history?org=<org>&repo=<repo>&pr=<pr number>
-func handlePRHistory(o options, cfg config.Getter, opener io.Opener, gitHubClient deckGitHubClient, gitClient git.ClientFactory, log *logrus.Entry) http.HandlerFunc {
+func handlePRHistory(o options, cfg config.Getter, opener io.Opener, gitHubClient deckGitHubClient, gitClient git.ClientFactory, ircg config.InRepoConfigGetter, log *logrus.Entry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		setHeadersNoCaching(w)
-		tmpl, err := getPRHistory(r.Context(), r.URL, cfg(), opener, gitHubClient, gitClient, o.github.Host)
+		tmpl, err := getPRHistory(r.Context(), r.URL, cfg(), opener, gitHubClient, gitClient, ircg, o.github.Host)
 		if err != nil {
 			msg := fmt.Sprintf("failed to get PR history: %v", err)
 			log.WithField("url", r.URL.String()).Info(msg)
@@ -920,6 +1035,29 @@ func handlePRHistory(o options, cfg config.Getter, opener io.Opener, gitHubClien
 	}
 }
 
+// handleFlakiness serves the flakiness report written by the flakiness
+// controller of prow-controller-manager. The jobs can be filtered by the org,
+// repo and job query parameters:
+//
+// /flakiness.js?org=<org>&repo=<repo>&job=<job name>
+func handleFlakiness(reports *flakiness.ReportCache, log *logrus.Entry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		setHeadersNoCaching(w)
+		report := reports.Report(r.Context())
+		if report == nil {
+			http.Error(w, "no flakiness report is available", http.StatusNotFound)
+			return
+		}
+		query := r.URL.Query()
+		b, err := json.Marshal(report.Filter(query.Get("org"), query.Get("repo"), query.Get("job")))
+		if err != nil {
+			log.WithError(err).Error("Error marshaling flakiness report.")
+			b = []byte("{}")
+		}
+		writeJSONResponse(w, r, b)
+	}
+}
+
 // handleRequestJobViews handles requests to get all available artifact views for a given job.
 // The url must specify a storage key type, such as "prowjob" or "gcs":
 //
@@ -1489,19 +1627,23 @@ func handleFavicon(staticFilesLocation string, cfg config.Getter) http.HandlerFu
 	}
 }
 
-func HandleGitHubLink(githubHost string, secure bool) http.HandlerFunc {
+// HandleGitHubLink redirects to a destination on the GitHub instance of the
+// org the destination starts with.
+func HandleGitHubLink(githubHost func(org string) string, secure bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		scheme := "http"
 		if secure {
 			scheme = "https"
 		}
-		redirectURL := scheme + "://" + githubHost + "/" + r.URL.Query().Get("dest")
+		dest := r.URL.Query().Get("dest")
+		org, _, _ := strings.Cut(dest, "/")
+		redirectURL := scheme + "://" + githubHost(org) + "/" + dest
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 	}
 }
 
 // HandleGenericProviderLink returns link based on different providers.
-func HandleGitProviderLink(githubHost string, secure bool) http.HandlerFunc {
+func HandleGitProviderLink(githubHost func(org string) string, secure bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var redirectURL string
 
@@ -1538,7 +1680,8 @@ func HandleGitProviderLink(githubHost string, secure bool) http.HandlerFunc {
 			if secure {
 				scheme = "https"
 			}
-			prefix := scheme + "://" + githubHost + "/"
+			org, _, _ := strings.Cut(repo, "/")
+			prefix := scheme + "://" + githubHost(org) + "/"
 			switch target {
 			case "commit":
 				redirectURL = prefix + repo + "/commit/" + commit
@@ -29,6 +29,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
@@ -51,6 +52,9 @@ import (
 	"sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	pluginsflagutil "sigs.k8s.io/prow/pkg/flagutil/plugins"
+	"sigs.k8s.io/prow/pkg/flakiness"
+	pkgio "sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pluginhelp"
 	"sigs.k8s.io/prow/pkg/plugins"
 	_ "sigs.k8s.io/prow/pkg/spyglass/lenses/buildlog"
@@ -174,6 +178,54 @@ func TestOptions_Validate(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "storage-only ok",
+			input: options{
+				config: configflagutil.ConfigOptions{ConfigPath: "test"},
+				controllerManager: flagutil.ControllerManagerOptions{
+					TimeoutListingProwJobsDefault: 30 * time.Second,
+				},
+				storageOnly:         true,
+				prowJobSnapshotPath: "gs://bucket/prowjobs.json",
+				tideHistoryPath:     "gs://bucket/tide-history.json",
+			},
+			expectedErr: false,
+		},
+		{
+			name: "storage-only requires a ProwJob snapshot",
+			input: options{
+				config: configflagutil.ConfigOptions{ConfigPath: "test"},
+				controllerManager: flagutil.ControllerManagerOptions{
+					TimeoutListingProwJobsDefault: 30 * time.Second,
+				},
+				storageOnly: true,
+			},
+			expectedErr: true,
+		},
+		{
+			name: "storage-only cannot rerun jobs",
+			input: options{
+				config: configflagutil.ConfigOptions{ConfigPath: "test"},
+				controllerManager: flagutil.ControllerManagerOptions{
+					TimeoutListingProwJobsDefault: 30 * time.Second,
+				},
+				storageOnly:         true,
+				prowJobSnapshotPath: "gs://bucket/prowjobs.json",
+				rerunCreatesJob:     true,
+			},
+			expectedErr: true,
+		},
+		{
+			name: "ProwJob snapshot requires storage-only",
+			input: options{
+				config: configflagutil.ConfigOptions{ConfigPath: "test"},
+				controllerManager: flagutil.ControllerManagerOptions{
+					TimeoutListingProwJobsDefault: 30 * time.Second,
+				},
+				prowJobSnapshotPath: "gs://bucket/prowjobs.json",
+			},
+			expectedErr: true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -564,6 +616,62 @@ func TestTideHistory(t *testing.T) {
 	}
 }
 
+func TestStorageOnly(t *testing.T) {
+	ctx := context.Background()
+	opener, err := pkgio.NewOpener(ctx, "", "")
+	if err != nil {
+		t.Fatalf("Creating opener: %v", err)
+	}
+	dir := t.TempDir()
+
+	testHist := map[string][]history.Record{
+		"o/r:b": {
+			{Action: "MERGE"}, {Action: "TRIGGER"},
+		},
+	}
+	b, err := json.Marshal(testHist)
+	if err != nil {
+		t.Fatalf("Marshaling: %v", err)
+	}
+	historyPath := filepath.Join(dir, "history.json")
+	if err := os.WriteFile(historyPath, b, 0644); err != nil {
+		t.Fatalf("Writing history: %v", err)
+	}
+	ta := tideAgent{
+		log:         logrus.WithField("agent", "tide"),
+		historyPath: historyPath,
+		opener:      opener,
+		hiddenRepos: func() []string {
+			return []string{}
+		},
+		updatePeriod: func() time.Duration { return time.Minute },
+		cfg:          func() *config.Config { return &config.Config{} },
+	}
+	if err := ta.updateHistory(); err != nil {
+		t.Fatalf("Updating: %v", err)
+	}
+	if !reflect.DeepEqual(ta.history, testHist) {
+		t.Errorf("Expected tideAgent history:\n%#v\n,but got:\n%#v\n", testHist, ta.history)
+	}
+
+	pjs := &prowapi.ProwJobList{Items: []prowapi.ProwJob{{
+		ObjectMeta: metav1.ObjectMeta{Name: "job"},
+		Spec:       prowapi.ProwJobSpec{Job: "job"},
+	}}}
+	snapshotPath := filepath.Join(dir, "prowjobs.json")
+	if err := pjutil.WriteProwJobSnapshot(ctx, opener, snapshotPath, pjs); err != nil {
+		t.Fatalf("Writing snapshot: %v", err)
+	}
+	var listed prowapi.ProwJobList
+	lister := &storagePJListingClient{opener: opener, path: snapshotPath}
+	if err := lister.List(ctx, &listed); err != nil {
+		t.Fatalf("Listing: %v", err)
+	}
+	if diff := cmp.Diff(pjs, &listed); diff != "" {
+		t.Errorf("Listed ProwJobs differ from snapshot: %s", diff)
+	}
+}
+
 func TestHelp(t *testing.T) {
 	hitCount := 0
 	help := pluginhelp.Help{
@@ -1050,25 +1158,33 @@ func TestSpyglassConfigDefaulting(t *testing.T) {
 	}
 }
 
-func TestHandleGitHubLink(t *testing.T) {
-	ghoptions := flagutil.GitHubOptions{Host: "github.mycompany.com"}
-	org, repo := "org", "repo"
-	handler := HandleGitHubLink(ghoptions.Host, true)
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/github-link?dest=%s/%s", org, repo), nil)
-	if err != nil {
-		t.Fatalf("Error making request: %v", err)
+// githubHost routes the enterprise-org org to another GitHub instance.
+func githubHost(org string) string {
+	if org == "enterprise-org" {
+		return "github.enterprise.com"
 	}
-	rr := httptest.NewRecorder()
-	handler.ServeHTTP(rr, req)
-	if rr.Code != http.StatusFound {
-		t.Fatalf("Bad error code: %d", rr.Code)
-	}
-	resp := rr.Result()
-	defer resp.Body.Close()
-	actual := resp.Header.Get("Location")
-	expected := fmt.Sprintf("https://%s/%s/%s", ghoptions.Host, org, repo)
-	if expected != actual {
-		t.Fatalf("%v", actual)
+	return "github.mycompany.com"
+}
+
+func TestHandleGitHubLink(t *testing.T) {
+	handler := HandleGitHubLink(githubHost, true)
+	for org, host := range map[string]string{"org": "github.mycompany.com", "enterprise-org": "github.enterprise.com"} {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/github-link?dest=%s/repo", org), nil)
+		if err != nil {
+			t.Fatalf("Error making request: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusFound {
+			t.Fatalf("Bad error code: %d", rr.Code)
+		}
+		resp := rr.Result()
+		defer resp.Body.Close()
+		actual := resp.Header.Get("Location")
+		expected := fmt.Sprintf("https://%s/%s/repo", host, org)
+		if expected != actual {
+			t.Fatalf("%v", actual)
+		}
 	}
 }
 
@@ -1098,6 +1214,11 @@ func TestHandleGitProviderLink(t *testing.T) {
 			query: "target=pr&repo='bar'&number=2",
 			want:  "https://github.mycompany.com/bar/pull/2",
 		},
+		{
+			name:  "github-pr-of-org-on-another-instance",
+			query: "target=pr&repo=enterprise-org/bar&number=2",
+			want:  "https://github.enterprise.com/enterprise-org/bar/pull/2",
+		},
 		{
 			name:  "github-author",
 			query: "target=author&author=chaodaiG",
@@ -1140,8 +1261,6 @@ func TestHandleGitProviderLink(t *testing.T) {
 		},
 	}
 
-	ghoptions := flagutil.GitHubOptions{Host: "github.mycompany.com"}
-
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			url := fmt.Sprintf("/git-provider-link?%s", tc.query)
@@ -1150,7 +1269,7 @@ func TestHandleGitProviderLink(t *testing.T) {
 				t.Fatalf("Error making request: %v", err)
 			}
 
-			handler := HandleGitProviderLink(ghoptions.Host, true)
+			handler := HandleGitProviderLink(githubHost, true)
 			rr := httptest.NewRecorder()
 			handler.ServeHTTP(rr, req)
 			if rr.Code != http.StatusFound {
@@ -1254,3 +1373,43 @@ func TestPRHistLink(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleFlakiness(t *testing.T) {
+	ctx := context.Background()
+	opener, err := pkgio.NewOpener(ctx, "", "")
+	if err != nil {
+		t.Fatalf("failed to create opener: %v", err)
+	}
+	cfg := &config.Config{ProwConfig: config.ProwConfig{Flakiness: config.Flakiness{ReportPath: filepath.Join(t.TempDir(), "flakiness.json")}}}
+	handler := handleFlakiness(flakiness.NewReportCache(opener, func() *config.Config { return cfg }), logrus.WithField("handler", "/flakiness.js"))
+
+	req := httptest.NewRequest(http.MethodGet, "/flakiness.js", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected %d without a report, got %d", http.StatusNotFound, rr.Code)
+	}
+
+	report := &flakiness.Report{Jobs: []flakiness.JobFlakiness{
+		{Org: "org", Repo: "repo", Job: "pull-unit", Runs: 4, FlakyRuns: 1, FlakeRate: 0.25},
+		{Org: "org", Repo: "other", Job: "pull-unit", Runs: 2},
+	}}
+	if err := flakiness.WriteReport(ctx, opener, cfg.Flakiness.ReportPath, report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	// A new cache, as the old one only reads the report again after a minute.
+	handler = handleFlakiness(flakiness.NewReportCache(opener, func() *config.Config { return cfg }), logrus.WithField("handler", "/flakiness.js"))
+	req = httptest.NewRequest(http.MethodGet, "/flakiness.js?org=org&repo=repo", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	actual := &flakiness.Report{}
+	if err := json.Unmarshal(rr.Body.Bytes(), actual); err != nil {
+		t.Fatalf("failed to unmarshal report: %v", err)
+	}
+	if diff := cmp.Diff(report.Jobs[:1], actual.Jobs); diff != "" {
+		t.Errorf("unexpected jobs: %s", diff)
+	}
+}
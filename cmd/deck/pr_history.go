@@ -207,8 +207,9 @@ func parsePullURL(u *url.URL) (org, repo string, pr int, err error) {
 	return org, repo, pr, nil
 }
 
-// getStorageDirsForPR returns a map from bucket names -> set of "directories" containing presubmit data
-func getStorageDirsForPR(c *config.Config, gitHubClient deckGitHubClient, gitClient git.ClientFactory, org, repo, cloneURI string, prNumber int) (map[string]sets.Set[string], error) {
+// getStorageDirsForPR returns a map from bucket names -> set of "directories" containing presubmit data.
+// The presubmits are read from Moonraker if ircg is set, and from a clone of the repository otherwise.
+func getStorageDirsForPR(c *config.Config, gitHubClient deckGitHubClient, gitClient git.ClientFactory, ircg config.InRepoConfigGetter, org, repo, cloneURI string, prNumber int) (map[string]sets.Set[string], error) {
 	toSearch := make(map[string]sets.Set[string])
 	fullRepo := org + "/" + repo
 
@@ -217,7 +218,13 @@ func getStorageDirsForPR(c *config.Config, gitHubClient deckGitHubClient, gitCli
 		return nil, nil
 	}
 	prRefGetter := config.NewRefGetterForGitHubPullRequest(gitHubClient, org, repo, prNumber)
-	presubmits, err := c.GetPresubmits(gitClient, org+"/"+repo, "", prRefGetter.BaseSHA, prRefGetter.HeadSHA)
+	var presubmits []config.Presubmit
+	var err error
+	if ircg != nil {
+		presubmits, err = ircg.GetPresubmits(fullRepo, "", prRefGetter.BaseSHA, prRefGetter.HeadSHA)
+	} else {
+		presubmits, err = c.GetPresubmits(gitClient, fullRepo, "", prRefGetter.BaseSHA, prRefGetter.HeadSHA)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Presubmits for pull request %s/%s#%d: %w", org, repo, prNumber, err)
 	}
@@ -264,7 +271,7 @@ func getStorageDirsForPR(c *config.Config, gitHubClient deckGitHubClient, gitCli
 	return toSearch, nil
 }
 
-func getPRHistory(ctx context.Context, prHistoryURL *url.URL, config *config.Config, opener pkgio.Opener, gitHubClient deckGitHubClient, gitClient git.ClientFactory, githubHost string) (prHistoryTemplate, error) {
+func getPRHistory(ctx context.Context, prHistoryURL *url.URL, config *config.Config, opener pkgio.Opener, gitHubClient deckGitHubClient, gitClient git.ClientFactory, ircg config.InRepoConfigGetter, githubHost string) (prHistoryTemplate, error) {
 	start := time.Now()
 	template := prHistoryTemplate{}
 
@@ -280,7 +287,7 @@ func getPRHistory(ctx context.Context, prHistoryURL *url.URL, config *config.Con
 	// TODO(chaodaiG): update once
 	// https://github.com/kubernetes/test-infra/issues/24130 is fixed.
 	cloneURI := ""
-	toSearch, err := getStorageDirsForPR(config, gitHubClient, gitClient, org, repo, cloneURI, pr)
+	toSearch, err := getStorageDirsForPR(config, gitHubClient, gitClient, ircg, org, repo, cloneURI, pr)
 	if err != nil {
 		return template, fmt.Errorf("failed to list directories for PR %s: %w", template.Name, err)
 	}
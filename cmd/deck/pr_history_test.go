@@ -483,7 +483,7 @@ func TestGetGCSDirsForPR(t *testing.T) {
 		gitHubClient.PullRequests = map[int]*github.PullRequest{
 			123: {Number: 123},
 		}
-		toSearch, err := getStorageDirsForPR(tc.config, gitHubClient, nil, tc.org, tc.repo, "", tc.pr)
+		toSearch, err := getStorageDirsForPR(tc.config, gitHubClient, nil, nil, tc.org, tc.repo, "", tc.pr)
 		if (err != nil) != tc.expErr {
 			t.Errorf("%s: unexpected error %v", tc.name, err)
 		}
@@ -646,7 +646,7 @@ func Test_getPRHistory(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			prHistoryURL, _ := url.Parse(tt.args.url)
-			got, err := getPRHistory(context.Background(), prHistoryURL, c, io.NewGCSOpener(fakeGCSClient), nil, nil, "github.com")
+			got, err := getPRHistory(context.Background(), prHistoryURL, c, io.NewGCSOpener(fakeGCSClient), nil, nil, nil, "github.com")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getPRHistory() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -323,7 +323,7 @@ func handleRerun(cfg config.Getter, prowJobClient prowv1.ProwJobInterface, creat
 				rerunDescription = fmt.Sprintf("Successfully reran %v.", name)
 			}
 			newPJ.Status.Description = rerunDescription
-			created, err := prowJobClient.Create(context.TODO(), &newPJ, metav1.CreateOptions{})
+			created, err := pjutil.CreateProwJob(context.TODO(), prowJobClient, &newPJ)
 			if err != nil {
 				l.WithError(err).Error("Error creating job.")
 				http.Error(w, fmt.Sprintf("Error creating job: %v", err), http.StatusInternalServerError)
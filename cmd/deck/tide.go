@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -29,6 +30,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/prow/pkg/config"
+	pkgio "sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/tide"
 	"sigs.k8s.io/prow/pkg/tide/history"
 )
@@ -48,6 +50,11 @@ type tideAgent struct {
 	path         string
 	updatePeriod func() time.Duration
 
+	// historyPath is the storage path the history is read from instead of
+	// from Tide, if set.
+	historyPath string
+	opener      pkgio.Opener
+
 	// Config for hiding repos
 	hiddenRepos func() []string
 	hiddenOnly  bool
@@ -62,24 +69,27 @@ type tideAgent struct {
 }
 
 func (ta *tideAgent) start() {
-	startTimePool := time.Now()
-	if err := ta.updatePools(); err != nil {
-		ta.log.WithError(err).Error("Updating pools the first time.")
+	// Without a path to Tide, only the history can be read from storage.
+	if ta.path != "" {
+		startTimePool := time.Now()
+		if err := ta.updatePools(); err != nil {
+			ta.log.WithError(err).Error("Updating pools the first time.")
+		}
+		go func() {
+			for {
+				time.Sleep(time.Until(startTimePool.Add(ta.updatePeriod())))
+				startTimePool = time.Now()
+				if err := ta.updatePools(); err != nil {
+					ta.log.WithError(err).Error("Updating pools.")
+				}
+			}
+		}()
 	}
 	startTimeHistory := time.Now()
 	if err := ta.updateHistory(); err != nil {
 		ta.log.WithError(err).Error("Updating history the first time.")
 	}
 
-	go func() {
-		for {
-			time.Sleep(time.Until(startTimePool.Add(ta.updatePeriod())))
-			startTimePool = time.Now()
-			if err := ta.updatePools(); err != nil {
-				ta.log.WithError(err).Error("Updating pools.")
-			}
-		}
-	}()
 	go func() {
 		for {
 			time.Sleep(time.Until(startTimeHistory.Add(ta.updatePeriod())))
@@ -146,10 +156,20 @@ func (ta *tideAgent) updatePools() error {
 }
 
 func (ta *tideAgent) updateHistory() error {
-	path := strings.TrimSuffix(ta.path, "/") + "/history"
 	var history map[string][]history.Record
-	if err := fetchTideData(ta.log, path, &history); err != nil {
-		return err
+	if ta.historyPath != "" {
+		content, err := pkgio.ReadContent(context.Background(), ta.log, ta.opener, ta.historyPath)
+		if err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+		if err := json.Unmarshal(content, &history); err != nil {
+			return fmt.Errorf("failed to unmarshal history: %w", err)
+		}
+	} else {
+		path := strings.TrimSuffix(ta.path, "/") + "/history"
+		if err := fetchTideData(ta.log, path, &history); err != nil {
+			return err
+		}
 	}
 	history = ta.filterHistory(history)
 
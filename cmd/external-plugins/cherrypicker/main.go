@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/prow/pkg/interrupts"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 	"sigs.k8s.io/prow/pkg/pluginhelp/externalplugins"
 )
 
@@ -148,6 +149,7 @@ func main() {
 	}
 
 	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
+	pprof.Instrument(o.instrumentationOptions)
 	health.ServeReady()
 
 	mux := http.NewServeMux()
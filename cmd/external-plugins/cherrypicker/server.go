@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -72,12 +73,12 @@ func HelpProvider(_ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
 		Description: `The cherrypick plugin is used for cherrypicking PRs across branches. For every successful cherrypick invocation a new PR is opened against the target branch and assigned to the requestor. If the parent PR contains a release note, it is copied to the cherrypick PR.`,
 	}
 	pluginHelp.AddCommand(pluginhelp.Command{
-		Usage:       "/cherrypick [branch]",
-		Description: "Cherrypick a PR to a different branch. This command works both in merged PRs (the cherrypick PR is opened immediately) and open PRs (the cherrypick PR opens as soon as the original PR merges).",
+		Usage:       "/cherrypick [branch...]",
+		Description: "Cherrypick a PR to one or more different branches. This command works both in merged PRs (the cherrypick PRs are opened immediately) and open PRs (the cherrypick PRs open as soon as the original PR merges). When cherrypicking to multiple branches, a summary linking all cherrypick PRs is posted and a tracking issue is opened if any of them fails to apply.",
 		Featured:    true,
 		// depends on how the cherrypick server runs; needs auth by default (--allow-all=false)
 		WhoCanUse: "Members of the trusted organization for the repo.",
-		Examples:  []string{"/cherrypick release-3.9", "/cherry-pick release-1.15"},
+		Examples:  []string{"/cherrypick release-3.9", "/cherry-pick release-1.15", "/cherrypick release-1.27 release-1.28"},
 	})
 	return pluginHelp, nil
 }
@@ -189,7 +190,10 @@ func (s *Server) handleIssueComment(l *logrus.Entry, ic github.IssueCommentEvent
 	if len(cherryPickMatches) == 0 || len(cherryPickMatches[0]) != 2 {
 		return nil
 	}
-	targetBranch := strings.TrimSpace(cherryPickMatches[0][1])
+	targetBranches := targetBranchesFrom(cherryPickMatches[0][1])
+	if len(targetBranches) == 0 {
+		return nil
+	}
 
 	if ic.Issue.State != "closed" {
 		if !s.allowAll {
@@ -204,7 +208,10 @@ func (s *Server) handleIssueComment(l *logrus.Entry, ic github.IssueCommentEvent
 				return s.ghc.CreateComment(org, repo, num, plugins.FormatICResponse(ic.Comment, resp))
 			}
 		}
-		resp := fmt.Sprintf("once the present PR merges, I will cherry-pick it on top of %s in a new PR and assign it to you.", targetBranch)
+		resp := fmt.Sprintf("once the present PR merges, I will cherry-pick it on top of %s in a new PR and assign it to you.", targetBranches[0])
+		if len(targetBranches) > 1 {
+			resp = fmt.Sprintf("once the present PR merges, I will cherry-pick it on top of %s in new PRs and assign them to you.", strings.Join(targetBranches, ", "))
+		}
 		l.Info(resp)
 		return s.ghc.CreateComment(org, repo, num, plugins.FormatICResponse(ic.Comment, resp))
 	}
@@ -225,10 +232,12 @@ func (s *Server) handleIssueComment(l *logrus.Entry, ic github.IssueCommentEvent
 	}
 
 	// TODO: Use an allowlist for allowed base and target branches.
-	if baseBranch == targetBranch {
-		resp := fmt.Sprintf("base branch (%s) needs to differ from target branch (%s)", baseBranch, targetBranch)
-		l.Info(resp)
-		return s.ghc.CreateComment(org, repo, num, plugins.FormatICResponse(ic.Comment, resp))
+	for _, targetBranch := range targetBranches {
+		if baseBranch == targetBranch {
+			resp := fmt.Sprintf("base branch (%s) needs to differ from target branch (%s)", baseBranch, targetBranch)
+			l.Info(resp)
+			return s.ghc.CreateComment(org, repo, num, plugins.FormatICResponse(ic.Comment, resp))
+		}
 	}
 
 	if !s.allowAll {
@@ -244,12 +253,8 @@ func (s *Server) handleIssueComment(l *logrus.Entry, ic github.IssueCommentEvent
 		}
 	}
 
-	*l = *l.WithFields(logrus.Fields{
-		"requestor":     ic.Comment.User.Login,
-		"target_branch": targetBranch,
-	})
-	l.Debug("Cherrypick request.")
-	return s.handle(l, ic.Comment.User.Login, &ic.Comment, org, repo, targetBranch, baseBranch, title, body, num)
+	*l = *l.WithField("requestor", ic.Comment.User.Login)
+	return s.handleAll(l, ic.Comment.User.Login, &ic.Comment, org, repo, targetBranches, baseBranch, title, body, num)
 }
 
 func (s *Server) handlePullRequest(l *logrus.Entry, pre github.PullRequestEvent) error {
@@ -290,11 +295,12 @@ func (s *Server) handlePullRequest(l *logrus.Entry, pre github.PullRequestEvent)
 		c := comments[i]
 		cherryPickMatches := cherryPickRe.FindAllStringSubmatch(c.Body, -1)
 		for _, match := range cherryPickMatches {
-			targetBranch := strings.TrimSpace(match[1])
-			if requestorToComments[c.User.Login] == nil {
-				requestorToComments[c.User.Login] = make(map[string]*github.IssueComment)
+			for _, targetBranch := range targetBranchesFrom(match[1]) {
+				if requestorToComments[c.User.Login] == nil {
+					requestorToComments[c.User.Login] = make(map[string]*github.IssueComment)
+				}
+				requestorToComments[c.User.Login][targetBranch] = &c
 			}
-			requestorToComments[c.User.Login][targetBranch] = &c
 		}
 	}
 
@@ -348,10 +354,12 @@ func (s *Server) handlePullRequest(l *logrus.Entry, pre github.PullRequestEvent)
 	}
 
 	// Handle multiple comments serially. Make sure to filter out
-	// comments targeting the same branch.
+	// comments targeting the same branch. Branches requested together
+	// are cherry-picked in one pass.
 	handledBranches := make(map[string]bool)
 	var errs []error
 	for requestor, branches := range requestorToComments {
+		commentToBranches := make(map[*github.IssueComment][]string)
 		for targetBranch, ic := range branches {
 			if handledBranches[targetBranch] {
 				// Branch already handled. Skip.
@@ -366,23 +374,110 @@ func (s *Server) handlePullRequest(l *logrus.Entry, pre github.PullRequestEvent)
 				continue
 			}
 			handledBranches[targetBranch] = true
-			l := l.WithFields(logrus.Fields{
-				"requestor":     requestor,
-				"target_branch": targetBranch,
-			})
-			l.Debug("Cherrypick request.")
-			err := s.handle(l, requestor, ic, org, repo, targetBranch, baseBranch, title, body, num)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to create cherrypick: %w", err))
+			commentToBranches[ic] = append(commentToBranches[ic], targetBranch)
+		}
+		for ic, targetBranches := range commentToBranches {
+			sort.Strings(targetBranches)
+			l := l.WithField("requestor", requestor)
+			if err := s.handleAll(l, requestor, ic, org, repo, targetBranches, baseBranch, title, body, num); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// targetBranchesFrom returns the unique target branches of a cherrypick
+// command, e.g. "release-1.27 release-1.28".
+func targetBranchesFrom(arg string) []string {
+	var targetBranches []string
+	seen := make(map[string]bool)
+	for _, targetBranch := range strings.Fields(arg) {
+		if !seen[targetBranch] {
+			seen[targetBranch] = true
+			targetBranches = append(targetBranches, targetBranch)
+		}
+	}
+	return targetBranches
+}
+
+// cherryPickResult is the outcome of cherry-picking a PR on top of a single
+// target branch.
+type cherryPickResult struct {
+	targetBranch string
+	// title is the title of the cherry-pick PR.
+	title string
+	// createdNum is the number of the cherry-pick PR, or 0 if none was created.
+	createdNum int
+	// conflict explains why the PR failed to apply on top of the target
+	// branch, if it did.
+	conflict string
+}
+
+// handleAll cherry-picks a PR on top of all target branches requested
+// together. If the PR fails to apply on top of any of multiple target
+// branches and issues are created on conflicts, a tracking issue listing the
+// outcome for every branch is created and assigned to the requestor.
+func (s *Server) handleAll(l *logrus.Entry, requestor string, comment *github.IssueComment, org, repo string, targetBranches []string, baseBranch, title, body string, num int) error {
+	if len(targetBranches) == 0 {
+		return nil
+	}
+	var errs []error
+	var results []cherryPickResult
+	for _, targetBranch := range targetBranches {
+		l := l.WithField("target_branch", targetBranch)
+		l.Debug("Cherrypick request.")
+		result, err := s.handle(l, requestor, comment, org, repo, targetBranch, baseBranch, title, body, num)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create cherrypick: %w", err))
+		}
+		results = append(results, result)
+	}
+
+	if len(targetBranches) == 1 {
+		if result := results[0]; result.conflict != "" && s.issueOnConflict {
+			resp := fmt.Sprintf("Manual cherrypick required.\n\n%v", result.conflict)
+			if err := s.createIssue(l, org, repo, result.title, resp, num, comment, nil, []string{requestor}); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create issue: %w", err))
 			}
 		}
+		return utilerrors.NewAggregate(errs)
+	}
+
+	var summary bytes.Buffer
+	var conflicts bool
+	for _, result := range results {
+		switch {
+		case result.createdNum != 0:
+			fmt.Fprintf(&summary, "- `%s`: #%d\n", result.targetBranch, result.createdNum)
+		case result.conflict != "":
+			conflicts = true
+			fmt.Fprintf(&summary, "- `%s`: manual cherrypick required, the PR failed to apply\n", result.targetBranch)
+		default:
+			fmt.Fprintf(&summary, "- `%s`: no cherrypick PR created\n", result.targetBranch)
+		}
+	}
+	resp := fmt.Sprintf("cherrypicks of #%d:\n\n%s", num, summary.String())
+	if conflicts && s.issueOnConflict {
+		trackingTitle := fmt.Sprintf("Track cherrypicks of #%d: %s", num, title)
+		trackingBody := fmt.Sprintf("Some cherrypicks of #%d failed to apply and need to be done manually.\n\n%s", num, summary.String())
+		issueNum, err := s.ghc.CreateIssue(org, repo, trackingTitle, trackingBody, 0, nil, []string{requestor})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create tracking issue: %w", err))
+		} else {
+			resp += fmt.Sprintf("\nTracking issue for the failed cherrypicks: #%d", issueNum)
+		}
+	}
+	if err := s.createComment(l, org, repo, num, comment, resp); err != nil {
+		errs = append(errs, fmt.Errorf("failed to create comment: %w", err))
 	}
 	return utilerrors.NewAggregate(errs)
 }
 
 var cherryPickBranchFmt = "cherry-pick-%d-to-%s"
 
-func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.IssueComment, org, repo, targetBranch, baseBranch, title, body string, num int) error {
+func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.IssueComment, org, repo, targetBranch, baseBranch, title, body string, num int) (cherryPickResult, error) {
+	result := cherryPickResult{targetBranch: targetBranch}
 	var lock *sync.Mutex
 	func() {
 		s.mapLock.Lock()
@@ -402,14 +497,14 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 	if err != nil {
 		logger.WithError(err).Warn("failed to ensure fork exists")
 		resp := fmt.Sprintf("cannot fork %s/%s: %v", org, repo, err)
-		return s.createComment(logger, org, repo, num, comment, resp)
+		return result, s.createComment(logger, org, repo, num, comment, resp)
 	}
 
 	// Clone the repo, checkout the target branch.
 	startClone := time.Now()
 	r, err := s.gc.ClientFor(org, repo)
 	if err != nil {
-		return fmt.Errorf("failed to get git client for %s/%s: %w", org, forkName, err)
+		return result, fmt.Errorf("failed to get git client for %s/%s: %w", org, forkName, err)
 	}
 	defer func() {
 		if err := r.Clean(); err != nil {
@@ -419,25 +514,25 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 	if err := r.Checkout(targetBranch); err != nil {
 		logger.WithError(err).Warn("failed to checkout target branch")
 		resp := fmt.Sprintf("cannot checkout `%s`: %v", targetBranch, err)
-		return s.createComment(logger, org, repo, num, comment, resp)
+		return result, s.createComment(logger, org, repo, num, comment, resp)
 	}
 	logger.WithField("duration", time.Since(startClone)).Info("Cloned and checked out target branch.")
 
 	// Fetch the patch from GitHub
 	localPath, err := s.getPatch(org, repo, targetBranch, num)
 	if err != nil {
-		return fmt.Errorf("failed to get patch: %w", err)
+		return result, fmt.Errorf("failed to get patch: %w", err)
 	}
 
 	if err := r.Config("user.name", s.botUser.Login); err != nil {
-		return fmt.Errorf("failed to configure git user: %w", err)
+		return result, fmt.Errorf("failed to configure git user: %w", err)
 	}
 	email := s.email
 	if email == "" {
 		email = s.botUser.Email
 	}
 	if err := r.Config("user.email", email); err != nil {
-		return fmt.Errorf("failed to configure git email: %w", err)
+		return result, fmt.Errorf("failed to configure git email: %w", err)
 	}
 
 	// New branch for the cherry-pick.
@@ -448,25 +543,27 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 		// Find the PR and link to it.
 		prs, err := s.ghc.GetPullRequests(org, repo)
 		if err != nil {
-			return fmt.Errorf("failed to get pullrequests for %s/%s: %w", org, repo, err)
+			return result, fmt.Errorf("failed to get pullrequests for %s/%s: %w", org, repo, err)
 		}
 		for _, pr := range prs {
 			if pr.Head.Ref == fmt.Sprintf("%s:%s", s.botUser.Login, newBranch) {
 				logger.WithField("preexisting_cherrypick", pr.HTMLURL).Info("PR already has cherrypick")
 				resp := fmt.Sprintf("Looks like #%d has already been cherry picked in %s", num, pr.HTMLURL)
-				return s.createComment(logger, org, repo, num, comment, resp)
+				result.createdNum = pr.Number
+				return result, s.createComment(logger, org, repo, num, comment, resp)
 			}
 		}
 	}
 
 	// Create the branch for the cherry-pick.
 	if err := r.CheckoutNewBranch(newBranch); err != nil {
-		return fmt.Errorf("failed to checkout %s: %w", newBranch, err)
+		return result, fmt.Errorf("failed to checkout %s: %w", newBranch, err)
 	}
 
 	// Title for GitHub issue/PR.
 	titleTargetBranchIndicator := fmt.Sprintf(titleTargetBranchIndicatorTemplate, targetBranch)
 	title = fmt.Sprintf("%s%s", titleTargetBranchIndicator, omitBaseBranchFromTitle(title, baseBranch))
+	result.title = title
 
 	// Apply the patch.
 	if err := r.Am(localPath); err != nil {
@@ -476,15 +573,9 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 		if err := s.createComment(logger, org, repo, num, comment, resp); err != nil {
 			errs = append(errs, fmt.Errorf("failed to create comment: %w", err))
 		}
+		result.conflict = resp
 
-		if s.issueOnConflict {
-			resp = fmt.Sprintf("Manual cherrypick required.\n\n%v", resp)
-			if err := s.createIssue(logger, org, repo, title, resp, num, comment, nil, []string{requestor}); err != nil {
-				errs = append(errs, fmt.Errorf("failed to create issue: %w", err))
-			}
-		}
-
-		return utilerrors.NewAggregate(errs)
+		return result, utilerrors.NewAggregate(errs)
 	}
 
 	push := r.PushToNamedFork
@@ -495,7 +586,7 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 	if err := push(forkName, newBranch, true); err != nil {
 		logger.WithError(err).Warn("failed to push chery-picked changes to GitHub")
 		resp := fmt.Sprintf("failed to push cherry-picked changes in GitHub: %v", err)
-		return utilerrors.NewAggregate([]error{err, s.createComment(logger, org, repo, num, comment, resp)})
+		return result, utilerrors.NewAggregate([]error{err, s.createComment(logger, org, repo, num, comment, resp)})
 	}
 
 	// Open a PR in GitHub.
@@ -510,17 +601,18 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 	if err != nil {
 		logger.WithError(err).Warn("failed to create new pull request")
 		resp := fmt.Sprintf("new pull request could not be created: %v", err)
-		return utilerrors.NewAggregate([]error{err, s.createComment(logger, org, repo, num, comment, resp)})
+		return result, utilerrors.NewAggregate([]error{err, s.createComment(logger, org, repo, num, comment, resp)})
 	}
 	*logger = *logger.WithField("new_pull_request_number", createdNum)
+	result.createdNum = createdNum
 	resp := fmt.Sprintf("new pull request created: #%d", createdNum)
 	logger.Info("new pull request created")
 	if err := s.createComment(logger, org, repo, num, comment, resp); err != nil {
-		return fmt.Errorf("failed to create comment: %w", err)
+		return result, fmt.Errorf("failed to create comment: %w", err)
 	}
 	for _, label := range s.labels {
 		if err := s.ghc.AddLabel(org, repo, createdNum, label); err != nil {
-			return fmt.Errorf("failed to add label %s: %w", label, err)
+			return result, fmt.Errorf("failed to add label %s: %w", label, err)
 		}
 	}
 	if s.prowAssignments {
@@ -529,10 +621,10 @@ func (s *Server) handle(logger *logrus.Entry, requestor string, comment *github.
 			// Ignore returning errors on failure to assign as this is most likely
 			// due to users not being members of the org so that they can't be assigned
 			// in PRs.
-			return nil
+			return result, nil
 		}
 	}
-	return nil
+	return result, nil
 }
 
 // omitBaseBranchFromTitle returns the title without the base branch's
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/prow/pkg/git/localgit"
 	v2 "sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins"
 )
 
 var (
@@ -357,6 +358,156 @@ func testCherryPickIC(clients localgit.Clients, t *testing.T) {
 	}
 }
 
+func TestCherryPickICMultipleBranchesV2(t *testing.T) {
+	t.Parallel()
+	for _, issueOnConflict := range []bool{true, false} {
+		t.Run(fmt.Sprintf("issue on conflict %t", issueOnConflict), func(t *testing.T) {
+			testCherryPickICMultipleBranches(localgit.NewV2, issueOnConflict, t)
+		})
+	}
+}
+
+func testCherryPickICMultipleBranches(clients localgit.Clients, issueOnConflict bool, t *testing.T) {
+	iNumber := fakePR.GetPRNumber()
+	lg, c := makeFakeRepoWithCommit(clients, t)
+	if err := lg.CheckoutNewBranch("foo", "bar", "release-1.27"); err != nil {
+		t.Fatalf("Checking out pull branch: %v", err)
+	}
+	if err := lg.CheckoutNewBranch("foo", "bar", "release-1.28"); err != nil {
+		t.Fatalf("Checking out pull branch: %v", err)
+	}
+	if err := lg.AddCommit("foo", "bar", map[string][]byte{"bar.go": []byte("package bar\n\nfunc Foo(wow int) int {\n\treturn 7 * wow\n}\n")}); err != nil {
+		t.Fatalf("Adding conflicting commit: %v", err)
+	}
+
+	ghc := &fghc{
+		pr: &github.PullRequest{
+			Base: github.PullRequestBranch{
+				Ref: "master",
+			},
+			Merged: true,
+			Title:  "This is a fix for X",
+			Body:   body,
+		},
+		isMember: true,
+		patch:    patch,
+	}
+	ic := github.IssueCommentEvent{
+		Action: github.IssueCommentActionCreated,
+		Repo: github.Repo{
+			Owner: github.User{
+				Login: "foo",
+			},
+			Name:     "bar",
+			FullName: "foo/bar",
+		},
+		Issue: github.Issue{
+			Number:      iNumber,
+			State:       "closed",
+			PullRequest: &struct{}{},
+		},
+		Comment: github.IssueComment{
+			User: github.User{
+				Login: "wiseguy",
+			},
+			Body: "/cherrypick release-1.27 release-1.28 release-1.27",
+		},
+	}
+
+	botUser := &github.UserData{Login: "ci-robot", Email: "ci-robot@users.noreply.github.com"}
+	s := &Server{
+		botUser:         botUser,
+		gc:              c,
+		push:            func(forkName, newBranch string, force bool) error { return nil },
+		ghc:             ghc,
+		tokenGenerator:  func() []byte { return []byte("sha=abcdefg") },
+		log:             logrus.StandardLogger().WithField("client", "cherrypicker"),
+		repos:           []github.Repo{{Fork: true, FullName: "ci-robot/bar"}},
+		issueOnConflict: issueOnConflict,
+	}
+
+	if err := s.handleIssueComment(logrus.NewEntry(logrus.StandardLogger()), ic); err == nil {
+		t.Error("expected an error for the conflicting cherrypick, got none")
+	}
+
+	if len(ghc.prs) != 1 || ghc.prs[0].Base.Ref != "release-1.27" {
+		t.Fatalf("expected a single cherrypick PR against release-1.27, got %+v", ghc.prs)
+	}
+	summary := "- `release-1.27`: #1\n- `release-1.28`: manual cherrypick required, the PR failed to apply\n"
+	if !issueOnConflict {
+		if len(ghc.issues) != 0 {
+			t.Errorf("expected no tracking issue, got %+v", ghc.issues)
+		}
+		resp := fmt.Sprintf("cherrypicks of #%d:\n\n%s", iNumber, summary)
+		expectedComment := fmt.Sprintf(commentFormat, "foo", "bar", iNumber, plugins.FormatICResponse(ic.Comment, resp))
+		if got := ghc.comments[len(ghc.comments)-1]; got != expectedComment {
+			t.Errorf("expected summary comment:\n%s\ngot:\n%s", expectedComment, got)
+		}
+		return
+	}
+	if len(ghc.issues) != 1 {
+		t.Fatalf("expected a single tracking issue, got %d", len(ghc.issues))
+	}
+	expectedIssue := github.Issue{
+		Number:    1,
+		Title:     "Track cherrypicks of #" + fmt.Sprint(iNumber) + ": This is a fix for X",
+		Body:      fmt.Sprintf("Some cherrypicks of #%d failed to apply and need to be done manually.\n\n%s", iNumber, summary),
+		Assignees: []github.User{{Login: "wiseguy"}},
+	}
+	if diff := cmp.Diff(expectedIssue, ghc.issues[0]); diff != "" {
+		t.Errorf("tracking issue differs from expected (-want +got):\n%s", diff)
+	}
+	resp := fmt.Sprintf("cherrypicks of #%d:\n\n%s\nTracking issue for the failed cherrypicks: #1", iNumber, summary)
+	expectedComment := fmt.Sprintf(commentFormat, "foo", "bar", iNumber, plugins.FormatICResponse(ic.Comment, resp))
+	if got := ghc.comments[len(ghc.comments)-1]; got != expectedComment {
+		t.Errorf("expected summary comment:\n%s\ngot:\n%s", expectedComment, got)
+	}
+}
+
+func TestCherryPickICWithoutTargetBranches(t *testing.T) {
+	for _, state := range []string{"open", "closed"} {
+		t.Run(state, func(t *testing.T) {
+			ghc := &fghc{
+				pr:       &github.PullRequest{Base: github.PullRequestBranch{Ref: "master"}, Merged: true},
+				isMember: true,
+			}
+			ic := github.IssueCommentEvent{
+				Action: github.IssueCommentActionCreated,
+				Repo:   github.Repo{Owner: github.User{Login: "foo"}, Name: "bar", FullName: "foo/bar"},
+				Issue:  github.Issue{Number: 2, State: state, PullRequest: &struct{}{}},
+				Comment: github.IssueComment{
+					User: github.User{Login: "wiseguy"},
+					Body: "/cherrypick \r\n",
+				},
+			}
+			s := &Server{ghc: ghc, log: logrus.StandardLogger().WithField("client", "cherrypicker")}
+			if err := s.handleIssueComment(logrus.NewEntry(logrus.StandardLogger()), ic); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ghc.comments) != 0 {
+				t.Errorf("expected no comments, got %v", ghc.comments)
+			}
+		})
+	}
+}
+
+func TestTargetBranchesFrom(t *testing.T) {
+	testCases := []struct {
+		arg      string
+		expected []string
+	}{
+		{arg: "release-1.27", expected: []string{"release-1.27"}},
+		{arg: " release-1.27\r", expected: []string{"release-1.27"}},
+		{arg: "\r"},
+		{arg: "release-1.27 release-1.28  release-1.27", expected: []string{"release-1.27", "release-1.28"}},
+	}
+	for _, tc := range testCases {
+		if diff := cmp.Diff(tc.expected, targetBranchesFrom(tc.arg)); diff != "" {
+			t.Errorf("%q: target branches differ from expected (-want +got):\n%s", tc.arg, diff)
+		}
+	}
+}
+
 func TestCherryPickPRV2(t *testing.T) {
 	t.Parallel()
 	testCherryPickPR(localgit.NewV2, t)
@@ -1007,13 +1158,13 @@ func TestHandleLocks(t *testing.T) {
 
 	go func() {
 		defer close(routine1Done)
-		if err := s.handle(l, "", &github.IssueComment{}, "org", "repo", "targetBranch", "baseBranch", "title", "body", 0); err != nil {
+		if _, err := s.handle(l, "", &github.IssueComment{}, "org", "repo", "targetBranch", "baseBranch", "title", "body", 0); err != nil {
 			t.Errorf("routine failed: %v", err)
 		}
 	}()
 	go func() {
 		defer close(routine2Done)
-		if err := s.handle(l, "", &github.IssueComment{}, "org", "repo", "targetBranch", "baseBranch", "title", "body", 0); err != nil {
+		if _, err := s.handle(l, "", &github.IssueComment{}, "org", "repo", "targetBranch", "baseBranch", "title", "body", 0); err != nil {
 			t.Errorf("routine failed: %v", err)
 		}
 	}()
@@ -36,6 +36,7 @@ import (
 	"sigs.k8s.io/prow/pkg/labels"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 	"sigs.k8s.io/prow/pkg/pluginhelp/externalplugins"
 )
 
@@ -135,6 +136,7 @@ func main() {
 	}, o.updatePeriod)
 
 	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
+	pprof.Instrument(o.instrumentationOptions)
 	health.ServeReady()
 
 	mux := http.NewServeMux()
@@ -186,6 +188,16 @@ func (s *Server) handleEvent(eventType, eventGUID string, payload []byte) error
 				l.WithField("event-type", eventType).WithError(err).Info("Error handling event.")
 			}
 		}()
+	case "push":
+		var pe github.PushEvent
+		if err := json.Unmarshal(payload, &pe); err != nil {
+			return err
+		}
+		go func() {
+			if err := plugin.HandlePushEvent(l, s.ghc, &pe); err != nil {
+				l.WithField("event-type", eventType).WithError(err).Info("Error handling event.")
+			}
+		}()
 	case "issue_comment":
 		var ice github.IssueCommentEvent
 		if err := json.Unmarshal(payload, &ice); err != nil {
@@ -62,7 +62,7 @@ type githubClient interface {
 func HelpProvider(_ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
 	return &pluginhelp.PluginHelp{
 			Description: `The needs-rebase plugin manages the '` + labels.NeedsRebase + `' label by removing it from Pull Requests that are mergeable and adding it to those which are not.
-The plugin reacts to commit changes on PRs in addition to periodically scanning all open PRs for any changes to mergeability that could have resulted from changes in other PRs.`,
+The plugin reacts to commit changes on PRs and to pushes to their base branches in addition to periodically scanning all open PRs for any changes to mergeability that could have resulted from changes in other PRs.`,
 		},
 		nil
 }
@@ -137,6 +137,51 @@ func handle(log *logrus.Entry, ghc githubClient, pr *github.PullRequest) error {
 
 const searchQueryPrefix = "archived:false is:pr is:open"
 
+const (
+	// pushRecheckAttempts is the number of times PRs whose mergeability is
+	// still being calculated after a push to their base branch are queried.
+	pushRecheckAttempts = 3
+	// pushRecheckDelay is the time to wait before querying PRs after a push
+	// to their base branch to give GitHub a chance to recalculate their
+	// mergeability.
+	pushRecheckDelay = 30 * time.Second
+)
+
+// HandlePushEvent handles a GitHub push event by checking all open PRs
+// targeting the pushed branch to determine if the "needs-rebase" label needs
+// to be added or removed, as a push to the base branch may introduce or
+// resolve merge conflicts.
+func HandlePushEvent(log *logrus.Entry, ghc githubClient, pe *github.PushEvent) error {
+	if pe.Deleted || !strings.HasPrefix(pe.Ref, "refs/heads/") {
+		return nil
+	}
+	org := pe.Repo.Owner.Login
+	repo := pe.Repo.Name
+	branch := pe.Branch()
+	log = log.WithFields(logrus.Fields{
+		github.OrgLogField:  org,
+		github.RepoLogField: repo,
+		"branch":            branch,
+	})
+	query := fmt.Sprintf(`%s repo:"%s/%s" base:"%s"`, searchQueryPrefix, org, repo, branch)
+
+	for attempt := 1; attempt <= pushRecheckAttempts; attempt++ {
+		sleep(pushRecheckDelay)
+		prs, err := search(context.Background(), log, ghc, query, org)
+		if err != nil {
+			return err
+		}
+		unknown := processPullRequests(log, ghc, prs)
+		if unknown == 0 {
+			return nil
+		}
+		if attempt == pushRecheckAttempts {
+			log.WithField("unknown_count", unknown).Info("Mergeability of some PRs is still unknown, they will be updated by event or periodic scan.")
+		}
+	}
+	return nil
+}
+
 // HandleAll checks all orgs and repos that enabled this plugin for open PRs to
 // determine if the "needs-rebase" label needs to be added or removed. It
 // depends on GitHub's mergeability check to decide the need for a rebase.
@@ -170,10 +215,22 @@ func HandleAll(log *logrus.Entry, ghc githubClient, config *plugins.Configuratio
 		log.WithError(err).Error("Encountered errors when querying GitHub but will process received results anyways")
 	}
 	log.WithField("prs_found_count", len(prs)).Debug("Processing all found PRs")
+	processPullRequests(log, ghc, prs)
+	return nil
+}
 
+// processPullRequests adds or removes the "needs-rebase" label on the given
+// PRs. It returns the number of open PRs that were skipped because GitHub is
+// still calculating their mergeability.
+func processPullRequests(log *logrus.Entry, ghc githubClient, prs []pullRequest) int {
+	var unknown int
 	for _, pr := range prs {
 		// Skip PRs that are calculating mergeability or are not open. They will be updated by event or next loop.
-		if pr.Mergeable == githubql.MergeableStateUnknown || pr.State != githubql.PullRequestStateOpen {
+		if pr.State != githubql.PullRequestStateOpen {
+			continue
+		}
+		if pr.Mergeable == githubql.MergeableStateUnknown {
+			unknown++
 			continue
 		}
 		org := string(pr.Repository.Owner.Login)
@@ -207,7 +264,7 @@ func HandleAll(log *logrus.Entry, ghc githubClient, config *plugins.Configuratio
 			l.WithError(err).Error("Error handling PR.")
 		}
 	}
-	return nil
+	return unknown
 }
 
 // takeAction adds or removes the "needs-rebase" label based on the current
@@ -51,6 +51,8 @@ type fghc struct {
 	// The following are maps are keyed using 'testKey'
 	commentCreated, commentDeleted       map[string]bool
 	IssueLabelsAdded, IssueLabelsRemoved map[string][]string
+
+	queries []string
 }
 
 func newFakeClient(prs []pullRequest, initialLabels []string, mergeable bool, pr *github.PullRequest) *fghc {
@@ -108,11 +110,12 @@ func (f *fghc) DeleteStaleCommentsWithContext(_ context.Context, org, repo strin
 	return nil
 }
 
-func (f *fghc) QueryWithGitHubAppsSupport(_ context.Context, q interface{}, _ map[string]interface{}, _ string) error {
+func (f *fghc) QueryWithGitHubAppsSupport(_ context.Context, q interface{}, vars map[string]interface{}, _ string) error {
 	query, ok := q.(*searchQuery)
 	if !ok {
 		return errors.New("invalid query format")
 	}
+	f.queries = append(f.queries, string(vars["query"].(githubql.String)))
 	query.Search.Nodes = f.allPRs
 	return nil
 }
@@ -425,6 +428,90 @@ func TestHandleAll(t *testing.T) {
 	}
 }
 
+func TestHandlePushEvent(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		ref       string
+		deleted   bool
+		mergeable githubql.MergeableState
+		labels    []string
+
+		expectedQueries                []string
+		expectedAdded, expectedRemoved []string
+		expectComment, expectDeletion  bool
+	}{
+		{
+			name: "Tag pushes are ignored",
+			ref:  "refs/tags/v1.0.0",
+		},
+		{
+			name:    "Branch deletions are ignored",
+			ref:     "refs/heads/main",
+			deleted: true,
+		},
+		{
+			name:      "Conflicting PR gets the label",
+			ref:       "refs/heads/main",
+			mergeable: githubql.MergeableStateConflicting,
+
+			expectedQueries: []string{searchQueryPrefix + ` repo:"org/repo" base:"main"`},
+			expectedAdded:   []string{labels.NeedsRebase},
+			expectComment:   true,
+		},
+		{
+			name:      "Mergeable PR loses the label",
+			ref:       "refs/heads/release-1.0",
+			mergeable: githubql.MergeableStateMergeable,
+			labels:    []string{labels.NeedsRebase},
+
+			expectedQueries: []string{searchQueryPrefix + ` repo:"org/repo" base:"release-1.0"`},
+			expectedRemoved: []string{labels.NeedsRebase},
+			expectDeletion:  true,
+		},
+		{
+			name:      "PR with unknown mergeability is queried again",
+			ref:       "refs/heads/main",
+			mergeable: githubql.MergeableStateUnknown,
+
+			expectedQueries: []string{
+				searchQueryPrefix + ` repo:"org/repo" base:"main"`,
+				searchQueryPrefix + ` repo:"org/repo" base:"main"`,
+				searchQueryPrefix + ` repo:"org/repo" base:"main"`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := pullRequest{
+				Number:    githubql.Int(1),
+				State:     githubql.PullRequestStateOpen,
+				Mergeable: tc.mergeable,
+			}
+			pr.Repository.Name = "repo"
+			pr.Repository.Owner.Login = "org"
+			for _, label := range tc.labels {
+				pr.Labels.Nodes = append(pr.Labels.Nodes, struct{ Name githubql.String }{Name: githubql.String(label)})
+			}
+			fake := newFakeClient([]pullRequest{pr}, nil, false, nil)
+			pe := &github.PushEvent{
+				Ref:     tc.ref,
+				Deleted: tc.deleted,
+				Repo:    github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+			}
+
+			if err := HandlePushEvent(logrus.WithField("plugin", PluginName), fake, pe); err != nil {
+				t.Fatalf("Unexpected error handling push event: %v.", err)
+			}
+			if diff := cmp.Diff(tc.expectedQueries, fake.queries); diff != "" {
+				t.Errorf("Queries differ from expected (-want +got):\n%s", diff)
+			}
+			fake.compareExpected(t, "org", "repo", 1, tc.expectedAdded, tc.expectedRemoved, tc.expectComment, tc.expectDeletion)
+		})
+	}
+}
+
 func TestConstructQueries(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
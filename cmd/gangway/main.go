@@ -17,7 +17,6 @@ limitations under the License.
 package main
 
 import (
-	"context"
 	"flag"
 	"fmt"
 	"net"
@@ -44,6 +43,7 @@ import (
 	"sigs.k8s.io/prow/pkg/metrics"
 	"sigs.k8s.io/prow/pkg/moonraker"
 	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 )
 
 // Empty string represents the overall health of all gRPC services. See
@@ -93,43 +93,6 @@ func (o *options) validate() error {
 	return utilerrors.NewAggregate(errs)
 }
 
-// interruptableServer is a wrapper type around the gRPC server, so that we can
-// pass it along to our own interrupts package.
-type interruptableServer struct {
-	grpcServer *grpc.Server
-	listener   net.Listener
-	port       int
-}
-
-// Shutdown shuts down the inner gRPC server as gracefully as possible, by first
-// invoking GracefulStop() on it. This gives the server time to try to handle
-// things gracefully internally. However if it takes too long (if the parent
-// context cancels us), we forcefully kill the server by calling Stop(). Stop()
-// interrupts GracefulStop() (see
-// https://pkg.go.dev/google.golang.org/grpc#Server.Stop).
-func (s *interruptableServer) Shutdown(ctx context.Context) error {
-
-	gracefulStopFinished := make(chan struct{})
-
-	go func() {
-		s.grpcServer.GracefulStop()
-		close(gracefulStopFinished)
-	}()
-
-	select {
-	case <-gracefulStopFinished:
-		return nil
-	case <-ctx.Done():
-		s.grpcServer.Stop()
-		return ctx.Err()
-	}
-}
-
-func (s *interruptableServer) ListenAndServe() error {
-	logrus.Infof("serving gRPC on port %d", s.port)
-	return s.grpcServer.Serve(s.listener)
-}
-
 func main() {
 	logrusutil.ComponentInit()
 
@@ -163,8 +126,8 @@ func main() {
 	}
 
 	// InRepoConfig getter.
-	if o.config.MoonrakerAddress != "" {
-		moonrakerClient, err := moonraker.NewClient(o.config.MoonrakerAddress, configAgent)
+	if o.config.MoonrakerEnabled() {
+		moonrakerClient, err := moonraker.NewInRepoConfigGetter(o.config.MoonrakerAddress, o.config.MoonrakerGRPCAddress, configAgent)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error getting Moonraker client.")
 		}
@@ -185,6 +148,7 @@ func main() {
 
 	// Start serving liveness endpoint /healthz.
 	healthHTTP := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
+	pprof.Instrument(o.instrumentationOptions)
 
 	lis, err := net.Listen("tcp", ":"+strconv.Itoa(o.port))
 	if err != nil {
@@ -217,10 +181,9 @@ func main() {
 	// clients that don't have the generated stubs baked in, such as grpcurl.
 	reflection.Register(grpcServer)
 
-	s := &interruptableServer{
-		grpcServer: grpcServer,
-		listener:   lis,
-		port:       o.port,
+	s := &interrupts.GRPCServer{
+		Server:   grpcServer,
+		Listener: lis,
 	}
 
 	// Start serving readiness endpoint /healthz/ready. Note that this is a
@@ -32,6 +32,7 @@ import (
 
 	"sigs.k8s.io/prow/cmd/generic-autobumper/updater"
 	"sigs.k8s.io/prow/pkg/config/secret"
+	gitv2 "sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/github"
 )
 
@@ -74,6 +75,11 @@ type Options struct {
 	HeadBranchName string `json:"headBranchName"`
 	// Optional list of labels to add to the bump PR
 	Labels []string `json:"labels"`
+	// The path to the private key to sign the commits with, for repos that require verified commits. The key must
+	// belong to the committer and must not be protected with a passphrase. If unset, commits are not signed.
+	SigningKey string `json:"signingKey"`
+	// The format of SigningKey, "gpg" or "ssh". Defaults to "gpg".
+	SigningFormat string `json:"signingFormat"`
 }
 
 // Information needed for gerrit bump
@@ -180,6 +186,14 @@ func validateOptions(o *Options) error {
 			o.HeadBranchName = defaultHeadBranchName
 		}
 	}
+	if o.SigningKey != "" {
+		if o.SigningFormat == "" {
+			o.SigningFormat = string(gitv2.SigningFormatGPG)
+		}
+		if format := gitv2.SigningFormat(o.SigningFormat); format != gitv2.SigningFormatGPG && format != gitv2.SigningFormatSSH {
+			return fmt.Errorf("signingFormat must be %q or %q", gitv2.SigningFormatGPG, gitv2.SigningFormatSSH)
+		}
+	}
 
 	return nil
 }
@@ -196,12 +210,57 @@ func Run(ctx context.Context, o *Options, prh PRHandler) error {
 	if o.SkipPullRequest {
 		logrus.Debugf("--skip-pull-request is set to true, won't create a pull request.")
 	}
+	if o.SigningKey != "" {
+		cleanup, err := configureCommitSigning(o)
+		if err != nil {
+			return fmt.Errorf("configure commit signing: %w", err)
+		}
+		defer cleanup()
+	}
 	if o.Gerrit == nil {
 		return processGitHub(ctx, o, prh)
 	}
 	return processGerrit(ctx, o, prh)
 }
 
+// configureCommitSigning configures the repo to sign commits with the signing
+// key, and returns a function that stops signing commits again once the key
+// is removed.
+func configureCommitSigning(o *Options) (func(), error) {
+	stdout := HideSecretsWriter{Delegate: os.Stdout, Censor: secret.Censor}
+	stderr := HideSecretsWriter{Delegate: os.Stderr, Censor: secret.Censor}
+	if err := secret.Add(o.SigningKey); err != nil {
+		return nil, fmt.Errorf("start secrets agent: %w", err)
+	}
+	signer, err := gitv2.NewCommitSigner(gitv2.SigningFormat(o.SigningFormat), secret.GetTokenGenerator(o.SigningKey), "")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() {
+		for _, key := range []string{"commit.gpgsign", "tag.gpgsign"} {
+			if err := Call(stdout, stderr, gitCmd, []string{"config", "--unset", key}); err != nil {
+				logrus.WithError(err).Warnf("Failed to unset %s.", key)
+			}
+		}
+		if err := signer.Clean(); err != nil {
+			logrus.WithError(err).Warn("Failed to remove the signing key.")
+		}
+	}
+	config, err := signer.GitConfig()
+	if err != nil {
+		signer.Clean()
+		return nil, err
+	}
+	for _, pair := range config {
+		key, value, _ := strings.Cut(pair, "=")
+		if err := Call(stdout, stderr, gitCmd, []string{"config", key, value}); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("set %s: %w", key, err)
+		}
+	}
+	return cleanup, nil
+}
+
 func processGitHub(ctx context.Context, o *Options, prh PRHandler) error {
 	stdout := HideSecretsWriter{Delegate: os.Stdout, Censor: secret.Censor}
 	stderr := HideSecretsWriter{Delegate: os.Stderr, Censor: secret.Censor}
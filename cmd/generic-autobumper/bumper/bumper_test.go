@@ -29,6 +29,8 @@ import (
 func TestValidateOptions(t *testing.T) {
 	emptyStr := ""
 	trueVar := true
+	signingKey := "/etc/signing/key"
+	x509 := "x509"
 	cases := []struct {
 		name                string
 		githubToken         *string
@@ -42,6 +44,8 @@ func TestValidateOptions(t *testing.T) {
 		remoteName          *string
 		skipPullRequest     *bool
 		signoff             *bool
+		signingKey          *string
+		signingFormat       *string
 		err                 bool
 		upstreamBaseChanged bool
 	}{
@@ -93,6 +97,17 @@ func TestValidateOptions(t *testing.T) {
 			gerritPRIdentifier: &emptyStr,
 			err:                true,
 		},
+		{
+			name:       "signingFormat defaults to gpg",
+			signingKey: &signingKey,
+			err:        false,
+		},
+		{
+			name:          "signingFormat must be gpg or ssh",
+			signingKey:    &signingKey,
+			signingFormat: &x509,
+			err:           true,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -148,6 +163,12 @@ func TestValidateOptions(t *testing.T) {
 			if tc.gerritHostRepo != nil {
 				defaultOption.Gerrit.HostRepo = *tc.gerritHostRepo
 			}
+			if tc.signingKey != nil {
+				defaultOption.SigningKey = *tc.signingKey
+			}
+			if tc.signingFormat != nil {
+				defaultOption.SigningFormat = *tc.signingFormat
+			}
 
 			err := validateOptions(defaultOption)
 			t.Logf("err is: %v", err)
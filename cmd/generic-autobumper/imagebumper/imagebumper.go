@@ -26,25 +26,40 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/blang/semver/v4"
 )
 
 var (
-	imageRegexp = regexp.MustCompile(`\b((?:[a-z0-9]+\.)?gcr\.io|(?:[a-z0-9-]+)?docker\.pkg\.dev)/([a-z][a-z0-9-]{5,29}/[a-zA-Z0-9][a-zA-Z0-9_./-]+):([a-zA-Z0-9_.-]+)\b`)
+	imageRegexp = regexp.MustCompile(`\b((?:[a-z0-9]+\.)?gcr\.io|(?:[a-z0-9-]+)?docker\.pkg\.dev|ghcr\.io|\d{12}\.dkr\.ecr\.[a-z0-9-]+\.amazonaws\.com)/([a-z0-9][a-z0-9_.-]*(?:/[a-zA-Z0-9][a-zA-Z0-9_.-]*)*):([a-zA-Z0-9_.-]+)(@sha256:[a-f0-9]{64})?\b`)
 	tagRegexp   = regexp.MustCompile(`(v?\d{8}-(?:v\d(?:[.-]\d+)*-g)?[0-9a-f]{6,10}|latest)(-.+)?`)
 )
 
 const (
-	imageHostPart  = 1
-	imageImagePart = 2
-	imageTagPart   = 3
-	tagVersionPart = 1
-	tagExtraPart   = 2
+	imageHostPart   = 1
+	imageImagePart  = 2
+	imageTagPart    = 3
+	imageDigestPart = 4
+	tagVersionPart  = 1
+	tagExtraPart    = 2
 )
 
+// ImagePolicy controls how images are bumped.
+type ImagePolicy struct {
+	// Constraint limits the versions images with semantic version tags are
+	// bumped to. A nil Constraint allows any version.
+	Constraint semver.Range
+	// PinDigest appends the digest of the new tag to the image reference.
+	PinDigest bool
+}
+
 type Client struct {
 	// Keys are <imageHost>/<imageName>:<currentTag>. Values are corresponding tags.
 	tagCache   map[string]string
 	httpClient *http.Client
+	registry   *registryClient
+	// Keys are image prefixes, <imageHost>/<imageName> of matching images start with them.
+	policies map[string]ImagePolicy
 }
 
 func NewClient(httpClient *http.Client) *Client {
@@ -55,7 +70,59 @@ func NewClient(httpClient *http.Client) *Client {
 	return &Client{
 		tagCache:   map[string]string{},
 		httpClient: &httpClientCopy,
+		registry:   newRegistryClient(&httpClientCopy),
+		policies:   map[string]ImagePolicy{},
+	}
+}
+
+// SetImagePolicy sets the policy of images starting with prefix. When several
+// prefixes match an image, the longest one wins.
+func (cli *Client) SetImagePolicy(prefix string, policy ImagePolicy) {
+	cli.policies[prefix] = policy
+}
+
+// SetRegistryCredentials sets the credentials used to authenticate to the
+// registry at host, e.g. AWS and the output of `aws ecr get-login-password`
+// for ECR, or a user and personal access token for GHCR.
+func (cli *Client) SetRegistryCredentials(host, username, password string) {
+	cli.registry.credentials[host] = registryCredential{username: username, password: password}
+}
+
+func (cli *Client) policy(imageHost, imageName string) ImagePolicy {
+	image := imageHost + "/" + imageName
+	var policy ImagePolicy
+	longest := -1
+	for prefix, p := range cli.policies {
+		if strings.HasPrefix(image, prefix) && len(prefix) > longest {
+			policy = p
+			longest = len(prefix)
+		}
+	}
+	return policy
+}
+
+// ParseSemverTag parses a tag as a semantic version, with an optional leading
+// "v". Date based tags like v20190404-65af07d are not semantic versions.
+func ParseSemverTag(tag string) (semver.Version, bool) {
+	if tagRegexp.MatchString(tag) {
+		return semver.Version{}, false
+	}
+	version, err := semver.ParseTolerant(tag)
+	if err != nil {
+		return semver.Version{}, false
 	}
+	return version, true
+}
+
+// SplitDigest separates a tag pinned to a digest, like v1.2.3@sha256:abc...,
+// into the tag and the digest.
+func SplitDigest(tag string) (string, string) {
+	tag, digest, _ := strings.Cut(tag, "@")
+	return tag, digest
+}
+
+func isGoogleRegistry(imageHost string) bool {
+	return strings.HasSuffix(imageHost, "gcr.io") || strings.HasSuffix(imageHost, "docker.pkg.dev")
 }
 
 type manifest map[string]struct {
@@ -132,22 +199,43 @@ func (cli *Client) FindLatestTag(imageHost, imageName, currentTag string) (strin
 		return result, nil
 	}
 
-	currentTagParts := tagRegexp.FindStringSubmatch(currentTag)
-	if currentTagParts == nil {
-		return "", fmt.Errorf("couldn't figure out the current tag in %q", currentTag)
-	}
-	if currentTagParts[tagVersionPart] == "latest" {
-		return currentTag, nil
-	}
+	policy := cli.policy(imageHost, imageName)
+	var latestTag string
+	if currentVersion, ok := ParseSemverTag(currentTag); ok {
+		tags, err := cli.registry.listTags(imageHost, imageName)
+		if err != nil {
+			return "", err
+		}
+		latestTag = pickBestSemverTag(currentTag, currentVersion, policy.Constraint, tags)
+	} else {
+		currentTagParts := tagRegexp.FindStringSubmatch(currentTag)
+		if currentTagParts == nil {
+			return "", fmt.Errorf("couldn't figure out the current tag in %q", currentTag)
+		}
+		if currentTagParts[tagVersionPart] == "latest" {
+			return currentTag, nil
+		}
+		if !isGoogleRegistry(imageHost) {
+			return "", fmt.Errorf("date based tags are only supported on GCR and Artifact Registry, not %s", imageHost)
+		}
 
-	imageList, err := cli.getManifest(imageHost, imageName)
-	if err != nil {
-		return "", err
+		imageList, err := cli.getManifest(imageHost, imageName)
+		if err != nil {
+			return "", err
+		}
+
+		latestTag, err = pickBestTag(currentTagParts, imageList)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	latestTag, err := pickBestTag(currentTagParts, imageList)
-	if err != nil {
-		return "", err
+	if policy.PinDigest {
+		digest, err := cli.registry.digest(imageHost, imageName, latestTag)
+		if err != nil {
+			return "", err
+		}
+		latestTag += "@" + digest
 	}
 
 	cli.tagCache[k] = latestTag
@@ -156,6 +244,19 @@ func (cli *Client) FindLatestTag(imageHost, imageName, currentTag string) (strin
 }
 
 func (cli *Client) TagExists(imageHost, imageName, currentTag string) (bool, error) {
+	if !isGoogleRegistry(imageHost) {
+		tags, err := cli.registry.listTags(imageHost, imageName)
+		if err != nil {
+			return false, err
+		}
+		for _, tag := range tags {
+			if tag == currentTag {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
 	imageList, err := cli.getManifest(imageHost, imageName)
 	if err != nil {
 		return false, err
@@ -220,6 +321,34 @@ func pickBestTag(currentTagParts []string, manifest manifest) (string, error) {
 	return latestTag, nil
 }
 
+// pickBestSemverTag returns the highest semantic version among tags that
+// satisfies the constraint. Tags are only considered if they spell the version
+// like the current tag, with or without a leading "v", and prereleases only if
+// the current tag is a prerelease. Images are never downgraded.
+func pickBestSemverTag(currentTag string, current semver.Version, constraint semver.Range, tags []string) string {
+	hasV := strings.HasPrefix(currentTag, "v")
+	bestTag, best := currentTag, current
+	for _, t := range tags {
+		if strings.HasPrefix(t, "v") != hasV {
+			continue
+		}
+		version, ok := ParseSemverTag(t)
+		if !ok {
+			continue
+		}
+		if len(version.Pre) > 0 && len(current.Pre) == 0 {
+			continue
+		}
+		if constraint != nil && !constraint(version) {
+			continue
+		}
+		if version.GT(best) {
+			bestTag, best = t, version
+		}
+	}
+	return bestTag
+}
+
 // AddToCache keeps track of changed tags
 func (cli *Client) AddToCache(image, newTag string) {
 	cli.tagCache[image] = newTag
@@ -239,6 +368,7 @@ func updateAllTags(tagPicker func(host, image, tag string) (string, error), cont
 		host := string(content[m[imageHostPart*2]:m[imageHostPart*2+1]])
 		image := string(content[m[imageImagePart*2]:m[imageImagePart*2+1]])
 		tag := string(content[m[imageTagPart*2]:m[imageTagPart*2+1]])
+		pinned := m[imageDigestPart*2] >= 0
 		lastIndex = m[1]
 
 		if tag == "" || (imageFilter != nil && !imageFilter.MatchString(host+"/"+image+":"+tag)) {
@@ -252,6 +382,15 @@ func updateAllTags(tagPicker func(host, image, tag string) (string, error), cont
 			newContent = append(newContent, content[m[imageTagPart*2]:m[1]]...)
 			continue
 		}
+		// Keep the digest of unchanged tags, and don't drop the digest of
+		// pinned images unless the new tag is pinned as well.
+		if latestTag, digest := SplitDigest(latest); digest == "" && (latestTag == tag || pinned) {
+			if latestTag != tag {
+				log.Printf("Not updating %s/%s:%s to unpinned %s.\n", host, image, tag, latest)
+			}
+			newContent = append(newContent, content[m[imageTagPart*2]:m[1]]...)
+			continue
+		}
 		newContent = append(newContent, []byte(latest)...)
 	}
 	newContent = append(newContent, content[lastIndex:]...)
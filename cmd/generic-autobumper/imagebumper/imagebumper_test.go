@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+
+	"github.com/blang/semver/v4"
 )
 
 func TestDeconstructCommit(t *testing.T) {
@@ -355,6 +357,47 @@ func TestUpdateAllTags(t *testing.T) {
 			},
 			imageFilter: regexp.MustCompile("gcr.io/k8s-testimages"),
 		},
+		{
+			name:           "GHCR and ECR images are supported",
+			content:        `{"images": ["ghcr.io/org/tool:v1.2.3", "123456789012.dkr.ecr.us-east-1.amazonaws.com/app:1.0.0"]}`,
+			expectedResult: `{"images": ["ghcr.io/org/tool:v1.3.0", "123456789012.dkr.ecr.us-east-1.amazonaws.com/app:1.0.1"]}`,
+			newTags: map[string]string{
+				"ghcr.io/org/tool:v1.2.3":                                "v1.3.0",
+				"123456789012.dkr.ecr.us-east-1.amazonaws.com/app:1.0.0": "1.0.1",
+			},
+		},
+		{
+			name:           "digests of pinned images are replaced",
+			content:        `image: ghcr.io/org/tool:v1.2.3@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa`,
+			expectedResult: `image: ghcr.io/org/tool:v1.3.0@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb`,
+			newTags: map[string]string{
+				"ghcr.io/org/tool:v1.2.3": "v1.3.0@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			},
+		},
+		{
+			name:           "digests of unchanged tags are kept",
+			content:        `image: ghcr.io/org/tool:v1.2.3@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa`,
+			expectedResult: `image: ghcr.io/org/tool:v1.2.3@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa`,
+			newTags: map[string]string{
+				"ghcr.io/org/tool:v1.2.3": "v1.2.3",
+			},
+		},
+		{
+			name:           "pinned images are not bumped to unpinned tags",
+			content:        `image: ghcr.io/org/tool:v1.2.3@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa`,
+			expectedResult: `image: ghcr.io/org/tool:v1.2.3@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa`,
+			newTags: map[string]string{
+				"ghcr.io/org/tool:v1.2.3": "v1.3.0",
+			},
+		},
+		{
+			name:           "unpinned images can be pinned",
+			content:        `image: ghcr.io/org/tool:v1.2.3`,
+			expectedResult: `image: ghcr.io/org/tool:v1.2.3@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb`,
+			newTags: map[string]string{
+				"ghcr.io/org/tool:v1.2.3": "v1.2.3@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -374,3 +417,82 @@ func TestUpdateAllTags(t *testing.T) {
 		})
 	}
 }
+
+func TestPickBestSemverTag(t *testing.T) {
+	tests := []struct {
+		name       string
+		currentTag string
+		constraint string
+		tags       []string
+		expected   string
+	}{
+		{
+			name:       "highest version is picked",
+			currentTag: "v1.2.3",
+			tags:       []string{"v1.2.3", "v1.10.0", "v1.9.0", "v2.0.0"},
+			expected:   "v2.0.0",
+		},
+		{
+			name:       "constraint limits the version",
+			currentTag: "v1.2.3",
+			constraint: "<2.0.0",
+			tags:       []string{"v1.2.3", "v1.10.0", "v2.0.0"},
+			expected:   "v1.10.0",
+		},
+		{
+			name:       "prereleases are skipped for releases",
+			currentTag: "v1.2.3",
+			tags:       []string{"v1.2.3", "v1.3.0-rc.1"},
+			expected:   "v1.2.3",
+		},
+		{
+			name:       "prereleases are considered for prereleases",
+			currentTag: "v1.3.0-rc.1",
+			tags:       []string{"v1.3.0-rc.1", "v1.3.0-rc.2"},
+			expected:   "v1.3.0-rc.2",
+		},
+		{
+			name:       "tags without the leading v of the current tag are skipped",
+			currentTag: "1.2.3",
+			tags:       []string{"1.2.3", "v1.3.0", "1.2.4", "latest", "v20190404-12345678"},
+			expected:   "1.2.4",
+		},
+		{
+			name:       "images are not downgraded",
+			currentTag: "v2.1.0",
+			constraint: "<2.0.0",
+			tags:       []string{"v1.10.0", "v2.1.0"},
+			expected:   "v2.1.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			current, ok := ParseSemverTag(test.currentTag)
+			if !ok {
+				t.Fatalf("current tag %q is not a semantic version", test.currentTag)
+			}
+			var constraint semver.Range
+			if test.constraint != "" {
+				constraint = semver.MustParseRange(test.constraint)
+			}
+			if actual := pickBestSemverTag(test.currentTag, current, constraint, test.tags); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseSemverTag(t *testing.T) {
+	for tag, expected := range map[string]bool{
+		"v1.2.3":             true,
+		"1.2":                true,
+		"v20190404-12345678": false,
+		"latest":             false,
+		"master":             false,
+	} {
+		if _, ok := ParseSemverTag(tag); ok != expected {
+			t.Errorf("expected ParseSemverTag(%q) to be %t", tag, expected)
+		}
+	}
+}
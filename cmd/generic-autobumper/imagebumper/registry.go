@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagebumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	nextLinkRegexp       = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// manifestMediaTypes are accepted when resolving the digest of a tag, so that
+// multi-arch images resolve to the digest of their index.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+type registryCredential struct {
+	username string
+	password string
+}
+
+// registryClient talks to container registries through the OCI distribution
+// API, which GCR, Artifact Registry, GHCR and ECR all implement. It answers
+// authentication challenges with a bearer token, requested anonymously or with
+// the credentials configured for the registry, or with basic auth.
+type registryClient struct {
+	httpClient  *http.Client
+	credentials map[string]registryCredential
+	// authorizations are Authorization headers by host and repository.
+	authorizations map[string]string
+	// scheme is only overridden in tests.
+	scheme string
+}
+
+func newRegistryClient(httpClient *http.Client) *registryClient {
+	return &registryClient{
+		httpClient:     httpClient,
+		credentials:    map[string]registryCredential{},
+		authorizations: map[string]string{},
+		scheme:         "https",
+	}
+}
+
+func (r *registryClient) do(method, host, name, path string, header http.Header) (*http.Response, error) {
+	u := path
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		u = r.scheme + "://" + host + path
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	key := host + "/" + name
+	if authorization, ok := r.authorizations[key]; ok {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if _, retried := r.authorizations[key]; retried || challenge == "" {
+		return nil, fmt.Errorf("unauthorized to access %s", key)
+	}
+	authorization, err := r.authorize(host, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't authorize to access %s: %w", key, err)
+	}
+	r.authorizations[key] = authorization
+	return r.do(method, host, name, path, header)
+}
+
+// authorize answers an authentication challenge of a registry.
+func (r *registryClient) authorize(host, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	credential, hasCredential := r.credentials[host]
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if !hasCredential {
+			return "", fmt.Errorf("registry %s requires credentials", host)
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(credential.username, credential.password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		values := map[string]string{}
+		for _, match := range challengeParamRegexp.FindAllStringSubmatch(params, -1) {
+			values[match[1]] = match[2]
+		}
+		realm, err := url.Parse(values["realm"])
+		if err != nil || values["realm"] == "" {
+			return "", fmt.Errorf("invalid realm in challenge %q", challenge)
+		}
+		query := realm.Query()
+		for _, param := range []string{"service", "scope"} {
+			if values[param] != "" {
+				query.Set(param, values[param])
+			}
+		}
+		realm.RawQuery = query.Encode()
+		req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		if hasCredential {
+			req.SetBasicAuth(credential.username, credential.password)
+		}
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("couldn't fetch token: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("couldn't fetch token: HTTP %d", resp.StatusCode)
+		}
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", fmt.Errorf("couldn't parse token: %w", err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	}
+	return "", fmt.Errorf("unsupported challenge %q", challenge)
+}
+
+// listTags returns all tags of an image, following pagination.
+func (r *registryClient) listTags(host, name string) ([]string, error) {
+	var tags []string
+	path := "/v2/" + name + "/tags/list"
+	for path != "" {
+		resp, err := r.do(http.MethodGet, host, name, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't fetch tag list: %w", err)
+		}
+		var result struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("couldn't fetch tag list: HTTP %d", resp.StatusCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse tag list from registry: %w", err)
+		}
+		tags = append(tags, result.Tags...)
+		path = ""
+		if match := nextLinkRegexp.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+			path = match[1]
+		}
+	}
+	return tags, nil
+}
+
+// digest returns the digest of the manifest a tag points to.
+func (r *registryClient) digest(host, name, tag string) (string, error) {
+	resp, err := r.do(http.MethodHead, host, name, "/v2/"+name+"/manifests/"+tag, http.Header{"Accept": manifestMediaTypes})
+	if err != nil {
+		return "", fmt.Errorf("couldn't fetch manifest: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("couldn't fetch manifest of %s/%s:%s: HTTP %d", host, name, tag, resp.StatusCode)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("registry returned no digest for %s/%s:%s", host, name, tag)
+	}
+	return digest, nil
+}
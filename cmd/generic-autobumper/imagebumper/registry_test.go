@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagebumper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver/v4"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// newTestRegistry serves a registry that requires bearer tokens, which it
+// only hands out to user:secret, and paginates tag lists.
+func newTestRegistry(t *testing.T) (*httptest.Server, *Client) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("scope") != "repository:org/tool:pull" {
+				t.Errorf("unexpected scope %q", r.URL.Query().Get("scope"))
+			}
+			fmt.Fprint(w, `{"token": "t0k3n"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:org/tool:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/org/tool/tags/list" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/org/tool/tags/list?last=v1.2.3&n=2>; rel="next"`)
+			fmt.Fprint(w, `{"name": "org/tool", "tags": ["v1.2.3", "v1.3.0"]}`)
+		case r.URL.Path == "/v2/org/tool/tags/list":
+			fmt.Fprint(w, `{"name": "org/tool", "tags": ["v2.0.0", "v2.1.0-rc.0"]}`)
+		case r.URL.Path == "/v2/org/tool/manifests/v1.3.0" && r.Method == http.MethodHead:
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+				t.Errorf("unexpected Accept header %q", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", testDigest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cli := NewClient(server.Client())
+	cli.registry.scheme = "http"
+	return server, cli
+}
+
+func TestFindLatestSemverTag(t *testing.T) {
+	server, cli := newTestRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+	cli.SetRegistryCredentials(host, "user", "secret")
+	cli.SetImagePolicy(host+"/org", ImagePolicy{Constraint: semver.MustParseRange("<2.0.0"), PinDigest: true})
+
+	tag, err := cli.FindLatestTag(host, "org/tool", "v1.2.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "v1.3.0@" + testDigest; tag != expected {
+		t.Errorf("expected %q, got %q", expected, tag)
+	}
+	if replacements := cli.GetReplacements(); replacements[host+"/org/tool:v1.2.3"] != tag {
+		t.Errorf("expected the replacement to be cached, got %v", replacements)
+	}
+
+	exists, err := cli.TagExists(host, "org/tool", "v2.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exists {
+		t.Error("expected tag v2.0.0 on the second page to exist")
+	}
+}
+
+func TestFindLatestSemverTagUnauthorized(t *testing.T) {
+	server, cli := newTestRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+	if _, err := cli.FindLatestTag(host, "org/tool", "v1.2.3"); err == nil {
+		t.Error("expected an error without credentials")
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	flag "github.com/spf13/pflag"
 	"golang.org/x/oauth2/google"
 
@@ -109,6 +110,9 @@ func generatePRBody(images map[string]string, prefixes []prefix) (body string) {
 	for _, prefix := range prefixes {
 		body = body + generateSummary(prefix.Name, prefix.Repo, prefix.Prefix, prefix.Summarise, images) + "\n\n"
 	}
+	if jumps := generateVersionJumps(images, prefixes); jumps != "" {
+		body = body + jumps + "\n\n"
+	}
 	return body + "\n"
 }
 
@@ -145,10 +149,24 @@ type options struct {
 	// * "" (empty) -- uses no auth token
 	// * "google" -- uses Google's "Application Default Credentials" as defined on https://pkg.go.dev/golang.org/x/oauth2/google#hdr-Credentials.
 	ImageRegistryAuth string `yaml:"imageRegistryAuth"`
+	// RegistryCredentials are used to authenticate to registries other than GCR and Artifact Registry,
+	// e.g. private GHCR or ECR repositories.
+	RegistryCredentials []registryCredentials `yaml:"registryCredentials"`
 	// AdditionalPRBody allows for generic, additional content in the body of the PR
 	AdditionalPRBody string `yaml:"additionalPRBody"`
 }
 
+// registryCredentials are the credentials for an image registry.
+type registryCredentials struct {
+	// Host of the registry, e.g. ghcr.io or 123456789012.dkr.ecr.us-east-1.amazonaws.com.
+	Host string `yaml:"host"`
+	// Username to authenticate with, AWS for ECR.
+	Username string `yaml:"username"`
+	// PasswordFile contains the password or token to authenticate with, e.g. the output of
+	// `aws ecr get-login-password` for ECR.
+	PasswordFile string `yaml:"passwordFile"`
+}
+
 // prefix is the information needed for each prefix being bumped.
 type prefix struct {
 	// Name of the tool being bumped
@@ -167,6 +185,11 @@ type prefix struct {
 	ConsistentImages bool `yaml:"consistentImages"`
 	// A list of images whose tags are not required to be consistent after the bump. Requires `consistentImages: true`.
 	ConsistentImageExceptions []string `yaml:"consistentImageExceptions"`
+	// Constrains the versions images with semantic version tags are bumped to when targetVersion is "latest",
+	// e.g. ">=1.2.0 <2.0.0" to stay on the current major version. By default images are bumped to the highest version.
+	SemverConstraint string `yaml:"semverConstraint"`
+	// Whether images bumped to the latest version are pinned to the digest of the new tag, as in image:v1.2.3@sha256:...
+	PinDigest bool `yaml:"pinDigest"`
 }
 
 func parseOptions() (*options, *bumper.Options, error) {
@@ -216,6 +239,16 @@ func validateOptions(o *options) error {
 		if len(prefix.ConsistentImageExceptions) > 0 && !prefix.ConsistentImages {
 			return fmt.Errorf("consistentImageExceptions requires consistentImages to be true, found in prefix %q", prefix.Name)
 		}
+		if prefix.SemverConstraint != "" {
+			if _, err := semver.ParseRange(prefix.SemverConstraint); err != nil {
+				return fmt.Errorf("semverConstraint %q is invalid in prefix %q: %w", prefix.SemverConstraint, prefix.Name, err)
+			}
+		}
+	}
+	for _, credentials := range o.RegistryCredentials {
+		if credentials.Host == "" || credentials.PasswordFile == "" {
+			return errors.New("registryCredentials require a host and a passwordFile")
+		}
 	}
 	if len(o.IncludedConfigPaths) == 0 {
 		return errors.New("includedConfigPaths is mandatory")
@@ -331,6 +364,23 @@ func updateReferencesWrapper(ctx context.Context, o *options) (map[string]string
 		}
 	}
 	imageBumperCli := imagebumper.NewClient(client)
+	for _, prefix := range o.Prefixes {
+		var policy imagebumper.ImagePolicy
+		if prefix.SemverConstraint != "" {
+			if policy.Constraint, err = semver.ParseRange(prefix.SemverConstraint); err != nil {
+				return nil, fmt.Errorf("bad semverConstraint %q: %w", prefix.SemverConstraint, err)
+			}
+		}
+		policy.PinDigest = prefix.PinDigest
+		imageBumperCli.SetImagePolicy(prefix.Prefix, policy)
+	}
+	for _, credentials := range o.RegistryCredentials {
+		password, err := os.ReadFile(credentials.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the password for %s: %w", credentials.Host, err)
+		}
+		imageBumperCli.SetRegistryCredentials(credentials.Host, credentials.Username, strings.TrimSpace(string(password)))
+	}
 	return updateReferences(imageBumperCli, filterRegexp, o)
 }
 
@@ -559,6 +609,10 @@ func generateSummary(name, repo, prefix string, summarise bool, images map[strin
 		if strings.HasSuffix(image, ":"+newTag) {
 			continue
 		}
+		// Semantic version bumps are listed by generateVersionJumps.
+		if _, ok := imagebumper.ParseSemverTag(tagFromName(image)); ok {
+			continue
+		}
 		oldDate, oldCommit, oldVariant := imagebumper.DeconstructTag(tagFromName(image))
 		newDate, newCommit, _ := imagebumper.DeconstructTag(newTag)
 		oldCommit = commitToRef(oldCommit)
@@ -601,6 +655,58 @@ func generateSummary(name, repo, prefix string, summarise bool, images map[strin
 	panic("unreachable!")
 }
 
+// generateVersionJumps lists the images with semantic version tags that were
+// bumped, and whether the bump is a major, minor or patch one.
+func generateVersionJumps(images map[string]string, prefixes []prefix) string {
+	var jumps []string
+	for image, newTag := range images {
+		if strings.HasSuffix(image, ":"+newTag) || !hasAnyPrefix(image, prefixes) {
+			continue
+		}
+		oldVersion, ok := imagebumper.ParseSemverTag(tagFromName(image))
+		if !ok {
+			continue
+		}
+		tag, digest := imagebumper.SplitDigest(newTag)
+		newVersion, ok := imagebumper.ParseSemverTag(tag)
+		if !ok {
+			continue
+		}
+		if digest != "" {
+			tag = fmt.Sprintf("%s (`%s`)", tag, digest)
+		}
+		jumps = append(jumps, fmt.Sprintf("%s | %s | %s | %s", imageFromName(image), tagFromName(image), tag, versionJump(oldVersion, newVersion)))
+	}
+	if len(jumps) == 0 {
+		return ""
+	}
+	sort.Strings(jumps)
+	return fmt.Sprintf("Version changes:\n\nImage | From | To | Jump\n--- | --- | --- | ---\n%s\n", strings.Join(jumps, "\n"))
+}
+
+func versionJump(oldVersion, newVersion semver.Version) string {
+	switch {
+	case newVersion.Major != oldVersion.Major:
+		return "major"
+	case newVersion.Minor != oldVersion.Minor:
+		return "minor"
+	case newVersion.Patch != oldVersion.Patch:
+		return "patch"
+	case newVersion.EQ(oldVersion):
+		return "digest"
+	}
+	return "prerelease"
+}
+
+func hasAnyPrefix(image string, prefixes []prefix) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(image, prefix.Prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func main() {
 	ctx := context.Background()
 	logrus.SetLevel(logrus.DebugLevel)
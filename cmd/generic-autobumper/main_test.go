@@ -187,6 +187,17 @@ func TestValidateOptions(t *testing.T) {
 		RefConfigFile:        "ref",
 		StagingRefConfigFile: "stagingRef",
 	}}
+	semverPrefixes := []prefix{{
+		Name:             "test",
+		Prefix:           "ghcr.io/test/",
+		SemverConstraint: ">=1.2.0 <2.0.0",
+		PinDigest:        true,
+	}}
+	invalidSemverPrefixes := []prefix{{
+		Name:             "test",
+		Prefix:           "ghcr.io/test/",
+		SemverConstraint: "not-a-version",
+	}}
 	upstreamVersion := "upstream"
 	stagingVersion := "upstream-staging"
 	cases := []struct {
@@ -243,6 +254,16 @@ func TestValidateOptions(t *testing.T) {
 			prefixes: &invalidExceptionPrefixes,
 			err:      true,
 		},
+		{
+			name:     "can constrain semantic versions",
+			prefixes: &semverPrefixes,
+			err:      false,
+		},
+		{
+			name:     "cannot use an invalid semverConstraint",
+			prefixes: &invalidSemverPrefixes,
+			err:      true,
+		},
 		{
 			name:          "must have ref files for upstream version",
 			targetVersion: &upstreamVersion,
@@ -1141,3 +1162,31 @@ func TestPRTitleBody(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateVersionJumps(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	prefixes := []prefix{{Name: "Tools", Prefix: "ghcr.io/org/"}, {Name: "Prow", Prefix: "gcr.io/k8s-prow/"}}
+	images := map[string]string{
+		"ghcr.io/org/major:v1.2.3":                "v2.0.0",
+		"ghcr.io/org/minor:1.2.3":                 "1.3.0@" + digest,
+		"ghcr.io/org/patch:v1.2.3":                "v1.2.4",
+		"ghcr.io/org/pinned:v1.2.3":               "v1.2.3@" + digest,
+		"ghcr.io/org/unchanged:v1.2.3":            "v1.2.3",
+		"ghcr.io/other/ignored:v1.2.3":            "v1.2.4",
+		"gcr.io/k8s-prow/hook:v20210128-2b123456": "v20210129-3a123456",
+	}
+	expected := "Version changes:\n\nImage | From | To | Jump\n--- | --- | --- | ---\n" +
+		"ghcr.io/org/major | v1.2.3 | v2.0.0 | major\n" +
+		"ghcr.io/org/minor | 1.2.3 | 1.3.0 (`" + digest + "`) | minor\n" +
+		"ghcr.io/org/patch | v1.2.3 | v1.2.4 | patch\n" +
+		"ghcr.io/org/pinned | v1.2.3 | v1.2.3 (`" + digest + "`) | digest\n"
+	if diff := cmp.Diff(expected, generateVersionJumps(images, prefixes)); diff != "" {
+		t.Errorf("version jumps don't match expected, diff: %s", diff)
+	}
+	if jumps := generateVersionJumps(map[string]string{"gcr.io/k8s-prow/hook:v20210128-2b123456": "v20210129-3a123456"}, prefixes); jumps != "" {
+		t.Errorf("expected no version jumps for date based tags, got %q", jumps)
+	}
+	if summary := generateSummary("Tools", "https://github.com/org/tools", "ghcr.io/org/", false, images); summary != "No ghcr.io/org/ changes." {
+		t.Errorf("expected semantic version bumps to be left out of the summary, got %q", summary)
+	}
+}
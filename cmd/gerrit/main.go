@@ -21,17 +21,21 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/config/secret"
 	"sigs.k8s.io/prow/pkg/diskutil"
 	"sigs.k8s.io/prow/pkg/metrics"
 	"sigs.k8s.io/prow/pkg/moonraker"
+	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -39,6 +43,7 @@ import (
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/gerrit/adapter"
+	gerritclient "sigs.k8s.io/prow/pkg/gerrit/client"
 	"sigs.k8s.io/prow/pkg/interrupts"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 )
@@ -94,6 +99,16 @@ type options struct {
 	changeWorkerPoolSize     int
 	pushGatewayInterval      time.Duration
 	instanceConcurrencyLimit uint
+	// webhookPort receives events from the webhooks plugin of Gerrit.
+	webhookPort int
+	// webhookTokenPath holds the token that webhook requests must carry.
+	webhookTokenPath string
+	// streamEvents are the instance=ssh://user@host:port addresses to stream
+	// events from over SSH.
+	streamEvents           prowflagutil.Strings
+	streamEventsSSHKeyPath string
+	// parsedStreamEvents maps instances to addresses to stream events from.
+	parsedStreamEvents map[string]string
 }
 
 func (o *options) validate() error {
@@ -123,6 +138,22 @@ func (o *options) validate() error {
 	if o.changeWorkerPoolSize < 1 {
 		return errors.New("change-worker-pool-size must be at least 1")
 	}
+	for _, streamEvents := range o.streamEvents.Strings() {
+		instance, address, ok := strings.Cut(streamEvents, "=")
+		if !ok || instance == "" || address == "" {
+			return fmt.Errorf("--stream-events=%q must look like instance=ssh://user@host:29418", streamEvents)
+		}
+		if o.parsedStreamEvents == nil {
+			o.parsedStreamEvents = map[string]string{}
+		}
+		o.parsedStreamEvents[instance] = address
+	}
+	if o.webhookPort != 0 && o.webhookTokenPath == "" {
+		return errors.New("--webhook-port requires --webhook-token-path")
+	}
+	if o.streamEventsSSHKeyPath != "" && len(o.parsedStreamEvents) == 0 {
+		return errors.New("--stream-events-ssh-key-path requires --stream-events")
+	}
 	return nil
 }
 
@@ -136,6 +167,10 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.DurationVar(&o.pushGatewayInterval, "push-gateway-interval", time.Minute, "Interval at which prometheus metrics for disk space are pushed.")
 	// TODO(cjwagner): remove deprecated flag.
 	fs.UintVar(&o.instanceConcurrencyLimit, "instance-concurrency-limit", 5, "[DEPRECATED] Number of concurrent calls that can be made to any single Gerrit host instance simultaneously.")
+	fs.IntVar(&o.webhookPort, "webhook-port", 0, "Port to receive events from the webhooks plugin of Gerrit on, which sync the projects of the events right away. 0 disables it.")
+	fs.StringVar(&o.webhookTokenPath, "webhook-token-path", "", "Path to the token that events from the webhooks plugin must carry as bearer token or as token query parameter.")
+	fs.Var(&o.streamEvents, "stream-events", "instance=ssh://user@host:29418 to stream events of a Gerrit instance from over SSH, which sync the projects of the events right away. Can be passed multiple times.")
+	fs.StringVar(&o.streamEventsSSHKeyPath, "stream-events-ssh-key-path", "", "Path to the SSH private key to stream events with, leave empty for the SSH config of the user.")
 	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.storage, &o.instrumentationOptions, &o.config, &o.gerrit} {
 		group.AddFlags(fs)
 	}
@@ -152,6 +187,7 @@ func main() {
 	}
 
 	runtime.SetBlockProfileRate(100_000_000) // 0.1 second sample rate https://github.com/DataDog/go-profiler-notes/blob/main/guide/README.md#block-profiler-limitations
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
 	pprof.Instrument(o.instrumentationOptions)
 
 	ca, err := o.config.ConfigAgent()
@@ -159,6 +195,7 @@ func main() {
 		logrus.WithError(err).Fatal("Error starting config agent.")
 	}
 	cfg := ca.Config
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(cfg))
 
 	// Expose Prometheus metrics
 	metrics.ExposeMetrics("gerrit", cfg().PushGateway, o.instrumentationOptions.MetricsPort)
@@ -167,6 +204,11 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting kube client.")
 	}
+	infrastructureClient, err := o.kubernetes.InfrastructureClusterClient(o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Kubernetes client for infrastructure cluster.")
+	}
+	health.AddReadinessChecks(pjutil.KubernetesHealthCheck(infrastructureClient.Discovery().RESTClient()))
 
 	ctx := context.Background() // TODO(fejta): use something better
 	op, err := o.storage.StorageClient(ctx)
@@ -180,8 +222,8 @@ func main() {
 	}
 
 	var ircg config.InRepoConfigGetter
-	if o.config.MoonrakerAddress != "" {
-		moonrakerClient, err := moonraker.NewClient(o.config.MoonrakerAddress, ca)
+	if o.config.MoonrakerEnabled() {
+		moonrakerClient, err := moonraker.NewInRepoConfigGetter(o.config.MoonrakerAddress, o.config.MoonrakerGRPCAddress, ca)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error getting Moonraker client.")
 		}
@@ -202,11 +244,36 @@ func main() {
 		}
 		ircg = ircc
 	}
-	c := adapter.NewController(ctx, prowJobClient, op, ca, o.cookiefilePath, o.tokenPathOverride, o.lastSyncFallback, o.changeWorkerPoolSize, o.gerrit.MaxQPS, o.gerrit.MaxBurst, ircg)
+	gerritOpts, err := gerritclient.OptionsFromFlags(&o.gerrit)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error configuring gerrit client.")
+	}
+	c := adapter.NewController(ctx, prowJobClient, op, ca, o.cookiefilePath, o.tokenPathOverride, o.lastSyncFallback, o.changeWorkerPoolSize, o.gerrit.MaxQPS, o.gerrit.MaxBurst, ircg, gerritOpts...)
 
 	logrus.Infof("Starting gerrit fetcher")
+	health.ServeReady()
 
 	defer interrupts.WaitForGracefulShutdown()
+
+	// Events sync projects right away, while ticks resync the changes of
+	// missed events.
+	if o.webhookPort != 0 {
+		mux := http.NewServeMux()
+		if err := secret.Add(o.webhookTokenPath); err != nil {
+			logrus.WithError(err).Fatal("Error starting secrets agent.")
+		}
+		mux.Handle("/", c.WebhookHandler(secret.GetTokenGenerator(o.webhookTokenPath)))
+		server := &http.Server{Addr: ":" + strconv.Itoa(o.webhookPort), Handler: mux}
+		interrupts.ListenAndServe(server, 5*time.Second)
+	}
+	for instance, address := range o.parsedStreamEvents {
+		instance, address := instance, address
+		interrupts.Run(func(ctx context.Context) {
+			if err := c.StreamEvents(ctx, instance, address, o.streamEventsSSHKeyPath); err != nil {
+				logrus.WithError(err).WithField("instance", instance).Fatal("Error streaming events.")
+			}
+		})
+	}
 	interrupts.Tick(func() {
 		c.Sync()
 	}, func() time.Duration {
@@ -80,6 +80,43 @@ func TestFlags(t *testing.T) {
 				o.storage.S3CredentialsFile = "/creds"
 			},
 		},
+		{
+			name: "events are configured",
+			args: map[string]string{
+				"--webhook-port":               "8888",
+				"--webhook-token-path":         "/etc/webhook/token",
+				"--stream-events":              "https://gerrit.example.com=ssh://prow@gerrit.example.com:29418",
+				"--stream-events-ssh-key-path": "/etc/ssh-key/key",
+			},
+			expected: func(o *options) {
+				o.webhookPort = 8888
+				o.webhookTokenPath = "/etc/webhook/token"
+				o.streamEvents = flagutil.NewStringsBeenSet("https://gerrit.example.com=ssh://prow@gerrit.example.com:29418")
+				o.streamEventsSSHKeyPath = "/etc/ssh-key/key"
+				o.parsedStreamEvents = map[string]string{"https://gerrit.example.com": "ssh://prow@gerrit.example.com:29418"}
+			},
+		},
+		{
+			name: "webhook without token",
+			args: map[string]string{
+				"--webhook-port": "8888",
+			},
+			err: true,
+		},
+		{
+			name: "stream events without address",
+			args: map[string]string{
+				"--stream-events": "https://gerrit.example.com",
+			},
+			err: true,
+		},
+		{
+			name: "stream events key without stream events",
+			args: map[string]string{
+				"--stream-events-ssh-key-path": "/etc/ssh-key/key",
+			},
+			err: true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -99,6 +136,7 @@ func TestFlags(t *testing.T) {
 				pushGatewayInterval:      time.Minute,
 				instanceConcurrencyLimit: 5,
 			}
+			expected.gerrit.AddFlags(flag.NewFlagSet("fake-flags", flag.PanicOnError))
 			if tc.expected != nil {
 				tc.expected(expected)
 			}
@@ -24,6 +24,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -85,6 +86,7 @@ func init() {
 //  v ^ ghcache: httpcache layer
 //  v ^ ghcache: upstreamTransport (cache-control, instrumentation)
 //  v ^ apptokenequalizer: Make sure all clients get the same app installation token so they can share a cache
+//  v ^ ghcache: budgetTransport (per-client budgets)
 //  v ^ http.DefaultTransport
 //  > ^   <Upstream>
 
@@ -93,7 +95,17 @@ type options struct {
 	sizeGB                                 int
 	diskCacheDisableAuthHeaderPartitioning bool
 
-	redisAddress string
+	redisAddress     string
+	memcachedAddress string
+
+	graphQLCacheTTL time.Duration
+
+	clientBudgetHigh    int
+	clientBudgetNormal  int
+	clientBudgetLow     int
+	clientBudgetMaxWait time.Duration
+	clientPriorities    flagutil.Strings
+	clientBudgets       ghcache.ClientBudgets
 
 	port           int
 	upstream       string
@@ -129,11 +141,33 @@ func (o *options) validate() error {
 	if (o.dir == "") != (o.sizeGB == 0) {
 		return errors.New("--cache-dir and --cache-sizeGB must be specified together to enable the disk cache (otherwise a memory cache is used)")
 	}
+	backends := 0
+	for _, set := range []bool{o.dir != "", o.redisAddress != "", o.memcachedAddress != ""} {
+		if set {
+			backends++
+		}
+	}
+	if backends > 1 {
+		return errors.New("only one of --cache-dir, --redis-address and --memcached-address may be specified")
+	}
 	upstreamURL, err := url.Parse(o.upstream)
 	if err != nil {
 		return fmt.Errorf("failed to parse upstream URL: %w", err)
 	}
 	o.upstreamParsed = upstreamURL
+	priorities, err := ghcache.ParseClientPriorities(o.clientPriorities.Strings())
+	if err != nil {
+		return fmt.Errorf("invalid --client-priority: %w", err)
+	}
+	o.clientBudgets = ghcache.ClientBudgets{
+		RequestsPerHour: map[ghcache.PriorityClass]int{
+			ghcache.PriorityHigh:   o.clientBudgetHigh,
+			ghcache.PriorityNormal: o.clientBudgetNormal,
+			ghcache.PriorityLow:    o.clientBudgetLow,
+		},
+		Priorities: priorities,
+		MaxWait:    o.clientBudgetMaxWait,
+	}
 	return nil
 }
 
@@ -142,7 +176,14 @@ func flagOptions() *options {
 	flag.StringVar(&o.dir, "cache-dir", "", "Directory to cache to if using a disk cache.")
 	flag.IntVar(&o.sizeGB, "cache-sizeGB", 0, "Cache size in GB per unique token if using a disk cache.")
 	flag.BoolVar(&o.diskCacheDisableAuthHeaderPartitioning, "legacy-disable-disk-cache-partitions-by-auth-header", true, "Whether to disable partitioning a disk cache by auth header. Disabling this will start a new cache at $cache_dir/$sha256sum_of_authorization_header for each unique authorization header. Bigger setups are advise to manually warm this up from an existing cache. This option will be removed and set to `false` in the future")
-	flag.StringVar(&o.redisAddress, "redis-address", "", "Redis address if using a redis cache e.g. localhost:6379.")
+	flag.StringVar(&o.redisAddress, "redis-address", "", "Comma-separated Redis addresses if using a redis cache shared by all replicas e.g. localhost:6379.")
+	flag.StringVar(&o.memcachedAddress, "memcached-address", "", "Comma-separated memcached addresses if using a memcached cache shared by all replicas e.g. localhost:11211.")
+	flag.DurationVar(&o.graphQLCacheTTL, "graphql-cache-ttl", 0, "How long responses to GraphQL queries are cached, GraphQL responses are not cached if zero. They cannot be revalidated, so clients may see data that is up to this old unless they send a 'Cache-Control: no-cache' header.")
+	flag.IntVar(&o.clientBudgetHigh, "client-budget-high", 0, "Requests per hour and token that each client in the high priority class may send to GitHub, unlimited if zero. Cache hits and revalidations don't count.")
+	flag.IntVar(&o.clientBudgetNormal, "client-budget-normal", 0, "Requests per hour and token that each client in the normal priority class may send to GitHub, unlimited if zero. Cache hits and revalidations don't count.")
+	flag.IntVar(&o.clientBudgetLow, "client-budget-low", 0, "Requests per hour and token that each client in the low priority class may send to GitHub, unlimited if zero. Cache hits and revalidations don't count.")
+	flag.DurationVar(&o.clientBudgetMaxWait, "client-budget-max-wait", time.Minute, "How long a request may wait for its client's budget before it is rejected with a Retry-After header.")
+	flag.Var(&o.clientPriorities, "client-priority", fmt.Sprintf("Assigns a client to a priority class in the form client=class, where class is one of %s, %s or %s. Clients are identified by the %s header or else by their token. Unlisted clients are in the normal class. Can be passed multiple times.", ghcache.PriorityHigh, ghcache.PriorityNormal, ghcache.PriorityLow, ghcache.ClientIdentifierHeader))
 	flag.IntVar(&o.port, "port", 8888, "Port to listen on.")
 	flag.StringVar(&o.upstream, "upstream", "https://api.github.com", "Scheme, host, and base path of reverse proxy upstream.")
 	flag.IntVar(&o.maxConcurrency, "concurrency", 25, "Maximum number of concurrent in-flight requests to GitHub.")
@@ -201,14 +242,26 @@ func main() {
 func proxy(o *options, upstreamTransport http.RoundTripper, diskCachePruneInterval time.Duration) http.Handler {
 	var cache http.RoundTripper
 	throttlingTimes := ghcache.NewRequestThrottlingTimes(o.requestThrottlingTime, o.requestThrottlingTimeV4, o.requestThrottlingTimeForGET, o.requestThrottlingMaxDelayTime, o.requestThrottlingMaxDelayTimeV4)
+	upstreamTransport = ghcache.NewBudgetTransport(upstreamTransport, o.clientBudgets)
+	var sharedStore ghcache.SharedStore
 	if o.redisAddress != "" {
-		cache = ghcache.NewRedisCache(apptokenequalizer.New(upstreamTransport), o.redisAddress, o.maxConcurrency, throttlingTimes)
+		sharedStore = ghcache.NewShardedStore(strings.Split(o.redisAddress, ","), ghcache.NewRedisStore)
+		cache = ghcache.NewSharedCache(apptokenequalizer.New(upstreamTransport), sharedStore, o.maxConcurrency, throttlingTimes)
+	} else if o.memcachedAddress != "" {
+		sharedStore = ghcache.NewShardedStore(strings.Split(o.memcachedAddress, ","), ghcache.NewMemcacheStore)
+		cache = ghcache.NewSharedCache(apptokenequalizer.New(upstreamTransport), sharedStore, o.maxConcurrency, throttlingTimes)
 	} else if o.dir == "" {
 		cache = ghcache.NewMemCache(apptokenequalizer.New(upstreamTransport), o.maxConcurrency, throttlingTimes)
 	} else {
 		cache = ghcache.NewDiskCache(apptokenequalizer.New(upstreamTransport), o.dir, o.sizeGB, o.maxConcurrency, o.diskCacheDisableAuthHeaderPartitioning, diskCachePruneInterval, throttlingTimes)
 		go diskMonitor(o.pushGatewayInterval, o.dir)
 	}
+	if o.graphQLCacheTTL > 0 {
+		if sharedStore == nil {
+			sharedStore = ghcache.NewMemoryStore()
+		}
+		cache = ghcache.NewGraphQLCache(cache, sharedStore, o.graphQLCacheTTL)
+	}
 
 	return newReverseProxy(o.upstreamParsed, cache, time.Duration(o.timeout)*time.Second)
 }
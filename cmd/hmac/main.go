@@ -42,7 +42,10 @@ import (
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/ghhook"
 	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/interrupts"
 	"sigs.k8s.io/prow/pkg/logrusutil"
+	"sigs.k8s.io/prow/pkg/metrics"
+	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 )
 
 type options struct {
@@ -53,10 +56,19 @@ type options struct {
 	kubernetes    prowflagutil.KubernetesOptions
 	kubeconfigCtx string
 
+	instrumentationOptions prowflagutil.InstrumentationOptions
+
 	hookUrl                  string
 	hmacTokenSecretNamespace string
 	hmacTokenSecretName      string
 	hmacTokenKey             string
+
+	// interval is how often the managed webhooks are reconciled. The tool
+	// exits after a single reconciliation if it is unset.
+	interval time.Duration
+	// secretPropagationDelay is how long to wait for an updated hmac secret
+	// to reach hook before the webhooks start signing with the new tokens.
+	secretPropagationDelay time.Duration
 }
 
 func (o *options) validate() error {
@@ -78,6 +90,9 @@ func (o *options) validate() error {
 	if o.hmacTokenKey == "" {
 		return errors.New("required flag --hmac-token-key was unset")
 	}
+	if o.interval < 0 {
+		return errors.New("--interval must not be negative")
+	}
 
 	return nil
 }
@@ -88,6 +103,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	o.config.AddFlags(fs)
 	o.github.AddFlags(fs)
 	o.kubernetes.AddFlags(fs)
+	o.instrumentationOptions.AddFlags(fs)
 
 	fs.StringVar(&o.kubeconfigCtx, "kubeconfig-context", "", "Context of the Prow component cluster and namespace in the kubeconfig.")
 	fs.BoolVar(&o.dryRun, "dry-run", true, "Dry run for testing. Uses API tokens but does not mutate.")
@@ -96,6 +112,8 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.hmacTokenSecretNamespace, "hmac-token-secret-namespace", "default", "Name of the namespace on the cluster where the hmac-token secret is in.")
 	fs.StringVar(&o.hmacTokenSecretName, "hmac-token-secret-name", "", "Name of the secret on the cluster containing the GitHub HMAC secret.")
 	fs.StringVar(&o.hmacTokenKey, "hmac-token-key", "", "Key of the hmac token in the secret.")
+	fs.DurationVar(&o.interval, "interval", 0, "If set, keep running and reconcile the managed webhooks at this interval, so that tokens are rotated and superseded tokens pruned on schedule. By default the tool exits after a single reconciliation.")
+	fs.DurationVar(&o.secretPropagationDelay, "secret-propagation-delay", 20*time.Second, "How long to wait for the updated hmac secret to propagate to hook before updating the webhooks to sign with the new tokens.")
 	fs.Parse(args)
 	return o
 }
@@ -130,16 +148,49 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Error starting config agent.")
 	}
-	newHMACConfig := configAgent.Config().ManagedWebhooks
 
 	gc, err := o.github.GitHubClient(o.dryRun)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error creating github client")
 	}
 
+	if o.interval == 0 {
+		updateErr := reconcile(kc, gc, configAgent.Config().ManagedWebhooks, o)
+		if pushGateway := configAgent.Config().PushGateway; pushGateway.Endpoint != "" {
+			if err := metrics.Push("hmac", pushGateway); err != nil {
+				logrus.WithError(err).Error("Error pushing metrics.")
+			}
+		}
+		if updateErr != nil {
+			logrus.WithError(updateErr).Fatal("Error handling hmac config update.")
+		}
+		return
+	}
+
+	defer interrupts.WaitForGracefulShutdown()
+	pprof.Instrument(o.instrumentationOptions)
+	metrics.ExposeMetrics("hmac", configAgent.Config().PushGateway, o.instrumentationOptions.MetricsPort)
+
+	// Every reconciliation reloads the config and the current tokens, so that
+	// config changes are picked up and tokens are rotated and pruned once
+	// their rotation interval and grace period pass.
+	interrupts.TickLiteral(func() {
+		start := time.Now()
+		if err := reconcile(kc, gc, configAgent.Config().ManagedWebhooks, o); err != nil {
+			logrus.WithError(err).Error("Error handling hmac config update.")
+			return
+		}
+		hmacMetrics.lastSuccessfulSync.SetToCurrentTime()
+		logrus.WithField("duration", time.Since(start)).Info("Reconciled managed webhooks")
+	}, o.interval)
+}
+
+// reconcile updates the hmac tokens, the webhooks and the hmac secret to match
+// the managed webhooks config.
+func reconcile(kc kubernetes.Interface, gc github.HookClient, newHMACConfig config.ManagedWebhooks, o options) error {
 	currentHMACYaml, err := getCurrentHMACTokens(kc, o.hmacTokenSecretNamespace, o.hmacTokenSecretName, o.hmacTokenKey)
 	if err != nil {
-		logrus.WithError(err).Fatal("Error getting the current hmac yaml.")
+		return fmt.Errorf("error getting the current hmac yaml: %w", err)
 	}
 
 	currentHMACMap := map[string]github.HMACsForRepo{}
@@ -147,7 +198,7 @@ func main() {
 		// When the token is still a single global token, respect_legacy_global_token must be set to true before running this tool.
 		// This can prevent the global token from being deleted by mistake before users migrate all repos/orgs to use auto-generated private tokens.
 		if !newHMACConfig.RespectLegacyGlobalToken {
-			logrus.Fatal("respect_legacy_global_token must be set to true before the hmac tool is run for the first time.")
+			return errors.New("respect_legacy_global_token must be set to true before the hmac tool is run for the first time")
 		}
 
 		logrus.WithError(err).Error("Couldn't unmarshal the hmac secret as hierarchical file. Parsing as a single global token and writing it back to the secret.")
@@ -170,12 +221,10 @@ func main() {
 	}
 
 	if err := c.handleInvitation(); err != nil {
-		logrus.WithError(err).Fatal("Error accepting invitations.")
+		return fmt.Errorf("error accepting invitations: %w", err)
 	}
 
-	if err := c.handleConfigUpdate(); err != nil {
-		logrus.WithError(err).Fatal("Error handling hmac config update.")
-	}
+	return c.handleConfigUpdate()
 }
 
 func (c *client) handleInvitation() error {
@@ -277,7 +326,7 @@ func (c *client) handleConfigUpdate() error {
 	}
 	// HACK: waiting for the hmac k8s secret update to propagate to the pods that are using the secret,
 	// so that components like hook can start respecting the new hmac values.
-	time.Sleep(20 * time.Second)
+	time.Sleep(c.options.secretPropagationDelay)
 	errs := c.batchOnboardNewTokenForRepos()
 
 	// Do necessary cleanups after the token and webhook updates are done.
@@ -341,12 +390,14 @@ func (c *client) handleAddedRepo(added map[string]config.ManagedWebhookInfo) err
 }
 
 func (c *client) handledRotatedRepo(rotated map[string]config.ManagedWebhookInfo) error {
-	// For each rotated repo, we only onboard a new token when none of the existing tokens is created after user specified time.
+	// For each rotated repo, we only onboard a new token when none of the existing tokens is created after user specified time,
+	// or when all of them are older than the rotation interval.
 	for repo, hmacConfig := range rotated {
 		needsRotation := true
+		interval := c.newHMACConfig.RotationIntervalFor(repo)
 		for _, token := range c.currentHMACMap[repo] {
-			// If the existing token is created after the user specified time, we do not need to rotate it.
-			if token.CreatedAt.After(hmacConfig.TokenCreatedAfter) {
+			// If the existing token is created after the user specified time and is still within the rotation interval, we do not need to rotate it.
+			if token.CreatedAt.After(hmacConfig.TokenCreatedAfter) && (interval == 0 || time.Since(token.CreatedAt) < interval) {
 				needsRotation = false
 				break
 			}
@@ -409,18 +460,34 @@ func (c *client) onboardNewTokenForRepo(repo, generatedToken string) error {
 	return o.HandleWebhookConfigChange()
 }
 
+// batchOnboardNewTokenForRepos updates the webhooks org by org, so that a
+// failure is easy to attribute and does not leave orgs half updated in a
+// random order.
 func (c *client) batchOnboardNewTokenForRepos() []error {
+	repos := make([]string, 0, len(c.hmacMapForBatchUpdate))
+	for repo := range c.hmacMapForBatchUpdate {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
 	var errs []error
-	for repo, generatedToken := range c.hmacMapForBatchUpdate {
-		if err := c.onboardNewTokenForRepo(repo, generatedToken); err != nil {
+	for _, repo := range repos {
+		org := strings.Split(repo, "/")[0]
+		if err := c.onboardNewTokenForRepo(repo, c.hmacMapForBatchUpdate[repo]); err != nil {
 			errs = append(errs, err)
-			logrus.WithError(err).Errorf("Error updating the webhook, will revert the hmacs for %q", repo)
+			hmacMetrics.rotations.WithLabelValues(repo, "failure").Inc()
+			hmacMetrics.updateFailing.WithLabelValues(repo).Set(1)
+			logrus.WithError(err).WithField("org", org).Errorf("Error updating the webhook, will revert the hmacs for %q", repo)
 			if hmacs, exist := c.hmacMapForRecovery[repo]; exist {
 				c.currentHMACMap[repo] = hmacs
 			} else {
 				delete(c.currentHMACMap, repo)
 			}
+			continue
 		}
+		hmacMetrics.rotations.WithLabelValues(repo, "success").Inc()
+		hmacMetrics.updateFailing.WithLabelValues(repo).Set(0)
+		logrus.WithField("org", org).Infof("Onboarded new hmac token for %q", repo)
 	}
 	return errs
 }
@@ -430,6 +497,7 @@ func (c *client) cleanup() error {
 	// Prune old tokens from current config.
 	for repoName := range c.currentHMACMap {
 		c.pruneOldTokens(repoName)
+		recordTokenMetrics(repoName, c.currentHMACMap[repoName])
 	}
 	// Update the secret.
 	if err := c.updateHMACTokenSecret(); err != nil {
@@ -459,7 +527,8 @@ func (c *client) updateHMACTokenSecret() error {
 	return nil
 }
 
-// pruneOldTokens removes all but most recent token from token config.
+// pruneOldTokens removes all but most recent token from token config, unless
+// the most recent token is still within the rotation grace period.
 func (c *client) pruneOldTokens(repo string) {
 	tokens := c.currentHMACMap[repo]
 	if len(tokens) <= 1 {
@@ -467,13 +536,32 @@ func (c *client) pruneOldTokens(repo string) {
 		return
 	}
 
-	logrus.WithField("repo", repo).Debugf("Token size is %d, prune to 1", len(tokens))
 	sort.SliceStable(tokens, func(i, j int) bool {
 		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
 	})
+	if gracePeriod := c.newHMACConfig.GracePeriod(); time.Since(tokens[0].CreatedAt) < gracePeriod {
+		logrus.WithField("repo", repo).Debugf("Newest token is within the grace period of %s, not pruning", gracePeriod)
+		return
+	}
+	logrus.WithField("repo", repo).Debugf("Token size is %d, prune to 1", len(tokens))
 	c.currentHMACMap[repo] = tokens[:1]
 }
 
+// recordTokenMetrics exposes the age of the newest token and the number of
+// accepted tokens for the repo.
+func recordTokenMetrics(repo string, tokens github.HMACsForRepo) {
+	hmacMetrics.tokens.WithLabelValues(repo).Set(float64(len(tokens)))
+	var newest time.Time
+	for _, token := range tokens {
+		if token.CreatedAt.After(newest) {
+			newest = token.CreatedAt
+		}
+	}
+	if !newest.IsZero() {
+		hmacMetrics.tokenAge.WithLabelValues(repo).Set(time.Since(newest).Seconds())
+	}
+}
+
 // generateNewHMACToken generates a hex encoded crypto random string of length 40.
 func generateNewHMACToken() (string, error) {
 	bytes := make([]byte, 20) // 20 bytes of entropy will result in a string of length 40 after hex encoding
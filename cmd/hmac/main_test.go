@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"sigs.k8s.io/prow/cmd/hmac/fakeghhook"
@@ -62,6 +63,22 @@ func TestGatherOptions(t *testing.T) {
 				o.dryRun = false
 			},
 		},
+		{
+			name: "run as a controller with --interval",
+			args: map[string]string{
+				"--interval": "1h",
+			},
+			expected: func(o *options) {
+				o.interval = time.Hour
+			},
+		},
+		{
+			name: "negative --interval is rejected",
+			args: map[string]string{
+				"--interval": "-1h",
+			},
+			err: true,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -83,6 +100,8 @@ func TestGatherOptions(t *testing.T) {
 				hmacTokenSecretNamespace: "default",
 				hmacTokenSecretName:      "hmac-token",
 				hmacTokenKey:             "hmac",
+				instrumentationOptions:   flagutil.DefaultInstrumentationOptions(),
+				secretPropagationDelay:   20 * time.Second,
 			}
 			if tc.expected != nil {
 				tc.expected(expected)
@@ -127,9 +146,11 @@ func TestPruneOldTokens(t *testing.T) {
 	time1, _ := time.Parse(time.RFC3339, "2020-01-05T19:07:08+00:00")
 	time2, _ := time.Parse(time.RFC3339, "2020-02-05T19:07:08+00:00")
 	time3, _ := time.Parse(time.RFC3339, "2020-03-05T19:07:08+00:00")
+	now := time.Now()
 
 	cases := []struct {
 		name     string
+		config   config.ManagedWebhooks
 		current  map[string]github.HMACsForRepo
 		repo     string
 		expected map[string]github.HMACsForRepo
@@ -206,11 +227,65 @@ func TestPruneOldTokens(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "nothing is pruned while the newest token is within the grace period",
+			config: config.ManagedWebhooks{RotationGracePeriod: &metav1.Duration{Duration: time.Hour}},
+			current: map[string]github.HMACsForRepo{
+				"org1/repo1": []github.HMACToken{
+					{
+						Value:     "rand-val1",
+						CreatedAt: time1,
+					},
+					{
+						Value:     "rand-val2",
+						CreatedAt: now,
+					},
+				},
+			},
+			repo: "org1/repo1",
+			expected: map[string]github.HMACsForRepo{
+				"org1/repo1": []github.HMACToken{
+					{
+						Value:     "rand-val2",
+						CreatedAt: now,
+					},
+					{
+						Value:     "rand-val1",
+						CreatedAt: time1,
+					},
+				},
+			},
+		},
+		{
+			name:   "old tokens are pruned once the grace period is over",
+			config: config.ManagedWebhooks{RotationGracePeriod: &metav1.Duration{Duration: time.Hour}},
+			current: map[string]github.HMACsForRepo{
+				"org1/repo1": []github.HMACToken{
+					{
+						Value:     "rand-val1",
+						CreatedAt: time1,
+					},
+					{
+						Value:     "rand-val2",
+						CreatedAt: time2,
+					},
+				},
+			},
+			repo: "org1/repo1",
+			expected: map[string]github.HMACsForRepo{
+				"org1/repo1": []github.HMACToken{
+					{
+						Value:     "rand-val2",
+						CreatedAt: time2,
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			c := &client{currentHMACMap: tc.current}
+			c := &client{currentHMACMap: tc.current, newHMACConfig: tc.config}
 			c.pruneOldTokens(tc.repo)
 			if !reflect.DeepEqual(tc.expected, c.currentHMACMap) {
 				t.Errorf("%#v != expected %#v", c.currentHMACMap, tc.expected)
@@ -434,6 +509,7 @@ func TestHandleRotatedRepo(t *testing.T) {
 
 	cases := []struct {
 		name                         string
+		config                       config.ManagedWebhooks
 		toRotate                     map[string]config.ManagedWebhookInfo
 		currentHMACs                 map[string]github.HMACsForRepo
 		currentHMACMapForBatchUpdate map[string]string
@@ -510,11 +586,50 @@ func TestHandleRotatedRepo(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "test a repo whose token is older than the rotation interval",
+			config: config.ManagedWebhooks{
+				RotationInterval: &metav1.Duration{Duration: 24 * time.Hour},
+				OrgRepoConfig: map[string]config.ManagedWebhookInfo{
+					"repo2": {TokenCreatedAfter: pastTime, RotationInterval: &metav1.Duration{Duration: 24 * 365 * 100 * time.Hour}},
+				},
+			},
+			toRotate: map[string]config.ManagedWebhookInfo{
+				"repo1": {TokenCreatedAfter: pastTime},
+				"repo2": {TokenCreatedAfter: pastTime},
+			},
+			currentHMACs: map[string]github.HMACsForRepo{
+				"repo1": []github.HMACToken{
+					{
+						Value:     "rand-val1",
+						CreatedAt: pastTime.Add(1 * time.Hour),
+					},
+				},
+				"repo2": []github.HMACToken{
+					{
+						Value:     "rand-val2",
+						CreatedAt: pastTime.Add(1 * time.Hour),
+					},
+				},
+			},
+			currentHMACMapForBatchUpdate: map[string]string{},
+			expectedHMACsSize:            map[string]int{"repo1": 2, "repo2": 1},
+			expectedReposForBatchUpdate:  []string{"repo1"},
+			expectedHMACMapForRecovery: map[string]github.HMACsForRepo{
+				"repo1": []github.HMACToken{
+					{
+						Value:     "rand-val1",
+						CreatedAt: pastTime.Add(1 * time.Hour),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			c := &client{
+				newHMACConfig:         tc.config,
 				currentHMACMap:        tc.currentHMACs,
 				hmacMapForBatchUpdate: tc.currentHMACMapForBatchUpdate,
 				hmacMapForRecovery:    map[string]github.HMACsForRepo{},
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var hmacMetrics = struct {
+	tokenAge  *prometheus.GaugeVec
+	tokens    *prometheus.GaugeVec
+	rotations *prometheus.CounterVec
+	// updateFailing is meant to be alerted on, it stays set until the
+	// webhook of the repo/org is updated successfully.
+	updateFailing      *prometheus.GaugeVec
+	lastSuccessfulSync prometheus.Gauge
+}{
+	tokenAge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hmac_token_age_seconds",
+		Help: "Age of the newest HMAC token of a managed repo/org.",
+	}, []string{
+		"org_repo",
+	}),
+	tokens: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hmac_tokens",
+		Help: "Number of HMAC tokens accepted for a managed repo/org. More than one token means a rotation is within its grace period.",
+	}, []string{
+		"org_repo",
+	}),
+	rotations: prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hmac_token_rotations_total",
+		Help: "Number of HMAC token rotations of a managed repo/org by result.",
+	}, []string{
+		"org_repo",
+		"result",
+	}),
+	updateFailing: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hmac_webhook_update_failing",
+		Help: "Whether the last update of the webhook of a managed repo/org to a new HMAC token failed.",
+	}, []string{
+		"org_repo",
+	}),
+	lastSuccessfulSync: prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "hmac_last_successful_sync_timestamp_seconds",
+		Help: "Time of the last reconciliation of the managed webhooks without errors.",
+	}),
+}
+
+func init() {
+	prometheus.MustRegister(hmacMetrics.tokenAge)
+	prometheus.MustRegister(hmacMetrics.tokens)
+	prometheus.MustRegister(hmacMetrics.rotations)
+	prometheus.MustRegister(hmacMetrics.updateFailing)
+	prometheus.MustRegister(hmacMetrics.lastSuccessfulSync)
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -34,12 +38,14 @@ import (
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	pluginsflagutil "sigs.k8s.io/prow/pkg/flagutil/plugins"
+	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/githubeventserver"
 	"sigs.k8s.io/prow/pkg/hook"
 	"sigs.k8s.io/prow/pkg/interrupts"
 	jiraclient "sigs.k8s.io/prow/pkg/jira"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/metrics"
+	"sigs.k8s.io/prow/pkg/moonraker"
 	"sigs.k8s.io/prow/pkg/pjutil"
 	pluginhelp "sigs.k8s.io/prow/pkg/pluginhelp/hook"
 	"sigs.k8s.io/prow/pkg/plugins"
@@ -49,11 +55,23 @@ import (
 	"sigs.k8s.io/prow/pkg/repoowners"
 	"sigs.k8s.io/prow/pkg/slack"
 
+	"sigs.k8s.io/prow/pkg/tracing"
 	_ "sigs.k8s.io/prow/pkg/version"
 )
 
 const (
-	defaultWebhookPath = "/hook"
+	defaultWebhookPath          = "/hook"
+	defaultGitLabWebhookPath    = "/gitlab-hook"
+	defaultBitbucketWebhookPath = "/bitbucket-hook"
+)
+
+const (
+	// modeAll receives and handles events in the same process.
+	modeAll = "all"
+	// modeReceiver only validates events and enqueues them.
+	modeReceiver = "receiver"
+	// modeWorker only handles the events in the queue.
+	modeWorker = "worker"
 )
 
 type options struct {
@@ -70,18 +88,69 @@ type options struct {
 	githubEnablement       prowflagutil.GitHubEnablementOptions
 	bugzilla               prowflagutil.BugzillaOptions
 	instrumentationOptions prowflagutil.InstrumentationOptions
+	tracing                prowflagutil.TracingOptions
 	jira                   prowflagutil.JiraOptions
+	gitlab                 prowflagutil.GitLabOptions
+	bitbucket              prowflagutil.BitbucketOptions
 
 	webhookSecretFile string
 	slackTokenFile    string
+
+	gitlabWebhookPath       string
+	gitlabWebhookSecretFile string
+
+	bitbucketWebhookPath       string
+	bitbucketWebhookSecretFile string
+
+	eventStorePath      string
+	eventStoreRetention time.Duration
+	eventStoreAdminPort int
+
+	auditSink string
+	storage   prowflagutil.StorageClientOptions
+
+	mode                    string
+	queuePubSubProject      string
+	queuePubSubTopic        string
+	queuePubSubSubscription string
+}
+
+// queueEnabled returns whether GitHub events go through the event queue.
+func (o *options) queueEnabled() bool {
+	return o.queuePubSubProject != ""
 }
 
 func (o *options) Validate() error {
-	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.bugzilla, &o.jira, &o.githubEnablement, &o.config, &o.pluginsConfig} {
+	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.bugzilla, &o.jira, &o.gitlab, &o.bitbucket, &o.githubEnablement, &o.config, &o.pluginsConfig, &o.tracing} {
 		if err := group.Validate(o.dryRun); err != nil {
 			return err
 		}
 	}
+	if o.gitlab.Enabled() && o.gitlabWebhookSecretFile == "" {
+		return errors.New("--gitlab-webhook-secret-file is required when --gitlab-endpoint is set")
+	}
+	if o.bitbucket.Enabled() && o.bitbucketWebhookSecretFile == "" {
+		return errors.New("--bitbucket-webhook-secret-file is required when --bitbucket-endpoint or --bitbucket-cloud is set")
+	}
+	if o.eventStorePath != "" && o.eventStoreRetention <= 0 {
+		return errors.New("--event-store-retention must be positive")
+	}
+	switch o.mode {
+	case modeAll:
+		if o.queueEnabled() && (o.queuePubSubTopic == "" || o.queuePubSubSubscription == "") {
+			return errors.New("--queue-pubsub-topic and --queue-pubsub-subscription are required when --queue-pubsub-project is set")
+		}
+	case modeReceiver:
+		if !o.queueEnabled() || o.queuePubSubTopic == "" {
+			return errors.New("--queue-pubsub-project and --queue-pubsub-topic are required in receiver mode")
+		}
+	case modeWorker:
+		if !o.queueEnabled() || o.queuePubSubSubscription == "" {
+			return errors.New("--queue-pubsub-project and --queue-pubsub-subscription are required in worker mode")
+		}
+	default:
+		return fmt.Errorf("invalid --mode %q, must be one of %q, %q or %q", o.mode, modeAll, modeReceiver, modeWorker)
+	}
 
 	return nil
 }
@@ -94,12 +163,25 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.BoolVar(&o.dryRun, "dry-run", true, "Dry run for testing. Uses API tokens but does not mutate.")
 	fs.DurationVar(&o.gracePeriod, "grace-period", 180*time.Second, "On shutdown, try to handle remaining events for the specified duration. ")
 	o.pluginsConfig.PluginConfigPathDefault = "/etc/plugins/plugins.yaml"
-	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.bugzilla, &o.instrumentationOptions, &o.jira, &o.githubEnablement, &o.config, &o.pluginsConfig} {
+	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.bugzilla, &o.instrumentationOptions, &o.tracing, &o.jira, &o.gitlab, &o.bitbucket, &o.githubEnablement, &o.config, &o.pluginsConfig} {
 		group.AddFlags(fs)
 	}
 
 	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
 	fs.StringVar(&o.slackTokenFile, "slack-token-file", "", "Path to the file containing the Slack token to use.")
+	fs.StringVar(&o.gitlabWebhookPath, "gitlab-webhook-path", defaultGitLabWebhookPath, "The path of GitLab webhook events, only served if --gitlab-endpoint is set.")
+	fs.StringVar(&o.gitlabWebhookSecretFile, "gitlab-webhook-secret-file", "", "Path to the file containing the secret token configured on GitLab webhooks.")
+	fs.StringVar(&o.bitbucketWebhookPath, "bitbucket-webhook-path", defaultBitbucketWebhookPath, "The path of Bitbucket webhook events, only served if --bitbucket-endpoint or --bitbucket-cloud is set.")
+	fs.StringVar(&o.bitbucketWebhookSecretFile, "bitbucket-webhook-secret-file", "", "Path to the file containing the secret Bitbucket webhooks are signed with.")
+	fs.StringVar(&o.eventStorePath, "event-store-path", "", "gs:// or s3:// path to persist validated GitHub webhook events below so they can be replayed. Disabled if empty.")
+	fs.DurationVar(&o.eventStoreRetention, "event-store-retention", 72*time.Hour, "How long to keep stored events for.")
+	fs.IntVar(&o.eventStoreAdminPort, "event-store-admin-port", 8889, "Port to serve the event store admin API on. It is only served on localhost.")
+	fs.StringVar(&o.auditSink, "audit-sink", "", "Where to record the write actions plugins take: 'log' to log them, an http(s):// URL to post records to, or a gs://, s3:// or local path to write them below. Records have the format of --github-client.audit-sink. Disabled if empty.")
+	fs.StringVar(&o.mode, "mode", modeAll, "Whether to receive GitHub webhook events and handle them (all), only validate and enqueue them (receiver) or only handle the enqueued events (worker).")
+	fs.StringVar(&o.queuePubSubProject, "queue-pubsub-project", "", "GCP project of the Pub/Sub topic and subscription used as durable queue of GitHub webhook events. Events are handled directly if empty.")
+	fs.StringVar(&o.queuePubSubTopic, "queue-pubsub-topic", "", "Pub/Sub topic that receivers publish GitHub webhook events to.")
+	fs.StringVar(&o.queuePubSubSubscription, "queue-pubsub-subscription", "", "Pub/Sub subscription of the --queue-pubsub-topic that workers receive GitHub webhook events from.")
+	o.storage.AddFlags(fs)
 	fs.Parse(args)
 	return o
 }
@@ -138,6 +220,14 @@ func main() {
 		tokens = append(tokens, o.bugzilla.ApiKeyPath)
 	}
 
+	if o.gitlabWebhookSecretFile != "" {
+		tokens = append(tokens, o.gitlabWebhookSecretFile)
+	}
+
+	if o.bitbucketWebhookSecretFile != "" {
+		tokens = append(tokens, o.bitbucketWebhookSecretFile)
+	}
+
 	if err := secret.Add(tokens...); err != nil {
 		logrus.WithError(err).Fatal("Error starting secrets agent.")
 	}
@@ -221,7 +311,10 @@ func main() {
 	resolver := func(org, repo string) ownersconfig.Filenames {
 		return pluginAgent.Config().OwnersFilenames(org, repo)
 	}
-	ownersClient := repoowners.NewClient(gitClient, githubClient, mdYAMLEnabled, skipCollaborators, ownersDirDenylist, resolver)
+	externalProvider := func(org, repo string) string {
+		return pluginAgent.Config().OwnersExternalProvider(org, repo)
+	}
+	ownersClient := repoowners.NewClient(gitClient, githubClient, mdYAMLEnabled, skipCollaborators, ownersDirDenylist, resolver, externalProvider)
 
 	clientAgent := &plugins.ClientAgent{
 		GitHubClient:              githubClient,
@@ -234,6 +327,13 @@ func main() {
 		BugzillaClient:            bugzillaClient,
 		JiraClient:                jiraClient,
 	}
+	if o.config.MoonrakerEnabled() {
+		moonrakerClient, err := moonraker.NewInRepoConfigGetter(o.config.MoonrakerAddress, o.config.MoonrakerGRPCAddress, configAgent)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error getting Moonraker client.")
+		}
+		clientAgent.InRepoConfigGetter = moonrakerClient
+	}
 
 	promMetrics := githubeventserver.NewMetrics()
 
@@ -242,6 +342,15 @@ func main() {
 	// Expose prometheus metrics
 	metrics.ExposeMetrics("hook", configAgent.Config().PushGateway, o.instrumentationOptions.MetricsPort)
 	pprof.Instrument(o.instrumentationOptions)
+	shutdownTracing, err := tracing.Setup(context.Background(), "hook", o.tracing)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error setting up tracing.")
+	}
+	interrupts.OnInterrupt(func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.WithError(err).Warn("Failed to flush traces.")
+		}
+	})
 
 	server := &hook.Server{
 		ClientAgent:    clientAgent,
@@ -251,24 +360,130 @@ func main() {
 		RepoEnabled:    o.githubEnablement.EnablementChecker(),
 		TokenGenerator: secret.GetTokenGenerator(o.webhookSecretFile),
 	}
+	if o.eventStorePath != "" {
+		opener, err := o.storage.StorageClient(context.Background())
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating opener for event store.")
+		}
+		eventStore, err := hook.NewStorageEventStore(opener, o.eventStorePath)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating event store.")
+		}
+		server.EventStore = eventStore
+		interrupts.TickLiteral(func() { server.PruneEvents(o.eventStoreRetention) }, time.Hour)
+		// The admin API is unauthenticated, so it is only reachable from within
+		// the pod, e.g. through kubectl port-forward.
+		adminServer := &http.Server{Addr: "127.0.0.1:" + strconv.Itoa(o.eventStoreAdminPort), Handler: server.EventStoreAdminHandler()}
+		interrupts.ListenAndServe(adminServer, 5*time.Second)
+	}
+	if o.auditSink != "" {
+		if o.auditSink == "log" {
+			server.AuditSink = github.NewLogAuditSink(logrus.WithField("client", "plugins"))
+		} else if strings.HasPrefix(o.auditSink, "http://") || strings.HasPrefix(o.auditSink, "https://") {
+			server.AuditSink = hook.NewHTTPAuditSink(o.auditSink)
+		} else {
+			opener, err := o.storage.StorageClient(context.Background())
+			if err != nil {
+				logrus.WithError(err).Fatal("Error creating opener for audit sink.")
+			}
+			server.AuditSink = hook.NewStorageAuditSink(opener, o.auditSink)
+		}
+	}
+	if o.queueEnabled() {
+		topic, subscription := o.queuePubSubTopic, o.queuePubSubSubscription
+		if o.mode == modeReceiver {
+			subscription = ""
+		} else if o.mode == modeWorker {
+			topic = ""
+		}
+		queue, err := hook.NewPubSubEventQueue(context.Background(), o.queuePubSubProject, topic, subscription)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating event queue.")
+		}
+		server.Queue = queue
+		if o.mode != modeReceiver {
+			interrupts.Run(func(ctx context.Context) {
+				if err := server.ProcessQueue(ctx); err != nil {
+					logrus.WithError(err).Fatal("Error processing event queue.")
+				}
+			})
+		}
+	}
+	var gitlabServer *hook.GitLabServer
+	if o.gitlab.Enabled() {
+		gitlabClient, err := o.gitlab.GitLabClient(o.dryRun)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error getting GitLab client.")
+		}
+		gitlabServer = &hook.GitLabServer{
+			ClientAgent:    clientAgent,
+			GitLabClient:   gitlabClient,
+			ConfigAgent:    configAgent,
+			Plugins:        pluginAgent,
+			Metrics:        promMetrics,
+			RepoEnabled:    o.githubEnablement.EnablementChecker(),
+			TokenGenerator: secret.GetTokenGenerator(o.gitlabWebhookSecretFile),
+		}
+	}
+	var bitbucketServer *hook.BitbucketServer
+	if o.bitbucket.Enabled() {
+		bitbucketClient, err := o.bitbucket.BitbucketClient(o.dryRun)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error getting Bitbucket client.")
+		}
+		bitbucketServer = &hook.BitbucketServer{
+			ClientAgent:     clientAgent,
+			BitbucketClient: bitbucketClient,
+			ConfigAgent:     configAgent,
+			Plugins:         pluginAgent,
+			Metrics:         promMetrics,
+			RepoEnabled:     o.githubEnablement.EnablementChecker(),
+			TokenGenerator:  secret.GetTokenGenerator(o.bitbucketWebhookSecretFile),
+			Cloud:           o.bitbucket.Cloud,
+		}
+	}
 	interrupts.OnInterrupt(func() {
 		server.GracefulShutdown()
+		if server.Queue != nil {
+			if err := server.Queue.Close(); err != nil {
+				logrus.WithError(err).Warn("Failed to close event queue.")
+			}
+		}
+		if gitlabServer != nil {
+			gitlabServer.GracefulShutdown()
+		}
+		if bitbucketServer != nil {
+			bitbucketServer.GracefulShutdown()
+		}
 		if err := gitClient.Clean(); err != nil {
 			logrus.WithError(err).Error("Could not clean up git client cache.")
 		}
 	})
 
 	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(configAgent.Config), pjutil.SecretsHealthCheck(tokens...))
+	health.AddReadinessChecks(pjutil.KubernetesHealthCheck(infrastructureClient.Discovery().RESTClient()), pjutil.GitHubHealthCheck(githubClient))
 
 	hookMux := http.NewServeMux()
 	// TODO remove this health endpoint when the migration to health endpoint is done
 	// Return 200 on / for health checks.
 	hookMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
 
-	// For /hook, handle a webhook normally.
-	hookMux.Handle(o.webhookPath, server)
+	// For /hook, handle a webhook normally. Workers only handle the events
+	// in the queue.
+	if o.mode != modeWorker {
+		hookMux.Handle(o.webhookPath, server)
+	}
+	// For /gitlab-hook, handle a GitLab webhook.
+	if gitlabServer != nil {
+		hookMux.Handle(o.gitlabWebhookPath, gitlabServer)
+	}
+	// For /bitbucket-hook, handle a Bitbucket Server webhook.
+	if bitbucketServer != nil {
+		hookMux.Handle(o.bitbucketWebhookPath, bitbucketServer)
+	}
 	// Serve plugin help information from /plugin-help.
-	hookMux.Handle("/plugin-help", pluginhelp.NewHelpAgent(pluginAgent, githubClient))
+	hookMux.Handle("/plugin-help", pluginhelp.NewHelpAgent(pluginAgent, githubClient, configAgent.Config))
 
 	httpServer := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: hookMux}
 
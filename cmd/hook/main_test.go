@@ -77,6 +77,43 @@ func Test_gatherOptions(t *testing.T) {
 				o.webhookPath = "/random/hook"
 			},
 		},
+		{
+			name: "receiver mode with a queue",
+			args: map[string]string{
+				"--mode":                 "receiver",
+				"--queue-pubsub-project": "project",
+				"--queue-pubsub-topic":   "topic",
+			},
+			expected: func(o *options) {
+				o.mode = "receiver"
+				o.queuePubSubProject = "project"
+				o.queuePubSubTopic = "topic"
+			},
+		},
+		{
+			name: "worker mode requires a subscription",
+			args: map[string]string{
+				"--mode":                 "worker",
+				"--queue-pubsub-project": "project",
+				"--queue-pubsub-topic":   "topic",
+			},
+			err: true,
+		},
+		{
+			name: "all mode with a queue requires a topic and subscription",
+			args: map[string]string{
+				"--queue-pubsub-project": "project",
+				"--queue-pubsub-topic":   "topic",
+			},
+			err: true,
+		},
+		{
+			name: "invalid mode",
+			args: map[string]string{
+				"--mode": "everything",
+			},
+			err: true,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -99,6 +136,11 @@ func Test_gatherOptions(t *testing.T) {
 				gracePeriod:            180 * time.Second,
 				webhookSecretFile:      "/etc/webhook/hmac",
 				instrumentationOptions: flagutil.DefaultInstrumentationOptions(),
+				gitlabWebhookPath:      "/gitlab-hook",
+				bitbucketWebhookPath:   "/bitbucket-hook",
+				eventStoreRetention:    72 * time.Hour,
+				eventStoreAdminPort:    8889,
+				mode:                   "all",
 			}
 			expectedfs := flag.NewFlagSet("fake-flags", flag.PanicOnError)
 			expected.github.AddFlags(expectedfs)
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
@@ -35,10 +36,12 @@ import (
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/interrupts"
+	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/metrics"
 	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pjutil/pprof"
+	"sigs.k8s.io/prow/pkg/prowjobcache"
 )
 
 const (
@@ -88,18 +91,30 @@ func main() {
 
 	defer interrupts.WaitForGracefulShutdown()
 
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
 	pprof.Instrument(o.instrumentationOptions)
 
 	configAgent, err := o.config.ConfigAgent()
 	if err != nil {
 		logrus.WithError(err).Fatal("Error starting config agent.")
 	}
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(configAgent.Config))
 
 	cfg, err := o.kubernetes.InfrastructureClusterConfig(o.dryRun)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to get prowjob kubeconfig")
 	}
-	cluster, err := cluster.New(cfg, func(o *cluster.Options) { o.Namespace = configAgent.Config().ProwJobNamespace })
+	infrastructureClient, err := o.kubernetes.InfrastructureClusterClient(o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Kubernetes client for infrastructure cluster.")
+	}
+	health.AddReadinessChecks(pjutil.KubernetesHealthCheck(infrastructureClient.Discovery().RESTClient()))
+	// Only periodic ProwJobs are needed, so the others are not cached.
+	periodicSelector := labels.SelectorFromSet(labels.Set{kube.ProwJobTypeLabel: string(prowapi.PeriodicJob)})
+	cluster, err := cluster.New(cfg, func(o *cluster.Options) {
+		o.Namespace = configAgent.Config().ProwJobNamespace
+		o.NewCache = prowjobcache.NewCacheFunc(periodicSelector)
+	})
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to construct prowjob client")
 	}
@@ -126,6 +141,7 @@ func main() {
 	cr.Start()
 
 	metrics.ExposeMetrics("horologium", configAgent.Config().PushGateway, o.instrumentationOptions.MetricsPort)
+	health.ServeReady()
 
 	tickInterval := defaultTickInterval
 	if configAgent.Config().Horologium.TickInterval != nil {
@@ -211,7 +227,7 @@ func sync(prowJobClient ctrlruntimeclient.Client, cfg *config.Config, cr cronCli
 			}).WithFields(
 				pjutil.ProwJobFields(&prowJob),
 			).Info("Triggering new run.")
-			if err := prowJobClient.Create(context.TODO(), &prowJob); err != nil {
+			if err := pjutil.CreateProwJobWithClient(context.TODO(), prowJobClient, &prowJob); err != nil {
 				errs = append(errs, err)
 			}
 		}
@@ -0,0 +1,451 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// label_sync syncs the labels of GitHub repos with a labels.yaml file.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/prow/pkg/flagutil"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/logrusutil"
+)
+
+const (
+	defaultTokens = 300
+	defaultBurst  = 100
+
+	outputJSON = "json"
+)
+
+// Configuration is the labels.yaml file. The labels of a repo are the default
+// labels, overridden by the labels of its org, overridden by the labels of the
+// repo. Labels are matched by name, ignoring case, like GitHub does.
+type Configuration struct {
+	Default LabelsConfig `json:"default"`
+	// Orgs holds the labels of all repos of an org, keyed by org.
+	Orgs map[string]OrgConfig `json:"orgs,omitempty"`
+	// Repos holds the labels of a single repo, keyed by org/repo.
+	Repos map[string]RepoConfig `json:"repos,omitempty"`
+}
+
+// LabelsConfig holds the default labels.
+type LabelsConfig struct {
+	Labels []Label `json:"labels,omitempty"`
+}
+
+// OrgConfig holds the labels of all repos of an org.
+type OrgConfig struct {
+	Labels []Label `json:"labels,omitempty"`
+	// ExcludedRepos are not synced.
+	ExcludedRepos []string `json:"excludedRepos,omitempty"`
+}
+
+// RepoConfig holds the labels of a repo.
+type RepoConfig struct {
+	Labels []Label `json:"labels,omitempty"`
+	// ExcludedLabels are default or org labels that are not synced to the
+	// repo.
+	ExcludedLabels []string `json:"excludedLabels,omitempty"`
+}
+
+// Label is a label of a repo.
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description,omitempty"`
+	// Previously lists the former names of the label. A label with a former
+	// name is renamed, or when the label exists already, its issues and pull
+	// requests are migrated to the label and it is deleted.
+	Previously []string `json:"previously,omitempty"`
+}
+
+var colorRegex = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// LabelsFor returns the labels of the repo, sorted by name.
+func (c *Configuration) LabelsFor(org, repo string) []Label {
+	labels := map[string]Label{}
+	add := func(ls []Label) {
+		for _, l := range ls {
+			labels[strings.ToLower(l.Name)] = l
+		}
+	}
+	add(c.Default.Labels)
+	add(c.Orgs[org].Labels)
+	repoConfig := c.Repos[org+"/"+repo]
+	add(repoConfig.Labels)
+	for _, name := range repoConfig.ExcludedLabels {
+		delete(labels, strings.ToLower(name))
+	}
+
+	var result []Label
+	for _, l := range labels {
+		result = append(result, l)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Excluded returns whether the repo must not be synced.
+func (c *Configuration) Excluded(org, repo string) bool {
+	return sets.New[string](c.Orgs[org].ExcludedRepos...).Has(repo)
+}
+
+// Validate returns an error if the labels of a configured level are invalid,
+// or if a name is used by more than one label of an org or repo, including
+// former names.
+func (c *Configuration) Validate() error {
+	var errs []error
+	for key := range c.Repos {
+		if parts := strings.Split(key, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errs = append(errs, fmt.Errorf("repos: %q is not of the form org/repo", key))
+		}
+	}
+	if err := validateLabels(c.Default.Labels); err != nil {
+		errs = append(errs, fmt.Errorf("default: %w", err))
+	}
+	for org := range c.Orgs {
+		if err := validateLabels(c.LabelsFor(org, "")); err != nil {
+			errs = append(errs, fmt.Errorf("orgs[%s]: %w", org, err))
+		}
+	}
+	for key := range c.Repos {
+		org, repo, _ := strings.Cut(key, "/")
+		if err := validateLabels(c.LabelsFor(org, repo)); err != nil {
+			errs = append(errs, fmt.Errorf("repos[%s]: %w", key, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateLabels(labels []Label) error {
+	var errs []error
+	seen := sets.New[string]()
+	use := func(name string) {
+		if seen.Has(strings.ToLower(name)) {
+			errs = append(errs, fmt.Errorf("name %q is used more than once", name))
+		}
+		seen.Insert(strings.ToLower(name))
+	}
+	for _, l := range labels {
+		if l.Name == "" {
+			errs = append(errs, errors.New("label has no name"))
+			continue
+		}
+		if !colorRegex.MatchString(l.Color) {
+			errs = append(errs, fmt.Errorf("label %q: color %q is not a six digit hex code", l.Name, l.Color))
+		}
+		use(l.Name)
+		for _, name := range l.Previously {
+			use(name)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+type options struct {
+	config  string
+	confirm bool
+	orgs    flagutil.Strings
+	only    flagutil.Strings
+	output  string
+	github  flagutil.GitHubOptions
+}
+
+func parseOptions() options {
+	var o options
+	if err := o.parseArgs(flag.CommandLine, os.Args[1:]); err != nil {
+		logrus.Fatalf("Invalid flags: %v", err)
+	}
+	return o
+}
+
+func (o *options) parseArgs(flags *flag.FlagSet, args []string) error {
+	o.orgs = flagutil.NewStrings()
+	o.only = flagutil.NewStrings()
+	flags.StringVar(&o.config, "config-path", "", "Path to labels.yaml")
+	flags.BoolVar(&o.confirm, "confirm", false, "Mutate github if set")
+	flags.Var(&o.orgs, "orgs", "Sync all repos of this org, can be passed multiple times")
+	flags.Var(&o.only, "only", "Sync only this org/repo, can be passed multiple times")
+	flags.StringVar(&o.output, "output", "", "Print the report of the changes made, or that would be made without --confirm, and of the labels not in the config to stdout in this format. Only json is supported")
+	o.github.AddCustomizedFlags(flags, flagutil.ThrottlerDefaults(defaultTokens, defaultBurst))
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := o.github.Validate(!o.confirm); err != nil {
+		return err
+	}
+	if o.config == "" {
+		return errors.New("--config-path is required")
+	}
+	if len(o.orgs.Strings()) == 0 && len(o.only.Strings()) == 0 {
+		return errors.New("--orgs or --only is required")
+	}
+	for _, orgRepo := range o.only.Strings() {
+		if parts := strings.Split(orgRepo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("--only=%s is not of the form org/repo", orgRepo)
+		}
+	}
+	if o.output != "" && o.output != outputJSON {
+		return fmt.Errorf("--output=%s is not supported, only json is", o.output)
+	}
+	return nil
+}
+
+func main() {
+	logrusutil.ComponentInit()
+
+	o := parseOptions()
+
+	raw, err := os.ReadFile(o.config)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not read --config-path file")
+	}
+	var cfg Configuration
+	if err := yaml.UnmarshalStrict(raw, &cfg); err != nil {
+		logrus.WithError(err).Fatal("Failed to load configuration")
+	}
+	if err := cfg.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid configuration")
+	}
+
+	githubClient, err := o.github.GitHubClient(!o.confirm)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting GitHub client.")
+	}
+
+	repos, err := listRepos(githubClient, &cfg, o.orgs.Strings(), o.only.Strings())
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to list repos")
+	}
+	changes, err := syncRepos(githubClient, &cfg, repos)
+	if o.output == outputJSON {
+		out, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to marshal changes.")
+		}
+		fmt.Println(string(out))
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to sync labels")
+	}
+	logrus.Info("Finished syncing labels.")
+}
+
+type client interface {
+	GetRepos(org string, isUser bool) ([]github.Repo, error)
+	GetRepoLabels(org, repo string) ([]github.Label, error)
+	AddRepoLabel(org, repo, label, description, color string) error
+	UpdateRepoLabel(org, repo, label, newName, description, color string) error
+	DeleteRepoLabel(org, repo, label string) error
+	FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error)
+	AddLabel(org, repo string, number int, label string) error
+}
+
+// listRepos returns the org/repos to sync: the unarchived repos of the orgs
+// and the only repos, minus the excluded ones.
+func listRepos(c client, cfg *Configuration, orgs, only []string) ([]string, error) {
+	repos := sets.New[string]()
+	for _, org := range orgs {
+		orgRepos, err := c.GetRepos(org, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repos of %s: %w", org, err)
+		}
+		for _, repo := range orgRepos {
+			if !repo.Archived {
+				repos.Insert(org + "/" + repo.Name)
+			}
+		}
+	}
+	repos.Insert(only...)
+	for _, orgRepo := range sets.List(repos) {
+		org, repo, _ := strings.Cut(orgRepo, "/")
+		if cfg.Excluded(org, repo) {
+			repos.Delete(orgRepo)
+		}
+	}
+	return sets.List(repos), nil
+}
+
+// Actions of changes.
+const (
+	actionCreate  = "create"
+	actionUpdate  = "update"
+	actionRename  = "rename"
+	actionMigrate = "migrate"
+	// actionUnmanaged reports a label that is not in the config. It is
+	// left alone.
+	actionUnmanaged = "unmanaged"
+)
+
+// change is a single entry of the reconciliation report: a change label_sync
+// made, or would have made when running without --confirm, or a label that is
+// not in the config.
+type change struct {
+	Action      string `json:"action"`
+	Org         string `json:"org"`
+	Repo        string `json:"repo"`
+	Label       string `json:"label"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+	// From is the former name of a renamed or migrated label, or the name of
+	// an updated label that differs in case.
+	From string `json:"from,omitempty"`
+	// Issues lists the issues and pull requests relabelled by a migration.
+	Issues []int `json:"issues,omitempty"`
+}
+
+// syncRepos syncs the labels of the org/repos and returns the report.
+func syncRepos(c client, cfg *Configuration, repos []string) ([]change, error) {
+	changes := []change{}
+	var errs []error
+	for _, orgRepo := range repos {
+		org, repo, _ := strings.Cut(orgRepo, "/")
+		repoChanges, err := syncRepo(c, org, repo, cfg.LabelsFor(org, repo))
+		changes = append(changes, repoChanges...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", orgRepo, err))
+		}
+	}
+	return changes, utilerrors.NewAggregate(errs)
+}
+
+// syncRepo syncs the labels of a repo and returns the applied changes and the
+// unmanaged labels.
+func syncRepo(c client, org, repo string, labels []Label) ([]change, error) {
+	current, err := c.GetRepoLabels(org, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels: %w", err)
+	}
+	logger := logrus.WithFields(logrus.Fields{"org": org, "repo": repo})
+	var changes []change
+	var errs []error
+	for _, ch := range planRepo(org, repo, labels, current) {
+		applied, err := apply(c, ch)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to %s label %q: %w", ch.Action, ch.Label, err))
+			continue
+		}
+		if applied.Action != actionUnmanaged {
+			logger.WithFields(logrus.Fields{"action": applied.Action, "label": applied.Label, "from": applied.From}).Info("Synced label.")
+		}
+		changes = append(changes, applied)
+	}
+	return changes, utilerrors.NewAggregate(errs)
+}
+
+// planRepo returns the changes that make the current labels of a repo match
+// the configured ones, followed by the unmanaged labels.
+func planRepo(org, repo string, labels []Label, current []github.Label) []change {
+	byName := map[string]github.Label{}
+	for _, l := range current {
+		byName[strings.ToLower(l.Name)] = l
+	}
+	managed := sets.New[string]()
+
+	var changes []change
+	for _, l := range labels {
+		base := change{Org: org, Repo: repo, Label: l.Name, Color: l.Color, Description: l.Description}
+		var former []github.Label
+		for _, name := range l.Previously {
+			if f, ok := byName[strings.ToLower(name)]; ok {
+				former = append(former, f)
+				managed.Insert(strings.ToLower(name))
+			}
+		}
+		existing, exists := byName[strings.ToLower(l.Name)]
+		managed.Insert(strings.ToLower(l.Name))
+
+		switch {
+		case !exists && len(former) > 0:
+			rename := base
+			rename.Action = actionRename
+			rename.From = former[0].Name
+			changes = append(changes, rename)
+			former = former[1:]
+		case !exists:
+			create := base
+			create.Action = actionCreate
+			changes = append(changes, create)
+		case existing.Name != l.Name || !strings.EqualFold(existing.Color, l.Color) || existing.Description != l.Description:
+			update := base
+			update.Action = actionUpdate
+			if existing.Name != l.Name {
+				update.From = existing.Name
+			}
+			changes = append(changes, update)
+		}
+		for _, f := range former {
+			migrate := base
+			migrate.Action = actionMigrate
+			migrate.From = f.Name
+			changes = append(changes, migrate)
+		}
+	}
+
+	var unmanaged []change
+	for _, l := range current {
+		if !managed.Has(strings.ToLower(l.Name)) {
+			unmanaged = append(unmanaged, change{Action: actionUnmanaged, Org: org, Repo: repo, Label: l.Name, Color: l.Color, Description: l.Description})
+		}
+	}
+	sort.Slice(unmanaged, func(i, j int) bool { return unmanaged[i].Label < unmanaged[j].Label })
+	return append(changes, unmanaged...)
+}
+
+// apply applies the change and returns it, with the relabelled issues and
+// pull requests of a migration.
+func apply(c client, ch change) (change, error) {
+	switch ch.Action {
+	case actionCreate:
+		return ch, c.AddRepoLabel(ch.Org, ch.Repo, ch.Label, ch.Description, ch.Color)
+	case actionUpdate:
+		name := ch.Label
+		if ch.From != "" {
+			name = ch.From
+		}
+		return ch, c.UpdateRepoLabel(ch.Org, ch.Repo, name, ch.Label, ch.Description, ch.Color)
+	case actionRename:
+		return ch, c.UpdateRepoLabel(ch.Org, ch.Repo, ch.From, ch.Label, ch.Description, ch.Color)
+	case actionMigrate:
+		issues, err := c.FindIssuesWithOrg(ch.Org, fmt.Sprintf("repo:%s/%s label:%q", ch.Org, ch.Repo, ch.From), "", false)
+		if err != nil {
+			return ch, fmt.Errorf("failed to find issues labelled %q: %w", ch.From, err)
+		}
+		for _, issue := range issues {
+			if err := c.AddLabel(ch.Org, ch.Repo, issue.Number, ch.Label); err != nil {
+				return ch, fmt.Errorf("failed to label #%d: %w", issue.Number, err)
+			}
+			ch.Issues = append(ch.Issues, issue.Number)
+		}
+		return ch, c.DeleteRepoLabel(ch.Org, ch.Repo, ch.From)
+	}
+	return ch, nil
+}
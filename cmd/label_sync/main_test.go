@@ -0,0 +1,354 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+const testConfig = `
+default:
+  labels:
+  - name: kind/bug
+    color: ee0701
+    description: Categorizes issue or PR as related to a bug.
+    previously:
+    - bug
+  - name: lgtm
+    color: 15dd18
+orgs:
+  org:
+    labels:
+    - name: lgtm
+      color: e11d21
+      description: Looks good to me.
+    - name: area/docs
+      color: 0052cc
+    excludedRepos:
+    - archive
+repos:
+  org/repo:
+    labels:
+    - name: area/docs
+      color: ffffff
+    excludedLabels:
+    - kind/bug
+`
+
+func loadTestConfig(t *testing.T) *Configuration {
+	var cfg Configuration
+	if err := yaml.UnmarshalStrict([]byte(testConfig), &cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return &cfg
+}
+
+func TestLabelsFor(t *testing.T) {
+	cfg := loadTestConfig(t)
+	bug := Label{Name: "kind/bug", Color: "ee0701", Description: "Categorizes issue or PR as related to a bug.", Previously: []string{"bug"}}
+	testCases := []struct {
+		name     string
+		org      string
+		repo     string
+		expected []Label
+	}{
+		{
+			name:     "default labels",
+			org:      "other",
+			repo:     "repo",
+			expected: []Label{bug, {Name: "lgtm", Color: "15dd18"}},
+		},
+		{
+			name: "org labels override default labels",
+			org:  "org",
+			repo: "other",
+			expected: []Label{
+				{Name: "area/docs", Color: "0052cc"},
+				bug,
+				{Name: "lgtm", Color: "e11d21", Description: "Looks good to me."},
+			},
+		},
+		{
+			name: "repo labels override org labels and exclude labels",
+			org:  "org",
+			repo: "repo",
+			expected: []Label{
+				{Name: "area/docs", Color: "ffffff"},
+				{Name: "lgtm", Color: "e11d21", Description: "Looks good to me."},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, cfg.LabelsFor(tc.org, tc.repo)); diff != "" {
+				t.Errorf("unexpected labels (-expected +actual): %s", diff)
+			}
+		})
+	}
+
+	if !cfg.Excluded("org", "archive") {
+		t.Error("expected org/archive to be excluded")
+	}
+	if cfg.Excluded("org", "repo") {
+		t.Error("expected org/repo not to be excluded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         Configuration
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			cfg:  *loadTestConfig(t),
+		},
+		{
+			name: "invalid color",
+			cfg: Configuration{
+				Default: LabelsConfig{Labels: []Label{{Name: "lgtm", Color: "green"}}},
+			},
+			expectedErr: `default: label "lgtm": color "green" is not a six digit hex code`,
+		},
+		{
+			name: "former name of another label",
+			cfg: Configuration{
+				Default: LabelsConfig{Labels: []Label{{Name: "bug", Color: "ee0701"}}},
+				Orgs: map[string]OrgConfig{
+					"org": {Labels: []Label{{Name: "kind/bug", Color: "ee0701", Previously: []string{"Bug"}}}},
+				},
+			},
+			expectedErr: `orgs[org]: name "Bug" is used more than once`,
+		},
+		{
+			name: "repo is not org/repo",
+			cfg: Configuration{
+				Repos: map[string]RepoConfig{"repo": {}},
+			},
+			expectedErr: `repos: "repo" is not of the form org/repo`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actualErr string
+			if err := tc.cfg.Validate(); err != nil {
+				actualErr = err.Error()
+			}
+			if actualErr != tc.expectedErr {
+				t.Errorf("expected error %q, got %q", tc.expectedErr, actualErr)
+			}
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	testCases := []struct {
+		name        string
+		args        []string
+		expectedErr bool
+	}{
+		{
+			name: "orgs",
+			args: []string{"--config-path=labels.yaml", "--orgs=org"},
+		},
+		{
+			name: "only",
+			args: []string{"--config-path=labels.yaml", "--only=org/repo", "--output=json"},
+		},
+		{
+			name:        "no config",
+			args:        []string{"--orgs=org"},
+			expectedErr: true,
+		},
+		{
+			name:        "no repos",
+			args:        []string{"--config-path=labels.yaml"},
+			expectedErr: true,
+		},
+		{
+			name:        "only is not org/repo",
+			args:        []string{"--config-path=labels.yaml", "--only=org"},
+			expectedErr: true,
+		},
+		{
+			name:        "unsupported output",
+			args:        []string{"--config-path=labels.yaml", "--orgs=org", "--output=yaml"},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var o options
+			err := o.parseArgs(flag.NewFlagSet(tc.name, flag.ContinueOnError), tc.args)
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("expected error: %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestPlanRepo(t *testing.T) {
+	labels := []Label{
+		{Name: "kind/bug", Color: "ee0701", Previously: []string{"bug", "type/bug"}},
+		{Name: "kind/feature", Color: "c7def8", Previously: []string{"enhancement"}},
+		{Name: "lgtm", Color: "15dd18", Description: "Looks good to me."},
+		{Name: "needs-rebase", Color: "BFD4F2"},
+		{Name: "triage/accepted", Color: "8fc951"},
+	}
+	current := []github.Label{
+		{Name: "bug", Color: "ee0701"},
+		{Name: "type/bug", Color: "ee0701"},
+		{Name: "kind/feature", Color: "c7def8"},
+		{Name: "enhancement", Color: "84b6eb"},
+		{Name: "LGTM", Color: "15dd18", Description: "Looks good to me."},
+		{Name: "needs-rebase", Color: "bfd4f2"},
+		{Name: "wontfix", Color: "ffffff"},
+		{Name: "duplicate", Color: "cccccc"},
+	}
+	expected := []change{
+		{Action: actionRename, Org: "org", Repo: "repo", Label: "kind/bug", Color: "ee0701", From: "bug"},
+		{Action: actionMigrate, Org: "org", Repo: "repo", Label: "kind/bug", Color: "ee0701", From: "type/bug"},
+		{Action: actionMigrate, Org: "org", Repo: "repo", Label: "kind/feature", Color: "c7def8", From: "enhancement"},
+		{Action: actionUpdate, Org: "org", Repo: "repo", Label: "lgtm", Color: "15dd18", Description: "Looks good to me.", From: "LGTM"},
+		{Action: actionCreate, Org: "org", Repo: "repo", Label: "triage/accepted", Color: "8fc951"},
+		{Action: actionUnmanaged, Org: "org", Repo: "repo", Label: "duplicate", Color: "cccccc"},
+		{Action: actionUnmanaged, Org: "org", Repo: "repo", Label: "wontfix", Color: "ffffff"},
+	}
+	if diff := cmp.Diff(expected, planRepo("org", "repo", labels, current)); diff != "" {
+		t.Errorf("unexpected changes (-expected +actual): %s", diff)
+	}
+}
+
+type fakeClient struct {
+	repos  map[string][]github.Repo
+	labels map[string][]github.Label
+	// issues holds the issue numbers by org/repo and label.
+	issues map[string]map[string][]int
+	calls  []string
+}
+
+func (c *fakeClient) GetRepos(org string, _ bool) ([]github.Repo, error) {
+	repos, ok := c.repos[org]
+	if !ok {
+		return nil, fmt.Errorf("org %s not found", org)
+	}
+	return repos, nil
+}
+
+func (c *fakeClient) GetRepoLabels(org, repo string) ([]github.Label, error) {
+	return c.labels[org+"/"+repo], nil
+}
+
+func (c *fakeClient) AddRepoLabel(org, repo, label, description, color string) error {
+	c.calls = append(c.calls, fmt.Sprintf("AddRepoLabel %s/%s %s %s %q", org, repo, label, color, description))
+	return nil
+}
+
+func (c *fakeClient) UpdateRepoLabel(org, repo, label, newName, description, color string) error {
+	c.calls = append(c.calls, fmt.Sprintf("UpdateRepoLabel %s/%s %s %s %s %q", org, repo, label, newName, color, description))
+	return nil
+}
+
+func (c *fakeClient) DeleteRepoLabel(org, repo, label string) error {
+	c.calls = append(c.calls, fmt.Sprintf("DeleteRepoLabel %s/%s %s", org, repo, label))
+	return nil
+}
+
+func (c *fakeClient) FindIssuesWithOrg(_, query, _ string, _ bool) ([]github.Issue, error) {
+	var orgRepo, label string
+	if _, err := fmt.Sscanf(query, "repo:%s label:%q", &orgRepo, &label); err != nil {
+		return nil, fmt.Errorf("unexpected query %q: %w", query, err)
+	}
+	var issues []github.Issue
+	for _, number := range c.issues[orgRepo][label] {
+		issues = append(issues, github.Issue{Number: number})
+	}
+	return issues, nil
+}
+
+func (c *fakeClient) AddLabel(org, repo string, number int, label string) error {
+	c.calls = append(c.calls, fmt.Sprintf("AddLabel %s/%s#%d %s", org, repo, number, label))
+	return nil
+}
+
+func TestSyncRepos(t *testing.T) {
+	cfg := loadTestConfig(t)
+	c := &fakeClient{
+		repos: map[string][]github.Repo{
+			"org": {{Name: "repo"}, {Name: "other"}, {Name: "archive"}, {Name: "old", Archived: true}},
+		},
+		labels: map[string][]github.Label{
+			"org/repo": {
+				{Name: "area/docs", Color: "ffffff"},
+				{Name: "lgtm", Color: "e11d21", Description: "Looks good to me."},
+				{Name: "bug", Color: "ee0701"},
+			},
+			"org/other": {
+				{Name: "kind/bug", Color: "ee0701", Description: "Categorizes issue or PR as related to a bug."},
+				{Name: "bug", Color: "ee0701"},
+				{Name: "lgtm", Color: "e11d21", Description: "Looks good to me."},
+			},
+			"other/repo": {
+				{Name: "bug", Color: "ee0701"},
+				{Name: "lgtm", Color: "15dd18"},
+			},
+		},
+		issues: map[string]map[string][]int{
+			"org/other": {"bug": {1, 2}},
+		},
+	}
+
+	repos, err := listRepos(c, cfg, []string{"org"}, []string{"other/repo"})
+	if err != nil {
+		t.Fatalf("failed to list repos: %v", err)
+	}
+	if diff := cmp.Diff([]string{"org/other", "org/repo", "other/repo"}, repos); diff != "" {
+		t.Errorf("unexpected repos (-expected +actual): %s", diff)
+	}
+
+	changes, err := syncRepos(c, cfg, repos)
+	if err != nil {
+		t.Fatalf("failed to sync repos: %v", err)
+	}
+	expectedChanges := []change{
+		{Action: actionCreate, Org: "org", Repo: "other", Label: "area/docs", Color: "0052cc"},
+		{Action: actionMigrate, Org: "org", Repo: "other", Label: "kind/bug", Color: "ee0701", Description: "Categorizes issue or PR as related to a bug.", From: "bug", Issues: []int{1, 2}},
+		{Action: actionUnmanaged, Org: "org", Repo: "repo", Label: "bug", Color: "ee0701"},
+		{Action: actionRename, Org: "other", Repo: "repo", Label: "kind/bug", Color: "ee0701", Description: "Categorizes issue or PR as related to a bug.", From: "bug"},
+	}
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("unexpected changes (-expected +actual): %s", diff)
+	}
+	expectedCalls := []string{
+		`AddRepoLabel org/other area/docs 0052cc ""`,
+		"AddLabel org/other#1 kind/bug",
+		"AddLabel org/other#2 kind/bug",
+		"DeleteRepoLabel org/other bug",
+		`UpdateRepoLabel other/repo bug kind/bug ee0701 "Categorizes issue or PR as related to a bug."`,
+	}
+	if diff := cmp.Diff(expectedCalls, c.calls); diff != "" {
+		t.Errorf("unexpected calls (-expected +actual): %s", diff)
+	}
+}
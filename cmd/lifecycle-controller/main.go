@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// lifecycle-controller periodically applies the stale issue and PR automation
+// configured for the lifecycle plugin.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/flagutil"
+	pluginsflagutil "sigs.k8s.io/prow/pkg/flagutil/plugins"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/interrupts"
+	"sigs.k8s.io/prow/pkg/logrusutil"
+	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/pjutil/pprof"
+	"sigs.k8s.io/prow/pkg/plugins/lifecycle"
+)
+
+const defaultHourlyTokens = 360
+
+type options struct {
+	pluginsConfig          pluginsflagutil.PluginOptions
+	github                 flagutil.GitHubOptions
+	instrumentationOptions flagutil.InstrumentationOptions
+
+	dryRun       bool
+	resyncPeriod time.Duration
+}
+
+func (o *options) Validate() error {
+	for _, group := range []flagutil.OptionGroup{&o.github, &o.pluginsConfig, &o.instrumentationOptions} {
+		if err := group.Validate(o.dryRun); err != nil {
+			return err
+		}
+	}
+	if o.resyncPeriod <= 0 {
+		return fmt.Errorf("--resync-period must be positive, got %v", o.resyncPeriod)
+	}
+	return nil
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	o := options{}
+	fs.BoolVar(&o.dryRun, "dry-run", true, "Dry run for testing. Uses API tokens but does not mutate.")
+	fs.DurationVar(&o.resyncPeriod, "resync-period", time.Hour, "Period for searching for inactive issues and PRs.")
+
+	o.github.AddCustomizedFlags(fs, flagutil.ThrottlerDefaults(defaultHourlyTokens, defaultHourlyTokens))
+	o.pluginsConfig.PluginConfigPathDefault = "/etc/plugins/plugins.yaml"
+	for _, group := range []flagutil.OptionGroup{&o.instrumentationOptions, &o.pluginsConfig} {
+		group.AddFlags(fs)
+	}
+	fs.Parse(args)
+	return o
+}
+
+func main() {
+	logrusutil.ComponentInit()
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	log := logrus.WithField("component", "lifecycle-controller")
+
+	pa, err := o.pluginsConfig.PluginAgent()
+	if err != nil {
+		log.WithError(err).Fatal("Error loading plugin config")
+	}
+	githubClient, err := o.github.GitHubClient(o.dryRun)
+	if err != nil {
+		log.WithError(err).Fatal("Error getting GitHub client.")
+	}
+	// Sweeping stale issues and PRs can wait when the rate limit runs low.
+	githubClient = githubClient.WithPriority(github.PriorityLow)
+
+	defer interrupts.WaitForGracefulShutdown()
+
+	interrupts.TickLiteral(func() {
+		start := time.Now()
+		if err := lifecycle.SyncStale(log, githubClient, pa.Config().Lifecycle, start); err != nil {
+			log.WithError(err).Error("Error during sync of inactive issues and PRs.")
+		}
+		log.WithField("duration", fmt.Sprintf("%v", time.Since(start))).Info("Sync of inactive issues and PRs complete.")
+	}, o.resyncPeriod)
+
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
+	pprof.Instrument(o.instrumentationOptions)
+	health.ServeReady()
+}
@@ -20,9 +20,17 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/yaml"
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
@@ -30,7 +38,9 @@ import (
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
 )
 
 type options struct {
@@ -45,14 +55,133 @@ type options struct {
 	pullSha     string
 	pullAuthor  string
 	pullHeadRef string
+	prURL       string
 	org         string
 	repo        string
 
+	env         prowflagutil.Strings
+	labels      prowflagutil.Strings
+	annotations prowflagutil.Strings
+
 	github       prowflagutil.GitHubOptions
 	githubClient githubClient
 	pullRequest  *github.PullRequest
 }
 
+// parseKeyValues parses the KEY=VALUE pairs given with flag, rejecting
+// invalid or duplicate keys.
+func parseKeyValues(flag string, pairs []string, validate func(key, value string) error) (map[string]string, error) {
+	parsed := map[string]string{}
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("--%s must be of the form KEY=VALUE, got %q", flag, pair)
+		}
+		if _, seen := parsed[key]; seen {
+			return nil, fmt.Errorf("--%s %s was given more than once", flag, key)
+		}
+		if err := validate(key, value); err != nil {
+			return nil, fmt.Errorf("invalid --%s %s: %w", flag, key, err)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+func validateEnv(key, _ string) error {
+	if errs := validation.IsEnvVarName(key); len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	if sets.New[string](downwardapi.EnvForType(prowapi.PresubmitJob)...).Has(key) {
+		return errors.New("the variable is set by Prow, change the refs instead")
+	}
+	return nil
+}
+
+// validateMetadataKey rejects the keys Prow uses to track its own resources.
+func validateMetadataKey(key string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	if key == kube.CreatedByProw || key == kube.CreatedByTideLabel || strings.HasPrefix(key, "prow.k8s.io/") {
+		return errors.New("the key is reserved for Prow")
+	}
+	return nil
+}
+
+func validateLabel(key, value string) error {
+	if err := validateMetadataKey(key); err != nil {
+		return err
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func validateAnnotation(key, _ string) error {
+	return validateMetadataKey(key)
+}
+
+// applyOverrides injects the environment variables, labels and annotations
+// given on the command line into the job.
+func (o *options) applyOverrides(job *config.JobBase, pjs *prowapi.ProwJobSpec) error {
+	env, err := parseKeyValues("env", o.env.Strings(), validateEnv)
+	if err != nil {
+		return err
+	}
+	if len(env) > 0 {
+		if pjs.PodSpec == nil {
+			return fmt.Errorf("job %s has no pod spec to set environment variables in", job.Name)
+		}
+		pjs.PodSpec = pjs.PodSpec.DeepCopy()
+		for i := range pjs.PodSpec.Containers {
+			pjs.PodSpec.Containers[i].Env = overrideEnv(pjs.PodSpec.Containers[i].Env, env)
+		}
+	}
+	labels, err := parseKeyValues("label", o.labels.Strings(), validateLabel)
+	if err != nil {
+		return err
+	}
+	job.Labels = mergeMaps(job.Labels, labels)
+	annotations, err := parseKeyValues("annotation", o.annotations.Strings(), validateAnnotation)
+	if err != nil {
+		return err
+	}
+	job.Annotations = mergeMaps(job.Annotations, annotations)
+	return nil
+}
+
+// overrideEnv replaces the variables of env that are overridden and appends
+// the others in a stable order.
+func overrideEnv(env []coreapi.EnvVar, overrides map[string]string) []coreapi.EnvVar {
+	seen := sets.New[string]()
+	for i := range env {
+		if value, ok := overrides[env[i].Name]; ok {
+			env[i] = coreapi.EnvVar{Name: env[i].Name, Value: value}
+			seen.Insert(env[i].Name)
+		}
+	}
+	for _, name := range sets.List(sets.KeySet(overrides).Difference(seen)) {
+		env = append(env, coreapi.EnvVar{Name: name, Value: overrides[name]})
+	}
+	return env
+}
+
+func mergeMaps(base, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
 func (o *options) genJobSpec(conf *config.Config) (config.JobBase, prowapi.ProwJobSpec) {
 	for fullRepoName, ps := range conf.PresubmitsStatic {
 		org, repo, err := config.SplitRepoName(fullRepoName)
@@ -60,6 +189,9 @@ func (o *options) genJobSpec(conf *config.Config) (config.JobBase, prowapi.ProwJ
 			logrus.WithError(err).Warnf("Invalid repo name %s.", fullRepoName)
 			continue
 		}
+		if o.prURL != "" && (org != o.org || repo != o.repo) {
+			continue
+		}
 		for _, p := range ps {
 			if p.Name == o.jobName {
 				return p.JobBase, pjutil.PresubmitSpec(p, prowapi.Refs{
@@ -171,16 +303,100 @@ func (o *options) defaultBaseRef(pjs *prowapi.ProwJobSpec) error {
 	return nil
 }
 
+// parsePRURL extracts the org, repo and number from the URL of a pull
+// request, e.g. https://github.com/org/repo/pull/123.
+func parsePRURL(raw string) (string, string, int, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid pull request URL %q: %w", raw, err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if u.Host == "" || len(parts) < 4 || parts[2] != "pull" {
+		return "", "", 0, fmt.Errorf("%q is not a pull request URL like https://github.com/org/repo/pull/123", raw)
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("invalid pull request number %q in %q", parts[3], raw)
+	}
+	return parts[0], parts[1], number, nil
+}
+
+// resolvePR fills the refs under test from the pull request given with
+// --pr-url. Refs that were set explicitly are kept.
+func (o *options) resolvePR() error {
+	org, repo, number, err := parsePRURL(o.prURL)
+	if err != nil {
+		return err
+	}
+	o.org, o.repo, o.pullNumber = org, repo, number
+	pr, err := o.getPullRequest()
+	if err != nil {
+		return err
+	}
+	for _, ref := range []struct {
+		value *string
+		from  string
+	}{
+		{value: &o.pullAuthor, from: pr.User.Login},
+		{value: &o.pullSha, from: pr.Head.SHA},
+		{value: &o.pullHeadRef, from: pr.Head.Ref},
+		{value: &o.baseRef, from: pr.Base.Ref},
+		{value: &o.baseSha, from: pr.Base.SHA},
+	} {
+		if *ref.value == "" {
+			*ref.value = ref.from
+		}
+	}
+	return nil
+}
+
+// pickJob lets the user choose one of the presubmits that could run against
+// the pull request.
+func (o *options) pickJob(conf *config.Config, in io.Reader, out io.Writer) error {
+	var names []string
+	for _, p := range conf.PresubmitsStatic[o.org+"/"+o.repo] {
+		if p.CouldRun(o.baseRef) {
+			names = append(names, p.Name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no presubmits configured for %s/%s on branch %s", o.org, o.repo, o.baseRef)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		fmt.Fprintf(out, "%3d) %s\n", i+1, name)
+	}
+	fmt.Fprint(out, "Job to run: ")
+	var choice int
+	if _, err := fmt.Fscanln(in, &choice); err != nil {
+		return fmt.Errorf("failed to read choice: %w", err)
+	}
+	if choice < 1 || choice > len(names) {
+		return fmt.Errorf("choice %d is not between 1 and %d", choice, len(names))
+	}
+	o.jobName = names[choice-1]
+	return nil
+}
+
 type githubClient interface {
 	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
 	GetRef(org, repo, ref string) (string, error)
 }
 
 func (o *options) Validate() error {
-	if o.jobName == "" {
+	if o.jobName == "" && o.prURL == "" {
 		return errors.New("required flag --job was unset")
 	}
 
+	if o.prURL != "" {
+		if o.pullNumber != 0 {
+			return errors.New("--pr-url and --pull-number are mutually exclusive")
+		}
+		if _, _, _, err := parsePRURL(o.prURL); err != nil {
+			return err
+		}
+	}
+
 	if err := o.config.Validate(false); err != nil {
 		return err
 	}
@@ -189,6 +405,20 @@ func (o *options) Validate() error {
 		return err
 	}
 
+	for _, kv := range []struct {
+		flag     string
+		pairs    []string
+		validate func(key, value string) error
+	}{
+		{flag: "env", pairs: o.env.Strings(), validate: validateEnv},
+		{flag: "label", pairs: o.labels.Strings(), validate: validateLabel},
+		{flag: "annotation", pairs: o.annotations.Strings(), validate: validateAnnotation},
+	} {
+		if _, err := parseKeyValues(kv.flag, kv.pairs, kv.validate); err != nil {
+			return err
+		}
+	}
+
 	if o.triggerJob {
 		if err := o.kubeOptions.Validate(false); err != nil {
 			return err
@@ -201,13 +431,17 @@ func (o *options) Validate() error {
 func gatherOptions() options {
 	var o options
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-	fs.StringVar(&o.jobName, "job", "", "Job to run.")
+	fs.StringVar(&o.jobName, "job", "", "Job to run. If unset with --pr-url, the job is picked interactively.")
+	fs.StringVar(&o.prURL, "pr-url", "", "URL of the pull request under test, e.g. https://github.com/org/repo/pull/123. Defaults all refs from the pull request.")
 	fs.StringVar(&o.baseRef, "base-ref", "", "Git base ref under test")
 	fs.StringVar(&o.baseSha, "base-sha", "", "Git base SHA under test")
 	fs.IntVar(&o.pullNumber, "pull-number", 0, "Git pull number under test")
 	fs.StringVar(&o.pullSha, "pull-sha", "", "Git pull SHA under test")
 	fs.StringVar(&o.pullAuthor, "pull-author", "", "Git pull author under test")
 	fs.StringVar(&o.pullHeadRef, "pull-head-ref", "", "Git branch name of the proposed change")
+	fs.Var(&o.env, "env", "Environment variable to set in all containers of the job, as KEY=VALUE. Can be passed multiple times.")
+	fs.Var(&o.labels, "label", "Extra label to add to the ProwJob, as KEY=VALUE. Can be passed multiple times.")
+	fs.Var(&o.annotations, "annotation", "Extra annotation to add to the ProwJob, as KEY=VALUE. Can be passed multiple times.")
 	fs.BoolVar(&o.triggerJob, "trigger-job", false, "Submit the job to Prow and wait for results")
 	fs.BoolVar(&o.failWithJob, "fail-with-job", false, "Exit with a non-zero exit code if the triggered job fails")
 	o.config.AddFlags(fs)
@@ -235,6 +469,16 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to get GitHub client")
 	}
+	if o.prURL != "" {
+		if err := o.resolvePR(); err != nil {
+			logrus.WithError(err).Fatal("Failed to resolve pull request")
+		}
+		if o.jobName == "" {
+			if err := o.pickJob(conf, os.Stdin, os.Stderr); err != nil {
+				logrus.WithError(err).Fatal("Failed to pick a job")
+			}
+		}
+	}
 	job, pjs := o.genJobSpec(conf)
 	if job.Name == "" {
 		logrus.Fatalf("Job %s not found.", o.jobName)
@@ -251,6 +495,9 @@ func main() {
 			logrus.WithError(err).Fatal("Failed to default base ref")
 		}
 	}
+	if err := o.applyOverrides(&job, &pjs); err != nil {
+		logrus.WithError(err).Fatal("Failed to apply overrides")
+	}
 	pj := pjutil.NewProwJob(pjs, job.Labels, job.Annotations, pjutil.RequireScheduling(conf.Scheduler.Enabled))
 	if !o.triggerJob {
 		b, err := yaml.Marshal(&pj)
@@ -17,9 +17,16 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	coreapi "k8s.io/api/core/v1"
+
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/github/fakegithub"
@@ -53,6 +60,42 @@ func TestOptions_Validate(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "missing job with PR URL",
+			input: options{
+				prURL:  "https://github.com/org/repo/pull/1",
+				config: configflagutil.ConfigOptions{ConfigPath: "somewhere"},
+			},
+			expectedErr: false,
+		},
+		{
+			name: "PR URL and pull number",
+			input: options{
+				jobName:    "job",
+				prURL:      "https://github.com/org/repo/pull/1",
+				pullNumber: 1,
+				config:     configflagutil.ConfigOptions{ConfigPath: "somewhere"},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "reserved env",
+			input: options{
+				jobName: "job",
+				env:     prowflagutil.NewStrings("JOB_NAME=other"),
+				config:  configflagutil.ConfigOptions{ConfigPath: "somewhere"},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid PR URL",
+			input: options{
+				jobName: "job",
+				prURL:   "https://github.com/org/repo/issues/1",
+				config:  configflagutil.ConfigOptions{ConfigPath: "somewhere"},
+			},
+			expectedErr: true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -126,3 +169,237 @@ func TestDefaultBaseRef(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePRURL(t *testing.T) {
+	testCases := []struct {
+		name           string
+		url            string
+		expectedOrg    string
+		expectedRepo   string
+		expectedNumber int
+		expectedErr    bool
+	}{
+		{
+			name:           "github.com",
+			url:            "https://github.com/org/repo/pull/123",
+			expectedOrg:    "org",
+			expectedRepo:   "repo",
+			expectedNumber: 123,
+		},
+		{
+			name:           "trailing path",
+			url:            "https://ghe.example.com/org/repo/pull/5/files",
+			expectedOrg:    "org",
+			expectedRepo:   "repo",
+			expectedNumber: 5,
+		},
+		{
+			name:        "issue",
+			url:         "https://github.com/org/repo/issues/123",
+			expectedErr: true,
+		},
+		{
+			name:        "no host",
+			url:         "org/repo/pull/123",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid number",
+			url:         "https://github.com/org/repo/pull/abc",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			org, repo, number, err := parsePRURL(tc.url)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if org != tc.expectedOrg || repo != tc.expectedRepo || number != tc.expectedNumber {
+				t.Errorf("expected %s/%s#%d, got %s/%s#%d", tc.expectedOrg, tc.expectedRepo, tc.expectedNumber, org, repo, number)
+			}
+		})
+	}
+}
+
+func TestResolvePR(t *testing.T) {
+	fakeGitHubClient := fakegithub.NewFakeClient()
+	fakeGitHubClient.PullRequests = map[int]*github.PullRequest{7: {
+		User: github.User{Login: "Ricardo Reis"},
+		Head: github.PullRequestBranch{SHA: "head-sha", Ref: "feature"},
+		Base: github.PullRequestBranch{SHA: "base-sha", Ref: "main"},
+	}}
+	o := &options{prURL: "https://github.com/org/repo/pull/7", baseSha: "explicit-sha", githubClient: fakeGitHubClient}
+	if err := o.resolvePR(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"org", "repo", "Ricardo Reis", "head-sha", "feature", "main", "explicit-sha"}
+	actual := []string{o.org, o.repo, o.pullAuthor, o.pullSha, o.pullHeadRef, o.baseRef, o.baseSha}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("resolved refs differ (-want +got):\n%s", diff)
+	}
+	if o.pullNumber != 7 {
+		t.Errorf("expected pull number 7, got %d", o.pullNumber)
+	}
+}
+
+func TestPickJob(t *testing.T) {
+	conf := &config.Config{JobConfig: config.JobConfig{PresubmitsStatic: map[string][]config.Presubmit{
+		"org/repo": {
+			{JobBase: config.JobBase{Name: "unit"}},
+			{JobBase: config.JobBase{Name: "e2e"}},
+			{JobBase: config.JobBase{Name: "release-only"}, Brancher: config.Brancher{Branches: []string{"release"}}},
+		},
+		"org/other": {{JobBase: config.JobBase{Name: "lint"}}},
+	}}}
+	if err := config.SetPresubmitRegexes(conf.PresubmitsStatic["org/repo"]); err != nil {
+		t.Fatalf("failed to set regexes: %v", err)
+	}
+	testCases := []struct {
+		name        string
+		input       string
+		expectedJob string
+		expectedErr bool
+	}{
+		{
+			name:        "pick second job",
+			input:       "2\n",
+			expectedJob: "unit",
+		},
+		{
+			name:        "out of range",
+			input:       "3\n",
+			expectedErr: true,
+		},
+		{
+			name:        "not a number",
+			input:       "unit\n",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &options{org: "org", repo: "repo", baseRef: "main"}
+			var out bytes.Buffer
+			err := o.pickJob(conf, strings.NewReader(tc.input), &out)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if o.jobName != tc.expectedJob {
+				t.Errorf("expected job %q, got %q", tc.expectedJob, o.jobName)
+			}
+			if expected := "  1) e2e\n  2) unit\nJob to run: "; out.String() != expected {
+				t.Errorf("expected prompt %q, got %q", expected, out.String())
+			}
+		})
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	strs := func(vals ...string) prowflagutil.Strings {
+		var s prowflagutil.Strings
+		for _, val := range vals {
+			s.Set(val)
+		}
+		return s
+	}
+	spec := &coreapi.PodSpec{Containers: []coreapi.Container{{
+		Name: "test",
+		Env:  []coreapi.EnvVar{{Name: "KEEP", Value: "1"}, {Name: "DEBUG", Value: "false"}},
+	}}}
+	testCases := []struct {
+		name                string
+		options             options
+		podSpec             *coreapi.PodSpec
+		expectedEnv         []coreapi.EnvVar
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+		expectedErr         bool
+	}{
+		{
+			name:        "no overrides",
+			podSpec:     spec,
+			expectedEnv: spec.Containers[0].Env,
+		},
+		{
+			name: "all overrides",
+			options: options{
+				env:         strs("DEBUG=true", "VERBOSE=2", "ADDED=yes"),
+				labels:      strs("team=infra"),
+				annotations: strs("note=debugging run"),
+			},
+			podSpec: spec,
+			expectedEnv: []coreapi.EnvVar{
+				{Name: "KEEP", Value: "1"},
+				{Name: "DEBUG", Value: "true"},
+				{Name: "ADDED", Value: "yes"},
+				{Name: "VERBOSE", Value: "2"},
+			},
+			expectedLabels:      map[string]string{"existing": "label", "team": "infra"},
+			expectedAnnotations: map[string]string{"note": "debugging run"},
+		},
+		{
+			name:        "env without pod spec",
+			options:     options{env: strs("DEBUG=true")},
+			expectedErr: true,
+		},
+		{
+			name:        "reserved env",
+			options:     options{env: strs("PULL_PULL_SHA=abc")},
+			podSpec:     spec,
+			expectedErr: true,
+		},
+		{
+			name:        "reserved label",
+			options:     options{labels: strs("prow.k8s.io/type=periodic")},
+			podSpec:     spec,
+			expectedErr: true,
+		},
+		{
+			name:        "invalid label value",
+			options:     options{labels: strs("team=not a label")},
+			podSpec:     spec,
+			expectedErr: true,
+		},
+		{
+			name:        "duplicate annotation",
+			options:     options{annotations: strs("note=a", "note=b")},
+			podSpec:     spec,
+			expectedErr: true,
+		},
+		{
+			name:        "missing value separator",
+			options:     options{env: strs("DEBUG")},
+			podSpec:     spec,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := config.JobBase{Name: "job", Labels: map[string]string{"existing": "label"}}
+			pjs := prowapi.ProwJobSpec{PodSpec: tc.podSpec}
+			err := tc.options.applyOverrides(&job, &pjs)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(tc.expectedEnv, pjs.PodSpec.Containers[0].Env); diff != "" {
+				t.Errorf("env differs (-want +got):\n%s", diff)
+			}
+			if tc.expectedLabels == nil {
+				tc.expectedLabels = map[string]string{"existing": "label"}
+			}
+			if diff := cmp.Diff(tc.expectedLabels, job.Labels); diff != "" {
+				t.Errorf("labels differ (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedAnnotations, job.Annotations); diff != "" {
+				t.Errorf("annotations differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if spec.Containers[0].Env[1].Value != "false" {
+		t.Error("applying overrides modified the pod spec of the job config")
+	}
+}
@@ -17,13 +17,16 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path"
 	"strings"
+	"syscall"
 
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
@@ -41,6 +44,11 @@ type options struct {
 
 	localMode bool
 	outputDir string
+
+	stubSecrets bool
+	run         bool
+	kubeContext string
+	namespace   string
 }
 
 func (o *options) Validate() error {
@@ -52,6 +60,10 @@ func (o *options) Validate() error {
 		return errors.New("out-dir may only be specified in --local mode")
 	}
 
+	if !o.localMode && (o.run || o.stubSecrets) {
+		return errors.New("--run and --stub-secrets may only be specified in --local mode")
+	}
+
 	return nil
 }
 
@@ -61,6 +73,10 @@ func gatherOptions() options {
 	flag.StringVar(&o.buildID, "build-id", "", "Build ID for the job run or 'snowflake' to generate one. Use 'snowflake' if tot is not used.")
 	flag.BoolVar(&o.localMode, "local", false, "Configures pod utils for local mode which avoids uploading to GCS and the need for credentials. Instead, files are copied to a directory on the host. Hint: This works great with kind!")
 	flag.StringVar(&o.outputDir, "out-dir", "", "Only allowed in --local mode. This is the directory to 'upload' to instead of GCS. If unspecified a temp dir is created.")
+	flag.BoolVar(&o.stubSecrets, "stub-secrets", false, "Only allowed in --local mode. Replace all secret volumes with emptyDirs instead of prompting for them.")
+	flag.BoolVar(&o.run, "run", false, "Only allowed in --local mode. Instead of printing the pod, run it in the cluster of --kube-context and stream the logs of the test container.")
+	flag.StringVar(&o.kubeContext, "kube-context", "kind-kind", "Kube context of the local cluster to run the pod in with --run.")
+	flag.StringVar(&o.namespace, "namespace", "default", "Namespace to run the pod in with --run.")
 	flag.Parse()
 	return o
 }
@@ -122,7 +138,7 @@ func main() {
 		logrus.WithField("out-dir", outDir).Info("Pod-utils configured for local mode. Instead of uploading to GCS, files will be copied to an output dir on the node.")
 
 		job.Status.BuildID = o.buildID
-		pod, err = makeLocalPod(job, outDir)
+		pod, err = makeLocalPod(job, outDir, o.stubSecrets)
 		if err != nil {
 			logrus.WithError(err).Fatal("Could not decorate PodSpec for local mode.")
 		}
@@ -145,6 +161,25 @@ func main() {
 	}
 	pod.Labels = newLabels
 
+	if o.run {
+		pods, err := podClient(o.kubeContext, o.namespace)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not create client for the local cluster.")
+		}
+		pod.Namespace = o.namespace
+		// Stop on interrupts rather than exit, so that the pod is deleted.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		succeeded, err := runPod(ctx, pods, pod, os.Stdout)
+		stop()
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not run pod.")
+		}
+		if !succeeded {
+			os.Exit(1)
+		}
+		return
+	}
+
 	pod.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Pod"))
 	podYAML, err := yaml.Marshal(pod)
 	if err != nil {
@@ -153,12 +188,18 @@ func main() {
 	fmt.Println(string(podYAML))
 }
 
-func makeLocalPod(pj prowapi.ProwJob, outDir string) (*v1.Pod, error) {
+func makeLocalPod(pj prowapi.ProwJob, outDir string, stubSecrets bool) (*v1.Pod, error) {
 	pod, err := decorate.ProwJobToPodLocal(pj, outDir)
 	if err != nil {
 		return nil, err
 	}
 
+	if stubSecrets {
+		if stubbed := stubSecretVolumes(pod.Spec.Volumes); len(stubbed) > 0 {
+			logrus.WithField("volumes", stubbed).Info("Replaced secret volumes with emptyDirs.")
+		}
+	}
+
 	// Prompt for emptyDir or hostPath replacements for all volume sources besides those two.
 	volsToFix := nonLocalVolumes(pod.Spec.Volumes)
 	if len(volsToFix) > 0 {
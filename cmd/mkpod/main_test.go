@@ -31,6 +31,24 @@ func TestOptions_Validate(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		{
+			name: "run in local mode",
+			input: options{
+				prowJobPath: "somewhere",
+				localMode:   true,
+				run:         true,
+				stubSecrets: true,
+			},
+			expectedErr: false,
+		},
+		{
+			name: "run without local mode",
+			input: options{
+				prowJobPath: "somewhere",
+				run:         true,
+			},
+			expectedErr: true,
+		},
 		{
 			name:        "missing config",
 			input:       options{},
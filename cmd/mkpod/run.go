@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+// pollInterval is how often the state of the pod is checked while it runs.
+var pollInterval = 2 * time.Second
+
+// podClient returns a client for pods in namespace of the cluster the kube
+// context points at, e.g. a kind cluster.
+func podClient(kubeContext, namespace string) (corev1client.PodInterface, error) {
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kube context %q: %w", kubeContext, err)
+	}
+	client, err := corev1client.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return client.Pods(namespace), nil
+}
+
+// stubSecretVolumes replaces the secrets mounted by the pod with empty
+// directories, as the secrets of the real instance are not available
+// locally. It returns the names of the stubbed volumes.
+func stubSecretVolumes(vols []v1.Volume) []string {
+	var stubbed []string
+	for i, vol := range vols {
+		if vol.Secret == nil && !projectsSecret(vol.Projected) {
+			continue
+		}
+		vols[i].VolumeSource = v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}
+		stubbed = append(stubbed, vol.Name)
+	}
+	return stubbed
+}
+
+func projectsSecret(projected *v1.ProjectedVolumeSource) bool {
+	if projected == nil {
+		return false
+	}
+	for _, source := range projected.Sources {
+		if source.Secret != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// runPod creates the pod, streams the logs of its test container to out and
+// waits for it to finish. It returns whether the pod succeeded. The pod is
+// deleted when it finishes or the run is interrupted, the artifacts stay in
+// the output dir on the node.
+func runPod(ctx context.Context, pods corev1client.PodInterface, pod *v1.Pod, out io.Writer) (bool, error) {
+	created, err := pods.Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to create pod: %w", err)
+	}
+	logger := logrus.WithField("pod", created.Name)
+	defer func() {
+		// ctx may be cancelled already.
+		if err := pods.Delete(context.Background(), created.Name, metav1.DeleteOptions{}); err != nil {
+			logger.WithError(err).Warn("Failed to delete pod.")
+		}
+	}()
+	logger.Info("Created pod, waiting for it to start.")
+
+	if _, err := waitForPod(ctx, pods, created.Name, func(p *v1.Pod) bool {
+		return p.Status.Phase != v1.PodPending
+	}); err != nil {
+		return false, err
+	}
+
+	logs, err := pods.GetLogs(created.Name, &v1.PodLogOptions{Container: kube.TestContainerName, Follow: true}).Stream(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to stream logs: %w", err)
+	}
+	defer logs.Close()
+	if _, err := io.Copy(out, logs); err != nil {
+		return false, fmt.Errorf("failed to stream logs: %w", err)
+	}
+
+	finished, err := waitForPod(ctx, pods, created.Name, func(p *v1.Pod) bool {
+		return p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed
+	})
+	if err != nil {
+		return false, err
+	}
+	logger.WithField("phase", finished.Status.Phase).Info("Pod finished.")
+	return finished.Status.Phase == v1.PodSucceeded, nil
+}
+
+func waitForPod(ctx context.Context, pods corev1client.PodInterface, name string, done func(*v1.Pod) bool) (*v1.Pod, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		pod, err := pods.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod: %w", err)
+		}
+		if done(pod) {
+			return pod, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
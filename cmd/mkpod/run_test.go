@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestStubSecretVolumes(t *testing.T) {
+	vols := []v1.Volume{
+		{Name: "secret", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "s"}}},
+		{Name: "config", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{}}},
+		{Name: "projected-secret", VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{
+			{ConfigMap: &v1.ConfigMapProjection{}},
+			{Secret: &v1.SecretProjection{}},
+		}}}},
+		{Name: "projected-config", VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{
+			{ConfigMap: &v1.ConfigMapProjection{}},
+		}}}},
+	}
+	stubbed := stubSecretVolumes(vols)
+	if diff := cmp.Diff([]string{"secret", "projected-secret"}, stubbed); diff != "" {
+		t.Errorf("stubbed volumes differ (-want +got):\n%s", diff)
+	}
+	for _, i := range []int{0, 2} {
+		if vols[i].EmptyDir == nil || vols[i].Secret != nil || vols[i].Projected != nil {
+			t.Errorf("expected volume %s to be an emptyDir, got %+v", vols[i].Name, vols[i].VolumeSource)
+		}
+	}
+	if vols[1].ConfigMap == nil || vols[3].Projected == nil {
+		t.Errorf("expected volumes without secrets to be kept, got %+v", vols)
+	}
+}
+
+func TestRunPod(t *testing.T) {
+	pollInterval = time.Millisecond
+	testCases := []struct {
+		name     string
+		phase    v1.PodPhase
+		expected bool
+	}{
+		{
+			name:     "pod succeeds",
+			phase:    v1.PodSucceeded,
+			expected: true,
+		},
+		{
+			name:     "pod fails",
+			phase:    v1.PodFailed,
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			gets := 0
+			client.PrependReactor("get", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "log" {
+					return false, nil, nil
+				}
+				// The pod is pending at first, then running and finally done.
+				gets++
+				phase := v1.PodPending
+				switch {
+				case gets > 3:
+					phase = tc.phase
+				case gets > 1:
+					phase = v1.PodRunning
+				}
+				name := action.(clienttesting.GetAction).GetName()
+				return true, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Status: v1.PodStatus{Phase: phase}}, nil
+			})
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "default"}}
+			var out bytes.Buffer
+			succeeded, err := runPod(context.Background(), client.CoreV1().Pods("default"), pod, &out)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if succeeded != tc.expected {
+				t.Errorf("expected succeeded %t, got %t", tc.expected, succeeded)
+			}
+			if out.String() != "fake logs" {
+				t.Errorf("expected the logs to be streamed, got %q", out.String())
+			}
+			if _, err := client.Tracker().Get(v1.SchemeGroupVersion.WithResource("pods"), "default", "job"); !apierrors.IsNotFound(err) {
+				t.Errorf("expected the pod to be deleted, got %v", err)
+			}
+		})
+	}
+}
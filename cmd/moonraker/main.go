@@ -26,6 +26,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
@@ -33,6 +34,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"sigs.k8s.io/prow/pkg/config"
@@ -87,6 +89,7 @@ func init() {
 type options struct {
 	github         prowflagutil.GitHubOptions
 	port           int
+	grpcPort       int
 	cookiefilePath string
 
 	config configflagutil.ConfigOptions
@@ -100,6 +103,7 @@ type options struct {
 func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	var o options
 	fs.IntVar(&o.port, "port", 8080, "HTTP port.")
+	fs.IntVar(&o.grpcPort, "grpc-port", 0, "TCP port for the gRPC API. The gRPC API is disabled if unset.")
 	// Kubernetes uses a 30-second default grace period for pods to
 	// terminate before sending a SIGKILL to the process in the pod. Our own
 	// grace period must be smaller than this.
@@ -122,6 +126,9 @@ func (o *options) validate() error {
 			errs = append(errs, err)
 		}
 	}
+	if o.grpcPort != 0 && o.grpcPort == o.port {
+		errs = append(errs, fmt.Errorf("both the HTTP port and gRPC port are using the same port number %d", o.port))
+	}
 
 	return utilerrors.NewAggregate(errs)
 }
@@ -191,6 +198,19 @@ func main() {
 	}
 	logrus.Infof("Listening on port %d...", o.port)
 	interrupts.ListenAndServe(server, o.gracePeriod)
+
+	if o.grpcPort != 0 {
+		lis, err := net.Listen("tcp", ":"+strconv.Itoa(o.grpcPort))
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to set up tcp connection")
+		}
+		grpcServer := grpc.NewServer()
+		moonraker.RegisterMoonrakerServer(grpcServer, &mr)
+		interrupts.ListenAndServe(&interrupts.GRPCServer{
+			Server:   grpcServer,
+			Listener: lis,
+		}, o.gracePeriod)
+	}
 	health.ServeReady(func() bool {
 		return true
 	})
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// Classes of changes, so that automation can require approval for some of
+// them before a real run.
+const (
+	changeOrgSettings    = "org-settings"
+	changeOrgMembership  = "org-membership"
+	changeRepo           = "repo"
+	changeTeam           = "team"
+	changeTeamMembership = "team-membership"
+	changeTeamRepo       = "team-repo"
+)
+
+// Actions of changes.
+const (
+	actionCreate = "create"
+	actionUpdate = "update"
+	actionDelete = "delete"
+	actionAdd    = "add"
+	actionRemove = "remove"
+)
+
+// change is a single mutation of GitHub that peribolos made, or would have
+// made when running without --confirm.
+type change struct {
+	Class  string `json:"class"`
+	Action string `json:"action"`
+	Org    string `json:"org"`
+	Team   string `json:"team,omitempty"`
+	Repo   string `json:"repo,omitempty"`
+	User   string `json:"user,omitempty"`
+	// Role is the role of a user or the permission of a team on a repo.
+	Role string `json:"role,omitempty"`
+	// Details holds the requested settings of settings changes.
+	Details interface{} `json:"details,omitempty"`
+}
+
+// changeRecorder records the mutations done through the client.
+type changeRecorder struct {
+	github.Client
+	changes []change
+}
+
+func (r *changeRecorder) record(err error, c change) {
+	if err == nil {
+		r.changes = append(r.changes, c)
+	}
+}
+
+func (r *changeRecorder) EditOrg(name string, config github.Organization) (*github.Organization, error) {
+	o, err := r.Client.EditOrg(name, config)
+	r.record(err, change{Class: changeOrgSettings, Action: actionUpdate, Org: name, Details: config})
+	return o, err
+}
+
+func (r *changeRecorder) UpdateOrgMembership(org, user string, admin bool) (*github.OrgMembership, error) {
+	m, err := r.Client.UpdateOrgMembership(org, user, admin)
+	role := github.RoleMember
+	if admin {
+		role = github.RoleAdmin
+	}
+	r.record(err, change{Class: changeOrgMembership, Action: actionAdd, Org: org, User: user, Role: role})
+	return m, err
+}
+
+func (r *changeRecorder) RemoveOrgMembership(org, user string) error {
+	err := r.Client.RemoveOrgMembership(org, user)
+	r.record(err, change{Class: changeOrgMembership, Action: actionRemove, Org: org, User: user})
+	return err
+}
+
+func (r *changeRecorder) CreateRepo(owner string, isUser bool, repo github.RepoCreateRequest) (*github.FullRepo, error) {
+	created, err := r.Client.CreateRepo(owner, isUser, repo)
+	var name string
+	if repo.Name != nil {
+		name = *repo.Name
+	}
+	r.record(err, change{Class: changeRepo, Action: actionCreate, Org: owner, Repo: name, Details: repo})
+	return created, err
+}
+
+func (r *changeRecorder) UpdateRepo(owner, name string, repo github.RepoUpdateRequest) (*github.FullRepo, error) {
+	updated, err := r.Client.UpdateRepo(owner, name, repo)
+	r.record(err, change{Class: changeRepo, Action: actionUpdate, Org: owner, Repo: name, Details: repo})
+	return updated, err
+}
+
+func (r *changeRecorder) CreateTeam(org string, team github.Team) (*github.Team, error) {
+	created, err := r.Client.CreateTeam(org, team)
+	r.record(err, change{Class: changeTeam, Action: actionCreate, Org: org, Team: team.Name, Details: team})
+	return created, err
+}
+
+func (r *changeRecorder) EditTeam(org string, team github.Team) (*github.Team, error) {
+	edited, err := r.Client.EditTeam(org, team)
+	r.record(err, change{Class: changeTeam, Action: actionUpdate, Org: org, Team: team.Slug, Details: team})
+	return edited, err
+}
+
+func (r *changeRecorder) DeleteTeamBySlug(org, teamSlug string) error {
+	err := r.Client.DeleteTeamBySlug(org, teamSlug)
+	r.record(err, change{Class: changeTeam, Action: actionDelete, Org: org, Team: teamSlug})
+	return err
+}
+
+func (r *changeRecorder) UpdateTeamMembershipBySlug(org, teamSlug, user string, maintainer bool) (*github.TeamMembership, error) {
+	m, err := r.Client.UpdateTeamMembershipBySlug(org, teamSlug, user, maintainer)
+	role := github.RoleMember
+	if maintainer {
+		role = github.RoleMaintainer
+	}
+	r.record(err, change{Class: changeTeamMembership, Action: actionAdd, Org: org, Team: teamSlug, User: user, Role: role})
+	return m, err
+}
+
+func (r *changeRecorder) RemoveTeamMembershipBySlug(org, teamSlug, user string) error {
+	err := r.Client.RemoveTeamMembershipBySlug(org, teamSlug, user)
+	r.record(err, change{Class: changeTeamMembership, Action: actionRemove, Org: org, Team: teamSlug, User: user})
+	return err
+}
+
+func (r *changeRecorder) UpdateTeamRepoBySlug(org, teamSlug, repo string, permission github.TeamPermission) error {
+	err := r.Client.UpdateTeamRepoBySlug(org, teamSlug, repo, permission)
+	r.record(err, change{Class: changeTeamRepo, Action: actionAdd, Org: org, Team: teamSlug, Repo: repo, Role: string(permission)})
+	return err
+}
+
+func (r *changeRecorder) RemoveTeamRepoBySlug(org, teamSlug, repo string) error {
+	err := r.Client.RemoveTeamRepoBySlug(org, teamSlug, repo)
+	r.record(err, change{Class: changeTeamRepo, Action: actionRemove, Org: org, Team: teamSlug, Repo: repo})
+	return err
+}
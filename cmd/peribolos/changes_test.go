@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// fakeMutations implements the mutating calls of github.Client, failing
+// for the user "fail".
+type fakeMutations struct {
+	github.Client
+}
+
+func (fakeMutations) UpdateOrgMembership(_, user string, _ bool) (*github.OrgMembership, error) {
+	if user == "fail" {
+		return nil, errors.New("injected failure")
+	}
+	return &github.OrgMembership{}, nil
+}
+
+func (fakeMutations) RemoveTeamMembershipBySlug(_, _, _ string) error {
+	return nil
+}
+
+func (fakeMutations) UpdateTeamRepoBySlug(_, _, _ string, _ github.TeamPermission) error {
+	return nil
+}
+
+func (fakeMutations) EditOrg(_ string, config github.Organization) (*github.Organization, error) {
+	return &config, nil
+}
+
+func TestChangeRecorder(t *testing.T) {
+	r := &changeRecorder{Client: fakeMutations{}}
+	if _, err := r.EditOrg("org", github.Organization{Company: "company"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := r.UpdateOrgMembership("org", "admin", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := r.UpdateOrgMembership("org", "fail", false); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := r.RemoveTeamMembershipBySlug("org", "team", "former"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.UpdateTeamRepoBySlug("org", "team", "repo", github.RepoPush); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []change{
+		{Class: "org-settings", Action: "update", Org: "org", Details: github.Organization{Company: "company"}},
+		{Class: "org-membership", Action: "add", Org: "org", User: "admin", Role: "admin"},
+		{Class: "team-membership", Action: "remove", Org: "org", Team: "team", User: "former"},
+		{Class: "team-repo", Action: "add", Org: "org", Team: "team", Repo: "repo", Role: "push"},
+	}
+	if diff := cmp.Diff(expected, r.changes); diff != "" {
+		t.Errorf("changes differ (-want +got):\n%s", diff)
+	}
+}
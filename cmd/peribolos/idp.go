@@ -0,0 +1,366 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2/google"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/option"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/config/org"
+)
+
+// directory lists the identities of the members of a group.
+type directory interface {
+	members(ctx context.Context, group string) ([]string, error)
+}
+
+// directories resolves the members_from of teams into GitHub logins.
+type directories struct {
+	ldap   directory
+	google directory
+	scim   directory
+
+	mapping identityMapper
+}
+
+// resolveMembersFrom adds the members of the directory groups declared with
+// members_from to the teams of all orgs.
+func resolveMembersFrom(ctx context.Context, cfg *org.FullConfig) error {
+	providers := cfg.IdentityProviders
+	if providers == nil {
+		providers = &org.IdentityProviders{}
+	}
+	d, err := newDirectories(ctx, providers)
+	if err != nil {
+		return err
+	}
+	for name, orgConfig := range cfg.Orgs {
+		if err := d.resolveTeams(ctx, name, orgConfig.Teams); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newDirectories(ctx context.Context, cfg *org.IdentityProviders) (*directories, error) {
+	mapping, err := newIdentityMapper(cfg.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	d := &directories{mapping: mapping}
+	if cfg.LDAP != nil {
+		d.ldap, err = newLDAPDirectory(*cfg.LDAP)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LDAP provider: %w", err)
+		}
+	}
+	if cfg.GoogleGroups != nil {
+		d.google, err = newGoogleDirectory(ctx, *cfg.GoogleGroups)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Google Groups provider: %w", err)
+		}
+	}
+	if cfg.SCIM != nil {
+		d.scim, err = newSCIMDirectory(*cfg.SCIM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCIM provider: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// resolveTeams adds the members of the directory groups of all teams, and of
+// their children, to their members.
+func (d *directories) resolveTeams(ctx context.Context, orgName string, teams map[string]org.Team) error {
+	for name, team := range teams {
+		if err := d.resolveTeams(ctx, orgName, team.Children); err != nil {
+			return err
+		}
+		if len(team.MembersFrom) == 0 {
+			continue
+		}
+		logins, err := d.logins(ctx, team.MembersFrom)
+		if err != nil {
+			return fmt.Errorf("failed to resolve members of %s team %s: %w", orgName, name, err)
+		}
+		added := logins.Difference(sets.New[string](team.Members...)).Difference(sets.New[string](team.Maintainers...))
+		logrus.WithFields(logrus.Fields{"org": orgName, "team": name, "members": sets.List(added)}).Info("Adding members from directory groups.")
+		team.Members = append(team.Members, sets.List(added)...)
+		teams[name] = team
+	}
+	return nil
+}
+
+func (d *directories) logins(ctx context.Context, sources []org.MemberSource) (sets.Set[string], error) {
+	logins := sets.New[string]()
+	for _, source := range sources {
+		dir, group, err := d.directoryFor(source)
+		if err != nil {
+			return nil, err
+		}
+		identities, err := dir.members(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of %s: %w", group, err)
+		}
+		for _, identity := range identities {
+			if login, ok := d.mapping.login(identity); ok {
+				logins.Insert(login)
+			} else {
+				logrus.WithFields(logrus.Fields{"group": group, "identity": identity}).Debug("Skipping unmapped identity.")
+			}
+		}
+	}
+	return logins, nil
+}
+
+func (d *directories) directoryFor(source org.MemberSource) (directory, string, error) {
+	var dir directory
+	var group, kind string
+	var set int
+	for _, candidate := range []struct {
+		dir   directory
+		group string
+		kind  string
+	}{
+		{dir: d.ldap, group: source.LDAP, kind: "ldap"},
+		{dir: d.google, group: source.GoogleGroup, kind: "google_groups"},
+		{dir: d.scim, group: source.SCIM, kind: "scim"},
+	} {
+		if candidate.group != "" {
+			dir, group, kind = candidate.dir, candidate.group, candidate.kind
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, "", errors.New("exactly one of ldap, google_group and scim must be set in members_from")
+	}
+	if dir == nil {
+		return nil, "", fmt.Errorf("group %s needs the %s identity provider, which is not configured", group, kind)
+	}
+	return dir, group, nil
+}
+
+// identityMapper maps directory identities to GitHub logins.
+type identityMapper struct {
+	users       map[string]string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func newIdentityMapper(m org.IdentityMapping) (identityMapper, error) {
+	mapper := identityMapper{users: m.Users, replacement: m.Replacement}
+	if m.Pattern != "" {
+		re, err := regexp.Compile(m.Pattern)
+		if err != nil {
+			return mapper, fmt.Errorf("invalid mapping pattern: %w", err)
+		}
+		mapper.pattern = re
+	}
+	return mapper, nil
+}
+
+func (m identityMapper) login(identity string) (string, bool) {
+	if login, ok := m.users[identity]; ok {
+		return login, login != ""
+	}
+	if m.pattern == nil {
+		return identity, true
+	}
+	if !m.pattern.MatchString(identity) {
+		return "", false
+	}
+	login := m.pattern.ReplaceAllString(identity, m.replacement)
+	return login, login != ""
+}
+
+func readSecret(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+type ldapDirectory struct {
+	cfg      org.LDAPProvider
+	password string
+}
+
+func newLDAPDirectory(cfg org.LDAPProvider) (*ldapDirectory, error) {
+	if cfg.URL == "" || cfg.BaseDN == "" {
+		return nil, errors.New("url and base_dn are required")
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(memberOf=%s)"
+	}
+	if cfg.UserAttribute == "" {
+		cfg.UserAttribute = "uid"
+	}
+	d := &ldapDirectory{cfg: cfg}
+	if cfg.BindPasswordFile != "" {
+		password, err := readSecret(cfg.BindPasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bind password: %w", err)
+		}
+		d.password = password
+	}
+	return d, nil
+}
+
+func (d *ldapDirectory) members(_ context.Context, group string) ([]string, error) {
+	conn, err := ldap.DialURL(d.cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+	if d.cfg.BindDN != "" {
+		if err := conn.Bind(d.cfg.BindDN, d.password); err != nil {
+			return nil, fmt.Errorf("failed to bind: %w", err)
+		}
+	}
+	result, err := conn.SearchWithPaging(ldap.NewSearchRequest(
+		d.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(d.cfg.UserFilter, ldap.EscapeFilter(group)),
+		[]string{d.cfg.UserAttribute}, nil,
+	), 500)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	var identities []string
+	for _, entry := range result.Entries {
+		if identity := entry.GetAttributeValue(d.cfg.UserAttribute); identity != "" {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+type googleDirectory struct {
+	service *admin.Service
+}
+
+func newGoogleDirectory(ctx context.Context, cfg org.GoogleProvider) (*googleDirectory, error) {
+	raw, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	jwt, err := google.JWTConfigFromJSON(raw, admin.AdminDirectoryGroupMemberReadonlyScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	// Reading groups requires impersonating a Workspace admin.
+	jwt.Subject = cfg.Subject
+	service, err := admin.NewService(ctx, option.WithHTTPClient(jwt.Client(ctx)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory client: %w", err)
+	}
+	return &googleDirectory{service: service}, nil
+}
+
+func (d *googleDirectory) members(ctx context.Context, group string) ([]string, error) {
+	var identities []string
+	err := d.service.Members.List(group).IncludeDerivedMembership(true).Pages(ctx, func(page *admin.Members) error {
+		for _, member := range page.Members {
+			if member.Type == "USER" {
+				identities = append(identities, member.Email)
+			}
+		}
+		return nil
+	})
+	return identities, err
+}
+
+type scimDirectory struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newSCIMDirectory(cfg org.SCIMProvider) (*scimDirectory, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("url is required")
+	}
+	token, err := readSecret(cfg.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	return &scimDirectory{url: strings.TrimSuffix(cfg.URL, "/"), token: token, client: &http.Client{}}, nil
+}
+
+type scimGroups struct {
+	Resources []struct {
+		Members []struct {
+			Value string `json:"value"`
+		} `json:"members"`
+	} `json:"Resources"`
+}
+
+type scimUser struct {
+	UserName string `json:"userName"`
+}
+
+func (d *scimDirectory) get(ctx context.Context, path string, query url.Values, into interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+d.token)
+	req.Header.Set("Accept", "application/scim+json")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(into)
+}
+
+func (d *scimDirectory) members(ctx context.Context, group string) ([]string, error) {
+	var groups scimGroups
+	filter := fmt.Sprintf("displayName eq %q", group)
+	if err := d.get(ctx, "/Groups", url.Values{"filter": []string{filter}}, &groups); err != nil {
+		return nil, err
+	}
+	if len(groups.Resources) != 1 {
+		return nil, fmt.Errorf("found %d groups named %s", len(groups.Resources), group)
+	}
+	var identities []string
+	for _, member := range groups.Resources[0].Members {
+		var user scimUser
+		if err := d.get(ctx, "/Users/"+url.PathEscape(member.Value), url.Values{"attributes": []string{"userName"}}, &user); err != nil {
+			return nil, err
+		}
+		identities = append(identities, user.UserName)
+	}
+	return identities, nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/config/org"
+)
+
+type fakeDirectory map[string][]string
+
+func (f fakeDirectory) members(_ context.Context, group string) ([]string, error) {
+	members, ok := f[group]
+	if !ok {
+		return nil, fmt.Errorf("no group %s", group)
+	}
+	return members, nil
+}
+
+func TestIdentityMapper(t *testing.T) {
+	mapper, err := newIdentityMapper(org.IdentityMapping{
+		Users:       map[string]string{"alice@example.com": "alice-gh", "bot@example.com": ""},
+		Pattern:     `^(.+)@example\.com$`,
+		Replacement: "${1}-corp",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testCases := []struct {
+		identity string
+		login    string
+		ok       bool
+	}{
+		{identity: "alice@example.com", login: "alice-gh", ok: true},
+		{identity: "bob@example.com", login: "bob-corp", ok: true},
+		{identity: "bot@example.com", ok: false},
+		{identity: "mallory@elsewhere.com", ok: false},
+	}
+	for _, tc := range testCases {
+		login, ok := mapper.login(tc.identity)
+		if login != tc.login || ok != tc.ok {
+			t.Errorf("%s: expected (%q, %t), got (%q, %t)", tc.identity, tc.login, tc.ok, login, ok)
+		}
+	}
+
+	identity, err := newIdentityMapper(org.IdentityMapping{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if login, ok := identity.login("carol"); login != "carol" || !ok {
+		t.Errorf("expected identities to be used as logins without a pattern, got (%q, %t)", login, ok)
+	}
+}
+
+func TestResolveTeams(t *testing.T) {
+	d := &directories{
+		ldap:   fakeDirectory{"cn=eng,dc=example,dc=com": {"alice", "bob", "carol"}},
+		google: fakeDirectory{"sre@example.com": {"dave", "alice"}},
+	}
+	testCases := []struct {
+		name        string
+		teams       map[string]org.Team
+		expected    map[string][]string
+		expectedErr bool
+	}{
+		{
+			name: "members are added from all sources",
+			teams: map[string]org.Team{
+				"eng": {
+					Members:     []string{"erin"},
+					Maintainers: []string{"carol"},
+					MembersFrom: []org.MemberSource{{LDAP: "cn=eng,dc=example,dc=com"}, {GoogleGroup: "sre@example.com"}},
+					Children: map[string]org.Team{
+						"sre": {MembersFrom: []org.MemberSource{{GoogleGroup: "sre@example.com"}}},
+					},
+				},
+				"static": {Members: []string{"frank"}},
+			},
+			expected: map[string][]string{
+				"eng":    {"erin", "alice", "bob", "dave"},
+				"sre":    {"alice", "dave"},
+				"static": {"frank"},
+			},
+		},
+		{
+			name: "provider is not configured",
+			teams: map[string]org.Team{
+				"eng": {MembersFrom: []org.MemberSource{{SCIM: "eng"}}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "source sets several groups",
+			teams: map[string]org.Team{
+				"eng": {MembersFrom: []org.MemberSource{{LDAP: "cn=eng,dc=example,dc=com", GoogleGroup: "sre@example.com"}}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "group does not exist",
+			teams: map[string]org.Team{
+				"eng": {MembersFrom: []org.MemberSource{{LDAP: "cn=gone,dc=example,dc=com"}}},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := d.resolveTeams(context.Background(), "org", tc.teams)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			actual := map[string][]string{}
+			var collect func(map[string]org.Team)
+			collect = func(teams map[string]org.Team) {
+				for name, team := range teams {
+					actual[name] = team.Members
+					collect(team.Children)
+				}
+			}
+			collect(tc.teams)
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("members differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSCIMDirectory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/scim/v2/Groups":
+			if filter := r.URL.Query().Get("filter"); filter != `displayName eq "eng"` {
+				fmt.Fprint(w, `{"Resources": []}`)
+				return
+			}
+			fmt.Fprint(w, `{"Resources": [{"members": [{"value": "1"}, {"value": "2"}]}]}`)
+		case "/scim/v2/Users/1":
+			fmt.Fprint(w, `{"userName": "alice@example.com"}`)
+		case "/scim/v2/Users/2":
+			fmt.Fprint(w, `{"userName": "bob@example.com"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("token\n"), 0600); err != nil {
+		t.Fatalf("failed to write token: %v", err)
+	}
+	d, err := newSCIMDirectory(org.SCIMProvider{URL: server.URL + "/scim/v2/", TokenFile: tokenFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	members, err := d.members(context.Background(), "eng")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"alice@example.com", "bob@example.com"}, members); diff != "" {
+		t.Errorf("members differ (-want +got):\n%s", diff)
+	}
+	if _, err := d.members(context.Background(), "gone"); err == nil {
+		t.Error("expected an error for an unknown group")
+	}
+}
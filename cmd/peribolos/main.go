@@ -17,6 +17,8 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -40,6 +42,8 @@ const (
 	defaultDelta     = 0.25
 	defaultTokens    = 300
 	defaultBurst     = 100
+
+	outputJSON = "json"
 )
 
 type options struct {
@@ -63,6 +67,7 @@ type options struct {
 	allowRepoPublish  bool
 	github            flagutil.GitHubOptions
 
+	output   string
 	logLevel string
 }
 
@@ -94,6 +99,7 @@ func (o *options) parseArgs(flags *flag.FlagSet, args []string) error {
 	flags.BoolVar(&o.fixRepos, "fix-repos", false, "Create/update repositories if set")
 	flags.BoolVar(&o.allowRepoArchival, "allow-repo-archival", false, "If set, archiving repos is allowed while updating repos")
 	flags.BoolVar(&o.allowRepoPublish, "allow-repo-publish", false, "If set, making private repos public is allowed while updating repos")
+	flags.StringVar(&o.output, "output", "", "Print the changes made, or that would be made without --confirm, to stdout in this format. Only json is supported")
 	flags.StringVar(&o.logLevel, "log-level", logrus.InfoLevel.String(), fmt.Sprintf("Logging level, one of %v", logrus.AllLevels))
 	o.github.AddCustomizedFlags(flags, flagutil.ThrottlerDefaults(defaultTokens, defaultBurst))
 	if err := flags.Parse(args); err != nil {
@@ -130,6 +136,13 @@ func (o *options) parseArgs(flags *flag.FlagSet, args []string) error {
 		return errors.New("--dump-full can't be used without --dump")
 	}
 
+	if o.output != "" && o.output != outputJSON {
+		return fmt.Errorf("--output=%s is not supported, only json is", o.output)
+	}
+	if o.output != "" && o.dump != "" {
+		return errors.New("--output can't be used with --dump")
+	}
+
 	if o.fixTeamMembers && !o.fixTeams {
 		return fmt.Errorf("--fix-team-members requires --fix-teams")
 	}
@@ -189,12 +202,35 @@ func main() {
 		logrus.WithError(err).Fatal("Failed to load configuration")
 	}
 
+	if err := resolveMembersFrom(context.Background(), &cfg); err != nil {
+		logrus.WithError(err).Fatal("Failed to resolve team members from identity providers")
+	}
+
+	capabilities := []github.TokenCapability{github.CapabilityOrgMembers}
+	if o.fixTeams {
+		capabilities = append(capabilities, github.CapabilityTeams)
+	}
+	for name := range cfg.Orgs {
+		if err := githubClient.ProbeCapabilities(name, capabilities...); err != nil {
+			logrus.WithError(err).Fatalf("The GitHub token cannot configure the %s org.", name)
+		}
+	}
+
+	recorder := &changeRecorder{Client: githubClient, changes: []change{}}
 	for name, orgcfg := range cfg.Orgs {
-		if err := configureOrg(o, githubClient, name, orgcfg); err != nil {
+		if err := configureOrg(o, recorder, name, orgcfg); err != nil {
 			logrus.Fatalf("Configuration failed: %v", err)
 		}
 	}
 	logrus.Info("Finished syncing configuration.")
+
+	if o.output == outputJSON {
+		out, err := json.MarshalIndent(recorder.changes, "", "  ")
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to marshal changes.")
+		}
+		fmt.Println(string(out))
+	}
 }
 
 type dumpClient interface {
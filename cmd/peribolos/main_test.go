@@ -62,6 +62,26 @@ func TestOptions(t *testing.T) {
 			name: "reject --dump-full-config without --dump",
 			args: []string{"--config-path=foo", "--dump-full-config"},
 		},
+		{
+			name: "reject unknown --output",
+			args: []string{"--config-path=foo", "--output=yaml"},
+		},
+		{
+			name: "reject --output with --dump",
+			args: []string{"--dump=frogger", "--output=json"},
+		},
+		{
+			name: "json output",
+			args: []string{"--config-path=foo", "--output=json"},
+			expected: &options{
+				config:       "foo",
+				minAdmins:    defaultMinAdmins,
+				requireSelf:  true,
+				maximumDelta: defaultDelta,
+				output:       "json",
+				logLevel:     "info",
+			},
+		},
 		{
 			name: "maximal delta",
 			args: []string{"--config-path=foo", "--maximum-removal-delta=1"},
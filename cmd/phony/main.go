@@ -25,20 +25,48 @@ import (
 	"sigs.k8s.io/prow/pkg/phony"
 )
 
+const (
+	forgeGitHub = "github"
+	forgeGitLab = "gitlab"
+	forgeGerrit = "gerrit"
+)
+
 var (
 	address = flag.String("address", "http://localhost:8888/hook", "Where to send the fake hook.")
-	hmac    = flag.String("hmac", "abcde12345", "HMAC token to sign payload with.")
-	event   = flag.String("event", "ping", "Type of event to send, such as pull_request.")
-	payload = flag.String("payload", "", "File to send as payload. If unspecified, sends \"{}\".")
+	forge   = flag.String("forge", forgeGitHub, "Forge to impersonate, one of github, gitlab or gerrit.")
+	hmac    = flag.String("hmac", "abcde12345", "HMAC token to sign GitHub payloads with, or the secret token to send with GitLab webhooks.")
+	event   = flag.String("event", "ping", "Type of event to send, such as pull_request, \"Merge Request Hook\" for GitLab or patchset-created for Gerrit.")
+	payload = flag.String("payload", "", "File to send as payload. If unspecified, sends \"{}\", or synthesizes an event from the --gerrit-* flags for Gerrit.")
+
+	gerritUser     = flag.String("gerrit-user", "", "User to authenticate Gerrit events with basic auth as. No authentication if unset.")
+	gerritPassword = flag.String("gerrit-password", "", "Password to authenticate Gerrit events with.")
+	gerritChange   = phony.GerritChange{}
 )
 
+func init() {
+	flag.StringVar(&gerritChange.Instance, "gerrit-instance", "https://review.example.com", "Gerrit instance of the synthesized change.")
+	flag.StringVar(&gerritChange.Project, "gerrit-project", "project", "Project of the synthesized change.")
+	flag.StringVar(&gerritChange.Branch, "gerrit-branch", "master", "Target branch of the synthesized change.")
+	flag.IntVar(&gerritChange.Number, "gerrit-change", 1, "Number of the synthesized change.")
+	flag.IntVar(&gerritChange.Patchset, "gerrit-patchset", 1, "Patchset of the synthesized change.")
+	flag.StringVar(&gerritChange.Owner, "gerrit-owner", "developer", "Owner, uploader and commenter of the synthesized change.")
+	flag.StringVar(&gerritChange.Comment, "gerrit-comment", "/test all", "Comment of synthesized comment-added events.")
+}
+
 func main() {
 	flag.Parse()
 
 	var body []byte
-	if *payload == "" {
+	switch {
+	case *payload == "" && *forge == forgeGerrit:
+		d, err := phony.NewGerritEvent(*event, gerritChange)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not synthesize Gerrit event.")
+		}
+		body = d
+	case *payload == "":
 		body = []byte("{}")
-	} else {
+	default:
 		d, err := os.ReadFile(*payload)
 		if err != nil {
 			logrus.WithError(err).Fatal("Could not read payload file.")
@@ -46,7 +74,18 @@ func main() {
 		body = d
 	}
 
-	if err := phony.SendHook(*address, *event, body, []byte(*hmac)); err != nil {
+	var err error
+	switch *forge {
+	case forgeGitHub:
+		err = phony.SendHook(*address, *event, body, []byte(*hmac))
+	case forgeGitLab:
+		err = phony.SendGitLabHook(*address, *event, body, []byte(*hmac))
+	case forgeGerrit:
+		err = phony.SendGerritEvent(*address, body, *gerritUser, *gerritPassword)
+	default:
+		logrus.Fatalf("Unknown --forge %q, must be one of github, gitlab or gerrit.", *forge)
+	}
+	if err != nil {
 		logrus.WithError(err).Error("Error sending hook.")
 	} else {
 		logrus.Info("Hook sent.")
@@ -35,19 +35,24 @@ import (
 	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 	"sigs.k8s.io/prow/pkg/scheduler"
 
+	"sigs.k8s.io/prow/pkg/bisection"
+	"sigs.k8s.io/prow/pkg/clusterregistration"
 	"sigs.k8s.io/prow/pkg/flagutil"
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
+	"sigs.k8s.io/prow/pkg/flakiness"
+	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/interrupts"
 	"sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/metrics"
 	"sigs.k8s.io/prow/pkg/plank"
 
+	"sigs.k8s.io/prow/pkg/tracing"
 	_ "sigs.k8s.io/prow/pkg/version"
 )
 
-var allControllers = sets.New(plank.ControllerName, scheduler.ControllerName)
+var allControllers = sets.New(plank.ControllerName, scheduler.ControllerName, clusterregistration.ControllerName, flakiness.ControllerName, bisection.ControllerName)
 
 type options struct {
 	totURL string
@@ -60,6 +65,7 @@ type options struct {
 	kubernetes             prowflagutil.KubernetesOptions
 	github                 prowflagutil.GitHubOptions // TODO(fejta): remove
 	instrumentationOptions prowflagutil.InstrumentationOptions
+	tracing                prowflagutil.TracingOptions
 	storage                prowflagutil.StorageClientOptions
 }
 
@@ -72,7 +78,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.Var(&o.enabledControllers, "enable-controller", fmt.Sprintf("Controllers to enable. Can be passed multiple times. Defaults to controllers: %s", plank.ControllerName))
 
 	fs.BoolVar(&o.dryRun, "dry-run", true, "Whether or not to make mutating API calls to GitHub.")
-	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.instrumentationOptions, &o.config, &o.storage} {
+	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.instrumentationOptions, &o.tracing, &o.config, &o.storage} {
 		group.AddFlags(fs)
 	}
 
@@ -84,7 +90,7 @@ func (o *options) Validate() error {
 	o.github.AllowAnonymous = true
 
 	var errs []error
-	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.instrumentationOptions, &o.config, &o.storage} {
+	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.instrumentationOptions, &o.tracing, &o.config, &o.storage} {
 		if err := group.Validate(o.dryRun); err != nil {
 			errs = append(errs, err)
 		}
@@ -119,6 +125,15 @@ func main() {
 
 	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort) // Start liveness endpoint
 	pprof.Instrument(o.instrumentationOptions)
+	shutdownTracing, err := tracing.Setup(context.Background(), "prow-controller-manager", o.tracing)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error setting up tracing.")
+	}
+	interrupts.OnInterrupt(func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.WithError(err).Warn("Failed to flush traces.")
+		}
+	})
 
 	configAgent, err := o.config.ConfigAgent()
 	if err != nil {
@@ -140,6 +155,12 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting infrastructure cluster config.")
 	}
+	infrastructureClient, err := o.kubernetes.InfrastructureClusterClient(o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Kubernetes client for infrastructure cluster.")
+	}
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(cfg))
+	health.AddReadinessChecks(pjutil.KubernetesHealthCheck(infrastructureClient.Discovery().RESTClient()))
 	opts := manager.Options{
 		MetricsBindAddress:      "0",
 		Namespace:               cfg().ProwJobNamespace,
@@ -210,6 +231,32 @@ func main() {
 		}
 	}
 
+	if enabledControllersSet.Has(clusterregistration.ControllerName) {
+		if err := clusterregistration.Add(mgr); err != nil {
+			logrus.WithError(err).Fatal("Failed to add cluster registration controller to manager")
+		}
+	}
+
+	var githubClient github.Client
+	if enabledControllersSet.HasAny(flakiness.ControllerName, bisection.ControllerName) {
+		githubClient, err = o.github.GitHubClient(o.dryRun)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error getting GitHub client.")
+		}
+	}
+
+	if enabledControllersSet.Has(flakiness.ControllerName) {
+		if err := flakiness.Add(mgr, cfg, opener, githubClient); err != nil {
+			logrus.WithError(err).Fatal("Failed to add flakiness controller to manager")
+		}
+	}
+
+	if enabledControllersSet.Has(bisection.ControllerName) {
+		if err := bisection.Add(mgr, cfg, githubClient); err != nil {
+			logrus.WithError(err).Fatal("Failed to add bisection controller to manager")
+		}
+	}
+
 	// Expose prometheus metrics
 	metrics.ExposeMetrics("plank", cfg().PushGateway, o.instrumentationOptions.MetricsPort)
 	// Serve readiness endpoint
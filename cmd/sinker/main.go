@@ -46,6 +46,7 @@ import (
 	"sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
 	"sigs.k8s.io/prow/pkg/interrupts"
+	"sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/metrics"
@@ -59,6 +60,8 @@ type options struct {
 	dryRun                 bool
 	kubernetes             flagutil.KubernetesOptions
 	instrumentationOptions flagutil.InstrumentationOptions
+	storage                flagutil.StorageClientOptions
+	prowJobSnapshotPath    string
 }
 
 const (
@@ -75,10 +78,12 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.BoolVar(&o.runOnce, "run-once", false, "If true, run only once then quit.")
 
 	fs.BoolVar(&o.dryRun, "dry-run", true, "Whether or not to make mutating API calls to Kubernetes.")
+	fs.StringVar(&o.prowJobSnapshotPath, "prowjob-snapshot-path", "", "Storage path (e.g. gs://bucket/prowjobs.json) to write a snapshot of all ProwJobs to on every resync, for Deck to serve in storage-only mode. Disabled if empty.")
 
 	o.config.AddFlags(fs)
 	o.kubernetes.AddFlags(fs)
 	o.instrumentationOptions.AddFlags(fs)
+	o.storage.AddFlags(fs)
 	fs.Parse(args)
 	return o
 }
@@ -92,6 +97,10 @@ func (o *options) Validate() error {
 		return err
 	}
 
+	if err := o.storage.Validate(o.dryRun); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -105,6 +114,7 @@ func main() {
 
 	defer interrupts.WaitForGracefulShutdown()
 
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
 	pprof.Instrument(o.instrumentationOptions)
 
 	configAgent, err := o.config.ConfigAgent()
@@ -112,6 +122,7 @@ func main() {
 		logrus.WithError(err).Fatal("Error starting config agent.")
 	}
 	cfg := configAgent.Config
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(cfg))
 	o.kubernetes.SetDisabledClusters(sets.New[string](cfg().DisabledClusters...))
 
 	if o.config.JobConfigPath != "" {
@@ -126,6 +137,11 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting config for infastructure cluster")
 	}
+	infrastructureClient, err := o.kubernetes.InfrastructureClusterClient(o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Kubernetes client for infrastructure cluster.")
+	}
+	health.AddReadinessChecks(pjutil.KubernetesHealthCheck(infrastructureClient.Discovery().RESTClient()))
 
 	// The watch apimachinery doesn't support restarts, so just exit the binary if a kubeconfig changes
 	// to make the kubelet restart us.
@@ -196,9 +212,18 @@ func main() {
 		config:        cfg,
 		runOnce:       o.runOnce,
 	}
+	if o.prowJobSnapshotPath != "" {
+		opener, err := o.storage.StorageClient(context.Background())
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating opener for ProwJob snapshots.")
+		}
+		c.opener = opener
+		c.prowJobSnapshotPath = o.prowJobSnapshotPath
+	}
 	if err := mgr.Add(&c); err != nil {
 		logrus.WithError(err).Fatal("failed to add controller to manager")
 	}
+	health.ServeReady()
 	if err := mgr.Start(interrupts.Context()); err != nil {
 		logrus.WithError(err).Fatal("failed to start manager")
 	}
@@ -212,6 +237,10 @@ type controller struct {
 	podClients    map[string]ctrlruntimeclient.Client
 	config        config.Getter
 	runOnce       bool
+	// opener and prowJobSnapshotPath are only set if ProwJob snapshots are
+	// enabled.
+	opener              io.Opener
+	prowJobSnapshotPath string
 }
 
 func (c *controller) Start(ctx context.Context) error {
@@ -340,6 +369,7 @@ func (c *controller) clean() {
 		return
 	}
 	metrics.prowJobsCreated = len(prowJobs.Items)
+	c.snapshotProwJobs(prowJobs)
 
 	// Only delete pod if its prowjob is marked as finished
 	pjMap := map[string]*prowapi.ProwJob{}
@@ -498,6 +528,16 @@ func (c *controller) clean() {
 	c.logger.Info("Sinker reconciliation complete.")
 }
 
+// snapshotProwJobs writes the ProwJobs to storage, if enabled.
+func (c *controller) snapshotProwJobs(prowJobs *prowapi.ProwJobList) {
+	if c.prowJobSnapshotPath == "" {
+		return
+	}
+	if err := pjutil.WriteProwJobSnapshot(c.ctx, c.opener, c.prowJobSnapshotPath, prowJobs); err != nil {
+		c.logger.WithError(err).Error("Error writing ProwJob snapshot.")
+	}
+}
+
 func (c *controller) cleanupKubernetesFinalizer(pod *corev1api.Pod, client ctrlruntimeclient.Client) error {
 
 	oldPod := pod.DeepCopy()
@@ -896,6 +896,15 @@ func TestFlags(t *testing.T) {
 				o.dryRun = true
 			},
 		},
+		{
+			name: "explicitly set --prowjob-snapshot-path",
+			args: map[string]string{
+				"--prowjob-snapshot-path": "gs://bucket/prowjobs.json",
+			},
+			expected: func(o *options) {
+				o.prowJobSnapshotPath = "gs://bucket/prowjobs.json"
+			},
+		},
 	}
 
 	for _, tc := range cases {
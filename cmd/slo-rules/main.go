@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// slo-rules generates the Prometheus recording and alerting rules for the job
+// success rate SLOs and the Tide merge latency SLOs of a Prow config.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/prow/pkg/config"
+	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
+	"sigs.k8s.io/prow/pkg/logrusutil"
+	"sigs.k8s.io/prow/pkg/slo"
+)
+
+type options struct {
+	config configflagutil.ConfigOptions
+	output string
+}
+
+func gatherOptions(fs *flag.FlagSet, args ...string) options {
+	var o options
+	fs.StringVar(&o.output, "output", "", "Path to write the Prometheus rule file to. Defaults to stdout.")
+	o.config.AddFlags(fs)
+	fs.Parse(args)
+	return o
+}
+
+func (o *options) Validate() error {
+	return o.config.Validate(false)
+}
+
+func run(o options) error {
+	cfg, err := config.Load(o.config.ConfigPath, o.config.JobConfigPath, o.config.SupplementalProwConfigDirs.Strings(), o.config.SupplementalProwConfigsFileNameSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	rules, err := slo.Generate(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to generate rules: %w", err)
+	}
+	raw, err := yaml.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rules: %w", err)
+	}
+	if o.output == "" {
+		_, err = os.Stdout.Write(raw)
+		return err
+	}
+	return os.WriteFile(o.output, raw, 0644)
+}
+
+func main() {
+	logrusutil.ComponentInit()
+
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	if err := run(o); err != nil {
+		logrus.WithError(err).Fatal("Failed to generate SLO rules.")
+	}
+}
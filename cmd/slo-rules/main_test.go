@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/prow/pkg/slo"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	jobConfigPath := filepath.Join(dir, "jobs.yaml")
+	output := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(configPath, []byte("prowjob_namespace: prowjobs\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	jobs := `periodics:
+- name: ci-e2e
+  interval: 1h
+  annotations:
+    prow.k8s.io/slo-success-rate: "95%"
+  spec:
+    containers:
+    - image: alpine
+`
+	if err := os.WriteFile(jobConfigPath, []byte(jobs), 0644); err != nil {
+		t.Fatalf("failed to write job config: %v", err)
+	}
+
+	o := gatherOptions(flag.NewFlagSet("slo-rules", flag.ContinueOnError), "--config-path", configPath, "--job-config-path", jobConfigPath, "--output", output)
+	if err := o.Validate(); err != nil {
+		t.Fatalf("invalid options: %v", err)
+	}
+	if err := run(o); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+
+	raw, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read rules: %v", err)
+	}
+	var rules slo.RuleGroups
+	if err := yaml.Unmarshal(raw, &rules); err != nil {
+		t.Fatalf("failed to unmarshal rules: %v", err)
+	}
+	if len(rules.Groups) != 2 || len(rules.Groups[1].Rules) != 4 {
+		t.Errorf("expected the four burn rate alerts of the job, got %s", raw)
+	}
+}
@@ -19,11 +19,14 @@ package main
 import (
 	"context"
 	"flag"
+	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 
 	"sigs.k8s.io/prow/pkg/flagutil"
@@ -59,6 +62,11 @@ type options struct {
 	// a) the gcs credentials can write to this bucket
 	// b) the default acls do not expose any private info
 	statusURI string
+
+	// reportURI where Status-reconciler writes a report of its actions for every
+	// reconciled config change. Can be /local/path, gs://path/to/dir or s3://path/to/dir.
+	reportURI string
+	port      int
 }
 
 func gatherOptions(fs *flag.FlagSet, args ...string) options {
@@ -66,6 +74,8 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 
 	fs.StringVar(&o.statusURI, "status-path", "", "The /local/path, gs://path/to/object or s3://path/to/object to store status controller state. GCS writes will use the default object ACL for the bucket.")
 
+	fs.StringVar(&o.reportURI, "report-path", "", "The /local/path, gs://path/to/dir or s3://path/to/dir to write a JSON report of the actions taken (or planned, in dry-run mode) for every reconciled config change to.")
+	fs.IntVar(&o.port, "port", 8888, "Port to serve summaries of recent reports on at /reports.")
 	fs.BoolVar(&o.continueOnError, "continue-on-error", false, "Indicates that the migration should continue if context migration fails for an individual PR.")
 	fs.Var(&o.addedPresubmitDenylist, "denylist", "Org or org/repo to ignore new added presubmits for, set more than once to add more.")
 	fs.Var(&o.addedPresubmitDenylistAll, "denylist-all", "Org or org/repo to ignore reconciling, set more than once to add more.")
@@ -110,12 +120,14 @@ func main() {
 
 	defer interrupts.WaitForGracefulShutdown()
 
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
 	pprof.Instrument(o.instrumentationOptions)
 
 	configAgent, err := o.config.ConfigAgent()
 	if err != nil {
 		logrus.WithError(err).Fatal("Error starting config agent.")
 	}
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(configAgent.Config))
 
 	pluginAgent, err := o.pluginsConfig.PluginAgent()
 	if err != nil {
@@ -131,6 +143,11 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting kube client.")
 	}
+	infrastructureClient, err := o.kubernetes.InfrastructureClusterClient(o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Kubernetes client for infrastructure cluster.")
+	}
+	health.AddReadinessChecks(pjutil.KubernetesHealthCheck(infrastructureClient.Discovery().RESTClient()), pjutil.GitHubHealthCheck(githubClient))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -139,7 +156,13 @@ func main() {
 		logrus.WithError(err).Fatal("Cannot create opener")
 	}
 
-	c := statusreconciler.NewController(o.continueOnError, o.getDenyList(), o.getDenyListAll(), opener, o.config, o.statusURI, prowJobClient, githubClient, pluginAgent)
+	reporter := statusreconciler.NewReporter(opener, o.reportURI, o.dryRun)
+	mux := http.NewServeMux()
+	mux.Handle("/reports", reporter)
+	interrupts.ListenAndServe(&http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}, 5*time.Second)
+
+	c := statusreconciler.NewController(o.continueOnError, o.getDenyList(), o.getDenyListAll(), opener, o.config, o.statusURI, prowJobClient, githubClient, pluginAgent, reporter)
+	health.ServeReady()
 	interrupts.Run(func(ctx context.Context) {
 		c.Run(ctx)
 	})
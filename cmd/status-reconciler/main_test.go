@@ -84,6 +84,7 @@ func TestGatherOptions(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			expected := &options{
 				dryRun: true,
+				port:   8888,
 				config: configflagutil.ConfigOptions{
 					ConfigPath:                            "yo",
 					ConfigPathFlagName:                    "config-path",
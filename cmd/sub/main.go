@@ -122,8 +122,8 @@ func main() {
 		Reporter:      pubsub.NewReporter(configAgent.Config), // reuse crier reporter
 	}
 
-	if o.config.MoonrakerAddress != "" {
-		moonrakerClient, err := moonraker.NewClient(o.config.MoonrakerAddress, configAgent)
+	if o.config.MoonrakerEnabled() {
+		moonrakerClient, err := moonraker.NewInRepoConfigGetter(o.config.MoonrakerAddress, o.config.MoonrakerGRPCAddress, configAgent)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error getting Moonraker client.")
 		}
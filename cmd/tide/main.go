@@ -29,14 +29,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pjutil/pprof"
 
 	"sigs.k8s.io/prow/pkg/flagutil"
 	prowflagutil "sigs.k8s.io/prow/pkg/flagutil"
 	configflagutil "sigs.k8s.io/prow/pkg/flagutil/config"
+	gerritclient "sigs.k8s.io/prow/pkg/gerrit/client"
 	"sigs.k8s.io/prow/pkg/interrupts"
 	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/metrics"
+	"sigs.k8s.io/prow/pkg/moonraker"
 	"sigs.k8s.io/prow/pkg/tide"
 )
 
@@ -137,6 +140,7 @@ func main() {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
+	health := pjutil.NewHealthOnPort(o.instrumentationOptions.HealthPort)
 	pprof.Instrument(o.instrumentationOptions)
 
 	opener, err := o.storage.StorageClient(context.Background())
@@ -149,11 +153,17 @@ func main() {
 		logrus.WithError(err).Fatal("Error starting config agent.")
 	}
 	cfg := configAgent.Config
+	health.AddLivenessChecks(pjutil.ConfigHealthCheck(cfg))
 
 	kubeCfg, err := o.kubernetes.InfrastructureClusterConfig(o.dryRun)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting kubeconfig.")
 	}
+	infrastructureClient, err := o.kubernetes.InfrastructureClusterClient(o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Kubernetes client for infrastructure cluster.")
+	}
+	health.AddReadinessChecks(pjutil.KubernetesHealthCheck(infrastructureClient.Discovery().RESTClient()))
 	// Do not activate leader election here, as we do not use the `mgr` to control the lifecylcle of our cotrollers,
 	// this would just be a no-op.
 	mgr, err := manager.New(kubeCfg, manager.Options{Namespace: cfg().ProwJobNamespace, MetricsBindAddress: "0"})
@@ -191,6 +201,15 @@ func main() {
 		// changing the context format or starting Tide on a new repo.
 		githubSync.Throttle(o.syncThrottle, 3*tokensPerIteration(o.syncThrottle, cfg().Tide.SyncPeriod.Duration))
 		githubStatus.Throttle(o.statusThrottle, o.statusThrottle/2)
+		health.AddReadinessChecks(pjutil.GitHubHealthCheck(githubStatus))
+
+		var ircg config.InRepoConfigGetter
+		if o.config.MoonrakerEnabled() {
+			ircg, err = moonraker.NewInRepoConfigGetter(o.config.MoonrakerAddress, o.config.MoonrakerGRPCAddress, configAgent)
+			if err != nil {
+				logrus.WithError(err).Fatal("Error getting Moonraker client.")
+			}
+		}
 
 		c, err = tide.NewController(
 			githubSync,
@@ -198,6 +217,7 @@ func main() {
 			mgr,
 			cfg,
 			gitClient,
+			ircg,
 			o.maxRecordsPerPool,
 			opener,
 			o.historyURI,
@@ -209,6 +229,10 @@ func main() {
 			logrus.WithError(err).Fatal("Error creating Tide controller.")
 		}
 	case gerritProviderName:
+		gerritOpts, err := gerritclient.OptionsFromFlags(&o.gerrit)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error configuring gerrit client.")
+		}
 		c, err = tide.NewGerritController(
 			mgr,
 			configAgent,
@@ -222,6 +246,7 @@ func main() {
 			o.cookiefilePath,
 			o.gerrit.MaxQPS,
 			o.gerrit.MaxBurst,
+			gerritOpts...,
 		)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error creating Tide controller.")
@@ -259,6 +284,8 @@ func main() {
 	// Push metrics to the configured prometheus pushgateway endpoint or serve them
 	metrics.ExposeMetrics("tide", cfg().PushGateway, o.instrumentationOptions.MetricsPort)
 
+	health.ServeReady()
+
 	start := time.Now()
 	sync(c)
 	if o.runOnce {
@@ -113,6 +113,7 @@ func Test_gatherOptions(t *testing.T) {
 			}
 			expectedfs := flag.NewFlagSet("fake-flags", flag.PanicOnError)
 			expected.github.AddFlags(expectedfs)
+			expected.gerrit.AddFlags(expectedfs)
 			if tc.expected != nil {
 				tc.expected(expected)
 			}
@@ -55,7 +55,7 @@ func (wa *webhookAgent) serveMutate(w http.ResponseWriter, r *http.Request) {
 	}
 	var mutatedProwJobPatch []byte
 	if admissionRequest.Operation == "CREATE" {
-		mutatedProwJobPatch, err = generateMutatingPatch(&prowJob, wa.plank)
+		mutatedProwJobPatch, err = generateMutatingPatch(&prowJob, wa.config())
 		if err != nil {
 			logrus.WithError(err).Info("unable to return mutated prowjob patch")
 			http.Error(w, fmt.Sprintf("unable to return mutated prowjob patch %v", err), http.StatusInternalServerError)
@@ -91,20 +91,13 @@ func createMutatingAdmissionResponse(uid types.UID, patch []byte) *v1beta1.Admis
 	}
 }
 
-func generateMutatingPatch(prowJob *v1.ProwJob, plank config.Plank) ([]byte, error) {
-	var defDecorationConfig *v1.DecorationConfig
+// generateMutatingPatch returns a patch that applies the default decoration
+// config from the Prow config to a ProwJob, so that ProwJobs created through the
+// API get the same defaults as the ones created from the job config.
+func generateMutatingPatch(prowJob *v1.ProwJob, cfg *config.Config) ([]byte, error) {
 	var patchBytes []byte
-	if prowJob.Spec.Type == v1.PeriodicJob {
-		var repo string
-		if len(prowJob.Spec.ExtraRefs) > 0 {
-			repo = fmt.Sprintf("%s/%s", prowJob.Spec.ExtraRefs[0].Org, prowJob.Spec.ExtraRefs[0].Repo)
-		}
-		defDecorationConfig = plank.GuessDefaultDecorationConfigWithJobDC(repo, prowJob.Spec.Cluster, prowJob.Spec.DecorationConfig)
-	} else {
-		defDecorationConfig = plank.GuessDefaultDecorationConfig(prowJob.Spec.Refs.Repo, prowJob.Spec.Cluster)
-	}
 	prowJobCopy := prowJob.DeepCopy()
-	prowJobCopy.Spec.DecorationConfig = prowJobCopy.Spec.DecorationConfig.ApplyDefault(defDecorationConfig)
+	cfg.Plank.DefaultProwJobDecorationConfig(&prowJobCopy.Spec)
 	originalProwJobJSON, err := json.Marshal(prowJob)
 	if err != nil {
 		return nil, fmt.Errorf("unable to marshal prowjob %v", err)
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func TestGenerateMutatingPatch(t *testing.T) {
+	cfg := &config.Config{
+		ProwConfig: config.ProwConfig{
+			Plank: config.Plank{
+				DefaultDecorationConfigs: []*config.DefaultDecorationConfigEntry{
+					{
+						OrgRepo: "*",
+						Cluster: "*",
+						Config:  &v1.DecorationConfig{GCSConfiguration: &v1.GCSConfiguration{Bucket: "default-bucket"}},
+					},
+					{
+						OrgRepo: "org/repo",
+						Cluster: "*",
+						Config:  &v1.DecorationConfig{GCSConfiguration: &v1.GCSConfiguration{Bucket: "repo-bucket"}},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		spec     v1.ProwJobSpec
+		expected *v1.DecorationConfig
+	}{
+		{
+			name:     "decorated presubmit gets the defaults for its repository",
+			spec:     v1.ProwJobSpec{Type: v1.PresubmitJob, Agent: v1.KubernetesAgent, Refs: &v1.Refs{Org: "org", Repo: "repo"}, DecorationConfig: &v1.DecorationConfig{}},
+			expected: &v1.DecorationConfig{GCSConfiguration: &v1.GCSConfiguration{Bucket: "repo-bucket"}},
+		},
+		{
+			name:     "decorated periodic without extra refs gets the global defaults",
+			spec:     v1.ProwJobSpec{Type: v1.PeriodicJob, Agent: v1.KubernetesAgent, DecorationConfig: &v1.DecorationConfig{}},
+			expected: &v1.DecorationConfig{GCSConfiguration: &v1.GCSConfiguration{Bucket: "default-bucket"}},
+		},
+		{
+			name: "undecorated job is left alone",
+			spec: v1.ProwJobSpec{Type: v1.PresubmitJob, Agent: v1.KubernetesAgent, Refs: &v1.Refs{Org: "org", Repo: "repo"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prowJob := &v1.ProwJob{Spec: tc.spec}
+			patch, err := generateMutatingPatch(prowJob, cfg)
+			if err != nil {
+				t.Fatalf("failed to generate patch: %v", err)
+			}
+			decoded, err := jsonpatch.DecodePatch(patch)
+			if err != nil {
+				t.Fatalf("failed to decode patch: %v", err)
+			}
+			original, err := json.Marshal(prowJob)
+			if err != nil {
+				t.Fatalf("failed to marshal prowjob: %v", err)
+			}
+			patched, err := decoded.Apply(original)
+			if err != nil {
+				t.Fatalf("failed to apply patch: %v", err)
+			}
+			var actual v1.ProwJob
+			if err := json.Unmarshal(patched, &actual); err != nil {
+				t.Fatalf("failed to unmarshal patched prowjob: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual.Spec.DecorationConfig); diff != "" {
+				t.Errorf("decoration config differs from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
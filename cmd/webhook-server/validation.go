@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/plank"
 )
@@ -64,7 +65,7 @@ func (wa *webhookAgent) serveValidate(w http.ResponseWriter, r *http.Request) {
 	}
 	var admissionResponse *v1beta1.AdmissionResponse
 	if admissionRequest.Operation == "CREATE" {
-		if err := validateProwJobClusterOnCreate(prowJob, wa.statuses); err != nil {
+		if err := validateProwJobOnCreate(prowJob, wa.statuses, wa.config()); err != nil {
 			admissionResponse = createValidatingAdmissionResponse(admissionRequest.UID, err)
 		} else {
 			admissionResponse = createValidatingAdmissionResponse(admissionRequest.UID, nil)
@@ -84,6 +85,55 @@ func (wa *webhookAgent) serveValidate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// validateProwJobOnCreate applies the checks that jobs get when the job config
+// is loaded to ProwJobs, as they are not necessarily created from the job
+// config, e.g. when external systems create them through the API.
+func validateProwJobOnCreate(prowJob v1.ProwJob, statuses map[string]plank.ClusterStatus, cfg *config.Config) error {
+	if err := validateProwJobClusterOnCreate(prowJob, statuses); err != nil {
+		return err
+	}
+	if err := validateProwJobClusterAllowed(prowJob, cfg); err != nil {
+		return err
+	}
+	if err := cfg.ValidateProwJobSpec(prowJob.Spec); err != nil {
+		return fmt.Errorf("%s: %w", prowJob.Name, err)
+	}
+	return nil
+}
+
+// validateProwJobClusterAllowed restricts ProwJobs of jobs that are not in the
+// job config to the clusters that the in-repo config allows for their repository.
+func validateProwJobClusterAllowed(prowJob v1.ProwJob, cfg *config.Config) error {
+	if prowJob.Spec.Agent != v1.KubernetesAgent || prowJob.Spec.Refs == nil || isStaticJob(prowJob.Spec.Job, &cfg.JobConfig) {
+		return nil
+	}
+	identifier := prowJob.Spec.Refs.OrgRepoString()
+	if !cfg.InRepoConfigAllowsCluster(prowJob.ClusterAlias(), identifier) {
+		return fmt.Errorf("%s: cluster %q is not allowed for repository %q", prowJob.Name, prowJob.ClusterAlias(), identifier)
+	}
+	return nil
+}
+
+// isStaticJob returns whether a job with the given name is in the job config.
+func isStaticJob(name string, jc *config.JobConfig) bool {
+	for _, p := range jc.AllPeriodics() {
+		if p.Name == name {
+			return true
+		}
+	}
+	for _, p := range jc.AllStaticPresubmits(nil) {
+		if p.Name == name {
+			return true
+		}
+	}
+	for _, p := range jc.AllStaticPostsubmits(nil) {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func validateProwJobClusterOnCreate(prowJob v1.ProwJob, statuses map[string]plank.ClusterStatus) error {
 	if prowJob.Spec.Cluster != "" && prowJob.Spec.Cluster != kube.DefaultClusterAlias && agentsNotSupportingCluster.Has(string(prowJob.Spec.Agent)) {
 		return fmt.Errorf("%s: cannot set cluster field if agent is %s", prowJob.Name, prowJob.Spec.Agent)
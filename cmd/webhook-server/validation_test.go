@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/plank"
+)
+
+func TestValidateProwJobOnCreate(t *testing.T) {
+	cfg := &config.Config{
+		ProwConfig: config.ProwConfig{
+			PodNamespace: "test-pods",
+			InRepoConfig: config.InRepoConfig{
+				AllowedClusters: map[string][]string{"org/repo": {"trusted"}},
+			},
+		},
+		JobConfig: config.JobConfig{
+			Periodics: []config.Periodic{{JobBase: config.JobBase{Name: "configured-job"}}},
+		},
+	}
+	statuses := map[string]plank.ClusterStatus{
+		"default": plank.ClusterStatusReachable,
+		"trusted": plank.ClusterStatusReachable,
+		"other":   plank.ClusterStatusReachable,
+	}
+	prowJob := func(modify func(*v1.ProwJob)) v1.ProwJob {
+		pj := v1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "pj"},
+			Spec: v1.ProwJobSpec{
+				Type:      v1.PresubmitJob,
+				Agent:     v1.KubernetesAgent,
+				Cluster:   "trusted",
+				Namespace: "test-pods",
+				Job:       "external-job",
+				Refs:      &v1.Refs{Org: "org", Repo: "repo"},
+				PodSpec:   &corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Image: "test"}}},
+			},
+		}
+		if modify != nil {
+			modify(&pj)
+		}
+		return pj
+	}
+
+	testCases := []struct {
+		name        string
+		prowJob     v1.ProwJob
+		expectError bool
+	}{
+		{
+			name:    "valid prowjob",
+			prowJob: prowJob(nil),
+		},
+		{
+			name: "unknown cluster",
+			prowJob: prowJob(func(pj *v1.ProwJob) {
+				pj.Spec.Cluster = "unknown"
+			}),
+			expectError: true,
+		},
+		{
+			name: "cluster not allowed for the repository",
+			prowJob: prowJob(func(pj *v1.ProwJob) {
+				pj.Spec.Cluster = "other"
+			}),
+			expectError: true,
+		},
+		{
+			name: "configured job may use any cluster",
+			prowJob: prowJob(func(pj *v1.ProwJob) {
+				pj.Spec.Cluster = "other"
+				pj.Spec.Job = "configured-job"
+			}),
+		},
+		{
+			name: "kubernetes agent without pod spec",
+			prowJob: prowJob(func(pj *v1.ProwJob) {
+				pj.Spec.PodSpec = nil
+			}),
+			expectError: true,
+		},
+		{
+			name: "reserved env var",
+			prowJob: prowJob(func(pj *v1.ProwJob) {
+				pj.Spec.PodSpec.Containers[0].Env = []corev1.EnvVar{{Name: "JOB_NAME", Value: "spoofed"}}
+			}),
+			expectError: true,
+		},
+		{
+			name: "rerun auth config allowing anyone and listing users",
+			prowJob: prowJob(func(pj *v1.ProwJob) {
+				pj.Spec.RerunAuthConfig = &v1.RerunAuthConfig{AllowAnyone: true, GitHubUsers: []string{"user"}}
+			}),
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateProwJobOnCreate(tc.prowJob, statuses, cfg)
+			if tc.expectError && err == nil {
+				t.Error("expected an error, got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
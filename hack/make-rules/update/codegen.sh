@@ -250,7 +250,7 @@ gen-prowjob-crd(){
     | $SED '/^$/d' \
     | $SED '/^spec:.*/a  \  preserveUnknownFields: false' \
     | $SED '/^  annotations.*/a  \    api-approved.kubernetes.io: https://github.com/kubernetes/test-infra/pull/8669' \
-    | $SED '/^          status:/r'<(cat<<EOF
+    | $SED -e '/^  name: prowjobs.prow.k8s.io$/,${' -e '/^          status:/r'<(cat<<EOF
             anyOf:
             - not:
                 properties:
@@ -262,7 +262,7 @@ gen-prowjob-crd(){
             - required:
               - completionTime
 EOF
-    ) > ./config/prow/cluster/prowjob-crd/prowjob_customresourcedefinition.yaml
+    ) -e '}' > ./config/prow/cluster/prowjob-crd/prowjob_customresourcedefinition.yaml
   copyfiles "./config/prow/cluster/prowjob-crd" "prowjob_customresourcedefinition.yaml"
   unset HOME
 }
@@ -0,0 +1,155 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterAuthMethod is how Prow authenticates against a registered build cluster.
+type ClusterAuthMethod string
+
+const (
+	// TokenClusterAuth authenticates with the bearer token stored under the
+	// "token" key of the auth secret.
+	TokenClusterAuth ClusterAuthMethod = "token"
+	// ClientCertificateClusterAuth authenticates with the client certificate
+	// and key stored under the "tls.crt" and "tls.key" keys of the auth secret.
+	ClientCertificateClusterAuth ClusterAuthMethod = "client_certificate"
+)
+
+// ClusterRegistrationReady is the condition reporting whether Prow could
+// connect to a registered build cluster the last time it was probed.
+const ClusterRegistrationReady = "Ready"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterRegistration registers a build cluster with Prow. The name of the
+// registration is the cluster alias that jobs use in their `cluster` field.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.spec.endpoint`,description="The API server of the build cluster."
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="Whether Prow can connect to the build cluster."
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.server_version`,description="The Kubernetes version of the build cluster."
+type ClusterRegistration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRegistrationSpec   `json:"spec,omitempty"`
+	Status ClusterRegistrationStatus `json:"status,omitempty"`
+}
+
+// ClusterRegistrationSpec describes how to connect to a build cluster.
+type ClusterRegistrationSpec struct {
+	// Endpoint is the URL of the API server of the build cluster.
+	// +kubebuilder:validation:Required
+	Endpoint string `json:"endpoint"`
+	// CAData holds the PEM-encoded certificate authority bundle used to
+	// verify the API server. The system roots are used when it is empty.
+	CAData []byte `json:"ca_data,omitempty"`
+	// Auth configures the credentials used to connect to the build cluster.
+	Auth ClusterAuth `json:"auth"`
+	// Capabilities lists features of the build cluster, like the node
+	// architectures or accelerators it offers.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Capacity is the number of test pods the build cluster is meant to run
+	// at the same time. Zero means unknown.
+	// +kubebuilder:validation:Minimum=0
+	Capacity int `json:"capacity,omitempty"`
+}
+
+// ClusterAuth references the credentials for a build cluster.
+type ClusterAuth struct {
+	// Method is the authentication method.
+	// +kubebuilder:validation:Enum=token;client_certificate
+	Method ClusterAuthMethod `json:"method"`
+	// SecretName is the name of the secret holding the credentials. It
+	// must live in the namespace of the registration.
+	SecretName string `json:"secret_name"`
+}
+
+// ClusterRegistrationStatus is the observed state of a registered build cluster.
+type ClusterRegistrationStatus struct {
+	// ObservedGeneration is the generation of the registration that was
+	// last probed.
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+	// ServerVersion is the Kubernetes version reported by the build cluster.
+	ServerVersion string `json:"server_version,omitempty"`
+	// LastProbeTime is when the build cluster was last probed.
+	LastProbeTime *metav1.Time `json:"last_probe_time,omitempty"`
+	// Conditions reflect the connectivity of the build cluster in the
+	// standard Kubernetes format.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterRegistrationList is a list of ClusterRegistration resources.
+type ClusterRegistrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterRegistration `json:"items"`
+}
+
+// Validate ensures the registration can be turned into a client config.
+func (s *ClusterRegistrationSpec) Validate() error {
+	if s.Endpoint == "" {
+		return errors.New("endpoint is not specified")
+	}
+	if u, err := url.Parse(s.Endpoint); err != nil {
+		return fmt.Errorf("endpoint is invalid: %w", err)
+	} else if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("endpoint %q must be an http or https URL", s.Endpoint)
+	}
+	switch s.Auth.Method {
+	case TokenClusterAuth, ClientCertificateClusterAuth:
+	default:
+		return fmt.Errorf("auth method %q is not one of %q", s.Auth.Method, []ClusterAuthMethod{TokenClusterAuth, ClientCertificateClusterAuth})
+	}
+	if s.Auth.SecretName == "" {
+		return errors.New("auth secret name is not specified")
+	}
+	if s.Capacity < 0 {
+		return fmt.Errorf("capacity %d must not be negative", s.Capacity)
+	}
+	return nil
+}
+
+// SetReadyCondition records the outcome of probing the build cluster.
+// The transition time is kept unless the readiness changes.
+func (r *ClusterRegistration) SetReadyCondition(ready bool, reason, message string, now metav1.Time) {
+	status := metav1.ConditionFalse
+	if ready {
+		status = metav1.ConditionTrue
+	}
+	r.Status.ObservedGeneration = r.Generation
+	r.Status.LastProbeTime = &now
+	setCondition(&r.Status.Conditions, metav1.Condition{
+		Type:               ClusterRegistrationReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: r.Generation,
+		LastTransitionTime: now,
+	})
+}
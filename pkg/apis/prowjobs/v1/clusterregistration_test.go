@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterRegistrationSpecValidate(t *testing.T) {
+	t.Parallel()
+	valid := func() ClusterRegistrationSpec {
+		return ClusterRegistrationSpec{
+			Endpoint: "https://build01.example.com:6443",
+			Auth:     ClusterAuth{Method: TokenClusterAuth, SecretName: "build01"},
+		}
+	}
+	testCases := []struct {
+		name        string
+		modify      func(*ClusterRegistrationSpec)
+		expectedErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(*ClusterRegistrationSpec) {},
+		},
+		{
+			name:        "missing endpoint",
+			modify:      func(s *ClusterRegistrationSpec) { s.Endpoint = "" },
+			expectedErr: true,
+		},
+		{
+			name:        "endpoint without scheme",
+			modify:      func(s *ClusterRegistrationSpec) { s.Endpoint = "build01.example.com" },
+			expectedErr: true,
+		},
+		{
+			name:        "unknown auth method",
+			modify:      func(s *ClusterRegistrationSpec) { s.Auth.Method = "password" },
+			expectedErr: true,
+		},
+		{
+			name:        "missing auth secret",
+			modify:      func(s *ClusterRegistrationSpec) { s.Auth.SecretName = "" },
+			expectedErr: true,
+		},
+		{
+			name:        "negative capacity",
+			modify:      func(s *ClusterRegistrationSpec) { s.Capacity = -1 },
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := valid()
+			tc.modify(&spec)
+			if err := spec.Validate(); tc.expectedErr != (err != nil) {
+				t.Errorf("expected error %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestClusterRegistrationSetReadyCondition(t *testing.T) {
+	t.Parallel()
+	r := &ClusterRegistration{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+	first := metav1.NewTime(time.Unix(100, 0))
+	second := metav1.NewTime(time.Unix(200, 0))
+	third := metav1.NewTime(time.Unix(300, 0))
+
+	r.SetReadyCondition(true, "Reachable", "", first)
+	r.SetReadyCondition(true, "Reachable", "", second)
+	if len(r.Status.Conditions) != 1 || !r.Status.Conditions[0].LastTransitionTime.Equal(&first) {
+		t.Fatalf("expected the transition time to be kept while ready, got %+v", r.Status.Conditions)
+	}
+	if !r.Status.LastProbeTime.Equal(&second) || r.Status.ObservedGeneration != 3 {
+		t.Errorf("expected the second probe of generation 3 to be recorded, got %+v", r.Status)
+	}
+
+	r.SetReadyCondition(false, "Unreachable", "connection refused", third)
+	if c := r.Status.Conditions[0]; c.Status != metav1.ConditionFalse || !c.LastTransitionTime.Equal(&third) {
+		t.Errorf("expected the condition to transition to false, got %+v", c)
+	}
+}
@@ -69,6 +69,17 @@ const (
 	ErrorState ProwJobState = "error"
 )
 
+// Types of the conditions of ProwJobs, which are derived from their state.
+const (
+	// ProwJobRunning is true while the job is pending.
+	ProwJobRunning = "Running"
+	// ProwJobComplete is true once the job is in a final state.
+	ProwJobComplete = "Complete"
+	// ProwJobSucceeded is true if the job succeeded and false if it failed,
+	// errored or was aborted. It is unknown until the job is complete.
+	ProwJobSucceeded = "Succeeded"
+)
+
 // GetAllProwJobStates returns all possible job states.
 func GetAllProwJobStates() []ProwJobState {
 	return []ProwJobState{
@@ -117,6 +128,7 @@ const (
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ProwJob contains the spec as well as runtime metadata.
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Job",type=string,JSONPath=`.spec.job`,description="The name of the job being run"
 // +kubebuilder:printcolumn:name="BuildId",type=string,JSONPath=`.status.build_id`,description="The ID of the job being run."
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="The type of job being run."
@@ -182,6 +194,10 @@ type ProwJobSpec struct {
 	// If this field is unspecified or false, a new pod will be created to replace
 	// the evicted one.
 	ErrorOnEviction bool `json:"error_on_eviction,omitempty"`
+	// RetryPolicy makes plank run the job again when an attempt
+	// fails, up to a maximum number of attempts. Only applies to
+	// the kubernetes agent.
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
 
 	// PodSpec provides the basis for running the test under
 	// a Kubernetes agent
@@ -473,6 +489,11 @@ type DecorationConfig struct {
 	// UtilityImages holds pull specs for utility container
 	// images used to decorate a PodSpec.
 	UtilityImages *UtilityImages `json:"utility_images,omitempty"`
+	// ArchUtilityImages holds pull specs for utility container images
+	// by CPU architecture (amd64, arm64, s390x, ...). They are used for
+	// pods that select the architecture with the kubernetes.io/arch node
+	// selector, and default to UtilityImages.
+	ArchUtilityImages map[string]*UtilityImages `json:"arch_utility_images,omitempty"`
 	// Resources holds resource requests and limits for utility
 	// containers used to decorate a PodSpec.
 	Resources *Resources `json:"resources,omitempty"`
@@ -711,6 +732,17 @@ func (d *DecorationConfig) ApplyDefault(def *DecorationConfig) *DecorationConfig
 		return &merged
 	}
 	merged.UtilityImages = merged.UtilityImages.ApplyDefault(def.UtilityImages)
+	for arch, images := range def.ArchUtilityImages {
+		if merged.ArchUtilityImages == nil {
+			merged.ArchUtilityImages = map[string]*UtilityImages{}
+		}
+		// The images set by d for all architectures take precedence over the
+		// ones def sets for this architecture.
+		if images != nil {
+			images = d.UtilityImages.ApplyDefault(images)
+		}
+		merged.ArchUtilityImages[arch] = merged.ArchUtilityImages[arch].ApplyDefault(images)
+	}
 	merged.Resources = merged.Resources.ApplyDefault(def.Resources)
 	merged.GCSConfiguration = merged.GCSConfiguration.ApplyDefault(def.GCSConfiguration)
 	merged.CensoringOptions = merged.CensoringOptions.ApplyDefault(def.CensoringOptions)
@@ -821,6 +853,11 @@ func (d *DecorationConfig) Validate() error {
 	if len(missing) > 0 {
 		return fmt.Errorf("the following utility images are not specified: %q", missing)
 	}
+	for arch := range d.ArchUtilityImages {
+		if arch == "" {
+			return errors.New("utility images are specified for an empty architecture")
+		}
+	}
 
 	if d.GCSConfiguration == nil {
 		return errors.New("GCS upload configuration is not specified")
@@ -838,6 +875,16 @@ func (d *DecorationConfig) Validate() error {
 	return nil
 }
 
+// UtilityImagesForArch returns the utility images for pods of the given CPU
+// architecture. Images that are not set for the architecture default to
+// UtilityImages.
+func (d *DecorationConfig) UtilityImagesForArch(arch string) *UtilityImages {
+	if images, ok := d.ArchUtilityImages[arch]; ok && arch != "" {
+		return images.ApplyDefault(d.UtilityImages)
+	}
+	return d.UtilityImages
+}
+
 func (d *Duration) Get() time.Duration {
 	if d == nil {
 		return 0
@@ -1076,6 +1123,130 @@ type ProwJobStatus struct {
 	// PrevReportStates stores the previous reported prowjob state per reporter
 	// So crier won't make duplicated report attempt
 	PrevReportStates map[string]ProwJobState `json:"prev_report_states,omitempty"`
+
+	// ResourceUsage applies only to ProwJobs fulfilled by
+	// plank with resource usage sampling enabled. It records
+	// how much CPU and memory the pod of the job used, to
+	// help right-size the resource requests of the job.
+	ResourceUsage *ResourceUsage `json:"resource_usage,omitempty"`
+
+	// Attempts are the previous attempts of a job with a retry
+	// policy, oldest first. The rest of the status describes the
+	// current attempt.
+	Attempts []ProwJobAttempt `json:"attempts,omitempty"`
+
+	// ObservedGeneration is the generation of the ProwJob
+	// the status was last written for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions reflect the state of the job in the standard
+	// form, so that e.g. `kubectl wait` works on ProwJobs.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// RetryReason is why an attempt of a job failed. The retry policy of the job
+// decides by it whether to run the job again.
+type RetryReason string
+
+const (
+	// FailureRetryReason means the pod of the job failed, e.g. because a test failed.
+	FailureRetryReason RetryReason = "Failure"
+	// EvictionRetryReason means the pod of the job was evicted. Evicted pods are
+	// only retried by the retry policy if ErrorOnEviction is set, as they are
+	// replaced otherwise anyway.
+	EvictionRetryReason RetryReason = "Evicted"
+	// PodPendingTimeoutRetryReason means the pod of the job was not scheduled or
+	// did not start in time.
+	PodPendingTimeoutRetryReason RetryReason = "PodPendingTimeout"
+	// PodRunningTimeoutRetryReason means the pod of the job ran for too long.
+	PodRunningTimeoutRetryReason RetryReason = "PodRunningTimeout"
+	// PodDeletedRetryReason means the pod of the job got deleted before it finished.
+	PodDeletedRetryReason RetryReason = "PodDeleted"
+)
+
+// GetAllRetryReasons returns all reasons attempts of jobs can fail for.
+func GetAllRetryReasons() []RetryReason {
+	return []RetryReason{
+		FailureRetryReason,
+		EvictionRetryReason,
+		PodPendingTimeoutRetryReason,
+		PodRunningTimeoutRetryReason,
+		PodDeletedRetryReason,
+	}
+}
+
+// RetryPolicy configures running a job again when an attempt fails.
+type RetryPolicy struct {
+	// MaxAttempts is how often the job runs at most, including the
+	// first attempt.
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts int `json:"max_attempts"`
+	// RetryableReasons limits the retries to attempts that failed for
+	// one of these reasons. Attempts are retried whatever they failed
+	// for if it is empty.
+	RetryableReasons []RetryReason `json:"retryable_reasons,omitempty"`
+	// Backoff is how long to wait after an attempt failed before the
+	// next one is started.
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+}
+
+// Retries returns whether the job is run again after the given attempt, its
+// first one being 1, failed for the given reason.
+func (p *RetryPolicy) Retries(attempt int, reason RetryReason) bool {
+	if p == nil || reason == "" || attempt >= p.MaxAttempts {
+		return false
+	}
+	if len(p.RetryableReasons) == 0 {
+		return true
+	}
+	for _, retryable := range p.RetryableReasons {
+		if retryable == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBackoff returns how long to wait before the next attempt.
+func (p *RetryPolicy) GetBackoff() time.Duration {
+	if p == nil || p.Backoff == nil {
+		return 0
+	}
+	return p.Backoff.Duration
+}
+
+// ProwJobAttempt is a previous attempt to run a ProwJob with a retry policy.
+type ProwJobAttempt struct {
+	// BuildID is the build identifier of the attempt, which its
+	// artifacts are grouped by.
+	BuildID string `json:"build_id"`
+	// URL links to the results of the attempt.
+	URL string `json:"url,omitempty"`
+	// PendingTime is when the attempt started running.
+	PendingTime *metav1.Time `json:"pendingTime,omitempty"`
+	// CompletionTime is when the attempt failed.
+	CompletionTime metav1.Time `json:"completionTime"`
+	// State is the state the attempt ended in.
+	State ProwJobState `json:"state"`
+	// Reason is why the attempt failed.
+	Reason RetryReason `json:"reason"`
+	// Description describes how the attempt ended.
+	Description string `json:"description,omitempty"`
+}
+
+// ResourceUsage is the CPU and memory usage of the pod of a ProwJob, summed over
+// its containers and sampled from the metrics API while the pod was running.
+type ResourceUsage struct {
+	// PeakCPU is the highest CPU usage sampled.
+	PeakCPU resource.Quantity `json:"peak_cpu"`
+	// PeakMemory is the highest memory usage (working set) sampled.
+	PeakMemory resource.Quantity `json:"peak_memory"`
+	// CPUSeconds is the total CPU time used, estimated from the samples.
+	CPUSeconds int64 `json:"cpu_seconds"`
+	// Samples is the number of samples the usage is based on.
+	Samples int `json:"samples"`
 }
 
 // Complete returns true if the prow job has finished
@@ -1090,6 +1261,65 @@ func (j *ProwJob) SetComplete() {
 	*j.Status.CompletionTime = metav1.Now()
 }
 
+// SetConditions updates the conditions of the job to reflect its state and
+// records the generation of the job the status is written for. Conditions
+// transition at the times recorded in the status, so that writing the same
+// state again does not change them.
+func (j *ProwJob) SetConditions() {
+	j.Status.ObservedGeneration = j.Generation
+
+	reason := "Unknown"
+	if j.Status.State != "" {
+		reason = strings.ToUpper(string(j.Status.State[:1])) + string(j.Status.State[1:])
+	}
+	running, complete, succeeded := metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionUnknown
+	since := j.Status.StartTime
+	switch j.Status.State {
+	case PendingState:
+		running = metav1.ConditionTrue
+		if j.Status.PendingTime != nil {
+			since = *j.Status.PendingTime
+		}
+	case SuccessState, FailureState, ErrorState, AbortedState:
+		complete = metav1.ConditionTrue
+		succeeded = metav1.ConditionFalse
+		if j.Status.State == SuccessState {
+			succeeded = metav1.ConditionTrue
+		}
+		if j.Status.CompletionTime != nil {
+			since = *j.Status.CompletionTime
+		}
+	}
+	for _, c := range []metav1.Condition{
+		{Type: ProwJobRunning, Status: running},
+		{Type: ProwJobComplete, Status: complete},
+		{Type: ProwJobSucceeded, Status: succeeded},
+	} {
+		c.Reason = reason
+		c.Message = j.Status.Description
+		c.ObservedGeneration = j.Generation
+		c.LastTransitionTime = since
+		setCondition(&j.Status.Conditions, c)
+	}
+}
+
+// setCondition sets a condition, keeping its transition time unless its
+// status changes.
+func setCondition(conditions *[]metav1.Condition, condition metav1.Condition) {
+	for i := range *conditions {
+		existing := &(*conditions)[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	*conditions = append(*conditions, condition)
+}
+
 // ClusterAlias specifies the key in the clusters map to use.
 //
 // This allows scheduling a prow job somewhere aside from the default build cluster.
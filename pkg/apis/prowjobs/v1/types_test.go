@@ -26,6 +26,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	fuzz "github.com/google/gofuzz"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func pStr(str string) *string {
@@ -893,3 +894,202 @@ func TestProwJobSpec_GetPipelineRunSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestSetConditions(t *testing.T) {
+	start := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	pending := metav1.NewTime(start.Add(time.Minute))
+	completion := metav1.NewTime(start.Add(time.Hour))
+	conditions := func(reason, message string, since metav1.Time, running, complete, succeeded metav1.ConditionStatus) []metav1.Condition {
+		return []metav1.Condition{
+			{Type: ProwJobRunning, Status: running, Reason: reason, Message: message, ObservedGeneration: 2, LastTransitionTime: since},
+			{Type: ProwJobComplete, Status: complete, Reason: reason, Message: message, ObservedGeneration: 2, LastTransitionTime: since},
+			{Type: ProwJobSucceeded, Status: succeeded, Reason: reason, Message: message, ObservedGeneration: 2, LastTransitionTime: since},
+		}
+	}
+	pj := ProwJob{}
+	pj.Generation = 2
+	pj.Status = ProwJobStatus{State: TriggeredState, StartTime: start}
+
+	pj.SetConditions()
+	expected := conditions("Triggered", "", start, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionUnknown)
+	if diff := cmp.Diff(expected, pj.Status.Conditions); diff != "" {
+		t.Errorf("conditions of triggered job differ (-want +got):\n%s", diff)
+	}
+	if pj.Status.ObservedGeneration != 2 {
+		t.Errorf("expected observed generation 2, got %d", pj.Status.ObservedGeneration)
+	}
+
+	pj.Status.State = PendingState
+	pj.Status.PendingTime = &pending
+	pj.Status.Description = "Job triggered."
+	pj.SetConditions()
+	expected = conditions("Pending", "Job triggered.", start, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown)
+	// Only the condition that changed its status transitioned.
+	expected[0].LastTransitionTime = pending
+	if diff := cmp.Diff(expected, pj.Status.Conditions); diff != "" {
+		t.Errorf("conditions of pending job differ (-want +got):\n%s", diff)
+	}
+
+	pj.Status.State = FailureState
+	pj.Status.CompletionTime = &completion
+	pj.Status.Description = "Job failed."
+	pj.SetConditions()
+	expected = conditions("Failure", "Job failed.", completion, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse)
+	if diff := cmp.Diff(expected, pj.Status.Conditions); diff != "" {
+		t.Errorf("conditions of failed job differ (-want +got):\n%s", diff)
+	}
+}
+
+func TestRetryPolicyRetries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   *RetryPolicy
+		attempt  int
+		reason   RetryReason
+		expected bool
+	}{
+		{
+			name:    "no retry policy",
+			attempt: 1,
+			reason:  FailureRetryReason,
+		},
+		{
+			name:     "attempts left",
+			policy:   &RetryPolicy{MaxAttempts: 3},
+			attempt:  2,
+			reason:   FailureRetryReason,
+			expected: true,
+		},
+		{
+			name:    "last attempt",
+			policy:  &RetryPolicy{MaxAttempts: 3},
+			attempt: 3,
+			reason:  FailureRetryReason,
+		},
+		{
+			name:    "attempt did not fail",
+			policy:  &RetryPolicy{MaxAttempts: 3},
+			attempt: 1,
+		},
+		{
+			name:     "retryable reason",
+			policy:   &RetryPolicy{MaxAttempts: 3, RetryableReasons: []RetryReason{EvictionRetryReason, PodPendingTimeoutRetryReason}},
+			attempt:  1,
+			reason:   PodPendingTimeoutRetryReason,
+			expected: true,
+		},
+		{
+			name:    "reason that is not retryable",
+			policy:  &RetryPolicy{MaxAttempts: 3, RetryableReasons: []RetryReason{EvictionRetryReason, PodPendingTimeoutRetryReason}},
+			attempt: 1,
+			reason:  FailureRetryReason,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.policy.Retries(tc.attempt, tc.reason); actual != tc.expected {
+				t.Errorf("expected retries to be %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyGetBackoff(t *testing.T) {
+	var policy *RetryPolicy
+	if backoff := policy.GetBackoff(); backoff != 0 {
+		t.Errorf("expected no backoff without a retry policy, got %s", backoff)
+	}
+	policy = &RetryPolicy{MaxAttempts: 2, Backoff: &metav1.Duration{Duration: time.Minute}}
+	if backoff := policy.GetBackoff(); backoff != time.Minute {
+		t.Errorf("expected a backoff of 1m, got %s", backoff)
+	}
+}
+
+func TestDecorationConfigUtilityImagesForArch(t *testing.T) {
+	t.Parallel()
+	dc := &DecorationConfig{
+		UtilityImages: &UtilityImages{
+			CloneRefs:  "clonerefs:amd64",
+			InitUpload: "initupload:amd64",
+			Entrypoint: "entrypoint:amd64",
+			Sidecar:    "sidecar:amd64",
+		},
+		ArchUtilityImages: map[string]*UtilityImages{
+			"arm64": {
+				CloneRefs:  "clonerefs:arm64",
+				Entrypoint: "entrypoint:arm64",
+			},
+		},
+	}
+	testCases := []struct {
+		name     string
+		arch     string
+		expected *UtilityImages
+	}{
+		{
+			name:     "no architecture uses the default images",
+			expected: dc.UtilityImages,
+		},
+		{
+			name:     "architecture without images uses the default images",
+			arch:     "s390x",
+			expected: dc.UtilityImages,
+		},
+		{
+			name: "architecture images are defaulted to the default images",
+			arch: "arm64",
+			expected: &UtilityImages{
+				CloneRefs:  "clonerefs:arm64",
+				InitUpload: "initupload:amd64",
+				Entrypoint: "entrypoint:arm64",
+				Sidecar:    "sidecar:amd64",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, dc.UtilityImagesForArch(tc.arch)); diff != "" {
+				t.Errorf("unexpected utility images: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecorationConfigApplyDefaultMergesArchUtilityImages(t *testing.T) {
+	t.Parallel()
+	dc := &DecorationConfig{
+		ArchUtilityImages: map[string]*UtilityImages{
+			"arm64": {CloneRefs: "clonerefs:job"},
+		},
+	}
+	def := &DecorationConfig{
+		ArchUtilityImages: map[string]*UtilityImages{
+			"arm64": {CloneRefs: "clonerefs:default", Sidecar: "sidecar:default"},
+			"s390x": {Sidecar: "sidecar:s390x"},
+		},
+	}
+	expected := map[string]*UtilityImages{
+		"arm64": {CloneRefs: "clonerefs:job", Sidecar: "sidecar:default"},
+		"s390x": {Sidecar: "sidecar:s390x"},
+	}
+	if diff := cmp.Diff(expected, dc.ApplyDefault(def).ArchUtilityImages); diff != "" {
+		t.Errorf("unexpected arch utility images: %s", diff)
+	}
+}
+
+func TestDecorationConfigApplyDefaultPrefersJobUtilityImages(t *testing.T) {
+	t.Parallel()
+	dc := &DecorationConfig{
+		UtilityImages: &UtilityImages{Sidecar: "sidecar:job"},
+	}
+	def := &DecorationConfig{
+		UtilityImages: &UtilityImages{CloneRefs: "clonerefs:default", Sidecar: "sidecar:default"},
+		ArchUtilityImages: map[string]*UtilityImages{
+			"arm64": {CloneRefs: "clonerefs:arm64", Sidecar: "sidecar:arm64"},
+		},
+	}
+	expected := &UtilityImages{CloneRefs: "clonerefs:arm64", Sidecar: "sidecar:job"}
+	if diff := cmp.Diff(expected, dc.ApplyDefault(def).UtilityImagesForArch("arm64")); diff != "" {
+		t.Errorf("unexpected utility images: %s", diff)
+	}
+}
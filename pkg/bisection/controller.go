@@ -0,0 +1,387 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bisection bisects postsubmit regressions: when a postsubmit flips
+// from passing to failing, it runs the postsubmit on the commits in between
+// and reports the commit that broke it.
+package bisection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/pjutil"
+)
+
+const ControllerName = "bisection"
+
+type githubClient interface {
+	GetSingleCommit(org, repo, SHA string) (github.RepositoryCommit, error)
+	CreateIssue(org, repo, title, body string, milestone int, labels, assignees []string) (int, error)
+}
+
+// Add adds the controller to the manager.
+func Add(mgr controllerruntime.Manager, cfg config.Getter, ghc githubClient) error {
+	c := NewController(mgr.GetClient(), cfg, ghc)
+	if err := mgr.Add(manager.RunnableFunc(c.run)); err != nil {
+		return fmt.Errorf("failed to add %s controller to manager: %w", ControllerName, err)
+	}
+	return nil
+}
+
+// Controller periodically looks for postsubmits that flipped from passing to
+// failing, creates postsubmits on the commits in between to bisect them and
+// opens an issue naming the culprit.
+type Controller struct {
+	client ctrlruntimeclient.Client
+	config config.Getter
+	ghc    githubClient
+	log    *logrus.Entry
+
+	// commits caches the commits of the bisected regressions by the name of
+	// their failed ProwJob, oldest first.
+	commits map[string][]github.RepositoryCommit
+}
+
+func NewController(client ctrlruntimeclient.Client, cfg config.Getter, ghc githubClient) *Controller {
+	return &Controller{
+		client:  client,
+		config:  cfg,
+		ghc:     ghc,
+		log:     logrus.NewEntry(logrus.StandardLogger()).WithField("controller", ControllerName),
+		commits: map[string][]github.RepositoryCommit{},
+	}
+}
+
+func (c *Controller) run(ctx context.Context) error {
+	for {
+		if err := c.Sync(ctx); err != nil {
+			c.log.WithError(err).Error("Failed to sync bisections.")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.config().Bisection.ResyncPeriod.Duration):
+		}
+	}
+}
+
+// regression is a postsubmit that failed on Bad after it passed on Good.
+type regression struct {
+	good, bad *prowapi.ProwJob
+	policy    config.BisectionPolicy
+}
+
+// Sync looks for regressions and advances their bisections.
+func (c *Controller) Sync(ctx context.Context) error {
+	cfg := c.config()
+	if len(cfg.Bisection.Policies) == 0 {
+		return nil
+	}
+	pjs := &prowapi.ProwJobList{}
+	if err := c.client.List(ctx, pjs, ctrlruntimeclient.InNamespace(cfg.ProwJobNamespace)); err != nil {
+		return fmt.Errorf("failed to list ProwJobs: %w", err)
+	}
+
+	runs := map[string][]*prowapi.ProwJob{}
+	bisections := map[string][]*prowapi.ProwJob{}
+	var running int
+	for i := range pjs.Items {
+		pj := &pjs.Items[i]
+		if pj.Spec.Type != prowapi.PostsubmitJob || pj.Spec.Refs == nil {
+			continue
+		}
+		if name, ok := pj.Labels[kube.BisectionLabel]; ok {
+			bisections[name] = append(bisections[name], pj)
+			if !pj.Complete() {
+				running++
+			}
+			continue
+		}
+		key := strings.Join([]string{pj.Spec.Refs.Org, pj.Spec.Refs.Repo, pj.Spec.Refs.BaseRef, pj.Spec.Job}, "/")
+		runs[key] = append(runs[key], pj)
+	}
+
+	var regressions []regression
+	for _, jobRuns := range runs {
+		refs := jobRuns[0].Spec.Refs
+		policy, ok := cfg.Bisection.PolicyFor(refs.Org, refs.Repo)
+		if !ok || !policy.Bisects(jobRuns[0].Spec.Job) {
+			continue
+		}
+		if good, bad := findRegression(jobRuns); bad != nil {
+			regressions = append(regressions, regression{good: good, bad: bad, policy: policy})
+		}
+	}
+	// Bisect the oldest regressions first when jobs are scarce.
+	sort.Slice(regressions, func(i, j int) bool {
+		return regressions[i].bad.Status.StartTime.Before(&regressions[j].bad.Status.StartTime)
+	})
+
+	active := sets.New[string]()
+	for _, r := range regressions {
+		active.Insert(r.bad.Name)
+		log := c.log.WithFields(logrus.Fields{"org": r.bad.Spec.Refs.Org, "repo": r.bad.Spec.Refs.Repo, "job": r.bad.Spec.Job, "prowjob": r.bad.Name})
+		created, err := c.bisect(ctx, cfg, r, bisections[r.bad.Name], cfg.Bisection.MaxConcurrency-running)
+		running += created
+		if err != nil {
+			log.WithError(err).Warn("Failed to bisect regression.")
+		}
+	}
+	for name := range c.commits {
+		if !active.Has(name) {
+			delete(c.commits, name)
+		}
+	}
+	return nil
+}
+
+// findRegression returns the last passing and the first failing run of a
+// postsubmit that is currently failing. Both are nil if it is not failing,
+// never passed, or if the regression was reported already.
+func findRegression(runs []*prowapi.ProwJob) (good, bad *prowapi.ProwJob) {
+	var completed []*prowapi.ProwJob
+	for _, pj := range runs {
+		if pj.Status.State == prowapi.SuccessState || pj.Status.State == prowapi.FailureState {
+			completed = append(completed, pj)
+		}
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].Status.StartTime.Before(&completed[j].Status.StartTime)
+	})
+	for i := len(completed) - 1; i >= 0; i-- {
+		if completed[i].Status.State != prowapi.SuccessState {
+			continue
+		}
+		if i == len(completed)-1 {
+			return nil, nil
+		}
+		good, bad = completed[i], completed[i+1]
+		break
+	}
+	if bad == nil || good.Spec.Refs.BaseSHA == bad.Spec.Refs.BaseSHA {
+		// A failure on a commit that passed before is a flake, not a regression.
+		return nil, nil
+	}
+	if _, reported := bad.Annotations[kube.BisectionCulpritAnnotation]; reported {
+		return nil, nil
+	}
+	return good, bad
+}
+
+// bisect advances the bisection of a regression by creating at most slots
+// postsubmits, or reports the culprit once it is known. It returns how many
+// postsubmits it created.
+func (c *Controller) bisect(ctx context.Context, cfg *config.Config, r regression, bisections []*prowapi.ProwJob, slots int) (int, error) {
+	commits, err := c.commitsBetween(r)
+	if err != nil {
+		return 0, err
+	}
+	results := map[string]prowapi.ProwJobState{}
+	for _, pj := range bisections {
+		results[pj.Spec.Refs.BaseSHA] = pj.Status.State
+	}
+
+	next, culprits := step(commits, results)
+	if culprits != nil {
+		return 0, c.report(ctx, r, culprits)
+	}
+	if len(next) == 0 || slots <= 0 {
+		return 0, nil
+	}
+	if len(next) > slots {
+		next = pick(next, slots)
+	}
+
+	ps, err := postsubmit(cfg, r.bad)
+	if err != nil {
+		return 0, err
+	}
+	var created int
+	for _, i := range next {
+		refs := *r.bad.Spec.Refs
+		refs.BaseSHA = commits[i].SHA
+		refs.Pulls = nil
+		spec := pjutil.PostsubmitSpec(ps, refs)
+		// Bisection results must not overwrite what the postsubmit reported
+		// on the commit, nor notify anyone.
+		spec.Report = false
+		spec.ReporterConfig = nil
+		pj := pjutil.NewProwJob(spec, map[string]string{kube.BisectionLabel: r.bad.Name}, nil)
+		pj.Namespace = cfg.ProwJobNamespace
+		if err := pjutil.CreateProwJobWithClient(ctx, c.client, &pj); err != nil {
+			return created, fmt.Errorf("failed to create bisection ProwJob for %s: %w", refs.BaseSHA, err)
+		}
+		created++
+		c.log.WithFields(logrus.Fields{"job": spec.Job, "sha": refs.BaseSHA, "prowjob": pj.Name}).Info("Created bisection ProwJob.")
+	}
+	return created, nil
+}
+
+// step narrows the regression down from the results of the bisection jobs.
+// Commits are ordered oldest first and the last one is the failing one. It
+// returns the indices of the commits that still need to be tested, or the
+// culprits once no commit is left to test. Commits the job errored or was
+// aborted on are skipped, so more than one culprit can remain.
+func step(commits []github.RepositoryCommit, results map[string]prowapi.ProwJobState) (next []int, culprits []github.RepositoryCommit) {
+	hi := len(commits) - 1
+	for i := 0; i < hi; i++ {
+		if results[commits[i].SHA] == prowapi.FailureState {
+			hi = i
+			break
+		}
+	}
+	lo := -1
+	for i := hi - 1; i >= 0; i-- {
+		if results[commits[i].SHA] == prowapi.SuccessState {
+			lo = i
+			break
+		}
+	}
+	var untested []int
+	for i := lo + 1; i < hi; i++ {
+		switch results[commits[i].SHA] {
+		case prowapi.PendingState, prowapi.TriggeredState, prowapi.SchedulingState:
+			// Wait for the running jobs before testing more commits.
+			return nil, nil
+		case prowapi.ErrorState, prowapi.AbortedState:
+			continue
+		}
+		untested = append(untested, i)
+	}
+	if len(untested) > 0 {
+		return untested, nil
+	}
+	return nil, commits[lo+1 : hi+1]
+}
+
+// pick spreads n picks evenly over the candidates, which splits the range into
+// n+1 parts of about the same size.
+func pick(candidates []int, n int) []int {
+	picked := make([]int, 0, n)
+	for i := 1; i <= n; i++ {
+		picked = append(picked, candidates[i*len(candidates)/(n+1)])
+	}
+	return picked
+}
+
+// commitsBetween returns the commits after the last passing up to the first
+// failing commit, oldest first, by following the first parents of the
+// failing one.
+func (c *Controller) commitsBetween(r regression) ([]github.RepositoryCommit, error) {
+	if commits, ok := c.commits[r.bad.Name]; ok {
+		return commits, nil
+	}
+	refs := r.bad.Spec.Refs
+	var commits []github.RepositoryCommit
+	for sha := refs.BaseSHA; sha != r.good.Spec.Refs.BaseSHA; {
+		if len(commits) == r.policy.MaxCommits {
+			return nil, fmt.Errorf("more than %d commits between %s and %s", r.policy.MaxCommits, r.good.Spec.Refs.BaseSHA, refs.BaseSHA)
+		}
+		commit, err := c.ghc.GetSingleCommit(refs.Org, refs.Repo, sha)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit %s: %w", sha, err)
+		}
+		if len(commit.Parents) == 0 {
+			return nil, fmt.Errorf("passing commit %s is not an ancestor of failing commit %s", r.good.Spec.Refs.BaseSHA, refs.BaseSHA)
+		}
+		commit.SHA = sha
+		commits = append(commits, commit)
+		sha = commit.Parents[0].SHA
+	}
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	c.commits[r.bad.Name] = commits
+	return commits, nil
+}
+
+// postsubmit returns the config of the postsubmit that regressed.
+func postsubmit(cfg *config.Config, pj *prowapi.ProwJob) (config.Postsubmit, error) {
+	refs := pj.Spec.Refs
+	for _, ps := range cfg.GetPostsubmitsStatic(refs.Org + "/" + refs.Repo) {
+		if ps.Name == pj.Spec.Job && ps.CouldRun(refs.BaseRef) {
+			return ps, nil
+		}
+	}
+	return config.Postsubmit{}, fmt.Errorf("no postsubmit %s for %s/%s@%s in the config", pj.Spec.Job, refs.Org, refs.Repo, refs.BaseRef)
+}
+
+// report opens an issue naming the culprits and marks the regression as
+// reported.
+func (c *Controller) report(ctx context.Context, r regression, culprits []github.RepositoryCommit) error {
+	refs := r.bad.Spec.Refs
+	var shas []string
+	for _, commit := range culprits {
+		shas = append(shas, commit.SHA)
+	}
+	org, repo, _ := strings.Cut(r.policy.ReportRepo, "/")
+	title, body := issue(r, culprits)
+	number, err := c.ghc.CreateIssue(org, repo, title, body, 0, r.policy.Labels, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open issue in %s: %w", r.policy.ReportRepo, err)
+	}
+	c.log.WithFields(logrus.Fields{"job": r.bad.Spec.Job, "culprits": shas, "issue": fmt.Sprintf("%s#%d", r.policy.ReportRepo, number)}).Info("Reported culprit of regression.")
+
+	original := r.bad.DeepCopy()
+	if r.bad.Annotations == nil {
+		r.bad.Annotations = map[string]string{}
+	}
+	r.bad.Annotations[kube.BisectionCulpritAnnotation] = strings.Join(shas, ",")
+	if err := c.client.Patch(ctx, r.bad, ctrlruntimeclient.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to mark regression of %s on %s/%s as reported: %w", r.bad.Spec.Job, refs.Org, refs.Repo, err)
+	}
+	delete(c.commits, r.bad.Name)
+	return nil
+}
+
+func issue(r regression, culprits []github.RepositoryCommit) (string, string) {
+	refs := r.bad.Spec.Refs
+	title := fmt.Sprintf("%s broke on %s", r.bad.Spec.Job, refs.BaseRef)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Postsubmit `%s` of %s/%s passed on %s and failed on %s", r.bad.Spec.Job, refs.Org, refs.Repo, r.good.Spec.Refs.BaseSHA, refs.BaseSHA)
+	if r.bad.Status.URL != "" {
+		fmt.Fprintf(&b, " ([failed run](%s))", r.bad.Status.URL)
+	}
+	b.WriteString(".\n\n")
+	if len(culprits) == 1 {
+		b.WriteString("Bisection found the culprit:\n\n")
+	} else {
+		b.WriteString("Bisection could not test every commit in between, one of these is the culprit:\n\n")
+	}
+	for _, commit := range culprits {
+		summary, _, _ := strings.Cut(commit.Commit.Message, "\n")
+		link := commit.SHA
+		if commit.HTMLURL != "" {
+			link = fmt.Sprintf("[%s](%s)", commit.SHA, commit.HTMLURL)
+		}
+		fmt.Fprintf(&b, "- %s %s\n", link, summary)
+	}
+	return title, b.String()
+}
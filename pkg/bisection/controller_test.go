@@ -0,0 +1,326 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bisection
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/pjutil/fakepjutil"
+)
+
+var now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func postsubmitRun(name, sha string, state prowapi.ProwJobState, started time.Duration) *prowapi.ProwJob {
+	return &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prowjobs"},
+		Spec: prowapi.ProwJobSpec{
+			Type: prowapi.PostsubmitJob,
+			Job:  "post-unit",
+			Refs: &prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: sha},
+		},
+		Status: prowapi.ProwJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(now.Add(started)),
+		},
+	}
+}
+
+func commits(shas ...string) []github.RepositoryCommit {
+	var commits []github.RepositoryCommit
+	for _, sha := range shas {
+		commits = append(commits, github.RepositoryCommit{SHA: sha})
+	}
+	return commits
+}
+
+func TestStep(t *testing.T) {
+	testcases := []struct {
+		name             string
+		results          map[string]prowapi.ProwJobState
+		expectedNext     []int
+		expectedCulprits []string
+	}{
+		{
+			name:         "nothing tested yet",
+			expectedNext: []int{0, 1, 2, 3},
+		},
+		{
+			name:         "commit in the middle passed",
+			results:      map[string]prowapi.ProwJobState{"b": prowapi.SuccessState},
+			expectedNext: []int{2, 3},
+		},
+		{
+			name:         "commit in the middle failed",
+			results:      map[string]prowapi.ProwJobState{"b": prowapi.FailureState},
+			expectedNext: []int{0},
+		},
+		{
+			name:    "waits for running jobs",
+			results: map[string]prowapi.ProwJobState{"b": prowapi.SuccessState, "c": prowapi.PendingState},
+		},
+		{
+			name:             "culprit found",
+			results:          map[string]prowapi.ProwJobState{"b": prowapi.SuccessState, "c": prowapi.FailureState},
+			expectedCulprits: []string{"c"},
+		},
+		{
+			name:             "first commit is the culprit",
+			results:          map[string]prowapi.ProwJobState{"a": prowapi.FailureState},
+			expectedCulprits: []string{"a"},
+		},
+		{
+			name:             "last commit is the culprit",
+			results:          map[string]prowapi.ProwJobState{"d": prowapi.SuccessState},
+			expectedCulprits: []string{"e"},
+		},
+		{
+			name:             "errored commits are skipped",
+			results:          map[string]prowapi.ProwJobState{"b": prowapi.SuccessState, "c": prowapi.ErrorState, "d": prowapi.AbortedState},
+			expectedCulprits: []string{"c", "d", "e"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			next, culprits := step(commits("a", "b", "c", "d", "e"), tc.results)
+			if diff := cmp.Diff(tc.expectedNext, next); diff != "" {
+				t.Errorf("unexpected commits to test: %s", diff)
+			}
+			var shas []string
+			for _, commit := range culprits {
+				shas = append(shas, commit.SHA)
+			}
+			if diff := cmp.Diff(tc.expectedCulprits, shas); diff != "" {
+				t.Errorf("unexpected culprits: %s", diff)
+			}
+		})
+	}
+}
+
+func TestPick(t *testing.T) {
+	candidates := []int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	if diff := cmp.Diff([]int{4}, pick(candidates, 1)); diff != "" {
+		t.Errorf("unexpected pick of one: %s", diff)
+	}
+	if diff := cmp.Diff([]int{3, 6}, pick(candidates, 2)); diff != "" {
+		t.Errorf("unexpected pick of two: %s", diff)
+	}
+}
+
+func TestFindRegression(t *testing.T) {
+	reported := postsubmitRun("3", "c", prowapi.FailureState, -time.Hour)
+	reported.Annotations = map[string]string{kube.BisectionCulpritAnnotation: "b"}
+	testcases := []struct {
+		name         string
+		runs         []*prowapi.ProwJob
+		expectedGood string
+		expectedBad  string
+	}{
+		{
+			name: "failing after passing",
+			runs: []*prowapi.ProwJob{
+				postsubmitRun("4", "d", prowapi.FailureState, 0),
+				postsubmitRun("1", "a", prowapi.SuccessState, -3*time.Hour),
+				postsubmitRun("3", "c", prowapi.FailureState, -time.Hour),
+				postsubmitRun("2", "b", prowapi.SuccessState, -2*time.Hour),
+				postsubmitRun("5", "e", prowapi.PendingState, time.Hour),
+			},
+			expectedGood: "2",
+			expectedBad:  "3",
+		},
+		{
+			name: "passing again",
+			runs: []*prowapi.ProwJob{
+				postsubmitRun("1", "a", prowapi.FailureState, -time.Hour),
+				postsubmitRun("2", "b", prowapi.SuccessState, 0),
+			},
+		},
+		{
+			name: "never passed",
+			runs: []*prowapi.ProwJob{
+				postsubmitRun("1", "a", prowapi.FailureState, -time.Hour),
+				postsubmitRun("2", "b", prowapi.FailureState, 0),
+			},
+		},
+		{
+			name: "flaked on a passing commit",
+			runs: []*prowapi.ProwJob{
+				postsubmitRun("1", "a", prowapi.SuccessState, -time.Hour),
+				postsubmitRun("2", "a", prowapi.FailureState, 0),
+			},
+		},
+		{
+			name: "reported already",
+			runs: []*prowapi.ProwJob{
+				postsubmitRun("2", "b", prowapi.SuccessState, -2*time.Hour),
+				reported,
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			good, bad := findRegression(tc.runs)
+			var goodName, badName string
+			if good != nil {
+				goodName = good.Name
+			}
+			if bad != nil {
+				badName = bad.Name
+			}
+			if goodName != tc.expectedGood || badName != tc.expectedBad {
+				t.Errorf("expected good %q and bad %q, got %q and %q", tc.expectedGood, tc.expectedBad, goodName, badName)
+			}
+		})
+	}
+}
+
+func testConfig(t *testing.T) *config.Config {
+	cfg := &config.Config{ProwConfig: config.ProwConfig{
+		ProwJobNamespace: "prowjobs",
+		Bisection: config.Bisection{
+			MaxConcurrency: 1,
+			Policies: map[string]config.BisectionPolicy{
+				"org": {ReportRepo: "org/triage", Labels: []string{"kind/regression"}},
+			},
+		},
+	}}
+	if err := cfg.SetPostsubmits(map[string][]config.Postsubmit{
+		"org/repo": {{JobBase: config.JobBase{Name: "post-unit"}, Reporter: config.Reporter{Context: "post-unit"}}},
+	}); err != nil {
+		t.Fatalf("failed to set postsubmits: %v", err)
+	}
+	return cfg
+}
+
+// bisectionJobs returns the SHAs of the bisection jobs and sets their state.
+func bisectionJobs(t *testing.T, client ctrlruntimeclient.Client, results map[string]prowapi.ProwJobState) []string {
+	t.Helper()
+	pjs := &prowapi.ProwJobList{}
+	if err := client.List(context.Background(), pjs, ctrlruntimeclient.HasLabels{kube.BisectionLabel}); err != nil {
+		t.Fatalf("failed to list ProwJobs: %v", err)
+	}
+	var shas []string
+	for i := range pjs.Items {
+		pj := &pjs.Items[i]
+		shas = append(shas, pj.Spec.Refs.BaseSHA)
+		if pj.Labels[kube.BisectionLabel] != "bad" || pj.Spec.Report {
+			t.Errorf("expected a non-reporting bisection job of the bad run, got %+v", pj)
+		}
+		if pj.Status.State == "" || pj.Status.StartTime.IsZero() {
+			t.Errorf("expected the bisection job to be created with its initial status, got %+v", pj.Status)
+		}
+		if state, ok := results[pj.Spec.Refs.BaseSHA]; ok && pj.Status.State != state {
+			pj.Status.State = state
+			pj.Status.CompletionTime = &metav1.Time{Time: now}
+			if err := client.Update(context.Background(), pj); err != nil {
+				t.Fatalf("failed to update ProwJob: %v", err)
+			}
+		}
+	}
+	sort.Strings(shas)
+	return shas
+}
+
+func TestSync(t *testing.T) {
+	cfg := testConfig(t)
+	ghc := fakegithub.NewFakeClient()
+	// a <- b <- c <- d <- e, where d broke the job.
+	parent := "a"
+	for _, sha := range []string{"b", "c", "d", "e"} {
+		ghc.Commits[sha] = github.RepositoryCommit{
+			Commit:  github.GitCommit{Message: "Change " + sha + "\n\nDetails."},
+			Parents: []github.GitCommit{{SHA: parent}},
+		}
+		parent = sha
+	}
+	good := postsubmitRun("good", "a", prowapi.SuccessState, -time.Hour)
+	bad := postsubmitRun("bad", "e", prowapi.FailureState, 0)
+	bad.Status.URL = "https://prow.example.com/view/bad"
+	client := fakepjutil.StatusDroppingCtrlClient{Client: fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(good, bad).Build()}
+	c := NewController(client, func() *config.Config { return cfg }, ghc)
+	results := map[string]prowapi.ProwJobState{"c": prowapi.SuccessState, "d": prowapi.FailureState}
+
+	var created [][]string
+	for i := 0; i < 4; i++ {
+		if err := c.Sync(context.Background()); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+		created = append(created, bisectionJobs(t, client, results))
+	}
+	// The concurrency limit allows one job at a time: c passes, so d is next
+	// and fails, which leaves d as the culprit.
+	expected := [][]string{{"c"}, {"c", "d"}, {"c", "d"}, {"c", "d"}}
+	if diff := cmp.Diff(expected, created); diff != "" {
+		t.Errorf("unexpected bisection jobs: %s", diff)
+	}
+
+	if len(ghc.Issues) != 1 {
+		t.Fatalf("expected one issue, got %v", ghc.Issues)
+	}
+	issue := ghc.Issues[1]
+	if issue.Title != "post-unit broke on main" || !strings.Contains(issue.Body, "- d Change d\n") || !strings.Contains(issue.Body, bad.Status.URL) {
+		t.Errorf("unexpected issue: %s\n%s", issue.Title, issue.Body)
+	}
+	if len(issue.Labels) != 1 || issue.Labels[0].Name != "kind/regression" {
+		t.Errorf("expected the issue to be labeled, got %v", issue.Labels)
+	}
+	reported := &prowapi.ProwJob{}
+	if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKeyFromObject(bad), reported); err != nil {
+		t.Fatalf("failed to get ProwJob: %v", err)
+	}
+	if culprit := reported.Annotations[kube.BisectionCulpritAnnotation]; culprit != "d" {
+		t.Errorf("expected the culprit to be recorded, got %q", culprit)
+	}
+	if len(c.commits) != 0 {
+		t.Errorf("expected the commits of the reported regression to be dropped, got %v", c.commits)
+	}
+}
+
+func TestSyncTooManyCommits(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Bisection.Policies["org"] = config.BisectionPolicy{MaxCommits: 2}
+	ghc := fakegithub.NewFakeClient()
+	parent := "a"
+	for _, sha := range []string{"b", "c", "d"} {
+		ghc.Commits[sha] = github.RepositoryCommit{Parents: []github.GitCommit{{SHA: parent}}}
+		parent = sha
+	}
+	client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(
+		postsubmitRun("good", "a", prowapi.SuccessState, -time.Hour),
+		postsubmitRun("bad", "d", prowapi.FailureState, 0),
+	).Build()
+	c := NewController(client, func() *config.Config { return cfg }, ghc)
+	if err := c.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if jobs := bisectionJobs(t, client, nil); len(jobs) != 0 {
+		t.Errorf("expected no bisection of more than max_commits commits, got %v", jobs)
+	}
+}
@@ -0,0 +1,381 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/version"
+)
+
+// Client interacts with the Bitbucket Server REST API (1.0) or the Bitbucket
+// Cloud REST API (2.0).
+type Client interface {
+	// BotUser returns the name of the user the client authenticates as.
+	BotUser() (string, error)
+	// CreatePullRequestComment adds a comment to a pull request.
+	CreatePullRequestComment(project, repo string, id int, text string) error
+	// ListPullRequestComments lists the comments on a pull request, oldest
+	// first.
+	ListPullRequestComments(project, repo string, id int) ([]Comment, error)
+	// GetPullRequestChanges lists the files changed by a pull request.
+	GetPullRequestChanges(project, repo string, id int) ([]Change, error)
+	// GetBranchHead returns the SHA the branch currently points to.
+	GetBranchHead(project, repo, branch string) (string, error)
+	// ResolveCommit returns the full SHA of a commit, e.g. of the abbreviated
+	// SHAs in Bitbucket Cloud webhooks.
+	ResolveCommit(project, repo, rev string) (string, error)
+	// GetUserPermission returns the highest permission granted to the user
+	// on the repository, either directly or through the project. Permissions
+	// granted through groups are not taken into account.
+	GetUserPermission(project, repo, user string) (Permission, error)
+	// SetBuildStatus creates or updates the build status with the given key
+	// on a commit of the repository.
+	SetBuildStatus(project, repo, sha string, status BuildStatus) error
+	// ListBuildStatuses lists the build statuses on a commit of the
+	// repository.
+	ListBuildStatuses(project, repo, sha string) ([]BuildStatus, error)
+}
+
+type client struct {
+	logger         *logrus.Entry
+	endpoint       string
+	tokenGenerator func() []byte
+	dryRun         bool
+	client         *http.Client
+
+	mut     sync.Mutex
+	botUser string
+}
+
+const maxRetries = 3
+
+// NewClient creates a client for the Bitbucket Server or Data Center instance
+// at endpoint, e.g. https://bitbucket.example.com, authenticating with the
+// HTTP access token returned by tokenGenerator.
+func NewClient(endpoint string, tokenGenerator func() []byte) Client {
+	return &client{
+		logger:         logrus.WithField("client", "bitbucket"),
+		endpoint:       strings.TrimSuffix(endpoint, "/"),
+		tokenGenerator: tokenGenerator,
+		client:         &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// NewDryRunClient creates a client that only performs read operations and
+// logs every mutation it would have made.
+func NewDryRunClient(endpoint string, tokenGenerator func() []byte) Client {
+	c := NewClient(endpoint, tokenGenerator).(*client)
+	c.dryRun = true
+	return c
+}
+
+// requestError is returned for requests that completed with an unexpected
+// status code.
+type requestError struct {
+	method, path string
+	statusCode   int
+	body         string
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d: %s", e.method, e.path, e.statusCode, e.body)
+}
+
+// IsNotFound returns whether the error was caused by a 404 response.
+func IsNotFound(err error) bool {
+	var reqErr *requestError
+	return errors.As(err, &reqErr) && reqErr.statusCode == http.StatusNotFound
+}
+
+// request performs a request against the API and decodes the response into
+// ret if it is non-nil. It returns the response headers.
+func (c *client) request(method, path string, body, ret interface{}) (http.Header, error) {
+	if c.dryRun && method != http.MethodGet {
+		c.logger.WithFields(logrus.Fields{"method": method, "path": path}).Info("Dry run, not sending request.")
+		return http.Header{}, nil
+	}
+	var payload []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = b
+	}
+
+	var resp *http.Response
+	var err error
+	backoff := time.Second
+	for retries := 0; retries < maxRetries; retries++ {
+		var req *http.Request
+		req, err = http.NewRequest(method, c.endpoint+path, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+string(c.tokenGenerator()))
+		req.Header.Set("User-Agent", version.UserAgentWithIdentifier("bitbucket"))
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err = c.client.Do(req)
+		if err == nil && resp.StatusCode < 500 {
+			break
+		}
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &requestError{method: method, path: path, statusCode: resp.StatusCode, body: string(b)}
+	}
+	if ret != nil && len(b) > 0 {
+		if err := json.Unmarshal(b, ret); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response of %s %s: %w", method, path, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// page is the envelope of paged API responses.
+type page struct {
+	Values        json.RawMessage `json:"values"`
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart int             `json:"nextPageStart"`
+}
+
+// getPaged follows nextPageStart and calls accumulate with the raw values of
+// every page.
+func (c *client) getPaged(path string, accumulate func(json.RawMessage) error) error {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	start := 0
+	for {
+		var p page
+		if _, err := c.request(http.MethodGet, fmt.Sprintf("%s%slimit=100&start=%d", path, separator, start), nil, &p); err != nil {
+			return err
+		}
+		if err := accumulate(p.Values); err != nil {
+			return fmt.Errorf("failed to unmarshal values of %s: %w", path, err)
+		}
+		if p.IsLastPage || p.NextPageStart <= start {
+			return nil
+		}
+		start = p.NextPageStart
+	}
+}
+
+func repoPath(project, repo string) string {
+	return fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s", url.PathEscape(project), url.PathEscape(repo))
+}
+
+func (c *client) BotUser() (string, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.botUser != "" {
+		return c.botUser, nil
+	}
+	// Bitbucket Server has no endpoint for the current user, but it returns
+	// the name of the authenticated user in a header of every response.
+	header, err := c.request(http.MethodGet, "/rest/api/1.0/application-properties", nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bot user: %w", err)
+	}
+	user := header.Get("X-AUSERNAME")
+	if user == "" {
+		return "", errors.New("failed to get bot user: response has no X-AUSERNAME header")
+	}
+	c.botUser = user
+	return c.botUser, nil
+}
+
+func (c *client) CreatePullRequestComment(project, repo string, id int, text string) error {
+	c.logger.WithFields(logrus.Fields{"project": project, "repo": repo, "id": id}).Debug("CreatePullRequestComment")
+	path := fmt.Sprintf("%s/pull-requests/%d/comments", repoPath(project, repo), id)
+	_, err := c.request(http.MethodPost, path, map[string]string{"text": text}, nil)
+	return err
+}
+
+func (c *client) ListPullRequestComments(project, repo string, id int) ([]Comment, error) {
+	var comments []Comment
+	path := fmt.Sprintf("%s/pull-requests/%d/activities", repoPath(project, repo), id)
+	err := c.getPaged(path, func(values json.RawMessage) error {
+		var activities []struct {
+			Action  string   `json:"action"`
+			Comment *Comment `json:"comment"`
+		}
+		if err := json.Unmarshal(values, &activities); err != nil {
+			return err
+		}
+		for _, activity := range activities {
+			if activity.Action == "COMMENTED" && activity.Comment != nil {
+				comments = append(comments, *activity.Comment)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Activities are returned newest first.
+	for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+		comments[i], comments[j] = comments[j], comments[i]
+	}
+	return comments, nil
+}
+
+func (c *client) GetPullRequestChanges(project, repo string, id int) ([]Change, error) {
+	var changes []Change
+	path := fmt.Sprintf("%s/pull-requests/%d/changes", repoPath(project, repo), id)
+	err := c.getPaged(path, func(values json.RawMessage) error {
+		var page []struct {
+			Path struct {
+				ToString string `json:"toString"`
+			} `json:"path"`
+			SrcPath *struct {
+				ToString string `json:"toString"`
+			} `json:"srcPath"`
+		}
+		if err := json.Unmarshal(values, &page); err != nil {
+			return err
+		}
+		for _, change := range page {
+			ch := Change{Path: change.Path.ToString}
+			if change.SrcPath != nil {
+				ch.SrcPath = change.SrcPath.ToString
+			}
+			changes = append(changes, ch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (c *client) GetBranchHead(project, repo, branch string) (string, error) {
+	var commits struct {
+		Values []struct {
+			ID string `json:"id"`
+		} `json:"values"`
+	}
+	path := fmt.Sprintf("%s/commits?limit=1&until=%s", repoPath(project, repo), url.QueryEscape("refs/heads/"+branch))
+	if _, err := c.request(http.MethodGet, path, nil, &commits); err != nil {
+		return "", err
+	}
+	if len(commits.Values) == 0 {
+		return "", fmt.Errorf("branch %s of %s/%s has no commits", branch, project, repo)
+	}
+	return commits.Values[0].ID, nil
+}
+
+func (c *client) GetUserPermission(project, repo, user string) (Permission, error) {
+	var highest Permission
+	for _, path := range []string{
+		repoPath(project, repo) + "/permissions/users",
+		fmt.Sprintf("/rest/api/1.0/projects/%s/permissions/users", url.PathEscape(project)),
+	} {
+		err := c.getPaged(path+"?filter="+url.QueryEscape(user), func(values json.RawMessage) error {
+			var grants []struct {
+				User       User       `json:"user"`
+				Permission Permission `json:"permission"`
+			}
+			if err := json.Unmarshal(values, &grants); err != nil {
+				return err
+			}
+			for _, grant := range grants {
+				// The filter matches substrings of names and emails.
+				if grant.User.Name != user {
+					continue
+				}
+				if highest == "" || grant.Permission.CanWrite() {
+					highest = grant.Permission
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to get permissions of %s: %w", user, err)
+		}
+		if highest.CanWrite() {
+			break
+		}
+	}
+	return highest, nil
+}
+
+func (c *client) ResolveCommit(project, repo, rev string) (string, error) {
+	var commit struct {
+		ID string `json:"id"`
+	}
+	path := fmt.Sprintf("%s/commits/%s", repoPath(project, repo), url.PathEscape(rev))
+	if _, err := c.request(http.MethodGet, path, nil, &commit); err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
+
+// SetBuildStatus uses the build status API that is not scoped to
+// repositories, as the repository scoped one needs Bitbucket Server 7.4.
+func (c *client) SetBuildStatus(_, _, sha string, status BuildStatus) error {
+	c.logger.WithFields(logrus.Fields{"sha": sha, "key": status.Key, "state": status.State}).Debug("SetBuildStatus")
+	_, err := c.request(http.MethodPost, "/rest/build-status/1.0/commits/"+url.PathEscape(sha), status, nil)
+	return err
+}
+
+func (c *client) ListBuildStatuses(_, _, sha string) ([]BuildStatus, error) {
+	var statuses []BuildStatus
+	err := c.getPaged("/rest/build-status/1.0/commits/"+url.PathEscape(sha), func(values json.RawMessage) error {
+		var page []BuildStatus
+		if err := json.Unmarshal(values, &page); err != nil {
+			return err
+		}
+		statuses = append(statuses, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
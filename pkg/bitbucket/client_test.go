@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, func() []byte { return []byte("token") })
+}
+
+func TestSetBuildStatus(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/build-status/1.0/commits/abc" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("unexpected authorization %q", auth)
+		}
+		b, _ := io.ReadAll(r.Body)
+		var body map[string]string
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Fatalf("failed to unmarshal body: %v", err)
+		}
+		expected := map[string]string{"key": "unit", "name": "unit", "state": "SUCCESSFUL", "url": "https://prow/job", "description": "Job succeeded."}
+		if diff := cmp.Diff(expected, body); diff != "" {
+			t.Errorf("unexpected body: %s", diff)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	err := c.SetBuildStatus("PRJ", "repo", "abc", BuildStatus{Key: "unit", Name: "unit", State: BuildStateSuccessful, URL: "https://prow/job", Description: "Job succeeded."})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestListBuildStatusesPaginates(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch start := r.URL.Query().Get("start"); start {
+		case "0":
+			fmt.Fprint(w, `{"isLastPage":false,"nextPageStart":1,"values":[{"key":"a","state":"FAILED"}]}`)
+		case "1":
+			fmt.Fprint(w, `{"isLastPage":true,"values":[{"key":"b","state":"SUCCESSFUL"}]}`)
+		default:
+			t.Errorf("unexpected start %q", start)
+		}
+	})
+	statuses, err := c.ListBuildStatuses("PRJ", "repo", "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []BuildStatus{{Key: "a", State: BuildStateFailed}, {Key: "b", State: BuildStateSuccessful}}
+	if diff := cmp.Diff(expected, statuses); diff != "" {
+		t.Errorf("unexpected statuses: %s", diff)
+	}
+}
+
+func TestGetUserPermission(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/1.0/projects/PRJ/repos/repo/permissions/users":
+			fmt.Fprint(w, `{"isLastPage":true,"values":[{"user":{"name":"reader"},"permission":"REPO_READ"},{"user":{"name":"dev-2"},"permission":"REPO_WRITE"}]}`)
+		case "/rest/api/1.0/projects/PRJ/permissions/users":
+			fmt.Fprint(w, `{"isLastPage":true,"values":[{"user":{"name":"dev"},"permission":"PROJECT_WRITE"}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	for user, expected := range map[string]Permission{"dev": PermissionProjectWrite, "reader": "REPO_READ", "dev-2": PermissionRepoWrite, "stranger": ""} {
+		permission, err := c.GetUserPermission("PRJ", "repo", user)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", user, err)
+		}
+		if permission != expected {
+			t.Errorf("expected %s to have %q, got %q", user, expected, permission)
+		}
+	}
+}
+
+func TestListPullRequestComments(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/1.0/projects/PRJ/repos/repo/pull-requests/1/activities" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"isLastPage":true,"values":[
+			{"action":"COMMENTED","comment":{"id":2,"text":"/ok-to-test","author":{"name":"dev"}}},
+			{"action":"APPROVED"},
+			{"action":"COMMENTED","comment":{"id":1,"text":"first","author":{"name":"author"}}}
+		]}`)
+	})
+	comments, err := c.ListPullRequestComments("PRJ", "repo", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Comment{
+		{ID: 1, Text: "first", Author: User{Name: "author"}},
+		{ID: 2, Text: "/ok-to-test", Author: User{Name: "dev"}},
+	}
+	if diff := cmp.Diff(expected, comments); diff != "" {
+		t.Errorf("unexpected comments: %s", diff)
+	}
+}
+
+func TestBotUser(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-AUSERNAME", "prow-bot")
+		fmt.Fprint(w, `{"version":"8.9.0"}`)
+	})
+	user, err := c.BotUser()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user != "prow-bot" {
+		t.Errorf("expected prow-bot, got %q", user)
+	}
+}
+
+func TestDryRunClientSkipsMutations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+	c := NewDryRunClient(server.URL, func() []byte { return nil })
+	if err := c.CreatePullRequestComment("PRJ", "repo", 1, "hello"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
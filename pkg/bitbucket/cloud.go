@@ -0,0 +1,371 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CloudEndpoint is the endpoint of the Bitbucket Cloud REST API.
+const CloudEndpoint = "https://api.bitbucket.org"
+
+// cloudClient implements Client with the Bitbucket Cloud REST API (2.0). It
+// reuses the request handling of the Bitbucket Server client, as both APIs
+// authenticate with bearer tokens.
+type cloudClient struct {
+	*client
+}
+
+// NewCloudClient creates a client for Bitbucket Cloud, authenticating with
+// the repository, project or workspace access token returned by
+// tokenGenerator. The endpoint is usually CloudEndpoint.
+func NewCloudClient(endpoint string, tokenGenerator func() []byte) Client {
+	return &cloudClient{client: NewClient(endpoint, tokenGenerator).(*client)}
+}
+
+// NewDryRunCloudClient creates a Bitbucket Cloud client that only performs
+// read operations and logs every mutation it would have made.
+func NewDryRunCloudClient(endpoint string, tokenGenerator func() []byte) Client {
+	c := NewCloudClient(endpoint, tokenGenerator).(*cloudClient)
+	c.dryRun = true
+	return c
+}
+
+// cloudPage is the envelope of paged API responses.
+type cloudPage struct {
+	Values json.RawMessage `json:"values"`
+	Next   string          `json:"next"`
+}
+
+// getPaged follows the next links and calls accumulate with the raw values of
+// every page.
+func (c *cloudClient) getPaged(path string, accumulate func(json.RawMessage) error) error {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	next := path + separator + "pagelen=100"
+	for next != "" {
+		var p cloudPage
+		if _, err := c.request(http.MethodGet, next, nil, &p); err != nil {
+			return err
+		}
+		if err := accumulate(p.Values); err != nil {
+			return fmt.Errorf("failed to unmarshal values of %s: %w", path, err)
+		}
+		if p.Next == "" {
+			return nil
+		}
+		// The next links are absolute, only follow the ones of the API the
+		// token is meant for.
+		if !strings.HasPrefix(p.Next, c.endpoint+"/") {
+			return fmt.Errorf("next page of %s is not on %s: %s", path, c.endpoint, p.Next)
+		}
+		next = strings.TrimPrefix(p.Next, c.endpoint)
+	}
+	return nil
+}
+
+func cloudRepoPath(workspace, repo string) string {
+	return fmt.Sprintf("/2.0/repositories/%s/%s", url.PathEscape(workspace), url.PathEscape(repo))
+}
+
+// cloudUser is a user in the Bitbucket Cloud API.
+type cloudUser struct {
+	AccountID   string `json:"account_id"`
+	Nickname    string `json:"nickname"`
+	DisplayName string `json:"display_name"`
+}
+
+func (u cloudUser) toUser() User {
+	return User{Name: u.AccountID, Slug: u.Nickname, DisplayName: u.DisplayName}
+}
+
+// cloudComment is a pull request comment in the Bitbucket Cloud API.
+type cloudComment struct {
+	ID      int `json:"id"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	User    cloudUser `json:"user"`
+	Deleted bool      `json:"deleted"`
+}
+
+func (c cloudComment) toComment() Comment {
+	return Comment{ID: c.ID, Text: c.Content.Raw, Author: c.User.toUser()}
+}
+
+func (c *cloudClient) BotUser() (string, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.botUser != "" {
+		return c.botUser, nil
+	}
+	var user cloudUser
+	if _, err := c.request(http.MethodGet, "/2.0/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to get bot user: %w", err)
+	}
+	c.botUser = user.AccountID
+	return c.botUser, nil
+}
+
+func (c *cloudClient) CreatePullRequestComment(workspace, repo string, id int, text string) error {
+	c.logger.WithFields(logrus.Fields{"workspace": workspace, "repo": repo, "id": id}).Debug("CreatePullRequestComment")
+	path := fmt.Sprintf("%s/pullrequests/%d/comments", cloudRepoPath(workspace, repo), id)
+	body := map[string]map[string]string{"content": {"raw": text}}
+	_, err := c.request(http.MethodPost, path, body, nil)
+	return err
+}
+
+func (c *cloudClient) ListPullRequestComments(workspace, repo string, id int) ([]Comment, error) {
+	var comments []Comment
+	path := fmt.Sprintf("%s/pullrequests/%d/comments?sort=created_on", cloudRepoPath(workspace, repo), id)
+	err := c.getPaged(path, func(values json.RawMessage) error {
+		var page []cloudComment
+		if err := json.Unmarshal(values, &page); err != nil {
+			return err
+		}
+		for _, comment := range page {
+			if !comment.Deleted {
+				comments = append(comments, comment.toComment())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (c *cloudClient) GetPullRequestChanges(workspace, repo string, id int) ([]Change, error) {
+	var changes []Change
+	path := fmt.Sprintf("%s/pullrequests/%d/diffstat", cloudRepoPath(workspace, repo), id)
+	err := c.getPaged(path, func(values json.RawMessage) error {
+		type file struct {
+			Path string `json:"path"`
+		}
+		var page []struct {
+			Old *file `json:"old"`
+			New *file `json:"new"`
+		}
+		if err := json.Unmarshal(values, &page); err != nil {
+			return err
+		}
+		for _, diffstat := range page {
+			var ch Change
+			if diffstat.Old != nil {
+				ch.Path = diffstat.Old.Path
+				ch.SrcPath = diffstat.Old.Path
+			}
+			if diffstat.New != nil {
+				ch.Path = diffstat.New.Path
+			}
+			changes = append(changes, ch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (c *cloudClient) GetBranchHead(workspace, repo, branch string) (string, error) {
+	var ref struct {
+		Target struct {
+			Hash string `json:"hash"`
+		} `json:"target"`
+	}
+	path := fmt.Sprintf("%s/refs/branches/%s", cloudRepoPath(workspace, repo), url.PathEscape(branch))
+	if _, err := c.request(http.MethodGet, path, nil, &ref); err != nil {
+		return "", err
+	}
+	return ref.Target.Hash, nil
+}
+
+func (c *cloudClient) ResolveCommit(workspace, repo, rev string) (string, error) {
+	var commit struct {
+		Hash string `json:"hash"`
+	}
+	path := fmt.Sprintf("%s/commit/%s", cloudRepoPath(workspace, repo), url.PathEscape(rev))
+	if _, err := c.request(http.MethodGet, path, nil, &commit); err != nil {
+		return "", err
+	}
+	return commit.Hash, nil
+}
+
+// cloudPermissions maps the repository permissions of Bitbucket Cloud to the
+// ones of Bitbucket Server.
+var cloudPermissions = map[string]Permission{
+	"read":  PermissionRepoRead,
+	"write": PermissionRepoWrite,
+	"admin": PermissionRepoAdmin,
+}
+
+// GetUserPermission returns the permission granted explicitly to the user,
+// identified by its account ID, on the repository. It requires a token with
+// admin permission on the repository.
+func (c *cloudClient) GetUserPermission(workspace, repo, user string) (Permission, error) {
+	var grant struct {
+		Permission string `json:"permission"`
+	}
+	path := fmt.Sprintf("%s/permissions-config/users/%s", cloudRepoPath(workspace, repo), url.PathEscape(user))
+	if _, err := c.request(http.MethodGet, path, nil, &grant); err != nil {
+		if IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get permissions of %s: %w", user, err)
+	}
+	return cloudPermissions[grant.Permission], nil
+}
+
+func (c *cloudClient) SetBuildStatus(workspace, repo, sha string, status BuildStatus) error {
+	c.logger.WithFields(logrus.Fields{"sha": sha, "key": status.Key, "state": status.State}).Debug("SetBuildStatus")
+	path := fmt.Sprintf("%s/commit/%s/statuses/build", cloudRepoPath(workspace, repo), url.PathEscape(sha))
+	_, err := c.request(http.MethodPost, path, status, nil)
+	return err
+}
+
+func (c *cloudClient) ListBuildStatuses(workspace, repo, sha string) ([]BuildStatus, error) {
+	var statuses []BuildStatus
+	path := fmt.Sprintf("%s/commit/%s/statuses", cloudRepoPath(workspace, repo), url.PathEscape(sha))
+	err := c.getPaged(path, func(values json.RawMessage) error {
+		var page []BuildStatus
+		if err := json.Unmarshal(values, &page); err != nil {
+			return err
+		}
+		statuses = append(statuses, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// cloudRepository is a repository in Bitbucket Cloud webhooks.
+type cloudRepository struct {
+	FullName string `json:"full_name"`
+	Name     string `json:"name"`
+	Links    struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+func (r cloudRepository) toRepository() Repository {
+	workspace, slug, _ := strings.Cut(r.FullName, "/")
+	repo := Repository{Slug: slug, Name: r.Name, Project: Project{Key: workspace}}
+	if href := r.Links.HTML.Href; href != "" {
+		repo.Links.Self = []Link{{Href: href}}
+		repo.Links.Clone = []Link{{Href: href + ".git", Name: "https"}}
+	}
+	return repo
+}
+
+// cloudRef is the source or destination of a pull request in Bitbucket Cloud
+// webhooks.
+type cloudRef struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+	Repository cloudRepository `json:"repository"`
+}
+
+func (r cloudRef) toRef() Ref {
+	return Ref{
+		ID:           "refs/heads/" + r.Branch.Name,
+		DisplayID:    r.Branch.Name,
+		LatestCommit: r.Commit.Hash,
+		Repository:   r.Repository.toRepository(),
+	}
+}
+
+// cloudPullRequestEvent is the payload of the pullrequest:* webhooks of
+// Bitbucket Cloud.
+type cloudPullRequestEvent struct {
+	Actor       cloudUser `json:"actor"`
+	PullRequest struct {
+		ID          int       `json:"id"`
+		Title       string    `json:"title"`
+		State       string    `json:"state"`
+		Draft       bool      `json:"draft"`
+		Author      cloudUser `json:"author"`
+		Source      cloudRef  `json:"source"`
+		Destination cloudRef  `json:"destination"`
+		Links       struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	} `json:"pullrequest"`
+	Comment *cloudComment `json:"comment,omitempty"`
+}
+
+// cloudEventKeys maps the Bitbucket Cloud event keys with a Bitbucket Server
+// equivalent to it.
+var cloudEventKeys = map[string]string{
+	CloudEventKeyPullRequestCreated:        EventKeyPullRequestOpened,
+	CloudEventKeyPullRequestCommentCreated: EventKeyPullRequestComment,
+}
+
+// ParseCloudPullRequestEvent converts the payload of a pullrequest:* webhook
+// of Bitbucket Cloud to a PullRequestEvent. Its commits are abbreviated, see
+// Client.ResolveCommit.
+func ParseCloudPullRequestEvent(eventKey string, payload []byte) (*PullRequestEvent, error) {
+	var e cloudPullRequestEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, err
+	}
+	pr := e.PullRequest
+	pre := &PullRequestEvent{
+		EventKey: eventKey,
+		Actor:    e.Actor.toUser(),
+		PullRequest: PullRequest{
+			ID:      pr.ID,
+			Title:   pr.Title,
+			State:   pr.State,
+			Draft:   pr.Draft,
+			FromRef: pr.Source.toRef(),
+			ToRef:   pr.Destination.toRef(),
+			Author:  Participant{User: pr.Author.toUser(), Role: "AUTHOR"},
+			cloud:   true,
+		},
+	}
+	if key, ok := cloudEventKeys[eventKey]; ok {
+		pre.EventKey = key
+	}
+	if href := pr.Links.HTML.Href; href != "" {
+		pre.PullRequest.Links.Self = []Link{{Href: href}}
+	}
+	if e.Comment != nil {
+		comment := e.Comment.toComment()
+		pre.Comment = &comment
+	}
+	return pre, nil
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestCloudClient(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, endpoint string)) Client {
+	t.Helper()
+	var endpoint string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, endpoint)
+	}))
+	t.Cleanup(server.Close)
+	endpoint = server.URL
+	return NewCloudClient(server.URL, func() []byte { return []byte("token") })
+}
+
+func TestCloudSetBuildStatus(t *testing.T) {
+	c := newTestCloudClient(t, func(w http.ResponseWriter, r *http.Request, _ string) {
+		if r.Method != http.MethodPost || r.URL.Path != "/2.0/repositories/ws/repo/commit/abc/statuses/build" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("unexpected authorization %q", auth)
+		}
+		b, _ := io.ReadAll(r.Body)
+		var body map[string]string
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Fatalf("failed to unmarshal body: %v", err)
+		}
+		expected := map[string]string{"key": "unit", "name": "unit", "state": "SUCCESSFUL", "url": "https://prow/job", "description": "Job succeeded."}
+		if diff := cmp.Diff(expected, body); diff != "" {
+			t.Errorf("unexpected body: %s", diff)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	err := c.SetBuildStatus("ws", "repo", "abc", BuildStatus{Key: "unit", Name: "unit", State: BuildStateSuccessful, URL: "https://prow/job", Description: "Job succeeded."})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCloudListBuildStatusesPaginates(t *testing.T) {
+	c := newTestCloudClient(t, func(w http.ResponseWriter, r *http.Request, endpoint string) {
+		if r.URL.Path != "/2.0/repositories/ws/repo/commit/abc/statuses" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch page := r.URL.Query().Get("page"); page {
+		case "":
+			fmt.Fprintf(w, `{"next":"%s/2.0/repositories/ws/repo/commit/abc/statuses?pagelen=100&page=2","values":[{"key":"a","state":"FAILED"}]}`, endpoint)
+		case "2":
+			fmt.Fprint(w, `{"values":[{"key":"b","state":"SUCCESSFUL"}]}`)
+		default:
+			t.Errorf("unexpected page %q", page)
+		}
+	})
+	statuses, err := c.ListBuildStatuses("ws", "repo", "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []BuildStatus{{Key: "a", State: BuildStateFailed}, {Key: "b", State: BuildStateSuccessful}}
+	if diff := cmp.Diff(expected, statuses); diff != "" {
+		t.Errorf("unexpected statuses: %s", diff)
+	}
+}
+
+func TestCloudGetPagedRejectsForeignNextLink(t *testing.T) {
+	c := newTestCloudClient(t, func(w http.ResponseWriter, r *http.Request, _ string) {
+		fmt.Fprint(w, `{"next":"https://attacker.example.com/2.0/repositories/ws/repo/commit/abc/statuses?page=2","values":[]}`)
+	})
+	if _, err := c.ListBuildStatuses("ws", "repo", "abc"); err == nil {
+		t.Error("expected an error for a next link on another host")
+	}
+}
+
+func TestCloudGetUserPermission(t *testing.T) {
+	c := newTestCloudClient(t, func(w http.ResponseWriter, r *http.Request, _ string) {
+		switch r.URL.Path {
+		case "/2.0/repositories/ws/repo/permissions-config/users/dev":
+			fmt.Fprint(w, `{"permission":"write"}`)
+		case "/2.0/repositories/ws/repo/permissions-config/users/reader":
+			fmt.Fprint(w, `{"permission":"read"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	for user, expected := range map[string]Permission{"dev": PermissionRepoWrite, "reader": PermissionRepoRead, "stranger": ""} {
+		permission, err := c.GetUserPermission("ws", "repo", user)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", user, err)
+		}
+		if permission != expected {
+			t.Errorf("expected %s to have %q, got %q", user, expected, permission)
+		}
+	}
+}
+
+func TestCloudListPullRequestComments(t *testing.T) {
+	c := newTestCloudClient(t, func(w http.ResponseWriter, r *http.Request, _ string) {
+		if r.URL.Path != "/2.0/repositories/ws/repo/pullrequests/1/comments" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"values":[
+			{"id":1,"content":{"raw":"first"},"user":{"account_id":"id-author","nickname":"author"}},
+			{"id":2,"content":{"raw":"gone"},"user":{"account_id":"id-dev","nickname":"dev"},"deleted":true},
+			{"id":3,"content":{"raw":"/ok-to-test"},"user":{"account_id":"id-dev","nickname":"dev"}}
+		]}`)
+	})
+	comments, err := c.ListPullRequestComments("ws", "repo", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Comment{
+		{ID: 1, Text: "first", Author: User{Name: "id-author", Slug: "author"}},
+		{ID: 3, Text: "/ok-to-test", Author: User{Name: "id-dev", Slug: "dev"}},
+	}
+	if diff := cmp.Diff(expected, comments); diff != "" {
+		t.Errorf("unexpected comments: %s", diff)
+	}
+}
+
+func TestCloudGetPullRequestChanges(t *testing.T) {
+	c := newTestCloudClient(t, func(w http.ResponseWriter, r *http.Request, _ string) {
+		fmt.Fprint(w, `{"values":[
+			{"old":null,"new":{"path":"added.go"}},
+			{"old":{"path":"old.go"},"new":{"path":"new.go"}},
+			{"old":{"path":"removed.go"},"new":null}
+		]}`)
+	})
+	changes, err := c.GetPullRequestChanges("ws", "repo", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Change{
+		{Path: "added.go"},
+		{Path: "new.go", SrcPath: "old.go"},
+		{Path: "removed.go", SrcPath: "removed.go"},
+	}
+	if diff := cmp.Diff(expected, changes); diff != "" {
+		t.Errorf("unexpected changes: %s", diff)
+	}
+}
+
+func TestCloudResolveCommit(t *testing.T) {
+	c := newTestCloudClient(t, func(w http.ResponseWriter, r *http.Request, _ string) {
+		if r.URL.Path != "/2.0/repositories/ws/repo/commit/abc123" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"hash":"abc123def456"}`)
+	})
+	sha, err := c.ResolveCommit("ws", "repo", "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sha != "abc123def456" {
+		t.Errorf("expected abc123def456, got %q", sha)
+	}
+}
+
+const cloudCommentPayload = `{
+	"actor": {"account_id": "id-dev", "nickname": "dev"},
+	"pullrequest": {
+		"id": 1,
+		"title": "Add feature",
+		"state": "OPEN",
+		"author": {"account_id": "id-author", "nickname": "author"},
+		"source": {
+			"branch": {"name": "feature"},
+			"commit": {"hash": "abc123"},
+			"repository": {"full_name": "%s", "links": {"html": {"href": "https://bitbucket.org/%[1]s"}}}
+		},
+		"destination": {
+			"branch": {"name": "main"},
+			"commit": {"hash": "def456"},
+			"repository": {"full_name": "ws/repo", "links": {"html": {"href": "https://bitbucket.org/ws/repo"}}}
+		},
+		"links": {"html": {"href": "https://bitbucket.org/ws/repo/pull-requests/1"}}
+	},
+	"comment": {"id": 2, "content": {"raw": "/lgtm"}, "user": {"account_id": "id-dev", "nickname": "dev"}}
+}`
+
+func TestParseCloudPullRequestEvent(t *testing.T) {
+	pre, err := ParseCloudPullRequestEvent(CloudEventKeyPullRequestCommentCreated, []byte(fmt.Sprintf(cloudCommentPayload, "ws/repo")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pre.EventKey != EventKeyPullRequestComment {
+		t.Errorf("expected event key %s, got %s", EventKeyPullRequestComment, pre.EventKey)
+	}
+	if pre.Comment == nil || pre.Comment.Text != "/lgtm" || pre.Comment.Author.Name != "id-dev" {
+		t.Errorf("unexpected comment %+v", pre.Comment)
+	}
+	pr := pre.PullRequest
+	repo := pr.ToRef.Repository
+	if repo.FullName() != "ws/repo" || repo.CloneURL() != "https://bitbucket.org/ws/repo.git" || repo.Host() != "https://bitbucket.org" {
+		t.Errorf("unexpected repository %+v", repo)
+	}
+	if pr.FromRef.LatestCommit != "abc123" || pr.ToRef.DisplayID != "main" || pr.Author.User.Name != "id-author" {
+		t.Errorf("unexpected pull request %+v", pr)
+	}
+	if pr.WebURL() != "https://bitbucket.org/ws/repo/pull-requests/1" {
+		t.Errorf("unexpected web URL %s", pr.WebURL())
+	}
+	if ref := pr.GitRef(); ref != "refs/heads/feature" {
+		t.Errorf("expected the source branch as ref, got %q", ref)
+	}
+
+	fork, err := ParseCloudPullRequestEvent(CloudEventKeyPullRequestUpdated, []byte(fmt.Sprintf(cloudCommentPayload, "someone/repo")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fork.EventKey != CloudEventKeyPullRequestUpdated {
+		t.Errorf("expected event key %s, got %s", CloudEventKeyPullRequestUpdated, fork.EventKey)
+	}
+	if ref := fork.PullRequest.GitRef(); ref != "" {
+		t.Errorf("expected no ref for a pull request from a fork, got %q", ref)
+	}
+}
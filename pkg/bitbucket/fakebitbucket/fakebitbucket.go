@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakebitbucket provides a fake implementation of the Bitbucket client.
+package fakebitbucket
+
+import (
+	"fmt"
+	"sync"
+
+	"sigs.k8s.io/prow/pkg/bitbucket"
+)
+
+// FakeClient is an in-memory bitbucket.Client.
+type FakeClient struct {
+	lock sync.RWMutex
+
+	Bot string
+	// Permissions maps "PROJECT/repo" to user names to permissions.
+	Permissions map[string]map[string]bitbucket.Permission
+	// BranchHeads maps "PROJECT/repo@branch" to a SHA.
+	BranchHeads map[string]string
+	// Changes maps "PROJECT/repo#id" to the files changed by a pull request.
+	Changes map[string][]bitbucket.Change
+	// Comments maps "PROJECT/repo#id" to the comments on a pull request.
+	Comments map[string][]bitbucket.Comment
+	// BuildStatuses maps a SHA to the build statuses on the commit.
+	BuildStatuses map[string][]bitbucket.BuildStatus
+	// Commits maps abbreviated SHAs to full ones. Unknown SHAs resolve to
+	// themselves.
+	Commits map[string]string
+}
+
+var _ bitbucket.Client = &FakeClient{}
+
+// NewFakeClient returns an initialized fake client.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		Bot:           "prow-bot",
+		Permissions:   map[string]map[string]bitbucket.Permission{},
+		BranchHeads:   map[string]string{},
+		Changes:       map[string][]bitbucket.Change{},
+		Comments:      map[string][]bitbucket.Comment{},
+		BuildStatuses: map[string][]bitbucket.BuildStatus{},
+		Commits:       map[string]string{},
+	}
+}
+
+// PullRequestKey builds the key used for pull request scoped fields.
+func PullRequestKey(project, repo string, id int) string {
+	return fmt.Sprintf("%s/%s#%d", project, repo, id)
+}
+
+func (f *FakeClient) BotUser() (string, error) {
+	return f.Bot, nil
+}
+
+func (f *FakeClient) CreatePullRequestComment(project, repo string, id int, text string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	key := PullRequestKey(project, repo, id)
+	f.Comments[key] = append(f.Comments[key], bitbucket.Comment{ID: len(f.Comments[key]) + 1, Text: text, Author: bitbucket.User{Name: f.Bot}})
+	return nil
+}
+
+func (f *FakeClient) ListPullRequestComments(project, repo string, id int) ([]bitbucket.Comment, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.Comments[PullRequestKey(project, repo, id)], nil
+}
+
+func (f *FakeClient) GetPullRequestChanges(project, repo string, id int) ([]bitbucket.Change, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.Changes[PullRequestKey(project, repo, id)], nil
+}
+
+func (f *FakeClient) GetBranchHead(project, repo, branch string) (string, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	sha, ok := f.BranchHeads[fmt.Sprintf("%s/%s@%s", project, repo, branch)]
+	if !ok {
+		return "", fmt.Errorf("branch %s not found in %s/%s", branch, project, repo)
+	}
+	return sha, nil
+}
+
+func (f *FakeClient) ResolveCommit(project, repo, rev string) (string, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	if sha, ok := f.Commits[rev]; ok {
+		return sha, nil
+	}
+	return rev, nil
+}
+
+func (f *FakeClient) GetUserPermission(project, repo, user string) (bitbucket.Permission, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.Permissions[project+"/"+repo][user], nil
+}
+
+func (f *FakeClient) SetBuildStatus(project, repo, sha string, status bitbucket.BuildStatus) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i, existing := range f.BuildStatuses[sha] {
+		if existing.Key == status.Key {
+			f.BuildStatuses[sha][i] = status
+			return nil
+		}
+	}
+	f.BuildStatuses[sha] = append(f.BuildStatuses[sha], status)
+	return nil
+}
+
+func (f *FakeClient) ListBuildStatuses(project, repo, sha string) ([]bitbucket.BuildStatus, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.BuildStatuses[sha], nil
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bitbucket contains webhook types and a minimal API client for
+// Bitbucket Server, Bitbucket Data Center and Bitbucket Cloud.
+//
+// The types follow the API of Bitbucket Server, Bitbucket Cloud webhooks are
+// converted to them by ParseCloudPullRequestEvent.
+package bitbucket
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Webhook headers sent by Bitbucket. Bitbucket Cloud sends the request ID
+// in CloudRequestIDHeader instead of RequestIDHeader.
+const (
+	EventKeyHeader       = "X-Event-Key"
+	RequestIDHeader      = "X-Request-Id"
+	CloudRequestIDHeader = "X-Request-UUID"
+	SignatureHeader      = "X-Hub-Signature"
+)
+
+// Values of the X-Event-Key header that Prow understands.
+const (
+	EventKeyPing                      = "diagnostics:ping"
+	EventKeyPullRequestOpened         = "pr:opened"
+	EventKeyPullRequestFromRefUpdated = "pr:from_ref_updated"
+	EventKeyPullRequestComment        = "pr:comment:added"
+)
+
+// Values of the X-Event-Key header sent by Bitbucket Cloud.
+// ParseCloudPullRequestEvent converts pullrequest:created and
+// pullrequest:comment_created to their Bitbucket Server equivalent.
+// pullrequest:updated is also sent when only the title or the description
+// of a pull request changed, so it is kept as is.
+const (
+	CloudEventKeyPullRequestCreated        = "pullrequest:created"
+	CloudEventKeyPullRequestUpdated        = "pullrequest:updated"
+	CloudEventKeyPullRequestCommentCreated = "pullrequest:comment_created"
+)
+
+// Pull request states.
+const (
+	PullRequestStateOpen     = "OPEN"
+	PullRequestStateMerged   = "MERGED"
+	PullRequestStateDeclined = "DECLINED"
+)
+
+// Permission is a repository or project permission of a user.
+type Permission string
+
+// Known permissions. Project permissions imply the corresponding repository
+// permission on every repository of the project.
+const (
+	PermissionRepoRead     Permission = "REPO_READ"
+	PermissionRepoWrite    Permission = "REPO_WRITE"
+	PermissionRepoAdmin    Permission = "REPO_ADMIN"
+	PermissionProjectWrite Permission = "PROJECT_WRITE"
+	PermissionProjectAdmin Permission = "PROJECT_ADMIN"
+)
+
+// CanWrite returns whether the permission allows pushing to a repository.
+func (p Permission) CanWrite() bool {
+	switch p {
+	case PermissionRepoWrite, PermissionRepoAdmin, PermissionProjectWrite, PermissionProjectAdmin:
+		return true
+	}
+	return false
+}
+
+// User is a Bitbucket user as it appears in webhooks and API responses. For
+// Bitbucket Cloud, Name is the account ID of the user and Slug its nickname.
+type User struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+}
+
+// Project is the project a repository belongs to.
+type Project struct {
+	Key string `json:"key"`
+}
+
+// Link is a link in the links section of an API object.
+type Link struct {
+	Href string `json:"href"`
+	Name string `json:"name,omitempty"`
+}
+
+// Links holds the clone and web links of an API object.
+type Links struct {
+	Clone []Link `json:"clone,omitempty"`
+	Self  []Link `json:"self,omitempty"`
+}
+
+// Repository is a Bitbucket repository. For Bitbucket Cloud, the project
+// key is the workspace of the repository.
+type Repository struct {
+	Slug    string  `json:"slug"`
+	Name    string  `json:"name"`
+	Project Project `json:"project"`
+	Links   Links   `json:"links"`
+}
+
+// FullName returns the "PROJECT/repo" name that Prow uses as org/repo for the
+// repository.
+func (r Repository) FullName() string {
+	return r.Project.Key + "/" + r.Slug
+}
+
+// CloneURL returns the HTTP clone URL of the repository.
+func (r Repository) CloneURL() string {
+	for _, l := range r.Links.Clone {
+		if l.Name == "http" || l.Name == "https" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// WebURL returns the URL of the repository in the Bitbucket UI.
+func (r Repository) WebURL() string {
+	if len(r.Links.Self) == 0 {
+		return ""
+	}
+	return r.Links.Self[0].Href
+}
+
+// Host returns the scheme and host of the Bitbucket instance serving the
+// repository, derived from its web URL.
+func (r Repository) Host() string {
+	u, err := url.Parse(r.WebURL())
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Ref is the source or target branch of a pull request.
+type Ref struct {
+	ID           string     `json:"id"`
+	DisplayID    string     `json:"displayId"`
+	LatestCommit string     `json:"latestCommit"`
+	Repository   Repository `json:"repository"`
+}
+
+// Participant is the author, a reviewer or another participant of a pull
+// request.
+type Participant struct {
+	User     User   `json:"user"`
+	Role     string `json:"role"`
+	Approved bool   `json:"approved"`
+}
+
+// PullRequest is a Bitbucket pull request.
+type PullRequest struct {
+	ID      int         `json:"id"`
+	Title   string      `json:"title"`
+	State   string      `json:"state"`
+	Draft   bool        `json:"draft"`
+	FromRef Ref         `json:"fromRef"`
+	ToRef   Ref         `json:"toRef"`
+	Author  Participant `json:"author"`
+	Links   Links       `json:"links"`
+
+	// cloud is set for pull requests of Bitbucket Cloud.
+	cloud bool
+}
+
+// GitRef returns the ref the commits of the pull request can be fetched from
+// the target repository with. Bitbucket Server maintains a ref for every pull
+// request, Bitbucket Cloud does not, so the source branch is used, and
+// pull requests from forks cannot be fetched, in which case it returns "".
+func (pr PullRequest) GitRef() string {
+	if !pr.cloud {
+		return fmt.Sprintf("refs/pull-requests/%d/from", pr.ID)
+	}
+	if pr.FromRef.Repository.FullName() != pr.ToRef.Repository.FullName() {
+		return ""
+	}
+	return "refs/heads/" + pr.FromRef.DisplayID
+}
+
+// WebURL returns the URL of the pull request in the Bitbucket UI.
+func (pr PullRequest) WebURL() string {
+	if len(pr.Links.Self) == 0 {
+		return ""
+	}
+	return pr.Links.Self[0].Href
+}
+
+// Comment is a comment on a pull request.
+type Comment struct {
+	ID     int    `json:"id"`
+	Text   string `json:"text"`
+	Author User   `json:"author"`
+}
+
+// PullRequestEvent is the payload of the pr:* webhooks. Comment is only set
+// for comment events and PreviousFromHash only for pr:from_ref_updated.
+type PullRequestEvent struct {
+	EventKey         string      `json:"eventKey"`
+	Actor            User        `json:"actor"`
+	PullRequest      PullRequest `json:"pullRequest"`
+	Comment          *Comment    `json:"comment,omitempty"`
+	PreviousFromHash string      `json:"previousFromHash,omitempty"`
+
+	// GUID is the request ID of the webhook, it isn't part of the payload.
+	GUID string `json:"-"`
+}
+
+// BuildState is the state of a build status.
+type BuildState string
+
+// Possible values for BuildState.
+const (
+	BuildStateInProgress BuildState = "INPROGRESS"
+	BuildStateSuccessful BuildState = "SUCCESSFUL"
+	BuildStateFailed     BuildState = "FAILED"
+)
+
+// BuildStatus is a build result attached to a commit, which Bitbucket shows
+// on pull requests.
+type BuildStatus struct {
+	Key         string     `json:"key"`
+	Name        string     `json:"name,omitempty"`
+	State       BuildState `json:"state"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+}
+
+// Change is a file changed by a pull request.
+type Change struct {
+	Path    string
+	SrcPath string
+}
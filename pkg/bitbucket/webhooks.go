@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ValidateWebhook ensures that the provided request conforms to the
+// format of a Bitbucket webhook and that its payload was signed with
+// the secret returned by tokenGenerator.
+// If it is, it returns the event key, the request ID, and the payload of
+// the webhook along with the http status code to respond with.
+func ValidateWebhook(w http.ResponseWriter, r *http.Request, tokenGenerator func() []byte) (string, string, []byte, bool, int) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		responseHTTPError(w, http.StatusMethodNotAllowed, "405 Method not allowed")
+		return "", "", nil, false, http.StatusMethodNotAllowed
+	}
+	eventKey := r.Header.Get(EventKeyHeader)
+	if eventKey == "" {
+		responseHTTPError(w, http.StatusBadRequest, "400 Bad Request: Missing X-Event-Key Header")
+		return "", "", nil, false, http.StatusBadRequest
+	}
+	sig := r.Header.Get(SignatureHeader)
+	if sig == "" {
+		responseHTTPError(w, http.StatusForbidden, "403 Forbidden: Missing X-Hub-Signature")
+		return "", "", nil, false, http.StatusForbidden
+	}
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, "500 Internal Server Error: Failed to read request body")
+		return "", "", nil, false, http.StatusInternalServerError
+	}
+	if !ValidatePayload(payload, sig, tokenGenerator) {
+		responseHTTPError(w, http.StatusForbidden, "403 Forbidden: Invalid X-Hub-Signature")
+		return "", "", nil, false, http.StatusForbidden
+	}
+	requestID := r.Header.Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = r.Header.Get(CloudRequestIDHeader)
+	}
+	return eventKey, requestID, payload, true, http.StatusOK
+}
+
+// ValidatePayload ensures that the request payload signature matches the key.
+// Bitbucket Server and Bitbucket Cloud sign payloads with HMAC-SHA256 and send
+// the signature as "sha256=<hex digest>".
+func ValidatePayload(payload []byte, sig string, tokenGenerator func() []byte) bool {
+	if !strings.HasPrefix(sig, "sha256=") {
+		return false
+	}
+	sb, err := hex.DecodeString(strings.TrimPrefix(sig, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, tokenGenerator())
+	mac.Write(payload)
+	return hmac.Equal(sb, mac.Sum(nil))
+}
+
+func responseHTTPError(w http.ResponseWriter, statusCode int, response string) {
+	logrus.WithFields(logrus.Fields{
+		"response":    response,
+		"status-code": statusCode,
+	}).Debug(response)
+	http.Error(w, response, statusCode)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func sign(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidateWebhook(t *testing.T) {
+	tokenGenerator := func() []byte { return []byte("secret") }
+	payload := `{"eventKey":"pr:opened"}`
+	testCases := []struct {
+		name         string
+		method       string
+		headers      map[string]string
+		expectedOK   bool
+		expectedCode int
+	}{
+		{
+			name:   "valid webhook",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventKeyHeader:  EventKeyPullRequestOpened,
+				RequestIDHeader: "some-id",
+				SignatureHeader: sign(payload, "secret"),
+			},
+			expectedOK:   true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "wrong method",
+			method:       http.MethodGet,
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "missing event key",
+			method: http.MethodPost,
+			headers: map[string]string{
+				SignatureHeader: sign(payload, "secret"),
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "missing signature",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventKeyHeader: EventKeyPullRequestOpened,
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:   "signed with another secret",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventKeyHeader:  EventKeyPullRequestOpened,
+				SignatureHeader: sign(payload, "not-the-secret"),
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:   "sha1 signature",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventKeyHeader:  EventKeyPullRequestOpened,
+				SignatureHeader: "sha1=abcdef",
+			},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/bitbucket-hook", bytes.NewBufferString(payload))
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			eventKey, _, body, ok, code := ValidateWebhook(w, req, tokenGenerator)
+			if ok != tc.expectedOK {
+				t.Errorf("expected ok=%t, got %t", tc.expectedOK, ok)
+			}
+			if code != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, code)
+			}
+			if ok && (eventKey != EventKeyPullRequestOpened || string(body) != payload) {
+				t.Errorf("unexpected event key %q or payload %q", eventKey, string(body))
+			}
+		})
+	}
+}
+
+func TestRepositoryLinks(t *testing.T) {
+	r := Repository{
+		Slug:    "repo",
+		Project: Project{Key: "PRJ"},
+		Links: Links{
+			Clone: []Link{
+				{Name: "ssh", Href: "ssh://git@bitbucket.example.com:7999/prj/repo.git"},
+				{Name: "http", Href: "https://bitbucket.example.com/scm/prj/repo.git"},
+			},
+			Self: []Link{{Href: "https://bitbucket.example.com/projects/PRJ/repos/repo/browse"}},
+		},
+	}
+	if name := r.FullName(); name != "PRJ/repo" {
+		t.Errorf("unexpected full name %s", name)
+	}
+	if clone := r.CloneURL(); clone != "https://bitbucket.example.com/scm/prj/repo.git" {
+		t.Errorf("unexpected clone URL %s", clone)
+	}
+	if host := r.Host(); host != "https://bitbucket.example.com" {
+		t.Errorf("unexpected host %s", host)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterregistration contains a controller that validates the
+// connectivity of build clusters registered with ClusterRegistrations.
+package clusterregistration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+const (
+	ControllerName = "cluster-registration"
+
+	// resyncPeriod is how often registered build clusters are probed.
+	resyncPeriod = 5 * time.Minute
+	// probeTimeout bounds a single connectivity check.
+	probeTimeout = 10 * time.Second
+)
+
+// Add adds the controller to the manager. Auth secrets are read uncached,
+// so that the manager does not have to cache every secret in the namespace.
+func Add(mgr controllerruntime.Manager) error {
+	reconciler := NewReconciler(mgr.GetClient(), mgr.GetAPIReader(), probeServerVersion)
+	if err := controllerruntime.NewControllerManagedBy(mgr).
+		Named(ControllerName).
+		For(&prowv1.ClusterRegistration{}).
+		Complete(reconciler); err != nil {
+		return fmt.Errorf("failed to construct controller: %w", err)
+	}
+	return nil
+}
+
+// Prober checks that a build cluster can be reached and returns its version.
+type Prober func(cfg *rest.Config) (string, error)
+
+type Reconciler struct {
+	client       ctrlruntimeclient.Client
+	secretReader ctrlruntimeclient.Reader
+	probe        Prober
+	log          *logrus.Entry
+}
+
+func NewReconciler(client ctrlruntimeclient.Client, secretReader ctrlruntimeclient.Reader, probe Prober) *Reconciler {
+	return &Reconciler{
+		client:       client,
+		secretReader: secretReader,
+		probe:        probe,
+		log:          logrus.NewEntry(logrus.StandardLogger()).WithField("controller", ControllerName),
+	}
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithField("request", request)
+
+	registration := &prowv1.ClusterRegistration{}
+	if err := r.client.Get(ctx, request.NamespacedName, registration); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("get cluster registration %s: %w", request.Name, err)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	probed := registration.DeepCopy()
+	now := metav1.Now()
+	cfg, err := kube.ClusterRegistrationConfig(ctx, r.secretReader, registration)
+	if err != nil {
+		log.WithError(err).Info("Cluster registration is invalid")
+		probed.SetReadyCondition(false, "InvalidRegistration", err.Error(), now)
+	} else if version, err := r.probe(cfg); err != nil {
+		log.WithError(err).Info("Build cluster is unreachable")
+		probed.SetReadyCondition(false, "Unreachable", err.Error(), now)
+	} else {
+		probed.Status.ServerVersion = version
+		probed.SetReadyCondition(true, "Reachable", fmt.Sprintf("Connected to Kubernetes %s", version), now)
+	}
+
+	if err := r.client.Status().Patch(ctx, probed, ctrlruntimeclient.MergeFrom(registration)); err != nil {
+		return reconcile.Result{}, fmt.Errorf("patch cluster registration status: %w", err)
+	}
+	return reconcile.Result{RequeueAfter: resyncPeriod}, nil
+}
+
+func probeServerVersion(cfg *rest.Config) (string, error) {
+	cfg = rest.CopyConfig(cfg)
+	cfg.Timeout = probeTimeout
+	client, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return "", fmt.Errorf("create discovery client: %w", err)
+	}
+	version, err := client.ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("get server version: %w", err)
+	}
+	return version.GitVersion, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterregistration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+func TestReconcile(t *testing.T) {
+	registration := &prowv1.ClusterRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: "build01", Generation: 2},
+		Spec: prowv1.ClusterRegistrationSpec{
+			Endpoint: "https://build01.example.com",
+			Auth:     prowv1.ClusterAuth{Method: prowv1.TokenClusterAuth, SecretName: "build01-token"},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: "build01-token"},
+		Data:       map[string][]byte{kube.ClusterRegistrationTokenKey: []byte("abc")},
+	}
+
+	testCases := []struct {
+		name            string
+		objects         []ctrlruntimeclient.Object
+		probe           Prober
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedVersion string
+	}{
+		{
+			name:    "reachable cluster is ready",
+			objects: []ctrlruntimeclient.Object{registration.DeepCopy(), secret.DeepCopy()},
+			probe: func(cfg *rest.Config) (string, error) {
+				if cfg.Host != "https://build01.example.com" || cfg.BearerToken != "abc" {
+					return "", errors.New("unexpected config")
+				}
+				return "v1.30.0", nil
+			},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  "Reachable",
+			expectedVersion: "v1.30.0",
+		},
+		{
+			name:    "unreachable cluster is not ready",
+			objects: []ctrlruntimeclient.Object{registration.DeepCopy(), secret.DeepCopy()},
+			probe: func(*rest.Config) (string, error) {
+				return "", errors.New("connection refused")
+			},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: "Unreachable",
+		},
+		{
+			name:    "registration without its secret is not ready",
+			objects: []ctrlruntimeclient.Object{registration.DeepCopy()},
+			probe: func(*rest.Config) (string, error) {
+				t.Error("unexpected probe of an invalid registration")
+				return "", nil
+			},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: "InvalidRegistration",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.objects...).Build()
+			r := NewReconciler(client, client, tc.probe)
+			name := types.NamespacedName{Namespace: "prow", Name: "build01"}
+			result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("reconcile failed: %v", err)
+			}
+			if result.RequeueAfter != resyncPeriod {
+				t.Errorf("expected requeue after %s, got %s", resyncPeriod, result.RequeueAfter)
+			}
+
+			actual := &prowv1.ClusterRegistration{}
+			if err := client.Get(context.Background(), name, actual); err != nil {
+				t.Fatalf("failed to get registration: %v", err)
+			}
+			if actual.Status.ServerVersion != tc.expectedVersion {
+				t.Errorf("expected server version %q, got %q", tc.expectedVersion, actual.Status.ServerVersion)
+			}
+			if actual.Status.ObservedGeneration != 2 || actual.Status.LastProbeTime == nil {
+				t.Errorf("expected the probe to be recorded for generation 2, got %+v", actual.Status)
+			}
+			expected := []metav1.Condition{{
+				Type:               prowv1.ClusterRegistrationReady,
+				Status:             tc.expectedStatus,
+				Reason:             tc.expectedReason,
+				ObservedGeneration: 2,
+			}}
+			if diff := cmp.Diff(expected, actual.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "Message", "LastTransitionTime")); diff != "" {
+				t.Errorf("unexpected conditions: %s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileDeletedRegistration(t *testing.T) {
+	client := fakectrlruntimeclient.NewClientBuilder().Build()
+	r := NewReconciler(client, client, func(*rest.Config) (string, error) {
+		t.Error("unexpected probe of a deleted registration")
+		return "", nil
+	})
+	result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "prow", Name: "gone"}})
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result != (reconcile.Result{}) {
+		t.Errorf("expected no requeue, got %+v", result)
+	}
+}
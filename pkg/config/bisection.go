@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DefaultBisectionMaxCommits is the longest range of commits a regression is
+// bisected over, unless the policy sets max_commits.
+const DefaultBisectionMaxCommits = 100
+
+// Bisection is config for the bisection controller of prow-controller-manager,
+// which bisects the commits between the last passing and the first failing
+// run of a postsubmit and reports the culprit commit.
+type Bisection struct {
+	// ResyncPeriod is how often regressions are looked for and bisections
+	// are advanced. Defaults to 5m.
+	ResyncPeriod *metav1.Duration `json:"resync_period,omitempty"`
+	// MaxConcurrency is how many bisection jobs may run at once over all
+	// bisections. Defaults to 2.
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+	// Policies is a key/value pair of an org or org/repo as the key and the
+	// bisection policy of its postsubmits as the value. Use '*' as key to set
+	// a policy globally. Postsubmits without a policy are not bisected.
+	Policies map[string]BisectionPolicy `json:"policies,omitempty"`
+}
+
+// BisectionPolicy decides which postsubmits are bisected and where the
+// culprits are reported.
+type BisectionPolicy struct {
+	// Jobs are the names of the postsubmits that are bisected. All
+	// postsubmits of the repo are bisected if it is empty.
+	Jobs []string `json:"jobs,omitempty"`
+	// MaxCommits is the longest range of commits that is bisected.
+	// Regressions over more commits are not bisected. Defaults to 100.
+	MaxCommits int `json:"max_commits,omitempty"`
+	// ReportRepo is the org/repo an issue naming the culprit is opened in.
+	// Defaults to the bisected repo.
+	ReportRepo string `json:"report_repo,omitempty"`
+	// Labels are added to the issues naming culprits.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// PolicyFor returns the bisection policy for the postsubmits of a repo, which
+// is the one of the repo, of its org or the global one, in that order. The
+// second return value is false if none applies.
+func (b *Bisection) PolicyFor(org, repo string) (BisectionPolicy, bool) {
+	for _, key := range []string{org + "/" + repo, org, "*"} {
+		if policy, ok := b.Policies[key]; ok {
+			if policy.MaxCommits == 0 {
+				policy.MaxCommits = DefaultBisectionMaxCommits
+			}
+			if policy.ReportRepo == "" {
+				policy.ReportRepo = org + "/" + repo
+			}
+			return policy, true
+		}
+	}
+	return BisectionPolicy{}, false
+}
+
+// Bisects tells whether the policy applies to a postsubmit.
+func (p *BisectionPolicy) Bisects(job string) bool {
+	if len(p.Jobs) == 0 {
+		return true
+	}
+	for _, name := range p.Jobs {
+		if name == job {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the bisection config.
+func (b *Bisection) Validate() error {
+	var errs []error
+	if b.MaxConcurrency < 0 {
+		errs = append(errs, fmt.Errorf("bisection.max_concurrency %d must not be negative", b.MaxConcurrency))
+	}
+	for key, policy := range b.Policies {
+		if policy.MaxCommits < 0 {
+			errs = append(errs, fmt.Errorf("bisection.policies[%q].max_commits %d must not be negative", key, policy.MaxCommits))
+		}
+		if policy.ReportRepo != "" {
+			if parts := strings.Split(policy.ReportRepo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				errs = append(errs, fmt.Errorf("bisection.policies[%q].report_repo %q must be of the form org/repo", key, policy.ReportRepo))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBisectionPolicyFor(t *testing.T) {
+	b := &Bisection{Policies: map[string]BisectionPolicy{
+		"*":        {Jobs: []string{"post-e2e"}},
+		"org":      {MaxCommits: 20, ReportRepo: "org/triage"},
+		"org/repo": {MaxCommits: 10, Labels: []string{"kind/regression"}},
+	}}
+	testcases := []struct {
+		org, repo string
+		expected  BisectionPolicy
+	}{
+		{org: "org", repo: "repo", expected: BisectionPolicy{MaxCommits: 10, ReportRepo: "org/repo", Labels: []string{"kind/regression"}}},
+		{org: "org", repo: "other", expected: BisectionPolicy{MaxCommits: 20, ReportRepo: "org/triage"}},
+		{org: "other", repo: "repo", expected: BisectionPolicy{Jobs: []string{"post-e2e"}, MaxCommits: DefaultBisectionMaxCommits, ReportRepo: "other/repo"}},
+	}
+	for _, tc := range testcases {
+		actual, ok := b.PolicyFor(tc.org, tc.repo)
+		if !ok {
+			t.Errorf("expected a policy for %s/%s", tc.org, tc.repo)
+		}
+		if diff := cmp.Diff(tc.expected, actual); diff != "" {
+			t.Errorf("unexpected policy for %s/%s: %s", tc.org, tc.repo, diff)
+		}
+	}
+
+	if _, ok := (&Bisection{}).PolicyFor("org", "repo"); ok {
+		t.Error("expected no policy without policies")
+	}
+}
+
+func TestBisectionPolicyBisects(t *testing.T) {
+	all := BisectionPolicy{}
+	if !all.Bisects("post-unit") {
+		t.Error("expected a policy without jobs to bisect every postsubmit")
+	}
+	some := BisectionPolicy{Jobs: []string{"post-e2e"}}
+	if !some.Bisects("post-e2e") || some.Bisects("post-unit") {
+		t.Error("expected a policy with jobs to only bisect those")
+	}
+}
+
+func TestValidateBisection(t *testing.T) {
+	testcases := []struct {
+		name        string
+		bisection   Bisection
+		expectedErr bool
+	}{
+		{
+			name: "valid",
+			bisection: Bisection{
+				MaxConcurrency: 3,
+				Policies:       map[string]BisectionPolicy{"org": {MaxCommits: 50, ReportRepo: "org/triage"}},
+			},
+		},
+		{
+			name:        "negative max concurrency",
+			bisection:   Bisection{MaxConcurrency: -1},
+			expectedErr: true,
+		},
+		{
+			name:        "negative max commits",
+			bisection:   Bisection{Policies: map[string]BisectionPolicy{"org": {MaxCommits: -1}}},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid report repo",
+			bisection:   Bisection{Policies: map[string]BisectionPolicy{"org": {ReportRepo: "triage"}}},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.bisection.Validate(); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -51,6 +51,9 @@ type Policy struct {
 	AllowForcePushes *bool `json:"allow_force_pushes,omitempty"`
 	// AllowDeletions allows deletion of the protected branch by anyone with write access to the repository.
 	AllowDeletions *bool `json:"allow_deletions,omitempty"`
+	// Rulesets manages the policy as a repository ruleset instead of classic
+	// branch protection.
+	Rulesets *RulesetPolicy `json:"rulesets,omitempty"`
 	// Exclude specifies a set of regular expressions which identify branches
 	// that should be excluded from the protection policy, mutually exclusive with Include
 	Exclude []string `json:"exclude,omitempty"`
@@ -117,6 +120,86 @@ type Restrictions struct {
 	Teams []string `json:"teams,omitempty"`
 }
 
+// RulesetPolicy configures how a policy is applied as a repository ruleset.
+// The ruleset of a branch is named after it and replaces its classic branch
+// protection, which is removed once the ruleset is in place.
+// Any nil values inherit the policy from the parent, bypass actors are
+// appended to the parent list.
+type RulesetPolicy struct {
+	// Enabled overrides whether the policy is applied as a ruleset if set.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Enforcement overrides the enforcement of the ruleset if set, either
+	// active, the default, or evaluate.
+	Enforcement *string `json:"enforcement,omitempty"`
+	// RequireSignedCommits overrides whether commits pushed to the branch
+	// must have verified signatures if set.
+	RequireSignedCommits *bool `json:"required_signatures,omitempty"`
+	// BypassActors appends actors that are allowed to bypass the ruleset.
+	BypassActors []RulesetBypassActor `json:"bypass_actors,omitempty"`
+}
+
+// RulesetBypassActor is allowed to bypass a ruleset. Exactly one of Team,
+// AppID, OrgAdmins and RepositoryRole must be set.
+type RulesetBypassActor struct {
+	// Team is the slug of a team.
+	Team string `json:"team,omitempty"`
+	// AppID is the ID of a GitHub App.
+	AppID int `json:"app_id,omitempty"`
+	// OrgAdmins allows the admins of the org to bypass the ruleset.
+	OrgAdmins bool `json:"org_admins,omitempty"`
+	// RepositoryRole is one of maintain, write or admin.
+	RepositoryRole string `json:"repository_role,omitempty"`
+	// Mode is always, the default, or pull_request to only bypass through
+	// pull requests.
+	Mode string `json:"mode,omitempty"`
+}
+
+// RulesetsEnabled returns whether the policy is applied as a ruleset.
+func (p Policy) RulesetsEnabled() bool {
+	return p.Rulesets != nil && p.Rulesets.Enabled != nil && *p.Rulesets.Enabled
+}
+
+func mergeRulesetPolicy(parent, child *RulesetPolicy) *RulesetPolicy {
+	if child == nil {
+		return parent
+	}
+	if parent == nil {
+		return child
+	}
+	actors := append([]RulesetBypassActor{}, parent.BypassActors...)
+	for _, actor := range child.BypassActors {
+		if !containsBypassActor(actors, actor) {
+			actors = append(actors, actor)
+		}
+	}
+	if len(actors) == 0 {
+		actors = nil
+	}
+	return &RulesetPolicy{
+		Enabled:              selectBool(parent.Enabled, child.Enabled),
+		Enforcement:          selectString(parent.Enforcement, child.Enforcement),
+		RequireSignedCommits: selectBool(parent.RequireSignedCommits, child.RequireSignedCommits),
+		BypassActors:         actors,
+	}
+}
+
+func containsBypassActor(actors []RulesetBypassActor, actor RulesetBypassActor) bool {
+	for _, a := range actors {
+		if a == actor {
+			return true
+		}
+	}
+	return false
+}
+
+// selectString returns the child argument if set, otherwise the parent
+func selectString(parent, child *string) *string {
+	if child != nil {
+		return child
+	}
+	return parent
+}
+
 // selectInt returns the child if set, else parent
 func selectInt(parent, child *int) *int {
 	if child != nil {
@@ -228,6 +311,7 @@ func (p Policy) Apply(child Policy) Policy {
 		RequireManuallyTriggeredJobs: selectBool(p.RequireManuallyTriggeredJobs, child.RequireManuallyTriggeredJobs),
 		Restrictions:                 mergeRestrictions(p.Restrictions, child.Restrictions),
 		RequiredPullRequestReviews:   mergeReviewPolicy(p.RequiredPullRequestReviews, child.RequiredPullRequestReviews),
+		Rulesets:                     mergeRulesetPolicy(p.Rulesets, child.Rulesets),
 		Exclude:                      unionStrings(p.Exclude, child.Exclude),
 		Include:                      unionStrings(p.Include, child.Include),
 	}
@@ -505,9 +589,51 @@ func (c *Config) GetPolicy(org, repo, branch string, b Branch, presubmits []Pres
 	if !policy.defined() {
 		return nil, nil
 	}
+	if err := validateRulesets(policy); err != nil {
+		return nil, fmt.Errorf("%s/%s=%s: %w", org, repo, branch, err)
+	}
 	return &policy, nil
 }
 
+// validateRulesets ensures that a policy applied as a ruleset only uses
+// settings that rulesets support.
+func validateRulesets(policy Policy) error {
+	if !policy.RulesetsEnabled() {
+		return nil
+	}
+	if policy.Restrictions != nil {
+		return errors.New("restrictions are not supported by rulesets, use bypass_actors instead")
+	}
+	if r := policy.RequiredPullRequestReviews; r != nil && (r.DismissalRestrictions != nil || r.BypassRestrictions != nil) {
+		return errors.New("dismissal_restrictions and bypass_pull_request_allowances are not supported by rulesets, use bypass_actors instead")
+	}
+	if e := policy.Rulesets.Enforcement; e != nil && *e != "active" && *e != "evaluate" {
+		return fmt.Errorf("invalid ruleset enforcement %q, must be active or evaluate", *e)
+	}
+	for _, actor := range policy.Rulesets.BypassActors {
+		kinds := 0
+		for _, set := range []bool{actor.Team != "", actor.AppID != 0, actor.OrgAdmins, actor.RepositoryRole != ""} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return fmt.Errorf("ruleset bypass actor %+v must set exactly one of team, app_id, org_admins and repository_role", actor)
+		}
+		switch actor.RepositoryRole {
+		case "", "maintain", "write", "admin":
+		default:
+			return fmt.Errorf("invalid ruleset bypass repository_role %q, must be one of maintain, write or admin", actor.RepositoryRole)
+		}
+		switch actor.Mode {
+		case "", "always", "pull_request":
+		default:
+			return fmt.Errorf("invalid ruleset bypass mode %q, must be always or pull_request", actor.Mode)
+		}
+	}
+	return nil
+}
+
 func (c *Config) shouldManageRequiredStatusCheck(requiredContexts, requiredIfPresentContexts, optionalContexts []string) bool {
 	if len(requiredContexts) > 0 {
 		return true
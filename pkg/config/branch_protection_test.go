@@ -302,6 +302,28 @@ func TestApply(test *testing.T) {
 				Include: []string{"bar*", "foo*"},
 			},
 		},
+		{
+			name: "merge rulesets",
+			parent: Policy{
+				Rulesets: &RulesetPolicy{
+					Enabled:      &t,
+					BypassActors: []RulesetBypassActor{{Team: "admins"}},
+				},
+			},
+			child: Policy{
+				Rulesets: &RulesetPolicy{
+					RequireSignedCommits: &t,
+					BypassActors:         []RulesetBypassActor{{Team: "admins"}, {AppID: 1}},
+				},
+			},
+			expected: Policy{
+				Rulesets: &RulesetPolicy{
+					Enabled:              &t,
+					RequireSignedCommits: &t,
+					BypassActors:         []RulesetBypassActor{{Team: "admins"}, {AppID: 1}},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -965,6 +987,76 @@ func TestConfig_GetBranchProtection(t *testing.T) {
 	}
 }
 
+func TestValidateRulesets(t *testing.T) {
+	enforcement := "disabled"
+	cases := []struct {
+		name   string
+		policy Policy
+		err    bool
+	}{
+		{
+			name:   "rulesets disabled",
+			policy: Policy{Restrictions: &Restrictions{Teams: []string{"team"}}},
+		},
+		{
+			name: "valid",
+			policy: Policy{Rulesets: &RulesetPolicy{
+				Enabled:      yes,
+				BypassActors: []RulesetBypassActor{{Team: "team", Mode: "pull_request"}, {RepositoryRole: "write"}},
+			}},
+		},
+		{
+			name: "restrictions",
+			policy: Policy{
+				Restrictions: &Restrictions{Teams: []string{"team"}},
+				Rulesets:     &RulesetPolicy{Enabled: yes},
+			},
+			err: true,
+		},
+		{
+			name: "dismissal restrictions",
+			policy: Policy{
+				RequiredPullRequestReviews: &ReviewPolicy{DismissalRestrictions: &DismissalRestrictions{Teams: []string{"team"}}},
+				Rulesets:                   &RulesetPolicy{Enabled: yes},
+			},
+			err: true,
+		},
+		{
+			name:   "invalid enforcement",
+			policy: Policy{Rulesets: &RulesetPolicy{Enabled: yes, Enforcement: &enforcement}},
+			err:    true,
+		},
+		{
+			name:   "actor without kind",
+			policy: Policy{Rulesets: &RulesetPolicy{Enabled: yes, BypassActors: []RulesetBypassActor{{Mode: "always"}}}},
+			err:    true,
+		},
+		{
+			name:   "actor with several kinds",
+			policy: Policy{Rulesets: &RulesetPolicy{Enabled: yes, BypassActors: []RulesetBypassActor{{Team: "team", AppID: 1}}}},
+			err:    true,
+		},
+		{
+			name:   "invalid repository role",
+			policy: Policy{Rulesets: &RulesetPolicy{Enabled: yes, BypassActors: []RulesetBypassActor{{RepositoryRole: "triage"}}}},
+			err:    true,
+		},
+		{
+			name:   "invalid mode",
+			policy: Policy{Rulesets: &RulesetPolicy{Enabled: yes, BypassActors: []RulesetBypassActor{{AppID: 1, Mode: "never"}}}},
+			err:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateRulesets(tc.policy); (err != nil) != tc.err {
+				t.Errorf("expected error %t, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
 func TestReposWithDisabledPolicy(t *testing.T) {
 	testCases := []struct {
 		name              string
@@ -148,6 +148,8 @@ type InRepoConfigCache struct {
 	*cache.LRUCache
 	configAgent prowConfigAgentClient
 	gitClient   git.ClientFactory
+	// fileGetter reads the InRepoConfig of repos that fetch it from Gerrit.
+	fileGetter ProwYAMLFileGetter
 }
 
 // NewInRepoConfigCache creates a new LRU cache for ProwYAML values, where the keys
@@ -236,11 +238,19 @@ func NewInRepoConfigCache(
 		// Make the cache be able to handle cache misses (by calling out to Git
 		// to construct the ProwYAML value).
 		gitClientFactory,
+		nil,
 	}
 
 	return cache, nil
 }
 
+// SetFileGetter makes the cache read the InRepoConfig of repos that fetch it
+// from Gerrit with the file getter, rather than from a clone of the repo. It
+// must be called before the cache is used.
+func (cache *InRepoConfigCache) SetFileGetter(getter ProwYAMLFileGetter) {
+	cache.fileGetter = getter
+}
+
 // CacheKey acts as a key to the InRepoConfigCache. We construct it by marshaling
 // CacheKeyParts into a JSON string.
 type CacheKey string
@@ -357,7 +367,13 @@ func (cache *InRepoConfigCache) GetProwYAMLWithoutDefaults(identifier, baseBranc
 
 	c := cache.configAgent.Config()
 
-	prowYAML, err := cache.getProwYAML(c.getProwYAML, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+	getProwYAML := c.getProwYAML
+	if cache.fileGetter != nil {
+		getProwYAML = func(gc git.ClientFactory, identifier, baseBranch string, baseSHAGetter RefGetter, headSHAGetters ...RefGetter) (*ProwYAML, error) {
+			return c.getProwYAMLFromFile(cache.fileGetter, gc, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+		}
+	}
+	prowYAML, err := cache.getProwYAML(getProwYAML, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
 	if err != nil {
 		return nil, err
 	}
@@ -30,6 +30,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -57,8 +58,10 @@ import (
 	"sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/pod-utils/decorate"
 	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
+	"sigs.k8s.io/prow/pkg/version"
 )
 
 const (
@@ -166,6 +169,17 @@ type ProwConfig struct {
 	// It has to be explicitly enabled.
 	Scheduler Scheduler `json:"scheduler,omitempty"`
 
+	// StatusReconciler contains configuration for the status-reconciler.
+	StatusReconciler StatusReconciler `json:"status_reconciler,omitempty"`
+
+	// Flakiness contains configuration for the flakiness controller of
+	// prow-controller-manager and the policies it applies to flaky jobs.
+	Flakiness Flakiness `json:"flakiness,omitempty"`
+
+	// Bisection contains configuration for the bisection controller of
+	// prow-controller-manager, which bisects postsubmit regressions.
+	Bisection Bisection `json:"bisection,omitempty"`
+
 	// TODO: Move this out of the main config.
 	JenkinsOperators []JenkinsOperator `json:"jenkins_operators,omitempty"`
 
@@ -190,6 +204,14 @@ type ProwConfig struct {
 	// Defaults to "info".
 	LogLevel string `json:"log_level,omitempty"`
 
+	// LogLevels overrides the log level for a component, e.g. "tide", or for
+	// a subsystem of a component, e.g. "tide/sync" for the sync controller
+	// of Tide or "hook/trigger" for the trigger plugin of Hook. Subsystems are
+	// named by the controller or plugin field of the log lines they write.
+	// The levels can also be changed at runtime on the /debug/loglevel
+	// endpoint of the pprof port, if the component has a --log-level-token-path.
+	LogLevels map[string]string `json:"log_levels,omitempty"`
+
 	// PushGateway is a prometheus push gateway.
 	PushGateway PushGateway `json:"push_gateway,omitempty"`
 
@@ -241,6 +263,12 @@ type InRepoConfig struct {
 	// a given repo. All clusters that are allowed for the specific repo, its org or
 	// globally can be used.
 	AllowedClusters map[string][]string `json:"allowed_clusters,omitempty"`
+	// FetchFromGerrit describes whether the .prow.yaml of a Gerrit repository
+	// is read with the REST API of Gerrit at the revision of a change, instead
+	// of from a clone of the repository with the change merged into its branch.
+	// Repositories without a .prow.yaml file, e.g. with a .prow directory, are
+	// still cloned. This can be set like Enabled.
+	FetchFromGerrit map[string]*bool `json:"fetch_from_gerrit,omitempty"`
 }
 
 func SplitRepoName(fullRepoName string) (string, string, error) {
@@ -267,6 +295,20 @@ func (c *Config) InRepoConfigEnabled(identifier string) bool {
 	return false
 }
 
+// InRepoConfigFetchFromGerrit returns whether the InRepoConfig of a given
+// Gerrit repository is read with the REST API of Gerrit.
+func (c *Config) InRepoConfigFetchFromGerrit(identifier string) bool {
+	if !gerritsource.IsGerritOrg(identifier) {
+		return false
+	}
+	for _, key := range keysForIdentifier(identifier) {
+		if c.InRepoConfig.FetchFromGerrit[key] != nil {
+			return *c.InRepoConfig.FetchFromGerrit[key]
+		}
+	}
+	return false
+}
+
 // InRepoConfigAllowsCluster determines if a given cluster may be used for a given repository
 // Assumes that config will not include http:// or https://
 func (c *Config) InRepoConfigAllowsCluster(clusterName, identifier string) bool {
@@ -708,6 +750,13 @@ type Plank struct {
 	// limit. An example use case would be easier scheduling of jobs using boskos resources.
 	// This mechanism is separate from ProwJob's MaxConcurrency setting.
 	JobQueueCapacities map[string]int `json:"job_queue_capacities,omitempty"`
+
+	// ResourceUsageSamplingInterval is how often the controller samples the CPU and
+	// memory usage of running job pods from the metrics API of the build clusters to
+	// record the resource usage of the jobs in their status. Sampling requires the
+	// metrics-server and permission to get pods.metrics.k8s.io in the build clusters.
+	// Unset or zero disables sampling.
+	ResourceUsageSamplingInterval *metav1.Duration `json:"resource_usage_sampling_interval,omitempty"`
 }
 
 type ProwJobDefaultEntry struct {
@@ -849,6 +898,29 @@ func (p *Plank) GuessDefaultDecorationConfigWithJobDC(repo, cluster string, jobD
 	return p.mergeDefaultDecorationConfig(repo, cluster, jobDC)
 }
 
+// DefaultProwJobDecorationConfig merges the matching DefaultDecorationConfigEntries
+// into the decoration config of a ProwJob, like they are merged into the ones of
+// jobs when the job config is loaded. This applies the defaults to ProwJobs that
+// were not created from the job config. ProwJobs are decorated if they run on
+// the kubernetes agent and have a decoration config, even an empty one.
+func (p *Plank) DefaultProwJobDecorationConfig(spec *prowapi.ProwJobSpec) {
+	if spec.Agent != prowapi.KubernetesAgent || spec.DecorationConfig == nil {
+		return
+	}
+	var repo string
+	switch {
+	case spec.Type != prowapi.PeriodicJob && spec.Refs != nil:
+		repo = spec.Refs.OrgRepoString()
+	case spec.Type == prowapi.PeriodicJob && len(spec.ExtraRefs) > 0:
+		repo = spec.ExtraRefs[0].OrgRepoString()
+	}
+	cluster := spec.Cluster
+	if cluster == "" {
+		cluster = kube.DefaultClusterAlias
+	}
+	spec.DecorationConfig = p.mergeDefaultDecorationConfig(repo, cluster, spec.DecorationConfig)
+}
+
 // defaultDecorationMapToSlice converts the old format
 // (map[string]*prowapi.DecorationConfig) to the new format
 // ([]*DefaultDecorationConfigEntry).
@@ -971,6 +1043,14 @@ func (g *Gerrit) DefaultAndValidate() error {
 		return fmt.Errorf("failed to compile regex for allowed presubmit triggers: %s", err.Error())
 	}
 	g.AllowedPresubmitTriggerRe = &CopyableRegexp{re}
+
+	if g.OrgReposConfig != nil {
+		for _, orgConfig := range *g.OrgReposConfig {
+			if _, err := template.New("Report").Parse(orgConfig.ReportTemplate); err != nil {
+				return fmt.Errorf("failed to parse report_template for %s: %w", orgConfig.Org, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -993,6 +1073,23 @@ type GerritOrgRepoConfig struct {
 	// the help message with comments like `/test ?`, `/retest ?`, `/test
 	// job-not-exist`, `/test job-only-available-from-another-prow`.
 	OptOutHelp bool `json:"opt_out_help,omitempty"`
+	// TrustedGroups are the Gerrit groups whose members are trusted to run
+	// presubmits, like the trusted orgs of the trigger plugin on GitHub. Jobs
+	// for changes of other owners only run automatically once a trusted member
+	// adds the `ok-to-test` hashtag, and only trusted members can trigger jobs
+	// with comment commands. If empty, everyone is trusted.
+	TrustedGroups []string `json:"trusted_groups,omitempty"`
+	// ReportTemplate is a Go template for text that Crier adds to the end of
+	// its result comments on changes of the repos, e.g. links to the docs of
+	// the jobs or to the flake policy. It is executed with the report, which
+	// has the Jobs with their Name, State and URL, and the Success and Total
+	// counts.
+	ReportTemplate string `json:"report_template,omitempty"`
+	// TopicTesting tests the changes of the repos together with the other
+	// open changes of their topic: the presubmits of a change check out the
+	// current revisions of the changes of the topic in other repos as extra
+	// refs, and Tide only submits a change once the whole topic verified.
+	TopicTesting bool `json:"topic_testing,omitempty"`
 	// Filters are used for limiting the scope of querying the Gerrit server.
 	// Currently supports branches and excluded branches.
 	Filters *GerritQueryFilter `json:"filters,omitempty"`
@@ -1036,6 +1133,50 @@ func (goc *GerritOrgRepoConfigs) OptOutHelpRepos() map[string]sets.Set[string] {
 	return res
 }
 
+// TrustedGroups returns the Gerrit groups trusted to run presubmits for the
+// repo, or nil if everyone is trusted.
+func (goc *GerritOrgRepoConfigs) TrustedGroups(org, repo string) []string {
+	if goc == nil {
+		return nil
+	}
+	var res []string
+	for _, orgConfig := range *goc {
+		if orgConfig.Org != org || !slices.Contains(orgConfig.Repos, repo) {
+			continue
+		}
+		res = append(res, orgConfig.TrustedGroups...)
+	}
+	return res
+}
+
+// ReportTemplate returns the template for text added to the result comments
+// on changes of the repo, or an empty string if there is none.
+func (goc *GerritOrgRepoConfigs) ReportTemplate(org, repo string) string {
+	if goc == nil {
+		return ""
+	}
+	for _, orgConfig := range *goc {
+		if orgConfig.Org == org && slices.Contains(orgConfig.Repos, repo) && orgConfig.ReportTemplate != "" {
+			return orgConfig.ReportTemplate
+		}
+	}
+	return ""
+}
+
+// TopicTesting returns whether the changes of the repo are tested together
+// with the other changes of their topic.
+func (goc *GerritOrgRepoConfigs) TopicTesting(org, repo string) bool {
+	if goc == nil {
+		return false
+	}
+	for _, orgConfig := range *goc {
+		if orgConfig.Org == org && slices.Contains(orgConfig.Repos, repo) && orgConfig.TopicTesting {
+			return true
+		}
+	}
+	return false
+}
+
 // Horologium is config for the Horologium.
 type Horologium struct {
 	// TickInterval is the interval in which we check if new jobs need to be
@@ -1043,6 +1184,31 @@ type Horologium struct {
 	TickInterval *metav1.Duration `json:"tick_interval,omitempty"`
 }
 
+// StatusReconciler is config for the status-reconciler.
+type StatusReconciler struct {
+	// ContextMigrations maps old status contexts to the new ones that replace
+	// them. When a presubmit reporting to an old context is replaced by one
+	// reporting to the new context, status-reconciler copies the state of the
+	// old context to the new one and retires the old context on open pull
+	// requests, instead of triggering the new presubmit.
+	ContextMigrations map[string]string `json:"context_migrations,omitempty"`
+}
+
+// Validate validates the status-reconciler config.
+func (s StatusReconciler) Validate() error {
+	var errs []error
+	for from, to := range s.ContextMigrations {
+		if from == "" || to == "" {
+			errs = append(errs, fmt.Errorf("status_reconciler.context_migrations: %q -> %q must not have an empty context", from, to))
+		} else if from == to {
+			errs = append(errs, fmt.Errorf("status_reconciler.context_migrations: context %q must not be migrated to itself", from))
+		} else if _, chained := s.ContextMigrations[to]; chained {
+			errs = append(errs, fmt.Errorf("status_reconciler.context_migrations: context %q is migrated to %q, which is migrated itself", from, to))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 // JenkinsOperator is config for the jenkins-operator controller.
 type JenkinsOperator struct {
 	Controller `json:",inline"`
@@ -1550,6 +1716,8 @@ type GitHubOptions struct {
 // ManagedWebhookInfo contains metadata about the repo/org which is onboarded.
 type ManagedWebhookInfo struct {
 	TokenCreatedAfter time.Time `json:"token_created_after"`
+	// RotationInterval overrides ManagedWebhooks.RotationInterval for this repo/org.
+	RotationInterval *metav1.Duration `json:"rotation_interval,omitempty"`
 }
 
 // ManagedWebhooks contains information about all the repos/orgs which are onboarded with auto-generated tokens.
@@ -1561,6 +1729,35 @@ type ManagedWebhooks struct {
 	// will be left pending.
 	AutoAcceptInvitation bool                          `json:"auto_accept_invitation"`
 	OrgRepoConfig        map[string]ManagedWebhookInfo `json:"org_repo_config,omitempty"`
+	// RotationInterval is the maximum age of the newest token of a repo/org
+	// before the hmac tool rotates it. If unset, tokens are only rotated when
+	// token_created_after is moved past them.
+	RotationInterval *metav1.Duration `json:"rotation_interval,omitempty"`
+	// RotationGracePeriod is how long superseded tokens are kept after a
+	// rotation so that hook keeps accepting events signed with them until
+	// all webhooks use the new token. Superseded tokens are pruned right
+	// after the webhooks are updated if unset.
+	RotationGracePeriod *metav1.Duration `json:"rotation_grace_period,omitempty"`
+}
+
+// RotationIntervalFor returns the rotation interval of the repo/org, or zero
+// if tokens for it are not rotated on a schedule.
+func (m *ManagedWebhooks) RotationIntervalFor(orgRepo string) time.Duration {
+	if info, ok := m.OrgRepoConfig[orgRepo]; ok && info.RotationInterval != nil {
+		return info.RotationInterval.Duration
+	}
+	if m.RotationInterval != nil {
+		return m.RotationInterval.Duration
+	}
+	return 0
+}
+
+// GracePeriod returns how long superseded tokens are kept after a rotation.
+func (m *ManagedWebhooks) GracePeriod() time.Duration {
+	if m.RotationGracePeriod != nil {
+		return m.RotationGracePeriod.Duration
+	}
+	return 0
 }
 
 // SlackReporter represents the config for the Slack reporter. The channel can be overridden
@@ -2178,10 +2375,19 @@ func (c *Config) validateComponentConfig() error {
 			if repoValue.TokenCreatedAfter.After(time.Now()) {
 				validationErrs = append(validationErrs, fmt.Errorf("token_created_after %s can be no later than current time for repo/org %s", repoValue.TokenCreatedAfter, repoName))
 			}
+			if repoValue.RotationInterval != nil && repoValue.RotationInterval.Duration <= 0 {
+				validationErrs = append(validationErrs, fmt.Errorf("rotation_interval must be positive for repo/org %s", repoName))
+			}
 		}
-		if len(validationErrs) > 0 {
-			return utilerrors.NewAggregate(validationErrs)
-		}
+	}
+	if c.ManagedWebhooks.RotationInterval != nil && c.ManagedWebhooks.RotationInterval.Duration <= 0 {
+		validationErrs = append(validationErrs, errors.New("managed_webhooks.rotation_interval must be positive"))
+	}
+	if c.ManagedWebhooks.RotationGracePeriod != nil && c.ManagedWebhooks.RotationGracePeriod.Duration < 0 {
+		validationErrs = append(validationErrs, errors.New("managed_webhooks.rotation_grace_period must not be negative"))
+	}
+	if len(validationErrs) > 0 {
+		return utilerrors.NewAggregate(validationErrs)
 	}
 
 	if c.SlackReporterConfigs != nil {
@@ -2209,6 +2415,22 @@ func (c *Config) validateComponentConfig() error {
 		return err
 	}
 
+	if err := c.StatusReconciler.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Tide.validateMergeLatencySLOs(); err != nil {
+		return err
+	}
+
+	if err := c.Flakiness.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Bisection.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -2217,6 +2439,50 @@ var (
 	jobNameRegexJenkins = regexp.MustCompile(`^[A-Za-z0-9-._]([A-Za-z0-9-._/]*[A-Za-z0-9-_])?$`)
 )
 
+// ValidateProwJobSpec applies the checks that jobs get when the job config is
+// loaded to the spec of a ProwJob, e.g. of one created by an external system
+// through the API rather than from the job config.
+func (c *Config) ValidateProwJobSpec(spec prowapi.ProwJobSpec) error {
+	namespace := spec.Namespace
+	if namespace == "" {
+		namespace = c.PodNamespace
+	}
+	// The decoration config is left out, as ProwJobs of any agent may get a
+	// default one.
+	v := JobBase{
+		Name:                  spec.Job,
+		Agent:                 string(spec.Agent),
+		Namespace:             &namespace,
+		ErrorOnEviction:       spec.ErrorOnEviction,
+		RetryPolicy:           spec.RetryPolicy,
+		Spec:                  spec.PodSpec,
+		PipelineRunSpec:       spec.PipelineRunSpec,
+		TektonPipelineRunSpec: spec.TektonPipelineRunSpec,
+	}
+	if err := validateJobName(v); err != nil {
+		return err
+	}
+	if spec.MaxConcurrency < 0 {
+		return fmt.Errorf("max_concurrency: %d must be a non-negative number", spec.MaxConcurrency)
+	}
+	if err := validateAgent(v, c.PodNamespace); err != nil {
+		return err
+	}
+	if err := validateRetryPolicy(spec.RetryPolicy); err != nil {
+		return err
+	}
+	if err := validatePodSpec(spec.Type, spec.PodSpec, spec.DecorationConfig); err != nil {
+		return err
+	}
+	if err := validateJobQueueName(spec.JobQueueName, sets.KeySet[string](c.Plank.JobQueueCapacities)); err != nil {
+		return err
+	}
+	if err := spec.RerunAuthConfig.Validate(); err != nil {
+		return fmt.Errorf("rerun_auth_config: %w", err)
+	}
+	return nil
+}
+
 func validateJobName(v JobBase) error {
 	nameRegex := jobNameRegex
 	if v.Agent == string(prowapi.JenkinsAgent) {
@@ -2235,6 +2501,10 @@ func (c Config) validateJobBase(v JobBase, jobType prowapi.ProwJobType) error {
 		return err
 	}
 
+	if err := validateSLOAnnotations(v.Annotations); err != nil {
+		return err
+	}
+
 	// Ensure max_concurrency is non-negative.
 	if v.MaxConcurrency < 0 {
 		return fmt.Errorf("max_concurrency: %d must be a non-negative number", v.MaxConcurrency)
@@ -2242,6 +2512,9 @@ func (c Config) validateJobBase(v JobBase, jobType prowapi.ProwJobType) error {
 	if err := validateAgent(v, c.PodNamespace); err != nil {
 		return err
 	}
+	if err := validateRetryPolicy(v.RetryPolicy); err != nil {
+		return err
+	}
 	if err := validatePodSpec(jobType, v.Spec, v.DecorationConfig); err != nil {
 		return err
 	}
@@ -2657,6 +2930,22 @@ func parseProwConfig(c *Config) error {
 		c.Sinker.ResyncPeriod = &metav1.Duration{Duration: time.Hour}
 	}
 
+	if c.Flakiness.Window == nil {
+		c.Flakiness.Window = &metav1.Duration{Duration: 7 * 24 * time.Hour}
+	}
+
+	if c.Flakiness.ResyncPeriod == nil {
+		c.Flakiness.ResyncPeriod = &metav1.Duration{Duration: time.Hour}
+	}
+
+	if c.Bisection.ResyncPeriod == nil {
+		c.Bisection.ResyncPeriod = &metav1.Duration{Duration: 5 * time.Minute}
+	}
+
+	if c.Bisection.MaxConcurrency == 0 {
+		c.Bisection.MaxConcurrency = 2
+	}
+
 	if c.Sinker.MaxProwJobAge == nil {
 		c.Sinker.MaxProwJobAge = &metav1.Duration{Duration: 7 * 24 * time.Hour}
 	}
@@ -2779,7 +3068,23 @@ func parseProwConfig(c *Config) error {
 	if err != nil {
 		return err
 	}
-	logrus.SetLevel(lvl)
+	subsystemLevels := map[string]logrus.Level{}
+	for name, level := range c.LogLevels {
+		parsed, err := logrus.ParseLevel(level)
+		if err != nil {
+			return fmt.Errorf("log_levels of %s: %w", name, err)
+		}
+		component, subsystem, isSubsystem := strings.Cut(name, "/")
+		if component != version.Name {
+			continue
+		}
+		if isSubsystem {
+			subsystemLevels[subsystem] = parsed
+		} else {
+			lvl = parsed
+		}
+	}
+	logrusutil.SetConfiguredLevels(lvl, subsystemLevels)
 
 	// Avoid using a job timeout of infinity by setting the default value to 24 hours.
 	if c.DefaultJobTimeout == nil {
@@ -2894,6 +3199,8 @@ func validateAgent(v JobBase, podNamespace string) error {
 		return fmt.Errorf("decoration requires agent: %s (found %q)", k, agent)
 	case v.ErrorOnEviction && agent != k:
 		return fmt.Errorf("error_on_eviction only applies to agent: %s (found %q)", k, agent)
+	case v.RetryPolicy != nil && agent != k:
+		return fmt.Errorf("retry_policy only applies to agent: %s (found %q)", k, agent)
 	case v.Namespace == nil || *v.Namespace == "":
 		return fmt.Errorf("failed to default namespace")
 	case *v.Namespace != podNamespace && agent != p:
@@ -2903,6 +3210,25 @@ func validateAgent(v JobBase, podNamespace string) error {
 	return nil
 }
 
+func validateRetryPolicy(policy *prowapi.RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.MaxAttempts < 1 {
+		return fmt.Errorf("retry_policy: max_attempts: %d must be at least 1", policy.MaxAttempts)
+	}
+	if policy.GetBackoff() < 0 {
+		return fmt.Errorf("retry_policy: backoff: %s must be a non-negative duration", policy.GetBackoff())
+	}
+	reasons := sets.New[prowapi.RetryReason](prowapi.GetAllRetryReasons()...)
+	for _, reason := range policy.RetryableReasons {
+		if !reasons.Has(reason) {
+			return fmt.Errorf("retry_policy: retryable_reasons: invalid reason %q, must be one of %v", reason, prowapi.GetAllRetryReasons())
+		}
+	}
+	return nil
+}
+
 func validateDecoration(container v1.Container, config *prowapi.DecorationConfig) error {
 	if config == nil {
 		return nil
@@ -33,6 +33,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	fuzz "github.com/google/gofuzz"
+	"github.com/sirupsen/logrus"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -50,8 +51,10 @@ import (
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/github/fakegithub"
 	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/pod-utils/decorate"
 	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
+	"sigs.k8s.io/prow/pkg/version"
 )
 
 func pStr(str string) *string {
@@ -1428,6 +1431,22 @@ func TestValidateAgent(t *testing.T) {
 			},
 			pass: true,
 		},
+		{
+			name: "retry_policy allowed for kubernetes agent",
+			base: func(j *JobBase) {
+				j.RetryPolicy = &prowapi.RetryPolicy{MaxAttempts: 2}
+			},
+			pass: true,
+		},
+		{
+			name: "retry_policy requires kubernetes agent",
+			base: func(j *JobBase) {
+				j.Agent = jenk
+				j.Spec = nil
+				j.DecorationConfig = nil
+				j.RetryPolicy = &prowapi.RetryPolicy{MaxAttempts: 2}
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -1446,6 +1465,51 @@ func TestValidateAgent(t *testing.T) {
 	}
 }
 
+func TestValidateRetryPolicy(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy *prowapi.RetryPolicy
+		pass   bool
+	}{
+		{
+			name: "accept no retry policy",
+			pass: true,
+		},
+		{
+			name: "accept retry policy",
+			policy: &prowapi.RetryPolicy{
+				MaxAttempts:      3,
+				RetryableReasons: []prowapi.RetryReason{prowapi.PodPendingTimeoutRetryReason, prowapi.EvictionRetryReason},
+				Backoff:          &metav1.Duration{Duration: time.Minute},
+			},
+			pass: true,
+		},
+		{
+			name:   "reject max_attempts below one",
+			policy: &prowapi.RetryPolicy{},
+		},
+		{
+			name:   "reject negative backoff",
+			policy: &prowapi.RetryPolicy{MaxAttempts: 2, Backoff: &metav1.Duration{Duration: -time.Minute}},
+		},
+		{
+			name:   "reject unknown reason",
+			policy: &prowapi.RetryPolicy{MaxAttempts: 2, RetryableReasons: []prowapi.RetryReason{"Flaky"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			switch err := validateRetryPolicy(tc.policy); {
+			case err == nil && !tc.pass:
+				t.Error("validation failed to raise an error")
+			case err != nil && tc.pass:
+				t.Errorf("validation should have passed, got: %v", err)
+			}
+		})
+	}
+}
+
 func TestValidatePodSpec(t *testing.T) {
 	periodEnv := sets.New[string](downwardapi.EnvForType(prowapi.PeriodicJob)...)
 	postEnv := sets.New[string](downwardapi.EnvForType(prowapi.PostsubmitJob)...)
@@ -2510,6 +2574,168 @@ func TestGerritOptOutHelpRepos(t *testing.T) {
 	}
 }
 
+func TestGerritTrustedGroups(t *testing.T) {
+	in := &GerritOrgRepoConfigs{
+		{
+			Org:           "org-1",
+			Repos:         []string{"repo-1", "repo-2"},
+			TrustedGroups: []string{"group-1"},
+		},
+		{
+			Org:           "org-1",
+			Repos:         []string{"repo-1"},
+			TrustedGroups: []string{"group-2"},
+		},
+		{
+			Org:   "org-2",
+			Repos: []string{"repo-1"},
+		},
+	}
+	tests := []struct {
+		name string
+		in   *GerritOrgRepoConfigs
+		org  string
+		repo string
+		want []string
+	}{
+		{
+			name: "union",
+			in:   in,
+			org:  "org-1",
+			repo: "repo-1",
+			want: []string{"group-1", "group-2"},
+		},
+		{
+			name: "single",
+			in:   in,
+			org:  "org-1",
+			repo: "repo-2",
+			want: []string{"group-1"},
+		},
+		{
+			name: "no-trusted-groups",
+			in:   in,
+			org:  "org-2",
+			repo: "repo-1",
+		},
+		{
+			name: "nil",
+			org:  "org-1",
+			repo: "repo-1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.in.TrustedGroups(tc.org, tc.repo)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("output mismatch. got(+), want(-):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGerritReportTemplate(t *testing.T) {
+	in := &GerritOrgRepoConfigs{
+		{
+			Org:   "org-1",
+			Repos: []string{"repo-1"},
+		},
+		{
+			Org:            "org-1",
+			Repos:          []string{"repo-1", "repo-2"},
+			ReportTemplate: "See the flake policy.",
+		},
+	}
+	tests := []struct {
+		name string
+		in   *GerritOrgRepoConfigs
+		org  string
+		repo string
+		want string
+	}{
+		{
+			name: "first non-empty template",
+			in:   in,
+			org:  "org-1",
+			repo: "repo-1",
+			want: "See the flake policy.",
+		},
+		{
+			name: "no template",
+			in:   in,
+			org:  "org-2",
+			repo: "repo-1",
+		},
+		{
+			name: "nil",
+			org:  "org-1",
+			repo: "repo-1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.in.ReportTemplate(tc.org, tc.repo); got != tc.want {
+				t.Errorf("expected template %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	invalid := Gerrit{OrgReposConfig: &GerritOrgRepoConfigs{{Org: "org-1", ReportTemplate: "{{ if .Jobs }}"}}}
+	if err := invalid.DefaultAndValidate(); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
+
+func TestGerritTopicTesting(t *testing.T) {
+	in := &GerritOrgRepoConfigs{
+		{
+			Org:   "org-1",
+			Repos: []string{"repo-1", "repo-2"},
+		},
+		{
+			Org:          "org-1",
+			Repos:        []string{"repo-2"},
+			TopicTesting: true,
+		},
+	}
+	tests := []struct {
+		name string
+		in   *GerritOrgRepoConfigs
+		org  string
+		repo string
+		want bool
+	}{
+		{
+			name: "enabled by any config of the repo",
+			in:   in,
+			org:  "org-1",
+			repo: "repo-2",
+			want: true,
+		},
+		{
+			name: "not enabled",
+			in:   in,
+			org:  "org-1",
+			repo: "repo-1",
+		},
+		{
+			name: "nil",
+			org:  "org-1",
+			repo: "repo-2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.in.TopicTesting(tc.org, tc.repo); got != tc.want {
+				t.Errorf("expected topic testing %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
 // integration test for fake config loading
 func TestValidConfigLoading(t *testing.T) {
 	ptrOrBool := func(p *bool) string {
@@ -4722,6 +4948,27 @@ func TestValidateComponentConfig(t *testing.T) {
 			}}},
 			errExpected: false,
 		},
+		{
+			name: "Valid context migrations, no err",
+			config: &Config{ProwConfig: ProwConfig{StatusReconciler: StatusReconciler{
+				ContextMigrations: map[string]string{"old-context": "new-context"},
+			}}},
+			errExpected: false,
+		},
+		{
+			name: "Context migrated to itself, err",
+			config: &Config{ProwConfig: ProwConfig{StatusReconciler: StatusReconciler{
+				ContextMigrations: map[string]string{"context": "context"},
+			}}},
+			errExpected: true,
+		},
+		{
+			name: "Chained context migrations, err",
+			config: &Config{ProwConfig: ProwConfig{StatusReconciler: StatusReconciler{
+				ContextMigrations: map[string]string{"a": "b", "b": "c"},
+			}}},
+			errExpected: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -4915,6 +5162,39 @@ func TestManagedHmacEntityValidation(t *testing.T) {
 			}},
 			shouldFail: true,
 		},
+		{
+			name: "Config with valid rotation settings",
+			prowConfig: Config{ProwConfig: ProwConfig{
+				ManagedWebhooks: ManagedWebhooks{
+					OrgRepoConfig: map[string]ManagedWebhookInfo{
+						"foo/bar": {TokenCreatedAfter: time.Now(), RotationInterval: &metav1.Duration{Duration: time.Hour}},
+					},
+					RotationInterval:    &metav1.Duration{Duration: 24 * time.Hour},
+					RotationGracePeriod: &metav1.Duration{Duration: time.Hour},
+				},
+			}},
+			shouldFail: false,
+		},
+		{
+			name: "Config with invalid repo rotation interval",
+			prowConfig: Config{ProwConfig: ProwConfig{
+				ManagedWebhooks: ManagedWebhooks{
+					OrgRepoConfig: map[string]ManagedWebhookInfo{
+						"foo/bar": {TokenCreatedAfter: time.Now(), RotationInterval: &metav1.Duration{}},
+					},
+				},
+			}},
+			shouldFail: true,
+		},
+		{
+			name: "Config with negative grace period",
+			prowConfig: Config{ProwConfig: ProwConfig{
+				ManagedWebhooks: ManagedWebhooks{
+					RotationGracePeriod: &metav1.Duration{Duration: -time.Hour},
+				},
+			}},
+			shouldFail: true,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -7659,6 +7939,45 @@ func TestInRepoConfigEnabled(t *testing.T) {
 	}
 }
 
+func TestInRepoConfigFetchFromGerrit(t *testing.T) {
+	testCases := []struct {
+		name            string
+		fetchFromGerrit map[string]*bool
+		identifier      string
+		expected        bool
+	}{
+		{
+			name:            "Host matches",
+			fetchFromGerrit: map[string]*bool{"host-name": utilpointer.Bool(true)},
+			identifier:      "https://host-name/repo/name",
+			expected:        true,
+		},
+		{
+			name:            "Repo overrides host",
+			fetchFromGerrit: map[string]*bool{"host-name": utilpointer.Bool(true), "host-name/repo/name": utilpointer.Bool(false)},
+			identifier:      "https://host-name/repo/name",
+		},
+		{
+			name:       "Disabled by default",
+			identifier: "https://host-name/repo/name",
+		},
+		{
+			name:            "Not a Gerrit repo",
+			fetchFromGerrit: map[string]*bool{"*": utilpointer.Bool(true)},
+			identifier:      "org/repo",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{ProwConfig: ProwConfig{InRepoConfig: InRepoConfig{FetchFromGerrit: tc.fetchFromGerrit}}}
+			if got := c.InRepoConfigFetchFromGerrit(tc.identifier); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestGetProwYAMLDoesNotCallRefGettersWhenInrepoconfigIsDisabled(t *testing.T) {
 	t.Parallel()
 
@@ -7913,6 +8232,80 @@ func TestMergeDefaultDecorationConfigThreadSafety(t *testing.T) {
 	<-s2
 }
 
+func TestDefaultProwJobDecorationConfig(t *testing.T) {
+	p := Plank{DefaultDecorationConfigs: []*DefaultDecorationConfigEntry{
+		{
+			OrgRepo: "*",
+			Cluster: "*",
+			Config: &prowapi.DecorationConfig{
+				GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "default-bucket"},
+				Timeout:          &prowapi.Duration{Duration: time.Hour},
+			},
+		},
+		{
+			OrgRepo: "org/repo",
+			Cluster: "*",
+			Config: &prowapi.DecorationConfig{
+				GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "repo-bucket"},
+			},
+		},
+		{
+			OrgRepo: "*",
+			Cluster: "build",
+			Config: &prowapi.DecorationConfig{
+				DefaultServiceAccountName: pStr("build-sa"),
+			},
+		},
+	}}
+
+	testCases := []struct {
+		name     string
+		spec     prowapi.ProwJobSpec
+		expected *prowapi.DecorationConfig
+	}{
+		{
+			name: "undecorated job is not decorated",
+			spec: prowapi.ProwJobSpec{Type: prowapi.PresubmitJob, Agent: prowapi.KubernetesAgent, Refs: &prowapi.Refs{Org: "org", Repo: "repo"}},
+		},
+		{
+			name:     "jobs of other agents are not decorated",
+			spec:     prowapi.ProwJobSpec{Type: prowapi.PresubmitJob, Agent: prowapi.JenkinsAgent, DecorationConfig: &prowapi.DecorationConfig{}},
+			expected: &prowapi.DecorationConfig{},
+		},
+		{
+			name: "presubmit gets the defaults for its repository",
+			spec: prowapi.ProwJobSpec{Type: prowapi.PresubmitJob, Agent: prowapi.KubernetesAgent, Refs: &prowapi.Refs{Org: "org", Repo: "repo"}, DecorationConfig: &prowapi.DecorationConfig{}},
+			expected: &prowapi.DecorationConfig{
+				GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "repo-bucket"},
+				Timeout:          &prowapi.Duration{Duration: time.Hour},
+			},
+		},
+		{
+			name: "periodic gets the defaults for the repository of its first extra ref and its cluster",
+			spec: prowapi.ProwJobSpec{
+				Type:             prowapi.PeriodicJob,
+				Agent:            prowapi.KubernetesAgent,
+				Cluster:          "build",
+				ExtraRefs:        []prowapi.Refs{{Org: "org", Repo: "repo"}},
+				DecorationConfig: &prowapi.DecorationConfig{Timeout: &prowapi.Duration{Duration: time.Minute}},
+			},
+			expected: &prowapi.DecorationConfig{
+				GCSConfiguration:          &prowapi.GCSConfiguration{Bucket: "repo-bucket"},
+				Timeout:                   &prowapi.Duration{Duration: time.Minute},
+				DefaultServiceAccountName: pStr("build-sa"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p.DefaultProwJobDecorationConfig(&tc.spec)
+			if diff := cmp.Diff(tc.expected, tc.spec.DecorationConfig); diff != "" {
+				t.Errorf("decoration config differs from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestDefaultAndValidateReportTemplate(t *testing.T) {
 	testCases := []struct {
 		id          string
@@ -8375,7 +8768,10 @@ func TestProwConfigMerging(t *testing.T) {
 branch-protection:
   allow_disabled_job_policies: true`,
 			},
-			expectedProwConfig: `branch-protection:
+			expectedProwConfig: `bisection:
+  max_concurrency: 2
+  resync_period: 5m0s
+branch-protection:
   allow_disabled_job_policies: true
 config_version_sha: abc
 deck:
@@ -8387,6 +8783,9 @@ deck:
     size_limit: 100000000
   tide_update_period: 10s
 default_job_timeout: 24h0m0s
+flakiness:
+  resync_period: 1h0m0s
+  window: 168h0m0s
 gangway: {}
 gerrit:
   ratelimit: 5
@@ -8425,6 +8824,7 @@ sinker:
   resync_period: 1h0m0s
   terminated_pod_ttl: 24h0m0s
 status_error_link: https://github.com/kubernetes/test-infra/issues
+status_reconciler: {}
 tide:
   context_options: {}
   max_goroutines: 20
@@ -8461,7 +8861,10 @@ plank:
 tide:
   merge_method:
     foo/bar: squash`},
-			expectedProwConfig: `branch-protection: {}
+			expectedProwConfig: `bisection:
+  max_concurrency: 2
+  resync_period: 5m0s
+branch-protection: {}
 deck:
   spyglass:
     gcs_browser_prefixes:
@@ -8471,6 +8874,9 @@ deck:
     size_limit: 100000000
   tide_update_period: 10s
 default_job_timeout: 24h0m0s
+flakiness:
+  resync_period: 1h0m0s
+  window: 168h0m0s
 gangway: {}
 gerrit:
   ratelimit: 5
@@ -8509,6 +8915,7 @@ sinker:
   resync_period: 1h0m0s
   terminated_pod_ttl: 24h0m0s
 status_error_link: https://github.com/kubernetes/test-infra/issues
+status_reconciler: {}
 tide:
   context_options: {}
   max_goroutines: 20
@@ -8538,7 +8945,10 @@ tide:
     repos:
     - another/repo
 `},
-			expectedProwConfig: `branch-protection: {}
+			expectedProwConfig: `bisection:
+  max_concurrency: 2
+  resync_period: 5m0s
+branch-protection: {}
 deck:
   spyglass:
     gcs_browser_prefixes:
@@ -8548,6 +8958,9 @@ deck:
     size_limit: 100000000
   tide_update_period: 10s
 default_job_timeout: 24h0m0s
+flakiness:
+  resync_period: 1h0m0s
+  window: 168h0m0s
 gangway: {}
 gerrit:
   ratelimit: 5
@@ -8586,6 +8999,7 @@ sinker:
   resync_period: 1h0m0s
   terminated_pod_ttl: 24h0m0s
 status_error_link: https://github.com/kubernetes/test-infra/issues
+status_reconciler: {}
 tide:
   context_options: {}
   max_goroutines: 20
@@ -8619,7 +9033,10 @@ slack_reporter_configs:
     report_template: Job {{.Spec.Job}} ended with state {{.Status.State}}.
 `,
 			},
-			expectedProwConfig: `branch-protection: {}
+			expectedProwConfig: `bisection:
+  max_concurrency: 2
+  resync_period: 5m0s
+branch-protection: {}
 config_version_sha: abc
 deck:
   spyglass:
@@ -8630,6 +9047,9 @@ deck:
     size_limit: 100000000
   tide_update_period: 10s
 default_job_timeout: 24h0m0s
+flakiness:
+  resync_period: 1h0m0s
+  window: 168h0m0s
 gangway: {}
 gerrit:
   ratelimit: 5
@@ -8680,6 +9100,7 @@ slack_reporter_configs:
     channel: '#other-channel'
     report_template: Job {{.Spec.Job}} ended with state {{.Status.State}}.
 status_error_link: https://github.com/kubernetes/test-infra/issues
+status_reconciler: {}
 tide:
   context_options: {}
   max_goroutines: 20
@@ -9567,3 +9988,51 @@ func TestSplitRepoName(t *testing.T) {
 		})
 	}
 }
+
+func TestParseProwConfigLogLevels(t *testing.T) {
+	previousName := version.Name
+	version.Name = "tide"
+	t.Cleanup(func() {
+		version.Name = previousName
+		logrusutil.SetConfiguredLevels(logrus.InfoLevel, nil)
+	})
+
+	testCases := []struct {
+		name          string
+		logLevels     map[string]string
+		expectedLevel logrus.Level
+		expectedErr   bool
+	}{
+		{
+			name:          "level of the config",
+			expectedLevel: logrus.WarnLevel,
+		},
+		{
+			name:          "level of the component",
+			logLevels:     map[string]string{"tide": "error", "hook": "debug"},
+			expectedLevel: logrus.ErrorLevel,
+		},
+		{
+			name:          "logger logs at the level of the most verbose subsystem",
+			logLevels:     map[string]string{"tide/sync": "debug"},
+			expectedLevel: logrus.DebugLevel,
+		},
+		{
+			name:        "invalid level",
+			logLevels:   map[string]string{"hook": "loud"},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{ProwConfig: ProwConfig{LogLevel: "warn", LogLevels: tc.logLevels}}
+			err := parseProwConfig(c)
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if err == nil && logrus.GetLevel() != tc.expectedLevel {
+				t.Errorf("expected level %s, got %s", tc.expectedLevel, logrus.GetLevel())
+			}
+		})
+	}
+}
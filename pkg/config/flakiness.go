@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DefaultFlakinessMinRuns is how many runs a job needs in the window before
+// its flakiness policy applies, unless the policy sets min_runs.
+const DefaultFlakinessMinRuns = 10
+
+// Flakiness is config for the flakiness controller of prow-controller-manager,
+// which aggregates the junit results of completed jobs per test case and
+// computes their flake rates.
+type Flakiness struct {
+	// ReportPath is where the flakiness report is written, e.g.
+	// gs://bucket/flakiness/report.json. Deck serves the report on
+	// /flakiness.js and Tide reads the quarantined jobs from it. The
+	// controller does nothing unless it is set.
+	ReportPath string `json:"report_path,omitempty"`
+	// Window is how far back completed jobs are considered. Jobs that sinker
+	// already deleted can not be considered, so it should not be longer than
+	// sinker.max_prowjob_age. Defaults to 168h.
+	Window *metav1.Duration `json:"window,omitempty"`
+	// ResyncPeriod is how often the report is computed. Defaults to 1h.
+	ResyncPeriod *metav1.Duration `json:"resync_period,omitempty"`
+	// Policies is a key/value pair of an org or org/repo as the key and the
+	// flakiness policy of its jobs as the value. Use '*' as key to set a
+	// policy globally. The flakiness of jobs without a policy is only
+	// reported.
+	Policies map[string]FlakinessPolicy `json:"policies,omitempty"`
+}
+
+// FlakinessPolicy decides what happens to flaky jobs and to the PRs they
+// flake on.
+type FlakinessPolicy struct {
+	// MinRuns is how many runs a job needs in the window before it can be
+	// quarantined. Defaults to 10.
+	MinRuns int `json:"min_runs,omitempty"`
+	// QuarantineThreshold is the flake rate of a presubmit, e.g. "10%" or
+	// "0.1", from which on it is quarantined: Tide no longer requires it to
+	// pass before merging. Presubmits are not quarantined if it is unset.
+	QuarantineThreshold string `json:"quarantine_threshold,omitempty"`
+	// Label is added to PRs on which a presubmit flaked, which means that a
+	// test both failed and passed on the same commit of the PR. No labels are
+	// added if it is unset.
+	Label string `json:"label,omitempty"`
+}
+
+// PolicyFor returns the flakiness policy for the jobs of a repo, which is
+// the one of the repo, of its org or the global one, in that order. The
+// second return value is false if none applies.
+func (f *Flakiness) PolicyFor(org, repo string) (FlakinessPolicy, bool) {
+	for _, key := range []string{org + "/" + repo, org, "*"} {
+		if policy, ok := f.Policies[key]; ok {
+			if policy.MinRuns == 0 {
+				policy.MinRuns = DefaultFlakinessMinRuns
+			}
+			return policy, true
+		}
+	}
+	return FlakinessPolicy{}, false
+}
+
+// ParseFlakeRate parses a flake rate given as a percentage, e.g. "10%", or
+// as a ratio, e.g. "0.1", into a ratio.
+func ParseFlakeRate(rate string) (float64, error) {
+	value := strings.TrimSpace(rate)
+	percentage := strings.HasSuffix(value, "%")
+	ratio, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid flake rate %q: %w", rate, err)
+	}
+	if percentage {
+		ratio /= 100
+	}
+	if ratio <= 0 || ratio > 1 {
+		return 0, fmt.Errorf("invalid flake rate %q: must be more than 0%% and at most 100%%", rate)
+	}
+	return ratio, nil
+}
+
+// Validate validates the flakiness config.
+func (f *Flakiness) Validate() error {
+	var errs []error
+	for key, policy := range f.Policies {
+		if policy.MinRuns < 0 {
+			errs = append(errs, fmt.Errorf("flakiness.policies[%q].min_runs %d must not be negative", key, policy.MinRuns))
+		}
+		if policy.QuarantineThreshold != "" {
+			if _, err := ParseFlakeRate(policy.QuarantineThreshold); err != nil {
+				errs = append(errs, fmt.Errorf("flakiness.policies[%q].quarantine_threshold: %w", key, err))
+			}
+		}
+	}
+	if len(f.Policies) > 0 && f.ReportPath == "" {
+		errs = append(errs, fmt.Errorf("flakiness.report_path must be set to apply flakiness policies"))
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseFlakeRate(t *testing.T) {
+	testcases := []struct {
+		rate        string
+		expected    float64
+		expectedErr bool
+	}{
+		{rate: "10%", expected: 0.1},
+		{rate: "100%", expected: 1},
+		{rate: "0.25", expected: 0.25},
+		{rate: "0", expectedErr: true},
+		{rate: "10", expectedErr: true},
+		{rate: "often", expectedErr: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.rate, func(t *testing.T) {
+			actual, err := ParseFlakeRate(tc.rate)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %f, got %f", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFlakinessPolicyFor(t *testing.T) {
+	f := &Flakiness{Policies: map[string]FlakinessPolicy{
+		"*":        {Label: "flaky"},
+		"org":      {MinRuns: 5, QuarantineThreshold: "20%"},
+		"org/repo": {MinRuns: 3, QuarantineThreshold: "10%", Label: "flake-hit"},
+	}}
+	testcases := []struct {
+		org, repo string
+		expected  FlakinessPolicy
+	}{
+		{org: "org", repo: "repo", expected: FlakinessPolicy{MinRuns: 3, QuarantineThreshold: "10%", Label: "flake-hit"}},
+		{org: "org", repo: "other", expected: FlakinessPolicy{MinRuns: 5, QuarantineThreshold: "20%"}},
+		{org: "other", repo: "repo", expected: FlakinessPolicy{MinRuns: DefaultFlakinessMinRuns, Label: "flaky"}},
+	}
+	for _, tc := range testcases {
+		actual, ok := f.PolicyFor(tc.org, tc.repo)
+		if !ok {
+			t.Errorf("expected a policy for %s/%s", tc.org, tc.repo)
+		}
+		if diff := cmp.Diff(tc.expected, actual); diff != "" {
+			t.Errorf("unexpected policy for %s/%s: %s", tc.org, tc.repo, diff)
+		}
+	}
+
+	if _, ok := (&Flakiness{}).PolicyFor("org", "repo"); ok {
+		t.Error("expected no policy without policies")
+	}
+}
+
+func TestValidateFlakiness(t *testing.T) {
+	testcases := []struct {
+		name        string
+		flakiness   Flakiness
+		expectedErr bool
+	}{
+		{
+			name: "valid",
+			flakiness: Flakiness{
+				ReportPath: "gs://bucket/flakiness.json",
+				Policies:   map[string]FlakinessPolicy{"*": {MinRuns: 5, QuarantineThreshold: "10%", Label: "flaky"}},
+			},
+		},
+		{
+			name: "report without policies",
+			flakiness: Flakiness{
+				ReportPath: "gs://bucket/flakiness.json",
+			},
+		},
+		{
+			name: "negative min runs",
+			flakiness: Flakiness{
+				ReportPath: "gs://bucket/flakiness.json",
+				Policies:   map[string]FlakinessPolicy{"org": {MinRuns: -1}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid quarantine threshold",
+			flakiness: Flakiness{
+				ReportPath: "gs://bucket/flakiness.json",
+				Policies:   map[string]FlakinessPolicy{"org": {QuarantineThreshold: "150%"}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "policies without report path",
+			flakiness: Flakiness{
+				Policies: map[string]FlakinessPolicy{"org": {Label: "flaky"}},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.flakiness.Validate(); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
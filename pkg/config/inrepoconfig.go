@@ -103,6 +103,11 @@ type ProwYAMLGetter func(c *Config, gc git.ClientFactory, identifier, baseBranch
 var _ ProwYAMLGetter = prowYAMLGetterWithDefaults
 var _ ProwYAMLGetter = prowYAMLGetter
 
+// ProwYAMLFileGetter reads a file of a repo at a commit without cloning the
+// repo, e.g. with the REST API of Gerrit. It returns false if the file doesn't
+// exist.
+type ProwYAMLFileGetter func(identifier, sha, path string) ([]byte, bool, error)
+
 // InRepoConfigGetter defines a common interface that both the Moonraker client
 // and raw InRepoConfigCache can implement. This way, Prow components like Sub
 // and Gerrit can choose either one (based on runtime flags), but regardless of
@@ -191,6 +196,48 @@ func prowYAMLGetter(
 	return ReadProwYAML(log, repo.Directory(), false)
 }
 
+// getProwYAMLFromFile is like getProwYAML, but reads the .prow.yaml file of
+// repos that fetch their InRepoConfig from Gerrit with the file getter. The
+// file is read at the head SHA if there is one, i.e. at the revision of the
+// change rather than merged into the base SHA. It falls back to cloning the
+// repo if there are several head SHAs to merge, or if the file can't be read
+// or doesn't exist, e.g. because the repo has a .prow directory instead.
+func (c *Config) getProwYAMLFromFile(getFile ProwYAMLFileGetter, gc git.ClientFactory, identifier, baseBranch string, baseSHAGetter RefGetter, headSHAGetters ...RefGetter) (*ProwYAML, error) {
+	if !c.InRepoConfigFetchFromGerrit(identifier) {
+		return c.getProwYAML(gc, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+	}
+	if !c.InRepoConfigEnabled(identifier) {
+		return &ProwYAML{}, nil
+	}
+
+	baseSHA, headSHAs, err := GetAndCheckRefs(baseSHAGetter, headSHAGetters...)
+	if err != nil {
+		return nil, err
+	}
+	if len(headSHAs) <= 1 {
+		sha := baseSHA
+		if len(headSHAs) == 1 {
+			sha = headSHAs[0]
+		}
+		log := logrus.WithFields(logrus.Fields{"repo": identifier, "sha": sha})
+		content, found, err := getFile(identifier, sha, inRepoConfigFileName)
+		switch {
+		case err != nil:
+			log.WithError(err).Warn("Failed to read the InRepoConfig file, cloning the repo instead.")
+		case found:
+			prowYAML := &ProwYAML{}
+			if err := yaml.Unmarshal(content, prowYAML); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %q: %w", inRepoConfigFileName, err)
+			}
+			return prowYAML, nil
+		default:
+			log.Debug("No InRepoConfig file, cloning the repo for a directory instead.")
+		}
+	}
+
+	return c.ProwYAMLGetter(c, gc, identifier, baseBranch, baseSHA, headSHAs...)
+}
+
 // ReadProwYAML parses the .prow.yaml file or .prow directory, no commit checkout or defaulting is included.
 func ReadProwYAML(log *logrus.Entry, dir string, strict bool) (*ProwYAML, error) {
 	prowYAML := &ProwYAML{}
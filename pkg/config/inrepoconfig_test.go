@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/prow/pkg/git/localgit"
 	"sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/kube"
@@ -743,3 +744,127 @@ func TestInRepoConfigClean(t *testing.T) {
 		t.Fatalf("%s should have been deleted", f)
 	}
 }
+
+func TestGetProwYAMLFromFile(t *testing.T) {
+	const identifier = "https://gerrit.example.com/repo"
+	files := map[string]string{
+		"head": "presubmits:\n- name: from-head\n",
+		"base": "presubmits:\n- name: from-base\n",
+		"bad":  "presubmits: {",
+	}
+	getFile := func(identifier, sha, path string) ([]byte, bool, error) {
+		if sha == "broken" {
+			return nil, false, errors.New("injected error")
+		}
+		if path != inRepoConfigFileName {
+			t.Errorf("unexpected path %q", path)
+		}
+		content, ok := files[sha]
+		return []byte(content), ok, nil
+	}
+	ref := func(sha string) RefGetter {
+		return func() (string, error) { return sha, nil }
+	}
+
+	testCases := []struct {
+		name            string
+		fetchFromGerrit bool
+		baseSHA         string
+		headSHAs        []string
+		expected        string
+		expectCloned    bool
+		expectErr       bool
+	}{
+		{
+			name:            "read at the head",
+			fetchFromGerrit: true,
+			baseSHA:         "base",
+			headSHAs:        []string{"head"},
+			expected:        "from-head",
+		},
+		{
+			name:            "read at the base without head",
+			fetchFromGerrit: true,
+			baseSHA:         "base",
+			expected:        "from-base",
+		},
+		{
+			name:            "no file is cloned",
+			fetchFromGerrit: true,
+			baseSHA:         "base",
+			headSHAs:        []string{"missing"},
+			expectCloned:    true,
+		},
+		{
+			name:            "failing to read the file is cloned",
+			fetchFromGerrit: true,
+			baseSHA:         "base",
+			headSHAs:        []string{"broken"},
+			expectCloned:    true,
+		},
+		{
+			name:            "several heads are cloned",
+			fetchFromGerrit: true,
+			baseSHA:         "base",
+			headSHAs:        []string{"head", "other"},
+			expectCloned:    true,
+		},
+		{
+			name:         "not fetched from Gerrit is cloned",
+			baseSHA:      "base",
+			headSHAs:     []string{"head"},
+			expectCloned: true,
+		},
+		{
+			name:            "invalid file",
+			fetchFromGerrit: true,
+			baseSHA:         "bad",
+			expectErr:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cloned bool
+			c := &Config{
+				JobConfig: JobConfig{
+					ProwYAMLGetter: func(_ *Config, _ git.ClientFactory, _, _, _ string, _ ...string) (*ProwYAML, error) {
+						cloned = true
+						return &ProwYAML{Presubmits: []Presubmit{{JobBase: JobBase{Name: "cloned"}}}}, nil
+					},
+				},
+				ProwConfig: ProwConfig{
+					InRepoConfig: InRepoConfig{
+						Enabled:         map[string]*bool{"*": utilpointer.Bool(true)},
+						FetchFromGerrit: map[string]*bool{"gerrit.example.com": &tc.fetchFromGerrit},
+					},
+				},
+			}
+			var headSHAGetters []RefGetter
+			for _, sha := range tc.headSHAs {
+				headSHAGetters = append(headSHAGetters, ref(sha))
+			}
+
+			prowYAML, err := c.getProwYAMLFromFile(getFile, nil, identifier, "master", ref(tc.baseSHA), headSHAGetters...)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cloned != tc.expectCloned {
+				t.Errorf("expected cloned to be %t", tc.expectCloned)
+			}
+			expected := tc.expected
+			if tc.expectCloned {
+				expected = "cloned"
+			}
+			if len(prowYAML.Presubmits) != 1 || prowYAML.Presubmits[0].Name != expected {
+				t.Errorf("expected presubmit %q, got %v", expected, prowYAML.Presubmits)
+			}
+		})
+	}
+}
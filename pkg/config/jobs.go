@@ -109,6 +109,12 @@ type JobBase struct {
 	// If this field is unspecified or false, a new pod will be created to replace
 	// the evicted one.
 	ErrorOnEviction bool `json:"error_on_eviction,omitempty"`
+	// RetryPolicy makes plank run the job again when an attempt fails, up to
+	// max_attempts times in total. Attempts are retried whatever they failed for
+	// unless retryable_reasons limits them to some of Failure, Evicted,
+	// PodPendingTimeout, PodRunningTimeout and PodDeleted. Only applies to the
+	// kubernetes agent.
+	RetryPolicy *prowapi.RetryPolicy `json:"retry_policy,omitempty"`
 	// SourcePath contains the path where this job is defined
 	SourcePath string `json:"-"`
 	// Spec is the Kubernetes pod spec used if Agent is kubernetes.
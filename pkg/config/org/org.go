@@ -26,6 +26,71 @@ import (
 // orgs to their configuration at the top level under an `orgs` key.
 type FullConfig struct {
 	Orgs map[string]Config `json:"orgs,omitempty"`
+
+	// IdentityProviders configures the directories that teams can sync
+	// their members from with members_from.
+	IdentityProviders *IdentityProviders `json:"identity_providers,omitempty"`
+}
+
+// IdentityProviders configures how to read group memberships from corporate
+// directories and how to map their identities to GitHub logins.
+type IdentityProviders struct {
+	LDAP         *LDAPProvider   `json:"ldap,omitempty"`
+	GoogleGroups *GoogleProvider `json:"google_groups,omitempty"`
+	SCIM         *SCIMProvider   `json:"scim,omitempty"`
+
+	Mapping IdentityMapping `json:"mapping,omitempty"`
+}
+
+// LDAPProvider configures an LDAP directory. The members of a group are the
+// users matching UserFilter, formatted with the DN of the group.
+type LDAPProvider struct {
+	URL              string `json:"url"`
+	BaseDN           string `json:"base_dn"`
+	BindDN           string `json:"bind_dn,omitempty"`
+	BindPasswordFile string `json:"bind_password_file,omitempty"`
+	// UserFilter defaults to (memberOf=%s).
+	UserFilter string `json:"user_filter,omitempty"`
+	// UserAttribute is the attribute holding the identity of a user,
+	// defaults to uid.
+	UserAttribute string `json:"user_attribute,omitempty"`
+}
+
+// GoogleProvider configures Google Groups, read with the Admin SDK
+// Directory API by a service account impersonating a Workspace admin.
+type GoogleProvider struct {
+	CredentialsFile string `json:"credentials_file"`
+	Subject         string `json:"subject"`
+}
+
+// SCIMProvider configures a SCIM 2.0 service.
+type SCIMProvider struct {
+	URL       string `json:"url"`
+	TokenFile string `json:"token_file"`
+}
+
+// IdentityMapping maps the identities of directory users, e.g. their email
+// addresses, to GitHub logins.
+type IdentityMapping struct {
+	// Users maps identities to logins explicitly. Mapping an identity to the
+	// empty string excludes it.
+	Users map[string]string `json:"users,omitempty"`
+	// Pattern and Replacement map the remaining identities, as done by
+	// regexp.ReplaceAllString. Identities that do not match Pattern are
+	// skipped. Without a pattern, identities are used as logins.
+	Pattern     string `json:"pattern,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// MemberSource declares a directory group whose members become members of a
+// team. Exactly one of its fields must be set.
+type MemberSource struct {
+	// LDAP is the DN of an LDAP group.
+	LDAP string `json:"ldap,omitempty"`
+	// GoogleGroup is the email address of a Google Group.
+	GoogleGroup string `json:"google_group,omitempty"`
+	// SCIM is the display name of a SCIM group.
+	SCIM string `json:"scim,omitempty"`
 }
 
 // Metadata declares metadata about the GitHub org.
@@ -100,6 +165,9 @@ type Team struct {
 	Maintainers []string        `json:"maintainers,omitempty"`
 	Children    map[string]Team `json:"teams,omitempty"`
 
+	// MembersFrom adds the members of directory groups to Members.
+	MembersFrom []MemberSource `json:"members_from,omitempty"`
+
 	Previously []string `json:"previously,omitempty"`
 
 	// This is injected to the Team structure by listing privilege
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	// SLOSuccessRateAnnotation is the annotation of a job that sets the
+	// objective for the ratio of its runs that succeed, e.g. "95%" or "0.95".
+	SLOSuccessRateAnnotation = "prow.k8s.io/slo-success-rate"
+	// SLOTeamAnnotation is the annotation of a job that names the team its
+	// SLO alerts are routed to.
+	SLOTeamAnnotation = "prow.k8s.io/slo-team"
+)
+
+// TideMergeLatencyBuckets are the buckets of the merge latency histogram of
+// Tide in seconds, and so the thresholds merge latency SLOs can have.
+var TideMergeLatencyBuckets = []float64{
+	time.Minute.Seconds(),
+	(5 * time.Minute).Seconds(),
+	(10 * time.Minute).Seconds(),
+	(30 * time.Minute).Seconds(),
+	time.Hour.Seconds(),
+	(2 * time.Hour).Seconds(),
+	(4 * time.Hour).Seconds(),
+	(8 * time.Hour).Seconds(),
+	(12 * time.Hour).Seconds(),
+	(24 * time.Hour).Seconds(),
+	(48 * time.Hour).Seconds(),
+	(168 * time.Hour).Seconds(),
+}
+
+// TideMergeLatencySLO is the objective for how long PRs wait in the pool of
+// Tide until they are merged.
+type TideMergeLatencySLO struct {
+	// Threshold is how long PRs may wait in the pool until they are merged.
+	// It must be one of the buckets of the merge latency histogram of Tide:
+	// 1m, 5m, 10m, 30m, 1h, 2h, 4h, 8h, 12h, 24h, 48h or 168h.
+	Threshold metav1.Duration `json:"threshold"`
+	// Objective is the ratio of PRs that must be merged within the threshold,
+	// e.g. "90%" or "0.9".
+	Objective string `json:"objective"`
+	// Team is the team the alerts of the SLO are routed to.
+	Team string `json:"team,omitempty"`
+}
+
+// ParseSLOObjective parses an objective given as a percentage, e.g. "99.5%",
+// or as a ratio, e.g. "0.995", into a ratio.
+func ParseSLOObjective(objective string) (float64, error) {
+	value := strings.TrimSpace(objective)
+	percentage := strings.HasSuffix(value, "%")
+	ratio, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid objective %q: %w", objective, err)
+	}
+	if percentage {
+		ratio /= 100
+	}
+	if ratio <= 0 || ratio >= 1 {
+		return 0, fmt.Errorf("invalid objective %q: must be between 0%% and 100%%, exclusive", objective)
+	}
+	return ratio, nil
+}
+
+func validateSLOAnnotations(annotations map[string]string) error {
+	objective, ok := annotations[SLOSuccessRateAnnotation]
+	if !ok {
+		return nil
+	}
+	if _, err := ParseSLOObjective(objective); err != nil {
+		return fmt.Errorf("annotation %s: %w", SLOSuccessRateAnnotation, err)
+	}
+	return nil
+}
+
+func (t *Tide) validateMergeLatencySLOs() error {
+	var errs []error
+	for orgRepo, slo := range t.MergeLatencySLOs {
+		if !isTideMergeLatencyBucket(slo.Threshold.Duration) {
+			errs = append(errs, fmt.Errorf("tide.merge_latency_slos[%q].threshold %s is not one of the buckets of the merge latency histogram", orgRepo, slo.Threshold.Duration))
+		}
+		if _, err := ParseSLOObjective(slo.Objective); err != nil {
+			errs = append(errs, fmt.Errorf("tide.merge_latency_slos[%q].objective: %w", orgRepo, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func isTideMergeLatencyBucket(threshold time.Duration) bool {
+	for _, bucket := range TideMergeLatencyBuckets {
+		if bucket == threshold.Seconds() {
+			return true
+		}
+	}
+	return false
+}
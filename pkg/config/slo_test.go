@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseSLOObjective(t *testing.T) {
+	testcases := []struct {
+		objective   string
+		expected    float64
+		expectedErr bool
+	}{
+		{objective: "95%", expected: 0.95},
+		{objective: "99.5%", expected: 0.995},
+		{objective: "0.9", expected: 0.9},
+		{objective: "100%", expectedErr: true},
+		{objective: "0", expectedErr: true},
+		{objective: "95", expectedErr: true},
+		{objective: "most", expectedErr: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.objective, func(t *testing.T) {
+			actual, err := ParseSLOObjective(tc.objective)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %f, got %f", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidateMergeLatencySLOs(t *testing.T) {
+	testcases := []struct {
+		name        string
+		slo         TideMergeLatencySLO
+		expectedErr bool
+	}{
+		{
+			name: "valid",
+			slo:  TideMergeLatencySLO{Threshold: metav1.Duration{Duration: time.Hour}, Objective: "90%"},
+		},
+		{
+			name:        "threshold is not a bucket",
+			slo:         TideMergeLatencySLO{Threshold: metav1.Duration{Duration: 90 * time.Minute}, Objective: "90%"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid objective",
+			slo:         TideMergeLatencySLO{Threshold: metav1.Duration{Duration: time.Hour}},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tide := &Tide{MergeLatencySLOs: map[string]TideMergeLatencySLO{"org/repo": tc.slo}}
+			if err := tide.validateMergeLatencySLOs(); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
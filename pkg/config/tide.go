@@ -219,6 +219,10 @@ type Tide struct {
 	// starting a new one requires to start new instances of all tests.
 	// Use '*' as key to set this globally. Defaults to true.
 	PrioritizeExistingBatchesMap map[string]bool `json:"prioritize_existing_batches,omitempty"`
+	// MergeLatencySLOs is a key/value pair of an org or org/repo as the key and the
+	// objective for how long its PRs wait in the pool until they are merged as the
+	// value. The SLOs are turned into Prometheus alerting rules by slo-rules.
+	MergeLatencySLOs map[string]TideMergeLatencySLO `json:"merge_latency_slos,omitempty"`
 
 	TideGitHubConfig `json:",inline"`
 }
@@ -19,11 +19,17 @@ package crier
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"k8s.io/apimachinery/pkg/api/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +39,8 @@ import (
 
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	"sigs.k8s.io/prow/pkg/logrusutil"
+	"sigs.k8s.io/prow/pkg/tracing"
 )
 
 type ReportClient interface {
@@ -53,6 +61,42 @@ type reconciler struct {
 	pjclientset       ctrlruntimeclient.Client
 	reporter          ReportClient
 	enablementChecker func(org, repo string) bool
+	dumper            FailedReportDumper
+	maxAttempts       int
+
+	attemptsLock sync.Mutex
+	// attempts counts the failed attempts to report the current state of
+	// ProwJobs.
+	attempts map[types.NamespacedName]reportAttempts
+}
+
+type reportAttempts struct {
+	state prowv1.ProwJobState
+	count int
+}
+
+// DefaultMaxReportAttempts is the number of failed attempts after which a
+// report is given up on. With the backoff of the controller, that is after
+// about an hour.
+const DefaultMaxReportAttempts = 20
+
+// Option configures optional behavior of the crier reconciler.
+type Option func(*reconciler)
+
+// WithFailedReportDumper makes the reconciler persist the reports it gives up
+// on through the given dumper.
+func WithFailedReportDumper(dumper FailedReportDumper) Option {
+	return func(r *reconciler) {
+		r.dumper = dumper
+	}
+}
+
+// WithMaxReportAttempts sets the number of failed attempts after which a report
+// is given up on.
+func WithMaxReportAttempts(attempts int) Option {
+	return func(r *reconciler) {
+		r.maxAttempts = attempts
+	}
 }
 
 // New constructs a new instance of the crier reconciler.
@@ -61,7 +105,16 @@ func New(
 	reporter ReportClient,
 	numWorkers int,
 	enablementChecker func(org, repo string) bool,
+	opts ...Option,
 ) error {
+	r := &reconciler{
+		pjclientset:       mgr.GetClient(),
+		reporter:          reporter,
+		enablementChecker: enablementChecker,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
 	if err := builder.
 		ControllerManagedBy(mgr).
 		// Is used for metrics, hence must be unique per controller instance
@@ -69,11 +122,7 @@ func New(
 		For(&prowv1.ProwJob{}).
 		WithOptions(controller.Options{MaxConcurrentReconciles: numWorkers,
 			RateLimiter: workqueue.DefaultControllerRateLimiter()}).
-		Complete(&reconciler{
-			pjclientset:       mgr.GetClient(),
-			reporter:          reporter,
-			enablementChecker: enablementChecker,
-		}); err != nil {
+		Complete(r); err != nil {
 		return fmt.Errorf("failed to construct controller: %w", err)
 	}
 
@@ -83,7 +132,7 @@ func New(
 // Reconcile retrieves each queued item and takes the necessary handler action based off of if
 // the item was created or deleted.
 func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
-	log := logrus.WithField("reporter", r.reporter.GetName()).WithField("key", req.String()).WithField("prowjob", req.Name)
+	log := logrus.WithField("reporter", r.reporter.GetName()).WithField("key", req.String()).WithField(logrusutil.ProwJobField, req.Name)
 	log.Debug("processing next key")
 	result, err := r.reconcile(ctx, log, req)
 	if err != nil {
@@ -108,8 +157,9 @@ func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req recon
 	defer cancel()
 	var pj prowv1.ProwJob
 	if err := r.pjclientset.Get(ctx, req.NamespacedName, &pj); err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			log.Debug("object no longer exist")
+			r.forgetAttempts(req.NamespacedName)
 			return nil, nil
 		}
 
@@ -120,7 +170,7 @@ func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req recon
 		return nil, nil
 	}
 
-	log = log.WithField("jobName", pj.Spec.Job)
+	log = log.WithFields(logrusutil.ProwJobFields(&pj))
 
 	if !r.reporter.ShouldReport(ctx, log, &pj) {
 		return nil, nil
@@ -137,23 +187,49 @@ func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req recon
 		return nil, nil
 	}
 
-	log = log.WithField("jobStatus", pj.Status.State)
+	org := orgForProwJob(&pj)
 	log.Info("Will report state")
-	pjs, requeue, err := r.reporter.Report(ctx, log, &pj)
+	start := time.Now()
+	reportCtx, span := tracing.StartProwJobSpan(ctx, &pj, "report prowjob", trace.WithAttributes(
+		attribute.String("prow.reporter", r.reporter.GetName()), attribute.String("prow.state", string(pj.Status.State))))
+	pjs, requeue, err := r.reporter.Report(reportCtx, log, &pj)
 	if err != nil {
-		if criercommonlib.IsUserError(err) {
-			log.WithError(err).Debug("Failed to report job.")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	if err != nil {
+		crierMetrics.reportingResults.WithLabelValues(r.reporter.GetName(), ResultError).Inc()
+		crierMetrics.reportDuration.WithLabelValues(r.reporter.GetName(), org, ResultError).Observe(time.Since(start).Seconds())
+		reason := failureReason(err)
+		attempts := r.failedAttempt(req.NamespacedName, pj.Status.State)
+		log := log.WithError(err).WithField("attempts", attempts)
+		// A user error does not go away by retrying, anything else is
+		// expected to be transient and retried with backoff until the
+		// attempts are exhausted.
+		if reason != FailureReasonUserError && attempts < r.maxReportAttempts() {
+			log.Error("Failed to report job, will retry.")
+			crierMetrics.reportRetries.WithLabelValues(r.reporter.GetName(), org).Inc()
+			return nil, fmt.Errorf("failed to report job: %w", err)
+		}
+		r.forgetAttempts(req.NamespacedName)
+		if reason == FailureReasonUserError {
+			log.Debug("Failed to report job, giving up.")
 		} else {
-			log.WithError(err).Error("Failed to report job.")
+			log.Error("Failed to report job, giving up.")
 		}
-		crierMetrics.reportingResults.WithLabelValues(r.reporter.GetName(), ResultError).Inc()
-		return nil, fmt.Errorf("failed to report job: %w", err)
+		crierMetrics.reportFailures.WithLabelValues(r.reporter.GetName(), org, reason).Inc()
+		r.dumpFailedReport(ctx, log, &pj, org, reason, attempts, err)
+		return nil, nil
 	}
 	if requeue != nil {
+		crierMetrics.reportRetries.WithLabelValues(r.reporter.GetName(), org).Inc()
 		return requeue, nil
 	}
 
+	r.forgetAttempts(req.NamespacedName)
 	crierMetrics.reportingResults.WithLabelValues(r.reporter.GetName(), ResultSuccess).Inc()
+	crierMetrics.reportDuration.WithLabelValues(r.reporter.GetName(), org, ResultSuccess).Observe(time.Since(start).Seconds())
 	log.WithField("job-count", len(pjs)).Info("Reported job(s), now will update pj(s).")
 	var lastErr error
 	for _, pjob := range pjs {
@@ -197,3 +273,75 @@ func (r *reconciler) shouldHandle(pj *prowv1.ProwJob) bool {
 
 	return enabled
 }
+
+func (r *reconciler) maxReportAttempts() int {
+	if r.maxAttempts <= 0 {
+		return DefaultMaxReportAttempts
+	}
+	return r.maxAttempts
+}
+
+// failedAttempt records a failed attempt to report the state of the ProwJob
+// and returns the number of failed attempts to report that state.
+func (r *reconciler) failedAttempt(name types.NamespacedName, state prowv1.ProwJobState) int {
+	r.attemptsLock.Lock()
+	defer r.attemptsLock.Unlock()
+	if r.attempts == nil {
+		r.attempts = map[types.NamespacedName]reportAttempts{}
+	}
+	attempts := r.attempts[name]
+	if attempts.state != state {
+		attempts = reportAttempts{state: state}
+	}
+	attempts.count++
+	r.attempts[name] = attempts
+	return attempts.count
+}
+
+func (r *reconciler) forgetAttempts(name types.NamespacedName) {
+	r.attemptsLock.Lock()
+	defer r.attemptsLock.Unlock()
+	delete(r.attempts, name)
+}
+
+func (r *reconciler) dumpFailedReport(ctx context.Context, log *logrus.Entry, pj *prowv1.ProwJob, org, reason string, attempts int, reportErr error) {
+	if r.dumper == nil {
+		return
+	}
+	report := &FailedReport{
+		Reporter: r.reporter.GetName(),
+		Org:      org,
+		Reason:   reason,
+		Attempts: attempts,
+		Error:    reportErr.Error(),
+		Time:     time.Now(),
+		ProwJob:  pj,
+		State:    pj.Status.State,
+	}
+	if err := r.dumper.Dump(ctx, report); err != nil {
+		log.WithError(err).Warn("Failed to dump failed report.")
+	}
+}
+
+// orgForProwJob returns the org used to label metrics for a ProwJob, which is
+// the org of its main refs or of its first extra refs.
+func orgForProwJob(pj *prowv1.ProwJob) string {
+	if pj.Spec.Refs != nil {
+		return pj.Spec.Refs.Org
+	}
+	if len(pj.Spec.ExtraRefs) > 0 {
+		return pj.Spec.ExtraRefs[0].Org
+	}
+	return ""
+}
+
+func failureReason(err error) string {
+	switch {
+	case criercommonlib.IsUserError(err):
+		return FailureReasonUserError
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureReasonTimeout
+	default:
+		return FailureReasonInternal
+	}
+}
@@ -18,6 +18,7 @@ package crier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -35,6 +36,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
 )
 
 const reporterName = "fakeReporter"
@@ -329,3 +332,200 @@ func (c *patchTrackingClient) Patch(ctx context.Context, obj ctrlruntimeclient.O
 	c.patches++
 	return c.Client.Patch(ctx, obj, patch, opts...)
 }
+
+func (c *patchTrackingClient) Status() ctrlruntimeclient.StatusWriter {
+	return &patchTrackingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type patchTrackingStatusWriter struct {
+	ctrlruntimeclient.StatusWriter
+	client *patchTrackingClient
+}
+
+func (w *patchTrackingStatusWriter) Patch(ctx context.Context, obj ctrlruntimeclient.Object, patch ctrlruntimeclient.Patch, opts ...ctrlruntimeclient.PatchOption) error {
+	w.client.patches++
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+type fakeDumper struct {
+	reports []*FailedReport
+}
+
+func (f *fakeDumper) Dump(_ context.Context, report *FailedReport) error {
+	f.reports = append(f.reports, report)
+	return nil
+}
+
+func TestReconcileDumpsFailedReports(t *testing.T) {
+	tests := []struct {
+		name           string
+		reportErr      error
+		expectReason   string
+		expectAttempts int
+	}{
+		{
+			name:           "internal error",
+			reportErr:      errors.New("some-err"),
+			expectReason:   FailureReasonInternal,
+			expectAttempts: 2,
+		},
+		{
+			name:           "user error",
+			reportErr:      criercommonlib.UserError(errors.New("bad config")),
+			expectReason:   FailureReasonUserError,
+			expectAttempts: 1,
+		},
+		{
+			name:           "timeout",
+			reportErr:      fmt.Errorf("posting status: %w", context.DeadlineExceeded),
+			expectReason:   FailureReasonTimeout,
+			expectAttempts: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			job := &prowv1.ProwJob{
+				ObjectMeta: v1.ObjectMeta{Name: "foo"},
+				Spec: prowv1.ProwJobSpec{
+					Job:    "foo",
+					Report: true,
+					Refs:   &prowv1.Refs{Org: "org", Repo: "repo"},
+				},
+				Status: prowv1.ProwJobStatus{
+					State: prowv1.FailureState,
+				},
+			}
+			rp := fakeReporter{
+				shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+				err:              test.reportErr,
+			}
+			dumper := &fakeDumper{}
+			r := &reconciler{
+				pjclientset:       fakectrlruntimeclient.NewFakeClient(job),
+				reporter:          &rp,
+				enablementChecker: func(_, _ string) bool { return true },
+				dumper:            dumper,
+				maxAttempts:       2,
+			}
+
+			for attempt := 1; ; attempt++ {
+				_, err := r.Reconcile(context.Background(), ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}})
+				if err == nil {
+					break
+				}
+				if attempt >= test.expectAttempts {
+					t.Fatalf("expected the report to be given up on after %d attempts, got %v", test.expectAttempts, err)
+				}
+				if len(dumper.reports) != 0 {
+					t.Fatalf("expected no dump for a retried report, got %d", len(dumper.reports))
+				}
+			}
+			if len(dumper.reports) != 1 {
+				t.Fatalf("expected exactly one dumped report, got %d", len(dumper.reports))
+			}
+			report := dumper.reports[0]
+			if report.Reason != test.expectReason {
+				t.Errorf("expected reason %q, got %q", test.expectReason, report.Reason)
+			}
+			if report.Attempts != test.expectAttempts {
+				t.Errorf("expected %d attempts, got %d", test.expectAttempts, report.Attempts)
+			}
+			if report.Org != "org" || report.Reporter != reporterName || report.State != prowv1.FailureState {
+				t.Errorf("unexpected report: %+v", report)
+			}
+		})
+	}
+}
+
+func TestReconcileGivesUpAfterMaxAttempts(t *testing.T) {
+	job := &prowv1.ProwJob{
+		ObjectMeta: v1.ObjectMeta{Name: "foo"},
+		Spec: prowv1.ProwJobSpec{
+			Job:    "foo",
+			Report: true,
+		},
+		Status: prowv1.ProwJobStatus{
+			State: prowv1.PendingState,
+		},
+	}
+	client := fakectrlruntimeclient.NewFakeClient(job)
+	r := &reconciler{
+		pjclientset: client,
+		reporter: &fakeReporter{
+			shouldReportFunc: func(*prowv1.ProwJob) bool { return true },
+			err:              errors.New("some-err"),
+		},
+		enablementChecker: func(_, _ string) bool { return true },
+		maxAttempts:       3,
+	}
+	req := ctrlruntime.Request{NamespacedName: types.NamespacedName{Name: "foo"}}
+	report := func() error {
+		_, err := r.Reconcile(context.Background(), req)
+		return err
+	}
+
+	for attempt := 1; attempt < 3; attempt++ {
+		if err := report(); err == nil {
+			t.Fatalf("expected attempt %d to be retried", attempt)
+		}
+	}
+
+	// A new state is reported with a fresh budget of attempts.
+	var current prowv1.ProwJob
+	if err := client.Get(context.Background(), req.NamespacedName, &current); err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	current.Status.State = prowv1.FailureState
+	if err := client.Update(context.Background(), &current); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	for attempt := 1; attempt < 3; attempt++ {
+		if err := report(); err == nil {
+			t.Fatalf("expected attempt %d of the new state to be retried", attempt)
+		}
+	}
+	if err := report(); err != nil {
+		t.Fatalf("expected the last attempt to be given up on, got %v", err)
+	}
+	if len(r.attempts) != 0 {
+		t.Errorf("expected the attempts to be forgotten, got %v", r.attempts)
+	}
+}
+
+func TestStorageFailedReportDumper(t *testing.T) {
+	opener := &fakeopener.FakeOpener{}
+	dumper := NewStorageFailedReportDumper(opener, "gs://bucket/crier/")
+	report := &FailedReport{
+		Reporter: "github-reporter",
+		Reason:   FailureReasonInternal,
+		Error:    "boom",
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		ProwJob:  &prowv1.ProwJob{ObjectMeta: v1.ObjectMeta{Name: "pj"}},
+		State:    prowv1.SuccessState,
+	}
+	if err := dumper.Dump(context.Background(), report); err != nil {
+		t.Fatalf("failed to dump: %v", err)
+	}
+	again := *report
+	again.Error = "boom again"
+	again.Time = report.Time.Add(time.Hour)
+	if err := dumper.Dump(context.Background(), &again); err != nil {
+		t.Fatalf("failed to dump again: %v", err)
+	}
+	if len(opener.Buffer) != 1 {
+		t.Errorf("expected a single report per ProwJob and state, got %v", opener.Buffer)
+	}
+	const expectedPath = "gs://bucket/crier/github-reporter/pj-success.json"
+	buf, ok := opener.Buffer[expectedPath]
+	if !ok {
+		t.Fatalf("expected report at %s, got %v", expectedPath, opener.Buffer)
+	}
+	var got FailedReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal report: %v", err)
+	}
+	if got.Error != "boom again" || got.Reason != FailureReasonInternal {
+		t.Errorf("unexpected report content: %+v", got)
+	}
+}
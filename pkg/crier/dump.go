@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+// FailedReport is the record written for a ProwJob that could not be reported.
+type FailedReport struct {
+	Reporter string `json:"reporter"`
+	Org      string `json:"org,omitempty"`
+	Reason   string `json:"reason"`
+	// Attempts is the number of failed attempts before the report was given
+	// up on.
+	Attempts int                 `json:"attempts"`
+	Error    string              `json:"error"`
+	Time     time.Time           `json:"time"`
+	ProwJob  *prowv1.ProwJob     `json:"prowjob"`
+	State    prowv1.ProwJobState `json:"state"`
+}
+
+// FailedReportDumper persists failed reports so that they can be inspected
+// after the fact, e.g. when a status never showed up on a pull request.
+type FailedReportDumper interface {
+	Dump(ctx context.Context, report *FailedReport) error
+}
+
+type storageDumper struct {
+	opener io.Opener
+	prefix string
+}
+
+// NewStorageFailedReportDumper returns a FailedReportDumper that writes one
+// JSON object per reporter, ProwJob and state under the given storage prefix,
+// e.g. gs://bucket/crier/failed-reports.
+func NewStorageFailedReportDumper(opener io.Opener, prefix string) FailedReportDumper {
+	return &storageDumper{opener: opener, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+func (d *storageDumper) Dump(ctx context.Context, report *FailedReport) error {
+	content, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal failed report: %w", err)
+	}
+	return io.WriteContent(ctx, logrus.WithField("reporter", report.Reporter), d.opener, d.path(report), content)
+}
+
+func (d *storageDumper) path(report *FailedReport) string {
+	name := fmt.Sprintf("%s-%s.json", report.ProwJob.Name, report.State)
+	// path.Join would collapse the double slash in the scheme of the prefix.
+	return d.prefix + "/" + path.Join(report.Reporter, name)
+}
@@ -24,12 +24,27 @@ const (
 	ResultSuccess = "SUCCESS"
 )
 
+// Reasons used to label failed reports. User errors are given up on right
+// away, the other failures only once the attempts are exhausted.
+const (
+	FailureReasonUserError = "user_error"
+	FailureReasonTimeout   = "timeout"
+	FailureReasonInternal  = "internal_error"
+)
+
 // Prometheus Metrics
 var (
 	crierMetrics = struct {
 		latency *prometheus.HistogramVec
 		// Count success/failures of reporting attempts.
 		reportingResults *prometheus.CounterVec
+		// Time spent in a single call to the reporter, by org.
+		reportDuration *prometheus.HistogramVec
+		// Count of reports that will be retried, either because the
+		// reporter asked to be requeued or because of a transient error.
+		reportRetries *prometheus.CounterVec
+		// Count of reports that were given up on, by reason.
+		reportFailures *prometheus.CounterVec
 	}{
 		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Name:    "crier_report_latency",
@@ -45,10 +60,37 @@ var (
 			"reporter",
 			"result",
 		}),
+		reportDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "crier_report_duration_seconds",
+			Help:    "Histogram of time spent in a single reporting attempt by reporter, org and result.",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{
+			"reporter",
+			"org",
+			"result",
+		}),
+		reportRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "crier_report_retries",
+			Help: "Count of reporting attempts that were requeued for retry by reporter and org.",
+		}, []string{
+			"reporter",
+			"org",
+		}),
+		reportFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "crier_report_failures",
+			Help: "Count of reports that were given up on by reporter, org and reason.",
+		}, []string{
+			"reporter",
+			"org",
+			"reason",
+		}),
 	}
 )
 
 func init() {
 	prometheus.MustRegister(crierMetrics.latency)
 	prometheus.MustRegister(crierMetrics.reportingResults)
+	prometheus.MustRegister(crierMetrics.reportDuration)
+	prometheus.MustRegister(crierMetrics.reportRetries)
+	prometheus.MustRegister(crierMetrics.reportFailures)
 }
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bitbucket implements a reporter interface for Bitbucket build statuses.
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+const (
+	// BitbucketReporterName is the name for the bitbucket reporter
+	BitbucketReporterName = "bitbucket-reporter"
+
+	// maxDescriptionLength is the limit Bitbucket enforces on build status
+	// descriptions.
+	maxDescriptionLength = 255
+)
+
+// Bitbucket Server only knows about running, successful and failed builds,
+// Bitbucket Cloud also accepts these.
+var stateToBuildState = map[v1.ProwJobState]bitbucket.BuildState{
+	v1.TriggeredState: bitbucket.BuildStateInProgress,
+	v1.PendingState:   bitbucket.BuildStateInProgress,
+	v1.SuccessState:   bitbucket.BuildStateSuccessful,
+	v1.FailureState:   bitbucket.BuildStateFailed,
+	v1.ErrorState:     bitbucket.BuildStateFailed,
+	v1.AbortedState:   bitbucket.BuildStateFailed,
+}
+
+type bitbucketClient interface {
+	SetBuildStatus(project, repo, sha string, status bitbucket.BuildStatus) error
+}
+
+// Client is a bitbucket reporter client
+type Client struct {
+	bc bitbucketClient
+}
+
+// NewReporter returns a reporter client
+func NewReporter(bc bitbucketClient) *Client {
+	return &Client{bc: bc}
+}
+
+// GetName returns the name of the reporter
+func (c *Client) GetName() string {
+	return BitbucketReporterName
+}
+
+// ShouldReport returns if this prowjob should be reported by the bitbucket
+// reporter, which only handles jobs triggered for Bitbucket repositories.
+func (c *Client) ShouldReport(_ context.Context, _ *logrus.Entry, pj *v1.ProwJob) bool {
+	if !pj.Spec.Report || pj.Spec.Refs == nil {
+		return false
+	}
+	if pj.Annotations[kube.BitbucketInstance] == "" {
+		return false
+	}
+	return pj.Spec.Type == v1.PresubmitJob || pj.Spec.Type == v1.PostsubmitJob
+}
+
+// Report sets a build status for the ProwJob on the tested commit, keyed by
+// the context of the job.
+func (c *Client) Report(_ context.Context, log *logrus.Entry, pj *v1.ProwJob) ([]*v1.ProwJob, *reconcile.Result, error) {
+	sha := pj.Spec.Refs.BaseSHA
+	if len(pj.Spec.Refs.Pulls) > 0 {
+		sha = pj.Spec.Refs.Pulls[0].SHA
+	}
+	if sha == "" {
+		return []*v1.ProwJob{pj}, nil, criercommonlib.UserError(errors.New("prowjob has no commit to report on"))
+	}
+	state, ok := stateToBuildState[pj.Status.State]
+	if !ok {
+		return []*v1.ProwJob{pj}, nil, fmt.Errorf("unknown prowjob state: %s", pj.Status.State)
+	}
+
+	description := pj.Status.Description
+	if len(description) > maxDescriptionLength {
+		description = description[:maxDescriptionLength-3] + "..."
+	}
+	status := bitbucket.BuildStatus{
+		Key:         pj.Spec.Context,
+		Name:        pj.Spec.Context,
+		State:       state,
+		URL:         pj.Status.URL,
+		Description: description,
+	}
+	log.WithFields(logrus.Fields{"sha": sha, "state": state}).Debug("Setting build status.")
+	if err := c.bc.SetBuildStatus(pj.Spec.Refs.Org, pj.Spec.Refs.Repo, sha, status); err != nil {
+		return []*v1.ProwJob{pj}, nil, fmt.Errorf("failed to set build status: %w", err)
+	}
+	return []*v1.ProwJob{pj}, nil, nil
+}
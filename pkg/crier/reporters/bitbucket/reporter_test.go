@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/bitbucket/fakebitbucket"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+var bitbucketAnnotations = map[string]string{kube.BitbucketInstance: "https://bitbucket.example.com"}
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "bitbucket presubmit is reported",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Annotations: bitbucketAnnotations},
+				Spec:       v1.ProwJobSpec{Type: v1.PresubmitJob, Report: true, Refs: &v1.Refs{}},
+			},
+			expected: true,
+		},
+		{
+			name: "github presubmit is not reported",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{Type: v1.PresubmitJob, Report: true, Refs: &v1.Refs{}},
+			},
+		},
+		{
+			name: "bitbucket periodic is not reported",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Annotations: bitbucketAnnotations},
+				Spec:       v1.ProwJobSpec{Type: v1.PeriodicJob, Report: true, Refs: &v1.Refs{}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewReporter(fakebitbucket.NewFakeClient())
+			if got := c.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name        string
+		state       v1.ProwJobState
+		pulls       []v1.Pull
+		expectedSHA string
+		expected    bitbucket.BuildState
+	}{
+		{
+			name:        "pending presubmit is in progress on the head commit",
+			state:       v1.PendingState,
+			pulls:       []v1.Pull{{Number: 1, SHA: "head-sha"}},
+			expectedSHA: "head-sha",
+			expected:    bitbucket.BuildStateInProgress,
+		},
+		{
+			name:        "successful postsubmit reports on the base commit",
+			state:       v1.SuccessState,
+			expectedSHA: "base-sha",
+			expected:    bitbucket.BuildStateSuccessful,
+		},
+		{
+			name:        "aborted job is failed",
+			state:       v1.AbortedState,
+			pulls:       []v1.Pull{{Number: 1, SHA: "head-sha"}},
+			expectedSHA: "head-sha",
+			expected:    bitbucket.BuildStateFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bc := fakebitbucket.NewFakeClient()
+			c := NewReporter(bc)
+			pj := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Annotations: bitbucketAnnotations},
+				Spec: v1.ProwJobSpec{
+					Type:    v1.PresubmitJob,
+					Context: "unit",
+					Report:  true,
+					Refs:    &v1.Refs{Org: "PRJ", Repo: "repo", BaseSHA: "base-sha", Pulls: tc.pulls},
+				},
+				Status: v1.ProwJobStatus{State: tc.state, URL: "https://prow/unit", Description: "Job triggered."},
+			}
+			if _, _, err := c.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := []bitbucket.BuildStatus{{Key: "unit", Name: "unit", State: tc.expected, URL: "https://prow/unit", Description: "Job triggered."}}
+			if diff := cmp.Diff(expected, bc.BuildStatuses[tc.expectedSHA]); diff != "" {
+				t.Errorf("unexpected build statuses: %s", diff)
+			}
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/logrusutil"
 )
 
 func updateReportState(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entry, reportedState prowv1.ProwJobState, pjclientset ctrlruntimeclient.Client, reporterName string) error {
@@ -41,7 +42,7 @@ func updateReportState(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entr
 	}
 	newpj.Status.PrevReportStates[reporterName] = reportedState
 
-	if err := pjclientset.Patch(ctx, newpj, ctrlruntimeclient.MergeFrom(pj)); err != nil {
+	if err := pjclientset.Status().Patch(ctx, newpj, ctrlruntimeclient.MergeFrom(pj)); err != nil {
 		return fmt.Errorf("failed to patch: %w", err)
 	}
 
@@ -66,11 +67,7 @@ func updateReportState(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entr
 
 func UpdateReportStateWithRetries(ctx context.Context, pj *prowv1.ProwJob, log *logrus.Entry, pjclientset ctrlruntimeclient.Client, reporterName string) error {
 	reportState := pj.Status.State
-	log = log.WithFields(logrus.Fields{
-		"prowjob":   pj.Name,
-		"jobName":   pj.Spec.Job,
-		"jobStatus": reportState,
-	})
+	log = log.WithFields(logrusutil.ProwJobFields(pj))
 	// We have to retry here, if we return we lose the information that we already reported this job.
 	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		// Get it first, this is very cheap
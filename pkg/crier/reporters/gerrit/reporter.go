@@ -25,6 +25,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -56,6 +57,13 @@ const (
 	jobReportFormatUrlNotFoundRegex = `^(\S+) (\S+) \(URL_NOT_FOUND\) (\S+)$`
 	jobReportFormatWithoutURLRegex  = `^(\S+) (\S+) (\S+)$`
 	errorLinePrefix                 = "NOTE FROM PROW"
+	// footerSeparator separates the text of the report template of a repo
+	// from the jobs, so that it isn't parsed as jobs.
+	footerSeparator = "---"
+	// reportTagPrefix tags the result comments. Gerrit only shows the latest
+	// comment with the same autogenerated tag by default, so every comment
+	// effectively updates the previous one.
+	reportTagPrefix = "autogenerated:prow"
 	// jobReportHeader expects 4 args. {defaultProwHeader}, {jobs-passed},
 	// {jobs-total}, {additional-text(optional)}.
 	jobReportHeader = "%s %d out of %d pjs passed! 👉 Comment `/retest` to rerun only failed tests (if any), or `/test all` to rerun all tests.%s\n"
@@ -102,16 +110,19 @@ var (
 )
 
 type gerritClient interface {
-	SetReview(instance, id, revision, message string, labels map[string]string) error
+	SetReviewWithTag(instance, id, revision, message, tag string, labels map[string]string) error
 	GetChange(instance, id string, additionalFields ...string) (*gerrit.ChangeInfo, error)
 	ChangeExist(instance, id string) (bool, error)
+	AddToAttentionSet(instance, id, user, reason string) error
+	RemoveFromAttentionSet(instance, id, user, reason string) error
 }
 
 // Client is a gerrit reporter client
 type Client struct {
-	gc          gerritClient
-	pjclientset ctrlruntimeclient.Client
-	prLocks     *criercommonlib.ShardedLock
+	gc                  gerritClient
+	pjclientset         ctrlruntimeclient.Client
+	prLocks             *criercommonlib.ShardedLock
+	orgRepoConfigGetter func() *config.GerritOrgRepoConfigs
 }
 
 // Job is the view of a prowjob scoped for a report
@@ -129,13 +140,15 @@ type JobReport struct {
 	Total   int
 	Message string
 	Header  string
+	// Footer is the text of the report template of the repo, if any.
+	Footer string
 }
 
 // NewReporter returns a reporter client
-func NewReporter(orgRepoConfigGetter func() *config.GerritOrgRepoConfigs, cookiefilePath string, pjclientset ctrlruntimeclient.Client, maxQPS, maxBurst int) (*Client, error) {
+func NewReporter(orgRepoConfigGetter func() *config.GerritOrgRepoConfigs, cookiefilePath string, pjclientset ctrlruntimeclient.Client, maxQPS, maxBurst int, opts ...client.Option) (*Client, error) {
 	// Initialize an empty client, the orgs/repos will be filled in by
 	// ApplyGlobalConfig later.
-	gc, err := client.NewClient(nil, maxQPS, maxBurst)
+	gc, err := client.NewClient(nil, maxQPS, maxBurst, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -152,9 +165,10 @@ func NewReporter(orgRepoConfigGetter func() *config.GerritOrgRepoConfigs, cookie
 	gc.Authenticate(cookiefilePath, "")
 
 	c := &Client{
-		gc:          gc,
-		pjclientset: pjclientset,
-		prLocks:     criercommonlib.NewShardedLock(),
+		gc:                  gc,
+		pjclientset:         pjclientset,
+		prLocks:             criercommonlib.NewShardedLock(),
+		orgRepoConfigGetter: orgRepoConfigGetter,
 	}
 
 	c.prLocks.RunCleanup()
@@ -327,8 +341,37 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 	var pjsToUpdateState []v1.ProwJob
 	var toReportJobs []*v1.ProwJob
 	if pj.ObjectMeta.Labels[gerritReportLabel] == "" && pj.Status.State != v1.AbortedState {
-		toReportJobs = append(toReportJobs, pj)
-		pjsToUpdateState = []v1.ProwJob{*pj}
+		// Jobs that don't vote are reported as soon as they finish, together
+		// with the other finished jobs on the revision that don't vote, so
+		// that the latest comment has the results of all of them.
+		selector := map[string]string{
+			clientGerritRevision: pj.ObjectMeta.Labels[clientGerritRevision],
+			pjTypeLabel:          pj.ObjectMeta.Labels[pjTypeLabel],
+		}
+		if err := c.pjclientset.List(newCtx, &pjsOnRevisionWithSameLabel, ctrlruntimeclient.MatchingLabels(selector)); err != nil {
+			logger.WithError(err).WithField("selector", selector).Errorf("Cannot list prowjob with selector")
+			return nil, nil, err
+		}
+		var found bool
+		for _, pjOnRevision := range pjsOnRevisionWithSameLabel.Items {
+			if pjOnRevision.ObjectMeta.Labels[gerritReportLabel] != "" {
+				continue
+			}
+			found = found || pjOnRevision.Name == pj.Name
+			pjsToUpdateState = append(pjsToUpdateState, pjOnRevision)
+		}
+		if !found {
+			pjsToUpdateState = append(pjsToUpdateState, *pj)
+		}
+		// Jobs that are still running, e.g. because they were retriggered,
+		// are reported once they finish.
+		for _, job := range mostRecentJobs(pjsToUpdateState) {
+			switch job.Status.State {
+			case v1.TriggeredState, v1.PendingState, v1.AbortedState:
+				continue
+			}
+			toReportJobs = append(toReportJobs, job)
+		}
 	} else { // generate an aggregated report
 
 		// list all prowjobs in the patchset matching pj's type (pre- or post-submit)
@@ -342,21 +385,16 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 			logger.WithError(err).WithField("selector", selector).Errorf("Cannot list prowjob with selector")
 			return nil, nil, err
 		}
-
-		mostRecentJob := map[string]*v1.ProwJob{}
-		for idx, pjOnRevisionWithSameLabel := range pjsOnRevisionWithSameLabel.Items {
-			job, ok := mostRecentJob[pjOnRevisionWithSameLabel.Spec.Job]
-			if !ok || job.CreationTimestamp.Time.Before(pjOnRevisionWithSameLabel.CreationTimestamp.Time) {
-				mostRecentJob[pjOnRevisionWithSameLabel.Spec.Job] = &pjsOnRevisionWithSameLabel.Items[idx]
-			}
-			pjsToUpdateState = append(pjsToUpdateState, pjOnRevisionWithSameLabel)
-		}
-		for _, pjOnRevisionWithSameLabel := range mostRecentJob {
-			toReportJobs = append(toReportJobs, pjOnRevisionWithSameLabel)
-		}
+		pjsToUpdateState = pjsOnRevisionWithSameLabel.Items
+		toReportJobs = mostRecentJobs(pjsToUpdateState)
 	}
 	report := GenerateReport(toReportJobs, 0)
-	message := report.Header + report.Message
+	if footer := c.reportFooter(logger, pj, report); footer != "" {
+		// Make room for the footer and the blank line before it.
+		report = GenerateReport(toReportJobs, maxCommentSizeLimit-len(footer)-2)
+		report.Footer = footer
+	}
+	message := report.comment()
 	// report back
 	gerritID := pj.ObjectMeta.Annotations[clientGerritID]
 	gerritInstance := pj.ObjectMeta.Annotations[clientGerritInstance]
@@ -412,7 +450,11 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 	}
 
 	logger.Infof("Reporting to instance %s on id %s with message %s", gerritInstance, gerritID, message)
-	if err := c.gc.SetReview(gerritInstance, gerritID, gerritRevision, message, reviewLabels); err != nil {
+	// outdated is set if the change no longer has the revision, so the jobs
+	// no longer matter for who needs to act on the change.
+	var outdated bool
+	tag := reportTag(pj)
+	if err := c.gc.SetReviewWithTag(gerritInstance, gerritID, gerritRevision, message, tag, reviewLabels); err != nil {
 		logger.WithError(err).WithField("gerrit_id", gerritID).WithField("label", reportLabel).Info("Failed to set review.")
 
 		// It could be that the commit is deleted by the time we want to report.
@@ -444,6 +486,7 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 				// still want the rest of the function continue, so that all
 				// jobs for this revision are marked reported.
 				err = nil
+				outdated = true
 			}
 		}
 
@@ -453,7 +496,7 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 			}
 			// Retry without voting on a label
 			message := fmt.Sprintf("[NOTICE]: Prow Bot cannot access %s label!\n%s", reportLabel, message)
-			if err := c.gc.SetReview(gerritInstance, gerritID, gerritRevision, message, nil); err != nil {
+			if err := c.gc.SetReviewWithTag(gerritInstance, gerritID, gerritRevision, message, tag, nil); err != nil {
 				return nil, nil, err
 			}
 		}
@@ -461,6 +504,10 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 
 	logger.Infof("Review Complete, reported jobs: %s", jobNames(toReportJobs))
 
+	if pj.Spec.Type == v1.PresubmitJob && !outdated {
+		c.updateAttentionSet(logger, gerritInstance, gerritID, change, report, toReportJobs)
+	}
+
 	// If return here, the shardedLock will be released, and other threads that
 	// are from the same PR will still not understand that it's already
 	// reported, as the change of previous report state happens only after the
@@ -491,6 +538,97 @@ func (c *Client) Report(ctx context.Context, logger *logrus.Entry, pj *v1.ProwJo
 	return nil, nil, err
 }
 
+// updateAttentionSet hands the change over like a human reviewer would: failed
+// jobs put the owner in the attention set, as it's their turn to fix the
+// change, while passing jobs remove Prow from it. Failing to update the
+// attention set doesn't fail the report.
+func (c *Client) updateAttentionSet(logger *logrus.Entry, instance, id string, change *gerrit.ChangeInfo, report JobReport, jobs []*v1.ProwJob) {
+	if report.Success == report.Total {
+		if err := c.gc.RemoveFromAttentionSet(instance, id, "self", "Prow jobs passed"); err != nil {
+			logger.WithError(err).Warn("Failed to remove Prow from the attention set.")
+		}
+		return
+	}
+
+	var failed bool
+	for _, job := range jobs {
+		if job.Status.State == v1.FailureState || job.Status.State == v1.ErrorState {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		return
+	}
+	if change == nil {
+		var err error
+		if change, err = c.gc.GetChange(instance, id); err != nil || change == nil {
+			logger.WithError(err).Warn("Unable to get change owner to add to the attention set.")
+			return
+		}
+	}
+	if change.Status == client.Merged {
+		return
+	}
+	if err := c.gc.AddToAttentionSet(instance, id, strconv.Itoa(change.Owner.AccountID), "Prow jobs failed"); err != nil {
+		logger.WithError(err).Warn("Failed to add the change owner to the attention set.")
+	}
+}
+
+// mostRecentJobs returns the most recently created job of each name.
+func mostRecentJobs(pjs []v1.ProwJob) []*v1.ProwJob {
+	mostRecentJob := map[string]*v1.ProwJob{}
+	for idx, pj := range pjs {
+		job, ok := mostRecentJob[pj.Spec.Job]
+		if !ok || job.CreationTimestamp.Time.Before(pj.CreationTimestamp.Time) {
+			mostRecentJob[pj.Spec.Job] = &pjs[idx]
+		}
+	}
+	var res []*v1.ProwJob
+	for _, pj := range mostRecentJob {
+		res = append(res, pj)
+	}
+	return res
+}
+
+// reportTag returns the tag of the result comments of the jobs that report
+// together with the job, so that the latest of them hides the previous ones.
+func reportTag(pj *v1.ProwJob) string {
+	tag := reportTagPrefix + ":" + string(pj.Spec.Type)
+	if label := pj.ObjectMeta.Labels[kube.GerritReportLabel]; label != "" {
+		tag += ":" + label
+	}
+	return tag
+}
+
+// reportFooter executes the report template of the repo of the job, if any.
+// Footers that would leave too little room for the jobs are dropped.
+func (c *Client) reportFooter(logger *logrus.Entry, pj *v1.ProwJob, report JobReport) string {
+	if c.orgRepoConfigGetter == nil || pj.Spec.Refs == nil {
+		return ""
+	}
+	raw := c.orgRepoConfigGetter().ReportTemplate(pj.Spec.Refs.Org, pj.Spec.Refs.Repo)
+	if raw == "" {
+		return ""
+	}
+	tmpl, err := template.New("Report").Parse(raw)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to parse report template.")
+		return ""
+	}
+	var footer strings.Builder
+	if err := tmpl.Execute(&footer, report); err != nil {
+		logger.WithError(err).Warn("Failed to execute report template.")
+		return ""
+	}
+	res := footerMessage(strings.TrimSpace(footer.String()))
+	if len(res) > maxCommentSizeLimit/2 {
+		logger.WithField("size", len(res)).Warn("Report template is too large, skipping it.")
+		return ""
+	}
+	return res
+}
+
 func jobNames(jobs []*v1.ProwJob) []string {
 	names := make([]string, len(jobs))
 	for i, job := range jobs {
@@ -604,6 +742,15 @@ func deserialize(s string, j *Job) error {
 	return fmt.Errorf("Could not deserialize %q to a job", s)
 }
 
+// footerMessage separates a footer from the jobs, or returns an empty
+// string if there is no footer.
+func footerMessage(footer string) string {
+	if footer == "" {
+		return ""
+	}
+	return footerSeparator + "\n" + footer + "\n"
+}
+
 func headerMessageLine(success, total int, additionalText string) string {
 	return fmt.Sprintf(jobReportHeader, defaultProwHeader, success, total, additionalText)
 }
@@ -818,6 +965,10 @@ func ParseReport(message string) *JobReport {
 	var report JobReport
 	report.Header = contents[start] + "\n"
 	for i := start + 1; i < len(contents); i++ {
+		if contents[i] == footerSeparator {
+			report.Footer = strings.Join(contents[i:], "\n")
+			break
+		}
 		if contents[i] == "" || isErrorMessageLine(contents[i]) {
 			continue
 		}
@@ -832,10 +983,19 @@ func ParseReport(message string) *JobReport {
 		}
 		report.Jobs = append(report.Jobs, j)
 	}
-	report.Message = strings.TrimPrefix(message, report.Header+"\n")
+	report.Message = strings.TrimSuffix(strings.TrimPrefix(message, report.Header+"\n"), report.Footer)
 	return &report
 }
 
+// comment returns the comment that reports the jobs.
+func (r JobReport) comment() string {
+	if r.Footer == "" {
+		return r.Header + r.Message
+	}
+	// The blank line makes the separator a horizontal rule in Markdown.
+	return strings.TrimSuffix(r.Header+r.Message, "\n") + "\n\n" + r.Footer
+}
+
 // String implements Stringer for JobReport
 func (r JobReport) String() string {
 	return fmt.Sprintf("%s\n%s", r.Header, r.Message)
@@ -37,6 +37,7 @@ import (
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
 	"sigs.k8s.io/prow/pkg/kube"
 )
@@ -50,13 +51,16 @@ const (
 
 type fgc struct {
 	reportMessage string
+	reportTag     string
 	reportLabel   map[string]string
 	instance      string
 	changes       map[string][]*gerrit.ChangeInfo
 	count         int
+	// attention records the attention set updates as "+user" or "-user"
+	attention []string
 }
 
-func (f *fgc) SetReview(instance, id, revision, message string, labels map[string]string) error {
+func (f *fgc) SetReviewWithTag(instance, id, revision, message, tag string, labels map[string]string) error {
 	if instance != f.instance {
 		return fmt.Errorf("wrong instance: %s", instance)
 	}
@@ -82,6 +86,7 @@ func (f *fgc) SetReview(instance, id, revision, message string, labels map[strin
 		}
 	}
 	f.reportMessage = message
+	f.reportTag = tag
 	if len(labels) > 0 {
 		f.reportLabel = labels
 	}
@@ -105,6 +110,16 @@ func (f *fgc) GetChange(instance, id string, addtionalFields ...string) (*gerrit
 	return nil, nil
 }
 
+func (f *fgc) AddToAttentionSet(instance, id, user, reason string) error {
+	f.attention = append(f.attention, "+"+user)
+	return nil
+}
+
+func (f *fgc) RemoveFromAttentionSet(instance, id, user, reason string) error {
+	f.attention = append(f.attention, "-"+user)
+	return nil
+}
+
 func (f *fgc) ChangeExist(instance, id string) (bool, error) {
 	if f.changes == nil {
 		return false, errors.New("fake client changes is not initialized")
@@ -137,6 +152,7 @@ func TestReport(t *testing.T) {
 		reportExclude     []string
 		expectLabel       map[string]string
 		expectError       bool
+		expectTag         string
 		numExpectedReport int
 	}{
 		{
@@ -852,6 +868,110 @@ func TestReport(t *testing.T) {
 			reportExclude:     []string{"2", "bar"},
 			numExpectedReport: 0,
 		},
+		{
+			name: "2 jobs, both finished, empty labels, should report together, no vote",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						kube.GerritRevision:    "abc",
+						kube.ProwJobTypeLabel:  presubmit,
+						kube.GerritReportLabel: "",
+					},
+					Annotations: map[string]string{
+						kube.GerritID:       "123-abc",
+						kube.GerritInstance: "gerrit",
+					},
+					Name:      "ci-foo",
+					Namespace: "test-pods",
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.SuccessState,
+					URL:   "guber/foo",
+				},
+				Spec: v1.ProwJobSpec{
+					Type: v1.PresubmitJob,
+					Refs: &v1.Refs{
+						Repo: "foo",
+						Pulls: []v1.Pull{
+							{
+								Number: 0,
+							},
+						},
+					},
+					Job:    "ci-foo",
+					Report: true,
+				},
+			},
+			existingPJs: []*v1.ProwJob{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							kube.GerritRevision:    "abc",
+							kube.ProwJobTypeLabel:  presubmit,
+							kube.GerritReportLabel: "",
+						},
+						Annotations: map[string]string{
+							kube.GerritID:       "123-abc",
+							kube.GerritInstance: "gerrit",
+						},
+						Namespace: "test-pods",
+					},
+					Status: v1.ProwJobStatus{
+						State: v1.FailureState,
+						URL:   "guber/bar",
+					},
+					Spec: v1.ProwJobSpec{
+						Type: v1.PresubmitJob,
+						Refs: &v1.Refs{
+							Repo: "foo",
+							Pulls: []v1.Pull{
+								{
+									Number: 0,
+								},
+							},
+						},
+						Job:    "ci-bar",
+						Report: true,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							kube.GerritRevision:    "abc",
+							kube.ProwJobTypeLabel:  presubmit,
+							kube.GerritReportLabel: "Code-Review",
+						},
+						Annotations: map[string]string{
+							kube.GerritID:       "123-abc",
+							kube.GerritInstance: "gerrit",
+						},
+						Namespace: "test-pods",
+					},
+					Status: v1.ProwJobStatus{
+						State: v1.SuccessState,
+						URL:   "guber/voting",
+					},
+					Spec: v1.ProwJobSpec{
+						Type: v1.PresubmitJob,
+						Refs: &v1.Refs{
+							Repo: "foo",
+							Pulls: []v1.Pull{
+								{
+									Number: 0,
+								},
+							},
+						},
+						Job:    "ci-voting",
+						Report: true,
+					},
+				},
+			},
+			expectReport:      true,
+			reportInclude:     []string{"1 out of 2", "ci-foo", "SUCCESS", "guber/foo", "ci-bar", "FAILURE", "guber/bar"},
+			reportExclude:     []string{"ci-voting"},
+			expectTag:         "autogenerated:prow:presubmit",
+			numExpectedReport: 0,
+		},
 		{
 			name: "non-presubmit failures vote zero",
 			pj: &v1.ProwJob{
@@ -2057,6 +2177,9 @@ func TestReport(t *testing.T) {
 			if !reflect.DeepEqual(tc.expectLabel, fgc.reportLabel) {
 				t.Errorf("labels: got %v, want %v", fgc.reportLabel, tc.expectLabel)
 			}
+			if tc.expectTag != "" && fgc.reportTag != tc.expectTag {
+				t.Errorf("tag: got %q, want %q", fgc.reportTag, tc.expectTag)
+			}
 			if len(reportedJobs) != tc.numExpectedReport {
 				t.Errorf("report count: got %d, want %d", len(reportedJobs), tc.numExpectedReport)
 			}
@@ -2064,6 +2187,167 @@ func TestReport(t *testing.T) {
 	}
 }
 
+func TestReportAttentionSet(t *testing.T) {
+	changes := map[string][]*gerrit.ChangeInfo{
+		"gerrit": {
+			{ID: "123-abc", Status: "NEW", Owner: gerrit.AccountInfo{AccountID: 1000}, Revisions: map[string]gerrit.RevisionInfo{"abc": {}}},
+			{ID: "merged", Status: "MERGED", Owner: gerrit.AccountInfo{AccountID: 1000}, Revisions: map[string]gerrit.RevisionInfo{"abc": {}}},
+		},
+	}
+	pj := func(id, revision string, jobType v1.ProwJobType, state v1.ProwJobState) *v1.ProwJob {
+		return &v1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "ci-foo",
+				Labels: map[string]string{
+					kube.GerritRevision:   revision,
+					kube.ProwJobTypeLabel: string(jobType),
+				},
+				Annotations: map[string]string{
+					kube.GerritID:       id,
+					kube.GerritInstance: "gerrit",
+				},
+			},
+			Status: v1.ProwJobStatus{State: state, URL: "guber/foo"},
+			Spec: v1.ProwJobSpec{
+				Type:   jobType,
+				Refs:   &v1.Refs{Repo: "foo", Pulls: []v1.Pull{{Number: 0}}},
+				Job:    "ci-foo",
+				Report: true,
+			},
+		}
+	}
+	var testcases = []struct {
+		name              string
+		pj                *v1.ProwJob
+		expectedAttention []string
+	}{
+		{
+			name:              "failed presubmit adds the owner",
+			pj:                pj("123-abc", "abc", v1.PresubmitJob, v1.FailureState),
+			expectedAttention: []string{"+1000"},
+		},
+		{
+			name:              "errored presubmit adds the owner",
+			pj:                pj("123-abc", "abc", v1.PresubmitJob, v1.ErrorState),
+			expectedAttention: []string{"+1000"},
+		},
+		{
+			name:              "passed presubmit removes Prow",
+			pj:                pj("123-abc", "abc", v1.PresubmitJob, v1.SuccessState),
+			expectedAttention: []string{"-self"},
+		},
+		{
+			name: "failed presubmit of merged change leaves the attention set",
+			pj:   pj("merged", "abc", v1.PresubmitJob, v1.FailureState),
+		},
+		{
+			name: "failed presubmit of outdated revision leaves the attention set",
+			pj:   pj("123-abc", "def", v1.PresubmitJob, v1.FailureState),
+		},
+		{
+			name: "failed postsubmit leaves the attention set",
+			pj:   pj("123-abc", "abc", v1.PostsubmitJob, v1.FailureState),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fgc := &fgc{instance: "gerrit", changes: changes}
+			reporter := &Client{
+				gc:          fgc,
+				pjclientset: fakectrlruntimeclient.NewFakeClient(tc.pj),
+				prLocks:     criercommonlib.NewShardedLock(),
+			}
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expectedAttention, fgc.attention) {
+				t.Errorf("attention set updates: got %v, want %v", fgc.attention, tc.expectedAttention)
+			}
+		})
+	}
+}
+
+func TestReportTemplate(t *testing.T) {
+	changes := map[string][]*gerrit.ChangeInfo{
+		"gerrit": {
+			{ID: "123-abc", Status: "NEW", Revisions: map[string]gerrit.RevisionInfo{"abc": {}}},
+		},
+	}
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ci-foo",
+			Labels: map[string]string{
+				kube.GerritRevision:    "abc",
+				kube.ProwJobTypeLabel:  presubmit,
+				kube.GerritReportLabel: "Verified",
+			},
+			Annotations: map[string]string{
+				kube.GerritID:       "123-abc",
+				kube.GerritInstance: "gerrit",
+			},
+		},
+		Status: v1.ProwJobStatus{State: v1.FailureState, URL: "guber/foo"},
+		Spec: v1.ProwJobSpec{
+			Type:   v1.PresubmitJob,
+			Refs:   &v1.Refs{Org: "gerrit", Repo: "foo", Pulls: []v1.Pull{{Number: 0}}},
+			Job:    "ci-foo",
+			Report: true,
+		},
+	}
+	var testcases = []struct {
+		name           string
+		template       string
+		expectedFooter string
+	}{
+		{
+			name:           "template",
+			template:       "{{range .Jobs}}{{if eq .State \"failure\"}}Rerun {{.Name}} with `/test {{.Name}}`.{{end}}{{end}}",
+			expectedFooter: "---\nRerun ci-foo with `/test ci-foo`.\n",
+		},
+		{
+			name: "no template",
+		},
+		{
+			name:     "template fails",
+			template: "{{.Unknown}}",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fgc := &fgc{instance: "gerrit", changes: changes}
+			reporter := &Client{
+				gc:          fgc,
+				pjclientset: fakectrlruntimeclient.NewFakeClient(pj.DeepCopy()),
+				prLocks:     criercommonlib.NewShardedLock(),
+				orgRepoConfigGetter: func() *config.GerritOrgRepoConfigs {
+					return &config.GerritOrgRepoConfigs{{Org: "gerrit", Repos: []string{"foo"}, ReportTemplate: tc.template}}
+				},
+			}
+			if _, _, err := reporter.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj.DeepCopy()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fgc.reportTag != "autogenerated:prow:presubmit:Verified" {
+				t.Errorf("tag: got %q, want %q", fgc.reportTag, "autogenerated:prow:presubmit:Verified")
+			}
+			report := ParseReport(fgc.reportMessage)
+			if report == nil {
+				t.Fatalf("failed to parse report %q", fgc.reportMessage)
+			}
+			if report.Footer != tc.expectedFooter {
+				t.Errorf("footer: got %q, want %q", report.Footer, tc.expectedFooter)
+			}
+			if len(report.Jobs) != 1 || report.Jobs[0].Name != "ci-foo" {
+				t.Errorf("jobs: got %v, want only ci-foo", report.Jobs)
+			}
+			if tc.expectedFooter != "" && !strings.Contains(fgc.reportMessage, "FAILURE\n\n"+tc.expectedFooter) {
+				t.Errorf("message: got %q, expected a blank line before the footer", fgc.reportMessage)
+			}
+		})
+	}
+}
+
 func TestMultipleWorks(t *testing.T) {
 	samplePJ := v1.ProwJob{
 		ObjectMeta: metav1.ObjectMeta{
@@ -2433,6 +2717,11 @@ Prow Status: 0 out of 2 pjs passed!
 `,
 			expectedJobs: 2,
 		},
+		{
+			name:         "do not parse the footer (Markdown)",
+			comment:      "Prow Status: 0 out of 1 passed\n❌ [bar-job](http://bar-status) FAILURE\n\n---\nSee the flake policy.\n✔️ foo-job SUCCESS",
+			expectedJobs: 1,
+		},
 		{
 			name:         "invalid job state (Markdown)",
 			comment:      "Prow Status: 0 out of 1 passed\n❌ [bar-job](http://bar-status) BANANAS",
@@ -77,10 +77,16 @@ func (c *Client) ShouldReport(_ context.Context, _ *logrus.Entry, pj *v1.ProwJob
 	switch {
 	case pj.Labels[kube.GerritReportLabel] != "":
 		return false // TODO(fejta): opt-in to github reporting
-	case pj.Spec.Type != v1.PresubmitJob && pj.Spec.Type != v1.PostsubmitJob:
-		return false // Report presubmit and postsubmit github jobs for github reporter
+	case pj.Labels[kube.GitLabProjectID] != "":
+		return false // Reported by the gitlab reporter
+	case pj.Annotations[kube.BitbucketInstance] != "":
+		return false // Reported by the bitbucket reporter
 	case c.reportAgent != "" && pj.Spec.Agent != c.reportAgent:
 		return false // Only report for specified agent
+	case pj.Spec.Type == v1.BatchJob && pj.Labels[kube.OnDemandBatchLabel] == "true":
+		// Report batch jobs requested with /test-batch, but not the ones of tide
+	case pj.Spec.Type != v1.PresubmitJob && pj.Spec.Type != v1.PostsubmitJob:
+		return false // Report presubmit and postsubmit github jobs for github reporter
 	}
 
 	return true
@@ -91,6 +97,10 @@ func (c *Client) Report(ctx context.Context, log *logrus.Entry, pj *v1.ProwJob)
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
+	if pj.Spec.Type == v1.BatchJob {
+		return []*v1.ProwJob{pj}, nil, report.ReportBatchComment(ctx, c.gc, *pj)
+	}
+
 	// TODO(krzyzacy): ditch ReportTemplate, and we can drop reference to config.Getter
 	err := report.ReportStatusContext(ctx, c.gc, *pj, c.config().GitHubReporter)
 	if err != nil {
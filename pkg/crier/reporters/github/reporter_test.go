@@ -74,6 +74,34 @@ func TestShouldReport(t *testing.T) {
 			},
 			report: false,
 		},
+		{
+			name: "should report on-demand batch job",
+			pj: v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{kube.OnDemandBatchLabel: "true"},
+				},
+				Spec: v1.ProwJobSpec{
+					Type:   v1.BatchJob,
+					Report: true,
+				},
+			},
+			report: true,
+		},
+		{
+			name: "should not report on-demand batch job of another agent",
+			pj: v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{kube.OnDemandBatchLabel: "true"},
+				},
+				Spec: v1.ProwJobSpec{
+					Type:   v1.BatchJob,
+					Agent:  v1.JenkinsAgent,
+					Report: true,
+				},
+			},
+			reportAgent: v1.KubernetesAgent,
+			report:      false,
+		},
 		{
 			name: "should report presubmit job",
 			pj: v1.ProwJob{
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gitlab implements a reporter interface for GitLab commit statuses.
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/crier/reporters/criercommonlib"
+	"sigs.k8s.io/prow/pkg/gitlab"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+const (
+	// GitLabReporterName is the name for the gitlab reporter
+	GitLabReporterName = "gitlab-reporter"
+
+	// maxDescriptionLength is the limit GitLab enforces on commit status
+	// descriptions.
+	maxDescriptionLength = 255
+)
+
+var stateToCommitState = map[v1.ProwJobState]gitlab.CommitState{
+	v1.TriggeredState: gitlab.CommitStatePending,
+	v1.PendingState:   gitlab.CommitStateRunning,
+	v1.SuccessState:   gitlab.CommitStateSuccess,
+	v1.FailureState:   gitlab.CommitStateFailed,
+	v1.ErrorState:     gitlab.CommitStateFailed,
+	v1.AbortedState:   gitlab.CommitStateCanceled,
+}
+
+type gitlabClient interface {
+	CreateCommitStatus(projectID int, sha string, status gitlab.CommitStatus) error
+}
+
+// Client is a gitlab reporter client
+type Client struct {
+	gc gitlabClient
+}
+
+// NewReporter returns a reporter client
+func NewReporter(gc gitlabClient) *Client {
+	return &Client{gc: gc}
+}
+
+// GetName returns the name of the reporter
+func (c *Client) GetName() string {
+	return GitLabReporterName
+}
+
+// ShouldReport returns if this prowjob should be reported by the gitlab reporter,
+// which only handles jobs triggered for GitLab projects.
+func (c *Client) ShouldReport(_ context.Context, _ *logrus.Entry, pj *v1.ProwJob) bool {
+	if !pj.Spec.Report || pj.Spec.Refs == nil {
+		return false
+	}
+	if pj.Labels[kube.GitLabProjectID] == "" {
+		return false
+	}
+	return pj.Spec.Type == v1.PresubmitJob || pj.Spec.Type == v1.PostsubmitJob
+}
+
+// Report sets a commit status for the ProwJob on the tested commit, which
+// shows up as an external job in the merge request pipeline.
+func (c *Client) Report(_ context.Context, log *logrus.Entry, pj *v1.ProwJob) ([]*v1.ProwJob, *reconcile.Result, error) {
+	projectID, err := strconv.Atoi(pj.Labels[kube.GitLabProjectID])
+	if err != nil {
+		return []*v1.ProwJob{pj}, nil, criercommonlib.UserError(fmt.Errorf("invalid %s label: %w", kube.GitLabProjectID, err))
+	}
+	sha := pj.Spec.Refs.BaseSHA
+	if len(pj.Spec.Refs.Pulls) > 0 {
+		sha = pj.Spec.Refs.Pulls[0].SHA
+	}
+	if sha == "" {
+		return []*v1.ProwJob{pj}, nil, criercommonlib.UserError(errors.New("prowjob has no commit to report on"))
+	}
+	state, ok := stateToCommitState[pj.Status.State]
+	if !ok {
+		return []*v1.ProwJob{pj}, nil, fmt.Errorf("unknown prowjob state: %s", pj.Status.State)
+	}
+
+	description := pj.Status.Description
+	if len(description) > maxDescriptionLength {
+		description = description[:maxDescriptionLength-3] + "..."
+	}
+	status := gitlab.CommitStatus{
+		Name:        pj.Spec.Context,
+		State:       state,
+		TargetURL:   pj.Status.URL,
+		Description: description,
+	}
+	log.WithFields(logrus.Fields{"project": projectID, "sha": sha, "state": state}).Debug("Setting commit status.")
+	if err := c.gc.CreateCommitStatus(projectID, sha, status); err != nil {
+		return []*v1.ProwJob{pj}, nil, fmt.Errorf("failed to set commit status: %w", err)
+	}
+	return []*v1.ProwJob{pj}, nil, nil
+}
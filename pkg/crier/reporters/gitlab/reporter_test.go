@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/gitlab"
+	"sigs.k8s.io/prow/pkg/gitlab/fakegitlab"
+	"sigs.k8s.io/prow/pkg/kube"
+)
+
+func TestShouldReport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       *v1.ProwJob
+		expected bool
+	}{
+		{
+			name: "gitlab presubmit is reported",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{kube.GitLabProjectID: "42"}},
+				Spec:       v1.ProwJobSpec{Type: v1.PresubmitJob, Report: true, Refs: &v1.Refs{}},
+			},
+			expected: true,
+		},
+		{
+			name: "github presubmit is not reported",
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{Type: v1.PresubmitJob, Report: true, Refs: &v1.Refs{}},
+			},
+		},
+		{
+			name: "gitlab periodic is not reported",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{kube.GitLabProjectID: "42"}},
+				Spec:       v1.ProwJobSpec{Type: v1.PeriodicJob, Report: true, Refs: &v1.Refs{}},
+			},
+		},
+		{
+			name: "job with reporting disabled is not reported",
+			pj: &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{kube.GitLabProjectID: "42"}},
+				Spec:       v1.ProwJobSpec{Type: v1.PresubmitJob, Refs: &v1.Refs{}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewReporter(fakegitlab.NewFakeClient())
+			if got := c.ShouldReport(context.Background(), logrus.NewEntry(logrus.StandardLogger()), tc.pj); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	testCases := []struct {
+		name        string
+		state       v1.ProwJobState
+		pulls       []v1.Pull
+		projectID   string
+		expectedSHA string
+		expected    []gitlab.CommitStatus
+		expectErr   bool
+	}{
+		{
+			name:        "pending presubmit reports running on the head commit",
+			state:       v1.PendingState,
+			pulls:       []v1.Pull{{Number: 1, SHA: "head-sha"}},
+			projectID:   "42",
+			expectedSHA: "head-sha",
+			expected: []gitlab.CommitStatus{{
+				Name: "unit", State: gitlab.CommitStateRunning, TargetURL: "https://prow/unit", Description: "Job triggered.",
+			}},
+		},
+		{
+			name:        "failed postsubmit reports on the base commit",
+			state:       v1.FailureState,
+			projectID:   "42",
+			expectedSHA: "base-sha",
+			expected: []gitlab.CommitStatus{{
+				Name: "unit", State: gitlab.CommitStateFailed, TargetURL: "https://prow/unit", Description: "Job triggered.",
+			}},
+		},
+		{
+			name:        "aborted job reports canceled",
+			state:       v1.AbortedState,
+			pulls:       []v1.Pull{{Number: 1, SHA: "head-sha"}},
+			projectID:   "42",
+			expectedSHA: "head-sha",
+			expected: []gitlab.CommitStatus{{
+				Name: "unit", State: gitlab.CommitStateCanceled, TargetURL: "https://prow/unit", Description: "Job triggered.",
+			}},
+		},
+		{
+			name:      "invalid project id is an error",
+			state:     v1.SuccessState,
+			projectID: "group/project",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			glc := fakegitlab.NewFakeClient()
+			c := NewReporter(glc)
+			pj := &v1.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{kube.GitLabProjectID: tc.projectID}},
+				Spec: v1.ProwJobSpec{
+					Type:    v1.PresubmitJob,
+					Context: "unit",
+					Report:  true,
+					Refs:    &v1.Refs{Org: "group", Repo: "project", BaseSHA: "base-sha", Pulls: tc.pulls},
+				},
+				Status: v1.ProwJobStatus{State: tc.state, URL: "https://prow/unit", Description: "Job triggered."},
+			}
+			_, _, err := c.Report(context.Background(), logrus.NewEntry(logrus.StandardLogger()), pj)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got %v", tc.expectErr, err)
+			}
+			if tc.expectErr {
+				return
+			}
+			if diff := cmp.Diff(tc.expected, glc.Statuses[fakegitlab.CommitKey(42, tc.expectedSHA)]); diff != "" {
+				t.Errorf("unexpected commit statuses: %s", diff)
+			}
+		})
+	}
+}
@@ -57,6 +57,9 @@ type ReportMessage struct {
 	JobType prowapi.ProwJobType  `json:"job_type"`
 	JobName string               `json:"job_name"`
 	Message string               `json:"message,omitempty"`
+	// ResourceUsage is the CPU and memory usage of the pod of the job, if plank
+	// sampled it, to analyze the resource requests of jobs.
+	ResourceUsage *prowapi.ResourceUsage `json:"resource_usage,omitempty"`
 }
 
 // Client is a reporter client fed to crier controller
@@ -193,5 +196,7 @@ func (c *Client) generateMessageFromPJ(pj *prowapi.ProwJob) *ReportMessage {
 		JobType: pj.Spec.Type,
 		JobName: pj.Spec.Job,
 		Message: pj.Status.Description,
+
+		ResourceUsage: pj.Status.ResourceUsage,
 	}
 }
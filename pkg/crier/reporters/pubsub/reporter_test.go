@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
@@ -127,6 +128,50 @@ func TestGenerateMessageFromPJ(t *testing.T) {
 				JobName: "test1",
 			},
 		},
+		{
+			name: "Prowjob with resource usage should report it",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test1",
+					Labels: map[string]string{
+						PubSubProjectLabel: testPubSubProjectName,
+						PubSubTopicLabel:   testPubSubTopicName,
+						PubSubRunIDLabel:   testPubSubRunID,
+					},
+				},
+				Status: prowapi.ProwJobStatus{
+					State: prowapi.SuccessState,
+					URL:   "guber/test1",
+					ResourceUsage: &prowapi.ResourceUsage{
+						PeakCPU:    resource.MustParse("1500m"),
+						PeakMemory: resource.MustParse("2Gi"),
+						CPUSeconds: 600,
+						Samples:    20,
+					},
+				},
+				Spec: prowapi.ProwJobSpec{
+					Type: prowapi.PeriodicJob,
+					Job:  "test1",
+				},
+			},
+			jobURLPrefix: "guber/",
+			expectedMessage: &ReportMessage{
+				Project: testPubSubProjectName,
+				Topic:   testPubSubTopicName,
+				RunID:   testPubSubRunID,
+				Status:  prowapi.SuccessState,
+				URL:     "guber/test1",
+				GCSPath: "gs://test1",
+				JobType: prowapi.PeriodicJob,
+				JobName: "test1",
+				ResourceUsage: &prowapi.ResourceUsage{
+					PeakCPU:    resource.MustParse("1500m"),
+					PeakMemory: resource.MustParse("2Gi"),
+					CPUSeconds: 600,
+					Samples:    20,
+				},
+			},
+		},
 		{
 			name: "Prowjob has no pubsub runID label, should return a message with runid empty",
 			pj: &prowapi.ProwJob{
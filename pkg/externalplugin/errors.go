@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalplugin
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reasonError annotates an error with the Error_Reason reported to hook.
+type reasonError struct {
+	reason Error_Reason
+	err    error
+}
+
+func (e *reasonError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reasonError) Unwrap() error {
+	return e.err
+}
+
+// RetryableError marks err as transient. Hook may redeliver the event.
+func RetryableError(err error) error {
+	return &reasonError{reason: Error_RETRYABLE, err: err}
+}
+
+// PermanentError marks err as permanent. Hook will not redeliver the event.
+func PermanentError(err error) error {
+	return &reasonError{reason: Error_PERMANENT, err: err}
+}
+
+// InvalidEventError marks err as caused by an event the plugin cannot handle.
+func InvalidEventError(err error) error {
+	return &reasonError{reason: Error_INVALID_EVENT, err: err}
+}
+
+var reasonCodes = map[Error_Reason]codes.Code{
+	Error_REASON_UNSPECIFIED: codes.Internal,
+	Error_INVALID_EVENT:      codes.InvalidArgument,
+	Error_RETRYABLE:          codes.Unavailable,
+	Error_PERMANENT:          codes.FailedPrecondition,
+}
+
+// toStatus converts err into a gRPC status error carrying an Error detail.
+func toStatus(err error) error {
+	reason := Error_REASON_UNSPECIFIED
+	var re *reasonError
+	if errors.As(err, &re) {
+		reason = re.reason
+	}
+	st := status.New(reasonCodes[reason], err.Error())
+	if withDetails, detailsErr := st.WithDetails(&Error{Reason: reason, Message: err.Error()}); detailsErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// ErrorFromStatus extracts the Error detail from an error returned by an
+// ExternalPlugin RPC. Errors without details are reported as retryable when
+// the plugin was unavailable and with an unspecified reason otherwise.
+func ErrorFromStatus(err error) *Error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return &Error{Reason: Error_REASON_UNSPECIFIED, Message: err.Error()}
+	}
+	for _, d := range st.Details() {
+		if e, ok := d.(*Error); ok {
+			return e
+		}
+	}
+	reason := Error_REASON_UNSPECIFIED
+	if st.Code() == codes.Unavailable || st.Code() == codes.DeadlineExceeded {
+		reason = Error_RETRYABLE
+	}
+	return &Error{Reason: reason, Message: st.Message()}
+}
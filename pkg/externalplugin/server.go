@@ -0,0 +1,274 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package externalplugin implements the gRPC protocol hook uses to deliver
+// events to external plugins, and a server library that external plugins can
+// use instead of validating and parsing raw GitHub webhooks themselves.
+package externalplugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// ProtocolVersion is the version of the external plugin protocol implemented
+// by this package.
+const ProtocolVersion uint32 = 1
+
+// RawEventHandler handles an event whose payload has not been parsed.
+type RawEventHandler func(*logrus.Entry, *HandleEventRequest) error
+
+// IssueCommentEventHandler is a type alias for a function handling issue comment events.
+type IssueCommentEventHandler func(*logrus.Entry, github.IssueCommentEvent) error
+
+// IssueEventHandler is a type alias for a function handling issue events.
+type IssueEventHandler func(*logrus.Entry, github.IssueEvent) error
+
+// PullRequestEventHandler is a type alias for a function handling pull request events.
+type PullRequestEventHandler func(*logrus.Entry, github.PullRequestEvent) error
+
+// PushEventHandler is a type alias for a function handling push events.
+type PushEventHandler func(*logrus.Entry, github.PushEvent) error
+
+// ReviewEventHandler is a type alias for a function handling review events.
+type ReviewEventHandler func(*logrus.Entry, github.ReviewEvent) error
+
+// ReviewCommentEventHandler is a type alias for a function handling review comment events.
+type ReviewCommentEventHandler func(*logrus.Entry, github.ReviewCommentEvent) error
+
+// StatusEventHandler is a type alias for a function handling status events.
+type StatusEventHandler func(*logrus.Entry, github.StatusEvent) error
+
+type eventHandler struct {
+	actions sets.Set[string]
+	fn      RawEventHandler
+}
+
+// Server implements the ExternalPlugin gRPC service. Handlers are registered
+// per event type and hook only delivers the events that have a handler.
+type Server struct {
+	UnimplementedExternalPluginServer
+
+	name string
+	log  *logrus.Entry
+
+	handlers map[string]eventHandler
+
+	lock    sync.RWMutex
+	configs map[string]map[string]string
+
+	grpcServer *grpc.Server
+}
+
+// NewServer creates a server for the external plugin with the given name.
+func NewServer(name string, log *logrus.Entry) *Server {
+	return &Server{
+		name:     name,
+		log:      log.WithField("external-plugin", name),
+		handlers: map[string]eventHandler{},
+		configs:  map[string]map[string]string{},
+	}
+}
+
+// RegisterEventHandler registers a handler for the given event type. If any
+// actions are given, only events with one of those actions are delivered.
+func (s *Server) RegisterEventHandler(eventType string, fn RawEventHandler, actions ...string) {
+	s.handlers[eventType] = eventHandler{actions: sets.New[string](actions...), fn: fn}
+}
+
+// RegisterIssueCommentEventHandler registers an IssueCommentEventHandler.
+func (s *Server) RegisterIssueCommentEventHandler(fn IssueCommentEventHandler, actions ...string) {
+	s.RegisterEventHandler("issue_comment", func(l *logrus.Entry, req *HandleEventRequest) error {
+		var ice github.IssueCommentEvent
+		if err := json.Unmarshal(req.Payload, &ice); err != nil {
+			return InvalidEventError(err)
+		}
+		ice.GUID = req.EventGuid
+		return fn(l, ice)
+	}, actions...)
+}
+
+// RegisterIssueEventHandler registers an IssueEventHandler.
+func (s *Server) RegisterIssueEventHandler(fn IssueEventHandler, actions ...string) {
+	s.RegisterEventHandler("issues", func(l *logrus.Entry, req *HandleEventRequest) error {
+		var ie github.IssueEvent
+		if err := json.Unmarshal(req.Payload, &ie); err != nil {
+			return InvalidEventError(err)
+		}
+		ie.GUID = req.EventGuid
+		return fn(l, ie)
+	}, actions...)
+}
+
+// RegisterPullRequestEventHandler registers a PullRequestEventHandler.
+func (s *Server) RegisterPullRequestEventHandler(fn PullRequestEventHandler, actions ...string) {
+	s.RegisterEventHandler("pull_request", func(l *logrus.Entry, req *HandleEventRequest) error {
+		var pr github.PullRequestEvent
+		if err := json.Unmarshal(req.Payload, &pr); err != nil {
+			return InvalidEventError(err)
+		}
+		pr.GUID = req.EventGuid
+		return fn(l, pr)
+	}, actions...)
+}
+
+// RegisterPushEventHandler registers a PushEventHandler.
+func (s *Server) RegisterPushEventHandler(fn PushEventHandler) {
+	s.RegisterEventHandler("push", func(l *logrus.Entry, req *HandleEventRequest) error {
+		var pe github.PushEvent
+		if err := json.Unmarshal(req.Payload, &pe); err != nil {
+			return InvalidEventError(err)
+		}
+		pe.GUID = req.EventGuid
+		return fn(l, pe)
+	})
+}
+
+// RegisterReviewEventHandler registers a ReviewEventHandler.
+func (s *Server) RegisterReviewEventHandler(fn ReviewEventHandler, actions ...string) {
+	s.RegisterEventHandler("pull_request_review", func(l *logrus.Entry, req *HandleEventRequest) error {
+		var re github.ReviewEvent
+		if err := json.Unmarshal(req.Payload, &re); err != nil {
+			return InvalidEventError(err)
+		}
+		re.GUID = req.EventGuid
+		return fn(l, re)
+	}, actions...)
+}
+
+// RegisterReviewCommentEventHandler registers a ReviewCommentEventHandler.
+func (s *Server) RegisterReviewCommentEventHandler(fn ReviewCommentEventHandler, actions ...string) {
+	s.RegisterEventHandler("pull_request_review_comment", func(l *logrus.Entry, req *HandleEventRequest) error {
+		var rce github.ReviewCommentEvent
+		if err := json.Unmarshal(req.Payload, &rce); err != nil {
+			return InvalidEventError(err)
+		}
+		rce.GUID = req.EventGuid
+		return fn(l, rce)
+	}, actions...)
+}
+
+// RegisterStatusEventHandler registers a StatusEventHandler.
+func (s *Server) RegisterStatusEventHandler(fn StatusEventHandler) {
+	s.RegisterEventHandler("status", func(l *logrus.Entry, req *HandleEventRequest) error {
+		var se github.StatusEvent
+		if err := json.Unmarshal(req.Payload, &se); err != nil {
+			return InvalidEventError(err)
+		}
+		se.GUID = req.EventGuid
+		return fn(l, se)
+	})
+}
+
+// ConfigFor returns the plugin configuration pushed by hook for the given
+// repo. Repo level settings take precedence over org level settings.
+func (s *Server) ConfigFor(org, repo string) map[string]string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	merged := map[string]string{}
+	for k, v := range s.configs[org] {
+		merged[k] = v
+	}
+	for k, v := range s.configs[org+"/"+repo] {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Handshake implements ExternalPluginServer.
+func (s *Server) Handshake(_ context.Context, req *HandshakeRequest) (*HandshakeResponse, error) {
+	if req.ProtocolVersion == 0 {
+		return nil, status.Error(codes.InvalidArgument, "protocol version must be set")
+	}
+	version := ProtocolVersion
+	if req.ProtocolVersion < version {
+		version = req.ProtocolVersion
+	}
+	resp := &HandshakeResponse{Name: s.name, ProtocolVersion: version}
+	for eventType, h := range s.handlers {
+		resp.Filters = append(resp.Filters, &EventFilter{EventType: eventType, Actions: sets.List(h.actions)})
+	}
+	sort.Slice(resp.Filters, func(i, j int) bool { return resp.Filters[i].EventType < resp.Filters[j].EventType })
+	return resp, nil
+}
+
+// UpdateConfig implements ExternalPluginServer.
+func (s *Server) UpdateConfig(_ context.Context, req *UpdateConfigRequest) (*UpdateConfigResponse, error) {
+	configs := map[string]map[string]string{}
+	for _, c := range req.Configs {
+		configs[c.OrgRepo] = c.Config
+	}
+	s.lock.Lock()
+	s.configs = configs
+	s.lock.Unlock()
+	s.log.WithField("org-repos", len(configs)).Info("Updated plugin configuration.")
+	return &UpdateConfigResponse{}, nil
+}
+
+// HandleEvent implements ExternalPluginServer.
+func (s *Server) HandleEvent(_ context.Context, req *HandleEventRequest) (*HandleEventResponse, error) {
+	l := s.log.WithFields(logrus.Fields{
+		"event-type":        req.EventType,
+		github.EventGUID:    req.EventGuid,
+		github.OrgLogField:  req.Org,
+		github.RepoLogField: req.Repo,
+	})
+	h, ok := s.handlers[req.EventType]
+	if !ok || (h.actions.Len() > 0 && !h.actions.Has(req.Action)) {
+		return nil, toStatus(InvalidEventError(errors.New("no handler registered for event " + eventName(req))))
+	}
+	if err := h.fn(l, req); err != nil {
+		l.WithError(err).Error("Error handling event.")
+		return nil, toStatus(err)
+	}
+	return &HandleEventResponse{}, nil
+}
+
+func eventName(req *HandleEventRequest) string {
+	if req.Action == "" {
+		return req.EventType
+	}
+	return strings.Join([]string{req.EventType, req.Action}, "/")
+}
+
+// Serve registers the server with a new gRPC server and serves on the
+// listener until GracefulStop is called.
+func (s *Server) Serve(lis net.Listener) error {
+	s.grpcServer = grpc.NewServer()
+	RegisterExternalPluginServer(s.grpcServer, s)
+	return s.grpcServer.Serve(lis)
+}
+
+// GracefulStop stops accepting new events and waits for in-flight events to
+// be handled.
+func (s *Server) GracefulStop() {
+	if s.grpcServer != nil {
+		s.grpcServer.GracefulStop()
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalplugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+func TestHandshake(t *testing.T) {
+	s := NewServer("coffeemachine", logrus.WithField("test", t.Name()))
+	s.RegisterIssueCommentEventHandler(func(*logrus.Entry, github.IssueCommentEvent) error { return nil }, "created")
+	s.RegisterPushEventHandler(func(*logrus.Entry, github.PushEvent) error { return nil })
+
+	testCases := []struct {
+		name        string
+		version     uint32
+		expected    *HandshakeResponse
+		expectedErr bool
+	}{
+		{
+			name:    "same version",
+			version: ProtocolVersion,
+			expected: &HandshakeResponse{
+				Name:            "coffeemachine",
+				ProtocolVersion: ProtocolVersion,
+				Filters: []*EventFilter{
+					{EventType: "issue_comment", Actions: []string{"created"}},
+					{EventType: "push"},
+				},
+			},
+		},
+		{
+			name:    "newer hook negotiates down",
+			version: ProtocolVersion + 1,
+			expected: &HandshakeResponse{
+				Name:            "coffeemachine",
+				ProtocolVersion: ProtocolVersion,
+				Filters: []*EventFilter{
+					{EventType: "issue_comment", Actions: []string{"created"}},
+					{EventType: "push"},
+				},
+			},
+		},
+		{
+			name:        "missing version",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := s.Handshake(context.Background(), &HandshakeRequest{ProtocolVersion: tc.version})
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expected, resp, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected response (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	var handled []string
+	s := NewServer("coffeemachine", logrus.WithField("test", t.Name()))
+	s.RegisterIssueCommentEventHandler(func(_ *logrus.Entry, ice github.IssueCommentEvent) error {
+		handled = append(handled, ice.GUID+":"+ice.Comment.Body)
+		if ice.Comment.Body == "/brew" {
+			return RetryableError(errors.New("out of beans"))
+		}
+		return nil
+	}, "created")
+
+	testCases := []struct {
+		name           string
+		req            *HandleEventRequest
+		expectedReason Error_Reason
+		expectedErr    bool
+		expected       []string
+	}{
+		{
+			name:     "handled",
+			req:      &HandleEventRequest{EventType: "issue_comment", EventGuid: "1", Action: "created", Payload: []byte(`{"comment":{"body":"/coffee"}}`)},
+			expected: []string{"1:/coffee"},
+		},
+		{
+			name:           "unregistered action",
+			req:            &HandleEventRequest{EventType: "issue_comment", EventGuid: "2", Action: "edited", Payload: []byte(`{}`)},
+			expectedErr:    true,
+			expectedReason: Error_INVALID_EVENT,
+		},
+		{
+			name:           "unregistered event type",
+			req:            &HandleEventRequest{EventType: "push", EventGuid: "3", Payload: []byte(`{}`)},
+			expectedErr:    true,
+			expectedReason: Error_INVALID_EVENT,
+		},
+		{
+			name:           "malformed payload",
+			req:            &HandleEventRequest{EventType: "issue_comment", EventGuid: "4", Action: "created", Payload: []byte(`{`)},
+			expectedErr:    true,
+			expectedReason: Error_INVALID_EVENT,
+		},
+		{
+			name:           "retryable handler error",
+			req:            &HandleEventRequest{EventType: "issue_comment", EventGuid: "5", Action: "created", Payload: []byte(`{"comment":{"body":"/brew"}}`)},
+			expectedErr:    true,
+			expectedReason: Error_RETRYABLE,
+			expected:       []string{"5:/brew"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handled = nil
+			_, err := s.HandleEvent(context.Background(), tc.req)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				if reason := ErrorFromStatus(err).Reason; reason != tc.expectedReason {
+					t.Errorf("expected reason %s, got %s", tc.expectedReason, reason)
+				}
+			}
+			if diff := cmp.Diff(tc.expected, handled); diff != "" {
+				t.Errorf("unexpected handled events (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigFor(t *testing.T) {
+	s := NewServer("coffeemachine", logrus.WithField("test", t.Name()))
+	if _, err := s.UpdateConfig(context.Background(), &UpdateConfigRequest{Configs: []*RepoConfig{
+		{OrgRepo: "org", Config: map[string]string{"roast": "dark", "size": "small"}},
+		{OrgRepo: "org/repo", Config: map[string]string{"size": "large"}},
+	}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(map[string]string{"roast": "dark", "size": "large"}, s.ConfigFor("org", "repo")); diff != "" {
+		t.Errorf("unexpected repo config (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"roast": "dark", "size": "small"}, s.ConfigFor("org", "other")); diff != "" {
+		t.Errorf("unexpected org config (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flagutil
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+
+	"sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/config/secret"
+)
+
+// BitbucketOptions holds options for interacting with a Bitbucket Server
+// instance or Bitbucket Cloud.
+type BitbucketOptions struct {
+	Endpoint  string
+	TokenPath string
+	Cloud     bool
+}
+
+// AddFlags injects Bitbucket options into the given FlagSet.
+func (o *BitbucketOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "bitbucket-endpoint", "", "Bitbucket Server or Data Center instance to use, e.g. https://bitbucket.example.com. Empty means Bitbucket support is disabled, unless --bitbucket-cloud is set.")
+	fs.StringVar(&o.TokenPath, "bitbucket-token-path", "", "Path to the file containing the Bitbucket HTTP access token.")
+	fs.BoolVar(&o.Cloud, "bitbucket-cloud", false, "Use Bitbucket Cloud rather than Bitbucket Server. --bitbucket-endpoint defaults to "+bitbucket.CloudEndpoint+" then.")
+}
+
+// Validate validates Bitbucket options.
+func (o *BitbucketOptions) Validate(_ bool) error {
+	if !o.Enabled() {
+		return nil
+	}
+	if _, err := url.ParseRequestURI(o.endpoint()); err != nil {
+		return fmt.Errorf("--bitbucket-endpoint %q is invalid: %w", o.endpoint(), err)
+	}
+	if o.TokenPath == "" {
+		return errors.New("--bitbucket-token-path is required when --bitbucket-endpoint or --bitbucket-cloud is set")
+	}
+	return nil
+}
+
+// Enabled returns whether a Bitbucket instance was configured.
+func (o *BitbucketOptions) Enabled() bool {
+	return o.endpoint() != ""
+}
+
+// endpoint returns the endpoint of the API, which defaults to the one of
+// Bitbucket Cloud.
+func (o *BitbucketOptions) endpoint() string {
+	if o.Cloud && o.Endpoint == "" {
+		return bitbucket.CloudEndpoint
+	}
+	return o.Endpoint
+}
+
+// BitbucketClient returns a Bitbucket client.
+func (o *BitbucketOptions) BitbucketClient(dryRun bool) (bitbucket.Client, error) {
+	if !o.Enabled() {
+		return nil, errors.New("empty --bitbucket-endpoint, can not create a client")
+	}
+	if err := secret.Add(o.TokenPath); err != nil {
+		return nil, fmt.Errorf("failed to get --bitbucket-token-path: %w", err)
+	}
+	if o.Cloud {
+		if dryRun {
+			return bitbucket.NewDryRunCloudClient(o.endpoint(), secret.GetTokenGenerator(o.TokenPath)), nil
+		}
+		return bitbucket.NewCloudClient(o.endpoint(), secret.GetTokenGenerator(o.TokenPath)), nil
+	}
+	if dryRun {
+		return bitbucket.NewDryRunClient(o.endpoint(), secret.GetTokenGenerator(o.TokenPath)), nil
+	}
+	return bitbucket.NewClient(o.endpoint(), secret.GetTokenGenerator(o.TokenPath)), nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package flagutil
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 
@@ -46,6 +47,9 @@ type ConfigOptions struct {
 	// Moonraker is the centralized Inrepconfig Caching Service. Using this flag
 	// overrides the use of the local InRepoConfigCache.
 	MoonrakerAddress string
+	// MoonrakerGRPCAddress is the address of the gRPC API of Moonraker. It is
+	// mutually exclusive with MoonrakerAddress.
+	MoonrakerGRPCAddress string
 }
 
 func (o *ConfigOptions) AddFlags(fs *flag.FlagSet) {
@@ -63,15 +67,25 @@ func (o *ConfigOptions) AddFlags(fs *flag.FlagSet) {
 	fs.IntVar(&o.InRepoConfigCacheSize, "in-repo-config-cache-size", 200, "Cache size for ProwYAMLs read from in-repo configs.")
 	fs.StringVar(&o.InRepoConfigCacheDirBase, "cache-dir-base", "", "Directory where the repo cache should be mounted.")
 	fs.StringVar(&o.MoonrakerAddress, "moonraker-address", "", "full HTTP address (domain and port) of moonraker service")
+	fs.StringVar(&o.MoonrakerGRPCAddress, "moonraker-grpc-address", "", "address (domain and port) of the gRPC API of moonraker service. Mutually exclusive with --moonraker-address")
 }
 
 func (o *ConfigOptions) Validate(_ bool) error {
 	if o.ConfigPath == "" {
 		return fmt.Errorf("--%s is mandatory", o.ConfigPathFlagName)
 	}
+	if o.MoonrakerAddress != "" && o.MoonrakerGRPCAddress != "" {
+		return errors.New("--moonraker-address and --moonraker-grpc-address are mutually exclusive")
+	}
 	return nil
 }
 
+// MoonrakerEnabled returns true if inrepoconfig should be retrieved from
+// Moonraker instead of the local InRepoConfigCache.
+func (o *ConfigOptions) MoonrakerEnabled() bool {
+	return o.MoonrakerAddress != "" || o.MoonrakerGRPCAddress != ""
+}
+
 func (o *ConfigOptions) ValidateConfigOptional() error {
 	if o.JobConfigPath != "" && o.ConfigPath == "" {
 		return fmt.Errorf("if --%s is given, --%s must be given as well", o.JobConfigPathFlagName, o.ConfigPathFlagName)
@@ -16,17 +16,37 @@ limitations under the License.
 
 package flagutil
 
-import "flag"
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type GerritOptions struct {
 	GerritFields
 	defaults GerritFields
+
+	// hostThrottles are host=qps,burst throttles of single Gerrit hosts.
+	hostThrottles       Strings
+	parsedHostThrottles map[string]GerritThrottle
+
+	// TokenCommand prints the token to authenticate to Gerrit with, and
+	// takes precedence over cookie files and token paths.
+	TokenCommand         string
+	TokenRefreshInterval time.Duration
 }
 
 type GerritFields struct {
 	MaxQPS, MaxBurst int // No throttling when unset.
 }
 
+// GerritThrottle is the throttle of a single Gerrit host.
+type GerritThrottle struct {
+	MaxQPS, MaxBurst int
+}
+
 func (o *GerritOptions) SetDefaultThrottle(MaxQPS, MaxBurst int) {
 	o.defaults.MaxQPS = MaxQPS
 	o.defaults.MaxBurst = MaxBurst
@@ -35,8 +55,53 @@ func (o *GerritOptions) SetDefaultThrottle(MaxQPS, MaxBurst int) {
 func (o *GerritOptions) AddFlags(fs *flag.FlagSet) {
 	fs.IntVar(&o.MaxQPS, "gerrit-max-qps", o.defaults.MaxQPS, "The maximum allowed queries per second to the Gerrit API from this component.")
 	fs.IntVar(&o.MaxBurst, "gerrit-max-burst", o.defaults.MaxBurst, "The maximum allowed burst size of queries to the Gerrit API from this component.")
+	fs.Var(&o.hostThrottles, "gerrit-host-throttle", "host=qps,burst to throttle the queries to a single Gerrit host separately from the other hosts, which share --gerrit-max-qps and --gerrit-max-burst. Can be passed multiple times.")
+	fs.StringVar(&o.TokenCommand, "gerrit-token-command", "", "Command that prints the token to authenticate to Gerrit with, e.g. 'gcloud auth print-access-token'. Takes precedence over cookie files and token paths.")
+	fs.DurationVar(&o.TokenRefreshInterval, "gerrit-token-refresh-interval", 5*time.Minute, "How long to use the token printed by --gerrit-token-command before running it again. Tokens rejected by Gerrit are refreshed right away.")
 }
 
 func (o *GerritOptions) Validate(dryrun bool) error {
+	for _, hostThrottle := range o.hostThrottles.Strings() {
+		host, throttle, ok := strings.Cut(hostThrottle, "=")
+		if !ok || host == "" {
+			return fmt.Errorf("--gerrit-host-throttle %q must look like host=qps,burst", hostThrottle)
+		}
+		qps, burst, ok := strings.Cut(throttle, ",")
+		if !ok {
+			return fmt.Errorf("--gerrit-host-throttle %q must look like host=qps,burst", hostThrottle)
+		}
+		var parsed GerritThrottle
+		var err error
+		if parsed.MaxQPS, err = strconv.Atoi(qps); err != nil || parsed.MaxQPS <= 0 {
+			return fmt.Errorf("--gerrit-host-throttle %q has an invalid qps %q", hostThrottle, qps)
+		}
+		if parsed.MaxBurst, err = strconv.Atoi(burst); err != nil || parsed.MaxBurst <= 0 {
+			return fmt.Errorf("--gerrit-host-throttle %q has an invalid burst %q", hostThrottle, burst)
+		}
+		if o.parsedHostThrottles == nil {
+			o.parsedHostThrottles = map[string]GerritThrottle{}
+		}
+		if _, ok := o.parsedHostThrottles[host]; ok {
+			return fmt.Errorf("--gerrit-host-throttle set more than once for host %q", host)
+		}
+		o.parsedHostThrottles[host] = parsed
+	}
+	if o.TokenCommand != "" && o.TokenRefreshInterval <= 0 {
+		return fmt.Errorf("--gerrit-token-refresh-interval must be positive, got %v", o.TokenRefreshInterval)
+	}
 	return nil
 }
+
+// HostThrottles returns the throttles of single Gerrit hosts by host.
+func (o *GerritOptions) HostThrottles() map[string]GerritThrottle {
+	return o.parsedHostThrottles
+}
+
+// TokenCommandArgs returns the command that prints the token to authenticate
+// to Gerrit with, if any.
+func (o *GerritOptions) TokenCommandArgs() []string {
+	if o.TokenCommand == "" {
+		return nil
+	}
+	return strings.Fields(o.TokenCommand)
+}
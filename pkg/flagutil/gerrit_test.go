@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flagutil
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGerritOptions(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		args                 []string
+		expectedThrottles    map[string]GerritThrottle
+		expectedTokenCommand []string
+		expectedRefresh      time.Duration
+		expectedErrorString  string
+	}{
+		{
+			name:            "defaults",
+			expectedRefresh: 5 * time.Minute,
+		},
+		{
+			name: "host throttles",
+			args: []string{
+				"--gerrit-host-throttle=android-review.googlesource.com=5,10",
+				"--gerrit-host-throttle=https://chromium-review.googlesource.com=1,2",
+			},
+			expectedThrottles: map[string]GerritThrottle{
+				"android-review.googlesource.com":          {MaxQPS: 5, MaxBurst: 10},
+				"https://chromium-review.googlesource.com": {MaxQPS: 1, MaxBurst: 2},
+			},
+			expectedRefresh: 5 * time.Minute,
+		},
+		{
+			name:                 "token command",
+			args:                 []string{"--gerrit-token-command=gcloud auth print-access-token", "--gerrit-token-refresh-interval=1m"},
+			expectedTokenCommand: []string{"gcloud", "auth", "print-access-token"},
+			expectedRefresh:      time.Minute,
+		},
+		{
+			name:                "host throttle without throttle",
+			args:                []string{"--gerrit-host-throttle=android-review.googlesource.com"},
+			expectedErrorString: `--gerrit-host-throttle "android-review.googlesource.com" must look like host=qps,burst`,
+		},
+		{
+			name:                "host throttle without burst",
+			args:                []string{"--gerrit-host-throttle=android-review.googlesource.com=5"},
+			expectedErrorString: `--gerrit-host-throttle "android-review.googlesource.com=5" must look like host=qps,burst`,
+		},
+		{
+			name:                "host throttle with invalid qps",
+			args:                []string{"--gerrit-host-throttle=android-review.googlesource.com=0,10"},
+			expectedErrorString: `--gerrit-host-throttle "android-review.googlesource.com=0,10" has an invalid qps "0"`,
+		},
+		{
+			name: "host throttled twice",
+			args: []string{
+				"--gerrit-host-throttle=android-review.googlesource.com=5,10",
+				"--gerrit-host-throttle=android-review.googlesource.com=1,2",
+			},
+			expectedErrorString: `--gerrit-host-throttle set more than once for host "android-review.googlesource.com"`,
+		},
+		{
+			name:                "token command without refresh interval",
+			args:                []string{"--gerrit-token-command=gcloud auth print-access-token", "--gerrit-token-refresh-interval=0"},
+			expectedErrorString: "--gerrit-token-refresh-interval must be positive, got 0s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var o GerritOptions
+			fs := flag.NewFlagSet(tc.name, flag.ContinueOnError)
+			o.AddFlags(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+			var actualErrMsg string
+			if err := o.Validate(false); err != nil {
+				actualErrMsg = err.Error()
+			}
+			if actualErrMsg != tc.expectedErrorString {
+				t.Fatalf("expected error %q, got %q", tc.expectedErrorString, actualErrMsg)
+			}
+			if tc.expectedErrorString != "" {
+				return
+			}
+			if !reflect.DeepEqual(tc.expectedThrottles, o.HostThrottles()) {
+				t.Errorf("expected host throttles %v, got %v", tc.expectedThrottles, o.HostThrottles())
+			}
+			if !reflect.DeepEqual(tc.expectedTokenCommand, o.TokenCommandArgs()) {
+				t.Errorf("expected token command %v, got %v", tc.expectedTokenCommand, o.TokenCommandArgs())
+			}
+			if o.TokenRefreshInterval != tc.expectedRefresh {
+				t.Errorf("expected refresh interval %v, got %v", tc.expectedRefresh, o.TokenRefreshInterval)
+			}
+		})
+	}
+}
@@ -55,6 +55,27 @@ type GitHubOptions struct {
 	OrgThrottlers       Strings
 	parsedOrgThrottlers map[string]throttlerSettings
 
+	OrgEndpoints       Strings
+	parsedOrgEndpoints map[string]orgEndpointSettings
+
+	// CommitSigningKeyPath is the private key git clients sign commits with
+	CommitSigningKeyPath string
+	CommitSigningFormat  string
+
+	// GitSSH makes git clients clone and push over SSH rather than HTTPS
+	GitSSH bool
+	// GitSSHKeyPath is the private key git clients authenticate with over SSH
+	GitSSHKeyPath        string
+	GitSSHAgentSocket    string
+	GitSSHKnownHostsPath string
+	GitSSHAcceptNewHosts bool
+
+	// Budget configures when the client defers requests of low priority
+	Budget github.BudgetOptions
+	// CircuitBreaker configures when the client fails requests fast during
+	// GitHub outages
+	CircuitBreaker github.CircuitBreakerOptions
+
 	// These will only be set after a github client was retrieved for the first time
 	tokenGenerator github.TokenGenerator
 	userGenerator  github.UserGenerator
@@ -65,6 +86,13 @@ type GitHubOptions struct {
 	max404Retries  int
 	initialDelay   time.Duration
 	maxSleepTime   time.Duration
+
+	noServerErrorRetries bool
+	noTimeoutRetries     bool
+	retryPolicies        Strings
+	parsedRetryPolicies  []github.EndpointRetryPolicy
+	conditionalCacheSize int
+	auditSink            string
 }
 
 type throttlerSettings struct {
@@ -72,6 +100,15 @@ type throttlerSettings struct {
 	burst        int
 }
 
+// orgEndpointSettings point an org at another GitHub instance, e.g. GitHub
+// Enterprise, than the one the other orgs live on.
+type orgEndpointSettings struct {
+	host            string
+	endpoint        string
+	graphqlEndpoint string
+	tokenPath       string
+}
+
 // flagParams struct is used indirectly by users of this package to customize
 // the common flags behavior, such as providing their own default values
 // or suppressing presence of certain flags.
@@ -120,6 +157,7 @@ func (o *GitHubOptions) addFlags(fs *flag.FlagSet, paramFuncs ...FlagParameter)
 			Host:            github.DefaultHost,
 			endpoint:        NewStrings(github.DefaultAPIEndpoint),
 			graphqlEndpoint: github.DefaultGraphQLEndpoint,
+			CircuitBreaker:  github.CircuitBreakerOptions{Cooldown: github.DefaultCircuitBreakerCooldown},
 		},
 	}
 
@@ -142,11 +180,29 @@ func (o *GitHubOptions) addFlags(fs *flag.FlagSet, paramFuncs ...FlagParameter)
 		fs.Var(&o.OrgThrottlers, "github-throttle-org", "Throttler settings for a specific org in org:hourlyTokens:burst format. Can be passed multiple times. Only valid when using github apps auth.")
 	}
 
+	fs.Var(&o.OrgEndpoints, "github-org-endpoint", "GitHub instance of an org that doesn't live on --github-host, e.g. on GitHub Enterprise, in org=host,api-endpoint,graphql-endpoint,token-path format. Can be passed multiple times. Not valid when using github apps auth.")
+	fs.StringVar(&o.CommitSigningKeyPath, "git-commit-signing-key-path", defaults.CommitSigningKeyPath, "Path to the file containing the private key to sign the commits of git clients with. The key must belong to the committer and must not be protected with a passphrase.")
+	fs.StringVar(&o.CommitSigningFormat, "git-commit-signing-format", string(gitv2.SigningFormatGPG), "Format of the key in --git-commit-signing-key-path, gpg or ssh.")
+	fs.BoolVar(&o.GitSSH, "git-ssh", defaults.GitSSH, "Clone and push over SSH rather than HTTPS, for git servers that don't support token auth.")
+	fs.StringVar(&o.GitSSHKeyPath, "git-ssh-key-path", defaults.GitSSHKeyPath, "Path to the file containing the private key git clients authenticate with over SSH. Requires --git-ssh. The key must not be protected with a passphrase.")
+	fs.StringVar(&o.GitSSHAgentSocket, "git-ssh-agent-socket", defaults.GitSSHAgentSocket, "Socket of the SSH agent git clients authenticate with. Requires --git-ssh. Defaults to $SSH_AUTH_SOCK.")
+	fs.StringVar(&o.GitSSHKnownHostsPath, "git-ssh-known-hosts-path", defaults.GitSSHKnownHostsPath, "Path to the known_hosts file to verify the host keys of git servers against. Requires --git-ssh. Leave empty for the SSH config of the user.")
+	fs.BoolVar(&o.GitSSHAcceptNewHosts, "git-ssh-accept-new-host-keys", defaults.GitSSHAcceptNewHosts, "Record the host keys of git servers missing from --git-ssh-known-hosts-path rather than refusing to connect to them.")
 	fs.DurationVar(&o.maxRequestTime, "github-client.request-timeout", github.DefaultMaxSleepTime, "Timeout for any single request to the GitHub API.")
 	fs.IntVar(&o.maxRetries, "github-client.max-retries", github.DefaultMaxRetries, "Maximum number of retries that will be used for a failing request to the GitHub API.")
 	fs.IntVar(&o.max404Retries, "github-client.max-404-retries", github.DefaultMax404Retries, "Maximum number of retries that will be used for a 404-ing request to the GitHub API.")
 	fs.DurationVar(&o.maxSleepTime, "github-client.backoff-timeout", github.DefaultMaxSleepTime, "Largest allowable Retry-After time for requests to the GitHub API.")
 	fs.DurationVar(&o.initialDelay, "github-client.initial-delay", github.DefaultInitialDelay, "Initial delay before retries begin for requests to the GitHub API.")
+	fs.BoolVar(&o.noServerErrorRetries, "github-client.disable-server-error-retries", false, "Fail requests to the GitHub API that 5XX rather than retrying them.")
+	fs.BoolVar(&o.noTimeoutRetries, "github-client.disable-timeout-retries", false, "Fail requests to the GitHub API that time out rather than retrying them.")
+	fs.Var(&o.retryPolicies, "github-client.retry-policy", "Retry policy for matching requests to the GitHub API in '[METHOD] PATH key=value,...' format, e.g. 'PUT /repos/*/*/pulls/*/merge max-retries=1,server-errors=false'. The keys are max-retries, max-404-retries, server-errors and timeouts. Can be passed multiple times, the first matching one wins.")
+	fs.IntVar(&o.CircuitBreaker.FailureThreshold, "github-client.circuit-breaker-threshold", defaults.CircuitBreaker.FailureThreshold, "Fail requests to the GitHub API fast once this many requests in a row failed with a 5XX or a connection problem. Zero disables the circuit breaker.")
+	fs.DurationVar(&o.CircuitBreaker.Cooldown, "github-client.circuit-breaker-cooldown", defaults.CircuitBreaker.Cooldown, "How long requests to the GitHub API fail fast once the circuit breaker opens, before a single request probes whether GitHub recovered.")
+	fs.IntVar(&o.conditionalCacheSize, "github-client.conditional-cache-size", 0, "Number of responses to GET requests the GitHub client keeps to revalidate with their ETag, which doesn't count against the rate limit. Useful when not using ghproxy, which does the same. Zero disables it.")
+	fs.StringVar(&o.auditSink, "github-client.audit-sink", "", "Where to record every write the GitHub client makes, e.g. comments, labels, merges and statuses, with the component, event and latency: 'log' to log them, or the path of a file to append them to as JSON lines. Disabled if empty.")
+	fs.IntVar(&o.Budget.LowThreshold, "github-client.budget-low-threshold", defaults.Budget.LowThreshold, "Defer low priority requests to the GitHub API until the rate limit resets while fewer requests than this remain. Zero never defers them.")
+	fs.IntVar(&o.Budget.NormalThreshold, "github-client.budget-normal-threshold", defaults.Budget.NormalThreshold, "Defer normal priority requests to the GitHub API until the rate limit resets while fewer requests than this remain. Zero never defers them. Must not be larger than --github-client.budget-low-threshold.")
+	fs.DurationVar(&o.Budget.MaxDeferral, "github-client.budget-max-deferral", defaults.Budget.MaxDeferral, "Fail requests to the GitHub API rather than deferring them for longer than this. Zero defers them until the rate limit resets.")
 }
 
 func (o *GitHubOptions) parseOrgThrottlers() error {
@@ -199,6 +255,58 @@ func (o *GitHubOptions) parseOrgThrottlers() error {
 	return utilerrors.NewAggregate(errs)
 }
 
+func (o *GitHubOptions) parseOrgEndpoints() error {
+	o.parsedOrgEndpoints = nil
+	if len(o.OrgEndpoints.vals) == 0 {
+		return nil
+	}
+
+	if o.AppID != "" {
+		return errors.New("--github-org-endpoint was passed, but client uses apps auth")
+	}
+
+	o.parsedOrgEndpoints = make(map[string]orgEndpointSettings, len(o.OrgEndpoints.vals))
+	var errs []error
+	for _, orgEndpoint := range o.OrgEndpoints.vals {
+		org, value, found := strings.Cut(orgEndpoint, "=")
+		parts := strings.Split(value, ",")
+		if !found || org == "" || len(parts) != 4 {
+			errs = append(errs, fmt.Errorf("--github-org-endpoint=%s is not in org=host,api-endpoint,graphql-endpoint,token-path format", orgEndpoint))
+			continue
+		}
+		settings := orgEndpointSettings{host: parts[0], endpoint: parts[1], graphqlEndpoint: parts[2], tokenPath: parts[3]}
+		if settings.host == "" || settings.tokenPath == "" {
+			errs = append(errs, fmt.Errorf("--github-org-endpoint=%s: host and token-path must not be empty", orgEndpoint))
+			continue
+		}
+		if _, err := url.ParseRequestURI(settings.endpoint); err != nil {
+			errs = append(errs, fmt.Errorf("--github-org-endpoint=%s: invalid api-endpoint URI: %q", orgEndpoint, settings.endpoint))
+			continue
+		}
+		if _, err := url.ParseRequestURI(settings.graphqlEndpoint); err != nil {
+			errs = append(errs, fmt.Errorf("--github-org-endpoint=%s: invalid graphql-endpoint URI: %q", orgEndpoint, settings.graphqlEndpoint))
+			continue
+		}
+		org = strings.ToLower(org)
+		if _, alreadyExists := o.parsedOrgEndpoints[org]; alreadyExists {
+			errs = append(errs, fmt.Errorf("got multiple --github-org-endpoint for the %s org", org))
+			continue
+		}
+		o.parsedOrgEndpoints[org] = settings
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// HostForOrg returns the host of the GitHub instance an org lives on, which is
+// --github-host unless the org has a --github-org-endpoint.
+func (o *GitHubOptions) HostForOrg(org string) string {
+	if settings, ok := o.parsedOrgEndpoints[strings.ToLower(org)]; ok {
+		return settings.host
+	}
+	return o.Host
+}
+
 // Validate validates GitHub options. Note that validate updates the GitHubOptions
 // to add default values for TokenPath and graphqlEndpoint.
 func (o *GitHubOptions) Validate(bool) error {
@@ -240,6 +348,46 @@ func (o *GitHubOptions) Validate(bool) error {
 		return errors.New("--github-allowed-burst must not be larger than --github-hourly-tokens")
 	}
 
+	if o.conditionalCacheSize < 0 {
+		return errors.New("--github-client.conditional-cache-size must not be negative")
+	}
+
+	if err := o.Budget.Validate(); err != nil {
+		return fmt.Errorf("invalid --github-client.budget-* flags: %w", err)
+	}
+
+	if err := o.CircuitBreaker.Validate(); err != nil {
+		return fmt.Errorf("invalid --github-client.circuit-breaker-* flags: %w", err)
+	}
+
+	o.parsedRetryPolicies = nil
+	for _, value := range o.retryPolicies.Strings() {
+		policy, err := github.ParseEndpointRetryPolicy(value)
+		if err != nil {
+			return fmt.Errorf("invalid --github-client.retry-policy: %w", err)
+		}
+		o.parsedRetryPolicies = append(o.parsedRetryPolicies, policy)
+	}
+
+	if o.CommitSigningKeyPath != "" {
+		switch gitv2.SigningFormat(o.CommitSigningFormat) {
+		case gitv2.SigningFormatGPG, gitv2.SigningFormatSSH:
+		default:
+			return fmt.Errorf("invalid --git-commit-signing-format %q, must be gpg or ssh", o.CommitSigningFormat)
+		}
+	}
+
+	if !o.GitSSH && (o.GitSSHKeyPath != "" || o.GitSSHAgentSocket != "" || o.GitSSHKnownHostsPath != "") {
+		return errors.New("--git-ssh-key-path, --git-ssh-agent-socket and --git-ssh-known-hosts-path require --git-ssh")
+	}
+	if o.GitSSHAcceptNewHosts && o.GitSSHKnownHostsPath == "" {
+		return errors.New("--git-ssh-accept-new-host-keys requires --git-ssh-known-hosts-path")
+	}
+
+	if err := o.parseOrgEndpoints(); err != nil {
+		return err
+	}
+
 	return o.parseOrgThrottlers()
 }
 
@@ -272,6 +420,20 @@ func (o *GitHubOptions) githubClient(dryRun bool) (github.Client, error) {
 		options.GetToken = secret.GetTokenGenerator(o.TokenPath)
 	}
 
+	if len(o.parsedOrgEndpoints) > 0 {
+		options.OrgEndpoints = make(map[string]github.OrgEndpoint, len(o.parsedOrgEndpoints))
+		for org, settings := range o.parsedOrgEndpoints {
+			if err := secret.Add(settings.tokenPath); err != nil {
+				return nil, fmt.Errorf("failed to add GitHub token for org %s to secret agent: %w", org, err)
+			}
+			options.OrgEndpoints[org] = github.OrgEndpoint{
+				Bases:           []string{settings.endpoint},
+				GraphqlEndpoint: settings.graphqlEndpoint,
+				GetToken:        secret.GetTokenGenerator(settings.tokenPath),
+			}
+		}
+	}
+
 	if o.AppPrivateKeyPath != "" {
 		apk, err := o.appPrivateKeyGenerator()
 		if err != nil {
@@ -305,15 +467,34 @@ func (o *GitHubOptions) githubClient(dryRun bool) (github.Client, error) {
 // baseClientOptions populates client options that are derived from flags without processing
 func (o *GitHubOptions) baseClientOptions() github.ClientOptions {
 	return github.ClientOptions{
-		Censor:          secret.Censor,
-		AppID:           o.AppID,
-		GraphqlEndpoint: o.graphqlEndpoint,
-		Bases:           o.endpoint.Strings(),
-		MaxRequestTime:  o.maxRequestTime,
-		InitialDelay:    o.initialDelay,
-		MaxSleepTime:    o.maxSleepTime,
-		MaxRetries:      o.maxRetries,
-		Max404Retries:   o.max404Retries,
+		Censor:               secret.Censor,
+		AppID:                o.AppID,
+		GraphqlEndpoint:      o.graphqlEndpoint,
+		Bases:                o.endpoint.Strings(),
+		MaxRequestTime:       o.maxRequestTime,
+		InitialDelay:         o.initialDelay,
+		MaxSleepTime:         o.maxSleepTime,
+		MaxRetries:           o.maxRetries,
+		Max404Retries:        o.max404Retries,
+		NoServerErrorRetries: o.noServerErrorRetries,
+		NoTimeoutRetries:     o.noTimeoutRetries,
+		RetryPolicies:        o.parsedRetryPolicies,
+		CircuitBreaker:       o.CircuitBreaker,
+		Budget:               o.Budget,
+		ConditionalCacheSize: o.conditionalCacheSize,
+		AuditSink:            o.githubAuditSink(),
+	}
+}
+
+// githubAuditSink returns the sink the client records its writes in, if any.
+func (o *GitHubOptions) githubAuditSink() github.AuditSink {
+	switch o.auditSink {
+	case "":
+		return nil
+	case "log":
+		return github.NewLogAuditSink(logrus.WithField("client", "github"))
+	default:
+		return github.NewFileAuditSink(o.auditSink)
 	}
 }
 
@@ -345,6 +526,12 @@ func (o *GitHubOptions) GitClientFactory(cookieFilePath string, cacheDir *string
 	if cacheDir != nil && *cacheDir != "" {
 		opts.CacheDirBase = cacheDir
 	}
+	if len(o.parsedOrgEndpoints) > 0 {
+		opts.OrgHosts = make(map[string]string, len(o.parsedOrgEndpoints))
+		for org, settings := range o.parsedOrgEndpoints {
+			opts.OrgHosts[org] = settings.host
+		}
+	}
 
 	if cookieFilePath == "" && (o.TokenPath != "" || o.AppPrivateKeyPath != "") {
 		// Make a client with auth suitable for GitHub
@@ -357,6 +544,27 @@ func (o *GitHubOptions) GitClientFactory(cookieFilePath string, cacheDir *string
 	}
 	// If the client is for Gerrit we're already set with the cookie filepath.
 
+	if o.CommitSigningKeyPath != "" {
+		if err := secret.Add(o.CommitSigningKeyPath); err != nil {
+			return nil, fmt.Errorf("failed to add the commit signing key to secret agent: %w", err)
+		}
+		opts.SigningKey = secret.GetTokenGenerator(o.CommitSigningKeyPath)
+		opts.SigningFormat = gitv2.SigningFormat(o.CommitSigningFormat)
+	}
+
+	if o.GitSSH {
+		opts.UseSSH = &o.GitSSH
+		opts.SSHAgentSocket = o.GitSSHAgentSocket
+		opts.SSHKnownHostsPath = o.GitSSHKnownHostsPath
+		opts.SSHAcceptNewHostKeys = &o.GitSSHAcceptNewHosts
+		if o.GitSSHKeyPath != "" {
+			if err := secret.Add(o.GitSSHKeyPath); err != nil {
+				return nil, fmt.Errorf("failed to add the git SSH key to secret agent: %w", err)
+			}
+			opts.SSHKey = secret.GetTokenGenerator(o.GitSSHKeyPath)
+		}
+	}
+
 	gitClientFactory, err := gitv2.NewClientFactory(opts.Apply)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create git client factory: %w", err)
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -57,6 +59,50 @@ func TestGitHubOptions_Validate(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "valid commit signing format",
+			in: &GitHubOptions{
+				CommitSigningKeyPath: "/etc/signing/key",
+				CommitSigningFormat:  "ssh",
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+		},
+		{
+			name: "invalid commit signing format, returns error",
+			in: &GitHubOptions{
+				CommitSigningKeyPath: "/etc/signing/key",
+				CommitSigningFormat:  "x509",
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+			expectedErr:             true,
+		},
+		{
+			name: "valid git ssh options",
+			in: &GitHubOptions{
+				GitSSH:               true,
+				GitSSHKeyPath:        "/etc/ssh-key/key",
+				GitSSHKnownHostsPath: "/etc/ssh-key/known_hosts",
+				GitSSHAcceptNewHosts: true,
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+		},
+		{
+			name: "git ssh key without --git-ssh, returns error",
+			in: &GitHubOptions{
+				GitSSHKeyPath: "/etc/ssh-key/key",
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+			expectedErr:             true,
+		},
+		{
+			name: "accepting new host keys without known hosts, returns error",
+			in: &GitHubOptions{
+				GitSSH:               true,
+				GitSSHAcceptNewHosts: true,
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+			expectedErr:             true,
+		},
 		{
 			name: "both --github-hourly-tokens and --github-allowed-burst are zero: no error",
 			in: &GitHubOptions{
@@ -98,6 +144,36 @@ func TestGitHubOptions_Validate(t *testing.T) {
 			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
 			expectedErr:             false,
 		},
+		{
+			name: "budget thresholds defer low priority requests first: no error",
+			in: &GitHubOptions{
+				Budget: github.BudgetOptions{LowThreshold: 1000, NormalThreshold: 100},
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+		},
+		{
+			name: "budget threshold of normal priority is larger than of low priority: error",
+			in: &GitHubOptions{
+				Budget: github.BudgetOptions{LowThreshold: 100, NormalThreshold: 1000},
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+			expectedErr:             true,
+		},
+		{
+			name: "circuit breaker with a cooldown: no error",
+			in: &GitHubOptions{
+				CircuitBreaker: github.CircuitBreakerOptions{FailureThreshold: 10, Cooldown: time.Minute},
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+		},
+		{
+			name: "circuit breaker without a cooldown: error",
+			in: &GitHubOptions{
+				CircuitBreaker: github.CircuitBreakerOptions{FailureThreshold: 10},
+			},
+			expectedGraphqlEndpoint: github.DefaultGraphQLEndpoint,
+			expectedErr:             true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -289,3 +365,162 @@ func TestOrgThottlerOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryPolicyOptions(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name       string
+		parameters []string
+
+		expectedErrorMsg            string
+		expectedParsedRetryPolicies []github.EndpointRetryPolicy
+	}{
+		{
+			name: "No retry policy, success",
+		},
+		{
+			name:       "Valid retry policies, success",
+			parameters: []string{"--github-client.retry-policy=put /repos/*/*/pulls/*/merge max-retries=1,server-errors=false", "--github-client.retry-policy=/search/* timeouts=false"},
+			expectedParsedRetryPolicies: []github.EndpointRetryPolicy{
+				{Method: "PUT", Path: "/repos/*/*/pulls/*/merge", RetryPolicy: github.RetryPolicy{MaxRetries: 1, NoServerErrorRetries: true}},
+				{Path: "/search/*", RetryPolicy: github.RetryPolicy{NoTimeoutRetries: true}},
+			},
+		},
+		{
+			name:             "Invalid format, no settings",
+			parameters:       []string{"--github-client.retry-policy=/repos/*/*"},
+			expectedErrorMsg: `invalid --github-client.retry-policy: retry policy "/repos/*/*" is not of the form "[METHOD] PATH key=value,..."`,
+		},
+		{
+			name:             "Invalid, unknown setting",
+			parameters:       []string{"--github-client.retry-policy=/repos/*/* retries=1"},
+			expectedErrorMsg: `invalid --github-client.retry-policy: unknown retry policy setting "retries"`,
+		},
+		{
+			name:             "Invalid, negative retries",
+			parameters:       []string{"--github-client.retry-policy=/repos/*/* max-retries=-1"},
+			expectedErrorMsg: "invalid --github-client.retry-policy: retries must not be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet(tc.name, flag.ContinueOnError)
+			opts := &GitHubOptions{}
+			opts.AddFlags(fs)
+			if err := fs.Parse(tc.parameters); err != nil {
+				t.Fatalf("flag parsing failed: %v", err)
+			}
+
+			var actualErrMsg string
+			if actualErr := opts.Validate(false); actualErr != nil {
+				actualErrMsg = actualErr.Error()
+			}
+			if actualErrMsg != tc.expectedErrorMsg {
+				t.Fatalf("actual error %s does not match expected error %s", actualErrMsg, tc.expectedErrorMsg)
+			}
+			if actualErrMsg != "" {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expectedParsedRetryPolicies, opts.parsedRetryPolicies); diff != "" {
+				t.Errorf("expected retry policies differ from actual: %s", diff)
+			}
+		})
+	}
+}
+
+func TestOrgEndpointOptions(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name       string
+		parameters []string
+		appsAuth   bool
+
+		expectedErrorMsg           string
+		expectedParsedOrgEndpoints map[string]orgEndpointSettings
+	}{
+		{
+			name: "No org endpoint, success",
+		},
+		{
+			name:       "Valid settings for multiple orgs, success",
+			parameters: []string{"--github-org-endpoint=Enterprise=ghe.example.com,https://ghe.example.com/api/v3,https://ghe.example.com/api/graphql,/etc/ghe/token", "--github-org-endpoint=other=ghe2.example.com,http://ghproxy-ghe2,https://ghe2.example.com/api/graphql,/etc/ghe2/token"},
+			expectedParsedOrgEndpoints: map[string]orgEndpointSettings{
+				"enterprise": {host: "ghe.example.com", endpoint: "https://ghe.example.com/api/v3", graphqlEndpoint: "https://ghe.example.com/api/graphql", tokenPath: "/etc/ghe/token"},
+				"other":      {host: "ghe2.example.com", endpoint: "http://ghproxy-ghe2", graphqlEndpoint: "https://ghe2.example.com/api/graphql", tokenPath: "/etc/ghe2/token"},
+			},
+		},
+		{
+			name:             "Invalid format, no org",
+			parameters:       []string{"--github-org-endpoint=ghe.example.com,https://ghe.example.com/api/v3,https://ghe.example.com/api/graphql,/etc/ghe/token"},
+			expectedErrorMsg: "--github-org-endpoint=ghe.example.com,https://ghe.example.com/api/v3,https://ghe.example.com/api/graphql,/etc/ghe/token is not in org=host,api-endpoint,graphql-endpoint,token-path format",
+		},
+		{
+			name:             "Invalid format, no token path",
+			parameters:       []string{"--github-org-endpoint=enterprise=ghe.example.com,https://ghe.example.com/api/v3,https://ghe.example.com/api/graphql"},
+			expectedErrorMsg: "--github-org-endpoint=enterprise=ghe.example.com,https://ghe.example.com/api/v3,https://ghe.example.com/api/graphql is not in org=host,api-endpoint,graphql-endpoint,token-path format",
+		},
+		{
+			name:             "Invalid, relative api endpoint",
+			parameters:       []string{"--github-org-endpoint=enterprise=ghe.example.com,api/v3,https://ghe.example.com/api/graphql,/etc/ghe/token"},
+			expectedErrorMsg: `--github-org-endpoint=enterprise=ghe.example.com,api/v3,https://ghe.example.com/api/graphql,/etc/ghe/token: invalid api-endpoint URI: "api/v3"`,
+		},
+		{
+			name: "Invalid, multiple settings for same org",
+			parameters: []string{
+				"--github-org-endpoint=enterprise=ghe.example.com,https://ghe.example.com/api/v3,https://ghe.example.com/api/graphql,/etc/ghe/token",
+				"--github-org-endpoint=Enterprise=ghe.example.com,https://ghe.example.com/api/v3,https://ghe.example.com/api/graphql,/etc/ghe/token",
+			},
+			expectedErrorMsg: "got multiple --github-org-endpoint for the enterprise org",
+		},
+		{
+			name:             "Invalid, apps auth",
+			parameters:       []string{"--github-org-endpoint=enterprise=ghe.example.com,https://ghe.example.com/api/v3,https://ghe.example.com/api/graphql,/etc/ghe/token"},
+			appsAuth:         true,
+			expectedErrorMsg: "--github-org-endpoint was passed, but client uses apps auth",
+		},
+	}
+
+	exportOrgEndpointSettings := cmp.Exporter(func(t reflect.Type) bool {
+		return t == reflect.TypeOf(orgEndpointSettings{})
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet(tc.name, flag.ContinueOnError)
+			opts := &GitHubOptions{}
+			opts.AddFlags(fs)
+			if err := fs.Parse(tc.parameters); err != nil {
+				t.Fatalf("flag parsing failed: %v", err)
+			}
+			if tc.appsAuth {
+				opts.AppID = "10"
+				opts.AppPrivateKeyPath = "/test/path"
+			}
+
+			var actualErrMsg string
+			if actualErr := opts.Validate(false); actualErr != nil {
+				actualErrMsg = actualErr.Error()
+			}
+			if actualErrMsg != tc.expectedErrorMsg {
+				t.Fatalf("actual error %s does not match expected error %s", actualErrMsg, tc.expectedErrorMsg)
+			}
+			if actualErrMsg != "" {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expectedParsedOrgEndpoints, opts.parsedOrgEndpoints, exportOrgEndpointSettings); diff != "" {
+				t.Errorf("expected org endpoints differ from actual: %s", diff)
+			}
+			for org, settings := range tc.expectedParsedOrgEndpoints {
+				if host := opts.HostForOrg(strings.ToUpper(org)); host != settings.host {
+					t.Errorf("expected org %s to live on %s, got %s", org, settings.host, host)
+				}
+			}
+			if host := opts.HostForOrg("unrouted"); host != opts.Host {
+				t.Errorf("expected an org without endpoint to live on %s, got %s", opts.Host, host)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flagutil
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+
+	"sigs.k8s.io/prow/pkg/config/secret"
+	"sigs.k8s.io/prow/pkg/gitlab"
+)
+
+// GitLabOptions holds options for interacting with a GitLab instance.
+type GitLabOptions struct {
+	Endpoint  string
+	TokenPath string
+}
+
+// AddFlags injects GitLab options into the given FlagSet.
+func (o *GitLabOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "gitlab-endpoint", "", "GitLab instance to use, e.g. https://gitlab.com. Empty means GitLab support is disabled.")
+	fs.StringVar(&o.TokenPath, "gitlab-token-path", "", "Path to the file containing the GitLab access token.")
+}
+
+// Validate validates GitLab options.
+func (o *GitLabOptions) Validate(_ bool) error {
+	if o.Endpoint == "" {
+		return nil
+	}
+	if _, err := url.ParseRequestURI(o.Endpoint); err != nil {
+		return fmt.Errorf("--gitlab-endpoint %q is invalid: %w", o.Endpoint, err)
+	}
+	if o.TokenPath == "" {
+		return errors.New("--gitlab-token-path is required when --gitlab-endpoint is set")
+	}
+	return nil
+}
+
+// Enabled returns whether a GitLab instance was configured.
+func (o *GitLabOptions) Enabled() bool {
+	return o.Endpoint != ""
+}
+
+// GitLabClient returns a GitLab client.
+func (o *GitLabOptions) GitLabClient(dryRun bool) (gitlab.Client, error) {
+	if o.Endpoint == "" {
+		return nil, errors.New("empty --gitlab-endpoint, can not create a client")
+	}
+	if err := secret.Add(o.TokenPath); err != nil {
+		return nil, fmt.Errorf("failed to get --gitlab-token-path: %w", err)
+	}
+	if dryRun {
+		return gitlab.NewDryRunClient(o.Endpoint, secret.GetTokenGenerator(o.TokenPath)), nil
+	}
+	return gitlab.NewClient(o.Endpoint, secret.GetTokenGenerator(o.TokenPath)), nil
+}
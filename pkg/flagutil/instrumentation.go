@@ -17,6 +17,7 @@ limitations under the License.
 package flagutil
 
 import (
+	"errors"
 	"flag"
 	"time"
 )
@@ -42,6 +43,20 @@ type InstrumentationOptions struct {
 	ProfileMemory bool
 	// MemoryProfileInterval is the interval at which memory profiles should be dumped
 	MemoryProfileInterval time.Duration
+
+	// LogLevelTokenPath is the path to the token that authenticates requests
+	// changing the log level, which are refused if it is unset
+	LogLevelTokenPath string
+
+	// ProfilingTokenPath is the path to the token that authenticates requests
+	// to the profiling endpoints, which are unauthenticated if it is unset
+	ProfilingTokenPath string
+	// MutexProfileFraction is the rate of mutex contention events reported in
+	// the mutex profile, zero disables it
+	MutexProfileFraction int
+	// BlockProfileRate is the rate of blocking events in nanoseconds reported
+	// in the block profile, zero disables it
+	BlockProfileRate int
 }
 
 // DefaultInstrumentationOptions returns an initialized options struct, mostly for use in tests.
@@ -62,8 +77,18 @@ func (o *InstrumentationOptions) AddFlags(fs *flag.FlagSet) {
 	fs.IntVar(&o.HealthPort, "health-port", DefaultHealthPort, "port to serve liveness and readiness")
 	fs.BoolVar(&o.ProfileMemory, "profile-memory-usage", false, "profile memory usage for analysis")
 	fs.DurationVar(&o.MemoryProfileInterval, "memory-profile-interval", DefaultMemoryProfileInterval, "duration at which memory profiles should be dumped")
+	fs.StringVar(&o.LogLevelTokenPath, "log-level-token-path", "", "path to a bearer token that authenticates requests to /debug/loglevel on the pprof port to change the log level at runtime")
+	fs.StringVar(&o.ProfilingTokenPath, "profiling-token-path", "", "path to a bearer token that authenticates requests to the profiling endpoints on the pprof port, which are unauthenticated if unset")
+	fs.IntVar(&o.MutexProfileFraction, "mutex-profile-fraction", 0, "report on average 1/n of the mutex contention events in the mutex profile, zero disables it")
+	fs.IntVar(&o.BlockProfileRate, "block-profile-rate", 0, "report on average one blocking event per this many nanoseconds spent blocked in the block profile, zero disables it")
 }
 
 func (o *InstrumentationOptions) Validate(_ bool) error {
+	if o.MutexProfileFraction < 0 {
+		return errors.New("--mutex-profile-fraction must not be negative")
+	}
+	if o.BlockProfileRate < 0 {
+		return errors.New("--block-profile-rate must not be negative")
+	}
 	return nil
 }
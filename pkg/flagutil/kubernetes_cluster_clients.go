@@ -32,7 +32,10 @@ import (
 	"gopkg.in/fsnotify.v1"
 
 	k8sauthorizationv1 "k8s.io/api/authorization/v1"
+	k8scorev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
@@ -43,6 +46,7 @@ import (
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	prow "sigs.k8s.io/prow/pkg/client/clientset/versioned"
 	prowv1 "sigs.k8s.io/prow/pkg/client/clientset/versioned/typed/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/kube"
@@ -64,6 +68,11 @@ type KubernetesOptions struct {
 	noInClusterConfig        bool
 	NOInClusterConfigDefault bool
 
+	clusterRegistrationNamespace string
+	// clusterRegistrationClient reads ClusterRegistrations and their secrets
+	// from the infrastructure cluster. It is created on resolution if unset.
+	clusterRegistrationClient ctrlruntimeclient.Reader
+
 	// from the setter SetDisabledClusters
 	disabledClusters sets.Set[string]
 
@@ -76,8 +85,13 @@ type KubernetesOptions struct {
 	infrastructureClusterConfig *rest.Config
 	kubeconfigWach              *sync.Once
 	kubeconfigWatchEvents       <-chan fsnotify.Event
+	clusterRegistrations        map[string]string
 }
 
+// clusterRegistrationPollInterval is how often ClusterRegistrations are checked
+// for changes once a kubeconfig change callback was added.
+var clusterRegistrationPollInterval = time.Minute
+
 var MissingPermissions = errors.New("missing permissions")
 
 // AddKubeconfigChangeCallback adds a callback that gets called whenever the kubeconfig changes.
@@ -147,6 +161,104 @@ func (o *KubernetesOptions) AddKubeconfigChangeCallback(callback func()) error {
 		}
 	}()
 
+	if o.clusterRegistrationNamespace != "" {
+		go func() {
+			for {
+				time.Sleep(clusterRegistrationPollInterval)
+				changed, err := o.clusterRegistrationsChanged(context.Background())
+				if err != nil {
+					logrus.WithError(err).Warn("Failed to check cluster registrations for changes")
+					continue
+				}
+				if changed {
+					logrus.Info("Cluster registrations changed")
+					callback()
+					return
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+// clusterRegistrationVersions returns the version of every
+// ClusterRegistration in the namespace by name, made of the generation of the
+// registration and the resource version of its auth secret. Status updates do
+// not change the generation, so this only changes when a registration is
+// added, removed or its spec is edited, or when its credentials are rotated.
+func (o *KubernetesOptions) clusterRegistrationVersions(ctx context.Context) (map[string]string, error) {
+	registrations := &prowapi.ClusterRegistrationList{}
+	if err := o.clusterRegistrationClient.List(ctx, registrations, ctrlruntimeclient.InNamespace(o.clusterRegistrationNamespace)); err != nil {
+		return nil, fmt.Errorf("list cluster registrations: %w", err)
+	}
+	versions := map[string]string{}
+	for _, registration := range registrations.Items {
+		// A missing secret has no resource version, so that the registration
+		// is loaded again once the secret is created.
+		var secretVersion string
+		if name := registration.Spec.Auth.SecretName; name != "" {
+			secret := &k8scorev1.Secret{}
+			err := o.clusterRegistrationClient.Get(ctx, types.NamespacedName{Namespace: registration.Namespace, Name: name}, secret)
+			if err != nil && !kerrors.IsNotFound(err) {
+				return nil, fmt.Errorf("get auth secret of cluster registration %s: %w", registration.Name, err)
+			}
+			secretVersion = secret.ResourceVersion
+		}
+		versions[registration.Name] = fmt.Sprintf("%d/%s", registration.Generation, secretVersion)
+	}
+	return versions, nil
+}
+
+// clusterRegistrationsChanged determines whether the ClusterRegistrations
+// differ from the ones that were loaded on resolution.
+func (o *KubernetesOptions) clusterRegistrationsChanged(ctx context.Context) (bool, error) {
+	versions, err := o.clusterRegistrationVersions(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(versions) != len(o.clusterRegistrations) {
+		return true, nil
+	}
+	for name, version := range versions {
+		if loaded, ok := o.clusterRegistrations[name]; !ok || loaded != version {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// loadClusterRegistrations adds the build clusters registered with
+// ClusterRegistrations to the configs loaded from kubeconfigs. A registration
+// must not use the name of a kubeconfig context. Registrations that cannot be
+// loaded are logged and skipped, so one broken registration does not take
+// down every other build cluster.
+func (o *KubernetesOptions) loadClusterRegistrations(clusterConfigs map[string]rest.Config) error {
+	if o.clusterRegistrationClient == nil {
+		infrastructureConfig := clusterConfigs[kube.InClusterContext]
+		client, err := ctrlruntimeclient.New(&infrastructureConfig, ctrlruntimeclient.Options{})
+		if err != nil {
+			return fmt.Errorf("create cluster registration client: %w", err)
+		}
+		o.clusterRegistrationClient = client
+	}
+
+	ctx := context.Background()
+	versions, err := o.clusterRegistrationVersions(ctx)
+	if err != nil {
+		return err
+	}
+	registered, err := kube.ClusterRegistrationConfigs(ctx, o.clusterRegistrationClient, o.clusterRegistrationNamespace, o.disabledClusters)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to load some cluster registrations.")
+	}
+	for name, config := range registered {
+		if _, ok := clusterConfigs[name]; ok {
+			return fmt.Errorf("cluster %s is registered with a ClusterRegistration and also exists in the kubeconfig", name)
+		}
+		clusterConfigs[name] = config
+	}
+	o.clusterRegistrations = versions
 	return nil
 }
 
@@ -188,6 +300,7 @@ func (o *KubernetesOptions) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&o.kubeconfigSuffix, "kubeconfig-suffix", "", "The files without the suffix will be ignored when loading kubeconfig files from --kubeconfig-dir. It must be used together with --kubeconfig-dir.")
 	fs.StringVar(&o.projectedTokenFile, "projected-token-file", "", "A projected serviceaccount token file. If set, this will be configured as token file in the in-cluster config.")
 	fs.BoolVar(&o.noInClusterConfig, "no-in-cluster-config", o.NOInClusterConfigDefault, "Not resolving InCluster Config if set.")
+	fs.StringVar(&o.clusterRegistrationNamespace, "cluster-registration-namespace", "", "Namespace on the infrastructure cluster to load ClusterRegistrations from. The registered clusters are used as build clusters in addition to the kubeconfig contexts. Disabled if empty.")
 }
 
 // Validate validates Kubernetes options.
@@ -210,6 +323,10 @@ func (o *KubernetesOptions) Validate(_ bool) error {
 		return fmt.Errorf("--kubeconfig-dir must be set if --kubeconfig-suffix is set")
 	}
 
+	if o.clusterRegistrationNamespace != "" && o.noInClusterConfig {
+		return fmt.Errorf("--cluster-registration-namespace requires the infrastructure cluster and can not be used with --no-in-cluster-config")
+	}
+
 	return nil
 }
 
@@ -228,6 +345,11 @@ func (o *KubernetesOptions) resolve(dryRun bool) error {
 	if err != nil {
 		return fmt.Errorf("load --kubeconfig=%q configs: %w", o.kubeconfig, err)
 	}
+	if o.clusterRegistrationNamespace != "" {
+		if err := o.loadClusterRegistrations(clusterConfigs); err != nil {
+			return fmt.Errorf("load cluster registrations from namespace %q: %w", o.clusterRegistrationNamespace, err)
+		}
+	}
 	o.clusterConfigs = clusterConfigs
 
 	clients := map[string]kubernetes.Interface{}
@@ -17,9 +17,21 @@ limitations under the License.
 package flagutil
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/kube"
 )
 
 func TestExperimentalKubernetesOptions_Validate(t *testing.T) {
@@ -89,6 +101,20 @@ func TestExperimentalKubernetesOptions_Validate(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "clusterRegistrationNamespace can be set",
+			kubernetes: &KubernetesOptions{
+				clusterRegistrationNamespace: "prow",
+			},
+		},
+		{
+			name: "clusterRegistrationNamespace requires the in-cluster config",
+			kubernetes: &KubernetesOptions{
+				clusterRegistrationNamespace: "prow",
+				noInClusterConfig:            true,
+			},
+			expectedErr: true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -169,3 +195,160 @@ users:
 		t.Errorf("expected prowJobClientset to be nil, was %v", o.prowJobClientset)
 	}
 }
+
+func TestLoadClusterRegistrations(t *testing.T) {
+	registration := func(name string) *prowapi.ClusterRegistration {
+		return &prowapi.ClusterRegistration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: name, Generation: 1},
+			Spec: prowapi.ClusterRegistrationSpec{
+				Endpoint: "https://" + name + ".example.com",
+				Auth:     prowapi.ClusterAuth{Method: prowapi.TokenClusterAuth, SecretName: "token"},
+			},
+		}
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: "token"},
+		Data:       map[string][]byte{kube.ClusterRegistrationTokenKey: []byte("abc")},
+	}
+	kubeconfigClusters := func() map[string]rest.Config {
+		return map[string]rest.Config{
+			kube.InClusterContext:    {Host: "https://kubernetes.default"},
+			kube.DefaultClusterAlias: {Host: "https://kubernetes.default"},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		objects          []ctrlruntimeclient.Object
+		disabledClusters sets.Set[string]
+		expectedClusters sets.Set[string]
+		expectedErr      bool
+	}{
+		{
+			name:             "registered clusters are added to the kubeconfig clusters",
+			objects:          []ctrlruntimeclient.Object{registration("build01"), registration("build02"), token},
+			expectedClusters: sets.New[string](kube.InClusterContext, kube.DefaultClusterAlias, "build01", "build02"),
+		},
+		{
+			name:             "disabled registered clusters are not added",
+			objects:          []ctrlruntimeclient.Object{registration("build01"), registration("build02"), token},
+			disabledClusters: sets.New[string]("build02"),
+			expectedClusters: sets.New[string](kube.InClusterContext, kube.DefaultClusterAlias, "build01"),
+		},
+		{
+			name:             "broken registrations are skipped",
+			objects:          []ctrlruntimeclient.Object{registration("build01")},
+			expectedClusters: sets.New[string](kube.InClusterContext, kube.DefaultClusterAlias),
+		},
+		{
+			name:        "registrations must not shadow kubeconfig contexts",
+			objects:     []ctrlruntimeclient.Object{registration(kube.DefaultClusterAlias), token},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &KubernetesOptions{
+				clusterRegistrationNamespace: "prow",
+				clusterRegistrationClient:    fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.objects...).Build(),
+				disabledClusters:             tc.disabledClusters,
+			}
+			clusterConfigs := kubeconfigClusters()
+			err := o.loadClusterRegistrations(clusterConfigs)
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(sets.List(tc.expectedClusters), sets.List(sets.KeySet(clusterConfigs))); diff != "" {
+				t.Errorf("unexpected clusters: %s", diff)
+			}
+		})
+	}
+}
+
+func TestClusterRegistrationsChanged(t *testing.T) {
+	ctx := context.Background()
+	build01 := &prowapi.ClusterRegistration{ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: "build01"}}
+	client := fakectrlruntimeclient.NewClientBuilder().WithObjects(build01).Build()
+	o := &KubernetesOptions{
+		clusterRegistrationNamespace: "prow",
+		clusterRegistrationClient:    client,
+	}
+	if err := o.loadClusterRegistrations(map[string]rest.Config{}); err != nil {
+		t.Fatalf("failed to load cluster registrations: %v", err)
+	}
+
+	assertChanged := func(expected bool) {
+		t.Helper()
+		changed, err := o.clusterRegistrationsChanged(ctx)
+		if err != nil {
+			t.Fatalf("failed to check cluster registrations: %v", err)
+		}
+		if changed != expected {
+			t.Errorf("expected changed to be %t, got %t", expected, changed)
+		}
+	}
+
+	assertChanged(false)
+	build02 := &prowapi.ClusterRegistration{ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: "build02"}}
+	if err := client.Create(ctx, build02); err != nil {
+		t.Fatalf("failed to create registration: %v", err)
+	}
+	assertChanged(true)
+	if err := client.Delete(ctx, build02); err != nil {
+		t.Fatalf("failed to delete registration: %v", err)
+	}
+	assertChanged(false)
+}
+
+func TestClusterRegistrationsChangedOnSecretRotation(t *testing.T) {
+	ctx := context.Background()
+	build01 := &prowapi.ClusterRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: "build01"},
+		Spec: prowapi.ClusterRegistrationSpec{
+			Endpoint: "https://build01.example.com",
+			Auth:     prowapi.ClusterAuth{Method: prowapi.TokenClusterAuth, SecretName: "token"},
+		},
+	}
+	client := fakectrlruntimeclient.NewClientBuilder().WithObjects(build01).Build()
+	o := &KubernetesOptions{
+		clusterRegistrationNamespace: "prow",
+		clusterRegistrationClient:    client,
+	}
+	if err := o.loadClusterRegistrations(map[string]rest.Config{}); err != nil {
+		t.Fatalf("failed to load cluster registrations: %v", err)
+	}
+
+	assertChanged := func(expected bool) {
+		t.Helper()
+		changed, err := o.clusterRegistrationsChanged(ctx)
+		if err != nil {
+			t.Fatalf("failed to check cluster registrations: %v", err)
+		}
+		if changed != expected {
+			t.Errorf("expected changed to be %t, got %t", expected, changed)
+		}
+	}
+
+	assertChanged(false)
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: "token"},
+		Data:       map[string][]byte{kube.ClusterRegistrationTokenKey: []byte("abc")},
+	}
+	if err := client.Create(ctx, token); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	assertChanged(true)
+	if err := o.loadClusterRegistrations(map[string]rest.Config{}); err != nil {
+		t.Fatalf("failed to load cluster registrations: %v", err)
+	}
+	assertChanged(false)
+	token.Data[kube.ClusterRegistrationTokenKey] = []byte("rotated")
+	if err := client.Update(ctx, token); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	assertChanged(true)
+}
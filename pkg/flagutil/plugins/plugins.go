@@ -35,7 +35,7 @@ type PluginOptions struct {
 
 func (o *PluginOptions) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&o.PluginConfigPath, "plugin-config", o.PluginConfigPathDefault, "Path to plugin config file.")
-	fs.Var(&o.SupplementalPluginsConfigDirs, "supplemental-plugin-config-dir", "An additional directory from which to load plugin configs. Can be used for config sharding but only supports a subset of the config. The flag can be passed multiple times.")
+	fs.Var(&o.SupplementalPluginsConfigDirs, "supplemental-plugin-config-dir", "An additional directory from which to load plugin configs. Can be used for config sharding but only supports a subset of the config. Plugins enabled for the same org or repo in several files are merged. The flag can be passed multiple times.")
 	fs.StringVar(&o.SupplementalPluginsConfigsFileNameSuffix, "supplemental-plugin-configs-filename-suffix", "_pluginconfig.yaml", "Suffix for additional plugin configs. Only files with this name will be considered")
 }
 
@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flagutil
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+)
+
+// TracingOptions holds the options to export the traces of a component.
+type TracingOptions struct {
+	// Endpoint is the URL of the OpenTelemetry collector that spans are
+	// exported to over OTLP/HTTP. Tracing is disabled when unset.
+	Endpoint string
+}
+
+// AddFlags injects tracing options into the given FlagSet.
+func (o *TracingOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "tracing-endpoint", "", "URL of the OpenTelemetry collector to export traces to over OTLP/HTTP, e.g. http://otel-collector:4318. Tracing is disabled when unset.")
+}
+
+// Validate validates tracing options.
+func (o *TracingOptions) Validate(_ bool) error {
+	if o.Endpoint == "" {
+		return nil
+	}
+	if u, err := url.Parse(o.Endpoint); err != nil || u.Host == "" {
+		return fmt.Errorf("invalid --tracing-endpoint %q, must be a URL like http://otel-collector:4318", o.Endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flakiness
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+// cacheTTL is how long a report is served before it is read again.
+const cacheTTL = time.Minute
+
+// ReportCache serves the flakiness report at the configured report path,
+// reading it from storage at most once per minute. If the report can not be
+// read, the last one is served. A nil ReportCache serves no report.
+type ReportCache struct {
+	opener io.Opener
+	config config.Getter
+	now    func() time.Time
+
+	lock   sync.Mutex
+	path   string
+	read   time.Time
+	report *Report
+}
+
+func NewReportCache(opener io.Opener, cfg config.Getter) *ReportCache {
+	return &ReportCache{opener: opener, config: cfg, now: time.Now}
+}
+
+// Report returns the flakiness report, or nil if there is none.
+func (c *ReportCache) Report(ctx context.Context) *Report {
+	if c == nil {
+		return nil
+	}
+	path := c.config().Flakiness.ReportPath
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if path != c.path {
+		c.path, c.report, c.read = path, nil, time.Time{}
+	}
+	if path == "" || c.now().Sub(c.read) < cacheTTL {
+		return c.report
+	}
+	c.read = c.now()
+	report, err := ReadReport(ctx, c.opener, path)
+	if err != nil {
+		if !io.IsNotExist(err) {
+			logrus.WithError(err).WithField("path", path).Warn("Failed to read flakiness report.")
+		}
+		return c.report
+	}
+	c.report = report
+	return report
+}
+
+// IsQuarantined tells whether a presubmit of the repo is quarantined.
+func (c *ReportCache) IsQuarantined(org, repo, job string) bool {
+	report := c.Report(context.Background())
+	if report == nil {
+		return false
+	}
+	j := report.Job(org, repo, job)
+	return j != nil && j.Quarantined
+}
+
+// QuarantinedContexts returns the contexts of the quarantined presubmits of
+// the repo.
+func (c *ReportCache) QuarantinedContexts(org, repo string) []string {
+	report := c.Report(context.Background())
+	if report == nil {
+		return nil
+	}
+	var contexts []string
+	for _, job := range report.Jobs {
+		if job.Quarantined && job.Org == org && job.Repo == repo && job.Context != "" {
+			contexts = append(contexts, job.Context)
+		}
+	}
+	return contexts
+}
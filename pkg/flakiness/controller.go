@@ -0,0 +1,185 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flakiness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+const ControllerName = "flakiness"
+
+type githubClient interface {
+	AddLabel(org, repo string, number int, label string) error
+}
+
+// Add adds the controller to the manager. Without a GitHub client, PRs are
+// not labeled.
+func Add(mgr controllerruntime.Manager, cfg config.Getter, opener io.Opener, ghc githubClient) error {
+	c := NewController(mgr.GetClient(), cfg, opener, ghc)
+	if err := mgr.Add(manager.RunnableFunc(c.run)); err != nil {
+		return fmt.Errorf("failed to add %s controller to manager: %w", ControllerName, err)
+	}
+	return nil
+}
+
+// Controller periodically computes the flakiness report from the completed
+// ProwJobs, writes it to storage and labels the PRs presubmits flaked on.
+type Controller struct {
+	client ctrlruntimeclient.Reader
+	config config.Getter
+	opener io.Opener
+	ghc    githubClient
+	log    *logrus.Entry
+
+	// runs caches the runs of completed ProwJobs by name, as their junit
+	// results do not change anymore.
+	runs map[string]Run
+	// lastSync is when the last report was computed. PRs are labeled for
+	// flakes that showed after it.
+	lastSync time.Time
+}
+
+func NewController(client ctrlruntimeclient.Reader, cfg config.Getter, opener io.Opener, ghc githubClient) *Controller {
+	return &Controller{
+		client: client,
+		config: cfg,
+		opener: opener,
+		ghc:    ghc,
+		log:    logrus.NewEntry(logrus.StandardLogger()).WithField("controller", ControllerName),
+		runs:   map[string]Run{},
+	}
+}
+
+func (c *Controller) run(ctx context.Context) error {
+	for {
+		if err := c.Sync(ctx); err != nil {
+			c.log.WithError(err).Error("Failed to compute the flakiness report.")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.config().Flakiness.ResyncPeriod.Duration):
+		}
+	}
+}
+
+// Sync computes the flakiness report and applies the flakiness policies.
+func (c *Controller) Sync(ctx context.Context) error {
+	cfg := c.config()
+	if cfg.Flakiness.ReportPath == "" {
+		c.log.Debug("No flakiness report path is configured, skipping.")
+		return nil
+	}
+	now := time.Now()
+	since := now.Add(-cfg.Flakiness.Window.Duration)
+
+	pjs := &prowapi.ProwJobList{}
+	if err := c.client.List(ctx, pjs, ctrlruntimeclient.InNamespace(cfg.ProwJobNamespace)); err != nil {
+		return fmt.Errorf("failed to list ProwJobs: %w", err)
+	}
+	var runs []Run
+	seen := sets.New[string]()
+	for i := range pjs.Items {
+		pj := &pjs.Items[i]
+		if !counts(pj, since) {
+			continue
+		}
+		seen.Insert(pj.Name)
+		run, cached := c.runs[pj.Name]
+		if !cached {
+			var err error
+			if run, err = runFromProwJob(ctx, c.config, c.opener, pj); err != nil {
+				// The outcome of the job is still worth counting, the junit
+				// results are read again on the next sync.
+				c.log.WithError(err).WithField("prowjob", pj.Name).Debug("Failed to read junit results.")
+			} else {
+				c.runs[pj.Name] = run
+			}
+		}
+		runs = append(runs, run)
+	}
+	for name := range c.runs {
+		if !seen.Has(name) {
+			delete(c.runs, name)
+		}
+	}
+
+	report := Aggregate(runs, &cfg.Flakiness, since, now)
+	if err := WriteReport(ctx, c.opener, cfg.Flakiness.ReportPath, report); err != nil {
+		return fmt.Errorf("failed to write flakiness report: %w", err)
+	}
+	c.log.WithFields(logrus.Fields{"jobs": len(report.Jobs), "runs": len(runs)}).Info("Wrote flakiness report.")
+
+	flakedSince := c.lastSync
+	if flakedSince.IsZero() {
+		// Do not label the PRs of the whole window again after a restart.
+		flakedSince = now.Add(-cfg.Flakiness.ResyncPeriod.Duration)
+	}
+	c.labelFlakyPulls(&cfg.Flakiness, report, flakedSince)
+	c.lastSync = now
+	return nil
+}
+
+// counts tells whether a ProwJob completed in the window with a result that
+// says something about the tested code. Errored and aborted jobs do not.
+func counts(pj *prowapi.ProwJob, since time.Time) bool {
+	if pj.Status.CompletionTime == nil || pj.Status.CompletionTime.Time.Before(since) {
+		return false
+	}
+	return pj.Status.State == prowapi.SuccessState || pj.Status.State == prowapi.FailureState
+}
+
+func (c *Controller) labelFlakyPulls(cfg *config.Flakiness, report *Report, since time.Time) {
+	if c.ghc == nil {
+		return
+	}
+	labeled := sets.New[string]()
+	for _, job := range report.Jobs {
+		policy, ok := cfg.PolicyFor(job.Org, job.Repo)
+		if !ok || policy.Label == "" {
+			continue
+		}
+		for _, pull := range job.FlakyPulls {
+			if !pull.Flaked.After(since) {
+				continue
+			}
+			key := fmt.Sprintf("%s/%s#%d", job.Org, job.Repo, pull.Number)
+			if labeled.Has(key) {
+				continue
+			}
+			labeled.Insert(key)
+			log := c.log.WithFields(logrus.Fields{"org": job.Org, "repo": job.Repo, "pr": pull.Number, "job": job.Job, "label": policy.Label})
+			if err := c.ghc.AddLabel(job.Org, job.Repo, pull.Number, policy.Label); err != nil {
+				log.WithError(err).Warn("Failed to label PR a presubmit flaked on.")
+				continue
+			}
+			log.Info("Labeled PR a presubmit flaked on.")
+		}
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flakiness
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/gcs/util"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+	"sigs.k8s.io/prow/pkg/io/providers"
+)
+
+const reportPath = "gs://bucket/flakiness/report.json"
+
+func testConfig() *config.Config {
+	return &config.Config{ProwConfig: config.ProwConfig{
+		ProwJobNamespace: "prowjobs",
+		Flakiness: config.Flakiness{
+			ReportPath:   reportPath,
+			Window:       &metav1.Duration{Duration: 24 * time.Hour},
+			ResyncPeriod: &metav1.Duration{Duration: time.Hour},
+			Policies: map[string]config.FlakinessPolicy{
+				"org": {MinRuns: 2, QuarantineThreshold: "50%", Label: "flaky-tests"},
+			},
+		},
+	}}
+}
+
+func presubmit(name string, state prowapi.ProwJobState, completed time.Time) *prowapi.ProwJob {
+	return &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prowjobs"},
+		Spec: prowapi.ProwJobSpec{
+			Type:    prowapi.PresubmitJob,
+			Job:     "pull-unit",
+			Context: "unit",
+			Refs: &prowapi.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseSHA: "base-" + name,
+				Pulls:   []prowapi.Pull{{Number: 1, SHA: "head"}},
+			},
+			DecorationConfig: &prowapi.DecorationConfig{
+				GCSConfiguration: &prowapi.GCSConfiguration{Bucket: "gs://bucket", PathStrategy: prowapi.PathStrategyExplicit},
+			},
+		},
+		Status: prowapi.ProwJobStatus{
+			State:          state,
+			BuildID:        name,
+			CompletionTime: &metav1.Time{Time: completed},
+		},
+	}
+}
+
+func addJUnit(t *testing.T, opener *fakeopener.FakeOpener, cfg config.Getter, pj *prowapi.ProwJob, name, content string) {
+	t.Helper()
+	bucket, dir, err := util.GetJobDestination(cfg, pj)
+	if err != nil {
+		t.Fatalf("failed to get job destination: %v", err)
+	}
+	path, err := providers.StoragePath(bucket, dir+"/artifacts/"+name)
+	if err != nil {
+		t.Fatalf("failed to get storage path: %v", err)
+	}
+	if opener.Buffer == nil {
+		opener.Buffer = map[string]*bytes.Buffer{}
+	}
+	opener.Buffer[path] = bytes.NewBufferString(content)
+}
+
+const failingJUnit = `<testsuites>
+  <testsuite name="unit">
+    <testcase classname="pkg" name="TestA"><failure message="boom"/></testcase>
+    <testcase classname="pkg" name="TestB"/>
+    <testcase classname="pkg" name="TestC"><skipped/></testcase>
+  </testsuite>
+</testsuites>`
+
+const passingJUnit = `<testsuite name="unit">
+  <testcase classname="pkg" name="TestA"/>
+  <testcase classname="pkg" name="TestB"/>
+</testsuite>`
+
+func TestRunFromProwJob(t *testing.T) {
+	cfg := testConfig()
+	cfgGetter := func() *config.Config { return cfg }
+	opener := &fakeopener.FakeOpener{}
+	pj := presubmit("1", prowapi.FailureState, now)
+	addJUnit(t, opener, cfgGetter, pj, "junit_01.xml", failingJUnit)
+	addJUnit(t, opener, cfgGetter, pj, "nested/junit_02.xml", `<testsuite><testcase name="TestD"/></testsuite>`)
+	addJUnit(t, opener, cfgGetter, pj, "junit_broken.xml", `<testsuite`)
+	addJUnit(t, opener, cfgGetter, pj, "coverage.xml", `<testsuite><testcase name="TestE"/></testsuite>`)
+
+	run, err := runFromProwJob(context.Background(), cfgGetter, opener, pj)
+	if err != nil {
+		t.Fatalf("failed to read run: %v", err)
+	}
+	expected := Run{
+		Org:      "org",
+		Repo:     "repo",
+		Job:      "pull-unit",
+		Type:     prowapi.PresubmitJob,
+		Context:  "unit",
+		Revision: "head",
+		Pull:     1,
+		Finished: now,
+		Tests: map[string]TestOutcome{
+			"pkg.TestA": {Failed: true},
+			"pkg.TestB": {Passed: true},
+			"TestD":     {Passed: true},
+		},
+	}
+	if diff := cmp.Diff(expected, run); diff != "" {
+		t.Errorf("unexpected run: %s", diff)
+	}
+}
+
+func TestSync(t *testing.T) {
+	cfg := testConfig()
+	cfgGetter := func() *config.Config { return cfg }
+	opener := &fakeopener.FakeOpener{}
+
+	failure := presubmit("1", prowapi.FailureState, time.Now().Add(-20*time.Minute))
+	success := presubmit("2", prowapi.SuccessState, time.Now().Add(-10*time.Minute))
+	aborted := presubmit("3", prowapi.AbortedState, time.Now())
+	expired := presubmit("4", prowapi.FailureState, time.Now().Add(-48*time.Hour))
+	addJUnit(t, opener, cfgGetter, failure, "junit.xml", failingJUnit)
+	addJUnit(t, opener, cfgGetter, success, "junit.xml", passingJUnit)
+
+	client := fakectrlruntimeclient.NewClientBuilder().WithRuntimeObjects(failure, success, aborted, expired).Build()
+	ghc := fakegithub.NewFakeClient()
+	c := NewController(client, cfgGetter, opener, ghc)
+	if err := c.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	report := NewReportCache(opener, cfgGetter).Report(context.Background())
+	if report == nil {
+		t.Fatal("expected the report to be written")
+	}
+	job := report.Job("org", "repo", "pull-unit")
+	if job == nil {
+		t.Fatalf("expected the job in the report, got %+v", report)
+	}
+	if job.Runs != 2 || job.FlakyRuns != 1 || !job.Quarantined {
+		t.Errorf("expected 2 runs, 1 flaky run and a quarantine, got %+v", job)
+	}
+	if diff := cmp.Diff([]TestFlakiness{{Name: "pkg.TestA", Runs: 2, Failures: 1, Flakes: 1, FlakeRate: 0.5}}, job.Tests); diff != "" {
+		t.Errorf("unexpected tests: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"org/repo#1:flaky-tests"}, ghc.IssueLabelsAdded); diff != "" {
+		t.Errorf("unexpected labels: %s", diff)
+	}
+	if len(c.runs) != 2 {
+		t.Errorf("expected the runs of the counted jobs to be cached, got %v", c.runs)
+	}
+
+	// The flake was labeled already, so the next sync must not label it again.
+	if err := client.Delete(context.Background(), failure); err != nil {
+		t.Fatalf("failed to delete ProwJob: %v", err)
+	}
+	if err := c.Sync(context.Background()); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	if len(ghc.IssueLabelsAdded) != 1 {
+		t.Errorf("expected no more labels, got %v", ghc.IssueLabelsAdded)
+	}
+	if _, ok := c.runs[failure.Name]; ok {
+		t.Error("expected the run of the deleted ProwJob to be dropped from the cache")
+	}
+}
+
+func TestSyncWithoutReportPath(t *testing.T) {
+	cfg := testConfig()
+	cfg.Flakiness.ReportPath = ""
+	opener := &fakeopener.FakeOpener{}
+	var client ctrlruntimeclient.Reader = fakectrlruntimeclient.NewClientBuilder().Build()
+	c := NewController(client, func() *config.Config { return cfg }, opener, nil)
+	if err := c.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	for path := range opener.Buffer {
+		if strings.Contains(path, "flakiness") {
+			t.Errorf("expected no report to be written, got %s", path)
+		}
+	}
+}
+
+func TestReportCache(t *testing.T) {
+	cfg := testConfig()
+	opener := &fakeopener.FakeOpener{}
+	clock := now
+	cache := NewReportCache(opener, func() *config.Config { return cfg })
+	cache.now = func() time.Time { return clock }
+
+	if cache.IsQuarantined("org", "repo", "pull-unit") {
+		t.Error("expected nothing to be quarantined without a report")
+	}
+
+	report := &Report{Generated: now, Jobs: []JobFlakiness{
+		{Org: "org", Repo: "repo", Job: "pull-unit", Context: "unit", Quarantined: true},
+		{Org: "org", Repo: "repo", Job: "pull-e2e", Context: "e2e"},
+	}}
+	if err := WriteReport(context.Background(), opener, reportPath, report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	if cache.IsQuarantined("org", "repo", "pull-unit") {
+		t.Error("expected the report to be read again only after the cache expired")
+	}
+	clock = clock.Add(cacheTTL)
+	if !cache.IsQuarantined("org", "repo", "pull-unit") {
+		t.Error("expected pull-unit to be quarantined")
+	}
+	if cache.IsQuarantined("org", "repo", "pull-e2e") {
+		t.Error("expected pull-e2e to not be quarantined")
+	}
+	if diff := cmp.Diff([]string{"unit"}, cache.QuarantinedContexts("org", "repo")); diff != "" {
+		t.Errorf("unexpected quarantined contexts: %s", diff)
+	}
+
+	var nilCache *ReportCache
+	if nilCache.IsQuarantined("org", "repo", "pull-unit") || nilCache.QuarantinedContexts("org", "repo") != nil {
+		t.Error("expected a nil cache to quarantine nothing")
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flakiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdio "io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/testgrid/metadata/junit"
+	"github.com/sirupsen/logrus"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/crier/reporters/gcs/util"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/providers"
+)
+
+// junitRe matches the junit results among the artifacts of a job, like the
+// default junit lens of Spyglass does.
+var junitRe = regexp.MustCompile(`^junit.*\.xml$`)
+
+// runFromProwJob reads the junit results of a completed ProwJob from storage.
+// Jobs without junit results only contribute their own outcome.
+func runFromProwJob(ctx context.Context, cfg config.Getter, opener io.Opener, pj *prowapi.ProwJob) (Run, error) {
+	run := Run{
+		Job:      pj.Spec.Job,
+		Type:     pj.Spec.Type,
+		Context:  pj.Spec.Context,
+		Revision: revision(pj),
+		Passed:   pj.Status.State == prowapi.SuccessState,
+		Tests:    map[string]TestOutcome{},
+	}
+	if pj.Status.CompletionTime != nil {
+		run.Finished = pj.Status.CompletionTime.Time
+	}
+	if refs := pj.Spec.Refs; refs != nil {
+		run.Org, run.Repo = refs.Org, refs.Repo
+		if pj.Spec.Type == prowapi.PresubmitJob && len(refs.Pulls) == 1 {
+			run.Pull = refs.Pulls[0].Number
+		}
+	} else if len(pj.Spec.ExtraRefs) > 0 {
+		run.Org, run.Repo = pj.Spec.ExtraRefs[0].Org, pj.Spec.ExtraRefs[0].Repo
+	}
+
+	bucket, dir, err := util.GetJobDestination(cfg, pj)
+	if err != nil {
+		return run, fmt.Errorf("failed to get the storage path of the job: %w", err)
+	}
+	artifacts, err := providers.StoragePath(bucket, strings.TrimSuffix(dir, "/")+"/artifacts/")
+	if err != nil {
+		return run, fmt.Errorf("failed to resolve the artifacts path of the job: %w", err)
+	}
+	paths, err := junitPaths(ctx, opener, artifacts)
+	if err != nil {
+		return run, err
+	}
+	for _, path := range paths {
+		content, err := io.ReadContent(ctx, logrus.WithField("client", "flakiness"), opener, path)
+		if err != nil {
+			return run, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		suites, err := junit.Parse(content)
+		if err != nil {
+			// Broken junit files are not worth failing the run for, the
+			// junit lens of Spyglass skips them as well.
+			logrus.WithError(err).WithField("path", path).Debug("Failed to parse junit results.")
+			continue
+		}
+		for _, suite := range suites.Suites {
+			recordSuite(run.Tests, suite)
+		}
+	}
+	return run, nil
+}
+
+// junitPaths lists the junit results below the artifacts directory.
+func junitPaths(ctx context.Context, opener io.Opener, artifacts string) ([]string, error) {
+	storageProvider, bucket, _, err := providers.ParseStoragePath(artifacts)
+	if err != nil {
+		return nil, err
+	}
+	it, err := opener.Iterator(ctx, artifacts, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", artifacts, err)
+	}
+	var paths []string
+	for {
+		attr, err := it.Next(ctx)
+		if errors.Is(err, stdio.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", artifacts, err)
+		}
+		if attr.IsDir || !junitRe.MatchString(attr.ObjName) {
+			continue
+		}
+		paths = append(paths, fmt.Sprintf("%s://%s/%s", storageProvider, bucket, attr.Name))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func recordSuite(tests map[string]TestOutcome, suite junit.Suite) {
+	for _, subSuite := range suite.Suites {
+		recordSuite(tests, subSuite)
+	}
+	for _, result := range suite.Results {
+		if result.Skipped != nil {
+			continue
+		}
+		name := result.Name
+		if result.ClassName != "" {
+			name = result.ClassName + "." + result.Name
+		}
+		outcome := tests[name]
+		if result.Failure != nil || result.Errored != nil {
+			outcome.Failed = true
+		} else {
+			outcome.Passed = true
+		}
+		tests[name] = outcome
+	}
+}
+
+// revision identifies the code tested by a ProwJob. Presubmits are retested
+// on a moving base, so only the heads of the pull requests count for them.
+func revision(pj *prowapi.ProwJob) string {
+	refs := pj.Spec.Refs
+	if refs == nil {
+		// Without refs every run of a job stands on its own.
+		return pj.Name
+	}
+	if len(refs.Pulls) > 0 {
+		shas := make([]string, 0, len(refs.Pulls))
+		for _, pull := range refs.Pulls {
+			shas = append(shas, pull.SHA)
+		}
+		return strings.Join(shas, ",")
+	}
+	if refs.BaseSHA != "" {
+		return refs.BaseSHA
+	}
+	return pj.Name
+}
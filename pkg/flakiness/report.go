@@ -0,0 +1,302 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package flakiness computes the flake rates of jobs and of their test cases
+// from the junit results of completed ProwJobs, and applies the flakiness
+// policies of the Prow config: quarantining flaky presubmits, so that Tide
+// does not require them, and labeling the PRs they flaked on.
+package flakiness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+// maxTestsPerJob bounds the test cases listed for a job in the report.
+const maxTestsPerJob = 100
+
+// Run is the outcome of a completed job and of the test cases in its junit
+// results.
+type Run struct {
+	Org     string
+	Repo    string
+	Job     string
+	Type    prowapi.ProwJobType
+	Context string
+	// Revision identifies the tested code: the head SHAs of the tested pull
+	// requests, or the base SHA if there are none. Runs of a job with the
+	// same revision are expected to have the same outcome.
+	Revision string
+	// Pull is the number of the pull request tested by a presubmit.
+	Pull     int
+	Passed   bool
+	Finished time.Time
+	// Tests are the outcomes of the test cases by name. Skipped test cases
+	// are left out.
+	Tests map[string]TestOutcome
+}
+
+// TestOutcome is the outcome of a test case in a run. A test case that was
+// retried within the run may have both failed and passed.
+type TestOutcome struct {
+	Passed bool
+	Failed bool
+}
+
+// Report is the flakiness of the jobs that completed in a window.
+type Report struct {
+	// Generated is when the report was computed.
+	Generated time.Time `json:"generated"`
+	// Since is the start of the window of the report.
+	Since time.Time      `json:"since"`
+	Jobs  []JobFlakiness `json:"jobs"`
+}
+
+// JobFlakiness is the flakiness of a job. A run of the job is flaky if it
+// failed while another run with the same revision passed, or if a test case
+// of it failed while it passed within the run or in another run with the
+// same revision.
+type JobFlakiness struct {
+	Org       string              `json:"org,omitempty"`
+	Repo      string              `json:"repo,omitempty"`
+	Job       string              `json:"job"`
+	Type      prowapi.ProwJobType `json:"type"`
+	Context   string              `json:"context,omitempty"`
+	Runs      int                 `json:"runs"`
+	FlakyRuns int                 `json:"flaky_runs"`
+	FlakeRate float64             `json:"flake_rate"`
+	// Quarantined is true if the job is a presubmit whose flake rate reached
+	// the quarantine threshold of its flakiness policy.
+	Quarantined bool `json:"quarantined,omitempty"`
+	// FlakyPulls are the pull requests the job flaked on.
+	FlakyPulls []FlakyPull `json:"flaky_pulls,omitempty"`
+	// Tests are the test cases of the job that failed at least once, the
+	// flakiest first.
+	Tests []TestFlakiness `json:"tests,omitempty"`
+}
+
+// FlakyPull is a pull request on which a presubmit flaked.
+type FlakyPull struct {
+	Number int    `json:"number"`
+	SHA    string `json:"sha"`
+	// Flaked is when the last run that showed the flake completed.
+	Flaked time.Time `json:"flaked"`
+}
+
+// TestFlakiness is the flakiness of a test case of a job.
+type TestFlakiness struct {
+	Name string `json:"name"`
+	// Runs is the number of runs the test case ran in.
+	Runs int `json:"runs"`
+	// Failures is the number of runs the test case failed in.
+	Failures int `json:"failures"`
+	// Flakes is the number of runs the test case failed in, while it passed
+	// in the same run or in another run with the same revision.
+	Flakes    int     `json:"flakes"`
+	FlakeRate float64 `json:"flake_rate"`
+}
+
+type jobKey struct {
+	org, repo, job string
+}
+
+// Aggregate computes the flakiness of the jobs of the runs and applies the
+// quarantine thresholds of the flakiness policies.
+func Aggregate(runs []Run, cfg *config.Flakiness, since, now time.Time) *Report {
+	byJob := map[jobKey][]Run{}
+	for _, run := range runs {
+		key := jobKey{org: run.Org, repo: run.Repo, job: run.Job}
+		byJob[key] = append(byJob[key], run)
+	}
+
+	report := &Report{Generated: now, Since: since, Jobs: []JobFlakiness{}}
+	for key, jobRuns := range byJob {
+		job := aggregateJob(jobRuns)
+		job.Org, job.Repo, job.Job = key.org, key.repo, key.job
+		job.Quarantined = quarantined(job, cfg)
+		report.Jobs = append(report.Jobs, job)
+	}
+	sort.Slice(report.Jobs, func(i, j int) bool {
+		a, b := report.Jobs[i], report.Jobs[j]
+		if a.Org != b.Org {
+			return a.Org < b.Org
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Job < b.Job
+	})
+	return report
+}
+
+func aggregateJob(runs []Run) JobFlakiness {
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Finished.Before(runs[j].Finished) })
+	latest := runs[len(runs)-1]
+	job := JobFlakiness{Type: latest.Type, Context: latest.Context, Runs: len(runs)}
+
+	byRevision := map[string][]Run{}
+	for _, run := range runs {
+		byRevision[run.Revision] = append(byRevision[run.Revision], run)
+	}
+
+	tests := map[string]*TestFlakiness{}
+	flakyPulls := map[FlakyPull]time.Time{}
+	for revision, revisionRuns := range byRevision {
+		revisionPassed := false
+		passedTests := map[string]bool{}
+		flaked := time.Time{}
+		for _, run := range revisionRuns {
+			revisionPassed = revisionPassed || run.Passed
+			for name, outcome := range run.Tests {
+				passedTests[name] = passedTests[name] || outcome.Passed
+			}
+			if run.Finished.After(flaked) {
+				flaked = run.Finished
+			}
+		}
+
+		for _, run := range revisionRuns {
+			flaky := !run.Passed && revisionPassed
+			for name, outcome := range run.Tests {
+				test, ok := tests[name]
+				if !ok {
+					test = &TestFlakiness{Name: name}
+					tests[name] = test
+				}
+				test.Runs++
+				if !outcome.Failed {
+					continue
+				}
+				test.Failures++
+				if outcome.Passed || passedTests[name] {
+					test.Flakes++
+					flaky = true
+				}
+			}
+			if !flaky {
+				continue
+			}
+			job.FlakyRuns++
+			if run.Type == prowapi.PresubmitJob && run.Pull != 0 {
+				flakyPulls[FlakyPull{Number: run.Pull, SHA: revision}] = flaked
+			}
+		}
+	}
+	job.FlakeRate = float64(job.FlakyRuns) / float64(job.Runs)
+
+	for pull, flaked := range flakyPulls {
+		pull.Flaked = flaked
+		job.FlakyPulls = append(job.FlakyPulls, pull)
+	}
+	sort.Slice(job.FlakyPulls, func(i, j int) bool {
+		if job.FlakyPulls[i].Number != job.FlakyPulls[j].Number {
+			return job.FlakyPulls[i].Number < job.FlakyPulls[j].Number
+		}
+		return job.FlakyPulls[i].SHA < job.FlakyPulls[j].SHA
+	})
+
+	for _, test := range tests {
+		if test.Failures == 0 {
+			continue
+		}
+		test.FlakeRate = float64(test.Flakes) / float64(test.Runs)
+		job.Tests = append(job.Tests, *test)
+	}
+	sort.Slice(job.Tests, func(i, j int) bool {
+		a, b := job.Tests[i], job.Tests[j]
+		if a.Flakes != b.Flakes {
+			return a.Flakes > b.Flakes
+		}
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.Name < b.Name
+	})
+	if len(job.Tests) > maxTestsPerJob {
+		job.Tests = job.Tests[:maxTestsPerJob]
+	}
+	return job
+}
+
+func quarantined(job JobFlakiness, cfg *config.Flakiness) bool {
+	if job.Type != prowapi.PresubmitJob {
+		return false
+	}
+	policy, ok := cfg.PolicyFor(job.Org, job.Repo)
+	if !ok || policy.QuarantineThreshold == "" || job.Runs < policy.MinRuns {
+		return false
+	}
+	threshold, err := config.ParseFlakeRate(policy.QuarantineThreshold)
+	if err != nil {
+		// The config validation rejects invalid thresholds.
+		return false
+	}
+	return job.FlakeRate >= threshold
+}
+
+// Job returns the flakiness of a job, or nil if it is not in the report.
+func (r *Report) Job(org, repo, job string) *JobFlakiness {
+	for i := range r.Jobs {
+		if r.Jobs[i].Org == org && r.Jobs[i].Repo == repo && r.Jobs[i].Job == job {
+			return &r.Jobs[i]
+		}
+	}
+	return nil
+}
+
+// Filter returns the jobs of the report that match the org, repo and job,
+// where empty values match everything.
+func (r *Report) Filter(org, repo, job string) *Report {
+	filtered := &Report{Generated: r.Generated, Since: r.Since, Jobs: []JobFlakiness{}}
+	for _, j := range r.Jobs {
+		if (org == "" || strings.EqualFold(j.Org, org)) && (repo == "" || strings.EqualFold(j.Repo, repo)) && (job == "" || j.Job == job) {
+			filtered.Jobs = append(filtered.Jobs, j)
+		}
+	}
+	return filtered
+}
+
+// WriteReport writes the report as JSON to the given storage path.
+func WriteReport(ctx context.Context, opener io.Opener, path string, report *Report) error {
+	content, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal flakiness report: %w", err)
+	}
+	return io.WriteContent(ctx, logrus.WithField("client", "flakiness"), opener, path, content)
+}
+
+// ReadReport reads the report written by WriteReport.
+func ReadReport(ctx context.Context, opener io.Opener, path string) (*Report, error) {
+	content, err := io.ReadContent(ctx, logrus.WithField("client", "flakiness"), opener, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flakiness report: %w", err)
+	}
+	report := &Report{}
+	if err := json.Unmarshal(content, report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal flakiness report: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flakiness
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+var now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func presubmitRun(pull int, sha string, passed bool, finished time.Duration, tests map[string]TestOutcome) Run {
+	return Run{
+		Org:      "org",
+		Repo:     "repo",
+		Job:      "pull-unit",
+		Type:     prowapi.PresubmitJob,
+		Context:  "unit",
+		Revision: sha,
+		Pull:     pull,
+		Passed:   passed,
+		Finished: now.Add(-finished),
+		Tests:    tests,
+	}
+}
+
+var (
+	passed  = TestOutcome{Passed: true}
+	failed  = TestOutcome{Failed: true}
+	retried = TestOutcome{Passed: true, Failed: true}
+)
+
+func TestAggregate(t *testing.T) {
+	runs := []Run{
+		// PR 1 failed on TestA and passed on the retest.
+		presubmitRun(1, "a", false, 3*time.Hour, map[string]TestOutcome{"TestA": failed, "TestB": passed}),
+		presubmitRun(1, "a", true, 2*time.Hour, map[string]TestOutcome{"TestA": passed, "TestB": passed}),
+		// PR 2 is broken: TestB fails consistently.
+		presubmitRun(2, "b", false, 3*time.Hour, map[string]TestOutcome{"TestA": passed, "TestB": failed}),
+		presubmitRun(2, "b", false, 2*time.Hour, map[string]TestOutcome{"TestA": passed, "TestB": failed}),
+		// PR 3 passed, TestA only passed on a retry within the run.
+		presubmitRun(3, "c", true, time.Hour, map[string]TestOutcome{"TestA": retried, "TestB": passed}),
+		{Job: "ci-periodic", Type: prowapi.PeriodicJob, Revision: "ci-periodic-1", Passed: true, Finished: now.Add(-time.Hour)},
+	}
+	cfg := &config.Flakiness{Policies: map[string]config.FlakinessPolicy{
+		"org/repo": {MinRuns: 5, QuarantineThreshold: "40%"},
+	}}
+
+	expected := &Report{
+		Generated: now,
+		Since:     now.Add(-24 * time.Hour),
+		Jobs: []JobFlakiness{
+			{
+				Job:  "ci-periodic",
+				Type: prowapi.PeriodicJob,
+				Runs: 1,
+			},
+			{
+				Org:         "org",
+				Repo:        "repo",
+				Job:         "pull-unit",
+				Type:        prowapi.PresubmitJob,
+				Context:     "unit",
+				Runs:        5,
+				FlakyRuns:   2,
+				FlakeRate:   0.4,
+				Quarantined: true,
+				FlakyPulls: []FlakyPull{
+					{Number: 1, SHA: "a", Flaked: now.Add(-2 * time.Hour)},
+					{Number: 3, SHA: "c", Flaked: now.Add(-time.Hour)},
+				},
+				Tests: []TestFlakiness{
+					{Name: "TestA", Runs: 5, Failures: 2, Flakes: 2, FlakeRate: 0.4},
+					{Name: "TestB", Runs: 5, Failures: 2, Flakes: 0, FlakeRate: 0},
+				},
+			},
+		},
+	}
+	actual := Aggregate(runs, cfg, now.Add(-24*time.Hour), now)
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected report: %s", diff)
+	}
+}
+
+func TestAggregateQuarantine(t *testing.T) {
+	flaky := []Run{
+		presubmitRun(1, "a", false, 2*time.Hour, map[string]TestOutcome{"TestA": failed}),
+		presubmitRun(1, "a", true, time.Hour, map[string]TestOutcome{"TestA": passed}),
+	}
+	testcases := []struct {
+		name     string
+		policies map[string]config.FlakinessPolicy
+		runs     []Run
+		expected bool
+	}{
+		{
+			name:     "flake rate reaches the threshold",
+			policies: map[string]config.FlakinessPolicy{"*": {MinRuns: 2, QuarantineThreshold: "50%"}},
+			runs:     flaky,
+			expected: true,
+		},
+		{
+			name:     "flake rate is below the threshold",
+			policies: map[string]config.FlakinessPolicy{"*": {MinRuns: 2, QuarantineThreshold: "60%"}},
+			runs:     flaky,
+		},
+		{
+			name:     "too few runs",
+			policies: map[string]config.FlakinessPolicy{"*": {QuarantineThreshold: "10%"}},
+			runs:     flaky,
+		},
+		{
+			name:     "no quarantine threshold",
+			policies: map[string]config.FlakinessPolicy{"*": {MinRuns: 1, Label: "flaky"}},
+			runs:     flaky,
+		},
+		{
+			name:     "no policy for the repo",
+			policies: map[string]config.FlakinessPolicy{"other": {MinRuns: 1, QuarantineThreshold: "10%"}},
+			runs:     flaky,
+		},
+		{
+			name:     "postsubmits are not quarantined",
+			policies: map[string]config.FlakinessPolicy{"*": {MinRuns: 1, QuarantineThreshold: "10%"}},
+			runs: []Run{
+				{Org: "org", Repo: "repo", Job: "pull-unit", Type: prowapi.PostsubmitJob, Revision: "a", Finished: now, Tests: map[string]TestOutcome{"TestA": failed}},
+				{Org: "org", Repo: "repo", Job: "pull-unit", Type: prowapi.PostsubmitJob, Revision: "a", Passed: true, Finished: now, Tests: map[string]TestOutcome{"TestA": passed}},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := Aggregate(tc.runs, &config.Flakiness{Policies: tc.policies}, now.Add(-time.Hour), now)
+			job := report.Job("org", "repo", "pull-unit")
+			if job == nil {
+				t.Fatalf("expected the job in the report, got %+v", report)
+			}
+			if job.Quarantined != tc.expected {
+				t.Errorf("expected quarantined %t, got %t", tc.expected, job.Quarantined)
+			}
+		})
+	}
+}
+
+func TestReportFilter(t *testing.T) {
+	report := &Report{Generated: now, Jobs: []JobFlakiness{
+		{Org: "org", Repo: "repo", Job: "pull-unit"},
+		{Org: "org", Repo: "repo", Job: "pull-e2e"},
+		{Org: "org", Repo: "other", Job: "pull-unit"},
+	}}
+	if diff := cmp.Diff([]JobFlakiness{{Org: "org", Repo: "repo", Job: "pull-unit"}, {Org: "org", Repo: "repo", Job: "pull-e2e"}}, report.Filter("org", "Repo", "").Jobs); diff != "" {
+		t.Errorf("unexpected jobs for the repo: %s", diff)
+	}
+	if diff := cmp.Diff([]JobFlakiness{{Org: "org", Repo: "repo", Job: "pull-unit"}, {Org: "org", Repo: "other", Job: "pull-unit"}}, report.Filter("", "", "pull-unit").Jobs); diff != "" {
+		t.Errorf("unexpected jobs for the job name: %s", diff)
+	}
+}
+
+func TestReadWriteReport(t *testing.T) {
+	ctx := context.Background()
+	opener, err := io.NewOpener(ctx, "", "")
+	if err != nil {
+		t.Fatalf("failed to create opener: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if _, err := ReadReport(ctx, opener, path); !io.IsNotExist(err) {
+		t.Errorf("expected reading a missing report to fail with not exist, got %v", err)
+	}
+
+	report := &Report{Generated: now, Since: now.Add(-time.Hour), Jobs: []JobFlakiness{{Org: "org", Repo: "repo", Job: "pull-unit", Runs: 2, FlakyRuns: 1, FlakeRate: 0.5}}}
+	if err := WriteReport(ctx, opener, path, report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	actual, err := ReadReport(ctx, opener, path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if diff := cmp.Diff(report, actual); diff != "" {
+		t.Errorf("read report differs from the written one: %s", diff)
+	}
+}
@@ -54,6 +54,7 @@ type Gangway struct {
 type ProwJobClient interface {
 	Create(context.Context, *prowcrd.ProwJob, metav1.CreateOptions) (*prowcrd.ProwJob, error)
 	Get(context.Context, string, metav1.GetOptions) (*prowcrd.ProwJob, error)
+	UpdateStatus(context.Context, *prowcrd.ProwJob, metav1.UpdateOptions) (*prowcrd.ProwJob, error)
 }
 
 // CreateJobExecution triggers a new Prow job.
@@ -559,7 +560,7 @@ func HandleProwJob(l *logrus.Entry,
 		}
 	}
 
-	if _, err := pjc.Create(context.TODO(), &prowJobCR, metav1.CreateOptions{}); err != nil {
+	if _, err := pjutil.CreateProwJob(context.TODO(), pjc, &prowJobCR); err != nil {
 		l.WithError(err).Errorf("failed to create job %q as %q", cjer.GetJobName(), prowJobCR.Name)
 		if reporterFunc != nil {
 			reporterFunc(&prowJobCR, prowcrd.ErrorState, err)
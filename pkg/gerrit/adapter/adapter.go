@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -40,6 +41,8 @@ import (
 	"sigs.k8s.io/prow/pkg/gerrit/source"
 	"sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/pjutil"
 )
 
@@ -59,6 +62,7 @@ var gerritMetrics = struct {
 	gerritRepoQueryDuration     *prometheus.HistogramVec
 	pickupChangeLatency         *prometheus.HistogramVec
 	jobCreationDuration         *prometheus.HistogramVec
+	events                      *prometheus.CounterVec
 }{
 	processingResults: prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "gerrit_processing_results",
@@ -131,6 +135,14 @@ var gerritMetrics = struct {
 		"org",
 		"repo",
 	}),
+	events: prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gerrit_events",
+		Help: "Count of events received from stream-events or the webhooks plugin, by instance, event type, and whether they woke up a project worker.",
+	}, []string{
+		"org",
+		"type",
+		"handled",
+	}),
 }
 
 func init() {
@@ -144,10 +156,12 @@ func init() {
 	prometheus.MustRegister(gerritMetrics.gerritRepoQueryDuration)
 	prometheus.MustRegister(gerritMetrics.pickupChangeLatency)
 	prometheus.MustRegister(gerritMetrics.jobCreationDuration)
+	prometheus.MustRegister(gerritMetrics.events)
 }
 
 type prowJobClient interface {
 	Create(context.Context, *prowapi.ProwJob, metav1.CreateOptions) (*prowapi.ProwJob, error)
+	UpdateStatus(context.Context, *prowapi.ProwJob, metav1.UpdateOptions) (*prowapi.ProwJob, error)
 }
 
 type gerritClient interface {
@@ -158,6 +172,8 @@ type gerritClient interface {
 	SetReview(instance, id, revision, message string, labels map[string]string) error
 	Account(instance string) (*gerrit.AccountInfo, error)
 	HasRelatedChanges(instance, id, revision string) (bool, error)
+	ListGroupMembers(instance, group string) ([]gerrit.AccountInfo, error)
+	QueryTopicChanges(instance, topic string) ([]gerrit.ChangeInfo, error)
 }
 
 // Controller manages gerrit changes.
@@ -173,8 +189,11 @@ type Controller struct {
 	inRepoConfigGetter          config.InRepoConfigGetter
 	inRepoConfigFailuresTracker map[string]bool
 	projectsWithWorker          map[string]bool
-	latestMux                   sync.Mutex
-	workerPoolSize              int
+	// projectWakeups wakes up the worker of a project before its next tick,
+	// e.g. when Gerrit sends an event for one of its changes. Guarded by lock.
+	projectWakeups map[string]chan struct{}
+	latestMux      sync.Mutex
+	workerPoolSize int
 }
 
 type LastSyncTracker interface {
@@ -184,7 +203,7 @@ type LastSyncTracker interface {
 
 // NewController returns a new gerrit controller client
 func NewController(ctx context.Context, prowJobClient prowv1.ProwJobInterface, op io.Opener,
-	ca *config.Agent, cookiefilePath, tokenPathOverride, lastSyncFallback string, workerPoolSize int, maxQPS, maxBurst int, ircg config.InRepoConfigGetter) *Controller {
+	ca *config.Agent, cookiefilePath, tokenPathOverride, lastSyncFallback string, workerPoolSize int, maxQPS, maxBurst int, ircg config.InRepoConfigGetter, opts ...client.Option) *Controller {
 
 	cfg := ca.Config
 	projectsOptOutHelpMap := map[string]sets.Set[string]{}
@@ -196,10 +215,15 @@ func NewController(ctx context.Context, prowJobClient prowv1.ProwJobInterface, o
 	if err := lastSyncTracker.Init(cfg().Gerrit.OrgReposConfig.AllRepos()); err != nil {
 		logrus.WithError(err).Fatal("Error initializing lastSyncFallback.")
 	}
-	gerritClient, err := client.NewClient(nil, maxQPS, maxBurst)
+	gerritClient, err := client.NewClient(nil, maxQPS, maxBurst, opts...)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error creating gerrit client.")
 	}
+	// Repos can fetch their inrepoconfig with the gerrit client rather than
+	// being cloned.
+	if ircc, ok := ircg.(*config.InRepoConfigCache); ok {
+		ircc.SetFileGetter(gerritClient.InRepoConfigFile)
+	}
 	c := &Controller{
 		prowJobClient:               prowJobClient,
 		config:                      cfg,
@@ -211,6 +235,7 @@ func NewController(ctx context.Context, prowJobClient prowv1.ProwJobInterface, o
 		inRepoConfigGetter:          ircg,
 		inRepoConfigFailuresTracker: map[string]bool{},
 		projectsWithWorker:          make(map[string]bool),
+		projectWakeups:              make(map[string]chan struct{}),
 		workerPoolSize:              workerPoolSize,
 	}
 
@@ -254,16 +279,16 @@ func (c *Controller) processChange(latest client.LastSyncState, changeChan <-cha
 		gerritMetrics.pickupChangeLatency.WithLabelValues(instance, change.Project).Observe(float64(time.Since(changeStruct.created).Seconds()))
 
 		log := log.WithFields(logrus.Fields{
-			"branch":   change.Branch,
-			"change":   change.Number,
-			"repo":     change.Project,
-			"revision": change.CurrentRevision,
+			"branch":             change.Branch,
+			logrusutil.PRField:   change.Number,
+			logrusutil.RepoField: change.Project,
+			"revision":           change.CurrentRevision,
 		})
 
 		now := time.Now()
 
 		result := client.ResultSuccess
-		if c.shouldTriggerJobs(change, lastProjectSyncTime) {
+		if c.shouldTriggerJobs(instance, change, lastProjectSyncTime) {
 			if err := c.triggerJobs(log, instance, change); err != nil {
 				result = client.ResultError
 				log.WithError(err).Info("Failed to trigger jobs based on change")
@@ -288,7 +313,7 @@ func (c *Controller) processChange(latest client.LastSyncState, changeChan <-cha
 
 func (c *Controller) processSingleProject(instance, project string) {
 	// Assumes the passed in instance was already normalized with https:// prefix.
-	log := logrus.WithFields(logrus.Fields{"host": instance, "repo": project})
+	log := logrus.WithFields(logrus.Fields{logrusutil.OrgField: instance, logrusutil.RepoField: project})
 	tracker := c.tracker.Current()
 	syncTime := time.Now()
 	if projects, ok := tracker[instance]; ok {
@@ -363,11 +388,15 @@ func checkAndLogQuery(log *logrus.Entry, changes []gerrit.ChangeInfo) {
 	log.Infof("Query returned changes: %v", seen.List())
 }
 
+func projectID(instance, project string) string {
+	return fmt.Sprintf("%s/%s", instance, project)
+}
+
 // Sync looks for newly made gerrit changes
 // and creates prowjobs according to specs
 func (c *Controller) Sync() {
 	// Identify projects without worker threads
-	id := func(instance, project string) string { return fmt.Sprintf("%s/%s", instance, project) }
+	id := projectID
 	needsWorker := map[string][]string{}
 	needsWorkerCount := map[string]int{}
 	for instance, projects := range c.config().Gerrit.OrgReposConfig.AllRepos() {
@@ -387,7 +416,11 @@ func (c *Controller) Sync() {
 		staggerIncement := c.config().Gerrit.TickInterval.Duration / time.Duration(needsWorkerCount[instance])
 		for _, project := range projects {
 			c.projectsWithWorker[id(instance, project)] = true
-			logrus.WithFields(logrus.Fields{"instance": instance, "repo": project}).Info("Starting worker for project.")
+			wakeup := make(chan struct{}, 1)
+			c.lock.Lock()
+			c.projectWakeups[id(instance, project)] = wakeup
+			c.lock.Unlock()
+			logrus.WithFields(logrus.Fields{logrusutil.OrgField: instance, logrusutil.RepoField: project}).Info("Starting worker for project.")
 			go func(instance, project string, staggerPosition int) {
 				// Stagger new worker threads across the loop period to reduce load on the Gerrit API and Git server.
 				napTime := staggerIncement * time.Duration(staggerPosition)
@@ -398,7 +431,14 @@ func (c *Controller) Sync() {
 				for {
 					timeDiff := time.Until(previousRun.Add(c.config().Gerrit.TickInterval.Duration))
 					if timeDiff > 0 {
-						time.Sleep(timeDiff)
+						// Events wake the worker up early, while the tick
+						// resyncs the changes of missed events.
+						timer := time.NewTimer(timeDiff)
+						select {
+						case <-timer.C:
+						case <-wakeup:
+							timer.Stop()
+						}
 					}
 					previousRun = time.Now()
 					c.processSingleProject(instance, project)
@@ -543,7 +583,7 @@ func (c *Controller) handleInRepoConfigError(err error, instance string, change
 
 // shouldTriggerJobs returns true if we should trigger jobs for the given
 // change.
-func (c *Controller) shouldTriggerJobs(change client.ChangeInfo, lastProjectSyncTime time.Time) bool {
+func (c *Controller) shouldTriggerJobs(instance string, change client.ChangeInfo, lastProjectSyncTime time.Time) bool {
 	// do not skip postsubmit jobs
 	if change.Status == client.Merged {
 		return true
@@ -552,6 +592,12 @@ func (c *Controller) shouldTriggerJobs(change client.ChangeInfo, lastProjectSync
 	if revision.Created.After(lastProjectSyncTime) {
 		return true
 	}
+	// The ok-to-test hashtag may have been added since, which only matters
+	// if not everyone is trusted. Whether a trusted account added it is
+	// checked when the jobs are triggered.
+	if change.Updated.After(lastProjectSyncTime) && slices.Contains(change.Hashtags, labels.OkToTest) && len(c.configAgent.Config().Gerrit.OrgReposConfig.TrustedGroups(instance, change.Project)) > 0 {
+		return true
+	}
 
 	for _, message := range currentMessages(change, lastProjectSyncTime) {
 		if c.messageContainsJobTriggeringCommand(message) {
@@ -722,22 +768,42 @@ func (c *Controller) triggerJobs(logger logrus.FieldLogger, instance string, cha
 			logger.WithField("lastUpdate", lastUpdate).Warnf("lastUpdate not found, falling back to now")
 		}
 
+		trusted, err := c.trustedAccounts(instance, change.Project)
+		if err != nil {
+			return err
+		}
+		okToTest := hasOkToTest(change, trusted)
+		ownerTrusted := trusted.isTrusted(change.Owner.AccountID)
+
 		revision := change.Revisions[change.CurrentRevision]
 		failedJobs := failedJobs(account.AccountID, revision.Number, change.Messages...)
 		failed, all := presubmitContexts(failedJobs, presubmits, logger)
-		messages := currentMessages(change, lastUpdate)
+		messages := trustedMessages(currentMessages(change, lastUpdate), trusted, change.Owner.AccountID, okToTest)
 		logger.WithField("failed", len(failed)).Debug("Failed jobs parsed from previous comments.")
 		filters := []pjutil.Filter{
 			messageFilter(messages, failed, all, triggerTimes, logger),
 		}
 		// Automatically trigger the Prow jobs if the revision is new and the
-		// change is not in WorkInProgress.
-		if revision.Created.Time.After(lastUpdate) && !change.WorkInProgress {
+		// change is not in WorkInProgress. Changes of untrusted owners need
+		// the ok-to-test hashtag, and adding it triggers the jobs of the
+		// current revision unless they already ran.
+		newRevision := revision.Created.Time.After(lastUpdate)
+		switch {
+		case change.WorkInProgress:
+		case newRevision && (ownerTrusted || okToTest):
 			filters = append(filters, &timeAnnotationFilter{
 				Filter:       pjutil.NewTestAllFilter(),
 				eventTime:    revision.Created.Time,
 				triggerTimes: triggerTimes,
 			})
+		case newRevision:
+			logger.WithField("owner", change.Owner.AccountID).Info("Not triggering jobs for the change of an untrusted owner without the ok-to-test hashtag.")
+		case okToTest && !ownerTrusted && change.Updated.Time.After(lastUpdate) && !commentedOn(account.AccountID, revision.Number, change.Messages...):
+			filters = append(filters, &timeAnnotationFilter{
+				Filter:       pjutil.NewTestAllFilter(),
+				eventTime:    change.Updated.Time,
+				triggerTimes: triggerTimes,
+			})
 		}
 		toTrigger, err := pjutil.FilterPresubmits(pjutil.NewAggregateFilter(filters), client.ChangedFilesProvider(&change), change.Branch, presubmits, logger)
 		if err != nil {
@@ -767,9 +833,19 @@ func (c *Controller) triggerJobs(logger logrus.FieldLogger, instance string, cha
 			}
 		}
 
+		var topicRefs []prowapi.Refs
+		if len(toTrigger) > 0 {
+			if topicRefs, err = c.topicRefs(logger, instance, change); err != nil {
+				return err
+			}
+		}
 		for _, presubmit := range toTrigger {
+			spec := pjutil.PresubmitSpec(presubmit, refs)
+			if len(topicRefs) > 0 {
+				spec.ExtraRefs = withTopicRefs(spec.ExtraRefs, topicRefs)
+			}
 			jobSpecs = append(jobSpecs, jobSpec{
-				spec:        pjutil.PresubmitSpec(presubmit, refs),
+				spec:        spec,
 				labels:      presubmit.Labels,
 				annotations: presubmit.Annotations,
 			})
@@ -783,9 +859,9 @@ func (c *Controller) triggerJobs(logger logrus.FieldLogger, instance string, cha
 
 		pj := pjutil.NewProwJob(jSpec.spec, labels, annotations, pjutil.RequireScheduling(schedulerEnabled))
 
-		logger := logger.WithField("prowjob", pj.Name)
+		logger := logger.WithFields(pjutil.ProwJobFields(&pj))
 		timeBeforeCreate := time.Now()
-		if _, err := c.prowJobClient.Create(context.TODO(), &pj, metav1.CreateOptions{}); err != nil {
+		if _, err := pjutil.CreateProwJob(context.TODO(), c.prowJobClient, &pj); err != nil {
 			logger.WithError(err).Errorf("Failed to create ProwJob")
 			continue
 		}
@@ -71,6 +71,24 @@ func (f *fca) Config() *config.Config {
 type fgc struct {
 	reviews     int
 	instanceMap map[string]*gerrit.AccountInfo
+	groups      map[string][]gerrit.AccountInfo
+	topics      map[string][]gerrit.ChangeInfo
+}
+
+func (f *fgc) ListGroupMembers(instance, group string) ([]gerrit.AccountInfo, error) {
+	members, ok := f.groups[group]
+	if !ok {
+		return nil, fmt.Errorf("group %s not found", group)
+	}
+	return members, nil
+}
+
+func (f *fgc) QueryTopicChanges(instance, topic string) ([]gerrit.ChangeInfo, error) {
+	changes, ok := f.topics[topic]
+	if !ok {
+		return nil, fmt.Errorf("topic %s not found", topic)
+	}
+	return changes, nil
 }
 
 func (f *fgc) HasRelatedChanges(instance, id, revision string) (bool, error) {
@@ -174,7 +192,12 @@ func TestShouldTriggerJobs(t *testing.T) {
 		configAgent: &config.Agent{},
 	}
 	presubmitTriggerRawString := "(?mi)/test\\s.*"
-	c.configAgent.Set(&config.Config{ProwConfig: config.ProwConfig{Gerrit: config.Gerrit{AllowedPresubmitTriggerReRawString: presubmitTriggerRawString}}})
+	c.configAgent.Set(&config.Config{ProwConfig: config.ProwConfig{Gerrit: config.Gerrit{
+		AllowedPresubmitTriggerReRawString: presubmitTriggerRawString,
+		OrgReposConfig: &config.GerritOrgRepoConfigs{
+			{Org: instance, Repos: []string{project}, TrustedGroups: []string{"trusted"}},
+		},
+	}}})
 	presubmitTriggerRegex, err := regexp.Compile(presubmitTriggerRawString)
 	if err != nil {
 		t.Fatalf("failed to compile regex for allowed presubmit triggers: %s", err.Error())
@@ -296,11 +319,31 @@ func TestShouldTriggerJobs(t *testing.T) {
 			latest: lastUpdateTime,
 			result: true,
 		},
+		{
+			name:     "trigger jobs when ok-to-test hashtag may have been added",
+			instance: instance,
+			change: gerrit.ChangeInfo{ID: "1", CurrentRevision: "10", Project: project, Hashtags: []string{"ok-to-test"}, Updated: makeStamp(now),
+				Revisions: map[string]gerrit.RevisionInfo{
+					"10": {Created: makeStamp(now.Add(-2 * time.Hour))},
+				}},
+			latest: lastUpdateTime,
+			result: true,
+		},
+		{
+			name:     "ignore ok-to-test hashtag when everyone is trusted",
+			instance: instance,
+			change: gerrit.ChangeInfo{ID: "1", CurrentRevision: "10", Project: "other-project", Hashtags: []string{"ok-to-test"}, Updated: makeStamp(now),
+				Revisions: map[string]gerrit.RevisionInfo{
+					"10": {Created: makeStamp(now.Add(-2 * time.Hour))},
+				}},
+			latest: lastUpdateTime,
+			result: false,
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			if got := c.shouldTriggerJobs(tc.change, tc.latest); got != tc.result {
+			if got := c.shouldTriggerJobs(tc.instance, tc.change, tc.latest); got != tc.result {
 				t.Errorf("want %t, got %t", tc.result, got)
 			}
 		})
@@ -3286,6 +3329,186 @@ func TestTriggerJobs(t *testing.T) {
 	}
 }
 
+func TestTriggerJobsTrustedGroups(t *testing.T) {
+	const (
+		instance  = "https://gerrit"
+		prow      = 42
+		trusted   = 1
+		untrusted = 2
+	)
+	lastUpdate := timeNow.Add(-time.Minute)
+	oldStamp := makeStamp(lastUpdate.Add(-time.Hour))
+	change := func(owner int, created gerrit.Timestamp, hashtags []string, messages ...gerrit.ChangeMessageInfo) client.ChangeInfo {
+		return client.ChangeInfo{
+			CurrentRevision: "1",
+			Project:         "trusted-repo",
+			Status:          "NEW",
+			Owner:           gerrit.AccountInfo{AccountID: owner},
+			Hashtags:        hashtags,
+			Updated:         stampNow,
+			Revisions: map[string]client.RevisionInfo{
+				"1": {Number: 1, Ref: "refs/changes/00/1/1", Created: created},
+			},
+			Messages: messages,
+		}
+	}
+	message := func(author int, text string) gerrit.ChangeMessageInfo {
+		return gerrit.ChangeMessageInfo{Author: gerrit.AccountInfo{AccountID: author}, Message: text, RevisionNumber: 1, Date: stampNow}
+	}
+	okToTest := []string{"ok-to-test"}
+	okToTestAdded := func(author int) gerrit.ChangeMessageInfo {
+		return gerrit.ChangeMessageInfo{Author: gerrit.AccountInfo{AccountID: author}, Message: "Hashtags added: needs-review, ok-to-test", Tag: "autogenerated:gerrit:setHashtag", RevisionNumber: 1, Date: oldStamp}
+	}
+
+	var testcases = []struct {
+		name      string
+		change    client.ChangeInfo
+		wantJobs  []string
+		wantError bool
+	}{
+		{
+			name:     "new revision of trusted owner triggers jobs",
+			change:   change(trusted, stampNow, nil),
+			wantJobs: []string{"always-runs"},
+		},
+		{
+			name:   "new revision of untrusted owner triggers no jobs",
+			change: change(untrusted, stampNow, nil),
+		},
+		{
+			name:     "new revision of untrusted owner with ok-to-test triggers jobs",
+			change:   change(untrusted, stampNow, okToTest, okToTestAdded(trusted)),
+			wantJobs: []string{"always-runs"},
+		},
+		{
+			name:     "adding ok-to-test to the change of an untrusted owner triggers jobs",
+			change:   change(untrusted, oldStamp, okToTest, okToTestAdded(trusted)),
+			wantJobs: []string{"always-runs"},
+		},
+		{
+			name:   "ok-to-test added by the untrusted owner triggers no jobs",
+			change: change(untrusted, stampNow, okToTest, okToTestAdded(untrusted)),
+		},
+		{
+			name:   "ok-to-test added by the untrusted owner after a trusted account removed it triggers no jobs",
+			change: change(untrusted, oldStamp, okToTest, okToTestAdded(trusted), okToTestAdded(untrusted)),
+		},
+		{
+			name:   "ok-to-test doesn't trigger jobs again",
+			change: change(untrusted, oldStamp, okToTest, okToTestAdded(trusted), message(prow, "Triggered 1 prow jobs")),
+		},
+		{
+			name:   "untrusted commenter can't trigger jobs",
+			change: change(trusted, oldStamp, nil, message(untrusted, "/test manual")),
+		},
+		{
+			name:     "trusted commenter triggers jobs of untrusted owner",
+			change:   change(untrusted, oldStamp, nil, message(trusted, "/test manual")),
+			wantJobs: []string{"manual"},
+		},
+		{
+			name:     "untrusted owner triggers jobs of change with ok-to-test",
+			change:   change(untrusted, oldStamp, okToTest, okToTestAdded(trusted), message(prow, "Triggered 1 prow jobs"), message(untrusted, "/test manual")),
+			wantJobs: []string{"manual"},
+		},
+		{
+			name:   "untrusted owner can't trigger jobs with the ok-to-test hashtag they added",
+			change: change(untrusted, oldStamp, okToTest, okToTestAdded(untrusted), message(untrusted, "/test manual")),
+		},
+		{
+			name: "failing to list trusted group members errors out",
+			change: func() client.ChangeInfo {
+				c := change(trusted, stampNow, nil)
+				c.Project = "broken-repo"
+				return c
+			}(),
+			wantError: true,
+		},
+	}
+
+	presubmits := []config.Presubmit{
+		{
+			JobBase:   config.JobBase{Name: "always-runs"},
+			AlwaysRun: true,
+			Reporter:  config.Reporter{Context: "always-runs", SkipReport: true},
+		},
+		{
+			JobBase:      config.JobBase{Name: "manual"},
+			RerunCommand: "/test manual",
+			Trigger:      `(?m)^/test manual`,
+			Reporter:     config.Reporter{Context: "manual", SkipReport: true},
+		},
+	}
+	if err := config.SetPresubmitRegexes(presubmits); err != nil {
+		t.Fatalf("could not set regexes: %v", err)
+	}
+	fca := &fca{
+		c: &config.Config{
+			JobConfig: config.JobConfig{
+				ProwYAMLGetterWithDefaults: fakeProwYAMLGetter,
+				ProwYAMLGetter:             fakeProwYAMLGetter,
+				PresubmitsStatic: map[string][]config.Presubmit{
+					"https://gerrit/trusted-repo": presubmits,
+					"https://gerrit/broken-repo":  presubmits,
+				},
+			},
+			ProwConfig: config.ProwConfig{
+				PodNamespace: namespace,
+				Gerrit: config.Gerrit{
+					OrgReposConfig: &config.GerritOrgRepoConfigs{
+						{Org: instance, Repos: []string{"trusted-repo"}, TrustedGroups: []string{"trusted"}},
+						{Org: instance, Repos: []string{"broken-repo"}, TrustedGroups: []string{"missing"}},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc // capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeProwJobClient := prowfake.NewSimpleClientset()
+			cache, err := createTestRepoCache(t, fca)
+			if err != nil {
+				t.Fatalf("error making test repo cache %v", err)
+			}
+			c := &Controller{
+				config:        fca.Config,
+				prowJobClient: fakeProwJobClient.ProwV1().ProwJobs("prowjobs"),
+				gc: &fgc{
+					instanceMap: map[string]*gerrit.AccountInfo{instance: {AccountID: prow}},
+					groups:      map[string][]gerrit.AccountInfo{"trusted": {{AccountID: trusted}}},
+				},
+				tracker:                     &fakeSync{val: client.LastSyncState{instance: {tc.change.Project: lastUpdate}}},
+				inRepoConfigGetter:          cache,
+				inRepoConfigFailuresTracker: make(map[string]bool),
+			}
+
+			err = c.triggerJobs(logrus.WithField("name", tc.name), instance, tc.change)
+			if tc.wantError {
+				if err == nil {
+					t.Fatal("Expected error, got nil.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expect no error, but got %v", err)
+			}
+
+			var gotJobs []string
+			for _, action := range fakeProwJobClient.Fake.Actions() {
+				if action, ok := action.(clienttesting.CreateActionImpl); ok {
+					gotJobs = append(gotJobs, action.Object.(*prowapi.ProwJob).Spec.Job)
+				}
+			}
+			if diff := cmp.Diff(tc.wantJobs, gotJobs); diff != "" {
+				t.Errorf("triggered jobs mismatch. Want(-), got(+):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestIsProjectExemptFromHelp(t *testing.T) {
 	var testcases = []struct {
 		name                   string
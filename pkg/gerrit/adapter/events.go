@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/gerrit/source"
+)
+
+// triggeringEvents are the types of events that may trigger jobs. Other
+// events, e.g. ref-updated that accompanies change-merged, are ignored.
+var triggeringEvents = sets.New[string](
+	"patchset-created",
+	"comment-added",
+	"change-merged",
+	"change-restored",
+	"wip-state-changed",
+	"hashtags-changed",
+)
+
+// Event is an event as sent by Gerrit stream-events and the webhooks plugin,
+// see https://gerrit-review.googlesource.com/Documentation/cmd-stream-events.html#events
+// Only the fields needed to find the project it's about are decoded.
+type Event struct {
+	Type   string       `json:"type"`
+	Change *EventChange `json:"change,omitempty"`
+}
+
+// EventChange is the change an event is about.
+type EventChange struct {
+	Project string `json:"project"`
+	Branch  string `json:"branch"`
+	Number  int    `json:"number"`
+	URL     string `json:"url"`
+}
+
+// HandleEvent wakes up the worker of the project the event is about, so that
+// its changes are synced right away rather than on the next tick. The sync
+// itself is the same as on a tick, so events that are missed or arrive out of
+// order are harmless. Returns true if a worker was woken up.
+func (c *Controller) HandleEvent(instance string, event Event) bool {
+	handled := c.handleEvent(instance, event)
+	gerritMetrics.events.WithLabelValues(instance, event.Type, strconv.FormatBool(handled)).Inc()
+	return handled
+}
+
+func (c *Controller) handleEvent(instance string, event Event) bool {
+	if !triggeringEvents.Has(event.Type) || event.Change == nil {
+		return false
+	}
+	c.lock.RLock()
+	wakeup, ok := c.projectWakeups[projectID(instance, event.Change.Project)]
+	c.lock.RUnlock()
+	if !ok {
+		return false
+	}
+	select {
+	case wakeup <- struct{}{}:
+	default:
+		// The worker already has a pending wakeup.
+	}
+	return true
+}
+
+// wakeupInstance wakes up the workers of all projects of an instance, e.g.
+// to sync changes whose events may have been missed.
+func (c *Controller) wakeupInstance(instance string) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	for id, wakeup := range c.projectWakeups {
+		if !strings.HasPrefix(id, instance+"/") {
+			continue
+		}
+		select {
+		case wakeup <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WebhookHandler returns the handler of events sent by the webhooks plugin of
+// Gerrit. Requests must carry the token returned by getToken, either as a
+// bearer token or, since the webhooks plugin can't set headers, as the
+// `token` query parameter of the webhook URL.
+func (c *Controller) WebhookHandler(getToken func() []byte) http.Handler {
+	return &webhookHandler{c: c, getToken: getToken}
+}
+
+type webhookHandler struct {
+	c        *Controller
+	getToken func() []byte
+}
+
+// ServeHTTP handles an event after authenticating it. The instance is taken
+// from the `instance` query parameter if set, e.g. when Gerrit doesn't know
+// its canonical web URL, and from the URL of the change otherwise.
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authenticated(r) {
+		http.Error(w, "401 Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode event: %v", err), http.StatusBadRequest)
+		return
+	}
+	instance := r.URL.Query().Get("instance")
+	if instance == "" && event.Change != nil {
+		changeURL, err := url.Parse(event.Change.URL)
+		if err == nil && changeURL.Host != "" {
+			instance = changeURL.Scheme + "://" + changeURL.Host
+		}
+	}
+	if instance == "" {
+		// Events without a change are ignored anyway.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.c.HandleEvent(source.NormalizeOrg(instance), event)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *webhookHandler) authenticated(r *http.Request) bool {
+	expected := h.getToken()
+	// An empty token would let anyone in.
+	if len(expected) == 0 {
+		return false
+	}
+	token := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(token), expected) == 1
+}
+
+// streamEventsCommand returns the command that streams the events of a Gerrit
+// instance over SSH.
+var streamEventsCommand = func(ctx context.Context, address *url.URL, keyPath string) *exec.Cmd {
+	args := []string{"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30"}
+	if keyPath != "" {
+		args = append(args, "-o", "IdentitiesOnly=yes", "-i", keyPath)
+	}
+	if port := address.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	host := address.Hostname()
+	if address.User != nil {
+		host = address.User.Username() + "@" + host
+	}
+	return exec.CommandContext(ctx, "ssh", append(args, host, "gerrit", "stream-events")...)
+}
+
+// StreamEvents handles the events of a Gerrit instance streamed over SSH from
+// an address like ssh://prow@gerrit.example.com:29418 until the context is
+// cancelled. The Gerrit account needs the Stream Events capability. It
+// reconnects with a backoff when the stream ends, and then wakes up all
+// workers of the instance to sync the changes of events it may have missed.
+func (c *Controller) StreamEvents(ctx context.Context, instance, address, keyPath string) error {
+	sshAddress, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse stream events address %q: %w", address, err)
+	}
+	if sshAddress.Scheme != "ssh" || sshAddress.Hostname() == "" {
+		return fmt.Errorf("stream events address %q must look like ssh://user@host:29418", address)
+	}
+	instance = source.NormalizeOrg(instance)
+	log := logrus.WithFields(logrus.Fields{"instance": instance, "address": address})
+
+	const minBackoff, maxBackoff = time.Second, 5 * time.Minute
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		if err := c.streamEventsOnce(ctx, instance, streamEventsCommand(ctx, sshAddress, keyPath), log); err != nil {
+			log.WithError(err).Warn("Streaming events failed.")
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		// A stream that was up for a while failed for a new reason.
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		c.wakeupInstance(instance)
+	}
+}
+
+func (c *Controller) streamEventsOnce(ctx context.Context, instance string, cmd *exec.Cmd, log *logrus.Entry) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start streaming events: %w", err)
+	}
+	log.Info("Streaming events.")
+
+	scanner := bufio.NewScanner(stdout)
+	// Events of changes with long commit messages can be large.
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			log.WithError(err).Debug("Failed to decode event.")
+			continue
+		}
+		c.HandleEvent(instance, event)
+	}
+	scanErr := scanner.Err()
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("stream ended: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if scanErr != nil {
+		return fmt.Errorf("failed to read events: %w", scanErr)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testInstance = "https://gerrit.example.com"
+
+func newEventsController() *Controller {
+	return &Controller{projectWakeups: map[string]chan struct{}{
+		projectID(testInstance, "foo"): make(chan struct{}, 1),
+		projectID(testInstance, "bar"): make(chan struct{}, 1),
+	}}
+}
+
+// wokenUp returns the projects of testInstance whose workers were woken up.
+func wokenUp(c *Controller) []string {
+	var res []string
+	for _, project := range []string{"bar", "foo"} {
+		select {
+		case <-c.projectWakeups[projectID(testInstance, project)]:
+			res = append(res, project)
+		default:
+		}
+	}
+	return res
+}
+
+func TestHandleEvent(t *testing.T) {
+	testcases := []struct {
+		name        string
+		instance    string
+		events      []Event
+		wantWokenUp []string
+	}{
+		{
+			name:        "patchset created",
+			instance:    testInstance,
+			events:      []Event{{Type: "patchset-created", Change: &EventChange{Project: "foo"}}},
+			wantWokenUp: []string{"foo"},
+		},
+		{
+			name:     "events are coalesced",
+			instance: testInstance,
+			events: []Event{
+				{Type: "comment-added", Change: &EventChange{Project: "foo"}},
+				{Type: "hashtags-changed", Change: &EventChange{Project: "foo"}},
+				{Type: "change-merged", Change: &EventChange{Project: "bar"}},
+			},
+			wantWokenUp: []string{"bar", "foo"},
+		},
+		{
+			name:     "irrelevant event",
+			instance: testInstance,
+			events:   []Event{{Type: "reviewer-deleted", Change: &EventChange{Project: "foo"}}},
+		},
+		{
+			name:     "event without change",
+			instance: testInstance,
+			events:   []Event{{Type: "ref-updated"}},
+		},
+		{
+			name:     "unknown project",
+			instance: testInstance,
+			events:   []Event{{Type: "patchset-created", Change: &EventChange{Project: "baz"}}},
+		},
+		{
+			name:     "unknown instance",
+			instance: "https://other.example.com",
+			events:   []Event{{Type: "patchset-created", Change: &EventChange{Project: "foo"}}},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newEventsController()
+			for _, event := range tc.events {
+				c.HandleEvent(tc.instance, event)
+			}
+			if got := wokenUp(c); !reflect.DeepEqual(tc.wantWokenUp, got) {
+				t.Errorf("expected %v to be woken up, got %v", tc.wantWokenUp, got)
+			}
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	testcases := []struct {
+		name        string
+		method      string
+		query       string
+		header      http.Header
+		body        string
+		wantStatus  int
+		wantWokenUp []string
+	}{
+		{
+			name:        "instance from change URL",
+			method:      http.MethodPost,
+			header:      http.Header{"Authorization": []string{"Bearer secret"}},
+			body:        `{"type": "patchset-created", "change": {"project": "foo", "url": "https://gerrit.example.com/c/foo/+/1"}}`,
+			wantStatus:  http.StatusNoContent,
+			wantWokenUp: []string{"foo"},
+		},
+		{
+			name:        "instance from query",
+			method:      http.MethodPost,
+			query:       "?instance=gerrit.example.com&token=secret",
+			body:        `{"type": "comment-added", "change": {"project": "bar", "url": "https://other.example.com/gerrit/c/bar/+/1"}}`,
+			wantStatus:  http.StatusNoContent,
+			wantWokenUp: []string{"bar"},
+		},
+		{
+			name:       "event without change",
+			method:     http.MethodPost,
+			query:      "?token=secret",
+			body:       `{"type": "ref-updated", "refUpdate": {"project": "foo"}}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid event",
+			method:     http.MethodPost,
+			query:      "?token=secret",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing token",
+			method:     http.MethodPost,
+			body:       `{"type": "patchset-created", "change": {"project": "foo", "url": "https://gerrit.example.com/c/foo/+/1"}}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong token",
+			method:     http.MethodPost,
+			header:     http.Header{"Authorization": []string{"Bearer wrong"}},
+			query:      "?token=secret",
+			body:       `{"type": "patchset-created", "change": {"project": "foo", "url": "https://gerrit.example.com/c/foo/+/1"}}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newEventsController()
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/"+tc.query, strings.NewReader(tc.body))
+			for k, v := range tc.header {
+				r.Header[k] = v
+			}
+			c.WebhookHandler(func() []byte { return []byte("secret") }).ServeHTTP(w, r)
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+			if got := wokenUp(c); !reflect.DeepEqual(tc.wantWokenUp, got) {
+				t.Errorf("expected %v to be woken up, got %v", tc.wantWokenUp, got)
+			}
+		})
+	}
+}
+
+func TestStreamEventsOnce(t *testing.T) {
+	c := newEventsController()
+	events := strings.Join([]string{
+		`{"type": "patchset-created", "change": {"project": "foo"}}`,
+		`not an event`,
+		`{"type": "change-merged", "change": {"project": "bar"}}`,
+	}, "\n")
+	cmd := exec.Command("echo", events)
+	if err := c.streamEventsOnce(context.Background(), testInstance, cmd, logrus.WithField("test", t.Name())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := wokenUp(c), []string{"bar", "foo"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("expected %v to be woken up, got %v", want, got)
+	}
+
+	cmd = exec.Command("sh", "-c", "echo 'Permission denied (publickey).' >&2; exit 255")
+	err := c.streamEventsOnce(context.Background(), testInstance, cmd, logrus.WithField("test", t.Name()))
+	if err == nil || !strings.Contains(err.Error(), "Permission denied") {
+		t.Errorf("expected the error to include the output of ssh, got %v", err)
+	}
+}
+
+func TestStreamEventsCommand(t *testing.T) {
+	address, err := url.Parse("ssh://prow@gerrit.example.com:29418")
+	if err != nil {
+		t.Fatalf("failed to parse address: %v", err)
+	}
+	cmd := streamEventsCommand(context.Background(), address, "/etc/ssh-key/key")
+	want := []string{"ssh", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", "-o", "IdentitiesOnly=yes", "-i", "/etc/ssh-key/key", "-p", "29418", "prow@gerrit.example.com", "gerrit", "stream-events"}
+	if !reflect.DeepEqual(want, cmd.Args) {
+		t.Errorf("expected command %v, got %v", want, cmd.Args)
+	}
+
+	for _, address := range []string{"https://gerrit.example.com", "gerrit.example.com:29418", "ssh://"} {
+		if err := newEventsController().StreamEvents(context.Background(), testInstance, address, ""); err == nil {
+			t.Errorf("expected an error for address %q", address)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/gerrit/client"
+)
+
+// TopicChanges returns the changes of the topic of a change that are tested
+// with it, sorted by project and number. A job checks out a repo once, and its
+// main refs have the change alone, so other changes of the topic in the
+// project of the change, or on another branch than the first change of their
+// project, are not tested with it.
+func TopicChanges(change client.ChangeInfo, topicChanges []client.ChangeInfo) []client.ChangeInfo {
+	changes := append([]client.ChangeInfo(nil), topicChanges...)
+	// Gerrit sorts by the update time, which would reorder the extra refs
+	// every time a change of the topic is updated.
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Project != changes[j].Project {
+			return changes[i].Project < changes[j].Project
+		}
+		return changes[i].Number < changes[j].Number
+	})
+
+	var res []client.ChangeInfo
+	branches := map[string]string{}
+	for _, other := range changes {
+		if other.Number == change.Number || other.Project == change.Project {
+			continue
+		}
+		if branch, ok := branches[other.Project]; !ok {
+			branches[other.Project] = other.Branch
+		} else if branch != other.Branch {
+			continue
+		}
+		res = append(res, other)
+	}
+	return res
+}
+
+// topicRefs returns the refs of the other open changes of the topic of a
+// change, one per project, for its presubmits to check out as extra refs if
+// topic testing is enabled for the project.
+func (c *Controller) topicRefs(logger logrus.FieldLogger, instance string, change client.ChangeInfo) ([]prowapi.Refs, error) {
+	if change.Topic == "" || !c.config().Gerrit.OrgReposConfig.TopicTesting(instance, change.Project) {
+		return nil, nil
+	}
+	changes, err := c.gc.QueryTopicChanges(instance, change.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("QueryTopicChanges: %w", err)
+	}
+	tested := TopicChanges(change, changes)
+	if skipped := len(changes) - len(tested) - 1; skipped > 0 {
+		logger.WithFields(logrus.Fields{"topic": change.Topic, "skipped": skipped}).Info("Not testing the changes of the topic in the same project or on other branches.")
+	}
+
+	var res []prowapi.Refs
+	for i := 0; i < len(tested); {
+		project, branch := tested[i].Project, tested[i].Branch
+		j := i + 1
+		for j < len(tested) && tested[j].Project == project {
+			j++
+		}
+		baseSHA, err := c.gc.GetBranchRevision(instance, project, branch)
+		if err != nil {
+			return nil, fmt.Errorf("GetBranchRevision of %s: %w", project, err)
+		}
+		refs, err := CreateRefs(instance, project, branch, baseSHA, tested[i:j]...)
+		if err != nil {
+			return nil, fmt.Errorf("createRefs for topic %q: %w", change.Topic, err)
+		}
+		res = append(res, refs)
+		i = j
+	}
+	return res, nil
+}
+
+// withTopicRefs adds the refs of the changes of a topic to the extra refs of
+// a job. The extra refs of a repo that the job already checks out are
+// replaced by the ones of the topic, keeping how the job clones the repo.
+func withTopicRefs(extraRefs, topicRefs []prowapi.Refs) []prowapi.Refs {
+	res := append([]prowapi.Refs(nil), extraRefs...)
+	for _, topic := range topicRefs {
+		replaced := false
+		for i := range res {
+			if res[i].Org != topic.Org || res[i].Repo != topic.Repo {
+				continue
+			}
+			res[i].BaseRef, res[i].BaseSHA, res[i].BaseLink = topic.BaseRef, topic.BaseSHA, topic.BaseLink
+			res[i].Pulls = topic.Pulls
+			replaced = true
+		}
+		if !replaced {
+			res = append(res, topic)
+		}
+	}
+	return res
+}
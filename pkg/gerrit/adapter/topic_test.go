@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	gerrit "github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	clienttesting "k8s.io/client-go/testing"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	prowfake "sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/gerrit/client"
+)
+
+func topicChange(number int, project, branch, topic string) client.ChangeInfo {
+	revision := fmt.Sprintf("rev-%d", number)
+	return client.ChangeInfo{
+		Number:          number,
+		Project:         project,
+		Branch:          branch,
+		Topic:           topic,
+		Status:          "NEW",
+		CurrentRevision: revision,
+		Created:         stampNow,
+		Updated:         stampNow,
+		Revisions: map[string]client.RevisionInfo{
+			revision: {Number: 1, Ref: fmt.Sprintf("refs/changes/00/%d/1", number), Created: stampNow},
+		},
+	}
+}
+
+func TestTriggerJobsTopic(t *testing.T) {
+	const instance = "https://gerrit"
+	lastUpdate := timeNow.Add(-time.Minute)
+	topics := map[string][]gerrit.ChangeInfo{
+		"feature": {
+			topicChange(6, "repo-c", "master", "feature"),
+			topicChange(3, "repo-b", "master", "feature"),
+			topicChange(1, "repo-a", "master", "feature"),
+			topicChange(4, "repo-a", "master", "feature"),
+			topicChange(5, "repo-b", "release", "feature"),
+			topicChange(2, "repo-b", "master", "feature"),
+		},
+	}
+	pulls := func(numbers ...int) []prowapi.Pull {
+		var res []prowapi.Pull
+		for _, number := range numbers {
+			res = append(res, prowapi.Pull{Number: number, SHA: fmt.Sprintf("rev-%d", number)})
+		}
+		return res
+	}
+
+	var testcases = []struct {
+		name          string
+		change        client.ChangeInfo
+		wantExtraRefs []prowapi.Refs
+		wantError     bool
+	}{
+		{
+			name:   "changes of the topic in other projects are extra refs",
+			change: topicChange(1, "repo-a", "master", "feature"),
+			wantExtraRefs: []prowapi.Refs{
+				{Org: instance, Repo: "repo-b", BaseRef: "master", BaseSHA: "abc", Pulls: pulls(2, 3)},
+				{Org: instance, Repo: "repo-c", BaseRef: "master", BaseSHA: "abc", Pulls: pulls(6)},
+			},
+		},
+		{
+			name:   "change without topic",
+			change: topicChange(1, "repo-a", "master", ""),
+		},
+		{
+			name:   "topic testing not enabled",
+			change: topicChange(1, "repo-off", "master", "feature"),
+		},
+		{
+			name:      "failing to query the topic errors out",
+			change:    topicChange(1, "repo-a", "master", "missing"),
+			wantError: true,
+		},
+	}
+
+	presubmits := []config.Presubmit{
+		{
+			JobBase:   config.JobBase{Name: "always-runs"},
+			AlwaysRun: true,
+			Reporter:  config.Reporter{Context: "always-runs", SkipReport: true},
+		},
+	}
+	if err := config.SetPresubmitRegexes(presubmits); err != nil {
+		t.Fatalf("could not set regexes: %v", err)
+	}
+	fca := &fca{
+		c: &config.Config{
+			JobConfig: config.JobConfig{
+				ProwYAMLGetterWithDefaults: fakeProwYAMLGetter,
+				ProwYAMLGetter:             fakeProwYAMLGetter,
+				PresubmitsStatic: map[string][]config.Presubmit{
+					"https://gerrit/repo-a":   presubmits,
+					"https://gerrit/repo-off": presubmits,
+				},
+			},
+			ProwConfig: config.ProwConfig{
+				PodNamespace: namespace,
+				Gerrit: config.Gerrit{
+					OrgReposConfig: &config.GerritOrgRepoConfigs{
+						{Org: instance, Repos: []string{"repo-a", "repo-off"}},
+						{Org: instance, Repos: []string{"repo-a"}, TopicTesting: true},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc // capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeProwJobClient := prowfake.NewSimpleClientset()
+			cache, err := createTestRepoCache(t, fca)
+			if err != nil {
+				t.Fatalf("error making test repo cache %v", err)
+			}
+			c := &Controller{
+				config:        fca.Config,
+				prowJobClient: fakeProwJobClient.ProwV1().ProwJobs("prowjobs"),
+				gc: &fgc{
+					instanceMap: map[string]*gerrit.AccountInfo{instance: {AccountID: 42}},
+					topics:      topics,
+				},
+				tracker:                     &fakeSync{val: client.LastSyncState{instance: {tc.change.Project: lastUpdate}}},
+				inRepoConfigGetter:          cache,
+				inRepoConfigFailuresTracker: make(map[string]bool),
+			}
+
+			err = c.triggerJobs(logrus.WithField("name", tc.name), instance, tc.change)
+			if tc.wantError {
+				if err == nil {
+					t.Fatal("Expected error, got nil.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expect no error, but got %v", err)
+			}
+
+			var created int
+			for _, action := range fakeProwJobClient.Fake.Actions() {
+				action, ok := action.(clienttesting.CreateActionImpl)
+				if !ok {
+					continue
+				}
+				created++
+				var gotExtraRefs []prowapi.Refs
+				for _, refs := range action.Object.(*prowapi.ProwJob).Spec.ExtraRefs {
+					got := prowapi.Refs{Org: refs.Org, Repo: refs.Repo, BaseRef: refs.BaseRef, BaseSHA: refs.BaseSHA}
+					for _, pull := range refs.Pulls {
+						got.Pulls = append(got.Pulls, prowapi.Pull{Number: pull.Number, SHA: pull.SHA})
+					}
+					gotExtraRefs = append(gotExtraRefs, got)
+				}
+				if diff := cmp.Diff(tc.wantExtraRefs, gotExtraRefs); diff != "" {
+					t.Errorf("extra refs mismatch. Want(-), got(+):\n%s", diff)
+				}
+			}
+			if created != 1 {
+				t.Errorf("expected 1 job to be triggered, got %d", created)
+			}
+		})
+	}
+}
+
+func TestWithTopicRefs(t *testing.T) {
+	extraRefs := []prowapi.Refs{
+		{Org: "https://gerrit", Repo: "tools", BaseRef: "master"},
+		{Org: "https://gerrit", Repo: "repo-b", BaseRef: "main", BaseSHA: "old", PathAlias: "src/repo-b"},
+	}
+	topicRefs := []prowapi.Refs{
+		{Org: "https://gerrit", Repo: "repo-b", BaseRef: "master", BaseSHA: "abc", Pulls: []prowapi.Pull{{Number: 2}}},
+		{Org: "https://gerrit", Repo: "repo-c", BaseRef: "master", BaseSHA: "def", Pulls: []prowapi.Pull{{Number: 3}}},
+	}
+	expected := []prowapi.Refs{
+		{Org: "https://gerrit", Repo: "tools", BaseRef: "master"},
+		{Org: "https://gerrit", Repo: "repo-b", BaseRef: "master", BaseSHA: "abc", PathAlias: "src/repo-b", Pulls: []prowapi.Pull{{Number: 2}}},
+		{Org: "https://gerrit", Repo: "repo-c", BaseRef: "master", BaseSHA: "def", Pulls: []prowapi.Pull{{Number: 3}}},
+	}
+	if diff := cmp.Diff(expected, withTopicRefs(extraRefs, topicRefs)); diff != "" {
+		t.Errorf("extra refs mismatch. Want(-), got(+):\n%s", diff)
+	}
+	if extraRefs[1].BaseSHA != "old" {
+		t.Error("expected the extra refs of the job to be left unchanged")
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package adapter
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -26,9 +28,103 @@ import (
 
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/gerrit/client"
+	"sigs.k8s.io/prow/pkg/labels"
 	"sigs.k8s.io/prow/pkg/pjutil"
 )
 
+// trustedAccounts are the IDs of the accounts trusted to run presubmits. A
+// nil set trusts everyone.
+type trustedAccounts sets.Set[int]
+
+func (t trustedAccounts) isTrusted(account int) bool {
+	return t == nil || sets.Set[int](t).Has(account)
+}
+
+// trustedAccounts returns the members of the groups trusted to run presubmits
+// of the project, or nil if everyone is trusted.
+func (c *Controller) trustedAccounts(instance, project string) (trustedAccounts, error) {
+	groups := c.config().Gerrit.OrgReposConfig.TrustedGroups(instance, project)
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	trusted := sets.New[int]()
+	for _, group := range groups {
+		members, err := c.gc.ListGroupMembers(instance, group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of trusted group %q: %w", group, err)
+		}
+		for _, member := range members {
+			trusted.Insert(member.AccountID)
+		}
+	}
+	return trustedAccounts(trusted), nil
+}
+
+// setHashtagsTag is the tag of the messages Gerrit adds to a change when its
+// hashtags are edited, e.g. "Hashtags added: foo, ok-to-test".
+const setHashtagsTag = "autogenerated:gerrit:setHashtag"
+
+// hasOkToTest returns true if a trusted account added the ok-to-test hashtag
+// to the change, which trusts it to run presubmits like the label does on
+// GitHub. Change owners can edit the hashtags of their own changes, so who
+// added it is looked up in the messages of the change.
+func hasOkToTest(change gerrit.ChangeInfo, trusted trustedAccounts) bool {
+	if !slices.Contains(change.Hashtags, labels.OkToTest) {
+		return false
+	}
+	if trusted == nil {
+		return true
+	}
+	// The hashtag may have been removed and added again since, so only the
+	// last time it was added counts.
+	for i := len(change.Messages) - 1; i >= 0; i-- {
+		if message := change.Messages[i]; addsHashtag(message, labels.OkToTest) {
+			return trusted.isTrusted(message.Author.AccountID)
+		}
+	}
+	return false
+}
+
+// addsHashtag returns true if the message records that the hashtag was added.
+func addsHashtag(message gerrit.ChangeMessageInfo, hashtag string) bool {
+	if message.Tag != setHashtagsTag {
+		return false
+	}
+	for _, line := range strings.Split(message.Message, "\n") {
+		_, added, ok := strings.Cut(line, " added: ")
+		if !ok || !strings.HasPrefix(line, "Hashtag") {
+			continue
+		}
+		if slices.Contains(strings.Split(added, ", "), hashtag) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedMessages returns the messages whose commands should be honored: the
+// ones of trusted accounts and, if the change is ok to test, of its owner.
+func trustedMessages(messages []gerrit.ChangeMessageInfo, trusted trustedAccounts, owner int, okToTest bool) []gerrit.ChangeMessageInfo {
+	var res []gerrit.ChangeMessageInfo
+	for _, message := range messages {
+		if trusted.isTrusted(message.Author.AccountID) || (okToTest && message.Author.AccountID == owner) {
+			res = append(res, message)
+		}
+	}
+	return res
+}
+
+// commentedOn returns true if the account commented on the revision, e.g.
+// because Prow triggered jobs for it.
+func commentedOn(account, revision int, messages ...gerrit.ChangeMessageInfo) bool {
+	for _, message := range messages {
+		if message.Author.AccountID == account && message.RevisionNumber == revision {
+			return true
+		}
+	}
+	return false
+}
+
 // presubmitContexts returns the set of failing and all job names contained in the reports.
 func presubmitContexts(failed sets.Set[string], presubmits []config.Presubmit, logger logrus.FieldLogger) (sets.Set[string], sets.Set[string]) {
 	allContexts := sets.Set[string]{}
@@ -363,3 +363,72 @@ func TestMessageFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestHasOkToTest(t *testing.T) {
+	const trusted, untrusted = 1, 2
+	setHashtags := func(author int, message string) gerrit.ChangeMessageInfo {
+		return gerrit.ChangeMessageInfo{Author: gerrit.AccountInfo{AccountID: author}, Message: message, Tag: setHashtagsTag}
+	}
+	testcases := []struct {
+		name     string
+		hashtags []string
+		messages []gerrit.ChangeMessageInfo
+		trusted  trustedAccounts
+		expected bool
+	}{
+		{
+			name:     "added by a trusted account",
+			hashtags: []string{"ok-to-test"},
+			messages: []gerrit.ChangeMessageInfo{setHashtags(trusted, "Hashtag added: ok-to-test")},
+			trusted:  trustedAccounts(sets.New(trusted)),
+			expected: true,
+		},
+		{
+			name:     "added along with other hashtags",
+			hashtags: []string{"ok-to-test", "wip"},
+			messages: []gerrit.ChangeMessageInfo{setHashtags(trusted, "Hashtag removed: needs-review\nHashtags added: ok-to-test, wip")},
+			trusted:  trustedAccounts(sets.New(trusted)),
+			expected: true,
+		},
+		{
+			name:     "added by an untrusted account",
+			hashtags: []string{"ok-to-test"},
+			messages: []gerrit.ChangeMessageInfo{setHashtags(untrusted, "Hashtag added: ok-to-test")},
+			trusted:  trustedAccounts(sets.New(trusted)),
+		},
+		{
+			name:     "added again by an untrusted account",
+			hashtags: []string{"ok-to-test"},
+			messages: []gerrit.ChangeMessageInfo{
+				setHashtags(trusted, "Hashtag added: ok-to-test"),
+				setHashtags(untrusted, "Hashtag removed: ok-to-test"),
+				setHashtags(untrusted, "Hashtag added: ok-to-test"),
+			},
+			trusted: trustedAccounts(sets.New(trusted)),
+		},
+		{
+			name:     "comment mentioning the hashtag",
+			hashtags: []string{"ok-to-test"},
+			messages: []gerrit.ChangeMessageInfo{{Author: gerrit.AccountInfo{AccountID: trusted}, Message: "Hashtag added: ok-to-test"}},
+			trusted:  trustedAccounts(sets.New(trusted)),
+		},
+		{
+			name:     "hashtag removed",
+			messages: []gerrit.ChangeMessageInfo{setHashtags(trusted, "Hashtag added: ok-to-test")},
+			trusted:  trustedAccounts(sets.New(trusted)),
+		},
+		{
+			name:     "everyone is trusted",
+			hashtags: []string{"ok-to-test"},
+			expected: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			change := gerrit.ChangeInfo{Hashtags: tc.hashtags, Messages: tc.messages}
+			if actual := hasOkToTest(change, tc.trusted); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
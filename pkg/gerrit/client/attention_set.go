@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net/url"
+
+	gerrit "github.com/andygrunwald/go-gerrit"
+)
+
+// AttentionSetInput is the input to add users to or remove users from the
+// attention set of a change, see
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#attention-set-input
+type AttentionSetInput struct {
+	User   string `json:"user,omitempty"`
+	Reason string `json:"reason"`
+	Notify string `json:"notify,omitempty"`
+}
+
+// attentionSetService implements the attention set endpoints, which
+// go-gerrit doesn't support.
+type attentionSetService struct {
+	client *gerrit.Client
+}
+
+// AddToAttentionSet adds a user to the attention set of a change.
+func (s *attentionSetService) AddToAttentionSet(changeID string, input *AttentionSetInput) (*gerrit.AccountInfo, *gerrit.Response, error) {
+	u := fmt.Sprintf("changes/%s/attention", changeID)
+
+	req, err := s.client.NewRequest("POST", u, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := new(gerrit.AccountInfo)
+	resp, err := s.client.Do(req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return v, resp, err
+}
+
+// RemoveFromAttentionSet removes a user from the attention set of a change.
+func (s *attentionSetService) RemoveFromAttentionSet(changeID, accountID string, input *AttentionSetInput) (*gerrit.Response, error) {
+	u := fmt.Sprintf("changes/%s/attention/%s/delete", changeID, url.PathEscape(accountID))
+
+	req, err := s.client.NewRequest("POST", u, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
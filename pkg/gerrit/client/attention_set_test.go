@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func TestAttentionSet(t *testing.T) {
+	type request struct {
+		path  string
+		input AttentionSetInput
+	}
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected a POST request, got %s", r.Method)
+		}
+		var input AttentionSetInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Errorf("failed to decode input: %v", err)
+		}
+		requests = append(requests, request{path: r.URL.Path, input: input})
+		if r.URL.Path == "/changes/missing/attention" {
+			http.Error(w, "Not found: missing", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(")]}'\n{\"_account_id\": 1000}"))
+	}))
+	defer server.Close()
+
+	c := &Client{handlers: map[string]*gerritInstanceHandler{}}
+	if err := c.UpdateClients(map[string]map[string]*config.GerritQueryFilter{server.URL: nil}); err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := c.AddToAttentionSet(server.URL, "123", "1000", "Prow jobs failed"); err != nil {
+		t.Errorf("unexpected error adding to attention set: %v", err)
+	}
+	if err := c.RemoveFromAttentionSet(server.URL, "123", "self", "Prow jobs passed"); err != nil {
+		t.Errorf("unexpected error removing from attention set: %v", err)
+	}
+	if err := c.AddToAttentionSet(server.URL, "missing", "1000", "Prow jobs failed"); err == nil {
+		t.Error("expected an error for a missing change")
+	}
+	if err := c.AddToAttentionSet("unknown", "123", "1000", "Prow jobs failed"); err == nil {
+		t.Error("expected an error for an unknown instance")
+	}
+
+	expected := []request{
+		{path: "/changes/123/attention", input: AttentionSetInput{User: "1000", Reason: "Prow jobs failed"}},
+		{path: "/changes/123/attention/self/delete", input: AttentionSetInput{Reason: "Prow jobs passed"}},
+		{path: "/changes/missing/attention", input: AttentionSetInput{User: "1000", Reason: "Prow jobs failed"}},
+	}
+	if diff := cmp.Diff(expected, requests, cmp.AllowUnexported(request{})); diff != "" {
+		t.Errorf("unexpected requests (-want +got):\n%s", diff)
+	}
+}
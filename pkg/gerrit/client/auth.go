@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/prow/pkg/flagutil"
+)
+
+// authCookie is the cookie the token is sent in.
+const authCookie = "o"
+
+// TokenSource provides the tokens to authenticate to Gerrit with, e.g. from an
+// external issuer of short-lived credentials. Token is called often, so
+// sources that are expensive to query should cache the token.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// RefreshableTokenSource is a TokenSource with a cached token, which Refresh
+// drops, e.g. after Gerrit rejected it before it was expected to expire.
+type RefreshableTokenSource interface {
+	TokenSource
+	Refresh()
+}
+
+// CommandTokenSource runs a command that prints a token, e.g.
+// `gcloud auth print-access-token`, and caches the token for an interval.
+type CommandTokenSource struct {
+	command         []string
+	refreshInterval time.Duration
+
+	lock    sync.Mutex
+	token   string
+	fetched time.Time
+}
+
+// NewCommandTokenSource returns a source of tokens printed by the command,
+// which are refreshed once they are older than the interval.
+func NewCommandTokenSource(command []string, refreshInterval time.Duration) (*CommandTokenSource, error) {
+	if len(command) == 0 {
+		return nil, errors.New("no token command")
+	}
+	return &CommandTokenSource{command: command, refreshInterval: refreshInterval}, nil
+}
+
+// Token returns the cached token, or a new one from the command.
+func (s *CommandTokenSource) Token() (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.token != "" && time.Since(s.fetched) < s.refreshInterval {
+		return s.token, nil
+	}
+	out, err := exec.Command(s.command[0], s.command[1:]...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("token command failed: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("token command failed: %w", err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", errors.New("token command printed no token")
+	}
+	s.token, s.fetched = token, time.Now()
+	return s.token, nil
+}
+
+// Refresh makes the next call to Token run the command.
+func (s *CommandTokenSource) Refresh() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.token = ""
+}
+
+// Option configures a Client.
+type Option func(*Client) error
+
+// WithHostThrottle throttles the requests to a Gerrit host, e.g.
+// android-review.googlesource.com, separately from the other hosts, which
+// share the throttle of the client.
+func WithHostThrottle(host string, maxQPS, maxBurst int) Option {
+	return func(c *Client) error {
+		if u, err := url.Parse(host); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		return c.throttle.Throttle(maxQPS*3600, maxBurst, host)
+	}
+}
+
+// WithTokenSource authenticates with the tokens of the source, rather than
+// with the ones read from a cookie file or token path.
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Client) error {
+		c.tokenSource = source
+		return nil
+	}
+}
+
+// reauthenticate gets a new token after Gerrit rejected the one a request was
+// sent with, e.g. because it expired before the periodic refresh. Returns
+// false if there is no other token to retry the request with.
+func (c *Client) reauthenticate(rejected string) (string, bool) {
+	// Serialize the refreshes of concurrently rejected requests.
+	c.reauthLock.Lock()
+	defer c.reauthLock.Unlock()
+	c.lock.RLock()
+	auth, current := c.authentication, c.previousToken
+	c.lock.RUnlock()
+	if auth == nil {
+		return "", false
+	}
+	if current == rejected {
+		if source, ok := c.tokenSource.(RefreshableTokenSource); ok {
+			source.Refresh()
+		}
+		c.authenticateOnce()
+		c.lock.RLock()
+		current = c.previousToken
+		c.lock.RUnlock()
+	}
+	return current, current != "" && current != rejected
+}
+
+// retryUnauthorized retries a request that Gerrit rejected as unauthorized
+// once, with a new token.
+func (rt *roundTripperWithThrottleAndHeader) retryUnauthorized(r *http.Request, resp *http.Response) (*http.Response, error) {
+	if rt.reauthenticate == nil || (r.Body != nil && r.GetBody == nil) {
+		return resp, nil
+	}
+	var rejected string
+	if cookie, err := r.Cookie(authCookie); err == nil {
+		rejected = cookie.Value
+	}
+	token, ok := rt.reauthenticate(rejected)
+	if !ok {
+		return resp, nil
+	}
+	retry := r.Clone(r.Context())
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	retry.Header.Del("Cookie")
+	retry.AddCookie(&http.Cookie{Name: authCookie, Value: token})
+	// Drain the rejected response, so that its connection can be reused.
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	rt.Wait(retry.Context(), retry.URL.Host)
+	return rt.upstream.RoundTrip(retry)
+}
+
+// OptionsFromFlags returns the options of a Client that are set by the flags
+// of a component.
+func OptionsFromFlags(o *flagutil.GerritOptions) ([]Option, error) {
+	var opts []Option
+	for host, throttle := range o.HostThrottles() {
+		opts = append(opts, WithHostThrottle(host, throttle.MaxQPS, throttle.MaxBurst))
+	}
+	if command := o.TokenCommandArgs(); len(command) > 0 {
+		source, err := NewCommandTokenSource(command, o.TokenRefreshInterval)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTokenSource(source))
+	}
+	return opts, nil
+}
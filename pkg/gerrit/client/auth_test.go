@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/flagutil"
+)
+
+// fakeTokenSource returns the next token after a refresh.
+type fakeTokenSource struct {
+	lock   sync.Mutex
+	tokens []string
+}
+
+func (s *fakeTokenSource) Token() (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.tokens[0], nil
+}
+
+func (s *fakeTokenSource) Refresh() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.tokens) > 1 {
+		s.tokens = s.tokens[1:]
+	}
+}
+
+func TestRetryUnauthorized(t *testing.T) {
+	type request struct {
+		cookie string
+		body   string
+	}
+	var lock sync.Mutex
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var cookie string
+		if c, err := r.Cookie(authCookie); err == nil {
+			cookie = c.Value
+		}
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		requests = append(requests, request{cookie: cookie, body: strings.TrimSpace(string(body))})
+		lock.Unlock()
+		if cookie != "valid" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(")]}'\n{\"_account_id\": 1000}"))
+	}))
+	defer server.Close()
+
+	testcases := []struct {
+		name         string
+		tokens       []string
+		wantErr      bool
+		wantRequests []request
+	}{
+		{
+			name:   "expired token is refreshed",
+			tokens: []string{"expired", "valid"},
+			wantRequests: []request{
+				{cookie: "expired", body: `{"user":"1000","reason":"Prow jobs failed"}`},
+				{cookie: "valid", body: `{"user":"1000","reason":"Prow jobs failed"}`},
+			},
+		},
+		{
+			name:   "valid token",
+			tokens: []string{"valid"},
+			wantRequests: []request{
+				{cookie: "valid", body: `{"user":"1000","reason":"Prow jobs failed"}`},
+			},
+		},
+		{
+			name:    "request is not retried without a new token",
+			tokens:  []string{"revoked"},
+			wantErr: true,
+			wantRequests: []request{
+				{cookie: "revoked", body: `{"user":"1000","reason":"Prow jobs failed"}`},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests = nil
+			c, err := NewClient(nil, 0, 0, WithTokenSource(&fakeTokenSource{tokens: tc.tokens}))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			c.authentication = c.tokenSource.Token
+			c.authenticateOnce()
+			// The instance is added after authenticating, like instances
+			// added to the config later are.
+			if err := c.UpdateClients(map[string]map[string]*config.GerritQueryFilter{server.URL: nil}); err != nil {
+				t.Fatalf("failed to update clients: %v", err)
+			}
+
+			err = c.AddToAttentionSet(server.URL, "123", "1000", "Prow jobs failed")
+			if tc.wantErr != (err != nil) {
+				t.Errorf("expected error %t, got %v", tc.wantErr, err)
+			}
+			if !reflect.DeepEqual(tc.wantRequests, requests) {
+				t.Errorf("expected requests %v, got %v", tc.wantRequests, requests)
+			}
+		})
+	}
+}
+
+func TestCommandTokenSource(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "counter")
+	// Prints token-1, token-2, ... on consecutive runs.
+	command := []string{"sh", "-c", `n=$(($(cat "$0" 2>/dev/null || echo 0) + 1)); echo $n > "$0"; echo "token-$n"`, counter}
+
+	source, err := NewCommandTokenSource(command, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create token source: %v", err)
+	}
+	for _, want := range []string{"token-1", "token-1"} {
+		if got, err := source.Token(); err != nil || got != want {
+			t.Errorf("expected cached token %q, got %q, %v", want, got, err)
+		}
+	}
+	source.Refresh()
+	if got, err := source.Token(); err != nil || got != "token-2" {
+		t.Errorf("expected refreshed token %q, got %q, %v", "token-2", got, err)
+	}
+
+	source, err = NewCommandTokenSource(command, 0)
+	if err != nil {
+		t.Fatalf("failed to create token source: %v", err)
+	}
+	for _, want := range []string{"token-3", "token-4"} {
+		if got, err := source.Token(); err != nil || got != want {
+			t.Errorf("expected expired token to be replaced with %q, got %q, %v", want, got, err)
+		}
+	}
+
+	if _, err := NewCommandTokenSource(nil, time.Hour); err == nil {
+		t.Error("expected an error for an empty command")
+	}
+	source, _ = NewCommandTokenSource([]string{"sh", "-c", "echo 'not logged in' >&2; exit 1"}, time.Hour)
+	if _, err := source.Token(); err == nil || !strings.Contains(err.Error(), "not logged in") {
+		t.Errorf("expected the error to include the output of the command, got %v", err)
+	}
+	source, _ = NewCommandTokenSource([]string{"true"}, time.Hour)
+	if _, err := source.Token(); err == nil {
+		t.Error("expected an error for a command that prints no token")
+	}
+}
+
+func TestWithHostThrottle(t *testing.T) {
+	c, err := NewClient(nil, 0, 0, WithHostThrottle("https://throttled.example.com", 1, 1))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 2; i++ {
+		if err := c.throttle.Wait(ctx, "other.example.com"); err != nil {
+			t.Errorf("expected other hosts not to be throttled, got %v", err)
+		}
+	}
+	if err := c.throttle.Wait(ctx, "throttled.example.com"); err != nil {
+		t.Errorf("expected the burst to be available, got %v", err)
+	}
+	if err := c.throttle.Wait(ctx, "throttled.example.com"); err == nil {
+		t.Error("expected the host to be throttled after the burst")
+	}
+}
+
+func TestOptionsFromFlags(t *testing.T) {
+	var o flagutil.GerritOptions
+	if opts, err := OptionsFromFlags(&o); err != nil || len(opts) != 0 {
+		t.Errorf("expected no options by default, got %d, %v", len(opts), err)
+	}
+	o.TokenCommand = "gcloud auth print-access-token"
+	o.TokenRefreshInterval = time.Minute
+	opts, err := OptionsFromFlags(&o)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c, err := NewClient(nil, 0, 0, opts...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	source, ok := c.tokenSource.(*CommandTokenSource)
+	if !ok {
+		t.Fatalf("expected a command token source, got %T", c.tokenSource)
+	}
+	if want := []string{"gcloud", "auth", "print-access-token"}; !reflect.DeepEqual(want, source.command) {
+		t.Errorf("expected command %v, got %v", want, source.command)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
@@ -37,6 +38,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/gerrit/source"
 	"sigs.k8s.io/prow/pkg/throttle"
 	"sigs.k8s.io/prow/pkg/version"
 )
@@ -96,6 +98,19 @@ type gerritChange interface {
 	GetRelatedChanges(changeID string, revisionID string) (*gerrit.RelatedChangesInfo, *gerrit.Response, error)
 }
 
+type gerritGroups interface {
+	ListGroupMembers(groupID string, opt *gerrit.ListGroupMembersOptions) (*[]gerrit.AccountInfo, *gerrit.Response, error)
+}
+
+type gerritContent interface {
+	GetCommitContent(project, commit, path string) ([]byte, *gerrit.Response, error)
+}
+
+type gerritAttentionSet interface {
+	AddToAttentionSet(changeID string, input *AttentionSetInput) (*gerrit.AccountInfo, *gerrit.Response, error)
+	RemoveFromAttentionSet(changeID, accountID string, input *AttentionSetInput) (*gerrit.Response, error)
+}
+
 type gerritProjects interface {
 	GetBranch(projectName, branchID string) (*gerrit.BranchInfo, *gerrit.Response, error)
 }
@@ -109,11 +124,14 @@ type gerritInstanceHandler struct {
 	instance string
 	projects map[string]*config.GerritQueryFilter
 
-	authService     gerritAuthentication
-	accountService  gerritAccount
-	changeService   gerritChange
-	projectService  gerritProjects
-	revisionService gerritRevision
+	authService      gerritAuthentication
+	accountService   gerritAccount
+	changeService    gerritChange
+	projectService   gerritProjects
+	revisionService  gerritRevision
+	attentionService gerritAttentionSet
+	groupService     gerritGroups
+	contentService   gerritContent
 
 	log logrus.FieldLogger
 }
@@ -126,9 +144,14 @@ type Client struct {
 
 	httpClient http.Client
 
+	throttle *throttle.Throttler
+
 	authentication func() (string, error)
 	previousToken  string
 	lock           sync.RWMutex
+	// tokenSource overrides the cookie file and token path if set.
+	tokenSource TokenSource
+	reauthLock  sync.Mutex
 }
 
 // ChangeInfo is a gerrit.ChangeInfo
@@ -157,19 +180,27 @@ func (l LastSyncState) DeepCopy() LastSyncState {
 type roundTripperWithThrottleAndHeader struct {
 	upstream http.RoundTripper
 	throttle.Throttler
+	// reauthenticate returns a token to retry requests that were rejected
+	// as unauthorized with, if there is one.
+	reauthenticate func(rejected string) (string, bool)
 }
 
 func (rt *roundTripperWithThrottleAndHeader) RoundTrip(r *http.Request) (*http.Response, error) {
 	r.Header.Add("user-agent", "prow")
 	// Also include component name
 	r.Header.Add("user-agent", "prow/"+version.Name)
-	// Gerrit quotas are shared across all orgs so we can omit the org field to use the global thottler.
-	rt.Wait(r.Context(), "")
-	return rt.upstream.RoundTrip(r)
+	// Gerrit quotas are shared across all orgs of a host, so hosts without a
+	// throttle of their own fall back to the global throttler.
+	rt.Wait(r.Context(), r.URL.Host)
+	resp, err := rt.upstream.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	return rt.retryUnauthorized(r, resp)
 }
 
 // NewClient returns a new gerrit client
-func NewClient(instances map[string]map[string]*config.GerritQueryFilter, maxQPS, maxBurst int) (*Client, error) {
+func NewClient(instances map[string]map[string]*config.GerritQueryFilter, maxQPS, maxBurst int, opts ...Option) (*Client, error) {
 	roundTripper := &roundTripperWithThrottleAndHeader{upstream: http.DefaultTransport}
 	roundTripper.Throttle(maxQPS*3600, maxBurst)
 
@@ -180,6 +211,13 @@ func NewClient(instances map[string]map[string]*config.GerritQueryFilter, maxQPS
 		httpClient: http.Client{
 			Transport: roundTripper,
 		},
+		throttle: &roundTripper.Throttler,
+	}
+	roundTripper.reauthenticate = c.reauthenticate
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
 	}
 
 	for instance := range instances {
@@ -251,7 +289,7 @@ func (c *Client) authenticateOnce() {
 
 	// update auth token for each instance
 	for _, handler := range c.getAllHandlers() {
-		handler.authService.SetCookieAuth("o", current)
+		handler.authService.SetCookieAuth(authCookie, current)
 	}
 }
 
@@ -272,6 +310,8 @@ func (c *Client) getAllHandlers() map[string]*gerritInstanceHandler {
 func (c *Client) Authenticate(cookiefilePath, tokenPath string) {
 	var was, auth func() (string, error)
 	switch {
+	case c.tokenSource != nil:
+		auth = c.tokenSource.Token
 	case cookiefilePath != "":
 		if tokenPath != "" {
 			logrus.WithFields(logrus.Fields{
@@ -321,14 +361,22 @@ func (c *Client) newInstanceHandler(instance string, projects map[string]*config
 		return nil, fmt.Errorf("failed to create gerrit client: %w", err)
 	}
 
+	// Authenticate instances added after the token was last updated.
+	if c.previousToken != "" {
+		gc.Authentication.SetCookieAuth(authCookie, c.previousToken)
+	}
+
 	return &gerritInstanceHandler{
-		instance:       instance,
-		projects:       projects,
-		authService:    gc.Authentication,
-		accountService: gc.Accounts,
-		changeService:  gc.Changes,
-		projectService: gc.Projects,
-		log:            logrus.WithField("host", instance),
+		instance:         instance,
+		projects:         projects,
+		authService:      gc.Authentication,
+		accountService:   gc.Accounts,
+		changeService:    gc.Changes,
+		projectService:   gc.Projects,
+		attentionService: &attentionSetService{client: gc},
+		groupService:     gc.Groups,
+		contentService:   &contentService{client: gc},
+		log:              logrus.WithField("host", instance),
 	}, nil
 }
 
@@ -483,6 +531,13 @@ func responseBodyError(err error, resp *gerrit.Response) error {
 
 // SetReview writes a review comment base on the change id + revision
 func (c *Client) SetReview(instance, id, revision, message string, labels map[string]string) error {
+	return c.SetReviewWithTag(instance, id, revision, message, "", labels)
+}
+
+// SetReviewWithTag is SetReview with a tag, which tells automated comments
+// apart from the ones of humans. The web UI of Gerrit only shows the latest of
+// the comments with the same tag starting with "autogenerated:" by default.
+func (c *Client) SetReviewWithTag(instance, id, revision, message, tag string, labels map[string]string) error {
 	c.lock.RLock()
 	h, ok := c.handlers[instance]
 	c.lock.RUnlock()
@@ -490,7 +545,7 @@ func (c *Client) SetReview(instance, id, revision, message string, labels map[st
 		return fmt.Errorf("not activated gerrit instance: %s", instance)
 	}
 
-	_, resp, err := h.changeService.SetReview(id, revision, &gerrit.ReviewInput{Message: message, Labels: labels})
+	_, resp, err := h.changeService.SetReview(id, revision, &gerrit.ReviewInput{Message: message, Tag: tag, Labels: labels})
 
 	if err != nil {
 		return fmt.Errorf("cannot comment to gerrit: %w", responseBodyError(err, resp))
@@ -499,6 +554,60 @@ func (c *Client) SetReview(instance, id, revision, message string, labels map[st
 	return nil
 }
 
+// AddToAttentionSet adds a user to the attention set of a change, so that
+// Gerrit shows them that it is their turn to act on it.
+func (c *Client) AddToAttentionSet(instance, id, user, reason string) error {
+	c.lock.RLock()
+	h, ok := c.handlers[instance]
+	c.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	_, resp, err := h.attentionService.AddToAttentionSet(id, &AttentionSetInput{User: user, Reason: reason})
+	if err != nil {
+		return fmt.Errorf("cannot add %s to attention set: %w", user, responseBodyError(err, resp))
+	}
+
+	return nil
+}
+
+// RemoveFromAttentionSet removes a user, e.g. "self", from the attention set
+// of a change.
+func (c *Client) RemoveFromAttentionSet(instance, id, user, reason string) error {
+	c.lock.RLock()
+	h, ok := c.handlers[instance]
+	c.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	resp, err := h.attentionService.RemoveFromAttentionSet(id, user, &AttentionSetInput{Reason: reason})
+	if err != nil {
+		return fmt.Errorf("cannot remove %s from attention set: %w", user, responseBodyError(err, resp))
+	}
+
+	return nil
+}
+
+// ListGroupMembers returns the members of a group, including the members of
+// the groups it includes.
+func (c *Client) ListGroupMembers(instance, group string) ([]gerrit.AccountInfo, error) {
+	c.lock.RLock()
+	h, ok := c.handlers[instance]
+	c.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	members, resp, err := h.groupService.ListGroupMembers(url.PathEscape(group), &gerrit.ListGroupMembersOptions{Recursive: true})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list members of group %s: %w", group, responseBodyError(err, resp))
+	}
+
+	return *members, nil
+}
+
 // GetBranchRevision returns SHA of HEAD of a branch
 func (c *Client) GetBranchRevision(instance, project, branch string) (string, error) {
 	c.lock.RLock()
@@ -834,3 +943,59 @@ func (c *Client) HasRelatedChanges(instance, id, revision string) (bool, error)
 
 	return len(info.Changes) > 0, nil
 }
+
+// QueryTopicChanges returns the open changes of a topic, with their current
+// revision and commit, across all projects of the instance.
+func (c *Client) QueryTopicChanges(instance, topic string) ([]ChangeInfo, error) {
+	c.lock.RLock()
+	h, ok := c.handlers[instance]
+	c.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	opt := gerrit.QueryChangeOptions{
+		QueryOptions:  gerrit.QueryOptions{Query: []string{fmt.Sprintf("topic:%q+status:open", topic)}},
+		ChangeOptions: gerrit.ChangeOptions{AdditionalFields: []string{"CURRENT_REVISION", "CURRENT_COMMIT"}},
+	}
+	changes, resp, err := h.changeService.QueryChanges(&opt)
+	if err != nil {
+		return nil, fmt.Errorf("error querying changes of topic %q: %w", topic, responseBodyError(err, resp))
+	}
+	if changes == nil {
+		return nil, nil
+	}
+	return *changes, nil
+}
+
+// GetFileContent returns the content of a file of a project at a commit, and
+// false if the file doesn't exist.
+func (c *Client) GetFileContent(instance, project, commit, path string) ([]byte, bool, error) {
+	c.lock.RLock()
+	h, ok := c.handlers[instance]
+	c.lock.RUnlock()
+	if !ok {
+		return nil, false, fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	content, resp, err := h.contentService.GetCommitContent(project, commit, path)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("error getting content of %s: %w", path, responseBodyError(err, resp))
+	}
+
+	return content, true, nil
+}
+
+// InRepoConfigFile returns the content of a file of the repo with the clone
+// URI at a commit, for reading the InRepoConfig of repos without cloning them,
+// see config.ProwYAMLFileGetter.
+func (c *Client) InRepoConfigFile(cloneURI, sha, path string) ([]byte, bool, error) {
+	instance, project, err := source.OrgRepoFromCloneURI(cloneURI)
+	if err != nil {
+		return nil, false, err
+	}
+	return c.GetFileContent(instance, project, sha, path)
+}
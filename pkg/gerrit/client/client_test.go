@@ -18,6 +18,10 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -1102,3 +1106,77 @@ func TestQueryChange(t *testing.T) {
 		}
 	}
 }
+
+type fakeGroups struct {
+	members map[string][]gerrit.AccountInfo
+}
+
+func (f *fakeGroups) ListGroupMembers(groupID string, opt *gerrit.ListGroupMembersOptions) (*[]gerrit.AccountInfo, *gerrit.Response, error) {
+	if opt == nil || !opt.Recursive {
+		return nil, nil, errors.New("expected members of included groups to be listed")
+	}
+	members, ok := f.members[groupID]
+	if !ok {
+		return nil, nil, fmt.Errorf("group %s not found", groupID)
+	}
+	return &members, nil, nil
+}
+
+func TestListGroupMembers(t *testing.T) {
+	client := &Client{
+		handlers: map[string]*gerritInstanceHandler{
+			"foo": {
+				instance: "foo",
+				groupService: &fakeGroups{members: map[string][]gerrit.AccountInfo{
+					"prow%20trusted": {{AccountID: 1}, {AccountID: 2}},
+				}},
+			},
+		},
+	}
+
+	members, err := client.ListGroupMembers("foo", "prow trusted")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]gerrit.AccountInfo{{AccountID: 1}, {AccountID: 2}}, members); diff != "" {
+		t.Errorf("unexpected members (-want +got):\n%s", diff)
+	}
+	if _, err := client.ListGroupMembers("foo", "unknown"); err == nil {
+		t.Error("expected an error for an unknown group")
+	}
+	if _, err := client.ListGroupMembers("bar", "prow trusted"); err == nil {
+		t.Error("expected an error for an unknown instance")
+	}
+}
+
+func TestQueryTopicChanges(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		if fields := r.URL.Query()["o"]; !reflect.DeepEqual([]string{"CURRENT_REVISION", "CURRENT_COMMIT"}, fields) {
+			t.Errorf("unexpected additional fields %v", fields)
+		}
+		w.Write([]byte(")]}'\n[{\"project\": \"foo\", \"_number\": 1, \"topic\": \"feature\"}, {\"project\": \"bar\", \"_number\": 2, \"topic\": \"feature\"}]"))
+	}))
+	defer server.Close()
+
+	c := &Client{handlers: map[string]*gerritInstanceHandler{}}
+	if err := c.UpdateClients(map[string]map[string]*config.GerritQueryFilter{server.URL: nil}); err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	changes, err := c.QueryTopicChanges(server.URL, "feature")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []ChangeInfo{{Project: "foo", Number: 1, Topic: "feature"}, {Project: "bar", Number: 2, Topic: "feature"}}
+	if diff := cmp.Diff(expected, changes); diff != "" {
+		t.Errorf("unexpected changes (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{`topic:"feature" status:open`}, queries); diff != "" {
+		t.Errorf("unexpected queries (-want +got):\n%s", diff)
+	}
+	if _, err := c.QueryTopicChanges("unknown", "feature"); err == nil {
+		t.Error("expected an error for an unknown instance")
+	}
+}
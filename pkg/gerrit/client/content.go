@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+
+	gerrit "github.com/andygrunwald/go-gerrit"
+)
+
+// contentService implements the endpoint for the content of a file at a
+// commit, whose base64 encoded response go-gerrit tries to decode as JSON.
+type contentService struct {
+	client *gerrit.Client
+}
+
+// GetCommitContent gets the content of a file of a project at a commit, see
+// https://gerrit-review.googlesource.com/Documentation/rest-api-projects.html#get-content-from-commit
+func (s *contentService) GetCommitContent(project, commit, path string) ([]byte, *gerrit.Response, error) {
+	u := fmt.Sprintf("projects/%s/commits/%s/files/%s/content", url.QueryEscape(project), commit, url.QueryEscape(path))
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var encoded bytes.Buffer
+	resp, err := s.client.Do(req, &encoded)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	content, err := base64.StdEncoding.DecodeString(encoded.String())
+	return content, resp, err
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func TestGetFileContent(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		switch r.URL.EscapedPath() {
+		case "/projects/platform%2Fbuild/commits/abc/files/.prow.yaml/content":
+			w.Write([]byte(base64.StdEncoding.EncodeToString([]byte("presubmits: []\n"))))
+		case "/projects/platform%2Fbuild/commits/broken/files/.prow.yaml/content":
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := &Client{handlers: map[string]*gerritInstanceHandler{}}
+	if err := c.UpdateClients(map[string]map[string]*config.GerritQueryFilter{server.URL: nil}); err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	content, found, err := c.InRepoConfigFile(server.URL+"/platform/build", "abc", ".prow.yaml")
+	if err != nil || !found {
+		t.Fatalf("expected the file to be found, got %t, %v", found, err)
+	}
+	if diff := cmp.Diff("presubmits: []\n", string(content)); diff != "" {
+		t.Errorf("unexpected content (-want +got):\n%s", diff)
+	}
+	if _, found, err := c.GetFileContent(server.URL, "platform/build", "def", ".prow.yaml"); err != nil || found {
+		t.Errorf("expected a missing file not to be found, got %t, %v", found, err)
+	}
+	if _, _, err := c.GetFileContent(server.URL, "platform/build", "broken", ".prow.yaml"); err == nil {
+		t.Error("expected an error for a server error")
+	}
+	if _, _, err := c.GetFileContent("unknown", "platform/build", "abc", ".prow.yaml"); err == nil {
+		t.Error("expected an error for an unknown instance")
+	}
+
+	expected := []string{
+		"/projects/platform%2Fbuild/commits/abc/files/.prow.yaml/content",
+		"/projects/platform%2Fbuild/commits/def/files/.prow.yaml/content",
+		"/projects/platform%2Fbuild/commits/broken/files/.prow.yaml/content",
+	}
+	if diff := cmp.Diff(expected, paths); diff != "" {
+		t.Errorf("unexpected requests (-want +got):\n%s", diff)
+	}
+}
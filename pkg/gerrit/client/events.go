@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+// Types of the events Gerrit emits on `gerrit stream-events` and sends with
+// the webhooks plugin.
+const (
+	PatchsetCreatedEvent = "patchset-created"
+	CommentAddedEvent    = "comment-added"
+	ChangeMergedEvent    = "change-merged"
+	ChangeAbandonedEvent = "change-abandoned"
+	ChangeRestoredEvent  = "change-restored"
+	HashtagsChangedEvent = "hashtags-changed"
+	TopicChangedEvent    = "topic-changed"
+)
+
+// StreamAccount is an account as it appears in stream events.
+type StreamAccount struct {
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// StreamChange is a change as it appears in stream events. Unlike ChangeInfo
+// of the REST API, it identifies the change by its number and Change-Id.
+type StreamChange struct {
+	Project  string        `json:"project"`
+	Branch   string        `json:"branch"`
+	Topic    string        `json:"topic,omitempty"`
+	ID       string        `json:"id"`
+	Number   int           `json:"number"`
+	Subject  string        `json:"subject"`
+	Owner    StreamAccount `json:"owner"`
+	URL      string        `json:"url"`
+	Status   string        `json:"status,omitempty"`
+	Hashtags []string      `json:"hashtags,omitempty"`
+}
+
+// StreamPatchSet is a patchset as it appears in stream events.
+type StreamPatchSet struct {
+	Number    int           `json:"number"`
+	Revision  string        `json:"revision"`
+	Ref       string        `json:"ref"`
+	Uploader  StreamAccount `json:"uploader"`
+	CreatedOn int64         `json:"createdOn,omitempty"`
+	Kind      string        `json:"kind,omitempty"`
+}
+
+// StreamApproval is a vote on a label as it appears in stream events.
+type StreamApproval struct {
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	OldValue string `json:"oldValue,omitempty"`
+}
+
+// StreamEvent is an event about a change. Which of the optional fields are
+// set depends on the type of the event.
+type StreamEvent struct {
+	Type           string           `json:"type"`
+	Change         StreamChange     `json:"change"`
+	PatchSet       StreamPatchSet   `json:"patchSet"`
+	Author         *StreamAccount   `json:"author,omitempty"`
+	Uploader       *StreamAccount   `json:"uploader,omitempty"`
+	Submitter      *StreamAccount   `json:"submitter,omitempty"`
+	Approvals      []StreamApproval `json:"approvals,omitempty"`
+	Comment        string           `json:"comment,omitempty"`
+	Added          []string         `json:"added,omitempty"`
+	Removed        []string         `json:"removed,omitempty"`
+	OldTopic       string           `json:"oldTopic,omitempty"`
+	EventCreatedOn int64            `json:"eventCreatedOn"`
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
+)
+
+// ClientIdentifierHeader identifies the component that sends a request, so
+// that it gets its own client budget. Requests without it are attributed to
+// their token budget.
+const ClientIdentifierHeader = "X-PROW-GHCACHE-CLIENT"
+
+// PriorityClass determines the budget of a client.
+type PriorityClass string
+
+const (
+	PriorityHigh   PriorityClass = "high"
+	PriorityNormal PriorityClass = "normal"
+	PriorityLow    PriorityClass = "low"
+)
+
+// clientBudgetBurst is how much of its hourly budget a client may use at once.
+const clientBudgetBurst = 5 * time.Minute
+
+var clientBudgetRejectionsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "ghcache_client_budget_rejections",
+		Help: "How many requests were rejected because their client exceeded its budget.",
+	},
+	[]string{"client", "priority"},
+)
+
+func init() {
+	prometheus.MustRegister(clientBudgetRejectionsCounter)
+}
+
+// ClientBudgets limits how many requests each client may send to GitHub per
+// token, so that a single misbehaving client cannot use up the API tokens
+// that other clients depend on.
+type ClientBudgets struct {
+	// RequestsPerHour is the budget of each client in a priority class. Clients
+	// in a class without a budget are not limited.
+	RequestsPerHour map[PriorityClass]int
+	// Priorities assigns clients to priority classes, all other clients are
+	// in PriorityNormal.
+	Priorities map[string]PriorityClass
+	// MaxWait is how long a request may wait for its client's budget before it
+	// is rejected.
+	MaxWait time.Duration
+}
+
+// ParseClientPriorities parses "client=class" pairs.
+func ParseClientPriorities(values []string) (map[string]PriorityClass, error) {
+	priorities := map[string]PriorityClass{}
+	for _, value := range values {
+		client, class, ok := strings.Cut(value, "=")
+		if !ok || client == "" {
+			return nil, fmt.Errorf("client priority %q is not of the form client=class", value)
+		}
+		switch PriorityClass(class) {
+		case PriorityHigh, PriorityNormal, PriorityLow:
+			priorities[client] = PriorityClass(class)
+		default:
+			return nil, fmt.Errorf("client priority %q has unknown class %q, expected one of %s, %s or %s", value, class, PriorityHigh, PriorityNormal, PriorityLow)
+		}
+	}
+	return priorities, nil
+}
+
+func (b ClientBudgets) isEnabled() bool {
+	for _, requestsPerHour := range b.RequestsPerHour {
+		if requestsPerHour > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenBucket holds the remaining budget of a client for a token.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// budgetTransport holds back requests of clients that exceed their budget,
+// and rejects them if they would have to wait too long.
+type budgetTransport struct {
+	roundTripper http.RoundTripper
+	hasher       ghmetrics.Hasher
+	budgets      ClientBudgets
+	now          func() time.Time
+	sleep        func(time.Duration)
+
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// NewBudgetTransport wraps the upstream RoundTripper of a GitHub cache to
+// enforce the client budgets. Each client gets a token bucket per token that
+// holds up to five minutes of its hourly budget. Cache hits and conditional
+// requests for unchanged resources don't count against the budget. Requests
+// that would wait longer than MaxWait are rejected with a Retry-After header.
+func NewBudgetTransport(roundTripper http.RoundTripper, budgets ClientBudgets) http.RoundTripper {
+	if !budgets.isEnabled() {
+		return roundTripper
+	}
+	return &budgetTransport{
+		roundTripper: roundTripper,
+		hasher:       ghmetrics.NewCachingHasher(),
+		budgets:      budgets,
+		now:          time.Now,
+		sleep:        time.Sleep,
+		buckets:      map[string]*tokenBucket{},
+	}
+}
+
+func (t *budgetTransport) client(req *http.Request) (string, string) {
+	tokenBudgetName := req.Header.Get(TokenBudgetIdentifierHeader)
+	if tokenBudgetName == "" {
+		tokenBudgetName = t.hasher.Hash(req)
+	}
+	if client := req.Header.Get(ClientIdentifierHeader); client != "" {
+		return client, tokenBudgetName
+	}
+	return tokenBudgetName, tokenBudgetName
+}
+
+// reserve takes a request from the bucket and returns how long the request
+// has to wait for it.
+func (t *budgetTransport) reserve(key string, requestsPerHour int) time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.now()
+	capacity := math.Max(1, float64(requestsPerHour)*clientBudgetBurst.Hours())
+	bucket, ok := t.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, last: now}
+		t.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.last).Hours()*float64(requestsPerHour))
+	bucket.last = now
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / float64(requestsPerHour) * float64(time.Hour))
+}
+
+// refund returns a request that did not use a token to the bucket.
+func (t *budgetTransport) refund(key string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if bucket, ok := t.buckets[key]; ok {
+		bucket.tokens++
+	}
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	client, tokenBudgetName := t.client(req)
+	priority, ok := t.budgets.Priorities[client]
+	if !ok {
+		priority = PriorityNormal
+	}
+	requestsPerHour := t.budgets.RequestsPerHour[priority]
+	if requestsPerHour <= 0 {
+		return t.roundTripper.RoundTrip(req)
+	}
+
+	key := client + "/" + tokenBudgetName
+	if wait := t.reserve(key, requestsPerHour); wait > t.budgets.MaxWait {
+		t.refund(key)
+		clientBudgetRejectionsCounter.WithLabelValues(client, string(priority)).Inc()
+		logrus.WithFields(logrus.Fields{"client": client, "priority": priority, "wait": wait}).Debug("Rejecting request of client that exceeded its budget.")
+		return &http.Response{
+			Status:     http.StatusText(http.StatusForbidden),
+			StatusCode: http.StatusForbidden,
+			Proto:      req.Proto,
+			ProtoMajor: req.ProtoMajor,
+			ProtoMinor: req.ProtoMinor,
+			Header:     http.Header{"Retry-After": []string{strconv.Itoa(int(math.Ceil(wait.Seconds())))}},
+			Body:       io.NopCloser(strings.NewReader(fmt.Sprintf("client %s exceeded its budget of %d requests per hour", client, requestsPerHour))),
+			Request:    req,
+		}, nil
+	} else if wait > 0 {
+		t.sleep(wait)
+	}
+
+	resp, err := t.roundTripper.RoundTrip(req)
+	if err != nil || resp.StatusCode == http.StatusNotModified {
+		// Failed requests and revalidated cache entries don't use tokens.
+		t.refund(key)
+	}
+	return resp, err
+}
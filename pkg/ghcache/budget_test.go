@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statusUpstream responds with a fixed status code and counts requests.
+type statusUpstream struct {
+	hits   int
+	status int
+}
+
+func (u *statusUpstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	u.hits++
+	return &http.Response{StatusCode: u.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func TestBudgetTransport(t *testing.T) {
+	cases := []struct {
+		name             string
+		client           string
+		status           int
+		requests         int
+		expectedHits     int
+		expectedWaited   time.Duration
+		expectedRejected int
+	}{
+		{
+			name:         "requests within the burst are not held back",
+			client:       "hook",
+			status:       http.StatusOK,
+			requests:     5,
+			expectedHits: 5,
+		},
+		{
+			name:             "concurrent requests beyond the burst wait for the budget or are rejected",
+			client:           "hook",
+			status:           http.StatusOK,
+			requests:         8,
+			expectedHits:     6,
+			expectedWaited:   time.Minute,
+			expectedRejected: 2,
+		},
+		{
+			name:         "revalidations don't count",
+			client:       "hook",
+			status:       http.StatusNotModified,
+			requests:     20,
+			expectedHits: 20,
+		},
+		{
+			name:         "clients without a budget are not limited",
+			client:       "tide",
+			status:       http.StatusOK,
+			requests:     20,
+			expectedHits: 20,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			upstream := &statusUpstream{status: tc.status}
+			now := time.Now()
+			var waited time.Duration
+			transport := NewBudgetTransport(upstream, ClientBudgets{
+				RequestsPerHour: map[PriorityClass]int{PriorityNormal: 60},
+				Priorities:      map[string]PriorityClass{"tide": PriorityHigh},
+				MaxWait:         time.Minute,
+			}).(*budgetTransport)
+			transport.now = func() time.Time { return now }
+			// The clock does not advance, as if all requests were sent at once.
+			transport.sleep = func(d time.Duration) { waited += d }
+
+			var rejected int
+			for i := 0; i < tc.requests; i++ {
+				req, err := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org/repo", nil)
+				if err != nil {
+					t.Fatalf("failed to create request: %v", err)
+				}
+				req.Header.Set(ClientIdentifierHeader, tc.client)
+				resp, err := transport.RoundTrip(req)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if resp.StatusCode == http.StatusForbidden {
+					if resp.Header.Get("Retry-After") == "" {
+						t.Error("expected rejected response to have a Retry-After header")
+					}
+					rejected++
+				}
+			}
+			if upstream.hits != tc.expectedHits {
+				t.Errorf("expected %d upstream requests, got %d", tc.expectedHits, upstream.hits)
+			}
+			if waited != tc.expectedWaited {
+				t.Errorf("expected to wait %v, waited %v", tc.expectedWaited, waited)
+			}
+			if rejected != tc.expectedRejected {
+				t.Errorf("expected %d rejected requests, got %d", tc.expectedRejected, rejected)
+			}
+		})
+	}
+}
+
+func TestParseClientPriorities(t *testing.T) {
+	priorities, err := ParseClientPriorities([]string{"tide=high", "branchprotector=low"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if priorities["tide"] != PriorityHigh || priorities["branchprotector"] != PriorityLow {
+		t.Errorf("unexpected priorities %v", priorities)
+	}
+	for _, value := range []string{"tide", "=high", "tide=urgent"} {
+		if _, err := ParseClientPriorities([]string{value}); err == nil {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+}
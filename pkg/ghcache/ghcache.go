@@ -43,8 +43,6 @@ import (
 
 	"github.com/cjwagner/httpcache"
 	"github.com/cjwagner/httpcache/diskcache"
-	rediscache "github.com/cjwagner/httpcache/redis"
-	"github.com/gomodule/redigo/redis"
 	"github.com/peterbourgon/diskv"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
@@ -67,6 +65,7 @@ const (
 	// free (no API tokens used).
 	ModeCoalesced   CacheResponseMode = "COALESCED"   // coalesced request, this is a copied response
 	ModeRevalidated CacheResponseMode = "REVALIDATED" // cached value revalidated and returned
+	ModeHit         CacheResponseMode = "HIT"         // cached GraphQL value returned without revalidation
 
 	// cacheEntryCreationDateHeader contains the creation date of the cache entry
 	cacheEntryCreationDateHeader = "X-PROW-REQUEST-DATE"
@@ -126,6 +125,8 @@ func CacheModeIsFree(mode CacheResponseMode) bool {
 		return true
 	case ModeRevalidated:
 		return true
+	case ModeHit:
+		return true
 	case ModeError:
 		// In this case we did not successfully communicate with the GH API, so no
 		// token is used, but we also don't return a response, so ModeError won't
@@ -165,6 +166,9 @@ func init() {
 }
 
 func cacheResponseMode(headers http.Header) CacheResponseMode {
+	if headers.Get(CacheModeHeader) == string(ModeCoalesced) {
+		return ModeCoalesced
+	}
 	if strings.Contains(headers.Get("Cache-Control"), "no-store") {
 		return ModeNoStore
 	}
@@ -512,31 +516,38 @@ type CachePartitionCreator func(partitionKey string, expiresAt *time.Time) httpc
 // NewFromCache creates a GitHub cache RoundTripper that is backed by the
 // specified httpcache.Cache implementation.
 func NewFromCache(roundTripper http.RoundTripper, cache CachePartitionCreator, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
+	return newFromCache(roundTripper, cache, nil, maxConcurrency, throttlingTimes)
+}
+
+// cacheTransportWrapper wraps the httpcache layer of a cache partition.
+type cacheTransportWrapper func(partitionKey string, cache httpcache.Cache, cacheTransport http.RoundTripper) http.RoundTripper
+
+func newFromCache(roundTripper http.RoundTripper, cache CachePartitionCreator, wrap cacheTransportWrapper, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
 	hasher := ghmetrics.NewCachingHasher()
 	return newPartitioningRoundTripper(func(partitionKey string, expiresAt *time.Time) http.RoundTripper {
-		cacheTransport := httpcache.NewTransport(cache(partitionKey, expiresAt))
+		partition := cache(partitionKey, expiresAt)
+		cacheTransport := httpcache.NewTransport(partition)
 		cacheTransport.Transport = newThrottlingTransport(maxConcurrency, upstreamTransport{roundTripper: roundTripper, hasher: hasher}, hasher, throttlingTimes)
+		var requestExecutor http.RoundTripper = cacheTransport
+		if wrap != nil {
+			requestExecutor = wrap(partitionKey, partition, cacheTransport)
+		}
 		return &requestCoalescer{
 			cache:           make(map[string]*firstRequest),
-			requestExecutor: cacheTransport,
+			requestExecutor: requestExecutor,
 			hasher:          hasher,
 		}
 	})
 }
 
-// NewRedisCache creates a GitHub cache RoundTripper that is backed by a Redis
-// cache.
-// Important note: The redis implementation does not support partitioning the cache
-// which means that requests to the same path from different tokens will invalidate
-// each other.
-func NewRedisCache(roundTripper http.RoundTripper, redisAddress string, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
-	conn, err := redis.Dial("tcp", redisAddress)
-	if err != nil {
-		logrus.WithError(err).Fatal("Error connecting to Redis")
-	}
-	redisCache := rediscache.NewWithClient(conn)
-	return NewFromCache(roundTripper,
-		func(_ string, _ *time.Time) httpcache.Cache { return redisCache },
-		maxConcurrency,
-		throttlingTimes)
+// NewRedisCache creates a GitHub cache RoundTripper that is backed by one or
+// more Redis servers, see NewSharedCache.
+func NewRedisCache(roundTripper http.RoundTripper, redisAddresses []string, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
+	return NewSharedCache(roundTripper, NewShardedStore(redisAddresses, NewRedisStore), maxConcurrency, throttlingTimes)
+}
+
+// NewMemcachedCache creates a GitHub cache RoundTripper that is backed by one
+// or more memcached servers, see NewSharedCache.
+func NewMemcachedCache(roundTripper http.RoundTripper, memcachedAddresses []string, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
+	return NewSharedCache(roundTripper, NewShardedStore(memcachedAddresses, NewMemcacheStore), maxConcurrency, throttlingTimes)
 }
@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
+)
+
+// maxGraphQLCacheSize is the largest request or response body that is
+// considered for GraphQL caching.
+const maxGraphQLCacheSize = 10 << 20
+
+// nonQueryOperationRE matches GraphQL documents that may have side effects or
+// do not return a single response. Field names matching it only disable
+// caching, which is safe.
+var nonQueryOperationRE = regexp.MustCompile(`(^|[\s{}])(mutation|subscription)\b`)
+
+// graphQLRequest is the body of a GraphQL request.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+// graphQLCache caches the responses to GraphQL queries for a fixed time.
+// Unlike the REST API, the GraphQL API does not support conditional requests,
+// so cached responses cannot be revalidated and are served as they are until
+// they expire. Mutations and responses with errors are never cached.
+type graphQLCache struct {
+	roundTripper http.RoundTripper
+	store        SharedStore
+	ttl          time.Duration
+	hasher       ghmetrics.Hasher
+}
+
+// NewGraphQLCache wraps a GitHub cache RoundTripper to cache the responses to
+// GraphQL queries in the store for ttl. The cache is keyed by the
+// Authorization header and the normalized query and variables. Clients can
+// skip the cache by sending a "Cache-Control: no-cache" header.
+func NewGraphQLCache(roundTripper http.RoundTripper, store SharedStore, ttl time.Duration) http.RoundTripper {
+	return &graphQLCache{
+		roundTripper: roundTripper,
+		store:        store,
+		ttl:          ttl,
+		hasher:       ghmetrics.NewCachingHasher(),
+	}
+}
+
+func isGraphQLRequest(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.Path, "graphql") || strings.HasPrefix(req.URL.Path, "/graphql")
+}
+
+// graphQLCacheKey returns the cache key of a GraphQL request body, or false
+// if the request must not be cached.
+func graphQLCacheKey(partitionKey string, body []byte) (string, bool) {
+	var gqlReq graphQLRequest
+	if err := json.Unmarshal(body, &gqlReq); err != nil || gqlReq.Query == "" {
+		return "", false
+	}
+	query := strings.Join(strings.Fields(gqlReq.Query), " ")
+	if nonQueryOperationRE.MatchString(query) {
+		return "", false
+	}
+	// encoding/json sorts map keys, which makes the variables canonical.
+	variables, err := json.Marshal(gqlReq.Variables)
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	for _, part := range []string{query, gqlReq.OperationName, string(variables)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("ghcache:graphql:%s:%x", partitionKey, h.Sum(nil)), true
+}
+
+// graphQLRateLimitKey returns the store key of the latest rate limit headers
+// of a partition, which replace the stale ones of cached responses. They are
+// stored for as long as the responses, so they expire with the partition.
+func graphQLRateLimitKey(partitionKey string) string {
+	return fmt.Sprintf("ghcache:graphql-ratelimit:%s", partitionKey)
+}
+
+func (c *graphQLCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || !isGraphQLRequest(req) || req.Body == nil ||
+		strings.Contains(req.Header.Get("Cache-Control"), "no-cache") || strings.Contains(req.Header.Get("Cache-Control"), "no-store") {
+		return c.roundTripper.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxGraphQLCacheSize+1))
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	partitionKey := getCachePartition(req)
+	key, cacheable := graphQLCacheKey(partitionKey, body)
+	if !cacheable || len(body) > maxGraphQLCacheSize {
+		return c.roundTripper.RoundTrip(req)
+	}
+
+	if raw, ok := c.store.Get(key); ok {
+		if resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req); err == nil {
+			if raw, ok := c.store.Get(graphQLRateLimitKey(partitionKey)); ok {
+				var rateLimits http.Header
+				if err := json.Unmarshal(raw, &rateLimits); err == nil {
+					for name, values := range rateLimits {
+						resp.Header[name] = values
+					}
+				}
+			}
+			tokenBudgetName := req.Header.Get(TokenBudgetIdentifierHeader)
+			if tokenBudgetName == "" {
+				tokenBudgetName = c.hasher.Hash(req)
+			}
+			collectMetrics(ModeHit, req, resp, tokenBudgetName)
+			return resp, nil
+		}
+		logrus.WithField("cache-key", key).WithError(err).Warn("Error loading cached GraphQL response.")
+	}
+
+	resp, err := c.roundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	rateLimits := http.Header{}
+	for name, values := range resp.Header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), "X-Ratelimit-") {
+			rateLimits[name] = values
+		}
+	}
+	if len(rateLimits) > 0 {
+		if raw, err := json.Marshal(rateLimits); err == nil {
+			c.store.Set(graphQLRateLimitKey(partitionKey), raw, c.ttl)
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxGraphQLCacheSize+1))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	var result struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	if len(respBody) > maxGraphQLCacheSize || json.Unmarshal(respBody, &result) != nil || len(result.Errors) > 0 {
+		return resp, nil
+	}
+	raw, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		logrus.WithField("cache-key", key).WithError(err).Warn("Error storing GraphQL response.")
+		return resp, nil
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	c.store.Set(key, raw, c.ttl)
+	return resp, nil
+}
+
+// memoryStore is a SharedStore that keeps values in memory, for a single
+// replica.
+type memoryStore struct {
+	lock      sync.Mutex
+	values    map[string]memoryStoreValue
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryStoreValue struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore returns a SharedStore that keeps values in memory. It is not
+// shared between replicas, expired values are removed periodically.
+func NewMemoryStore() SharedStore {
+	return &memoryStore{values: map[string]memoryStoreValue{}, now: time.Now}
+}
+
+func (s *memoryStore) expired(v memoryStoreValue, now time.Time) bool {
+	return !v.expiresAt.IsZero() && !now.Before(v.expiresAt)
+}
+
+func (s *memoryStore) Get(key string) ([]byte, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	v, ok := s.values[key]
+	if !ok || s.expired(v, s.now()) {
+		return nil, false
+	}
+	return v.value, true
+}
+
+func (s *memoryStore) set(key string, value []byte, ttl time.Duration) {
+	now := s.now()
+	v := memoryStoreValue{value: value}
+	if ttl > 0 {
+		v.expiresAt = now.Add(ttl)
+	}
+	s.values[key] = v
+	if now.Sub(s.lastSweep) > time.Minute {
+		s.lastSweep = now
+		for k, v := range s.values {
+			if s.expired(v, now) {
+				delete(s.values, k)
+			}
+		}
+	}
+}
+
+func (s *memoryStore) Set(key string, value []byte, ttl time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.set(key, value, ttl)
+}
+
+func (s *memoryStore) Add(key string, value []byte, ttl time.Duration) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if v, ok := s.values[key]; ok && !s.expired(v, s.now()) {
+		return false
+	}
+	s.set(key, value, ttl)
+	return true
+}
+
+func (s *memoryStore) Delete(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.values, key)
+}
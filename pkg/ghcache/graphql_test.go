@@ -0,0 +1,250 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGraphQLCacheKey(t *testing.T) {
+	key := func(body string) string {
+		k, ok := graphQLCacheKey("partition", []byte(body))
+		if !ok {
+			return ""
+		}
+		return k
+	}
+	base := key(`{"query": "query { viewer { login } }", "variables": {"a": 1, "b": 2}}`)
+	if base == "" {
+		t.Fatal("expected query to be cacheable")
+	}
+	if k := key(`{"query": "query {\n  viewer {\n    login\n  }\n}", "variables": {"b": 2, "a": 1}}`); k != base {
+		t.Error("expected whitespace and variable order to be normalized")
+	}
+	if k := key(`{"query": "query { viewer { login } }", "variables": {"a": 1, "b": 3}}`); k == base {
+		t.Error("expected different variables to have different keys")
+	}
+	if k, _ := graphQLCacheKey("other", []byte(`{"query": "query { viewer { login } }", "variables": {"a": 1, "b": 2}}`)); k == base {
+		t.Error("expected different partitions to have different keys")
+	}
+	for _, body := range []string{
+		`{"query": "mutation { addComment(input: {}) { clientMutationId } }"}`,
+		`{"query": "query q { viewer { login } } mutation m { addComment(input: {}) { clientMutationId } }"}`,
+		`{"query": "subscription { viewer { login } }"}`,
+		`{"variables": {}}`,
+		`not json`,
+	} {
+		if k := key(body); k != "" {
+			t.Errorf("expected %s not to be cacheable", body)
+		}
+	}
+}
+
+// graphQLUpstream responds to GraphQL requests and counts them.
+type graphQLUpstream struct {
+	hits      int
+	body      string
+	status    int
+	remaining int
+}
+
+func (u *graphQLUpstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	u.hits++
+	u.remaining--
+	status := u.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header: http.Header{
+			"Content-Type":          []string{"application/json"},
+			"X-Ratelimit-Remaining": []string{strconv.Itoa(u.remaining)},
+		},
+		Body: io.NopCloser(bytes.NewBufferString(u.body)),
+	}, nil
+}
+
+func TestGraphQLCache(t *testing.T) {
+	const query = `{"query": "query { viewer { login } }"}`
+	cases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		header       http.Header
+		status       int
+		response     string
+		expectedHits int
+	}{
+		{
+			name:         "query is cached",
+			body:         query,
+			response:     `{"data": {"viewer": {"login": "bot"}}}`,
+			expectedHits: 1,
+		},
+		{
+			name:         "mutation is not cached",
+			body:         `{"query": "mutation { addComment(input: {}) { clientMutationId } }"}`,
+			response:     `{"data": {}}`,
+			expectedHits: 2,
+		},
+		{
+			name:         "response with errors is not cached",
+			body:         query,
+			response:     `{"data": null, "errors": [{"message": "timeout"}]}`,
+			expectedHits: 2,
+		},
+		{
+			name:         "failed response is not cached",
+			body:         query,
+			status:       http.StatusBadGateway,
+			response:     `{}`,
+			expectedHits: 2,
+		},
+		{
+			name:         "client can skip the cache",
+			body:         query,
+			header:       http.Header{"Cache-Control": []string{"no-cache"}},
+			response:     `{"data": {"viewer": {"login": "bot"}}}`,
+			expectedHits: 2,
+		},
+		{
+			name:         "REST requests are not cached",
+			method:       http.MethodGet,
+			path:         "/repos/org/repo",
+			response:     `{}`,
+			expectedHits: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			upstream := &graphQLUpstream{body: tc.response, status: tc.status, remaining: 100}
+			cache := NewGraphQLCache(upstream, NewMemoryStore(), time.Minute)
+			if tc.method == "" {
+				tc.method = http.MethodPost
+			}
+			if tc.path == "" {
+				tc.path = "/graphql"
+			}
+
+			var responses []string
+			for i := 0; i < 2; i++ {
+				req, err := http.NewRequest(tc.method, "https://api.github.com"+tc.path, strings.NewReader(tc.body))
+				if err != nil {
+					t.Fatalf("failed to create request: %v", err)
+				}
+				req.Header.Set("Authorization", "Bearer token")
+				for name, values := range tc.header {
+					req.Header[name] = values
+				}
+				resp, err := cache.RoundTrip(req)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("failed to read body: %v", err)
+				}
+				responses = append(responses, string(body))
+			}
+			if upstream.hits != tc.expectedHits {
+				t.Errorf("expected %d upstream requests, got %d", tc.expectedHits, upstream.hits)
+			}
+			for _, r := range responses {
+				if r != tc.response {
+					t.Errorf("expected response %s, got %s", tc.response, r)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLCacheRateLimits(t *testing.T) {
+	upstream := &graphQLUpstream{body: `{"data": {}}`, remaining: 100}
+	store := NewMemoryStore().(*memoryStore)
+	cache := NewGraphQLCache(upstream, store, time.Minute)
+	remaining := func(query string) string {
+		req, err := http.NewRequest(http.MethodPost, "https://api.github.com/graphql", strings.NewReader(query))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := cache.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp.Header.Get("X-Ratelimit-Remaining")
+	}
+
+	if r := remaining(`{"query": "query { a }"}`); r != "99" {
+		t.Errorf("expected 99 remaining, got %s", r)
+	}
+	if r := remaining(`{"query": "query { b }"}`); r != "98" {
+		t.Errorf("expected 98 remaining, got %s", r)
+	}
+	if r := remaining(`{"query": "query { a }"}`); r != "98" {
+		t.Errorf("expected cached response to carry the latest rate limit of 98, got %s", r)
+	}
+	if upstream.hits != 2 {
+		t.Errorf("expected 2 upstream requests, got %d", upstream.hits)
+	}
+	for key, v := range store.values {
+		if v.expiresAt.IsZero() {
+			t.Errorf("expected %s to expire with the cache TTL", key)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore().(*memoryStore)
+	store.now = func() time.Time { return now }
+
+	store.Set("forever", []byte("value"), 0)
+	store.Set("short", []byte("value"), time.Second)
+	if !store.Add("lock", []byte("pending"), time.Second) {
+		t.Error("expected to add a new key")
+	}
+	if store.Add("lock", []byte("pending"), time.Second) {
+		t.Error("expected not to add an existing key")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := store.Get("short"); ok {
+		t.Error("expected key to expire")
+	}
+	if _, ok := store.Get("forever"); !ok {
+		t.Error("expected key without ttl not to expire")
+	}
+	if !store.Add("lock", []byte("pending"), time.Second) {
+		t.Error("expected to add an expired key")
+	}
+	if _, ok := store.values["short"]; ok {
+		t.Error("expected expired keys to be swept")
+	}
+	store.Delete("forever")
+	if _, ok := store.Get("forever"); ok {
+		t.Error("expected deleted key to be gone")
+	}
+}
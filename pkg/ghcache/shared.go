@@ -0,0 +1,358 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/cjwagner/httpcache"
+	"github.com/gomodule/redigo/redis"
+	"github.com/sirupsen/logrus"
+)
+
+// SharedStore is a key value store that is shared by all ghproxy replicas.
+// Errors are logged and treated as cache misses, so that ghproxy keeps
+// working without a cache if the store is unavailable.
+type SharedStore interface {
+	// Get returns the value of the key and whether it was found.
+	Get(key string) ([]byte, bool)
+	// Set stores the value of the key, which expires after ttl if positive.
+	Set(key string, value []byte, ttl time.Duration)
+	// Add stores the value of the key only if it does not exist yet and
+	// returns whether it did.
+	Add(key string, value []byte, ttl time.Duration) bool
+	// Delete removes the key.
+	Delete(key string)
+}
+
+type redisStore struct {
+	address string
+	pool    *redis.Pool
+}
+
+// NewRedisStore returns a SharedStore backed by the Redis server at address.
+func NewRedisStore(address string) SharedStore {
+	return &redisStore{
+		address: address,
+		pool: &redis.Pool{
+			MaxIdle:     10,
+			IdleTimeout: 5 * time.Minute,
+			Dial: func() (redis.Conn, error) {
+				return redis.Dial("tcp", address, redis.DialConnectTimeout(5*time.Second))
+			},
+		},
+	}
+}
+
+func (s *redisStore) do(command string, args ...interface{}) (interface{}, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+	return conn.Do(command, args...)
+}
+
+func (s *redisStore) Get(key string) ([]byte, bool) {
+	value, err := redis.Bytes(s.do("GET", key))
+	if err != nil {
+		if !errors.Is(err, redis.ErrNil) {
+			logrus.WithError(err).WithField("redis-address", s.address).Warn("Error reading from Redis.")
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+func (s *redisStore) Set(key string, value []byte, ttl time.Duration) {
+	args := []interface{}{key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	if _, err := s.do("SET", args...); err != nil {
+		logrus.WithError(err).WithField("redis-address", s.address).Warn("Error writing to Redis.")
+	}
+}
+
+func (s *redisStore) Add(key string, value []byte, ttl time.Duration) bool {
+	args := []interface{}{key, value, "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	reply, err := redis.String(s.do("SET", args...))
+	if err != nil {
+		if !errors.Is(err, redis.ErrNil) {
+			logrus.WithError(err).WithField("redis-address", s.address).Warn("Error writing to Redis.")
+		}
+		return false
+	}
+	return reply == "OK"
+}
+
+func (s *redisStore) Delete(key string) {
+	if _, err := s.do("DEL", key); err != nil {
+		logrus.WithError(err).WithField("redis-address", s.address).Warn("Error deleting from Redis.")
+	}
+}
+
+type memcacheStore struct {
+	address string
+	client  *memcache.Client
+}
+
+// NewMemcacheStore returns a SharedStore backed by the memcached server at
+// address. Note that memcached does not store values larger than its item
+// size limit, 1MB by default, such responses are never cached.
+func NewMemcacheStore(address string) SharedStore {
+	return &memcacheStore{address: address, client: memcache.New(address)}
+}
+
+// memcacheExpiration converts a ttl into a memcached expiration, which has
+// a granularity of seconds.
+func memcacheExpiration(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+	return int32(math.Ceil(ttl.Seconds()))
+}
+
+func (s *memcacheStore) Get(key string) ([]byte, bool) {
+	item, err := s.client.Get(key)
+	if err != nil {
+		if !errors.Is(err, memcache.ErrCacheMiss) {
+			logrus.WithError(err).WithField("memcached-address", s.address).Warn("Error reading from memcached.")
+		}
+		return nil, false
+	}
+	return item.Value, true
+}
+
+func (s *memcacheStore) Set(key string, value []byte, ttl time.Duration) {
+	if err := s.client.Set(&memcache.Item{Key: key, Value: value, Expiration: memcacheExpiration(ttl)}); err != nil {
+		logrus.WithError(err).WithField("memcached-address", s.address).Warn("Error writing to memcached.")
+	}
+}
+
+func (s *memcacheStore) Add(key string, value []byte, ttl time.Duration) bool {
+	err := s.client.Add(&memcache.Item{Key: key, Value: value, Expiration: memcacheExpiration(ttl)})
+	if err != nil && !errors.Is(err, memcache.ErrNotStored) {
+		logrus.WithError(err).WithField("memcached-address", s.address).Warn("Error writing to memcached.")
+	}
+	return err == nil
+}
+
+func (s *memcacheStore) Delete(key string) {
+	if err := s.client.Delete(key); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		logrus.WithError(err).WithField("memcached-address", s.address).Warn("Error deleting from memcached.")
+	}
+}
+
+// shardedStore spreads keys over several stores with rendezvous hashing, so
+// that every replica picks the same store for a key and adding or removing a
+// store only moves the keys of that store.
+type shardedStore struct {
+	names  []string
+	stores []SharedStore
+}
+
+// NewShardedStore returns a SharedStore that shards keys over the stores
+// created by newStore for each of the addresses.
+func NewShardedStore(addresses []string, newStore func(address string) SharedStore) SharedStore {
+	if len(addresses) == 1 {
+		return newStore(addresses[0])
+	}
+	s := &shardedStore{}
+	for _, address := range addresses {
+		s.names = append(s.names, address)
+		s.stores = append(s.stores, newStore(address))
+	}
+	return s
+}
+
+func (s *shardedStore) shard(key string) SharedStore {
+	var best int
+	var bestScore uint64
+	for i, name := range s.names {
+		h := fnv.New64a()
+		h.Write([]byte(name))
+		h.Write([]byte(key))
+		if score := mix(h.Sum64()); i == 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return s.stores[best]
+}
+
+// mix is the splitmix64 finalizer, it spreads similar hashes of similar
+// addresses and keys evenly.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func (s *shardedStore) Get(key string) ([]byte, bool) {
+	return s.shard(key).Get(key)
+}
+
+func (s *shardedStore) Set(key string, value []byte, ttl time.Duration) {
+	s.shard(key).Set(key, value, ttl)
+}
+
+func (s *shardedStore) Add(key string, value []byte, ttl time.Duration) bool {
+	return s.shard(key).Add(key, value, ttl)
+}
+
+func (s *shardedStore) Delete(key string) {
+	s.shard(key).Delete(key)
+}
+
+// sharedKey hashes the httpcache key so that it is valid for every store
+// and the same on all replicas.
+func sharedKey(kind, partitionKey, key string) string {
+	return fmt.Sprintf("ghcache:%s:%s:%x", kind, partitionKey, sha256.Sum256([]byte(key)))
+}
+
+// partitionedCache is a httpcache.Cache for one partition of a SharedStore.
+type partitionedCache struct {
+	store        SharedStore
+	partitionKey string
+}
+
+func (c partitionedCache) Get(key string) ([]byte, bool) {
+	return c.store.Get(sharedKey("response", c.partitionKey, key))
+}
+
+func (c partitionedCache) Set(key string, resp []byte) {
+	c.store.Set(sharedKey("response", c.partitionKey, key), resp, 0)
+}
+
+func (c partitionedCache) Delete(key string) {
+	c.store.Delete(sharedKey("response", c.partitionKey, key))
+}
+
+const (
+	revalidationPending = "pending"
+	revalidationDone    = "done"
+)
+
+// sharedCoalescer coalesces the revalidation of cache entries across
+// replicas. The replica that revalidates an entry marks it as pending in the
+// shared store, the others wait for it and then serve the revalidated entry
+// from the cache instead of revalidating it again.
+type sharedCoalescer struct {
+	store        SharedStore
+	partitionKey string
+	cache        httpcache.Cache
+
+	// requestExecutor revalidates the request through the cache.
+	requestExecutor http.RoundTripper
+
+	// lockTTL is how long other replicas wait for a revalidation at most.
+	lockTTL time.Duration
+	// doneTTL is how long a revalidated entry is served to waiting replicas.
+	doneTTL time.Duration
+	// pollInterval is how often waiting replicas check the revalidation.
+	pollInterval time.Duration
+}
+
+func newSharedCoalescer(store SharedStore, partitionKey string, cache httpcache.Cache, requestExecutor http.RoundTripper) *sharedCoalescer {
+	return &sharedCoalescer{
+		store:           store,
+		partitionKey:    partitionKey,
+		cache:           cache,
+		requestExecutor: requestExecutor,
+		lockTTL:         10 * time.Second,
+		doneTTL:         2 * time.Second,
+		pollInterval:    50 * time.Millisecond,
+	}
+}
+
+func (s *sharedCoalescer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return s.requestExecutor.RoundTrip(req)
+	}
+	key := sharedKey("revalidation", s.partitionKey, req.URL.String())
+	if s.store.Add(key, []byte(revalidationPending), s.lockTTL) {
+		resp, err := s.requestExecutor.RoundTrip(req)
+		if err != nil || resp.StatusCode >= 400 {
+			s.store.Delete(key)
+		} else {
+			s.store.Set(key, []byte(revalidationDone), s.doneTTL)
+		}
+		return resp, err
+	}
+
+	// Another replica is revalidating the entry, wait for it.
+	for deadline := time.Now().Add(s.lockTTL); time.Now().Before(deadline); time.Sleep(s.pollInterval) {
+		state, ok := s.store.Get(key)
+		if ok && string(state) == revalidationPending {
+			continue
+		}
+		if ok && string(state) == revalidationDone {
+			if resp := s.cachedResponse(req); resp != nil {
+				if req.Body != nil {
+					req.Body.Close()
+				}
+				return resp, nil
+			}
+		}
+		break
+	}
+	return s.requestExecutor.RoundTrip(req)
+}
+
+// cachedResponse returns the cached response for the request, marked as
+// coalesced, or nil if there is none.
+func (s *sharedCoalescer) cachedResponse(req *http.Request) *http.Response {
+	raw, ok := s.cache.Get(req.URL.String())
+	if !ok {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req)
+	if err != nil {
+		logrus.WithField("cache-key", req.URL.String()).WithError(err).Warn("Error loading cached response.")
+		return nil
+	}
+	resp.Header.Set(CacheModeHeader, string(ModeCoalesced))
+	return resp
+}
+
+// NewSharedCache creates a GitHub cache RoundTripper that is backed by a
+// SharedStore, so that it can be shared by several replicas of ghproxy.
+// The cache is partitioned by the Authorization header and revalidations are
+// coalesced across replicas.
+func NewSharedCache(roundTripper http.RoundTripper, store SharedStore, maxConcurrency int, throttlingTimes RequestThrottlingTimes) http.RoundTripper {
+	return newFromCache(roundTripper,
+		func(partitionKey string, _ *time.Time) httpcache.Cache {
+			return partitionedCache{store: store, partitionKey: partitionKey}
+		},
+		func(partitionKey string, cache httpcache.Cache, requestExecutor http.RoundTripper) http.RoundTripper {
+			return newSharedCoalescer(store, partitionKey, cache, requestExecutor)
+		},
+		maxConcurrency,
+		throttlingTimes)
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStore is an in-memory SharedStore that does not expire keys.
+type fakeStore struct {
+	lock   sync.Mutex
+	values map[string][]byte
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{values: map[string][]byte{}}
+}
+
+func (s *fakeStore) Get(key string) ([]byte, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+func (s *fakeStore) Set(key string, value []byte, _ time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.values[key] = value
+}
+
+func (s *fakeStore) Add(key string, value []byte, _ time.Duration) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.values[key]; ok {
+		return false
+	}
+	s.values[key] = value
+	return true
+}
+
+func (s *fakeStore) Delete(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.values, key)
+}
+
+func TestShardedStore(t *testing.T) {
+	stores := map[string]*fakeStore{}
+	newStore := func(address string) SharedStore {
+		stores[address] = newFakeStore()
+		return stores[address]
+	}
+	three := NewShardedStore([]string{"a", "b", "c"}, newStore).(*shardedStore)
+	two := NewShardedStore([]string{"a", "b"}, newStore).(*shardedStore)
+
+	used := map[SharedStore]bool{}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		shard := three.shard(key)
+		used[shard] = true
+		if shard != three.shard(key) {
+			t.Fatalf("key %s is not consistently sharded", key)
+		}
+		// Removing a store must only move the keys of that store.
+		if shard == three.stores[2] {
+			continue
+		}
+		if twoShard := two.shard(key); two.names[indexOf(two.stores, twoShard)] != three.names[indexOf(three.stores, shard)] {
+			t.Errorf("key %s moved from %s after removing c", key, three.names[indexOf(three.stores, shard)])
+		}
+	}
+	if len(used) != 3 {
+		t.Errorf("expected keys to be spread over 3 stores, got %d", len(used))
+	}
+
+	if _, ok := NewShardedStore([]string{"single"}, newStore).(*fakeStore); !ok {
+		t.Error("expected a single address not to be sharded")
+	}
+}
+
+func indexOf(stores []SharedStore, store SharedStore) int {
+	for i, s := range stores {
+		if s == store {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestPartitionedCache(t *testing.T) {
+	store := newFakeStore()
+	one := partitionedCache{store: store, partitionKey: "one"}
+	two := partitionedCache{store: store, partitionKey: "two"}
+
+	one.Set("https://api.github.com/repos/org/repo", []byte("response"))
+	if value, ok := one.Get("https://api.github.com/repos/org/repo"); !ok || string(value) != "response" {
+		t.Errorf("expected cached response, got %q, %t", value, ok)
+	}
+	if _, ok := two.Get("https://api.github.com/repos/org/repo"); ok {
+		t.Error("expected partitions to be isolated")
+	}
+	for key := range store.values {
+		if len(key) > 250 {
+			t.Errorf("key %s is too long for memcached", key)
+		}
+	}
+	one.Delete("https://api.github.com/repos/org/repo")
+	if _, ok := one.Get("https://api.github.com/repos/org/repo"); ok {
+		t.Error("expected deleted response to be gone")
+	}
+}
+
+// countingExecutor responds with the given status and counts requests.
+type countingExecutor struct {
+	lock   sync.Mutex
+	hits   int
+	status int
+	err    error
+}
+
+func (e *countingExecutor) RoundTrip(req *http.Request) (*http.Response, error) {
+	e.lock.Lock()
+	e.hits++
+	e.lock.Unlock()
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &http.Response{StatusCode: e.status, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString("upstream"))}, nil
+}
+
+func TestSharedCoalescer(t *testing.T) {
+	const url = "https://api.github.com/repos/org/repo"
+	cachedResponse := func() []byte {
+		recorder := httptest.NewRecorder()
+		recorder.WriteString("cached")
+		raw, err := httputil.DumpResponse(recorder.Result(), true)
+		if err != nil {
+			t.Fatalf("failed to dump response: %v", err)
+		}
+		return raw
+	}()
+
+	cases := []struct {
+		name          string
+		state         string
+		finish        bool
+		finishAfter   string
+		cached        bool
+		status        int
+		err           error
+		expectedHits  int
+		expectedBody  string
+		expectedMode  string
+		expectedState string
+	}{
+		{
+			name:          "revalidates and marks the entry as done",
+			status:        http.StatusOK,
+			expectedHits:  1,
+			expectedBody:  "upstream",
+			expectedState: revalidationDone,
+		},
+		{
+			name:         "failed revalidation is not shared",
+			status:       http.StatusBadGateway,
+			expectedHits: 1,
+			expectedBody: "upstream",
+		},
+		{
+			name:         "error is not shared",
+			err:          errors.New("injected error"),
+			expectedHits: 1,
+		},
+		{
+			name:          "waits for the revalidation of another replica",
+			state:         revalidationPending,
+			finish:        true,
+			finishAfter:   revalidationDone,
+			cached:        true,
+			status:        http.StatusOK,
+			expectedBody:  "cached",
+			expectedMode:  string(ModeCoalesced),
+			expectedState: revalidationDone,
+		},
+		{
+			name:         "revalidates itself if the other replica failed",
+			state:        revalidationPending,
+			finish:       true,
+			cached:       true,
+			status:       http.StatusOK,
+			expectedHits: 1,
+			expectedBody: "upstream",
+		},
+		{
+			name:          "revalidates itself if the other replica takes too long",
+			state:         revalidationPending,
+			cached:        true,
+			status:        http.StatusOK,
+			expectedHits:  1,
+			expectedBody:  "upstream",
+			expectedState: revalidationPending,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeStore()
+			cache := partitionedCache{store: store, partitionKey: "partition"}
+			if tc.cached {
+				cache.Set(url, cachedResponse)
+			}
+			key := sharedKey("revalidation", "partition", url)
+			if tc.state != "" {
+				store.Set(key, []byte(tc.state), 0)
+			}
+			executor := &countingExecutor{status: tc.status, err: tc.err}
+			coalescer := newSharedCoalescer(store, "partition", cache, executor)
+			coalescer.lockTTL = 200 * time.Millisecond
+			coalescer.pollInterval = time.Millisecond
+			if tc.finish {
+				go func() {
+					time.Sleep(20 * time.Millisecond)
+					if tc.finishAfter == "" {
+						store.Delete(key)
+					} else {
+						store.Set(key, []byte(tc.finishAfter), 0)
+					}
+				}()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			resp, err := coalescer.RoundTrip(req)
+			if (err != nil) != (tc.err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if executor.hits != tc.expectedHits {
+				t.Errorf("expected %d upstream requests, got %d", tc.expectedHits, executor.hits)
+			}
+			if resp != nil {
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("failed to read body: %v", err)
+				}
+				if string(body) != tc.expectedBody {
+					t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+				}
+				if mode := resp.Header.Get(CacheModeHeader); mode != tc.expectedMode {
+					t.Errorf("expected cache mode %q, got %q", tc.expectedMode, mode)
+				}
+			}
+			state, _ := store.Get(key)
+			if string(state) != tc.expectedState {
+				t.Errorf("expected revalidation state %q, got %q", tc.expectedState, state)
+			}
+		})
+	}
+}
+
+func TestMemcacheExpiration(t *testing.T) {
+	for ttl, expected := range map[time.Duration]int32{
+		0:                      0,
+		500 * time.Millisecond: 1,
+		2 * time.Second:        2,
+	} {
+		if actual := memcacheExpiration(ttl); actual != expected {
+			t.Errorf("expected expiration %d for %v, got %d", expected, ttl, actual)
+		}
+	}
+}
@@ -22,11 +22,13 @@ import (
 	"os/exec"
 	"path"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	utilpointer "k8s.io/utils/pointer"
 )
@@ -36,6 +38,7 @@ var gitMetrics = struct {
 	fetchByShaDuration         *prometheus.HistogramVec
 	secondaryCloneDuration     *prometheus.HistogramVec
 	sparseCheckoutDuration     prometheus.Histogram
+	cacheEvictions             prometheus.Counter
 }{
 	ensureFreshPrimaryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "git_ensure_fresh_primary_duration",
@@ -63,6 +66,10 @@ var gitMetrics = struct {
 		Help:    "Histogram of seconds spent performing sparse checkout for a repository",
 		Buckets: []float64{0.5, 1, 2, 5, 10, 20, 30, 45, 60, 90},
 	}),
+	cacheEvictions: prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "git_cache_evictions",
+		Help: "Number of primary clones evicted from the cache because it held too many repos.",
+	}),
 }
 
 func init() {
@@ -70,6 +77,7 @@ func init() {
 	prometheus.MustRegister(gitMetrics.fetchByShaDuration)
 	prometheus.MustRegister(gitMetrics.secondaryCloneDuration)
 	prometheus.MustRegister(gitMetrics.sparseCheckoutDuration)
+	prometheus.MustRegister(gitMetrics.cacheEvictions)
 }
 
 // ClientFactory knows how to create clientFactory for repos
@@ -102,12 +110,35 @@ type repoClient struct {
 type ClientFactoryOpts struct {
 	// Host, defaults to "github.com" if unset
 	Host string
+	// OrgHosts are the hosts of orgs that live on another GitHub instance
+	// than Host, e.g. on GitHub Enterprise.
+	OrgHosts map[string]string
 	// Whether to use HTTP. By default, HTTPS is used (overrides UseSSH).
 	//
 	// TODO (listx): Combine HTTPS, HTTP, and SSH schemes into a single enum.
 	UseInsecureHTTP *bool
 	// UseSSH, defaults to false
 	UseSSH *bool
+	// SSHKey returns the private key to authenticate with when UseSSH is set,
+	// e.g. from the secret agent. If unset, the keys of the SSH agent or of
+	// the SSH config of the user are used.
+	SSHKey func() []byte
+	// SSHAgentSocket is the socket of the SSH agent to authenticate with when
+	// UseSSH is set. Defaults to $SSH_AUTH_SOCK.
+	SSHAgentSocket string
+	// SSHKnownHostsPath is the known_hosts file to verify the host keys of
+	// servers against when UseSSH is set. If unset, the SSH config of the user
+	// is used.
+	SSHKnownHostsPath string
+	// SSHAcceptNewHostKeys records the host keys of servers that are not in
+	// SSHKnownHostsPath yet, rather than refusing to connect to them.
+	SSHAcceptNewHostKeys *bool
+	// SigningKey returns the private key to sign commits with, e.g. from the
+	// secret agent. If unset, commits are not signed. The key must belong to
+	// the committer, which is the GitUser if set.
+	SigningKey func() []byte
+	// SigningFormat is the format of the SigningKey, defaults to GPG.
+	SigningFormat SigningFormat
 	// The directory in which the cache should be
 	// created. Defaults to the "/var/tmp" on
 	// Linux and os.TempDir otherwise
@@ -125,6 +156,10 @@ type ClientFactoryOpts struct {
 	CookieFilePath string
 	// If set, cacheDir persist. Otherwise temp dir will be used for CacheDir
 	Persist *bool
+	// MaxCachedRepos is the number of primary clones kept in the cache. When
+	// there are more, the least recently used ones that no client uses are
+	// removed. Zero or unset keeps all of them.
+	MaxCachedRepos *int
 }
 
 // These options are scoped to the repo, not the ClientFactory level. The reason
@@ -152,6 +187,14 @@ type RepoOpts struct {
 	// branch name and SHA pairs will be fed into RetargetBranch in the git v2
 	// client, to update the current HEAD of each branch.
 	BranchesToRetarget map[string]string
+	// UseWorktree checks out a worktree of the primary clone instead of
+	// creating a secondary clone, which neither copies objects nor refs and is
+	// therefore much cheaper for large repos. The worktree starts out with a
+	// detached HEAD and shares the refs of the primary clone, so branches are
+	// not prefixed with "origin/" and new branches must not be created in it,
+	// as the next update of the primary clone prunes them. Cleaning the client
+	// removes the worktree. Sparse checkouts are not supported.
+	UseWorktree bool
 }
 
 // Apply allows to use a ClientFactoryOpts as Opt
@@ -159,12 +202,33 @@ func (cfo *ClientFactoryOpts) Apply(target *ClientFactoryOpts) {
 	if cfo.Host != "" {
 		target.Host = cfo.Host
 	}
+	if cfo.OrgHosts != nil {
+		target.OrgHosts = cfo.OrgHosts
+	}
 	if cfo.UseInsecureHTTP != nil {
 		target.UseInsecureHTTP = cfo.UseInsecureHTTP
 	}
 	if cfo.UseSSH != nil {
 		target.UseSSH = cfo.UseSSH
 	}
+	if cfo.SSHKey != nil {
+		target.SSHKey = cfo.SSHKey
+	}
+	if cfo.SSHAgentSocket != "" {
+		target.SSHAgentSocket = cfo.SSHAgentSocket
+	}
+	if cfo.SSHKnownHostsPath != "" {
+		target.SSHKnownHostsPath = cfo.SSHKnownHostsPath
+	}
+	if cfo.SSHAcceptNewHostKeys != nil {
+		target.SSHAcceptNewHostKeys = cfo.SSHAcceptNewHostKeys
+	}
+	if cfo.SigningKey != nil {
+		target.SigningKey = cfo.SigningKey
+	}
+	if cfo.SigningFormat != "" {
+		target.SigningFormat = cfo.SigningFormat
+	}
 	if cfo.CacheDirBase != nil {
 		target.CacheDirBase = cfo.CacheDirBase
 	}
@@ -186,6 +250,9 @@ func (cfo *ClientFactoryOpts) Apply(target *ClientFactoryOpts) {
 	if cfo.Persist != nil {
 		target.Persist = cfo.Persist
 	}
+	if cfo.MaxCachedRepos != nil {
+		target.MaxCachedRepos = cfo.MaxCachedRepos
+	}
 }
 
 func defaultTempDir() *string {
@@ -215,7 +282,8 @@ func defaultClientFactoryOpts(cfo *ClientFactoryOpts) {
 
 // NewClientFactory allows for the creation of repository clients. It uses github.com
 // without authentication by default, if UseSSH then returns
-// sshRemoteResolverFactory, and if CookieFilePath is provided then returns
+// sshRemoteResolverFactory, authenticating with the SSH* options if set, and if
+// CookieFilePath is provided then returns
 // gerritResolverFactory(Assuming that git http.cookiefile is used only by
 // Gerrit, this function needs to be updated if it turned out that this
 // assumtpion is not correct.)
@@ -241,10 +309,26 @@ func NewClientFactory(opts ...ClientFactoryOpt) (ClientFactory, error) {
 		return nil, err
 	}
 
+	ssh, err := newSSHAuth(o, *o.CacheDirBase)
+	if err != nil {
+		return nil, err
+	}
+	var signer *CommitSigner
+	if o.SigningKey != nil {
+		format := o.SigningFormat
+		if format == "" {
+			format = SigningFormatGPG
+		}
+		if signer, err = NewCommitSigner(format, o.SigningKey, *o.CacheDirBase); err != nil {
+			return nil, fmt.Errorf("failed to set up commit signing: %w", err)
+		}
+	}
+
 	var remote RemoteResolverFactory
 	if o.UseSSH != nil && *o.UseSSH {
 		remote = &sshRemoteResolverFactory{
 			host:     o.Host,
+			orgHosts: o.OrgHosts,
 			username: o.Username,
 		}
 	} else if o.CookieFilePath != "" {
@@ -252,19 +336,30 @@ func NewClientFactory(opts ...ClientFactoryOpt) (ClientFactory, error) {
 	} else {
 		remote = &httpResolverFactory{
 			host:     o.Host,
+			orgHosts: o.OrgHosts,
 			http:     o.UseInsecureHTTP != nil && *o.UseInsecureHTTP,
 			username: o.Username,
 			token:    o.Token,
 		}
 	}
+	var maxCachedRepos int
+	if o.MaxCachedRepos != nil {
+		if *o.MaxCachedRepos < 0 {
+			return nil, fmt.Errorf("the maximum number of cached repos must not be negative, got %d", *o.MaxCachedRepos)
+		}
+		maxCachedRepos = *o.MaxCachedRepos
+	}
 	return &clientFactory{
 		cacheDir:       cacheDir,
 		cacheDirBase:   *o.CacheDirBase,
 		remote:         remote,
+		ssh:            ssh,
+		signer:         signer,
 		gitUser:        o.GitUser,
 		censor:         o.Censor,
 		masterLock:     &sync.Mutex{},
-		repoLocks:      map[string]*sync.Mutex{},
+		repos:          map[string]*cachedRepo{},
+		maxCachedRepos: maxCachedRepos,
 		logger:         logrus.WithField("client", "git"),
 		cookieFilePath: o.CookieFilePath,
 	}, nil
@@ -283,13 +378,15 @@ func NewLocalClientFactory(baseDir string, gitUser GitUserGetter, censor Censor)
 		gitUser:    gitUser,
 		censor:     censor,
 		masterLock: &sync.Mutex{},
-		repoLocks:  map[string]*sync.Mutex{},
+		repos:      map[string]*cachedRepo{},
 		logger:     logrus.WithField("client", "git"),
 	}, nil
 }
 
 type clientFactory struct {
 	remote         RemoteResolverFactory
+	ssh            *sshAuth
+	signer         *CommitSigner
 	gitUser        GitUserGetter
 	censor         Censor
 	logger         *logrus.Entry
@@ -299,10 +396,28 @@ type clientFactory struct {
 	cacheDir string
 	// cacheDirBase is the basedir under which create tempdirs
 	cacheDirBase string
-	// masterLock guards mutations to the repoLocks records
+	// masterLock guards mutations to the repos records
 	masterLock *sync.Mutex
-	// repoLocks guard mutating access to subdirectories under the cacheDir
-	repoLocks map[string]*sync.Mutex
+	// repos tracks the primary clones in subdirectories under the cacheDir
+	repos map[string]*cachedRepo
+	// maxCachedRepos is the number of primary clones to keep, zero keeps all
+	maxCachedRepos int
+}
+
+// cachedRepo tracks the use of a primary clone.
+type cachedRepo struct {
+	// lock guards mutating access to the primary clone
+	lock sync.Mutex
+	// lastUpdate is when the last successful update of the primary clone
+	// started, guarded by lock
+	lastUpdate time.Time
+
+	// the following fields are guarded by the masterLock of the factory
+
+	// users counts the clients being created from or using the primary clone,
+	// which must not be evicted while there are any
+	users    int
+	lastUsed time.Time
 }
 
 // bootstrapClients returns a repository client and cloner for a dir.
@@ -320,6 +435,9 @@ func (c *clientFactory) bootstrapClients(org, repo, dir string) (cacher, cloner,
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if configs := c.gitConfigs(); len(configs) > 0 {
+		executor = &configuringExecutor{executor: executor, configs: configs}
+	}
 	var remote RemoteResolverFactory
 	remote = c.remote
 	client := &repoClient{
@@ -342,6 +460,32 @@ func (c *clientFactory) bootstrapClients(org, repo, dir string) (cacher, cloner,
 	return client, client, client, nil
 }
 
+// gitConfigs returns the configuration to run git with for SSH auth and
+// commit signing.
+func (c *clientFactory) gitConfigs() []func() ([]string, error) {
+	var configs []func() ([]string, error)
+	if c.ssh != nil {
+		configs = append(configs, c.ssh.gitConfig)
+	}
+	if c.signer != nil {
+		configs = append(configs, c.signer.GitConfig)
+		if c.gitUser != nil {
+			configs = append(configs, c.committerConfig)
+		}
+	}
+	return configs
+}
+
+// committerConfig commits as the git user, as signatures are only verified
+// for commits by the owner of the key.
+func (c *clientFactory) committerConfig() ([]string, error) {
+	name, email, err := c.gitUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the committer: %w", err)
+	}
+	return []string{"user.name=" + name, "user.email=" + email}, nil
+}
+
 // ClientFromDir returns a repository client for a directory that's already initialized with content.
 // If the directory isn't specified, the current working directory is used.
 func (c *clientFactory) ClientFromDir(org, repo, dir string) (RepoClient, error) {
@@ -371,33 +515,55 @@ func (c *clientFactory) ClientForWithRepoOpts(org, repo string, repoOpts RepoOpt
 	if repoOpts.ShareObjectsWithPrimaryClone && repoOpts.NeededCommits.Len() == 0 {
 		return nil, fmt.Errorf("programmer error: cannot share objects between primary and secondary without targeted fetches (NeededCommits)")
 	}
+	if repoOpts.UseWorktree && repoOpts.SparseCheckoutDirs != nil {
+		return nil, fmt.Errorf("programmer error: worktrees cannot be sparse checkouts")
+	}
 
 	cacheDir := path.Join(c.cacheDir, org, repo)
+	cached := c.acquireRepo(cacheDir)
+	release := func() { c.releaseRepo(cached) }
+	// The primary clone stays in use as long as a worktree of it exists.
+	if !repoOpts.UseWorktree {
+		defer release()
+	}
 	c.logger.WithFields(logrus.Fields{"org": org, "repo": repo, "dir": cacheDir}).Debug("Creating a client from the cache.")
 	cacheClientCacher, _, _, err := c.bootstrapClients(org, repo, cacheDir)
 	if err != nil {
+		if repoOpts.UseWorktree {
+			release()
+		}
 		return nil, err
 	}
 
 	// Put copies of the repo in temp dir.
 	repoDir, err := os.MkdirTemp(*defaultTempDir(), "gitrepo")
 	if err != nil {
+		if repoOpts.UseWorktree {
+			release()
+		}
 		return nil, err
 	}
 	_, repoClientCloner, repoClient, err := c.bootstrapClients(org, repo, repoDir)
 	if err != nil {
+		if repoOpts.UseWorktree {
+			release()
+		}
 		return nil, err
 	}
 
 	// First create or update the primary clone (in "cacheDir").
 	timeBeforeEnsureFreshPrimary := time.Now()
-	err = c.ensureFreshPrimary(cacheDir, cacheClientCacher, repoOpts, org, repo)
+	err = c.ensureFreshPrimary(cacheDir, cached, cacheClientCacher, repoOpts, org, repo)
 	if err != nil {
 		c.logger.WithFields(logrus.Fields{"org": org, "repo": repo, "dir": cacheDir}).Errorf("Error encountered while refreshing primary clone: %s", err.Error())
 	} else {
 		gitMetrics.ensureFreshPrimaryDuration.WithLabelValues(org, repo).Observe(time.Since(timeBeforeEnsureFreshPrimary).Seconds())
 	}
 
+	if repoOpts.UseWorktree {
+		return c.checkoutWorktree(cacheDir, cached, cacheClientCacher, repoClientCloner, repoClient)
+	}
+
 	// Initialize the new derivative repo (secondary clone) from the primary
 	// clone. This is a local clone operation.
 	timeBeforeSecondaryClone := time.Now()
@@ -409,14 +575,136 @@ func (c *clientFactory) ClientForWithRepoOpts(org, repo string, repoOpts RepoOpt
 	return repoClient, nil
 }
 
+// checkoutWorktree checks out a worktree of the primary clone in the directory
+// of the repoClient, and makes cleaning the client remove it again.
+func (c *clientFactory) checkoutWorktree(cacheDir string, cached *cachedRepo, cacheClientCacher cacher, repoClientCloner cloner, repoClient RepoClient) (RepoClient, error) {
+	// Adding worktrees writes to the primary clone, so it must not run
+	// concurrently with updates or the pruning of other worktrees.
+	cached.lock.Lock()
+	err := repoClientCloner.Worktree(cacheDir)
+	cached.lock.Unlock()
+	if err != nil {
+		c.releaseRepo(cached)
+		return nil, err
+	}
+	return &worktreeClient{
+		RepoClient: repoClient,
+		release: func() error {
+			defer c.releaseRepo(cached)
+			cached.lock.Lock()
+			defer cached.lock.Unlock()
+			return cacheClientCacher.PruneWorktrees()
+		},
+	}, nil
+}
+
+// worktreeClient is a client for a worktree of a primary clone.
+type worktreeClient struct {
+	RepoClient
+	release func() error
+	once    sync.Once
+}
+
+// Clean removes the worktree and releases the primary clone.
+func (c *worktreeClient) Clean() error {
+	var errs []error
+	if err := c.RepoClient.Clean(); err != nil {
+		errs = append(errs, err)
+	}
+	c.once.Do(func() {
+		if err := c.release(); err != nil {
+			errs = append(errs, err)
+		}
+	})
+	return utilerrors.NewAggregate(errs)
+}
+
+// acquireRepo marks the primary clone in the cacheDir as used until it is
+// released again, and evicts the least recently used primary clones if the
+// cache holds too many.
+func (c *clientFactory) acquireRepo(cacheDir string) *cachedRepo {
+	c.masterLock.Lock()
+	cached, exists := c.repos[cacheDir]
+	if !exists {
+		cached = &cachedRepo{}
+		c.repos[cacheDir] = cached
+	}
+	cached.users++
+	cached.lastUsed = time.Now()
+	evicted := c.evictLocked()
+	c.masterLock.Unlock()
+
+	c.removeEvicted(evicted)
+	return cached
+}
+
+// releaseRepo marks a primary clone as no longer used by a
+// client, and evicts the least recently used primary clones if the cache
+// holds too many.
+func (c *clientFactory) releaseRepo(cached *cachedRepo) {
+	c.masterLock.Lock()
+	cached.users--
+	cached.lastUsed = time.Now()
+	evicted := c.evictLocked()
+	c.masterLock.Unlock()
+
+	c.removeEvicted(evicted)
+}
+
+func (c *clientFactory) removeEvicted(dirs []string) {
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			c.logger.WithError(err).WithField("dir", dir).Warn("Failed to remove evicted primary clone.")
+		}
+	}
+}
+
+// evictLocked forgets about the least recently used primary clones that are not
+// in use while the cache holds more than maxCachedRepos, and returns
+// directories to remove. They are moved out of the way while the masterLock is
+// held so that the removal can't race with cloning the repo again.
+func (c *clientFactory) evictLocked() []string {
+	if c.maxCachedRepos == 0 || len(c.repos) <= c.maxCachedRepos {
+		return nil
+	}
+	var idle []string
+	for cacheDir, cached := range c.repos {
+		if cached.users == 0 {
+			idle = append(idle, cacheDir)
+		}
+	}
+	sort.Slice(idle, func(i, j int) bool {
+		return c.repos[idle[i]].lastUsed.Before(c.repos[idle[j]].lastUsed)
+	})
+
+	var evicted []string
+	for _, cacheDir := range idle {
+		if len(c.repos) <= c.maxCachedRepos {
+			break
+		}
+		delete(c.repos, cacheDir)
+		gitMetrics.cacheEvictions.Inc()
+		evictedDir := fmt.Sprintf("%s.evicted-%d", cacheDir, time.Now().UnixNano())
+		if err := os.Rename(cacheDir, evictedDir); err != nil {
+			if !os.IsNotExist(err) {
+				c.logger.WithError(err).WithField("dir", cacheDir).Warn("Failed to evict primary clone.")
+			}
+			continue
+		}
+		evicted = append(evicted, evictedDir)
+	}
+	return evicted
+}
+
 func (c *clientFactory) ensureFreshPrimary(
 	cacheDir string,
+	cached *cachedRepo,
 	cacheClientCacher cacher,
 	repoOpts RepoOpts,
 	org string,
 	repo string,
 ) error {
-	if err := c.maybeCloneAndUpdatePrimary(cacheDir, cacheClientCacher, repoOpts); err != nil {
+	if err := c.maybeCloneAndUpdatePrimary(cacheDir, cached, cacheClientCacher, repoOpts); err != nil {
 		return err
 	}
 	// For targeted fetches by SHA objects, there's no need to hold a lock on
@@ -453,30 +741,25 @@ func (c *clientFactory) ensureFreshPrimary(
 // also runs a RemoteUpdate() against it if NeededCommits is empty. The
 // operations in this function are protected by a lock so that only one thread
 // can run at a given time for the same cacheDir (primary clone path).
-func (c *clientFactory) maybeCloneAndUpdatePrimary(cacheDir string, cacheClientCacher cacher, repoOpts RepoOpts) error {
-	// Protect access to the shared repoLocks map. The main point of all this
-	// locking is to ensure that we only try to create the primary clone (if it
-	// doesn't exist) in a serial manner.
-	var repoLock *sync.Mutex
-	c.masterLock.Lock()
-	if _, exists := c.repoLocks[cacheDir]; exists {
-		repoLock = c.repoLocks[cacheDir]
-	} else {
-		repoLock = &sync.Mutex{}
-		c.repoLocks[cacheDir] = repoLock
-	}
-	c.masterLock.Unlock()
-
-	repoLock.Lock()
-	defer repoLock.Unlock()
+//
+// Updates are deduplicated: if an update started and succeeded while waiting
+// for the lock, the primary is already as fresh as it was requested to be.
+func (c *clientFactory) maybeCloneAndUpdatePrimary(cacheDir string, cached *cachedRepo, cacheClientCacher cacher, repoOpts RepoOpts) error {
+	requested := time.Now()
+	// The main point of all this locking is to ensure that we only try to
+	// create the primary clone (if it doesn't exist) in a serial manner.
+	cached.lock.Lock()
+	defer cached.lock.Unlock()
 	if _, err := os.Stat(path.Join(cacheDir, "HEAD")); os.IsNotExist(err) {
 		// we have not yet cloned this repo, we need to do a full clone
 		if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil && !os.IsExist(err) {
 			return err
 		}
+		started := time.Now()
 		if err := cacheClientCacher.MirrorClone(); err != nil {
 			return err
 		}
+		cached.lastUpdate = started
 	} else if err != nil {
 		// something unexpected happened
 		return err
@@ -490,9 +773,14 @@ func (c *clientFactory) maybeCloneAndUpdatePrimary(cacheDir string, cacheClientC
 		// This call to RemoteUpdate() still needs to be protected by a lock
 		// because it updates possibly hundreds, if not thousands, of refs
 		// (quite literally, files in .git/refs/*).
+		if cached.lastUpdate.After(requested) {
+			return nil
+		}
+		started := time.Now()
 		if err := cacheClientCacher.RemoteUpdate(); err != nil {
 			return err
 		}
+		cached.lastUpdate = started
 	}
 
 	return nil
@@ -500,5 +788,13 @@ func (c *clientFactory) maybeCloneAndUpdatePrimary(cacheDir string, cacheClientC
 
 // Clean removes the caches used to generate clients
 func (c *clientFactory) Clean() error {
+	if err := c.ssh.clean(); err != nil {
+		return err
+	}
+	if c.signer != nil {
+		if err := c.signer.Clean(); err != nil {
+			return err
+		}
+	}
 	return os.RemoveAll(c.cacheDir)
 }
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeRepo creates a repo with a single commit under baseDir/org/repo.
+func makeRepo(t *testing.T, baseDir, org, repo string) {
+	t.Helper()
+	dir := path.Join(baseDir, org, repo)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatalf("failed to create repo dir: %v", err)
+	}
+	if err := os.WriteFile(path.Join(dir, "README"), []byte(repo), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "README"},
+		{"-c", "user.name=robot", "-c", "user.email=robot@beep.boop", "commit", "-q", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v %s", args, err, out)
+		}
+	}
+}
+
+func newTestClientFactory(t *testing.T, baseDir string, maxCachedRepos int) *clientFactory {
+	t.Helper()
+	factory, err := NewLocalClientFactory(baseDir,
+		func() (name, email string, err error) { return "robot", "robot@beep.boop", nil },
+		func(content []byte) []byte { return content })
+	if err != nil {
+		t.Fatalf("failed to create client factory: %v", err)
+	}
+	t.Cleanup(func() { factory.Clean() })
+	c := factory.(*clientFactory)
+	c.maxCachedRepos = maxCachedRepos
+	return c
+}
+
+func worktrees(t *testing.T, cacheDir string) int {
+	t.Helper()
+	out, err := exec.Command("git", "-C", cacheDir, "worktree", "list", "--porcelain").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to list worktrees: %v %s", err, out)
+	}
+	return strings.Count(string(out), "worktree ")
+}
+
+func TestClientForWithRepoOptsWorktree(t *testing.T) {
+	baseDir := t.TempDir()
+	makeRepo(t, baseDir, "org", "repo")
+	c := newTestClientFactory(t, baseDir, 0)
+	cacheDir := path.Join(c.cacheDir, "org", "repo")
+
+	client, err := c.ClientForWithRepoOpts("org", "repo", RepoOpts{UseWorktree: true})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if content, err := os.ReadFile(path.Join(client.Directory(), "README")); err != nil || string(content) != "repo" {
+		t.Errorf("expected the worktree to be checked out, got %q (%v)", content, err)
+	}
+	if n := worktrees(t, cacheDir); n != 2 {
+		t.Errorf("expected the primary clone to have a worktree, got %d worktrees", n-1)
+	}
+	if users := c.repos[cacheDir].users; users != 1 {
+		t.Errorf("expected the worktree to use the primary clone, got %d users", users)
+	}
+
+	if err := client.Clean(); err != nil {
+		t.Fatalf("failed to clean client: %v", err)
+	}
+	if _, err := os.Stat(client.Directory()); !os.IsNotExist(err) {
+		t.Errorf("expected the worktree to be removed, got %v", err)
+	}
+	if n := worktrees(t, cacheDir); n != 1 {
+		t.Errorf("expected the worktree to be pruned, got %d worktrees", n-1)
+	}
+	if users := c.repos[cacheDir].users; users != 0 {
+		t.Errorf("expected the primary clone to be released, got %d users", users)
+	}
+
+	if _, err := c.ClientForWithRepoOpts("org", "repo", RepoOpts{UseWorktree: true, SparseCheckoutDirs: []string{}}); err == nil {
+		t.Error("expected an error for a sparse worktree")
+	}
+}
+
+func TestClientFactoryEviction(t *testing.T) {
+	baseDir := t.TempDir()
+	for _, repo := range []string{"first", "second", "third"} {
+		makeRepo(t, baseDir, "org", repo)
+	}
+	c := newTestClientFactory(t, baseDir, 1)
+	cached := func(repo string) bool {
+		_, err := os.Stat(path.Join(c.cacheDir, "org", repo, "HEAD"))
+		return err == nil
+	}
+
+	first, err := c.ClientFor("org", "first")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	first.Clean()
+	worktree, err := c.ClientForWithRepoOpts("org", "second", RepoOpts{UseWorktree: true})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if cached("first") || !cached("second") {
+		t.Errorf("expected the least recently used primary clone to be evicted, first cached: %t, second cached: %t", cached("first"), cached("second"))
+	}
+
+	third, err := c.ClientFor("org", "third")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	third.Clean()
+	if !cached("second") || cached("third") {
+		t.Errorf("expected the primary clone with a worktree to be kept, second cached: %t, third cached: %t", cached("second"), cached("third"))
+	}
+
+	worktree.Clean()
+	if len(c.repos) != 1 {
+		t.Errorf("expected a single cached repo once the worktree is cleaned, got %d", len(c.repos))
+	}
+}
+
+type countingCacher struct {
+	cacher
+	remoteUpdates int
+}
+
+func (c *countingCacher) RemoteUpdate() error {
+	c.remoteUpdates++
+	return nil
+}
+
+func TestMaybeCloneAndUpdatePrimaryDeduplicatesUpdates(t *testing.T) {
+	cacheDir := t.TempDir()
+	if err := os.WriteFile(path.Join(cacheDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatalf("failed to write HEAD: %v", err)
+	}
+	c := &clientFactory{}
+	cacher := &countingCacher{}
+
+	cached := &cachedRepo{lastUpdate: time.Now().Add(-time.Minute)}
+	if err := c.maybeCloneAndUpdatePrimary(cacheDir, cached, cacher, RepoOpts{}); err != nil {
+		t.Fatalf("failed to update primary: %v", err)
+	}
+	if cacher.remoteUpdates != 1 {
+		t.Errorf("expected a stale primary clone to be updated, got %d updates", cacher.remoteUpdates)
+	}
+
+	// An update that started after the request was made is fresh enough.
+	cached.lastUpdate = time.Now().Add(time.Minute)
+	if err := c.maybeCloneAndUpdatePrimary(cacheDir, cached, cacher, RepoOpts{}); err != nil {
+		t.Fatalf("failed to update primary: %v", err)
+	}
+	if cacher.remoteUpdates != 1 {
+		t.Errorf("expected the update to be deduplicated, got %d updates", cacher.remoteUpdates)
+	}
+}
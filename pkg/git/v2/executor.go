@@ -73,3 +73,25 @@ func (e *censoringExecutor) Run(args ...string) ([]byte, error) {
 	}
 	return b, err
 }
+
+// configuringExecutor runs git with configuration that can change between
+// invocations, e.g. because it refers to keys that are rotated.
+type configuringExecutor struct {
+	executor
+	// configs return the configuration as key=value pairs
+	configs []func() ([]string, error)
+}
+
+func (e *configuringExecutor) Run(args ...string) ([]byte, error) {
+	var configArgs []string
+	for _, config := range e.configs {
+		pairs, err := config()
+		if err != nil {
+			return nil, err
+		}
+		for _, pair := range pairs {
+			configArgs = append(configArgs, "-c", pair)
+		}
+	}
+	return e.executor.Run(append(configArgs, args...)...)
+}
@@ -87,6 +87,8 @@ type cacher interface {
 	FetchCommits([]string) error
 	// RetargetBranch moves the given branch to an already-existing commit.
 	RetargetBranch(string, string) error
+	// PruneWorktrees forgets about worktrees whose directory was removed.
+	PruneWorktrees() error
 }
 
 // cloner knows how to clone repositories from a central cache
@@ -94,6 +96,8 @@ type cloner interface {
 	// Clone clones the repository from a local path.
 	Clone(from string) error
 	CloneWithRepoOpts(from string, repoOpts RepoOpts) error
+	// Worktree checks out a worktree of the mirror at a local path.
+	Worktree(from string) error
 }
 
 // MergeOpt holds options for git merge operations.
@@ -191,6 +195,25 @@ func (i *interactor) CloneWithRepoOpts(from string, repoOpts RepoOpts) error {
 	return nil
 }
 
+// Worktree checks out a worktree of the mirror at a local path, with a detached
+// HEAD at the HEAD of the mirror. Unlike a clone, it doesn't copy any objects or
+// refs.
+func (i *interactor) Worktree(from string) error {
+	i.logger.Infof("Creating a worktree of the repo at %s from %s", i.dir, from)
+	if out, err := i.executor.Run("-C", from, "worktree", "add", "--detach", i.dir, "HEAD"); err != nil {
+		return fmt.Errorf("error creating a worktree: %w %v", err, string(out))
+	}
+	return nil
+}
+
+// PruneWorktrees forgets about worktrees whose directory was removed.
+func (i *interactor) PruneWorktrees() error {
+	if out, err := i.executor.Run("worktree", "prune"); err != nil {
+		return fmt.Errorf("error pruning worktrees: %w %v", err, string(out))
+	}
+	return nil
+}
+
 // MirrorClone sets up a mirror of the source repository.
 func (i *interactor) MirrorClone() error {
 	i.logger.Infof("Creating a mirror of the repo at %s", i.dir)
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// keyFile writes a key, e.g. from the secret agent, to a file for tools that
+// can only read keys from files.
+type keyFile struct {
+	// key returns the current key.
+	key func() []byte
+	// path is where the key is written to.
+	path string
+
+	// lock guards writing the key
+	lock sync.Mutex
+	// written is the key that was written to path last
+	written []byte
+}
+
+// write writes the key if it changed since it was last written, as keys from
+// the secret agent may be rotated, and tells whether it did.
+func (f *keyFile) write() (bool, error) {
+	key := f.key()
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.written != nil && bytes.Equal(key, f.written) {
+		return false, nil
+	}
+	// Write the key to a temporary file and move it into place, so that
+	// concurrent readers never read a partial key.
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), "key-")
+	if err != nil {
+		return false, fmt.Errorf("failed to write key: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	// The secret agent trims secrets, but ssh and gpg require keys to end
+	// with a newline.
+	if _, err := tmp.Write(append(bytes.TrimSpace(key), '\n')); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("failed to write key: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return false, fmt.Errorf("failed to write key: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return false, fmt.Errorf("failed to write key: %w", err)
+	}
+	f.written = key
+	return true, nil
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/url"
 	"path"
+	"strings"
 
 	gerritsource "sigs.k8s.io/prow/pkg/gerrit/source"
 )
@@ -54,15 +55,27 @@ type LoginGetter func() (login string, err error)
 // TokenGetter fetches a GitHub OAuth token on-demand
 type TokenGetter func(org string) (string, error)
 
+// hostFor returns the host of an org, which is the default host unless the org
+// lives on another GitHub instance. Org names are case insensitive.
+func hostFor(host string, orgHosts map[string]string, org string) string {
+	for name, orgHost := range orgHosts {
+		if strings.EqualFold(name, org) {
+			return orgHost
+		}
+	}
+	return host
+}
+
 type sshRemoteResolverFactory struct {
 	host     string
+	orgHosts map[string]string
 	username LoginGetter
 }
 
 // CentralRemote creates a remote resolver that refers to an authoritative remote
 // for the repository.
 func (f *sshRemoteResolverFactory) CentralRemote(org, repo string) RemoteResolver {
-	remote := fmt.Sprintf("git@%s:%s/%s.git", f.host, org, repo)
+	remote := fmt.Sprintf("git@%s:%s/%s.git", hostFor(f.host, f.orgHosts, org), org, repo)
 	return func() (string, error) {
 		return remote, nil
 	}
@@ -70,7 +83,9 @@ func (f *sshRemoteResolverFactory) CentralRemote(org, repo string) RemoteResolve
 
 // PublishRemote creates a remote resolver that refers to a user's remote
 // for the repository that can be published to.
-func (f *sshRemoteResolverFactory) PublishRemote(_, centralRepo string) ForkRemoteResolver {
+func (f *sshRemoteResolverFactory) PublishRemote(centralOrg, centralRepo string) ForkRemoteResolver {
+	// Forks live on the same instance as the central repo.
+	host := hostFor(f.host, f.orgHosts, centralOrg)
 	return func(forkName string) (string, error) {
 		repo := centralRepo
 		if forkName != "" {
@@ -80,14 +95,15 @@ func (f *sshRemoteResolverFactory) PublishRemote(_, centralRepo string) ForkRemo
 		if err != nil {
 			return "", err
 		}
-		return fmt.Sprintf("git@%s:%s/%s.git", f.host, org, repo), nil
+		return fmt.Sprintf("git@%s:%s/%s.git", host, org, repo), nil
 	}
 }
 
 type httpResolverFactory struct {
 	// Whether to use HTTP.
-	http bool
-	host string
+	http     bool
+	host     string
+	orgHosts map[string]string
 	// Optional, either both or none must be set
 	username LoginGetter
 	token    TokenGetter
@@ -97,13 +113,15 @@ type httpResolverFactory struct {
 // for the repository.
 func (f *httpResolverFactory) CentralRemote(org, repo string) RemoteResolver {
 	return func() (string, error) {
-		return f.resolve(org, repo)
+		return f.resolve(hostFor(f.host, f.orgHosts, org), org, repo)
 	}
 }
 
 // PublishRemote creates a remote resolver that refers to a user's remote
 // for the repository that can be published to.
-func (f *httpResolverFactory) PublishRemote(_, centralRepo string) ForkRemoteResolver {
+func (f *httpResolverFactory) PublishRemote(centralOrg, centralRepo string) ForkRemoteResolver {
+	// Forks live on the same instance as the central repo.
+	host := hostFor(f.host, f.orgHosts, centralOrg)
 	return func(forkName string) (string, error) {
 		// For the publsh remote we use:
 		// - the user login rather than the central org
@@ -119,7 +137,7 @@ func (f *httpResolverFactory) PublishRemote(_, centralRepo string) ForkRemoteRes
 		if err != nil {
 			return "", fmt.Errorf("could not resolve username: %w", err)
 		}
-		remote, err := f.resolve(org, repo)
+		remote, err := f.resolve(host, org, repo)
 		if err != nil {
 			err = fmt.Errorf("could not resolve remote: %w", err)
 		}
@@ -129,12 +147,12 @@ func (f *httpResolverFactory) PublishRemote(_, centralRepo string) ForkRemoteRes
 
 // resolve builds the URL string for the given org/repo remote identifier, it
 // respects the configured scheme, and the dynamic username and credentials.
-func (f *httpResolverFactory) resolve(org, repo string) (string, error) {
+func (f *httpResolverFactory) resolve(host, org, repo string) (string, error) {
 	scheme := "https"
 	if f.http {
 		scheme = "http"
 	}
-	remote := &url.URL{Scheme: scheme, Host: f.host, Path: fmt.Sprintf("%s/%s", org, repo)}
+	remote := &url.URL{Scheme: scheme, Host: host, Path: fmt.Sprintf("%s/%s", org, repo)}
 
 	if f.username != nil {
 		name, err := f.username()
@@ -209,3 +209,35 @@ func TestHTTPResolverFactory(t *testing.T) {
 		t.Errorf("publish remote with a different fork name returned an unexpected error: %v", actualErr)
 	}
 }
+
+func TestResolverFactoriesOrgHosts(t *testing.T) {
+	orgHosts := map[string]string{"Enterprise-Org": "ghe.example.com"}
+	sshFactory := sshRemoteResolverFactory{host: "github.com", orgHosts: orgHosts, username: usernameVendor([]stringWithError{{str: "bot"}})}
+	httpFactory := httpResolverFactory{host: "github.com", orgHosts: orgHosts}
+
+	for _, tc := range []struct {
+		name     string
+		resolver RemoteResolver
+		expected string
+	}{
+		{name: "ssh org on another host", resolver: sshFactory.CentralRemote("enterprise-org", "repo"), expected: "git@ghe.example.com:enterprise-org/repo.git"},
+		{name: "ssh org on the default host", resolver: sshFactory.CentralRemote("org", "repo"), expected: "git@github.com:org/repo.git"},
+		{name: "http org on another host", resolver: httpFactory.CentralRemote("enterprise-org", "repo"), expected: "https://ghe.example.com/enterprise-org/repo"},
+		{name: "http org on the default host", resolver: httpFactory.CentralRemote("org", "repo"), expected: "https://github.com/org/repo"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.resolver()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected remote %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+
+	publish := sshFactory.PublishRemote("enterprise-org", "repo")
+	if actual, err := publish(""); err != nil || actual != "git@ghe.example.com:bot/repo.git" {
+		t.Errorf("expected the fork to live on the host of the central repo, got %s (%v)", actual, err)
+	}
+}
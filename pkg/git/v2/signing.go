@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SigningFormat is the format of the key commits are signed with.
+type SigningFormat string
+
+const (
+	// SigningFormatGPG signs commits with an armored OpenPGP private key.
+	SigningFormatGPG SigningFormat = "gpg"
+	// SigningFormatSSH signs commits with an SSH private key.
+	SigningFormatSSH SigningFormat = "ssh"
+)
+
+// CommitSigner signs the commits git creates, so that orgs that require
+// verified commits accept the ones created by bots. The key must belong to
+// the committer, e.g. be registered with its GitHub account, and must not be
+// protected with a passphrase.
+type CommitSigner struct {
+	format SigningFormat
+	key    *keyFile
+	// dir holds the key and, for GPG, the keyring it is imported into
+	dir string
+
+	// gpg is the wrapper that runs gpg with the keyring in dir
+	gpg string
+	// lock guards importing the key and fingerprint
+	lock sync.Mutex
+	// fingerprint identifies the imported GPG key
+	fingerprint string
+}
+
+// NewCommitSigner creates a signer for a key, e.g. from the secret agent. The
+// key is written to a temporary directory below dir, which Clean removes.
+func NewCommitSigner(format SigningFormat, key func() []byte, dir string) (*CommitSigner, error) {
+	if key == nil {
+		return nil, fmt.Errorf("no signing key")
+	}
+	var gpg string
+	switch format {
+	case SigningFormatSSH:
+	case SigningFormatGPG:
+		var err error
+		if gpg, err = exec.LookPath("gpg"); err != nil {
+			return nil, fmt.Errorf("signing commits with GPG keys requires gpg: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown signing format %q, use %q or %q", format, SigningFormatGPG, SigningFormatSSH)
+	}
+
+	signingDir, err := os.MkdirTemp(dir, "gitsigning")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a directory for the signing key: %w", err)
+	}
+	s := &CommitSigner{
+		format: format,
+		key:    &keyFile{key: key, path: filepath.Join(signingDir, "key")},
+		dir:    signingDir,
+	}
+	if format == SigningFormatGPG {
+		// git can't pass a keyring to gpg, so it runs a wrapper that does.
+		s.gpg = filepath.Join(signingDir, "gpg")
+		wrapper := fmt.Sprintf("#!/bin/sh\nexec %s --homedir %s \"$@\"\n", shellQuote(gpg), shellQuote(s.gnupgHome()))
+		if err := os.WriteFile(s.gpg, []byte(wrapper), 0700); err != nil {
+			s.Clean()
+			return nil, fmt.Errorf("failed to write gpg wrapper: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *CommitSigner) gnupgHome() string {
+	return filepath.Join(s.dir, "gnupg")
+}
+
+// GitConfig returns the git configuration to sign commits with, as key=value
+// pairs to pass with `git -c`.
+func (s *CommitSigner) GitConfig() ([]string, error) {
+	changed, err := s.key.write()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the signing key: %w", err)
+	}
+	config := []string{"commit.gpgsign=true", "tag.gpgsign=true"}
+	if s.format == SigningFormatSSH {
+		return append(config, "gpg.format=ssh", "user.signingkey="+s.key.path), nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if changed || s.fingerprint == "" {
+		if s.fingerprint, err = s.importGPGKey(); err != nil {
+			return nil, err
+		}
+	}
+	return append(config, "gpg.format=openpgp", "gpg.program="+s.gpg, "user.signingkey="+s.fingerprint), nil
+}
+
+// importGPGKey imports the key into a new keyring, so that a rotated key
+// replaces the previous one, and returns its fingerprint.
+func (s *CommitSigner) importGPGKey() (string, error) {
+	if err := os.RemoveAll(s.gnupgHome()); err != nil {
+		return "", fmt.Errorf("failed to remove the previous keyring: %w", err)
+	}
+	if err := os.Mkdir(s.gnupgHome(), 0700); err != nil {
+		return "", fmt.Errorf("failed to create the keyring: %w", err)
+	}
+	if out, err := exec.Command(s.gpg, "--batch", "--import", s.key.path).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to import the signing key: %w %s", err, string(out))
+	}
+	out, err := exec.Command(s.gpg, "--batch", "--with-colons", "--list-secret-keys").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to list the signing key: %w %s", err, string(out))
+	}
+	// The first fingerprint is the one of the primary key.
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Split(line, ":"); len(fields) > 9 && fields[0] == "fpr" {
+			return fields[9], nil
+		}
+	}
+	return "", fmt.Errorf("the signing key is not a GPG private key")
+}
+
+// Clean removes the key.
+func (s *CommitSigner) Clean() error {
+	return os.RemoveAll(s.dir)
+}
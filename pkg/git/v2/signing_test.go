@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// generateSigningKey generates a key without a passphrase in the format.
+func generateSigningKey(t *testing.T, format SigningFormat) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	var commands [][]string
+	switch format {
+	case SigningFormatSSH:
+		commands = [][]string{{"ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", filepath.Join(dir, "key")}}
+	case SigningFormatGPG:
+		commands = [][]string{
+			{"gpg", "--homedir", dir, "--batch", "--passphrase", "", "--quick-gen-key", "robot <robot@beep.boop>", "ed25519", "sign", "never"},
+			{"sh", "-c", "gpg --homedir " + dir + " --batch --armor --export-secret-keys > " + filepath.Join(dir, "key")},
+		}
+	}
+	for _, command := range commands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			t.Skipf("%s is not installed", command[0])
+		}
+		if out, err := exec.Command(command[0], command[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("failed to generate key: %v %s", err, out)
+		}
+	}
+	key, err := os.ReadFile(filepath.Join(dir, "key"))
+	if err != nil {
+		t.Fatalf("failed to read key: %v", err)
+	}
+	return key
+}
+
+func TestCommitSigner(t *testing.T) {
+	for _, format := range []SigningFormat{SigningFormatSSH, SigningFormatGPG} {
+		t.Run(string(format), func(t *testing.T) {
+			key := generateSigningKey(t, format)
+			signer, err := NewCommitSigner(format, func() []byte { return key }, t.TempDir())
+			if err != nil {
+				t.Fatalf("failed to create signer: %v", err)
+			}
+
+			dir := t.TempDir()
+			executor, err := NewCensoringExecutor(dir, func(content []byte) []byte { return content }, logrus.NewEntry(logrus.New()))
+			if err != nil {
+				t.Fatalf("failed to create executor: %v", err)
+			}
+			committer := func() ([]string, error) {
+				return []string{"user.name=robot", "user.email=robot@beep.boop"}, nil
+			}
+			e := &configuringExecutor{executor: executor, configs: []func() ([]string, error){signer.GitConfig, committer}}
+			for _, args := range [][]string{{"init", "-q"}, {"commit", "-q", "--allow-empty", "-m", "signed"}} {
+				if out, err := e.Run(args...); err != nil {
+					t.Fatalf("git %v failed: %v %s", args, err, out)
+				}
+			}
+			out, err := e.Run("cat-file", "commit", "HEAD")
+			if err != nil {
+				t.Fatalf("failed to read commit: %v %s", err, out)
+			}
+			if !strings.Contains(string(out), "gpgsig ") {
+				t.Errorf("expected the commit to be signed, got:\n%s", out)
+			}
+
+			if err := signer.Clean(); err != nil {
+				t.Fatalf("failed to clean: %v", err)
+			}
+			if _, err := os.Stat(signer.dir); !os.IsNotExist(err) {
+				t.Errorf("expected the key to be removed, got %v", err)
+			}
+		})
+	}
+
+	if _, err := NewCommitSigner("x509", func() []byte { return nil }, t.TempDir()); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sshAuth configures how git authenticates to servers over SSH, for servers
+// that don't offer HTTPS token auth.
+type sshAuth struct {
+	// key is the private key to authenticate with, if any.
+	key *keyFile
+	// agentSocket is the socket of the SSH agent to authenticate with, if any.
+	agentSocket string
+	// knownHostsPath is the known_hosts file to verify host keys against.
+	knownHostsPath string
+	// acceptNewHostKeys records unknown host keys rather than failing.
+	acceptNewHostKeys bool
+}
+
+// newSSHAuth returns the SSH auth for the options, or nil if they leave it to
+// the SSH config of the user. Keys are written below dir.
+func newSSHAuth(o ClientFactoryOpts, dir string) (*sshAuth, error) {
+	acceptNewHostKeys := o.SSHAcceptNewHostKeys != nil && *o.SSHAcceptNewHostKeys
+	if acceptNewHostKeys && o.SSHKnownHostsPath == "" {
+		return nil, fmt.Errorf("accepting new host keys requires a known hosts file")
+	}
+	if o.SSHKey == nil && o.SSHAgentSocket == "" && o.SSHKnownHostsPath == "" {
+		return nil, nil
+	}
+	if o.UseSSH == nil || !*o.UseSSH {
+		return nil, fmt.Errorf("SSH auth is configured, but SSH is not used")
+	}
+	auth := &sshAuth{
+		agentSocket:       o.SSHAgentSocket,
+		knownHostsPath:    o.SSHKnownHostsPath,
+		acceptNewHostKeys: acceptNewHostKeys,
+	}
+	if o.SSHKey != nil {
+		keyDir, err := os.MkdirTemp(dir, "gitssh")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create a directory for the SSH key: %w", err)
+		}
+		auth.key = &keyFile{key: o.SSHKey, path: filepath.Join(keyDir, "id")}
+	}
+	return auth, nil
+}
+
+// command returns the ssh command for git to use.
+func (a *sshAuth) command() string {
+	args := []string{"ssh", "-o", "BatchMode=yes"}
+	if a.key != nil {
+		args = append(args, "-o", "IdentitiesOnly=yes", "-i", shellQuote(a.key.path))
+	}
+	if a.agentSocket != "" {
+		args = append(args, "-o", shellQuote("IdentityAgent="+a.agentSocket))
+	}
+	if a.knownHostsPath != "" {
+		strictHostKeyChecking := "yes"
+		if a.acceptNewHostKeys {
+			strictHostKeyChecking = "accept-new"
+		}
+		args = append(args, "-o", shellQuote("UserKnownHostsFile="+a.knownHostsPath), "-o", "StrictHostKeyChecking="+strictHostKeyChecking)
+	}
+	return strings.Join(args, " ")
+}
+
+// gitConfig returns the git config to use the ssh command with.
+func (a *sshAuth) gitConfig() ([]string, error) {
+	if a.key != nil {
+		if _, err := a.key.write(); err != nil {
+			return nil, fmt.Errorf("failed to write the SSH key: %w", err)
+		}
+	}
+	return []string{"core.sshCommand=" + a.command()}, nil
+}
+
+// clean removes the key.
+func (a *sshAuth) clean() error {
+	if a == nil || a.key == nil {
+		return nil
+	}
+	return os.RemoveAll(filepath.Dir(a.key.path))
+}
+
+// shellQuote quotes a string for the shell that git runs the ssh command with.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	utilpointer "k8s.io/utils/pointer"
+)
+
+func TestNewSSHAuth(t *testing.T) {
+	key := func() []byte { return []byte("key") }
+	testCases := []struct {
+		name            string
+		opts            ClientFactoryOpts
+		expectedCommand string
+		expectedErr     bool
+	}{
+		{
+			name: "no SSH auth",
+			opts: ClientFactoryOpts{UseSSH: utilpointer.Bool(true)},
+		},
+		{
+			name:            "key",
+			opts:            ClientFactoryOpts{UseSSH: utilpointer.Bool(true), SSHKey: key},
+			expectedCommand: "ssh -o BatchMode=yes -o IdentitiesOnly=yes -i '<key>'",
+		},
+		{
+			name:            "agent and known hosts",
+			opts:            ClientFactoryOpts{UseSSH: utilpointer.Bool(true), SSHAgentSocket: "/run/agent.sock", SSHKnownHostsPath: "/etc/ssh/known hosts"},
+			expectedCommand: "ssh -o BatchMode=yes -o 'IdentityAgent=/run/agent.sock' -o 'UserKnownHostsFile=/etc/ssh/known hosts' -o StrictHostKeyChecking=yes",
+		},
+		{
+			name:            "accept new host keys",
+			opts:            ClientFactoryOpts{UseSSH: utilpointer.Bool(true), SSHKnownHostsPath: "/known_hosts", SSHAcceptNewHostKeys: utilpointer.Bool(true)},
+			expectedCommand: "ssh -o BatchMode=yes -o 'UserKnownHostsFile=/known_hosts' -o StrictHostKeyChecking=accept-new",
+		},
+		{
+			name:        "accept new host keys without known hosts",
+			opts:        ClientFactoryOpts{UseSSH: utilpointer.Bool(true), SSHAcceptNewHostKeys: utilpointer.Bool(true)},
+			expectedErr: true,
+		},
+		{
+			name:        "SSH auth without SSH",
+			opts:        ClientFactoryOpts{SSHKey: key},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auth, err := newSSHAuth(tc.opts, t.TempDir())
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if tc.expectedCommand == "" {
+				if auth != nil {
+					t.Errorf("expected no SSH auth, got command %s", auth.command())
+				}
+				return
+			}
+			if auth.key != nil {
+				tc.expectedCommand = strings.ReplaceAll(tc.expectedCommand, "<key>", auth.key.path)
+			}
+			if command := auth.command(); command != tc.expectedCommand {
+				t.Errorf("expected command %s, got %s", tc.expectedCommand, command)
+			}
+		})
+	}
+}
+
+func TestSSHAuthGitConfig(t *testing.T) {
+	key := "first key"
+	auth, err := newSSHAuth(ClientFactoryOpts{UseSSH: utilpointer.Bool(true), SSHKey: func() []byte { return []byte(key) }}, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create SSH auth: %v", err)
+	}
+	command := "core.sshCommand=" + auth.command()
+	e := &configuringExecutor{
+		executor: &fakeExecutor{responses: map[string]execResponse{
+			"-c " + command + " fetch": {},
+		}},
+		configs: []func() ([]string, error){auth.gitConfig},
+	}
+
+	for _, expected := range []string{"first key\n", "second key\n"} {
+		if _, err := e.Run("fetch"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content, err := os.ReadFile(auth.key.path)
+		if err != nil {
+			t.Fatalf("failed to read key: %v", err)
+		}
+		if string(content) != expected {
+			t.Errorf("expected key %q, got %q", expected, content)
+		}
+		key = "second key"
+	}
+	info, err := os.Stat(auth.key.path)
+	if err != nil {
+		t.Fatalf("failed to stat key: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected the key to only be readable by the user, got mode %v", mode)
+	}
+	if records := e.executor.(*fakeExecutor).records; !reflect.DeepEqual(records[0], []string{"-c", command, "fetch"}) {
+		t.Errorf("expected the ssh command to be configured, got %v", records[0])
+	}
+
+	if err := auth.clean(); err != nil {
+		t.Fatalf("failed to clean: %v", err)
+	}
+	if _, err := os.Stat(auth.key.path); !os.IsNotExist(err) {
+		t.Errorf("expected the key to be removed, got %v", err)
+	}
+}
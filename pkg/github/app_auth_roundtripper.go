@@ -101,11 +101,34 @@ func (arr *appsRoundTripper) RoundTrip(r *http.Request) (*http.Response, error)
 		if err := arr.addAppAuth(r); err != nil {
 			return nil, err
 		}
-	} else if err := arr.addAppInstallationAuth(r); err != nil {
+		return arr.upstream.RoundTrip(r)
+	}
+
+	if err := arr.addAppInstallationAuth(r); err != nil {
 		return nil, err
 	}
+	resp, err := arr.upstream.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
 
-	return arr.upstream.RoundTrip(r)
+	// The installation token expired or was revoked before we expected, e.g.
+	// in the middle of a paginated listing. Mint a new one and retry once.
+	retry := r.Clone(r.Context())
+	if r.Body != nil && r.Body != http.NoBody {
+		if r.GetBody == nil {
+			return resp, nil
+		}
+		if retry.Body, err = r.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	arr.invalidateTokenFor(extractOrgFromContext(r.Context()))
+	if err := arr.addAppInstallationAuth(retry); err != nil {
+		return nil, err
+	}
+	return arr.upstream.RoundTrip(retry)
 }
 
 // TimeNow is exposed so that it can be mocked by unit test, to ensure that
@@ -256,6 +279,10 @@ func (arr *appsRoundTripper) getTokenForInstallation(installation int64) (string
 
 	token, err := arr.githubClient.getAppInstallationToken(installation)
 	if err != nil {
+		if IsNotFound(err) {
+			// The app was reinstalled under a new id, so list the installations again.
+			arr.forgetInstallation(installation)
+		}
 		return "", time.Time{}, fmt.Errorf("failed to get installation token from GitHub: %w", err)
 	}
 
@@ -267,6 +294,31 @@ func (arr *appsRoundTripper) getTokenForInstallation(installation int64) (string
 	return token.Token, token.ExpiresAt, nil
 }
 
+// invalidateTokenFor drops the cached installation token for the given org.
+func (arr *appsRoundTripper) invalidateTokenFor(org string) {
+	arr.installationLock.RLock()
+	installation, found := arr.installations[org]
+	arr.installationLock.RUnlock()
+	if !found {
+		return
+	}
+
+	arr.tokenLock.Lock()
+	defer arr.tokenLock.Unlock()
+	delete(arr.tokens, installation.ID)
+}
+
+// forgetInstallation drops a cached installation that no longer exists.
+func (arr *appsRoundTripper) forgetInstallation(id int64) {
+	arr.installationLock.Lock()
+	defer arr.installationLock.Unlock()
+	for org, installation := range arr.installations {
+		if installation.ID == id {
+			delete(arr.installations, org)
+		}
+	}
+}
+
 func (arr *appsRoundTripper) getSlug() (string, error) {
 	arr.appSlugLock.Lock()
 	defer arr.appSlugLock.Unlock()
@@ -518,3 +518,111 @@ func serializeOrDie(in interface{}) io.ReadCloser {
 	}
 	return io.NopCloser(bytes.NewBuffer(rawData))
 }
+
+type sequenceRoundTripper struct {
+	lock     sync.Mutex
+	requests []*http.Request
+	// path -> responses, served in order
+	responses map[string][]*http.Response
+}
+
+func (srt *sequenceRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	srt.lock.Lock()
+	defer srt.lock.Unlock()
+	srt.requests = append(srt.requests, r)
+	if responses := srt.responses[r.URL.Path]; len(responses) > 0 {
+		srt.responses[r.URL.Path] = responses[1:]
+		return responses[0], nil
+	}
+	return &http.Response{StatusCode: 400, Body: io.NopCloser(&bytes.Buffer{})}, nil
+}
+
+func newTestAppsAuthClient(t *testing.T) (Client, *appsRoundTripper) {
+	// Can not be smaller, otherwise the JWT signature generation
+	// fails with "message too long for RSA public key size"
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	_, _, ghClient, err := NewAppsAuthClientWithFields(logrus.Fields{}, func(b []byte) []byte { return b }, "13", func() *rsa.PrivateKey { return rsaKey }, "", "https://api.github.com")
+	if err != nil {
+		t.Fatalf("failed to construct github client: %v", err)
+	}
+	appsRoundTripper := validateAppsRoundTripper(t, ghClient)
+	appsRoundTripper.appSlug = "ci-app"
+	appsRoundTripper.installations = map[string]AppInstallation{"org": {ID: 1}}
+	appsRoundTripper.tokens = map[int64]*AppInstallationToken{1: {Token: "the-token", ExpiresAt: time.Now().Add(time.Hour)}}
+	return ghClient, appsRoundTripper
+}
+
+func TestAppsRoundTripperRenewsRevokedToken(t *testing.T) {
+	ghClient, appsRoundTripper := newTestAppsAuthClient(t)
+	upstream := &sequenceRoundTripper{responses: map[string][]*http.Response{
+		"/orgs/org": {
+			{StatusCode: 401, Body: io.NopCloser(strings.NewReader(`{"message": "Bad credentials"}`))},
+			{StatusCode: 200, Body: serializeOrDie(Organization{Login: "org"})},
+		},
+		"/app/installations/1/access_tokens": {
+			{StatusCode: 201, Body: serializeOrDie(AppInstallationToken{Token: "the-new-token", ExpiresAt: time.Now().Add(time.Hour)})},
+		},
+	}}
+	appsRoundTripper.upstream = upstream
+
+	if _, err := ghClient.GetOrg("org"); err != nil {
+		t.Fatalf("failed to get org: %v", err)
+	}
+	if n := len(upstream.requests); n != 3 {
+		t.Fatalf("expected three requests, got %d", n)
+	}
+	if val := upstream.requests[2].Header.Get("Authorization"); val != "Bearer the-new-token" {
+		t.Errorf("expected the retry to use the new token, got Authorization header %q", val)
+	}
+}
+
+func TestAppsRoundTripperForgetsRemovedInstallation(t *testing.T) {
+	ghClient, appsRoundTripper := newTestAppsAuthClient(t)
+	appsRoundTripper.tokens = nil
+	upstream := &sequenceRoundTripper{responses: map[string][]*http.Response{
+		"/app/installations/1/access_tokens": {
+			{StatusCode: 404, Body: io.NopCloser(strings.NewReader(`{"message": "Not Found"}`))},
+		},
+		"/app/installations": {
+			{StatusCode: 200, Body: serializeOrDie([]AppInstallation{{ID: 2, Account: User{Login: "org"}}})},
+		},
+		"/app/installations/2/access_tokens": {
+			{StatusCode: 201, Body: serializeOrDie(AppInstallationToken{Token: "the-token", ExpiresAt: time.Now().Add(time.Hour)})},
+		},
+		"/orgs/org": {
+			{StatusCode: 200, Body: serializeOrDie(Organization{Login: "org"})},
+		},
+	}}
+	appsRoundTripper.upstream = upstream
+	ghClient.SetMax404Retries(0)
+
+	if _, err := ghClient.GetOrg("org"); err == nil {
+		t.Fatal("expected an error for a removed installation")
+	}
+	if _, err := ghClient.GetOrg("org"); err != nil {
+		t.Fatalf("expected the new installation to be used, got %v", err)
+	}
+}
+
+func TestForOrg(t *testing.T) {
+	ghClient, appsRoundTripper := newTestAppsAuthClient(t)
+	upstream := &sequenceRoundTripper{responses: map[string][]*http.Response{
+		"/search/issues": {
+			{StatusCode: 200, Body: serializeOrDie(IssuesSearchResult{})},
+		},
+	}}
+	appsRoundTripper.upstream = upstream
+
+	if _, err := ghClient.FindIssues("is:open", "", false); err == nil {
+		t.Error("expected an error for a request without an org")
+	}
+	if _, err := ghClient.ForOrg("org").FindIssues("is:open", "", false); err != nil {
+		t.Fatalf("failed to search with an org-scoped client: %v", err)
+	}
+	if val := upstream.requests[len(upstream.requests)-1].Header.Get("Authorization"); val != "Bearer the-token" {
+		t.Errorf("expected the installation token of the org, got Authorization header %q", val)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/version"
+)
+
+// AuditRecord records a single write to the GitHub API, e.g. a comment, a
+// label, a merge or a status. The client records the requests it makes, and
+// hook records the actions plugins take through their client.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Component is the Prow component that made the write, and Caller the
+	// plugin or subcomponent of it, if any.
+	Component string `json:"component"`
+	Caller    string `json:"caller,omitempty"`
+	// EventGUID identifies the webhook that triggered the write, if any, and
+	// EventType and Actor are its type and the user that caused it.
+	EventGUID string `json:"event_guid,omitempty"`
+	EventType string `json:"event_type,omitempty"`
+	Actor     string `json:"actor,omitempty"`
+	// Method and Path are the ones of the REST request. Mutations are
+	// recorded as a POST to /graphql with the type of the mutation.
+	Method   string `json:"method,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Mutation string `json:"mutation,omitempty"`
+	// Action is the client method a plugin called instead, e.g. AddLabel,
+	// and Target its object, e.g. the label.
+	Action string `json:"action,omitempty"`
+	Target string `json:"target,omitempty"`
+	// Org, Repo and Number are the target of the write, as far as they are
+	// known from the request.
+	Org    string `json:"org,omitempty"`
+	Repo   string `json:"repo,omitempty"`
+	Number int    `json:"number,omitempty"`
+	// StatusCode is the status GitHub responded with, zero if it didn't.
+	StatusCode int `json:"status_code,omitempty"`
+	// LatencySeconds is how long the write took, retries included.
+	LatencySeconds float64 `json:"latency_seconds"`
+	// DryRun is set if the write was skipped as the client runs in dry mode.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Error is set if the write failed.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink persists audit records.
+type AuditSink interface {
+	Record(AuditRecord) error
+}
+
+// logAuditSink writes every audit record as a structured log entry.
+type logAuditSink struct {
+	logger *logrus.Entry
+}
+
+// NewLogAuditSink returns an AuditSink that logs records at info level.
+func NewLogAuditSink(logger *logrus.Entry) AuditSink {
+	return &logAuditSink{logger: logger}
+}
+
+func (s *logAuditSink) Record(r AuditRecord) error {
+	fields := logrus.Fields{
+		"component":    r.Component,
+		"latency":      r.LatencySeconds,
+		"audit-record": true,
+	}
+	for key, value := range map[string]string{
+		"caller": r.Caller, EventGUID: r.EventGUID, "event-type": r.EventType, "actor": r.Actor,
+		"method": r.Method, "path": r.Path, "mutation": r.Mutation, "action": r.Action, "target": r.Target,
+		"org": r.Org, "repo": r.Repo, "error": r.Error,
+	} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	if r.Number != 0 {
+		fields["number"] = r.Number
+	}
+	if r.StatusCode != 0 {
+		fields["status-code"] = r.StatusCode
+	}
+	if r.DryRun {
+		fields["dry-run"] = true
+	}
+	s.logger.WithFields(fields).Info("GitHub write.")
+	return nil
+}
+
+// fileAuditSink appends every audit record as a line of JSON to a file.
+type fileAuditSink struct {
+	lock sync.Mutex
+	path string
+}
+
+// NewFileAuditSink returns an AuditSink that appends records to the file at
+// path, one JSON object per line.
+func NewFileAuditSink(path string) AuditSink {
+	return &fileAuditSink{path: path}
+}
+
+func (s *fileAuditSink) Record(r AuditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// The file is opened for every record so that it can be rotated.
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return f.Close()
+}
+
+// auditStart returns when a write starts if the client has an audit sink.
+func (c *client) auditStart() time.Time {
+	if c.auditSink == nil {
+		return time.Time{}
+	}
+	return c.time.Now()
+}
+
+// audit records a write of the client if it has an audit sink. Failing to
+// record it does not fail the write, which has already happened.
+func (c *client) audit(record AuditRecord, start time.Time, err error) {
+	if c.auditSink == nil {
+		return
+	}
+	record.Time = start
+	record.LatencySeconds = c.time.Now().Sub(start).Seconds()
+	record.Component = version.Name
+	record.Caller = c.identifier
+	record.EventGUID, _ = c.logger.Data[EventGUID].(string)
+	record.DryRun = c.dry
+	if record.Org == "" {
+		record.Org = c.org
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if err := c.auditSink.Record(record); err != nil {
+		c.logger.WithError(err).WithField("path", record.Path).Error("Failed to record GitHub write in the audit sink.")
+	}
+}
+
+// restAuditRecord returns the audit record of a REST request, with the target
+// of the write taken from its path, e.g. /repos/org/repo/pulls/1/merge.
+func restAuditRecord(method, path, org string) AuditRecord {
+	record := AuditRecord{Method: method, Path: path, Org: org}
+	if pathOrg, repo, number := repoTarget(path); pathOrg != "" {
+		record.Org, record.Repo, record.Number = pathOrg, repo, number
+	}
+	return record
+}
+
+// mutationAuditRecord returns the audit record of a GraphQL mutation.
+func mutationAuditRecord(m interface{}, org string) AuditRecord {
+	mutation := reflect.TypeOf(m)
+	for mutation != nil && mutation.Kind() == reflect.Ptr {
+		mutation = mutation.Elem()
+	}
+	record := AuditRecord{Method: "POST", Path: "/graphql", Org: org}
+	if mutation != nil {
+		record.Mutation = mutation.Name()
+	}
+	return record
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/version"
+)
+
+type fakeAuditSink struct {
+	records []AuditRecord
+}
+
+func (s *fakeAuditSink) Record(r AuditRecord) error {
+	s.records = append(s.records, r)
+	return nil
+}
+
+func TestAuditWrites(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/repo/issues/1/comments":
+			w.WriteHeader(http.StatusCreated)
+		case "/repos/org/repo/issues/2/labels":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer ts.Close()
+
+	testcases := []struct {
+		name     string
+		dry      bool
+		expected []AuditRecord
+	}{
+		{
+			name: "writes are recorded, reads are not",
+			expected: []AuditRecord{
+				{Component: version.Name, Caller: "approve", EventGUID: "guid", Method: http.MethodPost, Path: "/repos/org/repo/issues/1/comments", Org: "org", Repo: "repo", Number: 1, StatusCode: http.StatusCreated},
+				{Component: version.Name, Caller: "approve", EventGUID: "guid", Method: http.MethodPost, Path: "/repos/org/repo/issues/2/labels", Org: "org", Repo: "repo", Number: 2, StatusCode: http.StatusNotFound, Error: "failed"},
+			},
+		},
+		{
+			name: "writes skipped in dry mode are recorded",
+			dry:  true,
+			expected: []AuditRecord{
+				{Component: version.Name, Caller: "approve", EventGUID: "guid", Method: http.MethodPost, Path: "/repos/org/repo/issues/1/comments", Org: "org", Repo: "repo", Number: 1, StatusCode: http.StatusCreated, DryRun: true},
+				{Component: version.Name, Caller: "approve", EventGUID: "guid", Method: http.MethodPost, Path: "/repos/org/repo/issues/2/labels", Org: "org", Repo: "repo", Number: 2, StatusCode: http.StatusOK, DryRun: true},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &fakeAuditSink{}
+			c := getClient(ts.URL)
+			c.auditSink = sink
+			c.dry = tc.dry
+			c.time = &testTime{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			c.identifier = "approve"
+			c.logger = c.logger.WithField(EventGUID, "guid")
+
+			if _, err := c.GetRepos("org", false); err != nil {
+				t.Fatalf("failed to list repos: %v", err)
+			}
+			if err := c.CreateComment("org", "repo", 1, "/lgtm"); err != nil {
+				t.Fatalf("failed to comment: %v", err)
+			}
+			_ = c.AddLabel("org", "repo", 2, "lgtm")
+
+			for i := range sink.records {
+				if !sink.records[i].Time.Equal(c.time.Now()) {
+					t.Errorf("expected record %d at %s, got %s", i, c.time.Now(), sink.records[i].Time)
+				}
+				sink.records[i].Time = time.Time{}
+				// Only check that the error of the request is recorded.
+				if sink.records[i].Error != "" {
+					sink.records[i].Error = "failed"
+				}
+			}
+			if diff := cmp.Diff(tc.expected, sink.records); diff != "" {
+				t.Errorf("records mismatch. Want(-), got(+):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRestAuditRecord(t *testing.T) {
+	testcases := []struct {
+		path     string
+		org      string
+		expected AuditRecord
+	}{
+		{
+			path:     "/repos/org/repo/issues/12/comments",
+			expected: AuditRecord{Path: "/repos/org/repo/issues/12/comments", Org: "org", Repo: "repo", Number: 12},
+		},
+		{
+			path:     "/repos/org/repo/statuses/abcdef",
+			expected: AuditRecord{Path: "/repos/org/repo/statuses/abcdef", Org: "org", Repo: "repo"},
+		},
+		{
+			path:     "/orgs/org/teams?per_page=100",
+			org:      "org",
+			expected: AuditRecord{Path: "/orgs/org/teams?per_page=100", Org: "org"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			tc.expected.Method = http.MethodPost
+			if diff := cmp.Diff(tc.expected, restAuditRecord(http.MethodPost, tc.path, tc.org)); diff != "" {
+				t.Errorf("record mismatch. Want(-), got(+):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMutationAuditRecord(t *testing.T) {
+	var m struct{}
+	type mergeMutation struct{}
+	if diff := cmp.Diff("mergeMutation", mutationAuditRecord(&mergeMutation{}, "org").Mutation); diff != "" {
+		t.Errorf("mutation mismatch. Want(-), got(+):\n%s", diff)
+	}
+	if diff := cmp.Diff("", mutationAuditRecord(&m, "org").Mutation); diff != "" {
+		t.Errorf("mutation mismatch. Want(-), got(+):\n%s", diff)
+	}
+}
+
+func TestFileAuditSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink := NewFileAuditSink(path)
+	records := []AuditRecord{
+		{Component: "tide", Method: http.MethodPost, Path: "/repos/org/repo/issues/1/comments", Org: "org", Repo: "repo", Number: 1, StatusCode: http.StatusCreated},
+		{Component: "hook", Caller: "lgtm", Method: http.MethodPost, Path: "/repos/org/repo/issues/1/labels", Org: "org", Repo: "repo", Number: 1, StatusCode: http.StatusOK},
+	}
+	for _, record := range records {
+		if err := sink.Record(record); err != nil {
+			t.Fatalf("failed to record: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open the audit log: %v", err)
+	}
+	defer f.Close()
+	var got []AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("failed to unmarshal %q: %v", scanner.Text(), err)
+		}
+		got = append(got, record)
+	}
+	if diff := cmp.Diff(records, got); diff != "" {
+		t.Errorf("records mismatch. Want(-), got(+):\n%s", diff)
+	}
+}
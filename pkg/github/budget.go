@@ -0,0 +1,214 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
+)
+
+// RequestPriority tells the budget manager how urgent a request is. Requests
+// are PriorityNormal unless tagged otherwise.
+type RequestPriority string
+
+const (
+	// PriorityHigh requests are never deferred, e.g. merges or replies to users.
+	PriorityHigh RequestPriority = "high"
+	// PriorityNormal is the priority of untagged requests.
+	PriorityNormal RequestPriority = "normal"
+	// PriorityLow requests can wait for the rate limit to reset, e.g. sweeps
+	// over stale issues and PRs.
+	PriorityLow RequestPriority = "low"
+)
+
+type requestPriorityContextKey struct{}
+
+// WithRequestPriority tags the requests made with ctx with a priority, which
+// takes precedence over the priority of the client.
+func WithRequestPriority(ctx context.Context, priority RequestPriority) context.Context {
+	return context.WithValue(ctx, requestPriorityContextKey{}, priority)
+}
+
+func requestPriorityFromContext(ctx context.Context) (RequestPriority, bool) {
+	priority, ok := ctx.Value(requestPriorityContextKey{}).(RequestPriority)
+	return priority, ok
+}
+
+// ErrRequestDeferred is returned for requests that the budget manager would
+// have to defer for longer than BudgetOptions.MaxDeferral.
+var ErrRequestDeferred = errors.New("request deferred until the rate limit resets")
+
+// BudgetOptions configure the budget manager of a client. While the remaining
+// quota of a rate limit is below the threshold of a priority, requests of that
+// priority are deferred until the rate limit resets. A zero threshold never
+// defers requests.
+type BudgetOptions struct {
+	LowThreshold    int
+	NormalThreshold int
+	// MaxDeferral fails requests with ErrRequestDeferred rather than deferring
+	// them if the rate limit resets later than that. Zero defers requests until
+	// the rate limit resets or their context is done.
+	MaxDeferral time.Duration
+}
+
+// Validate validates the budget options.
+func (o BudgetOptions) Validate() error {
+	if o.LowThreshold < 0 || o.NormalThreshold < 0 || o.MaxDeferral < 0 {
+		return errors.New("budget thresholds and max deferral must not be negative")
+	}
+	if o.NormalThreshold > o.LowThreshold {
+		return fmt.Errorf("the normal priority budget threshold (%d) must not be larger than the low priority one (%d)", o.NormalThreshold, o.LowThreshold)
+	}
+	return nil
+}
+
+type rateLimitBudget struct {
+	remaining int
+	reset     time.Time
+}
+
+// budgetManager tracks the remaining quota of the rate limits a client uses,
+// as reported by the X-RateLimit-* headers of GitHub responses, and defers
+// requests of low urgency when it runs low. With apps auth every installation
+// has its own rate limits, so they are tracked per org.
+type budgetManager struct {
+	options BudgetOptions
+	perOrg  bool
+	now     func() time.Time
+	after   func(time.Duration) <-chan time.Time
+
+	lock    sync.Mutex
+	budgets map[string]rateLimitBudget
+}
+
+func newBudgetManager(options BudgetOptions, perOrg bool) *budgetManager {
+	return &budgetManager{
+		options: options,
+		perOrg:  perOrg,
+		now:     time.Now,
+		after:   time.After,
+		budgets: map[string]rateLimitBudget{},
+	}
+}
+
+func (b *budgetManager) key(org, resource string) string {
+	if !b.perOrg {
+		org = ""
+	}
+	return org + "/" + resource
+}
+
+// rateLimitResource guesses which rate limit a request counts against, before
+// the response tells.
+func rateLimitResource(req *http.Request) string {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return "graphql"
+	case strings.Contains(req.URL.Path, "/search/"):
+		return "search"
+	}
+	return "core"
+}
+
+func (b *budgetManager) threshold(priority RequestPriority) int {
+	switch priority {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return b.options.LowThreshold
+	}
+	return b.options.NormalThreshold
+}
+
+// wait defers a request while the remaining quota of its rate limit is below
+// the threshold of its priority.
+func (b *budgetManager) wait(req *http.Request) error {
+	if b == nil {
+		return nil
+	}
+	ctx := req.Context()
+	priority, ok := requestPriorityFromContext(ctx)
+	if !ok {
+		priority = PriorityNormal
+	}
+	threshold := b.threshold(priority)
+	if threshold == 0 {
+		return nil
+	}
+	key := b.key(extractOrgFromContext(ctx), rateLimitResource(req))
+	b.lock.Lock()
+	budget, tracked := b.budgets[key]
+	b.lock.Unlock()
+	if !tracked || budget.remaining >= threshold {
+		return nil
+	}
+	deferral := budget.reset.Sub(b.now())
+	if deferral <= 0 {
+		return nil
+	}
+	if b.options.MaxDeferral > 0 && deferral > b.options.MaxDeferral {
+		return fmt.Errorf("%w: %d requests remaining, below the %s priority threshold of %d, resetting in %v", ErrRequestDeferred, budget.remaining, priority, threshold, deferral.Round(time.Second))
+	}
+	logrus.WithFields(logrus.Fields{
+		"client":    "github",
+		"priority":  string(priority),
+		"remaining": budget.remaining,
+		"threshold": threshold,
+		"deferral":  deferral.String(),
+		"path":      req.URL.Path,
+	}).Debug("Deferring request until the rate limit resets")
+	ghmetrics.CollectDeferredRequestMetrics(string(priority))
+	select {
+	case <-b.after(deferral):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// observe records the rate limit reported by a response.
+func (b *budgetManager) observe(req *http.Request, resp *http.Response) {
+	if b == nil || resp == nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = rateLimitResource(req)
+	}
+	key := b.key(extractOrgFromContext(req.Context()), resource)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.budgets[key] = rateLimitBudget{remaining: remaining, reset: time.Unix(reset, 0)}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBudgetManagerWait(t *testing.T) {
+	now := time.Unix(1000, 0)
+	options := BudgetOptions{LowThreshold: 100, NormalThreshold: 10}
+	testCases := []struct {
+		name        string
+		options     BudgetOptions
+		perOrg      bool
+		observedOrg string
+		remaining   int
+		reset       time.Time
+		resource    string
+		org         string
+		path        string
+		priority    RequestPriority
+		expected    time.Duration
+		expectedErr error
+	}{
+		{
+			name:      "untracked rate limit is not deferred",
+			options:   options,
+			remaining: 5,
+			reset:     now.Add(time.Minute),
+			resource:  "search",
+			path:      "/repos/org/repo",
+			priority:  PriorityLow,
+		},
+		{
+			name:      "low priority above its threshold is not deferred",
+			options:   options,
+			remaining: 100,
+			reset:     now.Add(time.Minute),
+			path:      "/repos/org/repo",
+			priority:  PriorityLow,
+		},
+		{
+			name:      "low priority below its threshold is deferred until reset",
+			options:   options,
+			remaining: 99,
+			reset:     now.Add(time.Minute),
+			path:      "/repos/org/repo",
+			priority:  PriorityLow,
+			expected:  time.Minute,
+		},
+		{
+			name:      "untagged request counts as normal priority",
+			options:   options,
+			remaining: 50,
+			reset:     now.Add(time.Minute),
+			path:      "/repos/org/repo",
+		},
+		{
+			name:      "normal priority below its threshold is deferred",
+			options:   options,
+			remaining: 5,
+			reset:     now.Add(time.Minute),
+			path:      "/repos/org/repo",
+			priority:  PriorityNormal,
+			expected:  time.Minute,
+		},
+		{
+			name:      "high priority is never deferred",
+			options:   options,
+			remaining: 1,
+			reset:     now.Add(time.Minute),
+			path:      "/repos/org/repo",
+			priority:  PriorityHigh,
+		},
+		{
+			name:      "rate limit that already reset is not deferred",
+			options:   options,
+			remaining: 1,
+			reset:     now.Add(-time.Second),
+			path:      "/repos/org/repo",
+			priority:  PriorityLow,
+		},
+		{
+			name:      "zero threshold never defers",
+			remaining: 1,
+			reset:     now.Add(time.Minute),
+			path:      "/repos/org/repo",
+			priority:  PriorityLow,
+		},
+		{
+			name:        "deferral longer than the max deferral fails",
+			options:     BudgetOptions{LowThreshold: 100, MaxDeferral: 10 * time.Second},
+			remaining:   1,
+			reset:       now.Add(time.Minute),
+			path:        "/repos/org/repo",
+			priority:    PriorityLow,
+			expectedErr: ErrRequestDeferred,
+		},
+		{
+			name:      "search requests are budgeted separately",
+			options:   options,
+			remaining: 1,
+			reset:     now.Add(time.Minute),
+			resource:  "search",
+			path:      "/search/issues",
+			priority:  PriorityLow,
+			expected:  time.Minute,
+		},
+		{
+			name:        "apps auth budgets every org separately",
+			options:     options,
+			perOrg:      true,
+			observedOrg: "other-org",
+			remaining:   1,
+			reset:       now.Add(time.Minute),
+			org:         "org",
+			path:        "/repos/org/repo",
+			priority:    PriorityLow,
+		},
+		{
+			name:        "token auth shares the budget between orgs",
+			options:     options,
+			observedOrg: "other-org",
+			remaining:   1,
+			reset:       now.Add(time.Minute),
+			org:         "org",
+			path:        "/repos/org/repo",
+			priority:    PriorityLow,
+			expected:    time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBudgetManager(tc.options, tc.perOrg)
+			b.now = func() time.Time { return now }
+			var deferred time.Duration
+			b.after = func(d time.Duration) <-chan time.Time {
+				deferred = d
+				c := make(chan time.Time, 1)
+				c <- now.Add(d)
+				return c
+			}
+
+			observedReq, _ := http.NewRequestWithContext(context.WithValue(context.Background(), githubOrgHeaderKey, tc.observedOrg), http.MethodGet, "https://api.github.com/repos/org/repo", nil)
+			resp := &http.Response{Header: http.Header{}}
+			resp.Header.Set("X-RateLimit-Remaining", strconv.Itoa(tc.remaining))
+			resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(tc.reset.Unix(), 10))
+			resp.Header.Set("X-RateLimit-Resource", tc.resource)
+			b.observe(observedReq, resp)
+
+			ctx := context.WithValue(context.Background(), githubOrgHeaderKey, tc.org)
+			if tc.priority != "" {
+				ctx = WithRequestPriority(ctx, tc.priority)
+			}
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com"+tc.path, nil)
+			if err := b.wait(req); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if deferred != tc.expected {
+				t.Errorf("expected to be deferred for %v, got %v", tc.expected, deferred)
+			}
+		})
+	}
+}
+
+func TestBudgetManagerWaitContextDone(t *testing.T) {
+	b := newBudgetManager(BudgetOptions{LowThreshold: 100}, false)
+	b.budgets[b.key("", "core")] = rateLimitBudget{remaining: 1, reset: time.Now().Add(time.Hour)}
+	ctx, cancel := context.WithCancel(WithRequestPriority(context.Background(), PriorityLow))
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/repos/org/repo", nil)
+	if err := b.wait(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the deferral to end with the context, got %v", err)
+	}
+}
+
+func TestBudgetOptionsValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		options     BudgetOptions
+		expectedErr bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:    "low priority deferred first",
+			options: BudgetOptions{LowThreshold: 1000, NormalThreshold: 100, MaxDeferral: time.Minute},
+		},
+		{
+			name:        "normal priority deferred first",
+			options:     BudgetOptions{LowThreshold: 100, NormalThreshold: 1000},
+			expectedErr: true,
+		},
+		{
+			name:        "negative max deferral",
+			options:     BudgetOptions{MaxDeferral: -time.Minute},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.options.Validate(); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestClientWithPriority(t *testing.T) {
+	c := &client{logger: logrus.WithField("client", "github"), gqlc: &graphQLGitHubAppsAuthClientWrapper{}, delegate: &delegate{}}
+	low := c.WithPriority(PriorityLow).(*client)
+	if priority, _ := requestPriorityFromContext(low.withPriority(context.Background())); priority != PriorityLow {
+		t.Errorf("expected requests of the client to be tagged %s, got %q", PriorityLow, priority)
+	}
+	if priority, _ := requestPriorityFromContext(low.ForPlugin("plugin").(*client).withPriority(context.Background())); priority != PriorityLow {
+		t.Errorf("expected the priority to be kept for plugins, got %q", priority)
+	}
+	ctx := WithRequestPriority(context.Background(), PriorityHigh)
+	if priority, _ := requestPriorityFromContext(low.withPriority(ctx)); priority != PriorityHigh {
+		t.Errorf("expected the priority of the context to take precedence, got %q", priority)
+	}
+	if _, ok := requestPriorityFromContext(c.withPriority(context.Background())); ok {
+		t.Error("expected requests of an untagged client to be untagged")
+	}
+}
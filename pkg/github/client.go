@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -42,6 +43,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"sigs.k8s.io/prow/pkg/ghcache"
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
 	"sigs.k8s.io/prow/pkg/throttle"
 	"sigs.k8s.io/prow/pkg/version"
 )
@@ -49,6 +51,7 @@ import (
 type timeClient interface {
 	Sleep(time.Duration)
 	Until(time.Time) time.Duration
+	Now() time.Time
 }
 
 type standardTime struct{}
@@ -59,6 +62,9 @@ func (s *standardTime) Sleep(d time.Duration) {
 func (s *standardTime) Until(t time.Time) time.Duration {
 	return time.Until(t)
 }
+func (s *standardTime) Now() time.Time {
+	return time.Now()
+}
 
 // OrganizationClient interface for organisation related API actions
 type OrganizationClient interface {
@@ -108,6 +114,7 @@ type IssueClient interface {
 	CreateIssueReaction(org, repo string, id int, reaction string) error
 	ListIssueComments(org, repo string, number int) ([]IssueComment, error)
 	ListIssueCommentsWithContext(ctx context.Context, org, repo string, number int) ([]IssueComment, error)
+	ForEachIssueComment(ctx context.Context, org, repo string, number int, fn func(IssueComment) error) error
 	GetIssueLabels(org, repo string, number int) ([]Label, error)
 	ListIssueEvents(org, repo string, num int) ([]ListedIssueEvent, error)
 	AssignIssue(org, repo string, number int, logins []string) error
@@ -118,6 +125,7 @@ type IssueClient interface {
 	FindIssues(query, sort string, asc bool) ([]Issue, error)
 	FindIssuesWithOrg(org, query, sort string, asc bool) ([]Issue, error)
 	ListOpenIssues(org, repo string) ([]Issue, error)
+	ForEachOpenIssue(ctx context.Context, org, repo string, fn func(Issue) error) error
 	GetIssue(org, repo string, number int) (*Issue, error)
 	EditIssue(org, repo string, number int, issue *Issue) (*Issue, error)
 }
@@ -125,6 +133,7 @@ type IssueClient interface {
 // PullRequestClient interface for pull request related API actions
 type PullRequestClient interface {
 	GetPullRequests(org, repo string) ([]PullRequest, error)
+	ForEachPullRequest(ctx context.Context, org, repo string, fn func(PullRequest) error) error
 	GetPullRequest(org, repo string, number int) (*PullRequest, error)
 	EditPullRequest(org, repo string, number int, pr *PullRequest) (*PullRequest, error)
 	GetPullRequestDiff(org, repo string, number int) ([]byte, error)
@@ -157,7 +166,11 @@ type CommitClient interface {
 	GetRef(org, repo, ref string) (string, error)
 	DeleteRef(org, repo, ref string) error
 	ListFileCommits(org, repo, path string) ([]RepositoryCommit, error)
-	CreateCheckRun(org, repo string, checkRun CheckRun) error
+	CreateCheckRun(org, repo string, checkRun CheckRun) (*CheckRun, error)
+	UpdateCheckRun(org, repo string, checkRunID int64, checkRun CheckRun) (*CheckRun, error)
+	GetCheckRun(org, repo string, checkRunID int64) (*CheckRun, error)
+	ListCheckSuites(org, repo, ref string) (*CheckSuiteList, error)
+	RerequestCheckSuite(org, repo string, checkSuiteID int64) error
 }
 
 // RepositoryClient interface for repository related API actions
@@ -168,6 +181,11 @@ type RepositoryClient interface {
 	GetBranchProtection(org, repo, branch string) (*BranchProtection, error)
 	RemoveBranchProtection(org, repo, branch string) error
 	UpdateBranchProtection(org, repo, branch string, config BranchProtectionRequest) error
+	ListRepoRulesets(org, repo string) ([]Ruleset, error)
+	GetRepoRuleset(org, repo string, id int) (*Ruleset, error)
+	CreateRepoRuleset(org, repo string, ruleset Ruleset) (*Ruleset, error)
+	UpdateRepoRuleset(org, repo string, id int, ruleset Ruleset) (*Ruleset, error)
+	DeleteRepoRuleset(org, repo string, id int) error
 	AddRepoLabel(org, repo, label, description, color string) error
 	UpdateRepoLabel(org, repo, label, newName, description, color string) error
 	DeleteRepoLabel(org, repo, label string) error
@@ -222,10 +240,22 @@ type UserClient interface {
 	// instead when checking for comment authorship, as the Username in comments might have
 	// a [bot] suffix when using github apps authentication.
 	BotUser() (*UserData, error)
-	// BotUserChecker can be used to check if a comment was authored by the bot user.
+	// BotUserForOrg is like BotUser, but returns the user on the GitHub instance
+	// of the org, which differs for orgs routed to another instance.
+	BotUserForOrg(org string) (*UserData, error)
+	// BotUserChecker can be used to check if a comment was authored by the bot user,
+	// on any of the GitHub instances of the client.
 	BotUserChecker() (func(candidate string) bool, error)
 	BotUserCheckerWithContext(ctx context.Context) (func(candidate string) bool, error)
 	Email() (string, error)
+	// TokenType tells what kind of token the client authenticates with.
+	TokenType() TokenType
+	// ProbeCapabilities checks that the token can do what the caller needs to
+	// in an org, explaining what it lacks in the terms of its type.
+	ProbeCapabilities(org string, capabilities ...TokenCapability) error
+	// Ping checks that GitHub can be reached with the credentials of the
+	// client, e.g. for readiness checks. It doesn't consume API tokens.
+	Ping(ctx context.Context) error
 }
 
 // ProjectClient interface for project related API actions
@@ -286,6 +316,8 @@ type Client interface {
 	WithFields(fields logrus.Fields) Client
 	ForPlugin(plugin string) Client
 	ForSubcomponent(subcomponent string) Client
+	WithPriority(priority RequestPriority) Client
+	ForOrg(org string) Client
 	Used() bool
 	TriggerGitHubWorkflow(org, repo string, id int) error
 	TriggerFailedGitHubWorkflow(org, repo string, id int) error
@@ -299,9 +331,14 @@ type client struct {
 	logger *logrus.Entry
 	// identifier is used to add more identification to the user-agent header
 	identifier string
-	gqlc       gqlClient
-	used       bool
-	mutUsed    sync.Mutex // protects used
+	// priority tags the requests of the client for the budget manager
+	priority RequestPriority
+	// org is used for requests that don't name an org, to authenticate them
+	// as the installation of the app in that org
+	org     string
+	gqlc    gqlClient
+	used    bool
+	mutUsed sync.Mutex // protects used
 	*delegate
 }
 
@@ -309,22 +346,33 @@ type client struct {
 type delegate struct {
 	time timeClient
 
-	maxRetries    int
-	max404Retries int
-	maxSleepTime  time.Duration
-	initialDelay  time.Duration
+	maxRetries           int
+	max404Retries        int
+	noServerErrorRetries bool
+	noTimeoutRetries     bool
+	retryPolicies        []EndpointRetryPolicy
+	maxSleepTime         time.Duration
+	initialDelay         time.Duration
 
 	client       httpClient
 	bases        []string
+	orgEndpoints orgEndpoints
 	dry          bool
 	fake         bool
 	usesAppsAuth bool
 	throttle     ghThrottler
+	budget       *budgetManager
+	secondary    *secondaryRateLimiter
+	breaker      *circuitBreaker
 	getToken     func() []byte
 	censor       func([]byte) []byte
+	auditSink    AuditSink
 
 	mut      sync.Mutex // protects botName and email
 	userData *UserData
+	// orgUserData is the user data on the instances of orgEndpoints, by
+	// their first API endpoint. Guarded by mut.
+	orgUserData map[string]*UserData
 }
 
 type UserData struct {
@@ -354,6 +402,8 @@ func (c *client) forKeyValue(key, value string) Client {
 	newClient := &client{
 		identifier: value,
 		logger:     c.logger.WithField(key, value),
+		priority:   c.priority,
+		org:        c.org,
 		delegate:   c.delegate,
 	}
 	newClient.gqlc = c.gqlc.forUserAgent(newClient.userAgent())
@@ -373,11 +423,50 @@ func (c *client) WithFields(fields logrus.Fields) Client {
 	return &client{
 		logger:     c.logger.WithFields(fields),
 		identifier: c.identifier,
+		priority:   c.priority,
+		org:        c.org,
 		gqlc:       c.gqlc,
 		delegate:   c.delegate,
 	}
 }
 
+// WithPriority clones the client, keeping the underlying delegate the same but
+// tagging its requests with a priority, which decides whether the budget
+// manager defers them when the rate limit runs low
+func (c *client) WithPriority(priority RequestPriority) Client {
+	return &client{
+		logger:     c.logger.WithField("priority", string(priority)),
+		identifier: c.identifier,
+		priority:   priority,
+		org:        c.org,
+		gqlc:       c.gqlc,
+		delegate:   c.delegate,
+	}
+}
+
+// ForOrg clones the client, keeping the underlying delegate the same but
+// scoping it to an org. With apps auth, requests that don't name an org, like
+// searches, use the installation token of that org.
+func (c *client) ForOrg(org string) Client {
+	return &client{
+		logger:     c.logger.WithField("org", org),
+		identifier: c.identifier,
+		priority:   c.priority,
+		org:        org,
+		gqlc:       c.gqlc,
+		delegate:   c.delegate,
+	}
+}
+
+// withPriority tags ctx with the priority of the client, unless it already
+// has one.
+func (c *client) withPriority(ctx context.Context) context.Context {
+	if _, ok := requestPriorityFromContext(ctx); ok || c.priority == "" {
+		return ctx
+	}
+	return WithRequestPriority(ctx, c.priority)
+}
+
 var (
 	teamRe = regexp.MustCompile(`^(.*)/(.*)$`)
 )
@@ -508,10 +597,27 @@ type ClientOptions struct {
 	// the following fields determine which server we talk to
 	GraphqlEndpoint string
 	Bases           []string
+	// OrgEndpoints route the requests for some orgs to other GitHub instances
+	OrgEndpoints map[string]OrgEndpoint
 
 	// the following fields determine client retry behavior
 	MaxRequestTime, InitialDelay, MaxSleepTime time.Duration
 	MaxRetries, Max404Retries                  int
+	NoServerErrorRetries, NoTimeoutRetries     bool
+	// RetryPolicies override the retry behavior for matching endpoints
+	RetryPolicies []EndpointRetryPolicy
+	// CircuitBreaker configures when requests fail fast during GitHub outages
+	CircuitBreaker CircuitBreakerOptions
+
+	// Budget configures when requests are deferred by priority
+	Budget BudgetOptions
+
+	// ConditionalCacheSize is how many responses to GET requests are kept to
+	// revalidate with their ETag. Zero disables the cache, e.g. behind ghproxy.
+	ConditionalCacheSize int
+
+	// AuditSink records every write to the GitHub API if set
+	AuditSink AuditSink
 
 	DryRun bool
 	// BaseRoundTripper is the last RoundTripper to be called. Used for testing, gets defaulted to http.DefaultTransport
@@ -597,7 +703,16 @@ func NewClientFromOptions(fields logrus.Fields, options ClientOptions) (TokenGen
 		Transport: options.BaseRoundTripper,
 		Timeout:   options.MaxRequestTime,
 	}
-	graphQLTransport := newAddHeaderTransport(options.BaseRoundTripper)
+	budget := newBudgetManager(options.Budget, options.AppID != "")
+	secondary := newSecondaryRateLimiter(options.AppID != "")
+	if len(options.OrgEndpoints) > 0 && options.AppID != "" {
+		return nil, nil, nil, errors.New("routing orgs to other endpoints is only supported with token auth")
+	}
+	orgEndpoints, err := newOrgEndpoints(options.OrgEndpoints)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	graphQLTransport := newAddHeaderTransport(options.BaseRoundTripper, budget, secondary, orgEndpoints)
 	c := &client{
 		logger: logrus.WithFields(fields).WithField("client", "github"),
 		gqlc: &graphQLGitHubAppsAuthClientWrapper{Client: githubql.NewEnterpriseClient(
@@ -610,22 +725,38 @@ func NewClientFromOptions(fields logrus.Fields, options ClientOptions) (TokenGen
 				},
 			})},
 		delegate: &delegate{
-			time:          &standardTime{},
-			client:        httpClient,
-			bases:         options.Bases,
-			throttle:      ghThrottler{Throttler: &throttle.Throttler{}},
-			getToken:      options.GetToken,
-			censor:        options.Censor,
-			dry:           options.DryRun,
-			usesAppsAuth:  options.AppID != "",
-			maxRetries:    options.MaxRetries,
-			max404Retries: options.Max404Retries,
-			initialDelay:  options.InitialDelay,
-			maxSleepTime:  options.MaxSleepTime,
+			time:                 &standardTime{},
+			client:               httpClient,
+			bases:                options.Bases,
+			orgEndpoints:         orgEndpoints,
+			throttle:             ghThrottler{Throttler: &throttle.Throttler{}},
+			budget:               budget,
+			secondary:            secondary,
+			getToken:             options.GetToken,
+			censor:               options.Censor,
+			auditSink:            options.AuditSink,
+			dry:                  options.DryRun,
+			usesAppsAuth:         options.AppID != "",
+			maxRetries:           options.MaxRetries,
+			max404Retries:        options.Max404Retries,
+			noServerErrorRetries: options.NoServerErrorRetries,
+			noTimeoutRetries:     options.NoTimeoutRetries,
+			retryPolicies:        options.RetryPolicies,
+			breaker:              newCircuitBreaker(options.CircuitBreaker),
+			initialDelay:         options.InitialDelay,
+			maxSleepTime:         options.MaxSleepTime,
 		},
 	}
 	c.gqlc = c.gqlc.forUserAgent(c.userAgent())
 
+	if options.ConditionalCacheSize > 0 {
+		cache, err := newConditionalRequestCache(c.client, options.ConditionalCacheSize)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		c.client = cache
+	}
+
 	// Wrap clients with the throttler
 	c.wrapThrottler()
 
@@ -650,8 +781,8 @@ func NewClientFromOptions(fields logrus.Fields, options ClientOptions) (TokenGen
 		}
 	} else {
 		// Use Personal Access token auth for git actions
-		tokenGenerator = func(_ string) (string, error) {
-			return string(options.GetToken()), nil
+		tokenGenerator = func(org string) (string, error) {
+			return string(c.tokenFor(org)), nil
 		}
 		userGenerator = func() (string, error) {
 			user, err := c.BotUser()
@@ -694,12 +825,15 @@ func (c *graphQLGitHubAppsAuthClientWrapper) forUserAgent(userAgent string) gqlC
 // addHeaderTransport implements http.RoundTripper
 var _ http.RoundTripper = &addHeaderTransport{}
 
-func newAddHeaderTransport(upstream http.RoundTripper) *addHeaderTransport {
-	return &addHeaderTransport{upstream}
+func newAddHeaderTransport(upstream http.RoundTripper, budget *budgetManager, secondary *secondaryRateLimiter, orgEndpoints orgEndpoints) *addHeaderTransport {
+	return &addHeaderTransport{upstream: upstream, budget: budget, secondary: secondary, orgEndpoints: orgEndpoints}
 }
 
 type addHeaderTransport struct {
-	upstream http.RoundTripper
+	upstream     http.RoundTripper
+	budget       *budgetManager
+	secondary    *secondaryRateLimiter
+	orgEndpoints orgEndpoints
 }
 
 func (s *addHeaderTransport) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -716,8 +850,38 @@ func (s *addHeaderTransport) RoundTrip(r *http.Request) (*http.Response, error)
 	if v := r.Context().Value(userAgentContextKey); v != nil {
 		r.Header.Add("User-Agent", v.(string))
 	}
+	// ghproxy budgets requests per component
+	r.Header.Set(ghcache.ClientIdentifierHeader, version.Name)
 
-	return s.upstream.RoundTrip(r)
+	org := extractOrgFromContext(r.Context())
+	if err := s.orgEndpoints.routeGraphQLRequest(r, org); err != nil {
+		return nil, err
+	}
+	if wait := s.secondary.blockedFor(org, time.Now()); wait > 0 {
+		ghmetrics.CollectSecondaryRateLimitWaitMetrics(wait)
+		select {
+		case <-time.After(wait):
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+	}
+	if err := s.budget.wait(r); err != nil {
+		return nil, err
+	}
+	resp, err := s.upstream.RoundTrip(r)
+	if err != nil {
+		return resp, err
+	}
+	s.budget.observe(r, resp)
+	if isSecondaryRateLimit(resp) {
+		// The GraphQL client fails the query, but later ones wait.
+		ghmetrics.CollectSecondaryRateLimitMetrics("graphql")
+		wait, _ := retryAfter(resp)
+		s.secondary.hit(org, time.Now(), wait)
+	} else {
+		s.secondary.reset(org)
+	}
+	return resp, err
 }
 
 // NewClient creates a new fully operational GitHub client.
@@ -896,9 +1060,30 @@ func (c *client) requestRaw(r *request) (int, []byte, error) {
 }
 
 func (c *client) requestRawWithContext(ctx context.Context, r *request) (int, []byte, error) {
-	if c.fake || (c.dry && r.method != http.MethodGet) {
+	if c.fake {
 		return r.exitCodes[0], nil, nil
 	}
+	if r.method != http.MethodGet && r.method != http.MethodHead {
+		start := c.auditStart()
+		code, b, err := c.writeRawWithContext(ctx, r)
+		record := restAuditRecord(r.method, r.path, r.org)
+		record.StatusCode = code
+		c.audit(record, start, err)
+		return code, b, err
+	}
+	return c.doRequestRawWithContext(ctx, r)
+}
+
+// writeRawWithContext makes a request that writes to the API, unless the
+// client runs in dry mode.
+func (c *client) writeRawWithContext(ctx context.Context, r *request) (int, []byte, error) {
+	if c.dry {
+		return r.exitCodes[0], nil, nil
+	}
+	return c.doRequestRawWithContext(ctx, r)
+}
+
+func (c *client) doRequestRawWithContext(ctx context.Context, r *request) (int, []byte, error) {
 	resp, err := c.requestRetryWithContext(ctx, r.method, r.path, r.accept, r.org, r.requestBody)
 	if err != nil {
 		return 0, nil, err
@@ -934,17 +1119,35 @@ func (c *client) requestRetry(method, path, accept, org string, body interface{}
 }
 
 func (c *client) requestRetryWithContext(ctx context.Context, method, path, accept, org string, body interface{}) (*http.Response, error) {
+	if org == "" {
+		org = c.org
+	}
+	policy := c.retryPolicyFor(method, path)
+	bases := c.basesFor(org)
 	var hostIndex int
 	var resp *http.Response
 	var err error
 	backoff := c.initialDelay
-	for retries := 0; retries < c.maxRetries; retries++ {
+	for retries := 0; retries < policy.MaxRetries; retries++ {
 		if retries > 0 && resp != nil {
 			resp.Body.Close()
 		}
-		resp, err = c.doRequest(ctx, method, c.bases[hostIndex]+path, accept, org, body)
+		if !c.allowedByCircuitBreaker(bases, &hostIndex) {
+			return nil, fmt.Errorf("%w, not sending %s %s", ErrCircuitOpen, method, path)
+		}
+		base := bases[hostIndex]
+		resp, err = c.doRequest(ctx, method, base+path, accept, org, body)
+		switch {
+		case err == nil:
+			c.accountRESTUsage(path, org, resp)
+			c.breaker.record(base, c.time.Now(), resp.StatusCode >= 500)
+		case isRetriableTimeout(ctx, err) || !isTerminalRequestError(err):
+			c.breaker.record(base, c.time.Now(), true)
+		default:
+			c.breaker.release(base)
+		}
 		if err == nil {
-			if resp.StatusCode == 404 && retries < c.max404Retries {
+			if resp.StatusCode == 404 && retries < policy.Max404Retries {
 				// Retry 404s a couple times. Sometimes GitHub is inconsistent in
 				// the sense that they send us an event such as "PR opened" but an
 				// immediate request to GET the PR returns 404. We don't want to
@@ -952,99 +1155,88 @@ func (c *client) requestRetryWithContext(ctx context.Context, method, path, acce
 				// be caused by a bad API call and we'll just burn through API
 				// tokens.
 				c.logger.WithField("backoff", backoff.String()).Debug("Retrying 404")
+				ghmetrics.CollectRequestRetryMetrics("404")
 				c.time.Sleep(backoff)
 				backoff *= 2
-			} else if resp.StatusCode == 403 {
-				if resp.Header.Get("X-RateLimit-Remaining") == "0" {
-					// If we are out of API tokens, sleep first. The X-RateLimit-Reset
-					// header tells us the time at which we can request again.
-					var t int
-					if t, err = strconv.Atoi(resp.Header.Get("X-RateLimit-Reset")); err == nil {
-						// Sleep an extra second plus how long GitHub wants us to
-						// sleep. If it's going to take too long, then break.
-						sleepTime := c.time.Until(time.Unix(int64(t), 0)) + time.Second
-						if sleepTime < c.maxSleepTime {
-							c.logger.WithField("backoff", sleepTime.String()).WithField("path", path).Debug("Retrying after token budget reset")
-							c.time.Sleep(sleepTime)
-						} else {
-							err = fmt.Errorf("sleep time for token reset exceeds max sleep time (%v > %v)", sleepTime, c.maxSleepTime)
-							resp.Body.Close()
-							break
-						}
-					} else {
-						err = fmt.Errorf("failed to parse rate limit reset unix time %q: %w", resp.Header.Get("X-RateLimit-Reset"), err)
-						resp.Body.Close()
-						break
-					}
-				} else if rawTime := resp.Header.Get("Retry-After"); rawTime != "" && rawTime != "0" {
-					// If we are getting abuse rate limited, we need to wait or
-					// else we risk continuing to make the situation worse
-					var t int
-					if t, err = strconv.Atoi(rawTime); err == nil {
-						// Sleep an extra second plus how long GitHub wants us to
-						// sleep. If it's going to take too long, then break.
-						sleepTime := time.Duration(t+1) * time.Second
-						if sleepTime < c.maxSleepTime {
-							c.logger.WithField("backoff", sleepTime.String()).WithField("path", path).Debug("Retrying after abuse ratelimit reset")
-							c.time.Sleep(sleepTime)
-						} else {
-							err = fmt.Errorf("sleep time for abuse rate limit exceeds max sleep time (%v > %v)", sleepTime, c.maxSleepTime)
-							resp.Body.Close()
-							break
-						}
+			} else if resp.StatusCode == 403 && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+				// If we are out of API tokens, sleep first. The X-RateLimit-Reset
+				// header tells us the time at which we can request again.
+				var t int
+				if t, err = strconv.Atoi(resp.Header.Get("X-RateLimit-Reset")); err == nil {
+					// Sleep an extra second plus how long GitHub wants us to
+					// sleep. If it's going to take too long, then break.
+					sleepTime := c.time.Until(time.Unix(int64(t), 0)) + time.Second
+					if sleepTime < c.maxSleepTime {
+						c.logger.WithField("backoff", sleepTime.String()).WithField("path", path).Debug("Retrying after token budget reset")
+						c.time.Sleep(sleepTime)
 					} else {
-						err = fmt.Errorf("failed to parse abuse rate limit wait time %q: %w", rawTime, err)
+						err = fmt.Errorf("sleep time for token reset exceeds max sleep time (%v > %v)", sleepTime, c.maxSleepTime)
 						resp.Body.Close()
 						break
 					}
 				} else {
-					acceptedScopes := resp.Header.Get("X-Accepted-OAuth-Scopes")
-					authorizedScopes := resp.Header.Get("X-OAuth-Scopes")
-					if authorizedScopes == "" {
-						authorizedScopes = "no"
-					}
-
-					want := sets.New[string]()
-					for _, acceptedScope := range strings.Split(acceptedScopes, ",") {
-						want.Insert(strings.TrimSpace(acceptedScope))
-					}
-					var got []string
-					for _, authorizedScope := range strings.Split(authorizedScopes, ",") {
-						got = append(got, strings.TrimSpace(authorizedScope))
-					}
-					if acceptedScopes != "" && !want.HasAny(got...) {
-						err = fmt.Errorf("the account is using %s oauth scopes, please make sure you are using at least one of the following oauth scopes: %s", authorizedScopes, acceptedScopes)
-					} else {
-						body, _ := io.ReadAll(resp.Body)
-						err = fmt.Errorf("the GitHub API request returns a 403 error: %s", string(body))
-					}
+					err = fmt.Errorf("failed to parse rate limit reset unix time %q: %w", resp.Header.Get("X-RateLimit-Reset"), err)
+					resp.Body.Close()
+					break
+				}
+			} else if isSecondaryRateLimit(resp) {
+				// Secondary rate limits block all requests using the token until
+				// they pass, else we risk continuing to make the situation worse.
+				var wait time.Duration
+				if wait, err = retryAfter(resp); err != nil {
+					err = fmt.Errorf("failed to parse secondary rate limit wait time %q: %w", resp.Header.Get("Retry-After"), err)
 					resp.Body.Close()
 					break
 				}
-			} else if resp.StatusCode < 500 {
+				ghmetrics.CollectSecondaryRateLimitMetrics("rest")
+				sleepTime := c.secondary.hit(org, c.time.Now(), wait)
+				if sleepTime >= c.maxSleepTime {
+					err = fmt.Errorf("sleep time for secondary rate limit exceeds max sleep time (%v > %v)", sleepTime, c.maxSleepTime)
+					resp.Body.Close()
+					break
+				}
+				c.logger.WithField("backoff", sleepTime.String()).WithField("path", path).Debug("Retrying after secondary rate limit")
+				if c.secondary == nil {
+					c.time.Sleep(sleepTime)
+				}
+			} else if resp.StatusCode == 403 {
+				err = c.newPermissionError(resp)
+				resp.Body.Close()
+				break
+			} else if resp.StatusCode < 500 || policy.NoServerErrorRetries {
 				// Normal, happy case.
 				break
 			} else {
 				// Retry 500 after a break.
 				c.logger.WithField("backoff", backoff.String()).Debug("Retrying 5XX")
+				ghmetrics.CollectRequestRetryMetrics("5xx")
 				c.time.Sleep(backoff)
 				backoff *= 2
 			}
+		} else if isRetriableTimeout(ctx, err) {
+			if policy.NoTimeoutRetries {
+				return resp, err
+			}
+			c.logger.WithError(err).WithField("backoff", backoff.String()).Debug("Retrying request that timed out")
+			ghmetrics.CollectRequestRetryMetrics("timeout")
+			c.time.Sleep(backoff)
+			backoff *= 2
 		} else if errors.Is(err, &appsAuthError{}) {
 			c.logger.WithError(err).Error("Stopping retry due to appsAuthError")
 			return resp, err
-		} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		} else if isTerminalRequestError(err) {
 			return resp, err
 		} else {
 			// Connection problem. Try a different host.
 			oldHostIndex := hostIndex
-			hostIndex = (hostIndex + 1) % len(c.bases)
+			hostIndex = (hostIndex + 1) % len(bases)
 			c.logger.WithFields(logrus.Fields{
 				"err":          err,
 				"backoff":      backoff.String(),
-				"old-endpoint": c.bases[oldHostIndex],
-				"new-endpoint": c.bases[hostIndex],
+				"old-endpoint": bases[oldHostIndex],
+				"new-endpoint": bases[hostIndex],
 			}).Debug("Retrying request due to connection problem")
+			ghmetrics.CollectRequestRetryMetrics("connection")
 			c.time.Sleep(backoff)
 			backoff *= 2
 		}
@@ -1052,6 +1244,34 @@ func (c *client) requestRetryWithContext(ctx context.Context, method, path, acce
 	return resp, err
 }
 
+// isRetriableTimeout tells whether a request timed out on its own, rather than
+// because the context of the caller is done.
+func isRetriableTimeout(ctx context.Context, err error) bool {
+	var netErr net.Error
+	return ctx.Err() == nil && errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isTerminalRequestError tells whether a request failed before it reached
+// GitHub and shouldn't be retried.
+func isTerminalRequestError(err error) bool {
+	return errors.Is(err, &appsAuthError{}) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrRequestDeferred) || errors.Is(err, errSecondaryRateLimited)
+}
+
+// allowedByCircuitBreaker moves hostIndex to the first endpoint starting from
+// it that the circuit breaker lets requests through to, and tells whether
+// there is one.
+func (c *client) allowedByCircuitBreaker(bases []string, hostIndex *int) bool {
+	for i := 0; i < len(bases); i++ {
+		index := (*hostIndex + i) % len(bases)
+		if c.breaker.allow(bases[index], c.time.Now()) {
+			*hostIndex = index
+			return true
+		}
+	}
+	return false
+}
+
 func (c *client) doRequest(ctx context.Context, method, path, accept, org string, body interface{}) (*http.Response, error) {
 	var buf io.Reader
 	if body != nil {
@@ -1062,7 +1282,7 @@ func (c *client) doRequest(ctx context.Context, method, path, accept, org string
 		b = c.censor(b)
 		buf = bytes.NewBuffer(b)
 	}
-	req, err := http.NewRequestWithContext(ctx, method, path, buf)
+	req, err := http.NewRequestWithContext(c.withPriority(ctx), method, path, buf)
 	if err != nil {
 		return nil, fmt.Errorf("failed creating new request: %w", err)
 	}
@@ -1072,7 +1292,7 @@ func (c *client) doRequest(ctx context.Context, method, path, accept, org string
 	// See https://pkg.go.dev/net/http#Header.Set for more info.
 	req.Header["X-GitHub-Api-Version"] = []string{githubApiVersion}
 	c.logger.Debugf("Using GitHub REST API Version: %s", githubApiVersion)
-	if header := c.authHeader(); len(header) > 0 {
+	if header := c.authHeader(org); len(header) > 0 {
 		req.Header.Set("Authorization", header)
 	}
 	if accept == acceptNone {
@@ -1083,6 +1303,7 @@ func (c *client) doRequest(ctx context.Context, method, path, accept, org string
 	if userAgent := c.userAgent(); userAgent != "" {
 		req.Header.Add("User-Agent", userAgent)
 	}
+	req.Header.Set(ghcache.ClientIdentifierHeader, version.Name)
 	if org != "" {
 		req = req.WithContext(context.WithValue(req.Context(), githubOrgHeaderKey, org))
 	}
@@ -1092,8 +1313,40 @@ func (c *client) doRequest(ctx context.Context, method, path, accept, org string
 	// for POST.
 	req.Close = true
 
+	if err := c.waitForSecondaryRateLimit(org); err != nil {
+		return nil, err
+	}
+	if err := c.budget.wait(req); err != nil {
+		return nil, err
+	}
 	c.logger.WithField("curl", toCurl(req)).Trace("Executing http request")
-	return c.client.Do(req)
+	resp, err := c.client.Do(req)
+	if err == nil {
+		c.budget.observe(req, resp)
+		if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+			c.secondary.reset(org)
+		}
+	}
+	return resp, err
+}
+
+// waitForSecondaryRateLimit waits until a secondary rate limit hit by any
+// request using the same token passes.
+func (c *client) waitForSecondaryRateLimit(org string) error {
+	if c.secondary == nil {
+		return nil
+	}
+	wait := c.secondary.blockedFor(org, c.time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	if wait >= c.maxSleepTime {
+		return fmt.Errorf("%w for %v, which exceeds max sleep time %v", errSecondaryRateLimited, wait, c.maxSleepTime)
+	}
+	c.logger.WithField("backoff", wait.String()).Debug("Waiting for secondary rate limit to pass")
+	ghmetrics.CollectSecondaryRateLimitWaitMetrics(wait)
+	c.time.Sleep(wait)
+	return nil
 }
 
 // toCurl is a slightly adjusted copy of https://github.com/kubernetes/kubernetes/blob/74053d555d71a14e3853b97e204d7d6415521375/staging/src/k8s.io/client-go/transport/round_trippers.go#L339
@@ -1136,11 +1389,8 @@ func maskAuthorizationHeader(key string, value string) string {
 	return value
 }
 
-func (c *client) authHeader() string {
-	if c.getToken == nil {
-		return ""
-	}
-	token := c.getToken()
+func (c *client) authHeader(org string) string {
+	token := c.tokenFor(org)
 	if len(token) == 0 {
 		return ""
 	}
@@ -1195,7 +1445,7 @@ func (c *client) getUserData(ctx context.Context) error {
 	// https://developer.github.com/v3/users/#get-a-single-user
 
 	// record information for the user
-	authHeaderHash := fmt.Sprintf("%x", sha256.Sum256([]byte(c.authHeader()))) // use %x to make this a utf-8 string for use as a label
+	authHeaderHash := fmt.Sprintf("%x", sha256.Sum256([]byte(c.authHeader("")))) // use %x to make this a utf-8 string for use as a label
 	userInfo.With(prometheus.Labels{"token_hash": authHeaderHash, "login": c.userData.Login, "email": c.userData.Email}).Set(1)
 	return nil
 }
@@ -1214,6 +1464,28 @@ func (c *client) BotUser() (*UserData, error) {
 	return c.userData, nil
 }
 
+// Ping checks that GitHub can be reached with the credentials of the client.
+// Clients authenticating as a GitHub App get the app, and others their rate
+// limit, neither of which consumes API tokens.
+//
+// See https://docs.github.com/en/rest/rate-limit/rate-limit#get-rate-limit-status-for-the-authenticated-user
+func (c *client) Ping(ctx context.Context) error {
+	c.log("Ping")
+	if c.fake {
+		return nil
+	}
+	if c.delegate.usesAppsAuth {
+		_, err := c.GetAppWithContext(ctx)
+		return err
+	}
+	_, err := c.requestWithContext(ctx, &request{
+		method:    http.MethodGet,
+		path:      "/rate_limit",
+		exitCodes: []int{200},
+	}, nil)
+	return err
+}
+
 func (c *client) BotUserChecker() (func(candidate string) bool, error) {
 	return c.BotUserCheckerWithContext(context.Background())
 }
@@ -1227,12 +1499,20 @@ func (c *client) BotUserCheckerWithContext(ctx context.Context) (func(candidate
 		}
 	}
 
-	botUser := c.userData.Login
+	botUsers := sets.New[string](c.userData.Login)
+	// The bot has another login on the instances of routed orgs.
+	for org, endpoint := range c.orgEndpoints {
+		userData, err := c.orgUserDataFor(ctx, org, endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("fetching userdata of org %s from GitHub: %w", org, err)
+		}
+		botUsers.Insert(userData.Login)
+	}
 	return func(candidate string) bool {
 		if c.usesAppsAuth {
 			candidate = strings.TrimSuffix(candidate, "[bot]")
 		}
-		return candidate == botUser
+		return botUsers.Has(candidate)
 	}, nil
 }
 
@@ -1878,6 +2158,20 @@ func (c *client) readPaginatedResultsWithValues(path string, values url.Values,
 }
 
 func (c *client) readPaginatedResultsWithValuesWithContext(ctx context.Context, path string, values url.Values, accept, org string, newObj func() interface{}, accumulate func(interface{})) error {
+	return c.forEachPage(ctx, path, values, accept, org, newObj, func(obj interface{}) error {
+		accumulate(obj)
+		return nil
+	})
+}
+
+// ErrStopIteration can be returned by the callback of a ForEach* method to
+// stop paging through results early. The method then returns nil.
+var ErrStopIteration = errors.New("stop iteration")
+
+// forEachPage requests the pages of a paginated listing one at a time and
+// hands each one to onPage, so that callers don't have to hold the whole
+// listing in memory. It stops at the first error returned by onPage.
+func (c *client) forEachPage(ctx context.Context, path string, values url.Values, accept, org string, newObj func() interface{}, onPage func(interface{}) error) error {
 	pagedPath := path
 	if len(values) > 0 {
 		pagedPath += "?" + values.Encode()
@@ -1887,12 +2181,13 @@ func (c *client) readPaginatedResultsWithValuesWithContext(ctx context.Context,
 		if err != nil {
 			return err
 		}
-		defer resp.Body.Close()
 		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			resp.Body.Close()
 			return fmt.Errorf("return code not 2XX: %s", resp.Status)
 		}
 
 		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
 		if err != nil {
 			return err
 		}
@@ -1902,7 +2197,9 @@ func (c *client) readPaginatedResultsWithValuesWithContext(ctx context.Context,
 			return err
 		}
 
-		accumulate(obj)
+		if err := onPage(obj); err != nil {
+			return err
+		}
 
 		link := parseLinks(resp.Header.Get("Link"))["next"]
 		if link == "" {
@@ -1931,6 +2228,31 @@ func (c *client) readPaginatedResultsWithValuesWithContext(ctx context.Context,
 	return nil
 }
 
+// forEachItem pages through a listing of T and calls fn for every item, until
+// fn returns an error. ErrStopIteration stops paging without an error.
+func forEachItem[T any](ctx context.Context, c *client, path, accept, org string, fn func(T) error) error {
+	values := url.Values{
+		"per_page": []string{"100"},
+	}
+	err := c.forEachPage(ctx, path, values, accept, org,
+		func() interface{} {
+			return &[]T{}
+		},
+		func(obj interface{}) error {
+			for _, item := range *(obj.(*[]T)) {
+				if err := fn(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+	if errors.Is(err, ErrStopIteration) {
+		return nil
+	}
+	return err
+}
+
 // ListIssueComments returns all comments on an issue.
 //
 // Each page of results consumes one API token.
@@ -1965,6 +2287,22 @@ func (c *client) ListIssueCommentsWithContext(ctx context.Context, org, repo str
 	return comments, nil
 }
 
+// ForEachIssueComment calls fn for every comment on an issue, one page at a
+// time, rather than returning them all at once like ListIssueComments. fn can
+// return ErrStopIteration to stop early.
+//
+// Each page of results consumes one API token.
+//
+// See https://developer.github.com/v3/issues/comments/#list-comments-on-an-issue
+func (c *client) ForEachIssueComment(ctx context.Context, org, repo string, number int, fn func(IssueComment) error) error {
+	c.log("ForEachIssueComment", org, repo, number)
+	if c.fake {
+		return nil
+	}
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments", org, repo, number)
+	return forEachItem(ctx, c, path, acceptNone, org, fn)
+}
+
 // ListOpenIssues returns all open issues, including pull requests
 //
 // Each page of results consumes one API token.
@@ -1994,6 +2332,21 @@ func (c *client) ListOpenIssues(org, repo string) ([]Issue, error) {
 	return issues, nil
 }
 
+// ForEachOpenIssue calls fn for every open issue, including pull requests, one
+// page at a time. fn can return ErrStopIteration to stop early.
+//
+// Each page of results consumes one API token.
+//
+// See https://developer.github.com/v3/issues/#list-issues-for-a-repository
+func (c *client) ForEachOpenIssue(ctx context.Context, org, repo string, fn func(Issue) error) error {
+	c.log("ForEachOpenIssue", org, repo)
+	if c.fake {
+		return nil
+	}
+	path := fmt.Sprintf("/repos/%s/%s/issues", org, repo)
+	return forEachItem(ctx, c, path, acceptNone, org, fn)
+}
+
 // GetPullRequests get all open pull requests for a repo.
 //
 // See https://developer.github.com/v3/pulls/#list-pull-requests
@@ -2024,6 +2377,24 @@ func (c *client) GetPullRequests(org, repo string) ([]PullRequest, error) {
 	return prs, err
 }
 
+// ForEachPullRequest calls fn for every open pull request of a repo, one page
+// at a time. fn can return ErrStopIteration to stop early.
+//
+// Each page of results consumes one API token.
+//
+// See https://developer.github.com/v3/pulls/#list-pull-requests
+func (c *client) ForEachPullRequest(ctx context.Context, org, repo string, fn func(PullRequest) error) error {
+	c.log("ForEachPullRequest", org, repo)
+	if c.fake {
+		return nil
+	}
+	path := fmt.Sprintf("/repos/%s/%s/pulls", org, repo)
+	// allow the description and draft fields
+	// https://developer.github.com/changes/2018-02-22-label-description-search-preview/
+	// https://developer.github.com/changes/2019-02-14-draft-pull-requests/
+	return forEachItem(ctx, c, path, "application/vnd.github.symmetra-preview+json, application/vnd.github.shadow-cat-preview", org, fn)
+}
+
 // GetPullRequest gets a pull request.
 //
 // See https://developer.github.com/v3/pulls/#get-a-single-pull-request
@@ -2692,6 +3063,111 @@ func (c *client) UpdateBranchProtection(org, repo, branch string, config BranchP
 	return err
 }
 
+// ListRepoRulesets lists the rulesets defined on org/repo, without those
+// inherited from the org. The rules of the rulesets are not included.
+//
+// See https://docs.github.com/en/rest/repos/rules#get-all-repository-rulesets
+func (c *client) ListRepoRulesets(org, repo string) ([]Ruleset, error) {
+	durationLogger := c.log("ListRepoRulesets", org, repo)
+	defer durationLogger()
+
+	var rulesets []Ruleset
+	err := c.readPaginatedResultsWithValues(
+		fmt.Sprintf("/repos/%s/%s/rulesets", org, repo),
+		url.Values{"includes_parents": []string{"false"}, "per_page": []string{"100"}},
+		acceptNone,
+		org,
+		func() interface{} {
+			return &[]Ruleset{}
+		},
+		func(obj interface{}) {
+			rulesets = append(rulesets, *(obj.(*[]Ruleset))...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rulesets, nil
+}
+
+// GetRepoRuleset returns the ruleset of org/repo with the given ID.
+//
+// See https://docs.github.com/en/rest/repos/rules#get-a-repository-ruleset
+func (c *client) GetRepoRuleset(org, repo string, id int) (*Ruleset, error) {
+	durationLogger := c.log("GetRepoRuleset", org, repo, id)
+	defer durationLogger()
+
+	var ruleset Ruleset
+	_, err := c.request(&request{
+		method:    http.MethodGet,
+		path:      fmt.Sprintf("/repos/%s/%s/rulesets/%d", org, repo, id),
+		org:       org,
+		exitCodes: []int{200},
+	}, &ruleset)
+	if err != nil {
+		return nil, err
+	}
+	return &ruleset, nil
+}
+
+// CreateRepoRuleset creates a ruleset in org/repo.
+//
+// See https://docs.github.com/en/rest/repos/rules#create-a-repository-ruleset
+func (c *client) CreateRepoRuleset(org, repo string, ruleset Ruleset) (*Ruleset, error) {
+	durationLogger := c.log("CreateRepoRuleset", org, repo, ruleset.Name)
+	defer durationLogger()
+
+	var created Ruleset
+	_, err := c.request(&request{
+		method:      http.MethodPost,
+		path:        fmt.Sprintf("/repos/%s/%s/rulesets", org, repo),
+		org:         org,
+		requestBody: ruleset,
+		exitCodes:   []int{201},
+	}, &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateRepoRuleset replaces the ruleset of org/repo with the given ID.
+//
+// See https://docs.github.com/en/rest/repos/rules#update-a-repository-ruleset
+func (c *client) UpdateRepoRuleset(org, repo string, id int, ruleset Ruleset) (*Ruleset, error) {
+	durationLogger := c.log("UpdateRepoRuleset", org, repo, id)
+	defer durationLogger()
+
+	var updated Ruleset
+	_, err := c.request(&request{
+		method:      http.MethodPut,
+		path:        fmt.Sprintf("/repos/%s/%s/rulesets/%d", org, repo, id),
+		org:         org,
+		requestBody: ruleset,
+		exitCodes:   []int{200},
+	}, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteRepoRuleset deletes the ruleset of org/repo with the given ID.
+//
+// See https://docs.github.com/en/rest/repos/rules#delete-a-repository-ruleset
+func (c *client) DeleteRepoRuleset(org, repo string, id int) error {
+	durationLogger := c.log("DeleteRepoRuleset", org, repo, id)
+	defer durationLogger()
+
+	_, err := c.request(&request{
+		method:    http.MethodDelete,
+		path:      fmt.Sprintf("/repos/%s/%s/rulesets/%d", org, repo, id),
+		org:       org,
+		exitCodes: []int{204},
+	}, nil)
+	return err
+}
+
 // AddRepoLabel adds a defined label given org/repo
 //
 // See https://developer.github.com/v3/issues/labels/#create-a-label
@@ -3521,12 +3997,28 @@ func (c *client) GetFile(org, repo, filepath, commit string) ([]byte, error) {
 func (c *client) QueryWithGitHubAppsSupport(ctx context.Context, q interface{}, vars map[string]interface{}, org string) error {
 	// Don't log query here because Query is typically called multiple times to get all pages.
 	// Instead log once per search and include total search cost.
-	return c.gqlc.QueryWithGitHubAppsSupport(ctx, q, vars, org)
+	if org == "" {
+		org = c.org
+	}
+	err := c.gqlc.QueryWithGitHubAppsSupport(c.withPriority(ctx), q, vars, org)
+	if err == nil {
+		c.accountGraphQLUsage(q, org)
+	}
+	return err
 }
 
 // MutateWithGitHubAppsSupport runs a GraphQL mutation using shurcooL/githubql's client.
 func (c *client) MutateWithGitHubAppsSupport(ctx context.Context, m interface{}, input githubql.Input, vars map[string]interface{}, org string) error {
-	return c.gqlc.MutateWithGitHubAppsSupport(ctx, m, input, vars, org)
+	if org == "" {
+		org = c.org
+	}
+	start := c.auditStart()
+	err := c.gqlc.MutateWithGitHubAppsSupport(c.withPriority(ctx), m, input, vars, org)
+	if err == nil {
+		c.accountGraphQLUsage(m, org)
+	}
+	c.audit(mutationAuditRecord(m, org), start, err)
+	return err
 }
 
 // CreateTeam adds a team with name to the org, returning a struct with the new ID.
@@ -4888,22 +5380,156 @@ func (c *client) ListCheckRuns(org, repo, ref string) (*CheckRunList, error) {
 }
 
 // CreateCheckRun Creates a new check run for a specific commit in a repository.
+// GitHub takes at most MaxCheckRunAnnotations annotations per request, so any
+// further ones are added by updating the check run.
 //
 // See https://docs.github.com/en/rest/checks/runs#create-a-check-run
-func (c *client) CreateCheckRun(org, repo string, checkRun CheckRun) error {
+func (c *client) CreateCheckRun(org, repo string, checkRun CheckRun) (*CheckRun, error) {
 	durationLogger := c.log("CreateCheckRun", org, repo, checkRun)
 	defer durationLogger()
+	if c.dry {
+		return &checkRun, nil
+	}
+
+	annotations := checkRun.Output.Annotations
+	checkRun.Output.Annotations, annotations = splitCheckRunAnnotations(annotations)
+	var created CheckRun
 	_, err := c.request(&request{
 		method:      http.MethodPost,
 		path:        fmt.Sprintf("/repos/%s/%s/check-runs", org, repo),
 		org:         org,
 		requestBody: &checkRun,
 		exitCodes:   []int{201},
-	}, nil)
+	}, &created)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return c.addCheckRunAnnotations(org, repo, &created, checkRun.Output, annotations)
+}
+
+// UpdateCheckRun updates a check run. Annotations are added to the existing
+// ones, in as many requests as needed.
+//
+// See https://docs.github.com/en/rest/checks/runs#update-a-check-run
+func (c *client) UpdateCheckRun(org, repo string, checkRunID int64, checkRun CheckRun) (*CheckRun, error) {
+	durationLogger := c.log("UpdateCheckRun", org, repo, checkRunID, checkRun)
+	defer durationLogger()
+	if c.dry {
+		return &checkRun, nil
+	}
+
+	annotations := checkRun.Output.Annotations
+	checkRun.Output.Annotations, annotations = splitCheckRunAnnotations(annotations)
+	updated, err := c.updateCheckRun(org, repo, checkRunID, checkRun)
+	if err != nil {
+		return nil, err
+	}
+	return c.addCheckRunAnnotations(org, repo, updated, checkRun.Output, annotations)
+}
+
+func (c *client) updateCheckRun(org, repo string, checkRunID int64, checkRun CheckRun) (*CheckRun, error) {
+	var updated CheckRun
+	_, err := c.request(&request{
+		method:      http.MethodPatch,
+		path:        fmt.Sprintf("/repos/%s/%s/check-runs/%d", org, repo, checkRunID),
+		org:         org,
+		requestBody: &checkRun,
+		exitCodes:   []int{200},
+	}, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// splitCheckRunAnnotations splits the annotations that fit into one request
+// from the rest.
+func splitCheckRunAnnotations(annotations []CheckRunAnnotation) ([]CheckRunAnnotation, []CheckRunAnnotation) {
+	if len(annotations) <= MaxCheckRunAnnotations {
+		return annotations, nil
+	}
+	return annotations[:MaxCheckRunAnnotations], annotations[MaxCheckRunAnnotations:]
+}
+
+// addCheckRunAnnotations adds annotations to a check run in batches. GitHub
+// requires the title and summary of the output in every update.
+func (c *client) addCheckRunAnnotations(org, repo string, checkRun *CheckRun, output CheckRunOutput, annotations []CheckRunAnnotation) (*CheckRun, error) {
+	for len(annotations) > 0 {
+		var batch []CheckRunAnnotation
+		batch, annotations = splitCheckRunAnnotations(annotations)
+		updated, err := c.updateCheckRun(org, repo, checkRun.ID, CheckRun{Output: CheckRunOutput{
+			Title:       output.Title,
+			Summary:     output.Summary,
+			Annotations: batch,
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add annotations to check run %d: %w", checkRun.ID, err)
+		}
+		checkRun = updated
+	}
+	return checkRun, nil
+}
+
+// GetCheckRun gets a check run by its id.
+//
+// See https://docs.github.com/en/rest/checks/runs#get-a-check-run
+func (c *client) GetCheckRun(org, repo string, checkRunID int64) (*CheckRun, error) {
+	durationLogger := c.log("GetCheckRun", org, repo, checkRunID)
+	defer durationLogger()
+
+	var checkRun CheckRun
+	_, err := c.request(&request{
+		method:    http.MethodGet,
+		path:      fmt.Sprintf("/repos/%s/%s/check-runs/%d", org, repo, checkRunID),
+		org:       org,
+		exitCodes: []int{200},
+	}, &checkRun)
+	if err != nil {
+		return nil, err
+	}
+	return &checkRun, nil
+}
+
+// ListCheckSuites lists all check suites for the given ref
+//
+// See https://docs.github.com/en/rest/checks/suites#list-check-suites-for-a-git-reference
+func (c *client) ListCheckSuites(org, repo, ref string) (*CheckSuiteList, error) {
+	durationLogger := c.log("ListCheckSuites", org, repo, ref)
+	defer durationLogger()
+
+	var checkSuiteList CheckSuiteList
+	if err := c.readPaginatedResults(
+		fmt.Sprintf("/repos/%s/%s/commits/%s/check-suites", org, repo, ref),
+		"",
+		org,
+		func() interface{} {
+			return &CheckSuiteList{}
+		},
+		func(obj interface{}) {
+			cs := *(obj.(*CheckSuiteList))
+			cs.CheckSuites = append(checkSuiteList.CheckSuites, cs.CheckSuites...)
+			checkSuiteList = cs
+		},
+	); err != nil {
+		return nil, err
+	}
+	return &checkSuiteList, nil
+}
+
+// RerequestCheckSuite asks the app that created a check suite to run it again.
+//
+// See https://docs.github.com/en/rest/checks/suites#rerequest-a-check-suite
+func (c *client) RerequestCheckSuite(org, repo string, checkSuiteID int64) error {
+	durationLogger := c.log("RerequestCheckSuite", org, repo, checkSuiteID)
+	defer durationLogger()
+
+	_, err := c.request(&request{
+		method:    http.MethodPost,
+		path:      fmt.Sprintf("/repos/%s/%s/check-suites/%d/rerequest", org, repo, checkSuiteID),
+		org:       org,
+		exitCodes: []int{201},
+	}, nil)
+	return err
 }
 
 // Simple function to check if GitHub App Authentication is being used
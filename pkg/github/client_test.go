@@ -41,6 +41,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/diff"
 
+	"sigs.k8s.io/prow/pkg/ghcache"
 	"sigs.k8s.io/prow/pkg/throttle"
 	"sigs.k8s.io/prow/pkg/version"
 )
@@ -56,6 +57,9 @@ func (tt *testTime) Sleep(d time.Duration) {
 func (tt *testTime) Until(t time.Time) time.Duration {
 	return t.Sub(tt.now)
 }
+func (tt *testTime) Now() time.Time {
+	return tt.now
+}
 
 func getClient(url string) *client {
 	getToken := func() []byte {
@@ -67,9 +71,10 @@ func getClient(url string) *client {
 	c := &client{
 		logger: logrus.NewEntry(logger),
 		delegate: &delegate{
-			time:     &testTime{},
-			throttle: ghThrottler{Throttler: &throttle.Throttler{}},
-			getToken: getToken,
+			time:      &testTime{},
+			throttle:  ghThrottler{Throttler: &throttle.Throttler{}},
+			secondary: newSecondaryRateLimiter(false),
+			getToken:  getToken,
 			censor: func(content []byte) []byte {
 				return content
 			},
@@ -863,6 +868,62 @@ func TestListIssueComments(t *testing.T) {
 	}
 }
 
+func TestForEachIssueComment(t *testing.T) {
+	var requests []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/repos/k8s/kuber/issues/15/comments":
+			w.Header().Set("Link", fmt.Sprintf(`<blorp>; rel="first", <https://%s/someotherpath>; rel="next"`, r.Host))
+			fmt.Fprint(w, `[{"id": 1}, {"id": 2}]`)
+		case "/someotherpath":
+			w.Header().Set("Link", fmt.Sprintf(`<blorp>; rel="first", <https://%s/lastpath>; rel="next"`, r.Host))
+			fmt.Fprint(w, `[{"id": 3}, {"id": 4}]`)
+		case "/lastpath":
+			fmt.Fprint(w, `[{"id": 5}]`)
+		default:
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+
+	var ids []int
+	if err := c.ForEachIssueComment(context.Background(), "k8s", "kuber", 15, func(ic IssueComment) error {
+		ids = append(ids, ic.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("Didn't expect error: %v", err)
+	}
+	if diff := cmp.Diff([]int{1, 2, 3, 4, 5}, ids); diff != "" {
+		t.Errorf("Wrong issue comment IDs: %s", diff)
+	}
+
+	requests, ids = nil, nil
+	if err := c.ForEachIssueComment(context.Background(), "k8s", "kuber", 15, func(ic IssueComment) error {
+		ids = append(ids, ic.ID)
+		if ic.ID == 3 {
+			return ErrStopIteration
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Didn't expect error when stopping early: %v", err)
+	}
+	if diff := cmp.Diff([]int{1, 2, 3}, ids); diff != "" {
+		t.Errorf("Wrong issue comment IDs when stopping early: %s", diff)
+	}
+	if len(requests) != 2 {
+		t.Errorf("Expected no more pages to be requested after stopping, got %v", requests)
+	}
+
+	expectedErr := errors.New("injected error")
+	if err := c.ForEachIssueComment(context.Background(), "k8s", "kuber", 15, func(IssueComment) error {
+		return expectedErr
+	}); !errors.Is(err, expectedErr) {
+		t.Errorf("Expected the error of the callback, got %v", err)
+	}
+}
+
 func addLabelHTTPServer(t *testing.T, org, repo string, number int, labels ...string) *httptest.Server {
 	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -2441,6 +2502,97 @@ func TestUpdateBranchProtection(t *testing.T) {
 	}
 }
 
+func TestListRepoRulesets(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/org/repo/rulesets" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		if parents := r.URL.Query().Get("includes_parents"); parents != "false" {
+			t.Errorf("Bad includes_parents: %s", parents)
+		}
+		fmt.Fprint(w, `[{"id": 1, "name": "prow: master", "enforcement": "active"}]`)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	rulesets, err := c.ListRepoRulesets("org", "repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []Ruleset{{ID: 1, Name: "prow: master", Enforcement: RulesetEnforcementActive}}
+	if !reflect.DeepEqual(rulesets, expected) {
+		t.Errorf("Expected %v, got %v", expected, rulesets)
+	}
+}
+
+func TestCreateRepoRuleset(t *testing.T) {
+	strict := true
+	ruleset := Ruleset{
+		Name:        "prow: master",
+		Target:      RulesetTargetBranch,
+		Enforcement: RulesetEnforcementActive,
+		Conditions: &RulesetConditions{
+			RefName: RulesetRefNameCondition{Include: []string{"refs/heads/master"}, Exclude: []string{}},
+		},
+		Rules: []RulesetRule{
+			{Type: RuleDeletion},
+			{
+				Type: RuleRequiredStatusChecks,
+				Parameters: &RulesetRuleParameters{
+					RequiredStatusChecks:       []RulesetStatusCheck{{Context: "unit"}},
+					StrictRequiredStatusChecks: &strict,
+				},
+			},
+		},
+	}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/org/repo/rulesets" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		var actual Ruleset
+		if err := json.NewDecoder(r.Body).Decode(&actual); err != nil {
+			t.Fatalf("Could not unmarshal request: %v", err)
+		}
+		if !reflect.DeepEqual(actual, ruleset) {
+			t.Errorf("Bad ruleset: %s", cmp.Diff(ruleset, actual))
+		}
+		actual.ID = 3
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(actual)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	created, err := c.CreateRepoRuleset("org", "repo", ruleset)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created.ID != 3 {
+		t.Errorf("Expected ID 3, got %d", created.ID)
+	}
+}
+
+func TestDeleteRepoRuleset(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/org/repo/rulesets/3" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		http.Error(w, "204 No Content", http.StatusNoContent)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	if err := c.DeleteRepoRuleset("org", "repo", 3); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestClearMilestone(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPatch {
@@ -2884,6 +3036,7 @@ func TestAuthHeaderGetsSet(t *testing.T) {
 				tc.expectedHeader = http.Header{}
 			}
 			tc.expectedHeader["Accept"] = []string{"application/vnd.github.v3+json"}
+			tc.expectedHeader.Set(ghcache.ClientIdentifierHeader, version.Name)
 
 			// Bazel injects some stuff in here, exclude it from comparison so both bazel test
 			// and go test yield the same result.
@@ -3476,11 +3629,136 @@ func TestCreateCheckRun(t *testing.T) {
 		} else if !reflect.DeepEqual(checkRun, cr) {
 			t.Errorf("expected checkrun differs from actual: %s", cmp.Diff(checkRun, cr))
 		}
-		http.Error(w, "201 Created", http.StatusCreated)
+		cr.ID = 1
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(cr)
 	}))
 	defer ts.Close()
 	c := getClient(ts.URL)
-	if err := c.CreateCheckRun("k8s", "kuber", checkRun); err != nil {
+	created, err := c.CreateCheckRun("k8s", "kuber", checkRun)
+	if err != nil {
+		t.Fatalf("Didn't expect error: %v", err)
+	}
+	if created.ID != 1 {
+		t.Errorf("Expected the created check run to have id 1, got %d", created.ID)
+	}
+}
+
+func TestCreateCheckRunWithManyAnnotations(t *testing.T) {
+	var annotations []CheckRunAnnotation
+	for i := 0; i < 2*MaxCheckRunAnnotations+1; i++ {
+		annotations = append(annotations, CheckRunAnnotation{Path: "file.go", StartLine: i + 1, EndLine: i + 1, AnnotationLevel: CheckRunAnnotationLevelWarning, Message: "lint"})
+	}
+	checkRun := CheckRun{
+		Name:    "lint",
+		HeadSHA: "someref",
+		Status:  CheckRunStatusCompleted,
+		Output:  CheckRunOutput{Title: "Lint", Summary: "Found issues", Annotations: annotations},
+	}
+	var received []CheckRunAnnotation
+	var requests []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var cr CheckRun
+		if err := json.NewDecoder(r.Body).Decode(&cr); err != nil {
+			t.Errorf("Could not unmarshal request: %v", err)
+		}
+		if n := len(cr.Output.Annotations); n > MaxCheckRunAnnotations {
+			t.Errorf("Expected at most %d annotations per request, got %d", MaxCheckRunAnnotations, n)
+		}
+		if cr.Output.Title != "Lint" || cr.Output.Summary != "Found issues" {
+			t.Errorf("Expected every request to have the title and summary, got %+v", cr.Output)
+		}
+		received = append(received, cr.Output.Annotations...)
+		cr.ID = 7
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(cr)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	if _, err := c.CreateCheckRun("k8s", "kuber", checkRun); err != nil {
+		t.Fatalf("Didn't expect error: %v", err)
+	}
+	expectedRequests := []string{
+		"POST /repos/k8s/kuber/check-runs",
+		"PATCH /repos/k8s/kuber/check-runs/7",
+		"PATCH /repos/k8s/kuber/check-runs/7",
+	}
+	if diff := cmp.Diff(expectedRequests, requests); diff != "" {
+		t.Errorf("Unexpected requests: %s", diff)
+	}
+	if diff := cmp.Diff(annotations, received); diff != "" {
+		t.Errorf("Unexpected annotations: %s", diff)
+	}
+}
+
+func TestUpdateCheckRun(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/k8s/kuber/check-runs/7" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		var cr CheckRun
+		if err := json.NewDecoder(r.Body).Decode(&cr); err != nil {
+			t.Errorf("Could not unmarshal request: %v", err)
+		}
+		cr.ID = 7
+		json.NewEncoder(w).Encode(cr)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	updated, err := c.UpdateCheckRun("k8s", "kuber", 7, CheckRun{Status: CheckRunStatusCompleted, Conclusion: CheckRunConclusionSuccess})
+	if err != nil {
+		t.Fatalf("Didn't expect error: %v", err)
+	}
+	if updated.ID != 7 || updated.Conclusion != CheckRunConclusionSuccess {
+		t.Errorf("Unexpected updated check run %+v", updated)
+	}
+}
+
+func TestListCheckSuites(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path == "/repos/k8s/kuber/commits/someref/check-suites" {
+			w.Header().Set("Link", fmt.Sprintf(`<https://%s/someotherpath>; rel="next"`, r.Host))
+			fmt.Fprint(w, `{"total_count": 2, "check_suites": [{"id": 1}]}`)
+		} else if r.URL.Path == "/someotherpath" {
+			fmt.Fprint(w, `{"total_count": 2, "check_suites": [{"id": 2}]}`)
+		} else {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	checkSuites, err := c.ListCheckSuites("k8s", "kuber", "someref")
+	if err != nil {
+		t.Fatalf("Didn't expect error: %v", err)
+	}
+	expected := &CheckSuiteList{Total: 2, CheckSuites: []CheckSuite{{ID: 1}, {ID: 2}}}
+	if diff := cmp.Diff(expected, checkSuites); diff != "" {
+		t.Errorf("Unexpected check suites: %s", diff)
+	}
+}
+
+func TestRerequestCheckSuite(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/k8s/kuber/check-suites/3/rerequest" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	if err := c.RerequestCheckSuite("k8s", "kuber", 3); err != nil {
 		t.Errorf("Didn't expect error: %v", err)
 	}
 }
@@ -3532,3 +3810,28 @@ func TestIsAppInstalled(t *testing.T) {
 		})
 	}
 }
+
+func TestPing(t *testing.T) {
+	var status int
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/rate_limit" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{"resources": {}}`)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+
+	status = http.StatusOK
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("Didn't expect error: %v", err)
+	}
+	status = http.StatusUnauthorized
+	if err := c.Ping(context.Background()); err == nil {
+		t.Error("Expected an error when GitHub rejects the credentials")
+	}
+}
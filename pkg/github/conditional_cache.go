@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"sigs.k8s.io/prow/pkg/ghcache"
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
+)
+
+// rateLimitHeaders are taken from the 304 response when a cached response is
+// served, so that they stay current.
+var rateLimitHeaders = []string{
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"X-RateLimit-Used",
+	"X-RateLimit-Resource",
+}
+
+type conditionalCacheEntry struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// conditionalRequestCache revalidates GET requests with the ETag of their last
+// response. GitHub doesn't count 304 responses against the rate limit, so
+// polling endpoints that rarely change is free. It does the same as ghproxy
+// for clients that don't use it, and stays out of the way of clients that do.
+type conditionalRequestCache struct {
+	upstream httpClient
+
+	lock    sync.Mutex
+	entries *simplelru.LRU
+}
+
+func newConditionalRequestCache(upstream httpClient, size int) (*conditionalRequestCache, error) {
+	entries, err := simplelru.NewLRU(size, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conditional request cache: %w", err)
+	}
+	return &conditionalRequestCache{upstream: upstream, entries: entries}, nil
+}
+
+// key tells apart responses for different orgs, since with apps auth every
+// installation sees different resources.
+func conditionalCacheKey(req *http.Request) string {
+	return strings.Join([]string{extractOrgFromContext(req.Context()), req.Header.Get("Accept"), req.URL.String()}, " ")
+}
+
+func (c *conditionalRequestCache) get(key string) (conditionalCacheEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		return conditionalCacheEntry{}, false
+	}
+	return entry.(conditionalCacheEntry), true
+}
+
+func (c *conditionalRequestCache) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return c.upstream.Do(req)
+	}
+	key := conditionalCacheKey(req)
+	entry, cached := c.get(key)
+	if cached {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+	resp, err := c.upstream.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		ghmetrics.CollectConditionalRequestMetrics("revalidated")
+		resp.Body.Close()
+		header := entry.header.Clone()
+		for _, name := range rateLimitHeaders {
+			if value := resp.Header.Get(name); value != "" {
+				header.Set(name, value)
+			}
+		}
+		// Let the throttler refund the token like for ghproxy.
+		header.Set(ghcache.CacheModeHeader, string(ghcache.ModeRevalidated))
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(entry.body)),
+			ContentLength: int64(len(entry.body)),
+			Request:       req,
+		}, nil
+	case resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" || resp.Header.Get(ghcache.CacheModeHeader) != "":
+		// Responses through ghproxy are already revalidated by it.
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if cached {
+		ghmetrics.CollectConditionalRequestMetrics("changed")
+	} else {
+		ghmetrics.CollectConditionalRequestMetrics("miss")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries.Add(key, conditionalCacheEntry{etag: resp.Header.Get("ETag"), header: resp.Header.Clone(), body: body})
+	return resp, nil
+}
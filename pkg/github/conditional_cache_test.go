@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/prow/pkg/ghcache"
+)
+
+func TestConditionalRequestCache(t *testing.T) {
+	etag := `"v1"`
+	body := `[{"number": 1}]`
+	var viaGHProxy bool
+	var conditional []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		if viaGHProxy {
+			w.Header().Set(ghcache.CacheModeHeader, string(ghcache.ModeMiss))
+		}
+		if match := r.Header.Get("If-None-Match"); match != "" {
+			conditional = append(conditional, r.URL.Path)
+			if match == etag {
+				w.Header().Set("X-RateLimit-Remaining", "3999")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Header().Set("ETag", etag)
+		io.WriteString(w, body)
+	}))
+	defer ts.Close()
+
+	cache, err := newConditionalRequestCache(&http.Client{}, 10)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	get := func(ctx context.Context, path string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+path, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := cache.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+	expectBody := func(resp *http.Response, expected string) {
+		t.Helper()
+		actual, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(actual) != expected {
+			t.Errorf("expected 200 with body %q, got %d with %q", expected, resp.StatusCode, string(actual))
+		}
+	}
+
+	expectBody(get(context.Background(), "/pulls"), body)
+	if len(conditional) != 0 {
+		t.Errorf("expected the first request to be unconditional, got %v", conditional)
+	}
+
+	resp := get(context.Background(), "/pulls")
+	if mode := resp.Header.Get(ghcache.CacheModeHeader); mode != string(ghcache.ModeRevalidated) {
+		t.Errorf("expected a revalidated response, got cache mode %q", mode)
+	}
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "3999" {
+		t.Errorf("expected the rate limit headers of the 304 response, got remaining %q", remaining)
+	}
+	expectBody(resp, body)
+
+	expectBody(get(context.WithValue(context.Background(), githubOrgHeaderKey, "org"), "/pulls"), body)
+	if len(conditional) != 1 {
+		t.Errorf("expected responses to be cached per org, got conditional requests %v", conditional)
+	}
+
+	etag, body = `"v2"`, `[{"number": 2}]`
+	expectBody(get(context.Background(), "/pulls"), body)
+	expectBody(get(context.Background(), "/pulls"), body)
+	if len(conditional) != 3 {
+		t.Errorf("expected changed responses to be cached again, got conditional requests %v", conditional)
+	}
+
+	viaGHProxy = true
+	expectBody(get(context.Background(), "/issues"), body)
+	expectBody(get(context.Background(), "/issues"), body)
+	if len(conditional) != 3 {
+		t.Errorf("expected responses through ghproxy not to be cached, got conditional requests %v", conditional)
+	}
+}
@@ -60,6 +60,12 @@ type FakeClient struct {
 	CreatedStatuses            map[string][]github.Status
 	IssueEvents                map[int][]github.ListedIssueEvent
 	Commits                    map[string]github.RepositoryCommit
+	// CheckRuns and CheckSuites map SHAs to the check runs and suites of the commit
+	CheckRuns   map[string][]github.CheckRun
+	CheckRunID  int64
+	CheckSuites map[string][]github.CheckSuite
+	// CheckSuitesRerequested are the ids of rerequested check suites
+	CheckSuitesRerequested []int64
 
 	// All Labels That Exist In The Repo
 	RepoLabelsExisting []string
@@ -163,6 +169,10 @@ func (f *FakeClient) BotUser() (*github.UserData, error) {
 	return &github.UserData{Login: botName}, nil
 }
 
+func (f *FakeClient) BotUserForOrg(_ string) (*github.UserData, error) {
+	return f.BotUser()
+}
+
 func (f *FakeClient) BotUserCheckerWithContext(_ context.Context) (func(candidate string) bool, error) {
 	return f.BotUserChecker()
 }
@@ -187,6 +197,8 @@ func NewFakeClient() *FakeClient {
 		CreatedStatuses:     make(map[string][]github.Status),
 		IssueEvents:         make(map[int][]github.ListedIssueEvent),
 		Commits:             make(map[string]github.RepositoryCommit),
+		CheckRuns:           make(map[string][]github.CheckRun),
+		CheckSuites:         make(map[string][]github.CheckSuite),
 
 		MilestoneMap: make(map[string]int),
 		CommitMap:    make(map[string][]github.RepositoryCommit),
@@ -249,6 +261,38 @@ func (f *FakeClient) ListIssueCommentsWithContext(ctx context.Context, owner, re
 	return append([]github.IssueComment{}, f.IssueComments[number]...), nil
 }
 
+// ForEachIssueComment calls fn for every comment.
+func (f *FakeClient) ForEachIssueComment(ctx context.Context, owner, repo string, number int, fn func(github.IssueComment) error) error {
+	comments, err := f.ListIssueCommentsWithContext(ctx, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	return forEach(comments, fn)
+}
+
+// ForEachOpenIssue calls fn for every issue.
+func (f *FakeClient) ForEachOpenIssue(ctx context.Context, owner, repo string, fn func(github.Issue) error) error {
+	issues, err := f.ListOpenIssues(owner, repo)
+	if err != nil {
+		return err
+	}
+	return forEach(issues, fn)
+}
+
+// forEach calls fn for every item like the ForEach* methods of the client do,
+// outside of the lock so that fn can use the fake client.
+func forEach[T any](items []T, fn func(T) error) error {
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			if errors.Is(err, github.ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // ListPullRequestComments returns review comments.
 func (f *FakeClient) ListPullRequestComments(owner, repo string, number int) ([]github.ReviewComment, error) {
 	f.lock.RLock()
@@ -298,9 +342,9 @@ func (f *FakeClient) EditCommentWithContext(_ context.Context, org, repo string,
 	defer f.lock.Unlock()
 	f.IssueCommentsEdited = append(f.IssueCommentsEdited, fmt.Sprintf("%s/%s#%d:%s", org, repo, ID, comment))
 	for _, ics := range f.IssueComments {
-		for _, ic := range ics {
-			if ic.ID == ID {
-				ic.Body = comment
+		for i := range ics {
+			if ics[i].ID == ID {
+				ics[i].Body = comment
 			}
 		}
 	}
@@ -387,6 +431,18 @@ func (f *FakeClient) GetPullRequest(owner, repo string, number int) (*github.Pul
 	return val, nil
 }
 
+// ForEachPullRequest calls fn for every pull request.
+func (f *FakeClient) ForEachPullRequest(ctx context.Context, owner, repo string, fn func(github.PullRequest) error) error {
+	f.lock.RLock()
+	var prs []github.PullRequest
+	for _, pr := range f.PullRequests {
+		prs = append(prs, *pr)
+	}
+	f.lock.RUnlock()
+	sort.Slice(prs, func(i, j int) bool { return prs[i].Number < prs[j].Number })
+	return forEach(prs, fn)
+}
+
 // EditPullRequest edits the pull request.
 func (f *FakeClient) EditPullRequest(org, repo string, number int, issue *github.PullRequest) (*github.PullRequest, error) {
 	f.lock.Lock()
@@ -550,6 +606,100 @@ func (f *FakeClient) GetCombinedStatus(owner, repo, ref string) (*github.Combine
 	return f.CombinedStatuses[ref], nil
 }
 
+// CreateCheckRun adds a check run to a commit.
+func (f *FakeClient) CreateCheckRun(org, repo string, checkRun github.CheckRun) (*github.CheckRun, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.Error != nil {
+		return nil, f.Error
+	}
+	if f.CheckRuns == nil {
+		f.CheckRuns = make(map[string][]github.CheckRun)
+	}
+	f.CheckRunID++
+	checkRun.ID = f.CheckRunID
+	f.CheckRuns[checkRun.HeadSHA] = append(f.CheckRuns[checkRun.HeadSHA], checkRun)
+	return &checkRun, nil
+}
+
+// UpdateCheckRun updates a check run, adding its annotations to the existing ones.
+func (f *FakeClient) UpdateCheckRun(org, repo string, checkRunID int64, checkRun github.CheckRun) (*github.CheckRun, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.Error != nil {
+		return nil, f.Error
+	}
+	for sha, checkRuns := range f.CheckRuns {
+		for i := range checkRuns {
+			if checkRuns[i].ID != checkRunID {
+				continue
+			}
+			existing := &f.CheckRuns[sha][i]
+			if checkRun.Name != "" {
+				existing.Name = checkRun.Name
+			}
+			if checkRun.DetailsURL != "" {
+				existing.DetailsURL = checkRun.DetailsURL
+			}
+			if checkRun.Status != "" {
+				existing.Status = checkRun.Status
+			}
+			if checkRun.Conclusion != "" {
+				existing.Conclusion = checkRun.Conclusion
+			}
+			if checkRun.CompletedAt != "" {
+				existing.CompletedAt = checkRun.CompletedAt
+			}
+			annotations := append(existing.Output.Annotations, checkRun.Output.Annotations...)
+			if checkRun.Output.Title != "" {
+				existing.Output = checkRun.Output
+			}
+			existing.Output.Annotations = annotations
+			updated := *existing
+			return &updated, nil
+		}
+	}
+	return nil, fmt.Errorf("check run %d not found", checkRunID)
+}
+
+// GetCheckRun returns a check run by its id.
+func (f *FakeClient) GetCheckRun(org, repo string, checkRunID int64) (*github.CheckRun, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	for _, checkRuns := range f.CheckRuns {
+		for _, checkRun := range checkRuns {
+			if checkRun.ID == checkRunID {
+				return &checkRun, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("check run %d not found", checkRunID)
+}
+
+// ListCheckRuns returns the check runs of a commit.
+func (f *FakeClient) ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	checkRuns := append([]github.CheckRun{}, f.CheckRuns[ref]...)
+	return &github.CheckRunList{Total: len(checkRuns), CheckRuns: checkRuns}, nil
+}
+
+// ListCheckSuites returns the check suites of a commit.
+func (f *FakeClient) ListCheckSuites(org, repo, ref string) (*github.CheckSuiteList, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	checkSuites := append([]github.CheckSuite{}, f.CheckSuites[ref]...)
+	return &github.CheckSuiteList{Total: len(checkSuites), CheckSuites: checkSuites}, nil
+}
+
+// RerequestCheckSuite records that a check suite was rerequested.
+func (f *FakeClient) RerequestCheckSuite(org, repo string, checkSuiteID int64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.CheckSuitesRerequested = append(f.CheckSuitesRerequested, checkSuiteID)
+	return nil
+}
+
 // GetRepoLabels gets labels in a repo.
 func (f *FakeClient) GetRepoLabels(owner, repo string) ([]github.Label, error) {
 	f.lock.RLock()
@@ -101,9 +101,93 @@ var cacheEntryAge = prometheus.NewHistogramVec(
 	[]string{"token_hash", "path", "user_agent"},
 )
 
+var graphQLQueryCost = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "github_graphql_query_cost",
+		Help: "Rate limit points spent on GitHub GraphQL queries by query.",
+	},
+	[]string{"query"},
+)
+
+var deferredRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "github_client_deferred_requests",
+		Help: "GitHub requests deferred by the client until the rate limit resets, by priority.",
+	},
+	[]string{"priority"},
+)
+
+var secondaryRateLimits = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "github_secondary_rate_limits",
+		Help: "GitHub responses that hit a secondary rate limit, by API.",
+	},
+	[]string{"api"},
+)
+
+var secondaryRateLimitWaitDuration = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "github_secondary_rate_limit_wait_seconds",
+		Help:    "How long GitHub requests waited for a secondary rate limit to pass.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+	},
+)
+
+var conditionalRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "github_client_conditional_requests",
+		Help: "GET requests revalidated by the conditional request cache of the GitHub client, by result.",
+	},
+	[]string{"result"},
+)
+
 var muxTokenUsage sync.Mutex
 var lastGitHubResponse time.Time
 
+var requestRetries = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "github_client_request_retries",
+		Help: "Requests retried by the GitHub client, by reason.",
+	},
+	[]string{"reason"},
+)
+
+var circuitBreakerOpen = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "github_client_circuit_breaker_open",
+		Help: "Whether the circuit breaker of the GitHub client fails requests to an endpoint fast, by endpoint.",
+	},
+	[]string{"endpoint"},
+)
+
+var circuitBreakerRejectedRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "github_client_circuit_breaker_rejected_requests",
+		Help: "Requests failed fast by the circuit breaker of the GitHub client, by endpoint.",
+	},
+	[]string{"endpoint"},
+)
+
+// APIUsageLabels are the labels the API usage of the GitHub client is
+// attributed to.
+var APIUsageLabels = []string{"component", "caller", "org", "repo", "api"}
+
+var apiRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "github_client_api_requests",
+		Help: "Requests the GitHub client sent to the GitHub API, by component, plugin or subcomponent, org, repo and API.",
+	},
+	APIUsageLabels,
+)
+
+var apiRateLimitPoints = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "github_client_api_rate_limit_points",
+		Help: "Rate limit points the GitHub client consumed, by component, plugin or subcomponent, org, repo and API.",
+	},
+	APIUsageLabels,
+)
+
 func init() {
 	prometheus.MustRegister(ghTokenUntilResetGaugeVec)
 	prometheus.MustRegister(ghTokenUsageGaugeVec)
@@ -112,6 +196,16 @@ func init() {
 	prometheus.MustRegister(cacheCounter)
 	prometheus.MustRegister(timeoutDuration)
 	prometheus.MustRegister(cacheEntryAge)
+	prometheus.MustRegister(graphQLQueryCost)
+	prometheus.MustRegister(deferredRequests)
+	prometheus.MustRegister(secondaryRateLimits)
+	prometheus.MustRegister(secondaryRateLimitWaitDuration)
+	prometheus.MustRegister(conditionalRequests)
+	prometheus.MustRegister(requestRetries)
+	prometheus.MustRegister(circuitBreakerOpen)
+	prometheus.MustRegister(circuitBreakerRejectedRequests)
+	prometheus.MustRegister(apiRequests)
+	prometheus.MustRegister(apiRateLimitPoints)
 }
 
 // CollectGitHubTokenMetrics publishes the rate limits of the github api to
@@ -156,6 +250,70 @@ func CollectGitHubRequestMetrics(tokenHash, path, statusCode, userAgent string,
 	ghRequestDurationHistVec.With(prometheus.Labels{"token_hash": tokenHash, "path": simplifier.Simplify(path), "status": statusCode, "user_agent": userAgentWithoutVersion(userAgent)}).Observe(roundTripTime)
 }
 
+// CollectGraphQLQueryCostMetrics publishes the rate limit cost of a GraphQL
+// query to `github_graphql_query_cost` on prometheus.
+func CollectGraphQLQueryCostMetrics(query string, cost int) {
+	graphQLQueryCost.With(prometheus.Labels{"query": query}).Add(float64(cost))
+}
+
+// CollectDeferredRequestMetrics counts a request deferred by the budget
+// manager of the client in `github_client_deferred_requests` on prometheus.
+func CollectDeferredRequestMetrics(priority string) {
+	deferredRequests.With(prometheus.Labels{"priority": priority}).Inc()
+}
+
+// CollectSecondaryRateLimitMetrics counts a secondary rate limit hit through
+// the given API, rest or graphql, in `github_secondary_rate_limits` on
+// prometheus.
+func CollectSecondaryRateLimitMetrics(api string) {
+	secondaryRateLimits.With(prometheus.Labels{"api": api}).Inc()
+}
+
+// CollectSecondaryRateLimitWaitMetrics publishes how long a request waited for
+// a secondary rate limit to `github_secondary_rate_limit_wait_seconds` on
+// prometheus.
+func CollectSecondaryRateLimitWaitMetrics(wait time.Duration) {
+	secondaryRateLimitWaitDuration.Observe(wait.Seconds())
+}
+
+// CollectConditionalRequestMetrics counts a GET request of the conditional
+// request cache of the client in `github_client_conditional_requests` on
+// prometheus. The result is revalidated, changed or miss.
+func CollectConditionalRequestMetrics(result string) {
+	conditionalRequests.With(prometheus.Labels{"result": result}).Inc()
+}
+
+// CollectRequestRetryMetrics counts a request retried by the GitHub client.
+func CollectRequestRetryMetrics(reason string) {
+	requestRetries.With(prometheus.Labels{"reason": reason}).Inc()
+}
+
+// CollectCircuitBreakerMetrics publishes whether the circuit breaker of the
+// GitHub client is open for an endpoint.
+func CollectCircuitBreakerMetrics(endpoint string, open bool) {
+	var value float64
+	if open {
+		value = 1
+	}
+	circuitBreakerOpen.With(prometheus.Labels{"endpoint": endpoint}).Set(value)
+}
+
+// CollectCircuitBreakerRejectedRequestMetrics counts a request failed fast by
+// the circuit breaker of the GitHub client.
+func CollectCircuitBreakerRejectedRequestMetrics(endpoint string) {
+	circuitBreakerRejectedRequests.With(prometheus.Labels{"endpoint": endpoint}).Inc()
+}
+
+// CollectAPIUsageMetrics attributes a request of the GitHub client and the
+// rate limit points it consumed, zero if GitHub or ghproxy answered it for
+// free, to the component and the plugin or subcomponent that sent it, and to
+// the org and repo it was about.
+func CollectAPIUsageMetrics(component, caller, org, repo, api string, points int) {
+	labels := prometheus.Labels{"component": component, "caller": caller, "org": org, "repo": repo, "api": api}
+	apiRequests.With(labels).Inc()
+	apiRateLimitPoints.With(labels).Add(float64(points))
+}
+
 // timestampStringToTime takes a unix timestamp and returns a `time.Time`
 // from the given time.
 func timestampStringToTime(tstamp string) time.Time {
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
+)
+
+// GraphQLQuerier runs GraphQL queries. It is implemented by Client.
+type GraphQLQuerier interface {
+	QueryWithGitHubAppsSupport(ctx context.Context, q interface{}, vars map[string]interface{}, org string) error
+}
+
+// GraphQLQuerierFunc adapts a query function to a GraphQLQuerier.
+type GraphQLQuerierFunc func(ctx context.Context, q interface{}, vars map[string]interface{}, org string) error
+
+func (f GraphQLQuerierFunc) QueryWithGitHubAppsSupport(ctx context.Context, q interface{}, vars map[string]interface{}, org string) error {
+	return f(ctx, q, vars, org)
+}
+
+// GraphQLRateLimit is the rate limit information of a query. Queries include
+// it as a RateLimit field to track their cost.
+type GraphQLRateLimit struct {
+	Cost      githubql.Int
+	Remaining githubql.Int
+}
+
+// GraphQLPageInfo is the page info of a paginated connection.
+type GraphQLPageInfo struct {
+	HasNextPage githubql.Boolean
+	EndCursor   githubql.String
+}
+
+// GraphQLPaginatedQuery is a query over a paginated connection.
+type GraphQLPaginatedQuery interface {
+	// GraphQLPageInfo returns the page info of the connection.
+	GraphQLPageInfo() GraphQLPageInfo
+	// GraphQLRateLimit returns the rate limit information of the query.
+	GraphQLRateLimit() GraphQLRateLimit
+}
+
+// GraphQLQueryStats sums up the pages of a paginated query.
+type GraphQLQueryStats struct {
+	Pages int
+	// Cost is the total rate limit cost of all pages.
+	Cost int
+	// Remaining is the rate limit left after the last page.
+	Remaining int
+}
+
+// QueryAllPages runs a paginated query and passes every page to onPage. Each
+// page is queried into a new query from newQuery, with the end cursor of the
+// previous page set as the cursorVar variable, which must be a
+// *githubql.String. The name of the query labels its cost in metrics.
+func QueryAllPages[Q GraphQLPaginatedQuery](ctx context.Context, c GraphQLQuerier, org, name string, newQuery func() Q, vars map[string]interface{}, cursorVar string, onPage func(Q) error) (GraphQLQueryStats, error) {
+	var stats GraphQLQueryStats
+	if _, ok := vars[cursorVar]; !ok {
+		vars[cursorVar] = (*githubql.String)(nil)
+	}
+	for {
+		q := newQuery()
+		if err := c.QueryWithGitHubAppsSupport(ctx, q, vars, org); err != nil {
+			if cursor, ok := vars[cursorVar].(*githubql.String); ok && cursor != nil {
+				err = fmt.Errorf("cursor: %q, err: %w", *cursor, err)
+			}
+			return stats, err
+		}
+		rateLimit := q.GraphQLRateLimit()
+		stats.Pages++
+		stats.Cost += int(rateLimit.Cost)
+		stats.Remaining = int(rateLimit.Remaining)
+		ghmetrics.CollectGraphQLQueryCostMetrics(name, int(rateLimit.Cost))
+		if err := onPage(q); err != nil {
+			return stats, err
+		}
+		pageInfo := q.GraphQLPageInfo()
+		if !pageInfo.HasNextPage {
+			return stats, nil
+		}
+		vars[cursorVar] = githubql.NewString(pageInfo.EndCursor)
+	}
+}
+
+// StatusContextFragment is a commit status in a status check rollup.
+type StatusContextFragment struct {
+	Context     githubql.String
+	Description githubql.String
+	State       githubql.StatusState
+	TargetURL   githubql.String `graphql:"targetUrl"`
+}
+
+// CheckRunFragment is a check run in a status check rollup.
+type CheckRunFragment struct {
+	Name       githubql.String
+	Status     githubql.String
+	Conclusion githubql.String
+	DetailsURL githubql.String `graphql:"detailsUrl"`
+}
+
+// CommitContextNode is either a commit status or a check run, as told by
+// its Typename.
+type CommitContextNode struct {
+	Typename      githubql.String       `graphql:"__typename"`
+	StatusContext StatusContextFragment `graphql:"... on StatusContext"`
+	CheckRun      CheckRunFragment      `graphql:"... on CheckRun"`
+}
+
+// CommitContextsQuery queries the statuses and check runs of a commit. It is
+// exported so that fake clients can answer it.
+type CommitContextsQuery struct {
+	RateLimit  GraphQLRateLimit
+	Repository struct {
+		Object struct {
+			Commit struct {
+				StatusCheckRollup struct {
+					Contexts struct {
+						PageInfo GraphQLPageInfo
+						Nodes    []CommitContextNode
+					} `graphql:"contexts(first: 100, after: $contextsCursor)"`
+				}
+			} `graphql:"... on Commit"`
+		} `graphql:"object(expression: $ref)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+func (q *CommitContextsQuery) GraphQLPageInfo() GraphQLPageInfo {
+	return q.Repository.Object.Commit.StatusCheckRollup.Contexts.PageInfo
+}
+
+func (q *CommitContextsQuery) GraphQLRateLimit() GraphQLRateLimit {
+	return q.RateLimit
+}
+
+// CommitContexts are the statuses and check runs of a commit.
+type CommitContexts struct {
+	Statuses  []StatusContextFragment
+	CheckRuns []CheckRunFragment
+}
+
+// GetCommitContexts returns the statuses and check runs of a commit in as few
+// GraphQL queries as possible, rather than listing them through the REST API
+// with GetCombinedStatus and ListCheckRuns.
+func GetCommitContexts(ctx context.Context, c GraphQLQuerier, org, repo, ref string) (*CommitContexts, error) {
+	vars := map[string]interface{}{
+		"owner": githubql.String(org),
+		"name":  githubql.String(repo),
+		"ref":   githubql.String(ref),
+	}
+	contexts := &CommitContexts{}
+	stats, err := QueryAllPages(ctx, c, org, "commit_contexts", func() *CommitContextsQuery { return &CommitContextsQuery{} }, vars, "contextsCursor", func(q *CommitContextsQuery) error {
+		for _, node := range q.Repository.Object.Commit.StatusCheckRollup.Contexts.Nodes {
+			switch node.Typename {
+			case "StatusContext":
+				contexts.Statuses = append(contexts.Statuses, node.StatusContext)
+			case "CheckRun":
+				contexts.CheckRuns = append(contexts.CheckRuns, node.CheckRun)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the contexts of %s/%s@%s: %w", org, repo, ref, err)
+	}
+	logrus.WithFields(logrus.Fields{
+		"org":       org,
+		"repo":      repo,
+		"ref":       ref,
+		"pages":     stats.Pages,
+		"cost":      stats.Cost,
+		"remaining": stats.Remaining,
+	}).Debug("Queried commit contexts")
+	return contexts, nil
+}
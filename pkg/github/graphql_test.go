@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	githubql "github.com/shurcooL/githubv4"
+)
+
+// commitContextsPages answers commit contexts queries with the given pages.
+func commitContextsPages(t *testing.T, pages [][]CommitContextNode, failOnPage int) (GraphQLQuerier, *int) {
+	var calls int
+	return GraphQLQuerierFunc(func(_ context.Context, q interface{}, vars map[string]interface{}, org string) error {
+		defer func() { calls++ }()
+		if org != "org" || vars["name"] != githubql.String("repo") || vars["ref"] != githubql.String("sha") {
+			t.Errorf("unexpected org %q or vars %v", org, vars)
+		}
+		var expectedCursor *githubql.String
+		if calls > 0 {
+			expectedCursor = githubql.NewString(githubql.String(rune('a' + calls - 1)))
+		}
+		if diff := cmp.Diff(expectedCursor, vars["contextsCursor"]); diff != "" {
+			t.Errorf("unexpected cursor on page %d: %s", calls, diff)
+		}
+		if calls == failOnPage {
+			return errors.New("injected error")
+		}
+		cq := q.(*CommitContextsQuery)
+		cq.RateLimit = GraphQLRateLimit{Cost: 1, Remaining: githubql.Int(100 - calls)}
+		contexts := &cq.Repository.Object.Commit.StatusCheckRollup.Contexts
+		contexts.Nodes = pages[calls]
+		if calls < len(pages)-1 {
+			contexts.PageInfo = GraphQLPageInfo{HasNextPage: true, EndCursor: githubql.String(rune('a' + calls))}
+		}
+		return nil
+	}), &calls
+}
+
+func TestGetCommitContexts(t *testing.T) {
+	pages := [][]CommitContextNode{
+		{
+			{Typename: "StatusContext", StatusContext: StatusContextFragment{Context: "status", State: githubql.StatusStateSuccess}},
+			{Typename: "CheckRun", CheckRun: CheckRunFragment{Name: "check", Status: "COMPLETED", Conclusion: "FAILURE"}},
+		},
+		{
+			{Typename: "StatusContext", StatusContext: StatusContextFragment{Context: "other-status", State: githubql.StatusStatePending}},
+		},
+	}
+
+	querier, calls := commitContextsPages(t, pages, -1)
+	contexts, err := GetCommitContexts(context.Background(), querier, "org", "repo", "sha")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &CommitContexts{
+		Statuses: []StatusContextFragment{
+			{Context: "status", State: githubql.StatusStateSuccess},
+			{Context: "other-status", State: githubql.StatusStatePending},
+		},
+		CheckRuns: []CheckRunFragment{{Name: "check", Status: "COMPLETED", Conclusion: "FAILURE"}},
+	}
+	if diff := cmp.Diff(expected, contexts); diff != "" {
+		t.Errorf("unexpected contexts: %s", diff)
+	}
+	if *calls != 2 {
+		t.Errorf("expected 2 queries, got %d", *calls)
+	}
+
+	querier, _ = commitContextsPages(t, pages, 1)
+	if _, err := GetCommitContexts(context.Background(), querier, "org", "repo", "sha"); err == nil {
+		t.Error("expected an error when a page fails")
+	}
+}
+
+func TestQueryAllPagesStats(t *testing.T) {
+	querier, _ := commitContextsPages(t, [][]CommitContextNode{nil, nil, nil}, -1)
+	vars := map[string]interface{}{
+		"owner": githubql.String("org"),
+		"name":  githubql.String("repo"),
+		"ref":   githubql.String("sha"),
+	}
+	var pages int
+	stats, err := QueryAllPages(context.Background(), querier, "org", "test", func() *CommitContextsQuery { return &CommitContextsQuery{} }, vars, "contextsCursor", func(*CommitContextsQuery) error {
+		pages++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(GraphQLQueryStats{Pages: 3, Cost: 3, Remaining: 98}, stats); diff != "" {
+		t.Errorf("unexpected stats: %s", diff)
+	}
+	if pages != 3 {
+		t.Errorf("expected onPage to be called 3 times, got %d", pages)
+	}
+}
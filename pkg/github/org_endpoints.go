@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OrgEndpoint routes the requests for an org to another GitHub instance than
+// the one of the client, e.g. to GitHub Enterprise for an org that wasn't
+// migrated to github.com yet.
+type OrgEndpoint struct {
+	// Bases are the REST API endpoints of the instance, in order of preference
+	// like ClientOptions.Bases.
+	Bases []string
+	// GraphqlEndpoint is the GraphQL API endpoint of the instance.
+	GraphqlEndpoint string
+	// GetToken returns the token to use for the instance.
+	GetToken func() []byte
+}
+
+func (e OrgEndpoint) validate() error {
+	if len(e.Bases) == 0 {
+		return errors.New("no API endpoint")
+	}
+	for _, base := range append([]string{e.GraphqlEndpoint}, e.Bases...) {
+		if _, err := url.ParseRequestURI(base); err != nil {
+			return fmt.Errorf("invalid endpoint %q: %w", base, err)
+		}
+	}
+	if e.GetToken == nil {
+		return errors.New("no token")
+	}
+	return nil
+}
+
+// orgEndpoints maps lowercase org names to their endpoints, as org names are
+// case insensitive.
+type orgEndpoints map[string]OrgEndpoint
+
+func newOrgEndpoints(endpoints map[string]OrgEndpoint) (orgEndpoints, error) {
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+	normalized := orgEndpoints{}
+	for org, endpoint := range endpoints {
+		if err := endpoint.validate(); err != nil {
+			return nil, fmt.Errorf("invalid endpoint for org %s: %w", org, err)
+		}
+		normalized[strings.ToLower(org)] = endpoint
+	}
+	return normalized, nil
+}
+
+func (e orgEndpoints) lookup(org string) (OrgEndpoint, bool) {
+	if len(e) == 0 || org == "" {
+		return OrgEndpoint{}, false
+	}
+	endpoint, ok := e[strings.ToLower(org)]
+	return endpoint, ok
+}
+
+// basesFor returns the REST API endpoints for the requests of an org.
+func (c *client) basesFor(org string) []string {
+	if endpoint, ok := c.orgEndpoints.lookup(org); ok {
+		return endpoint.Bases
+	}
+	return c.bases
+}
+
+// tokenFor returns the token to authenticate the requests of an org with.
+func (c *client) tokenFor(org string) []byte {
+	if endpoint, ok := c.orgEndpoints.lookup(org); ok {
+		return endpoint.GetToken()
+	}
+	if c.getToken == nil {
+		return nil
+	}
+	return c.getToken()
+}
+
+// BotUserForOrg returns the user data of the authenticated identity on the
+// GitHub instance of an org, which is another user than BotUser for orgs
+// routed to another instance.
+func (c *client) BotUserForOrg(org string) (*UserData, error) {
+	endpoint, ok := c.orgEndpoints.lookup(org)
+	if !ok {
+		return c.BotUser()
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	userData, err := c.orgUserDataFor(context.Background(), org, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("fetching bot name of org %s from GitHub: %w", org, err)
+	}
+	return userData, nil
+}
+
+// orgUserDataFor returns the user data on the instance of a routed org, which
+// is fetched once per instance. Not thread-safe - callers need to hold c.mut.
+func (c *client) orgUserDataFor(ctx context.Context, org string, endpoint OrgEndpoint) (*UserData, error) {
+	if userData, ok := c.orgUserData[endpoint.Bases[0]]; ok {
+		return userData, nil
+	}
+	c.log("User", org)
+	var u User
+	_, err := c.requestWithContext(ctx, &request{
+		method:    http.MethodGet,
+		path:      "/user",
+		org:       org,
+		exitCodes: []int{200},
+	}, &u)
+	if err != nil {
+		return nil, err
+	}
+	if c.orgUserData == nil {
+		c.orgUserData = map[string]*UserData{}
+	}
+	c.orgUserData[endpoint.Bases[0]] = &UserData{Name: u.Name, Login: u.Login, Email: u.Email}
+	return c.orgUserData[endpoint.Bases[0]], nil
+}
+
+// routeGraphQLRequest sends a GraphQL request for an org with its own endpoint
+// there, with its token.
+func (e orgEndpoints) routeGraphQLRequest(r *http.Request, org string) error {
+	endpoint, ok := e.lookup(org)
+	if !ok {
+		return nil
+	}
+	u, err := url.Parse(endpoint.GraphqlEndpoint)
+	if err != nil {
+		return fmt.Errorf("invalid GraphQL endpoint for org %s: %w", org, err)
+	}
+	r.URL = u
+	r.Host = u.Host
+	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", endpoint.GetToken()))
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRequestRetryOrgEndpoints(t *testing.T) {
+	var defaultAuth, enterpriseAuth []string
+	defaultServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defaultAuth = append(defaultAuth, r.Header.Get("Authorization"))
+	}))
+	defer defaultServer.Close()
+	enterpriseServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enterpriseAuth = append(enterpriseAuth, r.Header.Get("Authorization"))
+	}))
+	defer enterpriseServer.Close()
+
+	c := getClient(defaultServer.URL)
+	c.getToken = func() []byte { return []byte("default-token") }
+	endpoints, err := newOrgEndpoints(map[string]OrgEndpoint{
+		"Enterprise-Org": {Bases: []string{enterpriseServer.URL}, GraphqlEndpoint: enterpriseServer.URL + "/graphql", GetToken: func() []byte { return []byte("enterprise-token") }},
+	})
+	if err != nil {
+		t.Fatalf("failed to create org endpoints: %v", err)
+	}
+	c.orgEndpoints = endpoints
+
+	for _, org := range []string{"org", "enterprise-org", ""} {
+		resp, err := c.requestRetry(http.MethodGet, "/", "", org, nil)
+		if err != nil {
+			t.Fatalf("Error from request for org %q: %v", org, err)
+		}
+		resp.Body.Close()
+	}
+	if len(defaultAuth) != 2 || defaultAuth[0] != "Bearer default-token" || defaultAuth[1] != "Bearer default-token" {
+		t.Errorf("Expected two requests with the default token to the default endpoint, got %v", defaultAuth)
+	}
+	if len(enterpriseAuth) != 1 || enterpriseAuth[0] != "Bearer enterprise-token" {
+		t.Errorf("Expected a request with the enterprise token to the enterprise endpoint, got %v", enterpriseAuth)
+	}
+	if token := string(c.tokenFor("ENTERPRISE-ORG")); token != "enterprise-token" {
+		t.Errorf("Expected org names to be case insensitive, got token %q", token)
+	}
+}
+
+func TestBotUserOrgEndpoints(t *testing.T) {
+	var enterpriseRequests int
+	defaultServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login": "bot"}`))
+	}))
+	defer defaultServer.Close()
+	enterpriseServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enterpriseRequests++
+		if r.URL.Path != "/user" || r.Header.Get("Authorization") != "Bearer enterprise-token" {
+			t.Errorf("Unexpected request %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"login": "enterprise-bot"}`))
+	}))
+	defer enterpriseServer.Close()
+
+	c := getClient(defaultServer.URL)
+	c.getToken = func() []byte { return []byte("default-token") }
+	endpoints, err := newOrgEndpoints(map[string]OrgEndpoint{
+		"enterprise-org": {Bases: []string{enterpriseServer.URL}, GraphqlEndpoint: enterpriseServer.URL + "/graphql", GetToken: func() []byte { return []byte("enterprise-token") }},
+	})
+	if err != nil {
+		t.Fatalf("failed to create org endpoints: %v", err)
+	}
+	c.orgEndpoints = endpoints
+
+	for org, expected := range map[string]string{"org": "bot", "Enterprise-Org": "enterprise-bot"} {
+		user, err := c.BotUserForOrg(org)
+		if err != nil {
+			t.Fatalf("Error getting the bot user of org %s: %v", org, err)
+		}
+		if user.Login != expected {
+			t.Errorf("Expected the bot user of org %s to be %s, got %s", org, expected, user.Login)
+		}
+	}
+	checker, err := c.BotUserChecker()
+	if err != nil {
+		t.Fatalf("Error getting the bot user checker: %v", err)
+	}
+	for candidate, expected := range map[string]bool{"bot": true, "enterprise-bot": true, "someone": false} {
+		if checker(candidate) != expected {
+			t.Errorf("Expected %s to be the bot: %t", candidate, expected)
+		}
+	}
+	if enterpriseRequests != 1 {
+		t.Errorf("Expected the enterprise bot user to be fetched once, got %d requests", enterpriseRequests)
+	}
+}
+
+func TestRouteGraphQLRequest(t *testing.T) {
+	endpoints, err := newOrgEndpoints(map[string]OrgEndpoint{
+		"enterprise-org": {Bases: []string{"https://ghe.example.com/api/v3"}, GraphqlEndpoint: "https://ghe.example.com/api/graphql", GetToken: func() []byte { return []byte("enterprise-token") }},
+	})
+	if err != nil {
+		t.Fatalf("failed to create org endpoints: %v", err)
+	}
+
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodPost, DefaultGraphQLEndpoint, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	r.Header.Set("Authorization", "Bearer default-token")
+	if err := endpoints.routeGraphQLRequest(r, "org"); err != nil {
+		t.Fatalf("failed to route request: %v", err)
+	}
+	if r.URL.String() != DefaultGraphQLEndpoint || r.Header.Get("Authorization") != "Bearer default-token" {
+		t.Errorf("Expected the request for another org not to be routed, got %s with %q", r.URL, r.Header.Get("Authorization"))
+	}
+
+	if err := endpoints.routeGraphQLRequest(r, "Enterprise-Org"); err != nil {
+		t.Fatalf("failed to route request: %v", err)
+	}
+	if r.URL.String() != "https://ghe.example.com/api/graphql" || r.Host != "ghe.example.com" || r.Header.Get("Authorization") != "Bearer enterprise-token" {
+		t.Errorf("Expected the request to be routed to the enterprise endpoint, got %s with %q", r.URL, r.Header.Get("Authorization"))
+	}
+}
+
+func TestNewClientFromOptionsOrgEndpoints(t *testing.T) {
+	valid := map[string]OrgEndpoint{"org": {Bases: []string{"https://ghe.example.com/api/v3"}, GraphqlEndpoint: "https://ghe.example.com/api/graphql", GetToken: func() []byte { return nil }}}
+	testCases := []struct {
+		name        string
+		options     ClientOptions
+		expectedErr bool
+	}{
+		{
+			name:    "token auth",
+			options: ClientOptions{OrgEndpoints: valid},
+		},
+		{
+			name:        "apps auth",
+			options:     ClientOptions{OrgEndpoints: valid, AppID: "1"},
+			expectedErr: true,
+		},
+		{
+			name:        "no endpoint",
+			options:     ClientOptions{OrgEndpoints: map[string]OrgEndpoint{"org": {GetToken: func() []byte { return nil }}}},
+			expectedErr: true,
+		},
+		{
+			name:        "no token",
+			options:     ClientOptions{OrgEndpoints: map[string]OrgEndpoint{"org": {Bases: []string{"https://ghe.example.com/api/v3"}, GraphqlEndpoint: "https://ghe.example.com/api/graphql"}}},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.options.GetToken = func() []byte { return nil }
+			tc.options.Bases = []string{DefaultAPIEndpoint}
+			tc.options.GraphqlEndpoint = DefaultGraphQLEndpoint
+			_, _, _, err := NewClientFromOptions(logrus.Fields{}, tc.options)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
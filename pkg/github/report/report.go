@@ -146,6 +146,42 @@ func ReportStatusContext(ctx context.Context, ghc GitHubClient, pj prowapi.ProwJ
 	return nil
 }
 
+// ReportBatchComment reports the result of a completed on-demand batch job on
+// every PR of the batch.
+func ReportBatchComment(ctx context.Context, ghc GitHubClient, pj prowapi.ProwJob) error {
+	if ghc == nil {
+		return errors.New("trying to report pj, but found empty github client")
+	}
+	refs := pj.Spec.Refs
+	if !pj.Spec.Report || !pj.Complete() || refs == nil || len(refs.Pulls) == 0 {
+		return nil
+	}
+
+	var pulls []string
+	for _, pull := range refs.Pulls {
+		pulls = append(pulls, fmt.Sprintf("#%d", pull.Number))
+	}
+	result := "succeeded"
+	if pj.Status.State != prowapi.SuccessState {
+		result = "**" + string(pj.Status.State) + "**"
+	}
+	comment := fmt.Sprintf("The batch job `%s` testing %s on top of %s %s.", pj.Spec.Job, strings.Join(pulls, ", "), refs.BaseSHA, result)
+	if pj.Status.URL != "" {
+		comment += fmt.Sprintf(" [Details](%s)", pj.Status.URL)
+	}
+
+	var errs []string
+	for _, pull := range refs.Pulls {
+		if err := ghc.CreateCommentWithContext(ctx, refs.Org, refs.Repo, pull.Number, comment); err != nil {
+			errs = append(errs, fmt.Sprintf("#%d: %v", pull.Number, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error creating comments: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 // ReportComment takes multiple prowjobs as input. When there are more than one
 // prowjob, they are required to have identical refs, aka they are the same repo
 // and the same pull request.
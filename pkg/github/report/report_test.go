@@ -724,3 +724,67 @@ func TestReportComment(t *testing.T) {
 		})
 	}
 }
+
+func TestReportBatchComment(t *testing.T) {
+	t.Parallel()
+	batch := func(state prowapi.ProwJobState, complete bool) prowapi.ProwJob {
+		pj := prowapi.ProwJob{
+			Spec: prowapi.ProwJobSpec{
+				Type:   prowapi.BatchJob,
+				Job:    "pull-unit",
+				Report: true,
+				Refs: &prowapi.Refs{
+					Org:     "org",
+					Repo:    "repo",
+					BaseSHA: "abcdef",
+					Pulls:   []prowapi.Pull{{Number: 1}, {Number: 2}},
+				},
+			},
+			Status: prowapi.ProwJobStatus{State: state, URL: "https://prow.example.com/view/1"},
+		}
+		if complete {
+			pj.Status.CompletionTime = &metav1.Time{}
+		}
+		return pj
+	}
+	testCases := []struct {
+		name             string
+		pj               prowapi.ProwJob
+		expectedComments []string
+	}{
+		{
+			name: "successful batch is reported on every PR",
+			pj:   batch(prowapi.SuccessState, true),
+			expectedComments: []string{
+				"The batch job `pull-unit` testing #1, #2 on top of abcdef succeeded. [Details](https://prow.example.com/view/1)",
+				"The batch job `pull-unit` testing #1, #2 on top of abcdef succeeded. [Details](https://prow.example.com/view/1)",
+			},
+		},
+		{
+			name: "failed batch is reported on every PR",
+			pj:   batch(prowapi.FailureState, true),
+			expectedComments: []string{
+				"The batch job `pull-unit` testing #1, #2 on top of abcdef **failure**. [Details](https://prow.example.com/view/1)",
+				"The batch job `pull-unit` testing #1, #2 on top of abcdef **failure**. [Details](https://prow.example.com/view/1)",
+			},
+		},
+		{
+			name: "running batch is not reported",
+			pj:   batch(prowapi.PendingState, false),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ghc := &fakeGhClient{}
+			if err := ReportBatchComment(context.Background(), ghc, tc.pj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedComments, ghc.comments); diff != "" {
+				t.Errorf("comments differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
+)
+
+// RetryPolicy configures how the client retries failing requests.
+type RetryPolicy struct {
+	// MaxRetries is how many times a request is attempted at most.
+	MaxRetries int
+	// Max404Retries is how many times a request that 404s is retried, as
+	// GitHub sometimes 404s for objects it just told us about.
+	Max404Retries int
+	// NoServerErrorRetries fails requests that 5XX right away.
+	NoServerErrorRetries bool
+	// NoTimeoutRetries fails requests that time out right away.
+	NoTimeoutRetries bool
+}
+
+// EndpointRetryPolicy overrides the retry policy for the requests to matching
+// endpoints. Zero fields are taken from the retry policy of the client.
+type EndpointRetryPolicy struct {
+	// Method of the requests to match, or empty to match all methods.
+	Method string
+	// Path of the requests to match, as a path.Match pattern, e.g.
+	// /repos/*/*/pulls/*/merge.
+	Path string
+	RetryPolicy
+}
+
+func (p EndpointRetryPolicy) matches(method, requestPath string) bool {
+	if p.Method != "" && !strings.EqualFold(p.Method, method) {
+		return false
+	}
+	requestPath, _, _ = strings.Cut(requestPath, "?")
+	matched, _ := path.Match(p.Path, requestPath)
+	return matched
+}
+
+// ParseEndpointRetryPolicy parses a retry policy override of the form
+// "[METHOD] PATH key=value,...", e.g.
+// "PUT /repos/*/*/pulls/*/merge max-retries=1,server-errors=false". The keys
+// are max-retries, max-404-retries, server-errors and timeouts.
+func ParseEndpointRetryPolicy(value string) (EndpointRetryPolicy, error) {
+	var policy EndpointRetryPolicy
+	fields := strings.Fields(value)
+	switch len(fields) {
+	case 2:
+		policy.Path = fields[0]
+	case 3:
+		policy.Method, policy.Path = strings.ToUpper(fields[0]), fields[1]
+	default:
+		return policy, fmt.Errorf("retry policy %q is not of the form \"[METHOD] PATH key=value,...\"", value)
+	}
+	if _, err := path.Match(policy.Path, ""); err != nil {
+		return policy, fmt.Errorf("invalid path pattern %q: %w", policy.Path, err)
+	}
+	for _, setting := range strings.Split(fields[len(fields)-1], ",") {
+		key, val, ok := strings.Cut(setting, "=")
+		if !ok {
+			return policy, fmt.Errorf("retry policy setting %q is not of the form key=value", setting)
+		}
+		var err error
+		switch key {
+		case "max-retries":
+			policy.MaxRetries, err = strconv.Atoi(val)
+		case "max-404-retries":
+			policy.Max404Retries, err = strconv.Atoi(val)
+		case "server-errors":
+			var retry bool
+			retry, err = strconv.ParseBool(val)
+			policy.NoServerErrorRetries = !retry
+		case "timeouts":
+			var retry bool
+			retry, err = strconv.ParseBool(val)
+			policy.NoTimeoutRetries = !retry
+		default:
+			return policy, fmt.Errorf("unknown retry policy setting %q", key)
+		}
+		if err != nil {
+			return policy, fmt.Errorf("invalid value for retry policy setting %q: %w", key, err)
+		}
+	}
+	if policy.MaxRetries < 0 || policy.Max404Retries < 0 {
+		return policy, errors.New("retries must not be negative")
+	}
+	return policy, nil
+}
+
+// retryPolicyFor returns the retry policy for a request, taking overrides for
+// its endpoint into account. The first matching override wins.
+func (c *client) retryPolicyFor(method, requestPath string) RetryPolicy {
+	policy := RetryPolicy{
+		MaxRetries:           c.maxRetries,
+		Max404Retries:        c.max404Retries,
+		NoServerErrorRetries: c.noServerErrorRetries,
+		NoTimeoutRetries:     c.noTimeoutRetries,
+	}
+	for _, override := range c.retryPolicies {
+		if !override.matches(method, requestPath) {
+			continue
+		}
+		if override.MaxRetries > 0 {
+			policy.MaxRetries = override.MaxRetries
+		}
+		if override.Max404Retries > 0 {
+			policy.Max404Retries = override.Max404Retries
+		}
+		policy.NoServerErrorRetries = policy.NoServerErrorRetries || override.NoServerErrorRetries
+		policy.NoTimeoutRetries = policy.NoTimeoutRetries || override.NoTimeoutRetries
+		break
+	}
+	return policy
+}
+
+// DefaultCircuitBreakerCooldown is how long requests fail fast by default once
+// the circuit breaker opens.
+const DefaultCircuitBreakerCooldown = time.Minute
+
+// ErrCircuitOpen is returned for requests that the circuit breaker fails fast
+// because GitHub kept failing.
+var ErrCircuitOpen = errors.New("circuit breaker open after consecutive GitHub failures")
+
+// CircuitBreakerOptions configure the circuit breaker of a client. Once
+// FailureThreshold requests in a row fail with a 5XX or a connection problem,
+// requests fail fast with ErrCircuitOpen for Cooldown. Then a single request
+// is let through to probe whether GitHub has recovered. A zero threshold
+// disables the circuit breaker.
+type CircuitBreakerOptions struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// Validate validates the circuit breaker options.
+func (o CircuitBreakerOptions) Validate() error {
+	if o.FailureThreshold < 0 {
+		return errors.New("the circuit breaker failure threshold must not be negative")
+	}
+	if o.FailureThreshold > 0 && o.Cooldown <= 0 {
+		return errors.New("the circuit breaker cooldown must be positive")
+	}
+	return nil
+}
+
+type circuitState struct {
+	failures  int
+	openUntil time.Time
+	// probing is set while the request probing an open circuit is in flight.
+	probing bool
+}
+
+// circuitBreaker tracks consecutive failures per endpoint, so that a client
+// with ghproxy in front of GitHub still falls back to GitHub when ghproxy
+// is down.
+type circuitBreaker struct {
+	options CircuitBreakerOptions
+
+	lock   sync.Mutex
+	states map[string]*circuitState
+}
+
+func newCircuitBreaker(options CircuitBreakerOptions) *circuitBreaker {
+	if options.FailureThreshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{options: options, states: map[string]*circuitState{}}
+}
+
+func (b *circuitBreaker) state(endpoint string) *circuitState {
+	if _, ok := b.states[endpoint]; !ok {
+		b.states[endpoint] = &circuitState{}
+	}
+	return b.states[endpoint]
+}
+
+// allow tells whether a request can be sent to an endpoint.
+func (b *circuitBreaker) allow(endpoint string, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	state := b.state(endpoint)
+	switch {
+	case state.failures < b.options.FailureThreshold:
+		return true
+	case now.Before(state.openUntil) || state.probing:
+		ghmetrics.CollectCircuitBreakerRejectedRequestMetrics(endpoint)
+		return false
+	}
+	state.probing = true
+	return true
+}
+
+// record updates the circuit of an endpoint with whether a request to it
+// failed.
+func (b *circuitBreaker) record(endpoint string, now time.Time, failed bool) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	state := b.state(endpoint)
+	state.probing = false
+	if !failed {
+		if state.failures >= b.options.FailureThreshold {
+			logrus.WithFields(logrus.Fields{"client": "github", "endpoint": endpoint}).Info("Closing circuit breaker, GitHub has recovered")
+			ghmetrics.CollectCircuitBreakerMetrics(endpoint, false)
+		}
+		state.failures = 0
+		return
+	}
+	state.failures++
+	if state.failures >= b.options.FailureThreshold {
+		if now.After(state.openUntil) {
+			logrus.WithFields(logrus.Fields{"client": "github", "endpoint": endpoint, "failures": state.failures}).Warn("Opening circuit breaker after consecutive GitHub failures")
+		}
+		state.openUntil = now.Add(b.options.Cooldown)
+		ghmetrics.CollectCircuitBreakerMetrics(endpoint, true)
+	}
+}
+
+// release lets another request probe an endpoint after one ended before
+// GitHub answered, e.g. because its context was canceled.
+func (b *circuitBreaker) release(endpoint string) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.state(endpoint).probing = false
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetryPolicyFor(t *testing.T) {
+	c := &client{delegate: &delegate{
+		maxRetries:    DefaultMaxRetries,
+		max404Retries: DefaultMax404Retries,
+		retryPolicies: []EndpointRetryPolicy{
+			{Method: http.MethodPut, Path: "/repos/*/*/pulls/*/merge", RetryPolicy: RetryPolicy{MaxRetries: 1}},
+			{Path: "/repos/*/*/pulls/*/merge", RetryPolicy: RetryPolicy{NoServerErrorRetries: true}},
+			{Path: "/search/*", RetryPolicy: RetryPolicy{Max404Retries: 5, NoTimeoutRetries: true}},
+		},
+	}}
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		expected RetryPolicy
+	}{
+		{
+			name:     "no matching override",
+			method:   http.MethodGet,
+			path:     "/repos/org/repo/pulls/1",
+			expected: RetryPolicy{MaxRetries: DefaultMaxRetries, Max404Retries: DefaultMax404Retries},
+		},
+		{
+			name:     "first matching override wins",
+			method:   http.MethodPut,
+			path:     "/repos/org/repo/pulls/1/merge",
+			expected: RetryPolicy{MaxRetries: 1, Max404Retries: DefaultMax404Retries},
+		},
+		{
+			name:     "override for another method does not match",
+			method:   http.MethodGet,
+			path:     "/repos/org/repo/pulls/1/merge",
+			expected: RetryPolicy{MaxRetries: DefaultMaxRetries, Max404Retries: DefaultMax404Retries, NoServerErrorRetries: true},
+		},
+		{
+			name:     "query is ignored",
+			method:   http.MethodGet,
+			path:     "/search/issues?q=is:pr",
+			expected: RetryPolicy{MaxRetries: DefaultMaxRetries, Max404Retries: 5, NoTimeoutRetries: true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, c.retryPolicyFor(tc.method, tc.path)); diff != "" {
+				t.Errorf("unexpected retry policy: %s", diff)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	if b := newCircuitBreaker(CircuitBreakerOptions{}); b != nil || !b.allow("endpoint", time.Now()) {
+		t.Fatal("expected a zero threshold to disable the circuit breaker")
+	}
+
+	now := time.Unix(1000, 0)
+	b := newCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 2, Cooldown: time.Minute})
+	b.record("endpoint", now, true)
+	b.record("endpoint", now, false)
+	b.record("endpoint", now, true)
+	if !b.allow("endpoint", now) {
+		t.Error("expected a passing request to reset the failures")
+	}
+	b.record("endpoint", now, true)
+	if b.allow("endpoint", now) {
+		t.Error("expected the circuit to open after consecutive failures")
+	}
+	if !b.allow("other-endpoint", now) {
+		t.Error("expected other endpoints not to be affected")
+	}
+
+	later := now.Add(time.Minute)
+	if !b.allow("endpoint", later) {
+		t.Error("expected a request to probe the endpoint after the cooldown")
+	}
+	if b.allow("endpoint", later) {
+		t.Error("expected only a single request to probe the endpoint")
+	}
+	b.record("endpoint", later, true)
+	if b.allow("endpoint", later.Add(time.Second)) {
+		t.Error("expected a failing probe to open the circuit again")
+	}
+
+	evenLater := later.Add(time.Minute)
+	if !b.allow("endpoint", evenLater) {
+		t.Error("expected a request to probe the endpoint after the cooldown")
+	}
+	b.release("endpoint")
+	if !b.allow("endpoint", evenLater) {
+		t.Error("expected another request to probe the endpoint after the probe was released")
+	}
+	b.record("endpoint", evenLater, false)
+	if !b.allow("endpoint", evenLater) || !b.allow("endpoint", evenLater) {
+		t.Error("expected a passing probe to close the circuit")
+	}
+}
+
+func TestRequestRetryNoServerErrorRetries(t *testing.T) {
+	var requests int
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "502 Bad Gateway", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	c.retryPolicies = []EndpointRetryPolicy{{Path: "/no-retries", RetryPolicy: RetryPolicy{NoServerErrorRetries: true}}}
+
+	resp, err := c.requestRetry(http.MethodGet, "/no-retries", "", "", nil)
+	if err != nil {
+		t.Fatalf("Error from request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || requests != 1 {
+		t.Errorf("Expected a single request that 502s, got %d requests and status %d", requests, resp.StatusCode)
+	}
+
+	requests = 0
+	resp, err = c.requestRetry(http.MethodGet, "/retries", "", "", nil)
+	if err != nil {
+		t.Fatalf("Error from request: %v", err)
+	}
+	resp.Body.Close()
+	if requests != DefaultMaxRetries {
+		t.Errorf("Expected %d requests for an endpoint without an override, got %d", DefaultMaxRetries, requests)
+	}
+}
+
+func TestRequestRetryCircuitBreaker(t *testing.T) {
+	var requests int
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "503 Service Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	c.time = &testTime{now: time.Now()}
+	c.breaker = newCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 3, Cooldown: time.Minute})
+
+	if _, err := c.requestRetry(http.MethodGet, "/", "", "", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected the circuit breaker to stop retrying, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests before the circuit opened, got %d", requests)
+	}
+	if _, err := c.requestRetry(http.MethodGet, "/other", "", "", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected other requests to fail fast, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected no requests while the circuit is open, got %d", requests-3)
+	}
+}
+
+func TestRequestRetryTimeouts(t *testing.T) {
+	var requests int
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	c.client.(*ghThrottler).http.(*http.Client).Timeout = 10 * time.Millisecond
+	c.maxRetries = 3
+	c.retryPolicies = []EndpointRetryPolicy{{Path: "/no-retries", RetryPolicy: RetryPolicy{NoTimeoutRetries: true}}}
+
+	if _, err := c.requestRetry(http.MethodGet, "/retries", "", "", nil); err == nil {
+		t.Fatal("Expected an error for a request that times out")
+	}
+	if requests != 3 {
+		t.Errorf("Expected requests that time out to be retried, got %d requests", requests)
+	}
+
+	requests = 0
+	if _, err := c.requestRetry(http.MethodGet, "/no-retries", "", "", nil); err == nil {
+		t.Fatal("Expected an error for a request that times out")
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single request, got %d", requests)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// secondaryRateLimitBackoff is how long to wait after a secondary rate
+	// limit that doesn't say how long to wait, doubled for every consecutive one.
+	// GitHub asks to wait at least a minute.
+	secondaryRateLimitBackoff    = time.Minute
+	maxSecondaryRateLimitBackoff = time.Hour
+)
+
+// errSecondaryRateLimited is returned for requests that would have to wait
+// longer than the max sleep time for a secondary rate limit to pass.
+var errSecondaryRateLimited = errors.New("blocked by a secondary rate limit")
+
+var secondaryRateLimitMessages = [][]byte{
+	[]byte("secondary rate limit"),
+	[]byte("abuse detection"),
+}
+
+// isSecondaryRateLimit tells whether a response is a secondary (formerly
+// abuse) rate limit. Unlike the primary rate limit, the token has quota left.
+// If the body is read, it is replaced so that it can be read again.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+	default:
+		return false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return false
+	}
+	if rawTime := resp.Header.Get("Retry-After"); rawTime != "" && rawTime != "0" {
+		return true
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	body = bytes.ToLower(body)
+	for _, message := range secondaryRateLimitMessages {
+		if bytes.Contains(body, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of a response, or returns zero.
+func retryAfter(resp *http.Response) (time.Duration, error) {
+	rawTime := resp.Header.Get("Retry-After")
+	if rawTime == "" || rawTime == "0" {
+		return 0, nil
+	}
+	t, err := strconv.Atoi(rawTime)
+	if err != nil {
+		return 0, err
+	}
+	// Wait an extra second plus how long GitHub wants us to wait.
+	return time.Duration(t+1) * time.Second, nil
+}
+
+type secondaryRateLimitState struct {
+	blockedUntil time.Time
+	// hits counts consecutive secondary rate limits.
+	hits int
+}
+
+// secondaryRateLimiter blocks all requests made with a token once one of them
+// hits a secondary rate limit, rather than letting every goroutine find out
+// on its own and make the situation worse. With apps auth every installation
+// has its own token, so requests are blocked per org.
+type secondaryRateLimiter struct {
+	perOrg bool
+
+	lock   sync.Mutex
+	states map[string]*secondaryRateLimitState
+}
+
+func newSecondaryRateLimiter(perOrg bool) *secondaryRateLimiter {
+	return &secondaryRateLimiter{perOrg: perOrg, states: map[string]*secondaryRateLimitState{}}
+}
+
+func (s *secondaryRateLimiter) state(org string) *secondaryRateLimitState {
+	if !s.perOrg {
+		org = ""
+	}
+	if _, ok := s.states[org]; !ok {
+		s.states[org] = &secondaryRateLimitState{}
+	}
+	return s.states[org]
+}
+
+// hit records a secondary rate limit and returns how long requests are blocked
+// for. If GitHub didn't say how long to wait, it backs off exponentially.
+func (s *secondaryRateLimiter) hit(org string, now time.Time, wait time.Duration) time.Duration {
+	if s == nil {
+		return wait
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	state := s.state(org)
+	if wait == 0 {
+		wait = secondaryRateLimitBackoff << state.hits
+		if wait > maxSecondaryRateLimitBackoff || wait <= 0 {
+			wait = maxSecondaryRateLimitBackoff
+		}
+	}
+	state.hits++
+	if until := now.Add(wait); until.After(state.blockedUntil) {
+		state.blockedUntil = until
+	}
+	return state.blockedUntil.Sub(now)
+}
+
+// reset forgets about consecutive secondary rate limits once a request passes.
+func (s *secondaryRateLimiter) reset(org string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.state(org).hits = 0
+}
+
+// blockedFor returns how long requests have to wait for a secondary rate
+// limit to pass, jittered so that they don't all resume at once.
+func (s *secondaryRateLimiter) blockedFor(org string, now time.Time) time.Duration {
+	if s == nil {
+		return 0
+	}
+	s.lock.Lock()
+	wait := s.state(org).blockedUntil.Sub(now)
+	s.lock.Unlock()
+	if wait <= 0 {
+		return 0
+	}
+	return wait + time.Duration(rand.Int63n(int64(wait)/10+1))
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsSecondaryRateLimit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		header   map[string]string
+		body     string
+		expected bool
+	}{
+		{
+			name:     "too many requests",
+			status:   http.StatusTooManyRequests,
+			expected: true,
+		},
+		{
+			name:     "forbidden with retry after",
+			status:   http.StatusForbidden,
+			header:   map[string]string{"Retry-After": "30"},
+			expected: true,
+		},
+		{
+			name:     "forbidden with secondary rate limit message",
+			status:   http.StatusForbidden,
+			body:     `{"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`,
+			expected: true,
+		},
+		{
+			name:     "forbidden with abuse detection message",
+			status:   http.StatusForbidden,
+			body:     `{"message": "You have triggered an abuse detection mechanism."}`,
+			expected: true,
+		},
+		{
+			name:   "primary rate limit",
+			status: http.StatusForbidden,
+			header: map[string]string{"X-RateLimit-Remaining": "0", "Retry-After": "30"},
+		},
+		{
+			name:   "missing permissions",
+			status: http.StatusForbidden,
+			body:   `{"message": "Resource not accessible by integration"}`,
+		},
+		{
+			name:   "ok",
+			status: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(tc.body))}
+			for k, v := range tc.header {
+				resp.Header.Set(k, v)
+			}
+			if actual := isSecondaryRateLimit(resp); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+			if body, _ := io.ReadAll(resp.Body); string(body) != tc.body {
+				t.Errorf("expected the body to stay readable, got %q", string(body))
+			}
+		})
+	}
+}
+
+func TestSecondaryRateLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newSecondaryRateLimiter(true)
+	if wait := s.blockedFor("org", now); wait != 0 {
+		t.Errorf("expected no wait before any secondary rate limit, got %v", wait)
+	}
+
+	if wait := s.hit("org", now, 0); wait != time.Minute {
+		t.Errorf("expected to back off a minute, got %v", wait)
+	}
+	if wait := s.hit("org", now, 0); wait != 2*time.Minute {
+		t.Errorf("expected to back off exponentially, got %v", wait)
+	}
+	if wait := s.hit("org", now, 10*time.Second); wait != 2*time.Minute {
+		t.Errorf("expected a shorter retry after not to shorten the wait, got %v", wait)
+	}
+	if wait := s.blockedFor("org", now); wait < 2*time.Minute || wait > 2*time.Minute+12*time.Second {
+		t.Errorf("expected to wait two minutes with jitter, got %v", wait)
+	}
+	if wait := s.blockedFor("other-org", now); wait != 0 {
+		t.Errorf("expected other orgs not to be blocked with apps auth, got %v", wait)
+	}
+
+	s.reset("org")
+	later := now.Add(time.Hour)
+	if wait := s.hit("org", later, 0); wait != time.Minute {
+		t.Errorf("expected the backoff to start over after a passing request, got %v", wait)
+	}
+
+	shared := newSecondaryRateLimiter(false)
+	shared.hit("org", now, time.Minute)
+	if wait := shared.blockedFor("other-org", now); wait < time.Minute {
+		t.Errorf("expected all orgs to be blocked with a token, got %v", wait)
+	}
+}
+
+func TestSecondaryRateLimitBlocksOtherRequests(t *testing.T) {
+	tc := &testTime{now: time.Now()}
+	var requests []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path == "/limited" && len(requests) == 1 {
+			http.Error(w, `{"message": "You have exceeded a secondary rate limit."}`, http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	c.time = tc
+	c.maxSleepTime = 10 * time.Minute
+
+	resp, err := c.requestRetry(http.MethodGet, "/limited", "", "", nil)
+	if err != nil {
+		t.Fatalf("Error from request: %v", err)
+	} else if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", resp.StatusCode)
+	}
+	if tc.slept < time.Minute {
+		t.Errorf("Expected to wait at least a minute without a Retry-After header, got %v", tc.slept)
+	}
+
+	tc.slept = 0
+	if _, err := c.requestRetry(http.MethodGet, "/other", "", "", nil); err != nil {
+		t.Fatalf("Error from request: %v", err)
+	}
+	if tc.slept < time.Minute {
+		t.Errorf("Expected other requests to wait for the secondary rate limit too, got %v", tc.slept)
+	}
+	if len(requests) != 3 {
+		t.Errorf("Expected three requests, got %v", requests)
+	}
+}
+
+func TestSecondaryRateLimitExceedsMaxSleepTime(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "600")
+		http.Error(w, "429 Too Many Requests", http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	c.time = &testTime{now: time.Now()}
+	if _, err := c.requestRetry(http.MethodGet, "/", "", "", nil); err == nil {
+		t.Fatal("Expected an error for a secondary rate limit longer than the max sleep time")
+	}
+	if _, err := c.requestRetry(http.MethodGet, "/other", "", "", nil); err == nil || !strings.Contains(err.Error(), errSecondaryRateLimited.Error()) {
+		t.Errorf("Expected other requests to fail fast, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// TokenType is the kind of credentials a client authenticates with, which
+// determines how missing permissions have to be fixed.
+type TokenType string
+
+const (
+	// TokenTypeClassic is a classic personal access token, scoped by OAuth scopes.
+	TokenTypeClassic TokenType = "classic personal access token"
+	// TokenTypeFineGrained is a fine-grained personal access token, scoped by
+	// permissions per org and repo.
+	TokenTypeFineGrained TokenType = "fine-grained personal access token"
+	// TokenTypeAppInstallation is the installation token of a GitHub app.
+	TokenTypeAppInstallation TokenType = "GitHub app installation"
+	// TokenTypeOAuth is a token of a user of an OAuth or GitHub app.
+	TokenTypeOAuth TokenType = "OAuth token"
+	// TokenTypeUnknown is used for anonymous clients and unknown formats.
+	TokenTypeUnknown TokenType = "unknown token"
+)
+
+// DetectTokenType tells the type of a token by its prefix.
+//
+// See https://github.blog/2021-04-05-behind-githubs-new-authentication-token-formats/
+func DetectTokenType(token string) TokenType {
+	token = strings.TrimSpace(token)
+	switch {
+	case strings.HasPrefix(token, "github_pat_"):
+		return TokenTypeFineGrained
+	case strings.HasPrefix(token, "ghp_"):
+		return TokenTypeClassic
+	case strings.HasPrefix(token, "ghs_"):
+		return TokenTypeAppInstallation
+	case strings.HasPrefix(token, "gho_"), strings.HasPrefix(token, "ghu_"):
+		return TokenTypeOAuth
+	case len(token) == 40 && strings.Trim(token, "0123456789abcdef") == "":
+		// Tokens created before the prefixes were introduced are classic tokens.
+		return TokenTypeClassic
+	}
+	return TokenTypeUnknown
+}
+
+// TokenType returns the type of the token the client authenticates with.
+func (c *client) TokenType() TokenType {
+	if c.usesAppsAuth {
+		return TokenTypeAppInstallation
+	}
+	if c.getToken == nil {
+		return TokenTypeUnknown
+	}
+	return DetectTokenType(string(c.getToken()))
+}
+
+// PermissionError is returned for requests that GitHub refused because the
+// token lacks a scope or permission.
+type PermissionError struct {
+	TokenType TokenType
+	// Required is what GitHub says the request needs, if it does: OAuth scopes
+	// for classic tokens, permissions for fine-grained tokens and apps.
+	Required string
+	message  string
+}
+
+func (e *PermissionError) Error() string {
+	return e.message
+}
+
+// newPermissionError explains a 403 response that isn't a rate limit.
+func (c *client) newPermissionError(resp *http.Response) *PermissionError {
+	err := &PermissionError{TokenType: c.TokenType()}
+	acceptedScopes := resp.Header.Get("X-Accepted-OAuth-Scopes")
+	authorizedScopes := resp.Header.Get("X-OAuth-Scopes")
+	if authorizedScopes == "" {
+		authorizedScopes = "no"
+	}
+	want := sets.New[string]()
+	for _, acceptedScope := range strings.Split(acceptedScopes, ",") {
+		want.Insert(strings.TrimSpace(acceptedScope))
+	}
+	var got []string
+	for _, authorizedScope := range strings.Split(authorizedScopes, ",") {
+		got = append(got, strings.TrimSpace(authorizedScope))
+	}
+
+	// Fine-grained tokens and apps are told which permissions they lack.
+	if permissions := resp.Header.Get("X-Accepted-GitHub-Permissions"); permissions != "" && err.TokenType != TokenTypeClassic {
+		err.Required = permissions
+		err.message = fmt.Sprintf("the %s lacks permissions, please grant it the following permissions: %s", err.TokenType, permissions)
+	} else if acceptedScopes != "" && !want.HasAny(got...) {
+		err.Required = acceptedScopes
+		err.message = fmt.Sprintf("the account is using %s oauth scopes, please make sure you are using at least one of the following oauth scopes: %s", authorizedScopes, acceptedScopes)
+	} else {
+		body, _ := io.ReadAll(resp.Body)
+		err.message = fmt.Sprintf("the GitHub API request returns a 403 error: %s", string(body))
+	}
+	return err
+}
+
+// TokenCapability is something a component needs its token to be able to do,
+// which can be probed for before relying on it.
+type TokenCapability struct {
+	// Name tells what the capability is needed for.
+	Name string
+	// path is requested to probe for the capability in an org.
+	path string
+	// classicScope is the OAuth scope a classic token needs.
+	classicScope string
+	// permission is the permission a fine-grained token or app needs.
+	permission string
+}
+
+var (
+	// CapabilityOrgMembers is needed to list the members of an org. Listing
+	// them succeeds without read:org but only returns the public members, so
+	// it is probed with the teams of the org, which require the same scope.
+	CapabilityOrgMembers = TokenCapability{Name: "org member queries", path: "/orgs/%s/teams", classicScope: "read:org", permission: "members:read"}
+	// CapabilityTeams is needed to list the teams of an org.
+	CapabilityTeams = TokenCapability{Name: "team queries", path: "/orgs/%s/teams", classicScope: "read:org", permission: "members:read"}
+	// CapabilityOrgRepos is needed to list the private repos of an org.
+	CapabilityOrgRepos = TokenCapability{Name: "repo queries", path: "/orgs/%s/repos", classicScope: "repo", permission: "metadata:read"}
+)
+
+// TokenCapabilityError tells which scope or permission a token lacks, in
+// the terms of its type.
+type TokenCapabilityError struct {
+	TokenType  TokenType
+	Capability TokenCapability
+	Org        string
+	Err        error
+}
+
+func (e *TokenCapabilityError) Error() string {
+	if e.TokenType == TokenTypeClassic {
+		return fmt.Sprintf("%s lacks the %s scope for %s in org %s: %v", e.TokenType, e.Capability.classicScope, e.Capability.Name, e.Org, e.Err)
+	}
+	return fmt.Sprintf("%s lacks the %s permission for %s in org %s: %v", e.TokenType, e.Capability.permission, e.Capability.Name, e.Org, e.Err)
+}
+
+func (e *TokenCapabilityError) Unwrap() error {
+	return e.Err
+}
+
+// ProbeCapabilities checks that the token of the client can do what a component
+// needs to in an org, so that it can fail early with an actionable error
+// rather than on a 403 deep in its logic.
+//
+// Each capability consumes one API token.
+func (c *client) ProbeCapabilities(org string, capabilities ...TokenCapability) error {
+	c.log("ProbeCapabilities", org, capabilities)
+	if c.fake {
+		return nil
+	}
+	var errs []error
+	for _, capability := range capabilities {
+		if capability.path == "" {
+			errs = append(errs, fmt.Errorf("unknown capability %q, use one of the Capability* variables", capability.Name))
+			continue
+		}
+		resp, err := c.requestRetry(http.MethodGet, fmt.Sprintf(capability.path, org)+"?per_page=1", acceptNone, org, nil)
+		if err != nil {
+			var permissionErr *PermissionError
+			if errors.As(err, &permissionErr) {
+				err = &TokenCapabilityError{TokenType: permissionErr.TokenType, Capability: capability, Org: org, Err: err}
+			}
+			errs = append(errs, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			errs = append(errs, fmt.Errorf("failed to probe for %s in org %s: return code not 2XX: %s", capability.Name, org, resp.Status))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -681,6 +681,86 @@ type RestrictionsRequest struct {
 	Teams *[]string `json:"teams,omitempty"`
 }
 
+// Ruleset is a repository ruleset, the successor of branch protection.
+// See also: https://docs.github.com/en/rest/repos/rules
+type Ruleset struct {
+	ID           int                  `json:"id,omitempty"`
+	Name         string               `json:"name"`
+	Target       string               `json:"target,omitempty"`
+	Enforcement  string               `json:"enforcement"`
+	BypassActors []RulesetBypassActor `json:"bypass_actors,omitempty"`
+	Conditions   *RulesetConditions   `json:"conditions,omitempty"`
+	Rules        []RulesetRule        `json:"rules,omitempty"`
+}
+
+// Targets and enforcement levels of rulesets.
+const (
+	RulesetTargetBranch        = "branch"
+	RulesetEnforcementActive   = "active"
+	RulesetEnforcementEvaluate = "evaluate"
+)
+
+// Types of actors that can bypass rulesets.
+const (
+	RulesetActorIntegration       = "Integration"
+	RulesetActorOrganizationAdmin = "OrganizationAdmin"
+	RulesetActorRepositoryRole    = "RepositoryRole"
+	RulesetActorTeam              = "Team"
+)
+
+// RulesetBypassActor is allowed to bypass the rules of a ruleset.
+type RulesetBypassActor struct {
+	ActorID    int    `json:"actor_id"`
+	ActorType  string `json:"actor_type"`
+	BypassMode string `json:"bypass_mode"`
+}
+
+// RulesetConditions selects the refs a ruleset applies to.
+type RulesetConditions struct {
+	RefName RulesetRefNameCondition `json:"ref_name"`
+}
+
+// RulesetRefNameCondition includes and excludes refs by name or pattern.
+type RulesetRefNameCondition struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// Types of ruleset rules.
+const (
+	RuleDeletion              = "deletion"
+	RuleNonFastForward        = "non_fast_forward"
+	RuleRequiredLinearHistory = "required_linear_history"
+	RuleRequiredSignatures    = "required_signatures"
+	RulePullRequest           = "pull_request"
+	RuleRequiredStatusChecks  = "required_status_checks"
+)
+
+// RulesetRule is a single rule of a ruleset. Only the rules of types
+// pull_request and required_status_checks have parameters.
+type RulesetRule struct {
+	Type       string                 `json:"type"`
+	Parameters *RulesetRuleParameters `json:"parameters,omitempty"`
+}
+
+// RulesetRuleParameters holds the parameters of the pull_request and the
+// required_status_checks rules.
+type RulesetRuleParameters struct {
+	RequiredApprovingReviewCount   *int                 `json:"required_approving_review_count,omitempty"`
+	DismissStaleReviewsOnPush      *bool                `json:"dismiss_stale_reviews_on_push,omitempty"`
+	RequireCodeOwnerReview         *bool                `json:"require_code_owner_review,omitempty"`
+	RequireLastPushApproval        *bool                `json:"require_last_push_approval,omitempty"`
+	RequiredReviewThreadResolution *bool                `json:"required_review_thread_resolution,omitempty"`
+	RequiredStatusChecks           []RulesetStatusCheck `json:"required_status_checks,omitempty"`
+	StrictRequiredStatusChecks     *bool                `json:"strict_required_status_checks_policy,omitempty"`
+}
+
+// RulesetStatusCheck is a context required by the required_status_checks rule.
+type RulesetStatusCheck struct {
+	Context       string `json:"context"`
+	IntegrationID *int   `json:"integration_id,omitempty"`
+}
+
 // HookConfig holds the endpoint and its secret.
 type HookConfig struct {
 	URL         string  `json:"url"`
@@ -1417,11 +1497,45 @@ type ProjectCard struct {
 	ContentURL  string `json:"content_url"`
 }
 
+// Check run statuses.
+const (
+	CheckRunStatusQueued     = "queued"
+	CheckRunStatusInProgress = "in_progress"
+	CheckRunStatusCompleted  = "completed"
+)
+
+// Check run and check suite conclusions.
+const (
+	CheckRunConclusionSuccess        = "success"
+	CheckRunConclusionFailure        = "failure"
+	CheckRunConclusionNeutral        = "neutral"
+	CheckRunConclusionCancelled      = "cancelled"
+	CheckRunConclusionSkipped        = "skipped"
+	CheckRunConclusionTimedOut       = "timed_out"
+	CheckRunConclusionActionRequired = "action_required"
+)
+
+// Check run annotation levels.
+const (
+	CheckRunAnnotationLevelNotice  = "notice"
+	CheckRunAnnotationLevelWarning = "warning"
+	CheckRunAnnotationLevelFailure = "failure"
+)
+
+// MaxCheckRunAnnotations is the number of annotations GitHub takes per
+// request to create or update a check run.
+const MaxCheckRunAnnotations = 50
+
 type CheckRunList struct {
 	Total     int        `json:"total_count,omitempty"`
 	CheckRuns []CheckRun `json:"check_runs,omitempty"`
 }
 
+type CheckSuiteList struct {
+	Total       int          `json:"total_count,omitempty"`
+	CheckSuites []CheckSuite `json:"check_suites,omitempty"`
+}
+
 type CheckRun struct {
 	ID           int64          `json:"id,omitempty"`
 	NodeID       string         `json:"node_id,omitempty"`
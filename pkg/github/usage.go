@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/prow/pkg/ghcache"
+	"sigs.k8s.io/prow/pkg/github/ghmetrics"
+	"sigs.k8s.io/prow/pkg/version"
+)
+
+// accountRESTUsage attributes a REST request that got a response to the
+// component and caller that sent it and to the repo or org it was about, so
+// that the callers exhausting the rate limit of a shared token can be found.
+// Responses GitHub or a cache answered without charging the rate limit cost
+// nothing.
+func (c *client) accountRESTUsage(path, org string, resp *http.Response) {
+	pathOrg, repo, _ := repoTarget(path)
+	if pathOrg != "" {
+		org = pathOrg
+	}
+	ghmetrics.CollectAPIUsageMetrics(version.Name, c.identifier, org, repo, "rest", restRateLimitPoints(resp))
+}
+
+func restRateLimitPoints(resp *http.Response) int {
+	if resp.StatusCode == http.StatusNotModified || ghcache.CacheModeIsFree(ghcache.CacheResponseMode(resp.Header.Get(ghcache.CacheModeHeader))) {
+		return 0
+	}
+	return 1
+}
+
+// accountGraphQLUsage attributes a GraphQL query or mutation to the component
+// and caller that sent it and to its org. Queries that ask for their rate
+// limit cost it, any other costs a single point.
+func (c *client) accountGraphQLUsage(q interface{}, org string) {
+	ghmetrics.CollectAPIUsageMetrics(version.Name, c.identifier, org, "", "graphql", graphQLRateLimitPoints(q))
+}
+
+func graphQLRateLimitPoints(q interface{}) int {
+	if query, ok := q.(interface{ GraphQLRateLimit() GraphQLRateLimit }); ok {
+		if cost := int(query.GraphQLRateLimit().Cost); cost > 0 {
+			return cost
+		}
+	}
+	return 1
+}
+
+// repoTarget returns the org, repo and issue or pull request number a REST
+// path is about, e.g. /repos/org/repo/pulls/1/merge, as far as it names them.
+func repoTarget(path string) (org, repo string, number int) {
+	parts := strings.Split(strings.Trim(strings.SplitN(path, "?", 2)[0], "/"), "/")
+	if len(parts) < 3 || parts[0] != "repos" {
+		return "", "", 0
+	}
+	if len(parts) >= 5 && (parts[3] == "issues" || parts[3] == "pulls") {
+		number, _ = strconv.Atoi(parts[4])
+	}
+	return parts[1], parts[2], number
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"net/http"
+	"testing"
+
+	"sigs.k8s.io/prow/pkg/ghcache"
+)
+
+func TestRepoTarget(t *testing.T) {
+	testcases := []struct {
+		path           string
+		expectedOrg    string
+		expectedRepo   string
+		expectedNumber int
+	}{
+		{path: "/repos/org/repo/pulls/1/merge", expectedOrg: "org", expectedRepo: "repo", expectedNumber: 1},
+		{path: "/repos/org/repo/issues/12/labels?per_page=100", expectedOrg: "org", expectedRepo: "repo", expectedNumber: 12},
+		{path: "/repos/org/repo/statuses/abcdef", expectedOrg: "org", expectedRepo: "repo"},
+		{path: "/repos/org/repo/issues/comments/5", expectedOrg: "org", expectedRepo: "repo"},
+		{path: "/orgs/org/members"},
+		{path: "/user"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			org, repo, number := repoTarget(tc.path)
+			if org != tc.expectedOrg || repo != tc.expectedRepo || number != tc.expectedNumber {
+				t.Errorf("expected %s/%s#%d, got %s/%s#%d", tc.expectedOrg, tc.expectedRepo, tc.expectedNumber, org, repo, number)
+			}
+		})
+	}
+}
+
+func TestRESTRateLimitPoints(t *testing.T) {
+	testcases := []struct {
+		name     string
+		code     int
+		mode     ghcache.CacheResponseMode
+		expected int
+	}{
+		{name: "request answered by GitHub", code: http.StatusOK, expected: 1},
+		{name: "request failed by GitHub", code: http.StatusNotFound, expected: 1},
+		{name: "response not modified", code: http.StatusNotModified},
+		{name: "response revalidated by ghproxy", code: http.StatusOK, mode: ghcache.ModeRevalidated},
+		{name: "response changed behind ghproxy", code: http.StatusOK, mode: ghcache.ModeChanged, expected: 1},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.code, Header: http.Header{}}
+			if tc.mode != "" {
+				resp.Header.Set(ghcache.CacheModeHeader, string(tc.mode))
+			}
+			if points := restRateLimitPoints(resp); points != tc.expected {
+				t.Errorf("expected %d points, got %d", tc.expected, points)
+			}
+		})
+	}
+}
+
+func TestGraphQLRateLimitPoints(t *testing.T) {
+	var plain struct{}
+	if points := graphQLRateLimitPoints(&plain); points != 1 {
+		t.Errorf("expected a query without its rate limit to cost 1 point, got %d", points)
+	}
+	q := &CommitContextsQuery{}
+	if points := graphQLRateLimitPoints(q); points != 1 {
+		t.Errorf("expected a query without a reported cost to cost 1 point, got %d", points)
+	}
+	q.RateLimit.Cost = 3
+	if points := graphQLRateLimitPoints(q); points != 3 {
+		t.Errorf("expected the reported cost of 3 points, got %d", points)
+	}
+}
@@ -41,6 +41,18 @@ var (
 		Name: "prow_plugin_handle_errors",
 		Help: "Prow errors handling an event by plugin, event type and action.",
 	}, []string{"event_type", "action", "plugin", "took_action"})
+	droppedEventCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prow_webhook_dropped_events",
+		Help: "A counter of the webhooks dropped by the event filter by event type and reason.",
+	}, []string{"event_type", "reason"})
+	throttledCommands = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prow_webhook_throttled_commands",
+		Help: "A counter of the comments with commands that were not handled because of the command rate limit by org and repo.",
+	}, []string{"org", "repo"})
+	rateLimitedUsers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prow_webhook_command_rate_limited_users",
+		Help: "A counter of the times a user exceeded the command rate limit and was temporarily ignored by org and repo.",
+	}, []string{"org", "repo"})
 )
 
 func init() {
@@ -48,6 +60,9 @@ func init() {
 	prometheus.MustRegister(responseCounter)
 	prometheus.MustRegister(pluginHandleDuration)
 	prometheus.MustRegister(pluginHandleErrors)
+	prometheus.MustRegister(droppedEventCounter)
+	prometheus.MustRegister(throttledCommands)
+	prometheus.MustRegister(rateLimitedUsers)
 }
 
 // Metrics is a set of metrics gathered by hook.
@@ -56,6 +71,9 @@ type Metrics struct {
 	ResponseCounter      *prometheus.CounterVec
 	PluginHandleDuration *prometheus.HistogramVec
 	PluginHandleErrors   *prometheus.CounterVec
+	DroppedEventCounter  *prometheus.CounterVec
+	ThrottledCommands    *prometheus.CounterVec
+	RateLimitedUsers     *prometheus.CounterVec
 	*plugins.Metrics
 }
 
@@ -73,6 +91,9 @@ func NewMetrics() *Metrics {
 		ResponseCounter:      responseCounter,
 		PluginHandleDuration: pluginHandleDuration,
 		PluginHandleErrors:   pluginHandleErrors,
+		DroppedEventCounter:  droppedEventCounter,
+		ThrottledCommands:    throttledCommands,
+		RateLimitedUsers:     rateLimitedUsers,
 		Metrics:              plugins.NewMetrics(),
 	}
 }
@@ -0,0 +1,293 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/version"
+)
+
+// Client interacts with the GitLab REST API (v4).
+type Client interface {
+	// BotUser returns the user the client authenticates as.
+	BotUser() (*User, error)
+	// CreateMergeRequestNote adds a comment to a merge request.
+	CreateMergeRequestNote(projectID, iid int, body string) error
+	// AddMergeRequestLabels adds labels to a merge request.
+	AddMergeRequestLabels(projectID, iid int, labels ...string) error
+	// RemoveMergeRequestLabels removes labels from a merge request.
+	RemoveMergeRequestLabels(projectID, iid int, labels ...string) error
+	// GetMergeRequestChanges lists the files changed by a merge request.
+	GetMergeRequestChanges(projectID, iid int) ([]MergeRequestChange, error)
+	// GetBranchHead returns the SHA the branch currently points to.
+	GetBranchHead(projectID int, branch string) (string, error)
+	// GetProjectMember returns the effective membership of a user in a
+	// project, or nil if the user is not a member.
+	GetProjectMember(projectID, userID int) (*ProjectMember, error)
+	// CreateCommitStatus creates or updates the status with the given name
+	// on a commit.
+	CreateCommitStatus(projectID int, sha string, status CommitStatus) error
+	// ListCommitStatuses lists the latest statuses on a commit.
+	ListCommitStatuses(projectID int, sha string) ([]CommitStatus, error)
+}
+
+type client struct {
+	logger         *logrus.Entry
+	endpoint       string
+	tokenGenerator func() []byte
+	dryRun         bool
+	client         *http.Client
+
+	mut     sync.Mutex
+	botUser *User
+}
+
+const maxRetries = 3
+
+// NewClient creates a client for the GitLab instance at endpoint, e.g.
+// https://gitlab.com, authenticating with the personal, group or project
+// access token returned by tokenGenerator.
+func NewClient(endpoint string, tokenGenerator func() []byte) Client {
+	return &client{
+		logger:         logrus.WithField("client", "gitlab"),
+		endpoint:       strings.TrimSuffix(endpoint, "/") + "/api/v4",
+		tokenGenerator: tokenGenerator,
+		client:         &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// NewDryRunClient creates a client that only performs read operations and
+// logs every mutation it would have made.
+func NewDryRunClient(endpoint string, tokenGenerator func() []byte) Client {
+	c := NewClient(endpoint, tokenGenerator).(*client)
+	c.dryRun = true
+	return c
+}
+
+// requestError is returned for requests that completed with an unexpected
+// status code.
+type requestError struct {
+	method, path string
+	statusCode   int
+	body         string
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d: %s", e.method, e.path, e.statusCode, e.body)
+}
+
+// IsNotFound returns whether the error was caused by a 404 response.
+func IsNotFound(err error) bool {
+	var reqErr *requestError
+	return errors.As(err, &reqErr) && reqErr.statusCode == http.StatusNotFound
+}
+
+// request performs a request against the API and decodes the response into
+// ret if it is non-nil. It returns the response headers for pagination.
+func (c *client) request(method, path string, body, ret interface{}) (http.Header, error) {
+	if c.dryRun && method != http.MethodGet {
+		c.logger.WithFields(logrus.Fields{"method": method, "path": path}).Info("Dry run, not sending request.")
+		return http.Header{}, nil
+	}
+	var payload []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = b
+	}
+
+	var resp *http.Response
+	var err error
+	backoff := time.Second
+	for retries := 0; retries < maxRetries; retries++ {
+		var req *http.Request
+		req, err = http.NewRequest(method, c.endpoint+path, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", string(c.tokenGenerator()))
+		req.Header.Set("User-Agent", version.UserAgentWithIdentifier("gitlab"))
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err = c.client.Do(req)
+		if err == nil && resp.StatusCode < 500 {
+			break
+		}
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &requestError{method: method, path: path, statusCode: resp.StatusCode, body: string(b)}
+	}
+	if ret != nil {
+		if err := json.Unmarshal(b, ret); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response of %s %s: %w", method, path, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// getPaged follows the X-Next-Page header and calls accumulate with a fresh
+// page decoded by newObj.
+func (c *client) getPaged(path string, newObj func() interface{}, accumulate func(interface{})) error {
+	page := "1"
+	for page != "" {
+		separator := "?"
+		if strings.Contains(path, "?") {
+			separator = "&"
+		}
+		obj := newObj()
+		header, err := c.request(http.MethodGet, fmt.Sprintf("%s%sper_page=100&page=%s", path, separator, page), nil, obj)
+		if err != nil {
+			return err
+		}
+		accumulate(obj)
+		page = header.Get("X-Next-Page")
+	}
+	return nil
+}
+
+func projectPath(projectID int) string {
+	return "/projects/" + strconv.Itoa(projectID)
+}
+
+func (c *client) BotUser() (*User, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.botUser != nil {
+		return c.botUser, nil
+	}
+	var user User
+	if _, err := c.request(http.MethodGet, "/user", nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to get bot user: %w", err)
+	}
+	c.botUser = &user
+	return c.botUser, nil
+}
+
+func (c *client) CreateMergeRequestNote(projectID, iid int, body string) error {
+	c.logger.WithFields(logrus.Fields{"project": projectID, "iid": iid}).Debug("CreateMergeRequestNote")
+	path := fmt.Sprintf("%s/merge_requests/%d/notes", projectPath(projectID), iid)
+	_, err := c.request(http.MethodPost, path, map[string]string{"body": body}, nil)
+	return err
+}
+
+func (c *client) AddMergeRequestLabels(projectID, iid int, labels ...string) error {
+	c.logger.WithFields(logrus.Fields{"project": projectID, "iid": iid, "labels": labels}).Debug("AddMergeRequestLabels")
+	path := fmt.Sprintf("%s/merge_requests/%d", projectPath(projectID), iid)
+	_, err := c.request(http.MethodPut, path, map[string]string{"add_labels": strings.Join(labels, ",")}, nil)
+	return err
+}
+
+func (c *client) RemoveMergeRequestLabels(projectID, iid int, labels ...string) error {
+	c.logger.WithFields(logrus.Fields{"project": projectID, "iid": iid, "labels": labels}).Debug("RemoveMergeRequestLabels")
+	path := fmt.Sprintf("%s/merge_requests/%d", projectPath(projectID), iid)
+	_, err := c.request(http.MethodPut, path, map[string]string{"remove_labels": strings.Join(labels, ",")}, nil)
+	return err
+}
+
+func (c *client) GetMergeRequestChanges(projectID, iid int) ([]MergeRequestChange, error) {
+	var mr struct {
+		Changes []MergeRequestChange `json:"changes"`
+	}
+	path := fmt.Sprintf("%s/merge_requests/%d/changes", projectPath(projectID), iid)
+	if _, err := c.request(http.MethodGet, path, nil, &mr); err != nil {
+		return nil, err
+	}
+	return mr.Changes, nil
+}
+
+func (c *client) GetBranchHead(projectID int, branch string) (string, error) {
+	var b struct {
+		Commit Commit `json:"commit"`
+	}
+	path := fmt.Sprintf("%s/repository/branches/%s", projectPath(projectID), url.PathEscape(branch))
+	if _, err := c.request(http.MethodGet, path, nil, &b); err != nil {
+		return "", err
+	}
+	return b.Commit.ID, nil
+}
+
+func (c *client) GetProjectMember(projectID, userID int) (*ProjectMember, error) {
+	var member ProjectMember
+	path := fmt.Sprintf("%s/members/all/%d", projectPath(projectID), userID)
+	if _, err := c.request(http.MethodGet, path, nil, &member); err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (c *client) CreateCommitStatus(projectID int, sha string, status CommitStatus) error {
+	c.logger.WithFields(logrus.Fields{"project": projectID, "sha": sha, "name": status.Name, "state": status.State}).Debug("CreateCommitStatus")
+	body := map[string]string{
+		"state":       string(status.State),
+		"name":        status.Name,
+		"target_url":  status.TargetURL,
+		"description": status.Description,
+	}
+	if status.Ref != "" {
+		body["ref"] = status.Ref
+	}
+	_, err := c.request(http.MethodPost, fmt.Sprintf("%s/statuses/%s", projectPath(projectID), sha), body, nil)
+	return err
+}
+
+func (c *client) ListCommitStatuses(projectID int, sha string) ([]CommitStatus, error) {
+	var statuses []CommitStatus
+	path := fmt.Sprintf("%s/repository/commits/%s/statuses", projectPath(projectID), sha)
+	err := c.getPaged(path, func() interface{} {
+		return &[]CommitStatus{}
+	}, func(obj interface{}) {
+		statuses = append(statuses, *(obj.(*[]CommitStatus))...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
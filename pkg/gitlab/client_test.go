@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, func() []byte { return []byte("token") })
+}
+
+func TestCreateCommitStatus(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v4/projects/42/statuses/abc" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if token := r.Header.Get("PRIVATE-TOKEN"); token != "token" {
+			t.Errorf("unexpected token %q", token)
+		}
+		b, _ := io.ReadAll(r.Body)
+		var body map[string]string
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Fatalf("failed to unmarshal body: %v", err)
+		}
+		expected := map[string]string{"state": "success", "name": "unit", "target_url": "https://prow/job", "description": "Job succeeded."}
+		if diff := cmp.Diff(expected, body); diff != "" {
+			t.Errorf("unexpected body: %s", diff)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "{}")
+	})
+	err := c.CreateCommitStatus(42, "abc", CommitStatus{Name: "unit", State: CommitStateSuccess, TargetURL: "https://prow/job", Description: "Job succeeded."})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestListCommitStatusesPaginates(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch page := r.URL.Query().Get("page"); page {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"name":"a","status":"failed"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"name":"b","status":"success"}]`)
+		default:
+			t.Errorf("unexpected page %q", page)
+		}
+	})
+	statuses, err := c.ListCommitStatuses(42, "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []CommitStatus{{Name: "a", State: CommitStateFailed}, {Name: "b", State: CommitStateSuccess}}
+	if diff := cmp.Diff(expected, statuses); diff != "" {
+		t.Errorf("unexpected statuses: %s", diff)
+	}
+}
+
+func TestGetProjectMember(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/projects/42/members/all/7":
+			fmt.Fprint(w, `{"id":7,"username":"dev","access_level":30}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Not found"}`)
+		}
+	})
+	member, err := c.GetProjectMember(42, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if member == nil || member.AccessLevel != AccessLevelDeveloper || member.Username != "dev" {
+		t.Errorf("unexpected member: %+v", member)
+	}
+	member, err = c.GetProjectMember(42, 8)
+	if err != nil {
+		t.Fatalf("unexpected error for non-member: %v", err)
+	}
+	if member != nil {
+		t.Errorf("expected no member, got %+v", member)
+	}
+}
+
+func TestDryRunClientSkipsMutations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+	c := NewDryRunClient(server.URL, func() []byte { return nil })
+	if err := c.CreateMergeRequestNote(1, 2, "hello"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
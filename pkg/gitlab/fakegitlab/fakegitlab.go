@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakegitlab provides a fake implementation of the GitLab client.
+package fakegitlab
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/gitlab"
+)
+
+// FakeClient is an in-memory gitlab.Client.
+type FakeClient struct {
+	lock sync.RWMutex
+
+	Bot gitlab.User
+	// Members maps project IDs to user IDs to access levels.
+	Members map[int]map[int]int
+	// BranchHeads maps "projectID/branch" to a SHA.
+	BranchHeads map[string]string
+	// Changes maps "projectID!iid" to the files changed by a merge request.
+	Changes map[string][]gitlab.MergeRequestChange
+	// Notes maps "projectID!iid" to the comments created on a merge request.
+	Notes map[string][]string
+	// Labels maps "projectID!iid" to the labels on a merge request.
+	Labels map[string]sets.Set[string]
+	// Statuses maps "projectID@sha" to the commit statuses on a commit.
+	Statuses map[string][]gitlab.CommitStatus
+}
+
+var _ gitlab.Client = &FakeClient{}
+
+// NewFakeClient returns an initialized fake client.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		Bot:         gitlab.User{ID: 1, Username: "prow-bot"},
+		Members:     map[int]map[int]int{},
+		BranchHeads: map[string]string{},
+		Changes:     map[string][]gitlab.MergeRequestChange{},
+		Notes:       map[string][]string{},
+		Labels:      map[string]sets.Set[string]{},
+		Statuses:    map[string][]gitlab.CommitStatus{},
+	}
+}
+
+// MergeRequestKey builds the key used for merge request scoped fields.
+func MergeRequestKey(projectID, iid int) string {
+	return fmt.Sprintf("%d!%d", projectID, iid)
+}
+
+// CommitKey builds the key used for commit scoped fields.
+func CommitKey(projectID int, sha string) string {
+	return fmt.Sprintf("%d@%s", projectID, sha)
+}
+
+func (f *FakeClient) BotUser() (*gitlab.User, error) {
+	bot := f.Bot
+	return &bot, nil
+}
+
+func (f *FakeClient) CreateMergeRequestNote(projectID, iid int, body string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	key := MergeRequestKey(projectID, iid)
+	f.Notes[key] = append(f.Notes[key], body)
+	return nil
+}
+
+func (f *FakeClient) AddMergeRequestLabels(projectID, iid int, labels ...string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	key := MergeRequestKey(projectID, iid)
+	if f.Labels[key] == nil {
+		f.Labels[key] = sets.New[string]()
+	}
+	f.Labels[key].Insert(labels...)
+	return nil
+}
+
+func (f *FakeClient) RemoveMergeRequestLabels(projectID, iid int, labels ...string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if existing := f.Labels[MergeRequestKey(projectID, iid)]; existing != nil {
+		existing.Delete(labels...)
+	}
+	return nil
+}
+
+func (f *FakeClient) GetMergeRequestChanges(projectID, iid int) ([]gitlab.MergeRequestChange, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.Changes[MergeRequestKey(projectID, iid)], nil
+}
+
+func (f *FakeClient) GetBranchHead(projectID int, branch string) (string, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	sha, ok := f.BranchHeads[fmt.Sprintf("%d/%s", projectID, branch)]
+	if !ok {
+		return "", fmt.Errorf("branch %s not found in project %d", branch, projectID)
+	}
+	return sha, nil
+}
+
+func (f *FakeClient) GetProjectMember(projectID, userID int) (*gitlab.ProjectMember, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	level, ok := f.Members[projectID][userID]
+	if !ok {
+		return nil, nil
+	}
+	return &gitlab.ProjectMember{User: gitlab.User{ID: userID}, AccessLevel: level}, nil
+}
+
+func (f *FakeClient) CreateCommitStatus(projectID int, sha string, status gitlab.CommitStatus) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	key := CommitKey(projectID, sha)
+	for i, existing := range f.Statuses[key] {
+		if existing.Name == status.Name {
+			f.Statuses[key][i] = status
+			return nil
+		}
+	}
+	f.Statuses[key] = append(f.Statuses[key], status)
+	return nil
+}
+
+func (f *FakeClient) ListCommitStatuses(projectID int, sha string) ([]gitlab.CommitStatus, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.Statuses[CommitKey(projectID, sha)], nil
+}
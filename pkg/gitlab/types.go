@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gitlab contains webhook types and a minimal API client for GitLab.
+package gitlab
+
+import (
+	"path"
+	"strings"
+)
+
+// Webhook headers sent by GitLab.
+const (
+	EventHeader = "X-Gitlab-Event"
+	TokenHeader = "X-Gitlab-Token"
+	UUIDHeader  = "X-Gitlab-Event-UUID"
+)
+
+// Values of the X-Gitlab-Event header that Prow understands.
+const (
+	MergeRequestHook = "Merge Request Hook"
+	NoteHook         = "Note Hook"
+	PushHook         = "Push Hook"
+)
+
+// MergeRequestAction is the action of a merge request webhook.
+type MergeRequestAction string
+
+// Possible values for MergeRequestAction.
+const (
+	MergeRequestActionOpen   MergeRequestAction = "open"
+	MergeRequestActionReopen MergeRequestAction = "reopen"
+	MergeRequestActionUpdate MergeRequestAction = "update"
+	MergeRequestActionClose  MergeRequestAction = "close"
+	MergeRequestActionMerge  MergeRequestAction = "merge"
+)
+
+// NoteableTypeMergeRequest is the noteable type of a comment on a merge request.
+const NoteableTypeMergeRequest = "MergeRequest"
+
+// AccessLevelDeveloper is the minimum project access level considered trusted.
+const AccessLevelDeveloper = 30
+
+// User is a GitLab user as it appears in webhooks and API responses.
+type User struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	WebURL   string `json:"web_url,omitempty"`
+}
+
+// Project is a GitLab project as it appears in webhooks.
+type Project struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	WebURL            string `json:"web_url"`
+	GitHTTPURL        string `json:"git_http_url"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+}
+
+// OrgRepo splits the full path of a project into the namespace, which Prow
+// treats as the org, and the project path, which Prow treats as the repo.
+// Nested groups stay part of the org, e.g. "group/subgroup" and "project".
+func (p Project) OrgRepo() (string, string) {
+	return path.Dir(p.PathWithNamespace), path.Base(p.PathWithNamespace)
+}
+
+// Host returns the scheme and host of the GitLab instance serving the project.
+func (p Project) Host() string {
+	return strings.TrimSuffix(p.WebURL, "/"+p.PathWithNamespace)
+}
+
+// Commit is a commit as it appears in webhooks.
+type Commit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	URL     string `json:"url"`
+}
+
+// Label is a label as it appears in webhooks.
+type Label struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// MergeRequest holds the attributes of a merge request shared by merge request
+// and note webhooks.
+type MergeRequest struct {
+	ID              int                `json:"id"`
+	IID             int                `json:"iid"`
+	Title           string             `json:"title"`
+	Description     string             `json:"description"`
+	State           string             `json:"state"`
+	Action          MergeRequestAction `json:"action,omitempty"`
+	URL             string             `json:"url"`
+	SourceBranch    string             `json:"source_branch"`
+	TargetBranch    string             `json:"target_branch"`
+	SourceProjectID int                `json:"source_project_id"`
+	TargetProjectID int                `json:"target_project_id"`
+	AuthorID        int                `json:"author_id"`
+	Draft           bool               `json:"draft"`
+	WorkInProgress  bool               `json:"work_in_progress"`
+	LastCommit      Commit             `json:"last_commit"`
+	Labels          []Label            `json:"labels,omitempty"`
+	// OldRev is only set on update actions that pushed new commits.
+	OldRev string `json:"oldrev,omitempty"`
+}
+
+// IsDraft returns whether the merge request is marked as a draft.
+func (mr MergeRequest) IsDraft() bool {
+	return mr.Draft || mr.WorkInProgress
+}
+
+// MergeRequestEvent is sent for the Merge Request Hook.
+type MergeRequestEvent struct {
+	ObjectKind       string       `json:"object_kind"`
+	User             User         `json:"user"`
+	Project          Project      `json:"project"`
+	ObjectAttributes MergeRequest `json:"object_attributes"`
+	Labels           []Label      `json:"labels"`
+
+	// GUID is the value of the X-Gitlab-Event-UUID header, it is not part of
+	// the payload.
+	GUID string `json:"-"`
+}
+
+// Note is a comment as it appears in webhooks.
+type Note struct {
+	ID           int    `json:"id"`
+	Note         string `json:"note"`
+	NoteableType string `json:"noteable_type"`
+	AuthorID     int    `json:"author_id"`
+	URL          string `json:"url"`
+}
+
+// NoteEvent is sent for the Note Hook.
+type NoteEvent struct {
+	ObjectKind       string        `json:"object_kind"`
+	User             User          `json:"user"`
+	Project          Project       `json:"project"`
+	ObjectAttributes Note          `json:"object_attributes"`
+	MergeRequest     *MergeRequest `json:"merge_request,omitempty"`
+
+	GUID string `json:"-"`
+}
+
+// CommitState is the state of a commit status.
+type CommitState string
+
+// Possible values for CommitState.
+const (
+	CommitStatePending  CommitState = "pending"
+	CommitStateRunning  CommitState = "running"
+	CommitStateSuccess  CommitState = "success"
+	CommitStateFailed   CommitState = "failed"
+	CommitStateCanceled CommitState = "canceled"
+)
+
+// CommitStatus is an external status on a commit, shown as a job in the
+// merge request pipeline widget.
+type CommitStatus struct {
+	Name        string      `json:"name"`
+	State       CommitState `json:"status"`
+	TargetURL   string      `json:"target_url,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Ref         string      `json:"ref,omitempty"`
+}
+
+// MergeRequestChange is a single file changed by a merge request.
+type MergeRequestChange struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// ProjectMember is a member of a project, including inherited membership.
+type ProjectMember struct {
+	User
+	AccessLevel int `json:"access_level"`
+}
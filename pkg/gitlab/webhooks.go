@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ValidateWebhook ensures that the provided request conforms to the
+// format of a GitLab webhook and that the secret token matches the one
+// returned by tokenGenerator. GitLab does not sign payloads, it sends the
+// configured secret verbatim in the X-Gitlab-Token header.
+// If it is, it returns the event type, the event UUID, and the payload of
+// the webhook along with the http status code to respond with.
+func ValidateWebhook(w http.ResponseWriter, r *http.Request, tokenGenerator func() []byte) (string, string, []byte, bool, int) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		responseHTTPError(w, http.StatusMethodNotAllowed, "405 Method not allowed")
+		return "", "", nil, false, http.StatusMethodNotAllowed
+	}
+	eventType := r.Header.Get(EventHeader)
+	if eventType == "" {
+		responseHTTPError(w, http.StatusBadRequest, "400 Bad Request: Missing X-Gitlab-Event Header")
+		return "", "", nil, false, http.StatusBadRequest
+	}
+	token := r.Header.Get(TokenHeader)
+	if token == "" {
+		responseHTTPError(w, http.StatusForbidden, "403 Forbidden: Missing X-Gitlab-Token")
+		return "", "", nil, false, http.StatusForbidden
+	}
+	if subtle.ConstantTimeCompare([]byte(token), tokenGenerator()) != 1 {
+		responseHTTPError(w, http.StatusForbidden, "403 Forbidden: Invalid X-Gitlab-Token")
+		return "", "", nil, false, http.StatusForbidden
+	}
+	if contentType := r.Header.Get("content-type"); contentType != "application/json" {
+		responseHTTPError(w, http.StatusBadRequest, "400 Bad Request: Hook only accepts content-type: application/json")
+		return "", "", nil, false, http.StatusBadRequest
+	}
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		responseHTTPError(w, http.StatusInternalServerError, "500 Internal Server Error: Failed to read request body")
+		return "", "", nil, false, http.StatusInternalServerError
+	}
+
+	// Older GitLab versions don't send a UUID, it is only used for logging.
+	return eventType, r.Header.Get(UUIDHeader), payload, true, http.StatusOK
+}
+
+func responseHTTPError(w http.ResponseWriter, statusCode int, response string) {
+	logrus.WithFields(logrus.Fields{
+		"response":    response,
+		"status-code": statusCode,
+	}).Debug(response)
+	http.Error(w, response, statusCode)
+}
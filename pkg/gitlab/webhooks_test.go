@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlab
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateWebhook(t *testing.T) {
+	tokenGenerator := func() []byte { return []byte("secret") }
+	testCases := []struct {
+		name         string
+		method       string
+		headers      map[string]string
+		expectedOK   bool
+		expectedCode int
+	}{
+		{
+			name:   "valid webhook",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventHeader:    NoteHook,
+				TokenHeader:    "secret",
+				UUIDHeader:     "some-uuid",
+				"content-type": "application/json",
+			},
+			expectedOK:   true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "valid webhook without uuid",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventHeader:    NoteHook,
+				TokenHeader:    "secret",
+				"content-type": "application/json",
+			},
+			expectedOK:   true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "wrong method",
+			method:       http.MethodGet,
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "missing event",
+			method: http.MethodPost,
+			headers: map[string]string{
+				TokenHeader:    "secret",
+				"content-type": "application/json",
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "missing token",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventHeader:    NoteHook,
+				"content-type": "application/json",
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:   "wrong token",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventHeader:    NoteHook,
+				TokenHeader:    "not-the-secret",
+				"content-type": "application/json",
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:   "wrong content type",
+			method: http.MethodPost,
+			headers: map[string]string{
+				EventHeader:    NoteHook,
+				TokenHeader:    "secret",
+				"content-type": "application/x-www-form-urlencoded",
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/gitlab-hook", bytes.NewBufferString(`{"object_kind":"note"}`))
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			eventType, _, payload, ok, code := ValidateWebhook(w, req, tokenGenerator)
+			if ok != tc.expectedOK {
+				t.Errorf("expected ok=%t, got %t", tc.expectedOK, ok)
+			}
+			if code != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, code)
+			}
+			if ok && (eventType != NoteHook || len(payload) == 0) {
+				t.Errorf("unexpected event type %q or payload %q", eventType, string(payload))
+			}
+		})
+	}
+}
+
+func TestProjectOrgRepo(t *testing.T) {
+	testCases := []struct {
+		path, org, repo string
+	}{
+		{path: "group/project", org: "group", repo: "project"},
+		{path: "group/subgroup/project", org: "group/subgroup", repo: "project"},
+	}
+	for _, tc := range testCases {
+		p := Project{PathWithNamespace: tc.path, WebURL: "https://gitlab.example.com/" + tc.path}
+		org, repo := p.OrgRepo()
+		if org != tc.org || repo != tc.repo {
+			t.Errorf("%s: expected %s/%s, got %s/%s", tc.path, tc.org, tc.repo, org, repo)
+		}
+		if host := p.Host(); host != "https://gitlab.example.com" {
+			t.Errorf("%s: unexpected host %s", tc.path, host)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/plugins"
+	"sigs.k8s.io/prow/pkg/tracing"
+)
+
+// storageAuditSink writes every audit record as a JSON object to a storage
+// bucket or local directory, partitioned by day so that compliance reviews
+// can query a time range by listing its prefixes.
+type storageAuditSink struct {
+	opener io.Opener
+	base   string
+	seq    atomic.Uint64
+}
+
+// NewStorageAuditSink returns an AuditSink that writes records below base,
+// which may be a gs:// or s3:// path or a local directory.
+func NewStorageAuditSink(opener io.Opener, base string) github.AuditSink {
+	return &storageAuditSink{opener: opener, base: strings.TrimSuffix(base, "/")}
+}
+
+func (s *storageAuditSink) Record(r github.AuditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	action := r.Action
+	if action == "" {
+		action = r.Method
+	}
+	// The sequence number keeps records of the same action on the same event
+	// apart, e.g. when a plugin adds several labels.
+	name := fmt.Sprintf("%s/%s/%s-%s-%s-%d.json", s.base, r.Time.UTC().Format("2006-01-02"), r.Time.UTC().Format("150405.000000000"), r.Caller, action, s.seq.Add(1))
+	return io.WriteContent(context.Background(), logrus.WithField("audit-record", name), s.opener, name, b)
+}
+
+// httpAuditSink posts every audit record as JSON to an HTTP endpoint, e.g. the
+// ingestion endpoint of a log management system.
+type httpAuditSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPAuditSink returns an AuditSink that posts records to url.
+func NewHTTPAuditSink(url string) github.AuditSink {
+	return &httpAuditSink{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *httpAuditSink) Record(r github.AuditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to post audit record: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("posting audit record returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newAgent returns the agent a plugin handles an event with. The write actions
+// of the agent are recorded if the server has an audit sink.
+func (s *Server) newAgent(l *logrus.Entry, org, plugin, actor string) plugins.Agent {
+	agent := plugins.NewAgent(s.ConfigAgent, s.Plugins, s.ClientAgent, org, s.Metrics.Metrics, l, plugin)
+	if agent.ProwJobClient != nil {
+		agent.ProwJobClient = tracing.ProwJobClient(l.Context, agent.ProwJobClient)
+	}
+	if s.AuditSink != nil {
+		eventType, _ := l.Data[eventTypeField].(string)
+		eventGUID, _ := l.Data[github.EventGUID].(string)
+		agent.EnableAudit(s.AuditSink, plugin, plugins.AuditTrigger{EventType: eventType, EventGUID: eventGUID, Actor: actor})
+	}
+	return agent
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/bugzilla"
+	prowfake "sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/githubeventserver"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/plugins"
+	"sigs.k8s.io/prow/pkg/plugins/ownersconfig"
+	"sigs.k8s.io/prow/pkg/repoowners"
+	"sigs.k8s.io/prow/pkg/tracing"
+)
+
+func TestStorageAuditSink(t *testing.T) {
+	dir := t.TempDir()
+	opener, err := io.NewOpener(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("failed to create opener: %v", err)
+	}
+	sink := NewStorageAuditSink(opener, dir+"/")
+	entry := github.AuditRecord{
+		Time:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Caller: "lgtm",
+		Action: "AddLabel",
+		Org:    "org",
+		Repo:   "repo",
+		Number: 1,
+		Target: "lgtm",
+	}
+	for i := 0; i < 2; i++ {
+		if err := sink.Record(entry); err != nil {
+			t.Fatalf("failed to record entry: %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "2024-05-01", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list entries: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 entries to be written, got %v", files)
+	}
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+	var got github.AuditRecord
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("failed to unmarshal entry: %v", err)
+	}
+	if diff := cmp.Diff(entry, got); diff != "" {
+		t.Errorf("entry differs from expected (-want +got):\n%s", diff)
+	}
+}
+
+func TestHTTPAuditSink(t *testing.T) {
+	var got []github.AuditRecord
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e github.AuditRecord
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("failed to decode entry: %v", err)
+		}
+		got = append(got, e)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPAuditSink(server.URL)
+	entry := github.AuditRecord{Caller: "hold", Action: "RemoveLabel", Target: "do-not-merge/hold"}
+	if err := sink.Record(entry); err != nil {
+		t.Fatalf("failed to record entry: %v", err)
+	}
+	if diff := cmp.Diff([]github.AuditRecord{entry}, got); diff != "" {
+		t.Errorf("entries differ from expected (-want +got):\n%s", diff)
+	}
+
+	status = http.StatusInternalServerError
+	if err := sink.Record(entry); err == nil {
+		t.Error("expected error when the endpoint fails")
+	}
+}
+
+func TestNewAgentTracesProwJobs(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	pa := &plugins.ConfigAgent{}
+	pa.Set(&plugins.Configuration{})
+	ca := &config.Agent{}
+	ca.Set(&config.Config{})
+	s := &Server{
+		ConfigAgent: ca,
+		Plugins:     pa,
+		Metrics:     githubeventserver.NewMetrics(),
+		ClientAgent: &plugins.ClientAgent{
+			GitHubClient:   github.NewFakeClient(),
+			ProwJobClient:  prowfake.NewSimpleClientset().ProwV1().ProwJobs("prowjobs"),
+			OwnersClient:   repoowners.NewClient(nil, nil, func(org, repo string) bool { return false }, func(org, repo string) bool { return false }, func() *config.OwnersDirDenylist { return &config.OwnersDirDenylist{} }, ownersconfig.FakeResolver, nil),
+			BugzillaClient: &bugzilla.Fake{},
+		},
+	}
+
+	for _, traced := range []bool{true, false} {
+		l := logrus.WithField(eventTypeField, "pull_request")
+		if traced {
+			ctx, span := tracing.Tracer().Start(context.Background(), "webhook")
+			defer span.End()
+			l = l.WithContext(ctx)
+		}
+		agent := s.newAgent(l, "org", "trigger", "user")
+		pj, err := agent.ProwJobClient.Create(context.Background(), &prowapi.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("traced-%t", traced)}}, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("failed to create prowjob: %v", err)
+		}
+		if _, ok := pj.Annotations[tracing.AnnotationPrefix+"traceparent"]; ok != traced {
+			t.Errorf("expected the trace context in the annotations of the prowjob to be %t, got annotations %v", traced, pj.Annotations)
+		}
+	}
+}
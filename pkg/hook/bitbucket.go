@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/githubeventserver"
+	"sigs.k8s.io/prow/pkg/plugins"
+	"sigs.k8s.io/prow/pkg/plugins/lgtm"
+	"sigs.k8s.io/prow/pkg/plugins/trigger"
+)
+
+// Event types used to label metrics for Bitbucket webhooks.
+const (
+	bitbucketPullRequestEventType = "bitbucket_pull_request"
+	bitbucketCommentEventType     = "bitbucket_comment"
+)
+
+// BitbucketServer implements http.Handler. It validates incoming Bitbucket
+// webhooks, triggers presubmits for repositories that have the trigger plugin
+// enabled and handles /lgtm for repositories that have the lgtm plugin
+// enabled. Bitbucket repositories are configured like GitHub repos, using the
+// project key or the Cloud workspace as org, e.g. "PRJ/repo".
+type BitbucketServer struct {
+	ClientAgent     *plugins.ClientAgent
+	BitbucketClient bitbucket.Client
+	Plugins         *plugins.ConfigAgent
+	ConfigAgent     *config.Agent
+	TokenGenerator  func() []byte
+	Metrics         *githubeventserver.Metrics
+	RepoEnabled     func(org, repo string) bool
+	// Cloud is whether the webhooks are sent by Bitbucket Cloud rather than
+	// Bitbucket Server.
+	Cloud bool
+
+	// Tracks running handlers for graceful shutdown
+	wg sync.WaitGroup
+}
+
+// ServeHTTP validates an incoming webhook and handles it asynchronously.
+func (s *BitbucketServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	eventKey, eventGUID, payload, ok, resp := bitbucket.ValidateWebhook(w, r, s.TokenGenerator)
+	if counter, err := s.Metrics.ResponseCounter.GetMetricWithLabelValues(strconv.Itoa(resp)); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"status-code": resp,
+		}).WithError(err).Error("Failed to get metric for reporting webhook status code")
+	} else {
+		counter.Inc()
+	}
+
+	if !ok {
+		return
+	}
+	fmt.Fprint(w, "Event received. Have a nice day.")
+
+	if err := s.demuxEvent(eventKey, eventGUID, payload); err != nil {
+		logrus.WithError(err).Error("Error parsing event.")
+	}
+}
+
+func (s *BitbucketServer) demuxEvent(eventKey, eventGUID string, payload []byte) error {
+	l := logrus.WithFields(
+		logrus.Fields{
+			eventTypeField:   eventKey,
+			github.EventGUID: eventGUID,
+		},
+	)
+	var pre bitbucket.PullRequestEvent
+	switch {
+	case eventKey == bitbucket.EventKeyPing:
+		l.Debug("Received Bitbucket ping.")
+		return nil
+	case s.Cloud && (eventKey == bitbucket.CloudEventKeyPullRequestCreated || eventKey == bitbucket.CloudEventKeyPullRequestUpdated || eventKey == bitbucket.CloudEventKeyPullRequestCommentCreated):
+		e, err := bitbucket.ParseCloudPullRequestEvent(eventKey, payload)
+		if err != nil {
+			return err
+		}
+		pre = *e
+	case !s.Cloud && (eventKey == bitbucket.EventKeyPullRequestOpened || eventKey == bitbucket.EventKeyPullRequestFromRefUpdated || eventKey == bitbucket.EventKeyPullRequestComment):
+		if err := json.Unmarshal(payload, &pre); err != nil {
+			return err
+		}
+		if pre.EventKey == "" {
+			pre.EventKey = eventKey
+		}
+	default:
+		l.Debug("Ignoring unhandled Bitbucket event type.")
+		return nil
+	}
+	pre.GUID = eventGUID
+	if pre.EventKey == bitbucket.EventKeyPullRequestComment {
+		countWebhook(s.Metrics, l, bitbucketCommentEventType)
+	} else {
+		countWebhook(s.Metrics, l, bitbucketPullRequestEventType)
+	}
+
+	repo := pre.PullRequest.ToRef.Repository
+	if !s.RepoEnabled(repo.Project.Key, repo.Slug) {
+		return nil
+	}
+	handlers := s.Plugins.GenericCommentHandlers(repo.Project.Key, repo.Slug)
+	_, triggerEnabled := handlers[trigger.PluginName]
+	_, lgtmEnabled := handlers[lgtm.PluginName]
+	lgtmEnabled = lgtmEnabled && pre.EventKey == bitbucket.EventKeyPullRequestComment
+	if triggerEnabled || lgtmEnabled {
+		s.wg.Add(1)
+		go s.handlePullRequestEvent(l, pre, triggerEnabled, lgtmEnabled)
+	}
+	return nil
+}
+
+func (s *BitbucketServer) handlePullRequestEvent(l *logrus.Entry, pre bitbucket.PullRequestEvent, triggerEnabled, lgtmEnabled bool) {
+	defer s.wg.Done()
+	repo := pre.PullRequest.ToRef.Repository
+	l = l.WithFields(logrus.Fields{
+		github.OrgLogField:  repo.Project.Key,
+		github.RepoLogField: repo.Slug,
+		github.PrLogField:   pre.PullRequest.ID,
+		"actor":             pre.Actor.Name,
+		"url":               pre.PullRequest.WebURL(),
+	})
+	l.Infof("Pull request event %s.", pre.EventKey)
+	if pre.PullRequest.GitRef() == "" {
+		l.Info("Skipping pull request from a fork, which Bitbucket Cloud does not expose in the repository.")
+		return
+	}
+	if s.Cloud {
+		// Bitbucket Cloud webhooks only contain the abbreviated hash of the
+		// head commit.
+		sha, err := s.BitbucketClient.ResolveCommit(repo.Project.Key, repo.Slug, pre.PullRequest.FromRef.LatestCommit)
+		if err != nil {
+			l.WithError(err).Error("Failed to resolve the head commit of the pull request.")
+			return
+		}
+		pre.PullRequest.FromRef.LatestCommit = sha
+	}
+
+	if lgtmEnabled {
+		c := lgtm.BitbucketClient{
+			BitbucketClient: s.BitbucketClient,
+			Logger:          l.WithField("plugin", lgtm.PluginName),
+		}
+		if err := lgtm.HandleBitbucketComment(c, pre); err != nil {
+			l.WithError(err).Error("Error handling /lgtm on Bitbucket pull request.")
+		}
+	}
+	if !triggerEnabled {
+		return
+	}
+	c := trigger.BitbucketClient{
+		BitbucketClient: s.BitbucketClient,
+		ProwJobClient:   s.ClientAgent.ProwJobClient,
+		Config:          s.ConfigAgent.Config(),
+		Logger:          l,
+	}
+	triggerConfig := s.Plugins.Config().TriggerFor(repo.Project.Key, repo.Slug)
+	observeTrigger(s.Metrics, l, pre.EventKey, func() error {
+		if pre.EventKey == bitbucket.EventKeyPullRequestComment {
+			return trigger.HandleBitbucketComment(c, triggerConfig, pre)
+		}
+		return trigger.HandleBitbucketPullRequest(c, triggerConfig, pre)
+	})
+}
+
+// GracefulShutdown implements a graceful shutdown protocol. It handles all requests sent before
+// receiving the shutdown signal.
+func (s *BitbucketServer) GracefulShutdown() {
+	s.wg.Wait() // Handle remaining requests
+}
@@ -0,0 +1,453 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdio "io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/providers"
+)
+
+// replayHeader is set on replayed events so that external plugins can tell
+// them apart from events delivered by GitHub.
+const replayHeader = "X-Prow-Replay"
+
+// StoredEvent is a webhook event that passed HMAC validation.
+type StoredEvent struct {
+	GUID      string      `json:"guid"`
+	EventType string      `json:"event_type"`
+	OrgRepo   string      `json:"org_repo,omitempty"`
+	Received  time.Time   `json:"received"`
+	Header    http.Header `json:"header,omitempty"`
+	Payload   []byte      `json:"payload,omitempty"`
+}
+
+// EventFilter selects stored events. Empty fields match everything.
+type EventFilter struct {
+	EventType string
+	OrgRepo   string
+	Since     time.Time
+	Until     time.Time
+}
+
+func (f EventFilter) matches(e StoredEvent) bool {
+	if f.EventType != "" && f.EventType != e.EventType {
+		return false
+	}
+	if f.OrgRepo != "" && f.OrgRepo != e.OrgRepo && f.OrgRepo != strings.Split(e.OrgRepo, "/")[0] {
+		return false
+	}
+	if !f.Since.IsZero() && e.Received.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Received.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// EventStore persists received webhook events so that they can be replayed.
+type EventStore interface {
+	// Store persists the event unless an event with the same GUID is stored
+	// already.
+	Store(StoredEvent) error
+	// Get returns the event with the given GUID.
+	Get(guid string) (*StoredEvent, error)
+	// List returns the events matching the filter, oldest first. Headers and
+	// payloads are omitted.
+	List(EventFilter) ([]StoredEvent, error)
+	// Prune deletes all events received before the given time and returns
+	// how many were deleted.
+	Prune(before time.Time) (int, error)
+}
+
+// guidRe matches the GUIDs GitHub sets in the X-GitHub-Delivery header. It is
+// used to ensure that GUIDs are safe to use in object names.
+var guidRe = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+const (
+	eventDayLayout  = "2006-01-02"
+	eventTimeLayout = "150405.000000000"
+)
+
+// storageEventStore stores every event as a JSON object in a storage bucket.
+// Events are partitioned by day and named after the time they were received
+// and their metadata, so that listing and pruning only need to list object
+// names and never read payloads:
+//
+//	<base>/events/<day>/<time>,<guid>,<event-type>,<org%2Frepo>.json
+//	<base>/guids/<guid>
+//
+// The latter holds the name of the former so that events can be found by GUID.
+type storageEventStore struct {
+	opener io.Opener
+	base   string
+}
+
+// NewStorageEventStore returns an EventStore that persists events below base,
+// which must be a gs:// or s3:// path.
+func NewStorageEventStore(opener io.Opener, base string) (EventStore, error) {
+	if _, _, _, err := providers.ParseStoragePath(base); err != nil {
+		return nil, fmt.Errorf("invalid event store path: %w", err)
+	}
+	return &storageEventStore{opener: opener, base: strings.TrimSuffix(base, "/")}, nil
+}
+
+func (s *storageEventStore) guidPath(guid string) (string, error) {
+	if !guidRe.MatchString(guid) {
+		return "", fmt.Errorf("invalid event GUID %q", guid)
+	}
+	return s.base + "/guids/" + guid, nil
+}
+
+func (s *storageEventStore) dayPath(day string) string {
+	return s.base + "/events/" + day + "/"
+}
+
+func eventObjectName(e StoredEvent) string {
+	received := e.Received.UTC()
+	return fmt.Sprintf("%s/%s,%s,%s,%s.json", received.Format(eventDayLayout), received.Format(eventTimeLayout), e.GUID, url.QueryEscape(e.EventType), url.QueryEscape(e.OrgRepo))
+}
+
+// parseEventObjectName returns the event described by the name of an object
+// stored on day, without its header and payload.
+func parseEventObjectName(day, name string) (StoredEvent, bool) {
+	parts := strings.Split(strings.TrimSuffix(name, ".json"), ",")
+	if len(parts) != 4 || !strings.HasSuffix(name, ".json") {
+		return StoredEvent{}, false
+	}
+	received, err := time.Parse(eventDayLayout+"/"+eventTimeLayout, day+"/"+parts[0])
+	if err != nil {
+		return StoredEvent{}, false
+	}
+	eventType, err := url.QueryUnescape(parts[2])
+	if err != nil {
+		return StoredEvent{}, false
+	}
+	orgRepo, err := url.QueryUnescape(parts[3])
+	if err != nil {
+		return StoredEvent{}, false
+	}
+	return StoredEvent{GUID: parts[1], EventType: eventType, OrgRepo: orgRepo, Received: received}, true
+}
+
+func (s *storageEventStore) Store(e StoredEvent) error {
+	guidPath, err := s.guidPath(e.GUID)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	log := logrus.WithField(github.EventGUID, e.GUID)
+	// GitHub keeps the GUID of redelivered events, so only their first
+	// delivery is stored.
+	if _, err := io.ReadContent(ctx, log, s.opener, guidPath); err == nil {
+		return nil
+	} else if !io.IsNotExist(err) {
+		return fmt.Errorf("failed to check for stored event: %w", err)
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	name := eventObjectName(e)
+	// Write the event before its GUID so that Get never finds partial events.
+	if err := io.WriteContent(ctx, log, s.opener, s.base+"/events/"+name, raw); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	if err := io.WriteContent(ctx, log, s.opener, guidPath, []byte(name)); err != nil {
+		return fmt.Errorf("failed to write event GUID: %w", err)
+	}
+	return nil
+}
+
+func (s *storageEventStore) Get(guid string) (*StoredEvent, error) {
+	guidPath, err := s.guidPath(guid)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	log := logrus.WithField(github.EventGUID, guid)
+	name, err := io.ReadContent(ctx, log, s.opener, guidPath)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadContent(ctx, log, s.opener, s.base+"/events/"+string(name))
+	if err != nil {
+		return nil, err
+	}
+	var e StoredEvent
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event %s: %w", guid, err)
+	}
+	return &e, nil
+}
+
+// days returns the days events were stored on, oldest first.
+func (s *storageEventStore) days(ctx context.Context) ([]string, error) {
+	it, err := s.opener.Iterator(ctx, s.base+"/events/", "/")
+	if err != nil {
+		return nil, err
+	}
+	var days []string
+	for {
+		attr, err := it.Next(ctx)
+		if errors.Is(err, stdio.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		day := path.Base(attr.Name)
+		if _, err := time.Parse(eventDayLayout, day); !attr.IsDir || err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+// walkDay calls fn with the path and metadata of every event stored on day,
+// oldest first, until fn returns false.
+func (s *storageEventStore) walkDay(ctx context.Context, day string, fn func(path string, e StoredEvent) bool) error {
+	it, err := s.opener.Iterator(ctx, s.dayPath(day), "/")
+	if err != nil {
+		return err
+	}
+	var names []string
+	for {
+		attr, err := it.Next(ctx)
+		if errors.Is(err, stdio.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !attr.IsDir {
+			names = append(names, attr.ObjName)
+		}
+	}
+	// Object names start with the time the event was received.
+	sort.Strings(names)
+	for _, name := range names {
+		e, ok := parseEventObjectName(day, name)
+		if !ok {
+			continue
+		}
+		if !fn(s.dayPath(day)+name, e) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *storageEventStore) List(f EventFilter) ([]StoredEvent, error) {
+	ctx := context.Background()
+	days, err := s.days(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event days: %w", err)
+	}
+	var events []StoredEvent
+	for _, day := range days {
+		if !f.Since.IsZero() && day < f.Since.UTC().Format(eventDayLayout) {
+			continue
+		}
+		if !f.Until.IsZero() && day > f.Until.UTC().Format(eventDayLayout) {
+			break
+		}
+		if err := s.walkDay(ctx, day, func(_ string, e StoredEvent) bool {
+			if f.matches(e) {
+				events = append(events, e)
+			}
+			return true
+		}); err != nil {
+			return events, fmt.Errorf("failed to list events of %s: %w", day, err)
+		}
+	}
+	return events, nil
+}
+
+func (s *storageEventStore) Prune(before time.Time) (int, error) {
+	ctx := context.Background()
+	days, err := s.days(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list event days: %w", err)
+	}
+	var deleted int
+	var errs []error
+	for _, day := range days {
+		if day > before.UTC().Format(eventDayLayout) {
+			break
+		}
+		var done bool
+		if err := s.walkDay(ctx, day, func(path string, e StoredEvent) bool {
+			if !e.Received.Before(before) {
+				done = true
+				return false
+			}
+			if err := s.opener.Delete(ctx, path); err != nil && !io.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to delete event %s: %w", e.GUID, err))
+				return true
+			}
+			deleted++
+			if err := s.opener.Delete(ctx, s.base+"/guids/"+e.GUID); err != nil && !io.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to delete GUID of event %s: %w", e.GUID, err))
+			}
+			return true
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to list events of %s: %w", day, err))
+		}
+		if done {
+			break
+		}
+	}
+	return deleted, utilerrors.NewAggregate(errs)
+}
+
+// storeEvent persists a validated event if an EventStore is configured.
+// Failures are logged but never prevent the event from being handled.
+func (s *Server) storeEvent(eventType, eventGUID string, payload []byte, h http.Header) {
+	if s.EventStore == nil {
+		return
+	}
+	if err := s.EventStore.Store(newStoredEvent(eventType, eventGUID, payload, h)); err != nil {
+		logrus.WithError(err).WithField(github.EventGUID, eventGUID).Warn("Failed to store event.")
+	}
+}
+
+func newStoredEvent(eventType, eventGUID string, payload []byte, h http.Header) StoredEvent {
+	var ge github.GenericEvent
+	// Not every event has a repository, so an error is not fatal.
+	_ = json.Unmarshal(payload, &ge)
+	return StoredEvent{
+		GUID:      eventGUID,
+		EventType: eventType,
+		OrgRepo:   ge.Repo.FullName,
+		Received:  time.Now(),
+		Header:    h.Clone(),
+		Payload:   payload,
+	}
+}
+
+// Replay re-delivers the stored events with the given GUIDs to plugins.
+func (s *Server) Replay(guids []string) error {
+	if s.EventStore == nil {
+		return errors.New("no event store configured")
+	}
+	var errs []error
+	for _, guid := range guids {
+		e, err := s.EventStore.Get(guid)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get event %s: %w", guid, err))
+			continue
+		}
+		h := e.Header.Clone()
+		if h == nil {
+			h = http.Header{}
+		}
+		h.Set(replayHeader, "true")
+		logrus.WithFields(logrus.Fields{github.EventGUID: guid, "event-type": e.EventType}).Info("Replaying event.")
+		if err := s.demuxEvent(context.Background(), e.EventType, e.GUID, e.Payload, h); err != nil {
+			errs = append(errs, fmt.Errorf("failed to replay event %s: %w", guid, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// PruneEvents deletes stored events older than the retention window.
+func (s *Server) PruneEvents(retention time.Duration) {
+	if s.EventStore == nil {
+		return
+	}
+	deleted, err := s.EventStore.Prune(time.Now().Add(-retention))
+	l := logrus.WithField("deleted", deleted)
+	if err != nil {
+		l.WithError(err).Warn("Failed to prune stored events.")
+		return
+	}
+	l.Debug("Pruned stored events.")
+}
+
+// EventStoreAdminHandler serves the event store admin API:
+//
+//	GET /events?event-type=...&org-repo=...&since=...&until=... lists stored events.
+//	POST /events/replay?guid=...&guid=... replays the given events.
+//
+// Times are in RFC 3339 format. The API is unauthenticated, so it must only be
+// served on localhost.
+func (s *Server) EventStoreAdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		f := EventFilter{EventType: r.URL.Query().Get("event-type"), OrgRepo: r.URL.Query().Get("org-repo")}
+		for param, t := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+			if v := r.URL.Query().Get(param); v != "" {
+				parsed, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("400 Bad Request: invalid %s: %v", param, err), http.StatusBadRequest)
+					return
+				}
+				*t = parsed
+			}
+		}
+		events, err := s.EventStore.List(f)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("500 Internal Server Error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(events); err != nil {
+			logrus.WithError(err).Warn("Failed to write stored events.")
+		}
+	})
+	mux.HandleFunc("/events/replay", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		guids := r.URL.Query()["guid"]
+		if len(guids) == 0 {
+			http.Error(w, "400 Bad Request: no guid given", http.StatusBadRequest)
+			return
+		}
+		if err := s.Replay(guids); err != nil {
+			http.Error(w, fmt.Sprintf("500 Internal Server Error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "Replayed %d events.", len(guids))
+	})
+	return mux
+}
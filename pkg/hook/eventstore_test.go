@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/githubeventserver"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestStorageEventStore(t *testing.T) {
+	if _, err := NewStorageEventStore(&fakeopener.FakeOpener{}, "/local/dir"); err == nil {
+		t.Error("expected an event store with a local path to fail")
+	}
+	opener := &fakeopener.FakeOpener{}
+	store, err := NewStorageEventStore(opener, "gs://bucket/hook/")
+	if err != nil {
+		t.Fatalf("failed to create event store: %v", err)
+	}
+	// The events span two days to cover listing and pruning across days.
+	now := time.Date(2024, 3, 2, 0, 30, 0, 0, time.UTC)
+	events := []StoredEvent{
+		{GUID: "old", EventType: "push", OrgRepo: "org/repo", Received: now.Add(-2 * time.Hour), Payload: []byte(`{}`)},
+		{GUID: "new", EventType: "issue_comment", OrgRepo: "org/other", Received: now, Payload: []byte(`{}`)},
+		{GUID: "middle", EventType: "push", OrgRepo: "other/repo", Received: now.Add(-time.Hour), Header: http.Header{"X-Github-Event": []string{"push"}}, Payload: []byte(`{}`)},
+		{GUID: "no-repo", EventType: "installation", Received: now.Add(-time.Minute), Payload: []byte(`{}`)},
+	}
+	for _, e := range events {
+		if err := store.Store(e); err != nil {
+			t.Fatalf("failed to store event %s: %v", e.GUID, err)
+		}
+	}
+	if err := store.Store(StoredEvent{GUID: "../escape"}); err == nil {
+		t.Error("expected storing an event with an invalid GUID to fail")
+	}
+	redelivered := events[0]
+	redelivered.Received = now
+	if err := store.Store(redelivered); err != nil {
+		t.Fatalf("failed to store redelivered event: %v", err)
+	}
+	if expected := "gs://bucket/hook/events/2024-03-01/223000.000000000,old,push,org%2Frepo.json"; opener.Buffer[expected] == nil {
+		t.Errorf("expected event to be stored as %s", expected)
+	}
+
+	got, err := store.Get("middle")
+	if err != nil {
+		t.Fatalf("failed to get event: %v", err)
+	}
+	if diff := cmp.Diff(events[2], *got); diff != "" {
+		t.Errorf("unexpected event (-want +got):\n%s", diff)
+	}
+
+	testCases := []struct {
+		name     string
+		filter   EventFilter
+		expected []string
+	}{
+		{
+			name:     "no filter lists everything oldest first",
+			expected: []string{"old", "middle", "no-repo", "new"},
+		},
+		{
+			name:     "by event type",
+			filter:   EventFilter{EventType: "push"},
+			expected: []string{"old", "middle"},
+		},
+		{
+			name:     "by org",
+			filter:   EventFilter{OrgRepo: "org"},
+			expected: []string{"old", "new"},
+		},
+		{
+			name:     "by repo",
+			filter:   EventFilter{OrgRepo: "org/repo"},
+			expected: []string{"old"},
+		},
+		{
+			name:     "by time",
+			filter:   EventFilter{Since: now.Add(-90 * time.Minute), Until: now.Add(-2 * time.Minute)},
+			expected: []string{"middle"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listed, err := store.List(tc.filter)
+			if err != nil {
+				t.Fatalf("failed to list events: %v", err)
+			}
+			var guids []string
+			for _, e := range listed {
+				if e.Header != nil || e.Payload != nil {
+					t.Errorf("expected header and payload of %s to be omitted", e.GUID)
+				}
+				guids = append(guids, e.GUID)
+			}
+			if diff := cmp.Diff(tc.expected, guids); diff != "" {
+				t.Errorf("unexpected events (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	deleted, err := store.Prune(now.Add(-30 * time.Minute))
+	if err != nil {
+		t.Fatalf("failed to prune events: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 pruned events, got %d", deleted)
+	}
+	for _, guid := range []string{"old", "middle"} {
+		if _, err := store.Get(guid); err == nil {
+			t.Errorf("expected pruned event %s to be gone", guid)
+		}
+	}
+	if _, err := store.Get("no-repo"); err != nil {
+		t.Errorf("expected event newer than the cutoff to be kept: %v", err)
+	}
+	if len(opener.Buffer) != 4 {
+		t.Errorf("expected only the objects of the kept events to be left, got %d objects", len(opener.Buffer))
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var lock sync.Mutex
+	var received []http.Header
+	plugin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, r.Header)
+	}))
+	defer plugin.Close()
+
+	store, err := NewStorageEventStore(&fakeopener.FakeOpener{}, "gs://bucket/hook")
+	if err != nil {
+		t.Fatalf("failed to create event store: %v", err)
+	}
+	pa := &plugins.ConfigAgent{}
+	pa.Set(&plugins.Configuration{ExternalPlugins: map[string][]plugins.ExternalPlugin{
+		"org": {{Name: "coffeemachine", Endpoint: plugin.URL}},
+	}})
+	s := &Server{
+		Plugins:     pa,
+		Metrics:     githubeventserver.NewMetrics(),
+		RepoEnabled: func(_, _ string) bool { return true },
+		EventStore:  store,
+	}
+
+	s.storeEvent("fork", "guid", []byte(`{"repository":{"full_name":"org/repo"}}`), http.Header{"X-Github-Event": []string{"fork"}})
+	if err := s.Replay([]string{"guid"}); err != nil {
+		t.Fatalf("failed to replay event: %v", err)
+	}
+	if err := s.Replay([]string{"missing"}); err == nil {
+		t.Error("expected replaying a missing event to fail")
+	}
+	s.GracefulShutdown()
+
+	if len(received) != 1 {
+		t.Fatalf("expected the replayed event to be dispatched once, got %d", len(received))
+	}
+	if got := received[0].Get(replayHeader); got != "true" {
+		t.Errorf("expected %s header to be set, got %q", replayHeader, got)
+	}
+	if got := received[0].Get("X-GitHub-Event"); got != "fork" {
+		t.Errorf("expected original headers to be kept, got event %q", got)
+	}
+}
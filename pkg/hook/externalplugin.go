@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/externalplugin"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	grpcHandshakeTimeout = 10 * time.Second
+	grpcEventTimeout     = time.Minute
+	grpcMaxRetries       = 3
+)
+
+// grpcPlugin is a connection to an external plugin speaking the gRPC
+// protocol, together with the capabilities it declared in the handshake.
+type grpcPlugin struct {
+	conn   *grpc.ClientConn
+	client externalplugin.ExternalPluginClient
+	// filters maps event types to the accepted actions. An empty set
+	// accepts all actions.
+	filters map[string]sets.Set[string]
+
+	lock sync.Mutex
+	// pushedConfig is the last configuration pushed to the plugin.
+	pushedConfig string
+}
+
+// wants returns whether the plugin asked for the event in its handshake.
+func (p *grpcPlugin) wants(eventType, action string) bool {
+	actions, ok := p.filters[eventType]
+	if !ok {
+		return false
+	}
+	return actions.Len() == 0 || actions.Has(action)
+}
+
+// grpcPlugins caches connections to external plugins using the gRPC protocol
+// keyed by endpoint. The zero value is ready to use.
+type grpcPlugins struct {
+	lock    sync.Mutex
+	plugins map[string]*grpcPlugin
+	// dialOptions are appended to the default dial options.
+	dialOptions []grpc.DialOption
+}
+
+// get returns the handshaked connection to the plugin, establishing it if
+// needed. The handshake happens without holding the lock, so that a slow or
+// unreachable plugin does not hold up the events of the other plugins.
+func (g *grpcPlugins) get(ctx context.Context, p plugins.ExternalPlugin) (*grpcPlugin, error) {
+	g.lock.Lock()
+	gp, ok := g.plugins[p.Endpoint]
+	g.lock.Unlock()
+	if ok {
+		return gp, nil
+	}
+
+	gp, err := g.dial(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	// Another event may have connected to the plugin in the meantime, keep
+	// its connection so that there is only one per endpoint.
+	if existing, ok := g.plugins[p.Endpoint]; ok {
+		gp.conn.Close()
+		return existing, nil
+	}
+	if g.plugins == nil {
+		g.plugins = map[string]*grpcPlugin{}
+	}
+	g.plugins[p.Endpoint] = gp
+	return gp, nil
+}
+
+// dial connects to the plugin and does the handshake.
+func (g *grpcPlugins) dial(ctx context.Context, p plugins.ExternalPlugin) (*grpcPlugin, error) {
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, g.dialOptions...)
+	conn, err := grpc.Dial(p.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", p.Endpoint, err)
+	}
+	client := externalplugin.NewExternalPluginClient(conn)
+	hctx, cancel := context.WithTimeout(ctx, grpcHandshakeTimeout)
+	defer cancel()
+	resp, err := client.Handshake(hctx, &externalplugin.HandshakeRequest{ProtocolVersion: externalplugin.ProtocolVersion})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+	if resp.ProtocolVersion == 0 || resp.ProtocolVersion > externalplugin.ProtocolVersion {
+		conn.Close()
+		return nil, fmt.Errorf("plugin %q speaks unsupported protocol version %d", resp.Name, resp.ProtocolVersion)
+	}
+	gp := &grpcPlugin{conn: conn, client: client, filters: map[string]sets.Set[string]{}}
+	for _, f := range resp.Filters {
+		gp.filters[f.EventType] = sets.New[string](f.Actions...)
+	}
+	return gp, nil
+}
+
+// forget drops the cached connection so that the next event redoes the
+// handshake, e.g. after the plugin was redeployed.
+func (g *grpcPlugins) forget(endpoint string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if gp, ok := g.plugins[endpoint]; ok {
+		gp.conn.Close()
+		delete(g.plugins, endpoint)
+	}
+}
+
+// close closes all cached connections.
+func (g *grpcPlugins) close() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for endpoint, gp := range g.plugins {
+		gp.conn.Close()
+		delete(g.plugins, endpoint)
+	}
+}
+
+// pluginConfigs collects the configuration of the named plugin for all orgs
+// and repos it is enabled for.
+func pluginConfigs(cfg *plugins.Configuration, name string) []*externalplugin.RepoConfig {
+	var configs []*externalplugin.RepoConfig
+	for orgRepo, externalPlugins := range cfg.ExternalPlugins {
+		for _, p := range externalPlugins {
+			if p.Name != name || p.Protocol != plugins.ExternalPluginProtocolGRPC {
+				continue
+			}
+			configs = append(configs, &externalplugin.RepoConfig{OrgRepo: orgRepo, Config: p.Config})
+		}
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].OrgRepo < configs[j].OrgRepo })
+	return configs
+}
+
+// pushConfig pushes the plugin configuration if it changed since the last push.
+func (p *grpcPlugin) pushConfig(ctx context.Context, configs []*externalplugin.RepoConfig) error {
+	serialized, err := json.Marshal(configs)
+	if err != nil {
+		return err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if string(serialized) == p.pushedConfig {
+		return nil
+	}
+	if _, err := p.client.UpdateConfig(ctx, &externalplugin.UpdateConfigRequest{Configs: configs}); err != nil {
+		return err
+	}
+	p.pushedConfig = string(serialized)
+	return nil
+}
+
+// dispatchGRPC delivers the event to an external plugin using the gRPC
+// protocol. Events the plugin did not ask for in its handshake are dropped.
+// It returns whether the event was delivered.
+func (s *Server) dispatchGRPC(l *logrus.Entry, p plugins.ExternalPlugin, eventType, eventGUID, orgRepo string, payload []byte) (bool, error) {
+	var action struct {
+		Action string `json:"action"`
+	}
+	// Not all events have an action, so an error here is not fatal.
+	_ = json.Unmarshal(payload, &action)
+	org, repo, _ := strings.Cut(orgRepo, "/")
+	req := &externalplugin.HandleEventRequest{
+		EventType: eventType,
+		EventGuid: eventGUID,
+		Org:       org,
+		Repo:      repo,
+		Action:    action.Action,
+		Payload:   payload,
+	}
+
+	backoff := 100 * time.Millisecond
+	var err error
+	for retries := 0; retries < grpcMaxRetries; retries++ {
+		if retries > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var gp *grpcPlugin
+		if gp, err = s.grpcPlugins.get(context.Background(), p); err != nil {
+			continue
+		}
+		if !gp.wants(eventType, req.Action) {
+			return false, nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), grpcEventTimeout)
+		if err = gp.pushConfig(ctx, pluginConfigs(s.Plugins.Config(), p.Name)); err == nil {
+			_, err = gp.client.HandleEvent(ctx, req)
+		}
+		cancel()
+		if err == nil {
+			return true, nil
+		}
+		pluginErr := externalplugin.ErrorFromStatus(err)
+		err = fmt.Errorf("%s: %s", pluginErr.Reason, pluginErr.Message)
+		if pluginErr.Reason != externalplugin.Error_RETRYABLE {
+			return false, err
+		}
+		l.WithError(err).WithField("external-plugin", p.Name).Debug("Retrying event delivery to external plugin.")
+		s.grpcPlugins.forget(p.Endpoint)
+	}
+	return false, err
+}
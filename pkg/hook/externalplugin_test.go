@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"sigs.k8s.io/prow/pkg/externalplugin"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestDispatchGRPC(t *testing.T) {
+	var lock sync.Mutex
+	var handled []string
+	var config map[string]string
+
+	plugin := externalplugin.NewServer("coffeemachine", logrus.WithField("test", t.Name()))
+	plugin.RegisterIssueCommentEventHandler(func(_ *logrus.Entry, ice github.IssueCommentEvent) error {
+		lock.Lock()
+		defer lock.Unlock()
+		handled = append(handled, ice.Comment.Body)
+		config = plugin.ConfigFor(ice.Repo.Owner.Login, ice.Repo.Name)
+		if ice.Comment.Body == "/decaf" {
+			return externalplugin.PermanentError(errors.New("no decaf"))
+		}
+		return nil
+	}, "created")
+
+	lis := bufconn.Listen(1024 * 1024)
+	go plugin.Serve(lis)
+	defer plugin.GracefulStop()
+
+	p := plugins.ExternalPlugin{Name: "coffeemachine", Endpoint: "coffeemachine:80", Protocol: plugins.ExternalPluginProtocolGRPC}
+	pa := &plugins.ConfigAgent{}
+	pa.Set(&plugins.Configuration{ExternalPlugins: map[string][]plugins.ExternalPlugin{
+		"org": {{Name: p.Name, Endpoint: p.Endpoint, Protocol: p.Protocol, Config: map[string]string{"roast": "dark"}}},
+	}})
+	s := &Server{Plugins: pa}
+	s.grpcPlugins.dialOptions = []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})}
+	defer s.grpcPlugins.close()
+
+	testCases := []struct {
+		name              string
+		eventType         string
+		payload           string
+		expectedDelivered bool
+		expectedErr       bool
+		expectedHandled   []string
+	}{
+		{
+			name:              "requested event is delivered",
+			eventType:         "issue_comment",
+			payload:           `{"action":"created","comment":{"body":"/espresso"},"repository":{"name":"repo","owner":{"login":"org"}}}`,
+			expectedDelivered: true,
+			expectedHandled:   []string{"/espresso"},
+		},
+		{
+			name:      "unrequested action is filtered",
+			eventType: "issue_comment",
+			payload:   `{"action":"deleted","comment":{"body":"/espresso"},"repository":{"name":"repo","owner":{"login":"org"}}}`,
+		},
+		{
+			name:      "unrequested event type is filtered",
+			eventType: "push",
+			payload:   `{"ref":"refs/heads/main"}`,
+		},
+		{
+			name:            "permanent errors are not retried",
+			eventType:       "issue_comment",
+			payload:         `{"action":"created","comment":{"body":"/decaf"},"repository":{"name":"repo","owner":{"login":"org"}}}`,
+			expectedErr:     true,
+			expectedHandled: []string{"/decaf"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handled = nil
+			delivered, err := s.dispatchGRPC(logrus.WithField("test", t.Name()), p, tc.eventType, "guid", "org/repo", []byte(tc.payload))
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if delivered != tc.expectedDelivered {
+				t.Errorf("expected delivered: %t, got: %t", tc.expectedDelivered, delivered)
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if diff := cmp.Diff(tc.expectedHandled, handled); diff != "" {
+				t.Errorf("unexpected handled events (-want +got):\n%s", diff)
+			}
+			if len(handled) > 0 {
+				if diff := cmp.Diff(map[string]string{"roast": "dark"}, config); diff != "" {
+					t.Errorf("unexpected pushed config (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGRPCPluginsGet(t *testing.T) {
+	plugin := externalplugin.NewServer("coffeemachine", logrus.WithField("test", t.Name()))
+	lis := bufconn.Listen(1024 * 1024)
+	go plugin.Serve(lis)
+	defer plugin.GracefulStop()
+
+	// Connections to the slow plugin hang until it is released.
+	release := make(chan struct{})
+	var g grpcPlugins
+	g.dialOptions = []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		if addr == "slow:80" {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return lis.DialContext(ctx)
+	})}
+	defer g.close()
+
+	slowDone := make(chan error)
+	go func() {
+		_, err := g.get(context.Background(), plugins.ExternalPlugin{Name: "slow", Endpoint: "slow:80"})
+		slowDone <- err
+	}()
+
+	var wg sync.WaitGroup
+	connected := make([]*grpcPlugin, 5)
+	for i := range connected {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			gp, err := g.get(context.Background(), plugins.ExternalPlugin{Name: "coffeemachine", Endpoint: "coffeemachine:80"})
+			if err != nil {
+				t.Errorf("failed to connect to the plugin: %v", err)
+			}
+			connected[i] = gp
+		}(i)
+	}
+	// The slow plugin must not hold up the others.
+	wg.Wait()
+	for i, gp := range connected {
+		if gp != connected[0] {
+			t.Errorf("connection %d differs from the first one, expected one connection per endpoint", i)
+		}
+	}
+
+	close(release)
+	if err := <-slowDone; err != nil {
+		t.Errorf("failed to connect to the slow plugin: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const pubsubPublishTimeout = 30 * time.Second
+
+// eventPublisher publishes events to Pub/Sub topics.
+type eventPublisher interface {
+	publish(ctx context.Context, project, topic string, data []byte, attributes map[string]string) error
+	close()
+}
+
+// pubsubPublisher caches Pub/Sub topics keyed by project and topic. The zero
+// value is ready to use.
+type pubsubPublisher struct {
+	lock    sync.Mutex
+	clients map[string]*pubsub.Client
+	topics  map[string]*pubsub.Topic
+}
+
+func (p *pubsubPublisher) topic(ctx context.Context, project, topic string) (*pubsub.Topic, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	key := project + "/" + topic
+	if t, ok := p.topics[key]; ok {
+		return t, nil
+	}
+	client, ok := p.clients[project]
+	if !ok {
+		var err error
+		// The client outlives the event, so it must not use its context.
+		if client, err = pubsub.NewClient(context.Background(), project); err != nil {
+			return nil, fmt.Errorf("could not create pubsub client for project %q: %w", project, err)
+		}
+		if p.clients == nil {
+			p.clients = map[string]*pubsub.Client{}
+		}
+		p.clients[project] = client
+	}
+	if p.topics == nil {
+		p.topics = map[string]*pubsub.Topic{}
+	}
+	t := client.Topic(topic)
+	p.topics[key] = t
+	return t, nil
+}
+
+func (p *pubsubPublisher) publish(ctx context.Context, project, topic string, data []byte, attributes map[string]string) error {
+	t, err := p.topic(ctx, project, topic)
+	if err != nil {
+		return err
+	}
+	if _, err := t.Publish(ctx, &pubsub.Message{Data: data, Attributes: attributes}).Get(ctx); err != nil {
+		return fmt.Errorf("failed to publish to topic %s/%s: %w", project, topic, err)
+	}
+	return nil
+}
+
+// close flushes and stops all cached topics and closes the clients.
+func (p *pubsubPublisher) close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for key, t := range p.topics {
+		t.Stop()
+		delete(p.topics, key)
+	}
+	for project, client := range p.clients {
+		if err := client.Close(); err != nil {
+			logrus.WithError(err).WithField("project", project).Warn("Failed to close pubsub client.")
+		}
+		delete(p.clients, project)
+	}
+}
+
+// forwardEvent forwards the event to all event forwarders that want it. It
+// must be called before the headers are handed to other goroutines.
+func (s *Server) forwardEvent(l *logrus.Entry, eventType, eventGUID, srcRepo string, payload []byte, h http.Header) {
+	forwarders := s.Plugins.Config().EventForwarders
+	if len(forwarders) == 0 {
+		return
+	}
+	header := h.Clone()
+	header.Set("User-Agent", "ProwHook")
+	for i := range forwarders {
+		f := forwarders[i]
+		if !f.Forwards(eventType, srcRepo) {
+			continue
+		}
+		s.startHandler(l)
+		go func() {
+			defer s.handlerDone(l)
+			log := l.WithField("event-forwarder", f.Name)
+			if err := s.forward(f, eventType, eventGUID, srcRepo, payload, header); err != nil {
+				log.WithError(err).Error("Error forwarding event.")
+				return
+			}
+			log.Debug("Forwarded event.")
+		}()
+	}
+}
+
+func (s *Server) forward(f plugins.EventForwarder, eventType, eventGUID, srcRepo string, payload []byte, h http.Header) error {
+	if f.PubSub == nil {
+		return s.dispatch(f.Endpoint, payload, h)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pubsubPublishTimeout)
+	defer cancel()
+	return s.getPublisher().publish(ctx, f.PubSub.Project, f.PubSub.Topic, payload, map[string]string{
+		"event_type": eventType,
+		"event_guid": eventGUID,
+		"org_repo":   srcRepo,
+	})
+}
+
+func (s *Server) getPublisher() eventPublisher {
+	s.publisherOnce.Do(func() {
+		if s.publisher == nil {
+			s.publisher = &pubsubPublisher{}
+		}
+	})
+	return s.publisher
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+type publishedMessage struct {
+	project, topic string
+	data           string
+	attributes     map[string]string
+}
+
+type fakePublisher struct {
+	lock      sync.Mutex
+	published []publishedMessage
+}
+
+func (f *fakePublisher) publish(_ context.Context, project, topic string, data []byte, attributes map[string]string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.published = append(f.published, publishedMessage{project: project, topic: topic, data: string(data), attributes: attributes})
+	return nil
+}
+
+func (f *fakePublisher) close() {}
+
+func TestForwardEvent(t *testing.T) {
+	forwarders := []plugins.EventForwarder{
+		{
+			Name:     "all",
+			Endpoint: "https://all.example.com",
+		},
+		{
+			Name:     "prs",
+			Repos:    []string{"org"},
+			Events:   []string{"pull_request"},
+			Endpoint: "https://prs.example.com",
+		},
+		{
+			Name:   "pushes",
+			Repos:  []string{"org/repo"},
+			Events: []string{"push"},
+			PubSub: &plugins.EventForwarderPubSub{Project: "project", Topic: "pushes"},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		eventType     string
+		srcRepo       string
+		wantEndpoints []string
+		wantPublished []publishedMessage
+	}{
+		{
+			name:          "pull request event in org",
+			eventType:     "pull_request",
+			srcRepo:       "org/other",
+			wantEndpoints: []string{"https://all.example.com", "https://prs.example.com"},
+		},
+		{
+			name:          "push event in repo is published",
+			eventType:     "push",
+			srcRepo:       "org/repo",
+			wantEndpoints: []string{"https://all.example.com"},
+			wantPublished: []publishedMessage{{
+				project:    "project",
+				topic:      "pushes",
+				data:       "payload",
+				attributes: map[string]string{"event_type": "push", "event_guid": "guid", "org_repo": "org/repo"},
+			}},
+		},
+		{
+			name:          "push event in other repo",
+			eventType:     "push",
+			srcRepo:       "org/other",
+			wantEndpoints: []string{"https://all.example.com"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var lock sync.Mutex
+			endpoints := map[string]http.Header{}
+			pa := &plugins.ConfigAgent{}
+			pa.Set(&plugins.Configuration{EventForwarders: forwarders})
+			publisher := &fakePublisher{}
+			s := &Server{
+				Plugins: pa,
+				c: *newTestClient(func(req *http.Request) *http.Response {
+					lock.Lock()
+					endpoints[req.URL.String()] = req.Header
+					lock.Unlock()
+					return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("OK")), Header: make(http.Header)}
+				}),
+				publisher: publisher,
+			}
+			h := http.Header{"X-Github-Event": []string{tc.eventType}}
+			s.forwardEvent(logrus.WithField("test", tc.name), tc.eventType, "guid", tc.srcRepo, []byte("payload"), h)
+			s.wg.Wait()
+
+			var got []string
+			for endpoint, header := range endpoints {
+				got = append(got, endpoint)
+				if header.Get("User-Agent") != "ProwHook" || header.Get("X-Github-Event") != tc.eventType {
+					t.Errorf("unexpected headers forwarded to %s: %v", endpoint, header)
+				}
+			}
+			if diff := cmp.Diff(tc.wantEndpoints, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("forwarded endpoints differ (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantPublished, publisher.published, cmp.AllowUnexported(publishedMessage{})); diff != "" {
+				t.Errorf("published messages differ (-want +got):\n%s", diff)
+			}
+			if h.Get("User-Agent") != "" {
+				t.Error("the original headers were modified")
+			}
+		})
+	}
+}
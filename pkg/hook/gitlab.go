@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/githubeventserver"
+	"sigs.k8s.io/prow/pkg/gitlab"
+	"sigs.k8s.io/prow/pkg/plugins"
+	"sigs.k8s.io/prow/pkg/plugins/trigger"
+)
+
+// Event types used to label metrics for GitLab webhooks.
+const (
+	gitlabMergeRequestEventType = "gitlab_merge_request"
+	gitlabNoteEventType         = "gitlab_note"
+)
+
+// GitLabServer implements http.Handler. It validates incoming GitLab webhooks
+// and triggers presubmits for projects that have the trigger plugin enabled.
+// GitLab projects are configured like GitHub repos, using the full path of the
+// project, e.g. "group/subgroup/project".
+type GitLabServer struct {
+	ClientAgent    *plugins.ClientAgent
+	GitLabClient   gitlab.Client
+	Plugins        *plugins.ConfigAgent
+	ConfigAgent    *config.Agent
+	TokenGenerator func() []byte
+	Metrics        *githubeventserver.Metrics
+	RepoEnabled    func(org, repo string) bool
+
+	// Tracks running handlers for graceful shutdown
+	wg sync.WaitGroup
+}
+
+// ServeHTTP validates an incoming webhook and handles it asynchronously.
+func (s *GitLabServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	eventType, eventGUID, payload, ok, resp := gitlab.ValidateWebhook(w, r, s.TokenGenerator)
+	if counter, err := s.Metrics.ResponseCounter.GetMetricWithLabelValues(strconv.Itoa(resp)); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"status-code": resp,
+		}).WithError(err).Error("Failed to get metric for reporting webhook status code")
+	} else {
+		counter.Inc()
+	}
+
+	if !ok {
+		return
+	}
+	fmt.Fprint(w, "Event received. Have a nice day.")
+
+	if err := s.demuxEvent(eventType, eventGUID, payload); err != nil {
+		logrus.WithError(err).Error("Error parsing event.")
+	}
+}
+
+func (s *GitLabServer) demuxEvent(eventType, eventGUID string, payload []byte) error {
+	l := logrus.WithFields(
+		logrus.Fields{
+			eventTypeField:   eventType,
+			github.EventGUID: eventGUID,
+		},
+	)
+	switch eventType {
+	case gitlab.MergeRequestHook:
+		var mre gitlab.MergeRequestEvent
+		if err := json.Unmarshal(payload, &mre); err != nil {
+			return err
+		}
+		mre.GUID = eventGUID
+		countWebhook(s.Metrics, l, gitlabMergeRequestEventType)
+		if s.triggerEnabled(mre.Project) {
+			s.wg.Add(1)
+			go s.handleMergeRequestEvent(l, mre)
+		}
+	case gitlab.NoteHook:
+		var ne gitlab.NoteEvent
+		if err := json.Unmarshal(payload, &ne); err != nil {
+			return err
+		}
+		ne.GUID = eventGUID
+		countWebhook(s.Metrics, l, gitlabNoteEventType)
+		if s.triggerEnabled(ne.Project) {
+			s.wg.Add(1)
+			go s.handleNoteEvent(l, ne)
+		}
+	default:
+		l.Debug("Ignoring unhandled GitLab event type.")
+	}
+	return nil
+}
+
+// countWebhook counts a webhook from a forge other than GitHub.
+func countWebhook(metrics *githubeventserver.Metrics, l *logrus.Entry, eventType string) {
+	// We don't want to fail the webhook due to a metrics error.
+	if counter, err := metrics.WebhookCounter.GetMetricWithLabelValues(eventType); err != nil {
+		l.WithError(err).Warn("Failed to get metric for eventType " + eventType)
+	} else {
+		counter.Inc()
+	}
+}
+
+// triggerEnabled returns whether the trigger plugin is enabled for the project.
+func (s *GitLabServer) triggerEnabled(project gitlab.Project) bool {
+	org, repo := project.OrgRepo()
+	if !s.RepoEnabled(org, repo) {
+		return false
+	}
+	_, enabled := s.Plugins.GenericCommentHandlers(org, repo)[trigger.PluginName]
+	return enabled
+}
+
+func (s *GitLabServer) client(l *logrus.Entry) trigger.GitLabClient {
+	return trigger.GitLabClient{
+		GitLabClient:  s.GitLabClient,
+		ProwJobClient: s.ClientAgent.ProwJobClient,
+		Config:        s.ConfigAgent.Config(),
+		Logger:        l,
+	}
+}
+
+func (s *GitLabServer) handleMergeRequestEvent(l *logrus.Entry, mre gitlab.MergeRequestEvent) {
+	defer s.wg.Done()
+	org, repo := mre.Project.OrgRepo()
+	l = l.WithFields(logrus.Fields{
+		github.OrgLogField:  org,
+		github.RepoLogField: repo,
+		github.PrLogField:   mre.ObjectAttributes.IID,
+		"author":            mre.User.Username,
+		"url":               mre.ObjectAttributes.URL,
+	})
+	l.Infof("Merge request %s.", mre.ObjectAttributes.Action)
+	observeTrigger(s.Metrics, l, string(mre.ObjectAttributes.Action), func() error {
+		return trigger.HandleGitLabMergeRequest(s.client(l), s.Plugins.Config().TriggerFor(org, repo), mre)
+	})
+}
+
+func (s *GitLabServer) handleNoteEvent(l *logrus.Entry, ne gitlab.NoteEvent) {
+	defer s.wg.Done()
+	org, repo := ne.Project.OrgRepo()
+	l = l.WithFields(logrus.Fields{
+		github.OrgLogField:  org,
+		github.RepoLogField: repo,
+		"commenter":         ne.User.Username,
+		"url":               ne.ObjectAttributes.URL,
+	})
+	if ne.MergeRequest != nil {
+		l = l.WithField(github.PrLogField, ne.MergeRequest.IID)
+	}
+	l.Info("Note created.")
+	observeTrigger(s.Metrics, l, "created", func() error {
+		return trigger.HandleGitLabNote(s.client(l), s.Plugins.Config().TriggerFor(org, repo), ne)
+	})
+}
+
+// observeTrigger runs a trigger handler for a forge other than GitHub and
+// records the plugin metrics for it.
+func observeTrigger(metrics *githubeventserver.Metrics, l *logrus.Entry, action string, handle func() error) {
+	start := time.Now()
+	err := errorOnPanic(handle)
+	labels := prometheus.Labels{"event_type": l.Data[eventTypeField].(string), "action": action, "plugin": trigger.PluginName, "took_action": strconv.FormatBool(err == nil)}
+	if err != nil {
+		l.WithError(err).Error("Error handling event.")
+		metrics.PluginHandleErrors.With(labels).Inc()
+	}
+	metrics.PluginHandleDuration.With(labels).Observe(time.Since(start).Seconds())
+}
+
+// GracefulShutdown implements a graceful shutdown protocol. It handles all requests sent before
+// receiving the shutdown signal.
+func (s *GitLabServer) GracefulShutdown() {
+	s.wg.Wait() // Handle remaining requests
+}
@@ -31,6 +31,7 @@ import (
 	_ "sigs.k8s.io/prow/pkg/plugins/cherrypickunapproved"
 	_ "sigs.k8s.io/prow/pkg/plugins/cla"
 	_ "sigs.k8s.io/prow/pkg/plugins/dco"
+	_ "sigs.k8s.io/prow/pkg/plugins/dependency-approver"
 	_ "sigs.k8s.io/prow/pkg/plugins/dog"
 	_ "sigs.k8s.io/prow/pkg/plugins/golint"
 	_ "sigs.k8s.io/prow/pkg/plugins/goose"
@@ -49,12 +50,16 @@ import (
 	_ "sigs.k8s.io/prow/pkg/plugins/milestonestatus"
 	_ "sigs.k8s.io/prow/pkg/plugins/override"
 	_ "sigs.k8s.io/prow/pkg/plugins/owners-label"
+	_ "sigs.k8s.io/prow/pkg/plugins/path-label"
 	_ "sigs.k8s.io/prow/pkg/plugins/pony"
 	_ "sigs.k8s.io/prow/pkg/plugins/project"
 	_ "sigs.k8s.io/prow/pkg/plugins/projectmanager"
+	_ "sigs.k8s.io/prow/pkg/plugins/projects-v2"
 	_ "sigs.k8s.io/prow/pkg/plugins/releasenote"
 	_ "sigs.k8s.io/prow/pkg/plugins/require-matching-label"
+	_ "sigs.k8s.io/prow/pkg/plugins/required-labels"
 	_ "sigs.k8s.io/prow/pkg/plugins/retitle"
+	_ "sigs.k8s.io/prow/pkg/plugins/shadow"
 	_ "sigs.k8s.io/prow/pkg/plugins/shrug"
 	_ "sigs.k8s.io/prow/pkg/plugins/sigmention"
 	_ "sigs.k8s.io/prow/pkg/plugins/size"
@@ -62,7 +67,9 @@ import (
 	_ "sigs.k8s.io/prow/pkg/plugins/slackevents"
 	_ "sigs.k8s.io/prow/pkg/plugins/stage"
 	_ "sigs.k8s.io/prow/pkg/plugins/testfreeze"
+	_ "sigs.k8s.io/prow/pkg/plugins/title-convention"
 	_ "sigs.k8s.io/prow/pkg/plugins/transfer-issue"
+	_ "sigs.k8s.io/prow/pkg/plugins/triage-rotation"
 	_ "sigs.k8s.io/prow/pkg/plugins/trick-or-treat"
 	_ "sigs.k8s.io/prow/pkg/plugins/trigger"
 	_ "sigs.k8s.io/prow/pkg/plugins/updateconfig"
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// EventQueue is a durable work queue of validated webhook events. It allows
+// running hook as receivers that only validate and enqueue events, and
+// stateless workers that handle them.
+type EventQueue interface {
+	// Enqueue persists the event. The event must not be acknowledged to
+	// GitHub before Enqueue returns.
+	Enqueue(ctx context.Context, e StoredEvent) error
+	// Receive calls handle for every event in the queue until the context is
+	// cancelled. Events are removed from the queue once handle returns.
+	Receive(ctx context.Context, handle func(StoredEvent)) error
+	// Close releases the resources of the queue.
+	Close() error
+}
+
+// pubsubEventQueue is an EventQueue backed by a Pub/Sub topic and
+// subscription. Receivers only need the topic and workers only the
+// subscription.
+type pubsubEventQueue struct {
+	client       *pubsub.Client
+	topic        *pubsub.Topic
+	subscription string
+}
+
+// NewPubSubEventQueue returns an EventQueue that publishes events to topic and
+// receives them from subscription in the given project. Either of topic and
+// subscription may be empty if the queue is only used to enqueue or receive
+// events.
+func NewPubSubEventQueue(ctx context.Context, project, topic, subscription string) (EventQueue, error) {
+	client, err := pubsub.NewClient(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("could not create pubsub client for project %q: %w", project, err)
+	}
+	q := &pubsubEventQueue{client: client, subscription: subscription}
+	if topic != "" {
+		q.topic = client.Topic(topic)
+	}
+	return q, nil
+}
+
+func (q *pubsubEventQueue) Enqueue(ctx context.Context, e StoredEvent) error {
+	if q.topic == nil {
+		return errors.New("no topic configured to enqueue events to")
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	msg := &pubsub.Message{
+		Data: data,
+		Attributes: map[string]string{
+			"event_type": e.EventType,
+			"event_guid": e.GUID,
+			"org_repo":   e.OrgRepo,
+		},
+	}
+	if _, err := q.topic.Publish(ctx, msg).Get(ctx); err != nil {
+		return fmt.Errorf("failed to publish to topic %s: %w", q.topic.String(), err)
+	}
+	return nil
+}
+
+func (q *pubsubEventQueue) Receive(ctx context.Context, handle func(StoredEvent)) error {
+	if q.subscription == "" {
+		return errors.New("no subscription configured to receive events from")
+	}
+	return q.client.Subscription(q.subscription).Receive(ctx, func(_ context.Context, msg *pubsub.Message) {
+		// Malformed messages are acknowledged, as redelivering them would
+		// not help.
+		defer msg.Ack()
+		var e StoredEvent
+		if err := json.Unmarshal(msg.Data, &e); err != nil {
+			logrus.WithError(err).WithField("message-id", msg.ID).Error("Failed to unmarshal queued event.")
+			return
+		}
+		handle(e)
+	})
+}
+
+func (q *pubsubEventQueue) Close() error {
+	if q.topic != nil {
+		q.topic.Stop()
+	}
+	return q.client.Close()
+}
+
+// enqueueEvent adds a validated event to the queue.
+func (s *Server) enqueueEvent(ctx context.Context, eventType, eventGUID string, payload []byte, h http.Header) error {
+	ctx, cancel := context.WithTimeout(ctx, pubsubPublishTimeout)
+	defer cancel()
+	return s.Queue.Enqueue(ctx, newStoredEvent(eventType, eventGUID, payload, h))
+}
+
+// ProcessQueue handles the events in the queue until the context is
+// cancelled. An event is only removed from the queue once all of its handlers
+// returned, so that the events of a worker that crashes are redelivered to
+// another one.
+func (s *Server) ProcessQueue(ctx context.Context) error {
+	if s.Queue == nil {
+		return errors.New("no event queue configured")
+	}
+	return s.Queue.Receive(ctx, func(e StoredEvent) {
+		if e.Header == nil {
+			e.Header = http.Header{}
+		}
+		var handlers sync.WaitGroup
+		if err := s.demuxEvent(withEventHandlers(context.Background(), &handlers), e.EventType, e.GUID, e.Payload, e.Header); err != nil {
+			logrus.WithError(err).WithField(github.EventGUID, e.GUID).Error("Error parsing event.")
+		}
+		handlers.Wait()
+	})
+}
+
+type eventHandlersKey struct{}
+
+// withEventHandlers returns a context in which the handlers of an event are
+// registered with handlers.
+func withEventHandlers(ctx context.Context, handlers *sync.WaitGroup) context.Context {
+	return context.WithValue(ctx, eventHandlersKey{}, handlers)
+}
+
+// eventHandlers returns the handlers of the event the logger is for, or nil
+// if they are not tracked.
+func eventHandlers(l *logrus.Entry) *sync.WaitGroup {
+	if l.Context == nil {
+		return nil
+	}
+	handlers, _ := l.Context.Value(eventHandlersKey{}).(*sync.WaitGroup)
+	return handlers
+}
+
+// startHandler registers a handler with the server, so that GracefulShutdown
+// waits for it, and with the event it handles.
+func (s *Server) startHandler(l *logrus.Entry) {
+	s.wg.Add(1)
+	if handlers := eventHandlers(l); handlers != nil {
+		handlers.Add(1)
+	}
+}
+
+// handlerDone unregisters a handler registered with startHandler.
+func (s *Server) handlerDone(l *logrus.Entry) {
+	if handlers := eventHandlers(l); handlers != nil {
+		handlers.Done()
+	}
+	s.wg.Done()
+}
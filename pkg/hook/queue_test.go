@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/prow/pkg/githubeventserver"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+type fakeEventQueue struct {
+	err    error
+	events []StoredEvent
+}
+
+func (q *fakeEventQueue) Enqueue(_ context.Context, e StoredEvent) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.events = append(q.events, e)
+	return nil
+}
+
+func (q *fakeEventQueue) Receive(_ context.Context, handle func(StoredEvent)) error {
+	for _, e := range q.events {
+		handle(e)
+	}
+	q.events = nil
+	return nil
+}
+
+func (q *fakeEventQueue) Close() error {
+	return nil
+}
+
+func TestEventQueue(t *testing.T) {
+	var lock sync.Mutex
+	var received []string
+	plugin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, r.Header.Get("X-GitHub-Delivery"))
+	}))
+	defer plugin.Close()
+
+	// This is the SHA1 signature for the payload below and the secret "abc".
+	// echo -n '{"repository":{"full_name":"org/repo"}}' | openssl dgst -sha1 -hmac abc
+	const payload = `{"repository":{"full_name":"org/repo"}}`
+	const hmac = "sha1=82476ccf9ceccacc0ef3e109cf363781e10eff16"
+	request := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(payload))
+		r.Header.Set("X-GitHub-Event", "fork")
+		r.Header.Set("X-GitHub-Delivery", "guid")
+		r.Header.Set("X-Hub-Signature", hmac)
+		r.Header.Set("content-type", "application/json")
+		return r
+	}
+
+	pa := &plugins.ConfigAgent{}
+	pa.Set(&plugins.Configuration{ExternalPlugins: map[string][]plugins.ExternalPlugin{
+		"org": {{Name: "coffeemachine", Endpoint: plugin.URL}},
+	}})
+	queue := &fakeEventQueue{err: errors.New("injected error")}
+	s := &Server{
+		Plugins:        pa,
+		Metrics:        githubeventserver.NewMetrics(),
+		RepoEnabled:    func(_, _ string) bool { return true },
+		TokenGenerator: func() []byte { return []byte("abc") },
+		Queue:          queue,
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, request())
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected code %d if the event cannot be enqueued, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	queue.err = nil
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, request())
+	if w.Code != http.StatusOK {
+		t.Errorf("expected code %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(queue.events) != 1 {
+		t.Fatalf("expected one event to be enqueued, got %d", len(queue.events))
+	}
+	if e := queue.events[0]; e.GUID != "guid" || e.EventType != "fork" || e.OrgRepo != "org/repo" || string(e.Payload) != payload {
+		t.Errorf("unexpected enqueued event: %+v", e)
+	}
+	s.GracefulShutdown()
+	if len(received) != 0 {
+		t.Fatalf("expected enqueued events not to be dispatched by the receiver, got %d", len(received))
+	}
+
+	if err := s.ProcessQueue(context.Background()); err != nil {
+		t.Fatalf("failed to process queue: %v", err)
+	}
+	// Events are removed from the queue once handle returns, which must only
+	// happen once the event was dispatched.
+	lock.Lock()
+	if len(received) != 1 || received[0] != "guid" {
+		t.Errorf("expected the queued event to be dispatched once before it is removed from the queue, got %v", received)
+	}
+	lock.Unlock()
+	s.GracefulShutdown()
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// commandRateLimiter counts the comments with commands per user and repo.
+type commandRateLimiter struct {
+	lock  sync.Mutex
+	clock clock.PassiveClock
+	// commands holds the times of the recent commands per user and repo.
+	commands map[string][]time.Time
+	// ignoredUntil holds the users that exceeded the limit and are ignored.
+	ignoredUntil map[string]time.Time
+	lastSweep    time.Time
+}
+
+// allow records a command of user in org/repo and returns whether it may be
+// handled. ignored is true if the user has just started to be ignored.
+func (r *commandRateLimiter) allow(limit plugins.CommandRateLimit, org, repo, user string) (allowed, ignored bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.clock == nil {
+		r.clock = clock.RealClock{}
+	}
+	if r.commands == nil {
+		r.commands = map[string][]time.Time{}
+		r.ignoredUntil = map[string]time.Time{}
+	}
+	now := r.clock.Now()
+	r.sweep(now, limit.WindowDuration)
+
+	key := strings.ToLower(org + "/" + repo + "/" + user)
+	if until, ok := r.ignoredUntil[key]; ok && now.Before(until) {
+		return false, false
+	}
+	recent := pruneBefore(r.commands[key], now.Add(-limit.WindowDuration))
+	if len(recent) >= limit.MaxCommands {
+		r.commands[key] = recent
+		if limit.IgnoreForDuration == 0 {
+			return false, false
+		}
+		r.ignoredUntil[key] = now.Add(limit.IgnoreForDuration)
+		return false, true
+	}
+	r.commands[key] = append(recent, now)
+	return true, false
+}
+
+// sweep forgets users that did not comment within the last window, so that
+// the limiter does not grow without bounds.
+func (r *commandRateLimiter) sweep(now time.Time, window time.Duration) {
+	if now.Sub(r.lastSweep) < window {
+		return
+	}
+	r.lastSweep = now
+	for key, times := range r.commands {
+		if recent := pruneBefore(times, now.Add(-window)); len(recent) > 0 {
+			r.commands[key] = recent
+		} else {
+			delete(r.commands, key)
+		}
+	}
+	for key, until := range r.ignoredUntil {
+		if !now.Before(until) {
+			delete(r.ignoredUntil, key)
+		}
+	}
+}
+
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	for i, t := range times {
+		if t.After(cutoff) {
+			return times[i:]
+		}
+	}
+	return nil
+}
+
+// hasCommand returns whether a comment contains a slash command.
+func hasCommand(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// throttleComment returns whether a comment event must not be handled at all
+// because its author exceeded the command rate limit. It is checked before the
+// event is dispatched to any plugin, external plugin or event forwarder.
+func throttleComment[E github.CommentLikeEventTypes](s *Server, l *logrus.Entry, event E) bool {
+	ce, err := github.GeneralizeComment(event)
+	if err != nil {
+		return false
+	}
+	return s.throttleCommands(l, ce)
+}
+
+// throttleCommands returns whether the comment must not be handled because
+// its author exceeded the command rate limit.
+func (s *Server) throttleCommands(l *logrus.Entry, ce *github.GenericCommentEvent) bool {
+	limit := s.Plugins.Config().CommandRateLimit
+	if limit.MaxCommands == 0 || ce.Action == github.GenericCommentActionDeleted || !hasCommand(ce.Body) {
+		return false
+	}
+	for _, exempt := range limit.ExemptUsers {
+		if strings.EqualFold(exempt, ce.User.Login) {
+			return false
+		}
+	}
+	org, repo := ce.Repo.Owner.Login, ce.Repo.Name
+	allowed, ignored := s.commandLimiter.allow(limit, org, repo, ce.User.Login)
+	if allowed {
+		return false
+	}
+	if ignored {
+		l.WithField("user", ce.User.Login).Warnf("User exceeded the command rate limit of %d commands per %s, ignoring their commands for %s.", limit.MaxCommands, limit.WindowDuration, limit.IgnoreForDuration)
+		s.Metrics.RateLimitedUsers.WithLabelValues(org, repo).Inc()
+	}
+	l.WithField("user", ce.User.Login).Info("Not handling commands of rate limited user.")
+	s.Metrics.ThrottledCommands.WithLabelValues(org, repo).Inc()
+	return true
+}
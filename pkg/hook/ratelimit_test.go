@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/githubeventserver"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestCommandRateLimiterAllow(t *testing.T) {
+	type step struct {
+		advance         time.Duration
+		user            string
+		expectedAllowed bool
+		expectedIgnored bool
+	}
+	testCases := []struct {
+		name  string
+		limit plugins.CommandRateLimit
+		steps []step
+	}{
+		{
+			name:  "commands over the limit are dropped until the window passed",
+			limit: plugins.CommandRateLimit{MaxCommands: 2, WindowDuration: time.Minute},
+			steps: []step{
+				{user: "alice", expectedAllowed: true},
+				{advance: 10 * time.Second, user: "alice", expectedAllowed: true},
+				{advance: 10 * time.Second, user: "alice"},
+				{user: "bob", expectedAllowed: true},
+				{advance: 45 * time.Second, user: "alice", expectedAllowed: true},
+			},
+		},
+		{
+			name:  "users exceeding the limit are ignored",
+			limit: plugins.CommandRateLimit{MaxCommands: 1, WindowDuration: time.Minute, IgnoreForDuration: 10 * time.Minute},
+			steps: []step{
+				{user: "alice", expectedAllowed: true},
+				{user: "alice", expectedIgnored: true},
+				{advance: 5 * time.Minute, user: "alice"},
+				{advance: 5 * time.Minute, user: "alice", expectedAllowed: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := clocktesting.NewFakePassiveClock(time.Now())
+			r := &commandRateLimiter{clock: clock}
+			for i, s := range tc.steps {
+				clock.SetTime(clock.Now().Add(s.advance))
+				allowed, ignored := r.allow(tc.limit, "org", "repo", s.user)
+				if allowed != s.expectedAllowed || ignored != s.expectedIgnored {
+					t.Errorf("step %d: expected allowed=%t ignored=%t, got allowed=%t ignored=%t", i, s.expectedAllowed, s.expectedIgnored, allowed, ignored)
+				}
+			}
+		})
+	}
+}
+
+func TestThrottleCommands(t *testing.T) {
+	pa := &plugins.ConfigAgent{}
+	pa.Set(&plugins.Configuration{
+		CommandRateLimit: plugins.CommandRateLimit{MaxCommands: 1, WindowDuration: time.Minute, ExemptUsers: []string{"ci-robot"}},
+	})
+	s := &Server{Plugins: pa, Metrics: githubeventserver.NewMetrics()}
+	comment := func(user, body string) *github.GenericCommentEvent {
+		return &github.GenericCommentEvent{
+			Action: github.GenericCommentActionCreated,
+			Body:   body,
+			User:   github.User{Login: user},
+			Repo:   github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+		}
+	}
+	l := logrus.WithField("test", t.Name())
+
+	for i, tc := range []struct {
+		event    *github.GenericCommentEvent
+		expected bool
+	}{
+		{event: comment("alice", "/retest"), expected: false},
+		{event: comment("alice", "/retest"), expected: true},
+		{event: comment("alice", "looks good to me"), expected: false},
+		{event: comment("ci-robot", "/lgtm"), expected: false},
+		{event: comment("ci-robot", "/approve"), expected: false},
+	} {
+		if actual := s.throttleCommands(l, tc.event); actual != tc.expected {
+			t.Errorf("comment %d: expected throttled %t, got %t", i, tc.expected, actual)
+		}
+	}
+}
+
+func TestDemuxEventThrottlesComments(t *testing.T) {
+	pa := &plugins.ConfigAgent{}
+	pa.Set(&plugins.Configuration{
+		CommandRateLimit: plugins.CommandRateLimit{MaxCommands: 1, WindowDuration: time.Minute},
+		ExternalPlugins: map[string][]plugins.ExternalPlugin{
+			"org/repo": {{Name: "coffee", Endpoint: "/coffee"}},
+		},
+	})
+	var dispatched int
+	var m sync.Mutex
+	client := newTestClient(func(req *http.Request) *http.Response {
+		m.Lock()
+		dispatched++
+		m.Unlock()
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`OK`)),
+			Header:     make(http.Header),
+		}
+	})
+	s := &Server{
+		Metrics:     githubeventserver.NewMetrics(),
+		Plugins:     pa,
+		RepoEnabled: func(org, repo string) bool { return true },
+		c:           *client,
+	}
+	payload := []byte(`{
+  "action": "created",
+  "issue": {"number": 1},
+  "comment": {"body": "/retest", "user": {"login": "alice"}},
+  "repository": {"full_name": "org/repo", "name": "repo", "owner": {"login": "org"}}
+}`)
+
+	for i, expected := range []int{1, 1} {
+		if err := s.demuxEvent(context.Background(), "issue_comment", "guid", payload, http.Header{}); err != nil {
+			t.Fatalf("comment %d: unexpected error: %v", i, err)
+		}
+		s.wg.Wait()
+		if dispatched != expected {
+			t.Errorf("comment %d: expected %d dispatched events, got %d", i, expected, dispatched)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package hook
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,12 +29,15 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/githubeventserver"
 	_ "sigs.k8s.io/prow/pkg/hook/plugin-imports"
 	"sigs.k8s.io/prow/pkg/plugins"
+	"sigs.k8s.io/prow/pkg/tracing"
 )
 
 // Server implements http.Handler. It validates incoming GitHub webhooks and
@@ -45,10 +49,27 @@ type Server struct {
 	TokenGenerator func() []byte
 	Metrics        *githubeventserver.Metrics
 	RepoEnabled    func(org, repo string) bool
+	// EventStore persists validated events for replay. Optional.
+	EventStore EventStore
+	// AuditSink records the write actions plugins take. Optional.
+	AuditSink github.AuditSink
+	// Queue decouples receiving events from handling them. If set, ServeHTTP
+	// only enqueues validated events and ProcessQueue handles them, possibly
+	// in another replica. Optional.
+	Queue EventQueue
 
 	// c is an http client used for dispatching events
 	// to external plugin services.
 	c http.Client
+	// grpcPlugins holds the connections to external plugins
+	// using the gRPC protocol.
+	grpcPlugins grpcPlugins
+	// publisher publishes events to the Pub/Sub topics of event
+	// forwarders. It is created on first use.
+	publisher     eventPublisher
+	publisherOnce sync.Once
+	// commandLimiter enforces the command rate limit.
+	commandLimiter commandRateLimiter
 	// Tracks running handlers for graceful shutdown
 	wg sync.WaitGroup
 }
@@ -67,15 +88,37 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	if s.Queue != nil {
+		// Only acknowledge the event once it is persisted. GitHub does not
+		// retry failed deliveries by itself, but they are listed as failed
+		// and can be redelivered from the webhook settings or the API.
+		if err := s.enqueueEvent(r.Context(), eventType, eventGUID, payload, r.Header); err != nil {
+			logrus.WithError(err).WithField(github.EventGUID, eventGUID).Error("Failed to enqueue event.")
+			http.Error(w, "500 Internal Server Error: Failed to enqueue event.", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "Event received. Have a nice day.")
+		s.storeEvent(eventType, eventGUID, payload, r.Header)
+		return
+	}
 	fmt.Fprint(w, "Event received. Have a nice day.")
 
-	if err := s.demuxEvent(eventType, eventGUID, payload, r.Header); err != nil {
+	s.storeEvent(eventType, eventGUID, payload, r.Header)
+	if err := s.demuxEvent(context.Background(), eventType, eventGUID, payload, r.Header); err != nil {
 		logrus.WithError(err).Error("Error parsing event.")
 	}
 }
 
-func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.Header) error {
-	l := logrus.WithFields(
+// demuxEvent dispatches an event to the handlers of the plugins, which run
+// asynchronously. They are registered with the event handlers in ctx, if any.
+func (s *Server) demuxEvent(ctx context.Context, eventType, eventGUID string, payload []byte, h http.Header) error {
+	// The span of the event is the parent of the spans of the ProwJobs that
+	// plugins create for it. It is passed on to the handlers in the context
+	// of the logger.
+	ctx, span := tracing.Tracer().Start(ctx, "webhook "+eventType, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("github.event", eventType), attribute.String("github.event_guid", eventGUID)))
+	defer span.End()
+	l := logrus.WithContext(ctx).WithFields(
 		logrus.Fields{
 			eventTypeField:   eventType,
 			github.EventGUID: eventGUID,
@@ -87,6 +130,9 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 	} else {
 		counter.Inc()
 	}
+	if dropped, err := s.dropEvent(l, eventType, payload); err != nil || dropped {
+		return err
+	}
 	var srcRepo string
 	switch eventType {
 	case "issues":
@@ -97,7 +143,7 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 		i.GUID = eventGUID
 		srcRepo = i.Repo.FullName
 		if s.RepoEnabled(i.Repo.Owner.Login, i.Repo.Name) {
-			s.wg.Add(1)
+			s.startHandler(l)
 			go s.handleIssueEvent(l, i)
 		}
 	case "issue_comment":
@@ -107,8 +153,11 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 		}
 		ic.GUID = eventGUID
 		srcRepo = ic.Repo.FullName
+		if throttleComment(s, l, ic) {
+			return nil
+		}
 		if s.RepoEnabled(ic.Repo.Owner.Login, ic.Repo.Name) {
-			s.wg.Add(1)
+			s.startHandler(l)
 			go s.handleIssueCommentEvent(l, ic)
 		}
 	case "pull_request":
@@ -119,7 +168,7 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 		pr.GUID = eventGUID
 		srcRepo = pr.Repo.FullName
 		if s.RepoEnabled(pr.Repo.Owner.Login, pr.Repo.Name) {
-			s.wg.Add(1)
+			s.startHandler(l)
 			go s.handlePullRequestEvent(l, pr)
 		}
 	case "pull_request_review":
@@ -129,8 +178,11 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 		}
 		re.GUID = eventGUID
 		srcRepo = re.Repo.FullName
+		if throttleComment(s, l, re) {
+			return nil
+		}
 		if s.RepoEnabled(re.Repo.Owner.Login, re.Repo.Name) {
-			s.wg.Add(1)
+			s.startHandler(l)
 			go s.handleReviewEvent(l, re)
 		}
 	case "pull_request_review_comment":
@@ -140,8 +192,11 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 		}
 		rce.GUID = eventGUID
 		srcRepo = rce.Repo.FullName
+		if throttleComment(s, l, rce) {
+			return nil
+		}
 		if s.RepoEnabled(rce.Repo.Owner.Login, rce.Repo.Name) {
-			s.wg.Add(1)
+			s.startHandler(l)
 			go s.handleReviewCommentEvent(l, rce)
 		}
 	case "push":
@@ -152,7 +207,7 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 		pe.GUID = eventGUID
 		srcRepo = pe.Repo.FullName
 		if s.RepoEnabled(pe.Repo.Owner.Login, pe.Repo.Name) {
-			s.wg.Add(1)
+			s.startHandler(l)
 			go s.handlePushEvent(l, pe)
 		}
 	case "status":
@@ -163,7 +218,7 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 		se.GUID = eventGUID
 		srcRepo = se.Repo.FullName
 		if s.RepoEnabled(se.Repo.Owner.Login, se.Repo.Name) {
-			s.wg.Add(1)
+			s.startHandler(l)
 			go s.handleStatusEvent(l, se)
 		}
 	default:
@@ -174,14 +229,42 @@ func (s *Server) demuxEvent(eventType, eventGUID string, payload []byte, h http.
 		srcRepo = ge.Repo.FullName
 		l.Debug("Ignoring unhandled event type. (Might still be handled by external plugins.)")
 	}
+	s.forwardEvent(l, eventType, eventGUID, srcRepo, payload, h)
 	// Demux events only to external plugins that require this event.
 	if external := s.needDemux(eventType, srcRepo); len(external) > 0 {
-		s.wg.Add(1)
-		go s.demuxExternal(l, external, payload, h)
+		s.startHandler(l)
+		go s.demuxExternal(l, external, eventType, eventGUID, srcRepo, payload, h)
 	}
 	return nil
 }
 
+// dropEvent returns whether the event filter drops an event.
+func (s *Server) dropEvent(l *logrus.Entry, eventType string, payload []byte) (bool, error) {
+	filter := s.Plugins.Config().EventFilter
+	if len(filter.AllowedRepos) == 0 && len(filter.DeniedRepos) == 0 && len(filter.AllowedEventTypes) == 0 && len(filter.DeniedEventTypes) == 0 {
+		return false, nil
+	}
+	var ge github.GenericEvent
+	if err := json.Unmarshal(payload, &ge); err != nil {
+		return false, err
+	}
+	org, repo := ge.Org.Login, ""
+	if parts := strings.SplitN(ge.Repo.FullName, "/", 2); len(parts) == 2 {
+		org, repo = parts[0], parts[1]
+	}
+	dropped, reason := filter.Drops(eventType, org, repo)
+	if !dropped {
+		return false, nil
+	}
+	l.WithFields(logrus.Fields{github.OrgLogField: org, github.RepoLogField: repo, "reason": reason}).Debug("Dropping event.")
+	if counter, err := s.Metrics.DroppedEventCounter.GetMetricWithLabelValues(eventType, reason); err != nil {
+		l.WithError(err).Warn("Failed to get metric for dropped event " + eventType)
+	} else {
+		counter.Inc()
+	}
+	return true, nil
+}
+
 // needDemux returns whether there are any external plugins that need to
 // get the present event.
 func (s *Server) needDemux(eventType, orgRepo string) []plugins.ExternalPlugin {
@@ -221,13 +304,21 @@ func (s *Server) needDemux(eventType, orgRepo string) []plugins.ExternalPlugin {
 }
 
 // demuxExternal dispatches the provided payload to the external plugins.
-func (s *Server) demuxExternal(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, payload []byte, h http.Header) {
-	defer s.wg.Done()
+func (s *Server) demuxExternal(l *logrus.Entry, externalPlugins []plugins.ExternalPlugin, eventType, eventGUID, srcRepo string, payload []byte, h http.Header) {
+	defer s.handlerDone(l)
 	h.Set("User-Agent", "ProwHook")
 	for _, p := range externalPlugins {
-		s.wg.Add(1)
+		s.startHandler(l)
 		go func(p plugins.ExternalPlugin) {
-			defer s.wg.Done()
+			defer s.handlerDone(l)
+			if p.Protocol == plugins.ExternalPluginProtocolGRPC {
+				if delivered, err := s.dispatchGRPC(l, p, eventType, eventGUID, srcRepo, payload); err != nil {
+					l.WithError(err).WithField("external-plugin", p.Name).Error("Error dispatching event to external plugin.")
+				} else if delivered {
+					l.WithField("external-plugin", p.Name).Info("Dispatched event to external plugin")
+				}
+				return
+			}
 			if err := s.dispatch(p.Endpoint, payload, h); err != nil {
 				l.WithError(err).WithField("external-plugin", p.Name).Error("Error dispatching event to external plugin.")
 			} else {
@@ -264,6 +355,11 @@ func (s *Server) dispatch(endpoint string, payload []byte, h http.Header) error
 // receiving the shutdown signal.
 func (s *Server) GracefulShutdown() {
 	s.wg.Wait() // Handle remaining requests
+	s.grpcPlugins.close()
+	s.publisherOnce.Do(func() {})
+	if s.publisher != nil {
+		s.publisher.close()
+	}
 }
 
 func (s *Server) do(req *http.Request) (*http.Response, error) {
@@ -476,17 +476,14 @@ foo/bar:
 }`
 
 	metrics := githubeventserver.NewMetrics()
-	pa := &plugins.ConfigAgent{}
-	pa.Set(&plugins.Configuration{
-		ExternalPlugins: externalPlugins,
-	})
 
 	var testcases = []struct {
 		name string
 
-		Method string
-		Header map[string]string
-		Body   string
+		Method      string
+		Header      map[string]string
+		Body        string
+		EventFilter plugins.EventFilter
 
 		ExpectedDispatch []string
 	}{
@@ -532,12 +529,59 @@ foo/bar:
 
 			ExpectedDispatch: []string{"/coffee", "/water", "/unknown"},
 		},
+		{
+			name: "Event from a denied repo is dropped",
+
+			Method: http.MethodPost,
+			Header: map[string]string{
+				"X-GitHub-Event":    "repository",
+				"X-GitHub-Delivery": "I am unique",
+				"X-Hub-Signature":   hmac,
+				"content-type":      "application/json",
+			},
+			Body:        body,
+			EventFilter: plugins.EventFilter{DeniedRepos: []string{"kubernetes/test-infra"}},
+		},
+		{
+			name: "Event type outside the allowlist is dropped",
+
+			Method: http.MethodPost,
+			Header: map[string]string{
+				"X-GitHub-Event":    "repository",
+				"X-GitHub-Delivery": "I am unique",
+				"X-Hub-Signature":   hmac,
+				"content-type":      "application/json",
+			},
+			Body:        body,
+			EventFilter: plugins.EventFilter{AllowedEventTypes: []string{"pull_request"}},
+		},
+		{
+			name: "Event from an allowed org is dispatched",
+
+			Method: http.MethodPost,
+			Header: map[string]string{
+				"X-GitHub-Event":    "repository",
+				"X-GitHub-Delivery": "I am unique",
+				"X-Hub-Signature":   hmac,
+				"content-type":      "application/json",
+			},
+			Body:        body,
+			EventFilter: plugins.EventFilter{AllowedRepos: []string{"kubernetes"}},
+
+			ExpectedDispatch: []string{"/chicken", "/chocolate", "/coffee", "/water"},
+		},
 	}
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Logf("Running scenario %q", tc.name)
 
+			pa := &plugins.ConfigAgent{}
+			pa.Set(&plugins.Configuration{
+				ExternalPlugins: externalPlugins,
+				EventFilter:     tc.EventFilter,
+			})
+
 			var calledExternalPlugins []string
 			var m sync.Mutex
 
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interrupts
+
+import (
+	"context"
+	"net"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// GRPCServer is a wrapper type around a gRPC server and its listener, so that
+// it can be passed to ListenAndServe.
+type GRPCServer struct {
+	Server   *grpc.Server
+	Listener net.Listener
+}
+
+// Shutdown shuts down the inner gRPC server as gracefully as possible, by first
+// invoking GracefulStop() on it. This gives the server time to try to handle
+// things gracefully internally. However if it takes too long (if the parent
+// context cancels us), we forcefully kill the server by calling Stop(). Stop()
+// interrupts GracefulStop() (see
+// https://pkg.go.dev/google.golang.org/grpc#Server.Stop).
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	gracefulStopFinished := make(chan struct{})
+
+	go func() {
+		s.Server.GracefulStop()
+		close(gracefulStopFinished)
+	}()
+
+	select {
+	case <-gracefulStopFinished:
+		return nil
+	case <-ctx.Done():
+		s.Server.Stop()
+		return ctx.Err()
+	}
+}
+
+// ListenAndServe serves gRPC requests on the listener until the server is
+// shut down.
+func (s *GRPCServer) ListenAndServe() error {
+	logrus.Infof("serving gRPC on %s", s.Listener.Addr())
+	return s.Server.Serve(s.Listener)
+}
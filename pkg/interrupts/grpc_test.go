@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interrupts
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestGRPCServer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &GRPCServer{Server: grpc.NewServer(), Listener: lis}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.ListenAndServe()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("failed to shut down: %v", err)
+	}
+	select {
+	case err := <-served:
+		// Serve returns ErrServerStopped if the server was shut down
+		// before it started serving.
+		if err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("expected serving to end without an error, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Error("serving did not end after shutting down")
+	}
+}
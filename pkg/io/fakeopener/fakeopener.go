@@ -19,9 +19,13 @@ package fakeopener
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
 	pkgio "sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/io/providers"
 )
 
 type FakeOpener struct {
@@ -85,3 +89,63 @@ func (fo *FakeOpener) Writer(ctx context.Context, path string, opts ...pkgio.Wri
 
 	return &nopReadWriteCloser{Buffer: fo.Buffer[path]}, nil
 }
+
+func (fo *FakeOpener) Delete(ctx context.Context, path string) error {
+	if fo.WriteError != nil {
+		return fo.WriteError
+	}
+	if _, ok := fo.Buffer[path]; !ok {
+		return os.ErrNotExist
+	}
+	delete(fo.Buffer, path)
+	return nil
+}
+
+// Iterator lists the buffers below the prefix. Like the storage iterators,
+// it returns the names of the objects relative to their bucket.
+func (fo *FakeOpener) Iterator(ctx context.Context, prefix, delimiter string) (pkgio.ObjectIterator, error) {
+	storageProvider, bucket, relativePath, err := providers.ParseStoragePath(prefix)
+	if err != nil {
+		return nil, err
+	}
+	bucketPrefix := storageProvider + "://" + bucket + "/"
+
+	var attrs []pkgio.ObjectAttributes
+	dirs := map[string]bool{}
+	for path, buf := range fo.Buffer {
+		name, ok := strings.CutPrefix(path, bucketPrefix)
+		if !ok || !strings.HasPrefix(name, relativePath) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(name[len(relativePath):], delimiter); i >= 0 {
+				dir := name[:len(relativePath)+i+len(delimiter)]
+				if !dirs[dir] {
+					dirs[dir] = true
+					attrs = append(attrs, pkgio.ObjectAttributes{Name: dir, IsDir: true})
+				}
+				continue
+			}
+		}
+		attrs = append(attrs, pkgio.ObjectAttributes{
+			Name:    name,
+			ObjName: name[strings.LastIndex(name, "/")+1:],
+			Size:    int64(buf.Len()),
+		})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+	return &fakeObjectIterator{attrs: attrs}, nil
+}
+
+type fakeObjectIterator struct {
+	attrs []pkgio.ObjectAttributes
+}
+
+func (fi *fakeObjectIterator) Next(ctx context.Context) (pkgio.ObjectAttributes, error) {
+	if len(fi.attrs) == 0 {
+		return pkgio.ObjectAttributes{}, io.EOF
+	}
+	attr := fi.attrs[0]
+	fi.attrs = fi.attrs[1:]
+	return attr, nil
+}
@@ -93,6 +93,7 @@ type Opener interface {
 	SignedURL(ctx context.Context, path string, opts SignedURLOptions) (string, error)
 	Iterator(ctx context.Context, prefix, delimiter string) (ObjectIterator, error)
 	UpdateAtributes(context.Context, string, ObjectAttrsToUpdate) (*Attributes, error)
+	Delete(ctx context.Context, path string) error
 }
 
 type opener struct {
@@ -391,6 +392,26 @@ func (o *opener) UpdateAtributes(ctx context.Context, path string, attrs ObjectA
 	}, nil
 }
 
+// Delete removes the path, returning an IsNotExist() error when missing
+func (o *opener) Delete(ctx context.Context, path string) error {
+	if strings.HasPrefix(path, providers.GS+"://") {
+		g, err := o.openGCS(path)
+		if err != nil {
+			return fmt.Errorf("bad gcs path: %w", err)
+		}
+		return g.Delete(ctx)
+	}
+	if strings.HasPrefix(path, "/") {
+		return os.Remove(path)
+	}
+
+	bucket, relativePath, err := o.getBucket(ctx, path)
+	if err != nil {
+		return err
+	}
+	return bucket.Delete(ctx, relativePath)
+}
+
 const (
 	GSAnonHost   = "storage.googleapis.com"
 	GSCookieHost = "storage.cloud.google.com"
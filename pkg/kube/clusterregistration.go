@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/version"
+)
+
+const (
+	// ClusterRegistrationTokenKey is the key of the bearer token in the auth
+	// secret of a ClusterRegistration using token authentication.
+	ClusterRegistrationTokenKey = "token"
+	// ClusterRegistrationCertKey and ClusterRegistrationKeyKey are the keys of
+	// the client certificate and key in the auth secret of a
+	// ClusterRegistration using client certificate authentication.
+	ClusterRegistrationCertKey = corev1.TLSCertKey
+	ClusterRegistrationKeyKey  = corev1.TLSPrivateKeyKey
+)
+
+// ClusterRegistrationConfig builds the rest.Config for a registered build
+// cluster, reading its credentials from the auth secret.
+func ClusterRegistrationConfig(ctx context.Context, client ctrlruntimeclient.Reader, registration *prowapi.ClusterRegistration) (*rest.Config, error) {
+	if err := registration.Spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cluster registration %s: %w", registration.Name, err)
+	}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: registration.Namespace, Name: registration.Spec.Auth.SecretName}
+	if err := client.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("get auth secret %s: %w", key, err)
+	}
+
+	cfg := &rest.Config{
+		Host:            registration.Spec.Endpoint,
+		TLSClientConfig: rest.TLSClientConfig{CAData: registration.Spec.CAData},
+		UserAgent:       version.UserAgent(),
+	}
+	switch registration.Spec.Auth.Method {
+	case prowapi.TokenClusterAuth:
+		token := secret.Data[ClusterRegistrationTokenKey]
+		if len(token) == 0 {
+			return nil, fmt.Errorf("auth secret %s has no %q key", key, ClusterRegistrationTokenKey)
+		}
+		cfg.BearerToken = string(token)
+	case prowapi.ClientCertificateClusterAuth:
+		cert, certKey := secret.Data[ClusterRegistrationCertKey], secret.Data[ClusterRegistrationKeyKey]
+		if len(cert) == 0 || len(certKey) == 0 {
+			return nil, fmt.Errorf("auth secret %s must have %q and %q keys", key, ClusterRegistrationCertKey, ClusterRegistrationKeyKey)
+		}
+		cfg.CertData = cert
+		cfg.KeyData = certKey
+	}
+	return cfg, nil
+}
+
+// ClusterRegistrationConfigs builds the rest.Configs for all build clusters
+// registered in the namespace, keyed by the name of their registration.
+// Registrations for disabled clusters are skipped. Registrations that cannot be
+// turned into a config are reported in the aggregated error, while the configs
+// of all others are still returned.
+func ClusterRegistrationConfigs(ctx context.Context, client ctrlruntimeclient.Reader, namespace string, disabledClusters sets.Set[string]) (map[string]rest.Config, error) {
+	registrations := &prowapi.ClusterRegistrationList{}
+	if err := client.List(ctx, registrations, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list cluster registrations: %w", err)
+	}
+
+	configs := map[string]rest.Config{}
+	var errs []error
+	for i := range registrations.Items {
+		registration := &registrations.Items[i]
+		if disabledClusters.Has(registration.Name) {
+			logrus.WithField("disabledCluster", registration.Name).Info("Skipped cluster registration for disabled cluster")
+			continue
+		}
+		cfg, err := ClusterRegistrationConfig(ctx, client, registration)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		configs[registration.Name] = *cfg
+		logrus.WithField("cluster", registration.Name).Info("Loaded cluster registration")
+	}
+	return configs, utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestClusterRegistrationConfigs(t *testing.T) {
+	registration := func(name string, method prowapi.ClusterAuthMethod, secret string) *prowapi.ClusterRegistration {
+		return &prowapi.ClusterRegistration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: name},
+			Spec: prowapi.ClusterRegistrationSpec{
+				Endpoint: "https://" + name + ".example.com",
+				CAData:   []byte("ca"),
+				Auth:     prowapi.ClusterAuth{Method: method, SecretName: secret},
+			},
+		}
+	}
+	secret := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "prow", Name: name}, Data: data}
+	}
+
+	testCases := []struct {
+		name             string
+		objects          []ctrlruntimeclient.Object
+		disabledClusters sets.Set[string]
+		expected         map[string]rest.Config
+		expectedErr      bool
+	}{
+		{
+			name: "token and client certificate registrations are loaded",
+			objects: []ctrlruntimeclient.Object{
+				registration("build01", prowapi.TokenClusterAuth, "build01-token"),
+				secret("build01-token", map[string][]byte{ClusterRegistrationTokenKey: []byte("abc")}),
+				registration("build02", prowapi.ClientCertificateClusterAuth, "build02-cert"),
+				secret("build02-cert", map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")}),
+			},
+			expected: map[string]rest.Config{
+				"build01": {
+					Host:            "https://build01.example.com",
+					BearerToken:     "abc",
+					TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca")},
+				},
+				"build02": {
+					Host:            "https://build02.example.com",
+					TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca"), CertData: []byte("cert"), KeyData: []byte("key")},
+				},
+			},
+		},
+		{
+			name: "disabled clusters are skipped",
+			objects: []ctrlruntimeclient.Object{
+				registration("build01", prowapi.TokenClusterAuth, "build01-token"),
+				secret("build01-token", map[string][]byte{ClusterRegistrationTokenKey: []byte("abc")}),
+			},
+			disabledClusters: sets.New[string]("build01"),
+			expected:         map[string]rest.Config{},
+		},
+		{
+			name: "broken registrations are reported but do not prevent loading the others",
+			objects: []ctrlruntimeclient.Object{
+				registration("build01", prowapi.TokenClusterAuth, "build01-token"),
+				secret("build01-token", map[string][]byte{ClusterRegistrationTokenKey: []byte("abc")}),
+				registration("missing-secret", prowapi.TokenClusterAuth, "missing"),
+				registration("missing-key", prowapi.TokenClusterAuth, "empty"),
+				secret("empty", nil),
+				registration("invalid", "password", "build01-token"),
+			},
+			expected: map[string]rest.Config{
+				"build01": {
+					Host:            "https://build01.example.com",
+					BearerToken:     "abc",
+					TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca")},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.objects...).Build()
+			actual, err := ClusterRegistrationConfigs(context.Background(), client, "prow", tc.disabledClusters)
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expected, actual, cmpopts.IgnoreFields(rest.Config{}, "UserAgent")); diff != "" {
+				t.Errorf("unexpected configs: %s", diff)
+			}
+		})
+	}
+}
@@ -66,6 +66,16 @@ const (
 	PullLabel = "prow.k8s.io/refs.pull"
 	// RetestLabel exposes if the job was created by a re-test request.
 	RetestLabel = "prow.k8s.io/retest"
+	// OnDemandBatchLabel marks batch jobs that were requested with /test-batch
+	// rather than created by tide. Their results are reported on every PR of
+	// the batch.
+	OnDemandBatchLabel = "prow.k8s.io/on-demand-batch"
+	// BisectionLabel marks postsubmits created by the bisection controller and
+	// carries the name of the failed ProwJob whose regression they bisect.
+	BisectionLabel = "prow.k8s.io/bisection"
+	// BisectionCulpritAnnotation is added to a failed postsubmit once the
+	// bisection of its regression is reported and carries the culprit commits.
+	BisectionCulpritAnnotation = "prow.k8s.io/bisection-culprit"
 	// IsOptionalLabel is added in resources created by prow and
 	// carries the Optional from a Presubmit job.
 	IsOptionalLabel = "prow.k8s.io/is-optional"
@@ -82,4 +92,17 @@ const (
 	GerritPatchset = "prow.k8s.io/gerrit-patchset"
 	// GerritReportLabel is the gerrit label prow will cast vote on, fallback to CodeReview label if unset
 	GerritReportLabel = "prow.k8s.io/gerrit-report-label"
+
+	// GitLab related labels that are used by Prow
+
+	// GitLabInstance is the GitLab host url
+	GitLabInstance = "prow.k8s.io/gitlab-instance"
+	// GitLabProjectID is the numeric ID of the GitLab project a job reports to
+	GitLabProjectID = "prow.k8s.io/gitlab-project-id"
+
+	// Bitbucket related labels that are used by Prow
+
+	// BitbucketInstance is the Bitbucket Server host url, jobs with this
+	// annotation report build statuses to Bitbucket instead of GitHub
+	BitbucketInstance = "prow.k8s.io/bitbucket-instance"
 )
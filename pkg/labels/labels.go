@@ -31,6 +31,7 @@ const (
 	CpApproved                  = "cherry-pick-approved"
 	CpUnapproved                = "do-not-merge/cherry-pick-not-approved"
 	DeprecationLabel            = "kind/deprecation"
+	DependencyAutoApproved      = "dependency-auto-approved"
 	GoodFirstIssue              = "good first issue"
 	Help                        = "help wanted"
 	Hold                        = "do-not-merge/hold"
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logrusutil
+
+import (
+	"github.com/sirupsen/logrus"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// The names of the fields that correlate the log lines of the components
+// handling the same event or ProwJob. All components use the same names, so
+// that log pipelines can join their log lines.
+const (
+	// ComponentField is the name of the component writing the log line. It
+	// is set on every log line by ComponentInit.
+	ComponentField = "component"
+	// EventGUIDField is the GUID of the webhook event being handled, or that
+	// triggered a ProwJob. ProwJobs carry it in a label of the same name.
+	EventGUIDField = github.EventGUID
+	// ProwJobField is the name of a ProwJob.
+	ProwJobField = "prowjob"
+	// ProwJobUIDField is the UID of a ProwJob, which unlike its name is never
+	// reused.
+	ProwJobUIDField = "prowjob-uid"
+	// JobField is the name of the job a ProwJob runs.
+	JobField = "job"
+	// OrgField is the org of a repo, or the Gerrit instance of a project.
+	OrgField = github.OrgLogField
+	// RepoField is a repo, or a Gerrit project.
+	RepoField = github.RepoLogField
+	// PRField is the number of a pull request or a Gerrit change.
+	PRField = github.PrLogField
+)
+
+// ProwJobFields returns the fields that correlate the log lines about a
+// ProwJob: its name, UID and job, the event that triggered it and the repo it
+// tests, along with its type and state.
+func ProwJobFields(pj *prowapi.ProwJob) logrus.Fields {
+	fields := logrus.Fields{
+		ProwJobField: pj.Name,
+		JobField:     pj.Spec.Job,
+		"type":       pj.Spec.Type,
+		"state":      pj.Status.State,
+	}
+	if pj.UID != "" {
+		fields[ProwJobUIDField] = string(pj.UID)
+	}
+	if guid := pj.Labels[EventGUIDField]; guid != "" {
+		fields[EventGUIDField] = guid
+	}
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	if refs != nil {
+		fields[OrgField] = refs.Org
+		fields[RepoField] = refs.Repo
+		if len(refs.Pulls) == 1 && pj.Spec.Refs != nil {
+			fields[PRField] = refs.Pulls[0].Number
+		}
+	}
+	return fields
+}
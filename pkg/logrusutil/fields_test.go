@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logrusutil
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+func TestProwJobFields(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pj       *prowapi.ProwJob
+		expected logrus.Fields
+	}{
+		{
+			name: "presubmit triggered by an event",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "pj", UID: "uid", Labels: map[string]string{"event-GUID": "guid"}},
+				Spec: prowapi.ProwJobSpec{
+					Job:  "pull-test",
+					Type: prowapi.PresubmitJob,
+					Refs: &prowapi.Refs{Org: "org", Repo: "repo", Pulls: []prowapi.Pull{{Number: 1}}},
+				},
+				Status: prowapi.ProwJobStatus{State: prowapi.PendingState},
+			},
+			expected: logrus.Fields{
+				"prowjob":     "pj",
+				"prowjob-uid": "uid",
+				"event-GUID":  "guid",
+				"job":         "pull-test",
+				"type":        prowapi.PresubmitJob,
+				"state":       prowapi.PendingState,
+				"org":         "org",
+				"repo":        "repo",
+				"pr":          1,
+			},
+		},
+		{
+			name: "periodic with extra refs",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "pj"},
+				Spec: prowapi.ProwJobSpec{
+					Job:       "ci-test",
+					Type:      prowapi.PeriodicJob,
+					ExtraRefs: []prowapi.Refs{{Org: "org", Repo: "repo", Pulls: []prowapi.Pull{{Number: 1}}}, {Org: "other", Repo: "repo"}},
+				},
+			},
+			expected: logrus.Fields{
+				"prowjob": "pj",
+				"job":     "ci-test",
+				"type":    prowapi.PeriodicJob,
+				"state":   prowapi.ProwJobState(""),
+				"org":     "org",
+				"repo":    "repo",
+			},
+		},
+		{
+			name: "batch",
+			pj: &prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "pj"},
+				Spec: prowapi.ProwJobSpec{
+					Job:  "pull-test",
+					Type: prowapi.BatchJob,
+					Refs: &prowapi.Refs{Org: "org", Repo: "repo", Pulls: []prowapi.Pull{{Number: 1}, {Number: 2}}},
+				},
+				Status: prowapi.ProwJobStatus{State: prowapi.SuccessState},
+			},
+			expected: logrus.Fields{
+				"prowjob": "pj",
+				"job":     "pull-test",
+				"type":    prowapi.BatchJob,
+				"state":   prowapi.SuccessState,
+				"org":     "org",
+				"repo":    "repo",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, ProwJobFields(tc.pj)); diff != "" {
+				t.Errorf("fields mismatch. Want(-), got(+):\n%s", diff)
+			}
+		})
+	}
+}
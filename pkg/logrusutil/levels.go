@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logrusutil
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SubsystemFields are the fields that name the subsystem of a component a log
+// line is written by, e.g. the controllers of Tide or the plugins of Hook. The
+// level of a subsystem can be set apart from the one of its component.
+var SubsystemFields = []string{"controller", "plugin"}
+
+// levels holds the log levels of the component and of its subsystems, both
+// the ones of the config and the ones set at runtime, which take precedence
+// until they are reset.
+var levels = struct {
+	lock              sync.RWMutex
+	configured        logrus.Level
+	configSubsystems  map[string]logrus.Level
+	runtime           *logrus.Level
+	runtimeSubsystems map[string]logrus.Level
+	// subsystems are the effective levels of the subsystems.
+	subsystems map[string]logrus.Level
+}{
+	configured: logrus.InfoLevel,
+}
+
+// SetConfiguredLevels sets the levels of the component and of its subsystems
+// from its config. The levels set at runtime take precedence.
+func SetConfiguredLevels(level logrus.Level, subsystems map[string]logrus.Level) {
+	levels.lock.Lock()
+	defer levels.lock.Unlock()
+	levels.configured, levels.configSubsystems = level, subsystems
+	applyLevels()
+}
+
+// SetLevel sets the level of a subsystem at runtime, or the one of the
+// component if the subsystem is empty.
+func SetLevel(subsystem string, level logrus.Level) {
+	levels.lock.Lock()
+	defer levels.lock.Unlock()
+	if subsystem == "" {
+		levels.runtime = &level
+	} else {
+		if levels.runtimeSubsystems == nil {
+			levels.runtimeSubsystems = map[string]logrus.Level{}
+		}
+		levels.runtimeSubsystems[subsystem] = level
+	}
+	applyLevels()
+}
+
+// ResetLevel drops the level set at runtime for a subsystem, or for the
+// component if the subsystem is empty, going back to the configured one.
+func ResetLevel(subsystem string) {
+	levels.lock.Lock()
+	defer levels.lock.Unlock()
+	if subsystem == "" {
+		levels.runtime = nil
+	} else {
+		delete(levels.runtimeSubsystems, subsystem)
+	}
+	applyLevels()
+}
+
+// applyLevels sets the level of the standard logger to the most verbose one,
+// so that it writes the entries of the subsystems logging at a higher level
+// than their component. DefaultFieldsFormatter drops the other entries.
+// The caller must hold the lock of the levels.
+func applyLevels() {
+	level := levels.configured
+	if levels.runtime != nil {
+		level = *levels.runtime
+	}
+	subsystems := map[string]logrus.Level{}
+	for subsystem, subsystemLevel := range levels.configSubsystems {
+		subsystems[subsystem] = subsystemLevel
+	}
+	for subsystem, subsystemLevel := range levels.runtimeSubsystems {
+		subsystems[subsystem] = subsystemLevel
+	}
+	// Only keep the subsystems that don't log at the level of their
+	// component, so that entries are only filtered if needed.
+	loggerLevel := level
+	for subsystem, subsystemLevel := range subsystems {
+		if subsystemLevel == level {
+			delete(subsystems, subsystem)
+		}
+		if subsystemLevel > loggerLevel {
+			loggerLevel = subsystemLevel
+		}
+	}
+	levels.subsystems = subsystems
+	logrus.SetLevel(loggerLevel)
+}
+
+// currentLevels returns the effective level of the component and the ones of
+// its subsystems.
+func currentLevels() (logrus.Level, map[string]logrus.Level) {
+	levels.lock.RLock()
+	defer levels.lock.RUnlock()
+	level := levels.configured
+	if levels.runtime != nil {
+		level = *levels.runtime
+	}
+	subsystems := make(map[string]logrus.Level, len(levels.subsystems))
+	for subsystem, subsystemLevel := range levels.subsystems {
+		subsystems[subsystem] = subsystemLevel
+	}
+	return level, subsystems
+}
+
+// enabled returns whether an entry is logged at the level of its subsystem,
+// or of the component if it isn't written by a subsystem with its own level.
+func enabled(entry *logrus.Entry) bool {
+	levels.lock.RLock()
+	defer levels.lock.RUnlock()
+	if len(levels.subsystems) == 0 {
+		return true
+	}
+	level := levels.configured
+	if levels.runtime != nil {
+		level = *levels.runtime
+	}
+	for _, field := range SubsystemFields {
+		if subsystem, ok := entry.Data[field].(string); ok {
+			if subsystemLevel, ok := levels.subsystems[subsystem]; ok {
+				level = subsystemLevel
+				break
+			}
+		}
+	}
+	return entry.Level <= level
+}
+
+// levelsResponse is the body of the responses of the LevelHandler.
+type levelsResponse struct {
+	Level      string            `json:"level"`
+	Subsystems map[string]string `json:"subsystems,omitempty"`
+}
+
+// LevelHandler serves the log levels of the component, which requests
+// authenticated with the bearer token can change:
+//
+//	GET    /debug/loglevel                         returns the levels
+//	PUT    /debug/loglevel?level=debug             sets the level of the component
+//	PUT    /debug/loglevel?level=debug&subsystem=x sets the level of a subsystem
+//	DELETE /debug/loglevel[?subsystem=x]           goes back to the configured level
+func LevelHandler(token func() []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := token()
+		provided := []byte(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if len(expected) == 0 || subtle.ConstantTimeCompare(expected, provided) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		subsystem := r.URL.Query().Get("subsystem")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			level, err := logrus.ParseLevel(r.URL.Query().Get("level"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			SetLevel(subsystem, level)
+			logrus.WithField("subsystem", subsystem).Infof("Log level set to %s.", level)
+		case http.MethodDelete:
+			ResetLevel(subsystem)
+			logrus.WithField("subsystem", subsystem).Info("Log level reset to the configured one.")
+		default:
+			http.Error(w, fmt.Sprintf("Method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		level, subsystems := currentLevels()
+		response := levelsResponse{Level: level.String()}
+		for subsystem, subsystemLevel := range subsystems {
+			if response.Subsystems == nil {
+				response.Subsystems = map[string]string{}
+			}
+			response.Subsystems[subsystem] = subsystemLevel.String()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			logrus.WithError(err).Error("Failed to write the log levels.")
+		}
+	})
+}
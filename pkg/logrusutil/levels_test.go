@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logrusutil
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func resetLevels(t *testing.T) {
+	t.Cleanup(func() {
+		ResetLevel("")
+		levels.lock.Lock()
+		levels.runtimeSubsystems = nil
+		levels.lock.Unlock()
+		SetConfiguredLevels(logrus.InfoLevel, nil)
+	})
+}
+
+func TestSubsystemLevels(t *testing.T) {
+	resetLevels(t)
+	var out bytes.Buffer
+	logger := logrus.StandardLogger()
+	formatter, previousOut := logger.Formatter, logger.Out
+	t.Cleanup(func() {
+		logger.SetFormatter(formatter)
+		logger.SetOutput(previousOut)
+	})
+	logger.SetFormatter(&DefaultFieldsFormatter{WrappedFormatter: &logrus.TextFormatter{DisableTimestamp: true}})
+	logger.SetOutput(&out)
+
+	SetConfiguredLevels(logrus.InfoLevel, map[string]logrus.Level{"sync": logrus.DebugLevel, "trigger": logrus.WarnLevel})
+	if logrus.GetLevel() != logrus.DebugLevel {
+		t.Errorf("expected the logger to log at the most verbose level, got %s", logrus.GetLevel())
+	}
+	logrus.Debug("component debug")
+	logrus.WithField("controller", "sync").Debug("sync debug")
+	logrus.WithField("controller", "status-update").Debug("status debug")
+	logrus.WithField("plugin", "trigger").Info("trigger info")
+	logrus.WithField("plugin", "trigger").Warn("trigger warning")
+	logrus.Info("component info")
+
+	for _, line := range []string{"sync debug", "trigger warning", "component info"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected %q to be logged, got:\n%s", line, out.String())
+		}
+	}
+	for _, line := range []string{"component debug", "status debug", "trigger info"} {
+		if strings.Contains(out.String(), line) {
+			t.Errorf("expected %q not to be logged, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestRuntimeLevels(t *testing.T) {
+	resetLevels(t)
+	SetConfiguredLevels(logrus.InfoLevel, map[string]logrus.Level{"sync": logrus.WarnLevel})
+
+	SetLevel("", logrus.DebugLevel)
+	SetLevel("sync", logrus.ErrorLevel)
+	level, subsystems := currentLevels()
+	if level != logrus.DebugLevel || subsystems["sync"] != logrus.ErrorLevel {
+		t.Errorf("expected the runtime levels to take precedence, got %s and %v", level, subsystems)
+	}
+
+	// The config is reloaded.
+	SetConfiguredLevels(logrus.WarnLevel, map[string]logrus.Level{"sync": logrus.WarnLevel})
+	if level, _ := currentLevels(); level != logrus.DebugLevel {
+		t.Errorf("expected the runtime level to survive a config reload, got %s", level)
+	}
+
+	ResetLevel("")
+	ResetLevel("sync")
+	level, subsystems = currentLevels()
+	if level != logrus.WarnLevel || len(subsystems) != 0 {
+		t.Errorf("expected the configured levels after a reset, got %s and %v", level, subsystems)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	resetLevels(t)
+	testcases := []struct {
+		name         string
+		method       string
+		query        string
+		token        string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "missing token",
+			method:       http.MethodGet,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "wrong token",
+			method:       http.MethodPut,
+			query:        "?level=debug",
+			token:        "wrong",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "get levels",
+			method:       http.MethodGet,
+			token:        "secret",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"level":"info"}`,
+		},
+		{
+			name:         "set subsystem level",
+			method:       http.MethodPut,
+			query:        "?level=debug&subsystem=sync",
+			token:        "secret",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"level":"info","subsystems":{"sync":"debug"}}`,
+		},
+		{
+			name:         "set component level",
+			method:       http.MethodPut,
+			query:        "?level=warn",
+			token:        "secret",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"level":"warning","subsystems":{"sync":"debug"}}`,
+		},
+		{
+			name:         "invalid level",
+			method:       http.MethodPut,
+			query:        "?level=loud",
+			token:        "secret",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "reset subsystem level",
+			method:       http.MethodDelete,
+			query:        "?subsystem=sync",
+			token:        "secret",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"level":"warning"}`,
+		},
+		{
+			name:         "reset component level",
+			method:       http.MethodDelete,
+			token:        "secret",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"level":"info"}`,
+		},
+	}
+
+	handler := LevelHandler(func() []byte { return []byte("secret") })
+	// The cases run in order, as they change the levels.
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/debug/loglevel"+tc.query, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, w.Code)
+			}
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
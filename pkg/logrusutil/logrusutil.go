@@ -55,7 +55,7 @@ func ComponentInit() {
 	Init(
 		&DefaultFieldsFormatter{
 			PrintLineNumber: true,
-			DefaultFields:   logrus.Fields{"component": version.Name},
+			DefaultFields:   logrus.Fields{ComponentField: version.Name},
 		},
 	)
 }
@@ -64,6 +64,11 @@ func ComponentInit() {
 // map in order to not modify the caller's Entry, as that is not a thread
 // safe operation.
 func (f *DefaultFieldsFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	// The standard logger writes the entries of the most verbose subsystem,
+	// drop the ones of the others.
+	if !enabled(entry) {
+		return nil, nil
+	}
 	data := make(logrus.Fields, len(entry.Data)+len(f.DefaultFields)+1)
 	// GCP's log collection expects a "severity" field instead of "level"
 	data["severity"] = entry.Level
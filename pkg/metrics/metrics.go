@@ -78,6 +78,12 @@ func ExposeMetrics(component string, pushGateway config.PushGateway, port int) {
 	ExposeMetricsWithRegistry(component, pushGateway, port, nil, nil)
 }
 
+// Push pushes the metrics of the component to the push gateway once. It is
+// meant for components that exit after a single run.
+func Push(component string, pushGateway config.PushGateway) error {
+	return fromGatherer(component, hostnameGroupingKey(), pushGateway.Endpoint, prometheus.DefaultGatherer)
+}
+
 // pushMetrics is meant to run in a goroutine and continuously push
 // metrics to the provided endpoint.
 func pushMetrics(component, endpoint string, interval time.Duration) {
@@ -172,6 +172,33 @@ func (c *Client) GetProwYAML(refs *prowapi.Refs) (*config.ProwYAML, error) {
 // in them that would not be serialized into JSON when sent over from the
 // server. So the defaulting has to be done client-side.
 func (c *Client) GetInRepoConfig(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) (*config.ProwYAML, error) {
+	return getInRepoConfig(c.GetProwYAML, c.configAgent.Config(), identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+}
+
+func (c *Client) GetPresubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
+	prowYAML, err := c.GetInRepoConfig(identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+	if err != nil {
+		return nil, err
+	}
+
+	config := c.configAgent.Config()
+	return append(config.GetPresubmitsStatic(identifier), prowYAML.Presubmits...), nil
+}
+
+func (c *Client) GetPostsubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Postsubmit, error) {
+	prowYAML, err := c.GetInRepoConfig(identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+	if err != nil {
+		return nil, err
+	}
+
+	config := c.configAgent.Config()
+	return append(config.GetPostsubmitsStatic(identifier), prowYAML.Postsubmits...), nil
+}
+
+// getInRepoConfig converts the input parameters into a prowapi.Refs{} type,
+// retrieves the ProwYAML for it with getProwYAML and defaults it. It is shared
+// by the HTTP and gRPC clients.
+func getInRepoConfig(getProwYAML func(*prowapi.Refs) (*config.ProwYAML, error), cfg *config.Config, identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) (*config.ProwYAML, error) {
 	refs := prowapi.Refs{}
 
 	orgRepo := config.NewOrgRepo(identifier)
@@ -198,35 +225,14 @@ func (c *Client) GetInRepoConfig(identifier, baseBranch string, baseSHAGetter co
 	}
 	refs.Pulls = pulls
 
-	prowYAML, err := c.GetProwYAML(&refs)
+	prowYAML, err := getProwYAML(&refs)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := c.configAgent.Config()
 	if err := config.DefaultAndValidateProwYAML(cfg, prowYAML, identifier); err != nil {
 		return nil, err
 	}
 
 	return prowYAML, nil
 }
-
-func (c *Client) GetPresubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
-	prowYAML, err := c.GetInRepoConfig(identifier, baseBranch, baseSHAGetter, headSHAGetters...)
-	if err != nil {
-		return nil, err
-	}
-
-	config := c.configAgent.Config()
-	return append(config.GetPresubmitsStatic(identifier), prowYAML.Presubmits...), nil
-}
-
-func (c *Client) GetPostsubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Postsubmit, error) {
-	prowYAML, err := c.GetInRepoConfig(identifier, baseBranch, baseSHAGetter, headSHAGetters...)
-	if err != nil {
-		return nil, err
-	}
-
-	config := c.configAgent.Config()
-	return append(config.GetPostsubmitsStatic(identifier), prowYAML.Postsubmits...), nil
-}
@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package moonraker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+// Ping implements the Ping RPC of the Moonraker gRPC API.
+func (mr *Moonraker) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return &PingResponse{}, nil
+}
+
+// GetProwYAML implements the GetProwYAML RPC of the Moonraker gRPC API. Like
+// ServeGetInrepoconfig, it returns the ProwYAML without defaults, as the
+// private fields of the jobs cannot be serialized.
+func (mr *Moonraker) GetProwYAML(ctx context.Context, req *GetProwYAMLRequest) (*GetProwYAMLResponse, error) {
+	if req.GetIdentifier() == "" {
+		return nil, status.Error(codes.InvalidArgument, "identifier must be set")
+	}
+
+	prowYAML, err := mr.getProwYAML(req.GetIdentifier(), req.GetBaseRef(), req.GetBaseSha(), req.GetHeadShas())
+	if err != nil {
+		logrus.WithError(err).WithField("identifier", req.GetIdentifier()).Error("unable to retrieve inrepoconfig ProwYAML")
+		return nil, status.Errorf(codes.Internal, "unable to retrieve inrepoconfig ProwYAML: %v", err)
+	}
+
+	buf, err := json.Marshal(prowYAML)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to marshal inrepoconfig ProwYAML: %v", err)
+	}
+
+	return &GetProwYAMLResponse{ProwYaml: buf}, nil
+}
+
+// GetPresubmits implements the GetPresubmits RPC of the Moonraker gRPC API. It
+// returns the presubmits of the central config and of the inrepoconfig with
+// the defaults of the config of Moonraker applied, so that clients do not
+// have to load the config themselves.
+func (mr *Moonraker) GetPresubmits(ctx context.Context, req *GetPresubmitsRequest) (*GetPresubmitsResponse, error) {
+	if req.GetIdentifier() == "" {
+		return nil, status.Error(codes.InvalidArgument, "identifier must be set")
+	}
+
+	baseSHAGetter, headSHAGetters := refGetters(req.GetBaseSha(), req.GetHeadShas())
+	presubmits, err := mr.InRepoConfigCache.GetPresubmits(req.GetIdentifier(), req.GetBaseRef(), baseSHAGetter, headSHAGetters...)
+	if err != nil {
+		logrus.WithError(err).WithField("identifier", req.GetIdentifier()).Error("unable to retrieve presubmits")
+		return nil, status.Errorf(codes.Internal, "unable to retrieve presubmits: %v", err)
+	}
+
+	buf, err := json.Marshal(presubmits)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to marshal presubmits: %v", err)
+	}
+
+	return &GetPresubmitsResponse{Presubmits: buf}, nil
+}
+
+// GetPostsubmits implements the GetPostsubmits RPC of the Moonraker gRPC API,
+// see GetPresubmits.
+func (mr *Moonraker) GetPostsubmits(ctx context.Context, req *GetPostsubmitsRequest) (*GetPostsubmitsResponse, error) {
+	if req.GetIdentifier() == "" {
+		return nil, status.Error(codes.InvalidArgument, "identifier must be set")
+	}
+
+	baseSHAGetter, headSHAGetters := refGetters(req.GetBaseSha(), req.GetHeadShas())
+	postsubmits, err := mr.InRepoConfigCache.GetPostsubmits(req.GetIdentifier(), req.GetBaseRef(), baseSHAGetter, headSHAGetters...)
+	if err != nil {
+		logrus.WithError(err).WithField("identifier", req.GetIdentifier()).Error("unable to retrieve postsubmits")
+		return nil, status.Errorf(codes.Internal, "unable to retrieve postsubmits: %v", err)
+	}
+
+	buf, err := json.Marshal(postsubmits)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to marshal postsubmits: %v", err)
+	}
+
+	return &GetPostsubmitsResponse{Postsubmits: buf}, nil
+}
+
+// GRPCClient is a client of the gRPC API of Moonraker. It implements
+// config.InRepoConfigGetter, just like Client.
+type GRPCClient struct {
+	conn        *grpc.ClientConn
+	client      MoonrakerClient
+	configAgent prowConfigAgentClient
+}
+
+// NewGRPCClient connects to the Moonraker gRPC server at address and waits
+// for it to be available.
+func NewGRPCClient(address string, configAgent prowConfigAgentClient) (*GRPCClient, error) {
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Moonraker at %q: %w", address, err)
+	}
+
+	c := GRPCClient{
+		conn:        conn,
+		client:      NewMoonrakerClient(conn),
+		configAgent: configAgent,
+	}
+
+	isMoonrakerUp := func() (bool, error) {
+		return c.Ping() == nil, nil
+	}
+
+	pollLoopTimeout := 15 * time.Second
+	pollInterval := 500 * time.Millisecond
+	if err := wait.Poll(pollInterval, pollLoopTimeout, isMoonrakerUp); err != nil {
+		conn.Close()
+		return nil, errors.New("timed out waiting for Moonraker to be available")
+	}
+
+	return &c, nil
+}
+
+// Close closes the underlying connection to the server.
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+// context returns a context bounded by the client timeout from the Moonraker
+// configuration.
+func (c *GRPCClient) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.configAgent.Config().Moonraker.ClientTimeout.Duration)
+}
+
+func (c *GRPCClient) Ping() error {
+	ctx, cancel := c.context()
+	defer cancel()
+	_, err := c.client.Ping(ctx, &PingRequest{})
+	return err
+}
+
+// GetProwYAML returns the inrepoconfig contents for a repo, based on the Refs
+// struct as the input.
+func (c *GRPCClient) GetProwYAML(refs *prowapi.Refs) (*config.ProwYAML, error) {
+	req := &GetProwYAMLRequest{
+		Identifier: refs.Org + "/" + refs.Repo,
+		BaseRef:    refs.BaseRef,
+		BaseSha:    refs.BaseSHA,
+	}
+	for _, pull := range refs.Pulls {
+		req.HeadShas = append(req.HeadShas, pull.SHA)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.GetProwYAML(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	prowYAML := config.ProwYAML{}
+	if err := json.Unmarshal(resp.GetProwYaml(), &prowYAML); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal inrepoconfig ProwYAML: %w", err)
+	}
+
+	return &prowYAML, nil
+}
+
+// GetInRepoConfig returns the defaulted inrepoconfig contents, see
+// Client.GetInRepoConfig.
+func (c *GRPCClient) GetInRepoConfig(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) (*config.ProwYAML, error) {
+	return getInRepoConfig(c.GetProwYAML, c.configAgent.Config(), identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+}
+
+// GetPresubmits returns the presubmits of the central config and of the
+// inrepoconfig, as resolved and defaulted by Moonraker. Only their regular
+// expressions are compiled client-side.
+func (c *GRPCClient) GetPresubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
+	baseSHA, headSHAs, err := config.GetAndCheckRefs(baseSHAGetter, headSHAGetters...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.GetPresubmits(ctx, &GetPresubmitsRequest{
+		Identifier: identifier,
+		BaseRef:    baseBranch,
+		BaseSha:    baseSHA,
+		HeadShas:   headSHAs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var presubmits []config.Presubmit
+	if err := json.Unmarshal(resp.GetPresubmits(), &presubmits); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal presubmits: %w", err)
+	}
+	if err := config.SetPresubmitRegexes(presubmits); err != nil {
+		return nil, fmt.Errorf("unable to compile the regexes of the presubmits: %w", err)
+	}
+
+	return presubmits, nil
+}
+
+// GetPostsubmits returns the postsubmits of the central config and of the
+// inrepoconfig, see GetPresubmits.
+func (c *GRPCClient) GetPostsubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Postsubmit, error) {
+	baseSHA, headSHAs, err := config.GetAndCheckRefs(baseSHAGetter, headSHAGetters...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.GetPostsubmits(ctx, &GetPostsubmitsRequest{
+		Identifier: identifier,
+		BaseRef:    baseBranch,
+		BaseSha:    baseSHA,
+		HeadShas:   headSHAs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var postsubmits []config.Postsubmit
+	if err := json.Unmarshal(resp.GetPostsubmits(), &postsubmits); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal postsubmits: %w", err)
+	}
+	if err := config.SetPostsubmitRegexes(postsubmits); err != nil {
+		return nil, fmt.Errorf("unable to compile the regexes of the postsubmits: %w", err)
+	}
+
+	return postsubmits, nil
+}
+
+// NewInRepoConfigGetter returns a client of the gRPC API of Moonraker if
+// grpcAddress is set, and a client of its HTTP API otherwise.
+func NewInRepoConfigGetter(address, grpcAddress string, configAgent prowConfigAgentClient) (config.InRepoConfigGetter, error) {
+	if grpcAddress != "" {
+		return NewGRPCClient(grpcAddress, configAgent)
+	}
+	return NewClient(address, configAgent)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package moonraker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/git/v2"
+)
+
+type fakeConfigAgent struct {
+	c *config.Config
+}
+
+func (fca *fakeConfigAgent) Config() *config.Config {
+	return fca.c
+}
+
+// fakeClientFactory is never used, as the config of the tests gets the
+// inrepoconfig without cloning.
+type fakeClientFactory struct {
+	git.ClientFactory
+}
+
+func newTestGRPCClient(t *testing.T) *GRPCClient {
+	t.Helper()
+	enabled := true
+	namespace := "default"
+	spec := &v1.PodSpec{Containers: []v1.Container{{Image: "alpine"}}}
+
+	presubmits := []config.Presubmit{{
+		JobBase:             config.JobBase{Name: "unit", Agent: "kubernetes", Namespace: &namespace, Spec: spec},
+		Reporter:            config.Reporter{Context: "unit"},
+		Brancher:            config.Brancher{Branches: []string{"main"}},
+		RegexpChangeMatcher: config.RegexpChangeMatcher{RunIfChanged: "^src/"},
+		Trigger:             `(?m)^/test unit`,
+		RerunCommand:        "/test unit",
+	}}
+	if err := config.SetPresubmitRegexes(presubmits); err != nil {
+		t.Fatalf("failed to compile the regexes of the presubmits: %v", err)
+	}
+	postsubmits := []config.Postsubmit{{
+		JobBase:  config.JobBase{Name: "push", Agent: "kubernetes", Namespace: &namespace, Spec: spec},
+		Reporter: config.Reporter{Context: "push"},
+		Brancher: config.Brancher{SkipBranches: []string{"release-.*"}},
+	}}
+	if err := config.SetPostsubmitRegexes(postsubmits); err != nil {
+		t.Fatalf("failed to compile the regexes of the postsubmits: %v", err)
+	}
+	// The inrepoconfig has a presubmit only for the expected refs, so that
+	// tests can tell whether they were passed along.
+	prowYAMLGetter := func(_ *config.Config, _ git.ClientFactory, identifier, baseBranch, baseSHA string, headSHAs ...string) (*config.ProwYAML, error) {
+		if baseSHA != "base" || !reflect.DeepEqual(headSHAs, []string{"head"}) {
+			return &config.ProwYAML{}, nil
+		}
+		return &config.ProwYAML{Presubmits: []config.Presubmit{{
+			JobBase: config.JobBase{
+				Name: "integration",
+				Spec: spec,
+			},
+		}}}, nil
+	}
+	fca := &fakeConfigAgent{c: &config.Config{
+		ProwConfig: config.ProwConfig{
+			InRepoConfig: config.InRepoConfig{
+				Enabled:         map[string]*bool{"org/repo": &enabled},
+				AllowedClusters: map[string][]string{"org/repo": {"default"}},
+			},
+			Moonraker:    config.Moonraker{ClientTimeout: &metav1.Duration{Duration: time.Minute}},
+			PodNamespace: "default",
+		},
+		JobConfig: config.JobConfig{
+			PresubmitsStatic:  map[string][]config.Presubmit{"org/repo": presubmits},
+			PostsubmitsStatic: map[string][]config.Postsubmit{"org/repo": postsubmits},
+			ProwYAMLGetter:    prowYAMLGetter,
+		},
+	}}
+	cache, err := config.NewInRepoConfigCache(10, fca, &fakeClientFactory{})
+	if err != nil {
+		t.Fatalf("failed to create the inrepoconfig cache: %v", err)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	RegisterMoonrakerServer(s, &Moonraker{InRepoConfigCache: cache})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial Moonraker: %v", err)
+	}
+	c := &GRPCClient{conn: conn, client: NewMoonrakerClient(conn), configAgent: fca}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func sha(sha string) config.RefGetter {
+	return func() (string, error) {
+		return sha, nil
+	}
+}
+
+func TestGRPCPing(t *testing.T) {
+	c := newTestGRPCClient(t)
+	if err := c.Ping(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestGRPCGetProwYAMLRequiresIdentifier(t *testing.T) {
+	c := newTestGRPCClient(t)
+	ctx, cancel := c.context()
+	defer cancel()
+
+	_, err := c.client.GetProwYAML(ctx, &GetProwYAMLRequest{BaseSha: "base"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an InvalidArgument error, got %v", err)
+	}
+	_, err = c.client.GetPresubmits(ctx, &GetPresubmitsRequest{BaseSha: "base"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an InvalidArgument error, got %v", err)
+	}
+	_, err = c.client.GetPostsubmits(ctx, &GetPostsubmitsRequest{BaseSha: "base"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an InvalidArgument error, got %v", err)
+	}
+}
+
+func TestGRPCGetPresubmits(t *testing.T) {
+	c := newTestGRPCClient(t)
+
+	presubmits, err := c.GetPresubmits("org/repo", "main", sha("base"), sha("head"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(presubmits) != 2 || presubmits[0].Name != "unit" || presubmits[1].Name != "integration" {
+		t.Fatalf("expected the unit and integration presubmits, got %v", presubmits)
+	}
+	if presubmits[1].Context != "integration" {
+		t.Errorf("expected the integration presubmit to be defaulted, got context %q", presubmits[1].Context)
+	}
+	ps := presubmits[0]
+	if !ps.TriggerMatches("/test unit") {
+		t.Error("expected the trigger of the presubmit to be compiled")
+	}
+	if !ps.Brancher.ShouldRun("main") || ps.Brancher.ShouldRun("dev") {
+		t.Error("expected the branches of the presubmit to be compiled")
+	}
+	if !ps.RunsAgainstChanges([]string{"src/main.go"}) || ps.RunsAgainstChanges([]string{"README.md"}) {
+		t.Error("expected the run_if_changed of the presubmit to be compiled")
+	}
+
+	failingGetter := func() (string, error) {
+		return "", errors.New("injected error")
+	}
+	if _, err := c.GetPresubmits("org/repo", "main", sha("base"), failingGetter); err == nil {
+		t.Error("expected the error of a head SHA getter to be returned")
+	}
+}
+
+func TestGRPCGetPostsubmits(t *testing.T) {
+	c := newTestGRPCClient(t)
+
+	postsubmits, err := c.GetPostsubmits("org/repo", "main", sha("base"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(postsubmits) != 1 || postsubmits[0].Name != "push" {
+		t.Fatalf("expected the push postsubmit, got %v", postsubmits)
+	}
+	if !postsubmits[0].Brancher.ShouldRun("main") || postsubmits[0].Brancher.ShouldRun("release-1.0") {
+		t.Error("expected the skipped branches of the postsubmit to be compiled")
+	}
+
+	postsubmits, err = c.GetPostsubmits("other/repo", "main", sha("base"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(postsubmits) != 0 {
+		t.Errorf("expected no postsubmits for another repo, got %v", postsubmits)
+	}
+}
@@ -36,6 +36,8 @@ const (
 )
 
 type Moonraker struct {
+	UnimplementedMoonrakerServer
+
 	ConfigAgent       *config.Agent
 	InRepoConfigCache *config.InRepoConfigCache
 }
@@ -77,19 +79,13 @@ func (mr *Moonraker) ServeGetInrepoconfig(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	baseSHAGetter := func() (string, error) {
-		return payload.Refs.BaseSHA, nil
-	}
-	var headSHAGetters []func() (string, error)
+	var headSHAs []string
 	for _, pull := range payload.Refs.Pulls {
-		pull := pull
-		headSHAGetters = append(headSHAGetters, func() (string, error) {
-			return pull.SHA, nil
-		})
+		headSHAs = append(headSHAs, pull.SHA)
 	}
 	identifier := payload.Refs.Org + "/" + payload.Refs.Repo
 
-	prowYAML, err := mr.InRepoConfigCache.GetProwYAMLWithoutDefaults(identifier, payload.Refs.BaseRef, baseSHAGetter, headSHAGetters...)
+	prowYAML, err := mr.getProwYAML(identifier, payload.Refs.BaseRef, payload.Refs.BaseSHA, headSHAs)
 	if err != nil {
 		logrus.WithError(err).Error("unable to retrieve inrepoconfig ProwYAML")
 		http.Error(w, fmt.Sprintf("unable to retrieve inrepoconfig ProwYAML: %v", err), http.StatusBadRequest)
@@ -104,6 +100,29 @@ func (mr *Moonraker) ServeGetInrepoconfig(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// getProwYAML returns the ProwYAML of a repository without defaults, as they
+// are applied by the clients.
+func (mr *Moonraker) getProwYAML(identifier, baseRef, baseSHA string, headSHAs []string) (*config.ProwYAML, error) {
+	baseSHAGetter, headSHAGetters := refGetters(baseSHA, headSHAs)
+	return mr.InRepoConfigCache.GetProwYAMLWithoutDefaults(identifier, baseRef, baseSHAGetter, headSHAGetters...)
+}
+
+// refGetters returns getters of the SHAs the clients of Moonraker already
+// resolved.
+func refGetters(baseSHA string, headSHAs []string) (config.RefGetter, []config.RefGetter) {
+	baseSHAGetter := func() (string, error) {
+		return baseSHA, nil
+	}
+	var headSHAGetters []config.RefGetter
+	for _, headSHA := range headSHAs {
+		headSHA := headSHA
+		headSHAGetters = append(headSHAGetters, func() (string, error) {
+			return headSHA, nil
+		})
+	}
+	return baseSHAGetter, headSHAGetters
+}
+
 func (mr *Moonraker) RunConfigWatcher(ctx context.Context) error {
 	configEvent := make(chan config.Delta, 2)
 	mr.ConfigAgent.Subscribe(configEvent)
@@ -18,24 +18,121 @@ package phony
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
+	gerrit "sigs.k8s.io/prow/pkg/gerrit/client"
 	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/gitlab"
 )
 
 // SendHook sends a GitHub event of type eventType to the provided address.
 func SendHook(address, eventType string, payload, hmac []byte) error {
-	req, err := http.NewRequest(http.MethodPost, address, bytes.NewBuffer(payload))
+	req, err := newRequest(address, payload)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("X-GitHub-Event", eventType)
 	req.Header.Set("X-GitHub-Delivery", "GUID")
 	req.Header.Set("X-Hub-Signature", github.PayloadSignature(payload, hmac))
+	return send(req)
+}
+
+// SendGitLabHook sends a GitLab webhook of type eventType, e.g. "Merge Request
+// Hook", to the provided address. GitLab does not sign payloads but sends
+// the secret token as is.
+func SendGitLabHook(address, eventType string, payload, token []byte) error {
+	req, err := newRequest(address, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(gitlab.EventHeader, eventType)
+	req.Header.Set(gitlab.UUIDHeader, "GUID")
+	req.Header.Set(gitlab.TokenHeader, string(token))
+	return send(req)
+}
+
+// SendGerritEvent sends a Gerrit stream event to the provided address the
+// way the webhooks plugin of Gerrit does, authenticating with basic auth if
+// user is set.
+func SendGerritEvent(address string, payload []byte, user, password string) error {
+	req, err := newRequest(address, payload)
+	if err != nil {
+		return err
+	}
+	if user != "" {
+		req.SetBasicAuth(user, password)
+	}
+	return send(req)
+}
+
+// GerritChange describes the change a synthesized Gerrit event is about.
+type GerritChange struct {
+	Instance string
+	Project  string
+	Branch   string
+	Number   int
+	Patchset int
+	Owner    string
+	Comment  string
+}
+
+// NewGerritEvent synthesizes a Gerrit stream event of type eventType, such
+// as patchset-created or comment-added, for the change.
+func NewGerritEvent(eventType string, c GerritChange) ([]byte, error) {
+	now := time.Now().Unix()
+	account := gerrit.StreamAccount{Name: c.Owner, Username: c.Owner, Email: c.Owner + "@example.com"}
+	revision := fmt.Sprintf("%040d", c.Number*100+c.Patchset)
+	event := gerrit.StreamEvent{
+		Type: eventType,
+		Change: gerrit.StreamChange{
+			Project: c.Project,
+			Branch:  c.Branch,
+			ID:      fmt.Sprintf("I%040d", c.Number),
+			Number:  c.Number,
+			Subject: fmt.Sprintf("Change %d", c.Number),
+			Owner:   account,
+			URL:     fmt.Sprintf("%s/c/%s/+/%d", strings.TrimSuffix(c.Instance, "/"), c.Project, c.Number),
+			Status:  gerrit.New,
+		},
+		PatchSet: gerrit.StreamPatchSet{
+			Number:    c.Patchset,
+			Revision:  revision,
+			Ref:       fmt.Sprintf("refs/changes/%02d/%d/%d", c.Number%100, c.Number, c.Patchset),
+			Uploader:  account,
+			CreatedOn: now,
+		},
+		EventCreatedOn: now,
+	}
+	switch eventType {
+	case gerrit.PatchsetCreatedEvent:
+		event.Uploader = &account
+	case gerrit.CommentAddedEvent:
+		event.Author = &account
+		event.Comment = fmt.Sprintf("Patch Set %d:\n\n%s", c.Patchset, c.Comment)
+	case gerrit.ChangeMergedEvent:
+		event.Submitter = &account
+		event.Change.Status = gerrit.Merged
+	default:
+		return nil, fmt.Errorf("cannot synthesize Gerrit events of type %q", eventType)
+	}
+	return json.Marshal(event)
+}
+
+func newRequest(address string, payload []byte) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Set("content-type", "application/json")
+	return req, nil
+}
 
+func send(req *http.Request) error {
 	c := &http.Client{}
 	resp, err := c.Do(req)
 	if err != nil {
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phony
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	gerrit "sigs.k8s.io/prow/pkg/gerrit/client"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/gitlab"
+)
+
+func TestSend(t *testing.T) {
+	payload := []byte(`{"key":"value"}`)
+	testCases := []struct {
+		name            string
+		send            func(address string) error
+		expectedHeaders map[string]string
+		expectedAuth    string
+	}{
+		{
+			name: "GitHub",
+			send: func(address string) error { return SendHook(address, "pull_request", payload, []byte("secret")) },
+			expectedHeaders: map[string]string{
+				"X-GitHub-Event":  "pull_request",
+				"X-Hub-Signature": github.PayloadSignature(payload, []byte("secret")),
+			},
+		},
+		{
+			name: "GitLab",
+			send: func(address string) error {
+				return SendGitLabHook(address, gitlab.MergeRequestHook, payload, []byte("secret"))
+			},
+			expectedHeaders: map[string]string{
+				gitlab.EventHeader: gitlab.MergeRequestHook,
+				gitlab.TokenHeader: "secret",
+			},
+		},
+		{
+			name:         "Gerrit with basic auth",
+			send:         func(address string) error { return SendGerritEvent(address, payload, "user", "password") },
+			expectedAuth: "user:password",
+		},
+		{
+			name: "Gerrit without auth",
+			send: func(address string) error { return SendGerritEvent(address, payload, "", "") },
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for header, expected := range tc.expectedHeaders {
+					if actual := r.Header.Get(header); actual != expected {
+						t.Errorf("expected header %s to be %q, got %q", header, expected, actual)
+					}
+				}
+				var auth string
+				if user, password, ok := r.BasicAuth(); ok {
+					auth = user + ":" + password
+				}
+				if auth != tc.expectedAuth {
+					t.Errorf("expected basic auth %q, got %q", tc.expectedAuth, auth)
+				}
+				if body, _ := io.ReadAll(r.Body); string(body) != string(payload) {
+					t.Errorf("expected payload %s, got %s", payload, body)
+				}
+			}))
+			defer server.Close()
+			if err := tc.send(server.URL); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewGerritEvent(t *testing.T) {
+	change := GerritChange{
+		Instance: "https://review.example.com/",
+		Project:  "project",
+		Branch:   "main",
+		Number:   1234,
+		Patchset: 2,
+		Owner:    "developer",
+		Comment:  "/retest",
+	}
+	raw, err := NewGerritEvent(gerrit.CommentAddedEvent, change)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var event gerrit.StreamEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	actual := []string{event.Type, event.Change.URL, event.PatchSet.Ref, event.Author.Username, event.Comment}
+	expected := []string{"comment-added", "https://review.example.com/c/project/+/1234", "refs/changes/34/1234/2", "developer", "Patch Set 2:\n\n/retest"}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("event differs (-want +got):\n%s", diff)
+	}
+
+	if _, err := NewGerritEvent("ref-updated", change); err == nil {
+		t.Error("expected an error for an unsupported event type")
+	}
+}
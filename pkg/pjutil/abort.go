@@ -36,6 +36,7 @@ import (
 // patchClient a minimalistic prow client required by the aborter
 type patchClient interface {
 	Patch(ctx context.Context, obj ctrlruntimeclient.Object, patch ctrlruntimeclient.Patch, opts ...ctrlruntimeclient.PatchOption) error
+	Status() ctrlruntimeclient.StatusWriter
 }
 
 // prowClient a minimalistic prow client required by the aborter
@@ -110,7 +111,7 @@ func TerminateOlderJobs(pjc patchClient, log *logrus.Entry, pjs []prowapi.ProwJo
 			WithField("from", prevPJ.Status.State).
 			WithField("to", toCancel.Status.State).Info("Transitioning states")
 
-		if err := pjc.Patch(context.Background(), &toCancel, ctrlruntimeclient.MergeFrom(prevPJ)); err != nil {
+		if err := PatchProwJobWithClient(context.Background(), pjc, prevPJ, &toCancel); err != nil {
 			return err
 		}
 
@@ -121,7 +122,11 @@ func TerminateOlderJobs(pjc patchClient, log *logrus.Entry, pjs []prowapi.ProwJo
 	return nil
 }
 
+// PatchProwjob patches a ProwJob from srcPJ to destPJ. Changes of the status
+// are patched through the status subresource, along with the conditions that
+// reflect the state.
 func PatchProwjob(ctx context.Context, pjc prowClient, log *logrus.Entry, srcPJ prowapi.ProwJob, destPJ prowapi.ProwJob) (*prowapi.ProwJob, error) {
+	destPJ.SetConditions()
 	srcPJData, err := json.Marshal(srcPJ)
 	if err != nil {
 		return nil, fmt.Errorf("marshal source prow job: %w", err)
@@ -137,7 +142,30 @@ func PatchProwjob(ctx context.Context, pjc prowClient, log *logrus.Entry, srcPJ
 		return nil, fmt.Errorf("cannot create JSON patch: %w", err)
 	}
 
-	newPJ, err := pjc.Patch(ctx, srcPJ.Name, ktypes.MergePatchType, patch, metav1.PatchOptions{})
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		return nil, fmt.Errorf("cannot split JSON patch: %w", err)
+	}
+	status, patchStatus := fields["status"]
+	delete(fields, "status")
+
+	var newPJ *prowapi.ProwJob
+	if len(fields) > 0 || !patchStatus {
+		if patch, err = json.Marshal(fields); err != nil {
+			return nil, fmt.Errorf("marshal JSON patch: %w", err)
+		}
+		if newPJ, err = pjc.Patch(ctx, srcPJ.Name, ktypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	if patchStatus {
+		if patch, err = json.Marshal(map[string]json.RawMessage{"status": status}); err != nil {
+			return nil, fmt.Errorf("marshal JSON patch of status: %w", err)
+		}
+		if newPJ, err = pjc.Patch(ctx, srcPJ.Name, ktypes.MergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+			return nil, err
+		}
+	}
 	log.WithFields(ProwJobFields(&destPJ)).Debug("Patched ProwJob.")
-	return newPJ, err
+	return newPJ, nil
 }
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakepjutil provides fake ProwJob clients for tests of code that
+// creates ProwJobs.
+package fakepjutil
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	prowv1 "sigs.k8s.io/prow/pkg/client/clientset/versioned/typed/prowjobs/v1"
+)
+
+// StatusDroppingClient drops the status of created ProwJobs like the API
+// server does for resources with a status subresource.
+type StatusDroppingClient struct {
+	prowv1.ProwJobInterface
+}
+
+func (c StatusDroppingClient) Create(ctx context.Context, pj *prowapi.ProwJob, opts metav1.CreateOptions) (*prowapi.ProwJob, error) {
+	pj = pj.DeepCopy()
+	pj.Status = prowapi.ProwJobStatus{}
+	return c.ProwJobInterface.Create(ctx, pj, opts)
+}
+
+// StatusDroppingCtrlClient is the controller-runtime counterpart of
+// StatusDroppingClient.
+type StatusDroppingCtrlClient struct {
+	ctrlruntimeclient.Client
+}
+
+func (c StatusDroppingCtrlClient) Create(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+	if pj, ok := obj.(*prowapi.ProwJob); ok {
+		pj.Status = prowapi.ProwJobStatus{}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
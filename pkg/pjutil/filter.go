@@ -37,6 +37,27 @@ var RetestRequiredRe = regexp.MustCompile(`(?m)^/retest-required\s*$`)
 
 var OkToTestRe = regexp.MustCompile(`(?m)^/ok-to-test\s*$`)
 
+// TestRegexRe provides the regex for `/test re:<pattern>`
+var TestRegexRe = regexp.MustCompile(`(?m)^/test[ \t]+re:(\S+)\s*$`)
+
+// RetestRegexRe provides the regex for `/retest re:<pattern>`
+var RetestRegexRe = regexp.MustCompile(`(?m)^/retest[ \t]+re:(\S+)\s*$`)
+
+// JobNamePatterns compiles the job name patterns requested with
+// `/test re:<pattern>` or `/retest re:<pattern>` in the comment body.
+// The returned patterns are empty if no such command is present.
+func JobNamePatterns(re *regexp.Regexp, body string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, match := range re.FindAllStringSubmatch(body, -1) {
+		pattern, err := regexp.Compile(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid job name pattern %q: %w", match[1], err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
 // AvailablePresubmits returns 3 sets of presubmits:
 // 1. presubmits that can be run with '/test all' command.
 // 2. optional presubmits commands that can be run with their trigger, e.g. '/test job'
@@ -132,6 +153,41 @@ func (cf *CommandFilter) Name() string {
 	return "command-filter: " + body
 }
 
+// RegexFilter builds a filter for `/test re:<pattern>` and `/retest re:<pattern>`
+type RegexFilter struct {
+	patterns []*regexp.Regexp
+	// failedContexts restricts the filter to jobs that have failed when
+	// non-nil, which is the case for `/retest re:<pattern>`.
+	failedContexts sets.Set[string]
+}
+
+func NewRegexFilter(patterns []*regexp.Regexp, failedContexts sets.Set[string]) *RegexFilter {
+	return &RegexFilter{patterns: patterns, failedContexts: failedContexts}
+}
+
+func (rf *RegexFilter) ShouldRun(p config.Presubmit) (bool, bool, bool) {
+	if rf.failedContexts != nil && !rf.failedContexts.Has(p.Context) {
+		return false, false, false
+	}
+	for _, pattern := range rf.patterns {
+		if pattern.MatchString(p.Name) {
+			return true, true, true
+		}
+	}
+	return false, false, false
+}
+
+func (rf *RegexFilter) Name() string {
+	var patterns []string
+	for _, pattern := range rf.patterns {
+		patterns = append(patterns, pattern.String())
+	}
+	if rf.failedContexts != nil {
+		return "retest-regex-filter: " + strings.Join(patterns, ",")
+	}
+	return "regex-filter: " + strings.Join(patterns, ",")
+}
+
 // TestAllFilter builds a filter for the automatic behavior of `/test all`.
 // Jobs that explicitly match `/test all` in their trigger regex will be
 // handled by a commandFilter for the comment in question.
@@ -272,6 +328,12 @@ func PresubmitFilter(honorOkToTest bool, contextGetter contextGetter, body strin
 	// match before others. We order filters by amount of specificity.
 	var filters []Filter
 	filters = append(filters, NewCommandFilter(body))
+	if regexFilter, err := RegexPresubmitFilter(contextGetter, body); err != nil {
+		return nil, err
+	} else if regexFilter != nil {
+		logger.Info("Using regex filter.")
+		filters = append(filters, regexFilter)
+	}
 	if RetestRe.MatchString(body) {
 		logger.Info("Using retest filter.")
 		failedContexts, allContexts, err := contextGetter()
@@ -294,3 +356,35 @@ func PresubmitFilter(honorOkToTest bool, contextGetter contextGetter, body strin
 	}
 	return NewAggregateFilter(filters), nil
 }
+
+// RegexPresubmitFilter creates a filter for the `/test re:<pattern>` and
+// `/retest re:<pattern>` commands in the body, or returns nil if there are none.
+func RegexPresubmitFilter(contextGetter contextGetter, body string) (Filter, error) {
+	testPatterns, err := JobNamePatterns(TestRegexRe, body)
+	if err != nil {
+		return nil, err
+	}
+	retestPatterns, err := JobNamePatterns(RetestRegexRe, body)
+	if err != nil {
+		return nil, err
+	}
+	var filters []Filter
+	if len(testPatterns) > 0 {
+		filters = append(filters, NewRegexFilter(testPatterns, nil))
+	}
+	if len(retestPatterns) > 0 {
+		failedContexts, _, err := contextGetter()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, NewRegexFilter(retestPatterns, failedContexts))
+	}
+	switch len(filters) {
+	case 0:
+		return nil, nil
+	case 1:
+		return filters[0], nil
+	default:
+		return NewAggregateFilter(filters), nil
+	}
+}
@@ -975,6 +975,70 @@ func TestPresubmitFilter(t *testing.T) {
 				{true, false, false},
 			},
 		},
+		{
+			name: "test regex comment selects jobs whose name matches the pattern",
+			body: "/test re:^unit-",
+			org:  "org",
+			repo: "repo",
+			ref:  "ref",
+			presubmits: []config.Presubmit{
+				{
+					JobBase:  config.JobBase{Name: "unit-foo"},
+					Reporter: config.Reporter{Context: "unit-foo"},
+					RegexpChangeMatcher: config.RegexpChangeMatcher{
+						RunIfChanged: "sometimes",
+					},
+				},
+				{
+					JobBase:  config.JobBase{Name: "unit-bar"},
+					Reporter: config.Reporter{Context: "existing-successful"},
+				},
+				{
+					JobBase:  config.JobBase{Name: "e2e-unit"},
+					Reporter: config.Reporter{Context: "e2e-unit"},
+				},
+			},
+			expected: [][]bool{{true, true, true}, {true, true, true}, {false, false, false}},
+		},
+		{
+			name: "retest regex comment selects failed jobs whose name matches the pattern",
+			body: "/retest re:^existing",
+			org:  "org",
+			repo: "repo",
+			ref:  "ref",
+			presubmits: []config.Presubmit{
+				{
+					JobBase:  config.JobBase{Name: "existing-failure"},
+					Reporter: config.Reporter{Context: "existing-failure"},
+				},
+				{
+					JobBase:  config.JobBase{Name: "existing-successful"},
+					Reporter: config.Reporter{Context: "existing-successful"},
+				},
+				{
+					JobBase:  config.JobBase{Name: "other"},
+					Reporter: config.Reporter{Context: "existing-error"},
+				},
+			},
+			expected: [][]bool{{true, true, true}, {false, false, false}, {false, false, false}},
+		},
+		{
+			name:      "retest regex comment when status can't be fetched",
+			body:      "/retest re:^existing",
+			org:       "org",
+			repo:      "repo",
+			ref:       "ref",
+			statusErr: true,
+			expectErr: true,
+		},
+		{
+			name:      "test regex comment with an invalid pattern",
+			body:      "/test re:(",
+			org:       "org",
+			repo:      "repo",
+			ref:       "ref",
+			expectErr: true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -18,19 +18,37 @@ limitations under the License.
 package pjutil
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"sigs.k8s.io/prow/pkg/interrupts"
 )
 
-const healthPort = 8081
+const (
+	healthPort = 8081
+
+	// healthCheckTimeout bounds how long a single HealthCheck may take.
+	healthCheckTimeout = 5 * time.Second
+	// healthCheckInterval is how long the result of a HealthCheck is reused,
+	// so that frequent probes of many replicas don't hammer dependencies like
+	// GitHub.
+	healthCheckInterval = 10 * time.Second
+)
 
 // Health keeps a request multiplexer for health liveness and readiness endpoints
 type Health struct {
 	healthMux *http.ServeMux
+
+	lock            sync.RWMutex
+	ready           bool
+	readynessChecks []ReadynessCheck
+	liveness        []*healthCheckState
+	readiness       []*healthCheckState
 }
 
 // NewHealth creates a new health request multiplexer and starts serving the liveness endpoint
@@ -42,27 +60,136 @@ func NewHealth() *Health {
 // NewHealth creates a new health request multiplexer and starts serving the liveness endpoint
 // on the given port
 func NewHealthOnPort(port int) *Health {
-	healthMux := http.NewServeMux()
-	healthMux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "OK") })
-	server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: healthMux}
+	h := newHealth()
+	server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: h.healthMux}
 	interrupts.ListenAndServe(server, 5*time.Second)
-	return &Health{
-		healthMux: healthMux,
-	}
+	return h
+}
+
+func newHealth() *Health {
+	h := &Health{healthMux: http.NewServeMux()}
+	h.healthMux.HandleFunc("/healthz", h.serveLive)
+	h.healthMux.HandleFunc("/readyz", h.serveReady)
+	h.healthMux.HandleFunc("/healthz/ready", h.serveReady)
+	return h
 }
 
 type ReadynessCheck func() bool
 
-// ServeReady starts serving the readiness endpoint
+// HealthCheck probes a dependency of a component, e.g. that its config is
+// loaded or that it can reach GitHub. Check returns an error if the dependency
+// is unhealthy.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type healthCheckState struct {
+	HealthCheck
+
+	lock    sync.Mutex
+	checked time.Time
+	err     error
+}
+
+// run runs the check, unless it ran recently.
+func (s *healthCheckState) run(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.checked.IsZero() && time.Since(s.checked) < healthCheckInterval {
+		return s.err
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	s.err = s.Check(ctx)
+	s.checked = time.Now()
+	return s.err
+}
+
+// AddLivenessChecks adds checks to the liveness endpoint /healthz. Kubernetes
+// restarts a component that fails them, so they should only fail if the
+// component is wedged, e.g. it lost its config, and not if a dependency that
+// a restart doesn't fix is unavailable.
+func (h *Health) AddLivenessChecks(checks ...HealthCheck) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, check := range checks {
+		h.liveness = append(h.liveness, &healthCheckState{HealthCheck: check})
+	}
+}
+
+// AddReadinessChecks adds checks to the readiness endpoints /readyz and
+// /healthz/ready, e.g. that the component can reach the services it needs.
+func (h *Health) AddReadinessChecks(checks ...HealthCheck) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, check := range checks {
+		h.readiness = append(h.readiness, &healthCheckState{HealthCheck: check})
+	}
+}
+
+// ServeReady starts serving the readiness endpoints /readyz and /healthz/ready.
+// The component isn't ready before.
 func (h *Health) ServeReady(readynessChecks ...ReadynessCheck) {
-	h.healthMux.HandleFunc("/healthz/ready", func(w http.ResponseWriter, r *http.Request) {
-		for _, readynessCheck := range readynessChecks {
-			if !readynessCheck() {
-				w.WriteHeader(http.StatusServiceUnavailable)
-				fmt.Fprint(w, "ReadynessCheck failed")
-				return
-			}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.ready = true
+	h.readynessChecks = readynessChecks
+}
+
+func (h *Health) serveLive(w http.ResponseWriter, r *http.Request) {
+	h.lock.RLock()
+	checks := h.liveness
+	h.lock.RUnlock()
+	writeHealth(w, runHealthChecks(checks))
+}
+
+func (h *Health) serveReady(w http.ResponseWriter, r *http.Request) {
+	h.lock.RLock()
+	ready, readynessChecks, checks := h.ready, h.readynessChecks, h.readiness
+	h.lock.RUnlock()
+	if !ready {
+		http.NotFound(w, r)
+		return
+	}
+	for _, readynessCheck := range readynessChecks {
+		if !readynessCheck() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "ReadynessCheck failed")
+			return
 		}
-		fmt.Fprint(w, "OK")
-	})
+	}
+	writeHealth(w, runHealthChecks(checks))
+}
+
+// runHealthChecks runs the checks concurrently and returns the failures. The
+// checks don't run in the context of the probe, so that a probe that gives up
+// doesn't fail the checks the next probes reuse.
+func runHealthChecks(checks []*healthCheckState) []string {
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = checks[i].run(context.Background())
+		}(i)
+	}
+	wg.Wait()
+	var failures []string
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", checks[i].Name, err))
+		}
+	}
+	return failures
+}
+
+func writeHealth(w http.ResponseWriter, failures []string) {
+	if len(failures) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "HealthCheck failed\n%s\n", strings.Join(failures, "\n"))
+		return
+	}
+	fmt.Fprint(w, "OK")
 }
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pjutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func probe(h *Health, path string) (int, string) {
+	w := httptest.NewRecorder()
+	h.healthMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code, w.Body.String()
+}
+
+func TestHealth(t *testing.T) {
+	h := newHealth()
+	if code, _ := probe(h, "/healthz"); code != http.StatusOK {
+		t.Errorf("expected /healthz to succeed without checks, got %d", code)
+	}
+	for _, path := range []string{"/readyz", "/healthz/ready"} {
+		if code, _ := probe(h, path); code == http.StatusOK {
+			t.Errorf("expected %s to fail before ServeReady", path)
+		}
+	}
+
+	var githubErr error
+	var githubChecks int
+	h.AddLivenessChecks(ConfigHealthCheck(func() *config.Config { return &config.Config{} }))
+	h.AddReadinessChecks(HealthCheck{Name: "github", Check: func(context.Context) error {
+		githubChecks++
+		return githubErr
+	}})
+	h.ServeReady()
+	for _, path := range []string{"/healthz", "/readyz", "/healthz/ready"} {
+		if code, body := probe(h, path); code != http.StatusOK || body != "OK" {
+			t.Errorf("expected %s to succeed, got %d: %s", path, code, body)
+		}
+	}
+	if githubChecks != 1 {
+		t.Errorf("expected the result of the check to be reused, got %d checks", githubChecks)
+	}
+
+	h = newHealth()
+	githubErr = errors.New("connection refused")
+	h.AddLivenessChecks(ConfigHealthCheck(func() *config.Config { return nil }))
+	h.AddReadinessChecks(HealthCheck{Name: "github", Check: func(context.Context) error { return githubErr }})
+	h.ServeReady()
+	if code, body := probe(h, "/healthz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "config: config not loaded") {
+		t.Errorf("expected /healthz to fail with the config check, got %d: %s", code, body)
+	}
+	if code, body := probe(h, "/readyz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "github: connection refused") || strings.Contains(body, "config") {
+		t.Errorf("expected /readyz to fail with the github check only, got %d: %s", code, body)
+	}
+
+	h = newHealth()
+	h.ServeReady(func() bool { return false })
+	if code, _ := probe(h, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected /readyz to fail with a failing ReadynessCheck, got %d", code)
+	}
+}
+
+func TestSecretsHealthCheck(t *testing.T) {
+	if err := SecretsHealthCheck().Check(context.Background()); err != nil {
+		t.Errorf("expected no error without secrets, got %v", err)
+	}
+	if err := SecretsHealthCheck("/etc/missing/secret").Check(context.Background()); err == nil {
+		t.Error("expected an error for a secret that isn't loaded")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pjutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/config/secret"
+)
+
+// ConfigHealthCheck checks that the config of the component is loaded.
+func ConfigHealthCheck(cfg config.Getter) HealthCheck {
+	return HealthCheck{Name: "config", Check: func(context.Context) error {
+		if cfg() == nil {
+			return errors.New("config not loaded")
+		}
+		return nil
+	}}
+}
+
+// SecretsHealthCheck checks that the secret agent loaded the secrets at the
+// paths.
+func SecretsHealthCheck(paths ...string) HealthCheck {
+	return HealthCheck{Name: "secrets", Check: func(context.Context) error {
+		var missing []string
+		for _, path := range paths {
+			if len(secret.GetSecret(path)) == 0 {
+				missing = append(missing, path)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("secrets not loaded: %v", missing)
+		}
+		return nil
+	}}
+}
+
+// KubernetesHealthCheck checks that the API server of a cluster can be
+// reached, e.g. with the RESTClient of the discovery client of a clientset.
+func KubernetesHealthCheck(client rest.Interface) HealthCheck {
+	return HealthCheck{Name: "kubernetes", Check: func(ctx context.Context) error {
+		return client.Get().AbsPath("/readyz").Do(ctx).Error()
+	}}
+}
+
+// GitHubHealthCheck checks that GitHub can be reached with the credentials of
+// the client.
+func GitHubHealthCheck(client interface{ Ping(context.Context) error }) HealthCheck {
+	return HealthCheck{Name: "github", Check: client.Ping}
+}
@@ -19,9 +19,12 @@ package pjutil
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/config"
 )
 
 var (
@@ -34,6 +37,7 @@ var (
 	RetestWithTargetNote      = "The `/retest` command does not accept any targets.\n"
 	TargetNotFoundNote        = "The specified target(s) for `/test` were not found.\n"
 	ThereAreNoTestAllJobsNote = "No jobs can be run with `/test all`.\n"
+	NoRegexMatchNote          = "No jobs matched the pattern(s) given to `/test re:` or `/retest re:`.\n"
 )
 
 func MayNeedHelpComment(body string) bool {
@@ -49,6 +53,11 @@ func ShouldRespondWithHelp(body string, toRunOrSkip int) (bool, string) {
 		return true, ""
 	case EmptyTestRe.MatchString(body):
 		return true, TestWithoutTargetNote
+	case TestRegexRe.MatchString(body) || RetestRegexRe.MatchString(body):
+		if toRunOrSkip == 0 {
+			return true, NoRegexMatchNote
+		}
+		return false, ""
 	case RetestWithTargetRe.MatchString(body):
 		return true, RetestWithTargetNote
 	case toRunOrSkip == 0 && TestAllRe.MatchString(body):
@@ -95,3 +104,34 @@ func HelpMessage(org, repo, branch, note string, testAllNames, optionalTestComma
 
 	return resp
 }
+
+// ConditionalPresubmitsMessage returns a user friendly listing of the
+// presubmits that are triggered based on the files changed in the PR, with
+// their file filters and whether they are currently skipped. The listing is
+// empty if there are no such presubmits for the branch.
+func ConditionalPresubmitsMessage(changes config.ChangedFilesProvider, branch string, presubmits []config.Presubmit) (string, error) {
+	var lines []string
+	for _, ps := range presubmits {
+		if ps.AlwaysRun || !ps.RegexpChangeMatcher.CouldRun() || !ps.CouldRun(branch) {
+			continue
+		}
+		_, shouldRun, err := ps.RegexpChangeMatcher.ShouldRun(changes)
+		if err != nil {
+			return "", fmt.Errorf("%s: should run: %w", ps.Name, err)
+		}
+		filter := fmt.Sprintf("run_if_changed: `%s`", ps.RunIfChanged)
+		if ps.SkipIfOnlyChanged != "" {
+			filter = fmt.Sprintf("skip_if_only_changed: `%s`", ps.SkipIfOnlyChanged)
+		}
+		state := "runs automatically"
+		if !shouldRun {
+			state = "currently skipped"
+		}
+		lines = append(lines, fmt.Sprintf("\n* `%s` (%s): %s", ps.Name, filter, state))
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	sort.Strings(lines)
+	return fmt.Sprintf("The following jobs are triggered based on the files changed in this PR:%s\n\n", strings.Join(lines, "")), nil
+}
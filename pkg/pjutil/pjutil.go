@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/prow/pkg/gcsupload"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/pod-utils/decorate"
 	"sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
 )
@@ -147,6 +148,16 @@ func createRefs(pr github.PullRequest, baseSHA string) prowapi.Refs {
 	}
 }
 
+// BatchRefs returns the refs for testing the given PRs together on top of
+// baseSHA. All PRs must target the same repository and branch.
+func BatchRefs(prs []github.PullRequest, baseSHA string) prowapi.Refs {
+	refs := createRefs(prs[0], baseSHA)
+	for _, pr := range prs[1:] {
+		refs.Pulls = append(refs.Pulls, createRefs(pr, baseSHA).Pulls...)
+	}
+	return refs
+}
+
 // NewPresubmit converts a config.Presubmit into a prowapi.ProwJob.
 // The prowapi.Refs are configured correctly per the pr, baseSHA.
 // The eventGUID becomes a github.EventGUID label.
@@ -234,6 +245,7 @@ func specFromJobBase(jb config.JobBase) prowapi.ProwJobSpec {
 		Namespace:       namespace,
 		MaxConcurrency:  jb.MaxConcurrency,
 		ErrorOnEviction: jb.ErrorOnEviction,
+		RetryPolicy:     jb.RetryPolicy,
 
 		ExtraRefs:        DecorateExtraRefs(jb.ExtraRefs, jb),
 		DecorationConfig: jb.DecorationConfig,
@@ -348,21 +360,10 @@ func GetLatestProwJobs(pjs []prowapi.ProwJob, jobType prowapi.ProwJobType) map[s
 	return latestJobs
 }
 
-// ProwJobFields extracts logrus fields from a prowjob useful for logging.
+// ProwJobFields extracts logrus fields from a prowjob useful for logging, see
+// logrusutil.ProwJobFields.
 func ProwJobFields(pj *prowapi.ProwJob) logrus.Fields {
-	fields := make(logrus.Fields)
-	fields["name"] = pj.ObjectMeta.Name
-	fields["job"] = pj.Spec.Job
-	fields["type"] = pj.Spec.Type
-	fields["state"] = pj.Status.State
-	if len(pj.ObjectMeta.Labels[github.EventGUID]) > 0 {
-		fields[github.EventGUID] = pj.ObjectMeta.Labels[github.EventGUID]
-	}
-	if pj.Spec.Refs != nil && len(pj.Spec.Refs.Pulls) == 1 {
-		fields[github.PrLogField] = pj.Spec.Refs.Pulls[0].Number
-		fields[github.RepoLogField] = pj.Spec.Refs.Repo
-		fields[github.OrgLogField] = pj.Spec.Refs.Org
-	}
+	fields := logrusutil.ProwJobFields(pj)
 	if pj.Spec.JenkinsSpec != nil {
 		fields["github_based_job"] = pj.Spec.JenkinsSpec.GitHubBranchSourceJob
 	}
@@ -1428,6 +1428,34 @@ func TestCreateRefs(t *testing.T) {
 	}
 }
 
+func TestBatchRefs(t *testing.T) {
+	pr := func(number int, sha string) github.PullRequest {
+		return github.PullRequest{
+			Number: number,
+			Head:   github.PullRequestBranch{SHA: sha},
+			Base: github.PullRequestBranch{
+				Ref: "master",
+				Repo: github.Repo{
+					Name:    "repo",
+					HTMLURL: "https://github.com/org/repo",
+					Owner:   github.User{Login: "org"},
+				},
+			},
+		}
+	}
+	refs := BatchRefs([]github.PullRequest{pr(1, "111"), pr(2, "222")}, "abcdef")
+	if refs.Org != "org" || refs.Repo != "repo" || refs.BaseRef != "master" || refs.BaseSHA != "abcdef" {
+		t.Errorf("unexpected base refs: %+v", refs)
+	}
+	var pulls []string
+	for _, pull := range refs.Pulls {
+		pulls = append(pulls, fmt.Sprintf("%d:%s", pull.Number, pull.SHA))
+	}
+	if diff := cmp.Diff([]string{"1:111", "2:222"}, pulls); diff != "" {
+		t.Errorf("pulls differ (-want +got):\n%s", diff)
+	}
+}
+
 func TestSpecFromJobBase(t *testing.T) {
 	permittedGroups := []int{1234, 5678}
 	permittedUsers := []string{"authorized_user", "another_authorized_user"}
@@ -18,24 +18,50 @@ limitations under the License.
 package pprof
 
 import (
+	"crypto/subtle"
+	"encoding/json"
 	"net/http"
 	"net/http/pprof"
 	"os"
 	"runtime"
 	runtimepprof "runtime/pprof"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/felixge/fgprof"
 	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/prow/pkg/config/secret"
 	"sigs.k8s.io/prow/pkg/flagutil"
-
 	"sigs.k8s.io/prow/pkg/interrupts"
+	"sigs.k8s.io/prow/pkg/logrusutil"
 )
 
 // Instrument implements the profiling options a user has asked for on the command line.
 func Instrument(opts flagutil.InstrumentationOptions) {
-	Serve(opts.PProfPort)
+	if opts.MutexProfileFraction > 0 {
+		runtime.SetMutexProfileFraction(opts.MutexProfileFraction)
+	}
+	if opts.BlockProfileRate > 0 {
+		runtime.SetBlockProfileRate(opts.BlockProfileRate)
+	}
+
+	var profiling http.Handler = newMux()
+	if opts.ProfilingTokenPath != "" {
+		if err := secret.Add(opts.ProfilingTokenPath); err != nil {
+			logrus.WithError(err).Fatal("Error loading the profiling token.")
+		}
+		profiling = requireToken(secret.GetTokenGenerator(opts.ProfilingTokenPath), profiling)
+	}
+	pprofMux := http.NewServeMux()
+	pprofMux.Handle("/", profiling)
+	if opts.LogLevelTokenPath != "" {
+		if err := secret.Add(opts.LogLevelTokenPath); err != nil {
+			logrus.WithError(err).Fatal("Error loading the log level token.")
+		}
+		pprofMux.Handle("/debug/loglevel", logrusutil.LevelHandler(secret.GetTokenGenerator(opts.LogLevelTokenPath)))
+	}
+	serve(opts.PProfPort, pprofMux)
 	if opts.ProfileMemory {
 		WriteMemoryProfiles(opts.MemoryProfileInterval)
 	}
@@ -46,6 +72,15 @@ func Instrument(opts flagutil.InstrumentationOptions) {
 // the simple case where the default mux is to be used, but with a custom mux to ensure we don't serve
 // this data from an exposed port.
 func Serve(port int) {
+	serve(port, newMux())
+}
+
+func serve(port int, handler http.Handler) {
+	server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: handler}
+	interrupts.ListenAndServe(server, 5*time.Second)
+}
+
+func newMux() *http.ServeMux {
 	pprofMux := http.NewServeMux()
 	pprofMux.HandleFunc("/debug/pprof/", pprof.Index)
 	pprofMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
@@ -53,8 +88,72 @@ func Serve(port int) {
 	pprofMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	pprofMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	pprofMux.Handle("/debug/fgprof", fgprof.Handler())
-	server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: pprofMux}
-	interrupts.ListenAndServe(server, 5*time.Second)
+	pprofMux.HandleFunc("/debug/memstats", memStats)
+	return pprofMux
+}
+
+// requireToken refuses the requests that don't authenticate with the bearer
+// token.
+func requireToken(token func() []byte, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := token()
+		provided := []byte(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if len(expected) == 0 || subtle.ConstantTimeCompare(expected, provided) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// memStatsSnapshot is a summary of the memory allocations of the process.
+type memStatsSnapshot struct {
+	Goroutines   int    `json:"goroutines"`
+	HeapAlloc    uint64 `json:"heap_alloc_bytes"`
+	HeapInuse    uint64 `json:"heap_inuse_bytes"`
+	HeapIdle     uint64 `json:"heap_idle_bytes"`
+	HeapReleased uint64 `json:"heap_released_bytes"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	TotalAlloc   uint64 `json:"total_alloc_bytes"`
+	Mallocs      uint64 `json:"mallocs"`
+	Frees        uint64 `json:"frees"`
+	StackInuse   uint64 `json:"stack_inuse_bytes"`
+	Sys          uint64 `json:"sys_bytes"`
+	NumGC        uint32 `json:"num_gc"`
+	// LastGC is when the last garbage collection finished.
+	LastGC time.Time `json:"last_gc"`
+}
+
+// memStats serves a snapshot of the memory allocations of the process, after
+// a garbage collection if the gc query parameter is set, e.g. to compare
+// snapshots while memory grows without fetching and analyzing heap profiles.
+func memStats(w http.ResponseWriter, r *http.Request) {
+	if gc, _ := strconv.ParseBool(r.URL.Query().Get("gc")); gc {
+		runtime.GC()
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	snapshot := memStatsSnapshot{
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    stats.HeapAlloc,
+		HeapInuse:    stats.HeapInuse,
+		HeapIdle:     stats.HeapIdle,
+		HeapReleased: stats.HeapReleased,
+		HeapObjects:  stats.HeapObjects,
+		TotalAlloc:   stats.TotalAlloc,
+		Mallocs:      stats.Mallocs,
+		Frees:        stats.Frees,
+		StackInuse:   stats.StackInuse,
+		Sys:          stats.Sys,
+		NumGC:        stats.NumGC,
+	}
+	if stats.LastGC != 0 {
+		snapshot.LastGC = time.Unix(0, int64(stats.LastGC)).UTC()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		logrus.WithError(err).Error("Failed to write the memory statistics.")
+	}
 }
 
 // WriteMemoryProfiles is a non-blocking, best-effort routine to dump memory profiles at a
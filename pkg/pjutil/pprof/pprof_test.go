@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pprof
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireToken(t *testing.T) {
+	testcases := []struct {
+		name         string
+		token        string
+		header       string
+		expectedCode int
+	}{
+		{
+			name:         "no token provided",
+			token:        "secret",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "wrong token",
+			token:        "secret",
+			header:       "Bearer wrong",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "empty token refuses all requests",
+			header:       "Bearer ",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "right token",
+			token:        "secret",
+			header:       "Bearer secret",
+			expectedCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := requireToken(func() []byte { return []byte(tc.token) }, newMux())
+			req := httptest.NewRequest(http.MethodGet, "/debug/memstats", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestMemStats(t *testing.T) {
+	w := httptest.NewRecorder()
+	newMux().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/memstats?gc=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var snapshot memStatsSnapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", w.Body.String(), err)
+	}
+	if snapshot.Goroutines == 0 || snapshot.HeapAlloc == 0 || snapshot.NumGC == 0 || snapshot.LastGC.IsZero() {
+		t.Errorf("expected the snapshot to describe the process after a garbage collection, got %+v", snapshot)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pjutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+// WriteProwJobSnapshot writes the ProwJobs as a JSON encoded ProwJobList to
+// the given storage path, so that they can be read without access to the
+// cluster.
+func WriteProwJobSnapshot(ctx context.Context, opener io.Opener, path string, pjs *prowapi.ProwJobList) error {
+	content, err := json.Marshal(pjs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ProwJobs: %w", err)
+	}
+	return io.WriteContent(ctx, logrus.WithField("client", "prowjob-snapshot"), opener, path, content)
+}
+
+// ReadProwJobSnapshot reads the ProwJobs written by WriteProwJobSnapshot.
+func ReadProwJobSnapshot(ctx context.Context, opener io.Opener, path string) (*prowapi.ProwJobList, error) {
+	content, err := io.ReadContent(ctx, logrus.WithField("client", "prowjob-snapshot"), opener, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ProwJob snapshot: %w", err)
+	}
+	pjs := &prowapi.ProwJobList{}
+	if err := json.Unmarshal(content, pjs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ProwJob snapshot: %w", err)
+	}
+	return pjs, nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pjutil
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+func TestProwJobSnapshot(t *testing.T) {
+	ctx := context.Background()
+	opener, err := io.NewOpener(ctx, "", "")
+	if err != nil {
+		t.Fatalf("failed to create opener: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "prowjobs.json")
+
+	if _, err := ReadProwJobSnapshot(ctx, opener, path); err == nil {
+		t.Error("expected reading a missing snapshot to fail")
+	}
+
+	pjs := &prowapi.ProwJobList{Items: []prowapi.ProwJob{{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "prowjobs"},
+		Spec:       prowapi.ProwJobSpec{Job: "job", Type: prowapi.PeriodicJob},
+		Status:     prowapi.ProwJobStatus{State: prowapi.SuccessState},
+	}}}
+	if err := WriteProwJobSnapshot(ctx, opener, path, pjs); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	got, err := ReadProwJobSnapshot(ctx, opener, path)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	if diff := cmp.Diff(pjs, got); diff != "" {
+		t.Errorf("snapshot differs from written ProwJobs: %s", diff)
+	}
+}
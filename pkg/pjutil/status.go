@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pjutil
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// prowJobCreator is the part of the ProwJob clientset that creates ProwJobs.
+type prowJobCreator interface {
+	Create(ctx context.Context, pj *prowapi.ProwJob, opts metav1.CreateOptions) (*prowapi.ProwJob, error)
+	UpdateStatus(ctx context.Context, pj *prowapi.ProwJob, opts metav1.UpdateOptions) (*prowapi.ProwJob, error)
+}
+
+// CreateProwJob creates a ProwJob along with its initial status. As the status
+// of ProwJobs is a subresource, the API server drops it on creation and it is
+// set with a second request then.
+func CreateProwJob(ctx context.Context, pjc prowJobCreator, pj *prowapi.ProwJob) (*prowapi.ProwJob, error) {
+	status := pj.Status.DeepCopy()
+	created, err := pjc.Create(ctx, pj, metav1.CreateOptions{})
+	if err != nil || equality.Semantic.DeepEqual(created.Status, *status) {
+		return created, err
+	}
+	created.Status = *status
+	created.SetConditions()
+	updated, err := pjc.UpdateStatus(ctx, created, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("set status of created prowjob %s: %w", created.Name, err)
+	}
+	return updated, nil
+}
+
+// CreateProwJobWithClient creates a ProwJob along with its initial status
+// using a controller-runtime client.
+func CreateProwJobWithClient(ctx context.Context, c ctrlruntimeclient.Client, pj *prowapi.ProwJob) error {
+	status := pj.Status.DeepCopy()
+	if err := c.Create(ctx, pj); err != nil || equality.Semantic.DeepEqual(pj.Status, *status) {
+		return err
+	}
+	pj.Status = *status
+	pj.SetConditions()
+	if err := c.Status().Update(ctx, pj); err != nil {
+		return fmt.Errorf("set status of created prowjob %s: %w", pj.Name, err)
+	}
+	return nil
+}
+
+// PatchProwJobWithClient patches a ProwJob from prevPJ to pj using a
+// controller-runtime client. As the API server ignores changes of the status
+// in patches of the ProwJob, the status subresource is patched apart, along
+// with the conditions that reflect the state.
+func PatchProwJobWithClient(ctx context.Context, c patchClient, prevPJ, pj *prowapi.ProwJob) error {
+	pj.SetConditions()
+	status := pj.Status.DeepCopy()
+	if !equality.Semantic.DeepEqual(prevPJ.ObjectMeta, pj.ObjectMeta) || !equality.Semantic.DeepEqual(prevPJ.Spec, pj.Spec) {
+		if err := c.Patch(ctx, pj, ctrlruntimeclient.MergeFrom(prevPJ)); err != nil {
+			return err
+		}
+	}
+	if equality.Semantic.DeepEqual(prevPJ.Status, *status) {
+		return nil
+	}
+	// The ProwJob was possibly updated by the patch above, so only diff the status.
+	base := pj.DeepCopy()
+	base.Status = prevPJ.Status
+	pj.Status = *status
+	return c.Status().Patch(ctx, pj, ctrlruntimeclient.MergeFrom(base))
+}
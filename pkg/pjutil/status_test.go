@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pjutil
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/pjutil/fakepjutil"
+)
+
+func TestCreateProwJob(t *testing.T) {
+	pjc := fakepjutil.StatusDroppingClient{ProwJobInterface: fake.NewSimpleClientset().ProwV1().ProwJobs("prowjobs")}
+	pj := &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "prowjobs"},
+		Status:     prowapi.ProwJobStatus{State: prowapi.TriggeredState, StartTime: metav1.Now()},
+	}
+
+	if _, err := CreateProwJob(context.Background(), pjc, pj); err != nil {
+		t.Fatalf("failed to create prowjob: %v", err)
+	}
+
+	actual, err := pjc.Get(context.Background(), pj.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get prowjob: %v", err)
+	}
+	if actual.Status.State != prowapi.TriggeredState {
+		t.Errorf("expected the state to be %s, got %q", prowapi.TriggeredState, actual.Status.State)
+	}
+	if len(actual.Status.Conditions) == 0 {
+		t.Error("expected the conditions to be set")
+	}
+}
+
+// statusIgnoringClient ignores changes of the status in patches of ProwJobs
+// like the API server does for resources with a status subresource.
+type statusIgnoringClient struct {
+	ctrlruntimeclient.Client
+}
+
+func (c *statusIgnoringClient) Patch(ctx context.Context, obj ctrlruntimeclient.Object, patch ctrlruntimeclient.Patch, opts ...ctrlruntimeclient.PatchOption) error {
+	current := &prowapi.ProwJob{}
+	if err := c.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(obj), current); err != nil {
+		return err
+	}
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	pj := obj.(*prowapi.ProwJob)
+	pj.Status = current.Status
+	return c.Client.Update(ctx, pj)
+}
+
+func TestPatchProwJobWithClient(t *testing.T) {
+	prevPJ := &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "prowjobs"},
+		Status:     prowapi.ProwJobStatus{State: prowapi.SchedulingState},
+	}
+	c := &statusIgnoringClient{Client: fakectrlruntimeclient.NewClientBuilder().WithObjects(prevPJ.DeepCopy()).Build()}
+	if err := c.Get(context.Background(), ctrlruntimeclient.ObjectKeyFromObject(prevPJ), prevPJ); err != nil {
+		t.Fatalf("failed to get prowjob: %v", err)
+	}
+
+	pj := prevPJ.DeepCopy()
+	pj.Spec.Cluster = "build"
+	pj.Status.State = prowapi.TriggeredState
+	if err := PatchProwJobWithClient(context.Background(), c, prevPJ, pj); err != nil {
+		t.Fatalf("failed to patch prowjob: %v", err)
+	}
+
+	actual := &prowapi.ProwJob{}
+	if err := c.Get(context.Background(), ctrlruntimeclient.ObjectKeyFromObject(pj), actual); err != nil {
+		t.Fatalf("failed to get prowjob: %v", err)
+	}
+	if actual.Spec.Cluster != "build" {
+		t.Errorf("expected the cluster to be build, got %q", actual.Spec.Cluster)
+	}
+	if actual.Status.State != prowapi.TriggeredState {
+		t.Errorf("expected the state to be %s, got %q", prowapi.TriggeredState, actual.Status.State)
+	}
+	if len(actual.Status.Conditions) == 0 {
+		t.Error("expected the conditions to be set")
+	}
+}
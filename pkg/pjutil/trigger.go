@@ -59,7 +59,7 @@ func TriggerAndWatchProwJob(o prowflagutil.KubernetesOptions, prowjob *pjapi.Pro
 	}
 
 	logrus.WithFields(ProwJobFields(prowjob)).Info("submitting a new prowjob")
-	created, err := pjclient.Create(context.Background(), prowjob, metav1.CreateOptions{})
+	created, err := CreateProwJob(context.Background(), pjclient, prowjob)
 	if err != nil {
 		return false, fmt.Errorf("failed to submit the prowjob: %w", err)
 	}
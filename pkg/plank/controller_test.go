@@ -846,6 +846,7 @@ func TestSyncPendingJob(t *testing.T) {
 		ExpectedPodRunningTimeout     *metav1.Duration
 		ExpectedPodPendingTimeout     *metav1.Duration
 		ExpectedPodUnscheduledTimeout *metav1.Duration
+		ExpectedAttempts              int
 	}
 	testcases := []testCase{
 		{
@@ -1628,6 +1629,174 @@ func TestSyncPendingJob(t *testing.T) {
 			ExpectedComplete: false,
 			ExpectedNumPods:  0,
 		},
+		{
+			Name: "failed pod with retry policy gets retried",
+			PJ: prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "retried",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					PodSpec:     &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+					RetryPolicy: &prowapi.RetryPolicy{MaxAttempts: 2},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:   prowapi.PendingState,
+					PodName: "retried",
+					BuildID: "1",
+				},
+			},
+			Pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "retried",
+						Namespace: "pods",
+						Labels:    map[string]string{kube.ProwBuildIDLabel: "1"},
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodFailed,
+					},
+				},
+			},
+			ExpectedState:    prowapi.PendingState,
+			ExpectedComplete: false,
+			ExpectedNumPods:  0,
+			ExpectedAttempts: 1,
+		},
+		{
+			Name: "failed pod is not retried for reasons the retry policy excludes",
+			PJ: prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "retried",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					PodSpec: &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+					RetryPolicy: &prowapi.RetryPolicy{
+						MaxAttempts:      2,
+						RetryableReasons: []prowapi.RetryReason{prowapi.EvictionRetryReason},
+					},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:   prowapi.PendingState,
+					PodName: "retried",
+					BuildID: "1",
+				},
+			},
+			Pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "retried",
+						Namespace: "pods",
+						Labels:    map[string]string{kube.ProwBuildIDLabel: "1"},
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodFailed,
+					},
+				},
+			},
+			ExpectedState:    prowapi.FailureState,
+			ExpectedComplete: true,
+			ExpectedNumPods:  1,
+		},
+		{
+			Name: "failed pod of the last attempt completes the job",
+			PJ: prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "retried",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					PodSpec:     &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+					RetryPolicy: &prowapi.RetryPolicy{MaxAttempts: 2},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:    prowapi.PendingState,
+					PodName:  "retried",
+					BuildID:  "2",
+					Attempts: []prowapi.ProwJobAttempt{{BuildID: "1", State: prowapi.FailureState, Reason: prowapi.FailureRetryReason}},
+				},
+			},
+			Pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "retried",
+						Namespace: "pods",
+						Labels:    map[string]string{kube.ProwBuildIDLabel: "2"},
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodFailed,
+					},
+				},
+			},
+			ExpectedState:    prowapi.FailureState,
+			ExpectedComplete: true,
+			ExpectedNumPods:  1,
+			ExpectedAttempts: 1,
+		},
+		{
+			Name: "pod of the previous attempt is left to terminate",
+			PJ: prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "retried",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					PodSpec:     &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+					RetryPolicy: &prowapi.RetryPolicy{MaxAttempts: 2},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:    prowapi.PendingState,
+					PodName:  "retried",
+					BuildID:  "1",
+					Attempts: []prowapi.ProwJobAttempt{{BuildID: "1", State: prowapi.FailureState, Reason: prowapi.FailureRetryReason}},
+				},
+			},
+			Pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "retried",
+						Namespace:         "pods",
+						Labels:            map[string]string{kube.ProwBuildIDLabel: "1"},
+						DeletionTimestamp: func() *metav1.Time { n := metav1.Now(); return &n }(),
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodFailed,
+					},
+				},
+			},
+			ExpectedState:    prowapi.PendingState,
+			ExpectedComplete: false,
+			ExpectedNumPods:  1,
+			ExpectedAttempts: 1,
+		},
+		{
+			Name: "next attempt waits for the backoff of the retry policy",
+			PJ: prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "retried",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					PodSpec: &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+					RetryPolicy: &prowapi.RetryPolicy{
+						MaxAttempts: 2,
+						Backoff:     &metav1.Duration{Duration: time.Hour},
+					},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:    prowapi.PendingState,
+					PodName:  "retried",
+					BuildID:  "1",
+					Attempts: []prowapi.ProwJobAttempt{{BuildID: "1", CompletionTime: metav1.Now(), State: prowapi.FailureState, Reason: prowapi.FailureRetryReason}},
+				},
+			},
+			expectedReconcileResult: &reconcile.Result{RequeueAfter: time.Hour},
+			ExpectedState:           prowapi.PendingState,
+			ExpectedComplete:        false,
+			ExpectedNumPods:         0,
+			ExpectedAttempts:        1,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -1719,6 +1888,9 @@ func TestSyncPendingJob(t *testing.T) {
 			if actual := actual.Complete(); actual != tc.ExpectedComplete {
 				t.Errorf("expected complete: %t, got complete: %t", tc.ExpectedComplete, actual)
 			}
+			if got := len(actual.Status.Attempts); got != tc.ExpectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.ExpectedAttempts, got)
+			}
 		})
 	}
 }
@@ -2232,6 +2404,23 @@ func (c *patchTrackingFakeClient) Patch(ctx context.Context, obj ctrlruntimeclie
 	return c.Client.Patch(ctx, obj, patch, opts...)
 }
 
+func (c *patchTrackingFakeClient) Status() ctrlruntimeclient.StatusWriter {
+	return &patchTrackingFakeStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type patchTrackingFakeStatusWriter struct {
+	ctrlruntimeclient.StatusWriter
+	client *patchTrackingFakeClient
+}
+
+func (w *patchTrackingFakeStatusWriter) Patch(ctx context.Context, obj ctrlruntimeclient.Object, patch ctrlruntimeclient.Patch, opts ...ctrlruntimeclient.PatchOption) error {
+	if w.client.patched == nil {
+		w.client.patched = sets.New[string]()
+	}
+	w.client.patched.Insert(obj.GetName())
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
 type deleteTrackingFakeClient struct {
 	deleteError error
 	ctrlruntimeclient.Client
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plank
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+// The phases of a job that the scheduling latency is recorded for.
+const (
+	// phasePodCreated is the time from the creation of a ProwJob until its
+	// pod was created, including the time it waited for concurrency limits.
+	phasePodCreated = "pod_created"
+	// phasePodScheduled is the time from the creation of the pod until it was
+	// scheduled on a node.
+	phasePodScheduled = "pod_scheduled"
+	// phasePodStarted is the time from the scheduling of the pod until its
+	// first container started, e.g. pulling images.
+	phasePodStarted = "pod_started"
+	// phaseFinished is the time from the start of the pod until the job
+	// completed.
+	phaseFinished = "finished"
+)
+
+// The limits that keep triggered jobs from being started.
+const (
+	limitPlank = "plank"
+	limitJob   = "job"
+	limitQueue = "queue"
+)
+
+var plankMetrics = struct {
+	schedulingLatency  *prometheus.HistogramVec
+	queueDepth         *prometheus.GaugeVec
+	concurrencyLimited *prometheus.GaugeVec
+}{
+	schedulingLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "prowjob_scheduling_latency_seconds",
+		Help: "Time ProwJobs spent in each phase from their creation until they finished.",
+		Buckets: []float64{
+			1, 5, 10, 30,
+			time.Minute.Seconds(),
+			(2 * time.Minute).Seconds(),
+			(5 * time.Minute).Seconds(),
+			(10 * time.Minute).Seconds(),
+			(30 * time.Minute).Seconds(),
+			time.Hour.Seconds(),
+			(2 * time.Hour).Seconds(),
+			(4 * time.Hour).Seconds(),
+			(8 * time.Hour).Seconds(),
+		},
+	}, []string{
+		// the phase of the job: pod_created, pod_scheduled, pod_started, finished
+		"phase",
+		// the cluster the job runs on
+		"cluster",
+		// type of the prowjob: presubmit, postsubmit, periodic, batch
+		"type",
+		// the org of the prowjob's repo
+		"org",
+	}),
+	queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "prowjob_queue_depth",
+		Help: "Number of triggered ProwJobs waiting for their pod to be created.",
+	}, []string{
+		"cluster",
+		"org",
+	}),
+	concurrencyLimited: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "prowjob_concurrency_limited",
+		Help: "Number of triggered ProwJobs that a concurrency limit keeps from being started.",
+	}, []string{
+		"cluster",
+		"org",
+		// the limit that holds the jobs back: plank, job or queue
+		"limit",
+	}),
+}
+
+func init() {
+	prometheus.MustRegister(plankMetrics.schedulingLatency)
+	prometheus.MustRegister(plankMetrics.queueDepth)
+	prometheus.MustRegister(plankMetrics.concurrencyLimited)
+}
+
+func prowJobOrg(pj *prowv1.ProwJob) string {
+	if pj.Spec.Refs != nil {
+		return pj.Spec.Refs.Org
+	}
+	if len(pj.Spec.ExtraRefs) > 0 {
+		return pj.Spec.ExtraRefs[0].Org
+	}
+	return ""
+}
+
+// recordSchedulingLatency records the phases of a job that ended with the
+// transition of its state: the time until its pod was created once it is
+// pending, and the time its pod took to be scheduled, to start and to finish
+// once it completed.
+func recordSchedulingLatency(prevPJ, pj *prowv1.ProwJob, pod *corev1.Pod) {
+	if prevPJ.Status.State == pj.Status.State {
+		return
+	}
+	observe := func(phase string, start, end time.Time) {
+		if start.IsZero() || end.IsZero() || end.Before(start) {
+			return
+		}
+		plankMetrics.schedulingLatency.WithLabelValues(phase, pj.ClusterAlias(), string(pj.Spec.Type), prowJobOrg(pj)).Observe(end.Sub(start).Seconds())
+	}
+
+	if prevPJ.Status.State == prowv1.TriggeredState && pj.Status.PendingTime != nil {
+		observe(phasePodCreated, pj.CreationTimestamp.Time, pj.Status.PendingTime.Time)
+	}
+	if prevPJ.Complete() || !pj.Complete() || pod == nil {
+		return
+	}
+	var scheduled time.Time
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionTrue {
+			scheduled = condition.LastTransitionTime.Time
+		}
+	}
+	var started time.Time
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		var containerStarted time.Time
+		if status.State.Running != nil {
+			containerStarted = status.State.Running.StartedAt.Time
+		} else if status.State.Terminated != nil {
+			containerStarted = status.State.Terminated.StartedAt.Time
+		}
+		if !containerStarted.IsZero() && (started.IsZero() || containerStarted.Before(started)) {
+			started = containerStarted
+		}
+	}
+	observe(phasePodScheduled, pod.CreationTimestamp.Time, scheduled)
+	observe(phasePodStarted, scheduled, started)
+	observe(phaseFinished, started, pj.Status.CompletionTime.Time)
+}
+
+type queueLabel struct {
+	cluster string
+	org     string
+}
+
+type concurrencyLabel struct {
+	queueLabel
+	limit string
+}
+
+// gatherQueueMetrics records how many triggered jobs wait for their pod to be
+// created, and how many of them are held back by a concurrency limit.
+func gatherQueueMetrics(cfg config.Plank, pjs []prowv1.ProwJob) {
+	var pending int
+	byName := map[string][]prowv1.ProwJob{}
+	byQueue := map[string][]prowv1.ProwJob{}
+	for _, pj := range pjs {
+		if pj.Status.State != prowv1.PendingState && pj.Status.State != prowv1.TriggeredState {
+			continue
+		}
+		if pj.Status.State == prowv1.PendingState {
+			pending++
+		}
+		byName[pj.Spec.Job] = append(byName[pj.Spec.Job], pj)
+		if pj.Spec.JobQueueName != "" {
+			byQueue[pj.Spec.JobQueueName] = append(byQueue[pj.Spec.JobQueueName], pj)
+		}
+	}
+
+	depth := map[queueLabel]float64{}
+	limited := map[concurrencyLabel]float64{}
+	for _, pj := range pjs {
+		if pj.Status.State != prowv1.TriggeredState {
+			continue
+		}
+		label := queueLabel{cluster: pj.ClusterAlias(), org: prowJobOrg(&pj)}
+		depth[label]++
+		if limit := concurrencyLimit(cfg, pj, pending, byName, byQueue); limit != "" {
+			limited[concurrencyLabel{queueLabel: label, limit: limit}]++
+		}
+	}
+
+	// Remove the series of clusters and orgs that have no jobs waiting anymore.
+	plankMetrics.queueDepth.Reset()
+	for label, count := range depth {
+		plankMetrics.queueDepth.WithLabelValues(label.cluster, label.org).Set(count)
+	}
+	plankMetrics.concurrencyLimited.Reset()
+	for label, count := range limited {
+		plankMetrics.concurrencyLimited.WithLabelValues(label.cluster, label.org, label.limit).Set(count)
+	}
+}
+
+// concurrencyLimit returns the limit that keeps a triggered job from being
+// started, checked in the same order as canExecuteConcurrently does, or an
+// empty string if the job can be started.
+func concurrencyLimit(cfg config.Plank, pj prowv1.ProwJob, pending int, byName, byQueue map[string][]prowv1.ProwJob) string {
+	if cfg.MaxConcurrency > 0 && pending >= cfg.MaxConcurrency {
+		return limitPlank
+	}
+	if pj.Spec.MaxConcurrency > 0 && countPendingOrOlderTriggeredMatchingPJs(pj, byName[pj.Spec.Job]) >= pj.Spec.MaxConcurrency {
+		return limitJob
+	}
+	if queueName := pj.Spec.JobQueueName; queueName != "" {
+		if capacity, ok := cfg.JobQueueCapacities[queueName]; ok && capacity >= 0 && countPendingOrOlderTriggeredMatchingPJs(pj, byQueue[queueName]) >= capacity {
+			return limitQueue
+		}
+	}
+	return ""
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plank
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+func TestRecordSchedulingLatency(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(created.Add(d))
+		return &t
+	}
+	pj := prowv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: *at(0)},
+		Spec: prowv1.ProwJobSpec{
+			Type:    prowv1.PresubmitJob,
+			Cluster: "build",
+			Refs:    &prowv1.Refs{Org: "org", Repo: "repo"},
+		},
+		Status: prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+	}
+	pending := pj.DeepCopy()
+	pending.Status.State = prowv1.PendingState
+	pending.Status.PendingTime = at(10 * time.Second)
+	complete := pending.DeepCopy()
+	complete.Status.State = prowv1.SuccessState
+	complete.Status.CompletionTime = at(10 * time.Minute)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: *at(10 * time.Second)},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: *at(5 * time.Minute)},
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: *at(40 * time.Second)},
+			},
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{StartedAt: *at(2 * time.Minute)}}},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{StartedAt: *at(3 * time.Minute)}}},
+			},
+		},
+	}
+
+	plankMetrics.schedulingLatency.Reset()
+	recordSchedulingLatency(&pj, pending, nil)
+	recordSchedulingLatency(pending, pending, pod)
+	recordSchedulingLatency(pending, complete, pod)
+
+	expected := map[string]float64{
+		phasePodCreated:   10,
+		phasePodScheduled: 30,
+		phasePodStarted:   80,
+		phaseFinished:     480,
+	}
+	for phase, seconds := range expected {
+		histogram := plankMetrics.schedulingLatency.WithLabelValues(phase, "build", "presubmit", "org").(prometheus.Metric)
+		var metric dto.Metric
+		if err := histogram.Write(&metric); err != nil {
+			t.Fatalf("failed to read the histogram of %s: %v", phase, err)
+		}
+		if count := metric.GetHistogram().GetSampleCount(); count != 1 {
+			t.Errorf("expected 1 sample for %s, got %d", phase, count)
+		}
+		if sum := metric.GetHistogram().GetSampleSum(); sum != seconds {
+			t.Errorf("expected %s to take %vs, got %vs", phase, seconds, sum)
+		}
+	}
+}
+
+func TestGatherQueueMetrics(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	job := func(uid string, state prowv1.ProwJobState, name, queue string, maxConcurrency int, age time.Duration) prowv1.ProwJob {
+		return prowv1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid), CreationTimestamp: metav1.NewTime(created.Add(-age))},
+			Spec: prowv1.ProwJobSpec{
+				Job:            name,
+				Cluster:        "build",
+				JobQueueName:   queue,
+				MaxConcurrency: maxConcurrency,
+				Refs:           &prowv1.Refs{Org: "org"},
+			},
+			Status: prowv1.ProwJobStatus{State: state},
+		}
+	}
+	testcases := []struct {
+		name     string
+		cfg      config.Plank
+		pjs      []prowv1.ProwJob
+		expected string
+	}{
+		{
+			name: "jobs held back by their job and queue",
+			cfg:  config.Plank{JobQueueCapacities: map[string]int{"queue": 1}},
+			pjs: []prowv1.ProwJob{
+				job("1", prowv1.PendingState, "limited", "", 1, time.Hour),
+				job("2", prowv1.TriggeredState, "limited", "", 1, time.Minute),
+				job("3", prowv1.TriggeredState, "queued", "queue", 0, 2*time.Minute),
+				job("4", prowv1.TriggeredState, "queued", "queue", 0, time.Minute),
+				job("5", prowv1.TriggeredState, "other", "", 0, time.Minute),
+				job("6", prowv1.SuccessState, "other", "", 0, time.Hour),
+			},
+			expected: `
+# HELP prowjob_concurrency_limited Number of triggered ProwJobs that a concurrency limit keeps from being started.
+# TYPE prowjob_concurrency_limited gauge
+prowjob_concurrency_limited{cluster="build",limit="job",org="org"} 1
+prowjob_concurrency_limited{cluster="build",limit="queue",org="org"} 1
+# HELP prowjob_queue_depth Number of triggered ProwJobs waiting for their pod to be created.
+# TYPE prowjob_queue_depth gauge
+prowjob_queue_depth{cluster="build",org="org"} 4
+`,
+		},
+		{
+			name: "all jobs held back by the limit of plank",
+			cfg:  config.Plank{Controller: config.Controller{MaxConcurrency: 1}},
+			pjs: []prowv1.ProwJob{
+				job("1", prowv1.PendingState, "job", "", 0, time.Hour),
+				job("2", prowv1.TriggeredState, "job", "", 0, time.Minute),
+			},
+			expected: `
+# HELP prowjob_concurrency_limited Number of triggered ProwJobs that a concurrency limit keeps from being started.
+# TYPE prowjob_concurrency_limited gauge
+prowjob_concurrency_limited{cluster="build",limit="plank",org="org"} 1
+# HELP prowjob_queue_depth Number of triggered ProwJobs waiting for their pod to be created.
+# TYPE prowjob_queue_depth gauge
+prowjob_queue_depth{cluster="build",org="org"} 1
+`,
+		},
+		{
+			name: "no jobs waiting",
+			pjs: []prowv1.ProwJob{
+				job("1", prowv1.PendingState, "job", "", 0, time.Hour),
+			},
+		},
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(plankMetrics.queueDepth, plankMetrics.concurrencyLimited)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatherQueueMetrics(tc.cfg, tc.pjs)
+			if err := testutil.GatherAndCompare(registry, strings.NewReader(tc.expected)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	pj := prowv1.ProwJob{Spec: prowv1.ProwJobSpec{Job: "job", JobQueueName: "disabled"}}
+	cfg := config.Plank{JobQueueCapacities: map[string]int{"disabled": 0}}
+	if diff := cmp.Diff(limitQueue, concurrencyLimit(cfg, pj, 0, nil, nil)); diff != "" {
+		t.Errorf("limit mismatch. Want(-), got(+):\n%s", diff)
+	}
+	cfg.JobQueueCapacities["disabled"] = -1
+	if diff := cmp.Diff("", concurrencyLimit(cfg, pj, 0, nil, nil)); diff != "" {
+		t.Errorf("limit mismatch. Want(-), got(+):\n%s", diff)
+	}
+}
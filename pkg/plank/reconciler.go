@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -54,6 +55,7 @@ import (
 	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pod-utils/decorate"
+	"sigs.k8s.io/prow/pkg/tracing"
 	"sigs.k8s.io/prow/pkg/version"
 )
 
@@ -167,6 +169,10 @@ func add(
 		return fmt.Errorf("failed to add cluster status runnable to manager: %w", err)
 	}
 
+	if err := mgr.Add(manager.RunnableFunc(r.sampleResourceUsage)); err != nil {
+		return fmt.Errorf("failed to add resource usage runnable to manager: %w", err)
+	}
+
 	return nil
 }
 
@@ -217,6 +223,9 @@ type reconciler struct {
 	*/
 	maxConcurrencySerializationLocks *shardedLock
 	jobQueueSerializationLocks       *shardedLock
+	// resourceUsage holds the resource usage sampled from the pods of
+	// pending jobs, to store it in their status once they complete.
+	resourceUsage resourceUsageTracker
 }
 
 type shardedLock struct {
@@ -252,6 +261,7 @@ func (r *reconciler) syncMetrics(ctx context.Context) error {
 				continue
 			}
 			kube.GatherProwJobMetrics(r.log, pjs.Items)
+			gatherQueueMetrics(r.config().Plank, pjs.Items)
 		}
 	}
 }
@@ -355,7 +365,7 @@ func (r *reconciler) defaultReconcile(ctx context.Context, request reconcile.Req
 			pj.SetComplete()
 			pj.Status.State = prowv1.ErrorState
 			pj.Status.Description = fmt.Sprintf("Terminal error: %v.", err)
-			if err := r.pjClient.Patch(ctx, pj, ctrlruntimeclient.MergeFrom(originalPJ)); err != nil {
+			if err := pjutil.PatchProwJobWithClient(ctx, r.pjClient, originalPJ, pj); err != nil {
 				// If we fail to complete and mark the job as errorer we will try again on the next sync loop.
 				log.Errorf("Error marking job with terminal failure as errored: %v.", err)
 			} else {
@@ -435,7 +445,25 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 		return nil, err
 	}
 
+	var lastAttempt *prowv1.ProwJobAttempt
+	if n := len(pj.Status.Attempts); n > 0 {
+		lastAttempt = &pj.Status.Attempts[n-1]
+	}
+	if podExists && lastAttempt != nil && getPodBuildID(pod) == lastAttempt.BuildID {
+		// The pod of the previous attempt is still being deleted, its deletion
+		// triggers another sync that starts the next attempt.
+		return nil, nil
+	}
+
+	// retryReason is why the current attempt failed, if it did.
+	var retryReason prowv1.RetryReason
 	if !podExists {
+		if lastAttempt != nil {
+			// Wait for the backoff of the retry policy before starting the next attempt.
+			if backoff := lastAttempt.CompletionTime.Add(pj.Spec.RetryPolicy.GetBackoff()).Sub(r.clock.Now()); backoff > 0 {
+				return &reconcile.Result{RequeueAfter: backoff}, nil
+			}
+		}
 		// Pod is missing. This can happen in case the previous pod was deleted manually or by
 		// a rescheduler. Start a new pod.
 		id, pn, err := r.startPod(ctx, pj)
@@ -450,6 +478,10 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 		} else {
 			pj.Status.BuildID = id
 			pj.Status.PodName = pn
+			if pj.Status.PendingTime == nil {
+				now := metav1.NewTime(r.clock.Now())
+				pj.Status.PendingTime = &now
+			}
 			r.log.WithFields(pjutil.ProwJobFields(pj)).Info("Pod is missing, starting a new pod")
 		}
 	} else if pod.Status.Reason == Evicted {
@@ -461,6 +493,7 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 			pj.SetComplete()
 			pj.Status.State = prowv1.ErrorState
 			pj.Status.Description = "Job pod was evicted by the cluster."
+			retryReason = prowv1.EvictionRetryReason
 		} else {
 			// ErrorOnEviction is disabled. Delete the pod now and recreate it in
 			// the next resync.
@@ -540,6 +573,7 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 			pj.SetComplete()
 			pj.Status.State = prowv1.FailureState
 			pj.Status.Description = "Job failed."
+			retryReason = prowv1.FailureRetryReason
 
 		case corev1.PodPending:
 			var requeueAfter time.Duration
@@ -558,6 +592,7 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 					pj.SetComplete()
 					pj.Status.State = prowv1.ErrorState
 					pj.Status.Description = "Pod scheduling timeout."
+					retryReason = prowv1.PodPendingTimeoutRetryReason
 					r.log.WithFields(pjutil.ProwJobFields(pj)).Info("Marked job for stale unscheduled pod as errored.")
 					if err := r.deletePod(ctx, pj); err != nil {
 						return nil, fmt.Errorf("failed to delete pod %s/%s in cluster %s: %w", pod.Namespace, pod.Name, pj.ClusterAlias(), err)
@@ -575,6 +610,7 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 					pj.SetComplete()
 					pj.Status.State = prowv1.ErrorState
 					pj.Status.Description = "Pod pending timeout."
+					retryReason = prowv1.PodPendingTimeoutRetryReason
 					r.log.WithFields(pjutil.ProwJobFields(pj)).Info("Marked job for stale pending pod as errored.")
 					if err := r.deletePod(ctx, pj); err != nil {
 						return nil, fmt.Errorf("failed to delete pod %s/%s in cluster %s: %w", pod.Namespace, pod.Name, pj.ClusterAlias(), err)
@@ -609,6 +645,7 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 			pj.SetComplete()
 			pj.Status.State = prowv1.AbortedState
 			pj.Status.Description = "Pod running timeout."
+			retryReason = prowv1.PodRunningTimeoutRetryReason
 			if err := r.deletePod(ctx, pj); err != nil {
 				return nil, fmt.Errorf("failed to delete pod %s/%s in cluster %s: %w", pod.Namespace, pod.Name, pj.ClusterAlias(), err)
 			}
@@ -626,6 +663,7 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 		pj.SetComplete()
 		pj.Status.State = prowv1.ErrorState
 		pj.Status.Description = "Pod got deleted unexpectedly"
+		retryReason = prowv1.PodDeletedRetryReason
 	}
 
 	pj.Status.URL, err = pjutil.JobURL(r.config().Plank, *pj, r.log)
@@ -633,21 +671,37 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 		r.log.WithFields(pjutil.ProwJobFields(pj)).WithError(err).Warn("failed to get jobURL")
 	}
 
+	if pj.Complete() && pj.Spec.RetryPolicy.Retries(len(pj.Status.Attempts)+1, retryReason) {
+		if err := r.retry(ctx, pj, pod, retryReason); err != nil {
+			return nil, err
+		}
+	}
+
+	if pj.Complete() {
+		pj.Status.ResourceUsage = r.resourceUsage.get(pj.Name)
+	}
+
 	if prevPJ.Status.State != pj.Status.State {
 		r.log.WithFields(pjutil.ProwJobFields(pj)).
 			WithField("from", prevPJ.Status.State).
 			WithField("to", pj.Status.State).Info("Transitioning states.")
 	}
 
-	if err := r.pjClient.Patch(ctx, pj.DeepCopy(), ctrlruntimeclient.MergeFrom(prevPJ)); err != nil {
+	if err := pjutil.PatchProwJobWithClient(ctx, r.pjClient, prevPJ, pj.DeepCopy()); err != nil {
 		return nil, fmt.Errorf("patching prowjob: %w", err)
 	}
+	traceTransition(ctx, prevPJ, pj)
+	recordSchedulingLatency(prevPJ, pj, pod)
+	if pj.Complete() {
+		r.resourceUsage.forget(pj.Name)
+	}
 
 	// If the ProwJob state has changed, we must ensure that the update reaches the cache before
 	// processing the key again. Without this we might accidentally replace intentionally deleted pods
 	// or otherwise incorrectly react to stale ProwJob state.
 	state := pj.Status.State
-	if prevPJ.Status.State == state {
+	attempts := len(pj.Status.Attempts)
+	if prevPJ.Status.State == state && len(prevPJ.Status.Attempts) == attempts {
 		return nil, nil
 	}
 	nn := types.NamespacedName{Namespace: pj.Namespace, Name: pj.Name}
@@ -655,7 +709,7 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 		if err := r.pjClient.Get(ctx, nn, pj); err != nil {
 			return false, fmt.Errorf("failed to get prowjob: %w", err)
 		}
-		return pj.Status.State == state, nil
+		return pj.Status.State == state && len(pj.Status.Attempts) == attempts, nil
 	}); err != nil {
 		return nil, fmt.Errorf("failed to wait for cached prowjob %s to get into state %s: %w", nn.String(), state, err)
 	}
@@ -663,6 +717,47 @@ func (r *reconciler) syncPendingJob(ctx context.Context, pj *prowv1.ProwJob) (*r
 	return nil, nil
 }
 
+// retry records the failed attempt of a job in its status and deletes its pod,
+// so that the next sync starts the next attempt.
+func (r *reconciler) retry(ctx context.Context, pj *prowv1.ProwJob, pod *corev1.Pod, reason prowv1.RetryReason) error {
+	pj.Status.Attempts = append(pj.Status.Attempts, prowv1.ProwJobAttempt{
+		BuildID:        pj.Status.BuildID,
+		URL:            pj.Status.URL,
+		PendingTime:    pj.Status.PendingTime,
+		CompletionTime: *pj.Status.CompletionTime,
+		State:          pj.Status.State,
+		Reason:         reason,
+		Description:    pj.Status.Description,
+	})
+	r.log.WithFields(pjutil.ProwJobFields(pj)).WithField("reason", reason).WithField("attempt", len(pj.Status.Attempts)).Info("Retrying job.")
+
+	if pod != nil {
+		client, ok := r.buildClients[pj.ClusterAlias()]
+		if !ok {
+			return TerminalError(fmt.Errorf("retried pod %s: unknown cluster alias %q", pod.Name, pj.ClusterAlias()))
+		}
+		if finalizers := sets.New[string](pod.Finalizers...); finalizers.Has(kubernetesreporterapi.FinalizerName) {
+			// The job is not complete yet, so the finalizer has to be removed here, otherwise the pod hangs
+			oldPod := pod.DeepCopy()
+			pod.Finalizers = finalizers.Delete(kubernetesreporterapi.FinalizerName).UnsortedList()
+			if err := client.Patch(ctx, pod, ctrlruntimeclient.MergeFrom(oldPod)); err != nil {
+				return fmt.Errorf("failed to patch pod trying to remove %s finalizer: %w", kubernetesreporterapi.FinalizerName, err)
+			}
+		}
+		if pod.DeletionTimestamp == nil {
+			if err := ctrlruntimeclient.IgnoreNotFound(client.Delete(ctx, pod)); err != nil {
+				return fmt.Errorf("failed to delete pod %s/%s in cluster %s: %w", pod.Namespace, pod.Name, pj.ClusterAlias(), err)
+			}
+		}
+	}
+
+	pj.Status.State = prowv1.PendingState
+	pj.Status.CompletionTime = nil
+	pj.Status.PendingTime = nil
+	pj.Status.Description = fmt.Sprintf("Attempt %d of %d failed, retrying.", len(pj.Status.Attempts), pj.Spec.RetryPolicy.MaxAttempts)
+	return nil
+}
+
 // syncTriggeredJob syncs jobs that do not yet have an associated test workload running
 func (r *reconciler) syncTriggeredJob(ctx context.Context, pj *prowv1.ProwJob) (*reconcile.Result, error) {
 	prevPJ := pj.DeepCopy()
@@ -721,9 +816,11 @@ func (r *reconciler) syncTriggeredJob(ctx context.Context, pj *prowv1.ProwJob) (
 			WithField("from", prevPJ.Status.State).
 			WithField("to", pj.Status.State).Info("Transitioning states.")
 	}
-	if err := r.pjClient.Patch(ctx, pj.DeepCopy(), ctrlruntimeclient.MergeFrom(prevPJ)); err != nil {
+	if err := pjutil.PatchProwJobWithClient(ctx, r.pjClient, prevPJ, pj.DeepCopy()); err != nil {
 		return nil, fmt.Errorf("patch prowjob: %w", err)
 	}
+	traceTransition(ctx, prevPJ, pj)
+	recordSchedulingLatency(prevPJ, pj, nil)
 
 	// If the job has either MaxConcurrency or JobQueueName configured, we must block here until we observe the state transition in our cache,
 	// otherwise subequent reconciliations for a different run of the same job might incorrectly conclude that they
@@ -745,6 +842,33 @@ func (r *reconciler) syncTriggeredJob(ctx context.Context, pj *prowv1.ProwJob) (
 	return nil, nil
 }
 
+// traceTransition records the phases of a job that ended with the transition
+// of its state in the trace of the job: the time it waited for its pod to be
+// created, and the time its pod ran.
+func traceTransition(ctx context.Context, prevPJ, pj *prowv1.ProwJob) {
+	if prevPJ.Status.State == pj.Status.State || pj.Status.StartTime.IsZero() {
+		return
+	}
+	if prevPJ.Status.State == prowv1.TriggeredState {
+		end := pj.Status.PendingTime
+		if end == nil {
+			end = pj.Status.CompletionTime
+		}
+		if end != nil {
+			tracing.RecordProwJobSpan(ctx, pj, "schedule prowjob", pj.Status.StartTime.Time, end.Time,
+				attribute.String("prow.cluster", pj.ClusterAlias()))
+		}
+	}
+	if !prevPJ.Complete() && pj.Complete() {
+		start := pj.Status.StartTime
+		if pj.Status.PendingTime != nil {
+			start = *pj.Status.PendingTime
+		}
+		tracing.RecordProwJobSpan(ctx, pj, "run prowjob", start.Time, pj.Status.CompletionTime.Time,
+			attribute.String("prow.cluster", pj.ClusterAlias()), attribute.String("prow.state", string(pj.Status.State)))
+	}
+}
+
 // syncAbortedJob syncs jobs that got aborted because their result isn't needed anymore,
 // for example because of a new push or because a pull request got closed.
 func (r *reconciler) syncAbortedJob(ctx context.Context, pj *prowv1.ProwJob) error {
@@ -765,7 +889,7 @@ func (r *reconciler) syncAbortedJob(ctx context.Context, pj *prowv1.ProwJob) err
 
 	originalPJ := pj.DeepCopy()
 	pj.SetComplete()
-	return r.pjClient.Patch(ctx, pj, ctrlruntimeclient.MergeFrom(originalPJ))
+	return pjutil.PatchProwJobWithClient(ctx, r.pjClient, originalPJ, pj)
 }
 
 // pod Gets pod for a pj, returns pod, whether pod exist, and error.
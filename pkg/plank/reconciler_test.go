@@ -28,6 +28,9 @@ import (
 
 	"github.com/go-test/deep"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -52,6 +55,7 @@ import (
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/io"
+	"sigs.k8s.io/prow/pkg/tracing"
 )
 
 func TestAdd(t *testing.T) {
@@ -712,3 +716,75 @@ func TestSyncClusterStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestTraceTransition(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	ctx, span := tracing.Tracer().Start(context.Background(), "webhook")
+	span.End()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(start.Add(d))
+		return &t
+	}
+	triggered := &prowv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job"},
+		Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, StartTime: *at(0)},
+	}
+	tracing.InjectProwJob(ctx, triggered)
+	pending := triggered.DeepCopy()
+	pending.Status.State, pending.Status.PendingTime = prowv1.PendingState, at(time.Minute)
+	succeeded := pending.DeepCopy()
+	succeeded.Status.State, succeeded.Status.CompletionTime = prowv1.SuccessState, at(time.Hour)
+	errored := triggered.DeepCopy()
+	errored.Status.State, errored.Status.CompletionTime = prowv1.ErrorState, at(time.Second)
+
+	testCases := []struct {
+		name          string
+		prevPJ, pj    *prowv1.ProwJob
+		expectedSpans map[string]time.Duration
+	}{
+		{
+			name:          "pod created",
+			prevPJ:        triggered,
+			pj:            pending,
+			expectedSpans: map[string]time.Duration{"schedule prowjob": time.Minute},
+		},
+		{
+			name:          "pod completed",
+			prevPJ:        pending,
+			pj:            succeeded,
+			expectedSpans: map[string]time.Duration{"run prowjob": time.Hour - time.Minute},
+		},
+		{
+			name:          "pod could not be created",
+			prevPJ:        triggered,
+			pj:            errored,
+			expectedSpans: map[string]time.Duration{"schedule prowjob": time.Second, "run prowjob": time.Second},
+		},
+		{
+			name:   "no transition",
+			prevPJ: pending,
+			pj:     pending,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorded := len(recorder.Ended())
+			traceTransition(context.Background(), tc.prevPJ, tc.pj)
+			spans := map[string]time.Duration{}
+			for _, span := range recorder.Ended()[recorded:] {
+				spans[span.Name()] = span.EndTime().Sub(span.StartTime())
+			}
+			if len(tc.expectedSpans) == 0 {
+				tc.expectedSpans = map[string]time.Duration{}
+			}
+			if diff := deep.Equal(tc.expectedSpans, spans); diff != nil {
+				t.Errorf("spans mismatch: %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plank
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/pjutil"
+)
+
+// podMetricsGVK is the kind the metrics API serves the resource usage of pods as.
+var podMetricsGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetrics"}
+
+// podMetrics is the part of a PodMetrics of the metrics API that plank uses.
+// It is read as unstructured to not depend on the metrics client.
+type podMetrics struct {
+	// Timestamp is the end of the window the usage was averaged over.
+	Timestamp  metav1.Time        `json:"timestamp"`
+	Window     metav1.Duration    `json:"window"`
+	Containers []containerMetrics `json:"containers"`
+}
+
+type containerMetrics struct {
+	Name  string              `json:"name"`
+	Usage corev1.ResourceList `json:"usage"`
+}
+
+// resourceUsageTracker accumulates the resource usage sampled from the pods of
+// pending ProwJobs until the jobs complete.
+type resourceUsageTracker struct {
+	lock  sync.Mutex
+	usage map[string]*trackedUsage
+}
+
+type trackedUsage struct {
+	prowv1.ResourceUsage
+	// cpuMilliSeconds is kept apart from CPUSeconds to not lose the fractions
+	// of seconds of every sample.
+	cpuMilliSeconds int64
+	lastSample      time.Time
+}
+
+// record adds a sample of the usage of the pod of a ProwJob. Samples of a
+// window that was already recorded are ignored, as the metrics API refreshes
+// less often than it may be sampled.
+func (t *resourceUsageTracker) record(name string, m *podMetrics) {
+	var cpu, memory resource.Quantity
+	for _, container := range m.Containers {
+		cpu.Add(container.Usage[corev1.ResourceCPU])
+		memory.Add(container.Usage[corev1.ResourceMemory])
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.usage == nil {
+		t.usage = map[string]*trackedUsage{}
+	}
+	usage, ok := t.usage[name]
+	if !ok {
+		usage = &trackedUsage{}
+		t.usage[name] = usage
+	}
+	if !m.Timestamp.Time.After(usage.lastSample) {
+		return
+	}
+	// The usage is an average over the window, so the first sample accounts
+	// for its window and every later one for the time since the previous one.
+	elapsed := m.Window.Duration
+	if !usage.lastSample.IsZero() {
+		elapsed = m.Timestamp.Time.Sub(usage.lastSample)
+	}
+	usage.lastSample = m.Timestamp.Time
+	usage.cpuMilliSeconds += cpu.MilliValue() * elapsed.Milliseconds() / 1000
+	usage.CPUSeconds = usage.cpuMilliSeconds / 1000
+	if cpu.Cmp(usage.PeakCPU) > 0 {
+		usage.PeakCPU = cpu
+	}
+	if memory.Cmp(usage.PeakMemory) > 0 {
+		usage.PeakMemory = memory
+	}
+	usage.Samples++
+}
+
+// get returns the usage recorded for a ProwJob, nil if there is none.
+func (t *resourceUsageTracker) get(name string) *prowv1.ResourceUsage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	usage, ok := t.usage[name]
+	if !ok {
+		return nil
+	}
+	return usage.ResourceUsage.DeepCopy()
+}
+
+// forget drops the usage recorded for a ProwJob once it is stored in its status.
+func (t *resourceUsageTracker) forget(name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.usage, name)
+}
+
+// prune drops the usage recorded for ProwJobs that are not pending anymore,
+// e.g. because they were aborted or deleted.
+func (t *resourceUsageTracker) prune(pending sets.Set[string]) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for name := range t.usage {
+		if !pending.Has(name) {
+			delete(t.usage, name)
+		}
+	}
+}
+
+// sampleResourceUsage periodically samples the usage of the pods of pending
+// ProwJobs while resource usage sampling is enabled.
+func (r *reconciler) sampleResourceUsage(ctx context.Context) error {
+	for {
+		// Check for the sampling to get enabled at the same interval as the
+		// metrics are synced.
+		interval := 30 * time.Second
+		if d := r.config().Plank.ResourceUsageSamplingInterval; d != nil && d.Duration > 0 {
+			interval = d.Duration
+			r.syncResourceUsage(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (r *reconciler) syncResourceUsage(ctx context.Context) {
+	pjs := &prowv1.ProwJobList{}
+	if err := r.pjClient.List(ctx, pjs, optPendingProwJobs()); err != nil {
+		r.log.WithError(err).Error("Failed to list pending prowjobs for sampling their resource usage.")
+		return
+	}
+
+	pending := sets.New[string]()
+	for i := range pjs.Items {
+		pj := &pjs.Items[i]
+		if pj.Spec.Agent != prowv1.KubernetesAgent || pj.Status.PodName == "" {
+			continue
+		}
+		pending.Insert(pj.Name)
+		m, err := r.podMetrics(ctx, pj)
+		if err != nil {
+			// The pod might not run yet or the metrics API might not be
+			// served in the build cluster, so this is not worth more.
+			r.log.WithFields(pjutil.ProwJobFields(pj)).WithError(err).Debug("Failed to sample the resource usage of the pod.")
+			continue
+		}
+		r.resourceUsage.record(pj.Name, m)
+	}
+	r.resourceUsage.prune(pending)
+}
+
+// podMetrics gets the current usage of the pod of a ProwJob from the metrics
+// API of its build cluster.
+func (r *reconciler) podMetrics(ctx context.Context, pj *prowv1.ProwJob) (*podMetrics, error) {
+	client, ok := r.buildClients[pj.ClusterAlias()]
+	if !ok {
+		return nil, fmt.Errorf("unknown cluster alias %q", pj.ClusterAlias())
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(podMetricsGVK)
+	if err := client.Get(ctx, types.NamespacedName{Namespace: r.config().PodNamespace, Name: pj.Status.PodName}, u); err != nil {
+		return nil, err
+	}
+	m := &podMetrics{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
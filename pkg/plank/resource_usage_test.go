@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plank
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+var quantityComparer = cmp.Comparer(func(a, b resource.Quantity) bool { return a.Cmp(b) == 0 })
+
+func newPodMetrics(name string, timestamp time.Time, usages ...map[string]interface{}) *unstructured.Unstructured {
+	var containers []interface{}
+	for _, usage := range usages {
+		containers = append(containers, map[string]interface{}{"name": "test", "usage": usage})
+	}
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"timestamp":  timestamp.Format(time.RFC3339),
+		"window":     "30s",
+		"containers": containers,
+	}}
+	u.SetGroupVersionKind(podMetricsGVK)
+	u.SetNamespace("pods")
+	u.SetName(name)
+	return u
+}
+
+func TestRecordResourceUsage(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sample := func(after time.Duration, cpu, memory string) *podMetrics {
+		return &podMetrics{
+			Timestamp:  metav1.NewTime(start.Add(after)),
+			Window:     metav1.Duration{Duration: 30 * time.Second},
+			Containers: []containerMetrics{{Name: "test", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}}},
+		}
+	}
+	testcases := []struct {
+		name     string
+		samples  []*podMetrics
+		expected *prowv1.ResourceUsage
+	}{
+		{
+			name:     "first sample accounts for its window",
+			samples:  []*podMetrics{sample(0, "2", "1Gi")},
+			expected: &prowv1.ResourceUsage{PeakCPU: resource.MustParse("2"), PeakMemory: resource.MustParse("1Gi"), CPUSeconds: 60, Samples: 1},
+		},
+		{
+			name:     "later samples account for the time since the previous one",
+			samples:  []*podMetrics{sample(0, "1", "2Gi"), sample(time.Minute, "1500m", "1Gi"), sample(90*time.Second, "100m", "512Mi")},
+			expected: &prowv1.ResourceUsage{PeakCPU: resource.MustParse("1500m"), PeakMemory: resource.MustParse("2Gi"), CPUSeconds: 123, Samples: 3},
+		},
+		{
+			name:     "samples of a window that was already recorded are ignored",
+			samples:  []*podMetrics{sample(0, "1", "1Gi"), sample(0, "1", "1Gi"), sample(-time.Minute, "1", "1Gi")},
+			expected: &prowv1.ResourceUsage{PeakCPU: resource.MustParse("1"), PeakMemory: resource.MustParse("1Gi"), CPUSeconds: 30, Samples: 1},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var tracker resourceUsageTracker
+			for _, m := range tc.samples {
+				tracker.record("job", m)
+			}
+			if diff := cmp.Diff(tc.expected, tracker.get("job"), quantityComparer); diff != "" {
+				t.Errorf("usage mismatch. Want(-), got(+):\n%s", diff)
+			}
+			tracker.forget("job")
+			if usage := tracker.get("job"); usage != nil {
+				t.Errorf("expected the usage to be forgotten, got %+v", usage)
+			}
+		})
+	}
+}
+
+func TestSyncResourceUsage(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newPJ := func(name string, state prowv1.ProwJobState) *prowv1.ProwJob {
+		return &prowv1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prowjobs"},
+			Spec:       prowv1.ProwJobSpec{Agent: prowv1.KubernetesAgent},
+			Status:     prowv1.ProwJobStatus{State: state, PodName: name},
+		}
+	}
+	pjClient := &indexingClient{
+		Client:     fakectrlruntimeclient.NewClientBuilder().WithObjects(newPJ("running", prowv1.PendingState), newPJ("scheduling", prowv1.PendingState), newPJ("done", prowv1.SuccessState)).Build(),
+		indexFuncs: map[string]ctrlruntimeclient.IndexerFunc{prowJobIndexName: prowJobIndexer("prowjobs")},
+	}
+	buildClusterClient := fakectrlruntimeclient.NewClientBuilder().WithObjects(
+		newPodMetrics("running", start,
+			map[string]interface{}{"cpu": "500m", "memory": "1Gi"},
+			map[string]interface{}{"cpu": "250m", "memory": "512Mi"}),
+		newPodMetrics("done", start, map[string]interface{}{"cpu": "1", "memory": "1Gi"}),
+	).Build()
+	r := &reconciler{
+		pjClient:     pjClient,
+		buildClients: map[string]buildClient{prowv1.DefaultClusterAlias: {Client: buildClusterClient}},
+		log:          logrus.NewEntry(logrus.New()),
+		config: func() *config.Config {
+			return &config.Config{ProwConfig: config.ProwConfig{PodNamespace: "pods"}}
+		},
+	}
+	// The job that is not pending anymore is pruned.
+	r.resourceUsage.record("done", &podMetrics{Timestamp: metav1.NewTime(start)})
+
+	r.syncResourceUsage(context.Background())
+
+	expected := &prowv1.ResourceUsage{
+		PeakCPU:    resource.MustParse("750m"),
+		PeakMemory: resource.MustParse("1536Mi"),
+		// The first sample accounts for its window of 30s.
+		CPUSeconds: 22,
+		Samples:    1,
+	}
+	if diff := cmp.Diff(expected, r.resourceUsage.get("running"), quantityComparer); diff != "" {
+		t.Errorf("usage mismatch. Want(-), got(+):\n%s", diff)
+	}
+	for _, name := range []string{"scheduling", "done"} {
+		if usage := r.resourceUsage.get(name); usage != nil {
+			t.Errorf("expected no usage for %s, got %+v", name, usage)
+		}
+	}
+}
+
+func TestSyncPendingJobStoresResourceUsage(t *testing.T) {
+	pj := &prowv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "prowjobs"},
+		Spec:       prowv1.ProwJobSpec{Agent: prowv1.KubernetesAgent, Type: prowv1.PeriodicJob},
+		Status:     prowv1.ProwJobStatus{State: prowv1.PendingState, PodName: "job"},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "pods"},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+	pjClient := fakectrlruntimeclient.NewClientBuilder().WithObjects(pj).Build()
+	r := &reconciler{
+		pjClient:     pjClient,
+		buildClients: map[string]buildClient{prowv1.DefaultClusterAlias: {Client: fakectrlruntimeclient.NewClientBuilder().WithObjects(pod).Build()}},
+		log:          logrus.NewEntry(logrus.New()),
+		config:       newFakeConfigAgent(t, 0, nil).Config,
+	}
+	r.resourceUsage.record(pj.Name, &podMetrics{
+		Timestamp:  metav1.Now(),
+		Window:     metav1.Duration{Duration: 10 * time.Second},
+		Containers: []containerMetrics{{Name: "test", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")}}},
+	})
+
+	if _, err := r.syncPendingJob(context.Background(), pj.DeepCopy()); err != nil {
+		t.Fatalf("failed to sync pending job: %v", err)
+	}
+
+	var actual prowv1.ProwJob
+	if err := pjClient.Get(context.Background(), ctrlruntimeclient.ObjectKeyFromObject(pj), &actual); err != nil {
+		t.Fatalf("failed to get prowjob: %v", err)
+	}
+	expected := &prowv1.ResourceUsage{PeakCPU: resource.MustParse("1"), PeakMemory: resource.MustParse("1Gi"), CPUSeconds: 10, Samples: 1}
+	if diff := cmp.Diff(expected, actual.Status.ResourceUsage, quantityComparer); diff != "" {
+		t.Errorf("usage mismatch. Want(-), got(+):\n%s", diff)
+	}
+	if usage := r.resourceUsage.get(pj.Name); usage != nil {
+		t.Errorf("expected the usage to be forgotten once stored, got %+v", usage)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -64,15 +65,19 @@ type HelpAgent struct {
 	log *logrus.Entry
 	pa  pluginAgent
 	oa  *orgAgent
+	// cfg is used to list the jobs of a repo. Optional.
+	cfg prowconfig.Getter
 }
 
-// NewHelpAgent constructs a new HelpAgent.
-func NewHelpAgent(pa pluginAgent, ghc githubClient) *HelpAgent {
+// NewHelpAgent constructs a new HelpAgent. The job config getter is used to
+// include the jobs of a repo in repo specific help and may be nil.
+func NewHelpAgent(pa pluginAgent, ghc githubClient, cfg prowconfig.Getter) *HelpAgent {
 	l := logrus.WithField("client", "plugin-help")
 	return &HelpAgent{
 		log: l,
 		pa:  pa,
 		oa:  newOrgAgent(l, ghc, newRepoDetectionLimit),
+		cfg: cfg,
 	}
 }
 
@@ -102,7 +107,12 @@ func (ha *HelpAgent) generateExternalPluginHelp(config *plugins.Configuration, r
 			externals[ext.Name] = ext
 		}
 	}
+	return ha.fetchExternalPluginHelp(externals, revMap)
+}
 
+// fetchExternalPluginHelp concurrently requests help from the given external
+// plugins, giving up on plugins that do not respond within a second.
+func (ha *HelpAgent) fetchExternalPluginHelp(externals map[string]plugins.ExternalPlugin, revMap map[string][]prowconfig.OrgRepo) (allPlugins []string, pluginHelp map[string]pluginhelp.PluginHelp) {
 	type externalResult struct {
 		name string
 		help *pluginhelp.PluginHelp
@@ -178,6 +188,80 @@ func (ha *HelpAgent) GeneratePluginHelp() *pluginhelp.Help {
 	}
 }
 
+// GenerateRepoHelp compiles and returns the effective help information for a
+// single repo: the plugins enabled for it, their help restricted to the repo
+// and the presubmits that can be triggered on it.
+func (ha *HelpAgent) GenerateRepoHelp(org, repo string) *pluginhelp.RepoHelp {
+	config := ha.pa.Config()
+	orgRepo := prowconfig.OrgRepo{Org: org, Repo: repo}
+	enabledRepos := []prowconfig.OrgRepo{orgRepo}
+	help := &pluginhelp.RepoHelp{
+		Repo:               orgRepo.String(),
+		Plugins:            config.EnabledPluginsFor(org, repo),
+		PluginHelp:         map[string]pluginhelp.PluginHelp{},
+		ExternalPluginHelp: map[string]pluginhelp.PluginHelp{},
+	}
+
+	providers := plugins.HelpProviders()
+	for _, name := range help.Plugins {
+		provider := providers[name]
+		if provider == nil {
+			continue
+		}
+		pluginHelp, err := provider(config, enabledRepos)
+		if err != nil {
+			ha.log.WithError(err).Errorf("Generating help from normal plugin %q.", name)
+			continue
+		}
+		pluginHelp.Events = plugins.EventsForPlugin(name)
+		pluginHelp.Config = configForRepo(pluginHelp.Config, orgRepo)
+		help.PluginHelp[name] = *pluginHelp
+	}
+
+	externals := map[string]plugins.ExternalPlugin{}
+	revMap := map[string][]prowconfig.OrgRepo{}
+	for _, ext := range config.ExternalPluginsFor(org, repo) {
+		externals[ext.Name] = ext
+		revMap[ext.Name] = enabledRepos
+	}
+	var externalHelp map[string]pluginhelp.PluginHelp
+	help.ExternalPlugins, externalHelp = ha.fetchExternalPluginHelp(externals, revMap)
+	sort.Strings(help.ExternalPlugins)
+	for name, pluginHelp := range externalHelp {
+		pluginHelp.Config = configForRepo(pluginHelp.Config, orgRepo)
+		help.ExternalPluginHelp[name] = pluginHelp
+	}
+
+	if ha.cfg != nil {
+		for _, presubmit := range ha.cfg().GetPresubmitsStatic(orgRepo.String()) {
+			help.Jobs = append(help.Jobs, pluginhelp.Job{
+				Name:              presubmit.Name,
+				Context:           presubmit.Context,
+				RerunCommand:      presubmit.RerunCommand,
+				AlwaysRun:         presubmit.AlwaysRun,
+				Optional:          presubmit.Optional,
+				RunIfChanged:      presubmit.RunIfChanged,
+				SkipIfOnlyChanged: presubmit.SkipIfOnlyChanged,
+				Branches:          presubmit.Branches,
+				SkipBranches:      presubmit.SkipBranches,
+			})
+		}
+		sort.Slice(help.Jobs, func(i, j int) bool { return help.Jobs[i].Name < help.Jobs[j].Name })
+	}
+	return help
+}
+
+// configForRepo drops the config descriptions that apply to other repos.
+func configForRepo(config map[string]string, orgRepo prowconfig.OrgRepo) map[string]string {
+	filtered := map[string]string{}
+	for key, value := range config {
+		if key == "" || key == orgRepo.Org || key == orgRepo.String() {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
 func allRepos(config *plugins.Configuration, orgToRepos map[string]sets.Set[string]) []string {
 	all := sets.New[string]()
 	for repo := range config.Plugins {
@@ -391,7 +475,17 @@ func (ha *HelpAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	help := ha.GeneratePluginHelp()
+	var help interface{}
+	if orgRepo := r.URL.Query().Get("repo"); orgRepo != "" {
+		org, repo, ok := strings.Cut(orgRepo, "/")
+		if !ok || org == "" || repo == "" {
+			http.Error(w, fmt.Sprintf("400 Bad Request: repo %q is not of the form org/repo", orgRepo), http.StatusBadRequest)
+			return
+		}
+		help = ha.GenerateRepoHelp(org, repo)
+	} else {
+		help = ha.GeneratePluginHelp()
+	}
 	b, err := json.Marshal(help)
 	if err != nil {
 		serverError("marshaling plugin help", err)
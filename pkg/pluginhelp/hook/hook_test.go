@@ -24,6 +24,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -139,7 +140,7 @@ func TestGeneratePluginHelp(t *testing.T) {
 
 	registerNormalPlugins(t, normalExpectedEvents, normalHelp, normalExpectedReposForPlugin)
 
-	help := NewHelpAgent(fpa, fghc).GeneratePluginHelp()
+	help := NewHelpAgent(fpa, fghc, nil).GeneratePluginHelp()
 	if help == nil {
 		t.Fatal("NewHelpAgent returned nil HelpAgent struct pointer.")
 	}
@@ -207,3 +208,101 @@ func registerNormalPlugins(t *testing.T, pluginsToEvents map[string][]string, pl
 		}
 	}
 }
+
+func TestGenerateRepoHelp(t *testing.T) {
+	pluginHelp := map[string]pluginhelp.PluginHelp{
+		"repohelp-org-plugin": {
+			Description: "org plugin",
+			Config: map[string]string{
+				"":           "overall config",
+				"org1":       "org config",
+				"org1/repo1": "repo1 config",
+				"org1/repo2": "repo2 config",
+				"org2":       "other org config",
+			},
+		},
+		"repohelp-repo-plugin": {Description: "repo plugin"},
+	}
+	registerNormalPlugins(t,
+		map[string][]string{"repohelp-org-plugin": {"issue_comment"}, "repohelp-repo-plugin": {"pull_request"}},
+		pluginHelp,
+		map[string][]string{"repohelp-org-plugin": {"org1/repo1"}, "repohelp-repo-plugin": {"org1/repo1"}},
+	)
+
+	fpa := fakePluginAgent(plugins.Configuration{
+		Plugins: plugins.Plugins{
+			"org1":       {Plugins: []string{"repohelp-org-plugin"}, ExcludedRepos: []string{"repo3"}},
+			"org1/repo1": {Plugins: []string{"repohelp-repo-plugin"}},
+			"org1/repo2": {Plugins: []string{"repohelp-unrelated-plugin"}},
+		},
+	})
+	presubmits := []prowconfig.Presubmit{
+		{
+			JobBase:      prowconfig.JobBase{Name: "unit"},
+			AlwaysRun:    true,
+			Reporter:     prowconfig.Reporter{Context: "unit"},
+			RerunCommand: "/test unit",
+		},
+		{
+			JobBase:             prowconfig.JobBase{Name: "docs"},
+			Optional:            true,
+			Brancher:            prowconfig.Brancher{Branches: []string{"main"}},
+			RegexpChangeMatcher: prowconfig.RegexpChangeMatcher{RunIfChanged: `^docs/`},
+			Reporter:            prowconfig.Reporter{Context: "docs"},
+			RerunCommand:        "/test docs",
+		},
+	}
+	cfg := &prowconfig.Config{}
+	if err := cfg.SetPresubmits(map[string][]prowconfig.Presubmit{"org1/repo1": presubmits}); err != nil {
+		t.Fatalf("failed to set presubmits: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		repo     string
+		expected *pluginhelp.RepoHelp
+	}{
+		{
+			name: "org and repo plugins and jobs",
+			repo: "repo1",
+			expected: &pluginhelp.RepoHelp{
+				Repo:    "org1/repo1",
+				Plugins: []string{"repohelp-org-plugin", "repohelp-repo-plugin"},
+				PluginHelp: map[string]pluginhelp.PluginHelp{
+					"repohelp-org-plugin": {
+						Description: "org plugin",
+						Config: map[string]string{
+							"":           "overall config",
+							"org1":       "org config",
+							"org1/repo1": "repo1 config",
+						},
+						Events: []string{"issue_comment"},
+					},
+					"repohelp-repo-plugin": {Description: "repo plugin", Config: map[string]string{}, Events: []string{"pull_request"}},
+				},
+				ExternalPluginHelp: map[string]pluginhelp.PluginHelp{},
+				Jobs: []pluginhelp.Job{
+					{Name: "docs", Context: "docs", RerunCommand: "/test docs", Optional: true, RunIfChanged: `^docs/`, Branches: []string{"main"}},
+					{Name: "unit", Context: "unit", RerunCommand: "/test unit", AlwaysRun: true},
+				},
+			},
+		},
+		{
+			name: "excluded repo",
+			repo: "repo3",
+			expected: &pluginhelp.RepoHelp{
+				Repo:               "org1/repo3",
+				PluginHelp:         map[string]pluginhelp.PluginHelp{},
+				ExternalPluginHelp: map[string]pluginhelp.PluginHelp{},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewHelpAgent(fpa, fakeGitHubClient{}, func() *prowconfig.Config { return cfg }).GenerateRepoHelp("org1", tc.repo)
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("unexpected repo help (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
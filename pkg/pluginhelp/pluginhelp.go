@@ -69,6 +69,43 @@ type Help struct {
 	ExternalPluginHelp map[string]PluginHelp
 }
 
+// Job is a serializable representation of a presubmit job that can be triggered on a repo.
+type Job struct {
+	// Name is the name of the job.
+	Name string
+	// Context is the status context the job reports to.
+	Context string
+	// RerunCommand is the comment command that triggers the job.
+	RerunCommand string
+	// AlwaysRun is true if the job runs on every PR.
+	AlwaysRun bool
+	// Optional is true if the job is not required for merge.
+	Optional bool
+	// RunIfChanged is the regexp of changed files that trigger the job, if any.
+	RunIfChanged string `json:",omitempty"`
+	// SkipIfOnlyChanged is the regexp of changed files that alone do not trigger the job, if any.
+	SkipIfOnlyChanged string `json:",omitempty"`
+	// Branches are the branches the job runs for. The job runs for all branches if empty.
+	Branches []string `json:",omitempty"`
+	// SkipBranches are the branches the job does not run for.
+	SkipBranches []string `json:",omitempty"`
+}
+
+// RepoHelp is a serializable representation of the effective help information for a single repo.
+// Unlike Help, it only contains the plugins and jobs that are actually enabled for the repo.
+type RepoHelp struct {
+	// Repo is the org/repo string the help is for.
+	Repo string
+	// Plugins are the plugins enabled for the repo, taking org level config and excluded repos into account.
+	Plugins         []string
+	ExternalPlugins []string
+	// PluginHelp maps plugin names to their help info. Config only contains the entries relevant to the repo.
+	PluginHelp         map[string]PluginHelp
+	ExternalPluginHelp map[string]PluginHelp
+	// Jobs are the presubmits configured for the repo.
+	Jobs []Job
+}
+
 // AddCommand registers new help text for a bot command.
 func (pluginHelp *PluginHelp) AddCommand(command Command) {
 	pluginHelp.Commands = append(pluginHelp.Commands, command)
@@ -395,11 +395,13 @@ func handle(log *logrus.Entry, ghc githubClient, repo approvers.Repo, githubConf
 	if err != nil {
 		return fetchErr("issue labels", err)
 	}
-	var hasApprovedLabel bool
+	var hasApprovedLabel, hasAutoApprovedLabel bool
 	for _, label := range issueLabels {
-		if label.Name == labels.Approved {
+		switch label.Name {
+		case labels.Approved:
 			hasApprovedLabel = true
-			break
+		case labels.DependencyAutoApproved:
+			hasAutoApprovedLabel = true
 		}
 	}
 	botUserChecker, err := ghc.BotUserChecker()
@@ -434,7 +436,11 @@ func handle(log *logrus.Entry, ghc githubClient, repo approvers.Repo, githubConf
 		log.WithError(err).Errorf("Failed to find associated issue from PR body: %v", err)
 	}
 	approversHandler.RequireIssue = opts.IssueRequired
-	approversHandler.ManuallyApproved = humanAddedApproved(ghc, log, pr.org, pr.repo, pr.number, hasApprovedLabel)
+	humanApproved := humanAddedApproved(ghc, log, pr.org, pr.repo, pr.number, hasApprovedLabel)
+	// PRs approved by the dependency-approver policy count as manually approved.
+	approversHandler.ManuallyApproved = func() bool {
+		return (hasApprovedLabel && hasAutoApprovedLabel) || humanApproved()
+	}
 
 	// Author implicitly approves their own PR if config allows it
 	if opts.HasSelfApproval() {
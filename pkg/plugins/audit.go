@@ -0,0 +1,467 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	prowv1 "sigs.k8s.io/prow/pkg/client/clientset/versioned/typed/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/version"
+)
+
+// AuditTrigger describes the event a plugin is handling.
+type AuditTrigger struct {
+	EventType string
+	EventGUID string
+	Actor     string
+}
+
+// EnableAudit records the write actions the agent's GitHub and ProwJob
+// clients take in sink, as the same records the GitHub client writes for its
+// requests. It must be called before the comment pruner is initialized for
+// pruned comments to be recorded.
+func (a *Agent) EnableAudit(sink github.AuditSink, plugin string, trigger AuditTrigger) {
+	auditor := &auditor{sink: sink, plugin: plugin, trigger: trigger, log: a.Logger}
+	a.GitHubClient = newAuditingGitHubClient(a.GitHubClient, auditor)
+	if a.ProwJobClient != nil {
+		a.ProwJobClient = &auditingProwJobClient{ProwJobInterface: a.ProwJobClient, auditor: auditor}
+	}
+}
+
+type auditor struct {
+	sink    github.AuditSink
+	plugin  string
+	trigger AuditTrigger
+	log     *logrus.Entry
+}
+
+// record persists a record of an action. Failing to persist it does not fail
+// the action, which has already happened.
+func (a *auditor) record(action, org, repo string, number int, target string, err error) {
+	record := github.AuditRecord{
+		Time:      time.Now(),
+		Component: version.Name,
+		Caller:    a.plugin,
+		EventType: a.trigger.EventType,
+		EventGUID: a.trigger.EventGUID,
+		Actor:     a.trigger.Actor,
+		Action:    action,
+		Org:       org,
+		Repo:      repo,
+		Number:    number,
+		Target:    target,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if err := a.sink.Record(record); err != nil && a.log != nil {
+		a.log.WithError(err).WithField("action", action).Error("Failed to record plugin write in the audit sink.")
+	}
+}
+
+// githubWriteClient is the set of GitHub client methods that write, which
+// are recorded for plugins.
+type githubWriteClient interface {
+	AddLabel(org, repo string, number int, label string) error
+	AddLabelWithContext(ctx context.Context, org, repo string, number int, label string) error
+	AddLabels(org, repo string, number int, labels ...string) error
+	AddLabelsWithContext(ctx context.Context, org, repo string, number int, labels ...string) error
+	RemoveLabel(org, repo string, number int, label string) error
+	RemoveLabelWithContext(ctx context.Context, org, repo string, number int, label string) error
+	CreateComment(org, repo string, number int, comment string) error
+	CreateCommentWithContext(ctx context.Context, org, repo string, number int, comment string) error
+	EditComment(org, repo string, id int, comment string) error
+	EditCommentWithContext(ctx context.Context, org, repo string, id int, comment string) error
+	DeleteComment(org, repo string, id int) error
+	DeleteCommentWithContext(ctx context.Context, org, repo string, id int) error
+	DeleteStaleComments(org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error
+	DeleteStaleCommentsWithContext(ctx context.Context, org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error
+	CreateCommentReaction(org, repo string, id int, reaction string) error
+	CreateIssueReaction(org, repo string, id int, reaction string) error
+	CreateIssue(org, repo, title, body string, milestone int, labels, assignees []string) (int, error)
+	EditIssue(org, repo string, number int, issue *github.Issue) (*github.Issue, error)
+	AssignIssue(org, repo string, number int, logins []string) error
+	UnassignIssue(org, repo string, number int, logins []string) error
+	CloseIssue(org, repo string, number int) error
+	CloseIssueAsNotPlanned(org, repo string, number int) error
+	ReopenIssue(org, repo string, number int) error
+	SetMilestone(org, repo string, issueNum, milestoneNum int) error
+	ClearMilestone(org, repo string, num int) error
+	CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (int, error)
+	EditPullRequest(org, repo string, number int, pr *github.PullRequest) (*github.PullRequest, error)
+	UpdatePullRequest(org, repo string, number int, title, body *string, open *bool, branch *string, canModify *bool) error
+	UpdatePullRequestBranch(org, repo string, number int, expectedHeadSha *string) error
+	ClosePullRequest(org, repo string, number int) error
+	ReopenPullRequest(org, repo string, number int) error
+	CreateReview(org, repo string, number int, r github.DraftReview) error
+	CreatePullRequestReviewComment(org, repo string, number int, rc github.ReviewComment) error
+	RequestReview(org, repo string, number int, logins []string) error
+	UnrequestReview(org, repo string, number int, logins []string) error
+	Merge(org, repo string, pr int, details github.MergeDetails) error
+	CreateStatus(org, repo, SHA string, s github.Status) error
+	CreateStatusWithContext(ctx context.Context, org, repo, SHA string, s github.Status) error
+	DeleteRef(org, repo, ref string) error
+	CreateFork(owner, repo string) (string, error)
+	EnsureFork(forkingUser, org, repo string) (string, error)
+	CreateProjectCard(org string, columnID int, projectCard github.ProjectCard) (*github.ProjectCard, error)
+	MoveProjectCard(org string, projectCardID int, newColumnID int) error
+	DeleteProjectCard(org string, projectCardID int) error
+	MutateWithGitHubAppsSupport(ctx context.Context, m interface{}, input githubv4.Input, vars map[string]interface{}, org string) error
+}
+
+// auditingGitHubClient records the write actions plugins take through the
+// GitHub client. Reads are passed through unrecorded.
+type auditingGitHubClient struct {
+	*auditedGitHubWrites
+	// The wrapped client is embedded one level deeper, so that the writes of
+	// auditedGitHubWrites take precedence over its own.
+	wrappedGitHubClient
+}
+
+type wrappedGitHubClient struct {
+	PluginGitHubClient
+}
+
+func newAuditingGitHubClient(client PluginGitHubClient, auditor *auditor) *auditingGitHubClient {
+	return &auditingGitHubClient{
+		auditedGitHubWrites: &auditedGitHubWrites{client: client, auditor: auditor},
+		wrappedGitHubClient: wrappedGitHubClient{PluginGitHubClient: client},
+	}
+}
+
+// auditedGitHubWrites implements every write of the client, so that none
+// can bypass the audit.
+type auditedGitHubWrites struct {
+	client  PluginGitHubClient
+	auditor *auditor
+}
+
+var _ githubWriteClient = &auditedGitHubWrites{}
+var _ PluginGitHubClient = &auditingGitHubClient{}
+
+func (c *auditedGitHubWrites) AddLabel(org, repo string, number int, label string) error {
+	err := c.client.AddLabel(org, repo, number, label)
+	c.auditor.record("AddLabel", org, repo, number, label, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) AddLabelWithContext(ctx context.Context, org, repo string, number int, label string) error {
+	err := c.client.AddLabelWithContext(ctx, org, repo, number, label)
+	c.auditor.record("AddLabel", org, repo, number, label, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) AddLabels(org, repo string, number int, labels ...string) error {
+	err := c.client.AddLabels(org, repo, number, labels...)
+	c.auditor.record("AddLabels", org, repo, number, strings.Join(labels, ","), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) AddLabelsWithContext(ctx context.Context, org, repo string, number int, labels ...string) error {
+	err := c.client.AddLabelsWithContext(ctx, org, repo, number, labels...)
+	c.auditor.record("AddLabels", org, repo, number, strings.Join(labels, ","), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) RemoveLabel(org, repo string, number int, label string) error {
+	err := c.client.RemoveLabel(org, repo, number, label)
+	c.auditor.record("RemoveLabel", org, repo, number, label, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) RemoveLabelWithContext(ctx context.Context, org, repo string, number int, label string) error {
+	err := c.client.RemoveLabelWithContext(ctx, org, repo, number, label)
+	c.auditor.record("RemoveLabel", org, repo, number, label, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateComment(org, repo string, number int, comment string) error {
+	err := c.client.CreateComment(org, repo, number, comment)
+	c.auditor.record("CreateComment", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateCommentWithContext(ctx context.Context, org, repo string, number int, comment string) error {
+	err := c.client.CreateCommentWithContext(ctx, org, repo, number, comment)
+	c.auditor.record("CreateComment", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) EditComment(org, repo string, id int, comment string) error {
+	err := c.client.EditComment(org, repo, id, comment)
+	c.auditor.record("EditComment", org, repo, 0, strconv.Itoa(id), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) EditCommentWithContext(ctx context.Context, org, repo string, id int, comment string) error {
+	err := c.client.EditCommentWithContext(ctx, org, repo, id, comment)
+	c.auditor.record("EditComment", org, repo, 0, strconv.Itoa(id), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) DeleteComment(org, repo string, id int) error {
+	err := c.client.DeleteComment(org, repo, id)
+	c.auditor.record("DeleteComment", org, repo, 0, strconv.Itoa(id), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) DeleteCommentWithContext(ctx context.Context, org, repo string, id int) error {
+	err := c.client.DeleteCommentWithContext(ctx, org, repo, id)
+	c.auditor.record("DeleteComment", org, repo, 0, strconv.Itoa(id), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) DeleteStaleComments(org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error {
+	err := c.client.DeleteStaleComments(org, repo, number, comments, isStale)
+	c.auditor.record("DeleteStaleComments", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) DeleteStaleCommentsWithContext(ctx context.Context, org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error {
+	err := c.client.DeleteStaleCommentsWithContext(ctx, org, repo, number, comments, isStale)
+	c.auditor.record("DeleteStaleComments", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateCommentReaction(org, repo string, id int, reaction string) error {
+	err := c.client.CreateCommentReaction(org, repo, id, reaction)
+	c.auditor.record("CreateCommentReaction", org, repo, 0, strconv.Itoa(id)+":"+reaction, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateIssueReaction(org, repo string, id int, reaction string) error {
+	err := c.client.CreateIssueReaction(org, repo, id, reaction)
+	c.auditor.record("CreateIssueReaction", org, repo, id, reaction, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateIssue(org, repo, title, body string, milestone int, labels, assignees []string) (int, error) {
+	number, err := c.client.CreateIssue(org, repo, title, body, milestone, labels, assignees)
+	c.auditor.record("CreateIssue", org, repo, number, title, err)
+	return number, err
+}
+
+func (c *auditedGitHubWrites) EditIssue(org, repo string, number int, issue *github.Issue) (*github.Issue, error) {
+	edited, err := c.client.EditIssue(org, repo, number, issue)
+	c.auditor.record("EditIssue", org, repo, number, "", err)
+	return edited, err
+}
+
+func (c *auditedGitHubWrites) AssignIssue(org, repo string, number int, logins []string) error {
+	err := c.client.AssignIssue(org, repo, number, logins)
+	c.auditor.record("AssignIssue", org, repo, number, strings.Join(logins, ","), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) UnassignIssue(org, repo string, number int, logins []string) error {
+	err := c.client.UnassignIssue(org, repo, number, logins)
+	c.auditor.record("UnassignIssue", org, repo, number, strings.Join(logins, ","), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CloseIssue(org, repo string, number int) error {
+	err := c.client.CloseIssue(org, repo, number)
+	c.auditor.record("CloseIssue", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CloseIssueAsNotPlanned(org, repo string, number int) error {
+	err := c.client.CloseIssueAsNotPlanned(org, repo, number)
+	c.auditor.record("CloseIssueAsNotPlanned", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) ReopenIssue(org, repo string, number int) error {
+	err := c.client.ReopenIssue(org, repo, number)
+	c.auditor.record("ReopenIssue", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) SetMilestone(org, repo string, issueNum, milestoneNum int) error {
+	err := c.client.SetMilestone(org, repo, issueNum, milestoneNum)
+	c.auditor.record("SetMilestone", org, repo, issueNum, strconv.Itoa(milestoneNum), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) ClearMilestone(org, repo string, num int) error {
+	err := c.client.ClearMilestone(org, repo, num)
+	c.auditor.record("ClearMilestone", org, repo, num, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (int, error) {
+	number, err := c.client.CreatePullRequest(org, repo, title, body, head, base, canModify)
+	c.auditor.record("CreatePullRequest", org, repo, number, head+"->"+base, err)
+	return number, err
+}
+
+func (c *auditedGitHubWrites) EditPullRequest(org, repo string, number int, pr *github.PullRequest) (*github.PullRequest, error) {
+	edited, err := c.client.EditPullRequest(org, repo, number, pr)
+	c.auditor.record("EditPullRequest", org, repo, number, "", err)
+	return edited, err
+}
+
+func (c *auditedGitHubWrites) UpdatePullRequest(org, repo string, number int, title, body *string, open *bool, branch *string, canModify *bool) error {
+	err := c.client.UpdatePullRequest(org, repo, number, title, body, open, branch, canModify)
+	c.auditor.record("UpdatePullRequest", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) UpdatePullRequestBranch(org, repo string, number int, expectedHeadSha *string) error {
+	err := c.client.UpdatePullRequestBranch(org, repo, number, expectedHeadSha)
+	var target string
+	if expectedHeadSha != nil {
+		target = *expectedHeadSha
+	}
+	c.auditor.record("UpdatePullRequestBranch", org, repo, number, target, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) ClosePullRequest(org, repo string, number int) error {
+	err := c.client.ClosePullRequest(org, repo, number)
+	c.auditor.record("ClosePullRequest", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) ReopenPullRequest(org, repo string, number int) error {
+	err := c.client.ReopenPullRequest(org, repo, number)
+	c.auditor.record("ReopenPullRequest", org, repo, number, "", err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateReview(org, repo string, number int, r github.DraftReview) error {
+	err := c.client.CreateReview(org, repo, number, r)
+	c.auditor.record("CreateReview", org, repo, number, string(r.Action), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreatePullRequestReviewComment(org, repo string, number int, rc github.ReviewComment) error {
+	err := c.client.CreatePullRequestReviewComment(org, repo, number, rc)
+	c.auditor.record("CreatePullRequestReviewComment", org, repo, number, rc.Path, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) RequestReview(org, repo string, number int, logins []string) error {
+	err := c.client.RequestReview(org, repo, number, logins)
+	c.auditor.record("RequestReview", org, repo, number, strings.Join(logins, ","), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) UnrequestReview(org, repo string, number int, logins []string) error {
+	err := c.client.UnrequestReview(org, repo, number, logins)
+	c.auditor.record("UnrequestReview", org, repo, number, strings.Join(logins, ","), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) Merge(org, repo string, pr int, details github.MergeDetails) error {
+	err := c.client.Merge(org, repo, pr, details)
+	c.auditor.record("Merge", org, repo, pr, details.SHA, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateStatus(org, repo, SHA string, s github.Status) error {
+	err := c.client.CreateStatus(org, repo, SHA, s)
+	c.auditor.record("CreateStatus", org, repo, 0, s.Context+"@"+SHA+"="+s.State, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateStatusWithContext(ctx context.Context, org, repo, SHA string, s github.Status) error {
+	err := c.client.CreateStatusWithContext(ctx, org, repo, SHA, s)
+	c.auditor.record("CreateStatus", org, repo, 0, s.Context+"@"+SHA+"="+s.State, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) DeleteRef(org, repo, ref string) error {
+	err := c.client.DeleteRef(org, repo, ref)
+	c.auditor.record("DeleteRef", org, repo, 0, ref, err)
+	return err
+}
+
+func (c *auditedGitHubWrites) CreateFork(owner, repo string) (string, error) {
+	fork, err := c.client.CreateFork(owner, repo)
+	c.auditor.record("CreateFork", owner, repo, 0, fork, err)
+	return fork, err
+}
+
+func (c *auditedGitHubWrites) EnsureFork(forkingUser, org, repo string) (string, error) {
+	fork, err := c.client.EnsureFork(forkingUser, org, repo)
+	c.auditor.record("EnsureFork", org, repo, 0, forkingUser+"/"+fork, err)
+	return fork, err
+}
+
+func (c *auditedGitHubWrites) CreateProjectCard(org string, columnID int, projectCard github.ProjectCard) (*github.ProjectCard, error) {
+	card, err := c.client.CreateProjectCard(org, columnID, projectCard)
+	c.auditor.record("CreateProjectCard", org, "", 0, strconv.Itoa(columnID), err)
+	return card, err
+}
+
+func (c *auditedGitHubWrites) MoveProjectCard(org string, projectCardID int, newColumnID int) error {
+	err := c.client.MoveProjectCard(org, projectCardID, newColumnID)
+	c.auditor.record("MoveProjectCard", org, "", 0, fmt.Sprintf("%d->%d", projectCardID, newColumnID), err)
+	return err
+}
+
+func (c *auditedGitHubWrites) DeleteProjectCard(org string, projectCardID int) error {
+	err := c.client.DeleteProjectCard(org, projectCardID)
+	c.auditor.record("DeleteProjectCard", org, "", 0, strconv.Itoa(projectCardID), err)
+	return err
+}
+
+// MutateWithGitHubAppsSupport records the type of the mutation as its target.
+func (c *auditedGitHubWrites) MutateWithGitHubAppsSupport(ctx context.Context, m interface{}, input githubv4.Input, vars map[string]interface{}, org string) error {
+	err := c.client.MutateWithGitHubAppsSupport(ctx, m, input, vars, org)
+	var mutation string
+	if t := reflect.TypeOf(m); t != nil {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		mutation = t.Name()
+	}
+	c.auditor.record("Mutate", org, "", 0, mutation, err)
+	return err
+}
+
+// auditingProwJobClient records the ProwJobs plugins create.
+type auditingProwJobClient struct {
+	prowv1.ProwJobInterface
+	auditor *auditor
+}
+
+func (c *auditingProwJobClient) Create(ctx context.Context, pj *prowapi.ProwJob, opts metav1.CreateOptions) (*prowapi.ProwJob, error) {
+	created, err := c.ProwJobInterface.Create(ctx, pj, opts)
+	var org, repo string
+	var number int
+	if refs := pj.Spec.Refs; refs != nil {
+		org, repo = refs.Org, refs.Repo
+		if len(refs.Pulls) > 0 {
+			number = refs.Pulls[0].Number
+		}
+	}
+	c.auditor.record("CreateProwJob", org, repo, number, pj.Spec.Job, err)
+	return created, err
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/version"
+)
+
+type fakeAuditSink struct {
+	records []github.AuditRecord
+}
+
+func (s *fakeAuditSink) Record(r github.AuditRecord) error {
+	s.records = append(s.records, r)
+	return nil
+}
+
+// fakeWriteClient implements the GitHub writes exercised below; all other
+// methods panic through the nil embedded interface.
+type fakeWriteClient struct {
+	PluginGitHubClient
+}
+
+func (fakeWriteClient) AddLabel(org, repo string, number int, label string) error {
+	return nil
+}
+
+func (fakeWriteClient) CreateComment(org, repo string, number int, comment string) error {
+	return errors.New("injected failure")
+}
+
+func (fakeWriteClient) CloseIssueAsNotPlanned(org, repo string, number int) error {
+	return nil
+}
+
+func (fakeWriteClient) MutateWithGitHubAppsSupport(ctx context.Context, m interface{}, input githubv4.Input, vars map[string]interface{}, org string) error {
+	return nil
+}
+
+func TestEnableAudit(t *testing.T) {
+	sink := &fakeAuditSink{}
+	agent := Agent{
+		GitHubClient:  fakeWriteClient{},
+		ProwJobClient: fake.NewSimpleClientset().ProwV1().ProwJobs("prowjobs"),
+		Logger:        logrus.WithField("plugin", "trigger"),
+	}
+	agent.EnableAudit(sink, "trigger", AuditTrigger{EventType: "issue_comment", EventGUID: "guid", Actor: "alice"})
+
+	if err := agent.GitHubClient.AddLabel("org", "repo", 1, "lgtm"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := agent.GitHubClient.CreateComment("org", "repo", 1, "hello"); err == nil {
+		t.Fatal("expected injected error to be returned")
+	}
+	if err := agent.GitHubClient.CloseIssueAsNotPlanned("org", "repo", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	type addPullRequestReview struct{}
+	if err := agent.GitHubClient.MutateWithGitHubAppsSupport(context.Background(), &addPullRequestReview{}, nil, nil, "org"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pj := &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "pj"},
+		Spec: prowapi.ProwJobSpec{
+			Job:  "pull-unit",
+			Refs: &prowapi.Refs{Org: "org", Repo: "repo", Pulls: []prowapi.Pull{{Number: 1}}},
+		},
+	}
+	if _, err := agent.ProwJobClient.Create(context.Background(), pj, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []github.AuditRecord{
+		{Component: version.Name, Caller: "trigger", EventType: "issue_comment", EventGUID: "guid", Actor: "alice", Action: "AddLabel", Org: "org", Repo: "repo", Number: 1, Target: "lgtm"},
+		{Component: version.Name, Caller: "trigger", EventType: "issue_comment", EventGUID: "guid", Actor: "alice", Action: "CreateComment", Org: "org", Repo: "repo", Number: 1, Error: "injected failure"},
+		{Component: version.Name, Caller: "trigger", EventType: "issue_comment", EventGUID: "guid", Actor: "alice", Action: "CloseIssueAsNotPlanned", Org: "org", Repo: "repo", Number: 2},
+		{Component: version.Name, Caller: "trigger", EventType: "issue_comment", EventGUID: "guid", Actor: "alice", Action: "Mutate", Org: "org", Target: "addPullRequestReview"},
+		{Component: version.Name, Caller: "trigger", EventType: "issue_comment", EventGUID: "guid", Actor: "alice", Action: "CreateProwJob", Org: "org", Repo: "repo", Number: 1, Target: "pull-unit"},
+	}
+	if diff := cmp.Diff(expected, sink.records, cmpopts.IgnoreFields(github.AuditRecord{}, "Time")); diff != "" {
+		t.Errorf("audit records differ from expected (-want +got):\n%s", diff)
+	}
+}
@@ -55,7 +55,7 @@ func helpProvider(config *plugins.Configuration, enabledRepos []prowconfig.OrgRe
 		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", pluginName)
 	}
 	return &pluginhelp.PluginHelp{
-		Description: "The branchcleaner plugin automatically deletes source branches for merged PRs between two branches on the same repository. This is helpful to keep repos that don't allow forking clean.",
+		Description: "The branchcleaner plugin automatically deletes source branches for merged PRs between two branches on the same repository. This is helpful to keep repos that don't allow forking clean. The default branch, protected branches, branches that received new commits after the merge and branches that are the base of other open PRs are never deleted.",
 		Config: func(repos []prowconfig.OrgRepo) map[string]string {
 			configMap := make(map[string]string)
 			for _, repo := range repos {
@@ -76,6 +76,9 @@ func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
 
 type githubClient interface {
 	DeleteRef(owner, repo, ref string) error
+	GetRef(org, repo, ref string) (string, error)
+	GetBranches(org, repo string, onlyProtected bool) ([]github.Branch, error)
+	FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error)
 }
 
 func handle(gc githubClient, log *logrus.Entry, config plugins.BranchCleaner, pre github.PullRequestEvent) error {
@@ -91,15 +94,66 @@ func handle(gc githubClient, log *logrus.Entry, config plugins.BranchCleaner, pr
 		return nil
 	}
 
+	org, repo, branch := pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Head.Ref
+	log = log.WithField("branch", branch)
+
 	// skip preserved branches
-	if config.IsPreservedBranch(pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Head.Ref) {
+	if config.IsPreservedBranch(org, repo, branch) {
 		return nil
 	}
 
-	if err := gc.DeleteRef(pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, fmt.Sprintf("heads/%s", pr.Head.Ref)); err != nil {
+	if safe, err := safeToDelete(gc, log, pr); err != nil || !safe {
+		return err
+	}
+
+	if err := gc.DeleteRef(org, repo, fmt.Sprintf("heads/%s", branch)); err != nil {
 		return fmt.Errorf("failed to delete branch %s on repo %s/%s after Pull Request #%d got merged: %w",
 			pr.Head.Ref, pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pre.PullRequest.Number, err)
 	}
 
 	return nil
 }
+
+// safeToDelete checks that deleting the head branch of a merged PR does not
+// lose work or disrupt other PRs.
+func safeToDelete(gc githubClient, log *logrus.Entry, pr github.PullRequest) (bool, error) {
+	org, repo, branch := pr.Base.Repo.Owner.Login, pr.Base.Repo.Name, pr.Head.Ref
+
+	if branch == pr.Base.Repo.DefaultBranch {
+		log.Info("Not deleting the default branch of the repository.")
+		return false, nil
+	}
+
+	protected, err := gc.GetBranches(org, repo, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to list protected branches of %s/%s: %w", org, repo, err)
+	}
+	for _, b := range protected {
+		if b.Name == branch {
+			log.Info("Not deleting protected branch.")
+			return false, nil
+		}
+	}
+
+	// Commits pushed after the merge would be lost.
+	sha, err := gc.GetRef(org, repo, fmt.Sprintf("heads/%s", branch))
+	if err != nil {
+		return false, fmt.Errorf("failed to get ref of branch %s on repo %s/%s: %w", branch, org, repo, err)
+	}
+	if sha != pr.Head.SHA {
+		log.Infof("Not deleting branch that moved from %s to %s after the merge.", pr.Head.SHA, sha)
+		return false, nil
+	}
+
+	// GitHub closes open PRs whose base branch is deleted.
+	query := fmt.Sprintf("is:pr is:open repo:%s/%s base:%q", org, repo, branch)
+	dependents, err := gc.FindIssuesWithOrg(org, query, "", false)
+	if err != nil {
+		return false, fmt.Errorf("failed to search for PRs against branch %s on repo %s/%s: %w", branch, org, repo, err)
+	}
+	if len(dependents) > 0 {
+		log.Infof("Not deleting branch that is the base of %d open PR(s).", len(dependents))
+		return false, nil
+	}
+	return true, nil
+}
@@ -27,6 +27,28 @@ import (
 	"sigs.k8s.io/prow/pkg/plugins"
 )
 
+type fakeClient struct {
+	*fakegithub.FakeClient
+	protectedBranches []string
+	openDependents    int
+}
+
+func (f *fakeClient) GetBranches(org, repo string, onlyProtected bool) ([]github.Branch, error) {
+	var branches []github.Branch
+	for _, b := range f.protectedBranches {
+		branches = append(branches, github.Branch{Name: b, Protected: true})
+	}
+	return branches, nil
+}
+
+func (f *fakeClient) FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error) {
+	var issues []github.Issue
+	for i := 0; i < f.openDependents; i++ {
+		issues = append(issues, github.Issue{Number: 100 + i})
+	}
+	return issues, nil
+}
+
 func TestBranchCleaner(t *testing.T) {
 	baseRepoOrg := "my-org"
 	baseRepoRepo := "repo"
@@ -39,6 +61,9 @@ func TestBranchCleaner(t *testing.T) {
 		headRepoFullName     string
 		srcBranchName        string
 		preservedBranches    map[string][]string
+		protectedBranches    []string
+		openDependents       int
+		headSHA              string
 		branchDeleteExpected bool
 	}{
 		{
@@ -136,6 +161,50 @@ func TestBranchCleaner(t *testing.T) {
 			headRepoFullName:     "my-org/repo",
 			branchDeleteExpected: true,
 		},
+		{
+			name:                 "PR from default branch of same repo",
+			prAction:             github.PullRequestActionClosed,
+			srcBranchName:        "master",
+			merged:               true,
+			headRepoFullName:     "my-org/repo",
+			branchDeleteExpected: false,
+		},
+		{
+			name:                 "PR from protected branch",
+			prAction:             github.PullRequestActionClosed,
+			srcBranchName:        "release-1.0",
+			protectedBranches:    []string{"master", "release-1.0"},
+			merged:               true,
+			headRepoFullName:     "my-org/repo",
+			branchDeleteExpected: false,
+		},
+		{
+			name:                 "PR from branch with other protected branches",
+			prAction:             github.PullRequestActionClosed,
+			srcBranchName:        "my-chore2",
+			protectedBranches:    []string{"master", "release-1.0"},
+			merged:               true,
+			headRepoFullName:     "my-org/repo",
+			branchDeleteExpected: true,
+		},
+		{
+			name:                 "PR from branch that moved after merge",
+			prAction:             github.PullRequestActionClosed,
+			srcBranchName:        "my-chore3",
+			headSHA:              "older-sha",
+			merged:               true,
+			headRepoFullName:     "my-org/repo",
+			branchDeleteExpected: false,
+		},
+		{
+			name:                 "PR from branch that is the base of open PRs",
+			prAction:             github.PullRequestActionClosed,
+			srcBranchName:        "my-chore4",
+			openDependents:       2,
+			merged:               true,
+			headRepoFullName:     "my-org/repo",
+			branchDeleteExpected: false,
+		},
 		{
 			name:                 "PR from same repo delete head ref",
 			prAction:             github.PullRequestActionClosed,
@@ -153,6 +222,10 @@ func TestBranchCleaner(t *testing.T) {
 
 		t.Run(tc.name, func(t *testing.T) {
 			log := logrus.WithField("plugin", pluginName)
+			headSHA := tc.headSHA
+			if headSHA == "" {
+				headSHA = fakegithub.TestRef
+			}
 			event := github.PullRequestEvent{
 				Action: tc.prAction,
 				Number: prNumber,
@@ -168,6 +241,7 @@ func TestBranchCleaner(t *testing.T) {
 					},
 					Head: github.PullRequestBranch{
 						Ref: tc.srcBranchName,
+						SHA: headSHA,
 						Repo: github.Repo{
 							FullName: tc.headRepoFullName,
 						},
@@ -178,7 +252,11 @@ func TestBranchCleaner(t *testing.T) {
 				event.PullRequest.MergeSHA = &mergeSHA
 			}
 
-			fgc := fakegithub.NewFakeClient()
+			fgc := &fakeClient{
+				FakeClient:        fakegithub.NewFakeClient(),
+				protectedBranches: tc.protectedBranches,
+				openDependents:    tc.openDependents,
+			}
 			fgc.PullRequests = map[int]*github.PullRequest{
 				prNumber: {
 					Number: prNumber,
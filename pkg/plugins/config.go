@@ -20,11 +20,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"path"
 	"reflect"
 	"regexp"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"sigs.k8s.io/yaml"
@@ -32,11 +34,13 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/mattn/go-zglob"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"sigs.k8s.io/prow/pkg/bugzilla"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/labels"
 	"sigs.k8s.io/prow/pkg/logrusutil"
@@ -45,6 +49,7 @@ import (
 
 const (
 	defaultBlunderbussReviewerCount = 2
+	defaultRegexJobLimit            = 20
 )
 
 // Configuration is the top-level serialization target for plugin Configuration.
@@ -65,6 +70,16 @@ type Configuration struct {
 	// Owners contains configuration related to handling OWNERS files.
 	Owners Owners `json:"owners,omitempty"`
 
+	// EventFilter restricts the webhook events hook processes.
+	EventFilter EventFilter `json:"event_filter,omitempty"`
+
+	// EventForwarders forward validated webhook events to external
+	// systems that do not need a webhook of their own.
+	EventForwarders []EventForwarder `json:"event_forwarders,omitempty"`
+
+	// CommandRateLimit throttles the handling of slash commands per user.
+	CommandRateLimit CommandRateLimit `json:"command_rate_limit,omitempty"`
+
 	// Built-in plugins specific configuration.
 	Approve              []Approve                    `json:"approve,omitempty"`
 	Blockades            []Blockade                   `json:"blockades,omitempty"`
@@ -76,27 +91,175 @@ type Configuration struct {
 	CherryPickUnapproved CherryPickUnapproved         `json:"cherry_pick_unapproved,omitempty"`
 	ConfigUpdater        ConfigUpdater                `json:"config_updater,omitempty"`
 	Dco                  map[string]*Dco              `json:"dco,omitempty"`
+	DependencyApprover   []DependencyApprover         `json:"dependency_approver,omitempty"`
 	Golint               Golint                       `json:"golint,omitempty"`
 	Goose                Goose                        `json:"goose,omitempty"`
 	Heart                Heart                        `json:"heart,omitempty"`
 	Label                Label                        `json:"label,omitempty"`
 	Lgtm                 []Lgtm                       `json:"lgtm,omitempty"`
+	Lifecycle            []Lifecycle                  `json:"lifecycle,omitempty"`
 	Jira                 *Jira                        `json:"jira,omitempty"`
 	MilestoneApplier     map[string]BranchToMilestone `json:"milestone_applier,omitempty"`
+	MilestoneRules       []MilestoneApplierRule       `json:"milestone_applier_rules,omitempty"`
+	PathLabel            []PathLabel                  `json:"path_label,omitempty"`
 	RepoMilestone        map[string]Milestone         `json:"repo_milestone,omitempty"`
 	Project              ProjectConfig                `json:"project_config,omitempty"`
 	ProjectManager       ProjectManager               `json:"project_manager,omitempty"`
+	ProjectsV2           []ProjectsV2                 `json:"projects_v2,omitempty"`
+	ReleaseNote          []ReleaseNote                `json:"release_note,omitempty"`
 	RequireMatchingLabel []RequireMatchingLabel       `json:"require_matching_label,omitempty"`
+	RequiredLabels       []RequiredLabels             `json:"required_labels,omitempty"`
 	Retitle              Retitle                      `json:"retitle,omitempty"`
 	Slack                Slack                        `json:"slack,omitempty"`
+	Shadow               []Shadow                     `json:"shadow,omitempty"`
 	SigMention           SigMention                   `json:"sigmention,omitempty"`
 	Size                 Size                         `json:"size,omitempty"`
+	TitleConvention      []TitleConvention            `json:"title_convention,omitempty"`
+	TriageRotation       []TriageRotation             `json:"triage_rotation,omitempty"`
 	Triggers             []Trigger                    `json:"triggers,omitempty"`
 	Welcome              []Welcome                    `json:"welcome,omitempty"`
 	Override             Override                     `json:"override,omitempty"`
 	Help                 Help                         `json:"help,omitempty"`
 }
 
+// EventFilter restricts the webhook events hook processes, so that a webhook
+// receiving the events of a whole GitHub instance does not spend cycles on
+// irrelevant orgs, repos or event types. Dropped events are neither handled
+// by plugins nor forwarded to external plugins.
+type EventFilter struct {
+	// AllowedRepos are orgs or org/repos whose events are processed. If set,
+	// events of all other orgs and repos are dropped.
+	AllowedRepos []string `json:"allowed_repos,omitempty"`
+	// DeniedRepos are orgs or org/repos whose events are dropped, even if
+	// they are allowed by AllowedRepos.
+	DeniedRepos []string `json:"denied_repos,omitempty"`
+	// AllowedEventTypes are the event types that are processed, e.g.
+	// "pull_request". If set, events of all other types are dropped.
+	AllowedEventTypes []string `json:"allowed_event_types,omitempty"`
+	// DeniedEventTypes are event types that are dropped.
+	DeniedEventTypes []string `json:"denied_event_types,omitempty"`
+}
+
+// Drops returns whether an event of a type for an org and repo is dropped and
+// why. The repo is empty for org-level events.
+func (f *EventFilter) Drops(eventType, org, repo string) (bool, string) {
+	if len(f.AllowedEventTypes) > 0 && !sets.New[string](f.AllowedEventTypes...).Has(eventType) {
+		return true, "event_type_not_allowed"
+	}
+	if sets.New[string](f.DeniedEventTypes...).Has(eventType) {
+		return true, "event_type_denied"
+	}
+	matches := func(repos []string) bool {
+		for _, r := range repos {
+			if r == org || (repo != "" && r == org+"/"+repo) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(f.AllowedRepos) > 0 && !matches(f.AllowedRepos) {
+		return true, "repo_not_allowed"
+	}
+	if matches(f.DeniedRepos) {
+		return true, "repo_denied"
+	}
+	return false, ""
+}
+
+// CommandRateLimit limits how many comments with slash commands a user may
+// leave in a repo, so that a malicious or runaway commenter can neither
+// exhaust the API quota nor start an unbounded number of jobs. Comments over
+// the limit are not handled by plugins.
+type CommandRateLimit struct {
+	// MaxCommands is the maximum number of comments with commands a user may
+	// leave in a repo within Window. Zero disables the rate limit.
+	MaxCommands int `json:"max_commands,omitempty"`
+	// Window is the period MaxCommands applies to. Defaults to 1m.
+	Window         string        `json:"window,omitempty"`
+	WindowDuration time.Duration `json:"-"`
+	// IgnoreFor is how long all commands of a user exceeding the limit are
+	// ignored in the repo. If unset, only the commands over the limit are
+	// ignored.
+	IgnoreFor         string        `json:"ignore_for,omitempty"`
+	IgnoreForDuration time.Duration `json:"-"`
+	// ExemptUsers are never rate limited, e.g. bot accounts that drive
+	// automation through commands.
+	ExemptUsers []string `json:"exempt_users,omitempty"`
+}
+
+func (l *CommandRateLimit) compileDurations() error {
+	if l.MaxCommands < 0 {
+		return fmt.Errorf("max_commands must not be negative, got %d", l.MaxCommands)
+	}
+	if l.MaxCommands == 0 {
+		return nil
+	}
+	l.WindowDuration = time.Minute
+	if l.Window != "" {
+		dur, err := time.ParseDuration(l.Window)
+		if err != nil {
+			return fmt.Errorf("failed to parse window duration: %q, error: %w", l.Window, err)
+		}
+		l.WindowDuration = dur
+	}
+	if l.IgnoreFor != "" {
+		dur, err := time.ParseDuration(l.IgnoreFor)
+		if err != nil {
+			return fmt.Errorf("failed to parse ignore_for duration: %q, error: %w", l.IgnoreFor, err)
+		}
+		l.IgnoreForDuration = dur
+	}
+	if l.WindowDuration <= 0 || l.IgnoreForDuration < 0 {
+		return errors.New("window must be positive and ignore_for must not be negative")
+	}
+	return nil
+}
+
+// EventForwarder forwards the validated webhook events of some orgs and
+// repos to an HTTP endpoint or a Pub/Sub topic. Unlike external plugins,
+// forwarders only consume events and get no plugin help or configuration.
+type EventForwarder struct {
+	// Name identifies the forwarder in logs.
+	Name string `json:"name"`
+	// Repos are orgs or org/repos whose events are forwarded. If empty,
+	// the events of all orgs and repos are forwarded.
+	Repos []string `json:"repos,omitempty"`
+	// Events are the event types that are forwarded, e.g. "pull_request".
+	// If empty, all event types are forwarded.
+	Events []string `json:"events,omitempty"`
+	// Endpoint is an HTTP(S) URL the raw webhook is posted to with its
+	// original headers.
+	Endpoint string `json:"endpoint,omitempty"`
+	// PubSub is the Pub/Sub topic the webhook payload is published to.
+	PubSub *EventForwarderPubSub `json:"pubsub,omitempty"`
+}
+
+// EventForwarderPubSub is a Pub/Sub topic events are published to. Messages
+// carry the payload as data and the event type, GUID and org/repo as the
+// "event_type", "event_guid" and "org_repo" attributes.
+type EventForwarderPubSub struct {
+	Project string `json:"project"`
+	Topic   string `json:"topic"`
+}
+
+// Forwards returns whether the forwarder forwards an event of a type for an
+// org/repo. The org/repo is empty for events without a repository.
+func (f *EventForwarder) Forwards(eventType, orgRepo string) bool {
+	if len(f.Events) > 0 && !sets.New[string](f.Events...).Has(eventType) {
+		return false
+	}
+	if len(f.Repos) == 0 {
+		return true
+	}
+	org, _, _ := strings.Cut(orgRepo, "/")
+	for _, r := range f.Repos {
+		if r == orgRepo || r == org {
+			return true
+		}
+	}
+	return false
+}
+
 type Help struct {
 	// HelpGuidelinesURL is the URL of the help page, which provides guidance on how and when to use the help wanted and good first issue labels.
 	// The default value is "https://git.k8s.io/community/contributors/guide/help-wanted.md".
@@ -138,14 +301,30 @@ type ExternalPlugin struct {
 	// Name of the plugin.
 	Name string `json:"name"`
 	// Endpoint is the location of the external plugin. Defaults to
-	// the name of the plugin, ie. "http://{{name}}".
+	// the name of the plugin, ie. "http://{{name}}", or "{{name}}:80"
+	// for the grpc protocol.
 	Endpoint string `json:"endpoint,omitempty"`
 	// Events are the events that need to be demuxed by the hook
 	// server to the external plugin. If no events are specified,
-	// everything is sent.
+	// everything is sent. Plugins using the grpc protocol can
+	// further narrow this down during the handshake.
 	Events []string `json:"events,omitempty"`
+	// Protocol is how hook delivers events to the plugin. Either
+	// "http" (default), which forwards the raw webhook, or "grpc",
+	// which uses the protocol defined in pkg/externalplugin.
+	Protocol string `json:"protocol,omitempty"`
+	// Config is pushed to plugins using the grpc protocol. It is
+	// ignored for the http protocol.
+	Config map[string]string `json:"config,omitempty"`
 }
 
+const (
+	// ExternalPluginProtocolHTTP forwards raw webhooks over HTTP.
+	ExternalPluginProtocolHTTP = "http"
+	// ExternalPluginProtocolGRPC delivers events over gRPC.
+	ExternalPluginProtocolGRPC = "grpc"
+)
+
 // Blunderbuss defines configuration for the blunderbuss plugin.
 type Blunderbuss struct {
 	// ReviewerCount is the minimum number of reviewers to request
@@ -203,6 +382,28 @@ type Owners struct {
 	// Filenames allows configuring repos to use a separate set of filenames for
 	// any plugin that interacts with these files. Keys are in "org" or "org/repo" format.
 	Filenames map[string]ownersconfig.Filenames `json:"filenames,omitempty"`
+
+	// ExternalProviders allows configuring repos to load their ownership data
+	// from an external OWNERS provider instead of the OWNERS files in the repo.
+	// Keys are in "org" or "org/repo" format and values are the HTTP endpoints
+	// of the providers, which are queried with the org, repo, base and sha query
+	// parameters and must respond with the JSON encoded repoowners.ExternalOwners.
+	ExternalProviders map[string]string `json:"external_providers,omitempty"`
+
+	// RequiredTeams allows configuring repos to require users added to OWNERS
+	// and OWNERS_ALIASES files to be members of all of the given GitHub teams
+	// of the org. Keys are in "org" or "org/repo" format and values are team
+	// slugs. This check is performed by the verify-owners plugin.
+	RequiredTeams map[string][]string `json:"required_teams,omitempty"`
+
+	// IdentityServices allows configuring repos to require users added to
+	// OWNERS and OWNERS_ALIASES files to be known to an external identity
+	// service, e.g. the one backing the SSO of the org. Keys are in "org" or
+	// "org/repo" format and values are the HTTP endpoints of the services,
+	// which are queried with the org and user query parameters and must
+	// respond with 200 for known users and 404 for unknown ones.
+	// This check is performed by the verify-owners plugin.
+	IdentityServices map[string]string `json:"identity_services,omitempty"`
 }
 
 // OwnersFilenames determines which filenames to use for OWNERS and OWNERS_ALIASES for a repo.
@@ -223,6 +424,33 @@ func (c *Configuration) OwnersFilenames(org, repo string) ownersconfig.Filenames
 	}
 }
 
+// OwnersExternalProvider returns the endpoint of the external OWNERS provider
+// configured for a repo, or an empty string if the repo uses OWNERS files.
+func (c *Configuration) OwnersExternalProvider(org, repo string) string {
+	if endpoint, configured := c.Owners.ExternalProviders[fmt.Sprintf("%s/%s", org, repo)]; configured {
+		return endpoint
+	}
+	return c.Owners.ExternalProviders[org]
+}
+
+// OwnersRequiredTeams returns the GitHub teams users added to OWNERS files of
+// a repo must be members of.
+func (c *Configuration) OwnersRequiredTeams(org, repo string) []string {
+	if teams, configured := c.Owners.RequiredTeams[fmt.Sprintf("%s/%s", org, repo)]; configured {
+		return teams
+	}
+	return c.Owners.RequiredTeams[org]
+}
+
+// OwnersIdentityService returns the endpoint of the identity service users
+// added to OWNERS files of a repo must be known to, or an empty string.
+func (c *Configuration) OwnersIdentityService(org, repo string) string {
+	if endpoint, configured := c.Owners.IdentityServices[fmt.Sprintf("%s/%s", org, repo)]; configured {
+		return endpoint
+	}
+	return c.Owners.IdentityServices[org]
+}
+
 // MDYAMLEnabled returns a boolean denoting if the passed repo supports YAML OWNERS config headers
 // at the top of markdown (*.md) files. These function like OWNERS files but only apply to the file
 // itself.
@@ -283,6 +511,59 @@ type Size struct {
 	L   int `json:"l"`
 	Xl  int `json:"xl"`
 	Xxl int `json:"xxl"`
+	// ExcludedPaths are globs of paths whose changes are not counted, e.g.
+	// `vendor/**/*` or `**/zz_generated.*.go`. The globs are parsed with the
+	// go-zglob library.
+	ExcludedPaths []string `json:"excluded_paths,omitempty"`
+	// Repos overrides the configuration for orgs or repos, keyed by org or
+	// org/repo. Unset thresholds fall back to the ones above and excluded
+	// paths are added to the ones above.
+	Repos map[string]SizeOverride `json:"repos,omitempty"`
+}
+
+// SizeOverride specifies the size plugin configuration of an org or repo.
+type SizeOverride struct {
+	S   int `json:"s,omitempty"`
+	M   int `json:"m,omitempty"`
+	L   int `json:"l,omitempty"`
+	Xl  int `json:"xl,omitempty"`
+	Xxl int `json:"xxl,omitempty"`
+	// ExcludedPaths are globs of paths whose changes are not counted in
+	// addition to the globally excluded paths.
+	ExcludedPaths []string `json:"excluded_paths,omitempty"`
+}
+
+// For returns the size configuration for a repo, applying the overrides of
+// the org and then of the repo.
+func (s Size) For(org, repo string) Size {
+	result := Size{
+		S:             s.S,
+		M:             s.M,
+		L:             s.L,
+		Xl:            s.Xl,
+		Xxl:           s.Xxl,
+		ExcludedPaths: append([]string(nil), s.ExcludedPaths...),
+	}
+	for _, key := range []string{org, org + "/" + repo} {
+		override, ok := s.Repos[key]
+		if !ok {
+			continue
+		}
+		result.S = overrideIfSet(result.S, override.S)
+		result.M = overrideIfSet(result.M, override.M)
+		result.L = overrideIfSet(result.L, override.L)
+		result.Xl = overrideIfSet(result.Xl, override.Xl)
+		result.Xxl = overrideIfSet(result.Xxl, override.Xxl)
+		result.ExcludedPaths = append(result.ExcludedPaths, override.ExcludedPaths...)
+	}
+	return result
+}
+
+func overrideIfSet(value, override int) int {
+	if override != 0 {
+		return override
+	}
+	return value
 }
 
 // Blockade specifies a configuration for a single blockade.
@@ -305,6 +586,143 @@ type Blockade struct {
 	Explanation string `json:"explanation,omitempty"`
 }
 
+// Lifecycle specifies the stale issue and PR automation for a set of repos.
+// It is applied periodically by the lifecycle-controller.
+type Lifecycle struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// DaysUntilStale is the number of days without activity after which a PR
+	// is labeled lifecycle/stale. PRs are never marked as stale if unset.
+	DaysUntilStale int `json:"days_until_stale,omitempty"`
+	// DaysUntilRotten is the number of days without activity after which a
+	// stale PR is labeled lifecycle/rotten. PRs never rot if unset.
+	DaysUntilRotten int `json:"days_until_rotten,omitempty"`
+	// DaysUntilClose is the number of days without activity after which a
+	// rotten PR is closed. PRs are never closed if unset.
+	DaysUntilClose int `json:"days_until_close,omitempty"`
+	// IncludeIssues applies the automation to issues as well as PRs.
+	IncludeIssues bool `json:"include_issues,omitempty"`
+	// ExemptLabels are labels exempting issues and PRs from the automation in
+	// addition to lifecycle/frozen.
+	ExemptLabels []string `json:"exempt_labels,omitempty"`
+	// StaleComment, RottenComment and CloseComment are templates of the
+	// comments posted when an issue or PR goes stale, rots or is closed.
+	// For the info struct see prow/plugins/lifecycle/stale.go's StaleInfo.
+	StaleComment  string `json:"stale_comment,omitempty"`
+	RottenComment string `json:"rotten_comment,omitempty"`
+	CloseComment  string `json:"close_comment,omitempty"`
+}
+
+func (l Lifecycle) getRepos() []string {
+	return l.Repos
+}
+
+// PathLabel specifies the path-label plugin configuration for a set of repos.
+//
+// The configuration for the path-label plugin is defined as a list of these structures.
+type PathLabel struct {
+	// Repos are either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Rules map file paths to the labels that are applied to PRs changing them.
+	// The rules of all entries matching a repo or its org are combined.
+	Rules []PathLabelRule `json:"rules,omitempty"`
+}
+
+// PathLabelRule maps file paths to a label.
+type PathLabelRule struct {
+	// Regexp is the regular expression matching file paths, relative to the
+	// root of the repo, e.g. "^pkg/network/".
+	// Compiles into Re during config load.
+	Regexp string         `json:"regexp"`
+	Re     *regexp.Regexp `json:"-"`
+	// Label is applied to PRs changing at least one file matching Regexp and
+	// removed again once none of the changed files match.
+	Label string `json:"label"`
+}
+
+// DependencyApprover specifies the dependency-approver plugin configuration
+// for a set of repos. PRs of the configured authors that only change allowed
+// files are labeled with lgtm and approved once all their required jobs pass.
+type DependencyApprover struct {
+	// Repos is either of the form org/repo or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Authors are the logins of the bots whose PRs may be approved
+	// automatically. GitHub Apps need the [bot] suffix, e.g. "dependabot[bot]".
+	Authors []string `json:"authors,omitempty"`
+	// AllowedFiles are the files the PRs may change. Entries ending with a
+	// slash allow all files below a directory, other entries are path.Match
+	// patterns for the full path, e.g. "go.sum" or "staging/*/go.mod".
+	AllowedFiles []string `json:"allowed_files,omitempty"`
+	// Labels are the labels added to approved PRs.
+	// Defaults to "lgtm" and "approved".
+	Labels []string `json:"labels,omitempty"`
+}
+
+func (d DependencyApprover) getRepos() []string {
+	return d.Repos
+}
+
+// Allows returns whether the file may be changed by automatically approved PRs.
+func (d *DependencyApprover) Allows(file string) bool {
+	for _, allowed := range d.AllowedFiles {
+		if strings.HasSuffix(allowed, "/") {
+			if strings.HasPrefix(file, allowed) {
+				return true
+			}
+			continue
+		}
+		if match, _ := path.Match(allowed, file); match {
+			return true
+		}
+	}
+	return false
+}
+
+// DependencyApproverFor finds the DependencyApprover configuration for a repo,
+// which can be listed for the repo itself or for the owning organization. It
+// returns nil if the repo has no configuration.
+func (c *Configuration) DependencyApproverFor(org, repo string) *DependencyApprover {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for i := range c.DependencyApprover {
+		if sets.New[string](c.DependencyApprover[i].Repos...).Has(fullName) {
+			return &c.DependencyApprover[i]
+		}
+	}
+	for i := range c.DependencyApprover {
+		if sets.New[string](c.DependencyApprover[i].Repos...).Has(org) {
+			return &c.DependencyApprover[i]
+		}
+	}
+	return nil
+}
+
+// ReleaseNote specifies the release-note plugin configuration for a set of repos.
+//
+// The configuration for the release-note plugin is defined as a list of these structures.
+type ReleaseNote struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// RequiredSections are the sections a release note must contain. A section
+	// is a line in the release-note block starting with its case-insensitive
+	// name followed by a colon, e.g. `Kind: feature`.
+	RequiredSections []string `json:"required_sections,omitempty"`
+	// AllowedKinds are the values allowed in the `Kind` section of a release
+	// note. If set, a release note must contain the `Kind` section.
+	AllowedKinds []string `json:"allowed_kinds,omitempty"`
+	// MaxLength is the maximum number of characters of a release note.
+	// There is no limit if unset.
+	MaxLength int `json:"max_length,omitempty"`
+	// StatusContext is the context of the status the plugin reports on PRs
+	// to show whether their release note is valid. No status is reported if
+	// unset.
+	StatusContext string `json:"status_context,omitempty"`
+}
+
+// HasSchema returns whether release notes need to match a schema.
+func (r *ReleaseNote) HasSchema() bool {
+	return r != nil && (len(r.RequiredSections) > 0 || len(r.AllowedKinds) > 0 || r.MaxLength > 0)
+}
+
 // Approve specifies a configuration for a single approve.
 //
 // The configuration for the approve plugin is defined as a list of these structures.
@@ -372,6 +790,39 @@ type Lgtm struct {
 	// StickyLgtmTeam specifies the GitHub team whose members are trusted with sticky LGTM,
 	// which eliminates the need to re-lgtm minor fixes/updates.
 	StickyLgtmTeam string `json:"trusted_team_for_sticky_lgtm,omitempty"`
+	// LgtmThreshold is the number of distinct reviewers that need to LGTM a PR
+	// before the lgtm label is applied. Partial LGTMs are tracked in a comment.
+	// Defaults to 1.
+	LgtmThreshold int `json:"lgtm_threshold,omitempty"`
+	// BranchLgtmThresholds overrides LgtmThreshold for PRs against the given
+	// base branches.
+	BranchLgtmThresholds map[string]int `json:"branch_lgtm_thresholds,omitempty"`
+}
+
+// ThresholdFor returns the number of distinct reviewers that need to LGTM a
+// PR against the given base branch.
+func (l *Lgtm) ThresholdFor(branch string) int {
+	if threshold, ok := l.BranchLgtmThresholds[branch]; ok && threshold > 0 {
+		return threshold
+	}
+	if l.LgtmThreshold > 0 {
+		return l.LgtmThreshold
+	}
+	return 1
+}
+
+// HasThreshold determines if more than one reviewer may be required to LGTM
+// a PR.
+func (l *Lgtm) HasThreshold() bool {
+	if l.LgtmThreshold > 1 {
+		return true
+	}
+	for _, threshold := range l.BranchLgtmThresholds {
+		if threshold > 1 {
+			return true
+		}
+	}
+	return false
 }
 
 // Jira holds the config for the jira plugin.
@@ -380,6 +831,55 @@ type Jira struct {
 	// for example including `enterprise` here would disable linking for all issues
 	// that start with `enterprise-` like `enterprise-4.` Matching is case-insenitive.
 	DisabledJiraProjects []string `json:"disabled_jira_projects,omitempty"`
+	// Projects holds per-project configuration, keyed by the Jira project key,
+	// e.g. `ABC` for issues like `ABC-123`. Matching is case-insensitive.
+	Projects map[string]JiraProject `json:"projects,omitempty"`
+}
+
+// JiraProject contains the configuration of the jira plugin for a Jira project.
+type JiraProject struct {
+	// FixVersionLabelPrefix enables syncing the fix versions of the issues of
+	// this project referenced in a PR's title or description to labels on
+	// the PR. Each fix version is added as a label consisting of this prefix
+	// followed by the version, e.g. `jira/fix-version/` results in
+	// `jira/fix-version/4.12`. Labels with this prefix that don't match any
+	// fix version are removed.
+	FixVersionLabelPrefix string `json:"fix_version_label_prefix,omitempty"`
+	// TransitionOnMerge is the name of the transition applied to the issues
+	// of this project referenced in a PR's title or description when the PR
+	// merges, e.g. `MODIFIED`. No transition is applied if unset.
+	TransitionOnMerge string `json:"transition_on_merge,omitempty"`
+}
+
+// ProjectFor returns the configuration of the Jira project the issue with the
+// given key, e.g. `ABC-123`, belongs to.
+func (j *Jira) ProjectFor(issue string) (JiraProject, bool) {
+	if j == nil {
+		return JiraProject{}, false
+	}
+	projectKey := strings.Split(issue, "-")[0]
+	for key, project := range j.Projects {
+		if strings.EqualFold(key, projectKey) {
+			return project, true
+		}
+	}
+	return JiraProject{}, false
+}
+
+// FixVersionLabelPrefixes returns the label prefixes of all projects that
+// have their fix versions synced to labels.
+func (j *Jira) FixVersionLabelPrefixes() []string {
+	if j == nil {
+		return nil
+	}
+	var prefixes []string
+	for _, project := range j.Projects {
+		if project.FixVersionLabelPrefix != "" {
+			prefixes = append(prefixes, project.FixVersionLabelPrefix)
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes
 }
 
 // Cat contains the configuration for the cat plugin.
@@ -450,6 +950,10 @@ type Trigger struct {
 	// considered as trusted. The list should contain usernames of each GitHub App without [bot] suffix.
 	// By default, trigger will ignore this list.
 	TrustedApps []string `json:"trusted_apps,omitempty"`
+	// TrustedBots is the explicit list of bot accounts, i.e. regular GitHub
+	// users operated by automation, whose PRs will be automatically considered
+	// as trusted. Use TrustedApps for GitHub Apps.
+	TrustedBots []string `json:"trusted_bots,omitempty"`
 	// TrustedOrg is the org whose members' PRs will be automatically built for
 	// PRs to the above repos. The default is the PR's org.
 	//
@@ -466,8 +970,84 @@ type Trigger struct {
 	// IgnoreOkToTest makes trigger ignore /ok-to-test comments.
 	// This is a security mitigation to only allow testing from trusted users.
 	IgnoreOkToTest bool `json:"ignore_ok_to_test,omitempty"`
+	// ExpireOkToTestOnPush makes trigger remove the ok-to-test label when new
+	// commits are pushed to a PR from an untrusted author, so that each new
+	// revision needs to be approved with /ok-to-test again before it is tested.
+	ExpireOkToTestOnPush bool `json:"expire_ok_to_test_on_push,omitempty"`
 	// TriggerGitHubWorkflows enables workflows run by github to be triggered by prow.
 	TriggerGitHubWorkflows bool `json:"trigger_github_workflows,omitempty"`
+	// RegexJobLimit is the maximum number of jobs a single `/test re:<pattern>`
+	// or `/retest re:<pattern>` command may start. Commands matching more jobs
+	// are rejected. Defaults to 20.
+	RegexJobLimit int `json:"regex_job_limit,omitempty"`
+	// RetestBudget limits how often jobs can be retested. Retests are not
+	// limited if unset.
+	RetestBudget *RetestBudget `json:"retest_budget,omitempty"`
+	// MembershipService is consulted for users that are not trusted because
+	// of their GitHub org membership or collaborator status, for setups where
+	// trust is defined in an internal directory.
+	MembershipService *MembershipService `json:"membership_service,omitempty"`
+}
+
+// MembershipService is an external HTTP service that decides whether a
+// GitHub user is trusted. Prow sends a GET request to URL with the "user",
+// "org" and "repo" query parameters and expects a JSON response of the form
+// {"trusted": true, "groups": ["team-a"]}.
+type MembershipService struct {
+	// URL is the endpoint of the service.
+	URL string `json:"url"`
+	// TrustedGroups are the groups whose members are trusted, e.g. the
+	// values of an OIDC groups claim returned by the service. A user is
+	// trusted if the service responds with "trusted": true or with at least
+	// one of these groups.
+	TrustedGroups []string `json:"trusted_groups,omitempty"`
+	// Timeout is the timeout of requests to the service. Defaults to 10s.
+	Timeout         string        `json:"timeout,omitempty"`
+	TimeoutDuration time.Duration `json:"-"`
+}
+
+// RetestBudget limits how often jobs can be retested with /retest and
+// /retest-required, to stop retest loops from burning cluster capacity.
+// Retests are counted from the ProwJobs that such commands created.
+type RetestBudget struct {
+	// MaxPerPR is the maximum number of retests of a single PR within Window.
+	// Zero means unlimited.
+	MaxPerPR int `json:"max_per_pr,omitempty"`
+	// MaxPerRepo is the maximum number of retests across all PRs of a
+	// repository within Window. Zero means unlimited.
+	MaxPerRepo int `json:"max_per_repo,omitempty"`
+	// Window is the period over which retests are counted.
+	// Defaults to '24h'.
+	Window         string        `json:"window,omitempty"`
+	WindowDuration time.Duration `json:"-"`
+	// InitialBackoff is the time that must pass after the first retest of a
+	// PR before it can be retested again. The backoff doubles with every
+	// further retest within Window. No backoff is applied if unset.
+	InitialBackoff         string        `json:"initial_backoff,omitempty"`
+	InitialBackoffDuration time.Duration `json:"-"`
+	// MaxBackoff caps the backoff between retests of a PR.
+	// Defaults to '1h'.
+	MaxBackoff         string        `json:"max_backoff,omitempty"`
+	MaxBackoffDuration time.Duration `json:"-"`
+}
+
+// Backoff returns how long a PR that was already retested the given number
+// of times within the window has to wait before it can be retested again.
+func (b RetestBudget) Backoff(retests int) time.Duration {
+	if retests == 0 || b.InitialBackoffDuration == 0 {
+		return 0
+	}
+	backoff := b.InitialBackoffDuration
+	for i := 1; i < retests; i++ {
+		backoff *= 2
+		if backoff >= b.MaxBackoffDuration {
+			break
+		}
+	}
+	if backoff > b.MaxBackoffDuration {
+		return b.MaxBackoffDuration
+	}
+	return backoff
 }
 
 // Heart contains the configuration for the heart plugin.
@@ -501,6 +1081,48 @@ type Milestone struct {
 // This is used by the milestoneapplier plugin.
 type BranchToMilestone map[string]string
 
+// MilestoneApplierRule derives the milestone of PRs from their base branch.
+// This is used by the milestoneapplier plugin for branches that have no
+// milestone configured in MilestoneApplier.
+type MilestoneApplierRule struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// BranchRegexp matches the base branches the rule applies to.
+	// Compiles into BranchRe during config load.
+	BranchRegexp string         `json:"branch_regexp"`
+	BranchRe     *regexp.Regexp `json:"-"`
+	// Milestone is the milestone for matching branches. It may reference
+	// capture groups of BranchRegexp, e.g. a branch_regexp of
+	// `^release-(\d+\.\d+)$` and a milestone of `v$1` map the
+	// `release-1.29` branch to the `v1.29` milestone.
+	Milestone string `json:"milestone"`
+}
+
+// MilestoneForBranch returns the milestone the milestoneapplier plugin applies
+// to PRs against a branch of a repo. Milestones configured for the branch
+// take precedence over the first matching rule.
+func (c *Configuration) MilestoneForBranch(org, repo, branch string) (string, bool) {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	if milestone, ok := c.MilestoneApplier[fullName][branch]; ok {
+		return milestone, true
+	}
+	for _, rule := range c.MilestoneRules {
+		repos := sets.New[string](rule.Repos...)
+		if !repos.Has(org) && !repos.Has(fullName) {
+			continue
+		}
+		if rule.BranchRe == nil {
+			continue
+		}
+		match := rule.BranchRe.FindStringSubmatchIndex(branch)
+		if match == nil {
+			continue
+		}
+		return string(rule.BranchRe.ExpandString(nil, rule.Milestone, branch, match)), true
+	}
+	return "", false
+}
+
 // Slack contains the configuration for the slack plugin.
 type Slack struct {
 	MentionChannels []string       `json:"mentionchannels,omitempty"`
@@ -636,6 +1258,56 @@ type ProjectManager struct {
 	OrgRepos map[string]ManagedOrgRepo `json:"orgsRepos,omitempty"`
 }
 
+// ProjectsV2 is the config for the projects-v2 plugin. It adds issues and PRs
+// of a set of repos to a GitHub Projects (v2) board.
+type ProjectsV2 struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Org is the organization owning the project. Defaults to the org of
+	// the issue or PR.
+	Org string `json:"org,omitempty"`
+	// ProjectNumber is the number of the project within its organization.
+	ProjectNumber int `json:"project_number"`
+	// Labels restricts the issues and PRs added to the project to those
+	// carrying any of these labels. All issues and PRs are added if unset.
+	Labels []string `json:"labels,omitempty"`
+	// ExcludeIssues stops issues from being added to the project.
+	ExcludeIssues bool `json:"exclude_issues,omitempty"`
+	// ExcludePullRequests stops PRs from being added to the project.
+	ExcludePullRequests bool `json:"exclude_pull_requests,omitempty"`
+	// FieldRules set single select fields of project items.
+	FieldRules []ProjectsV2FieldRule `json:"field_rules,omitempty"`
+}
+
+// ProjectsV2FieldRule sets a single select field of a project item when the
+// issue or PR is labeled, or when a status context of a PR reaches a state.
+type ProjectsV2FieldRule struct {
+	// Field is the name of the single select field.
+	Field string `json:"field"`
+	// Value is the name of the option the field is set to.
+	Value string `json:"value"`
+	// Label triggers the rule when the issue or PR carries it.
+	Label string `json:"label,omitempty"`
+	// Context triggers the rule when the status context of the head of a PR
+	// reaches State, e.g. when a job reports its outcome.
+	Context string `json:"context,omitempty"`
+	// State is one of "pending", "success", "failure" or "error".
+	State string `json:"state,omitempty"`
+}
+
+// ProjectsV2For returns the projects-v2 configurations for a repo.
+func (c *Configuration) ProjectsV2For(org, repo string) []ProjectsV2 {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	var configs []ProjectsV2
+	for _, p := range c.ProjectsV2 {
+		repos := sets.New[string](p.Repos...)
+		if repos.Has(org) || repos.Has(fullName) {
+			configs = append(configs, p)
+		}
+	}
+	return configs
+}
+
 // ManagedOrgRepo is used by the ProjectManager plugin to represent an Organisation
 // or Repository with a list of Projects
 type ManagedOrgRepo struct {
@@ -676,6 +1348,160 @@ type MergeWarning struct {
 	ExemptBranches map[string][]string `json:"exempt_branches,omitempty"`
 }
 
+// Shadow is config for the shadow plugin, which runs jobs as defined by
+// the config in a pull request against the latest merge of the repositories
+// they belong to, and reports the results only to the pull request.
+type Shadow struct {
+	// Repos is either of the form org/repo or just org. These are the
+	// repositories holding the Prow config.
+	Repos []string `json:"repos,omitempty"`
+	// ProwConfigPath is the path of the Prow config in the repository.
+	ProwConfigPath string `json:"prow_config_path,omitempty"`
+	// JobConfigPath is the path of the job config file or directory in the
+	// repository, if jobs are not configured in the Prow config.
+	JobConfigPath string `json:"job_config_path,omitempty"`
+	// MaxJobs is the maximum number of jobs a single /shadow command can
+	// run. Defaults to 10.
+	MaxJobs int `json:"max_jobs,omitempty"`
+}
+
+func (s Shadow) getRepos() []string {
+	return s.Repos
+}
+
+// ShadowFor finds the Shadow configuration for a repo, which can be listed
+// for the repo itself or for the owning organization. It returns nil if the
+// repo has no configuration.
+func (c *Configuration) ShadowFor(org, repo string) *Shadow {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for i := range c.Shadow {
+		if sets.New[string](c.Shadow[i].Repos...).Has(fullName) {
+			return &c.Shadow[i]
+		}
+	}
+	for i := range c.Shadow {
+		if sets.New[string](c.Shadow[i].Repos...).Has(org) {
+			return &c.Shadow[i]
+		}
+	}
+	return nil
+}
+
+// TitleConvention is config for the title-convention plugin, which
+// validates PR titles against a naming convention.
+type TitleConvention struct {
+	// Repos is either of the form org/repo or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Patterns are regular expressions of which PR titles must match at
+	// least one, e.g. `^(feat|fix|docs|chore)(\([a-z-]+\))?!?: \S` for
+	// Conventional Commits or `^[A-Z][A-Z0-9]+-[0-9]+: ` for a JIRA key.
+	Patterns []string         `json:"patterns,omitempty"`
+	Res      []*regexp.Regexp `json:"-"`
+	// Description explains the convention to PR authors, e.g.
+	// "Titles must follow Conventional Commits: type(scope): summary".
+	Description string `json:"description,omitempty"`
+	// Context is the name of the status context reporting whether the title
+	// follows the convention. Defaults to "title-convention".
+	Context string `json:"context,omitempty"`
+	// TargetURL is linked from the status, e.g. to the contributor guide.
+	TargetURL string `json:"target_url,omitempty"`
+}
+
+func (t TitleConvention) getRepos() []string {
+	return t.Repos
+}
+
+// Matches returns whether title follows the convention.
+func (t *TitleConvention) Matches(title string) bool {
+	for _, re := range t.Res {
+		if re.MatchString(title) {
+			return true
+		}
+	}
+	return false
+}
+
+// TitleConventionFor finds the TitleConvention configuration for a repo,
+// which can be listed for the repo itself or for the owning organization. It
+// returns nil if the repo has no configuration.
+func (c *Configuration) TitleConventionFor(org, repo string) *TitleConvention {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for i := range c.TitleConvention {
+		if sets.New[string](c.TitleConvention[i].Repos...).Has(fullName) {
+			return &c.TitleConvention[i]
+		}
+	}
+	for i := range c.TitleConvention {
+		if sets.New[string](c.TitleConvention[i].Repos...).Has(org) {
+			return &c.TitleConvention[i]
+		}
+	}
+	return nil
+}
+
+// TriageRotation is config for the triage-rotation plugin, which assigns
+// newly opened issues to a member of a rotation group.
+type TriageRotation struct {
+	// Repos is either of the form org/repo or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Groups are the rotation groups of the repos. An issue is assigned to
+	// the first group whose labels it carries at least one of; a group
+	// without labels matches every issue and should therefore come last.
+	Groups []TriageGroup `json:"groups,omitempty"`
+}
+
+// TriageGroup is a group of users sharing triage duty.
+type TriageGroup struct {
+	// Labels limits the group to issues with at least one of these labels.
+	Labels []string `json:"labels,omitempty"`
+	// Members are the GitHub logins of the group members. Issues are assigned
+	// to the member with the fewest open issues assigned in the repo, ties
+	// are broken in rotation order.
+	Members []string `json:"members,omitempty"`
+}
+
+func (t TriageRotation) getRepos() []string {
+	return t.Repos
+}
+
+// GroupFor returns the rotation group responsible for an issue with the
+// given labels, or nil if no group matches.
+func (t *TriageRotation) GroupFor(issueLabels []github.Label) *TriageGroup {
+	names := sets.New[string]()
+	for _, l := range issueLabels {
+		names.Insert(strings.ToLower(l.Name))
+	}
+	for i, g := range t.Groups {
+		if len(g.Labels) == 0 {
+			return &t.Groups[i]
+		}
+		for _, l := range g.Labels {
+			if names.Has(strings.ToLower(l)) {
+				return &t.Groups[i]
+			}
+		}
+	}
+	return nil
+}
+
+// TriageRotationFor finds the TriageRotation configuration for a repo, which
+// can be listed for the repo itself or for the owning organization. It
+// returns nil if the repo has no configuration.
+func (c *Configuration) TriageRotationFor(org, repo string) *TriageRotation {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for i := range c.TriageRotation {
+		if sets.New[string](c.TriageRotation[i].Repos...).Has(fullName) {
+			return &c.TriageRotation[i]
+		}
+	}
+	for i := range c.TriageRotation {
+		if sets.New[string](c.TriageRotation[i].Repos...).Has(org) {
+			return &c.TriageRotation[i]
+		}
+	}
+	return nil
+}
+
 // Welcome is config for the welcome plugin.
 type Welcome struct {
 	// Repos is either of the form org/repos or just org.
@@ -711,6 +1537,11 @@ type Dco struct {
 	ContributingBranch string `json:"contributing_branch,omitempty"`
 	// ContributingPath is used to override the default path to CONTRIBUTING.md
 	ContributingPath string `json:"contributing_path,omitempty"`
+	// CLALabel is the label a CLA check applies to PRs whose authors signed
+	// the CLA, e.g. "cncf-cla: yes". Commits of PRs carrying it do not need a
+	// sign-off, so that contributors can choose between signing the CLA and
+	// signing off their commits. By default, a sign-off is always required.
+	CLALabel string `json:"cla_label,omitempty"`
 }
 
 // CherryPickApproved is the config for the cherrypick-approved plugin.
@@ -740,6 +1571,43 @@ type CherryPickUnapproved struct {
 	Comment string `json:"comment,omitempty"`
 }
 
+// RequiredLabels specifies the required-labels plugin configuration for a set
+// of repos.
+type RequiredLabels struct {
+	// Repos is either of the form org/repos or just org.
+	Repos []string `json:"repos,omitempty"`
+	// Branches are the base branches of PRs this configuration applies to.
+	// It applies to PRs against all branches if unset.
+	Branches []string `json:"branches,omitempty"`
+	// Labels are the labels a PR must carry, e.g. the labels required by the
+	// Tide queries of the repo.
+	Labels []string `json:"labels,omitempty"`
+	// BlockingLabels are the labels a PR must not carry, e.g. the missing
+	// labels of the Tide queries of the repo.
+	BlockingLabels []string `json:"blocking_labels,omitempty"`
+	// Context is the status context reporting whether the PR carries the
+	// right labels. Defaults to "required-labels".
+	Context string `json:"context,omitempty"`
+}
+
+// RequiredLabelsFor returns the required-labels configurations that apply to
+// PRs against a branch of a repo.
+func (c *Configuration) RequiredLabelsFor(org, repo, branch string) []RequiredLabels {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	var configs []RequiredLabels
+	for _, requiredLabels := range c.RequiredLabels {
+		repos := sets.New[string](requiredLabels.Repos...)
+		if !repos.Has(org) && !repos.Has(fullName) {
+			continue
+		}
+		if len(requiredLabels.Branches) > 0 && !sets.New[string](requiredLabels.Branches...).Has(branch) {
+			continue
+		}
+		configs = append(configs, requiredLabels)
+	}
+	return configs
+}
+
 // RequireMatchingLabel is the config for the require-matching-label plugin.
 type RequireMatchingLabel struct {
 	// Org is the GitHub organization that this config applies to.
@@ -902,6 +1770,55 @@ func (c *Configuration) LgtmFor(org, repo string) *Lgtm {
 	return &Lgtm{}
 }
 
+// ReleaseNoteFor finds the ReleaseNote configuration for a repo, which can be
+// listed for the repo itself or for the owning organization. It returns nil
+// if the repo has no configuration.
+func (c *Configuration) ReleaseNoteFor(org, repo string) *ReleaseNote {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for i := range c.ReleaseNote {
+		if sets.New[string](c.ReleaseNote[i].Repos...).Has(fullName) {
+			return &c.ReleaseNote[i]
+		}
+	}
+	for i := range c.ReleaseNote {
+		if sets.New[string](c.ReleaseNote[i].Repos...).Has(org) {
+			return &c.ReleaseNote[i]
+		}
+	}
+	return nil
+}
+
+// LifecycleFor returns the lifecycle configuration of a repo, or nil if it
+// is not configured.
+func (c *Configuration) LifecycleFor(org, repo string) *Lifecycle {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	for i := range c.Lifecycle {
+		if sets.New[string](c.Lifecycle[i].Repos...).Has(fullName) {
+			return &c.Lifecycle[i]
+		}
+	}
+	for i := range c.Lifecycle {
+		if sets.New[string](c.Lifecycle[i].Repos...).Has(org) {
+			return &c.Lifecycle[i]
+		}
+	}
+	return nil
+}
+
+// PathLabelRulesFor returns the path-label rules configured for a repo and
+// its org.
+func (c *Configuration) PathLabelRulesFor(org, repo string) []PathLabelRule {
+	fullName := fmt.Sprintf("%s/%s", org, repo)
+	var rules []PathLabelRule
+	for _, pathLabel := range c.PathLabel {
+		repos := sets.New[string](pathLabel.Repos...)
+		if repos.Has(org) || repos.Has(fullName) {
+			rules = append(rules, pathLabel.Rules...)
+		}
+	}
+	return rules
+}
+
 // TriggerFor finds the Trigger for a repo, if one exists
 // a trigger can be listed for the repo itself or for the
 // owning organization
@@ -931,6 +1848,17 @@ func (t *Trigger) SetDefaults() {
 	if t.TrustedOrg != "" && t.JoinOrgURL == "" {
 		t.JoinOrgURL = fmt.Sprintf("https://github.com/orgs/%s/people", t.TrustedOrg)
 	}
+	if t.RegexJobLimit == 0 {
+		t.RegexJobLimit = defaultRegexJobLimit
+	}
+	if t.RetestBudget != nil {
+		if t.RetestBudget.Window == "" {
+			t.RetestBudget.Window = "24h"
+		}
+		if t.RetestBudget.MaxBackoff == "" {
+			t.RetestBudget.MaxBackoff = "1h"
+		}
+	}
 }
 
 // DcoFor finds the Dco for a repo, if one exists
@@ -1007,6 +1935,25 @@ func (c *Configuration) EnabledReposForPlugin(plugin string) (orgs, repos []stri
 	return
 }
 
+// EnabledPluginsFor returns the plugins enabled for the repo, taking org
+// level config and excluded repos into account.
+func (c *Configuration) EnabledPluginsFor(org, repo string) []string {
+	var plugins []string
+	if !sets.New[string](c.Plugins[org].ExcludedRepos...).Has(repo) {
+		plugins = append(plugins, c.Plugins[org].Plugins...)
+	}
+	plugins = append(plugins, c.Plugins[org+"/"+repo].Plugins...)
+	return plugins
+}
+
+// ExternalPluginsFor returns the external plugins enabled for the repo.
+func (c *Configuration) ExternalPluginsFor(org, repo string) []ExternalPlugin {
+	var externals []ExternalPlugin
+	externals = append(externals, c.ExternalPlugins[org]...)
+	externals = append(externals, c.ExternalPlugins[org+"/"+repo]...)
+	return externals
+}
+
 // EnabledReposForExternalPlugin returns the orgs and repos that have enabled the passed
 // external plugin.
 func (c *Configuration) EnabledReposForExternalPlugin(plugin string) (orgs, repos []string) {
@@ -1053,6 +2000,24 @@ func (cu *ConfigUpdater) SetDefaults() {
 func (c *Configuration) setDefaults() {
 	c.Help.setDefaults()
 
+	for i := range c.Shadow {
+		if c.Shadow[i].MaxJobs == 0 {
+			c.Shadow[i].MaxJobs = 10
+		}
+	}
+
+	for i := range c.TitleConvention {
+		if c.TitleConvention[i].Context == "" {
+			c.TitleConvention[i].Context = "title-convention"
+		}
+	}
+
+	for i := range c.DependencyApprover {
+		if len(c.DependencyApprover[i].Labels) == 0 {
+			c.DependencyApprover[i].Labels = []string{labels.LGTM, labels.Approved}
+		}
+	}
+
 	c.ConfigUpdater.SetDefaults()
 
 	for repo, plugins := range c.ExternalPlugins {
@@ -1060,6 +2025,10 @@ func (c *Configuration) setDefaults() {
 			if p.Endpoint != "" {
 				continue
 			}
+			if p.Protocol == ExternalPluginProtocolGRPC {
+				c.ExternalPlugins[repo][i].Endpoint = fmt.Sprintf("%s:80", p.Name)
+				continue
+			}
 			c.ExternalPlugins[repo][i].Endpoint = fmt.Sprintf("http://%s", p.Name)
 		}
 	}
@@ -1099,6 +2068,12 @@ func (c *Configuration) setDefaults() {
 			c.RequireMatchingLabel[i].GracePeriod = "5s"
 		}
 	}
+
+	for i := range c.RequiredLabels {
+		if c.RequiredLabels[i].Context == "" {
+			c.RequiredLabels[i].Context = "required-labels"
+		}
+	}
 }
 
 // validatePluginsDupes will return an error if there are duplicated plugins.
@@ -1131,10 +2106,49 @@ func (c *Configuration) ValidatePluginsUnknown() error {
 	return utilerrors.NewAggregate(errors)
 }
 
+func validateEventForwarders(forwarders []EventForwarder) error {
+	var errs []error
+	names := sets.New[string]()
+	for i, f := range forwarders {
+		if f.Name == "" {
+			errs = append(errs, fmt.Errorf("event_forwarders[%d]: name must be set", i))
+		} else if names.Has(f.Name) {
+			errs = append(errs, fmt.Errorf("event_forwarders[%d]: name %q is duplicated", i, f.Name))
+		}
+		names.Insert(f.Name)
+		switch {
+		case f.Endpoint == "" && f.PubSub == nil:
+			errs = append(errs, fmt.Errorf("event forwarder %q: one of endpoint or pubsub must be set", f.Name))
+		case f.Endpoint != "" && f.PubSub != nil:
+			errs = append(errs, fmt.Errorf("event forwarder %q: only one of endpoint or pubsub may be set", f.Name))
+		case f.Endpoint != "":
+			if u, err := url.Parse(f.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				errs = append(errs, fmt.Errorf("event forwarder %q: endpoint %q is not an http(s) URL", f.Name, f.Endpoint))
+			}
+		case f.PubSub.Project == "" || f.PubSub.Topic == "":
+			errs = append(errs, fmt.Errorf("event forwarder %q: pubsub project and topic must be set", f.Name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func validateSizes(size Size) error {
 	if size.S > size.M || size.M > size.L || size.L > size.Xl || size.Xl > size.Xxl {
 		return errors.New("invalid size plugin configuration - one of the smaller sizes is bigger than a larger one")
 	}
+	for _, glob := range size.ExcludedPaths {
+		// Validate with the library that matches the globs, path.Match
+		// rejects some globs it accepts, e.g. with a literal "[".
+		if _, err := zglob.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid size plugin configuration - excluded path %q is not a valid glob: %w", glob, err)
+		}
+	}
+	for key := range size.Repos {
+		org, repo, _ := strings.Cut(key, "/")
+		if err := validateSizes(size.For(org, repo)); err != nil {
+			return fmt.Errorf("%w (in the configuration for %s)", err, key)
+		}
+	}
 
 	return nil
 }
@@ -1156,6 +2170,13 @@ func validateExternalPlugins(pluginMap map[string][]ExternalPlugin) error {
 	var errors []string
 
 	for repo, plugins := range pluginMap {
+		for _, p := range plugins {
+			switch p.Protocol {
+			case "", ExternalPluginProtocolHTTP, ExternalPluginProtocolGRPC:
+			default:
+				errors = append(errors, fmt.Sprintf("external plugin %s for %s has invalid protocol %q, must be one of %q or %q", p.Name, repo, p.Protocol, ExternalPluginProtocolHTTP, ExternalPluginProtocolGRPC))
+			}
+		}
 		if !strings.Contains(repo, "/") {
 			continue
 		}
@@ -1313,10 +2334,198 @@ func validateTrigger(triggers []Trigger) error {
 		if trigger.TrustedOrg != "" {
 			logrusutil.ThrottledWarnf(&warnTriggerTrustedOrg, 5*time.Minute, "trusted_org functionality is deprecated. Please ensure your configuration is updated before the end of December 2019.")
 		}
+		if s := trigger.MembershipService; s != nil {
+			if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("membership_service url for trigger of %v must be an http or https URL, got %q", trigger.Repos, s.URL)
+			}
+			if s.TimeoutDuration <= 0 {
+				return fmt.Errorf("membership_service timeout for trigger of %v must be positive, got %q", trigger.Repos, s.Timeout)
+			}
+		}
 	}
 	return nil
 }
 
+func validateLgtm(lgtms []Lgtm) error {
+	var errs []error
+	for _, lgtm := range lgtms {
+		if lgtm.LgtmThreshold < 0 {
+			errs = append(errs, fmt.Errorf("lgtm_threshold for %v must not be negative, got %d", lgtm.Repos, lgtm.LgtmThreshold))
+		}
+		for branch, threshold := range lgtm.BranchLgtmThresholds {
+			if threshold < 1 {
+				errs = append(errs, fmt.Errorf("branch_lgtm_thresholds for %v must be positive, got %d for branch %q", lgtm.Repos, threshold, branch))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateOwners(owners Owners) error {
+	var errs []error
+	for repo, endpoint := range owners.ExternalProviders {
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("external OWNERS provider for %s must be an absolute URL, got %q", repo, endpoint))
+		}
+	}
+	for repo, endpoint := range owners.IdentityServices {
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("identity service for %s must be an absolute URL, got %q", repo, endpoint))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validatePathLabel(pathLabels []PathLabel) error {
+	var errs []error
+	for _, pathLabel := range pathLabels {
+		for _, rule := range pathLabel.Rules {
+			if rule.Regexp == "" || rule.Label == "" {
+				errs = append(errs, fmt.Errorf("path_label rules for %v must specify both regexp and label, got regexp %q and label %q", pathLabel.Repos, rule.Regexp, rule.Label))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateProjectsV2(projects []ProjectsV2) error {
+	states := sets.New[string](github.StatusPending, github.StatusSuccess, github.StatusFailure, github.StatusError)
+	var errs []error
+	for _, p := range projects {
+		if p.ProjectNumber <= 0 {
+			errs = append(errs, fmt.Errorf("projects_v2 for %v must specify a positive project_number", p.Repos))
+		}
+		for _, r := range p.FieldRules {
+			if r.Field == "" || r.Value == "" {
+				errs = append(errs, fmt.Errorf("projects_v2 field rule for project %d must specify field and value", p.ProjectNumber))
+			}
+			if (r.Label == "") == (r.Context == "") {
+				errs = append(errs, fmt.Errorf("projects_v2 field rule %q for project %d must specify exactly one of label or context", r.Field, p.ProjectNumber))
+			}
+			if r.Context != "" && !states.Has(r.State) {
+				errs = append(errs, fmt.Errorf("projects_v2 field rule %q for project %d has invalid state %q, must be one of %v", r.Field, p.ProjectNumber, r.State, sets.List(states)))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateDependencyApprover(approvers []DependencyApprover) error {
+	var errs []error
+	for _, d := range approvers {
+		if len(d.Authors) == 0 {
+			errs = append(errs, fmt.Errorf("dependency_approver for %v must specify authors", d.Repos))
+		}
+		if len(d.AllowedFiles) == 0 {
+			errs = append(errs, fmt.Errorf("dependency_approver for %v must specify allowed_files", d.Repos))
+		}
+		for _, allowed := range d.AllowedFiles {
+			if _, err := path.Match(allowed, ""); err != nil {
+				errs = append(errs, fmt.Errorf("dependency_approver for %v has invalid allowed file pattern %q: %w", d.Repos, allowed, err))
+			}
+		}
+	}
+	if err := validateRepoDupes(approvers); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateShadow(shadows []Shadow) error {
+	var errs []error
+	for _, s := range shadows {
+		if s.ProwConfigPath == "" {
+			errs = append(errs, fmt.Errorf("shadow for %v must specify prow_config_path", s.Repos))
+		}
+		if s.MaxJobs < 0 {
+			errs = append(errs, fmt.Errorf("shadow for %v has negative max_jobs %d", s.Repos, s.MaxJobs))
+		}
+	}
+	if err := validateRepoDupes(shadows); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateTitleConvention(conventions []TitleConvention) error {
+	var errs []error
+	for _, t := range conventions {
+		if len(t.Patterns) == 0 {
+			errs = append(errs, fmt.Errorf("title_convention for %v must specify patterns", t.Repos))
+		}
+	}
+	if err := validateRepoDupes(conventions); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateTriageRotation(rotations []TriageRotation) error {
+	var errs []error
+	for _, r := range rotations {
+		if len(r.Groups) == 0 {
+			errs = append(errs, fmt.Errorf("triage_rotation for %v must specify groups", r.Repos))
+		}
+		for i, g := range r.Groups {
+			if len(g.Members) == 0 {
+				errs = append(errs, fmt.Errorf("triage_rotation for %v: group %d must specify members", r.Repos, i))
+			}
+			if len(g.Labels) == 0 && i != len(r.Groups)-1 {
+				errs = append(errs, fmt.Errorf("triage_rotation for %v: group %d without labels must be the last group", r.Repos, i))
+			}
+		}
+	}
+	if err := validateRepoDupes(rotations); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateRequiredLabels(requiredLabels []RequiredLabels) error {
+	var errs []error
+	for _, r := range requiredLabels {
+		if len(r.Labels) == 0 && len(r.BlockingLabels) == 0 {
+			errs = append(errs, fmt.Errorf("required_labels for %v must specify labels or blocking_labels", r.Repos))
+		}
+		if both := sets.New[string](r.Labels...).Intersection(sets.New[string](r.BlockingLabels...)); both.Len() > 0 {
+			errs = append(errs, fmt.Errorf("required_labels for %v has labels that are both required and blocking: %v", r.Repos, sets.List(both)))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateLifecycle(lifecycles []Lifecycle) error {
+	var errs []error
+	for _, lifecycle := range lifecycles {
+		if lifecycle.DaysUntilStale < 0 || lifecycle.DaysUntilRotten < 0 || lifecycle.DaysUntilClose < 0 {
+			errs = append(errs, fmt.Errorf("days of lifecycle for %v must not be negative", lifecycle.Repos))
+		}
+		for name, comment := range map[string]string{
+			"stale_comment":  lifecycle.StaleComment,
+			"rotten_comment": lifecycle.RottenComment,
+			"close_comment":  lifecycle.CloseComment,
+		} {
+			if _, err := template.New(name).Parse(comment); err != nil {
+				errs = append(errs, fmt.Errorf("%s of lifecycle for %v is not a valid template: %w", name, lifecycle.Repos, err))
+			}
+		}
+	}
+	if err := validateRepoDupes(lifecycles); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateReleaseNote(releaseNotes []ReleaseNote) error {
+	var errs []error
+	for _, releaseNote := range releaseNotes {
+		if releaseNote.MaxLength < 0 {
+			errs = append(errs, fmt.Errorf("max_length of release_note for %v must not be negative, got %d", releaseNote.Repos, releaseNote.MaxLength))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 var warnRepoMilestone time.Time
 
 func validateRepoMilestone(milestones map[string]Milestone) {
@@ -1348,6 +2557,30 @@ func compileRegexpsAndDurations(pc *Configuration) error {
 		pc.CherryPickApproved[i].BranchRe = approvedBranchRe
 	}
 
+	for i := range pc.MilestoneRules {
+		rule := &pc.MilestoneRules[i]
+		if rule.BranchRegexp == "" || rule.Milestone == "" {
+			return fmt.Errorf("milestone_applier_rules for %v must specify branch_regexp and milestone", rule.Repos)
+		}
+		branchRe, err := regexp.Compile(rule.BranchRegexp)
+		if err != nil {
+			return fmt.Errorf("failed to compile milestone_applier_rules branch_regexp: %q, error: %w", rule.BranchRegexp, err)
+		}
+		rule.BranchRe = branchRe
+	}
+
+	for i := range pc.TitleConvention {
+		t := &pc.TitleConvention[i]
+		t.Res = nil
+		for _, pattern := range t.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("failed to compile title_convention pattern for %v: %q, error: %w", t.Repos, pattern, err)
+			}
+			t.Res = append(t.Res, re)
+		}
+	}
+
 	for i := range pc.Blockades {
 		if pc.Blockades[i].BranchRegexp == nil {
 			continue
@@ -1380,6 +2613,73 @@ func compileRegexpsAndDurations(pc *Configuration) error {
 		}
 		rs[i].GracePeriodDuration = dur
 	}
+
+	for i := range pc.PathLabel {
+		for j, rule := range pc.PathLabel[i].Rules {
+			re, err := regexp.Compile(rule.Regexp)
+			if err != nil {
+				return fmt.Errorf("failed to compile path_label regexp: %q, error: %w", rule.Regexp, err)
+			}
+			pc.PathLabel[i].Rules[j].Re = re
+		}
+	}
+
+	for _, trigger := range pc.Triggers {
+		if trigger.RetestBudget == nil {
+			continue
+		}
+		if err := trigger.RetestBudget.compileDurations(); err != nil {
+			return fmt.Errorf("invalid retest_budget for trigger of %v: %w", trigger.Repos, err)
+		}
+	}
+
+	if err := pc.CommandRateLimit.compileDurations(); err != nil {
+		return fmt.Errorf("invalid command_rate_limit: %w", err)
+	}
+
+	for _, trigger := range pc.Triggers {
+		s := trigger.MembershipService
+		if s == nil {
+			continue
+		}
+		s.TimeoutDuration = 10 * time.Second
+		if s.Timeout == "" {
+			continue
+		}
+		dur, err := time.ParseDuration(s.Timeout)
+		if err != nil {
+			return fmt.Errorf("failed to parse membership_service timeout for trigger of %v: %q, error: %w", trigger.Repos, s.Timeout, err)
+		}
+		s.TimeoutDuration = dur
+	}
+	return nil
+}
+
+func (b *RetestBudget) compileDurations() error {
+	for _, d := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{name: "window", value: b.Window, dest: &b.WindowDuration},
+		{name: "initial_backoff", value: b.InitialBackoff, dest: &b.InitialBackoffDuration},
+		{name: "max_backoff", value: b.MaxBackoff, dest: &b.MaxBackoffDuration},
+	} {
+		if d.value == "" {
+			continue
+		}
+		dur, err := time.ParseDuration(d.value)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s duration: %q, error: %w", d.name, d.value, err)
+		}
+		if dur < 0 {
+			return fmt.Errorf("%s must not be negative, got %q", d.name, d.value)
+		}
+		*d.dest = dur
+	}
+	if b.MaxPerPR < 0 || b.MaxPerRepo < 0 {
+		return errors.New("max_per_pr and max_per_repo must not be negative")
+	}
 	return nil
 }
 
@@ -1401,6 +2701,9 @@ func (c *Configuration) Validate() error {
 	if err := validateExternalPlugins(c.ExternalPlugins); err != nil {
 		return err
 	}
+	if err := validateEventForwarders(c.EventForwarders); err != nil {
+		return err
+	}
 	if err := validateBlunderbuss(&c.Blunderbuss); err != nil {
 		return err
 	}
@@ -1419,6 +2722,39 @@ func (c *Configuration) Validate() error {
 	if err := validateTrigger(c.Triggers); err != nil {
 		return err
 	}
+	if err := validateTriageRotation(c.TriageRotation); err != nil {
+		return err
+	}
+	if err := validateTitleConvention(c.TitleConvention); err != nil {
+		return err
+	}
+	if err := validateShadow(c.Shadow); err != nil {
+		return err
+	}
+	if err := validateLgtm(c.Lgtm); err != nil {
+		return err
+	}
+	if err := validateOwners(c.Owners); err != nil {
+		return err
+	}
+	if err := validatePathLabel(c.PathLabel); err != nil {
+		return err
+	}
+	if err := validateReleaseNote(c.ReleaseNote); err != nil {
+		return err
+	}
+	if err := validateLifecycle(c.Lifecycle); err != nil {
+		return err
+	}
+	if err := validateDependencyApprover(c.DependencyApprover); err != nil {
+		return err
+	}
+	if err := validateRequiredLabels(c.RequiredLabels); err != nil {
+		return err
+	}
+	if err := validateProjectsV2(c.ProjectsV2); err != nil {
+		return err
+	}
 	if err := validateRepoDupes(c.Approve); err != nil {
 		return err
 	}
@@ -2001,17 +3337,34 @@ func (b *BranchCleaner) IsPreservedBranch(org, repo, branch string) bool {
 // Override holds options for the override plugin
 type Override struct {
 	AllowTopLevelOwners bool `json:"allow_top_level_owners,omitempty"`
+	// AllowTopLevelOwnersRepos is a list of orgs and/or repositories (eg "org" or "org/repo") in which approvers
+	// in the top level OWNERS file are allowed to override contexts. AllowTopLevelOwners enables this for all repos.
+	AllowTopLevelOwnersRepos []string `json:"allow_top_level_owners_repos,omitempty"`
 	// AllowedGitHubTeams is a map of orgs and/or repositories (eg "org" or "org/repo") to list of GitHub team slugs,
 	// members of which are allowed to override contexts
 	AllowedGitHubTeams map[string][]string `json:"allowed_github_teams,omitempty"`
+	// RequireReason requires the /override comment to explain why the contexts are overridden. The reason is
+	// given on the lines following the /override command and is recorded in the comment auditing the override.
+	RequireReason bool `json:"require_reason,omitempty"`
+}
+
+// TopLevelOwnersAllowed returns whether approvers in the top level OWNERS file
+// are allowed to override contexts in the repo.
+func (o Override) TopLevelOwnersAllowed(org, repo string) bool {
+	if o.AllowTopLevelOwners {
+		return true
+	}
+	repos := sets.New[string](o.AllowTopLevelOwnersRepos...)
+	return repos.Has(org) || repos.Has(fmt.Sprintf("%s/%s", org, repo))
 }
 
 func (c *Configuration) mergeFrom(other *Configuration) error {
 	var errs []error
 
 	diff := cmp.Diff(other, &Configuration{Approve: other.Approve, Bugzilla: other.Bugzilla,
-		ExternalPlugins: other.ExternalPlugins, Label: Label{RestrictedLabels: other.Label.RestrictedLabels},
-		Lgtm: other.Lgtm, Plugins: other.Plugins, Triggers: other.Triggers, Welcome: other.Welcome},
+		Dco: other.Dco, ExternalPlugins: other.ExternalPlugins, Label: Label{RestrictedLabels: other.Label.RestrictedLabels},
+		Lgtm: other.Lgtm, MilestoneApplier: other.MilestoneApplier, Plugins: other.Plugins, ProjectsV2: other.ProjectsV2,
+		RepoMilestone: other.RepoMilestone, RequiredLabels: other.RequiredLabels, Triggers: other.Triggers, Welcome: other.Welcome},
 		config.DefaultDiffOpts...)
 
 	if diff != "" {
@@ -2031,9 +3384,21 @@ func (c *Configuration) mergeFrom(other *Configuration) error {
 
 	c.Approve = append(c.Approve, other.Approve...)
 	c.Lgtm = append(c.Lgtm, other.Lgtm...)
+	c.ProjectsV2 = append(c.ProjectsV2, other.ProjectsV2...)
+	c.RequiredLabels = append(c.RequiredLabels, other.RequiredLabels...)
 	c.Triggers = append(c.Triggers, other.Triggers...)
 	c.Welcome = append(c.Welcome, other.Welcome...)
 
+	if err := mergeOrgRepoMap("dco", &c.Dco, other.Dco); err != nil {
+		errs = append(errs, err)
+	}
+	if err := mergeOrgRepoMap("milestone_applier", &c.MilestoneApplier, other.MilestoneApplier); err != nil {
+		errs = append(errs, err)
+	}
+	if err := mergeOrgRepoMap("repo_milestone", &c.RepoMilestone, other.RepoMilestone); err != nil {
+		errs = append(errs, err)
+	}
+
 	if err := c.mergeExternalPluginsFrom(other.ExternalPlugins); err != nil {
 		errs = append(errs, fmt.Errorf("failed to merge .external-plugins from supplemental config: %w", err))
 	}
@@ -2046,22 +3411,32 @@ func (c *Configuration) mergeFrom(other *Configuration) error {
 }
 
 func (c *Configuration) mergeExternalPluginsFrom(other map[string][]ExternalPlugin) error {
-	if c.ExternalPlugins == nil && other != nil {
-		c.ExternalPlugins = make(map[string][]ExternalPlugin)
+	return mergeOrgRepoMap("external-plugins", &c.ExternalPlugins, other)
+}
+
+// mergeOrgRepoMap merges org or org/repo keyed configuration, failing for
+// keys that are configured in both.
+func mergeOrgRepoMap[V any](field string, into *map[string]V, from map[string]V) error {
+	if *into == nil && from != nil {
+		*into = make(map[string]V, len(from))
 	}
 
 	var errs []error
-	for orgOrRepo, config := range other {
-		if _, ok := c.ExternalPlugins[orgOrRepo]; ok {
-			errs = append(errs, fmt.Errorf("found duplicate config for external-plugins.%s", orgOrRepo))
+	for orgOrRepo, config := range from {
+		if _, ok := (*into)[orgOrRepo]; ok {
+			errs = append(errs, fmt.Errorf("found duplicate config for %s.%s", field, orgOrRepo))
 			continue
 		}
-		c.ExternalPlugins[orgOrRepo] = config
+		(*into)[orgOrRepo] = config
 	}
 
 	return utilerrors.NewAggregate(errs)
 }
 
+// mergeFrom merges the plugin enablement of another file. An org or repo may
+// be configured in several files, so that teams can own the enablement of
+// their plugins, as long as each plugin is enabled in only one of them and
+// only one of them excludes repos of the org.
 func (p *Plugins) mergeFrom(other *Plugins) error {
 	if other == nil {
 		return nil
@@ -2073,11 +3448,31 @@ func (p *Plugins) mergeFrom(other *Plugins) error {
 
 	var errs []error
 	for orgOrRepo, config := range *other {
-		if _, ok := (*p)[orgOrRepo]; ok {
+		existing, ok := (*p)[orgOrRepo]
+		if !ok {
+			(*p)[orgOrRepo] = config
+			continue
+		}
+		if len(config.Plugins) == 0 {
 			errs = append(errs, fmt.Errorf("found duplicate config for plugins.%s", orgOrRepo))
 			continue
 		}
-		(*p)[orgOrRepo] = config
+		if dupes := sets.List(sets.New[string](existing.Plugins...).Intersection(sets.New[string](config.Plugins...))); len(dupes) > 0 {
+			errs = append(errs, fmt.Errorf("found duplicate config for plugins.%s: %s enabled more than once", orgOrRepo, strings.Join(dupes, ", ")))
+			continue
+		}
+		if len(existing.ExcludedRepos) > 0 && len(config.ExcludedRepos) > 0 {
+			errs = append(errs, fmt.Errorf("found duplicate config for plugins.%s: excluded_repos set more than once", orgOrRepo))
+			continue
+		}
+		merged := OrgPlugins{
+			ExcludedRepos: existing.ExcludedRepos,
+			Plugins:       append(append([]string{}, existing.Plugins...), config.Plugins...),
+		}
+		if len(config.ExcludedRepos) > 0 {
+			merged.ExcludedRepos = config.ExcludedRepos
+		}
+		(*p)[orgOrRepo] = merged
 	}
 
 	return utilerrors.NewAggregate(errs)
@@ -93,6 +93,30 @@ func TestValidateExternalPlugins(t *testing.T) {
 			},
 			expectedErr: errors.New("invalid plugin configuration:\n\texternal plugins [tetris] are duplicated for kubernetes/test-infra and kubernetes"),
 		},
+		{
+			name: "grpc protocol is valid",
+			plugins: map[string][]ExternalPlugin{
+				"kubernetes": {
+					{
+						Name:     "coffeemachine",
+						Protocol: ExternalPluginProtocolGRPC,
+					},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "unknown protocol is invalid",
+			plugins: map[string][]ExternalPlugin{
+				"kubernetes": {
+					{
+						Name:     "coffeemachine",
+						Protocol: "smtp",
+					},
+				},
+			},
+			expectedErr: errors.New("invalid plugin configuration:\n\texternal plugin coffeemachine for kubernetes has invalid protocol \"smtp\", must be one of \"http\" or \"grpc\""),
+		},
 	}
 
 	for _, test := range tests {
@@ -151,6 +175,369 @@ func TestOwnersFilenames(t *testing.T) {
 	}
 }
 
+func TestOwnersExternalProvider(t *testing.T) {
+	config := Owners{
+		ExternalProviders: map[string]string{
+			"kubernetes":            "https://owners.example.com/org",
+			"kubernetes/test-infra": "https://owners.example.com/repo",
+		},
+	}
+	cases := []struct {
+		org      string
+		repo     string
+		expected string
+	}{
+		{org: "kubernetes", repo: "test-infra", expected: "https://owners.example.com/repo"},
+		{org: "kubernetes", repo: "kubernetes", expected: "https://owners.example.com/org"},
+		{org: "other", repo: "repo", expected: ""},
+	}
+
+	for _, tc := range cases {
+		cfg := Configuration{Owners: config}
+		if actual := cfg.OwnersExternalProvider(tc.org, tc.repo); actual != tc.expected {
+			t.Errorf("%s/%s: expected %q, got %q", tc.org, tc.repo, tc.expected, actual)
+		}
+	}
+}
+
+func TestPathLabelRulesFor(t *testing.T) {
+	cfg := Configuration{
+		PathLabel: []PathLabel{
+			{Repos: []string{"org"}, Rules: []PathLabelRule{{Regexp: "^docs/", Label: "kind/documentation"}}},
+			{Repos: []string{"org/repo"}, Rules: []PathLabelRule{{Regexp: "^pkg/network/", Label: "sig/network"}}},
+			{Repos: []string{"other"}, Rules: []PathLabelRule{{Regexp: ".*", Label: "other"}}},
+		},
+	}
+	cases := []struct {
+		org      string
+		repo     string
+		expected []string
+	}{
+		{org: "org", repo: "repo", expected: []string{"kind/documentation", "sig/network"}},
+		{org: "org", repo: "other-repo", expected: []string{"kind/documentation"}},
+		{org: "unknown", repo: "repo"},
+	}
+
+	for _, tc := range cases {
+		var actual []string
+		for _, rule := range cfg.PathLabelRulesFor(tc.org, tc.repo) {
+			actual = append(actual, rule.Label)
+		}
+		if diff := cmp.Diff(tc.expected, actual); diff != "" {
+			t.Errorf("%s/%s: rules differ from expected (-want +got):\n%s", tc.org, tc.repo, diff)
+		}
+	}
+}
+
+func TestReleaseNoteFor(t *testing.T) {
+	cfg := Configuration{
+		ReleaseNote: []ReleaseNote{
+			{Repos: []string{"org"}, MaxLength: 100},
+			{Repos: []string{"org/repo"}, MaxLength: 200},
+		},
+	}
+	cases := []struct {
+		org      string
+		repo     string
+		expected *ReleaseNote
+	}{
+		{org: "org", repo: "repo", expected: &cfg.ReleaseNote[1]},
+		{org: "org", repo: "other-repo", expected: &cfg.ReleaseNote[0]},
+		{org: "unknown", repo: "repo"},
+	}
+
+	for _, tc := range cases {
+		if actual := cfg.ReleaseNoteFor(tc.org, tc.repo); actual != tc.expected {
+			t.Errorf("%s/%s: expected %+v, got %+v", tc.org, tc.repo, tc.expected, actual)
+		}
+	}
+}
+
+func TestSizeFor(t *testing.T) {
+	size := Size{
+		S:             10,
+		M:             30,
+		L:             100,
+		Xl:            500,
+		Xxl:           1000,
+		ExcludedPaths: []string{"vendor/**/*"},
+		Repos: map[string]SizeOverride{
+			"org":      {Xl: 1000, Xxl: 2000, ExcludedPaths: []string{"**/*.pb.go"}},
+			"org/repo": {Xxl: 5000, ExcludedPaths: []string{"**/zz_generated.*.go"}},
+		},
+	}
+	cases := []struct {
+		org      string
+		repo     string
+		expected Size
+	}{
+		{
+			org:      "other",
+			repo:     "repo",
+			expected: Size{S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000, ExcludedPaths: []string{"vendor/**/*"}},
+		},
+		{
+			org:      "org",
+			repo:     "other",
+			expected: Size{S: 10, M: 30, L: 100, Xl: 1000, Xxl: 2000, ExcludedPaths: []string{"vendor/**/*", "**/*.pb.go"}},
+		},
+		{
+			org:      "org",
+			repo:     "repo",
+			expected: Size{S: 10, M: 30, L: 100, Xl: 1000, Xxl: 5000, ExcludedPaths: []string{"vendor/**/*", "**/*.pb.go", "**/zz_generated.*.go"}},
+		},
+	}
+
+	for _, tc := range cases {
+		if diff := cmp.Diff(tc.expected, size.For(tc.org, tc.repo)); diff != "" {
+			t.Errorf("%s/%s: size differs from expected (-want +got):\n%s", tc.org, tc.repo, diff)
+		}
+	}
+}
+
+func TestValidateSizes(t *testing.T) {
+	cases := []struct {
+		name        string
+		size        Size
+		expectedErr bool
+	}{
+		{
+			name: "empty config",
+		},
+		{
+			name: "valid overrides",
+			size: Size{S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000, Repos: map[string]SizeOverride{"org/repo": {Xl: 600, Xxl: 2000, ExcludedPaths: []string{"vendor/**/*"}}}},
+		},
+		{
+			name: "glob with a literal bracket, which path.Match rejects",
+			size: Size{ExcludedPaths: []string{"vendor/[", "**/*.{pb,pb.gw}.go"}},
+		},
+		{
+			name:        "override makes thresholds inconsistent",
+			size:        Size{S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000, Repos: map[string]SizeOverride{"org": {Xl: 2000}}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		if err := validateSizes(tc.size); (err != nil) != tc.expectedErr {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestValidateLifecycle(t *testing.T) {
+	cases := []struct {
+		name        string
+		lifecycles  []Lifecycle
+		expectedErr bool
+	}{
+		{
+			name:       "valid config",
+			lifecycles: []Lifecycle{{Repos: []string{"org"}, DaysUntilStale: 90, StaleComment: "{{.Kind}} is stale"}},
+		},
+		{
+			name:        "negative days",
+			lifecycles:  []Lifecycle{{Repos: []string{"org"}, DaysUntilClose: -1}},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid template",
+			lifecycles:  []Lifecycle{{Repos: []string{"org"}, RottenComment: "{{.Kind"}},
+			expectedErr: true,
+		},
+		{
+			name:        "duplicated repo",
+			lifecycles:  []Lifecycle{{Repos: []string{"org/repo"}}, {Repos: []string{"org/repo"}}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		if err := validateLifecycle(tc.lifecycles); (err != nil) != tc.expectedErr {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestMilestoneForBranch(t *testing.T) {
+	c := &Configuration{
+		MilestoneApplier: map[string]BranchToMilestone{
+			"org/repo": {"release-1.0": "v1.0-custom"},
+		},
+		MilestoneRules: []MilestoneApplierRule{
+			{Repos: []string{"org"}, BranchRegexp: `^release-(\d+\.\d+)$`, Milestone: "v$1"},
+			{Repos: []string{"org/other"}, BranchRegexp: `^main$`, Milestone: "next"},
+		},
+	}
+	if err := compileRegexpsAndDurations(c); err != nil {
+		t.Fatalf("failed to compile config: %v", err)
+	}
+	cases := []struct {
+		name, repo, branch string
+		expected           string
+		expectedOK         bool
+	}{
+		{name: "branch mapping takes precedence", repo: "repo", branch: "release-1.0", expected: "v1.0-custom", expectedOK: true},
+		{name: "milestone derived from branch", repo: "repo", branch: "release-1.29", expected: "v1.29", expectedOK: true},
+		{name: "rule for other repo", repo: "other", branch: "main", expected: "next", expectedOK: true},
+		{name: "rule for other repo does not apply", repo: "repo", branch: "main"},
+		{name: "no matching rule", repo: "repo", branch: "release-next"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := c.MilestoneForBranch("org", tc.repo, tc.branch)
+			if got != tc.expected || ok != tc.expectedOK {
+				t.Errorf("expected (%q, %t), got (%q, %t)", tc.expected, tc.expectedOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestRequiredLabelsFor(t *testing.T) {
+	c := &Configuration{
+		RequiredLabels: []RequiredLabels{
+			{Repos: []string{"org"}, Labels: []string{"lgtm"}, Context: "org-labels"},
+			{Repos: []string{"org/repo"}, Branches: []string{"main"}, Labels: []string{"approved"}, Context: "main-labels"},
+		},
+	}
+	cases := []struct {
+		name, org, repo, branch string
+		expected                []string
+	}{
+		{name: "org and repo config apply on matching branch", org: "org", repo: "repo", branch: "main", expected: []string{"org-labels", "main-labels"}},
+		{name: "branch config skipped on other branch", org: "org", repo: "repo", branch: "release", expected: []string{"org-labels"}},
+		{name: "other repo in org", org: "org", repo: "other", branch: "main", expected: []string{"org-labels"}},
+		{name: "unconfigured org", org: "other", repo: "repo", branch: "main"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, r := range c.RequiredLabelsFor(tc.org, tc.repo, tc.branch) {
+				got = append(got, r.Context)
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("contexts differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateProjectsV2(t *testing.T) {
+	cases := []struct {
+		name        string
+		projects    []ProjectsV2
+		expectedErr bool
+	}{
+		{
+			name: "valid config",
+			projects: []ProjectsV2{{
+				Repos:         []string{"org"},
+				ProjectNumber: 1,
+				FieldRules: []ProjectsV2FieldRule{
+					{Field: "Status", Value: "Triaged", Label: "triage/accepted"},
+					{Field: "CI", Value: "Failing", Context: "pull-unit", State: "failure"},
+				},
+			}},
+		},
+		{
+			name:        "missing project number",
+			projects:    []ProjectsV2{{Repos: []string{"org"}}},
+			expectedErr: true,
+		},
+		{
+			name:        "rule with label and context",
+			projects:    []ProjectsV2{{ProjectNumber: 1, FieldRules: []ProjectsV2FieldRule{{Field: "Status", Value: "Todo", Label: "a", Context: "b", State: "success"}}}},
+			expectedErr: true,
+		},
+		{
+			name:        "rule with invalid state",
+			projects:    []ProjectsV2{{ProjectNumber: 1, FieldRules: []ProjectsV2FieldRule{{Field: "CI", Value: "Failing", Context: "pull-unit", State: "failed"}}}},
+			expectedErr: true,
+		},
+		{
+			name:        "rule without value",
+			projects:    []ProjectsV2{{ProjectNumber: 1, FieldRules: []ProjectsV2FieldRule{{Field: "Status", Label: "a"}}}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		if err := validateProjectsV2(tc.projects); (err != nil) != tc.expectedErr {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestValidateRequiredLabels(t *testing.T) {
+	cases := []struct {
+		name           string
+		requiredLabels []RequiredLabels
+		expectedErr    bool
+	}{
+		{
+			name:           "valid config",
+			requiredLabels: []RequiredLabels{{Repos: []string{"org"}, Labels: []string{"lgtm"}, BlockingLabels: []string{"do-not-merge/hold"}}},
+		},
+		{
+			name:           "no labels",
+			requiredLabels: []RequiredLabels{{Repos: []string{"org"}}},
+			expectedErr:    true,
+		},
+		{
+			name:           "label both required and blocking",
+			requiredLabels: []RequiredLabels{{Repos: []string{"org"}, Labels: []string{"lgtm"}, BlockingLabels: []string{"lgtm"}}},
+			expectedErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		if err := validateRequiredLabels(tc.requiredLabels); (err != nil) != tc.expectedErr {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestValidateOwners(t *testing.T) {
+	cases := []struct {
+		name        string
+		config      Owners
+		expectedErr bool
+	}{
+		{
+			name:   "no external providers",
+			config: Owners{},
+		},
+		{
+			name:   "valid external provider",
+			config: Owners{ExternalProviders: map[string]string{"org": "http://owners.default.svc.cluster.local/owners"}},
+		},
+		{
+			name:        "relative external provider",
+			config:      Owners{ExternalProviders: map[string]string{"org": "owners/api"}},
+			expectedErr: true,
+		},
+		{
+			name:   "valid identity service",
+			config: Owners{IdentityServices: map[string]string{"org": "https://sso.example.com/users"}},
+		},
+		{
+			name:        "relative identity service",
+			config:      Owners{IdentityServices: map[string]string{"org/repo": "sso/users"}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateOwners(tc.config)
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
 func TestSetDefault_Maps(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -441,6 +828,64 @@ func TestSetTriggerDefaults(t *testing.T) {
 	}
 }
 
+func TestRetestBudget(t *testing.T) {
+	tests := []struct {
+		name            string
+		budget          RetestBudget
+		expectedErr     bool
+		expectedBackoff []time.Duration
+	}{
+		{
+			name:            "defaults disable backoff",
+			budget:          RetestBudget{MaxPerPR: 3},
+			expectedBackoff: []time.Duration{0, 0, 0},
+		},
+		{
+			name:            "backoff doubles up to the maximum",
+			budget:          RetestBudget{InitialBackoff: "10m", MaxBackoff: "30m"},
+			expectedBackoff: []time.Duration{0, 10 * time.Minute, 20 * time.Minute, 30 * time.Minute, 30 * time.Minute},
+		},
+		{
+			name:        "invalid window",
+			budget:      RetestBudget{Window: "a day"},
+			expectedErr: true,
+		},
+		{
+			name:        "negative backoff",
+			budget:      RetestBudget{InitialBackoff: "-1m"},
+			expectedErr: true,
+		},
+		{
+			name:        "negative budget",
+			budget:      RetestBudget{MaxPerRepo: -1},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			budget := test.budget
+			c := &Configuration{Triggers: []Trigger{{Repos: []string{"org"}, RetestBudget: &budget}}}
+			c.setDefaults()
+			err := compileRegexpsAndDurations(c)
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", test.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if budget.WindowDuration != 24*time.Hour {
+				t.Errorf("expected window to default to 24h, got %s", budget.WindowDuration)
+			}
+			for retests, expected := range test.expectedBackoff {
+				if backoff := budget.Backoff(retests); backoff != expected {
+					t.Errorf("expected backoff after %d retests to be %s, got %s", retests, expected, backoff)
+				}
+			}
+		})
+	}
+}
+
 func TestSetCherryPickUnapprovedDefaults(t *testing.T) {
 	defaultBranchRegexp := `^release-.*$`
 	defaultComment := `This PR is not for the master branch but does not have the ` + "`cherry-pick-approved`" + `  label. Adding the ` + "`do-not-merge/cherry-pick-not-approved`" + `  label.`
@@ -1703,6 +2148,32 @@ func TestPluginsMergeFrom(t *testing.T) {
 			from: &Plugins{"org/repo-1": OrgPlugins{Plugins: []string{"wip"}}},
 			to:   &Plugins{"org/repo-1": OrgPlugins{Plugins: []string{"wip"}}},
 
+			expectedErrMsg: "found duplicate config for plugins.org/repo-1: wip enabled more than once",
+		},
+		{
+			name: "Merging disjoint plugins for the same repo succeeds",
+
+			from: &Plugins{"org": OrgPlugins{Plugins: []string{"lgtm"}}},
+			to:   &Plugins{"org": OrgPlugins{ExcludedRepos: []string{"repo"}, Plugins: []string{"wip"}}},
+
+			expected: &Plugins{
+				"org": OrgPlugins{ExcludedRepos: []string{"repo"}, Plugins: []string{"wip", "lgtm"}},
+			},
+		},
+		{
+			name: "Merging excluded repos for the same org twice fails",
+
+			from: &Plugins{"org": OrgPlugins{ExcludedRepos: []string{"repo-1"}, Plugins: []string{"lgtm"}}},
+			to:   &Plugins{"org": OrgPlugins{ExcludedRepos: []string{"repo-2"}, Plugins: []string{"wip"}}},
+
+			expectedErrMsg: "found duplicate config for plugins.org: excluded_repos set more than once",
+		},
+		{
+			name: "Merging an entry without plugins for a configured repo fails",
+
+			from: &Plugins{"org/repo-1": OrgPlugins{}},
+			to:   &Plugins{"org/repo-1": OrgPlugins{Plugins: []string{"wip"}}},
+
 			expectedErrMsg: "found duplicate config for plugins.org/repo-1",
 		},
 	}
@@ -2328,6 +2799,36 @@ func TestMergeFrom(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Repo scoped configs get merged",
+			in: Configuration{
+				Dco:            map[string]*Dco{"foo": {SkipDCOCheckForMembers: true}},
+				RequiredLabels: []RequiredLabels{{Repos: []string{"foo/bar"}}},
+			},
+			supplementalConfigs: []Configuration{{
+				Dco:              map[string]*Dco{"foo/baz": {SkipDCOCheckForCollaborators: true}},
+				MilestoneApplier: map[string]BranchToMilestone{"foo/baz": {"main": "v1.0"}},
+				ProjectsV2:       []ProjectsV2{{Repos: []string{"foo/baz"}}},
+				RepoMilestone:    map[string]Milestone{"foo/baz": {MaintainersTeam: "maintainers"}},
+				RequiredLabels:   []RequiredLabels{{Repos: []string{"foo/baz"}}},
+			}},
+			expected: Configuration{
+				Dco: map[string]*Dco{
+					"foo":     {SkipDCOCheckForMembers: true},
+					"foo/baz": {SkipDCOCheckForCollaborators: true},
+				},
+				MilestoneApplier: map[string]BranchToMilestone{"foo/baz": {"main": "v1.0"}},
+				ProjectsV2:       []ProjectsV2{{Repos: []string{"foo/baz"}}},
+				RepoMilestone:    map[string]Milestone{"foo/baz": {MaintainersTeam: "maintainers"}},
+				RequiredLabels:   []RequiredLabels{{Repos: []string{"foo/bar"}}, {Repos: []string{"foo/baz"}}},
+			},
+		},
+		{
+			name:                "Dco can't merge duplicated configs",
+			in:                  Configuration{Dco: map[string]*Dco{"foo": {SkipDCOCheckForMembers: true}}},
+			supplementalConfigs: []Configuration{{Dco: map[string]*Dco{"foo": {SkipDCOCheckForMembers: true}}}},
+			errorExpected:       true,
+		},
 		{
 			name: "ExternalPlugins cant't merge duplicated configs",
 			in: Configuration{
@@ -2356,3 +2857,429 @@ func TestMergeFrom(t *testing.T) {
 		}
 	}
 }
+
+func TestEventFilterDrops(t *testing.T) {
+	testCases := []struct {
+		name       string
+		filter     EventFilter
+		eventType  string
+		org, repo  string
+		wantDrop   bool
+		wantReason string
+	}{
+		{
+			name:      "empty filter drops nothing",
+			eventType: "push",
+			org:       "org",
+			repo:      "repo",
+		},
+		{
+			name:       "event type not in allowlist",
+			filter:     EventFilter{AllowedEventTypes: []string{"pull_request"}},
+			eventType:  "push",
+			org:        "org",
+			repo:       "repo",
+			wantDrop:   true,
+			wantReason: "event_type_not_allowed",
+		},
+		{
+			name:       "denied event type",
+			filter:     EventFilter{DeniedEventTypes: []string{"push"}},
+			eventType:  "push",
+			org:        "org",
+			repo:       "repo",
+			wantDrop:   true,
+			wantReason: "event_type_denied",
+		},
+		{
+			name:      "org in repo allowlist",
+			filter:    EventFilter{AllowedRepos: []string{"org"}},
+			eventType: "push",
+			org:       "org",
+			repo:      "repo",
+		},
+		{
+			name:       "repo not in allowlist",
+			filter:     EventFilter{AllowedRepos: []string{"org/other"}},
+			eventType:  "push",
+			org:        "org",
+			repo:       "repo",
+			wantDrop:   true,
+			wantReason: "repo_not_allowed",
+		},
+		{
+			name:       "denied repo",
+			filter:     EventFilter{DeniedRepos: []string{"org/repo"}},
+			eventType:  "push",
+			org:        "org",
+			repo:       "repo",
+			wantDrop:   true,
+			wantReason: "repo_denied",
+		},
+		{
+			name:      "denied repo does not match org level event",
+			filter:    EventFilter{DeniedRepos: []string{"org/repo"}},
+			eventType: "membership",
+			org:       "org",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			drop, reason := tc.filter.Drops(tc.eventType, tc.org, tc.repo)
+			if drop != tc.wantDrop || reason != tc.wantReason {
+				t.Errorf("expected (%t, %q), got (%t, %q)", tc.wantDrop, tc.wantReason, drop, reason)
+			}
+		})
+	}
+}
+
+func TestValidateEventForwarders(t *testing.T) {
+	testCases := []struct {
+		name       string
+		forwarders []EventForwarder
+		wantErr    bool
+	}{
+		{
+			name: "valid forwarders",
+			forwarders: []EventForwarder{
+				{Name: "http", Endpoint: "https://example.com/events"},
+				{Name: "pubsub", PubSub: &EventForwarderPubSub{Project: "p", Topic: "t"}},
+			},
+		},
+		{
+			name:       "missing name",
+			forwarders: []EventForwarder{{Endpoint: "https://example.com/events"}},
+			wantErr:    true,
+		},
+		{
+			name: "duplicated name",
+			forwarders: []EventForwarder{
+				{Name: "http", Endpoint: "https://example.com/events"},
+				{Name: "http", Endpoint: "https://example.com/other"},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "no destination",
+			forwarders: []EventForwarder{{Name: "none"}},
+			wantErr:    true,
+		},
+		{
+			name:       "both destinations",
+			forwarders: []EventForwarder{{Name: "both", Endpoint: "https://example.com", PubSub: &EventForwarderPubSub{Project: "p", Topic: "t"}}},
+			wantErr:    true,
+		},
+		{
+			name:       "endpoint is not an http URL",
+			forwarders: []EventForwarder{{Name: "grpc", Endpoint: "localhost:8080"}},
+			wantErr:    true,
+		},
+		{
+			name:       "pubsub without topic",
+			forwarders: []EventForwarder{{Name: "pubsub", PubSub: &EventForwarderPubSub{Project: "p"}}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateEventForwarders(tc.forwarders); (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateDependencyApprover(t *testing.T) {
+	testCases := []struct {
+		name      string
+		approvers []DependencyApprover
+		wantErr   bool
+	}{
+		{
+			name: "valid",
+			approvers: []DependencyApprover{
+				{Repos: []string{"org"}, Authors: []string{"dependabot[bot]"}, AllowedFiles: []string{"go.mod", "vendor/"}},
+				{Repos: []string{"other/repo"}, Authors: []string{"renovate[bot]"}, AllowedFiles: []string{"*.lock"}},
+			},
+		},
+		{
+			name:      "missing authors",
+			approvers: []DependencyApprover{{Repos: []string{"org"}, AllowedFiles: []string{"go.mod"}}},
+			wantErr:   true,
+		},
+		{
+			name:      "missing allowed files",
+			approvers: []DependencyApprover{{Repos: []string{"org"}, Authors: []string{"dependabot[bot]"}}},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid pattern",
+			approvers: []DependencyApprover{{Repos: []string{"org"}, Authors: []string{"dependabot[bot]"}, AllowedFiles: []string{"[go.mod"}}},
+			wantErr:   true,
+		},
+		{
+			name: "repo configured twice",
+			approvers: []DependencyApprover{
+				{Repos: []string{"org/repo"}, Authors: []string{"dependabot[bot]"}, AllowedFiles: []string{"go.mod"}},
+				{Repos: []string{"org/repo"}, Authors: []string{"renovate[bot]"}, AllowedFiles: []string{"go.mod"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateDependencyApprover(tc.approvers); (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDependencyApproverAllows(t *testing.T) {
+	d := DependencyApprover{AllowedFiles: []string{"go.mod", "go.sum", "*/go.mod", "vendor/"}}
+	testCases := []struct {
+		file     string
+		expected bool
+	}{
+		{file: "go.mod", expected: true},
+		{file: "hack/go.mod", expected: true},
+		{file: "hack/tools/go.mod", expected: false},
+		{file: "vendor/github.com/foo/bar.go", expected: true},
+		{file: "vendored.go", expected: false},
+		{file: "main.go", expected: false},
+	}
+	for _, tc := range testCases {
+		if actual := d.Allows(tc.file); actual != tc.expected {
+			t.Errorf("Allows(%q): expected %t, got %t", tc.file, tc.expected, actual)
+		}
+	}
+}
+
+func TestDependencyApproverFor(t *testing.T) {
+	c := &Configuration{DependencyApprover: []DependencyApprover{
+		{Repos: []string{"org"}, Authors: []string{"org-bot"}},
+		{Repos: []string{"org/repo"}, Authors: []string{"repo-bot"}},
+	}}
+	if d := c.DependencyApproverFor("org", "repo"); d == nil || d.Authors[0] != "repo-bot" {
+		t.Errorf("expected the repo configuration to take precedence, got %+v", d)
+	}
+	if d := c.DependencyApproverFor("org", "other"); d == nil || d.Authors[0] != "org-bot" {
+		t.Errorf("expected the org configuration, got %+v", d)
+	}
+	if d := c.DependencyApproverFor("other", "repo"); d != nil {
+		t.Errorf("expected no configuration, got %+v", d)
+	}
+}
+
+func TestValidateTriageRotation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		rotations []TriageRotation
+		wantErr   bool
+	}{
+		{
+			name: "valid",
+			rotations: []TriageRotation{{
+				Repos: []string{"org"},
+				Groups: []TriageGroup{
+					{Labels: []string{"area/docs"}, Members: []string{"alice"}},
+					{Members: []string{"bob"}},
+				},
+			}},
+		},
+		{
+			name:      "no groups",
+			rotations: []TriageRotation{{Repos: []string{"org"}}},
+			wantErr:   true,
+		},
+		{
+			name:      "group without members",
+			rotations: []TriageRotation{{Repos: []string{"org"}, Groups: []TriageGroup{{Labels: []string{"area/docs"}}}}},
+			wantErr:   true,
+		},
+		{
+			name: "catch-all group is not last",
+			rotations: []TriageRotation{{
+				Repos: []string{"org"},
+				Groups: []TriageGroup{
+					{Members: []string{"bob"}},
+					{Labels: []string{"area/docs"}, Members: []string{"alice"}},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "repo configured twice",
+			rotations: []TriageRotation{
+				{Repos: []string{"org/repo"}, Groups: []TriageGroup{{Members: []string{"bob"}}}},
+				{Repos: []string{"org/repo"}, Groups: []TriageGroup{{Members: []string{"alice"}}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateTriageRotation(tc.rotations); (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateTriggerMembershipService(t *testing.T) {
+	testCases := []struct {
+		name    string
+		service *MembershipService
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			service: &MembershipService{URL: "https://directory.example.com/trusted", TimeoutDuration: time.Second},
+		},
+		{
+			name:    "not an http URL",
+			service: &MembershipService{URL: "directory.example.com", TimeoutDuration: time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "zero timeout",
+			service: &MembershipService{URL: "https://directory.example.com/trusted", Timeout: "0s"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateTrigger([]Trigger{{Repos: []string{"org"}, MembershipService: tc.service}}); (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCommandRateLimitCompileDurations(t *testing.T) {
+	testCases := []struct {
+		name              string
+		limit             CommandRateLimit
+		expectedWindow    time.Duration
+		expectedIgnoreFor time.Duration
+		wantErr           bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:           "defaults",
+			limit:          CommandRateLimit{MaxCommands: 10},
+			expectedWindow: time.Minute,
+		},
+		{
+			name:              "configured",
+			limit:             CommandRateLimit{MaxCommands: 10, Window: "5m", IgnoreFor: "1h"},
+			expectedWindow:    5 * time.Minute,
+			expectedIgnoreFor: time.Hour,
+		},
+		{
+			name:    "invalid window",
+			limit:   CommandRateLimit{MaxCommands: 10, Window: "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "negative max commands",
+			limit:   CommandRateLimit{MaxCommands: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.limit.compileDurations()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if tc.limit.WindowDuration != tc.expectedWindow || tc.limit.IgnoreForDuration != tc.expectedIgnoreFor {
+				t.Errorf("expected window %s and ignore_for %s, got %s and %s", tc.expectedWindow, tc.expectedIgnoreFor, tc.limit.WindowDuration, tc.limit.IgnoreForDuration)
+			}
+		})
+	}
+}
+
+func TestValidateTitleConvention(t *testing.T) {
+	testCases := []struct {
+		name        string
+		conventions []TitleConvention
+		wantErr     bool
+	}{
+		{
+			name:        "valid",
+			conventions: []TitleConvention{{Repos: []string{"org"}, Patterns: []string{"^fix: "}}},
+		},
+		{
+			name:        "no patterns",
+			conventions: []TitleConvention{{Repos: []string{"org"}}},
+			wantErr:     true,
+		},
+		{
+			name: "repo configured twice",
+			conventions: []TitleConvention{
+				{Repos: []string{"org"}, Patterns: []string{"^fix: "}},
+				{Repos: []string{"org"}, Patterns: []string{"^feat: "}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateTitleConvention(tc.conventions); (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateShadow(t *testing.T) {
+	testCases := []struct {
+		name    string
+		shadows []Shadow
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			shadows: []Shadow{{Repos: []string{"org/config"}, ProwConfigPath: "config/prow/config.yaml", JobConfigPath: "config/jobs"}},
+		},
+		{
+			name:    "no prow config path",
+			shadows: []Shadow{{Repos: []string{"org/config"}}},
+			wantErr: true,
+		},
+		{
+			name:    "negative max jobs",
+			shadows: []Shadow{{Repos: []string{"org/config"}, ProwConfigPath: "config.yaml", MaxJobs: -1}},
+			wantErr: true,
+		},
+		{
+			name: "repo configured twice",
+			shadows: []Shadow{
+				{Repos: []string{"org/config"}, ProwConfigPath: "config.yaml"},
+				{Repos: []string{"org/config"}, ProwConfigPath: "prow.yaml"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateShadow(tc.shadows); (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
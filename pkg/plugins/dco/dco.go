@@ -50,6 +50,10 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 %s
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+%s
+
 <details>
 
 %s
@@ -71,8 +75,15 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 	configInfo := map[string]string{}
 	for _, repo := range enabledRepos {
 		opts := config.DcoFor(repo.Org, repo.Repo)
+		var info []string
 		if opts.SkipDCOCheckForMembers || opts.SkipDCOCheckForCollaborators {
-			configInfo[repo.String()] = fmt.Sprintf("The trusted GitHub organization for this repository is %q.", repo)
+			info = append(info, fmt.Sprintf("The trusted GitHub organization for this repository is %q.", repo))
+		}
+		if opts.CLALabel != "" {
+			info = append(info, fmt.Sprintf("PRs with the %q label do not need a sign-off.", opts.CLALabel))
+		}
+		if len(info) > 0 {
+			configInfo[repo.String()] = strings.Join(info, " ")
 		}
 	}
 	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
@@ -84,6 +95,7 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 				ContributingRepo:             "other-org/other-repo",
 				ContributingBranch:           "main",
 				ContributingPath:             "docs/CONTRIBUTING.md",
+				CLALabel:                     "cncf-cla: yes",
 			},
 		},
 	})
@@ -91,7 +103,7 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", pluginName)
 	}
 	pluginHelp := &pluginhelp.PluginHelp{
-		Description: "The dco plugin checks pull request commits for 'DCO sign off' and maintains the '" + dcoContextName + "' status context, as well as the 'dco' label.",
+		Description: "The dco plugin checks pull request commits for 'DCO sign off' and maintains the '" + dcoContextName + "' status context, as well as the 'dco' label. Its comment lists the commits missing the sign-off along with the git commands that add it. If a CLA label is configured, PRs carrying it do not need a sign-off.",
 		Config:      configInfo,
 		Snippet:     yamlSnippet,
 	}
@@ -128,7 +140,7 @@ func filterTrustedUsers(gc gitHubClient, l *logrus.Entry, skipDCOCheckForCollabo
 	untrustedCommits := make([]github.RepositoryCommit, 0, len(allCommits))
 
 	for _, commit := range allCommits {
-		trustedResponse, err := trigger.TrustedUser(gc, !skipDCOCheckForCollaborators, trustedApps, trustedOrg, commit.Author.Login, org, repo)
+		trustedResponse, err := trigger.TrustedUser(gc, !skipDCOCheckForCollaborators, trustedApps, nil, nil, trustedOrg, commit.Author.Login, org, repo)
 		if err != nil {
 			return nil, fmt.Errorf("Error checking is member trusted: %w", err)
 		}
@@ -184,11 +196,12 @@ func checkExistingStatus(gc gitHubClient, l *logrus.Entry, org, repo, sha string
 }
 
 // checkExistingLabels will check the provided PR for the dco sign off labels,
-// returning bool's indicating whether the 'yes' and the 'no' label are present.
-func checkExistingLabels(gc gitHubClient, l *logrus.Entry, org, repo string, number int) (hasYesLabel, hasNoLabel bool, err error) {
+// returning bool's indicating whether the 'yes', the 'no' and the CLA label
+// are present.
+func checkExistingLabels(gc gitHubClient, l *logrus.Entry, org, repo string, number int, claLabel string) (hasYesLabel, hasNoLabel, hasCLALabel bool, err error) {
 	labels, err := gc.GetIssueLabels(org, repo, number)
 	if err != nil {
-		return false, false, fmt.Errorf("error getting pull request labels: %w", err)
+		return false, false, false, fmt.Errorf("error getting pull request labels: %w", err)
 	}
 
 	for _, l := range labels {
@@ -198,9 +211,12 @@ func checkExistingLabels(gc gitHubClient, l *logrus.Entry, org, repo string, num
 		if l.Name == dcoNoLabel {
 			hasNoLabel = true
 		}
+		if claLabel != "" && l.Name == claLabel {
+			hasCLALabel = true
+		}
 	}
 
-	return hasYesLabel, hasNoLabel, nil
+	return hasYesLabel, hasNoLabel, hasCLALabel, nil
 }
 
 // takeAction will take appropriate action on the pull request according to its
@@ -273,7 +289,7 @@ func takeAction(gc gitHubClient, cp commentPruner, l *logrus.Entry, org, repo st
 		// failing commits
 		cp.PruneComments(shouldPrune(l))
 		l.Debugf("Commenting on PR to advise users of DCO check")
-		if err := gc.CreateComment(org, repo, pr.Number, fmt.Sprintf(dcoNotFoundMessage, contributingUrl, MarkdownSHAList(org, repo, commitsMissingDCO), plugins.AboutThisBot)); err != nil {
+		if err := gc.CreateComment(org, repo, pr.Number, fmt.Sprintf(dcoNotFoundMessage, contributingUrl, MarkdownSHAList(org, repo, commitsMissingDCO), remediationCommands(pr, commitsMissingDCO), plugins.AboutThisBot)); err != nil {
 			l.WithError(err).Warning("Could not create DCO not found comment.")
 		}
 	}
@@ -310,11 +326,15 @@ func handle(config plugins.Dco, gc gitHubClient, cp commentPruner, log *logrus.E
 		return err
 	}
 
-	hasYesLabel, hasNoLabel, err := checkExistingLabels(gc, l, org, repo, pr.Number)
+	hasYesLabel, hasNoLabel, hasCLALabel, err := checkExistingLabels(gc, l, org, repo, pr.Number, config.CLALabel)
 	if err != nil {
 		l.WithError(err).Infof("Error checking existing PR labels")
 		return err
 	}
+	if hasCLALabel && len(commitsMissingDCO) > 0 {
+		l.Debugf("PR has the %q label, not requiring sign-off", config.CLALabel)
+		commitsMissingDCO = nil
+	}
 
 	contributingRepo := fmt.Sprintf("%s/%s", org, repo)
 	if config.ContributingRepo != "" {
@@ -359,6 +379,21 @@ func MarkdownSHAList(org, repo string, list []github.RepositoryCommit) string {
 	return strings.Join(lines, "\n")
 }
 
+// remediationCommands returns the git commands that add the missing sign-off
+// to the commits of a PR, formatted as a markdown code block. Amending is
+// enough if only the head commit is missing the sign-off, otherwise all
+// commits from the oldest one missing it are rebased.
+func remediationCommands(pr github.PullRequest, commitsMissingDCO []github.RepositoryCommit) string {
+	var commands []string
+	if len(commitsMissingDCO) == 1 && commitsMissingDCO[0].SHA == pr.Head.SHA {
+		commands = append(commands, "git commit --amend --signoff --no-edit")
+	} else {
+		commands = append(commands, fmt.Sprintf("git rebase --signoff %s~1", commitsMissingDCO[0].SHA))
+	}
+	commands = append(commands, "git push --force-with-lease")
+	return "    " + strings.Join(commands, "\n    ")
+}
+
 // shouldPrune finds comments left by this plugin.
 func shouldPrune(log *logrus.Entry) func(github.IssueComment) bool {
 	return func(comment github.IssueComment) bool {
@@ -381,12 +416,17 @@ func handlePullRequest(config plugins.Dco, gc gitHubClient, cp commentPruner, lo
 	org := pe.Repo.Owner.Login
 	repo := pe.Repo.Name
 
-	// we only reprocess on label, unlabel, open, reopen and synchronize events
-	// this will reduce our API token usage and save processing of unrelated events
+	// we only reprocess on open, reopen and synchronize events, as well as
+	// changes to the CLA label, this will reduce our API token usage and save
+	// processing of unrelated events
 	switch pe.Action {
 	case github.PullRequestActionOpened,
 		github.PullRequestActionReopened,
 		github.PullRequestActionSynchronize:
+	case github.PullRequestActionLabeled, github.PullRequestActionUnlabeled:
+		if config.CLALabel == "" || pe.Label.Name != config.CLALabel {
+			return nil
+		}
 	default:
 		return nil
 	}
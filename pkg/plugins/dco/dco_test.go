@@ -44,6 +44,7 @@ func TestHandlePullRequest(t *testing.T) {
 		issueState       string
 		hasDCOYes        bool
 		hasDCONo         bool
+		hasCLA           bool
 		// status of the DCO github context
 		status string
 
@@ -90,6 +91,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha](https://github.com///commits/sha) not a sign off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -123,6 +129,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha](https://github.com///commits/sha) not a sign off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -155,6 +166,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha](https://github.com///commits/sha) not a sign off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -189,6 +205,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha](https://github.com///commits/sha) not signed off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -364,6 +385,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha2](https://github.com///commits/sha2) not signed off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git rebase --signoff sha2~1
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -413,6 +439,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha2](https://github.com///commits/sha2) not signed off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git rebase --signoff sha2~1
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -454,6 +485,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha](https://github.com///commits/sha) not signed off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -522,6 +558,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha](https://github.com///commits/sha) not signed off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -564,12 +605,67 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha](https://github.com///commits/sha) not signed off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
 </details>
 `,
 		},
+		{
+			name:   "should add 'yes' label & status context if commits lack sign off but the PR has the CLA label",
+			config: plugins.Dco{CLALabel: "cncf-cla: yes"},
+			pullRequestEvent: github.PullRequestEvent{
+				Action:      github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{Number: 3, Head: github.PullRequestBranch{SHA: "sha"}},
+			},
+			commits: []github.RepositoryCommit{
+				{SHA: "sha", Commit: github.GitCommit{Message: "not a sign off"}},
+			},
+			issueState: "open",
+			hasCLA:     true,
+
+			addedLabel:     fmt.Sprintf("/#3:%s", dcoYesLabel),
+			expectedStatus: github.StatusSuccess,
+		},
+		{
+			name:   "should recheck when the CLA label is added",
+			config: plugins.Dco{CLALabel: "cncf-cla: yes"},
+			pullRequestEvent: github.PullRequestEvent{
+				Action:      github.PullRequestActionLabeled,
+				Label:       github.Label{Name: "cncf-cla: yes"},
+				PullRequest: github.PullRequest{Number: 3, Head: github.PullRequestBranch{SHA: "sha"}},
+			},
+			commits: []github.RepositoryCommit{
+				{SHA: "sha", Commit: github.GitCommit{Message: "not a sign off"}},
+			},
+			issueState: "open",
+			hasDCONo:   true,
+			hasCLA:     true,
+			status:     github.StatusFailure,
+
+			addedLabel:     fmt.Sprintf("/#3:%s", dcoYesLabel),
+			removedLabel:   fmt.Sprintf("/#3:%s", dcoNoLabel),
+			expectedStatus: github.StatusSuccess,
+		},
+		{
+			name:   "should not do anything when another label is added",
+			config: plugins.Dco{CLALabel: "cncf-cla: yes"},
+			pullRequestEvent: github.PullRequestEvent{
+				Action:      github.PullRequestActionLabeled,
+				Label:       github.Label{Name: "lgtm"},
+				PullRequest: github.PullRequest{Number: 3, Head: github.PullRequestBranch{SHA: "sha"}},
+			},
+			commits: []github.RepositoryCommit{
+				{SHA: "sha", Commit: github.GitCommit{Message: "not a sign off"}},
+			},
+			issueState: "open",
+			hasCLA:     true,
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -591,6 +687,9 @@ Instructions for interacting with me using PR comments are available [here](http
 			if tc.hasDCONo {
 				fc.IssueLabelsAdded = append(fc.IssueLabelsAdded, fmt.Sprintf("/#3:%s", dcoNoLabel))
 			}
+			if tc.hasCLA {
+				fc.IssueLabelsAdded = append(fc.IssueLabelsAdded, "/#3:cncf-cla: yes")
+			}
 			combinedStatus := &github.CombinedStatus{
 				Statuses: []github.Status{},
 			}
@@ -738,6 +837,11 @@ Full details of the Developer Certificate of Origin can be found at [developerce
 
 - [sha](https://github.com///commits/sha) not a sign off
 
+**To sign off these commits**, run the following commands in your local branch of the PR:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
 <details>
 
 Instructions for interacting with me using PR comments are available [here](https://git.k8s.io/community/contributors/guide/pull-requests.md).  If you have questions or suggestions related to my behavior, please file an issue against the [kubernetes/test-infra](https://github.com/kubernetes/test-infra/issues/new?title=Prow%20issue:) repository. I understand the commands that are listed [here](https://go.k8s.io/bot-commands).
@@ -869,3 +973,35 @@ Instructions for interacting with me using PR comments are available [here](http
 		})
 	}
 }
+
+func TestRemediationCommands(t *testing.T) {
+	pr := github.PullRequest{Head: github.PullRequestBranch{SHA: "head"}}
+	testcases := []struct {
+		name     string
+		missing  []github.RepositoryCommit
+		expected string
+	}{
+		{
+			name:     "only the head commit is missing sign off",
+			missing:  []github.RepositoryCommit{{SHA: "head"}},
+			expected: "    git commit --amend --signoff --no-edit\n    git push --force-with-lease",
+		},
+		{
+			name:     "an earlier commit is missing sign off",
+			missing:  []github.RepositoryCommit{{SHA: "first"}},
+			expected: "    git rebase --signoff first~1\n    git push --force-with-lease",
+		},
+		{
+			name:     "several commits are missing sign off",
+			missing:  []github.RepositoryCommit{{SHA: "first"}, {SHA: "head"}},
+			expected: "    git rebase --signoff first~1\n    git push --force-with-lease",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := remediationCommands(pr, tc.missing); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
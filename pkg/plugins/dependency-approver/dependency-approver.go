@@ -0,0 +1,343 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dependencyapprover implements the dependency-approver plugin, which
+// approves PRs of dependency update bots that only change allowed files once
+// all their required jobs passed.
+package dependencyapprover
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/pluginhelp"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	// PluginName defines this plugin's registered name.
+	PluginName = "dependency-approver"
+
+	auditMarker = "<!-- dependency-approver audit -->"
+)
+
+func init() {
+	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
+	plugins.RegisterStatusEventHandler(PluginName, handleStatusEvent, helpProvider)
+}
+
+func helpProvider(cfg *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	// The {WhoCanUse, Usage, Examples} fields are omitted because this plugin cannot be triggered manually.
+	approverConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		d := cfg.DependencyApproverFor(repo.Org, repo.Repo)
+		if d == nil {
+			continue
+		}
+		approverConfig[repo.String()] = fmt.Sprintf("PRs of %s that only change %s are labeled with %s once all required jobs passed.",
+			strings.Join(d.Authors, ", "), strings.Join(d.AllowedFiles, ", "), strings.Join(d.Labels, ", "))
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		DependencyApprover: []plugins.DependencyApprover{
+			{
+				Repos:        []string{"ORGANIZATION", "ORGANIZATION/REPOSITORY"},
+				Authors:      []string{"dependabot[bot]", "renovate[bot]"},
+				AllowedFiles: []string{"go.mod", "go.sum", "vendor/"},
+				Labels:       []string{labels.LGTM, labels.Approved},
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
+	return &pluginhelp.PluginHelp{
+			Description: fmt.Sprintf("The dependency-approver plugin labels PRs of dependency update bots with lgtm and approved when they only change allowed files and all their required jobs passed. "+
+				"Every approval is recorded in a PR comment that lists the checked files and jobs together with a digest of the record. "+
+				"The %q label marks such PRs, so that the approve plugin keeps their approval. It is removed together with the approval labels when a later push no longer satisfies the policy.", labels.DependencyAutoApproved),
+			Config:  approverConfig,
+			Snippet: yamlSnippet,
+		},
+		nil
+}
+
+type githubClient interface {
+	AddLabel(org, repo string, number int, label string) error
+	RemoveLabel(org, repo string, number int, label string) error
+	CreateComment(org, repo string, number int, comment string) error
+	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
+	GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error)
+	GetRef(org, repo, ref string) (string, error)
+	FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error)
+}
+
+// presubmitGetter returns the presubmits of a PR.
+type presubmitGetter func(org, repo, branch string, baseSHA, headSHA config.RefGetter) ([]config.Presubmit, error)
+
+func newPresubmitGetter(pc plugins.Agent) presubmitGetter {
+	return func(org, repo, branch string, baseSHA, headSHA config.RefGetter) ([]config.Presubmit, error) {
+		return pc.GetPresubmits(org+"/"+repo, branch, baseSHA, headSHA)
+	}
+}
+
+func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
+	switch pre.Action {
+	case github.PullRequestActionOpened, github.PullRequestActionReopened, github.PullRequestActionSynchronize, github.PullRequestActionReadyForReview:
+	default:
+		return nil
+	}
+	org, repo := pre.Repo.Owner.Login, pre.Repo.Name
+	d := pc.PluginConfig.DependencyApproverFor(org, repo)
+	if d == nil {
+		return nil
+	}
+	return handle(pc.GitHubClient, pc.Logger, newPresubmitGetter(pc), d, org, repo, pre.Number)
+}
+
+func handleStatusEvent(pc plugins.Agent, se github.StatusEvent) error {
+	if se.State != github.StatusSuccess {
+		return nil
+	}
+	org, repo := se.Repo.Owner.Login, se.Repo.Name
+	d := pc.PluginConfig.DependencyApproverFor(org, repo)
+	if d == nil {
+		return nil
+	}
+	return handleStatus(pc.GitHubClient, pc.Logger, newPresubmitGetter(pc), d, org, repo, se)
+}
+
+func handleStatus(ghc githubClient, log *logrus.Entry, getPresubmits presubmitGetter, d *plugins.DependencyApprover, org, repo string, se github.StatusEvent) error {
+	prs, err := ghc.FindIssuesWithOrg(org, searchQuery(org, repo, se.SHA, d.Authors), "", false)
+	if err != nil {
+		return fmt.Errorf("failed to search for PRs with head %s: %w", se.SHA, err)
+	}
+	var errs []error
+	for _, pr := range prs {
+		if err := handle(ghc, log.WithField("pr", pr.Number), getPresubmits, d, org, repo, pr.Number); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// searchQuery returns the query for the open PRs of the authors with the given
+// head SHA. The search API only finds PRs of GitHub Apps with the app/ prefix.
+func searchQuery(org, repo, sha string, authors []string) string {
+	var qualifiers []string
+	for _, author := range authors {
+		if name, isBot := strings.CutSuffix(author, "[bot]"); isBot {
+			qualifiers = append(qualifiers, "author:app/"+name)
+		} else {
+			qualifiers = append(qualifiers, "author:"+author)
+		}
+	}
+	return fmt.Sprintf("is:pr is:open repo:%s/%s %s %s", org, repo, sha, strings.Join(qualifiers, " "))
+}
+
+// auditRecord is the record of an automatic approval.
+type auditRecord struct {
+	Org      string   `json:"org"`
+	Repo     string   `json:"repo"`
+	Number   int      `json:"number"`
+	Author   string   `json:"author"`
+	HeadSHA  string   `json:"head_sha"`
+	Files    []string `json:"files"`
+	Contexts []string `json:"contexts"`
+	Labels   []string `json:"labels"`
+}
+
+// digest returns the SHA-256 of the serialized record. The digest is not a
+// signature: it is logged along with the approval, so that the record in the
+// audit comment can be checked against the logs of hook.
+func (r auditRecord) digest() (string, []byte, error) {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), raw, nil
+}
+
+func handle(ghc githubClient, log *logrus.Entry, getPresubmits presubmitGetter, d *plugins.DependencyApprover, org, repo string, number int) error {
+	pr, err := ghc.GetPullRequest(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to get PR: %w", err)
+	}
+	if pr.State != github.PullRequestStateOpen || pr.Draft || !sets.New[string](d.Authors...).Has(pr.User.Login) {
+		return nil
+	}
+	log = log.WithFields(logrus.Fields{"author": pr.User.Login, "head-sha": pr.Head.SHA})
+
+	issueLabels, err := ghc.GetIssueLabels(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to get labels: %w", err)
+	}
+	current := sets.New[string]()
+	for _, l := range issueLabels {
+		current.Insert(l.Name)
+	}
+
+	changes, err := ghc.GetPullRequestChanges(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to get changes: %w", err)
+	}
+	var files, disallowed []string
+	for _, change := range changes {
+		files = append(files, change.Filename)
+		if !d.Allows(change.Filename) {
+			disallowed = append(disallowed, change.Filename)
+		}
+		// A rename also changes the previous path.
+		if change.PreviousFilename != "" && !d.Allows(change.PreviousFilename) {
+			disallowed = append(disallowed, change.PreviousFilename)
+		}
+	}
+	if len(disallowed) > 0 {
+		log.WithField("disallowed-files", disallowed).Debug("PR does not satisfy the dependency approval policy.")
+		return revoke(ghc, log, d, org, repo, number, current, "it no longer satisfies the dependency approval policy")
+	}
+
+	contexts, passed, err := requiredContexts(ghc, getPresubmits, org, repo, pr, files)
+	if err != nil {
+		return err
+	}
+	if !passed {
+		log.Debug("Required jobs did not pass yet.")
+		// An approval of an earlier head doesn't hold for a new push, which is
+		// approved again with a new audit record once its jobs passed.
+		return revoke(ghc, log, d, org, repo, number, current, "its required jobs did not pass on the latest push yet")
+	}
+
+	toAdd := append([]string{labels.DependencyAutoApproved}, d.Labels...)
+	var missing []string
+	for _, label := range toAdd {
+		if !current.Has(label) {
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	record := auditRecord{Org: org, Repo: repo, Number: number, Author: pr.User.Login, HeadSHA: pr.Head.SHA, Files: files, Contexts: contexts, Labels: d.Labels}
+	digest, raw, err := record.digest()
+	if err != nil {
+		return fmt.Errorf("failed to serialize audit record: %w", err)
+	}
+	log.WithFields(logrus.Fields{"files": files, "contexts": contexts, "digest": digest}).Info("Approving dependency update.")
+	// The marker label goes first, so that the approve plugin never sees the
+	// approved label without it.
+	var errs []error
+	for _, label := range missing {
+		if err := ghc.AddLabel(org, repo, number, label); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add %s label: %w", label, err))
+		}
+	}
+	comment := fmt.Sprintf("%s\nThis PR of `%s` was approved automatically by the dependency approval policy at %s: it only changes allowed files and the required jobs %s passed.\n\n"+
+		"<details>\n<summary>Audit record (sha256 %s)</summary>\n\n```json\n%s\n```\n</details>",
+		auditMarker, pr.User.Login, pr.Head.SHA, formatContexts(contexts), digest, string(raw))
+	if err := ghc.CreateComment(org, repo, number, comment); err != nil {
+		errs = append(errs, fmt.Errorf("failed to create audit comment: %w", err))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// requiredContexts returns the contexts of the required presubmits that run
+// for the PR and whether all of them passed on the head of the PR. PRs without
+// required presubmits are never approved.
+func requiredContexts(ghc githubClient, getPresubmits presubmitGetter, org, repo string, pr *github.PullRequest, files []string) ([]string, bool, error) {
+	refGetter := config.NewRefGetterForGitHubPullRequest(ghc, org, repo, pr.Number)
+	presubmits, err := getPresubmits(org, repo, pr.Base.Ref, refGetter.BaseSHA, refGetter.HeadSHA)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get presubmits: %w", err)
+	}
+	changes := func() ([]string, error) { return files, nil }
+	var contexts []string
+	for _, ps := range presubmits {
+		if !ps.ContextRequired() {
+			continue
+		}
+		shouldRun, err := ps.ShouldRun(pr.Base.Ref, changes, false, false)
+		if err != nil {
+			return nil, false, err
+		}
+		if shouldRun {
+			contexts = append(contexts, ps.Context)
+		}
+	}
+	if len(contexts) == 0 {
+		return nil, false, nil
+	}
+	sort.Strings(contexts)
+
+	combined, err := ghc.GetCombinedStatus(org, repo, pr.Head.SHA)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get combined status: %w", err)
+	}
+	succeeded := sets.New[string]()
+	for _, status := range combined.Statuses {
+		if status.State == github.StatusSuccess {
+			succeeded.Insert(status.Context)
+		}
+	}
+	return contexts, succeeded.HasAll(contexts...), nil
+}
+
+// revoke removes the labels of an earlier automatic approval from a PR that
+// does not satisfy the policy for the given reason.
+func revoke(ghc githubClient, log *logrus.Entry, d *plugins.DependencyApprover, org, repo string, number int, current sets.Set[string], reason string) error {
+	if !current.Has(labels.DependencyAutoApproved) {
+		return nil
+	}
+	log.WithField("reason", reason).Info("Revoking automatic dependency approval.")
+	var errs []error
+	// Remove the marker label last, so that the approve plugin never sees the
+	// approved label without it.
+	for _, label := range append(append([]string{}, d.Labels...), labels.DependencyAutoApproved) {
+		if !current.Has(label) {
+			continue
+		}
+		if err := ghc.RemoveLabel(org, repo, number, label); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove %s label: %w", label, err))
+		}
+	}
+	if len(errs) == 0 {
+		if err := ghc.CreateComment(org, repo, number, fmt.Sprintf("The automatic dependency approval of this PR was revoked because %s.", reason)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func formatContexts(contexts []string) string {
+	var quoted []string
+	for _, c := range contexts {
+		quoted = append(quoted, "`"+c+"`")
+	}
+	return strings.Join(quoted, ", ")
+}
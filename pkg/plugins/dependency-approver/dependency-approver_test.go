@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependencyapprover
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestHandle(t *testing.T) {
+	presubmits := []config.Presubmit{
+		{
+			JobBase:   config.JobBase{Name: "unit"},
+			AlwaysRun: true,
+			Reporter:  config.Reporter{Context: "unit"},
+		},
+		{
+			JobBase:   config.JobBase{Name: "lint"},
+			AlwaysRun: true,
+			Optional:  true,
+			Reporter:  config.Reporter{Context: "lint"},
+		},
+		{
+			JobBase:             config.JobBase{Name: "docs"},
+			RegexpChangeMatcher: config.RegexpChangeMatcher{RunIfChanged: "^docs/"},
+			Reporter:            config.Reporter{Context: "docs"},
+		},
+	}
+	if err := config.SetPresubmitRegexes(presubmits); err != nil {
+		t.Fatalf("failed to compile presubmit regexes: %v", err)
+	}
+	getPresubmits := func(org, repo, branch string, baseSHA, headSHA config.RefGetter) ([]config.Presubmit, error) {
+		return presubmits, nil
+	}
+
+	testCases := []struct {
+		name           string
+		author         string
+		draft          bool
+		files          []string
+		statuses       []github.Status
+		existingLabels []string
+		expectedAdded  []string
+		expectedRemove []string
+		expectComment  string
+	}{
+		{
+			name:          "dependency update with passing jobs is approved",
+			author:        "dependabot[bot]",
+			files:         []string{"go.mod", "go.sum", "vendor/foo/foo.go"},
+			statuses:      []github.Status{{Context: "unit", State: github.StatusSuccess}, {Context: "lint", State: github.StatusFailure}},
+			expectedAdded: []string{labels.DependencyAutoApproved, labels.LGTM, labels.Approved},
+			expectComment: "the required jobs `unit` passed",
+		},
+		{
+			name:     "required job is pending",
+			author:   "dependabot[bot]",
+			files:    []string{"go.mod"},
+			statuses: []github.Status{{Context: "unit", State: github.StatusPending}},
+		},
+		{
+			name:     "other author",
+			author:   "someone",
+			files:    []string{"go.mod"},
+			statuses: []github.Status{{Context: "unit", State: github.StatusSuccess}},
+		},
+		{
+			name:     "draft PR",
+			author:   "dependabot[bot]",
+			draft:    true,
+			files:    []string{"go.mod"},
+			statuses: []github.Status{{Context: "unit", State: github.StatusSuccess}},
+		},
+		{
+			name:     "disallowed file",
+			author:   "dependabot[bot]",
+			files:    []string{"go.mod", "main.go"},
+			statuses: []github.Status{{Context: "unit", State: github.StatusSuccess}},
+		},
+		{
+			name:           "disallowed file revokes an earlier approval",
+			author:         "dependabot[bot]",
+			files:          []string{"go.mod", "main.go"},
+			statuses:       []github.Status{{Context: "unit", State: github.StatusSuccess}},
+			existingLabels: []string{labels.DependencyAutoApproved, labels.LGTM, labels.Approved},
+			expectedRemove: []string{labels.LGTM, labels.Approved, labels.DependencyAutoApproved},
+			expectComment:  "was revoked",
+		},
+		{
+			name:           "pending required job on a new push revokes an earlier approval",
+			author:         "dependabot[bot]",
+			files:          []string{"go.mod"},
+			statuses:       []github.Status{{Context: "unit", State: github.StatusPending}},
+			existingLabels: []string{labels.DependencyAutoApproved, labels.LGTM, labels.Approved},
+			expectedRemove: []string{labels.LGTM, labels.Approved, labels.DependencyAutoApproved},
+			expectComment:  "did not pass on the latest push yet",
+		},
+		{
+			name:           "already approved",
+			author:         "dependabot[bot]",
+			files:          []string{"go.mod"},
+			statuses:       []github.Status{{Context: "unit", State: github.StatusSuccess}},
+			existingLabels: []string{labels.DependencyAutoApproved, labels.LGTM, labels.Approved},
+		},
+		{
+			name:          "conditionally required job must pass too",
+			author:        "dependabot[bot]",
+			files:         []string{"go.mod", "docs/go.mod"},
+			statuses:      []github.Status{{Context: "unit", State: github.StatusSuccess}},
+			expectedAdded: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegithub.NewFakeClient()
+			fc.PullRequests = map[int]*github.PullRequest{
+				1: {
+					Number: 1,
+					State:  github.PullRequestStateOpen,
+					Draft:  tc.draft,
+					User:   github.User{Login: tc.author},
+					Head:   github.PullRequestBranch{SHA: "head"},
+					Base:   github.PullRequestBranch{Ref: "main"},
+				},
+			}
+			var changes []github.PullRequestChange
+			for _, f := range tc.files {
+				changes = append(changes, github.PullRequestChange{Filename: f})
+			}
+			fc.PullRequestChanges = map[int][]github.PullRequestChange{1: changes}
+			fc.CombinedStatuses = map[string]*github.CombinedStatus{"head": {Statuses: tc.statuses}}
+			for _, l := range tc.existingLabels {
+				fc.IssueLabelsExisting = append(fc.IssueLabelsExisting, "org/repo#1:"+l)
+			}
+			d := &plugins.DependencyApprover{
+				Authors:      []string{"dependabot[bot]"},
+				AllowedFiles: []string{"go.mod", "go.sum", "*/go.mod", "vendor/"},
+				Labels:       []string{labels.LGTM, labels.Approved},
+			}
+
+			if err := handle(fc, logrus.WithField("test", tc.name), getPresubmits, d, "org", "repo", 1); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var expectedAdded, expectedRemoved []string
+			for _, l := range tc.expectedAdded {
+				expectedAdded = append(expectedAdded, "org/repo#1:"+l)
+			}
+			for _, l := range tc.expectedRemove {
+				expectedRemoved = append(expectedRemoved, "org/repo#1:"+l)
+			}
+			if diff := cmp.Diff(expectedAdded, fc.IssueLabelsAdded); diff != "" {
+				t.Errorf("added labels differ (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(expectedRemoved, fc.IssueLabelsRemoved); diff != "" {
+				t.Errorf("removed labels differ (-want +got):\n%s", diff)
+			}
+			switch {
+			case tc.expectComment == "" && len(fc.IssueCommentsAdded) > 0:
+				t.Errorf("expected no comment, got %v", fc.IssueCommentsAdded)
+			case tc.expectComment != "" && (len(fc.IssueCommentsAdded) != 1 || !strings.Contains(fc.IssueCommentsAdded[0], tc.expectComment)):
+				t.Errorf("expected a comment containing %q, got %v", tc.expectComment, fc.IssueCommentsAdded)
+			}
+		})
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	got := searchQuery("org", "repo", "head", []string{"dependabot[bot]", "someone"})
+	expected := "is:pr is:open repo:org/repo head author:app/dependabot author:someone"
+	if got != expected {
+		t.Errorf("expected query %q, got %q", expected, got)
+	}
+}
+
+func TestAuditRecordDigest(t *testing.T) {
+	record := auditRecord{Org: "org", Repo: "repo", Number: 1, Author: "dependabot[bot]", HeadSHA: "head", Files: []string{"go.mod"}, Contexts: []string{"unit"}}
+	digest, raw, err := record.digest()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, _, _ := auditRecord{Org: "org", Repo: "repo", Number: 1, Author: "dependabot[bot]", HeadSHA: "other", Files: []string{"go.mod"}, Contexts: []string{"unit"}}.digest()
+	if digest == other {
+		t.Error("expected records with different head SHAs to have different digests")
+	}
+	if !strings.Contains(string(raw), `"head_sha":"head"`) {
+		t.Errorf("expected the serialized record to contain the head SHA, got %s", raw)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
@@ -61,20 +62,65 @@ func extractCandidatesFromText(t string) []string {
 
 func init() {
 	plugins.RegisterGenericCommentHandler(PluginName, handleGenericComment, helpProvider)
+	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
 }
 
 func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
-	// The Config field is omitted because this plugin is not configurable.
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		Jira: &plugins.Jira{
+			DisabledJiraProjects: []string{"enterprise"},
+			Projects: map[string]plugins.JiraProject{
+				"ABC": {
+					FixVersionLabelPrefix: "jira/fix-version/",
+					TransitionOnMerge:     "MODIFIED",
+				},
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
 	pluginHelp := &pluginhelp.PluginHelp{
-		Description: "The Jira plugin links Pull Requests and Issues to Jira issues",
+		Description: "The Jira plugin links Pull Requests and Issues to Jira issues. For configured Jira projects, it can also sync the fix versions of referenced issues to labels on the PR and transition referenced issues when the PR merges.",
+		Config: map[string]string{
+			"": configString(config.Jira),
+		},
+		Snippet: yamlSnippet,
 	}
 	return pluginHelp, nil
 }
 
+func configString(cfg *plugins.Jira) string {
+	if cfg == nil || len(cfg.Projects) == 0 {
+		return "No Jira projects are configured."
+	}
+	var keys []string
+	for key := range cfg.Projects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var lines []string
+	for _, key := range keys {
+		project := cfg.Projects[key]
+		line := fmt.Sprintf("Jira project %s:", key)
+		if project.FixVersionLabelPrefix != "" {
+			line += fmt.Sprintf(" fix versions are synced to labels prefixed with %q.", project.FixVersionLabelPrefix)
+		}
+		if project.TransitionOnMerge != "" {
+			line += fmt.Sprintf(" issues are transitioned with %q when the PR merges.", project.TransitionOnMerge)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "<br>")
+}
+
 type githubClient interface {
 	EditComment(org, repo string, id int, comment string) error
 	GetIssue(org, repo string, number int) (*github.Issue, error)
 	EditIssue(org, repo string, number int, issue *github.Issue) (*github.Issue, error)
+	AddLabel(org, repo string, number int, label string) error
+	RemoveLabel(org, repo string, number int, label string) error
+	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
 }
 
 func handleGenericComment(pc plugins.Agent, e github.GenericCommentEvent) error {
@@ -82,21 +128,29 @@ func handleGenericComment(pc plugins.Agent, e github.GenericCommentEvent) error
 }
 
 func handle(jc jiraclient.Client, ghc githubClient, cfg *plugins.Jira, log *logrus.Entry, e *github.GenericCommentEvent) error {
-	if projectCache.entryCount() == 0 {
-		projects, err := jc.ListProjects()
-		if err != nil {
-			return fmt.Errorf("failed to list jira projects: %w", err)
-		}
-		var projectNames []string
-		for _, project := range *projects {
-			projectNames = append(projectNames, strings.ToLower(project.Key))
-		}
-		projectCache.insert(projectNames...)
+	if err := ensureProjectCache(jc); err != nil {
+		return err
 	}
 
 	return handleWithProjectCache(jc, ghc, cfg, log, e, projectCache)
 }
 
+func ensureProjectCache(jc jiraclient.Client) error {
+	if projectCache.entryCount() != 0 {
+		return nil
+	}
+	projects, err := jc.ListProjects()
+	if err != nil {
+		return fmt.Errorf("failed to list jira projects: %w", err)
+	}
+	var projectNames []string
+	for _, project := range *projects {
+		projectNames = append(projectNames, strings.ToLower(project.Key))
+	}
+	projectCache.insert(projectNames...)
+	return nil
+}
+
 func handleWithProjectCache(jc jiraclient.Client, ghc githubClient, cfg *plugins.Jira, log *logrus.Entry, e *github.GenericCommentEvent, projectCache *threadsafeSet) error {
 	// Nothing to do on deletion
 	if e.Action == github.GenericCommentActionDeleted {
@@ -108,24 +162,38 @@ func handleWithProjectCache(jc jiraclient.Client, ghc githubClient, cfg *plugins
 	issueCandidateNames := extractCandidatesFromText(e.Body)
 	issueCandidateNames = append(issueCandidateNames, extractCandidatesFromText(e.IssueTitle)...)
 	issueCandidateNames = filterOutDisabledJiraProjects(issueCandidateNames, cfg)
-	if len(issueCandidateNames) == 0 {
-		return nil
-	}
 
 	var errs []error
 	referencedIssues := sets.Set[string]{}
+	issues := map[string]*jira.Issue{}
 	for _, match := range issueCandidateNames {
 		if referencedIssues.Has(match) {
 			continue
 		}
-		_, err := jc.GetIssue(match)
+		issue, err := jc.GetIssue(match)
 		if err != nil {
 			if !jiraclient.IsNotFound(err) {
 				errs = append(errs, fmt.Errorf("failed to get issue %s: %w", match, err))
+				// The fix versions of the issue are unknown, which keeps
+				// the fix version labels of its project.
+				issues[match] = nil
 			}
 			continue
 		}
 		referencedIssues.Insert(match)
+		issues[match] = issue
+	}
+
+	// Only the title and description of a PR determine its fix versions,
+	// so ignore comments and reviews. PRs that no longer reference issues
+	// are synced too, so that their stale labels are removed.
+	if e.IsPR && e.CommentID == nil && e.Body == e.IssueBody && len(cfg.FixVersionLabelPrefixes()) > 0 {
+		if err := syncFixVersionLabels(ghc, cfg, log, e.Repo.Owner.Login, e.Repo.Name, e.Number, issues); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync fix version labels: %w", err))
+		}
+	}
+	if len(issueCandidateNames) == 0 {
+		return utilerrors.NewAggregate(errs)
 	}
 
 	wg := &sync.WaitGroup{}
@@ -147,6 +215,106 @@ func handleWithProjectCache(jc jiraclient.Client, ghc githubClient, cfg *plugins
 	return utilerrors.NewAggregate(errs)
 }
 
+// syncFixVersionLabels ensures that the PR has a label for every fix version
+// of the referenced issues of projects that have fix version labels
+// configured, and no other labels with the configured prefixes. Issues that
+// could not be looked up are nil, and the labels of their projects are kept.
+func syncFixVersionLabels(ghc githubClient, cfg *plugins.Jira, log *logrus.Entry, org, repo string, number int, issues map[string]*jira.Issue) error {
+	expected := sets.New[string]()
+	keptPrefixes := sets.New[string]()
+	for name, issue := range issues {
+		project, ok := cfg.ProjectFor(name)
+		if !ok || project.FixVersionLabelPrefix == "" {
+			continue
+		}
+		if issue == nil {
+			keptPrefixes.Insert(project.FixVersionLabelPrefix)
+			continue
+		}
+		if issue.Fields == nil {
+			continue
+		}
+		for _, fixVersion := range issue.Fields.FixVersions {
+			if fixVersion != nil && fixVersion.Name != "" {
+				expected.Insert(project.FixVersionLabelPrefix + fixVersion.Name)
+			}
+		}
+	}
+
+	labels, err := ghc.GetIssueLabels(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to get labels: %w", err)
+	}
+	current := sets.New[string]()
+	for _, label := range labels {
+		current.Insert(label.Name)
+	}
+
+	var errs []error
+	for _, label := range sets.List(expected.Difference(current)) {
+		if err := ghc.AddLabel(org, repo, number, label); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add label %s: %w", label, err))
+			continue
+		}
+		log.WithField("label", label).Info("Added fix version label")
+	}
+	for _, label := range sets.List(current.Difference(expected)) {
+		for _, prefix := range cfg.FixVersionLabelPrefixes() {
+			if !strings.HasPrefix(label, prefix) {
+				continue
+			}
+			if keptPrefixes.Has(prefix) {
+				break
+			}
+			if err := ghc.RemoveLabel(org, repo, number, label); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove label %s: %w", label, err))
+			} else {
+				log.WithField("label", label).Info("Removed fix version label")
+			}
+			break
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func handlePullRequest(pc plugins.Agent, pe github.PullRequestEvent) error {
+	cfg := pc.PluginConfig.Jira
+	if pe.Action != github.PullRequestActionClosed || !pe.PullRequest.Merged || cfg == nil || len(cfg.Projects) == 0 {
+		return nil
+	}
+	if err := ensureProjectCache(pc.JiraClient); err != nil {
+		return err
+	}
+	return transitionOnMerge(&projectCachingJiraClient{pc.JiraClient, projectCache}, cfg, pc.Logger, &pe.PullRequest)
+}
+
+// transitionOnMerge applies the configured transitions to the issues
+// referenced in the title or description of a merged PR.
+func transitionOnMerge(jc jiraclient.Client, cfg *plugins.Jira, log *logrus.Entry, pr *github.PullRequest) error {
+	candidates := append(extractCandidatesFromText(pr.Title), extractCandidatesFromText(pr.Body)...)
+	candidates = filterOutDisabledJiraProjects(candidates, cfg)
+
+	var errs []error
+	for _, name := range sets.List(sets.New[string](candidates...)) {
+		project, ok := cfg.ProjectFor(name)
+		if !ok || project.TransitionOnMerge == "" {
+			continue
+		}
+		if _, err := jc.GetIssue(name); err != nil {
+			if !jiraclient.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to get issue %s: %w", name, err))
+			}
+			continue
+		}
+		if err := jc.UpdateStatus(name, project.TransitionOnMerge); err != nil {
+			errs = append(errs, fmt.Errorf("failed to transition issue %s: %w", name, err))
+			continue
+		}
+		log.WithFields(logrus.Fields{"Issue": name, "transition": project.TransitionOnMerge}).Info("Transitioned jira issue on merge")
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func updateComment(e *github.GenericCommentEvent, validIssues []string, jiraBaseURL string, ghc githubClient) error {
 	withLinks := insertLinksIntoComment(e.Body, validIssues, jiraBaseURL)
 	if withLinks == e.Body {
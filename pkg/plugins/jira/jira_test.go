@@ -563,3 +563,153 @@ func TestFilterOutDisabledJiraProjects(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncFixVersionLabels(t *testing.T) {
+	t.Parallel()
+	cfg := &plugins.Jira{
+		Projects: map[string]plugins.JiraProject{
+			"abc": {FixVersionLabelPrefix: "jira/fix-version/"},
+			"DEF": {TransitionOnMerge: "MODIFIED"},
+		},
+	}
+	testCases := []struct {
+		name            string
+		event           github.GenericCommentEvent
+		existingLabels  []string
+		getIssueErrors  map[string]error
+		expectedAdded   []string
+		expectedRemoved []string
+		expectedErr     bool
+	}{
+		{
+			name: "Fix versions of issues referenced in the PR description are added",
+			event: github.GenericCommentEvent{
+				IsPR:       true,
+				IssueTitle: "ABC-123: Fix the thing",
+				Body:       "Also fixes DEF-1",
+				IssueBody:  "Also fixes DEF-1",
+			},
+			expectedAdded: []string{"org/repo#3:jira/fix-version/4.12", "org/repo#3:jira/fix-version/4.13"},
+		},
+		{
+			name: "Stale fix version labels are removed",
+			event: github.GenericCommentEvent{
+				IsPR:       true,
+				IssueTitle: "ABC-123: Fix the thing",
+			},
+			existingLabels:  []string{"org/repo#3:jira/fix-version/4.12", "org/repo#3:jira/fix-version/4.11", "org/repo#3:lgtm"},
+			expectedAdded:   []string{"org/repo#3:jira/fix-version/4.13"},
+			expectedRemoved: []string{"org/repo#3:jira/fix-version/4.11"},
+		},
+		{
+			name: "Comments don't sync fix version labels",
+			event: github.GenericCommentEvent{
+				IsPR:       true,
+				CommentID:  intPtr(1),
+				IssueTitle: "Fix the thing",
+				Body:       "This is ABC-123",
+			},
+			existingLabels: []string{"org/repo#3:jira/fix-version/4.11"},
+		},
+		{
+			name: "Stale fix version labels are removed when the PR no longer references issues",
+			event: github.GenericCommentEvent{
+				IsPR:       true,
+				IssueTitle: "Fix the thing",
+			},
+			existingLabels:  []string{"org/repo#3:jira/fix-version/4.11", "org/repo#3:lgtm"},
+			expectedRemoved: []string{"org/repo#3:jira/fix-version/4.11"},
+		},
+		{
+			name: "Fix version labels are kept when the issue can't be looked up",
+			event: github.GenericCommentEvent{
+				IsPR:       true,
+				IssueTitle: "ABC-123: Fix the thing",
+			},
+			existingLabels: []string{"org/repo#3:jira/fix-version/4.11"},
+			getIssueErrors: map[string]error{"ABC-123": errors.New("injected error")},
+			expectedErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jiraClient := &fakejira.FakeClient{
+				Issues: []*jira.Issue{
+					{ID: "ABC-123", Fields: &jira.IssueFields{FixVersions: []*jira.FixVersion{{Name: "4.12"}, {Name: "4.13"}}}},
+					{ID: "DEF-1", Fields: &jira.IssueFields{FixVersions: []*jira.FixVersion{{Name: "1.0"}}}},
+				},
+				GetIssueError: tc.getIssueErrors,
+			}
+			githubClient := fakegithub.NewFakeClient()
+			githubClient.IssueLabelsExisting = tc.existingLabels
+			githubClient.Issues = map[int]*github.Issue{3: {Number: 3, Body: tc.event.IssueBody}}
+			tc.event.Repo = github.Repo{FullName: "org/repo", Owner: github.User{Login: "org"}, Name: "repo"}
+			tc.event.Number = 3
+
+			projectCache := &threadsafeSet{data: sets.New[string]("abc", "def")}
+			err := handleWithProjectCache(jiraClient, githubClient, cfg, logrus.NewEntry(logrus.New()), &tc.event, projectCache)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expectedAdded, githubClient.IssueLabelsAdded); diff != "" {
+				t.Errorf("added labels differ from expected: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedRemoved, githubClient.IssueLabelsRemoved); diff != "" {
+				t.Errorf("removed labels differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestTransitionOnMerge(t *testing.T) {
+	t.Parallel()
+	cfg := &plugins.Jira{
+		DisabledJiraProjects: []string{"ghi"},
+		Projects: map[string]plugins.JiraProject{
+			"ABC": {TransitionOnMerge: "modified"},
+			"GHI": {TransitionOnMerge: "modified"},
+		},
+	}
+	jiraClient := &fakejira.FakeClient{
+		Issues: []*jira.Issue{
+			{ID: "ABC-123", Fields: &jira.IssueFields{Status: &jira.Status{Name: "New"}}},
+			{ID: "DEF-1", Fields: &jira.IssueFields{Status: &jira.Status{Name: "New"}}},
+			{ID: "GHI-1", Fields: &jira.IssueFields{Status: &jira.Status{Name: "New"}}},
+		},
+		Transitions: []jira.Transition{{ID: "1", Name: "MODIFIED", To: jira.Status{Name: "Modified"}}},
+	}
+	pr := &github.PullRequest{
+		Title: "ABC-123: Fix the thing",
+		Body:  "Related to DEF-1 and GHI-1, but not ABC-999",
+	}
+
+	if err := transitionOnMerge(jiraClient, cfg, logrus.NewEntry(logrus.New()), pr); err != nil {
+		t.Fatalf("transitionOnMerge failed: %v", err)
+	}
+	expected := map[string]string{"ABC-123": "Modified", "DEF-1": "New", "GHI-1": "New"}
+	for id, status := range expected {
+		issue, err := jiraClient.GetIssue(id)
+		if err != nil {
+			t.Fatalf("failed to get issue %s: %v", id, err)
+		}
+		if issue.Fields.Status.Name != status {
+			t.Errorf("expected issue %s to have status %q, got %q", id, status, issue.Fields.Status.Name)
+		}
+	}
+}
+
+func TestJiraProjectFor(t *testing.T) {
+	t.Parallel()
+	cfg := &plugins.Jira{Projects: map[string]plugins.JiraProject{"abc": {TransitionOnMerge: "MODIFIED"}}}
+	if project, ok := cfg.ProjectFor("ABC-123"); !ok || project.TransitionOnMerge != "MODIFIED" {
+		t.Errorf("expected ABC-123 to match project abc, got %+v, %t", project, ok)
+	}
+	if _, ok := cfg.ProjectFor("ABCD-123"); ok {
+		t.Error("expected ABCD-123 not to match project abc")
+	}
+	var nilCfg *plugins.Jira
+	if _, ok := nilCfg.ProjectFor("ABC-123"); ok {
+		t.Error("expected no project for a nil config")
+	}
+}
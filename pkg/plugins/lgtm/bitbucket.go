@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lgtm
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/bitbucket"
+)
+
+// BitbucketStatusKey is the key of the build status that records the LGTM of
+// a Bitbucket pull request.
+const BitbucketStatusKey = "lgtm"
+
+// BitbucketClient holds the clients needed to handle /lgtm on Bitbucket pull
+// requests.
+type BitbucketClient struct {
+	BitbucketClient bitbucket.Client
+	Logger          *logrus.Entry
+}
+
+// HandleBitbucketComment handles /lgtm and /lgtm cancel comments on Bitbucket
+// pull requests. Bitbucket has no labels, so the LGTM is recorded as a build
+// status of the head commit, which merge checks can require. Like the label
+// on GitHub it does not carry over to new commits. Review thresholds are not
+// supported.
+func HandleBitbucketComment(c BitbucketClient, e bitbucket.PullRequestEvent) error {
+	if e.EventKey != bitbucket.EventKeyPullRequestComment || e.Comment == nil {
+		return nil
+	}
+	pr := e.PullRequest
+	if pr.State != bitbucket.PullRequestStateOpen {
+		return nil
+	}
+	body := e.Comment.Text
+	var wantLGTM bool
+	switch {
+	case LGTMRe.MatchString(body):
+		wantLGTM = true
+	case LGTMCancelRe.MatchString(body):
+	default:
+		return nil
+	}
+
+	repo := pr.ToRef.Repository
+	author := e.Comment.Author.Name
+	if wantLGTM && author == pr.Author.User.Name {
+		return c.BitbucketClient.CreatePullRequestComment(repo.Project.Key, repo.Slug, pr.ID, "You cannot LGTM your own pull request.")
+	}
+	permission, err := c.BitbucketClient.GetUserPermission(repo.Project.Key, repo.Slug, author)
+	if err != nil {
+		return fmt.Errorf("failed to get the permission of %s: %w", author, err)
+	}
+	if !permission.CanWrite() {
+		c.Logger.Infof("Ignoring /lgtm from %s without write permission.", author)
+		return c.BitbucketClient.CreatePullRequestComment(repo.Project.Key, repo.Slug, pr.ID,
+			fmt.Sprintf("@%s: changing LGTM is restricted to users with write permission on this repository.", e.Comment.Author.Slug))
+	}
+
+	status := bitbucket.BuildStatus{
+		Key:         BitbucketStatusKey,
+		Name:        BitbucketStatusKey,
+		State:       bitbucket.BuildStateSuccessful,
+		URL:         pr.WebURL(),
+		Description: fmt.Sprintf("LGTM by %s", e.Comment.Author.Slug),
+	}
+	if !wantLGTM {
+		status.State = bitbucket.BuildStateFailed
+		status.Description = fmt.Sprintf("LGTM cancelled by %s", e.Comment.Author.Slug)
+	}
+	c.Logger.Infof("Setting the LGTM status of the pull request to %s.", status.State)
+	return c.BitbucketClient.SetBuildStatus(repo.Project.Key, repo.Slug, pr.FromRef.LatestCommit, status)
+}
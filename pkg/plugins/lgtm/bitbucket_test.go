@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lgtm
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/bitbucket/fakebitbucket"
+)
+
+func TestHandleBitbucketComment(t *testing.T) {
+	testCases := []struct {
+		name             string
+		commenter        string
+		body             string
+		state            string
+		existing         []bitbucket.BuildStatus
+		expectedStatuses []bitbucket.BuildStatus
+		expectedComments int
+	}{
+		{
+			name:      "reviewer with write permission approves",
+			commenter: "dev",
+			body:      "/lgtm",
+			expectedStatuses: []bitbucket.BuildStatus{{
+				Key: BitbucketStatusKey, Name: BitbucketStatusKey, State: bitbucket.BuildStateSuccessful,
+				URL: "https://bitbucket.example.com/projects/PRJ/repos/repo/pull-requests/1", Description: "LGTM by dev",
+			}},
+		},
+		{
+			name:      "reviewer with write permission cancels",
+			commenter: "dev",
+			body:      "/lgtm cancel",
+			existing:  []bitbucket.BuildStatus{{Key: BitbucketStatusKey, State: bitbucket.BuildStateSuccessful}},
+			expectedStatuses: []bitbucket.BuildStatus{{
+				Key: BitbucketStatusKey, Name: BitbucketStatusKey, State: bitbucket.BuildStateFailed,
+				URL: "https://bitbucket.example.com/projects/PRJ/repos/repo/pull-requests/1", Description: "LGTM cancelled by dev",
+			}},
+		},
+		{
+			name:             "author approves own pull request",
+			commenter:        "author",
+			body:             "/lgtm",
+			expectedComments: 1,
+		},
+		{
+			name:             "reviewer without write permission approves",
+			commenter:        "reader",
+			body:             "/lgtm",
+			expectedComments: 1,
+		},
+		{
+			name:      "unrelated comment",
+			commenter: "dev",
+			body:      "looks good to me",
+		},
+		{
+			name:      "merged pull request",
+			commenter: "dev",
+			body:      "/lgtm",
+			state:     bitbucket.PullRequestStateMerged,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bc := fakebitbucket.NewFakeClient()
+			bc.Permissions["PRJ/repo"] = map[string]bitbucket.Permission{
+				"dev":    bitbucket.PermissionRepoWrite,
+				"author": bitbucket.PermissionRepoWrite,
+				"reader": bitbucket.PermissionRepoRead,
+			}
+			bc.BuildStatuses["head-sha"] = tc.existing
+			repo := bitbucket.Repository{Slug: "repo", Project: bitbucket.Project{Key: "PRJ"}}
+			state := tc.state
+			if state == "" {
+				state = bitbucket.PullRequestStateOpen
+			}
+			event := bitbucket.PullRequestEvent{
+				EventKey: bitbucket.EventKeyPullRequestComment,
+				PullRequest: bitbucket.PullRequest{
+					ID:      1,
+					State:   state,
+					FromRef: bitbucket.Ref{LatestCommit: "head-sha", Repository: repo},
+					ToRef:   bitbucket.Ref{DisplayID: "main", Repository: repo},
+					Author:  bitbucket.Participant{User: bitbucket.User{Name: "author"}},
+					Links:   bitbucket.Links{Self: []bitbucket.Link{{Href: "https://bitbucket.example.com/projects/PRJ/repos/repo/pull-requests/1"}}},
+				},
+				Comment: &bitbucket.Comment{Text: tc.body, Author: bitbucket.User{Name: tc.commenter, Slug: tc.commenter}},
+			}
+			c := BitbucketClient{BitbucketClient: bc, Logger: logrus.WithField("plugin", PluginName)}
+			if err := HandleBitbucketComment(c, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := tc.expectedStatuses
+			if expected == nil {
+				expected = tc.existing
+			}
+			if diff := cmp.Diff(expected, bc.BuildStatuses["head-sha"]); diff != "" {
+				t.Errorf("unexpected build statuses: %s", diff)
+			}
+			key := fakebitbucket.PullRequestKey("PRJ", "repo", 1)
+			if n := len(bc.Comments[key]); n != tc.expectedComments {
+				t.Errorf("expected %d comments, got %d: %v", tc.expectedComments, n, bc.Comments[key])
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/github"
@@ -56,6 +57,14 @@ func configInfoStickyLgtmTeam(team string) string {
 	return fmt.Sprintf(`Commits from "%s" do not remove LGTM.`, team)
 }
 
+func configInfoLgtmThreshold(opts *plugins.Lgtm) string {
+	info := fmt.Sprintf("LGTM from %d distinct reviewers is required.", opts.ThresholdFor(""))
+	for _, branch := range sets.List(sets.KeySet(opts.BranchLgtmThresholds)) {
+		info += fmt.Sprintf(" PRs against %q require LGTM from %d distinct reviewers.", branch, opts.BranchLgtmThresholds[branch])
+	}
+	return info
+}
+
 type commentPruner interface {
 	PruneComments(shouldPrune func(github.IssueComment) bool)
 }
@@ -87,6 +96,10 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 			configInfoStrings = append(configInfoStrings, "<li>"+configInfoStickyLgtmTeam(opts.StickyLgtmTeam)+"</li>")
 			isConfigured = true
 		}
+		if opts.HasThreshold() {
+			configInfoStrings = append(configInfoStrings, "<li>"+configInfoLgtmThreshold(opts)+"</li>")
+			isConfigured = true
+		}
 		configInfoStrings = append(configInfoStrings, "</ul>")
 		if isConfigured {
 			configInfo[repo.String()] = strings.Join(configInfoStrings, "\n")
@@ -99,6 +112,10 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 				ReviewActsAsLgtm: true,
 				StickyLgtmTeam:   "team1",
 				StoreTreeHash:    true,
+				LgtmThreshold:    2,
+				BranchLgtmThresholds: map[string]int{
+					"release": 3,
+				},
 			},
 		},
 	})
@@ -131,6 +148,7 @@ type githubClient interface {
 	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
 	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
 	DeleteComment(org, repo string, ID int) error
+	EditComment(org, repo string, ID int, comment string) error
 	BotUserChecker() (func(candidate string) bool, error)
 	GetSingleCommit(org, repo, SHA string) (github.RepositoryCommit, error)
 	IsMember(org, user string) (bool, error)
@@ -324,6 +342,35 @@ func handle(wantLGTM bool, config *plugins.Configuration, ownersClient repoowner
 	// now we update the LGTM labels, having checked all cases where changing
 	// LGTM was not allowed for the commenter
 
+	// If more than one LGTM is required, record the LGTM of the commenter
+	// and only want the label once enough reviewers have given theirs.
+	opts := config.LgtmFor(rc.repo.Owner.Login, rc.repo.Name)
+	if opts.HasThreshold() {
+		threshold, err := lgtmThreshold(gc, opts, org, repoName, number)
+		if err != nil {
+			return err
+		}
+		if threshold > 1 {
+			tracker, err := loadThresholdTracker(gc, org, repoName, number)
+			if err != nil {
+				return err
+			}
+			switch {
+			case wantLGTM:
+				tracker.reviewers.Insert(github.NormLogin(author))
+			case isAuthor:
+				tracker.reviewers = sets.New[string]()
+			default:
+				tracker.reviewers.Delete(github.NormLogin(author))
+			}
+			log.Infof("PR has LGTM from %d of %d required reviewers.", tracker.reviewers.Len(), threshold)
+			if err := tracker.save(gc, org, repoName, number, threshold); err != nil {
+				return fmt.Errorf("failed to save LGTM reviewers: %w", err)
+			}
+			wantLGTM = tracker.reviewers.Len() >= threshold
+		}
+	}
+
 	// Only add the label if it doesn't have it, and vice versa.
 	labels, err := gc.GetIssueLabels(org, repoName, number)
 	if err != nil {
@@ -332,7 +379,6 @@ func handle(wantLGTM bool, config *plugins.Configuration, ownersClient repoowner
 	hasLGTM := github.HasLabel(LGTMLabel, labels)
 
 	// remove the label if necessary, we're done after this
-	opts := config.LgtmFor(rc.repo.Owner.Login, rc.repo.Name)
 	if hasLGTM && !wantLGTM {
 		log.Info("Removing LGTM label.")
 		if err := removeLGTMAndRequestReview(gc, org, repoName, number, getLogins(assignees), opts.StoreTreeHash); err != nil {
@@ -429,6 +475,10 @@ func handlePullRequest(log *logrus.Entry, gc githubClient, config *plugins.Confi
 		log.WithError(err).Error("Failed to get labels.")
 	}
 	if !github.HasLabel(LGTMLabel, labels) {
+		if opts.HasThreshold() {
+			// Partial LGTMs were given to the previous changes.
+			return clearThresholdTracker(gc, org, repo, number)
+		}
 		return nil
 	}
 
@@ -471,6 +521,11 @@ func handlePullRequest(log *logrus.Entry, gc githubClient, config *plugins.Confi
 	if err := removeLGTMAndRequestReview(gc, org, repo, number, getLogins(pe.PullRequest.Assignees), opts.StoreTreeHash); err != nil {
 		return fmt.Errorf("failed removing lgtm label: %w", err)
 	}
+	if opts.HasThreshold() {
+		if err := clearThresholdTracker(gc, org, repo, number); err != nil {
+			return fmt.Errorf("failed clearing LGTM reviewers: %w", err)
+		}
+	}
 
 	// Create a comment to inform participants that LGTM label is removed due to new
 	// pull request changes.
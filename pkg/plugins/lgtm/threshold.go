@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lgtm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+var (
+	thresholdTrackerNotification = "LGTM from %d of %d required reviewers: %s\n<!-- lgtm-threshold-reviewers: %s -->"
+	thresholdTrackerRe           = regexp.MustCompile(`<!-- lgtm-threshold-reviewers: ?(.*?) -->`)
+)
+
+// thresholdTracker tracks the reviewers that LGTM'd a PR in a comment when
+// more than one LGTM is required.
+type thresholdTracker struct {
+	commentID int
+	reviewers sets.Set[string]
+}
+
+// lgtmThreshold returns the number of LGTMs that are required for the PR.
+func lgtmThreshold(gc githubClient, opts *plugins.Lgtm, org, repo string, number int) (int, error) {
+	if len(opts.BranchLgtmThresholds) == 0 {
+		return opts.ThresholdFor(""), nil
+	}
+	pr, err := gc.GetPullRequest(org, repo, number)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	return opts.ThresholdFor(pr.Base.Ref), nil
+}
+
+// loadThresholdTracker finds the tracker comment of the bot on the PR. The
+// returned tracker has no reviewers if there is no such comment yet.
+func loadThresholdTracker(gc githubClient, org, repo string, number int) (*thresholdTracker, error) {
+	botUserChecker, err := gc.BotUserChecker()
+	if err != nil {
+		return nil, err
+	}
+	comments, err := gc.ListIssueComments(org, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	tracker := &thresholdTracker{reviewers: sets.New[string]()}
+	for _, comment := range comments {
+		if !botUserChecker(comment.User.Login) {
+			continue
+		}
+		m := thresholdTrackerRe.FindStringSubmatch(comment.Body)
+		if m == nil {
+			continue
+		}
+		tracker.commentID = comment.ID
+		for _, reviewer := range strings.Split(m[1], ",") {
+			if reviewer = strings.TrimSpace(reviewer); reviewer != "" {
+				tracker.reviewers.Insert(reviewer)
+			}
+		}
+		break
+	}
+	return tracker, nil
+}
+
+// save creates or updates the tracker comment on the PR.
+func (t *thresholdTracker) save(gc githubClient, org, repo string, number, threshold int) error {
+	reviewers := sets.List(t.reviewers)
+	var mentions []string
+	for _, reviewer := range reviewers {
+		mentions = append(mentions, "@"+reviewer)
+	}
+	mentionList := "none"
+	if len(mentions) > 0 {
+		mentionList = strings.Join(mentions, ", ")
+	}
+	comment := fmt.Sprintf(thresholdTrackerNotification, len(reviewers), threshold, mentionList, strings.Join(reviewers, ","))
+	if t.commentID == 0 {
+		return gc.CreateComment(org, repo, number, comment)
+	}
+	return gc.EditComment(org, repo, t.commentID, comment)
+}
+
+// clearThresholdTracker removes the tracker comment from the PR, if any, so
+// that LGTMs given to previous changes are not counted any more.
+func clearThresholdTracker(gc githubClient, org, repo string, number int) error {
+	tracker, err := loadThresholdTracker(gc, org, repo, number)
+	if err != nil {
+		return err
+	}
+	if tracker.commentID == 0 {
+		return nil
+	}
+	return gc.DeleteComment(org, repo, tracker.commentID)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lgtm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestLGTMThreshold(t *testing.T) {
+	type step struct {
+		commenter       string
+		body            string
+		expectLGTM      bool
+		expectReviewers string
+	}
+	testcases := []struct {
+		name  string
+		lgtm  plugins.Lgtm
+		steps []step
+	}{
+		{
+			name: "label is added once enough reviewers LGTM",
+			lgtm: plugins.Lgtm{Repos: []string{"org/repo"}, LgtmThreshold: 2},
+			steps: []step{
+				{commenter: "collab1", body: "/lgtm", expectReviewers: "LGTM from 1 of 2 required reviewers: @collab1"},
+				{commenter: "collab1", body: "/lgtm", expectReviewers: "LGTM from 1 of 2 required reviewers: @collab1"},
+				{commenter: "collab2", body: "/lgtm", expectLGTM: true, expectReviewers: "LGTM from 2 of 2 required reviewers: @collab1, @collab2"},
+				{commenter: "collab1", body: "/lgtm cancel", expectReviewers: "LGTM from 1 of 2 required reviewers: @collab2"},
+				{commenter: "author", body: "/lgtm cancel", expectReviewers: "LGTM from 0 of 2 required reviewers: none"},
+			},
+		},
+		{
+			name: "branch threshold overrides the repo threshold",
+			lgtm: plugins.Lgtm{Repos: []string{"org/repo"}, LgtmThreshold: 2, BranchLgtmThresholds: map[string]int{"master": 3}},
+			steps: []step{
+				{commenter: "collab1", body: "/lgtm", expectReviewers: "LGTM from 1 of 3 required reviewers: @collab1"},
+				{commenter: "collab2", body: "/lgtm", expectReviewers: "LGTM from 2 of 3 required reviewers: @collab1, @collab2"},
+				{commenter: "collab3", body: "/lgtm", expectLGTM: true, expectReviewers: "LGTM from 3 of 3 required reviewers: @collab1, @collab2, @collab3"},
+			},
+		},
+		{
+			name: "branch threshold of one behaves like a single LGTM",
+			lgtm: plugins.Lgtm{Repos: []string{"org/repo"}, LgtmThreshold: 2, BranchLgtmThresholds: map[string]int{"master": 1}},
+			steps: []step{
+				{commenter: "collab1", body: "/lgtm", expectLGTM: true},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegithub.NewFakeClient()
+			fc.IssueComments = map[int][]github.IssueComment{}
+			fc.PullRequests = map[int]*github.PullRequest{
+				5: {Base: github.PullRequestBranch{Ref: "master"}},
+			}
+			fc.Collaborators = []string{"collab1", "collab2", "collab3"}
+			pc := &plugins.Configuration{Lgtm: []plugins.Lgtm{tc.lgtm}}
+			for i, s := range tc.steps {
+				e := github.GenericCommentEvent{
+					Action:      github.GenericCommentActionCreated,
+					IssueState:  "open",
+					IsPR:        true,
+					Body:        s.body,
+					User:        github.User{Login: s.commenter},
+					IssueAuthor: github.User{Login: "author"},
+					Number:      5,
+					Assignees:   []github.User{{Login: "collab1"}, {Login: "collab2"}, {Login: "collab3"}},
+					Repo:        github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+				}
+				if err := handleGenericComment(fc, pc, &fakeOwnersClient{}, logrus.WithField("plugin", PluginName), &fakePruner{GitHubClient: fc}, e); err != nil {
+					t.Fatalf("step %d: unexpected error: %v", i, err)
+				}
+				labels, _ := fc.GetIssueLabels("org", "repo", 5)
+				if hasLGTM := github.HasLabel(LGTMLabel, labels); hasLGTM != s.expectLGTM {
+					t.Errorf("step %d: expected lgtm label: %t, got: %t", i, s.expectLGTM, hasLGTM)
+				}
+				tracker, err := loadThresholdTracker(fc, "org", "repo", 5)
+				if err != nil {
+					t.Fatalf("step %d: failed to load tracker: %v", i, err)
+				}
+				var body string
+				for _, comment := range fc.IssueComments[5] {
+					if comment.ID == tracker.commentID {
+						body = comment.Body
+					}
+				}
+				if s.expectReviewers == "" {
+					if tracker.commentID != 0 {
+						t.Errorf("step %d: expected no tracker comment, got %q", i, body)
+					}
+				} else if !thresholdTrackerRe.MatchString(body) || !strings.HasPrefix(body, s.expectReviewers) {
+					t.Errorf("step %d: expected tracker comment to start with %q, got %q", i, s.expectReviewers, body)
+				}
+			}
+		})
+	}
+}
+
+func TestLGTMThresholdResetOnPush(t *testing.T) {
+	for _, hasLGTM := range []bool{false, true} {
+		fc := fakegithub.NewFakeClient()
+		fc.IssueComments = map[int][]github.IssueComment{
+			5: {{ID: 1, User: github.User{Login: "k8s-ci-robot"}, Body: "LGTM from 1 of 2 required reviewers: @collab1\n<!-- lgtm-threshold-reviewers: collab1 -->"}},
+		}
+		if hasLGTM {
+			fc.IssueLabelsExisting = []string{"org/repo#5:" + LGTMLabel}
+		}
+		pc := &plugins.Configuration{Lgtm: []plugins.Lgtm{{Repos: []string{"org/repo"}, LgtmThreshold: 2}}}
+		pe := &github.PullRequestEvent{
+			Action: github.PullRequestActionSynchronize,
+			PullRequest: github.PullRequest{
+				Number: 5,
+				Base:   github.PullRequestBranch{Repo: github.Repo{Owner: github.User{Login: "org"}, Name: "repo"}},
+			},
+		}
+		if err := handlePullRequest(logrus.WithField("plugin", PluginName), fc, pc, pe); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fc.IssueCommentsDeleted) != 1 {
+			t.Errorf("hasLGTM=%t: expected the tracker comment to be deleted, deleted: %v", hasLGTM, fc.IssueCommentsDeleted)
+		}
+	}
+}
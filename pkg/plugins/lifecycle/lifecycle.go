@@ -19,6 +19,7 @@ package lifecycle
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
@@ -38,9 +39,59 @@ func init() {
 	plugins.RegisterGenericCommentHandler("lifecycle", lifecycleHandleGenericComment, help)
 }
 
-func help(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+func help(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	staleConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		lifecycle := config.LifecycleFor(repo.Org, repo.Repo)
+		if lifecycle == nil {
+			continue
+		}
+		kinds := "PRs"
+		if lifecycle.IncludeIssues {
+			kinds = "Issues and PRs"
+		}
+		var steps []string
+		if lifecycle.DaysUntilStale > 0 {
+			steps = append(steps, fmt.Sprintf("go stale after %d days", lifecycle.DaysUntilStale))
+		}
+		if lifecycle.DaysUntilRotten > 0 {
+			steps = append(steps, fmt.Sprintf("rot after %d more days", lifecycle.DaysUntilRotten))
+		}
+		if lifecycle.DaysUntilClose > 0 {
+			steps = append(steps, fmt.Sprintf("are closed after %d more days", lifecycle.DaysUntilClose))
+		}
+		if len(steps) == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("%s without activity %s.", kinds, strings.Join(steps, ", "))
+		if len(lifecycle.ExemptLabels) > 0 {
+			msg += fmt.Sprintf(" Issues and PRs with the labels %s are exempt.", strings.Join(lifecycle.ExemptLabels, ", "))
+		}
+		staleConfig[repo.String()] = msg
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		Lifecycle: []plugins.Lifecycle{
+			{
+				Repos: []string{
+					"ORGANIZATION",
+					"ORGANIZATION/REPOSITORY",
+				},
+				DaysUntilStale:  90,
+				DaysUntilRotten: 30,
+				DaysUntilClose:  30,
+				IncludeIssues:   true,
+				ExemptLabels:    []string{"priority/critical-urgent"},
+				StaleComment:    "This {{.Kind}} has been inactive for {{.DaysUntilStale}} days.",
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for lifecycle plugin")
+	}
 	pluginHelp := &pluginhelp.PluginHelp{
-		Description: "Close, reopen, flag and/or unflag an issue or PR as frozen/stale/rotten",
+		Description: "Close, reopen, flag and/or unflag an issue or PR as frozen/stale/rotten. If configured, the lifecycle-controller periodically marks inactive issues and PRs as stale, then rotten, and finally closes them.",
+		Config:      staleConfig,
+		Snippet:     yamlSnippet,
 	}
 	pluginHelp.AddCommand(pluginhelp.Command{
 		Usage:       "/close [not-planned]",
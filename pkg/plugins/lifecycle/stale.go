@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	defaultStaleComment = `{{.Kind}}s go stale after {{.DaysUntilStale}} days of inactivity.
+{{if .DaysUntilRotten}}Stale {{.Kind}}s rot after an additional {{.DaysUntilRotten}} days of inactivity.
+{{end}}
+Mark this {{.Kind}} as fresh with ` + "`/remove-lifecycle stale`" + ` or exempt it from the automation with ` + "`/lifecycle frozen`" + `.`
+	defaultRottenComment = `Stale {{.Kind}}s rot after {{.DaysUntilRotten}} days of inactivity.
+{{if .DaysUntilClose}}Rotten {{.Kind}}s close after an additional {{.DaysUntilClose}} days of inactivity.
+{{end}}
+Mark this {{.Kind}} as fresh with ` + "`/remove-lifecycle rotten`" + ` or exempt it from the automation with ` + "`/lifecycle frozen`" + `.`
+	defaultCloseComment = `Rotten {{.Kind}}s close after {{.DaysUntilClose}} days of inactivity.
+Reopen this {{.Kind}} with ` + "`/reopen`" + ` and mark it as fresh with ` + "`/remove-lifecycle rotten`" + `.`
+)
+
+var htmlURLRe = regexp.MustCompile(`^https?://[^/]+/([^/]+)/([^/]+)/(?:issues|pull)/\d+$`)
+
+// StaleInfo is the info provided to the comment templates of the stale
+// issue and PR automation.
+type StaleInfo struct {
+	Org    string
+	Repo   string
+	Number int
+	Author string
+	// Kind is either "PR" or "issue".
+	Kind            string
+	DaysUntilStale  int
+	DaysUntilRotten int
+	DaysUntilClose  int
+}
+
+type staleClient interface {
+	FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error)
+	AddLabel(owner, repo string, number int, label string) error
+	RemoveLabel(owner, repo string, number int, label string) error
+	CreateComment(owner, repo string, number int, comment string) error
+	ClosePullRequest(owner, repo string, number int) error
+	CloseIssueAsNotPlanned(owner, repo string, number int) error
+}
+
+// stage is a step of the stale automation, applied to issues and PRs that
+// were not updated for a number of days.
+type stage struct {
+	name    string
+	days    int
+	query   string
+	comment string
+	apply   func(gc staleClient, org, repo string, issue github.Issue) error
+}
+
+// SyncStale marks the issues and PRs of the configured repos that saw no
+// activity as stale, marks stale ones as rotten and closes rotten ones.
+func SyncStale(log *logrus.Entry, gc staleClient, lifecycles []plugins.Lifecycle, now time.Time) error {
+	var errs []error
+	for _, lifecycle := range lifecycles {
+		searches := staleSearches(lifecycle, lifecycles)
+		// Later stages go first so that nothing moves through more than one
+		// stage in a single sync.
+		for _, stage := range stagesFor(lifecycle) {
+			if stage.days == 0 {
+				continue
+			}
+			for _, search := range searches {
+				query := staleQuery(lifecycle, search.scope, stage.query, now.AddDate(0, 0, -stage.days))
+				issues, err := gc.FindIssuesWithOrg(search.org, query, "updated", true)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to search for issues to %s with query %q: %w", stage.name, query, err))
+					continue
+				}
+				for _, issue := range issues {
+					if err := applyStage(log, gc, lifecycle, stage, issue); err != nil {
+						errs = append(errs, err)
+					}
+				}
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func stagesFor(lifecycle plugins.Lifecycle) []stage {
+	return []stage{
+		{
+			name:    "close",
+			days:    lifecycle.DaysUntilClose,
+			query:   "label:" + labels.LifecycleRotten,
+			comment: commentOrDefault(lifecycle.CloseComment, defaultCloseComment),
+			apply: func(gc staleClient, org, repo string, issue github.Issue) error {
+				if issue.IsPullRequest() {
+					return gc.ClosePullRequest(org, repo, issue.Number)
+				}
+				return gc.CloseIssueAsNotPlanned(org, repo, issue.Number)
+			},
+		},
+		{
+			name:    "mark rotten",
+			days:    lifecycle.DaysUntilRotten,
+			query:   fmt.Sprintf("label:%s -label:%s", labels.LifecycleStale, labels.LifecycleRotten),
+			comment: commentOrDefault(lifecycle.RottenComment, defaultRottenComment),
+			apply: func(gc staleClient, org, repo string, issue github.Issue) error {
+				if err := gc.AddLabel(org, repo, issue.Number, labels.LifecycleRotten); err != nil {
+					return err
+				}
+				return gc.RemoveLabel(org, repo, issue.Number, labels.LifecycleStale)
+			},
+		},
+		{
+			name:    "mark stale",
+			days:    lifecycle.DaysUntilStale,
+			query:   fmt.Sprintf("-label:%s -label:%s", labels.LifecycleStale, labels.LifecycleRotten),
+			comment: commentOrDefault(lifecycle.StaleComment, defaultStaleComment),
+			apply: func(gc staleClient, org, repo string, issue github.Issue) error {
+				return gc.AddLabel(org, repo, issue.Number, labels.LifecycleStale)
+			},
+		},
+	}
+}
+
+func commentOrDefault(comment, defaultComment string) string {
+	if comment == "" {
+		return defaultComment
+	}
+	return comment
+}
+
+// staleSearch is a search for the issues and PRs of an org.
+type staleSearch struct {
+	org   string
+	scope string
+}
+
+// staleSearches returns the searches selecting the repos of the lifecycle
+// configuration. Repos configured by other entries are excluded from org wide
+// searches.
+func staleSearches(lifecycle plugins.Lifecycle, lifecycles []plugins.Lifecycle) []staleSearch {
+	var searches []staleSearch
+	for _, entry := range lifecycle.Repos {
+		org, _, isRepo := strings.Cut(entry, "/")
+		if isRepo {
+			searches = append(searches, staleSearch{org: org, scope: "repo:" + entry})
+			continue
+		}
+		scope := "org:" + org
+		for _, other := range lifecycles {
+			for _, repo := range other.Repos {
+				if strings.HasPrefix(repo, org+"/") {
+					scope += " -repo:" + repo
+				}
+			}
+		}
+		searches = append(searches, staleSearch{org: org, scope: scope})
+	}
+	return searches
+}
+
+func applyStage(log *logrus.Entry, gc staleClient, lifecycle plugins.Lifecycle, stage stage, issue github.Issue) error {
+	match := htmlURLRe.FindStringSubmatch(issue.HTMLURL)
+	if match == nil {
+		return fmt.Errorf("cannot determine the repo of %q", issue.HTMLURL)
+	}
+	org, repo := match[1], match[2]
+	// The search index may be outdated, so check the exemptions again.
+	if issue.HasLabel(labels.LifecycleFrozen) {
+		return nil
+	}
+	for _, label := range lifecycle.ExemptLabels {
+		if issue.HasLabel(label) {
+			return nil
+		}
+	}
+
+	info := StaleInfo{
+		Org:             org,
+		Repo:            repo,
+		Number:          issue.Number,
+		Author:          issue.User.Login,
+		Kind:            "issue",
+		DaysUntilStale:  lifecycle.DaysUntilStale,
+		DaysUntilRotten: lifecycle.DaysUntilRotten,
+		DaysUntilClose:  lifecycle.DaysUntilClose,
+	}
+	if issue.IsPullRequest() {
+		info.Kind = "PR"
+	}
+	parsedTemplate, err := template.New(stage.name).Parse(stage.comment)
+	if err != nil {
+		return fmt.Errorf("failed to parse the comment template to %s %s/%s#%d: %w", stage.name, org, repo, issue.Number, err)
+	}
+	var comment bytes.Buffer
+	if err := parsedTemplate.Execute(&comment, info); err != nil {
+		return fmt.Errorf("failed to execute the comment template to %s %s/%s#%d: %w", stage.name, org, repo, issue.Number, err)
+	}
+
+	log = log.WithFields(logrus.Fields{"org": org, "repo": repo, "number": issue.Number})
+	log.Infof("Applying %q to inactive %s.", stage.name, info.Kind)
+	if err := gc.CreateComment(org, repo, issue.Number, plugins.FormatSimpleResponse(comment.String())); err != nil {
+		return fmt.Errorf("failed to comment on %s/%s#%d: %w", org, repo, issue.Number, err)
+	}
+	if err := stage.apply(gc, org, repo, issue); err != nil {
+		return fmt.Errorf("failed to %s %s/%s#%d: %w", stage.name, org, repo, issue.Number, err)
+	}
+	return nil
+}
+
+func staleQuery(lifecycle plugins.Lifecycle, scope, stageQuery string, updatedBefore time.Time) string {
+	query := []string{"is:open", "archived:false", scope, stageQuery, "-label:" + labels.LifecycleFrozen}
+	if !lifecycle.IncludeIssues {
+		query = append(query, "is:pr")
+	}
+	for _, label := range lifecycle.ExemptLabels {
+		query = append(query, fmt.Sprintf("-label:%q", label))
+	}
+	query = append(query, "updated:<"+updatedBefore.UTC().Format("2006-01-02T15:04:05Z"))
+	return strings.Join(query, " ")
+}
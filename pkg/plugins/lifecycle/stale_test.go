@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+type fakeStaleClient struct {
+	// results maps query fragments to the issues returned for queries
+	// containing them.
+	results map[string][]github.Issue
+	queries []string
+	actions []string
+}
+
+func (f *fakeStaleClient) FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error) {
+	f.queries = append(f.queries, query)
+	for fragment, issues := range f.results {
+		if strings.Contains(query, fragment) {
+			return issues, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeStaleClient) AddLabel(owner, repo string, number int, label string) error {
+	f.actions = append(f.actions, fmt.Sprintf("%s/%s#%d:add:%s", owner, repo, number, label))
+	return nil
+}
+
+func (f *fakeStaleClient) RemoveLabel(owner, repo string, number int, label string) error {
+	f.actions = append(f.actions, fmt.Sprintf("%s/%s#%d:remove:%s", owner, repo, number, label))
+	return nil
+}
+
+func (f *fakeStaleClient) CreateComment(owner, repo string, number int, comment string) error {
+	f.actions = append(f.actions, fmt.Sprintf("%s/%s#%d:comment:%s", owner, repo, number, strings.SplitN(comment, "\n", 2)[0]))
+	return nil
+}
+
+func (f *fakeStaleClient) ClosePullRequest(owner, repo string, number int) error {
+	f.actions = append(f.actions, fmt.Sprintf("%s/%s#%d:close-pr", owner, repo, number))
+	return nil
+}
+
+func (f *fakeStaleClient) CloseIssueAsNotPlanned(owner, repo string, number int) error {
+	f.actions = append(f.actions, fmt.Sprintf("%s/%s#%d:close-issue", owner, repo, number))
+	return nil
+}
+
+func pr(repo string, number int, labelNames ...string) github.Issue {
+	issue := issue(repo, number, labelNames...)
+	issue.HTMLURL = fmt.Sprintf("https://github.com/%s/pull/%d", repo, number)
+	issue.PullRequest = &struct{}{}
+	return issue
+}
+
+func issue(repo string, number int, labelNames ...string) github.Issue {
+	issue := github.Issue{
+		Number:  number,
+		HTMLURL: fmt.Sprintf("https://github.com/%s/issues/%d", repo, number),
+		User:    github.User{Login: "author"},
+	}
+	for _, name := range labelNames {
+		issue.Labels = append(issue.Labels, github.Label{Name: name})
+	}
+	return issue
+}
+
+func TestSyncStale(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name            string
+		lifecycles      []plugins.Lifecycle
+		results         map[string][]github.Issue
+		expectedQueries []string
+		expectedActions []string
+	}{
+		{
+			name: "all stages",
+			lifecycles: []plugins.Lifecycle{{
+				Repos:           []string{"org/repo"},
+				DaysUntilStale:  90,
+				DaysUntilRotten: 30,
+				DaysUntilClose:  10,
+				IncludeIssues:   true,
+				ExemptLabels:    []string{"priority/critical-urgent"},
+			}},
+			results: map[string][]github.Issue{
+				" label:lifecycle/rotten": {pr("org/repo", 1, labels.LifecycleRotten), issue("org/repo", 2, labels.LifecycleRotten)},
+				" label:lifecycle/stale":  {pr("org/repo", 3, labels.LifecycleStale)},
+				" -label:lifecycle/stale": {pr("org/repo", 4), pr("org/repo", 5, "priority/critical-urgent")},
+			},
+			expectedQueries: []string{
+				`is:open archived:false repo:org/repo label:lifecycle/rotten -label:lifecycle/frozen -label:"priority/critical-urgent" updated:<2024-04-21T12:00:00Z`,
+				`is:open archived:false repo:org/repo label:lifecycle/stale -label:lifecycle/rotten -label:lifecycle/frozen -label:"priority/critical-urgent" updated:<2024-04-01T12:00:00Z`,
+				`is:open archived:false repo:org/repo -label:lifecycle/stale -label:lifecycle/rotten -label:lifecycle/frozen -label:"priority/critical-urgent" updated:<2024-02-01T12:00:00Z`,
+			},
+			expectedActions: []string{
+				"org/repo#1:comment:Rotten PRs close after 10 days of inactivity.",
+				"org/repo#1:close-pr",
+				"org/repo#2:comment:Rotten issues close after 10 days of inactivity.",
+				"org/repo#2:close-issue",
+				"org/repo#3:comment:Stale PRs rot after 30 days of inactivity.",
+				"org/repo#3:add:lifecycle/rotten",
+				"org/repo#3:remove:lifecycle/stale",
+				"org/repo#4:comment:PRs go stale after 90 days of inactivity.",
+				"org/repo#4:add:lifecycle/stale",
+			},
+		},
+		{
+			name: "org wide search excludes separately configured repos and uses custom comments",
+			lifecycles: []plugins.Lifecycle{
+				{
+					Repos:          []string{"org"},
+					DaysUntilStale: 30,
+					StaleComment:   "@{{.Author}}: this {{.Kind}} in {{.Org}}/{{.Repo}} is stale.",
+				},
+				{
+					Repos:          []string{"org/special"},
+					DaysUntilStale: 60,
+				},
+			},
+			results: map[string][]github.Issue{
+				"org:org": {pr("org/repo", 1)},
+			},
+			expectedQueries: []string{
+				`is:open archived:false org:org -repo:org/special -label:lifecycle/stale -label:lifecycle/rotten -label:lifecycle/frozen is:pr updated:<2024-04-01T12:00:00Z`,
+				`is:open archived:false repo:org/special -label:lifecycle/stale -label:lifecycle/rotten -label:lifecycle/frozen is:pr updated:<2024-03-02T12:00:00Z`,
+			},
+			expectedActions: []string{
+				"org/repo#1:comment:@author: this PR in org/repo is stale.",
+				"org/repo#1:add:lifecycle/stale",
+			},
+		},
+		{
+			name:       "nothing to do without days",
+			lifecycles: []plugins.Lifecycle{{Repos: []string{"org"}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gc := &fakeStaleClient{results: tc.results}
+			if err := SyncStale(logrus.WithField("test", tc.name), gc, tc.lifecycles, now); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedQueries, gc.queries); diff != "" {
+				t.Errorf("queries differ from expected (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedActions, gc.actions); diff != "" {
+				t.Errorf("actions differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStaleQueryUsesUTC(t *testing.T) {
+	updatedBefore := time.Date(2024, 4, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	query := staleQuery(plugins.Lifecycle{}, "org:org", "-label:lifecycle/stale", updatedBefore)
+	if !strings.HasSuffix(query, "updated:<2024-04-01T12:00:00Z") {
+		t.Errorf("expected the update time in UTC, got query %q", query)
+	}
+}
@@ -19,6 +19,7 @@ limitations under the License.
 package milestoneapplier
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -49,6 +50,14 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 		for branch, milestone := range config.MilestoneApplier[repo.String()] {
 			branchesToMilestone = append(branchesToMilestone, fmt.Sprintf("- `%s`: `%s`", branch, milestone))
 		}
+		for _, rule := range config.MilestoneRules {
+			for _, r := range rule.Repos {
+				if r == repo.Org || r == repo.String() {
+					branchesToMilestone = append(branchesToMilestone, fmt.Sprintf("- branches matching `%s`: `%s`", rule.BranchRegexp, rule.Milestone))
+					break
+				}
+			}
+		}
 		configInfo[repo.String()] = fmt.Sprintf("The configured branches and milestones for this repo are:\n%s", strings.Join(branchesToMilestone, "\n"))
 	}
 
@@ -60,12 +69,19 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 				"release-1.18": "v1.18",
 			},
 		},
+		MilestoneRules: []plugins.MilestoneApplierRule{
+			{
+				Repos:        []string{"kubernetes"},
+				BranchRegexp: `^release-(\d+\.\d+)$`,
+				Milestone:    "v$1",
+			},
+		},
 	})
 	if err != nil {
 		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", pluginName)
 	}
 	return &pluginhelp.PluginHelp{
-		Description: "The milestoneapplier plugin automatically applies the configured milestone for the base branch after a PR is merged. If a PR targets a non-default branch, it also adds the milestone when the PR is opened.",
+		Description: "The milestoneapplier plugin automatically applies the configured milestone for the base branch after a PR is merged. If a PR targets a non-default branch, it also adds the milestone when the PR is opened, reopened or retargeted. Milestones are configured either per branch or derived from the branch name by rules.",
 		Config:      configInfo,
 		Snippet:     yamlSnippet,
 	}, nil
@@ -76,13 +92,8 @@ func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
 	repo := pre.PullRequest.Base.Repo.Name
 	baseBranch := pre.PullRequest.Base.Ref
 
-	// if there are no branch to milestone mappings for this repo, return early
-	branchToMilestone, ok := pc.PluginConfig.MilestoneApplier[fmt.Sprintf("%s/%s", org, repo)]
-	if !ok {
-		return nil
-	}
 	// if the repo does not define milestones for this branch, return early
-	milestone, ok := branchToMilestone[baseBranch]
+	milestone, ok := pc.PluginConfig.MilestoneForBranch(org, repo, baseBranch)
 	if !ok {
 		return nil
 	}
@@ -98,11 +109,13 @@ func handle(gc githubClient, log *logrus.Entry, configuredMilestone string, pre
 		return nil
 	}
 
-	// if a PR targets a non-default branch, apply milestone when opened and on merge
+	// if a PR targets a non-default branch, apply milestone when opened, reopened,
+	// retargeted and on merge
 	// if a PR targets the default branch, apply the milestone only on merge
 	merged := pre.Action == github.PullRequestActionClosed && pr.Merged
 	if pr.Base.Repo.DefaultBranch != pr.Base.Ref {
-		if !merged && pre.Action != github.PullRequestActionOpened {
+		opened := pre.Action == github.PullRequestActionOpened || pre.Action == github.PullRequestActionReopened
+		if !merged && !opened && !(pre.Action == github.PullRequestActionEdited && baseChanged(pre.Changes)) {
 			return nil
 		}
 	} else if !merged {
@@ -132,3 +145,15 @@ func handle(gc githubClient, log *logrus.Entry, configuredMilestone string, pre
 
 	return nil
 }
+
+// baseChanged tells whether the changes of an edited PR event include its base
+// branch.
+func baseChanged(changes json.RawMessage) bool {
+	var c struct {
+		Base *struct{} `json:"base"`
+	}
+	if err := json.Unmarshal(changes, &c); err != nil {
+		return false
+	}
+	return c.Base != nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package milestoneapplier
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -31,6 +32,7 @@ func TestMilestoneApplier(t *testing.T) {
 		name                string
 		baseBranch          string
 		prAction            github.PullRequestEventAction
+		changes             string
 		merged              bool
 		previousMilestone   int
 		configuredMilestone int
@@ -85,6 +87,30 @@ func TestMilestoneApplier(t *testing.T) {
 			configuredMilestone: 1,
 			expectedMilestone:   1,
 		},
+		{
+			name:                "reopened PR on non-default branch => add milestone",
+			baseBranch:          "release-1.0",
+			prAction:            github.PullRequestActionReopened,
+			configuredMilestone: 1,
+			expectedMilestone:   1,
+		},
+		{
+			name:                "PR retargeted to non-default branch => add configured milestone",
+			baseBranch:          "release-1.0",
+			prAction:            github.PullRequestActionEdited,
+			changes:             `{"base":{"ref":{"from":"master"}}}`,
+			previousMilestone:   2,
+			configuredMilestone: 1,
+			expectedMilestone:   1,
+		},
+		{
+			name:                "PR on non-default branch with edited title => do nothing",
+			baseBranch:          "release-1.0",
+			prAction:            github.PullRequestActionEdited,
+			changes:             `{"title":{"from":"old"}}`,
+			configuredMilestone: 1,
+			expectedMilestone:   0,
+		},
 		{
 			name:                "synced PR on non-default branch => do nothing",
 			baseBranch:          "release-1.0",
@@ -158,6 +184,9 @@ func TestMilestoneApplier(t *testing.T) {
 				Number:      basicPR.Number,
 				PullRequest: basicPR,
 			}
+			if tc.changes != "" {
+				event.Changes = json.RawMessage(tc.changes)
+			}
 
 			fakeClient := fakegithub.NewFakeClient()
 			fakeClient.PullRequests = map[int]*github.PullRequest{
@@ -61,12 +61,13 @@ type githubClient interface {
 	ListTeams(org string) ([]github.Team, error)
 	ListTeamMembersBySlug(org, teamSlug, role string) ([]github.TeamMember, error)
 	ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error)
-	CreateCheckRun(org, repo string, checkRun github.CheckRun) error
+	CreateCheckRun(org, repo string, checkRun github.CheckRun) (*github.CheckRun, error)
 	UsesAppAuth() bool
 }
 
 type prowJobClient interface {
 	Create(context.Context, *prowapi.ProwJob, metav1.CreateOptions) (*prowapi.ProwJob, error)
+	UpdateStatus(context.Context, *prowapi.ProwJob, metav1.UpdateOptions) (*prowapi.ProwJob, error)
 }
 
 type ownersClient interface {
@@ -83,6 +84,7 @@ type overrideClient interface {
 type client struct {
 	ghc           githubClient
 	gc            git.ClientFactory
+	ircg          config.InRepoConfigGetter
 	config        *config.Config
 	ownersClient  ownersClient
 	prowJobClient prowJobClient
@@ -121,7 +123,7 @@ func (c client) ListCheckRuns(org, teamSlug, role string) (*github.CheckRunList,
 	return c.ghc.ListCheckRuns(org, teamSlug, role)
 }
 
-func (c client) CreateCheckRun(org, repo string, checkRun github.CheckRun) error {
+func (c client) CreateCheckRun(org, repo string, checkRun github.CheckRun) (*github.CheckRun, error) {
 	return c.ghc.CreateCheckRun(org, repo, checkRun)
 }
 
@@ -133,11 +135,15 @@ func (c client) Create(ctx context.Context, pj *prowapi.ProwJob, o metav1.Create
 	return c.prowJobClient.Create(ctx, pj, o)
 }
 
+func (c client) UpdateStatus(ctx context.Context, pj *prowapi.ProwJob, o metav1.UpdateOptions) (*prowapi.ProwJob, error) {
+	return c.prowJobClient.UpdateStatus(ctx, pj, o)
+}
+
 func (c client) presubmits(org, repo string, baseSHAGetter config.RefGetter, headSHA string) ([]config.Presubmit, error) {
 	headSHAGetter := func() (string, error) {
 		return headSHA, nil
 	}
-	presubmits, err := c.config.GetPresubmits(c.gc, org+"/"+repo, "", baseSHAGetter, headSHAGetter)
+	presubmits, err := plugins.GetPresubmits(c.ircg, c.config, c.gc, org+"/"+repo, "", baseSHAGetter, headSHAGetter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get presubmits: %w", err)
 	}
@@ -164,10 +170,12 @@ func init() {
 func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
 	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
 		Override: plugins.Override{
-			AllowTopLevelOwners: true,
+			AllowTopLevelOwners:      true,
+			AllowTopLevelOwnersRepos: []string{"kubernetes/test-infra"},
 			AllowedGitHubTeams: map[string][]string{
 				"kubernetes/kubernetes": {"team1", "team2"},
 			},
+			RequireReason: true,
 		},
 	})
 	if err != nil {
@@ -183,10 +191,10 @@ func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhel
 	}
 	pluginHelp.AddCommand(pluginhelp.Command{
 		Usage:       "/override [context1] [context2]",
-		Description: "Forces github status contexts to green (multiple can be given). If the desired context has spaces, it must be quoted.",
+		Description: "Forces github status contexts to green (multiple can be given). If the desired context has spaces, it must be quoted. The reason for the override can be given on the following lines and is recorded in the comment auditing the override.",
 		Featured:    false,
 		WhoCanUse:   whoCanUse(overrideConfig, "", ""),
-		Examples:    []string{"/override pull-repo-whatever", "/override \"test / Unit Tests\"", "/override ci/circleci", "/override deleted-job other-job"},
+		Examples:    []string{"/override pull-repo-whatever", "/override \"test / Unit Tests\"", "/override ci/circleci", "/override deleted-job other-job", "/override pull-repo-whatever\r\nThe job is broken on the release branch, see #123."},
 	})
 	return pluginHelp, nil
 }
@@ -196,8 +204,10 @@ func whoCanUse(overrideConfig plugins.Override, org, repo string) string {
 	owners := ""
 	teams := ""
 
-	if overrideConfig.AllowTopLevelOwners {
+	if overrideConfig.AllowTopLevelOwners || (org != "" && overrideConfig.TopLevelOwnersAllowed(org, repo)) {
 		owners = ", approvers in top level OWNERS file"
+	} else if org == "" && len(overrideConfig.AllowTopLevelOwnersRepos) > 0 {
+		owners = fmt.Sprintf(", approvers in top level OWNERS file (in %s)", strings.Join(overrideConfig.AllowTopLevelOwnersRepos, ", "))
 	}
 
 	if len(overrideConfig.AllowedGitHubTeams) > 0 {
@@ -218,6 +228,7 @@ func whoCanUse(overrideConfig plugins.Override, org, repo string) string {
 func handleGenericComment(pc plugins.Agent, e github.GenericCommentEvent) error {
 	c := client{
 		gc:            pc.GitClient,
+		ircg:          pc.InRepoConfigGetter,
 		ghc:           pc.GitHubClient,
 		config:        pc.Config,
 		prowJobClient: pc.ProwJobClient,
@@ -287,6 +298,12 @@ func authorizedGitHubTeamMember(gc githubClient, log *logrus.Entry, teamSlugs ma
 	return false
 }
 
+// overrideReason returns the reason given for the override, which is the
+// comment without the /override commands.
+func overrideReason(body string) string {
+	return strings.TrimSpace(strings.ReplaceAll(overrideRe.ReplaceAllString(body, ""), "\r\n", "\n"))
+}
+
 func description(user string) string {
 	return fmt.Sprintf("Overridden by %s", user)
 }
@@ -347,11 +364,15 @@ func handle(oc overrideClient, log *logrus.Entry, e *github.GenericCommentEvent,
 		overrides.Insert(parseOverrideInput(m[2])...)
 	}
 
-	authorized := authorizedUser(oc, log, org, repo, user)
-	if !authorized && len(options.AllowedGitHubTeams) > 0 {
-		authorized = authorizedGitHubTeamMember(oc, log, options.AllowedGitHubTeams, org, repo, user)
+	// authorizedAs records why the user is allowed to override for the audit comment.
+	var authorizedAs string
+	if authorizedUser(oc, log, org, repo, user) {
+		authorizedAs = "a repo administrator"
+	} else if len(options.AllowedGitHubTeams) > 0 && authorizedGitHubTeamMember(oc, log, options.AllowedGitHubTeams, org, repo, user) {
+		authorizedAs = "a member of an allowed github team"
 	}
-	if !authorized && !options.AllowTopLevelOwners {
+	allowTopLevelOwners := options.TopLevelOwnersAllowed(org, repo)
+	if authorizedAs == "" && !allowTopLevelOwners {
 		resp := fmt.Sprintf("%s unauthorized: /override is restricted to %s", user, whoCanUse(options, org, repo))
 		log.Debug(resp)
 		return oc.CreateComment(org, repo, number, plugins.FormatResponseRaw(e.Body, e.HTMLURL, user, resp))
@@ -364,8 +385,18 @@ func handle(oc overrideClient, log *logrus.Entry, e *github.GenericCommentEvent,
 		return oc.CreateComment(org, repo, number, plugins.FormatResponseRaw(e.Body, e.HTMLURL, user, resp))
 	}
 
-	if !authorized && !authorizedTopLevelOwner(oc, options.AllowTopLevelOwners, log, org, repo, user, pr) {
-		resp := fmt.Sprintf("%s unauthorized: /override is restricted to %s", user, whoCanUse(options, org, repo))
+	if authorizedAs == "" {
+		if !authorizedTopLevelOwner(oc, allowTopLevelOwners, log, org, repo, user, pr) {
+			resp := fmt.Sprintf("%s unauthorized: /override is restricted to %s", user, whoCanUse(options, org, repo))
+			log.Debug(resp)
+			return oc.CreateComment(org, repo, number, plugins.FormatResponseRaw(e.Body, e.HTMLURL, user, resp))
+		}
+		authorizedAs = "an approver in the top level OWNERS file"
+	}
+
+	reason := overrideReason(e.Body)
+	if reason == "" && options.RequireReason {
+		resp := "/override requires a reason in this repository. Please explain why the contexts need to be overridden on the lines following the /override command."
 		log.Debug(resp)
 		return oc.CreateComment(org, repo, number, plugins.FormatResponseRaw(e.Body, e.HTMLURL, user, resp))
 	}
@@ -480,7 +511,11 @@ If you are trying to override a checkrun that has a space in it, you must put a
 			return
 		}
 		msg := fmt.Sprintf("Overrode contexts on behalf of %s: %s", user, strings.Join(sets.List(done), ", "))
-		log.Info(msg)
+		log.WithFields(logrus.Fields{"authorized-as": authorizedAs, "reason": reason}).Info(msg)
+		msg += fmt.Sprintf("\n\n%s is allowed to override as %s.", user, authorizedAs)
+		if reason != "" {
+			msg += "\n\nReason:\n> " + strings.ReplaceAll(reason, "\n", "\n> ")
+		}
 		oc.CreateComment(org, repo, number, plugins.FormatResponseRaw(e.Body, e.HTMLURL, user, msg))
 	}()
 
@@ -510,7 +545,7 @@ If you are trying to override a checkrun that has a space in it, you must put a
 			}
 
 			log.WithFields(pjutil.ProwJobFields(&pj)).Info("Creating a new prowjob.")
-			if _, err := oc.Create(context.TODO(), &pj, metav1.CreateOptions{}); err != nil {
+			if _, err := pjutil.CreateProwJob(context.TODO(), oc, &pj); err != nil {
 				resp := fmt.Sprintf("Failed to create override job for %s", status.Context)
 				log.WithError(err).Warn(resp)
 				return oc.CreateComment(org, repo, number, plugins.FormatResponseRaw(e.Body, e.HTMLURL, user, resp))
@@ -543,7 +578,7 @@ If you are trying to override a checkrun that has a space in it, you must put a
 						Summary: fmt.Sprintf("Prow has received override command for the %s checkrun.", checkrun.Context),
 					},
 				}
-				if err := oc.CreateCheckRun(org, repo, prowOverrideCR); err != nil {
+				if _, err := oc.CreateCheckRun(org, repo, prowOverrideCR); err != nil {
 					resp := fmt.Sprintf("cannot create prow-override CheckRun %v", prowOverrideCR)
 					log.WithError(err).Warn(resp)
 					return oc.CreateComment(org, repo, number, plugins.FormatResponseRaw(e.Body, e.HTMLURL, user, resp))
@@ -217,7 +217,7 @@ func (c *fakeClient) ListCheckRuns(org, repo, ref string) (*github.CheckRunList,
 	return &github.CheckRunList{}, nil
 }
 
-func (c *fakeClient) CreateCheckRun(org, repo string, checkRun github.CheckRun) error {
+func (c *fakeClient) CreateCheckRun(org, repo string, checkRun github.CheckRun) (*github.CheckRun, error) {
 	for _, checkrun := range c.checkruns.CheckRuns {
 		if checkrun.CompletedAt == "" {
 			continue
@@ -240,7 +240,7 @@ func (c *fakeClient) CreateCheckRun(org, repo string, checkRun github.CheckRun)
 			c.checkruns.CheckRuns = append(c.checkruns.CheckRuns, prowOverrideCR)
 		}
 	}
-	return nil
+	return &checkRun, nil
 }
 
 func (c *fakeClient) GetBranchProtection(org, repo, branch string) (*github.BranchProtection, error) {
@@ -317,6 +317,10 @@ func (c *fakeClient) Create(_ context.Context, pj *prowapi.ProwJob, _ metav1.Cre
 	return pj, nil
 }
 
+func (c *fakeClient) UpdateStatus(_ context.Context, pj *prowapi.ProwJob, _ metav1.UpdateOptions) (*prowapi.ProwJob, error) {
+	return pj, nil
+}
+
 func (c *fakeClient) LoadRepoOwners(org, repo, base string) (repoowners.RepoOwner, error) {
 	return c.owners.LoadRepoOwners(org, repo, base)
 }
@@ -1003,6 +1007,93 @@ func TestHandle(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "override records the reason in the audit comment",
+			comment: "/override job\r\nobnoxious flake\r\nsee #123",
+			contexts: []github.Status{
+				{
+					Context:     "job",
+					Description: "failed",
+					State:       github.StatusFailure,
+				},
+			},
+			expected: []github.Status{
+				{
+					Context:     "job",
+					Description: description(adminUser),
+					State:       github.StatusSuccess,
+				},
+			},
+			checkComments: []string{
+				"on behalf of " + adminUser + ": job",
+				adminUser + " is allowed to override as a repo administrator.",
+				"Reason:\n> obnoxious flake\n> see #123",
+			},
+		},
+		{
+			name:    "override without a reason fails when a reason is required",
+			comment: "/override job",
+			options: plugins.Override{RequireReason: true},
+			contexts: []github.Status{
+				{
+					Context:     "job",
+					Description: "failed",
+					State:       github.StatusFailure,
+				},
+			},
+			expected: []github.Status{
+				{
+					Context:     "job",
+					Description: "failed",
+					State:       github.StatusFailure,
+				},
+			},
+			checkComments: []string{"/override requires a reason in this repository"},
+		},
+		{
+			name:      "override with allow_top_level_owners_repos works",
+			comment:   "/override job",
+			user:      "code_owner",
+			options:   plugins.Override{AllowTopLevelOwnersRepos: []string{fakeOrg + "/" + fakeRepo}},
+			approvers: []string{"code_owner"},
+			contexts: []github.Status{
+				{
+					Context:     "job",
+					Description: "failed",
+					State:       github.StatusFailure,
+				},
+			},
+			expected: []github.Status{
+				{
+					Context:     "job",
+					Description: description("code_owner"),
+					State:       github.StatusSuccess,
+				},
+			},
+			checkComments: []string{"code_owner is allowed to override as an approver in the top level OWNERS file."},
+		},
+		{
+			name:      "override with allow_top_level_owners_repos for another repo fails",
+			comment:   "/override job",
+			user:      "code_owner",
+			options:   plugins.Override{AllowTopLevelOwnersRepos: []string{fakeOrg + "/other-repo"}},
+			approvers: []string{"code_owner"},
+			contexts: []github.Status{
+				{
+					Context:     "job",
+					Description: "failed",
+					State:       github.StatusFailure,
+				},
+			},
+			expected: []github.Status{
+				{
+					Context:     "job",
+					Description: "failed",
+					State:       github.StatusFailure,
+				},
+			},
+			checkComments: []string{"code_owner unauthorized"},
+		},
 		{
 			name:      "override with allow_top_level_owners works for uppercase user",
 			comment:   "/override job",
@@ -1338,6 +1429,24 @@ func TestWhoCanUse(t *testing.T) {
 	}
 }
 
+func TestWhoCanUseTopLevelOwnersRepos(t *testing.T) {
+	override := plugins.Override{AllowTopLevelOwnersRepos: []string{"org1", "org2/repo2"}}
+	cases := []struct {
+		org, repo   string
+		expectedWho string
+	}{
+		{expectedWho: "Repo administrators, approvers in top level OWNERS file (in org1, org2/repo2)."},
+		{org: "org1", repo: "repo1", expectedWho: "Repo administrators, approvers in top level OWNERS file."},
+		{org: "org2", repo: "repo2", expectedWho: "Repo administrators, approvers in top level OWNERS file."},
+		{org: "org2", repo: "repo1", expectedWho: "Repo administrators."},
+	}
+	for _, tc := range cases {
+		if who := whoCanUse(override, tc.org, tc.repo); who != tc.expectedWho {
+			t.Errorf("%s/%s: expected %q, got %q", tc.org, tc.repo, tc.expectedWho, who)
+		}
+	}
+}
+
 func TestAuthorizedGitHubTeamMember(t *testing.T) {
 	repoRef := fmt.Sprintf("%s/%s", fakeOrg, fakeRepo)
 	cases := []struct {
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pathlabel implements the path-label plugin, which labels PRs based
+// on the paths of the files they change.
+package pathlabel
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/pluginhelp"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	// PluginName defines this plugin's registered name.
+	PluginName = "path-label"
+)
+
+func init() {
+	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
+}
+
+func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	// The {WhoCanUse, Usage, Examples} fields are omitted because this plugin cannot be triggered manually.
+	labelConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		var buf bytes.Buffer
+		fmt.Fprint(&buf, "The following labels are applied to PRs changing matching files in this repository:")
+		for _, rule := range config.PathLabelRulesFor(repo.Org, repo.Repo) {
+			fmt.Fprintf(&buf, "<br>&nbsp&nbsp&nbsp&nbsp'%s': %q", rule.Label, rule.Regexp)
+		}
+		labelConfig[repo.String()] = buf.String()
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		PathLabel: []plugins.PathLabel{
+			{
+				Repos: []string{
+					"ORGANIZATION",
+					"ORGANIZATION/REPOSITORY",
+				},
+				Rules: []plugins.PathLabelRule{
+					{
+						Regexp: "^pkg/network/",
+						Label:  "sig/network",
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
+	return &pluginhelp.PluginHelp{
+			Description: "The path-label plugin automatically labels PRs based on the paths of the files they change. The labels are re-evaluated whenever the PR is updated and removed again once none of the changed files match.",
+			Config:      labelConfig,
+			Snippet:     yamlSnippet,
+		},
+		nil
+}
+
+type githubClient interface {
+	AddLabel(org, repo string, number int, label string) error
+	RemoveLabel(org, repo string, number int, label string) error
+	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
+	GetRepoLabels(owner, repo string) ([]github.Label, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+}
+
+func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
+	if pre.Action != github.PullRequestActionOpened && pre.Action != github.PullRequestActionReopened && pre.Action != github.PullRequestActionSynchronize {
+		return nil
+	}
+	rules := pc.PluginConfig.PathLabelRulesFor(pre.Repo.Owner.Login, pre.Repo.Name)
+	if len(rules) == 0 {
+		return nil
+	}
+	return handle(pc.GitHubClient, pc.Logger, rules, &pre)
+}
+
+func handle(ghc githubClient, log *logrus.Entry, rules []plugins.PathLabelRule, pre *github.PullRequestEvent) error {
+	org := pre.Repo.Owner.Login
+	repo := pre.Repo.Name
+	number := pre.Number
+
+	changes, err := ghc.GetPullRequestChanges(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("error getting PR changes: %w", err)
+	}
+	managedLabels := sets.New[string]()
+	neededLabels := sets.New[string]()
+	for _, rule := range rules {
+		managedLabels.Insert(rule.Label)
+		for _, change := range changes {
+			// A file moved away from a matching path still changes that path.
+			if rule.Re.MatchString(change.Filename) || (change.PreviousFilename != "" && rule.Re.MatchString(change.PreviousFilename)) {
+				neededLabels.Insert(rule.Label)
+				break
+			}
+		}
+	}
+
+	issueLabels, err := ghc.GetIssueLabels(org, repo, number)
+	if err != nil {
+		return err
+	}
+	currentLabels := sets.New[string]()
+	for _, label := range issueLabels {
+		currentLabels.Insert(label.Name)
+	}
+
+	toAdd := neededLabels.Difference(currentLabels)
+	if toAdd.Len() > 0 {
+		repoLabels, err := ghc.GetRepoLabels(org, repo)
+		if err != nil {
+			return err
+		}
+		existingLabels := sets.New[string]()
+		for _, label := range repoLabels {
+			existingLabels.Insert(label.Name)
+		}
+		nonexistent := toAdd.Difference(existingLabels)
+		if nonexistent.Len() > 0 {
+			log.Warnf("Unable to add nonexistent labels: %q", sets.List(nonexistent))
+		}
+		for _, label := range sets.List(toAdd.Intersection(existingLabels)) {
+			if err := ghc.AddLabel(org, repo, number, label); err != nil {
+				log.WithError(err).Errorf("GitHub failed to add the following label: %s", label)
+			}
+		}
+	}
+
+	for _, label := range sets.List(managedLabels.Intersection(currentLabels).Difference(neededLabels)) {
+		if err := ghc.RemoveLabel(org, repo, number, label); err != nil {
+			log.WithError(err).Errorf("GitHub failed to remove the following label: %s", label)
+		}
+	}
+	return nil
+}
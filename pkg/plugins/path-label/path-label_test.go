@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pathlabel
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func formatLabels(labels ...string) []string {
+	var r []string
+	for _, l := range labels {
+		r = append(r, fmt.Sprintf("%s/%s#%d:%s", "org", "repo", 1, l))
+	}
+	return r
+}
+
+func TestHandle(t *testing.T) {
+	rules := []plugins.PathLabelRule{
+		{Regexp: "^pkg/network/", Re: regexp.MustCompile("^pkg/network/"), Label: "sig/network"},
+		{Regexp: "^pkg/storage/", Re: regexp.MustCompile("^pkg/storage/"), Label: "sig/storage"},
+		{Regexp: "\\.md$", Re: regexp.MustCompile("\\.md$"), Label: "kind/documentation"},
+	}
+
+	testcases := []struct {
+		name            string
+		changes         []github.PullRequestChange
+		repoLabels      []string
+		issueLabels     []string
+		expectedAdded   []string
+		expectedRemoved []string
+	}{
+		{
+			name:       "no matching files",
+			changes:    []github.PullRequestChange{{Filename: "cmd/main.go"}},
+			repoLabels: []string{"sig/network", "sig/storage", "kind/documentation"},
+		},
+		{
+			name:          "matching files are labeled",
+			changes:       []github.PullRequestChange{{Filename: "pkg/network/proxy.go"}, {Filename: "README.md"}},
+			repoLabels:    []string{"sig/network", "sig/storage", "kind/documentation"},
+			expectedAdded: formatLabels("kind/documentation", "sig/network"),
+		},
+		{
+			name:        "existing labels are kept",
+			changes:     []github.PullRequestChange{{Filename: "pkg/network/proxy.go"}},
+			repoLabels:  []string{"sig/network", "sig/storage", "kind/documentation"},
+			issueLabels: []string{"sig/network"},
+		},
+		{
+			name:          "nonexistent labels are not added",
+			changes:       []github.PullRequestChange{{Filename: "pkg/network/proxy.go"}, {Filename: "pkg/storage/volume.go"}},
+			repoLabels:    []string{"sig/storage"},
+			expectedAdded: formatLabels("sig/storage"),
+		},
+		{
+			name:            "labels for paths no longer changed are removed",
+			changes:         []github.PullRequestChange{{Filename: "pkg/storage/volume.go"}},
+			repoLabels:      []string{"sig/network", "sig/storage", "kind/documentation"},
+			issueLabels:     []string{"sig/network", "lgtm"},
+			expectedAdded:   formatLabels("sig/storage"),
+			expectedRemoved: formatLabels("sig/network"),
+		},
+		{
+			name:        "files moved away from a matching path keep the label",
+			changes:     []github.PullRequestChange{{Filename: "pkg/proxy/proxy.go", PreviousFilename: "pkg/network/proxy.go"}},
+			repoLabels:  []string{"sig/network", "sig/storage", "kind/documentation"},
+			issueLabels: []string{"sig/network"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fghc := fakegithub.NewFakeClient()
+			fghc.PullRequestChanges = map[int][]github.PullRequestChange{1: tc.changes}
+			fghc.RepoLabelsExisting = tc.repoLabels
+			fghc.IssueLabelsExisting = formatLabels(tc.issueLabels...)
+
+			pre := &github.PullRequestEvent{
+				Action: github.PullRequestActionSynchronize,
+				Number: 1,
+				Repo:   github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+			}
+			if err := handle(fghc, logrus.WithField("plugin", PluginName), rules, pre); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedAdded, fghc.IssueLabelsAdded); diff != "" {
+				t.Errorf("added labels differ from expected (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedRemoved, fghc.IssueLabelsRemoved); diff != "" {
+				t.Errorf("removed labels differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
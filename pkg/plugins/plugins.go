@@ -28,8 +28,6 @@ import (
 	"sync"
 	"time"
 
-	"k8s.io/apimachinery/pkg/util/sets"
-
 	"sigs.k8s.io/prow/pkg/genyaml"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -182,6 +180,8 @@ type Agent struct {
 	SlackClient               *slack.Client
 	BugzillaClient            bugzilla.Client
 	JiraClient                jira.Client
+	// InRepoConfigGetter may be nil, see GetPresubmits.
+	InRepoConfigGetter config.InRepoConfigGetter
 
 	OwnersClient repoowners.Interface
 
@@ -220,6 +220,7 @@ func NewAgent(configAgent *config.Agent, pluginConfigAgent *ConfigAgent, clientA
 		OwnersClient:              clientAgent.OwnersClient.WithFields(logger.Data).WithGitHubClient(gitHubClient).ForPlugin(plugin),
 		BugzillaClient:            clientAgent.BugzillaClient.WithFields(logger.Data).ForPlugin(plugin),
 		JiraClient:                jiraClient,
+		InRepoConfigGetter:        clientAgent.InRepoConfigGetter,
 		Metrics:                   metrics,
 		Config:                    prowConfig,
 		PluginConfig:              pluginConfig,
@@ -227,6 +228,33 @@ func NewAgent(configAgent *config.Agent, pluginConfigAgent *ConfigAgent, clientA
 	}
 }
 
+// GetPresubmits returns the presubmits of a repository, see GetPresubmits.
+func (a *Agent) GetPresubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
+	return GetPresubmits(a.InRepoConfigGetter, a.Config, a.GitClient, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+}
+
+// GetPostsubmits returns the postsubmits of a repository, see GetPostsubmits.
+func (a *Agent) GetPostsubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Postsubmit, error) {
+	return GetPostsubmits(a.InRepoConfigGetter, a.Config, a.GitClient, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+}
+
+// GetPresubmits returns the presubmits of a repository from Moonraker if ircg
+// is set, and from the config and a clone of the repository otherwise.
+func GetPresubmits(ircg config.InRepoConfigGetter, cfg *config.Config, gc git.ClientFactory, identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
+	if ircg != nil {
+		return ircg.GetPresubmits(identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+	}
+	return cfg.GetPresubmits(gc, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+}
+
+// GetPostsubmits returns the postsubmits of a repository, see GetPresubmits.
+func GetPostsubmits(ircg config.InRepoConfigGetter, cfg *config.Config, gc git.ClientFactory, identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Postsubmit, error) {
+	if ircg != nil {
+		return ircg.GetPostsubmits(identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+	}
+	return cfg.GetPostsubmits(gc, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+}
+
 // InitializeCommentPruner attaches a commentpruner.EventClient to the agent to handle
 // pruning comments.
 func (a *Agent) InitializeCommentPruner(org, repo string, pr int) {
@@ -265,6 +293,9 @@ type ClientAgent struct {
 	OwnersClient              repoowners.Interface
 	BugzillaClient            bugzilla.Client
 	JiraClient                jira.Client
+	// InRepoConfigGetter gets the jobs of repositories from Moonraker. It is
+	// nil if hook clones the repositories itself.
+	InRepoConfigGetter config.InRepoConfigGetter
 }
 
 // ConfigAgent contains the agent mutex and the Agent configuration.
@@ -517,15 +548,7 @@ func (pa *ConfigAgent) PushEventHandlers(owner, repo string) map[string]PushEven
 
 // getPlugins returns a list of plugins that are enabled on a given (org, repository).
 func (pa *ConfigAgent) getPlugins(owner, repo string) []string {
-	var plugins []string
-
-	fullName := fmt.Sprintf("%s/%s", owner, repo)
-	if !sets.NewString(pa.configuration.Plugins[owner].ExcludedRepos...).Has(repo) {
-		plugins = append(plugins, pa.configuration.Plugins[owner].Plugins...)
-	}
-	plugins = append(plugins, pa.configuration.Plugins[fullName].Plugins...)
-
-	return plugins
+	return pa.configuration.EnabledPluginsFor(owner, repo)
 }
 
 // EventsForPlugin returns the registered events for the passed plugin.
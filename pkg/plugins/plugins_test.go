@@ -298,6 +298,23 @@ plugins:
 				}
 			}),
 		},
+		{
+			name: "Supplemental configs enabling other plugins for the same repo get merged",
+			config: `
+plugins:
+  org/repo:
+  - wip`,
+			supplementalConfigs: map[string]string{
+				"team-a-extra_config.yaml": `
+plugins:
+  org/repo:
+  - lgtm`,
+			},
+			supplementalPluginConfigFileSuffix: "extra_config.yaml",
+			expected: defaultedConfig(func(c *Configuration) {
+				c.Plugins = Plugins{"org/repo": {Plugins: []string{"wip", "lgtm"}}}
+			}),
+		},
 		{
 			name: "Supplemental configs that do not have right suffix are ignored",
 			config: `
@@ -75,7 +75,7 @@ func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhel
 	projectConfig := config.ProjectManager
 	if len(projectConfig.OrgRepos) == 0 {
 		pluginHelp := &pluginhelp.PluginHelp{
-			Description: "The project-manager plugin automatically adds Pull Requests to specified GitHub Project Columns, if the label on the PR matches with configured project and the column.",
+			Description: "The project-manager plugin automatically adds Pull Requests to specified GitHub Project Columns, if the label on the PR matches with configured project and the column. It only supports classic projects, which GitHub has deprecated; use the projects-v2 plugin for Projects (v2) boards.",
 			Config:      map[string]string{},
 		}
 		return pluginHelp, nil
@@ -119,7 +119,7 @@ func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhel
 		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", pluginName)
 	}
 	pluginHelp := &pluginhelp.PluginHelp{
-		Description: "The project-manager plugin automatically adds Pull Requests to specified GitHub Project Columns, if the label on the PR matches with configured project and the column.",
+		Description: "The project-manager plugin automatically adds Pull Requests to specified GitHub Project Columns, if the label on the PR matches with configured project and the column. It only supports classic projects, which GitHub has deprecated; use the projects-v2 plugin for Projects (v2) boards.",
 		Config:      configString,
 		Snippet:     yamlSnippet,
 	}
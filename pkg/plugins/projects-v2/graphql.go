@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projectsv2
+
+import (
+	"context"
+	"fmt"
+
+	githubql "github.com/shurcooL/githubv4"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// The githubv4 library derives the GraphQL type of mutation inputs from the
+// name of the Go type and predates Projects (v2), so the input types are
+// declared here with the names used by the GitHub schema.
+
+// AddProjectV2ItemByIdInput is the input of the addProjectV2ItemById mutation.
+//
+// See https://docs.github.com/en/graphql/reference/input-objects#addprojectv2itembyidinput
+type AddProjectV2ItemByIdInput struct {
+	ProjectID githubql.ID `json:"projectId"`
+	ContentID githubql.ID `json:"contentId"`
+}
+
+// UpdateProjectV2ItemFieldValueInput is the input of the
+// updateProjectV2ItemFieldValue mutation.
+//
+// See https://docs.github.com/en/graphql/reference/input-objects#updateprojectv2itemfieldvalueinput
+type UpdateProjectV2ItemFieldValueInput struct {
+	ProjectID githubql.ID         `json:"projectId"`
+	ItemID    githubql.ID         `json:"itemId"`
+	FieldID   githubql.ID         `json:"fieldId"`
+	Value     ProjectV2FieldValue `json:"value"`
+}
+
+// ProjectV2FieldValue is the value of a project item field.
+//
+// See https://docs.github.com/en/graphql/reference/input-objects#projectv2fieldvalue
+type ProjectV2FieldValue struct {
+	SingleSelectOptionID githubql.String `json:"singleSelectOptionId"`
+}
+
+type projectQuery struct {
+	RateLimit    github.GraphQLRateLimit
+	Organization struct {
+		ProjectV2 struct {
+			ID     githubql.ID
+			Fields struct {
+				PageInfo github.GraphQLPageInfo
+				Nodes    []struct {
+					SingleSelectField struct {
+						ID      githubql.ID
+						Name    githubql.String
+						Options []struct {
+							ID   githubql.String
+							Name githubql.String
+						}
+					} `graphql:"... on ProjectV2SingleSelectField"`
+				}
+			} `graphql:"fields(first: 100, after: $fieldsCursor)"`
+		} `graphql:"projectV2(number: $number)"`
+	} `graphql:"organization(login: $org)"`
+}
+
+func (q *projectQuery) GraphQLPageInfo() github.GraphQLPageInfo {
+	return q.Organization.ProjectV2.Fields.PageInfo
+}
+
+func (q *projectQuery) GraphQLRateLimit() github.GraphQLRateLimit {
+	return q.RateLimit
+}
+
+type addItemMutation struct {
+	AddProjectV2ItemByID struct {
+		Item struct {
+			ID githubql.ID
+		}
+	} `graphql:"addProjectV2ItemById(input: $input)"`
+}
+
+type updateFieldMutation struct {
+	UpdateProjectV2ItemFieldValue struct {
+		ProjectV2Item struct {
+			ID githubql.ID
+		}
+	} `graphql:"updateProjectV2ItemFieldValue(input: $input)"`
+}
+
+type singleSelectField struct {
+	id      githubql.ID
+	options map[string]githubql.String
+}
+
+type project struct {
+	id     githubql.ID
+	fields map[string]singleSelectField
+}
+
+func getProject(ghc githubClient, org string, number int) (*project, error) {
+	vars := map[string]interface{}{
+		"org":    githubql.String(org),
+		"number": githubql.Int(number),
+	}
+	p := &project{fields: map[string]singleSelectField{}}
+	_, err := github.QueryAllPages(context.Background(), ghc, org, "projects_v2_fields", func() *projectQuery { return &projectQuery{} }, vars, "fieldsCursor", func(q *projectQuery) error {
+		p.id = q.Organization.ProjectV2.ID
+		for _, node := range q.Organization.ProjectV2.Fields.Nodes {
+			f := node.SingleSelectField
+			// Nodes of other field types have no ID in the fragment.
+			if f.ID == nil || f.ID == "" {
+				continue
+			}
+			field := singleSelectField{id: f.ID, options: map[string]githubql.String{}}
+			for _, o := range f.Options {
+				field.options[string(o.Name)] = o.ID
+			}
+			p.fields[string(f.Name)] = field
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if p.id == nil || p.id == "" {
+		return nil, fmt.Errorf("project not found")
+	}
+	return p, nil
+}
+
+// addItem adds an issue or PR to a project. Adding content that is already in
+// the project returns the existing item.
+func addItem(ghc githubClient, org string, projectID githubql.ID, contentID string) (githubql.ID, error) {
+	var m addItemMutation
+	input := AddProjectV2ItemByIdInput{ProjectID: projectID, ContentID: githubql.ID(contentID)}
+	if err := ghc.MutateWithGitHubAppsSupport(context.Background(), &m, input, nil, org); err != nil {
+		return nil, fmt.Errorf("failed to add item: %w", err)
+	}
+	return m.AddProjectV2ItemByID.Item.ID, nil
+}
+
+func setField(ghc githubClient, org string, projectID, itemID, fieldID githubql.ID, optionID githubql.String) error {
+	var m updateFieldMutation
+	input := UpdateProjectV2ItemFieldValueInput{
+		ProjectID: projectID,
+		ItemID:    itemID,
+		FieldID:   fieldID,
+		Value:     ProjectV2FieldValue{SingleSelectOptionID: optionID},
+	}
+	return ghc.MutateWithGitHubAppsSupport(context.Background(), &m, input, nil, org)
+}
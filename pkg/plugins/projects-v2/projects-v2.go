@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package projectsv2 implements the projects-v2 plugin, which adds issues and
+// PRs to GitHub Projects (v2) boards and keeps single select fields of their
+// project items up to date based on labels and job outcomes.
+package projectsv2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/pluginhelp"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	// PluginName defines this plugin's registered name.
+	PluginName = "projects-v2"
+)
+
+func init() {
+	plugins.RegisterIssueHandler(PluginName, handleIssue, helpProvider)
+	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
+	plugins.RegisterStatusEventHandler(PluginName, handleStatus, helpProvider)
+}
+
+func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	// The {WhoCanUse, Usage, Examples} fields are omitted because this plugin cannot be triggered manually.
+	projectConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		var lines []string
+		for _, p := range config.ProjectsV2For(repo.Org, repo.Repo) {
+			org := p.Org
+			if org == "" {
+				org = repo.Org
+			}
+			line := fmt.Sprintf("Issues and PRs are added to project %s/%d", org, p.ProjectNumber)
+			if len(p.Labels) > 0 {
+				line += fmt.Sprintf(" when labeled with any of %q", p.Labels)
+			}
+			lines = append(lines, line+".")
+			for _, r := range p.FieldRules {
+				trigger := fmt.Sprintf("the %q label is present", r.Label)
+				if r.Context != "" {
+					trigger = fmt.Sprintf("the %q context is %s", r.Context, r.State)
+				}
+				lines = append(lines, fmt.Sprintf("&nbsp&nbsp&nbsp&nbspThe %q field is set to %q when %s.", r.Field, r.Value, trigger))
+			}
+		}
+		if len(lines) > 0 {
+			projectConfig[repo.String()] = strings.Join(lines, "<br>")
+		}
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		ProjectsV2: []plugins.ProjectsV2{
+			{
+				Repos:         []string{"ORGANIZATION", "ORGANIZATION/REPOSITORY"},
+				Org:           "ORGANIZATION",
+				ProjectNumber: 1,
+				Labels:        []string{"area/testing"},
+				FieldRules: []plugins.ProjectsV2FieldRule{
+					{Field: "Status", Value: "Triaged", Label: "triage/accepted"},
+					{Field: "CI", Value: "Failing", Context: "pull-unit-tests", State: github.StatusFailure},
+				},
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
+	return &pluginhelp.PluginHelp{
+			Description: "The projects-v2 plugin adds issues and PRs to GitHub Projects (v2) boards and sets single select fields of their project items based on labels and on the status contexts reported by jobs. It replaces the project-manager plugin, which only supports the deprecated classic projects.",
+			Config:      projectConfig,
+			Snippet:     yamlSnippet,
+		},
+		nil
+}
+
+type githubClient interface {
+	QueryWithGitHubAppsSupport(ctx context.Context, q interface{}, vars map[string]interface{}, org string) error
+	MutateWithGitHubAppsSupport(ctx context.Context, m interface{}, input githubql.Input, vars map[string]interface{}, org string) error
+	FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error)
+}
+
+// item is an issue or PR that may be added to a project.
+type item struct {
+	nodeID string
+	isPR   bool
+	labels sets.Set[string]
+}
+
+func labelSet(labels []github.Label) sets.Set[string] {
+	s := sets.New[string]()
+	for _, l := range labels {
+		s.Insert(l.Name)
+	}
+	return s
+}
+
+func handleIssue(pc plugins.Agent, ie github.IssueEvent) error {
+	if ie.Issue.IsPullRequest() {
+		return nil
+	}
+	switch ie.Action {
+	case github.IssueActionOpened, github.IssueActionReopened, github.IssueActionLabeled:
+	default:
+		return nil
+	}
+	org, repo := ie.Repo.Owner.Login, ie.Repo.Name
+	it := item{nodeID: ie.Issue.NodeID, labels: labelSet(ie.Issue.Labels)}
+	return handle(pc.GitHubClient, pc.Logger, pc.PluginConfig.ProjectsV2For(org, repo), org, it, labelTriggered(it))
+}
+
+func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
+	switch pre.Action {
+	case github.PullRequestActionOpened, github.PullRequestActionReopened, github.PullRequestActionLabeled:
+	default:
+		return nil
+	}
+	org, repo := pre.Repo.Owner.Login, pre.Repo.Name
+	it := item{nodeID: pre.PullRequest.NodeID, isPR: true, labels: labelSet(pre.PullRequest.Labels)}
+	return handle(pc.GitHubClient, pc.Logger, pc.PluginConfig.ProjectsV2For(org, repo), org, it, labelTriggered(it))
+}
+
+func handleStatus(pc plugins.Agent, se github.StatusEvent) error {
+	return handleStatusEvent(pc.GitHubClient, pc.Logger, pc.PluginConfig, se)
+}
+
+func handleStatusEvent(ghc githubClient, log *logrus.Entry, cfg *plugins.Configuration, se github.StatusEvent) error {
+	org, repo := se.Repo.Owner.Login, se.Repo.Name
+	configs := cfg.ProjectsV2For(org, repo)
+	triggered := func(r plugins.ProjectsV2FieldRule) bool {
+		return r.Context != "" && r.Context == se.Context && r.State == se.State
+	}
+	// Avoid searching for the PRs of every status that no rule cares about.
+	var relevant []plugins.ProjectsV2
+	for _, p := range configs {
+		for _, r := range p.FieldRules {
+			if triggered(r) {
+				relevant = append(relevant, p)
+				break
+			}
+		}
+	}
+	if len(relevant) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("is:pr is:open repo:%s/%s %s", org, repo, se.SHA)
+	prs, err := ghc.FindIssuesWithOrg(org, query, "", false)
+	if err != nil {
+		return fmt.Errorf("failed to search for PRs with head %s: %w", se.SHA, err)
+	}
+	var errs []error
+	for _, pr := range prs {
+		it := item{nodeID: pr.NodeID, isPR: true, labels: labelSet(pr.Labels)}
+		if err := handle(ghc, log.WithField("pr", pr.Number), relevant, org, it, triggered); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func labelTriggered(it item) func(plugins.ProjectsV2FieldRule) bool {
+	return func(r plugins.ProjectsV2FieldRule) bool {
+		return r.Label != "" && it.labels.Has(r.Label)
+	}
+}
+
+func eligible(p plugins.ProjectsV2, it item) bool {
+	if (it.isPR && p.ExcludePullRequests) || (!it.isPR && p.ExcludeIssues) {
+		return false
+	}
+	return len(p.Labels) == 0 || it.labels.HasAny(p.Labels...)
+}
+
+func handle(ghc githubClient, log *logrus.Entry, configs []plugins.ProjectsV2, org string, it item, triggered func(plugins.ProjectsV2FieldRule) bool) error {
+	var errs []error
+	for _, p := range configs {
+		if !eligible(p, it) {
+			continue
+		}
+		projectOrg := p.Org
+		if projectOrg == "" {
+			projectOrg = org
+		}
+		if err := syncItem(ghc, log.WithField("project", fmt.Sprintf("%s/%d", projectOrg, p.ProjectNumber)), projectOrg, p, it, triggered); err != nil {
+			errs = append(errs, fmt.Errorf("project %s/%d: %w", projectOrg, p.ProjectNumber, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func syncItem(ghc githubClient, log *logrus.Entry, org string, p plugins.ProjectsV2, it item, triggered func(plugins.ProjectsV2FieldRule) bool) error {
+	project, err := getProject(ghc, org, p.ProjectNumber)
+	if err != nil {
+		return err
+	}
+	itemID, err := addItem(ghc, org, project.id, it.nodeID)
+	if err != nil {
+		return err
+	}
+	log.WithField("item", itemID).Debug("Added item to project.")
+
+	var errs []error
+	for _, r := range p.FieldRules {
+		if !triggered(r) {
+			continue
+		}
+		field, ok := project.fields[r.Field]
+		if !ok {
+			errs = append(errs, fmt.Errorf("project has no single select field %q", r.Field))
+			continue
+		}
+		optionID, ok := field.options[r.Value]
+		if !ok {
+			errs = append(errs, fmt.Errorf("field %q has no option %q", r.Field, r.Value))
+			continue
+		}
+		if err := setField(ghc, org, project.id, itemID, field.id, optionID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set field %q to %q: %w", r.Field, r.Value, err))
+			continue
+		}
+		log.WithField("item", itemID).Infof("Set field %q to %q.", r.Field, r.Value)
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projectsv2
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+type fakeClient struct {
+	// projects maps project numbers to single select fields and their options.
+	projects map[int]map[string][]string
+	prs      []github.Issue
+	queries  []string
+
+	added   []string
+	updated []string
+}
+
+// QueryWithGitHubAppsSupport returns one field per page to exercise the
+// pagination of the project fields.
+func (f *fakeClient) QueryWithGitHubAppsSupport(_ context.Context, q interface{}, vars map[string]interface{}, _ string) error {
+	pq := q.(*projectQuery)
+	number := int(vars["number"].(githubql.Int))
+	fields, ok := f.projects[number]
+	if !ok {
+		return nil
+	}
+	pq.Organization.ProjectV2.ID = "project"
+	names := sets.List(sets.KeySet(fields))
+	page := 0
+	if cursor, ok := vars["fieldsCursor"].(*githubql.String); ok && cursor != nil {
+		page, _ = strconv.Atoi(string(*cursor))
+	}
+	if page >= len(names) {
+		return nil
+	}
+	name := names[page]
+	node := pq.Organization.ProjectV2.Fields.Nodes
+	node = append(node, struct {
+		SingleSelectField struct {
+			ID      githubql.ID
+			Name    githubql.String
+			Options []struct {
+				ID   githubql.String
+				Name githubql.String
+			}
+		} `graphql:"... on ProjectV2SingleSelectField"`
+	}{})
+	field := &node[len(node)-1].SingleSelectField
+	field.ID = "field-" + name
+	field.Name = githubql.String(name)
+	for _, o := range fields[name] {
+		field.Options = append(field.Options, struct {
+			ID   githubql.String
+			Name githubql.String
+		}{ID: githubql.String("option-" + o), Name: githubql.String(o)})
+	}
+	pq.Organization.ProjectV2.Fields.Nodes = node
+	pq.Organization.ProjectV2.Fields.PageInfo = github.GraphQLPageInfo{
+		HasNextPage: page+1 < len(names),
+		EndCursor:   githubql.String(strconv.Itoa(page + 1)),
+	}
+	return nil
+}
+
+func (f *fakeClient) MutateWithGitHubAppsSupport(_ context.Context, m interface{}, input githubql.Input, _ map[string]interface{}, _ string) error {
+	switch mutation := m.(type) {
+	case *addItemMutation:
+		in := input.(AddProjectV2ItemByIdInput)
+		f.added = append(f.added, in.ContentID.(string))
+		mutation.AddProjectV2ItemByID.Item.ID = "item-" + in.ContentID.(string)
+	case *updateFieldMutation:
+		in := input.(UpdateProjectV2ItemFieldValueInput)
+		f.updated = append(f.updated, in.ItemID.(string)+":"+in.FieldID.(string)+"="+string(in.Value.SingleSelectOptionID))
+	default:
+		return errors.New("unexpected mutation")
+	}
+	return nil
+}
+
+func (f *fakeClient) FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error) {
+	f.queries = append(f.queries, query)
+	return f.prs, nil
+}
+
+func TestHandle(t *testing.T) {
+	projects := map[int]map[string][]string{
+		1: {"Status": {"Todo", "Triaged"}, "CI": {"Passing", "Failing"}},
+	}
+	testcases := []struct {
+		name            string
+		configs         []plugins.ProjectsV2
+		item            item
+		expectedAdded   []string
+		expectedUpdated []string
+		expectedErr     bool
+	}{
+		{
+			name:          "item added without label filter",
+			configs:       []plugins.ProjectsV2{{ProjectNumber: 1}},
+			item:          item{nodeID: "I_1", labels: labelSet(nil)},
+			expectedAdded: []string{"I_1"},
+		},
+		{
+			name:    "item without filtered label is not added",
+			configs: []plugins.ProjectsV2{{ProjectNumber: 1, Labels: []string{"area/testing"}}},
+			item:    item{nodeID: "I_1", labels: labelSet([]github.Label{{Name: "area/docs"}})},
+		},
+		{
+			name:    "excluded PR is not added",
+			configs: []plugins.ProjectsV2{{ProjectNumber: 1, ExcludePullRequests: true}},
+			item:    item{nodeID: "PR_1", isPR: true, labels: labelSet(nil)},
+		},
+		{
+			name: "label rule sets field",
+			configs: []plugins.ProjectsV2{{
+				ProjectNumber: 1,
+				Labels:        []string{"area/testing"},
+				FieldRules: []plugins.ProjectsV2FieldRule{
+					{Field: "Status", Value: "Triaged", Label: "triage/accepted"},
+					{Field: "Status", Value: "Todo", Label: "needs-triage"},
+				},
+			}},
+			item:            item{nodeID: "I_1", labels: labelSet([]github.Label{{Name: "area/testing"}, {Name: "triage/accepted"}})},
+			expectedAdded:   []string{"I_1"},
+			expectedUpdated: []string{"item-I_1:field-Status=option-Triaged"},
+		},
+		{
+			name: "unknown option is an error",
+			configs: []plugins.ProjectsV2{{
+				ProjectNumber: 1,
+				FieldRules:    []plugins.ProjectsV2FieldRule{{Field: "Status", Value: "Done", Label: "triage/accepted"}},
+			}},
+			item:          item{nodeID: "I_1", labels: labelSet([]github.Label{{Name: "triage/accepted"}})},
+			expectedAdded: []string{"I_1"},
+			expectedErr:   true,
+		},
+		{
+			name:        "unknown project is an error",
+			configs:     []plugins.ProjectsV2{{ProjectNumber: 2}},
+			item:        item{nodeID: "I_1", labels: labelSet(nil)},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fakeClient{projects: projects}
+			err := handle(fc, logrus.WithField("plugin", PluginName), tc.configs, "org", tc.item, labelTriggered(tc.item))
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expectedAdded, fc.added); diff != "" {
+				t.Errorf("added items differ from expected (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedUpdated, fc.updated); diff != "" {
+				t.Errorf("updated fields differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleStatusEvent(t *testing.T) {
+	cfg := &plugins.Configuration{
+		ProjectsV2: []plugins.ProjectsV2{{
+			Repos:         []string{"org/repo"},
+			ProjectNumber: 1,
+			FieldRules: []plugins.ProjectsV2FieldRule{
+				{Field: "CI", Value: "Failing", Context: "pull-unit", State: github.StatusFailure},
+				{Field: "CI", Value: "Passing", Context: "pull-unit", State: github.StatusSuccess},
+			},
+		}},
+	}
+	testcases := []struct {
+		name            string
+		context         string
+		state           string
+		expectedQueries []string
+		expectedUpdated []string
+	}{
+		{
+			name:            "failing context sets field",
+			context:         "pull-unit",
+			state:           github.StatusFailure,
+			expectedQueries: []string{"is:pr is:open repo:org/repo sha"},
+			expectedUpdated: []string{"item-PR_1:field-CI=option-Failing"},
+		},
+		{
+			name:    "pending context is ignored",
+			context: "pull-unit",
+			state:   github.StatusPending,
+		},
+		{
+			name:    "other context is ignored",
+			context: "pull-e2e",
+			state:   github.StatusFailure,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fakeClient{
+				projects: map[int]map[string][]string{1: {"CI": {"Passing", "Failing"}}},
+				prs:      []github.Issue{{Number: 1, NodeID: "PR_1"}},
+			}
+			se := github.StatusEvent{
+				SHA:     "sha",
+				Context: tc.context,
+				State:   tc.state,
+				Repo:    github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+			}
+			if err := handleStatusEvent(fc, logrus.WithField("plugin", PluginName), cfg, se); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedQueries, fc.queries); diff != "" {
+				t.Errorf("queries differ from expected (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedUpdated, fc.updated); diff != "" {
+				t.Errorf("updated fields differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -72,13 +72,54 @@ func init() {
 	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
 }
 
-func helpProvider(_ *plugins.Configuration, _ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	schemaConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		cfg := config.ReleaseNoteFor(repo.Org, repo.Repo)
+		if !cfg.HasSchema() {
+			continue
+		}
+		var rules []string
+		if len(cfg.RequiredSections) > 0 {
+			rules = append(rules, fmt.Sprintf("contain the sections %s", strings.Join(cfg.RequiredSections, ", ")))
+		}
+		if len(cfg.AllowedKinds) > 0 {
+			rules = append(rules, fmt.Sprintf("have one of the kinds %s", strings.Join(cfg.AllowedKinds, ", ")))
+		}
+		if cfg.MaxLength > 0 {
+			rules = append(rules, fmt.Sprintf("be at most %d characters long", cfg.MaxLength))
+		}
+		schemaConfig[repo.String()] = "Release notes in this repository must " + strings.Join(rules, " and ") + "."
+		if cfg.StatusContext != "" {
+			schemaConfig[repo.String()] += fmt.Sprintf(" The result is reported as the %q status.", cfg.StatusContext)
+		}
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		ReleaseNote: []plugins.ReleaseNote{
+			{
+				Repos: []string{
+					"ORGANIZATION",
+					"ORGANIZATION/REPOSITORY",
+				},
+				RequiredSections: []string{"Kind"},
+				AllowedKinds:     []string{"feature", "bug", "deprecation"},
+				MaxLength:        500,
+				StatusContext:    "release-note",
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
 	pluginHelp := &pluginhelp.PluginHelp{
+		Config:  schemaConfig,
+		Snippet: yamlSnippet,
 		Description: `The releasenote plugin implements a release note process that uses a markdown 'release-note' code block to associate a release note with a pull request. Until the 'release-note' block in the pull request body is populated the PR will be assigned the '` + labels.ReleaseNoteLabelNeeded + `' label.
 <br>There are three valid types of release notes that can replace this label:
 <ol><li>PRs with a normal release note in the 'release-note' block are given the label '` + labels.ReleaseNote + `'.</li>
 <li>PRs that have a release note of 'none' in the block are given the label '` + labels.ReleaseNoteNone + `' to indicate that the PR does not warrant a release note.</li>
 <li>PRs that contain 'action required' in their 'release-note' block are given the label '` + labels.ReleaseNoteActionRequired + `' to indicate that the PR introduces potentially breaking changes that necessitate user action before upgrading to the release.</li></ol>
+Repositories can additionally configure a schema the release note has to match. Until it does, the '` + labels.ReleaseNoteLabelNeeded + `' label stays on the PR and a comment explains what needs to be fixed.
 ` + "To use the plugin, in the pull request body text:\n\n```release-note\n<release note content>\n```",
 	}
 	// NOTE: the other two commands re deprecated, so we're not documenting them
@@ -107,6 +148,7 @@ type githubClient interface {
 	DeleteStaleComments(org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error
 	BotUserChecker() (func(candidate string) bool, error)
 	EditIssue(org, repo string, number int, issue *github.Issue) (*github.Issue, error)
+	CreateStatus(org, repo, ref string, s github.Status) error
 }
 
 func handleIssueComment(pc plugins.Agent, ic github.IssueCommentEvent) error {
@@ -215,7 +257,7 @@ func removeOtherLabels(remover func(string) error, label string, labelSet []stri
 }
 
 func handlePullRequest(pc plugins.Agent, pr github.PullRequestEvent) error {
-	return handlePR(pc.GitHubClient, pc.Logger, &pr)
+	return handlePR(pc.GitHubClient, pc.Logger, pc.PluginConfig.ReleaseNoteFor(pr.Repo.Owner.Login, pr.Repo.Name), &pr)
 }
 
 func shouldHandlePR(pr *github.PullRequestEvent) bool {
@@ -239,7 +281,7 @@ func shouldHandlePR(pr *github.PullRequestEvent) bool {
 	return true
 }
 
-func handlePR(gc githubClient, log *logrus.Entry, pr *github.PullRequestEvent) error {
+func handlePR(gc githubClient, log *logrus.Entry, cfg *plugins.ReleaseNote, pr *github.PullRequestEvent) error {
 	if !shouldHandlePR(pr) {
 		return nil
 	}
@@ -288,6 +330,22 @@ func handlePR(gc githubClient, log *logrus.Entry, pr *github.PullRequestEvent) e
 		}
 	}
 
+	// Hold back the release note label until the release note matches the
+	// schema configured for the repo.
+	var problems []string
+	if cfg.HasSchema() && !pr.PullRequest.Merged {
+		if labelToAdd == labels.ReleaseNote || labelToAdd == labels.ReleaseNoteActionRequired {
+			problems = schemaProblems(getReleaseNote(pr.PullRequest.Body), cfg)
+		}
+		if len(problems) > 0 {
+			labelToAdd = labels.ReleaseNoteLabelNeeded
+		}
+		if err := syncSchemaComment(gc, pr, problems); err != nil {
+			log.WithError(err).Errorf("Failed to update the release note schema comment on %s/%s#%d.", org, repo, pr.Number)
+		}
+	}
+	reportSchemaStatus(gc, log, pr, cfg, labelToAdd, problems)
+
 	// Add the label if needed
 	if !prLabels.Has(labelToAdd) {
 		if err = gc.AddLabel(org, repo, pr.Number, labelToAdd); err != nil {
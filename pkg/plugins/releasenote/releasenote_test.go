@@ -23,12 +23,14 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/github/fakegithub"
 	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/plugins"
 )
 
 func TestReleaseNoteComment(t *testing.T) {
@@ -489,7 +491,7 @@ func TestReleaseNotePR(t *testing.T) {
 		fc, pr := newFakeClient(test.body, test.branch, test.initialLabels, test.issueComments, test.parentPRs)
 		pr.PullRequest.Merged = test.merged
 
-		err := handlePR(fc, logrus.WithField("plugin", PluginName), pr)
+		err := handlePR(fc, logrus.WithField("plugin", PluginName), nil, pr)
 		if err != nil {
 			t.Fatalf("Unexpected error from handlePR: %v", err)
 		}
@@ -513,6 +515,137 @@ func TestReleaseNotePR(t *testing.T) {
 	}
 }
 
+func TestReleaseNoteSchema(t *testing.T) {
+	cfg := &plugins.ReleaseNote{
+		RequiredSections: []string{"Component"},
+		AllowedKinds:     []string{"feature", "bug"},
+		MaxLength:        60,
+		StatusContext:    "release-note",
+	}
+	tests := []struct {
+		name             string
+		body             string
+		cfg              *plugins.ReleaseNote
+		expectedLabel    string
+		expectedProblems []string
+		expectedState    string
+	}{
+		{
+			name:          "valid release note",
+			body:          "```release-note\nKind: Feature\nComponent: hook\nAdd a flag.\n```",
+			cfg:           cfg,
+			expectedLabel: labels.ReleaseNote,
+			expectedState: github.StatusSuccess,
+		},
+		{
+			name:          "release note none is not validated",
+			body:          "```release-note\nNONE\n```",
+			cfg:           cfg,
+			expectedLabel: labels.ReleaseNoteNone,
+			expectedState: github.StatusSuccess,
+		},
+		{
+			name:          "missing release note fails the status",
+			body:          "```release-note\n```",
+			cfg:           cfg,
+			expectedLabel: labels.ReleaseNoteLabelNeeded,
+			expectedState: github.StatusFailure,
+		},
+		{
+			name:          "missing sections",
+			body:          "```release-note\nAdd a flag.\n```",
+			cfg:           cfg,
+			expectedLabel: labels.ReleaseNoteLabelNeeded,
+			expectedProblems: []string{
+				"The required section `Component` is missing. Add a line starting with `Component:` to the release note.",
+				"The kind of the change is missing. Add a line like `Kind: feature` to the release note.",
+			},
+			expectedState: github.StatusFailure,
+		},
+		{
+			name:          "disallowed kind and too long",
+			body:          "```release-note\nKind: cleanup\nComponent: hook\nRemove a long deprecated flag now.\n```",
+			cfg:           cfg,
+			expectedLabel: labels.ReleaseNoteLabelNeeded,
+			expectedProblems: []string{
+				"The kind `cleanup` is not allowed. Use one of: feature, bug.",
+				"The release note is 64 characters long, which exceeds the maximum of 60 characters. Shorten it and link to further documentation instead.",
+			},
+			expectedState: github.StatusFailure,
+		},
+		{
+			name:          "no status without status context",
+			body:          "```release-note\nAdd a flag.\n```",
+			cfg:           &plugins.ReleaseNote{AllowedKinds: []string{"feature"}},
+			expectedLabel: labels.ReleaseNoteLabelNeeded,
+			expectedProblems: []string{
+				"The kind of the change is missing. Add a line like `Kind: feature` to the release note.",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fc, pr := newFakeClient(test.body, "master", nil, nil, nil)
+			pr.PullRequest.Head.SHA = "sha"
+			// Handle the event twice to verify that comments are not duplicated.
+			for i := 0; i < 2; i++ {
+				if err := handlePR(fc, logrus.WithField("plugin", PluginName), test.cfg, pr); err != nil {
+					t.Fatalf("Unexpected error from handlePR: %v", err)
+				}
+			}
+
+			if expected := formatLabels(1, test.expectedLabel); !reflect.DeepEqual(expected, fc.IssueLabelsAdded) {
+				t.Errorf("Expected labels to be added: %q, but got: %q.", expected, fc.IssueLabelsAdded)
+			}
+			var schemaComments []string
+			for _, c := range fc.IssueComments[1] {
+				if strings.Contains(c.Body, schemaMismatchBody) {
+					schemaComments = append(schemaComments, c.Body)
+				}
+			}
+			var expectedComments []string
+			if len(test.expectedProblems) > 0 {
+				expectedComments = []string{schemaMismatchComment("cjwagner", test.expectedProblems)}
+			}
+			if diff := cmp.Diff(expectedComments, schemaComments); diff != "" {
+				t.Errorf("Unexpected schema comments (-want +got):\n%s", diff)
+			}
+			statuses := fc.CreatedStatuses["sha"]
+			if test.expectedState == "" {
+				if len(statuses) != 0 {
+					t.Errorf("Expected no statuses, but got %v.", statuses)
+				}
+				return
+			}
+			if len(statuses) == 0 {
+				t.Fatalf("Expected a status, but got none.")
+			}
+			if status := statuses[len(statuses)-1]; status.Context != test.cfg.StatusContext || status.State != test.expectedState {
+				t.Errorf("Expected status %q with state %q, but got %+v.", test.cfg.StatusContext, test.expectedState, status)
+			}
+		})
+	}
+}
+
+func TestReleaseNoteSchemaCommentUpdated(t *testing.T) {
+	cfg := &plugins.ReleaseNote{AllowedKinds: []string{"feature"}}
+	fc, pr := newFakeClient("```release-note\nAdd a flag.\n```", "master", nil, nil, nil)
+	if err := handlePR(fc, logrus.WithField("plugin", PluginName), cfg, pr); err != nil {
+		t.Fatalf("Unexpected error from handlePR: %v", err)
+	}
+	if len(fc.IssueComments[1]) != 1 {
+		t.Fatalf("Expected a schema comment, but got %v.", fc.IssueComments[1])
+	}
+
+	pr.PullRequest.Body = "```release-note\nKind: feature\nAdd a flag.\n```"
+	if err := handlePR(fc, logrus.WithField("plugin", PluginName), cfg, pr); err != nil {
+		t.Fatalf("Unexpected error from handlePR: %v", err)
+	}
+	if len(fc.IssueComments[1]) != 0 {
+		t.Errorf("Expected the schema comment to be deleted, but got %v.", fc.IssueComments[1])
+	}
+}
+
 func TestGetReleaseNote(t *testing.T) {
 	tests := []struct {
 		body                        string
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releasenote
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	schemaMismatchBody = "The release note in the `release-note` block of the PR description does not match the release note schema of this repository."
+	// kindSection is the section of a release note whose value is checked
+	// against the allowed kinds.
+	kindSection = "kind"
+)
+
+var sectionRe = regexp.MustCompile(`(?m)^\s*([A-Za-z][A-Za-z0-9 _-]*?)\s*:[ \t]*(.*)$`)
+
+// schemaProblems returns actionable descriptions of the ways the release note
+// does not match the schema configured for the repo.
+func schemaProblems(note string, cfg *plugins.ReleaseNote) []string {
+	sections := map[string]string{}
+	for _, match := range sectionRe.FindAllStringSubmatch(note, -1) {
+		name := strings.ToLower(match[1])
+		if _, ok := sections[name]; !ok {
+			sections[name] = strings.TrimSpace(match[2])
+		}
+	}
+
+	var problems []string
+	for _, section := range cfg.RequiredSections {
+		if _, ok := sections[strings.ToLower(section)]; !ok {
+			problems = append(problems, fmt.Sprintf("The required section `%s` is missing. Add a line starting with `%s:` to the release note.", section, section))
+		}
+	}
+	if len(cfg.AllowedKinds) > 0 {
+		allowed := sets.New[string]()
+		for _, kind := range cfg.AllowedKinds {
+			allowed.Insert(strings.ToLower(kind))
+		}
+		kind, ok := sections[kindSection]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("The kind of the change is missing. Add a line like `Kind: %s` to the release note.", cfg.AllowedKinds[0]))
+		case !allowed.Has(strings.ToLower(kind)):
+			problems = append(problems, fmt.Sprintf("The kind `%s` is not allowed. Use one of: %s.", kind, strings.Join(cfg.AllowedKinds, ", ")))
+		}
+	}
+	if length := utf8.RuneCountInString(note); cfg.MaxLength > 0 && length > cfg.MaxLength {
+		problems = append(problems, fmt.Sprintf("The release note is %d characters long, which exceeds the maximum of %d characters. Shorten it and link to further documentation instead.", length, cfg.MaxLength))
+	}
+	return problems
+}
+
+// schemaMismatchComment returns the comment explaining why the release note
+// does not match the schema.
+func schemaMismatchComment(author string, problems []string) string {
+	var reasons []string
+	for _, problem := range problems {
+		reasons = append(reasons, "- "+problem)
+	}
+	return plugins.FormatResponse(author, schemaMismatchBody, strings.Join(reasons, "\n")+"\n\nPlease update the `release-note` block in the PR description.")
+}
+
+// syncSchemaComment ensures that the PR has a single up-to-date comment
+// explaining the schema problems of its release note, or none if there are
+// no problems.
+func syncSchemaComment(gc githubClient, pr *github.PullRequestEvent, problems []string) error {
+	org := pr.Repo.Owner.Login
+	repo := pr.Repo.Name
+	comments, err := gc.ListIssueComments(org, repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to list comments on %s/%s#%d. err: %w", org, repo, pr.Number, err)
+	}
+	botUserChecker, err := gc.BotUserChecker()
+	if err != nil {
+		return err
+	}
+
+	var expected string
+	if len(problems) > 0 {
+		expected = schemaMismatchComment(pr.PullRequest.User.Login, problems)
+	}
+	var upToDate bool
+	if err := gc.DeleteStaleComments(org, repo, pr.Number, comments, func(c github.IssueComment) bool {
+		if !botUserChecker(c.User.Login) || !strings.Contains(c.Body, schemaMismatchBody) {
+			return false
+		}
+		if c.Body == expected && !upToDate {
+			upToDate = true
+			return false
+		}
+		return true
+	}); err != nil {
+		return err
+	}
+	if expected == "" || upToDate {
+		return nil
+	}
+	return gc.CreateComment(org, repo, pr.Number, expected)
+}
+
+// reportSchemaStatus reports the status of the release note of the PR if a
+// status context is configured.
+func reportSchemaStatus(gc githubClient, log *logrus.Entry, pr *github.PullRequestEvent, cfg *plugins.ReleaseNote, labelToAdd string, problems []string) {
+	if cfg == nil || cfg.StatusContext == "" {
+		return
+	}
+	status := github.Status{Context: cfg.StatusContext, State: github.StatusSuccess}
+	switch {
+	case labelToAdd == labels.ReleaseNoteLabelNeeded && len(problems) == 0:
+		status.State = github.StatusFailure
+		status.Description = "A release-note block is required."
+	case len(problems) > 0:
+		status.State = github.StatusFailure
+		status.Description = fmt.Sprintf("The release note does not match the schema: %d problem(s).", len(problems))
+	case labelToAdd == labels.ReleaseNoteNone:
+		status.Description = "No release note is required."
+	default:
+		status.Description = "The release note is valid."
+	}
+	org := pr.Repo.Owner.Login
+	repo := pr.Repo.Name
+	if err := gc.CreateStatus(org, repo, pr.PullRequest.Head.SHA, status); err != nil {
+		log.WithError(err).Errorf("Failed to report status %q on %s/%s#%d.", cfg.StatusContext, org, repo, pr.Number)
+	}
+}
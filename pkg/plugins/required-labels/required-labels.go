@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requiredlabels implements the required-labels plugin, which reports
+// a status context on PRs that are missing required labels or carry blocking
+// labels.
+package requiredlabels
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/pluginhelp"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	// PluginName defines this plugin's registered name.
+	PluginName = "required-labels"
+
+	// maxStatusDescriptionLength is the maximum length GitHub accepts for
+	// status descriptions.
+	maxStatusDescriptionLength = 140
+)
+
+func init() {
+	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
+}
+
+func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	// The {WhoCanUse, Usage, Examples} fields are omitted because this plugin cannot be triggered manually.
+	labelConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		var buf bytes.Buffer
+		fmt.Fprint(&buf, "The following label requirements are reported on PRs in this repository:")
+		for _, r := range config.RequiredLabels {
+			repos := sets.New[string](r.Repos...)
+			if !repos.Has(repo.Org) && !repos.Has(repo.String()) {
+				continue
+			}
+			branches := "all branches"
+			if len(r.Branches) > 0 {
+				branches = strings.Join(r.Branches, ", ")
+			}
+			fmt.Fprintf(&buf, "<br>&nbsp&nbsp&nbsp&nbsp'%s' (%s): required %q, blocking %q", r.Context, branches, r.Labels, r.BlockingLabels)
+		}
+		labelConfig[repo.String()] = buf.String()
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		RequiredLabels: []plugins.RequiredLabels{
+			{
+				Repos: []string{
+					"ORGANIZATION",
+					"ORGANIZATION/REPOSITORY",
+				},
+				Branches:       []string{"main"},
+				Labels:         []string{"lgtm", "approved"},
+				BlockingLabels: []string{"do-not-merge/hold"},
+				Context:        "required-labels",
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
+	return &pluginhelp.PluginHelp{
+			Description: "The required-labels plugin reports a status context that fails while a PR is missing any of the configured required labels or carries any of the configured blocking labels. This makes the label requirements of merge automation such as Tide visible on the PR itself.",
+			Config:      labelConfig,
+			Snippet:     yamlSnippet,
+		},
+		nil
+}
+
+type githubClient interface {
+	CreateStatus(org, repo, ref string, s github.Status) error
+}
+
+func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
+	return handlePR(pc.GitHubClient, pc.Logger, pc.PluginConfig, &pre)
+}
+
+func handlePR(ghc githubClient, log *logrus.Entry, cfg *plugins.Configuration, pre *github.PullRequestEvent) error {
+	switch pre.Action {
+	case github.PullRequestActionOpened, github.PullRequestActionReopened, github.PullRequestActionSynchronize,
+		github.PullRequestActionLabeled, github.PullRequestActionUnlabeled, github.PullRequestActionEdited:
+	default:
+		return nil
+	}
+	if pre.PullRequest.Merged || pre.PullRequest.State != github.PullRequestStateOpen {
+		return nil
+	}
+	configs := cfg.RequiredLabelsFor(pre.Repo.Owner.Login, pre.Repo.Name, pre.PullRequest.Base.Ref)
+	if len(configs) == 0 {
+		return nil
+	}
+	return handle(ghc, log, configs, &pre.PullRequest)
+}
+
+func handle(ghc githubClient, log *logrus.Entry, configs []plugins.RequiredLabels, pr *github.PullRequest) error {
+	org := pr.Base.Repo.Owner.Login
+	repo := pr.Base.Repo.Name
+
+	current := sets.New[string]()
+	for _, label := range pr.Labels {
+		current.Insert(label.Name)
+	}
+
+	// Several configurations may share a context, in which case their
+	// requirements are combined into a single status.
+	var contexts []string
+	required := map[string]sets.Set[string]{}
+	blocking := map[string]sets.Set[string]{}
+	for _, c := range configs {
+		if _, seen := required[c.Context]; !seen {
+			contexts = append(contexts, c.Context)
+			required[c.Context] = sets.New[string]()
+			blocking[c.Context] = sets.New[string]()
+		}
+		required[c.Context].Insert(c.Labels...)
+		blocking[c.Context].Insert(c.BlockingLabels...)
+	}
+
+	var errs []error
+	for _, context := range contexts {
+		status := github.Status{
+			Context:     context,
+			State:       github.StatusSuccess,
+			Description: "All required labels are present.",
+		}
+		missing := required[context].Difference(current)
+		present := blocking[context].Intersection(current)
+		if missing.Len() > 0 || present.Len() > 0 {
+			status.State = github.StatusFailure
+			status.Description = description(sets.List(missing), sets.List(present))
+		}
+		log.WithField("context", context).Debugf("Setting %s status: %s", status.State, status.Description)
+		if err := ghc.CreateStatus(org, repo, pr.Head.SHA, status); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set %s status: %w", context, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func description(missing, blocking []string) string {
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, fmt.Sprintf("Needs %s label(s)", strings.Join(missing, ", ")))
+	}
+	if len(blocking) > 0 {
+		parts = append(parts, fmt.Sprintf("Should not have %s label(s)", strings.Join(blocking, ", ")))
+	}
+	desc := strings.Join(parts, ". ") + "."
+	if len(desc) > maxStatusDescriptionLength {
+		desc = desc[:maxStatusDescriptionLength-3] + "..."
+	}
+	return desc
+}
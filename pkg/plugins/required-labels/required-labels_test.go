@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requiredlabels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestHandle(t *testing.T) {
+	testcases := []struct {
+		name     string
+		configs  []plugins.RequiredLabels
+		labels   []string
+		expected []github.Status
+	}{
+		{
+			name:    "all required labels present",
+			configs: []plugins.RequiredLabels{{Labels: []string{"lgtm", "approved"}, Context: "required-labels"}},
+			labels:  []string{"approved", "lgtm", "kind/bug"},
+			expected: []github.Status{
+				{Context: "required-labels", State: github.StatusSuccess, Description: "All required labels are present."},
+			},
+		},
+		{
+			name:    "missing required labels",
+			configs: []plugins.RequiredLabels{{Labels: []string{"lgtm", "approved"}, Context: "required-labels"}},
+			labels:  []string{"kind/bug"},
+			expected: []github.Status{
+				{Context: "required-labels", State: github.StatusFailure, Description: "Needs approved, lgtm label(s)."},
+			},
+		},
+		{
+			name:    "blocking label present",
+			configs: []plugins.RequiredLabels{{Labels: []string{"lgtm"}, BlockingLabels: []string{"do-not-merge/hold"}, Context: "required-labels"}},
+			labels:  []string{"lgtm", "do-not-merge/hold"},
+			expected: []github.Status{
+				{Context: "required-labels", State: github.StatusFailure, Description: "Should not have do-not-merge/hold label(s)."},
+			},
+		},
+		{
+			name: "configs sharing a context are combined",
+			configs: []plugins.RequiredLabels{
+				{Labels: []string{"lgtm"}, Context: "required-labels"},
+				{Labels: []string{"approved"}, BlockingLabels: []string{"needs-rebase"}, Context: "required-labels"},
+			},
+			labels: []string{"lgtm", "needs-rebase"},
+			expected: []github.Status{
+				{Context: "required-labels", State: github.StatusFailure, Description: "Needs approved label(s). Should not have needs-rebase label(s)."},
+			},
+		},
+		{
+			name: "configs with distinct contexts report separately",
+			configs: []plugins.RequiredLabels{
+				{Labels: []string{"lgtm"}, Context: "required-labels"},
+				{Labels: []string{"cherry-pick-approved"}, Context: "release-labels"},
+			},
+			labels: []string{"lgtm"},
+			expected: []github.Status{
+				{Context: "required-labels", State: github.StatusSuccess, Description: "All required labels are present."},
+				{Context: "release-labels", State: github.StatusFailure, Description: "Needs cherry-pick-approved label(s)."},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fghc := fakegithub.NewFakeClient()
+			pr := &github.PullRequest{
+				Base: github.PullRequestBranch{Ref: "main", Repo: github.Repo{Owner: github.User{Login: "org"}, Name: "repo"}},
+				Head: github.PullRequestBranch{SHA: "sha"},
+			}
+			for _, label := range tc.labels {
+				pr.Labels = append(pr.Labels, github.Label{Name: label})
+			}
+			if err := handle(fghc, logrus.WithField("plugin", PluginName), tc.configs, pr); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fghc.CreatedStatuses["sha"]); diff != "" {
+				t.Errorf("statuses differ from expected (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePR(t *testing.T) {
+	cfg := &plugins.Configuration{
+		RequiredLabels: []plugins.RequiredLabels{
+			{Repos: []string{"org/repo"}, Branches: []string{"main"}, Labels: []string{"lgtm"}, Context: "required-labels"},
+		},
+	}
+	testcases := []struct {
+		name           string
+		action         github.PullRequestEventAction
+		branch         string
+		state          string
+		expectStatuses bool
+	}{
+		{name: "labeled PR against configured branch", action: github.PullRequestActionLabeled, branch: "main", state: github.PullRequestStateOpen, expectStatuses: true},
+		{name: "opened PR against configured branch", action: github.PullRequestActionOpened, branch: "main", state: github.PullRequestStateOpen, expectStatuses: true},
+		{name: "PR against other branch", action: github.PullRequestActionLabeled, branch: "release-1.0", state: github.PullRequestStateOpen},
+		{name: "closed PR", action: github.PullRequestActionLabeled, branch: "main", state: github.PullRequestStateClosed},
+		{name: "irrelevant action", action: github.PullRequestActionAssigned, branch: "main", state: github.PullRequestStateOpen},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fghc := fakegithub.NewFakeClient()
+			repo := github.Repo{Owner: github.User{Login: "org"}, Name: "repo"}
+			pre := github.PullRequestEvent{
+				Action: tc.action,
+				Repo:   repo,
+				PullRequest: github.PullRequest{
+					State: tc.state,
+					Base:  github.PullRequestBranch{Ref: tc.branch, Repo: repo},
+					Head:  github.PullRequestBranch{SHA: "sha"},
+				},
+			}
+			if err := handlePR(fghc, logrus.WithField("plugin", PluginName), cfg, &pre); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := len(fghc.CreatedStatuses["sha"]) > 0; got != tc.expectStatuses {
+				t.Errorf("expected statuses to be created: %t, got %t", tc.expectStatuses, got)
+			}
+		})
+	}
+}
+
+func TestDescriptionTruncated(t *testing.T) {
+	desc := description([]string{strings.Repeat("a", 100), strings.Repeat("b", 100)}, nil)
+	if len(desc) != maxStatusDescriptionLength {
+		t.Errorf("expected description of length %d, got %d", maxStatusDescriptionLength, len(desc))
+	}
+	if !strings.HasSuffix(desc, "...") {
+		t.Errorf("expected truncated description to end with ellipsis, got %q", desc)
+	}
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shadow implements the shadow plugin, which runs jobs as they are
+// configured in a pull request to the Prow config against the latest merge of
+// the repositories they belong to. The results are reported to the pull
+// request only, so broken job definitions are caught before they merge and
+// block everyone.
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/git/types"
+	"sigs.k8s.io/prow/pkg/git/v2"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/pluginhelp"
+	"sigs.k8s.io/prow/pkg/plugins"
+	"sigs.k8s.io/prow/pkg/plugins/trigger"
+)
+
+const (
+	// PluginName defines this plugin's registered name.
+	PluginName = "shadow"
+
+	// ShadowLabel marks the ProwJobs run by this plugin.
+	ShadowLabel = "prow.k8s.io/shadow"
+
+	// contextPrefix is prepended to the contexts of shadow runs, so that they
+	// can not be mistaken for the jobs of the pull request itself.
+	contextPrefix = "shadow/"
+)
+
+var shadowRe = regexp.MustCompile(`(?mi)^/shadow((?:[ \t]+\S+)+)[ \t]*$`)
+
+func init() {
+	plugins.RegisterGenericCommentHandler(PluginName, handleGenericComment, helpProvider)
+}
+
+func helpProvider(cfg *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	shadowConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		s := cfg.ShadowFor(repo.Org, repo.Repo)
+		if s == nil {
+			continue
+		}
+		info := fmt.Sprintf("The Prow config is loaded from %s", s.ProwConfigPath)
+		if s.JobConfigPath != "" {
+			info += fmt.Sprintf(" and the job config from %s", s.JobConfigPath)
+		}
+		shadowConfig[repo.String()] = fmt.Sprintf("%s. Up to %d jobs can be run at once.", info, s.MaxJobs)
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		Shadow: []plugins.Shadow{
+			{
+				Repos:          []string{"ORGANIZATION/REPOSITORY"},
+				ProwConfigPath: "config/prow/config.yaml",
+				JobConfigPath:  "config/jobs",
+				MaxJobs:        10,
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
+	pluginHelp := &pluginhelp.PluginHelp{
+		Description: "The shadow plugin runs jobs as they are configured in a pull request to the Prow config against the latest merge of the default branch of the repositories they belong to. " +
+			"The results are reported to the pull request in status contexts prefixed with " + contextPrefix + " and not to the repositories of the jobs.",
+		Config:  shadowConfig,
+		Snippet: yamlSnippet,
+	}
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/shadow <job-name> [<job-name>...]",
+		Description: "Runs the presubmits or postsubmits with the given names as configured in this pull request.",
+		Featured:    false,
+		WhoCanUse:   "Trusted users, like repository collaborators.",
+		Examples:    []string{"/shadow pull-test-infra-unit-test", "/shadow pull-test-infra-unit-test post-test-infra-push-image"},
+	})
+	return pluginHelp, nil
+}
+
+type githubClient interface {
+	CreateComment(org, repo string, number int, comment string) error
+	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	GetRepo(org, name string) (github.FullRepo, error)
+	GetRef(org, repo, ref string) (string, error)
+}
+
+type prowJobClient interface {
+	Create(context.Context, *prowapi.ProwJob, metav1.CreateOptions) (*prowapi.ProwJob, error)
+	UpdateStatus(context.Context, *prowapi.ProwJob, metav1.UpdateOptions) (*prowapi.ProwJob, error)
+}
+
+type client struct {
+	ghc       githubClient
+	gc        git.ClientFactory
+	pjc       prowJobClient
+	isTrusted func(user string) (bool, error)
+	// requireScheduling is whether the ProwJobs have to be scheduled first.
+	requireScheduling bool
+	log               *logrus.Entry
+}
+
+func handleGenericComment(pc plugins.Agent, e github.GenericCommentEvent) error {
+	org, repo := e.Repo.Owner.Login, e.Repo.Name
+	s := pc.PluginConfig.ShadowFor(org, repo)
+	if s == nil {
+		return nil
+	}
+	c := client{
+		ghc: pc.GitHubClient,
+		gc:  pc.GitClient,
+		pjc: pc.ProwJobClient,
+		isTrusted: func(user string) (bool, error) {
+			t := pc.PluginConfig.TriggerFor(org, repo)
+			trustedResponse, err := trigger.TrustedUser(pc.GitHubClient, t.OnlyOrgMembers, t.TrustedApps, t.TrustedBots, t.MembershipService, t.TrustedOrg, user, org, repo)
+			return trustedResponse.IsTrusted, err
+		},
+		requireScheduling: pc.Config.Scheduler.Enabled,
+		log:               pc.Logger,
+	}
+	return handle(c, s, e)
+}
+
+// shadowJob is a job of the config in the pull request that was requested.
+type shadowJob struct {
+	orgRepo  string
+	jobBase  config.JobBase
+	context  string
+	brancher config.Brancher
+}
+
+func handle(c client, s *plugins.Shadow, e github.GenericCommentEvent) error {
+	if !e.IsPR || e.IssueState != "open" || e.Action != github.GenericCommentActionCreated {
+		return nil
+	}
+	matches := shadowRe.FindAllStringSubmatch(e.Body, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	org, repo, number, user := e.Repo.Owner.Login, e.Repo.Name, e.Number, e.User.Login
+	respond := func(message string) error {
+		return c.ghc.CreateComment(org, repo, number, plugins.FormatResponseRaw(e.Body, e.HTMLURL, user, message))
+	}
+
+	trusted, err := c.isTrusted(user)
+	if err != nil {
+		return fmt.Errorf("error checking trust of %s: %w", user, err)
+	}
+	if !trusted {
+		return respond("Shadow runs can only be requested by trusted users, like repository collaborators.")
+	}
+
+	names := sets.New[string]()
+	for _, match := range matches {
+		names.Insert(strings.Fields(match[1])...)
+	}
+
+	pr, err := c.ghc.GetPullRequest(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("error getting pull request: %w", err)
+	}
+	cfg, err := loadConfig(c.gc, s, org, repo, pr)
+	if err != nil {
+		c.log.WithError(err).Info("Failed to load the config of the pull request.")
+		return respond(fmt.Sprintf("The config of this pull request can not be loaded:\n```\n%v\n```", err))
+	}
+
+	jobs, missing := findJobs(cfg, names)
+	if len(jobs) > s.MaxJobs {
+		return respond(fmt.Sprintf("The requested jobs are configured %d times, but at most %d jobs can be shadowed at once.", len(jobs), s.MaxJobs))
+	}
+
+	var lines []string
+	for _, name := range missing {
+		lines = append(lines, fmt.Sprintf("- `%s`: no presubmit or postsubmit with this name is configured in this pull request", name))
+	}
+	for _, job := range jobs {
+		line, err := c.run(job, pr, e.GUID)
+		if err != nil {
+			c.log.WithError(err).WithField("job", job.jobBase.Name).Error("Failed to shadow job.")
+			line = fmt.Sprintf("failed: %v", err)
+		}
+		lines = append(lines, fmt.Sprintf("- `%s` (%s): %s", job.jobBase.Name, job.orgRepo, line))
+	}
+	return respond("Shadow runs of the requested jobs:\n\n" + strings.Join(lines, "\n"))
+}
+
+// loadConfig loads the Prow and job config as they would be after merging
+// the pull request.
+func loadConfig(gc git.ClientFactory, s *plugins.Shadow, org, repo string, pr *github.PullRequest) (*config.Config, error) {
+	r, err := gc.ClientFor(org, repo)
+	if err != nil {
+		return nil, fmt.Errorf("error cloning %s/%s: %w", org, repo, err)
+	}
+	defer func() {
+		if err := r.Clean(); err != nil {
+			logrus.WithError(err).Error("Error cleaning up repo.")
+		}
+	}()
+	if err := r.Config("user.name", "prow"); err != nil {
+		return nil, err
+	}
+	if err := r.Config("user.email", "prow@localhost"); err != nil {
+		return nil, err
+	}
+	if err := r.Config("commit.gpgsign", "false"); err != nil {
+		logrus.WithError(err).Errorf("Cannot set gpgsign=false in gitconfig: %v", err)
+	}
+	if err := r.MergeAndCheckout(pr.Base.Ref, string(types.MergeMerge), pr.Head.SHA); err != nil {
+		return nil, fmt.Errorf("error merging the pull request: %w", err)
+	}
+
+	var jobConfig string
+	if s.JobConfigPath != "" {
+		jobConfig = filepath.Join(r.Directory(), s.JobConfigPath)
+	}
+	return config.Load(filepath.Join(r.Directory(), s.ProwConfigPath), jobConfig, nil, "")
+}
+
+// findJobs returns the presubmits and postsubmits with the given names, and
+// the names that are not configured. A name can be configured for several
+// repositories, in which case all of them are returned.
+func findJobs(cfg *config.Config, names sets.Set[string]) ([]shadowJob, []string) {
+	var jobs []shadowJob
+	found := sets.New[string]()
+	for _, orgRepo := range sets.List(sets.KeySet(cfg.PresubmitsStatic)) {
+		for _, presubmit := range cfg.PresubmitsStatic[orgRepo] {
+			if names.Has(presubmit.Name) {
+				found.Insert(presubmit.Name)
+				jobs = append(jobs, shadowJob{orgRepo: orgRepo, jobBase: presubmit.JobBase, context: presubmit.Context, brancher: presubmit.Brancher})
+			}
+		}
+	}
+	for _, orgRepo := range sets.List(sets.KeySet(cfg.PostsubmitsStatic)) {
+		for _, postsubmit := range cfg.PostsubmitsStatic[orgRepo] {
+			if names.Has(postsubmit.Name) {
+				found.Insert(postsubmit.Name)
+				jobContext := postsubmit.Context
+				if jobContext == "" {
+					jobContext = postsubmit.Name
+				}
+				jobs = append(jobs, shadowJob{orgRepo: orgRepo, jobBase: postsubmit.JobBase, context: jobContext, brancher: postsubmit.Brancher})
+			}
+		}
+	}
+	return jobs, sets.List(names.Difference(found))
+}
+
+// run creates a ProwJob running the job against the latest merge of the
+// default branch of its repository. The primary refs of the ProwJob are the
+// pull request, so that its result is only reported there.
+func (c *client) run(job shadowJob, pr *github.PullRequest, eventGUID string) (string, error) {
+	org, repo, err := config.SplitRepoName(job.orgRepo)
+	if err != nil {
+		return "", err
+	}
+	fullRepo, err := c.ghc.GetRepo(org, repo)
+	if err != nil {
+		return "", fmt.Errorf("error getting repository: %w", err)
+	}
+	branch := fullRepo.DefaultBranch
+	if !job.brancher.ShouldRun(branch) {
+		return fmt.Sprintf("skipped, the job does not run against the default branch `%s`", branch), nil
+	}
+	sha, err := c.ghc.GetRef(org, repo, "heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("error getting the head of %s: %w", branch, err)
+	}
+
+	target := prowapi.Refs{
+		Org:      org,
+		Repo:     repo,
+		RepoLink: fullRepo.HTMLURL,
+		BaseRef:  branch,
+		BaseSHA:  sha,
+		BaseLink: fmt.Sprintf("%s/commit/%s", fullRepo.HTMLURL, sha),
+	}
+	presubmit := config.Presubmit{
+		JobBase:  shadowJobBase(job.jobBase, target),
+		Reporter: config.Reporter{Context: contextPrefix + job.context},
+	}
+	pj := pjutil.NewPresubmit(*pr, pr.Base.SHA, presubmit, eventGUID, map[string]string{ShadowLabel: "true"}, pjutil.RequireScheduling(c.requireScheduling))
+	c.log.WithFields(pjutil.ProwJobFields(&pj)).Info("Creating a new shadow prowjob.")
+	if _, err := pjutil.CreateProwJob(context.TODO(), c.pjc, &pj); err != nil {
+		return "", fmt.Errorf("error creating prowjob: %w", err)
+	}
+	return fmt.Sprintf("running against `%s` at %s as `%s`", branch, sha, presubmit.Context), nil
+}
+
+// shadowJobBase moves the clone options of the job from its primary refs,
+// which become the pull request, to the refs of its repository, and drops the
+// reporting configuration so that the original channels are not notified.
+func shadowJobBase(jb config.JobBase, target prowapi.Refs) config.JobBase {
+	workDir := true
+	for _, ref := range jb.ExtraRefs {
+		if ref.WorkDir {
+			workDir = false
+		}
+	}
+	target.WorkDir = workDir
+	jb.ExtraRefs = append([]prowapi.Refs{*pjutil.CompletePrimaryRefs(target, jb)}, jb.ExtraRefs...)
+	jb.PathAlias = ""
+	jb.CloneURI = ""
+	jb.SkipSubmodules = false
+	jb.CloneDepth = 0
+	jb.SkipFetchHead = false
+	jb.ReporterConfig = nil
+	return jb
+}
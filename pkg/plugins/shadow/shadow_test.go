@@ -0,0 +1,279 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shadow
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/git/localgit"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/pjutil/fakepjutil"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+var defaultBranch = localgit.DefaultBranch("")
+
+const prowConfig = `prowjob_namespace: prowjobs
+pod_namespace: test-pods
+`
+
+const jobConfig = `presubmits:
+  org/repo:
+  - name: pull-repo-unit
+    context: unit
+    always_run: true
+    spec:
+      containers:
+      - image: golang
+        command: ["make", "test"]
+  - name: pull-repo-release
+    branches:
+    - release
+    spec:
+      containers:
+      - image: golang
+postsubmits:
+  org/repo:
+  - name: post-repo-push
+    path_alias: example.com/repo
+    spec:
+      containers:
+      - image: golang
+        command: ["make", "push"]
+`
+
+func TestHandle(t *testing.T) {
+	lg, gc, err := localgit.NewV2()
+	if err != nil {
+		t.Fatalf("Making localgit: %v", err)
+	}
+	defer func() {
+		if err := lg.Clean(); err != nil {
+			t.Errorf("Cleaning up localgit: %v", err)
+		}
+		if err := gc.Clean(); err != nil {
+			t.Errorf("Cleaning up client: %v", err)
+		}
+	}()
+	if err := lg.MakeFakeRepo("org", "config"); err != nil {
+		t.Fatalf("Making fake repo: %v", err)
+	}
+	if err := lg.AddCommit("org", "config", map[string][]byte{"config.yaml": []byte(prowConfig)}); err != nil {
+		t.Fatalf("Adding base commit: %v", err)
+	}
+	if err := lg.CheckoutNewBranch("org", "config", "pull/1/head"); err != nil {
+		t.Fatalf("Checking out pull branch: %v", err)
+	}
+	if err := lg.AddCommit("org", "config", map[string][]byte{"jobs/jobs.yaml": []byte(jobConfig)}); err != nil {
+		t.Fatalf("Adding PR commit: %v", err)
+	}
+	headSHA, err := lg.RevParse("org", "config", "HEAD")
+	if err != nil {
+		t.Fatalf("Getting commit SHA: %v", err)
+	}
+	if err := lg.Checkout("org", "config", defaultBranch); err != nil {
+		t.Fatalf("Switching to the default branch: %v", err)
+	}
+	baseSHA, err := lg.RevParse("org", "config", "HEAD")
+	if err != nil {
+		t.Fatalf("Getting commit SHA: %v", err)
+	}
+
+	testCases := []struct {
+		name             string
+		body             string
+		untrusted        bool
+		maxJobs          int
+		expectedJobs     []string
+		expectedComments []string
+	}{
+		{
+			name: "comment without command is ignored",
+			body: "looks good",
+		},
+		{
+			name:             "untrusted users can not shadow jobs",
+			body:             "/shadow pull-repo-unit",
+			untrusted:        true,
+			expectedComments: []string{"can only be requested by trusted users"},
+		},
+		{
+			name:             "presubmit and postsubmit are shadowed",
+			body:             "/shadow pull-repo-unit post-repo-push",
+			expectedJobs:     []string{"post-repo-push", "pull-repo-unit"},
+			expectedComments: []string{"`pull-repo-unit` (org/repo): running against `master`", "`post-repo-push` (org/repo): running against `master`"},
+		},
+		{
+			name:             "unknown jobs and jobs not running against the default branch are reported",
+			body:             "/shadow pull-repo-release missing-job",
+			expectedComments: []string{"`missing-job`: no presubmit or postsubmit", "`pull-repo-release` (org/repo): skipped"},
+		},
+		{
+			name:             "too many jobs are rejected",
+			body:             "/shadow pull-repo-unit\n/shadow post-repo-push",
+			maxJobs:          1,
+			expectedComments: []string{"at most 1 jobs can be shadowed"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ghc := fakegithub.NewFakeClient()
+			ghc.PullRequests = map[int]*github.PullRequest{1: {
+				Number: 1,
+				User:   github.User{Login: "author"},
+				Base: github.PullRequestBranch{
+					Ref:  defaultBranch,
+					SHA:  baseSHA,
+					Repo: github.Repo{Owner: github.User{Login: "org"}, Name: "config"},
+				},
+				Head: github.PullRequestBranch{SHA: headSHA},
+			}}
+			pjClient := fake.NewSimpleClientset()
+			c := client{
+				ghc: ghc,
+				gc:  gc,
+				pjc: fakepjutil.StatusDroppingClient{ProwJobInterface: pjClient.ProwV1().ProwJobs("prowjobs")},
+				isTrusted: func(string) (bool, error) {
+					return !tc.untrusted, nil
+				},
+				log: logrus.WithField("plugin", PluginName),
+			}
+			maxJobs := tc.maxJobs
+			if maxJobs == 0 {
+				maxJobs = 10
+			}
+			s := &plugins.Shadow{ProwConfigPath: "config.yaml", JobConfigPath: "jobs", MaxJobs: maxJobs}
+			e := github.GenericCommentEvent{
+				IsPR:       true,
+				IssueState: "open",
+				Action:     github.GenericCommentActionCreated,
+				Body:       tc.body,
+				Number:     1,
+				Repo:       github.Repo{Owner: github.User{Login: "org"}, Name: "config"},
+				User:       github.User{Login: "user"},
+			}
+			if err := handle(c, s, e); err != nil {
+				t.Fatalf("handle failed: %v", err)
+			}
+
+			pjs, err := pjClient.ProwV1().ProwJobs("prowjobs").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("failed to list prowjobs: %v", err)
+			}
+			var jobs []string
+			for _, pj := range pjs.Items {
+				jobs = append(jobs, pj.Spec.Job)
+				checkShadowProwJob(t, pj, headSHA)
+			}
+			sort.Strings(jobs)
+			if diff := cmp.Diff(tc.expectedJobs, jobs); diff != "" {
+				t.Errorf("unexpected shadowed jobs: %s", diff)
+			}
+
+			comments := ghc.IssueComments[1]
+			if len(tc.expectedComments) == 0 {
+				if len(comments) != 0 {
+					t.Errorf("expected no comments, got %v", comments)
+				}
+				return
+			}
+			if len(comments) != 1 {
+				t.Fatalf("expected one comment, got %v", comments)
+			}
+			for _, expected := range tc.expectedComments {
+				if !strings.Contains(comments[0].Body, expected) {
+					t.Errorf("expected comment to contain %q, got %q", expected, comments[0].Body)
+				}
+			}
+		})
+	}
+}
+
+func checkShadowProwJob(t *testing.T, pj prowapi.ProwJob, headSHA string) {
+	t.Helper()
+	if pj.Spec.Type != prowapi.PresubmitJob || !pj.Spec.Report {
+		t.Errorf("expected a reporting presubmit, got type %s and report %t", pj.Spec.Type, pj.Spec.Report)
+	}
+	if !strings.HasPrefix(pj.Spec.Context, contextPrefix) {
+		t.Errorf("expected context %q to start with %q", pj.Spec.Context, contextPrefix)
+	}
+	if pj.Status.State != prowapi.TriggeredState || pj.Status.StartTime.IsZero() {
+		t.Errorf("expected the job to be created with its initial status, got %+v", pj.Status)
+	}
+	if pj.Labels[ShadowLabel] != "true" {
+		t.Errorf("expected the %s label, got %v", ShadowLabel, pj.Labels)
+	}
+	if refs := pj.Spec.Refs; refs.Org != "org" || refs.Repo != "config" || len(refs.Pulls) != 1 || refs.Pulls[0].SHA != headSHA || refs.PathAlias != "" {
+		t.Errorf("expected the primary refs to be the config pull request, got %+v", refs)
+	}
+	if len(pj.Spec.ExtraRefs) != 1 {
+		t.Fatalf("expected the repository of the job as extra refs, got %+v", pj.Spec.ExtraRefs)
+	}
+	expected := prowapi.Refs{
+		Org:      "org",
+		Repo:     "repo",
+		BaseRef:  "master",
+		BaseSHA:  fakegithub.TestRef,
+		BaseLink: "/commit/" + fakegithub.TestRef,
+		WorkDir:  true,
+	}
+	if pj.Spec.Job == "post-repo-push" {
+		expected.PathAlias = "example.com/repo"
+	}
+	if diff := cmp.Diff(expected, pj.Spec.ExtraRefs[0]); diff != "" {
+		t.Errorf("unexpected extra refs: %s", diff)
+	}
+}
+
+func TestShadowJobBase(t *testing.T) {
+	jb := config.JobBase{
+		Name: "job",
+		UtilityConfig: config.UtilityConfig{
+			PathAlias:  "example.com/repo",
+			CloneDepth: 1,
+			ExtraRefs:  []prowapi.Refs{{Org: "org", Repo: "tools", WorkDir: true}},
+		},
+		ReporterConfig: &prowapi.ReporterConfig{Slack: &prowapi.SlackReporterConfig{Channel: "alerts"}},
+	}
+	shadowed := shadowJobBase(jb, prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "main"})
+
+	expectedExtraRefs := []prowapi.Refs{
+		{Org: "org", Repo: "repo", BaseRef: "main", PathAlias: "example.com/repo", CloneDepth: 1},
+		{Org: "org", Repo: "tools", WorkDir: true},
+	}
+	if diff := cmp.Diff(expectedExtraRefs, shadowed.ExtraRefs); diff != "" {
+		t.Errorf("unexpected extra refs: %s", diff)
+	}
+	if shadowed.PathAlias != "" || shadowed.CloneDepth != 0 || shadowed.ReporterConfig != nil {
+		t.Errorf("expected clone options and reporter config to be dropped, got %+v", shadowed)
+	}
+	if len(jb.ExtraRefs) != 1 || jb.PathAlias == "" {
+		t.Errorf("expected the original job to not be modified, got %+v", jb)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mattn/go-zglob"
 	"github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/prow/pkg/config"
@@ -49,39 +50,59 @@ func init() {
 	plugins.RegisterPullRequestHandler(pluginName, handlePullRequest, helpProvider)
 }
 
-func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
-	sizes := sizesOrDefault(config.Size)
+func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	sizeConfig := map[string]string{
+		"": configString(sizesOrDefault(config.Size.For("", ""))),
+	}
+	for _, repo := range enabledRepos {
+		sizeConfig[repo.String()] = configString(sizesOrDefault(config.Size.For(repo.Org, repo.Repo)))
+	}
 	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
 		Size: plugins.Size{
-			S:   10,
-			M:   30,
-			L:   100,
-			Xl:  500,
-			Xxl: 1000,
+			S:             10,
+			M:             30,
+			L:             100,
+			Xl:            500,
+			Xxl:           1000,
+			ExcludedPaths: []string{"vendor/**/*"},
+			Repos: map[string]plugins.SizeOverride{
+				"ORGANIZATION/REPOSITORY": {
+					Xl:            1000,
+					Xxl:           2000,
+					ExcludedPaths: []string{"**/zz_generated.*.go"},
+				},
+			},
 		},
 	})
 	if err != nil {
 		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", pluginName)
 	}
 	return &pluginhelp.PluginHelp{
-			Description: "The size plugin manages the 'size/*' labels, maintaining the appropriate label on each pull request as it is updated. Generated files identified by the config file '.generated_files' at the repo root are ignored. Labels are applied based on the total number of lines of changes (additions and deletions).",
-			Config: map[string]string{
-				"": fmt.Sprintf(`The plugin has the following thresholds:<ul>
+			Description: "The size plugin manages the 'size/*' labels, maintaining the appropriate label on each pull request as it is updated. Generated files identified by the config file '.generated_files' at the repo root are ignored, as are files matching the excluded paths configured for the repo. Labels are applied based on the total number of lines of changes (additions and deletions).",
+			Config:      sizeConfig,
+			Snippet:     yamlSnippet,
+		},
+		nil
+}
+
+func configString(sizes plugins.Size) string {
+	msg := fmt.Sprintf(`The plugin has the following thresholds:<ul>
 <li>size/XS:  0-%d</li>
 <li>size/S:   %d-%d</li>
 <li>size/M:   %d-%d</li>
 <li>size/L:   %d-%d</li>
 <li>size/XL:  %d-%d</li>
 <li>size/XXL: %d+</li>
-</ul>`, sizes.S-1, sizes.S, sizes.M-1, sizes.M, sizes.L-1, sizes.L, sizes.Xl-1, sizes.Xl, sizes.Xxl-1, sizes.Xxl),
-			},
-			Snippet: yamlSnippet,
-		},
-		nil
+</ul>`, sizes.S-1, sizes.S, sizes.M-1, sizes.M, sizes.L-1, sizes.L, sizes.Xl-1, sizes.Xl, sizes.Xxl-1, sizes.Xxl)
+	if len(sizes.ExcludedPaths) > 0 {
+		msg += fmt.Sprintf("Changes to paths matching the following globs are not counted: %s", strings.Join(sizes.ExcludedPaths, ", "))
+	}
+	return msg
 }
 
 func handlePullRequest(pc plugins.Agent, pe github.PullRequestEvent) error {
-	return handlePR(pc.GitHubClient, sizesOrDefault(pc.PluginConfig.Size), pc.Logger, pe)
+	sizes := pc.PluginConfig.Size.For(pe.Repo.Owner.Login, pe.Repo.Name)
+	return handlePR(pc.GitHubClient, sizesOrDefault(sizes), pc.Logger, pe)
 }
 
 // Strict subset of github.Client methods.
@@ -132,6 +153,9 @@ func handlePR(gc githubClient, sizes plugins.Size, le *logrus.Entry, pe github.P
 		if gf.Match(change.Filename) || ga.IsLinguistGenerated(change.Filename) {
 			continue
 		}
+		if isExcluded(change.Filename, sizes.ExcludedPaths, le) {
+			continue
+		}
 
 		count += change.Additions + change.Deletions
 	}
@@ -168,6 +192,21 @@ func handlePR(gc githubClient, sizes plugins.Size, le *logrus.Entry, pe github.P
 	return nil
 }
 
+// isExcluded returns whether the file matches one of the excluded globs.
+func isExcluded(filename string, globs []string, le *logrus.Entry) bool {
+	for _, glob := range globs {
+		matched, err := zglob.Match(glob, filename)
+		if err != nil {
+			le.WithError(err).Warnf("invalid excluded path glob %q", glob)
+			continue
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
 // One of a set of discrete buckets.
 type size int
 
@@ -17,6 +17,7 @@ limitations under the License.
 package size
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -106,7 +107,7 @@ func TestSizesOrDefault(t *testing.T) {
 			expected: defaultSizes,
 		},
 	} {
-		if c.expected != sizesOrDefault(c.input) {
+		if !reflect.DeepEqual(c.expected, sizesOrDefault(c.input)) {
 			t.Fatalf("Unexpected sizes from sizesOrDefault - expected %+v but got %+v", c.expected, sizesOrDefault(c.input))
 		}
 	}
@@ -609,6 +610,60 @@ func TestHandlePR(t *testing.T) {
 				Xxl: 4,
 			},
 		},
+		{
+			name: "excluded paths are not counted",
+			client: &ghc{
+				labels:     map[github.Label]bool{},
+				getFileErr: &github.FileNotFound{},
+				prChanges: []github.PullRequestChange{
+					{
+						SHA:       "abcd",
+						Filename:  "vendor/github.com/foo/bar.go",
+						Additions: 1000,
+						Changes:   1000,
+					},
+					{
+						SHA:       "abcd",
+						Filename:  "pkg/api/zz_generated.deepcopy.go",
+						Additions: 500,
+						Changes:   500,
+					},
+					{
+						SHA:       "abcd",
+						Filename:  "pkg/api/types.go",
+						Additions: 15,
+						Changes:   15,
+					},
+				},
+			},
+			event: github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				Number: 101,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Base: github.PullRequestBranch{
+						SHA: "abcd",
+						Repo: github.Repo{
+							Owner: github.User{
+								Login: "kubernetes",
+							},
+							Name: "kubernetes",
+						},
+					},
+				},
+			},
+			finalLabels: []github.Label{
+				{Name: "size/S"},
+			},
+			sizes: plugins.Size{
+				S:             10,
+				M:             30,
+				L:             100,
+				Xl:            500,
+				Xxl:           1000,
+				ExcludedPaths: []string{"vendor/**/*", "**/zz_generated.*.go"},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -671,6 +726,18 @@ func TestHelpProvider(t *testing.T) {
 			},
 			enabledRepos: enabledRepos,
 		},
+		{
+			name: "Repo overrides specified",
+			config: &plugins.Configuration{
+				Size: plugins.Size{
+					ExcludedPaths: []string{"vendor/**/*"},
+					Repos: map[string]plugins.SizeOverride{
+						"org1/repo": {Xxl: 2000, ExcludedPaths: []string{"**/*.pb.go"}},
+					},
+				},
+			},
+			enabledRepos: enabledRepos,
+		},
 		{
 			name: "Sizes specified",
 			config: &plugins.Configuration{
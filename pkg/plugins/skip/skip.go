@@ -66,10 +66,10 @@ func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhel
 
 func handleGenericComment(pc plugins.Agent, e github.GenericCommentEvent) error {
 	honorOkToTest := trigger.HonorOkToTest(pc.PluginConfig.TriggerFor(e.Repo.Owner.Login, e.Repo.Name))
-	return handle(pc.GitHubClient, pc.Logger, &e, pc.Config, pc.GitClient, honorOkToTest)
+	return handle(pc.GitHubClient, pc.Logger, &e, pc.Config, pc.GitClient, pc.InRepoConfigGetter, honorOkToTest)
 }
 
-func handle(gc githubClient, log *logrus.Entry, e *github.GenericCommentEvent, c *config.Config, gitClient git.ClientFactory, honorOkToTest bool) error {
+func handle(gc githubClient, log *logrus.Entry, e *github.GenericCommentEvent, c *config.Config, gitClient git.ClientFactory, ircg config.InRepoConfigGetter, honorOkToTest bool) error {
 	if !e.IsPR || e.IssueState != "open" || e.Action != github.GenericCommentActionCreated {
 		return nil
 	}
@@ -98,7 +98,7 @@ func handle(gc githubClient, log *logrus.Entry, e *github.GenericCommentEvent, c
 	headSHAGetter := func() (string, error) {
 		return pr.Head.SHA, nil
 	}
-	presubmits, err := c.GetPresubmits(gitClient, org+"/"+repo, pr.Base.Ref, baseSHAGetter, headSHAGetter)
+	presubmits, err := plugins.GetPresubmits(ircg, c, gitClient, org+"/"+repo, pr.Base.Ref, baseSHAGetter, headSHAGetter)
 	if err != nil {
 		return fmt.Errorf("failed to get presubmits: %w", err)
 	}
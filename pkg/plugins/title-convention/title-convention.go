@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package titleconvention implements the title-convention plugin, which
+// validates PR titles against configurable patterns and suggests corrected
+// titles.
+package titleconvention
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/pluginhelp"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	// PluginName defines this plugin's registered name.
+	PluginName = "title-convention"
+
+	invalidTitleCommentPruneBody = "does not follow the title convention of this repository"
+	invalidTitleCommentBody      = `The title of this Pull Request ` + invalidTitleCommentPruneBody + `.
+
+%s%s
+You can edit the title by writing **/retitle <new-title>** in a comment.
+
+<details>
+
+%s
+</details>
+`
+
+	// maxStatusDescription is the maximum length of a status description.
+	maxStatusDescription = 140
+)
+
+var (
+	// jiraKeyRe matches JIRA issue keys, e.g. in branch names.
+	jiraKeyRe = regexp.MustCompile(`[A-Z][A-Z0-9]+-[0-9]+`)
+	// typePrefixRe matches a leading type in the most common misspellings of
+	// Conventional Commits, e.g. "Fix: ...", "fix:...", "[fix] ..." or
+	// "fix - ...".
+	typePrefixRe = regexp.MustCompile(`^\[?([A-Za-z]+)(\([^)]*\))?(!)?\]?\s*(?::|-|\])\s*(.*)$`)
+)
+
+func init() {
+	plugins.RegisterPullRequestHandler(PluginName, handlePullRequest, helpProvider)
+}
+
+func helpProvider(cfg *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	// The {WhoCanUse, Usage, Examples} fields are omitted because this plugin cannot be triggered manually.
+	conventionConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		t := cfg.TitleConventionFor(repo.Org, repo.Repo)
+		if t == nil {
+			continue
+		}
+		info := fmt.Sprintf("PR titles must match one of %s.", strings.Join(t.Patterns, ", "))
+		if t.Description != "" {
+			info = t.Description
+		}
+		conventionConfig[repo.String()] = fmt.Sprintf("%s The result is reported in the %q status context.", info, t.Context)
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		TitleConvention: []plugins.TitleConvention{
+			{
+				Repos:       []string{"ORGANIZATION", "ORGANIZATION/REPOSITORY"},
+				Patterns:    []string{`^(feat|fix|docs|chore)(\([a-z-]+\))?!?: \S`},
+				Description: "Titles must follow Conventional Commits: type(scope): summary",
+				Context:     "title-convention",
+				TargetURL:   "https://www.conventionalcommits.org",
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
+	return &pluginhelp.PluginHelp{
+			Description: "The title-convention plugin validates PR titles against the configured patterns and reports the result in a status context. " +
+				"If a title does not follow the convention, the plugin comments on the PR and suggests a corrected title where it can derive one.",
+			Config:  conventionConfig,
+			Snippet: yamlSnippet,
+		},
+		nil
+}
+
+type githubClient interface {
+	CreateComment(owner, repo string, number int, comment string) error
+	CreateStatus(owner, repo, ref string, status github.Status) error
+}
+
+type commentPruner interface {
+	PruneComments(shouldPrune func(github.IssueComment) bool)
+}
+
+func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
+	t := pc.PluginConfig.TitleConventionFor(pre.Repo.Owner.Login, pre.Repo.Name)
+	if t == nil {
+		return nil
+	}
+	switch pre.Action {
+	case github.PullRequestActionOpened, github.PullRequestActionReopened, github.PullRequestActionSynchronize, github.PullRequestActionEdited:
+	default:
+		return nil
+	}
+	cp, err := pc.CommentPruner()
+	if err != nil {
+		return err
+	}
+	return handle(pc.GitHubClient, pc.Logger, cp, t, pre)
+}
+
+func titleChanged(changes json.RawMessage) bool {
+	var c struct {
+		Title *struct{} `json:"title"`
+	}
+	if err := json.Unmarshal(changes, &c); err != nil {
+		return false
+	}
+	return c.Title != nil
+}
+
+func handle(gc githubClient, log *logrus.Entry, cp commentPruner, t *plugins.TitleConvention, pre github.PullRequestEvent) error {
+	// Edits of e.g. the body leave the result as it is.
+	if pre.Action == github.PullRequestActionEdited && !titleChanged(pre.Changes) {
+		return nil
+	}
+	org, repo, number := pre.Repo.Owner.Login, pre.Repo.Name, pre.Number
+	title := pre.PullRequest.Title
+	valid := t.Matches(title)
+
+	status := github.Status{
+		Context:     t.Context,
+		State:       github.StatusSuccess,
+		TargetURL:   t.TargetURL,
+		Description: "The PR title follows the convention.",
+	}
+	if !valid {
+		status.State = github.StatusFailure
+		status.Description = "The PR title does not follow the convention."
+		if t.Description != "" {
+			status.Description = t.Description
+		}
+		if runes := []rune(status.Description); len(runes) > maxStatusDescription {
+			status.Description = string(runes[:maxStatusDescription-3]) + "..."
+		}
+	}
+	if err := gc.CreateStatus(org, repo, pre.PullRequest.Head.SHA, status); err != nil {
+		return fmt.Errorf("error setting pull request status: %w", err)
+	}
+
+	// The title did not change with new commits, so neither does the comment.
+	if pre.Action == github.PullRequestActionSynchronize {
+		return nil
+	}
+	cp.PruneComments(func(comment github.IssueComment) bool {
+		return strings.Contains(comment.Body, invalidTitleCommentPruneBody)
+	})
+	if valid {
+		return nil
+	}
+
+	var description, suggestion string
+	if t.Description != "" {
+		description = t.Description + "\n"
+	}
+	if suggested := suggestTitle(t, title, pre.PullRequest.Head.Ref); suggested != "" {
+		suggestion = fmt.Sprintf("\nA title following the convention could be:\n```\n/retitle %s\n```\n", suggested)
+	}
+	log.Debug("Commenting on PR to advise users of a PR title not following the convention")
+	return gc.CreateComment(org, repo, number, fmt.Sprintf(invalidTitleCommentBody, description, suggestion, plugins.AboutThisBot))
+}
+
+// suggestTitle tries common corrections of title and returns the first one
+// that follows the convention, or an empty string if none does.
+func suggestTitle(t *plugins.TitleConvention, original, headRef string) string {
+	title := strings.Join(strings.Fields(original), " ")
+	candidates := []string{title}
+
+	// Normalize a leading type, e.g. "Fix: typo" or "[fix] typo" to "fix: typo".
+	if m := typePrefixRe.FindStringSubmatch(title); m != nil && m[4] != "" {
+		candidates = append(candidates, fmt.Sprintf("%s%s%s: %s", strings.ToLower(m[1]), strings.ToLower(m[2]), m[3], m[4]))
+	}
+
+	// Prefix the title with a JIRA key from the title or the branch name.
+	key := jiraKeyRe.FindString(title)
+	if key == "" {
+		key = jiraKeyRe.FindString(strings.ToUpper(headRef))
+	}
+	if key != "" {
+		rest := strings.NewReplacer("()", "", "[]", "").Replace(strings.Replace(title, key, "", 1))
+		rest = strings.Trim(strings.Join(strings.Fields(rest), " "), " :-")
+		candidates = append(candidates, key+": "+rest, key+" "+rest, "["+key+"] "+rest)
+	}
+
+	for _, candidate := range candidates {
+		if candidate != original && t.Matches(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package titleconvention
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+type fakePruner struct {
+	pruned bool
+}
+
+func (fp *fakePruner) PruneComments(shouldPrune func(github.IssueComment) bool) {
+	fp.pruned = true
+}
+
+func convention(patterns ...string) *plugins.TitleConvention {
+	t := &plugins.TitleConvention{Patterns: patterns, Context: "title-convention"}
+	for _, p := range patterns {
+		t.Res = append(t.Res, regexp.MustCompile(p))
+	}
+	return t
+}
+
+const conventionalCommits = `^(feat|fix|docs|chore)(\([a-z-]+\))?!?: \S`
+
+func TestHandle(t *testing.T) {
+	testCases := []struct {
+		name            string
+		action          github.PullRequestEventAction
+		changes         string
+		title           string
+		description     string
+		expectedState   string
+		expectComment   bool
+		expectedComment string
+		expectPrune     bool
+	}{
+		{
+			name:          "valid title",
+			action:        github.PullRequestActionOpened,
+			title:         "fix(hook): handle empty payloads",
+			expectedState: github.StatusSuccess,
+			expectPrune:   true,
+		},
+		{
+			name:            "invalid title gets a suggestion",
+			action:          github.PullRequestActionEdited,
+			changes:         `{"title": {"from": "handle empty payloads"}}`,
+			title:           "Fix: handle empty payloads",
+			expectedState:   github.StatusFailure,
+			expectComment:   true,
+			expectedComment: "/retitle fix: handle empty payloads",
+			expectPrune:     true,
+		},
+		{
+			name:            "invalid title without suggestion",
+			action:          github.PullRequestActionOpened,
+			title:           "handle empty payloads",
+			expectedState:   github.StatusFailure,
+			expectComment:   true,
+			expectedComment: "does not follow the title convention",
+			expectPrune:     true,
+		},
+		{
+			name:    "body edits are ignored",
+			action:  github.PullRequestActionEdited,
+			changes: `{"body": {"from": "old description"}}`,
+			title:   "handle empty payloads",
+		},
+		{
+			name:          "long description is truncated by characters",
+			action:        github.PullRequestActionSynchronize,
+			title:         "handle empty payloads",
+			description:   strings.Repeat("é", 200),
+			expectedState: github.StatusFailure,
+		},
+		{
+			name:          "new commits only update the status",
+			action:        github.PullRequestActionSynchronize,
+			title:         "handle empty payloads",
+			expectedState: github.StatusFailure,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegithub.NewFakeClient()
+			fp := &fakePruner{}
+			pre := github.PullRequestEvent{
+				Action: tc.action,
+				Number: 1,
+				Repo:   github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+				PullRequest: github.PullRequest{
+					Title: tc.title,
+					Head:  github.PullRequestBranch{SHA: "head", Ref: "feature"},
+				},
+				Changes: json.RawMessage(tc.changes),
+			}
+			c := convention(conventionalCommits)
+			c.Description = tc.description
+			if err := handle(fc, logrus.WithField("plugin", PluginName), fp, c, pre); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			statuses := fc.CreatedStatuses["head"]
+			if tc.expectedState == "" {
+				if len(statuses) != 0 {
+					t.Errorf("expected no status, got %v", statuses)
+				}
+			} else if len(statuses) != 1 || statuses[0].Context != "title-convention" || statuses[0].State != tc.expectedState {
+				t.Errorf("expected a %s status, got %v", tc.expectedState, statuses)
+			} else if description := statuses[0].Description; !utf8.ValidString(description) || utf8.RuneCountInString(description) > maxStatusDescription {
+				t.Errorf("expected a valid description of at most %d characters, got %q", maxStatusDescription, description)
+			}
+			if tc.expectComment != (len(fc.IssueComments[1]) == 1) {
+				t.Fatalf("expected comment: %t, got %v", tc.expectComment, fc.IssueComments[1])
+			}
+			if tc.expectComment && !strings.Contains(fc.IssueComments[1][0].Body, tc.expectedComment) {
+				t.Errorf("expected the comment to contain %q, got %q", tc.expectedComment, fc.IssueComments[1][0].Body)
+			}
+			if fp.pruned != tc.expectPrune {
+				t.Errorf("expected pruning: %t, got %t", tc.expectPrune, fp.pruned)
+			}
+		})
+	}
+}
+
+func TestSuggestTitle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		patterns []string
+		title    string
+		headRef  string
+		expected string
+	}{
+		{
+			name:     "capitalized type",
+			patterns: []string{conventionalCommits},
+			title:    "Docs: update the README",
+			expected: "docs: update the README",
+		},
+		{
+			name:     "bracketed type with scope",
+			patterns: []string{conventionalCommits},
+			title:    "[feat(deck)] add dark mode",
+			expected: "feat(deck): add dark mode",
+		},
+		{
+			name:     "missing space after colon",
+			patterns: []string{conventionalCommits},
+			title:    "chore:bump dependencies",
+			expected: "chore: bump dependencies",
+		},
+		{
+			name:     "extra whitespace",
+			patterns: []string{conventionalCommits},
+			title:    "fix:  typo  ",
+			expected: "fix: typo",
+		},
+		{
+			name:     "JIRA key from the branch name",
+			patterns: []string{`^[A-Z][A-Z0-9]+-[0-9]+: `},
+			title:    "Add retries",
+			headRef:  "proj-123-add-retries",
+			expected: "PROJ-123: Add retries",
+		},
+		{
+			name:     "JIRA key in the wrong place",
+			patterns: []string{`^\[[A-Z][A-Z0-9]+-[0-9]+\] `},
+			title:    "Add retries (PROJ-123)",
+			expected: "[PROJ-123] Add retries",
+		},
+		{
+			name:     "JIRA key in the wrong format",
+			patterns: []string{`^\[[A-Z][A-Z0-9]+-[0-9]+\] `},
+			title:    "PROJ-123: Add retries",
+			expected: "[PROJ-123] Add retries",
+		},
+		{
+			name:     "no suggestion",
+			patterns: []string{conventionalCommits},
+			title:    "random title",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := suggestTitle(convention(tc.patterns...), tc.title, tc.headRef); actual != tc.expected {
+				t.Errorf("expected suggestion %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package triagerotation implements the triage-rotation plugin, which assigns
+// newly opened issues to the members of a rotation group.
+package triagerotation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/pluginhelp"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// PluginName defines this plugin's registered name.
+const PluginName = "triage-rotation"
+
+func init() {
+	plugins.RegisterIssueHandler(PluginName, handleIssue, helpProvider)
+}
+
+func helpProvider(cfg *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	// The {WhoCanUse, Usage, Examples} fields are omitted because this plugin cannot be triggered manually.
+	rotationConfig := map[string]string{}
+	for _, repo := range enabledRepos {
+		r := cfg.TriageRotationFor(repo.Org, repo.Repo)
+		if r == nil {
+			continue
+		}
+		var groups []string
+		for _, g := range r.Groups {
+			labels := "all issues"
+			if len(g.Labels) > 0 {
+				labels = "issues labeled " + strings.Join(g.Labels, " or ")
+			}
+			groups = append(groups, fmt.Sprintf("%s are assigned to one of %s", labels, strings.Join(g.Members, ", ")))
+		}
+		rotationConfig[repo.String()] = strings.Join(groups, "; ") + "."
+	}
+	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
+		TriageRotation: []plugins.TriageRotation{
+			{
+				Repos: []string{"ORGANIZATION", "ORGANIZATION/REPOSITORY"},
+				Groups: []plugins.TriageGroup{
+					{
+						Labels:  []string{"area/docs"},
+						Members: []string{"alice", "bob"},
+					},
+					{
+						Members: []string{"carol", "dave", "erin"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", PluginName)
+	}
+	return &pluginhelp.PluginHelp{
+			Description: "The triage-rotation plugin assigns newly opened issues to a member of the configured rotation group. " +
+				"The group is chosen by the labels of the issue and the issue goes to the member with the fewest open issues assigned in the repo.",
+			Config:  rotationConfig,
+			Snippet: yamlSnippet,
+		},
+		nil
+}
+
+type githubClient interface {
+	AssignIssue(org, repo string, number int, logins []string) error
+	FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error)
+}
+
+func handleIssue(pc plugins.Agent, ie github.IssueEvent) error {
+	if ie.Action != github.IssueActionOpened || ie.Issue.IsPullRequest() {
+		return nil
+	}
+	org, repo := ie.Repo.Owner.Login, ie.Repo.Name
+	r := pc.PluginConfig.TriageRotationFor(org, repo)
+	if r == nil {
+		return nil
+	}
+	return handle(pc.GitHubClient, pc.Logger, r, org, repo, ie.Issue)
+}
+
+func handle(ghc githubClient, log *logrus.Entry, r *plugins.TriageRotation, org, repo string, issue github.Issue) error {
+	if len(issue.Assignees) > 0 {
+		return nil
+	}
+	group := r.GroupFor(issue.Labels)
+	if group == nil {
+		log.Debug("No triage rotation group matches the issue labels.")
+		return nil
+	}
+	assignee, err := pickAssignee(ghc, org, repo, issue, group.Members)
+	if err != nil {
+		return err
+	}
+	log.WithField("assignee", assignee).Info("Assigning issue from triage rotation.")
+	return ghc.AssignIssue(org, repo, issue.Number, []string{assignee})
+}
+
+// pickAssignee returns the member with the fewest open issues assigned in the
+// repo. Members tied for the fewest are picked in rotation order starting at
+// the issue number, so that consecutive issues spread over the group even if
+// the counts lag behind. The issue author is only picked if they are the only
+// member.
+func pickAssignee(ghc githubClient, org, repo string, issue github.Issue, members []string) (string, error) {
+	var candidates []string
+	for _, member := range members {
+		if !strings.EqualFold(member, issue.User.Login) {
+			candidates = append(candidates, member)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = members
+	}
+
+	var best string
+	fewest := -1
+	for i := range candidates {
+		member := candidates[(issue.Number+i)%len(candidates)]
+		query := fmt.Sprintf("is:issue is:open repo:%s/%s assignee:%s", org, repo, member)
+		assigned, err := ghc.FindIssuesWithOrg(org, query, "", false)
+		if err != nil {
+			return "", fmt.Errorf("failed to count the issues assigned to %s: %w", member, err)
+		}
+		if fewest == -1 || len(assigned) < fewest {
+			best, fewest = member, len(assigned)
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triagerotation
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+type fakeClient struct {
+	assigned map[string]int
+	assigns  []string
+}
+
+func (f *fakeClient) AssignIssue(org, repo string, number int, logins []string) error {
+	for _, login := range logins {
+		f.assigns = append(f.assigns, fmt.Sprintf("%s/%s#%d:%s", org, repo, number, login))
+	}
+	return nil
+}
+
+func (f *fakeClient) FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error) {
+	for _, term := range strings.Fields(query) {
+		if login, ok := strings.CutPrefix(term, "assignee:"); ok {
+			return make([]github.Issue, f.assigned[login]), nil
+		}
+	}
+	return nil, fmt.Errorf("query %q has no assignee", query)
+}
+
+func TestHandle(t *testing.T) {
+	rotation := &plugins.TriageRotation{
+		Groups: []plugins.TriageGroup{
+			{Labels: []string{"area/docs"}, Members: []string{"alice", "bob"}},
+			{Members: []string{"carol", "dave", "erin"}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		issue    github.Issue
+		assigned map[string]int
+		expected []string
+	}{
+		{
+			name:     "issue goes to the member with the fewest assignments",
+			issue:    github.Issue{Number: 3},
+			assigned: map[string]int{"carol": 2, "dave": 1, "erin": 4},
+			expected: []string{"org/repo#3:dave"},
+		},
+		{
+			name:     "ties are broken in rotation order",
+			issue:    github.Issue{Number: 4},
+			assigned: map[string]int{},
+			expected: []string{"org/repo#4:dave"},
+		},
+		{
+			name:     "labeled issue goes to its group",
+			issue:    github.Issue{Number: 1, Labels: []github.Label{{Name: "area/docs"}}},
+			assigned: map[string]int{"alice": 1},
+			expected: []string{"org/repo#1:bob"},
+		},
+		{
+			name:     "author is skipped",
+			issue:    github.Issue{Number: 1, User: github.User{Login: "Bob"}, Labels: []github.Label{{Name: "area/docs"}}},
+			assigned: map[string]int{"alice": 5},
+			expected: []string{"org/repo#1:alice"},
+		},
+		{
+			name:  "assigned issue is left alone",
+			issue: github.Issue{Number: 1, Assignees: []github.User{{Login: "frank"}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := &fakeClient{assigned: tc.assigned}
+			if err := handle(fc, logrus.WithField("test", tc.name), rotation, "org", "repo", tc.issue); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, fc.assigns); diff != "" {
+				t.Errorf("assignments differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleNoMatchingGroup(t *testing.T) {
+	rotation := &plugins.TriageRotation{
+		Groups: []plugins.TriageGroup{{Labels: []string{"kind/bug"}, Members: []string{"alice"}}},
+	}
+	fc := &fakeClient{}
+	if err := handle(fc, logrus.NewEntry(logrus.New()), rotation, "org", "repo", github.Issue{Number: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fc.assigns) != 0 {
+		t.Errorf("expected no assignment, got %v", fc.assigns)
+	}
+}
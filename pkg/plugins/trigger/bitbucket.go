@@ -0,0 +1,330 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// BitbucketClient holds the clients needed to trigger presubmits for
+// Bitbucket pull requests.
+type BitbucketClient struct {
+	BitbucketClient bitbucket.Client
+	ProwJobClient   prowJobClient
+	Config          *config.Config
+	Logger          *logrus.Entry
+}
+
+// HandleBitbucketPullRequest triggers presubmits for opened and updated pull
+// requests. Bitbucket has no labels, so a pull request from an author without
+// write permission is trusted once a user with write permission commented
+// /ok-to-test on it.
+func HandleBitbucketPullRequest(c BitbucketClient, trigger plugins.Trigger, e bitbucket.PullRequestEvent) error {
+	pr := e.PullRequest
+	switch e.EventKey {
+	case bitbucket.EventKeyPullRequestOpened, bitbucket.EventKeyPullRequestFromRefUpdated, bitbucket.CloudEventKeyPullRequestUpdated:
+	default:
+		return nil
+	}
+	if pr.Draft {
+		c.Logger.Info("Skipping all jobs for draft pull request.")
+		return nil
+	}
+
+	presubmits := c.Config.GetPresubmitsStatic(pr.ToRef.Repository.FullName())
+	if len(presubmits) == 0 {
+		return nil
+	}
+	if e.EventKey == bitbucket.CloudEventKeyPullRequestUpdated {
+		tested, err := bitbucketHeadTested(c.BitbucketClient, pr, presubmits)
+		if err != nil {
+			return err
+		}
+		if tested {
+			c.Logger.Debug("Skipping update of pull request that did not change its commits.")
+			return nil
+		}
+	}
+
+	trusted, err := bitbucketTrustedPullRequest(c.BitbucketClient, trigger, pr)
+	if err != nil {
+		return err
+	}
+	if !trusted {
+		if e.EventKey != bitbucket.EventKeyPullRequestOpened {
+			return nil
+		}
+		c.Logger.Infof("Asking for /ok-to-test on pull request from untrusted author %s.", pr.Author.User.Name)
+		return bitbucketWelcomeMsg(c.BitbucketClient, pr)
+	}
+
+	c.Logger.Info("Starting all jobs for pull request.")
+	toTest, err := pjutil.FilterPresubmits(pjutil.NewTestAllFilter(), bitbucketChanges(c.BitbucketClient, pr), pr.ToRef.DisplayID, presubmits, c.Logger)
+	if err != nil {
+		return err
+	}
+	return runBitbucketRequested(c, pr, toTest, e.GUID, nil)
+}
+
+// HandleBitbucketComment handles /test, /retest and /ok-to-test comments on
+// pull requests.
+func HandleBitbucketComment(c BitbucketClient, trigger plugins.Trigger, e bitbucket.PullRequestEvent) error {
+	if e.EventKey != bitbucket.EventKeyPullRequestComment || e.Comment == nil {
+		return nil
+	}
+	pr := e.PullRequest
+	if pr.State != bitbucket.PullRequestStateOpen {
+		return nil
+	}
+	body := e.Comment.Text
+	if !triggerCommandRe.MatchString(body) {
+		return nil
+	}
+	bot, err := c.BitbucketClient.BotUser()
+	if err != nil {
+		return err
+	}
+	if e.Comment.Author.Name == bot {
+		return nil
+	}
+
+	repo := pr.ToRef.Repository
+	presubmits := c.Config.GetPresubmitsStatic(repo.FullName())
+	if len(presubmits) == 0 {
+		return nil
+	}
+
+	honorOkToTest := !trigger.IgnoreOkToTest
+	isOkToTest := honorOkToTest && pjutil.OkToTestRe.MatchString(body)
+	commenterTrusted, err := bitbucketTrustedUser(c.BitbucketClient, repo, e.Comment.Author.Name)
+	if err != nil {
+		return err
+	}
+	if !commenterTrusted {
+		if isOkToTest {
+			c.Logger.Infof("Ignoring /ok-to-test from untrusted user %s.", e.Comment.Author.Name)
+			return nil
+		}
+		trusted, err := bitbucketTrustedPullRequest(c.BitbucketClient, trigger, pr)
+		if err != nil {
+			return err
+		}
+		if !trusted {
+			c.Logger.Infof("Ignoring comment from %s on untrusted pull request.", e.Comment.Author.Name)
+			return nil
+		}
+	}
+
+	contextGetter := func() (sets.Set[string], sets.Set[string], error) {
+		statuses, err := c.BitbucketClient.ListBuildStatuses(repo.Project.Key, repo.Slug, pr.FromRef.LatestCommit)
+		if err != nil {
+			return nil, nil, err
+		}
+		failed, all := sets.New[string](), sets.New[string]()
+		for _, status := range statuses {
+			all.Insert(status.Key)
+			if status.State == bitbucket.BuildStateFailed {
+				failed.Insert(status.Key)
+			}
+		}
+		return failed, all, nil
+	}
+	filter, err := pjutil.PresubmitFilter(honorOkToTest, contextGetter, body, c.Logger)
+	if err != nil {
+		return err
+	}
+	changes := bitbucketChanges(c.BitbucketClient, pr)
+	toTest, err := pjutil.FilterPresubmits(filter, changes, pr.ToRef.DisplayID, presubmits, c.Logger)
+	if err != nil {
+		return err
+	}
+	if len(toTest) == 0 {
+		if !testCommandRe.MatchString(body) {
+			return nil
+		}
+		return bitbucketListJobsMsg(c, pr, changes, presubmits, body)
+	}
+	additionalLabels := map[string]string{}
+	if isRetest(body) {
+		additionalLabels[kube.RetestLabel] = "true"
+		if trigger.RetestBudget != nil {
+			resp, err := checkRetestBudget(c.ProwJobClient, *trigger.RetestBudget, repo.Project.Key, repo.Slug, pr.ID, time.Now())
+			if err != nil {
+				return err
+			}
+			if resp != "" {
+				c.Logger.Infof("Commenting \"%s\".", resp)
+				return c.BitbucketClient.CreatePullRequestComment(repo.Project.Key, repo.Slug, pr.ID, resp)
+			}
+		}
+	}
+	return runBitbucketRequested(c, pr, toTest, e.GUID, additionalLabels)
+}
+
+func bitbucketChanges(bc bitbucket.Client, pr bitbucket.PullRequest) config.ChangedFilesProvider {
+	var changedFiles []string
+	return func() ([]string, error) {
+		if changedFiles != nil {
+			return changedFiles, nil
+		}
+		repo := pr.ToRef.Repository
+		changes, err := bc.GetPullRequestChanges(repo.Project.Key, repo.Slug, pr.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request changes: %w", err)
+		}
+		changedFiles = []string{}
+		for _, change := range changes {
+			changedFiles = append(changedFiles, change.Path)
+			if change.SrcPath != "" && change.SrcPath != change.Path {
+				changedFiles = append(changedFiles, change.SrcPath)
+			}
+		}
+		return changedFiles, nil
+	}
+}
+
+// bitbucketHeadTested returns whether the head commit of the pull request has
+// a build status of one of the presubmits already. Bitbucket Cloud does not
+// tell whether an update of a pull request pushed new commits, so this tells
+// pushes from edits of the title or the description.
+func bitbucketHeadTested(bc bitbucket.Client, pr bitbucket.PullRequest, presubmits []config.Presubmit) (bool, error) {
+	repo := pr.ToRef.Repository
+	statuses, err := bc.ListBuildStatuses(repo.Project.Key, repo.Slug, pr.FromRef.LatestCommit)
+	if err != nil {
+		return false, fmt.Errorf("failed to list build statuses: %w", err)
+	}
+	contexts := sets.New[string]()
+	for _, status := range statuses {
+		contexts.Insert(status.Key)
+	}
+	for _, presubmit := range presubmits {
+		if contexts.Has(presubmit.Context) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// bitbucketTrustedUser returns whether the user is allowed to push to the
+// repository.
+func bitbucketTrustedUser(bc bitbucket.Client, repo bitbucket.Repository, user string) (bool, error) {
+	permission, err := bc.GetUserPermission(repo.Project.Key, repo.Slug, user)
+	if err != nil {
+		return false, err
+	}
+	return permission.CanWrite(), nil
+}
+
+// bitbucketTrustedPullRequest returns whether the author of the pull request
+// is trusted or a trusted user commented /ok-to-test on it.
+func bitbucketTrustedPullRequest(bc bitbucket.Client, trigger plugins.Trigger, pr bitbucket.PullRequest) (bool, error) {
+	repo := pr.ToRef.Repository
+	if trusted, err := bitbucketTrustedUser(bc, repo, pr.Author.User.Name); err != nil || trusted {
+		return trusted, err
+	}
+	if trigger.IgnoreOkToTest {
+		return false, nil
+	}
+	comments, err := bc.ListPullRequestComments(repo.Project.Key, repo.Slug, pr.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list pull request comments: %w", err)
+	}
+	checked := sets.New[string]()
+	for _, comment := range comments {
+		if !pjutil.OkToTestRe.MatchString(comment.Text) || checked.Has(comment.Author.Name) {
+			continue
+		}
+		checked.Insert(comment.Author.Name)
+		trusted, err := bitbucketTrustedUser(bc, repo, comment.Author.Name)
+		if err != nil || trusted {
+			return trusted, err
+		}
+	}
+	return false, nil
+}
+
+func bitbucketWelcomeMsg(bc bitbucket.Client, pr bitbucket.PullRequest) error {
+	repo := pr.ToRef.Repository
+	comment := "Hi! Thanks for your pull request.\n\n" +
+		"Tests will not run automatically because you don't have write permission on this repository. " +
+		"Once a user with write permission has verified that this pull request is safe to test, they can reply with `/ok-to-test`.\n\n" +
+		"Users with write permission can also run individual jobs with `/test <job name>`."
+	return bc.CreatePullRequestComment(repo.Project.Key, repo.Slug, pr.ID, comment)
+}
+
+func bitbucketListJobsMsg(c BitbucketClient, pr bitbucket.PullRequest, changes config.ChangedFilesProvider, presubmits []config.Presubmit, body string) error {
+	msg, err := listJobsMsg(c.Logger, changes, pr.ToRef.DisplayID, presubmits, body)
+	if err != nil {
+		return err
+	}
+	repo := pr.ToRef.Repository
+	return c.BitbucketClient.CreatePullRequestComment(repo.Project.Key, repo.Slug, pr.ID, msg)
+}
+
+func bitbucketRefs(c BitbucketClient, pr bitbucket.PullRequest) (prowapi.Refs, error) {
+	repo := pr.ToRef.Repository
+	baseSHA, err := c.BitbucketClient.GetBranchHead(repo.Project.Key, repo.Slug, pr.ToRef.DisplayID)
+	if err != nil {
+		return prowapi.Refs{}, fmt.Errorf("failed to get baseSHA: %w", err)
+	}
+	repoLink := strings.TrimSuffix(repo.WebURL(), "/browse")
+	return prowapi.Refs{
+		Org:      repo.Project.Key,
+		Repo:     repo.Slug,
+		RepoLink: repoLink,
+		BaseRef:  pr.ToRef.DisplayID,
+		BaseSHA:  baseSHA,
+		BaseLink: fmt.Sprintf("%s/commits/%s", repoLink, baseSHA),
+		CloneURI: repo.CloneURL(),
+		Pulls: []prowapi.Pull{
+			{
+				Number:     pr.ID,
+				Author:     pr.Author.User.Name,
+				SHA:        pr.FromRef.LatestCommit,
+				Ref:        pr.GitRef(),
+				HeadRef:    pr.FromRef.DisplayID,
+				Title:      pr.Title,
+				Link:       pr.WebURL(),
+				CommitLink: fmt.Sprintf("%s/commits/%s", repoLink, pr.FromRef.LatestCommit),
+			},
+		},
+	}, nil
+}
+
+func runBitbucketRequested(c BitbucketClient, pr bitbucket.PullRequest, requestedJobs []config.Presubmit, eventGUID string, additionalLabels map[string]string) error {
+	if len(requestedJobs) == 0 {
+		return nil
+	}
+	refs, err := bitbucketRefs(c, pr)
+	if err != nil {
+		return err
+	}
+	annotations := map[string]string{kube.BitbucketInstance: pr.ToRef.Repository.Host()}
+	return runRequestedForRefs(c.Logger, c.ProwJobClient, c.Config, refs, requestedJobs, eventGUID, additionalLabels, annotations)
+}
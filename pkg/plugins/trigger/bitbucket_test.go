@@ -0,0 +1,361 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/prow/pkg/bitbucket"
+	"sigs.k8s.io/prow/pkg/bitbucket/fakebitbucket"
+	"sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func newBitbucketTestClient(t *testing.T) (BitbucketClient, *fakebitbucket.FakeClient, *fake.Clientset) {
+	bc := fakebitbucket.NewFakeClient()
+	bc.Permissions["PRJ/repo"] = map[string]bitbucket.Permission{
+		"dev":    bitbucket.PermissionRepoWrite,
+		"reader": "REPO_READ",
+	}
+	bc.BranchHeads["PRJ/repo@main"] = "base-sha"
+	pjClient := fake.NewSimpleClientset()
+	c := BitbucketClient{
+		BitbucketClient: bc,
+		ProwJobClient:   pjClient.ProwV1().ProwJobs("prowjobs"),
+		Config:          &config.Config{},
+		Logger:          logrus.WithField("plugin", PluginName),
+	}
+	presubmits := map[string][]config.Presubmit{
+		"PRJ/repo": {
+			{
+				JobBase:      config.JobBase{Name: "unit"},
+				AlwaysRun:    true,
+				Reporter:     config.Reporter{Context: "unit"},
+				Trigger:      `(?m)^/test (?:.*? )?unit(?: .*?)?$`,
+				RerunCommand: "/test unit",
+			},
+			{
+				JobBase:      config.JobBase{Name: "lint"},
+				Reporter:     config.Reporter{Context: "lint"},
+				Trigger:      `(?m)^/test (?:.*? )?lint(?: .*?)?$`,
+				RerunCommand: "/test lint",
+			},
+		},
+	}
+	if err := c.Config.SetPresubmits(presubmits); err != nil {
+		t.Fatalf("failed to set presubmits: %v", err)
+	}
+	return c, bc, pjClient
+}
+
+func bitbucketPullRequest(author string) bitbucket.PullRequest {
+	repo := bitbucket.Repository{
+		Slug:    "repo",
+		Project: bitbucket.Project{Key: "PRJ"},
+		Links: bitbucket.Links{
+			Clone: []bitbucket.Link{{Name: "http", Href: "https://bitbucket.example.com/scm/prj/repo.git"}},
+			Self:  []bitbucket.Link{{Href: "https://bitbucket.example.com/projects/PRJ/repos/repo/browse"}},
+		},
+	}
+	return bitbucket.PullRequest{
+		ID:      1,
+		State:   bitbucket.PullRequestStateOpen,
+		FromRef: bitbucket.Ref{DisplayID: "feature", LatestCommit: "head-sha", Repository: repo},
+		ToRef:   bitbucket.Ref{DisplayID: "main", Repository: repo},
+		Author:  bitbucket.Participant{User: bitbucket.User{Name: author}},
+	}
+}
+
+func startedBitbucketJobs(t *testing.T, pjClient *fake.Clientset) []string {
+	pjs, err := pjClient.ProwV1().ProwJobs("prowjobs").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list prowjobs: %v", err)
+	}
+	var names []string
+	for _, pj := range pjs.Items {
+		names = append(names, pj.Spec.Job)
+		if pj.Annotations[kube.BitbucketInstance] != "https://bitbucket.example.com" {
+			t.Errorf("prowjob %s lacks bitbucket annotation: %v", pj.Spec.Job, pj.Annotations)
+		}
+		refs := pj.Spec.Refs
+		if refs.Org != "PRJ" || refs.Repo != "repo" || refs.BaseSHA != "base-sha" || refs.Pulls[0].SHA != "head-sha" || refs.Pulls[0].Ref != "refs/pull-requests/1/from" {
+			t.Errorf("unexpected refs for prowjob %s: %+v", pj.Spec.Job, refs)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestHandleBitbucketPullRequest(t *testing.T) {
+	testCases := []struct {
+		name             string
+		eventKey         string
+		author           string
+		draft            bool
+		comments         []bitbucket.Comment
+		statuses         []bitbucket.BuildStatus
+		expectedJobs     []string
+		expectedComments int
+	}{
+		{
+			name:         "trusted author opens pull request",
+			eventKey:     bitbucket.EventKeyPullRequestOpened,
+			author:       "dev",
+			expectedJobs: []string{"unit"},
+		},
+		{
+			name:     "trusted author opens draft pull request",
+			eventKey: bitbucket.EventKeyPullRequestOpened,
+			author:   "dev",
+			draft:    true,
+		},
+		{
+			name:             "untrusted author opens pull request",
+			eventKey:         bitbucket.EventKeyPullRequestOpened,
+			author:           "reader",
+			expectedComments: 1,
+		},
+		{
+			name:     "untrusted author pushes without ok-to-test",
+			eventKey: bitbucket.EventKeyPullRequestFromRefUpdated,
+			author:   "reader",
+		},
+		{
+			name:     "untrusted author pushes after trusted ok-to-test",
+			eventKey: bitbucket.EventKeyPullRequestFromRefUpdated,
+			author:   "reader",
+			comments: []bitbucket.Comment{
+				{Text: "/ok-to-test", Author: bitbucket.User{Name: "reader"}},
+				{Text: "/ok-to-test", Author: bitbucket.User{Name: "dev"}},
+			},
+			expectedJobs:     []string{"unit"},
+			expectedComments: 2,
+		},
+		{
+			name:     "untrusted author pushes after own ok-to-test",
+			eventKey: bitbucket.EventKeyPullRequestFromRefUpdated,
+			author:   "reader",
+			comments: []bitbucket.Comment{
+				{Text: "/ok-to-test", Author: bitbucket.User{Name: "reader"}},
+			},
+			expectedComments: 1,
+		},
+		{
+			name:     "title edit",
+			eventKey: "pr:modified",
+			author:   "dev",
+		},
+		{
+			name:         "trusted author pushes to Bitbucket Cloud",
+			eventKey:     bitbucket.CloudEventKeyPullRequestUpdated,
+			author:       "dev",
+			statuses:     []bitbucket.BuildStatus{{Key: "lgtm", State: bitbucket.BuildStateSuccessful}},
+			expectedJobs: []string{"unit"},
+		},
+		{
+			name:     "trusted author edits title on Bitbucket Cloud",
+			eventKey: bitbucket.CloudEventKeyPullRequestUpdated,
+			author:   "dev",
+			statuses: []bitbucket.BuildStatus{{Key: "unit", State: bitbucket.BuildStateSuccessful}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, bc, pjClient := newBitbucketTestClient(t)
+			key := fakebitbucket.PullRequestKey("PRJ", "repo", 1)
+			bc.Comments[key] = tc.comments
+			bc.BuildStatuses["head-sha"] = tc.statuses
+			pr := bitbucketPullRequest(tc.author)
+			pr.Draft = tc.draft
+			event := bitbucket.PullRequestEvent{EventKey: tc.eventKey, PullRequest: pr}
+			trigger := plugins.Trigger{}
+			trigger.SetDefaults()
+			if err := HandleBitbucketPullRequest(c, trigger, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedJobs, startedBitbucketJobs(t, pjClient)); diff != "" {
+				t.Errorf("unexpected jobs started: %s", diff)
+			}
+			if n := len(bc.Comments[key]); n != tc.expectedComments {
+				t.Errorf("expected %d comments, got %d: %v", tc.expectedComments, n, bc.Comments[key])
+			}
+		})
+	}
+}
+
+func TestHandleBitbucketComment(t *testing.T) {
+	testCases := []struct {
+		name            string
+		commenter       string
+		author          string
+		body            string
+		state           string
+		statuses        []bitbucket.BuildStatus
+		expectedJobs    []string
+		expectedComment bool
+	}{
+		{
+			name:         "trusted user runs a single job",
+			commenter:    "dev",
+			author:       "reader",
+			body:         "/test lint",
+			expectedJobs: []string{"lint"},
+		},
+		{
+			name:         "trusted user approves untrusted pull request",
+			commenter:    "dev",
+			author:       "reader",
+			body:         "/ok-to-test",
+			expectedJobs: []string{"unit"},
+		},
+		{
+			name:      "untrusted user can't approve pull request",
+			commenter: "reader",
+			author:    "reader",
+			body:      "/ok-to-test",
+		},
+		{
+			name:      "untrusted user can't test untrusted pull request",
+			commenter: "reader",
+			author:    "reader",
+			body:      "/test lint",
+		},
+		{
+			name:         "untrusted user can test trusted pull request",
+			commenter:    "reader",
+			author:       "dev",
+			body:         "/test lint",
+			expectedJobs: []string{"lint"},
+		},
+		{
+			name:      "retest runs failed jobs",
+			commenter: "dev",
+			author:    "dev",
+			body:      "/retest",
+			statuses: []bitbucket.BuildStatus{
+				{Key: "unit", State: bitbucket.BuildStateSuccessful},
+				{Key: "lint", State: bitbucket.BuildStateFailed},
+			},
+			expectedJobs: []string{"lint"},
+		},
+		{
+			name:            "unknown job lists available jobs",
+			commenter:       "dev",
+			author:          "dev",
+			body:            "/test nope",
+			expectedComment: true,
+		},
+		{
+			name:      "comments on merged pull requests are ignored",
+			commenter: "dev",
+			author:    "dev",
+			body:      "/test lint",
+			state:     bitbucket.PullRequestStateMerged,
+		},
+		{
+			name:      "bot comments are ignored",
+			commenter: "prow-bot",
+			author:    "dev",
+			body:      "/test lint",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, bc, pjClient := newBitbucketTestClient(t)
+			bc.Permissions["PRJ/repo"]["prow-bot"] = bitbucket.PermissionRepoAdmin
+			bc.BuildStatuses["head-sha"] = tc.statuses
+			pr := bitbucketPullRequest(tc.author)
+			if tc.state != "" {
+				pr.State = tc.state
+			}
+			event := bitbucket.PullRequestEvent{
+				EventKey:    bitbucket.EventKeyPullRequestComment,
+				PullRequest: pr,
+				Comment:     &bitbucket.Comment{Text: tc.body, Author: bitbucket.User{Name: tc.commenter}},
+			}
+			trigger := plugins.Trigger{}
+			trigger.SetDefaults()
+			if err := HandleBitbucketComment(c, trigger, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedJobs, startedBitbucketJobs(t, pjClient)); diff != "" {
+				t.Errorf("unexpected jobs started: %s", diff)
+			}
+			key := fakebitbucket.PullRequestKey("PRJ", "repo", 1)
+			if commented := len(bc.Comments[key]) > 0; commented != tc.expectedComment {
+				t.Errorf("expected comment: %t, got %v", tc.expectedComment, bc.Comments[key])
+			}
+		})
+	}
+}
+
+func TestHandleBitbucketCommentRetestBudget(t *testing.T) {
+	testCases := []struct {
+		name            string
+		retested        bool
+		expectedJobs    []string
+		expectedComment bool
+	}{
+		{
+			name:         "retest within the budget runs failed jobs",
+			expectedJobs: []string{"lint"},
+		},
+		{
+			name:            "retest over the budget is refused",
+			retested:        true,
+			expectedComment: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, bc, pjClient := newBitbucketTestClient(t)
+			bc.BuildStatuses["head-sha"] = []bitbucket.BuildStatus{{Key: "lint", State: bitbucket.BuildStateFailed}}
+			if tc.retested {
+				createRetestedJob(t, pjClient, "PRJ", "repo")
+			}
+			event := bitbucket.PullRequestEvent{
+				EventKey:    bitbucket.EventKeyPullRequestComment,
+				PullRequest: bitbucketPullRequest("dev"),
+				Comment:     &bitbucket.Comment{Text: "/retest", Author: bitbucket.User{Name: "dev"}},
+			}
+			trigger := plugins.Trigger{RetestBudget: &plugins.RetestBudget{MaxPerPR: 1, WindowDuration: time.Hour}}
+			trigger.SetDefaults()
+			if err := HandleBitbucketComment(c, trigger, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedJobs, retestJobs(t, pjClient)); diff != "" {
+				t.Errorf("unexpected retest jobs started: %s", diff)
+			}
+			key := fakebitbucket.PullRequestKey("PRJ", "repo", 1)
+			if commented := len(bc.Comments[key]) > 0; commented != tc.expectedComment {
+				t.Errorf("expected comment: %t, got %v", tc.expectedComment, bc.Comments[key])
+			}
+		})
+	}
+}
@@ -18,6 +18,9 @@ package trigger
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/prow/pkg/kube"
@@ -53,8 +56,12 @@ func handleGenericComment(c Client, trigger plugins.Trigger, gc github.GenericCo
 		return nil
 	}
 
+	if testBatchRe.MatchString(gc.Body) {
+		return handleTestBatch(c, trigger, gc)
+	}
+
 	refGetter := config.NewRefGetterForGitHubPullRequest(c.GitHubClient, org, repo, number)
-	presubmits := getPresubmits(c.Logger, c.GitClient, c.Config, org+"/"+repo, refGetter.BaseSHA, refGetter.HeadSHA)
+	presubmits := getPresubmits(c.Logger, c.InRepoConfigGetter, c.GitClient, c.Config, org+"/"+repo, refGetter.BaseSHA, refGetter.HeadSHA)
 
 	// Skip comments not germane to this plugin
 	if !pjutil.RetestRe.MatchString(gc.Body) &&
@@ -76,10 +83,11 @@ func handleGenericComment(c Client, trigger plugins.Trigger, gc github.GenericCo
 	}
 
 	// Skip untrusted users comments.
-	trustedResponse, err := TrustedUser(c.GitHubClient, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedOrg, commentAuthor, org, repo)
+	trustedResponse, err := TrustedUser(c.GitHubClient, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedBots, trigger.MembershipService, trigger.TrustedOrg, commentAuthor, org, repo)
 	if err != nil {
 		return fmt.Errorf("error checking trust of %s: %w", commentAuthor, err)
 	}
+	auditTrustedVia(c.Logger, commentAuthor, trustedResponse)
 
 	trusted := trustedResponse.IsTrusted
 	var l []github.Label
@@ -126,6 +134,13 @@ func handleGenericComment(c Client, trigger plugins.Trigger, gc github.GenericCo
 		return err
 	}
 
+	patterns, err := jobNamePatterns(gc.Body)
+	if err != nil {
+		resp := fmt.Sprintf("Cannot trigger jobs: %v.", err)
+		c.Logger.Infof("Commenting \"%s\".", resp)
+		return c.GitHubClient.CreateComment(org, repo, number, plugins.FormatResponseRaw(gc.Body, gc.HTMLURL, commentAuthor, resp))
+	}
+
 	toTest, err := FilterPresubmits(HonorOkToTest(trigger), c.GitHubClient, gc.Body, pr, presubmits, c.Logger)
 	if err != nil {
 		return err
@@ -133,10 +148,26 @@ func handleGenericComment(c Client, trigger plugins.Trigger, gc github.GenericCo
 	if needsHelp, note := pjutil.ShouldRespondWithHelp(gc.Body, len(toTest)); needsHelp {
 		return addHelpComment(c.GitHubClient, gc.Body, org, repo, pr.Base.Ref, pr.Number, presubmits, gc.HTMLURL, commentAuthor, note, c.Logger)
 	}
+	regexMatched := jobsMatchingPatterns(patterns, toTest)
+	if len(regexMatched) > trigger.RegexJobLimit {
+		resp := fmt.Sprintf("The given pattern(s) match %d jobs, which exceeds the limit of %d jobs per command. Please use a more specific pattern.", len(regexMatched), trigger.RegexJobLimit)
+		c.Logger.Infof("Commenting \"%s\".", resp)
+		return c.GitHubClient.CreateComment(org, repo, number, plugins.FormatResponseRaw(gc.Body, gc.HTMLURL, commentAuthor, resp))
+	}
 	// we want to be able to track re-tests separately from the general body of tests
 	additionalLabels := map[string]string{}
-	if pjutil.RetestRe.MatchString(gc.Body) || pjutil.RetestRequiredRe.MatchString(gc.Body) {
+	if isRetest(gc.Body) {
 		additionalLabels[kube.RetestLabel] = "true"
+		if trigger.RetestBudget != nil && len(toTest) > 0 {
+			resp, err := checkRetestBudget(c.ProwJobClient, *trigger.RetestBudget, org, repo, number, time.Now())
+			if err != nil {
+				return err
+			}
+			if resp != "" {
+				c.Logger.Infof("Commenting \"%s\".", resp)
+				return c.GitHubClient.CreateComment(org, repo, number, plugins.FormatResponseRaw(gc.Body, gc.HTMLURL, commentAuthor, resp))
+			}
+		}
 	}
 	// run failed github actions
 	if trigger.TriggerGitHubWorkflows && (pjutil.RetestRe.MatchString(gc.Body) || pjutil.TestAllRe.MatchString(gc.Body)) {
@@ -167,7 +198,50 @@ func handleGenericComment(c Client, trigger plugins.Trigger, gc github.GenericCo
 			}
 		}
 	}
-	return RunRequestedWithLabels(c, pr, baseSHA, toTest, gc.GUID, additionalLabels)
+	if err := RunRequestedWithLabels(c, pr, baseSHA, toTest, gc.GUID, additionalLabels); err != nil {
+		return err
+	}
+	if len(regexMatched) == 0 {
+		return nil
+	}
+	var resp strings.Builder
+	resp.WriteString("Triggered the following jobs matching the given pattern(s):")
+	for _, name := range regexMatched {
+		resp.WriteString(fmt.Sprintf("\n* `%s`", name))
+	}
+	return c.GitHubClient.CreateComment(org, repo, number, plugins.FormatResponseRaw(gc.Body, gc.HTMLURL, commentAuthor, resp.String()))
+}
+
+// jobNamePatterns returns the job name patterns requested with
+// `/test re:<pattern>` and `/retest re:<pattern>`.
+func jobNamePatterns(body string) ([]*regexp.Regexp, error) {
+	testPatterns, err := pjutil.JobNamePatterns(pjutil.TestRegexRe, body)
+	if err != nil {
+		return nil, err
+	}
+	retestPatterns, err := pjutil.JobNamePatterns(pjutil.RetestRegexRe, body)
+	if err != nil {
+		return nil, err
+	}
+	return append(testPatterns, retestPatterns...), nil
+}
+
+// jobsMatchingPatterns returns the sorted names of the jobs whose name
+// matches any of the patterns.
+func jobsMatchingPatterns(patterns []*regexp.Regexp, presubmits []config.Presubmit) []string {
+	if len(patterns) == 0 {
+		return nil
+	}
+	names := sets.New[string]()
+	for _, presubmit := range presubmits {
+		for _, pattern := range patterns {
+			if pattern.MatchString(presubmit.Name) {
+				names.Insert(presubmit.Name)
+				break
+			}
+		}
+	}
+	return sets.List(names)
 }
 
 func HonorOkToTest(trigger plugins.Trigger) bool {
@@ -239,5 +313,12 @@ func addHelpComment(githubClient githubClient, body, org, repo, branch string, n
 	}
 
 	resp := pjutil.HelpMessage(org, repo, branch, note, testAllNames, optionalJobsCommands, requiredJobsCommands)
+	if pjutil.TestHelpRe.MatchString(body) {
+		conditional, err := pjutil.ConditionalPresubmitsMessage(changes, branch, presubmits)
+		if err != nil {
+			return err
+		}
+		resp += conditional
+	}
 	return githubClient.CreateComment(org, repo, number, plugins.FormatResponseRaw(body, HTMLURL, user, resp))
 }
@@ -62,6 +62,7 @@ type testcase struct {
 	IssueLabels    []string
 	IgnoreOkToTest bool
 	AddedComment   string
+	RegexJobLimit  int
 }
 
 func TestHandleGenericComment(t *testing.T) {
@@ -1246,6 +1247,104 @@ func TestHandleGenericComment(t *testing.T) {
 				"The following commands are available to trigger optional jobs:\n* `/test jub`\n\n" +
 				"Use `/test all` to run all jobs.",
 		},
+		{
+			name:   `help command "/test ?" lists conditionally triggered jobs`,
+			Author: "trusted-member",
+			Body:   "/test ?",
+			State:  "open",
+			IsPR:   true,
+			Presubmits: map[string][]config.Presubmit{
+				"org/repo": {
+					{
+						JobBase: config.JobBase{
+							Name: "job",
+						},
+						AlwaysRun: true,
+						Reporter: config.Reporter{
+							Context: "pull-job",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?job(?: .*?)?$`,
+						RerunCommand: `/test job`,
+					},
+					{
+						JobBase: config.JobBase{
+							Name: "docs",
+						},
+						RegexpChangeMatcher: config.RegexpChangeMatcher{
+							RunIfChanged: "^docs/",
+						},
+						Reporter: config.Reporter{
+							Context: "pull-docs",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?docs(?: .*?)?$`,
+						RerunCommand: `/test docs`,
+					},
+					{
+						JobBase: config.JobBase{
+							Name: "changed",
+						},
+						RegexpChangeMatcher: config.RegexpChangeMatcher{
+							SkipIfOnlyChanged: "^docs/",
+						},
+						Reporter: config.Reporter{
+							Context: "pull-changed",
+						},
+						Trigger:      `(?m)^/test (?:.*? )?changed(?: .*?)?$`,
+						RerunCommand: `/test changed`,
+					},
+				},
+			},
+			AddedComment: "The following jobs are triggered based on the files changed in this PR:\n" +
+				"* `changed` (skip_if_only_changed: `^docs/`): runs automatically\n" +
+				"* `docs` (run_if_changed: `^docs/`): currently skipped\n\n",
+		},
+		{
+			name:         "/test re:<pattern> starts all matching jobs",
+			Author:       "trusted-member",
+			Body:         "/test re:^j.b$",
+			State:        "open",
+			IsPR:         true,
+			ShouldBuild:  true,
+			AddedComment: "Triggered the following jobs matching the given pattern(s):\n* `jib`\n* `job`",
+		},
+		{
+			name:          "/retest re:<pattern> starts only failed matching jobs",
+			Author:        "trusted-member",
+			Body:          "/retest re:^j",
+			State:         "open",
+			IsPR:          true,
+			ShouldBuild:   true,
+			StartsExactly: "pull-jib",
+			AddedComment:  "Triggered the following jobs matching the given pattern(s):\n* `jib`",
+		},
+		{
+			name:         "/test re:<pattern> with an invalid pattern",
+			Author:       "trusted-member",
+			Body:         "/test re:j(b",
+			State:        "open",
+			IsPR:         true,
+			ShouldBuild:  false,
+			AddedComment: "Cannot trigger jobs: invalid job name pattern \"j(b\"",
+		},
+		{
+			name:         "/test re:<pattern> without matching jobs",
+			Author:       "trusted-member",
+			Body:         "/test re:^unknown",
+			State:        "open",
+			IsPR:         true,
+			ShouldBuild:  false,
+			AddedComment: pjutil.NoRegexMatchNote,
+		},
+		{
+			name:          "/test re:<pattern> matching more jobs than allowed",
+			Author:        "trusted-member",
+			Body:          "/test re:^j",
+			State:         "open",
+			IsPR:          true,
+			ShouldBuild:   false,
+			RegexJobLimit: 1,
+			AddedComment:  "The given pattern(s) match 2 jobs, which exceeds the limit of 1 jobs per command.",
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1342,6 +1441,7 @@ func TestHandleGenericComment(t *testing.T) {
 
 			trigger := plugins.Trigger{
 				IgnoreOkToTest: tc.IgnoreOkToTest,
+				RegexJobLimit:  tc.RegexJobLimit,
 			}
 			trigger.SetDefaults()
 
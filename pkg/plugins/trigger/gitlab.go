@@ -0,0 +1,352 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/gitlab"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+var (
+	triggerCommandRe = regexp.MustCompile(`(?m)^/(test|retest|retest-required|ok-to-test)(\s|$)`)
+	testCommandRe    = regexp.MustCompile(`(?m)^/test(\s|$)`)
+)
+
+// GitLabClient holds the clients needed to trigger presubmits for GitLab
+// merge requests.
+type GitLabClient struct {
+	GitLabClient  gitlab.Client
+	ProwJobClient prowJobClient
+	Config        *config.Config
+	Logger        *logrus.Entry
+}
+
+// HandleGitLabMergeRequest triggers presubmits for opened, reopened and
+// updated merge requests, mirroring what the plugin does for GitHub pull
+// requests. Merge requests from authors that aren't project developers need
+// a trusted user to comment /ok-to-test first.
+func HandleGitLabMergeRequest(c GitLabClient, trigger plugins.Trigger, e gitlab.MergeRequestEvent) error {
+	mr := e.ObjectAttributes
+	switch mr.Action {
+	case gitlab.MergeRequestActionOpen, gitlab.MergeRequestActionReopen:
+	case gitlab.MergeRequestActionUpdate:
+		if mr.OldRev == "" {
+			// Only the title, description or labels changed.
+			return nil
+		}
+	default:
+		return nil
+	}
+	if mr.IsDraft() {
+		c.Logger.Info("Skipping all jobs for draft merge request.")
+		return nil
+	}
+	if len(mr.Labels) == 0 {
+		mr.Labels = e.Labels
+	}
+
+	presubmits := gitlabPresubmits(c, e.Project)
+	if len(presubmits) == 0 {
+		return nil
+	}
+
+	trusted, err := gitlabTrustedMergeRequest(c.GitLabClient, trigger, e.Project.ID, mr)
+	if err != nil {
+		return err
+	}
+	if !trusted {
+		if mr.Action != gitlab.MergeRequestActionOpen {
+			return nil
+		}
+		c.Logger.Infof("Asking for /ok-to-test on merge request from untrusted author %d.", mr.AuthorID)
+		return gitlabWelcomeMsg(c.GitLabClient, trigger, e.Project.ID, mr)
+	}
+
+	c.Logger.Info("Starting all jobs for merge request.")
+	toTest, err := pjutil.FilterPresubmits(pjutil.NewTestAllFilter(), gitlabChanges(c.GitLabClient, e.Project.ID, mr.IID), mr.TargetBranch, presubmits, c.Logger)
+	if err != nil {
+		return err
+	}
+	return runGitLabRequested(c, e.Project, mr, toTest, e.GUID, nil)
+}
+
+// HandleGitLabNote handles /test, /retest and /ok-to-test comments on merge
+// requests.
+func HandleGitLabNote(c GitLabClient, trigger plugins.Trigger, e gitlab.NoteEvent) error {
+	if e.ObjectAttributes.NoteableType != gitlab.NoteableTypeMergeRequest || e.MergeRequest == nil {
+		return nil
+	}
+	mr := *e.MergeRequest
+	if mr.State != "" && mr.State != "opened" {
+		return nil
+	}
+	body := e.ObjectAttributes.Note
+	honorOkToTest := !trigger.IgnoreOkToTest
+	if !triggerCommandRe.MatchString(body) {
+		return nil
+	}
+	bot, err := c.GitLabClient.BotUser()
+	if err != nil {
+		return err
+	}
+	if e.User.ID == bot.ID {
+		return nil
+	}
+
+	presubmits := gitlabPresubmits(c, e.Project)
+	if len(presubmits) == 0 {
+		return nil
+	}
+
+	commenterTrusted, err := gitlabTrustedUser(c.GitLabClient, e.Project.ID, e.User.ID)
+	if err != nil {
+		return err
+	}
+	isOkToTest := honorOkToTest && pjutil.OkToTestRe.MatchString(body)
+	if isOkToTest && commenterTrusted {
+		if err := c.GitLabClient.AddMergeRequestLabels(e.Project.ID, mr.IID, labels.OkToTest); err != nil {
+			return err
+		}
+		if gitlabHasLabel(mr.Labels, labels.NeedsOkToTest) {
+			if err := c.GitLabClient.RemoveMergeRequestLabels(e.Project.ID, mr.IID, labels.NeedsOkToTest); err != nil {
+				return err
+			}
+		}
+	}
+	if !commenterTrusted && !isOkToTest {
+		trusted, err := gitlabTrustedMergeRequest(c.GitLabClient, trigger, e.Project.ID, mr)
+		if err != nil {
+			return err
+		}
+		if !trusted {
+			c.Logger.Infof("Ignoring comment from %s on untrusted merge request.", e.User.Username)
+			return nil
+		}
+	} else if !commenterTrusted {
+		c.Logger.Infof("Ignoring /ok-to-test from untrusted user %s.", e.User.Username)
+		return nil
+	}
+
+	contextGetter := func() (sets.Set[string], sets.Set[string], error) {
+		statuses, err := c.GitLabClient.ListCommitStatuses(e.Project.ID, mr.LastCommit.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		failed, all := sets.New[string](), sets.New[string]()
+		for _, status := range statuses {
+			all.Insert(status.Name)
+			if status.State == gitlab.CommitStateFailed || status.State == gitlab.CommitStateCanceled {
+				failed.Insert(status.Name)
+			}
+		}
+		return failed, all, nil
+	}
+	filter, err := pjutil.PresubmitFilter(honorOkToTest, contextGetter, body, c.Logger)
+	if err != nil {
+		return err
+	}
+	changes := gitlabChanges(c.GitLabClient, e.Project.ID, mr.IID)
+	toTest, err := pjutil.FilterPresubmits(filter, changes, mr.TargetBranch, presubmits, c.Logger)
+	if err != nil {
+		return err
+	}
+	if len(toTest) == 0 {
+		if !testCommandRe.MatchString(body) {
+			return nil
+		}
+		return gitlabListJobsMsg(c, e.Project.ID, mr, changes, presubmits, body)
+	}
+	additionalLabels := map[string]string{}
+	if isRetest(body) {
+		additionalLabels[kube.RetestLabel] = "true"
+		if trigger.RetestBudget != nil {
+			org, repo := e.Project.OrgRepo()
+			resp, err := checkRetestBudget(c.ProwJobClient, *trigger.RetestBudget, org, repo, mr.IID, time.Now())
+			if err != nil {
+				return err
+			}
+			if resp != "" {
+				c.Logger.Infof("Commenting \"%s\".", resp)
+				return c.GitLabClient.CreateMergeRequestNote(e.Project.ID, mr.IID, resp)
+			}
+		}
+	}
+	return runGitLabRequested(c, e.Project, mr, toTest, e.GUID, additionalLabels)
+}
+
+func gitlabPresubmits(c GitLabClient, project gitlab.Project) []config.Presubmit {
+	// In-repo config is not supported for GitLab yet.
+	return c.Config.GetPresubmitsStatic(project.PathWithNamespace)
+}
+
+func gitlabChanges(glc gitlab.Client, projectID, iid int) config.ChangedFilesProvider {
+	var changedFiles []string
+	return func() ([]string, error) {
+		if changedFiles != nil {
+			return changedFiles, nil
+		}
+		changes, err := glc.GetMergeRequestChanges(projectID, iid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get merge request changes: %w", err)
+		}
+		changedFiles = []string{}
+		for _, change := range changes {
+			changedFiles = append(changedFiles, change.NewPath)
+			if change.RenamedFile {
+				changedFiles = append(changedFiles, change.OldPath)
+			}
+		}
+		return changedFiles, nil
+	}
+}
+
+// gitlabTrustedUser returns whether the user has at least developer access to
+// the project, which is what GitLab requires to push to it.
+func gitlabTrustedUser(glc gitlab.Client, projectID, userID int) (bool, error) {
+	member, err := glc.GetProjectMember(projectID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get membership of user %d: %w", userID, err)
+	}
+	return member != nil && member.AccessLevel >= gitlab.AccessLevelDeveloper, nil
+}
+
+func gitlabTrustedMergeRequest(glc gitlab.Client, trigger plugins.Trigger, projectID int, mr gitlab.MergeRequest) (bool, error) {
+	if trusted, err := gitlabTrustedUser(glc, projectID, mr.AuthorID); err != nil || trusted {
+		return trusted, err
+	}
+	return !trigger.IgnoreOkToTest && gitlabHasLabel(mr.Labels, labels.OkToTest), nil
+}
+
+func gitlabHasLabel(mrLabels []gitlab.Label, label string) bool {
+	for _, l := range mrLabels {
+		if l.Title == label {
+			return true
+		}
+	}
+	return false
+}
+
+func gitlabWelcomeMsg(glc gitlab.Client, trigger plugins.Trigger, projectID int, mr gitlab.MergeRequest) error {
+	var errs []error
+	if !trigger.IgnoreOkToTest {
+		if err := glc.AddMergeRequestLabels(projectID, mr.IID, labels.NeedsOkToTest); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	comment := "Hi! Thanks for your merge request.\n\n" +
+		"Tests will not run automatically because you are not a developer of this project. " +
+		"Once a project developer has verified that this merge request is safe to test, they can reply with `/ok-to-test`.\n\n" +
+		"Project developers can also run individual jobs with `/test <job name>`."
+	if err := glc.CreateMergeRequestNote(projectID, mr.IID, comment); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func gitlabListJobsMsg(c GitLabClient, projectID int, mr gitlab.MergeRequest, changes config.ChangedFilesProvider, presubmits []config.Presubmit, body string) error {
+	msg, err := listJobsMsg(c.Logger, changes, mr.TargetBranch, presubmits, body)
+	if err != nil {
+		return err
+	}
+	return c.GitLabClient.CreateMergeRequestNote(projectID, mr.IID, msg)
+}
+
+// listJobsMsg explains that a /test command matched no jobs and lists the
+// jobs that can be triggered instead.
+func listJobsMsg(log *logrus.Entry, changes config.ChangedFilesProvider, branch string, presubmits []config.Presubmit, body string) (string, error) {
+	testAll, optional, required, err := pjutil.AvailablePresubmits(changes, branch, presubmits, log)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "No presubmit jobs matched %q.\n\n", strings.TrimSpace(body))
+	if testAll.Len() > 0 {
+		sb.WriteString("The following jobs run with `/test all`:\n")
+		for _, name := range sets.List(testAll) {
+			fmt.Fprintf(&sb, "* `%s`\n", name)
+		}
+	}
+	commands := sets.List(required.Union(optional))
+	sort.Strings(commands)
+	if len(commands) > 0 {
+		sb.WriteString("\nThe following commands are available to trigger jobs individually:\n")
+		for _, command := range commands {
+			fmt.Fprintf(&sb, "* `%s`\n", command)
+		}
+	}
+	return sb.String(), nil
+}
+
+func gitlabRefs(c GitLabClient, project gitlab.Project, mr gitlab.MergeRequest) (prowapi.Refs, error) {
+	baseSHA, err := c.GitLabClient.GetBranchHead(project.ID, mr.TargetBranch)
+	if err != nil {
+		return prowapi.Refs{}, fmt.Errorf("failed to get baseSHA: %w", err)
+	}
+	org, repo := project.OrgRepo()
+	return prowapi.Refs{
+		Org:      org,
+		Repo:     repo,
+		RepoLink: project.WebURL,
+		BaseRef:  mr.TargetBranch,
+		BaseSHA:  baseSHA,
+		BaseLink: fmt.Sprintf("%s/-/commit/%s", project.WebURL, baseSHA),
+		CloneURI: project.GitHTTPURL,
+		Pulls: []prowapi.Pull{
+			{
+				Number:     mr.IID,
+				SHA:        mr.LastCommit.ID,
+				Ref:        fmt.Sprintf("refs/merge-requests/%d/head", mr.IID),
+				HeadRef:    mr.SourceBranch,
+				Title:      mr.Title,
+				Link:       mr.URL,
+				CommitLink: fmt.Sprintf("%s/diffs?commit_id=%s", mr.URL, mr.LastCommit.ID),
+			},
+		},
+	}, nil
+}
+
+func runGitLabRequested(c GitLabClient, project gitlab.Project, mr gitlab.MergeRequest, requestedJobs []config.Presubmit, eventGUID string, additionalLabels map[string]string) error {
+	if len(requestedJobs) == 0 {
+		return nil
+	}
+	refs, err := gitlabRefs(c, project, mr)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{kube.GitLabProjectID: strconv.Itoa(project.ID)}
+	for k, v := range additionalLabels {
+		labels[k] = v
+	}
+	annotations := map[string]string{kube.GitLabInstance: project.Host()}
+	return runRequestedForRefs(c.Logger, c.ProwJobClient, c.Config, refs, requestedJobs, eventGUID, labels, annotations)
+}
@@ -0,0 +1,392 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/gitlab"
+	"sigs.k8s.io/prow/pkg/gitlab/fakegitlab"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/labels"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+const (
+	gitlabProjectID = 42
+	gitlabDeveloper = 7
+	gitlabRando     = 8
+)
+
+func newGitLabTestClient(t *testing.T) (GitLabClient, *fakegitlab.FakeClient, *fake.Clientset) {
+	glc := fakegitlab.NewFakeClient()
+	glc.Members[gitlabProjectID] = map[int]int{gitlabDeveloper: gitlab.AccessLevelDeveloper}
+	glc.BranchHeads["42/main"] = "base-sha"
+	glc.Changes[fakegitlab.MergeRequestKey(gitlabProjectID, 1)] = []gitlab.MergeRequestChange{{NewPath: "docs/README.md", OldPath: "docs/README.md"}}
+	pjClient := fake.NewSimpleClientset()
+	c := GitLabClient{
+		GitLabClient:  glc,
+		ProwJobClient: pjClient.ProwV1().ProwJobs("prowjobs"),
+		Config:        &config.Config{},
+		Logger:        logrus.WithField("plugin", PluginName),
+	}
+	presubmits := map[string][]config.Presubmit{
+		"group/project": {
+			{
+				JobBase:      config.JobBase{Name: "unit"},
+				AlwaysRun:    true,
+				Reporter:     config.Reporter{Context: "unit"},
+				Trigger:      `(?m)^/test (?:.*? )?unit(?: .*?)?$`,
+				RerunCommand: "/test unit",
+			},
+			{
+				JobBase:             config.JobBase{Name: "e2e"},
+				RegexpChangeMatcher: config.RegexpChangeMatcher{RunIfChanged: "^pkg/"},
+				Reporter:            config.Reporter{Context: "e2e"},
+				Trigger:             `(?m)^/test (?:.*? )?e2e(?: .*?)?$`,
+				RerunCommand:        "/test e2e",
+			},
+			{
+				JobBase:      config.JobBase{Name: "lint"},
+				Reporter:     config.Reporter{Context: "lint"},
+				Trigger:      `(?m)^/test (?:.*? )?lint(?: .*?)?$`,
+				RerunCommand: "/test lint",
+			},
+		},
+	}
+	if err := c.Config.SetPresubmits(presubmits); err != nil {
+		t.Fatalf("failed to set presubmits: %v", err)
+	}
+	return c, glc, pjClient
+}
+
+func gitlabProject() gitlab.Project {
+	return gitlab.Project{
+		ID:                gitlabProjectID,
+		WebURL:            "https://gitlab.example.com/group/project",
+		GitHTTPURL:        "https://gitlab.example.com/group/project.git",
+		PathWithNamespace: "group/project",
+	}
+}
+
+func startedGitLabJobs(t *testing.T, pjClient *fake.Clientset) []string {
+	pjs, err := pjClient.ProwV1().ProwJobs("prowjobs").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list prowjobs: %v", err)
+	}
+	var names []string
+	for _, pj := range pjs.Items {
+		names = append(names, pj.Spec.Job)
+		if pj.Labels[kube.GitLabProjectID] != "42" || pj.Annotations[kube.GitLabInstance] != "https://gitlab.example.com" {
+			t.Errorf("prowjob %s lacks gitlab labels: %v %v", pj.Spec.Job, pj.Labels, pj.Annotations)
+		}
+		if refs := pj.Spec.Refs; refs.Org != "group" || refs.Repo != "project" || refs.BaseSHA != "base-sha" || refs.Pulls[0].SHA != "head-sha" {
+			t.Errorf("unexpected refs for prowjob %s: %+v", pj.Spec.Job, refs)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestHandleGitLabMergeRequest(t *testing.T) {
+	testCases := []struct {
+		name            string
+		author          int
+		action          gitlab.MergeRequestAction
+		oldRev          string
+		draft           bool
+		mrLabels        []string
+		expectedJobs    []string
+		expectedComment bool
+		expectedLabels  []string
+	}{
+		{
+			name:         "developer opens merge request",
+			author:       gitlabDeveloper,
+			action:       gitlab.MergeRequestActionOpen,
+			expectedJobs: []string{"unit"},
+		},
+		{
+			name:   "developer opens draft merge request",
+			author: gitlabDeveloper,
+			action: gitlab.MergeRequestActionOpen,
+			draft:  true,
+		},
+		{
+			name:            "untrusted user opens merge request",
+			author:          gitlabRando,
+			action:          gitlab.MergeRequestActionOpen,
+			expectedComment: true,
+			expectedLabels:  []string{labels.NeedsOkToTest},
+		},
+		{
+			name:         "untrusted user pushes to merge request with ok-to-test",
+			author:       gitlabRando,
+			action:       gitlab.MergeRequestActionUpdate,
+			oldRev:       "old-sha",
+			mrLabels:     []string{labels.OkToTest},
+			expectedJobs: []string{"unit"},
+		},
+		{
+			name:   "untrusted user pushes to merge request without ok-to-test",
+			author: gitlabRando,
+			action: gitlab.MergeRequestActionUpdate,
+			oldRev: "old-sha",
+		},
+		{
+			name:   "developer edits the title",
+			author: gitlabDeveloper,
+			action: gitlab.MergeRequestActionUpdate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, glc, pjClient := newGitLabTestClient(t)
+			var mrLabels []gitlab.Label
+			for _, l := range tc.mrLabels {
+				mrLabels = append(mrLabels, gitlab.Label{Title: l})
+			}
+			event := gitlab.MergeRequestEvent{
+				Project: gitlabProject(),
+				ObjectAttributes: gitlab.MergeRequest{
+					IID:          1,
+					Action:       tc.action,
+					OldRev:       tc.oldRev,
+					AuthorID:     tc.author,
+					TargetBranch: "main",
+					Draft:        tc.draft,
+					LastCommit:   gitlab.Commit{ID: "head-sha"},
+				},
+				Labels: mrLabels,
+			}
+			trigger := plugins.Trigger{}
+			trigger.SetDefaults()
+			if err := HandleGitLabMergeRequest(c, trigger, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedJobs, startedGitLabJobs(t, pjClient)); diff != "" {
+				t.Errorf("unexpected jobs started: %s", diff)
+			}
+			key := fakegitlab.MergeRequestKey(gitlabProjectID, 1)
+			if commented := len(glc.Notes[key]) > 0; commented != tc.expectedComment {
+				t.Errorf("expected comment: %t, got notes %v", tc.expectedComment, glc.Notes[key])
+			}
+			var gotLabels []string
+			if glc.Labels[key] != nil && glc.Labels[key].Len() > 0 {
+				gotLabels = sets.List(glc.Labels[key])
+			}
+			if diff := cmp.Diff(tc.expectedLabels, gotLabels); diff != "" {
+				t.Errorf("unexpected labels: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleGitLabNote(t *testing.T) {
+	testCases := []struct {
+		name            string
+		commenter       int
+		author          int
+		body            string
+		mrLabels        []string
+		statuses        []gitlab.CommitStatus
+		expectedJobs    []string
+		expectedComment bool
+		expectedLabels  []string
+	}{
+		{
+			name:         "developer runs a single job",
+			commenter:    gitlabDeveloper,
+			author:       gitlabRando,
+			body:         "/test lint",
+			expectedJobs: []string{"lint"},
+		},
+		{
+			name:         "developer runs all jobs",
+			commenter:    gitlabDeveloper,
+			author:       gitlabDeveloper,
+			body:         "/test all",
+			expectedJobs: []string{"unit"},
+		},
+		{
+			name:      "untrusted user can't test untrusted merge request",
+			commenter: gitlabRando,
+			author:    gitlabRando,
+			body:      "/test lint",
+		},
+		{
+			name:         "untrusted user can test trusted merge request",
+			commenter:    gitlabRando,
+			author:       gitlabDeveloper,
+			body:         "/test lint",
+			expectedJobs: []string{"lint"},
+		},
+		{
+			name:           "developer approves untrusted merge request",
+			commenter:      gitlabDeveloper,
+			author:         gitlabRando,
+			body:           "/ok-to-test",
+			mrLabels:       []string{labels.NeedsOkToTest},
+			expectedJobs:   []string{"unit"},
+			expectedLabels: []string{labels.OkToTest},
+		},
+		{
+			name:      "untrusted user can't approve merge request",
+			commenter: gitlabRando,
+			author:    gitlabRando,
+			body:      "/ok-to-test",
+		},
+		{
+			name:      "retest runs failed jobs",
+			commenter: gitlabDeveloper,
+			author:    gitlabDeveloper,
+			body:      "/retest",
+			statuses: []gitlab.CommitStatus{
+				{Name: "unit", State: gitlab.CommitStateSuccess},
+				{Name: "lint", State: gitlab.CommitStateFailed},
+			},
+			expectedJobs: []string{"lint"},
+		},
+		{
+			name:            "unknown job lists available jobs",
+			commenter:       gitlabDeveloper,
+			author:          gitlabDeveloper,
+			body:            "/test nope",
+			expectedComment: true,
+		},
+		{
+			name:      "unrelated comment is ignored",
+			commenter: gitlabDeveloper,
+			author:    gitlabDeveloper,
+			body:      "looks great",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, glc, pjClient := newGitLabTestClient(t)
+			glc.Statuses[fakegitlab.CommitKey(gitlabProjectID, "head-sha")] = tc.statuses
+			key := fakegitlab.MergeRequestKey(gitlabProjectID, 1)
+			var mrLabels []gitlab.Label
+			for _, l := range tc.mrLabels {
+				mrLabels = append(mrLabels, gitlab.Label{Title: l})
+				glc.AddMergeRequestLabels(gitlabProjectID, 1, l)
+			}
+			event := gitlab.NoteEvent{
+				User:    gitlab.User{ID: tc.commenter},
+				Project: gitlabProject(),
+				ObjectAttributes: gitlab.Note{
+					Note:         tc.body,
+					NoteableType: gitlab.NoteableTypeMergeRequest,
+				},
+				MergeRequest: &gitlab.MergeRequest{
+					IID:          1,
+					State:        "opened",
+					AuthorID:     tc.author,
+					TargetBranch: "main",
+					LastCommit:   gitlab.Commit{ID: "head-sha"},
+					Labels:       mrLabels,
+				},
+			}
+			trigger := plugins.Trigger{}
+			trigger.SetDefaults()
+			if err := HandleGitLabNote(c, trigger, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedJobs, startedGitLabJobs(t, pjClient)); diff != "" {
+				t.Errorf("unexpected jobs started: %s", diff)
+			}
+			if commented := len(glc.Notes[key]) > 0; commented != tc.expectedComment {
+				t.Errorf("expected comment: %t, got notes %v", tc.expectedComment, glc.Notes[key])
+			}
+			var gotLabels []string
+			if glc.Labels[key] != nil && glc.Labels[key].Len() > 0 {
+				gotLabels = sets.List(glc.Labels[key])
+			}
+			if diff := cmp.Diff(tc.expectedLabels, gotLabels); diff != "" {
+				t.Errorf("unexpected labels: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleGitLabNoteRetestBudget(t *testing.T) {
+	testCases := []struct {
+		name            string
+		retested        bool
+		expectedJobs    []string
+		expectedComment bool
+	}{
+		{
+			name:         "retest within the budget runs failed jobs",
+			expectedJobs: []string{"lint"},
+		},
+		{
+			name:            "retest over the budget is refused",
+			retested:        true,
+			expectedComment: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, glc, pjClient := newGitLabTestClient(t)
+			glc.Statuses[fakegitlab.CommitKey(gitlabProjectID, "head-sha")] = []gitlab.CommitStatus{{Name: "lint", State: gitlab.CommitStateFailed}}
+			if tc.retested {
+				createRetestedJob(t, pjClient, "group", "project")
+			}
+			event := gitlab.NoteEvent{
+				User:    gitlab.User{ID: gitlabDeveloper},
+				Project: gitlabProject(),
+				ObjectAttributes: gitlab.Note{
+					Note:         "/retest",
+					NoteableType: gitlab.NoteableTypeMergeRequest,
+				},
+				MergeRequest: &gitlab.MergeRequest{
+					IID:          1,
+					State:        "opened",
+					AuthorID:     gitlabDeveloper,
+					TargetBranch: "main",
+					LastCommit:   gitlab.Commit{ID: "head-sha"},
+				},
+			}
+			trigger := plugins.Trigger{RetestBudget: &plugins.RetestBudget{MaxPerPR: 1, WindowDuration: time.Hour}}
+			trigger.SetDefaults()
+			if err := HandleGitLabNote(c, trigger, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedJobs, retestJobs(t, pjClient)); diff != "" {
+				t.Errorf("unexpected retest jobs started: %s", diff)
+			}
+			key := fakegitlab.MergeRequestKey(gitlabProjectID, 1)
+			if commented := len(glc.Notes[key]) > 0; commented != tc.expectedComment {
+				t.Errorf("expected comment: %t, got notes %v", tc.expectedComment, glc.Notes[key])
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// TrustedViaMembershipService means the user is trusted by the configured
+// external membership service.
+const TrustedViaMembershipService = "membership_service"
+
+// membershipResponse is the response of an external membership service.
+type membershipResponse struct {
+	Trusted bool     `json:"trusted"`
+	Groups  []string `json:"groups,omitempty"`
+}
+
+// trustedByMembershipService asks the external membership service whether
+// user is trusted in org/repo.
+func trustedByMembershipService(s *plugins.MembershipService, user, org, repo string) (bool, error) {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return false, fmt.Errorf("invalid membership service url %q: %w", s.URL, err)
+	}
+	query := u.Query()
+	query.Set("user", user)
+	query.Set("org", org)
+	query.Set("repo", repo)
+	u.RawQuery = query.Encode()
+
+	client := &http.Client{Timeout: s.TimeoutDuration}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return false, fmt.Errorf("failed to query membership service: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read membership service response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("membership service responded with %d: %s", resp.StatusCode, string(body))
+	}
+	var membership membershipResponse
+	if err := json.Unmarshal(body, &membership); err != nil {
+		return false, fmt.Errorf("failed to unmarshal membership service response: %w", err)
+	}
+	return membership.Trusted || sets.New[string](s.TrustedGroups...).HasAny(membership.Groups...), nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestTrustedUserMembershipService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("org") != "kubernetes" || query.Get("repo") != "kubernetes" {
+			http.Error(w, "unexpected org or repo", http.StatusBadRequest)
+			return
+		}
+		var resp membershipResponse
+		switch query.Get("user") {
+		case "employee":
+			resp.Trusted = true
+		case "contractor":
+			resp.Groups = []string{"contractors", "team-a"}
+		case "broken":
+			http.Error(w, "directory unavailable", http.StatusInternalServerError)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name               string
+		user               string
+		trustedGroups      []string
+		expectedTrusted    bool
+		expectedReason     string
+		expectedTrustedVia string
+		expectedErr        bool
+	}{
+		{
+			name:               "org member is trusted without asking the service",
+			user:               "test",
+			expectedTrusted:    true,
+			expectedTrustedVia: "",
+		},
+		{
+			name:               "service trusts user",
+			user:               "employee",
+			expectedTrusted:    true,
+			expectedTrustedVia: TrustedViaMembershipService,
+		},
+		{
+			name:               "user is in a trusted group",
+			user:               "contractor",
+			trustedGroups:      []string{"team-a"},
+			expectedTrusted:    true,
+			expectedTrustedVia: TrustedViaMembershipService,
+		},
+		{
+			name:           "user is not in a trusted group",
+			user:           "contractor",
+			trustedGroups:  []string{"team-b"},
+			expectedReason: (notMember | notCollaborator | notTrustedByMembershipService).String(),
+		},
+		{
+			name:           "unknown user",
+			user:           "stranger",
+			expectedReason: (notMember | notCollaborator | notTrustedByMembershipService).String(),
+		},
+		{
+			name:        "service error",
+			user:        "broken",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegithub.NewFakeClient()
+			fc.OrgMembers = map[string][]string{"kubernetes": {"test"}}
+			service := &plugins.MembershipService{URL: server.URL, TrustedGroups: tc.trustedGroups, TimeoutDuration: time.Second}
+
+			resp, err := TrustedUser(fc, false, nil, nil, service, "", tc.user, "kubernetes", "kubernetes")
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if resp.IsTrusted != tc.expectedTrusted {
+				t.Errorf("expected trusted: %t, got: %t", tc.expectedTrusted, resp.IsTrusted)
+			}
+			if resp.Reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, resp.Reason)
+			}
+			if resp.TrustedVia != tc.expectedTrustedVia {
+				t.Errorf("expected trusted via %q, got %q", tc.expectedTrustedVia, resp.TrustedVia)
+			}
+		})
+	}
+}
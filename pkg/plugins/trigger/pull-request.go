@@ -60,7 +60,7 @@ func handlePR(c Client, trigger plugins.Trigger, pr github.PullRequestEvent) err
 		return pr.PullRequest.Head.SHA, nil
 	}
 
-	presubmits := getPresubmits(c.Logger, c.GitClient, c.Config, org+"/"+repo, baseSHAGetter, headSHAGetter)
+	presubmits := getPresubmits(c.Logger, c.InRepoConfigGetter, c.GitClient, c.Config, org+"/"+repo, baseSHAGetter, headSHAGetter)
 	if len(presubmits) == 0 {
 		return nil
 	}
@@ -76,11 +76,12 @@ func handlePR(c Client, trigger plugins.Trigger, pr github.PullRequestEvent) err
 		// When a PR is opened, if the author is in the org then build it.
 		// Otherwise, ask for "/ok-to-test". There's no need to look for previous
 		// "/ok-to-test" comments since the PR was just opened!
-		trustedResponse, err := TrustedUser(c.GitHubClient, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedOrg, author, org, repo)
+		trustedResponse, err := TrustedUser(c.GitHubClient, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedBots, trigger.MembershipService, trigger.TrustedOrg, author, org, repo)
 		member := trustedResponse.IsTrusted
 		if err != nil {
 			return fmt.Errorf("could not check membership: %s", err)
 		}
+		auditTrustedVia(c.Logger, author, trustedResponse)
 		if member {
 			// dedicated draft check for create to comment on the PR
 			if pr.PullRequest.Draft {
@@ -123,6 +124,15 @@ func handlePR(c Client, trigger plugins.Trigger, pr github.PullRequestEvent) err
 		if err := abortAllJobs(c, &pr.PullRequest); err != nil {
 			errs = append(errs, fmt.Errorf("failed to abort jobs: %w", err))
 		}
+		if trigger.ExpireOkToTestOnPush {
+			expired, err := expireOkToTest(c, trigger, pr.PullRequest)
+			if err != nil {
+				return utilerrors.NewAggregate(append(errs, fmt.Errorf("failed to expire %s: %w", labels.OkToTest, err)))
+			}
+			if expired {
+				return utilerrors.NewAggregate(errs)
+			}
+		}
 		return utilerrors.NewAggregate(append(errs, buildAllIfTrusted(c, trigger, pr, baseSHA, presubmits)))
 	case github.PullRequestActionLabeled:
 		// When a PR is LGTMd, if it is untrusted then build it once.
@@ -185,11 +195,12 @@ func abortAllJobs(c Client, pr *github.PullRequest) error {
 		}
 		job.Status.State = prowapi.AbortedState
 		job.Status.Description = abortedDescription
+		job.SetConditions()
 		// We use Update and not Patch here, because we are not the authority of the .Status.State field
 		// and must not overwrite changes made to it in the interim by the responsible agent.
 		// The accepted trade-off for now is that this leads to failure if unrelated fields where changed
 		// by another different actor.
-		if _, err := c.ProwJobClient.Update(context.TODO(), &job, metav1.UpdateOptions{}); err != nil && !apierrors.IsConflict(err) {
+		if _, err := c.ProwJobClient.UpdateStatus(context.TODO(), &job, metav1.UpdateOptions{}); err != nil && !apierrors.IsConflict(err) {
 			errs = append(errs, fmt.Errorf("failed to abort job %s: %w", job.Name, err))
 		}
 	}
@@ -247,6 +258,41 @@ func buildAllIfTrusted(c Client, trigger plugins.Trigger, pr github.PullRequestE
 	return nil
 }
 
+// expireOkToTest removes the ok-to-test label from a PR of an untrusted author
+// after new commits were pushed to it, so that the new revision is not tested
+// before a trusted user approves it again. It returns whether the label was
+// removed.
+func expireOkToTest(c Client, trigger plugins.Trigger, pr github.PullRequest) (bool, error) {
+	org, repo, a := orgRepoAuthor(pr)
+	author := string(a)
+	trustedResponse, err := TrustedUser(c.GitHubClient, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedBots, trigger.MembershipService, trigger.TrustedOrg, author, org, repo)
+	if err != nil {
+		return false, fmt.Errorf("error checking %s for trust: %w", author, err)
+	}
+	if trustedResponse.IsTrusted {
+		return false, nil
+	}
+	l, err := c.GitHubClient.GetIssueLabels(org, repo, pr.Number)
+	if err != nil {
+		return false, err
+	}
+	if !github.HasLabel(labels.OkToTest, l) {
+		return false, nil
+	}
+	c.Logger.Infof("Removing %s label after new commits from untrusted author %q.", labels.OkToTest, author)
+	if err := c.GitHubClient.RemoveLabel(org, repo, pr.Number, labels.OkToTest); err != nil {
+		return false, err
+	}
+	if !github.HasLabel(labels.NeedsOkToTest, l) {
+		if err := c.GitHubClient.AddLabel(org, repo, pr.Number, labels.NeedsOkToTest); err != nil {
+			return true, err
+		}
+	}
+	comment := fmt.Sprintf("New changes were pushed to this PR by an untrusted author, so the `%s` label was removed. "+
+		"A trusted user needs to review the changes and leave an `/ok-to-test` message before they are tested.", labels.OkToTest)
+	return true, c.GitHubClient.CreateComment(org, repo, pr.Number, comment)
+}
+
 func welcomeMsg(ghc githubClient, trigger plugins.Trigger, pr github.PullRequest) error {
 	var errors []error
 	org, repo, a := orgRepoAuthor(pr)
@@ -327,7 +373,7 @@ func draftMsg(ghc githubClient, pr github.PullRequest) error {
 // If already known, GitHub labels should be provided to save tokens. Otherwise, it fetches them.
 func TrustedPullRequest(tprc trustedPullRequestClient, trigger plugins.Trigger, author, org, repo string, num int, l []github.Label) ([]github.Label, bool, error) {
 	// First check if the author is a member of the org.
-	if trustedResponse, err := TrustedUser(tprc, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedOrg, author, org, repo); err != nil {
+	if trustedResponse, err := TrustedUser(tprc, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedBots, trigger.MembershipService, trigger.TrustedOrg, author, org, repo); err != nil {
 		return l, false, fmt.Errorf("error checking %s for trust: %w", author, err)
 	} else if trustedResponse.IsTrusted {
 		return l, true, nil
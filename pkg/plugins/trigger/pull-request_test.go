@@ -142,6 +142,9 @@ func TestHandlePullRequest(t *testing.T) {
 		eventSender      string
 		jobToAbort       *prowapi.ProwJob
 		issueLabelsAdded []string
+
+		expireOkToTestOnPush bool
+		issueLabelsRemoved   []string
 	}{
 		{
 			name: "Trusted user open PR should build",
@@ -417,6 +420,38 @@ func TestHandlePullRequest(t *testing.T) {
 			ShouldBuild: true,
 			jobToAbort:  jobToAbort,
 		},
+		{
+			name: "Untrusted user push with ok-to-test should build when ok-to-test does not expire",
+
+			Author:      "u",
+			HasOkToTest: true,
+			prAction:    github.PullRequestActionSynchronize,
+			ShouldBuild: true,
+			jobToAbort:  jobToAbort,
+		},
+		{
+			name: "Untrusted user push should expire ok-to-test and not build",
+
+			Author:               "u",
+			HasOkToTest:          true,
+			prAction:             github.PullRequestActionSynchronize,
+			ShouldBuild:          false,
+			ShouldComment:        true,
+			jobToAbort:           jobToAbort,
+			expireOkToTestOnPush: true,
+			issueLabelsAdded:     []string{"org/repo#0:needs-ok-to-test"},
+			issueLabelsRemoved:   []string{"org/repo#0:ok-to-test"},
+		},
+		{
+			name: "Trusted user push should not expire ok-to-test",
+
+			Author:               "t",
+			HasOkToTest:          true,
+			prAction:             github.PullRequestActionSynchronize,
+			ShouldBuild:          true,
+			jobToAbort:           jobToAbort,
+			expireOkToTestOnPush: true,
+		},
 	}
 	for _, tc := range testcases {
 		t.Logf("running scenario %q", tc.name)
@@ -492,8 +527,9 @@ func TestHandlePullRequest(t *testing.T) {
 				pr.Changes = (json.RawMessage)(data)
 			}
 			trigger := plugins.Trigger{
-				TrustedOrg:     "org",
-				OnlyOrgMembers: true,
+				TrustedOrg:           "org",
+				OnlyOrgMembers:       true,
+				ExpireOkToTestOnPush: tc.expireOkToTestOnPush,
 			}
 			trigger.SetDefaults()
 			if err := handlePR(c, trigger, pr); err != nil {
@@ -532,6 +568,9 @@ func TestHandlePullRequest(t *testing.T) {
 			if cmp.Diff(tc.issueLabelsAdded, g.IssueLabelsAdded) != "" {
 				t.Errorf("exptected added issue labels %v to match %v", tc.issueLabelsAdded, g.IssueLabelsAdded)
 			}
+			if cmp.Diff(tc.issueLabelsRemoved, g.IssueLabelsRemoved) != "" {
+				t.Errorf("expected removed issue labels %v to match %v", tc.issueLabelsRemoved, g.IssueLabelsRemoved)
+			}
 		})
 	}
 }
@@ -71,7 +71,7 @@ func handlePE(c Client, pe github.PushEvent) error {
 		return pe.After, nil
 	}
 
-	postsubmits := getPostsubmits(c.Logger, c.GitClient, c.Config, org+"/"+repo, shaGetter)
+	postsubmits := getPostsubmits(c.Logger, c.InRepoConfigGetter, c.GitClient, c.Config, org+"/"+repo, shaGetter)
 
 	for _, j := range postsubmits {
 		if shouldRun, err := j.ShouldRun(pe.Branch(), listPushEventChanges(pe)); err != nil {
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// isRetest determines if the comment body requests a retest.
+func isRetest(body string) bool {
+	return pjutil.RetestRe.MatchString(body) || pjutil.RetestRequiredRe.MatchString(body) || pjutil.RetestRegexRe.MatchString(body)
+}
+
+// retestRound is a single retest request, which may have created several jobs.
+type retestRound struct {
+	pull  string
+	start time.Time
+}
+
+// retestRounds returns the retests of the repository that happened after
+// since, keyed by the GUID of the event that requested them.
+func retestRounds(pjc prowJobClient, org, repo string, since time.Time) (map[string]retestRound, error) {
+	selector := klabels.SelectorFromSet(klabels.Set{
+		kube.OrgLabel:         org,
+		kube.RepoLabel:        repo,
+		kube.RetestLabel:      "true",
+		kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+	})
+	jobs, err := pjc.List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retested prowjobs: %w", err)
+	}
+	rounds := map[string]retestRound{}
+	for _, job := range jobs.Items {
+		start := job.Status.StartTime.Time
+		if start.Before(since) {
+			continue
+		}
+		guid := job.Labels[github.EventGUID]
+		if guid == "" {
+			guid = job.Name
+		}
+		if round, ok := rounds[guid]; ok && !start.Before(round.start) {
+			continue
+		}
+		rounds[guid] = retestRound{pull: job.Labels[kube.PullLabel], start: start}
+	}
+	return rounds, nil
+}
+
+// checkRetestBudget determines if the PR can be retested within the budget.
+// It returns a user facing explanation if the retest must be refused, or an
+// empty string if it is allowed.
+func checkRetestBudget(pjc prowJobClient, budget plugins.RetestBudget, org, repo string, number int, now time.Time) (string, error) {
+	rounds, err := retestRounds(pjc, org, repo, now.Add(-budget.WindowDuration))
+	if err != nil {
+		return "", err
+	}
+	pull := strconv.Itoa(number)
+	var prRetests int
+	var lastRetest time.Time
+	for _, round := range rounds {
+		if round.pull != pull {
+			continue
+		}
+		prRetests++
+		if round.start.After(lastRetest) {
+			lastRetest = round.start
+		}
+	}
+
+	window := budget.WindowDuration.String()
+	if budget.MaxPerPR > 0 && prRetests >= budget.MaxPerPR {
+		return fmt.Sprintf("This PR has already been retested %d times in the last %s, which is the maximum allowed. Please investigate the failures instead of retesting.", prRetests, window), nil
+	}
+	if budget.MaxPerRepo > 0 && len(rounds) >= budget.MaxPerRepo {
+		return fmt.Sprintf("The PRs of %s/%s have already been retested %d times in the last %s, which is the maximum allowed. Please try again later.", org, repo, len(rounds), window), nil
+	}
+	if backoff := budget.Backoff(prRetests); backoff > 0 {
+		if next := lastRetest.Add(backoff); now.Before(next) {
+			return fmt.Sprintf("This PR has been retested %d times in the last %s. Further retests are backed off until %s.", prRetests, window, next.UTC().Format(time.RFC3339)), nil
+		}
+	}
+	return "", nil
+}
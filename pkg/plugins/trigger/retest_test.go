@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestCheckRetestBudget(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	retestJob := func(name, pull, guid string, age time.Duration) runtime.Object {
+		return &prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "prowjobs",
+				Labels: map[string]string{
+					kube.OrgLabel:         "org",
+					kube.RepoLabel:        "repo",
+					kube.PullLabel:        pull,
+					kube.RetestLabel:      "true",
+					kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+					github.EventGUID:      guid,
+				},
+			},
+			Status: prowapi.ProwJobStatus{StartTime: metav1.NewTime(now.Add(-age))},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		budget   plugins.RetestBudget
+		jobs     []runtime.Object
+		expected string
+	}{
+		{
+			name:   "no retests yet",
+			budget: plugins.RetestBudget{MaxPerPR: 1, MaxPerRepo: 1, WindowDuration: time.Hour, InitialBackoffDuration: time.Hour, MaxBackoffDuration: time.Hour},
+		},
+		{
+			name:   "jobs of one retest are counted once",
+			budget: plugins.RetestBudget{MaxPerPR: 2, WindowDuration: time.Hour},
+			jobs: []runtime.Object{
+				retestJob("a", "1", "guid-1", time.Minute),
+				retestJob("b", "1", "guid-1", time.Minute),
+			},
+		},
+		{
+			name:   "PR budget exhausted",
+			budget: plugins.RetestBudget{MaxPerPR: 2, WindowDuration: time.Hour},
+			jobs: []runtime.Object{
+				retestJob("a", "1", "guid-1", 10*time.Minute),
+				retestJob("b", "1", "guid-2", time.Minute),
+			},
+			expected: "This PR has already been retested 2 times in the last 1h0m0s, which is the maximum allowed. Please investigate the failures instead of retesting.",
+		},
+		{
+			name:   "retests outside of the window are not counted",
+			budget: plugins.RetestBudget{MaxPerPR: 2, WindowDuration: time.Hour},
+			jobs: []runtime.Object{
+				retestJob("a", "1", "guid-1", 2*time.Hour),
+				retestJob("b", "1", "guid-2", time.Minute),
+			},
+		},
+		{
+			name:   "repo budget exhausted by other PRs",
+			budget: plugins.RetestBudget{MaxPerPR: 2, MaxPerRepo: 2, WindowDuration: time.Hour},
+			jobs: []runtime.Object{
+				retestJob("a", "2", "guid-1", 10*time.Minute),
+				retestJob("b", "3", "guid-2", time.Minute),
+			},
+			expected: "The PRs of org/repo have already been retested 2 times in the last 1h0m0s, which is the maximum allowed. Please try again later.",
+		},
+		{
+			name:   "backoff after the first retest",
+			budget: plugins.RetestBudget{WindowDuration: time.Hour, InitialBackoffDuration: 5 * time.Minute, MaxBackoffDuration: time.Hour},
+			jobs: []runtime.Object{
+				retestJob("a", "1", "guid-1", time.Minute),
+			},
+			expected: "This PR has been retested 1 times in the last 1h0m0s. Further retests are backed off until 2024-01-01T12:04:00Z.",
+		},
+		{
+			name:   "backoff doubles with further retests",
+			budget: plugins.RetestBudget{WindowDuration: time.Hour, InitialBackoffDuration: 5 * time.Minute, MaxBackoffDuration: time.Hour},
+			jobs: []runtime.Object{
+				retestJob("a", "1", "guid-1", 30*time.Minute),
+				retestJob("b", "1", "guid-2", 7*time.Minute),
+			},
+			expected: "This PR has been retested 2 times in the last 1h0m0s. Further retests are backed off until 2024-01-01T12:03:00Z.",
+		},
+		{
+			name:   "backoff expired",
+			budget: plugins.RetestBudget{WindowDuration: time.Hour, InitialBackoffDuration: 5 * time.Minute, MaxBackoffDuration: time.Hour},
+			jobs: []runtime.Object{
+				retestJob("a", "1", "guid-1", 30*time.Minute),
+				retestJob("b", "1", "guid-2", 11*time.Minute),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pjc := fake.NewSimpleClientset(tc.jobs...).ProwV1().ProwJobs("prowjobs")
+			got, err := checkRetestBudget(pjc, tc.budget, "org", "repo", 1, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+// createRetestedJob creates a job of an earlier retest of PR 1 of the repo.
+func createRetestedJob(t *testing.T, pjClient *fake.Clientset, org, repo string) {
+	pj := &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "retested",
+			Namespace: "prowjobs",
+			Labels: map[string]string{
+				kube.OrgLabel:         org,
+				kube.RepoLabel:        repo,
+				kube.PullLabel:        "1",
+				kube.RetestLabel:      "true",
+				kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+			},
+		},
+		Spec:   prowapi.ProwJobSpec{Job: "lint"},
+		Status: prowapi.ProwJobStatus{StartTime: metav1.NewTime(time.Now().Add(-time.Minute))},
+	}
+	if _, err := pjClient.ProwV1().ProwJobs("prowjobs").Create(context.Background(), pj, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create retested prowjob: %v", err)
+	}
+}
+
+// retestJobs returns the names of the jobs started by retests, except for the
+// one created by createRetestedJob.
+func retestJobs(t *testing.T, pjClient *fake.Clientset) []string {
+	pjs, err := pjClient.ProwV1().ProwJobs("prowjobs").List(context.Background(), metav1.ListOptions{LabelSelector: kube.RetestLabel + "=true"})
+	if err != nil {
+		t.Fatalf("failed to list prowjobs: %v", err)
+	}
+	var names []string
+	for _, pj := range pjs.Items {
+		if pj.Name != "retested" {
+			names = append(names, pj.Spec.Job)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
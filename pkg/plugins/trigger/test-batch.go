@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/pjutil"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// maxTestBatchPulls is the maximum number of PRs a single /test-batch
+// command may test together.
+const maxTestBatchPulls = 10
+
+var (
+	testBatchRe       = regexp.MustCompile(`(?mi)^/test-batch((?:[ \t]+#?\d+)+)[ \t]*$`)
+	testBatchNumberRe = regexp.MustCompile(`\d+`)
+)
+
+// testBatchPulls returns the sorted numbers of the PRs to test together,
+// including the PR the command was left on.
+func testBatchPulls(body string, number int) []int {
+	numbers := sets.New[int](number)
+	for _, match := range testBatchRe.FindAllStringSubmatch(body, -1) {
+		for _, n := range testBatchNumberRe.FindAllString(match[1], -1) {
+			if i, err := strconv.Atoi(n); err == nil {
+				numbers.Insert(i)
+			}
+		}
+	}
+	return sets.List(numbers)
+}
+
+// handleTestBatch creates batch jobs that test the PR together with the PRs
+// listed in a /test-batch command. The jobs are the required presubmits tide
+// would run for such a batch, so that coupled PRs can be validated before
+// tide picks them up. Crier reports the results on every PR of the batch.
+func handleTestBatch(c Client, trigger plugins.Trigger, gc github.GenericCommentEvent) error {
+	org := gc.Repo.Owner.Login
+	repo := gc.Repo.Name
+	respond := func(msg string) error {
+		c.Logger.Infof("Commenting \"%s\".", msg)
+		return c.GitHubClient.CreateComment(org, repo, gc.Number, plugins.FormatResponseRaw(gc.Body, gc.HTMLURL, gc.User.Login, msg))
+	}
+
+	trustedResponse, err := TrustedUser(c.GitHubClient, trigger.OnlyOrgMembers, trigger.TrustedApps, trigger.TrustedBots, trigger.MembershipService, trigger.TrustedOrg, gc.User.Login, org, repo)
+	if err != nil {
+		return fmt.Errorf("error checking trust of %s: %w", gc.User.Login, err)
+	}
+	if !trustedResponse.IsTrusted {
+		return respond("Only trusted users can start batch tests. " + trustedResponse.Reason)
+	}
+	auditTrustedVia(c.Logger, gc.User.Login, trustedResponse)
+
+	numbers := testBatchPulls(gc.Body, gc.Number)
+	if len(numbers) < 2 {
+		return respond("`/test-batch` needs at least one other PR to test this PR with, e.g. `/test-batch #123`.")
+	}
+	if len(numbers) > maxTestBatchPulls {
+		return respond(fmt.Sprintf("`/test-batch` can test at most %d PRs together.", maxTestBatchPulls))
+	}
+
+	var prs []github.PullRequest
+	var problems []string
+	for _, number := range numbers {
+		pr, err := c.GitHubClient.GetPullRequest(org, repo, number)
+		if err != nil {
+			return fmt.Errorf("failed to get PR #%d: %w", number, err)
+		}
+		switch {
+		case pr.State != github.PullRequestStateOpen:
+			problems = append(problems, fmt.Sprintf("#%d is not open", number))
+		case pr.Mergable != nil && !*pr.Mergable:
+			problems = append(problems, fmt.Sprintf("#%d has merge conflicts", number))
+		}
+		prs = append(prs, *pr)
+	}
+	var base string
+	for _, pr := range prs {
+		if pr.Number == gc.Number {
+			base = pr.Base.Ref
+		}
+	}
+	for _, pr := range prs {
+		if pr.Base.Ref != base {
+			problems = append(problems, fmt.Sprintf("#%d does not target %s", pr.Number, base))
+		}
+	}
+	if len(problems) > 0 {
+		return respond(fmt.Sprintf("Cannot test these PRs together: %s.", strings.Join(sets.List(sets.New[string](problems...)), ", ")))
+	}
+
+	baseSHA, err := c.GitHubClient.GetRef(org, repo, "heads/"+base)
+	if err != nil {
+		return fmt.Errorf("failed to get the head of %s: %w", base, err)
+	}
+	presubmits, err := testBatchPresubmits(c, org, repo, base, baseSHA, prs)
+	if err != nil {
+		return err
+	}
+	if len(presubmits) == 0 {
+		return respond(fmt.Sprintf("There are no required presubmits to run for a batch of %s.", formatPulls(numbers)))
+	}
+
+	refs := pjutil.BatchRefs(prs, baseSHA)
+	var errs []error
+	var started []string
+	for _, job := range presubmits {
+		labels := map[string]string{
+			github.EventGUID:        gc.GUID,
+			kube.OnDemandBatchLabel: "true",
+		}
+		for k, v := range job.Labels {
+			labels[k] = v
+		}
+		spec := pjutil.BatchSpec(job, refs)
+		spec.Report = !job.SkipReport
+		pj := pjutil.NewProwJob(spec, labels, job.Annotations, pjutil.RequireScheduling(c.Config.Scheduler.Enabled))
+		c.Logger.WithFields(pjutil.ProwJobFields(&pj)).Info("Creating a new batch prowjob.")
+		if err := createWithRetry(context.TODO(), c.ProwJobClient, &pj); err != nil {
+			c.Logger.WithError(err).Error("Failed to create prowjob.")
+			errs = append(errs, err)
+			continue
+		}
+		started = append(started, job.Name)
+	}
+	if len(started) > 0 {
+		msg := fmt.Sprintf("Started %s for the batch of %s on top of %s. The results will be reported on every PR of the batch.",
+			strings.Join(started, ", "), formatPulls(numbers), baseSHA)
+		for _, number := range numbers {
+			if err := c.GitHubClient.CreateComment(org, repo, number, plugins.FormatResponseRaw(gc.Body, gc.HTMLURL, gc.User.Login, msg)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// testBatchPresubmits returns the required presubmits that tide would run for
+// a batch of the PRs.
+func testBatchPresubmits(c Client, org, repo, base, baseSHA string, prs []github.PullRequest) ([]config.Presubmit, error) {
+	var headSHAGetters []config.RefGetter
+	for _, pr := range prs {
+		headSHAGetters = append(headSHAGetters, config.NewRefGetterForGitHubPullRequest(c.GitHubClient, org, repo, pr.Number).HeadSHA)
+	}
+	presubmits, err := plugins.GetPresubmits(c.InRepoConfigGetter, c.Config, c.GitClient, org+"/"+repo, base, func() (string, error) { return baseSHA, nil }, headSHAGetters...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presubmits: %w", err)
+	}
+
+	var changes []string
+	changesLoaded := false
+	changedFiles := func() ([]string, error) {
+		if changesLoaded {
+			return changes, nil
+		}
+		files := sets.New[string]()
+		for _, pr := range prs {
+			prChanges, err := c.GitHubClient.GetPullRequestChanges(org, repo, pr.Number)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get changes of PR #%d: %w", pr.Number, err)
+			}
+			for _, change := range prChanges {
+				files.Insert(change.Filename)
+			}
+		}
+		changes, changesLoaded = sets.List(files), true
+		return changes, nil
+	}
+
+	var result []config.Presubmit
+	for _, ps := range presubmits {
+		if !ps.ContextRequired() {
+			continue
+		}
+		shouldRun, err := ps.ShouldRun(base, changedFiles, ps.RunBeforeMerge, false)
+		if err != nil {
+			return nil, err
+		}
+		if shouldRun {
+			result = append(result, ps)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func formatPulls(numbers []int) string {
+	var pulls []string
+	for _, number := range numbers {
+		pulls = append(pulls, fmt.Sprintf("#%d", number))
+	}
+	return strings.Join(pulls, ", ")
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+func TestTestBatchPulls(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected []int
+	}{
+		{
+			name:     "numbers with and without hash",
+			body:     "/test-batch #3 2",
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "duplicates and the current PR are collapsed",
+			body:     "/test-batch 1 2 #2",
+			expected: []int{1, 2},
+		},
+		{
+			name:     "not a command",
+			body:     "please /test-batch 2",
+			expected: []int{1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, testBatchPulls(tc.body, 1)); diff != "" {
+				t.Errorf("pulls differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleTestBatch(t *testing.T) {
+	pr := func(number int, base, state string) *github.PullRequest {
+		return &github.PullRequest{
+			Number: number,
+			State:  state,
+			User:   github.User{Login: "author"},
+			Head:   github.PullRequestBranch{SHA: "head"},
+			Base: github.PullRequestBranch{
+				Ref:  base,
+				Repo: github.Repo{Owner: github.User{Login: "org"}, Name: "repo"},
+			},
+		}
+	}
+	testCases := []struct {
+		name            string
+		author          string
+		body            string
+		pulls           map[int]*github.PullRequest
+		expectedJobs    []string
+		expectedPulls   []int
+		expectedComment string
+	}{
+		{
+			name:   "batch of required jobs is created",
+			author: "trusted-member",
+			body:   "/test-batch #2",
+			pulls: map[int]*github.PullRequest{
+				1: pr(1, "master", "open"),
+				2: pr(2, "master", "open"),
+			},
+			expectedJobs:    []string{"required"},
+			expectedPulls:   []int{1, 2},
+			expectedComment: "Started required for the batch of #1, #2",
+		},
+		{
+			name:   "untrusted user",
+			author: "someone",
+			body:   "/test-batch #2",
+			pulls: map[int]*github.PullRequest{
+				1: pr(1, "master", "open"),
+				2: pr(2, "master", "open"),
+			},
+			expectedComment: "Only trusted users can start batch tests.",
+		},
+		{
+			name:   "PRs target different branches",
+			author: "trusted-member",
+			body:   "/test-batch #2",
+			pulls: map[int]*github.PullRequest{
+				1: pr(1, "master", "open"),
+				2: pr(2, "release", "open"),
+			},
+			expectedComment: "Cannot test these PRs together: #2 does not target master.",
+		},
+		{
+			name:   "closed PR",
+			author: "trusted-member",
+			body:   "/test-batch #2",
+			pulls: map[int]*github.PullRequest{
+				1: pr(1, "master", "open"),
+				2: pr(2, "master", "closed"),
+			},
+			expectedComment: "Cannot test these PRs together: #2 is not open.",
+		},
+		{
+			name:   "only the current PR",
+			author: "trusted-member",
+			body:   "/test-batch #1",
+			pulls: map[int]*github.PullRequest{
+				1: pr(1, "master", "open"),
+			},
+			expectedComment: "`/test-batch` needs at least one other PR",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := fakegithub.NewFakeClient()
+			g.OrgMembers = map[string][]string{"org": {"trusted-member"}}
+			g.PullRequests = tc.pulls
+			fakeConfig := &config.Config{ProwConfig: config.ProwConfig{ProwJobNamespace: "prowjobs"}}
+			if err := fakeConfig.SetPresubmits(map[string][]config.Presubmit{
+				"org/repo": {
+					{
+						JobBase:   config.JobBase{Name: "required"},
+						AlwaysRun: true,
+						Reporter:  config.Reporter{Context: "required"},
+					},
+					{
+						JobBase:   config.JobBase{Name: "optional"},
+						AlwaysRun: true,
+						Optional:  true,
+						Reporter:  config.Reporter{Context: "optional"},
+					},
+				},
+			}); err != nil {
+				t.Fatalf("failed to set presubmits: %v", err)
+			}
+			fakeProwJobClient := fake.NewSimpleClientset()
+			c := Client{
+				GitHubClient:  g,
+				ProwJobClient: fakeProwJobClient.ProwV1().ProwJobs(fakeConfig.ProwJobNamespace),
+				Config:        fakeConfig,
+				Logger:        logrus.WithField("plugin", PluginName),
+			}
+			event := github.GenericCommentEvent{
+				Action:     github.GenericCommentActionCreated,
+				Repo:       github.Repo{Owner: github.User{Login: "org"}, Name: "repo", FullName: "org/repo"},
+				Number:     1,
+				Body:       tc.body,
+				User:       github.User{Login: tc.author},
+				IssueState: "open",
+				IsPR:       true,
+				GUID:       "guid",
+			}
+
+			if err := handleGenericComment(c, plugins.Trigger{}, event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pjs, err := fakeProwJobClient.ProwV1().ProwJobs("prowjobs").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("failed to list prowjobs: %v", err)
+			}
+			var jobs []string
+			for _, pj := range pjs.Items {
+				jobs = append(jobs, pj.Spec.Job)
+				if pj.Spec.Type != prowapi.BatchJob || pj.Labels[kube.OnDemandBatchLabel] != "true" || !pj.Spec.Report {
+					t.Errorf("expected a reported on-demand batch job, got type %s, labels %v and report %t", pj.Spec.Type, pj.Labels, pj.Spec.Report)
+				}
+				var pulls []int
+				for _, pull := range pj.Spec.Refs.Pulls {
+					pulls = append(pulls, pull.Number)
+				}
+				if diff := cmp.Diff(tc.expectedPulls, pulls); diff != "" {
+					t.Errorf("batch pulls differ (-want +got):\n%s", diff)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedJobs, jobs); diff != "" {
+				t.Errorf("created jobs differ (-want +got):\n%s", diff)
+			}
+			if len(g.IssueCommentsAdded) == 0 || !strings.Contains(g.IssueCommentsAdded[0], tc.expectedComment) {
+				t.Errorf("expected a comment containing %q, got %v", tc.expectedComment, g.IssueCommentsAdded)
+			}
+			if tc.expectedJobs != nil && len(g.IssueCommentsAdded) != len(tc.expectedPulls) {
+				t.Errorf("expected a comment on each of %v, got %v", tc.expectedPulls, g.IssueCommentsAdded)
+			}
+		})
+	}
+}
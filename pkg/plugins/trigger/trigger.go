@@ -19,6 +19,7 @@ package trigger
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -34,6 +35,7 @@ import (
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/pluginhelp"
 	"sigs.k8s.io/prow/pkg/plugins"
@@ -52,6 +54,7 @@ const (
 	notMember untrustedReason = 1 << iota
 	notCollaborator
 	notSecondaryMember
+	notTrustedByMembershipService
 )
 
 // String constructs a string explaining the reason for a user's denial of trust
@@ -67,6 +70,9 @@ func (u untrustedReason) String() string {
 	if u&notSecondaryMember != 0 {
 		response += "User is not a member of the trusted secondary org. "
 	}
+	if u&notTrustedByMembershipService != 0 {
+		response += "User is not trusted by the membership service. "
+	}
 	response += "Satisfy at least one of these conditions to make the user trusted."
 	return response
 }
@@ -85,7 +91,17 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 		if trigger.TrustedOrg != "" {
 			org = trigger.TrustedOrg
 		}
-		configInfo[repo.String()] = fmt.Sprintf("The trusted GitHub organization for this repository is %q.", org)
+		info := fmt.Sprintf("The trusted GitHub organization for this repository is %q.", org)
+		if len(trigger.TrustedApps) > 0 {
+			info += fmt.Sprintf(" PRs of the GitHub Apps %s are trusted.", strings.Join(trigger.TrustedApps, ", "))
+		}
+		if len(trigger.TrustedBots) > 0 {
+			info += fmt.Sprintf(" PRs of the bot accounts %s are trusted.", strings.Join(trigger.TrustedBots, ", "))
+		}
+		if trigger.MembershipService != nil {
+			info += fmt.Sprintf(" Users trusted by the membership service at %s are trusted.", trigger.MembershipService.URL)
+		}
+		configInfo[repo.String()] = info
 	}
 	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
 		Triggers: []plugins.Trigger{
@@ -97,6 +113,8 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 				JoinOrgURL:     "https://github.com/kubernetes/community/blob/master/community-membership.md",
 				OnlyOrgMembers: true,
 				IgnoreOkToTest: true,
+				TrustedApps:    []string{"dependabot"},
+				TrustedBots:    []string{"k8s-infra-ci-robot"},
 			},
 		},
 	})
@@ -107,9 +125,12 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 		Description: `The trigger plugin starts jobs in reaction to various events.
 <br>Presubmit jobs are run automatically on pull requests that are trusted and not in a draft state with file changes matching the file filters and targeting a branch matching the branch filters.
 <br>A pull request is considered trusted if the author is a member of the 'trusted organization' for the repository or if such a member has left an '/ok-to-test' command on the PR.
+<br>PRs of GitHub Apps listed in 'trusted_apps' and of bot accounts listed in 'trusted_bots' are trusted as well. Trust granted this way is logged.
+<br>If a 'membership_service' is configured, users that are not trusted because of their GitHub membership are looked up in that service, so that trust can be defined in an internal directory.
+<br>If 'expire_ok_to_test_on_push' is enabled, the 'ok-to-test' label is removed when an untrusted author pushes new commits, and the new revision must be approved again.
 <br>Trigger will not automatically start jobs for a PR in draft state, and if a PR is changed to draft it cancels pending jobs.
 <br>If jobs are not run automatically for a PR because it is not trusted or is in draft state, a trusted user can still start jobs manually via the '/test' command.
-<br>The '/retest' command can be used to rerun jobs that have reported failure.
+<br>The '/retest' command can be used to rerun jobs that have reported failure. Retests can be limited per PR and per repository, with an exponential backoff between retests of the same PR, by configuring a 'retest_budget'.
 <br>Trigger starts postsubmit jobs when commits are pushed if the filters on the job match files and branches affected by that push.`,
 		Config:  configInfo,
 		Snippet: yamlSnippet,
@@ -135,13 +156,34 @@ func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo)
 		WhoCanUse:   "Anyone can trigger this command on a trusted PR.",
 		Examples:    []string{"/retest"},
 	})
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/test re:<pattern>",
+		Description: "Manually starts all test jobs whose name matches the regular expression. Commands matching more jobs than the configured limit are rejected.",
+		Featured:    false,
+		WhoCanUse:   "Anyone can trigger this command on a trusted PR.",
+		Examples:    []string{"/test re:^pull-.*-unit$"},
+	})
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/retest re:<pattern>",
+		Description: "Rerun failed test jobs whose name matches the regular expression.",
+		Featured:    false,
+		WhoCanUse:   "Anyone can trigger this command on a trusted PR.",
+		Examples:    []string{"/retest re:e2e"},
+	})
 	pluginHelp.AddCommand(pluginhelp.Command{
 		Usage:       "/test ?",
-		Description: "List available test job(s) for a trusted PR.",
+		Description: "List available test job(s) for a trusted PR, including the file filters of conditionally triggered jobs and whether they are currently skipped.",
 		Featured:    true,
 		WhoCanUse:   "Anyone can trigger this command on a trusted PR.",
 		Examples:    []string{"/test ?"},
 	})
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/test-batch <PR number>...",
+		Description: "Runs the presubmits that tide requires for a batch of this PR together with the listed PRs of the same branch. The results are reported on every PR of the batch.",
+		Featured:    false,
+		WhoCanUse:   "Members of the trusted organization for the repo.",
+		Examples:    []string{"/test-batch #123", "/test-batch 123 456"},
+	})
 	return pluginHelp, nil
 }
 
@@ -173,7 +215,7 @@ type trustedPullRequestClient interface {
 type prowJobClient interface {
 	Create(context.Context, *prowapi.ProwJob, metav1.CreateOptions) (*prowapi.ProwJob, error)
 	List(ctx context.Context, opts metav1.ListOptions) (*prowapi.ProwJobList, error)
-	Update(context.Context, *prowapi.ProwJob, metav1.UpdateOptions) (*prowapi.ProwJob, error)
+	UpdateStatus(context.Context, *prowapi.ProwJob, metav1.UpdateOptions) (*prowapi.ProwJob, error)
 }
 
 // Client holds the necessary structures to work with prow via logging, github, kubernetes and its configuration.
@@ -185,6 +227,8 @@ type Client struct {
 	Config        *config.Config
 	Logger        *logrus.Entry
 	GitClient     git.ClientFactory
+	// InRepoConfigGetter may be nil, see plugins.GetPresubmits.
+	InRepoConfigGetter config.InRepoConfigGetter
 }
 
 // trustedUserClient is used to check is user member and repo collaborator
@@ -196,11 +240,12 @@ type trustedUserClient interface {
 
 func getClient(pc plugins.Agent) Client {
 	return Client{
-		GitHubClient:  pc.GitHubClient,
-		Config:        pc.Config,
-		ProwJobClient: pc.ProwJobClient,
-		Logger:        pc.Logger,
-		GitClient:     pc.GitClient,
+		GitHubClient:       pc.GitHubClient,
+		Config:             pc.Config,
+		ProwJobClient:      pc.ProwJobClient,
+		Logger:             pc.Logger,
+		GitClient:          pc.GitClient,
+		InRepoConfigGetter: pc.InRepoConfigGetter,
 	}
 }
 
@@ -223,11 +268,34 @@ type TrustedUserResponse struct {
 	IsTrusted bool
 	// Reason contains the reason that a user is not trusted if IsTrusted is false
 	Reason string
+	// TrustedVia is set if the user is trusted only because of the trusted
+	// apps or bots configuration or the membership service, so that callers
+	// can audit these decisions.
+	TrustedVia string
+}
+
+const (
+	// TrustedViaApp means the user is a GitHub App listed in trusted_apps.
+	TrustedViaApp = "trusted_apps"
+	// TrustedViaBot means the user is a bot account listed in trusted_bots.
+	TrustedViaBot = "trusted_bots"
+)
+
+// auditTrustedVia logs when a user is trusted only because of the trusted
+// apps or bots configuration or the membership service, so that these
+// decisions can be reviewed.
+func auditTrustedVia(log *logrus.Entry, user string, resp TrustedUserResponse) {
+	if resp.TrustedVia == "" {
+		return
+	}
+	log.WithFields(logrus.Fields{"user": user, "trusted-via": resp.TrustedVia}).Infof("Trusting %q because of the %s configuration.", user, resp.TrustedVia)
 }
 
 // TrustedUser returns true if user is trusted in repo.
-// Trusted users are either repo collaborators, org members or trusted org members.
-func TrustedUser(ghc trustedUserClient, onlyOrgMembers bool, trustedApps []string, trustedOrg, user, org, repo string) (TrustedUserResponse, error) {
+// Trusted users are either repo collaborators, org members, trusted org members,
+// trusted GitHub Apps, trusted bot accounts or users trusted by the external
+// membership service.
+func TrustedUser(ghc trustedUserClient, onlyOrgMembers bool, trustedApps, trustedBots []string, membershipService *plugins.MembershipService, trustedOrg, user, org, repo string) (TrustedUserResponse, error) {
 	errorResponse := TrustedUserResponse{IsTrusted: false}
 	okResponse := TrustedUserResponse{IsTrusted: true}
 
@@ -261,35 +329,52 @@ func TrustedUser(ghc trustedUserClient, onlyOrgMembers bool, trustedApps []strin
 
 	// Determine if user is on trusted_apps list.
 	// This allows automatic tests execution for GitHub automations that cannot be added as collaborators.
-	for _, trustedApp := range trustedApps {
-		if tUser := strings.TrimSuffix(user, "[bot]"); tUser == trustedApp {
-			return okResponse, nil
+	// Only the app itself is trusted, not a user account that happens to have the name of the app.
+	if appName, isApp := strings.CutSuffix(user, "[bot]"); isApp {
+		for _, trustedApp := range trustedApps {
+			if appName == trustedApp {
+				return TrustedUserResponse{IsTrusted: true, TrustedVia: TrustedViaApp}, nil
+			}
 		}
 	}
 
+	// Determine if user is on trusted_bots list. These are regular accounts used by automation.
+	for _, trustedBot := range trustedBots {
+		if user == trustedBot {
+			return TrustedUserResponse{IsTrusted: true, TrustedVia: TrustedViaBot}, nil
+		}
+	}
+
+	// the reason is only to improve error messaging
+	reason := notMember
+	if !onlyOrgMembers {
+		reason |= notCollaborator
+	}
+
 	// Determine if there is a second org to check. If there is no secondary org or they are the same, the result
 	// is the same because the user already failed the check for the primary org.
-	if trustedOrg == "" || trustedOrg == org {
-		// the if/else is only to improve error messaging
-		if onlyOrgMembers {
-			return TrustedUserResponse{IsTrusted: false, Reason: notMember.String()}, nil // No trusted org and/or it is the same
+	if trustedOrg != "" && trustedOrg != org {
+		member, err := ghc.IsMember(trustedOrg, user)
+		if err != nil {
+			return errorResponse, fmt.Errorf("error in IsMember(%s): %w", trustedOrg, err)
+		} else if member {
+			return okResponse, nil
 		}
-		return TrustedUserResponse{IsTrusted: false, Reason: (notMember | notCollaborator).String()}, nil // No trusted org and/or it is the same
+		reason |= notSecondaryMember
 	}
 
-	// Check the second trusted org.
-	member, err := ghc.IsMember(trustedOrg, user)
-	if err != nil {
-		return errorResponse, fmt.Errorf("error in IsMember(%s): %w", trustedOrg, err)
-	} else if member {
-		return okResponse, nil
+	// Finally ask the external membership service, which is the most expensive check.
+	if membershipService != nil {
+		trusted, err := trustedByMembershipService(membershipService, user, org, repo)
+		if err != nil {
+			return errorResponse, err
+		} else if trusted {
+			return TrustedUserResponse{IsTrusted: true, TrustedVia: TrustedViaMembershipService}, nil
+		}
+		reason |= notTrustedByMembershipService
 	}
 
-	// the if/else is only to improve error messaging
-	if onlyOrgMembers {
-		return TrustedUserResponse{IsTrusted: false, Reason: (notMember | notSecondaryMember).String()}, nil
-	}
-	return TrustedUserResponse{IsTrusted: false, Reason: (notMember | notSecondaryMember | notCollaborator).String()}, nil
+	return TrustedUserResponse{IsTrusted: false, Reason: reason.String()}, nil
 }
 
 // validateContextOverlap ensures that there will be no overlap in contexts between a set of jobs running and a set to skip
@@ -340,8 +425,42 @@ func runRequested(c Client, pr *github.PullRequest, baseSHA string, requestedJob
 	return utilerrors.NewAggregate(errors)
 }
 
-func getPresubmits(log *logrus.Entry, gc git.ClientFactory, cfg *config.Config, orgRepo string, baseSHAGetter, headSHAGetter config.RefGetter) []config.Presubmit {
-	presubmits, err := cfg.GetPresubmits(gc, orgRepo, "", baseSHAGetter, headSHAGetter)
+// runRequestedForRefs creates presubmits for refs that don't come from a GitHub
+// pull request. The given labels and annotations are added to the ones of the
+// jobs so that the matching reporter picks them up.
+func runRequestedForRefs(log *logrus.Entry, pjc prowJobClient, cfg *config.Config, refs prowapi.Refs, requestedJobs []config.Presubmit, eventGUID string, extraLabels, extraAnnotations map[string]string) error {
+	var errs []error
+	for _, job := range requestedJobs {
+		log.Infof("Starting %s build.", job.Name)
+		labels := map[string]string{
+			github.EventGUID:     eventGUID,
+			kube.IsOptionalLabel: strconv.FormatBool(job.Optional),
+		}
+		for k, v := range extraLabels {
+			labels[k] = v
+		}
+		for k, v := range job.Labels {
+			labels[k] = v
+		}
+		annotations := map[string]string{}
+		for k, v := range extraAnnotations {
+			annotations[k] = v
+		}
+		for k, v := range job.Annotations {
+			annotations[k] = v
+		}
+		pj := pjutil.NewProwJob(pjutil.PresubmitSpec(job, refs), labels, annotations, pjutil.RequireScheduling(cfg.Scheduler.Enabled))
+		log.WithFields(pjutil.ProwJobFields(&pj)).Info("Creating a new prowjob.")
+		if err := createWithRetry(context.TODO(), pjc, &pj); err != nil {
+			log.WithError(err).Error("Failed to create prowjob.")
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func getPresubmits(log *logrus.Entry, ircg config.InRepoConfigGetter, gc git.ClientFactory, cfg *config.Config, orgRepo string, baseSHAGetter, headSHAGetter config.RefGetter) []config.Presubmit {
+	presubmits, err := plugins.GetPresubmits(ircg, cfg, gc, orgRepo, "", baseSHAGetter, headSHAGetter)
 	if err != nil {
 		// Fall back to static presubmits to avoid deadlocking when a presubmit is used to verify
 		// inrepoconfig. Tide will still respect errors here and not merge.
@@ -351,8 +470,8 @@ func getPresubmits(log *logrus.Entry, gc git.ClientFactory, cfg *config.Config,
 	return presubmits
 }
 
-func getPostsubmits(log *logrus.Entry, gc git.ClientFactory, cfg *config.Config, orgRepo string, baseSHAGetter config.RefGetter) []config.Postsubmit {
-	postsubmits, err := cfg.GetPostsubmits(gc, orgRepo, "", baseSHAGetter)
+func getPostsubmits(log *logrus.Entry, ircg config.InRepoConfigGetter, gc git.ClientFactory, cfg *config.Config, orgRepo string, baseSHAGetter config.RefGetter) []config.Postsubmit {
+	postsubmits, err := plugins.GetPostsubmits(ircg, cfg, gc, orgRepo, "", baseSHAGetter)
 	if err != nil {
 		// Fall back to static postsubmits, loading inrepoconfig returned an error.
 		log.WithError(err).Error("Failed to get postsubmits")
@@ -371,7 +490,7 @@ func createWithRetry(ctx context.Context, client prowJobClient, pj *prowapi.Prow
 
 	var errs []error
 	if err := wait.ExponentialBackoff(wait.Backoff{Duration: 250 * millisecond, Factor: 2.0, Jitter: 0.1, Steps: 8}, func() (bool, error) {
-		if _, err := client.Create(ctx, pj, metav1.CreateOptions{}); err != nil {
+		if _, err := pjutil.CreateProwJob(ctx, client, pj); err != nil {
 			// Can happen if a previous request was successful but returned an error
 			if apierrors.IsAlreadyExists(err) {
 				return true, nil
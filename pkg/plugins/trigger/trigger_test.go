@@ -328,14 +328,16 @@ func TestTrustedUser(t *testing.T) {
 
 		onlyOrgMembers bool
 		trustedApps    []string
+		trustedBots    []string
 		trustedOrg     string
 
 		user string
 		org  string
 		repo string
 
-		expectedTrusted bool
-		expectedReason  string
+		expectedTrusted    bool
+		expectedReason     string
+		expectedTrustedVia string
 	}{
 		{
 			name:            "user is member of trusted org",
@@ -419,10 +421,32 @@ func TestTrustedUser(t *testing.T) {
 			expectedTrusted: true,
 		},
 		{
-			name:            "github-app[bot] is in trusted list",
-			user:            "github-app[bot]",
+			name:               "github-app[bot] is in trusted list",
+			user:               "github-app[bot]",
+			trustedApps:        []string{"github-app"},
+			expectedTrusted:    true,
+			expectedTrustedVia: TrustedViaApp,
+		},
+		{
+			name:            "user named like a trusted app is not trusted",
+			user:            "github-app",
 			trustedApps:     []string{"github-app"},
-			expectedTrusted: true,
+			expectedTrusted: false,
+			expectedReason:  (notMember | notCollaborator).String(),
+		},
+		{
+			name:               "bot account is in trusted list",
+			user:               "ci-bot",
+			trustedBots:        []string{"ci-bot"},
+			expectedTrusted:    true,
+			expectedTrustedVia: TrustedViaBot,
+		},
+		{
+			name:            "bot account is not in trusted list",
+			user:            "ci-bot",
+			trustedBots:     []string{"other-bot"},
+			expectedTrusted: false,
+			expectedReason:  (notMember | notCollaborator).String(),
 		},
 		{
 			name:            "github-app[bot] is not in trusted list",
@@ -441,7 +465,7 @@ func TestTrustedUser(t *testing.T) {
 			}
 			fc.Collaborators = []string{"test-collaborator"}
 
-			trustedResponse, err := TrustedUser(fc, tc.onlyOrgMembers, tc.trustedApps, tc.trustedOrg, tc.user, tc.org, tc.repo)
+			trustedResponse, err := TrustedUser(fc, tc.onlyOrgMembers, tc.trustedApps, tc.trustedBots, nil, tc.trustedOrg, tc.user, tc.org, tc.repo)
 			if err != nil {
 				t.Errorf("For case %s, didn't expect error from TrustedUser: %v", tc.name, err)
 			}
@@ -451,6 +475,9 @@ func TestTrustedUser(t *testing.T) {
 			if trustedResponse.Reason != tc.expectedReason {
 				t.Errorf("For case %s, expect trusted reason: %v, but got: %v", tc.name, tc.expectedReason, trustedResponse.Reason)
 			}
+			if trustedResponse.TrustedVia != tc.expectedTrustedVia {
+				t.Errorf("For case %s, expect trusted via: %q, but got: %q", tc.name, tc.expectedTrustedVia, trustedResponse.TrustedVia)
+			}
 		})
 	}
 }
@@ -520,7 +547,7 @@ func TestGetPresubmits(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			presubmits := getPresubmits(logrus.NewEntry(logrus.New()), nil, tc.cfg, orgRepo, shaGetter, shaGetter)
+			presubmits := getPresubmits(logrus.NewEntry(logrus.New()), nil, nil, tc.cfg, orgRepo, shaGetter, shaGetter)
 			actualPresubmits := sets.Set[string]{}
 			for _, presubmit := range presubmits {
 				actualPresubmits.Insert(presubmit.Name)
@@ -598,7 +625,7 @@ func TestGetPostsubmits(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			postsubmits := getPostsubmits(logrus.NewEntry(logrus.New()), nil, tc.cfg, orgRepo, shaGetter)
+			postsubmits := getPostsubmits(logrus.NewEntry(logrus.New()), nil, nil, tc.cfg, orgRepo, shaGetter)
 			actualPostsubmits := sets.Set[string]{}
 			for _, postsubmit := range postsubmits {
 				actualPostsubmits.Insert(postsubmit.Name)
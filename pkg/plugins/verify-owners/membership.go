@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifyowners
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+var identityServiceClient = &http.Client{Timeout: time.Minute}
+
+// membershipRequirements are the requirements users added to OWNERS files
+// must meet in addition to being trusted.
+type membershipRequirements struct {
+	// teams are the slugs of the GitHub teams of the org users must be members of.
+	teams []string
+	// identityService is the endpoint of the identity service users must be known to.
+	identityService string
+}
+
+func membershipRequirementsFor(c *plugins.Configuration, org, repo string) membershipRequirements {
+	return membershipRequirements{
+		teams:           c.OwnersRequiredTeams(org, repo),
+		identityService: c.OwnersIdentityService(org, repo),
+	}
+}
+
+// unmetBy returns a description of the requirements the user does not meet,
+// or an empty string if the user meets all of them.
+func (r membershipRequirements) unmetBy(ghc githubClient, org, user string) (string, error) {
+	var missingTeams []string
+	for _, team := range r.teams {
+		member, err := ghc.TeamBySlugHasMember(org, team, user)
+		if err != nil {
+			return "", fmt.Errorf("failed to check whether %s is a member of team %s/%s: %w", user, org, team, err)
+		}
+		if !member {
+			missingTeams = append(missingTeams, fmt.Sprintf("%s/%s", org, team))
+		}
+	}
+
+	var response string
+	if len(missingTeams) > 0 {
+		response += fmt.Sprintf("User is not a member of the required GitHub team(s) %s. ", strings.Join(missingTeams, ", "))
+	}
+	if r.identityService != "" {
+		known, err := knownToIdentityService(r.identityService, org, user)
+		if err != nil {
+			return "", err
+		}
+		if !known {
+			response += "User is not known to the identity service of the org. "
+		}
+	}
+	if response == "" {
+		return "", nil
+	}
+	return response + "Satisfy all of these conditions to add the user to OWNERS files.", nil
+}
+
+// knownToIdentityService returns whether the identity service knows the user.
+func knownToIdentityService(endpoint, org, user string) (bool, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false, fmt.Errorf("invalid identity service endpoint %q: %w", endpoint, err)
+	}
+	query := u.Query()
+	query.Set("org", org)
+	query.Set("user", user)
+	u.RawQuery = query.Encode()
+
+	resp, err := identityServiceClient.Get(u.String())
+	if err != nil {
+		return false, fmt.Errorf("failed to query identity service for %s: %w", user, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("identity service responded with status %d for %s", resp.StatusCode, user)
+	}
+}
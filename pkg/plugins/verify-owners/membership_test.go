@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifyowners
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/github/fakegithub"
+)
+
+func TestUnmetBy(t *testing.T) {
+	identityService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("org") != "org" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("user") {
+		case "alice":
+			w.WriteHeader(http.StatusOK)
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer identityService.Close()
+
+	testCases := []struct {
+		name         string
+		requirements membershipRequirements
+		user         string
+		expected     string
+		expectedErr  bool
+	}{
+		{
+			name: "no requirements",
+			user: "bob",
+		},
+		{
+			name:         "member of all teams and known to the identity service",
+			requirements: membershipRequirements{teams: []string{"a", "b"}, identityService: identityService.URL},
+			user:         "alice",
+		},
+		{
+			name:         "not a member of a team and unknown to the identity service",
+			requirements: membershipRequirements{teams: []string{"a", "b"}, identityService: identityService.URL},
+			user:         "bob",
+			expected:     "User is not a member of the required GitHub team(s) org/b. User is not known to the identity service of the org. Satisfy all of these conditions to add the user to OWNERS files.",
+		},
+		{
+			name:         "identity service failure",
+			requirements: membershipRequirements{identityService: identityService.URL},
+			user:         "broken",
+			expectedErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fghc := fakegithub.NewFakeClient()
+			fghc.Teams = map[string]map[string]fakegithub.TeamWithMembers{
+				"org": {
+					"a": {Members: sets.New[string]("alice", "bob")},
+					"b": {Members: sets.New[string]("alice")},
+				},
+			}
+			unmet, err := tc.requirements.unmetBy(fghc, "org", tc.user)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if unmet != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, unmet)
+			}
+		})
+	}
+}
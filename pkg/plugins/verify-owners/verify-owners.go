@@ -83,9 +83,19 @@ func helpProvider(c *plugins.Configuration, orgRepo []config.OrgRepo) (*pluginhe
 	pluginHelp.Config["default"] = descriptionFor(defaultFilenames)
 	for _, item := range orgRepo {
 		filenames := c.OwnersFilenames(item.Org, item.Repo)
-		if !reflect.DeepEqual(filenames, defaultFilenames) {
-			pluginHelp.Config[item.String()] = descriptionFor(filenames)
+		teams := c.OwnersRequiredTeams(item.Org, item.Repo)
+		identityService := c.OwnersIdentityService(item.Org, item.Repo)
+		if reflect.DeepEqual(filenames, defaultFilenames) && len(teams) == 0 && identityService == "" {
+			continue
+		}
+		description := descriptionFor(filenames)
+		if len(teams) > 0 {
+			description = fmt.Sprintf("%s Users added to them must be members of the GitHub teams %s.", description, strings.Join(teams, ", "))
 		}
+		if identityService != "" {
+			description = fmt.Sprintf("%s Users added to them must be known to the identity service of the org.", description)
+		}
+		pluginHelp.Config[item.String()] = description
 	}
 	pluginHelp.AddCommand(pluginhelp.Command{
 		Usage:       "/verify-owners",
@@ -117,6 +127,7 @@ type githubClient interface {
 	RemoveLabel(owner, repo string, number int, label string) error
 	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
 	BotUserChecker() (func(candidate string) bool, error)
+	TeamBySlugHasMember(org string, teamSlug string, memberLogin string) (bool, error)
 }
 
 type commentPruner interface {
@@ -155,7 +166,8 @@ func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
 		number:       pre.Number,
 	}
 
-	return handle(pc.GitHubClient, pc.GitClient, pc.OwnersClient, pc.Logger, &pre.PullRequest, prInfo, pc.PluginConfig.Owners.LabelsDenyList, pc.PluginConfig.TriggerFor(pre.Repo.Owner.Login, pre.Repo.Name), skipTrustedUserCheck, cp, pc.PluginConfig.OwnersFilenames)
+	requirements := membershipRequirementsFor(pc.PluginConfig, pre.Repo.Owner.Login, pre.Repo.Name)
+	return handle(pc.GitHubClient, pc.GitClient, pc.OwnersClient, pc.Logger, &pre.PullRequest, prInfo, pc.PluginConfig.Owners.LabelsDenyList, pc.PluginConfig.TriggerFor(pre.Repo.Owner.Login, pre.Repo.Name), skipTrustedUserCheck, requirements, cp, pc.PluginConfig.OwnersFilenames)
 }
 
 func handleGenericCommentEvent(pc plugins.Agent, e github.GenericCommentEvent) error {
@@ -172,10 +184,11 @@ func handleGenericCommentEvent(pc plugins.Agent, e github.GenericCommentEvent) e
 		}
 	}
 
-	return handleGenericComment(pc.GitHubClient, pc.GitClient, pc.OwnersClient, pc.Logger, &e, pc.PluginConfig.Owners.LabelsDenyList, pc.PluginConfig.TriggerFor(e.Repo.Owner.Login, e.Repo.Name), skipTrustedUserCheck, cp, pc.PluginConfig.OwnersFilenames)
+	requirements := membershipRequirementsFor(pc.PluginConfig, e.Repo.Owner.Login, e.Repo.Name)
+	return handleGenericComment(pc.GitHubClient, pc.GitClient, pc.OwnersClient, pc.Logger, &e, pc.PluginConfig.Owners.LabelsDenyList, pc.PluginConfig.TriggerFor(e.Repo.Owner.Login, e.Repo.Name), skipTrustedUserCheck, requirements, cp, pc.PluginConfig.OwnersFilenames)
 }
 
-func handleGenericComment(ghc githubClient, gc git.ClientFactory, roc repoownersClient, log *logrus.Entry, ce *github.GenericCommentEvent, bannedLabels []string, triggerConfig plugins.Trigger, skipTrustedUserCheck bool, cp commentPruner, resolver ownersconfig.Resolver) error {
+func handleGenericComment(ghc githubClient, gc git.ClientFactory, roc repoownersClient, log *logrus.Entry, ce *github.GenericCommentEvent, bannedLabels []string, triggerConfig plugins.Trigger, skipTrustedUserCheck bool, requirements membershipRequirements, cp commentPruner, resolver ownersconfig.Resolver) error {
 	// Only consider open PRs and new comments.
 	if ce.IssueState != "open" || !ce.IsPR || ce.Action != github.GenericCommentActionCreated {
 		return nil
@@ -197,7 +210,7 @@ func handleGenericComment(ghc githubClient, gc git.ClientFactory, roc repoowners
 		return err
 	}
 
-	return handle(ghc, gc, roc, log, pr, prInfo, bannedLabels, triggerConfig, skipTrustedUserCheck, cp, resolver)
+	return handle(ghc, gc, roc, log, pr, prInfo, bannedLabels, triggerConfig, skipTrustedUserCheck, requirements, cp, resolver)
 }
 
 type messageWithLine struct {
@@ -205,7 +218,7 @@ type messageWithLine struct {
 	message string
 }
 
-func handle(ghc githubClient, gc git.ClientFactory, roc repoownersClient, log *logrus.Entry, pr *github.PullRequest, info info, bannedLabels []string, triggerConfig plugins.Trigger, skipTrustedUserCheck bool, cp commentPruner, resolver ownersconfig.Resolver) error {
+func handle(ghc githubClient, gc git.ClientFactory, roc repoownersClient, log *logrus.Entry, pr *github.PullRequest, info info, bannedLabels []string, triggerConfig plugins.Trigger, skipTrustedUserCheck bool, requirements membershipRequirements, cp commentPruner, resolver ownersconfig.Resolver) error {
 	org := info.org
 	repo := info.repo
 	number := info.number
@@ -271,7 +284,7 @@ func handle(ghc githubClient, gc git.ClientFactory, roc repoownersClient, log *l
 	}
 	// If OWNERS_ALIASES file exists, get all aliases.
 	// If the file was modified, check for non trusted users in the newly added owners.
-	nonTrustedUsers, trustedUsers, repoAliases, err := nonTrustedUsersInOwnersAliases(ghc, log, triggerConfig, org, repo, r.Directory(), modifiedOwnerAliasesFile.Patch, ownerAliasesModified, skipTrustedUserCheck, filenames)
+	nonTrustedUsers, trustedUsers, repoAliases, err := nonTrustedUsersInOwnersAliases(ghc, log, triggerConfig, requirements, org, repo, r.Directory(), modifiedOwnerAliasesFile.Patch, ownerAliasesModified, skipTrustedUserCheck, filenames)
 	if err != nil {
 		return err
 	}
@@ -292,7 +305,7 @@ func handle(ghc githubClient, gc git.ClientFactory, roc repoownersClient, log *l
 		}
 
 		if !skipTrustedUserCheck {
-			nonTrustedUsers, err = nonTrustedUsersInOwners(ghc, log, triggerConfig, org, repo, c.Patch, c.Filename, owners, nonTrustedUsers, trustedUsers, repoAliases)
+			nonTrustedUsers, err = nonTrustedUsersInOwners(ghc, log, triggerConfig, requirements, org, repo, c.Patch, c.Filename, owners, nonTrustedUsers, trustedUsers, repoAliases)
 			if err != nil {
 				return err
 			}
@@ -451,7 +464,7 @@ func markdownFriendlyComment(org, joinOrgURL string, nonTrustedUsers map[string]
 	return strings.Join(commentLines, "\n")
 }
 
-func nonTrustedUsersInOwnersAliases(ghc githubClient, log *logrus.Entry, triggerConfig plugins.Trigger, org, repo, dir, patch string, ownerAliasesModified, skipTrustedUserCheck bool, filenames ownersconfig.Filenames) (map[string]nonTrustedReasons, sets.Set[string], repoowners.RepoAliases, error) {
+func nonTrustedUsersInOwnersAliases(ghc githubClient, log *logrus.Entry, triggerConfig plugins.Trigger, requirements membershipRequirements, org, repo, dir, patch string, ownerAliasesModified, skipTrustedUserCheck bool, filenames ownersconfig.Filenames) (map[string]nonTrustedReasons, sets.Set[string], repoowners.RepoAliases, error) {
 	repoAliases := make(repoowners.RepoAliases)
 	// nonTrustedUsers is a map of non-trusted users to the reasons they were not trusted
 	nonTrustedUsers := map[string]nonTrustedReasons{}
@@ -475,7 +488,7 @@ func nonTrustedUsersInOwnersAliases(ghc githubClient, log *logrus.Entry, trigger
 	if ownerAliasesModified && !skipTrustedUserCheck {
 		allOwners := sets.List(repoAliases.ExpandAllAliases())
 		for _, owner := range allOwners {
-			nonTrustedUsers, err = checkIfTrustedUser(ghc, log, triggerConfig, owner, patch, filenames.OwnersAliases, org, repo, nonTrustedUsers, trustedUsers, repoAliases)
+			nonTrustedUsers, err = checkIfTrustedUser(ghc, log, triggerConfig, requirements, owner, patch, filenames.OwnersAliases, org, repo, nonTrustedUsers, trustedUsers, repoAliases)
 			if err != nil {
 				return nonTrustedUsers, trustedUsers, repoAliases, err
 			}
@@ -485,7 +498,7 @@ func nonTrustedUsersInOwnersAliases(ghc githubClient, log *logrus.Entry, trigger
 	return nonTrustedUsers, trustedUsers, repoAliases, nil
 }
 
-func nonTrustedUsersInOwners(ghc githubClient, log *logrus.Entry, triggerConfig plugins.Trigger, org, repo, patch, fileName string, owners []string, nonTrustedUsers map[string]nonTrustedReasons, trustedUsers sets.Set[string], repoAliases repoowners.RepoAliases) (map[string]nonTrustedReasons, error) {
+func nonTrustedUsersInOwners(ghc githubClient, log *logrus.Entry, triggerConfig plugins.Trigger, requirements membershipRequirements, org, repo, patch, fileName string, owners []string, nonTrustedUsers map[string]nonTrustedReasons, trustedUsers sets.Set[string], repoAliases repoowners.RepoAliases) (map[string]nonTrustedReasons, error) {
 	var err error
 	for _, owner := range owners {
 		// ignore if owner is an alias
@@ -493,7 +506,7 @@ func nonTrustedUsersInOwners(ghc githubClient, log *logrus.Entry, triggerConfig
 			continue
 		}
 
-		nonTrustedUsers, err = checkIfTrustedUser(ghc, log, triggerConfig, owner, patch, fileName, org, repo, nonTrustedUsers, trustedUsers, repoAliases)
+		nonTrustedUsers, err = checkIfTrustedUser(ghc, log, triggerConfig, requirements, owner, patch, fileName, org, repo, nonTrustedUsers, trustedUsers, repoAliases)
 		if err != nil {
 			return nonTrustedUsers, err
 		}
@@ -504,7 +517,7 @@ func nonTrustedUsersInOwners(ghc githubClient, log *logrus.Entry, triggerConfig
 // checkIfTrustedUser looks for newly addded owners by checking if they are in the patch
 // and then checks if the owner is a trusted user.
 // returns a map from user to reasons for not being trusted
-func checkIfTrustedUser(ghc githubClient, log *logrus.Entry, triggerConfig plugins.Trigger, owner, patch, fileName, org, repo string, nonTrustedUsers map[string]nonTrustedReasons, trustedUsers sets.Set[string], repoAliases repoowners.RepoAliases) (map[string]nonTrustedReasons, error) {
+func checkIfTrustedUser(ghc githubClient, log *logrus.Entry, triggerConfig plugins.Trigger, requirements membershipRequirements, owner, patch, fileName, org, repo string, nonTrustedUsers map[string]nonTrustedReasons, trustedUsers sets.Set[string], repoAliases repoowners.RepoAliases) (map[string]nonTrustedReasons, error) {
 	// cap the number of checks to avoid exhausting tokens in case of large OWNERS refactors.
 	if len(nonTrustedUsers)+trustedUsers.Len() > 50 {
 		return nonTrustedUsers, nil
@@ -532,10 +545,20 @@ func checkIfTrustedUser(ghc githubClient, log *logrus.Entry, triggerConfig plugi
 	var err error
 	var triggerTrustedResponse trigger.TrustedUserResponse
 	if !isAlreadyTrusted {
-		triggerTrustedResponse, err = trigger.TrustedUser(ghc, triggerConfig.OnlyOrgMembers, triggerConfig.TrustedApps, triggerConfig.TrustedOrg, owner, org, repo)
+		triggerTrustedResponse, err = trigger.TrustedUser(ghc, triggerConfig.OnlyOrgMembers, triggerConfig.TrustedApps, triggerConfig.TrustedBots, triggerConfig.MembershipService, triggerConfig.TrustedOrg, owner, org, repo)
 		if err != nil {
 			return nonTrustedUsers, err
 		}
+		// Trusted users must meet the membership requirements of the repo as well.
+		if triggerTrustedResponse.IsTrusted {
+			unmet, err := requirements.unmetBy(ghc, org, owner)
+			if err != nil {
+				return nonTrustedUsers, err
+			}
+			if unmet != "" {
+				triggerTrustedResponse = trigger.TrustedUserResponse{IsTrusted: false, Reason: unmet}
+			}
+		}
 	}
 
 	if !isAlreadyTrusted && triggerTrustedResponse.IsTrusted {
@@ -534,7 +534,7 @@ func testHandle(clients localgit.Clients, t *testing.T) {
 				number:       pr,
 			}
 
-			if err := handle(fghc, c, makeFakeRepoOwnersClient(), logrus.WithField("plugin", PluginName), &pre.PullRequest, prInfo, []string{labels.Approved, labels.LGTM}, plugins.Trigger{}, false, &fakePruner{}, ownersconfig.FakeResolver); err != nil {
+			if err := handle(fghc, c, makeFakeRepoOwnersClient(), logrus.WithField("plugin", PluginName), &pre.PullRequest, prInfo, []string{labels.Approved, labels.LGTM}, plugins.Trigger{}, false, membershipRequirements{}, &fakePruner{}, ownersconfig.FakeResolver); err != nil {
 				t.Fatalf("Handle PR: %v", err)
 			}
 			if !test.shouldLabel && IssueLabelsContain(fghc.IssueLabelsAdded, labels.InvalidOwners) {
@@ -883,6 +883,7 @@ func testNonCollaborators(clients localgit.Clients, t *testing.T) {
 		ownersAliasesPatch   string
 		includeVendorOwners  bool
 		skipTrustedUserCheck bool
+		requirements         membershipRequirements
 		shouldLabel          bool
 		shouldComment        bool
 		commentShouldContain string
@@ -895,6 +896,35 @@ func testNonCollaborators(clients localgit.Clients, t *testing.T) {
 			shouldLabel:   false,
 			shouldComment: false,
 		},
+		{
+			name:          "collaborators additions in OWNERS file by members of the required teams",
+			filesChanged:  []string{"OWNERS"},
+			ownersFile:    "nonCollaborators",
+			ownersPatch:   "collaboratorAdditions",
+			requirements:  membershipRequirements{teams: []string{"owners"}},
+			shouldLabel:   false,
+			shouldComment: false,
+		},
+		{
+			name:                 "collaborators additions in OWNERS file by non-members of the required teams",
+			filesChanged:         []string{"OWNERS"},
+			ownersFile:           "nonCollaborators",
+			ownersPatch:          "collaboratorAdditions",
+			requirements:         membershipRequirements{teams: []string{"owners", "sso"}},
+			shouldLabel:          true,
+			shouldComment:        true,
+			commentShouldContain: "User is not a member of the required GitHub team(s) org/sso.",
+		},
+		{
+			name:                 "collaborators additions in OWNERS_ALIASES file by non-members of the required teams",
+			filesChanged:         []string{"OWNERS_ALIASES"},
+			ownersAliasesFile:    "collaborators",
+			ownersAliasesPatch:   "collaboratorAdditions",
+			requirements:         membershipRequirements{teams: []string{"sso"}},
+			shouldLabel:          true,
+			shouldComment:        true,
+			commentShouldContain: "User is not a member of the required GitHub team(s) org/sso.",
+		},
 		{
 			name:          "collaborators removals in OWNERS file",
 			filesChanged:  []string{"OWNERS"},
@@ -1089,6 +1119,12 @@ func testNonCollaborators(clients localgit.Clients, t *testing.T) {
 			}
 			fghc := newFakeGitHubClient(emptyPatch(test.filesChanged), nil, pr)
 			fghc.PullRequestChanges[pr] = changes
+			fghc.Teams = map[string]map[string]fakegithub.TeamWithMembers{
+				"org": {
+					"owners": {Members: sets.New[string]("alice", "bob")},
+					"sso":    {Members: sets.New[string]("bob")},
+				},
+			}
 
 			fghc.PullRequests = map[int]*github.PullRequest{}
 			fghc.PullRequests[pr] = &github.PullRequest{
@@ -1115,7 +1151,7 @@ func testNonCollaborators(clients localgit.Clients, t *testing.T) {
 				number:       pr,
 			}
 
-			if err := handle(fghc, c, froc, logrus.WithField("plugin", PluginName), &pre.PullRequest, prInfo, []string{labels.Approved, labels.LGTM}, plugins.Trigger{}, test.skipTrustedUserCheck, &fakePruner{}, ownersconfig.FakeResolver); err != nil {
+			if err := handle(fghc, c, froc, logrus.WithField("plugin", PluginName), &pre.PullRequest, prInfo, []string{labels.Approved, labels.LGTM}, plugins.Trigger{}, test.skipTrustedUserCheck, test.requirements, &fakePruner{}, ownersconfig.FakeResolver); err != nil {
 				t.Fatalf("Handle PR: %v", err)
 			}
 			if !test.shouldLabel && IssueLabelsContain(fghc.IssueLabelsAdded, labels.InvalidOwners) {
@@ -1286,7 +1322,7 @@ func testHandleGenericComment(clients localgit.Clients, t *testing.T) {
 				},
 			}
 
-			if err := handleGenericComment(fghc, c, makeFakeRepoOwnersClient(), logrus.WithField("plugin", PluginName), &test.commentEvent, []string{labels.Approved, labels.LGTM}, plugins.Trigger{}, false, &fakePruner{}, ownersconfig.FakeResolver); err != nil {
+			if err := handleGenericComment(fghc, c, makeFakeRepoOwnersClient(), logrus.WithField("plugin", PluginName), &test.commentEvent, []string{labels.Approved, labels.LGTM}, plugins.Trigger{}, false, membershipRequirements{}, &fakePruner{}, ownersconfig.FakeResolver); err != nil {
 				t.Fatalf("Handle PR: %v", err)
 			}
 			if !test.shouldLabel && IssueLabelsContain(fghc.IssueLabelsAdded, labels.InvalidOwners) {
@@ -1419,7 +1455,7 @@ func testOwnersRemoval(clients localgit.Clients, t *testing.T) {
 
 			froc := makeFakeRepoOwnersClient()
 
-			if err := handle(fghc, c, froc, logrus.WithField("plugin", PluginName), &pre.PullRequest, prInfo, []string{labels.Approved, labels.LGTM}, plugins.Trigger{}, false, &fakePruner{}, ownersconfig.FakeResolver); err != nil {
+			if err := handle(fghc, c, froc, logrus.WithField("plugin", PluginName), &pre.PullRequest, prInfo, []string{labels.Approved, labels.LGTM}, plugins.Trigger{}, false, membershipRequirements{}, &fakePruner{}, ownersconfig.FakeResolver); err != nil {
 				t.Fatalf("Handle PR: %v", err)
 			}
 			if test.shouldRemoveLabel && !IssueLabelsContain(fghc.IssueLabelsRemoved, labels.InvalidOwners) {
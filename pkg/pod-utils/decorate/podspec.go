@@ -226,6 +226,9 @@ func ProwJobToPodLocal(pj prowapi.ProwJob, outputDir string) (*coreapi.Pod, erro
 			spec.Containers[i].Env = append(container.Env, KubeEnv(rawEnv)...)
 		}
 	} else {
+		// Use the utility images for the architecture the pod selects.
+		pj.Spec.DecorationConfig = pj.Spec.DecorationConfig.DeepCopy()
+		pj.Spec.DecorationConfig.UtilityImages = pj.Spec.DecorationConfig.UtilityImagesForArch(spec.NodeSelector[coreapi.LabelArchStable])
 		if err := decorate(spec, &pj, rawEnv, outputDir); err != nil {
 			return nil, fmt.Errorf("error decorating podspec: %w", err)
 		}
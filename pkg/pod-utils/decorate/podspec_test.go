@@ -1277,6 +1277,87 @@ func TestProwJobToPod_setsTerminationGracePeriodSeconds(t *testing.T) {
 	}
 }
 
+func TestProwJobToPod_usesArchUtilityImages(t *testing.T) {
+	testCases := []struct {
+		name              string
+		nodeSelector      map[string]string
+		expectedCloneRefs string
+		expectedSidecar   string
+	}{
+		{
+			name:              "no architecture node selector uses the default images",
+			expectedCloneRefs: "clonerefs:default",
+			expectedSidecar:   "sidecar:default",
+		},
+		{
+			name:              "arm64 node selector uses the arm64 images",
+			nodeSelector:      map[string]string{coreapi.LabelArchStable: "arm64"},
+			expectedCloneRefs: "clonerefs:arm64",
+			expectedSidecar:   "sidecar:default",
+		},
+	}
+
+	for idx := range testCases {
+		tc := testCases[idx]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pj := prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Spec: prowapi.ProwJobSpec{
+					Type: prowapi.PeriodicJob,
+					Job:  "job-name",
+					ExtraRefs: []prowapi.Refs{{
+						Org:     "org-name",
+						Repo:    "repo-name",
+						BaseRef: "base-ref",
+					}},
+					PodSpec: &coreapi.PodSpec{
+						NodeSelector: tc.nodeSelector,
+						Containers:   []coreapi.Container{{Image: "tester", Command: []string{"/bin/thing"}}},
+					},
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: 120 * time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: 10 * time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							CloneRefs:  "clonerefs:default",
+							InitUpload: "initupload:default",
+							Entrypoint: "entrypoint:default",
+							Sidecar:    "sidecar:default",
+						},
+						ArchUtilityImages: map[string]*prowapi.UtilityImages{
+							"arm64": {CloneRefs: "clonerefs:arm64"},
+						},
+						GCSConfiguration: &prowapi.GCSConfiguration{
+							Bucket:       "my-bucket",
+							PathStrategy: "legacy",
+							DefaultOrg:   "kubernetes",
+							DefaultRepo:  "kubernetes",
+						},
+						GCSCredentialsSecret: utilpointer.String("secret-name"),
+					},
+				},
+			}
+			pod, err := ProwJobToPod(pj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			images := map[string]string{}
+			for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+				images[container.Name] = container.Image
+			}
+			if images["clonerefs"] != tc.expectedCloneRefs {
+				t.Errorf("expected clonerefs image %q, got %q", tc.expectedCloneRefs, images["clonerefs"])
+			}
+			if images["sidecar"] != tc.expectedSidecar {
+				t.Errorf("expected sidecar image %q, got %q", tc.expectedSidecar, images["sidecar"])
+			}
+			if pj.Spec.DecorationConfig.UtilityImages.CloneRefs != "clonerefs:default" {
+				t.Errorf("expected the prowjob decoration config not to be mutated, got clonerefs image %q", pj.Spec.DecorationConfig.UtilityImages.CloneRefs)
+			}
+		})
+	}
+}
+
 func TestSidecar(t *testing.T) {
 	var testCases = []struct {
 		name                                    string
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prowjobcache provides a shared, indexed cache of ProwJobs. It is
+// backed by the informer of a controller-runtime cluster or manager, so all
+// readers in a component share a single watch instead of each issuing LIST
+// calls against the API server.
+package prowjobcache
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+const (
+	// OrgRepoIndex indexes ProwJobs by the org/repo of their refs and extra
+	// refs.
+	OrgRepoIndex = "prowjobcache.org-repo"
+	// StateIndex indexes ProwJobs by their state.
+	StateIndex = "prowjobcache.state"
+	// JobIndex indexes ProwJobs by the name of their job.
+	JobIndex = "prowjobcache.job"
+)
+
+// cluster is the subset of a controller-runtime cluster or manager the cache
+// needs.
+type cluster interface {
+	GetCache() cache.Cache
+	GetFieldIndexer() ctrlruntimeclient.FieldIndexer
+}
+
+// Cache lists ProwJobs from an informer cache.
+type Cache struct {
+	reader    ctrlruntimeclient.Reader
+	namespace string
+}
+
+// New adds the indexes of the cache to the cluster and returns a Cache of the
+// ProwJobs in namespace. It must be called before the cluster is started.
+func New(ctx context.Context, c cluster, namespace string) (*Cache, error) {
+	if err := AddIndexes(ctx, c.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+	// Create the informer now, so that waiting for the cache to sync
+	// actually waits for the ProwJobs.
+	if _, err := c.GetCache().GetInformer(ctx, &prowapi.ProwJob{}); err != nil {
+		return nil, fmt.Errorf("failed to get ProwJob informer: %w", err)
+	}
+	return &Cache{reader: c.GetCache(), namespace: namespace}, nil
+}
+
+// NewCacheFunc returns a cache constructor that only caches the ProwJobs
+// matching the selector. It can be set as the NewCache option of a cluster or
+// manager, to reduce the memory usage of components that only need some
+// ProwJobs.
+func NewCacheFunc(selector labels.Selector) cache.NewCacheFunc {
+	return cache.BuilderWithOptions(cache.Options{
+		SelectorsByObject: cache.SelectorsByObject{
+			&prowapi.ProwJob{}: {Label: selector},
+		},
+	})
+}
+
+// AddIndexes adds the indexes of the cache to the field indexer.
+func AddIndexes(ctx context.Context, indexer ctrlruntimeclient.FieldIndexer) error {
+	for name, fn := range map[string]ctrlruntimeclient.IndexerFunc{
+		OrgRepoIndex: orgRepoIndexFunc,
+		StateIndex:   stateIndexFunc,
+		JobIndex:     jobIndexFunc,
+	} {
+		if err := indexer.IndexField(ctx, &prowapi.ProwJob{}, name, fn); err != nil {
+			return fmt.Errorf("failed to add %s index: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func orgRepoIndexFunc(obj ctrlruntimeclient.Object) []string {
+	pj, ok := obj.(*prowapi.ProwJob)
+	if !ok {
+		return nil
+	}
+	orgRepos := sets.New[string]()
+	if pj.Spec.Refs != nil {
+		orgRepos.Insert(pj.Spec.Refs.Org + "/" + pj.Spec.Refs.Repo)
+	}
+	for _, ref := range pj.Spec.ExtraRefs {
+		orgRepos.Insert(ref.Org + "/" + ref.Repo)
+	}
+	return sets.List(orgRepos)
+}
+
+func stateIndexFunc(obj ctrlruntimeclient.Object) []string {
+	pj, ok := obj.(*prowapi.ProwJob)
+	if !ok {
+		return nil
+	}
+	return []string{string(pj.Status.State)}
+}
+
+func jobIndexFunc(obj ctrlruntimeclient.Object) []string {
+	pj, ok := obj.(*prowapi.ProwJob)
+	if !ok {
+		return nil
+	}
+	return []string{pj.Spec.Job}
+}
+
+// Get returns the ProwJob with the given name.
+func (c *Cache) Get(ctx context.Context, name string) (*prowapi.ProwJob, error) {
+	pj := &prowapi.ProwJob{}
+	if err := c.reader.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: name}, pj); err != nil {
+		return nil, err
+	}
+	return pj, nil
+}
+
+// List returns the ProwJobs matching the options.
+func (c *Cache) List(ctx context.Context, opts ...ctrlruntimeclient.ListOption) ([]prowapi.ProwJob, error) {
+	pjs := &prowapi.ProwJobList{}
+	opts = append([]ctrlruntimeclient.ListOption{ctrlruntimeclient.InNamespace(c.namespace)}, opts...)
+	if err := c.reader.List(ctx, pjs, opts...); err != nil {
+		return nil, err
+	}
+	return pjs.Items, nil
+}
+
+// ListByOrgRepo returns the ProwJobs whose refs or extra refs are of the
+// repository.
+func (c *Cache) ListByOrgRepo(ctx context.Context, org, repo string) ([]prowapi.ProwJob, error) {
+	return c.List(ctx, ctrlruntimeclient.MatchingFields{OrgRepoIndex: org + "/" + repo})
+}
+
+// ListByState returns the ProwJobs in the state.
+func (c *Cache) ListByState(ctx context.Context, state prowapi.ProwJobState) ([]prowapi.ProwJob, error) {
+	return c.List(ctx, ctrlruntimeclient.MatchingFields{StateIndex: string(state)})
+}
+
+// ListByJob returns the ProwJobs of the job.
+func (c *Cache) ListByJob(ctx context.Context, job string) ([]prowapi.ProwJob, error) {
+	return c.List(ctx, ctrlruntimeclient.MatchingFields{JobIndex: job})
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prowjobcache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// fakeIndexer records the index functions added to it.
+type fakeIndexer map[string]ctrlruntimeclient.IndexerFunc
+
+func (f fakeIndexer) IndexField(_ context.Context, _ ctrlruntimeclient.Object, field string, fn ctrlruntimeclient.IndexerFunc) error {
+	f[field] = fn
+	return nil
+}
+
+// indexingClient applies the index functions to field selectors, which the
+// fake client does not support.
+type indexingClient struct {
+	ctrlruntimeclient.Client
+	indexFuncs map[string]ctrlruntimeclient.IndexerFunc
+}
+
+func (c *indexingClient) List(ctx context.Context, list ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) error {
+	listOpts := &ctrlruntimeclient.ListOptions{}
+	for _, opt := range opts {
+		opt.ApplyToList(listOpts)
+	}
+	fieldSelector := listOpts.FieldSelector
+	listOpts.FieldSelector = nil
+	if err := c.Client.List(ctx, list, listOpts); err != nil {
+		return err
+	}
+	if fieldSelector == nil || fieldSelector.Empty() {
+		return nil
+	}
+	requirements := fieldSelector.Requirements()
+	if len(requirements) != 1 {
+		return fmt.Errorf("expected one field selector requirement, got %d", len(requirements))
+	}
+	indexFunc, ok := c.indexFuncs[requirements[0].Field]
+	if !ok {
+		return fmt.Errorf("no index with key %q found", requirements[0].Field)
+	}
+	pjList := list.(*prowapi.ProwJobList)
+	var result []prowapi.ProwJob
+	for i := range pjList.Items {
+		if sets.New[string](indexFunc(&pjList.Items[i])...).Has(requirements[0].Value) {
+			result = append(result, pjList.Items[i])
+		}
+	}
+	pjList.Items = result
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	pjs := []runtime.Object{
+		&prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "presubmit", Namespace: "prowjobs"},
+			Spec: prowapi.ProwJobSpec{
+				Job:  "pull-test",
+				Refs: &prowapi.Refs{Org: "org", Repo: "repo"},
+			},
+			Status: prowapi.ProwJobStatus{State: prowapi.PendingState},
+		},
+		&prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "periodic", Namespace: "prowjobs"},
+			Spec: prowapi.ProwJobSpec{
+				Job:       "ci-test",
+				ExtraRefs: []prowapi.Refs{{Org: "org", Repo: "repo"}, {Org: "org", Repo: "other"}},
+			},
+			Status: prowapi.ProwJobStatus{State: prowapi.SuccessState},
+		},
+		&prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "default"},
+			Spec: prowapi.ProwJobSpec{
+				Job:  "pull-test",
+				Refs: &prowapi.Refs{Org: "org", Repo: "repo"},
+			},
+			Status: prowapi.ProwJobStatus{State: prowapi.PendingState},
+		},
+	}
+
+	indexer := fakeIndexer{}
+	if err := AddIndexes(context.Background(), indexer); err != nil {
+		t.Fatalf("failed to add indexes: %v", err)
+	}
+	c := &Cache{
+		reader: &indexingClient{
+			Client:     fakectrlruntimeclient.NewFakeClient(pjs...),
+			indexFuncs: indexer,
+		},
+		namespace: "prowjobs",
+	}
+
+	names := func(pjs []prowapi.ProwJob, err error) []string {
+		if err != nil {
+			t.Fatalf("failed to list ProwJobs: %v", err)
+		}
+		var names []string
+		for _, pj := range pjs {
+			names = append(names, pj.Name)
+		}
+		return sets.List(sets.New[string](names...))
+	}
+
+	ctx := context.Background()
+	testCases := []struct {
+		name     string
+		actual   []string
+		expected []string
+	}{
+		{
+			name:     "all ProwJobs in the namespace",
+			actual:   names(c.List(ctx)),
+			expected: []string{"periodic", "presubmit"},
+		},
+		{
+			name:     "by refs and extra refs",
+			actual:   names(c.ListByOrgRepo(ctx, "org", "repo")),
+			expected: []string{"periodic", "presubmit"},
+		},
+		{
+			name:     "by extra refs",
+			actual:   names(c.ListByOrgRepo(ctx, "org", "other")),
+			expected: []string{"periodic"},
+		},
+		{
+			name:     "by state",
+			actual:   names(c.ListByState(ctx, prowapi.PendingState)),
+			expected: []string{"presubmit"},
+		},
+		{
+			name:     "by job",
+			actual:   names(c.ListByJob(ctx, "ci-test")),
+			expected: []string{"periodic"},
+		},
+		{
+			name:     "no match",
+			actual:   names(c.ListByJob(ctx, "missing")),
+			expected: []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, tc.actual); diff != "" {
+				t.Errorf("unexpected ProwJobs (-expected +actual): %s", diff)
+			}
+		})
+	}
+
+	pj, err := c.Get(ctx, "presubmit")
+	if err != nil {
+		t.Fatalf("failed to get ProwJob: %v", err)
+	}
+	if pj.Spec.Job != "pull-test" {
+		t.Errorf("expected job pull-test, got %q", pj.Spec.Job)
+	}
+	if _, err := c.Get(ctx, "other-namespace"); err == nil {
+		t.Error("expected getting a ProwJob of another namespace to fail")
+	}
+}
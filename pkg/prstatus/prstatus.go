@@ -44,7 +44,7 @@ const (
 // pullRequestQueryHandler defines an interface that query handlers should implement.
 type pullRequestQueryHandler interface {
 	queryPullRequests(context.Context, githubQuerier, string) ([]PullRequest, error)
-	getHeadContexts(ghc githubStatusFetcher, pr PullRequest) ([]Context, error)
+	getHeadContexts(ghc githubQuerier, pr PullRequest) ([]Context, error)
 }
 
 // UserData represents data returned to client request to the endpoint. It has a flag that indicates
@@ -122,21 +122,23 @@ type UserLoginQuery struct {
 }
 
 type searchQuery struct {
-	RateLimit struct {
-		Cost      githubql.Int
-		Remaining githubql.Int
-	}
-	Search struct {
-		PageInfo struct {
-			HasNextPage githubql.Boolean
-			EndCursor   githubql.String
-		}
-		Nodes []struct {
+	RateLimit github.GraphQLRateLimit
+	Search    struct {
+		PageInfo github.GraphQLPageInfo
+		Nodes    []struct {
 			PullRequest PullRequest `graphql:"... on PullRequest"`
 		}
 	} `graphql:"search(type: ISSUE, first: 100, after: $searchCursor, query: $query)"`
 }
 
+func (sq *searchQuery) GraphQLPageInfo() github.GraphQLPageInfo {
+	return sq.Search.PageInfo
+}
+
+func (sq *searchQuery) GraphQLRateLimit() github.GraphQLRateLimit {
+	return sq.RateLimit
+}
+
 // NewDashboardAgent creates a new user dashboard agent .
 func NewDashboardAgent(repos []string, config *githuboauth.Config, log *logrus.Entry) *DashboardAgent {
 	return &DashboardAgent{
@@ -163,7 +165,6 @@ func invalidateGitHubSession(w http.ResponseWriter, r *http.Request, session *se
 
 type GitHubClient interface {
 	githubQuerier
-	githubStatusFetcher
 	BotUser() (*github.UserData, error)
 }
 
@@ -301,15 +302,7 @@ func (da *DashboardAgent) queryPullRequests(ctx context.Context, ghc githubQueri
 		"query":        (githubql.String)(query),
 		"searchCursor": (*githubql.String)(nil),
 	}
-	var totalCost int
-	var remaining int
-	for {
-		sq := searchQuery{}
-		if err := ghc.QueryWithGitHubAppsSupport(ctx, &sq, vars, ""); err != nil {
-			return nil, err
-		}
-		totalCost += int(sq.RateLimit.Cost)
-		remaining = int(sq.RateLimit.Remaining)
+	stats, err := github.QueryAllPages(ctx, ghc, "", "prstatus_search", func() *searchQuery { return &searchQuery{} }, vars, "searchCursor", func(sq *searchQuery) error {
 		for _, n := range sq.Search.Nodes {
 			org := string(n.PullRequest.Repository.Owner.Login)
 			repo := string(n.PullRequest.Repository.Name)
@@ -320,52 +313,45 @@ func (da *DashboardAgent) queryPullRequests(ctx context.Context, ghc githubQueri
 			}
 			prs = append(prs, n.PullRequest)
 		}
-		if !sq.Search.PageInfo.HasNextPage {
-			break
-		}
-		vars["searchCursor"] = githubql.NewString(sq.Search.PageInfo.EndCursor)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	da.log.Infof("Search for query \"%s\" cost %d point(s). %d remaining.", query, totalCost, remaining)
+	da.log.Infof("Search for query \"%s\" cost %d point(s). %d remaining.", query, stats.Cost, stats.Remaining)
 	return prs, nil
 }
 
-type githubStatusFetcher interface {
-	GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error)
-	ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error)
-}
-
 // getHeadContexts returns the status checks' contexts of the head commit of the PR.
-func (da *DashboardAgent) getHeadContexts(ghc githubStatusFetcher, pr PullRequest) ([]Context, error) {
+func (da *DashboardAgent) getHeadContexts(ghc githubQuerier, pr PullRequest) ([]Context, error) {
 	org := string(pr.Repository.Owner.Login)
 	repo := string(pr.Repository.Name)
-	combined, err := ghc.GetCombinedStatus(org, repo, string(pr.HeadRefOID))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get the combined status: %w", err)
-	}
-	checkruns, err := ghc.ListCheckRuns(org, repo, string(pr.HeadRefOID))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	commitContexts, err := github.GetCommitContexts(ctx, ghc, org, repo, string(pr.HeadRefOID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch checkruns: %w", err)
+		return nil, fmt.Errorf("failed to get the head contexts: %w", err)
 	}
-	contexts := make([]Context, 0, len(combined.Statuses)+len(checkruns.CheckRuns))
-	for _, status := range combined.Statuses {
+	contexts := make([]Context, 0, len(commitContexts.Statuses)+len(commitContexts.CheckRuns))
+	for _, status := range commitContexts.Statuses {
 		contexts = append(contexts, Context{
-			Context:     status.Context,
-			Description: status.Description,
-			State:       strings.ToUpper(status.State),
+			Context:     string(status.Context),
+			Description: string(status.Description),
+			State:       string(status.State),
 		})
 	}
-	for _, checkrun := range checkruns.CheckRuns {
+	for _, checkrun := range commitContexts.CheckRuns {
 		var state string
-		if checkrun.CompletedAt == "" {
+		if checkrun.Status != "COMPLETED" {
 			state = "PENDING"
-		} else if strings.ToUpper(checkrun.Conclusion) == "NEUTRAL" {
+		} else if checkrun.Conclusion == "NEUTRAL" {
 			state = "SUCCESS"
 		} else {
-			state = strings.ToUpper(checkrun.Conclusion)
+			state = string(checkrun.Conclusion)
 		}
 		contexts = append(contexts, Context{
-			Context:     checkrun.Name,
-			Description: checkrun.DetailsURL,
+			Context:     string(checkrun.Name),
+			Description: string(checkrun.DetailsURL),
 			State:       state,
 		})
 	}
@@ -25,11 +25,13 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/gorilla/sessions"
+	githubql "github.com/shurcooL/githubv4"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"sigs.k8s.io/yaml"
@@ -47,7 +49,7 @@ func (mh *MockQueryHandler) queryPullRequests(ctx context.Context, ghc githubQue
 	return mh.prs, nil
 }
 
-func (mh *MockQueryHandler) getHeadContexts(ghc githubStatusFetcher, pr PullRequest) ([]Context, error) {
+func (mh *MockQueryHandler) getHeadContexts(ghc githubQuerier, pr PullRequest) ([]Context, error) {
 	return mh.contextMap[int(pr.Number)], nil
 }
 
@@ -57,19 +59,44 @@ type fgc struct {
 	botName        string
 }
 
-func (c fgc) QueryWithGitHubAppsSupport(context.Context, interface{}, map[string]interface{}, string) error {
-	return nil
-}
-
-func (c fgc) GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error) {
-	return c.combinedStatus, nil
-}
-
-func (c fgc) ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error) {
+// QueryWithGitHubAppsSupport answers commit contexts queries with the
+// combined status and check runs like GitHub's GraphQL API would.
+func (c fgc) QueryWithGitHubAppsSupport(_ context.Context, q interface{}, _ map[string]interface{}, _ string) error {
+	cq, ok := q.(*github.CommitContextsQuery)
+	if !ok {
+		return nil
+	}
+	contexts := &cq.Repository.Object.Commit.StatusCheckRollup.Contexts
+	if c.combinedStatus != nil {
+		for _, status := range c.combinedStatus.Statuses {
+			contexts.Nodes = append(contexts.Nodes, github.CommitContextNode{
+				Typename: "StatusContext",
+				StatusContext: github.StatusContextFragment{
+					Context:     githubql.String(status.Context),
+					Description: githubql.String(status.Description),
+					State:       githubql.StatusState(strings.ToUpper(status.State)),
+				},
+			})
+		}
+	}
 	if c.checkruns != nil {
-		return c.checkruns, nil
+		for _, checkrun := range c.checkruns.CheckRuns {
+			status := "IN_PROGRESS"
+			if checkrun.CompletedAt != "" {
+				status = "COMPLETED"
+			}
+			contexts.Nodes = append(contexts.Nodes, github.CommitContextNode{
+				Typename: "CheckRun",
+				CheckRun: github.CheckRunFragment{
+					Name:       githubql.String(checkrun.Name),
+					Status:     githubql.String(status),
+					Conclusion: githubql.String(strings.ToUpper(checkrun.Conclusion)),
+					DetailsURL: githubql.String(checkrun.DetailsURL),
+				},
+			})
+		}
 	}
-	return &github.CheckRunList{}, nil
+	return nil
 }
 
 func (c fgc) BotUser() (*github.UserData, error) {
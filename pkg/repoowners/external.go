@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repoowners
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins/ownersconfig"
+)
+
+// ExternalOwners is the ownership data of a repository served by an external
+// OWNERS provider, which is used instead of the OWNERS files in the repository.
+//
+// The provider is queried with an HTTP GET request to its endpoint with the
+// org, repo, base and sha query parameters set, and must respond with this
+// struct encoded as JSON.
+type ExternalOwners struct {
+	// Directories maps directories relative to the root of the repository,
+	// with "" denoting the root, to their OWNERS configuration.
+	Directories map[string]FullConfig `json:"directories"`
+	// Aliases maps the aliases used in Directories to their members.
+	Aliases map[string][]string `json:"aliases,omitempty"`
+}
+
+// externalProviderClient is the HTTP client used to query external OWNERS
+// providers.
+var externalProviderClient = &http.Client{Timeout: time.Minute}
+
+func (c *Client) externalCacheEntryFor(endpoint, org, repo, base, fullName, sha string, setEntry bool, log *logrus.Entry) (cacheEntry, error) {
+	key := "external:" + endpoint + ":" + fullName
+	entry, ok, entryLock := c.cache.getEntry(key)
+	defer entryLock.Unlock()
+	if ok && entry.sha == sha && entry.owners != nil {
+		return entry, nil
+	}
+
+	start := time.Now()
+	external, err := fetchExternalOwners(externalProviderClient, endpoint, org, repo, base, sha)
+	if err != nil {
+		return cacheEntry{}, fmt.Errorf("failed to load OWNERS for %s from external provider: %w", fullName, err)
+	}
+	log.WithField("duration", time.Since(start).String()).Debugf("Completed fetchExternalOwners(%s)", endpoint)
+
+	entry = cacheEntry{sha: sha, aliases: RepoAliases{}}
+	for alias, members := range external.Aliases {
+		entry.aliases[github.NormLogin(alias)] = NormLogins(members)
+	}
+	entry.owners = externalRepoOwners(external, entry.aliases, c.filenames(org, repo), log)
+	if setEntry {
+		c.cache.setEntry(key, entry)
+	}
+	return entry, nil
+}
+
+func fetchExternalOwners(client *http.Client, endpoint, org, repo, base, sha string) (*ExternalOwners, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	query := u.Query()
+	query.Set("org", org)
+	query.Set("repo", repo)
+	query.Set("base", base)
+	query.Set("sha", sha)
+	u.RawQuery = query.Encode()
+
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("response has status %q and body %q", resp.Status, string(body))
+	}
+	external := &ExternalOwners{}
+	if err := json.NewDecoder(resp.Body).Decode(external); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return external, nil
+}
+
+// externalRepoOwners builds the RepoOwners from ownership data served by an
+// external provider. As there are no OWNERS files backing it, ParseSimpleConfig
+// and ParseFullConfig of the result fail.
+func externalRepoOwners(external *ExternalOwners, aliases RepoAliases, filenames ownersconfig.Filenames, log *logrus.Entry) *RepoOwners {
+	o := &RepoOwners{
+		RepoAliases: aliases,
+		filenames:   filenames,
+		log:         log,
+
+		approvers:         make(map[string]map[*regexp.Regexp]sets.Set[string]),
+		reviewers:         make(map[string]map[*regexp.Regexp]sets.Set[string]),
+		requiredReviewers: make(map[string]map[*regexp.Regexp]sets.Set[string]),
+		labels:            make(map[string]map[*regexp.Regexp]sets.Set[string]),
+		options:           make(map[string]dirOptions),
+	}
+	for dir, config := range external.Directories {
+		path := canonicalize(dir)
+		for pattern, filter := range config.Filters {
+			var re *regexp.Regexp
+			if pattern != ".*" {
+				var err error
+				if re, err = regexp.Compile(pattern); err != nil {
+					log.WithError(err).Debugf("Invalid regexp %q.", pattern)
+					continue
+				}
+			}
+			filter := filter
+			o.applyConfigToPath(path, re, &filter)
+		}
+		o.applyOptionsToPath(path, config.Options)
+	}
+	return o
+}
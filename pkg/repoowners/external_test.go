@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repoowners
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/prow/pkg/plugins/ownersconfig"
+)
+
+func TestLoadRepoOwnersExternal(t *testing.T) {
+	external := ExternalOwners{
+		Directories: map[string]FullConfig{
+			"": {
+				Filters: map[string]Config{
+					".*": {Approvers: []string{"Alice", "sig-leads"}, Reviewers: []string{"bob"}},
+				},
+			},
+			"docs": {
+				Options: dirOptions{NoParentOwners: true},
+				Filters: map[string]Config{
+					".*":       {Approvers: []string{"carl"}},
+					"\\.md$":   {Reviewers: []string{"dave"}, Labels: []string{"kind/docs"}},
+					"invalid(": {Approvers: []string{"mallory"}},
+				},
+			},
+		},
+		Aliases: map[string][]string{"Sig-Leads": {"Erin", "frank"}},
+	}
+
+	var requests int
+	var query map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query = map[string]string{}
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		if err := json.NewEncoder(w).Encode(external); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		logger: logrus.WithField("client", "repoowners"),
+		ghc:    &fakeGitHubClient{Collaborators: []string{"alice", "bob", "carl", "dave", "erin"}, ref: "abc"},
+		delegate: &delegate{
+			cache:             newCache(),
+			mdYAMLEnabled:     func(org, repo string) bool { return false },
+			skipCollaborators: func(org, repo string) bool { return false },
+			filenames:         ownersconfig.FakeResolver,
+			externalProvider: func(org, repo string) string {
+				return server.URL + "/owners?token=x"
+			},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		ro, err := client.LoadRepoOwners("org", "repo", "main")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected, got := sets.New[string]("alice", "erin"), ro.Approvers("").Set(); !expected.Equal(got) {
+			t.Errorf("expected root approvers %v, got %v", sets.List(expected), sets.List(got))
+		}
+		if expected, got := sets.New[string]("carl"), ro.Approvers("docs/guide.md").Set(); !expected.Equal(got) {
+			t.Errorf("expected docs approvers %v, got %v", sets.List(expected), sets.List(got))
+		}
+		if expected, got := sets.New[string]("dave"), ro.Reviewers("docs/guide.md").Set(); !expected.Equal(got) {
+			t.Errorf("expected docs reviewers %v, got %v", sets.List(expected), sets.List(got))
+		}
+		if expected, got := sets.New[string]("kind/docs"), ro.FindLabelsForFile("docs/guide.md"); !expected.Equal(got) {
+			t.Errorf("expected docs labels %v, got %v", sets.List(expected), sets.List(got))
+		}
+		if !ro.IsNoParentOwners("docs") {
+			t.Error("expected docs to have no_parent_owners set")
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the provider to be queried once for an unchanged sha, got %d requests", requests)
+	}
+	expectedQuery := map[string]string{"org": "org", "repo": "repo", "base": "main", "sha": "abc", "token": "x"}
+	for key, value := range expectedQuery {
+		if query[key] != value {
+			t.Errorf("expected query parameter %s=%q, got %q", key, value, query[key])
+		}
+	}
+}
+
+func TestLoadRepoOwnersExternalError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such repo", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		logger: logrus.WithField("client", "repoowners"),
+		ghc:    &fakeGitHubClient{ref: "abc"},
+		delegate: &delegate{
+			cache:             newCache(),
+			skipCollaborators: func(org, repo string) bool { return true },
+			filenames:         ownersconfig.FakeResolver,
+			externalProvider:  func(org, repo string) string { return server.URL },
+		},
+	}
+	if _, err := client.LoadRepoOwners("org", "repo", "main"); err == nil {
+		t.Error("expected an error when the provider fails, got none")
+	}
+}
@@ -169,6 +169,7 @@ type delegate struct {
 	skipCollaborators func(org, repo string) bool
 	ownersDirDenylist func() *prowConf.OwnersDirDenylist
 	filenames         ownersconfig.Resolver
+	externalProvider  func(org, repo string) string
 
 	cache *cache
 }
@@ -219,6 +220,7 @@ func NewClient(
 	skipCollaborators func(org, repo string) bool,
 	ownersDirDenylist func() *prowConf.OwnersDirDenylist,
 	filenames ownersconfig.Resolver,
+	externalProvider func(org, repo string) string,
 ) *Client {
 	return &Client{
 		logger: logrus.WithField("client", "repoowners"),
@@ -231,6 +233,7 @@ func NewClient(
 			skipCollaborators: skipCollaborators,
 			ownersDirDenylist: ownersDirDenylist,
 			filenames:         filenames,
+			externalProvider:  externalProvider,
 		},
 	}
 }
@@ -309,7 +312,13 @@ func (c *Client) LoadRepoOwnersSha(org, repo, base, sha string, updateCache bool
 	cloneRef := fmt.Sprintf("%s/%s", org, repo)
 	fullName := fmt.Sprintf("%s:%s", cloneRef, base)
 
-	entry, err := c.cacheEntryFor(org, repo, base, cloneRef, fullName, sha, updateCache, log)
+	var entry cacheEntry
+	var err error
+	if endpoint := c.externalProviderFor(org, repo); endpoint != "" {
+		entry, err = c.externalCacheEntryFor(endpoint, org, repo, base, fullName, sha, updateCache, log)
+	} else {
+		entry, err = c.cacheEntryFor(org, repo, base, cloneRef, fullName, sha, updateCache, log)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -339,6 +348,15 @@ func (c *Client) LoadRepoOwnersSha(org, repo, base, sha string, updateCache bool
 	return owners, nil
 }
 
+// externalProviderFor returns the endpoint of the external OWNERS provider
+// for the repo, or an empty string if OWNERS files should be used.
+func (c *Client) externalProviderFor(org, repo string) string {
+	if c.externalProvider == nil {
+		return ""
+	}
+	return c.externalProvider(org, repo)
+}
+
 func (c *Client) cacheEntryFor(org, repo, base, cloneRef, fullName, sha string, setEntry bool, log *logrus.Entry) (cacheEntry, error) {
 	mdYaml := c.mdYAMLEnabled(org, repo)
 	lockStart := time.Now()
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/pjutil"
 	"sigs.k8s.io/prow/pkg/scheduler/strategy"
 )
 
@@ -97,7 +98,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	scheduled.Spec.Cluster = result.Cluster
 	scheduled.Status.State = prowv1.TriggeredState
 
-	if err := r.pjClient.Patch(ctx, scheduled, client.MergeFrom(pj.DeepCopy())); err != nil {
+	if err := pjutil.PatchProwJobWithClient(ctx, r.pjClient, pj.DeepCopy(), scheduled); err != nil {
 		return reconcile.Result{}, fmt.Errorf("patch prowjob: %w", err)
 	}
 
@@ -67,6 +67,13 @@ func (ft *fakeTracker) Update(gvr schema.GroupVersionResource, obj runtime.Objec
 	return ft.ObjectTracker.Update(gvr, obj, ns)
 }
 
+// triggeredConditions are the conditions of a ProwJob that was scheduled.
+var triggeredConditions = []v1.Condition{
+	{Type: prowv1.ProwJobRunning, Status: v1.ConditionFalse, Reason: "Triggered"},
+	{Type: prowv1.ProwJobComplete, Status: v1.ConditionFalse, Reason: "Triggered"},
+	{Type: prowv1.ProwJobSucceeded, Status: v1.ConditionUnknown, Reason: "Triggered"},
+}
+
 func TestReconcile(t *testing.T) {
 	for _, tc := range []struct {
 		name            string
@@ -87,9 +94,9 @@ func TestReconcile(t *testing.T) {
 			request: reconcile.Request{NamespacedName: types.NamespacedName{Name: "pj", Namespace: "ns"}},
 			cluster: "foo",
 			wantPJ: &prowv1.ProwJob{
-				ObjectMeta: v1.ObjectMeta{Name: "pj", Namespace: "ns", ResourceVersion: "2"},
+				ObjectMeta: v1.ObjectMeta{Name: "pj", Namespace: "ns", ResourceVersion: "3"},
 				Spec:       prowv1.ProwJobSpec{Cluster: "foo", Agent: prowv1.KubernetesAgent},
-				Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+				Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, Conditions: triggeredConditions},
 			},
 		},
 		{
@@ -101,9 +108,9 @@ func TestReconcile(t *testing.T) {
 			request: reconcile.Request{NamespacedName: types.NamespacedName{Name: "pj", Namespace: "ns"}},
 			cluster: "foo",
 			wantPJ: &prowv1.ProwJob{
-				ObjectMeta: v1.ObjectMeta{Name: "pj", Namespace: "ns", ResourceVersion: "2"},
+				ObjectMeta: v1.ObjectMeta{Name: "pj", Namespace: "ns", ResourceVersion: "3"},
 				Spec:       prowv1.ProwJobSpec{Cluster: "foo", Agent: prowv1.TektonAgent},
-				Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+				Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, Conditions: triggeredConditions},
 			},
 		},
 		{
@@ -127,7 +134,7 @@ func TestReconcile(t *testing.T) {
 			wantPJ: &prowv1.ProwJob{
 				ObjectMeta: v1.ObjectMeta{Name: "pj", Namespace: "ns", ResourceVersion: "2"},
 				Spec:       prowv1.ProwJobSpec{Cluster: "foo"},
-				Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+				Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, Conditions: triggeredConditions},
 			},
 			clientErrors: map[string]error{"UPDATE": errors.New("expected")},
 			wantError:    errors.New("patch prowjob: expected"),
@@ -153,7 +160,7 @@ func TestReconcile(t *testing.T) {
 			wantPJ: &prowv1.ProwJob{
 				ObjectMeta: v1.ObjectMeta{Name: "pj", Namespace: "ns", ResourceVersion: "2"},
 				Spec:       prowv1.ProwJobSpec{Cluster: "untouched"},
-				Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+				Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, Conditions: triggeredConditions},
 			},
 		},
 	} {
@@ -241,12 +248,12 @@ func TestConfigHotReload(t *testing.T) {
 				{
 					ObjectMeta: v1.ObjectMeta{Name: "job1", Namespace: "", ResourceVersion: "2"},
 					Spec:       prowv1.ProwJobSpec{Agent: prowv1.KubernetesAgent, Cluster: "foo"},
-					Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+					Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, Conditions: triggeredConditions},
 				},
 				{
-					ObjectMeta: v1.ObjectMeta{Name: "job2", Namespace: "", ResourceVersion: "2"},
+					ObjectMeta: v1.ObjectMeta{Name: "job2", Namespace: "", ResourceVersion: "3"},
 					Spec:       prowv1.ProwJobSpec{Agent: prowv1.KubernetesAgent, Cluster: "bar"},
-					Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+					Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, Conditions: triggeredConditions},
 				},
 			},
 		},
@@ -279,14 +286,14 @@ func TestConfigHotReload(t *testing.T) {
 			},
 			wantPJs: []prowv1.ProwJob{
 				{
-					ObjectMeta: v1.ObjectMeta{Name: "job1", Namespace: "", ResourceVersion: "2"},
+					ObjectMeta: v1.ObjectMeta{Name: "job1", Namespace: "", ResourceVersion: "3"},
 					Spec:       prowv1.ProwJobSpec{Agent: prowv1.KubernetesAgent, Cluster: "bar"},
-					Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+					Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, Conditions: triggeredConditions},
 				},
 				{
-					ObjectMeta: v1.ObjectMeta{Name: "job2", Namespace: "", ResourceVersion: "2"},
+					ObjectMeta: v1.ObjectMeta{Name: "job2", Namespace: "", ResourceVersion: "3"},
 					Spec:       prowv1.ProwJobSpec{Agent: prowv1.KubernetesAgent, Cluster: "duper"},
-					Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState},
+					Status:     prowv1.ProwJobStatus{State: prowv1.TriggeredState, Conditions: triggeredConditions},
 				},
 			},
 		},
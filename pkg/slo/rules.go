@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package slo derives Prometheus recording and alerting rules for the SLOs
+// defined in the Prow config, so that every team gets the same burn rate
+// alerts for its jobs and repos without writing PromQL.
+package slo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/prow/pkg/config"
+)
+
+// RuleGroups is the content of a Prometheus rule file.
+type RuleGroups struct {
+	Groups []RuleGroup `json:"groups"`
+}
+
+// RuleGroup is a group of Prometheus rules that are evaluated together.
+type RuleGroup struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// Rule is a Prometheus recording or alerting rule.
+type Rule struct {
+	Record      string            `json:"record,omitempty"`
+	Alert       string            `json:"alert,omitempty"`
+	Expr        string            `json:"expr"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// burnRate alerts when the error budget of an SLO over 30 days is consumed
+// too fast over both a long and a short window. The short window resets the
+// alert soon after the burn stopped.
+type burnRate struct {
+	long, short string
+	// factor is how many times faster than allowed the budget is consumed.
+	factor   float64
+	severity string
+}
+
+// burnRates are the multiwindow, multi-burn-rate alerts recommended by the
+// SRE workbook: the pages fire when 2% or 5% of the budget is consumed in an
+// hour or six hours, the tickets when 10% is consumed in a day or three days.
+var burnRates = []burnRate{
+	{long: "1h", short: "5m", factor: 14.4, severity: "page"},
+	{long: "6h", short: "30m", factor: 6, severity: "page"},
+	{long: "1d", short: "2h", factor: 3, severity: "ticket"},
+	{long: "3d", short: "6h", factor: 1, severity: "ticket"},
+}
+
+// windows are all the windows of the burn rates, for which the error ratios
+// are recorded.
+var windows = []string{"5m", "30m", "1h", "2h", "6h", "1d", "3d"}
+
+// objective is an SLO that rules are generated for.
+type objective struct {
+	// id identifies the SLO, e.g. the name of the job.
+	id string
+	// record is the name of the error ratio recording rules, which is
+	// suffixed with the window.
+	record string
+	// errorRatio returns the ratio of bad events over a window.
+	errorRatio func(window string) string
+	target     float64
+	team       string
+	alert      string
+	summary    string
+}
+
+// Generate derives the rules for the job success rate SLOs set with the
+// annotations of jobs and the merge latency SLOs of Tide.
+func Generate(cfg *config.Config) (*RuleGroups, error) {
+	objectives, err := jobObjectives(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tideObjectives, err := tideObjectives(cfg.Tide)
+	if err != nil {
+		return nil, err
+	}
+	objectives = append(objectives, tideObjectives...)
+
+	recording := RuleGroup{Name: "prow-slo-error-ratios"}
+	alerting := RuleGroup{Name: "prow-slo-burn-rates"}
+	for _, o := range objectives {
+		for _, window := range windows {
+			recording.Rules = append(recording.Rules, Rule{
+				Record: o.record + ":rate" + window,
+				Expr:   o.errorRatio(window),
+				Labels: map[string]string{"slo": o.id},
+			})
+		}
+		for _, b := range burnRates {
+			threshold := strconv.FormatFloat(b.factor*(1-o.target), 'g', 6, 64)
+			selector := fmt.Sprintf("{slo=%s}", strconv.Quote(o.id))
+			labels := map[string]string{"severity": b.severity, "slo": o.id}
+			if o.team != "" {
+				labels["team"] = o.team
+			}
+			alerting.Rules = append(alerting.Rules, Rule{
+				Alert: o.alert,
+				Expr: fmt.Sprintf("%s:rate%s%s > %s and %s:rate%s%s > %s",
+					o.record, b.long, selector, threshold, o.record, b.short, selector, threshold),
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary":     o.summary,
+					"description": fmt.Sprintf("%s is consuming its error budget %gx as fast as allowed over the last %s for an objective of %g%% over 30 days.", o.id, b.factor, b.long, 100*o.target),
+				},
+			})
+		}
+	}
+	return &RuleGroups{Groups: []RuleGroup{recording, alerting}}, nil
+}
+
+// jobObjectives returns the success rate SLOs of all jobs that set one, in the
+// order of their names. Jobs of the same name must agree on their SLO, as the
+// metrics of jobs only tell them apart by their name.
+func jobObjectives(cfg *config.Config) ([]objective, error) {
+	var jobs []config.JobBase
+	for _, p := range cfg.AllStaticPresubmits(nil) {
+		jobs = append(jobs, p.JobBase)
+	}
+	for _, p := range cfg.AllStaticPostsubmits(nil) {
+		jobs = append(jobs, p.JobBase)
+	}
+	for _, p := range cfg.AllPeriodics() {
+		jobs = append(jobs, p.JobBase)
+	}
+
+	byName := map[string]objective{}
+	for _, job := range jobs {
+		value, ok := job.Annotations[config.SLOSuccessRateAnnotation]
+		if !ok {
+			continue
+		}
+		target, err := config.ParseSLOObjective(value)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		name := job.Name
+		o := objective{
+			id:     name,
+			record: "prowjob_slo:error_ratio",
+			errorRatio: func(window string) string {
+				selector := fmt.Sprintf("job_name=%s", strconv.Quote(name))
+				return fmt.Sprintf(`sum by (job_name) (rate(prowjob_state_transitions{%s,state=~"failure|error"}[%s])) / sum by (job_name) (rate(prowjob_state_transitions{%s,state=~"success|failure|error"}[%s]))`,
+					selector, window, selector, window)
+			},
+			target:  target,
+			team:    job.Annotations[config.SLOTeamAnnotation],
+			alert:   "ProwJobSuccessRateBudgetBurn",
+			summary: fmt.Sprintf("Job %s fails too often to meet its success rate objective.", name),
+		}
+		if existing, ok := byName[name]; ok && (existing.target != o.target || existing.team != o.team) {
+			return nil, fmt.Errorf("jobs named %s have different SLOs", name)
+		}
+		byName[name] = o
+	}
+
+	var objectives []objective
+	for _, o := range byName {
+		objectives = append(objectives, o)
+	}
+	sort.Slice(objectives, func(i, j int) bool { return objectives[i].id < objectives[j].id })
+	return objectives, nil
+}
+
+// tideObjectives returns the merge latency SLOs of Tide, in the order of the
+// orgs and repos they are set for.
+func tideObjectives(tide config.Tide) ([]objective, error) {
+	var objectives []objective
+	for orgRepo, slo := range tide.MergeLatencySLOs {
+		target, err := config.ParseSLOObjective(slo.Objective)
+		if err != nil {
+			return nil, fmt.Errorf("merge latency SLO of %s: %w", orgRepo, err)
+		}
+		labels := []string{"org"}
+		matchers := []string{fmt.Sprintf("org=%s", strconv.Quote(orgRepo))}
+		if org, repo, ok := strings.Cut(orgRepo, "/"); ok {
+			labels = append(labels, "repo")
+			matchers = []string{fmt.Sprintf("org=%s", strconv.Quote(org)), fmt.Sprintf("repo=%s", strconv.Quote(repo))}
+		}
+		by := strings.Join(labels, ", ")
+		selector := strings.Join(matchers, ",")
+		// Prometheus may normalize the bucket of the threshold to e.g. 3600.0.
+		le := strconv.FormatFloat(slo.Threshold.Duration.Seconds(), 'f', -1, 64)
+		threshold := slo.Threshold.Duration.String()
+		objectives = append(objectives, objective{
+			id:     orgRepo,
+			record: "tide_slo:merge_latency_error_ratio",
+			errorRatio: func(window string) string {
+				return fmt.Sprintf(`1 - sum by (%s) (rate(tidemergelatency_bucket{%s,le=~"%s(\\.0)?"}[%s])) / sum by (%s) (rate(tidemergelatency_count{%s}[%s]))`,
+					by, selector, le, window, by, selector, window)
+			},
+			target:  target,
+			team:    slo.Team,
+			alert:   "TideMergeLatencyBudgetBurn",
+			summary: fmt.Sprintf("Too many PRs of %s wait longer than %s in the pool of Tide until they are merged.", orgRepo, threshold),
+		})
+	}
+	sort.Slice(objectives, func(i, j int) bool { return objectives[i].id < objectives[j].id })
+	return objectives, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/testutil"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := &config.Config{
+		JobConfig: config.JobConfig{
+			PresubmitsStatic: map[string][]config.Presubmit{
+				"org/repo": {
+					{JobBase: config.JobBase{Name: "pull-unit", Annotations: map[string]string{config.SLOSuccessRateAnnotation: "95%", config.SLOTeamAnnotation: "sig-testing"}}},
+					{JobBase: config.JobBase{Name: "pull-lint"}},
+				},
+			},
+			Periodics: []config.Periodic{
+				{JobBase: config.JobBase{Name: "ci-e2e", Annotations: map[string]string{config.SLOSuccessRateAnnotation: "0.9"}}},
+			},
+		},
+		ProwConfig: config.ProwConfig{
+			Tide: config.Tide{
+				MergeLatencySLOs: map[string]config.TideMergeLatencySLO{
+					"org/repo": {Threshold: metav1.Duration{Duration: time.Hour}, Objective: "99%", Team: "sig-testing"},
+					"other":    {Threshold: metav1.Duration{Duration: 4 * time.Hour}, Objective: "90%"},
+				},
+			},
+		},
+	}
+
+	rules, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("failed to generate rules: %v", err)
+	}
+	raw, err := yaml.Marshal(rules)
+	if err != nil {
+		t.Fatalf("failed to marshal rules: %v", err)
+	}
+	output := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(output, raw, 0644); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+	testutil.CompareWithFixture(t, "testdata/rules.yaml", output)
+}
+
+func TestGenerateConflictingJobs(t *testing.T) {
+	cfg := &config.Config{
+		JobConfig: config.JobConfig{
+			PresubmitsStatic: map[string][]config.Presubmit{
+				"org/repo":  {{JobBase: config.JobBase{Name: "pull-unit", Annotations: map[string]string{config.SLOSuccessRateAnnotation: "95%"}}}},
+				"org/other": {{JobBase: config.JobBase{Name: "pull-unit", Annotations: map[string]string{config.SLOSuccessRateAnnotation: "99%"}}}},
+			},
+		},
+	}
+	if _, err := Generate(cfg); err == nil {
+		t.Error("expected jobs of the same name with different SLOs to fail the generation")
+	}
+}
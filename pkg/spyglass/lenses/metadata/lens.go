@@ -93,6 +93,11 @@ func (lens Lens) Body(artifacts []api.Artifact, resourceDir string, data string,
 		Elapsed      time.Duration
 		Hint         string
 		Metadata     map[string]interface{}
+		// ResourceUsage is the CPU and memory usage of the pod of the job,
+		// if it was sampled.
+		ResourceUsage *prowv1.ResourceUsage
+		// Attempts are the previous attempts of the job, if it was retried.
+		Attempts []prowv1.ProwJobAttempt
 	}
 	metadataViewData := MetadataViewData{}
 	started := metadata.Started{}
@@ -132,6 +137,8 @@ func (lens Lens) Body(artifacts []api.Artifact, resourceDir string, data string,
 		case "podinfo.json":
 			metadataViewData.Hint = hintFromPodInfo(read)
 		case prowv1.ProwJobFile:
+			metadataViewData.ResourceUsage = resourceUsageFromProwJob(read)
+			metadataViewData.Attempts = attemptsFromProwJob(read)
 			// Only show the prowjob-based hint if we don't have a pod-based one
 			// (the pod-based ones are probably more useful when they exist)
 			if metadataViewData.Hint == "" {
@@ -161,7 +168,10 @@ func (lens Lens) Body(artifacts []api.Artifact, resourceDir string, data string,
 		}
 	}
 
-	metadataTemplate, err := template.ParseFiles(filepath.Join(resourceDir, "template.html"))
+	metadataTemplate, err := template.New("template.html").Funcs(template.FuncMap{
+		// inc numbers attempts from one.
+		"inc": func(i int) int { return i + 1 },
+	}).ParseFiles(filepath.Join(resourceDir, "template.html"))
 	if err != nil {
 		return fmt.Sprintf("Failed to load template: %v", err)
 	}
@@ -279,6 +289,24 @@ func hintFromProwJob(buf []byte) (string, bool) {
 	return "", false
 }
 
+func resourceUsageFromProwJob(buf []byte) *prowv1.ResourceUsage {
+	var pj prowv1.ProwJob
+	if err := json.Unmarshal(buf, &pj); err != nil {
+		// This is already logged by hintFromProwJob.
+		return nil
+	}
+	return pj.Status.ResourceUsage
+}
+
+func attemptsFromProwJob(buf []byte) []prowv1.ProwJobAttempt {
+	var pj prowv1.ProwJob
+	if err := json.Unmarshal(buf, &pj); err != nil {
+		// This is already logged by hintFromProwJob.
+		return nil
+	}
+	return pj.Status.Attempts
+}
+
 // flattenMetadata flattens the metadata for use by Body.
 func (lens Lens) flattenMetadata(metadata map[string]interface{}) map[string]string {
 	results := map[string]string{}
@@ -81,6 +81,28 @@ func TestCheckTimestamps(t *testing.T) {
 			expectedSubstrings: []string{`WARNING: The elapsed duration (-1328h39m7s) is negative. This can be caused by another process outside of Prow writing into the finished.json file. The file currently has a completion time of`},
 			err:                nil,
 		},
+		{
+			name: "resource usage of the job",
+			artifacts: []api.Artifact{
+				startedJson, finishedJsonNormal, &FakeArtifact{
+					Path:    prowv1.ProwJobFile,
+					Content: []byte(`{"status":{"state":"success","resource_usage":{"peak_cpu":"1500m","peak_memory":"2Gi","cpu_seconds":600,"samples":20}}}`),
+				},
+			},
+			expectedSubstrings: []string{`Peak CPU`, `1500m`, `2Gi`, `600s`},
+			err:                nil,
+		},
+		{
+			name: "previous attempts of the job",
+			artifacts: []api.Artifact{
+				startedJson, finishedJsonNormal, &FakeArtifact{
+					Path:    prowv1.ProwJobFile,
+					Content: []byte(`{"status":{"state":"success","attempts":[{"build_id":"123","url":"https://prow.example.com/view/gs/bucket/logs/job/123","completionTime":"2024-01-01T00:00:00Z","state":"failure","reason":"Failure","description":"Job failed."}]}}`),
+				},
+			},
+			expectedSubstrings: []string{`Attempt 1`, `<a href="https://prow.example.com/view/gs/bucket/logs/job/123">123</a>`, `failure (Failure)`},
+			err:                nil,
+		},
 	}
 	for _, tc := range testCases {
 		lens, err := lenses.GetLens("metadata")
@@ -38,7 +38,7 @@ import (
 )
 
 // NewController constructs a new controller to reconcile stauses on config change
-func NewController(continueOnError bool, addedPresubmitDenylist, addedPresubmitDenylistAll sets.Set[string], opener io.Opener, configOpts configflagutil.ConfigOptions, statusURI string, prowJobClient prowv1.ProwJobInterface, githubClient github.Client, pluginAgent *plugins.ConfigAgent) *Controller {
+func NewController(continueOnError bool, addedPresubmitDenylist, addedPresubmitDenylistAll sets.Set[string], opener io.Opener, configOpts configflagutil.ConfigOptions, statusURI string, prowJobClient prowv1.ProwJobInterface, githubClient github.Client, pluginAgent *plugins.ConfigAgent, reporter *Reporter) *Controller {
 	sc := &statusController{
 		logger:     logrus.WithField("client", "statusController"),
 		opener:     opener,
@@ -60,12 +60,14 @@ func NewController(continueOnError bool, addedPresubmitDenylist, addedPresubmitD
 		statusMigrator: &gitHubMigrator{
 			githubClient:    githubClient,
 			continueOnError: continueOnError,
+			reporter:        reporter,
 		},
 		trustedChecker: &githubTrustedChecker{
 			githubClient: githubClient,
 			pluginAgent:  pluginAgent,
 		},
 		statusClient: sc,
+		reporter:     reporter,
 	}
 }
 
@@ -77,20 +79,21 @@ type statusMigrator interface {
 type gitHubMigrator struct {
 	githubClient    github.Client
 	continueOnError bool
+	reporter        *Reporter
 }
 
 func (m *gitHubMigrator) retire(org, repo, context string, targetBranchFilter func(string) bool) error {
 	return migrator.New(
 		*migrator.RetireMode(context, "", ""),
 		m.githubClient, org, repo, targetBranchFilter, m.continueOnError,
-	).Migrate()
+	).WithStatusRecorder(m.reporter.recordStatus(ActionRetire, org, repo)).Migrate()
 }
 
 func (m *gitHubMigrator) migrate(org, repo, from, to string, targetBranchFilter func(string) bool) error {
 	return migrator.New(
 		*migrator.MoveMode(from, to, ""),
 		m.githubClient, org, repo, targetBranchFilter, m.continueOnError,
-	).Migrate()
+	).WithStatusRecorder(m.reporter.recordStatus(ActionMigrate, org, repo)).Migrate()
 }
 
 type prowJobTriggerer interface {
@@ -154,6 +157,7 @@ type Controller struct {
 	statusMigrator            statusMigrator
 	trustedChecker            trustedChecker
 	statusClient              statusClient
+	reporter                  *Reporter
 }
 
 // Run monitors the incoming configuration changes to determine when statuses need to be
@@ -170,9 +174,11 @@ func (c *Controller) Run(ctx context.Context) {
 		case change := <-changes:
 			start := time.Now()
 			log := logrus.WithField("old_config_revision", change.Before.ConfigVersionSHA).WithField("config_revision", change.After.ConfigVersionSHA)
+			c.reporter.begin(change.Before.ConfigVersionSHA, change.After.ConfigVersionSHA)
 			if err := c.reconcile(change, log); err != nil {
 				log.WithError(err).Error("Error reconciling statuses.")
 			}
+			c.reporter.finish()
 			log.WithField("duration", fmt.Sprintf("%v", time.Since(start))).Info("Statuses reconciled")
 			c.statusClient.Save()
 		case <-ctx.Done():
@@ -184,21 +190,29 @@ func (c *Controller) Run(ctx context.Context) {
 
 func (c *Controller) reconcile(delta config.Delta, log *logrus.Entry) error {
 	var errors []error
-	if err := c.triggerNewPresubmits(addedBlockingPresubmits(delta.Before.PresubmitsStatic, delta.After.PresubmitsStatic, log)); err != nil {
+	renamed, _ := renamedBlockingPresubmits(delta.Before.PresubmitsStatic, delta.After.PresubmitsStatic, delta.After.StatusReconciler.ContextMigrations, log)
+
+	added, _ := addedBlockingPresubmits(delta.Before.PresubmitsStatic, delta.After.PresubmitsStatic, log)
+	if err := c.triggerNewPresubmits(withoutRenamed(added, renamed, func(m presubmitMigration) config.Presubmit { return m.to }), log); err != nil {
 		errors = append(errors, err)
 		if !c.continueOnError {
 			return utilerrors.NewAggregate(errors)
 		}
 	}
 
-	if err := c.retireRemovedContexts(removedPresubmits(delta.Before.PresubmitsStatic, delta.After.PresubmitsStatic, log)); err != nil {
+	removed, _ := removedPresubmits(delta.Before.PresubmitsStatic, delta.After.PresubmitsStatic, log)
+	if err := c.retireRemovedContexts(withoutRenamed(removed, renamed, func(m presubmitMigration) config.Presubmit { return m.from }), log); err != nil {
 		errors = append(errors, err)
 		if !c.continueOnError {
 			return utilerrors.NewAggregate(errors)
 		}
 	}
 
-	if err := c.updateMigratedContexts(migratedBlockingPresubmits(delta.Before.PresubmitsStatic, delta.After.PresubmitsStatic, log)); err != nil {
+	migrated, _ := migratedBlockingPresubmits(delta.Before.PresubmitsStatic, delta.After.PresubmitsStatic, log)
+	for repo, migrations := range renamed {
+		migrated[repo] = append(migrated[repo], migrations...)
+	}
+	if err := c.updateMigratedContexts(migrated, log); err != nil {
 		errors = append(errors, err)
 		if !c.continueOnError {
 			return utilerrors.NewAggregate(errors)
@@ -284,7 +298,16 @@ func (c *Controller) triggerIfTrusted(org, repo string, pr github.PullRequest, t
 		"org":        org,
 		"repo":       repo,
 	}).Info("Triggering and skipping new ProwJobs to create newly-required contexts.")
-	return c.prowJobTriggerer.runAndSkip(&pr, toTrigger)
+	err = c.prowJobTriggerer.runAndSkip(&pr, toTrigger)
+	action := Action{Type: ActionTrigger, Org: org, Repo: repo, PR: pr.Number, SHA: pr.Head.SHA}
+	for _, presubmit := range toTrigger {
+		action.Jobs = append(action.Jobs, presubmit.Name)
+	}
+	if err != nil {
+		action.Error = err.Error()
+	}
+	c.reporter.record(action)
+	return err
 }
 
 func (c *Controller) retireRemovedContexts(retiredPresubmits map[string][]config.Presubmit, log *logrus.Entry) error {
@@ -471,3 +494,65 @@ func migratedBlockingPresubmits(old, new map[string][]config.Presubmit, log *log
 	log.Infof("Identified %d migrated blocking presubmits.", numMigrated)
 	return migrated, log
 }
+
+// renamedBlockingPresubmits determines blocking presubmits that replace a
+// presubmit with a different name, where the context of the old presubmit is
+// configured to migrate to the context of the new one. Presubmits that keep
+// their name are handled by migratedBlockingPresubmits.
+func renamedBlockingPresubmits(old, new map[string][]config.Presubmit, contextMigrations map[string]string, log *logrus.Entry) (map[string][]presubmitMigration, *logrus.Entry) {
+	renamed := map[string][]presubmitMigration{}
+
+	for repo, oldPresubmits := range old {
+		oldContexts, newContexts := sets.New[string](), sets.New[string]()
+		for _, oldPresubmit := range oldPresubmits {
+			oldContexts.Insert(oldPresubmit.Context)
+		}
+		for _, newPresubmit := range new[repo] {
+			newContexts.Insert(newPresubmit.Context)
+		}
+		for _, oldPresubmit := range oldPresubmits {
+			to, ok := contextMigrations[oldPresubmit.Context]
+			if !ok || newContexts.Has(oldPresubmit.Context) || oldContexts.Has(to) {
+				continue
+			}
+			for _, newPresubmit := range new[repo] {
+				if newPresubmit.Context != to || newPresubmit.Name == oldPresubmit.Name || !newPresubmit.ContextRequired() {
+					continue
+				}
+				renamed[repo] = append(renamed[repo], presubmitMigration{from: oldPresubmit, to: newPresubmit})
+				log.WithFields(logrus.Fields{
+					"repo": repo,
+					"from": oldPresubmit.Name,
+					"to":   newPresubmit.Name,
+				}).Debug("Identified a renamed blocking presubmit.")
+				break
+			}
+		}
+	}
+
+	var numRenamed int
+	for _, presubmits := range renamed {
+		numRenamed += len(presubmits)
+	}
+	log.Infof("Identified %d renamed blocking presubmits.", numRenamed)
+	return renamed, log
+}
+
+// withoutRenamed removes the presubmits of renamed presubmits, as selected by
+// side, from presubmits.
+func withoutRenamed(presubmits map[string][]config.Presubmit, renamed map[string][]presubmitMigration, side func(presubmitMigration) config.Presubmit) map[string][]config.Presubmit {
+	filtered := map[string][]config.Presubmit{}
+	for repo, repoPresubmits := range presubmits {
+		names := sets.New[string]()
+		for _, migration := range renamed[repo] {
+			names.Insert(side(migration).Name)
+		}
+		filtered[repo] = []config.Presubmit{}
+		for _, presubmit := range repoPresubmits {
+			if !names.Has(presubmit.Name) {
+				filtered[repo] = append(filtered[repo], presubmit)
+			}
+		}
+	}
+	return filtered
+}
@@ -1205,6 +1205,81 @@ func TestControllerReconcile(t *testing.T) {
 	}
 }
 
+func TestControllerReconcileRenamedContexts(t *testing.T) {
+	oldConfigData := `presubmits:
+  "org/repo":
+  - name: required-job
+    context: required-job
+    always_run: true
+  - name: other-required-job
+    context: other-required-job
+    always_run: true`
+	newConfigData := `status_reconciler:
+  context_migrations:
+    required-job: renamed-context
+presubmits:
+  "org/repo":
+  - name: renamed-job
+    context: renamed-context
+    always_run: true
+  - name: new-required-job
+    context: new-required-context
+    always_run: true`
+
+	var oldConfig, newConfig config.Config
+	if err := yaml.Unmarshal([]byte(oldConfigData), &oldConfig); err != nil {
+		t.Fatalf("could not unmarshal old config: %v", err)
+	}
+	if err := yaml.Unmarshal([]byte(newConfigData), &newConfig); err != nil {
+		t.Fatalf("could not unmarshal new config: %v", err)
+	}
+	for _, c := range []config.Config{oldConfig, newConfig} {
+		for _, presubmits := range c.PresubmitsStatic {
+			if err := config.SetPresubmitRegexes(presubmits); err != nil {
+				t.Fatalf("could not set presubmit regexes: %v", err)
+			}
+		}
+	}
+	org, repo := "org", "repo"
+	orgRepoKey := orgRepo{org: org, repo: repo}
+	author := "user"
+	pr := github.PullRequest{
+		User:   github.User{Login: author},
+		Number: 1,
+		Base: github.PullRequestBranch{
+			Repo: github.Repo{Owner: github.User{Login: org}, Name: repo},
+			Ref:  "base",
+		},
+	}
+
+	fpjt := newfakeProwJobTriggerer()
+	fghc := newFakeGitHubClient(orgRepoKey)
+	fghc.prs[orgRepoKey] = []github.PullRequest{pr}
+	fghc.refs[orgRepoKey]["heads/"+pr.Base.Ref] = "abc"
+	fsm := newFakeMigrator(orgRepoKey)
+	ftc := newFakeTrustedChecker(orgRepoKey)
+	ftc.trusted[orgRepoKey][prAuthor{author: author, pr: pr.Number}] = true
+	controller := Controller{
+		continueOnError:        true,
+		addedPresubmitDenylist: sets.New[string](),
+		prowJobTriggerer:       &fpjt,
+		githubClient:           &fghc,
+		statusMigrator:         &fsm,
+		trustedChecker:         &ftc,
+	}
+	if err := controller.reconcile(config.Delta{Before: oldConfig, After: newConfig}, logrusEntry()); err != nil {
+		t.Fatalf("expected no error, but got one: %v", err)
+	}
+
+	// The renamed job is neither triggered nor its old context retired, its
+	// state is moved to the new context instead.
+	checkTriggerer(t, fpjt, map[prKey]sets.Set[string]{{org: org, repo: repo, num: pr.Number}: sets.New[string]("new-required-job")})
+	checkMigrator(t, fsm,
+		map[orgRepo]sets.Set[string]{orgRepoKey: sets.New[string]("other-required-job")},
+		map[orgRepo]migrationSet{orgRepoKey: {migration{from: "required-job", to: "renamed-context"}: nil}},
+	)
+}
+
 func logrusEntry() *logrus.Entry {
 	return logrus.NewEntry(logrus.StandardLogger())
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package migrator
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/golang/glog"
@@ -201,7 +202,7 @@ func (m Mode) processStatuses(combStatus *github.CombinedStatus) []github.Status
 type githubClient interface {
 	GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error)
 	CreateStatus(org, repo, SHA string, s github.Status) error
-	GetPullRequests(org, repo string) ([]github.PullRequest, error)
+	ForEachPullRequest(ctx context.Context, org, repo string, fn func(github.PullRequest) error) error
 }
 
 // Migrator will search github for PRs with a given context and migrate/retire/move them.
@@ -215,6 +216,9 @@ type Migrator struct {
 
 	client githubClient
 	Mode
+
+	// recordStatus is called with every status that is created on a PR.
+	recordStatus func(pr github.PullRequest, status github.Status, err error)
 }
 
 // New creates a new migrator with specified options and client.
@@ -229,6 +233,13 @@ func New(mode Mode, client github.Client, org, repo string, targetBranchFilter f
 	}
 }
 
+// WithStatusRecorder sets a function that is called with every status the
+// migrator creates on a PR and the error creating it, if any.
+func (m *Migrator) WithStatusRecorder(record func(pr github.PullRequest, status github.Status, err error)) *Migrator {
+	m.recordStatus = record
+	return m
+}
+
 func (m *Migrator) processPR(pr github.PullRequest) error {
 	if !m.targetBranchFilter(pr.Base.Ref) {
 		return nil
@@ -241,7 +252,11 @@ func (m *Migrator) processPR(pr github.PullRequest) error {
 	actions := m.processStatuses(combined)
 
 	for _, action := range actions {
-		if err := m.client.CreateStatus(m.org, m.repo, pr.Head.SHA, action); err != nil {
+		err := m.client.CreateStatus(m.org, m.repo, pr.Head.SHA, action)
+		if m.recordStatus != nil {
+			m.recordStatus(pr, action, err)
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -250,20 +265,19 @@ func (m *Migrator) processPR(pr github.PullRequest) error {
 
 // Migrate will retire/migrate/copy statuses for all matching PRs.
 func (m *Migrator) Migrate() error {
-	prs, err := m.client.GetPullRequests(m.org, m.repo)
-	if err != nil {
-		return err
-	}
-
 	var errors []error
-	for _, pr := range prs {
+	err := m.client.ForEachPullRequest(context.Background(), m.org, m.repo, func(pr github.PullRequest) error {
 		if err := m.processPR(pr); err != nil {
 			if m.continueOnError {
 				errors = append(errors, err)
-				continue
+				return nil
 			}
 			return err
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	return utilerrors.NewAggregate(errors)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package migrator
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -389,8 +390,8 @@ func (c *fakeGitHubClient) CreateStatus(org, repo, SHA string, s github.Status)
 	return nil
 }
 
-func (c *fakeGitHubClient) GetPullRequests(org, repo string) ([]github.PullRequest, error) {
-	return []github.PullRequest{}, nil
+func (c *fakeGitHubClient) ForEachPullRequest(ctx context.Context, org, repo string, fn func(github.PullRequest) error) error {
+	return nil
 }
 
 func TestProcessPR(t *testing.T) {
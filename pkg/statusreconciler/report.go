@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusreconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/io"
+)
+
+// maxReportSummaries is how many report summaries the Reporter serves.
+const maxReportSummaries = 100
+
+// ActionType is the kind of action status-reconciler takes on a PR.
+type ActionType string
+
+const (
+	// ActionTrigger triggers presubmits that were added.
+	ActionTrigger ActionType = "trigger"
+	// ActionRetire retires the context of a removed presubmit.
+	ActionRetire ActionType = "retire"
+	// ActionMigrate moves the status of a migrated context to its new name.
+	ActionMigrate ActionType = "migrate"
+)
+
+// Action is a status or retrigger action on a PR. In dry-run mode it is only
+// planned.
+type Action struct {
+	Type ActionType `json:"type"`
+	Org  string     `json:"org"`
+	Repo string     `json:"repo"`
+	PR   int        `json:"pr"`
+	SHA  string     `json:"sha,omitempty"`
+	// Jobs are the presubmits that were triggered.
+	Jobs []string `json:"jobs,omitempty"`
+	// Context, State and Description describe the status that was created.
+	Context     string `json:"context,omitempty"`
+	State       string `json:"state,omitempty"`
+	Description string `json:"description,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Report records the actions of reconciling one config change.
+type Report struct {
+	OldConfigRevision string    `json:"old_config_revision"`
+	ConfigRevision    string    `json:"config_revision"`
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+	DryRun            bool      `json:"dry_run"`
+	Actions           []Action  `json:"actions"`
+}
+
+// ReportSummary summarizes a Report.
+type ReportSummary struct {
+	OldConfigRevision string             `json:"old_config_revision"`
+	ConfigRevision    string             `json:"config_revision"`
+	Start             time.Time          `json:"start"`
+	End               time.Time          `json:"end"`
+	DryRun            bool               `json:"dry_run"`
+	PullRequests      int                `json:"pull_requests"`
+	Actions           map[ActionType]int `json:"actions"`
+	Errors            int                `json:"errors"`
+	// Path is where the full report was written, if anywhere.
+	Path string `json:"path,omitempty"`
+}
+
+func (r *Report) summary(path string) ReportSummary {
+	summary := ReportSummary{
+		OldConfigRevision: r.OldConfigRevision,
+		ConfigRevision:    r.ConfigRevision,
+		Start:             r.Start,
+		End:               r.End,
+		DryRun:            r.DryRun,
+		Actions:           map[ActionType]int{},
+		Path:              path,
+	}
+	prs := map[string]bool{}
+	for _, action := range r.Actions {
+		prs[fmt.Sprintf("%s/%s#%d", action.Org, action.Repo, action.PR)] = true
+		summary.Actions[action.Type]++
+		if action.Error != "" {
+			summary.Errors++
+		}
+	}
+	summary.PullRequests = len(prs)
+	return summary
+}
+
+// Reporter records the actions of status-reconciler. Each reconciliation is
+// written as a JSON report under the report URI, and summaries of recent
+// reports are served over HTTP. A nil Reporter records nothing.
+type Reporter struct {
+	opener    opener
+	reportURI string
+	dryRun    bool
+	logger    *logrus.Entry
+
+	lock      sync.Mutex
+	current   *Report
+	summaries []ReportSummary
+}
+
+// NewReporter creates a Reporter that writes reports under reportURI, which
+// can be /local/path, gs://path/to/dir or s3://path/to/dir. If reportURI is
+// empty, reports are only summarized.
+func NewReporter(opener opener, reportURI string, dryRun bool) *Reporter {
+	return &Reporter{
+		opener:    opener,
+		reportURI: strings.TrimSuffix(reportURI, "/"),
+		dryRun:    dryRun,
+		logger:    logrus.WithField("client", "reporter"),
+	}
+}
+
+func (r *Reporter) begin(oldRevision, revision string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.current = &Report{
+		OldConfigRevision: oldRevision,
+		ConfigRevision:    revision,
+		Start:             time.Now(),
+		DryRun:            r.dryRun,
+		Actions:           []Action{},
+	}
+}
+
+func (r *Reporter) record(action Action) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.current != nil {
+		r.current.Actions = append(r.current.Actions, action)
+	}
+}
+
+// recordStatus returns a function that records the statuses a migrator
+// creates as actions of the given type.
+func (r *Reporter) recordStatus(actionType ActionType, org, repo string) func(github.PullRequest, github.Status, error) {
+	return func(pr github.PullRequest, status github.Status, err error) {
+		action := Action{
+			Type:        actionType,
+			Org:         org,
+			Repo:        repo,
+			PR:          pr.Number,
+			SHA:         pr.Head.SHA,
+			Context:     status.Context,
+			State:       status.State,
+			Description: status.Description,
+		}
+		if err != nil {
+			action.Error = err.Error()
+		}
+		r.record(action)
+	}
+}
+
+// finish writes the current report and keeps its summary.
+func (r *Reporter) finish() {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	report := r.current
+	r.current = nil
+	r.lock.Unlock()
+	if report == nil {
+		return
+	}
+	report.End = time.Now()
+
+	var path string
+	if r.reportURI != "" {
+		path = fmt.Sprintf("%s/%s-%s.json", r.reportURI, report.Start.UTC().Format("20060102T150405Z"), report.ConfigRevision)
+		if err := r.write(path, report); err != nil {
+			r.logger.WithError(err).WithField("path", path).Warn("Cannot write report")
+			path = ""
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.summaries = append(r.summaries, report.summary(path))
+	if len(r.summaries) > maxReportSummaries {
+		r.summaries = r.summaries[len(r.summaries)-maxReportSummaries:]
+	}
+}
+
+func (r *Reporter) write(path string, report *Report) error {
+	buf, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	writer, err := r.opener.Writer(ctx, path)
+	if err != nil {
+		return fmt.Errorf("open report writer: %w", err)
+	}
+	if _, err := writer.Write(buf); err != nil {
+		io.LogClose(writer)
+		return fmt.Errorf("write report: %w", err)
+	}
+	return writer.Close()
+}
+
+// ServeHTTP serves the summaries of recent reports as JSON, most recent first.
+func (r *Reporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.lock.Lock()
+	summaries := make([]ReportSummary, 0, len(r.summaries))
+	for i := len(r.summaries) - 1; i >= 0; i-- {
+		summaries = append(summaries, r.summaries[i])
+	}
+	r.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summaries); err != nil {
+		r.logger.WithError(err).Warn("Cannot serve report summaries")
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusreconciler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+func TestReporter(t *testing.T) {
+	reporter := NewReporter(&testOpener{}, t.TempDir()+"/", true)
+	reporter.begin("old", "new")
+	reporter.record(Action{Type: ActionTrigger, Org: "org", Repo: "repo", PR: 1, Jobs: []string{"job"}})
+	record := reporter.recordStatus(ActionRetire, "org", "repo")
+	record(github.PullRequest{Number: 1}, github.Status{Context: "old-job", State: github.StatusSuccess}, nil)
+	record(github.PullRequest{Number: 2}, github.Status{Context: "old-job", State: github.StatusSuccess}, errors.New("injected"))
+	reporter.finish()
+
+	recorder := httptest.NewRecorder()
+	reporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/reports", nil))
+	var summaries []ReportSummary
+	if err := json.Unmarshal(recorder.Body.Bytes(), &summaries); err != nil {
+		t.Fatalf("failed to unmarshal summaries: %v", err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("expected one summary, got %d", len(summaries))
+	}
+	summary := summaries[0]
+	if summary.ConfigRevision != "new" || !summary.DryRun || summary.PullRequests != 2 || summary.Errors != 1 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if diff := cmp.Diff(map[ActionType]int{ActionTrigger: 1, ActionRetire: 2}, summary.Actions); diff != "" {
+		t.Errorf("unexpected action counts: %s", diff)
+	}
+
+	buf, err := os.ReadFile(summary.Path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(buf, &report); err != nil {
+		t.Fatalf("failed to unmarshal report: %v", err)
+	}
+	if len(report.Actions) != 3 || report.Actions[2].Error != "injected" {
+		t.Errorf("unexpected report actions %+v", report.Actions)
+	}
+}
+
+func TestNilReporter(t *testing.T) {
+	var reporter *Reporter
+	reporter.begin("old", "new")
+	reporter.record(Action{Type: ActionTrigger})
+	reporter.recordStatus(ActionRetire, "org", "repo")(github.PullRequest{}, github.Status{}, nil)
+	reporter.finish()
+}
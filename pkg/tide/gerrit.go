@@ -36,6 +36,7 @@ import (
 	"sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/io"
 	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/logrusutil"
 	"sigs.k8s.io/prow/pkg/moonraker"
 	"sigs.k8s.io/prow/pkg/tide/blockers"
 	"sigs.k8s.io/prow/pkg/tide/history"
@@ -91,6 +92,7 @@ type gerritClient interface {
 	GetBranchRevision(instance, project, branch string) (string, error)
 	SubmitChange(instance, id string, wait bool) (*gerrit.ChangeInfo, error)
 	SetReview(instance, id, revision, message string, _ map[string]string) error
+	QueryTopicChanges(instance, topic string) ([]gerrit.ChangeInfo, error)
 }
 
 // NewController makes a Controller out of the given clients.
@@ -106,6 +108,7 @@ func NewGerritController(
 	configOptions configflagutil.ConfigOptions,
 	cookieFilePath string,
 	maxQPS, maxBurst int,
+	opts ...client.Option,
 ) (*Controller, error) {
 	if logger == nil {
 		logger = logrus.NewEntry(logrus.StandardLogger())
@@ -123,8 +126,8 @@ func NewGerritController(
 	}
 
 	var ircg config.InRepoConfigGetter
-	if configOptions.MoonrakerAddress != "" {
-		moonrakerClient, err := moonraker.NewClient(configOptions.MoonrakerAddress, cfgAgent)
+	if configOptions.MoonrakerEnabled() {
+		moonrakerClient, err := moonraker.NewInRepoConfigGetter(configOptions.MoonrakerAddress, configOptions.MoonrakerGRPCAddress, cfgAgent)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error getting Moonraker client.")
 		}
@@ -137,7 +140,7 @@ func NewGerritController(
 		}
 	}
 
-	provider := newGerritProvider(logger, cfgAgent.Config, mgr.GetClient(), ircg, cookieFilePath, "", maxQPS, maxBurst)
+	provider := newGerritProvider(logger, cfgAgent.Config, mgr.GetClient(), ircg, cookieFilePath, "", maxQPS, maxBurst, opts...)
 	syncCtrl, err := newSyncController(ctx, logger, mgr, provider, cfgAgent.Config, gc, hist, false, statusUpdate)
 	if err != nil {
 		return nil, err
@@ -172,11 +175,17 @@ func newGerritProvider(
 	cookiefilePath string,
 	tokenPathOverride string,
 	maxQPS, maxBurst int,
+	opts ...client.Option,
 ) *GerritProvider {
-	gerritClient, err := client.NewClient(nil, maxQPS, maxBurst)
+	gerritClient, err := client.NewClient(nil, maxQPS, maxBurst, opts...)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error creating gerrit client.")
 	}
+	// Repos can fetch their inrepoconfig with the gerrit client rather than
+	// being cloned.
+	if ircc, ok := ircg.(*config.InRepoConfigCache); ok {
+		ircc.SetFileGetter(gerritClient.InRepoConfigFile)
+	}
 	orgRepoConfigGetter := func() *config.GerritOrgRepoConfigs {
 		return &cfg().Tide.Gerrit.Queries
 	}
@@ -219,7 +228,7 @@ func (p *GerritProvider) Query() (map[string]CodeReviewCommon, error) {
 			go func(projName string, optInByDefault bool) {
 				changes, err := p.gc.QueryChangesForProject(instance, projName, lastUpdate, p.cfg().Gerrit.RateLimit, gerritQueryParam(optInByDefault))
 				if err != nil {
-					p.logger.WithFields(logrus.Fields{"instance": instance, "project": projName}).WithError(err).Warn("Querying gerrit project for changes.")
+					p.logger.WithFields(logrus.Fields{logrusutil.OrgField: instance, logrusutil.RepoField: projName}).WithError(err).Warn("Querying gerrit project for changes.")
 					errChan <- fmt.Errorf("failed querying project '%s' from instance '%s': %v", projName, instance, err)
 					return
 				}
@@ -252,9 +261,82 @@ func (p *GerritProvider) Query() (map[string]CodeReviewCommon, error) {
 	if len(combinedErrs) > 0 && len(res) == 0 {
 		return nil, utilerrors.NewAggregate(combinedErrs)
 	}
+	p.excludeUnverifiedTopics(res)
 	return res, nil
 }
 
+// excludeUnverifiedTopics removes the changes that are tested with the other
+// changes of their topic from the pool, unless the whole topic verified: all
+// open changes of the topic are in the pool, and the latest presubmits of each
+// of them tested the current revisions of the others.
+func (p *GerritProvider) excludeUnverifiedTopics(res map[string]CodeReviewCommon) {
+	verified := map[string]bool{}
+	for key, crc := range res {
+		if crc.Gerrit == nil || crc.Gerrit.Topic == "" || !p.cfg().Gerrit.OrgReposConfig.TopicTesting(crc.Org, crc.Repo) {
+			continue
+		}
+		topic := crc.Org + "/" + crc.Gerrit.Topic
+		ok, seen := verified[topic]
+		if !seen {
+			ok = p.topicVerified(crc.Org, crc.Gerrit.Topic, res)
+			verified[topic] = ok
+		}
+		if !ok {
+			delete(res, key)
+		}
+	}
+}
+
+func (p *GerritProvider) topicVerified(instance, topic string, res map[string]CodeReviewCommon) bool {
+	logger := p.logger.WithFields(logrus.Fields{logrusutil.OrgField: instance, "topic": topic})
+	changes, err := p.gc.QueryTopicChanges(instance, topic)
+	if err != nil {
+		logger.WithError(err).Warn("Failed querying the changes of the topic.")
+		return false
+	}
+	for _, change := range changes {
+		logger := logger.WithFields(logrus.Fields{logrusutil.RepoField: change.Project, logrusutil.PRField: change.Number})
+		crc, ok := res[prKey(CodeReviewCommonFromGerrit(&change, instance))]
+		if !ok {
+			logger.Info("Not merging the topic until all of its changes are ready.")
+			return false
+		}
+		if !p.cfg().Gerrit.OrgReposConfig.TopicTesting(instance, change.Project) {
+			continue
+		}
+		pjs, err := p.latestPresubmits(&crc)
+		if err != nil {
+			logger.WithError(err).Warn("Failed listing the presubmits of the change.")
+			return false
+		}
+		for _, other := range gerritadaptor.TopicChanges(change, changes) {
+			for _, pj := range pjs {
+				if !testedRevision(pj, instance, other) {
+					logger.WithFields(logrus.Fields{"job": pj.Spec.Job, "untested": other.Number}).Info("Not merging the topic until the presubmits of its changes tested the current revisions of the others.")
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// testedRevision returns whether a job checked out the current revision of a
+// change as an extra ref.
+func testedRevision(pj *prowapi.ProwJob, instance string, change gerrit.ChangeInfo) bool {
+	for _, refs := range pj.Spec.ExtraRefs {
+		if refs.Org != instance || refs.Repo != change.Project {
+			continue
+		}
+		for _, pull := range refs.Pulls {
+			if pull.SHA == change.CurrentRevision {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (p *GerritProvider) blockers() (blockers.Blockers, error) {
 	// This is not supported yet, so return an empty blocker for now.
 	return blockers.Blockers{}, nil
@@ -285,6 +367,24 @@ func (p *GerritProvider) GetRef(org, repo, ref string) (string, error) {
 func (p *GerritProvider) headContexts(crc *CodeReviewCommon) ([]Context, error) {
 	var res []Context
 
+	latestPjs, err := p.latestPresubmits(crc)
+	if err != nil {
+		return nil, err
+	}
+	for _, pj := range latestPjs {
+		res = append(res, Context{
+			Context:     githubql.String(pj.Spec.Context),
+			Description: githubql.String(config.ContextDescriptionWithBaseSha(pj.Status.Description, pj.Spec.Refs.BaseSHA)),
+			State:       githubql.StatusState(pj.Status.State),
+		})
+	}
+
+	return res, nil
+}
+
+// latestPresubmits returns the latest presubmit of each context for the
+// current revision of a change.
+func (p *GerritProvider) latestPresubmits(crc *CodeReviewCommon) (map[string]*prowapi.ProwJob, error) {
 	selector := map[string]string{
 		kube.GerritRevision:   crc.HeadRefOID,
 		kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
@@ -306,16 +406,7 @@ func (p *GerritProvider) headContexts(crc *CodeReviewCommon) ([]Context, error)
 		}
 		latestPjs[pj.Spec.Context] = &pj
 	}
-
-	for _, pj := range latestPjs {
-		res = append(res, Context{
-			Context:     githubql.String(pj.Spec.Context),
-			Description: githubql.String(config.ContextDescriptionWithBaseSha(pj.Status.Description, pj.Spec.Refs.BaseSHA)),
-			State:       githubql.StatusState(pj.Status.State),
-		})
-	}
-
-	return res, nil
+	return latestPjs, nil
 }
 
 func (p *GerritProvider) mergePRs(sp subpool, prs []CodeReviewCommon, _ *threadSafePRSet) ([]CodeReviewCommon, error) {
@@ -330,6 +421,13 @@ func (p *GerritProvider) mergePRs(sp subpool, prs []CodeReviewCommon, _ *threadS
 		logger := logger.WithField("id", pr.Gerrit.ID)
 		logger.Info("Submitting change.")
 		_, err := p.gc.SubmitChange(sp.org, pr.Gerrit.ID, true)
+		if err != nil && pr.Gerrit.Topic != "" {
+			// Gerrit submits the whole topic with its first change if
+			// change.submitWholeTopic is enabled.
+			if change, getErr := p.gc.GetChange(sp.org, pr.Gerrit.ID); getErr == nil && change.Status == client.Merged {
+				err = nil
+			}
+		}
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed submitting change '%s' from org '%s': %v", sp.org, pr.Gerrit.ID, err))
 		} else {
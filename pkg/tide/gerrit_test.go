@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"testing"
 	"time"
@@ -44,6 +45,8 @@ var _ gerritClient = (*fakeGerritClient)(nil)
 type fakeGerritClient struct {
 	// map{org: map{project: []changes}}
 	changes map[string]map[string][]gerrit.ChangeInfo
+	// map{id: error}
+	submitErrs map[string]error
 }
 
 func newFakeGerritClient() *fakeGerritClient {
@@ -79,9 +82,27 @@ func (f *fakeGerritClient) GetBranchRevision(instance, project, branch string) (
 }
 
 func (f *fakeGerritClient) SubmitChange(instance, id string, wait bool) (*gerrit.ChangeInfo, error) {
+	if err, ok := f.submitErrs[id]; ok {
+		return nil, err
+	}
 	return f.GetChange(instance, id)
 }
 
+func (f *fakeGerritClient) QueryTopicChanges(instance, topic string) ([]gerrit.ChangeInfo, error) {
+	if f.changes == nil || f.changes[instance] == nil {
+		return nil, errors.New("instance not exist")
+	}
+	var res []gerrit.ChangeInfo
+	for _, prs := range f.changes[instance] {
+		for _, pr := range prs {
+			if pr.Topic == topic {
+				res = append(res, pr)
+			}
+		}
+	}
+	return res, nil
+}
+
 func (f *fakeGerritClient) SetReview(instance, id, revision, message string, _ map[string]string) error {
 	change, err := f.GetChange(instance, id)
 	if err != nil {
@@ -311,6 +332,153 @@ func TestQuery(t *testing.T) {
 	}
 }
 
+func TestQueryTopics(t *testing.T) {
+	change := func(number int, project, revision string) gerrit.ChangeInfo {
+		return gerrit.ChangeInfo{Number: number, Project: project, Topic: "feature", CurrentRevision: revision}
+	}
+	presubmit := func(number int, project, revision string, extraRefs ...prowapi.Refs) prowapi.ProwJob {
+		return prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", project, number),
+				Namespace: "prowjobs",
+				Labels: map[string]string{
+					kube.GerritRevision:   revision,
+					kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+					kube.OrgLabel:         "foo",
+					kube.RepoLabel:        project,
+					kube.PullLabel:        strconv.Itoa(number),
+				},
+			},
+			Spec: prowapi.ProwJobSpec{
+				Type:      prowapi.PresubmitJob,
+				Job:       "job",
+				Context:   "job",
+				ExtraRefs: extraRefs,
+			},
+		}
+	}
+	tested := func(project, revision string) prowapi.Refs {
+		return prowapi.Refs{Org: "foo", Repo: project, Pulls: []prowapi.Pull{{SHA: revision}}}
+	}
+
+	tests := []struct {
+		name         string
+		prs          map[string][]gerrit.ChangeInfo
+		notReady     []gerrit.ChangeInfo
+		jobs         []prowapi.ProwJob
+		topicTesting []string
+		expect       []string
+	}{
+		{
+			name: "whole topic verified",
+			prs: map[string][]gerrit.ChangeInfo{
+				"bar1": {change(1, "bar1", "a")},
+				"bar2": {change(2, "bar2", "b")},
+			},
+			jobs: []prowapi.ProwJob{
+				presubmit(1, "bar1", "a", tested("bar2", "b")),
+				presubmit(2, "bar2", "b", tested("bar1", "a")),
+			},
+			topicTesting: []string{"bar1", "bar2"},
+			expect:       []string{"foo/bar1#1", "foo/bar2#2"},
+		},
+		{
+			name: "change of the topic not ready",
+			prs: map[string][]gerrit.ChangeInfo{
+				"bar1": {change(1, "bar1", "a"), {Number: 3, Project: "bar1"}},
+				"bar2": {},
+			},
+			notReady: []gerrit.ChangeInfo{change(2, "bar2", "b")},
+			jobs: []prowapi.ProwJob{
+				presubmit(1, "bar1", "a", tested("bar2", "b")),
+			},
+			topicTesting: []string{"bar1", "bar2"},
+			expect:       []string{"foo/bar1#3"},
+		},
+		{
+			name: "presubmit tested an old revision",
+			prs: map[string][]gerrit.ChangeInfo{
+				"bar1": {change(1, "bar1", "a")},
+				"bar2": {change(2, "bar2", "b")},
+			},
+			jobs: []prowapi.ProwJob{
+				presubmit(1, "bar1", "a", tested("bar2", "old")),
+				presubmit(2, "bar2", "b", tested("bar1", "a")),
+			},
+			topicTesting: []string{"bar1", "bar2"},
+		},
+		{
+			name: "topic testing not enabled",
+			prs: map[string][]gerrit.ChangeInfo{
+				"bar1": {change(1, "bar1", "a")},
+				"bar2": {},
+			},
+			notReady: []gerrit.ChangeInfo{change(2, "bar2", "b")},
+			expect:   []string{"foo/bar1#1"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.Config{
+				ProwConfig: config.ProwConfig{
+					Tide: config.Tide{
+						Gerrit: &config.TideGerritConfig{
+							Queries: config.GerritOrgRepoConfigs{{Org: "foo", Repos: []string{"bar1", "bar2"}}},
+						},
+					},
+					Gerrit: config.Gerrit{
+						OrgReposConfig: &config.GerritOrgRepoConfigs{{Org: "foo", Repos: tc.topicTesting, TopicTesting: true}},
+					},
+				},
+			}
+			var jobs []runtime.Object
+			for _, job := range tc.jobs {
+				job := job
+				jobs = append(jobs, &job)
+			}
+
+			fc := newGerritProvider(logrus.WithContext(context.Background()), func() *config.Config { return &cfg }, fakectrlruntimeclient.NewFakeClient(jobs...), nil, "", "", 0, 0)
+			fgc := newFakeGerritClient()
+			for project, changes := range tc.prs {
+				fgc.addChanges("foo", project, changes)
+			}
+			fc.gc = &notReadyGerritClient{fakeGerritClient: fgc, notReady: tc.notReady}
+
+			got, err := fc.Query()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var gotKeys []string
+			for key := range got {
+				gotKeys = append(gotKeys, key)
+			}
+			sort.Strings(gotKeys)
+			if diff := cmp.Diff(tc.expect, gotKeys); diff != "" {
+				t.Errorf("Query result mismatch. Want(-), got(+):\n%s", diff)
+			}
+		})
+	}
+}
+
+// notReadyGerritClient has changes of topics that are open, but not returned by
+// the queries of Tide.
+type notReadyGerritClient struct {
+	*fakeGerritClient
+	notReady []gerrit.ChangeInfo
+}
+
+func (f *notReadyGerritClient) QueryTopicChanges(instance, topic string) ([]gerrit.ChangeInfo, error) {
+	res, err := f.fakeGerritClient.QueryTopicChanges(instance, topic)
+	for _, change := range f.notReady {
+		if change.Topic == topic {
+			res = append(res, change)
+		}
+	}
+	return res, err
+}
+
 func TestBlocker(t *testing.T) {
 	fc := &GerritProvider{}
 	want := blockers.Blockers{}
@@ -603,6 +771,7 @@ func TestMergePR(t *testing.T) {
 		name          string
 		subpool       subpool
 		clientChanges map[string]map[string][]gerrit.ChangeInfo
+		submitErrs    map[string]error
 		prs           []gerrit.ChangeInfo
 		wantErr       error
 	}{
@@ -714,6 +883,32 @@ func TestMergePR(t *testing.T) {
 			},
 			wantErr: errors.New("failed submitting change 'org' from org 'def456': change not exist"),
 		},
+		{
+			name: "submitted-with-topic",
+			subpool: subpool{
+				org:  "org",
+				repo: "repo",
+			},
+			clientChanges: map[string]map[string][]gerrit.ChangeInfo{
+				"org": {
+					"repo": {
+						{
+							ID:     "abc123",
+							Topic:  "feature",
+							Status: "MERGED",
+						},
+					},
+				},
+			},
+			submitErrs: map[string]error{"abc123": errors.New("change is merged")},
+			prs: []gerrit.ChangeInfo{
+				{
+					ID:    "abc123",
+					Topic: "feature",
+				},
+			},
+			wantErr: nil,
+		},
 	}
 
 	for _, tc := range tests {
@@ -721,6 +916,7 @@ func TestMergePR(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			fgc := newFakeGerritClient()
 			fgc.changes = tc.clientChanges
+			fgc.submitErrs = tc.submitErrs
 			cfg := config.Config{
 				ProwConfig: config.ProwConfig{
 					Gerrit: config.Gerrit{
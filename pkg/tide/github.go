@@ -22,13 +22,14 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/flakiness"
 	"sigs.k8s.io/prow/pkg/git/types"
 	"sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/github"
@@ -56,6 +57,12 @@ type GitHubProvider struct {
 	ghc                githubClient
 	gc                 git.ClientFactory
 	usesGitHubAppsAuth bool
+	// quarantine tells which presubmits are quarantined for being flaky.
+	// Tide does not require them to pass.
+	quarantine *flakiness.ReportCache
+	// inRepoConfigGetter gets the presubmits from Moonraker. If it is nil,
+	// Tide clones the repositories itself.
+	inRepoConfigGetter config.InRepoConfigGetter
 
 	*mergeChecker
 	logger *logrus.Entry
@@ -152,7 +159,23 @@ func (gi *GitHubProvider) GetRef(org, repo, ref string) (string, error) {
 }
 
 func (gi *GitHubProvider) GetTideContextPolicy(org, repo, branch string, baseSHAGetter config.RefGetter, pr *CodeReviewCommon) (contextChecker, error) {
-	return gi.cfg().GetTideContextPolicy(gi.gc, org, repo, branch, baseSHAGetter, pr.HeadRefOID)
+	contextPolicy, err := gi.cfg().GetTideContextPolicy(gi.gc, org, repo, branch, baseSHAGetter, pr.HeadRefOID)
+	if err != nil {
+		return nil, err
+	}
+	quarantineContexts(contextPolicy, gi.quarantine.QuarantinedContexts(org, repo))
+	return contextPolicy, nil
+}
+
+// quarantineContexts makes the contexts of quarantined presubmits optional.
+func quarantineContexts(contextPolicy *config.TideContextPolicy, quarantined []string) {
+	if len(quarantined) == 0 {
+		return
+	}
+	contexts := sets.New[string](quarantined...)
+	contextPolicy.RequiredContexts = sets.List(sets.New[string](contextPolicy.RequiredContexts...).Difference(contexts))
+	contextPolicy.RequiredIfPresentContexts = sets.List(sets.New[string](contextPolicy.RequiredIfPresentContexts...).Difference(contexts))
+	contextPolicy.OptionalContexts = sets.List(sets.New[string](contextPolicy.OptionalContexts...).Union(contexts))
 }
 
 func (gi *GitHubProvider) prMergeMethod(crc *CodeReviewCommon) *types.PullRequestMergeType {
@@ -174,36 +197,25 @@ func (gi *GitHubProvider) search(query querier, log *logrus.Entry, q string, sta
 		"searchCursor": cursor,
 	}
 
-	var totalCost, remaining int
 	var ret []PullRequest
-	var sq searchQuery
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
-	for {
-		log.Debug("Sending query")
-		if err := query(ctx, &sq, vars, org); err != nil {
-			if cursor != nil {
-				err = fmt.Errorf("cursor: %q, err: %w", *cursor, err)
-			}
-			return ret, err
-		}
-		totalCost += int(sq.RateLimit.Cost)
-		remaining = int(sq.RateLimit.Remaining)
+	log.Debug("Sending query")
+	stats, err := github.QueryAllPages(ctx, github.GraphQLQuerierFunc(query), org, "tide_search", func() *searchQuery { return &searchQuery{} }, vars, "searchCursor", func(sq *searchQuery) error {
 		for _, n := range sq.Search.Nodes {
 			ret = append(ret, n.PullRequest)
 		}
-		if !sq.Search.PageInfo.HasNextPage {
-			break
-		}
-		cursor = &sq.Search.PageInfo.EndCursor
-		vars["searchCursor"] = cursor
-		log = log.WithField("searchCursor", *cursor)
+		return nil
+	})
+	if err != nil {
+		return ret, err
 	}
 	log.WithFields(logrus.Fields{
 		"duration":       time.Since(requestStart).String(),
 		"pr_found_count": len(ret),
-		"cost":           totalCost,
-		"remaining":      remaining,
+		"pages":          stats.Pages,
+		"cost":           stats.Cost,
+		"remaining":      stats.Remaining,
 	}).Debug("Finished query")
 	return ret, nil
 }
@@ -325,38 +337,32 @@ func (gi *GitHubProvider) headContexts(pr *CodeReviewCommon) ([]Context, error)
 		}
 	}
 	// We didn't get the head commit from the query (the commits must not be
-	// logically ordered) so we need to specifically ask GitHub for the status
-	// and coerce it to a graphql type.
+	// logically ordered) so we need to specifically ask GitHub for the status.
 	org := pr.Org
 	repo := pr.Repo
 	// Log this event so we can tune the number of commits we list to minimize this.
-	// TODO alvaroaleman: Add checkrun support here. Doesn't seem to happen often though,
-	// openshift doesn't have a single occurrence of this in the past seven days.
 	log.Warnf("'last' %d commits didn't contain logical last commit. Querying GitHub...", len(commits.Nodes))
-	combined, err := gi.ghc.GetCombinedStatus(org, repo, pr.HeadRefOID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get the combined status: %w", err)
-	}
-	checkRunList, err := gi.ghc.ListCheckRuns(org, repo, pr.HeadRefOID)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	commitContexts, err := github.GetCommitContexts(ctx, gi.ghc, org, repo, pr.HeadRefOID)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to list checkruns: %w", err)
+		return nil, fmt.Errorf("failed to get the head contexts: %w", err)
 	}
-	checkRunNodes := make([]CheckRunNode, 0, len(checkRunList.CheckRuns))
-	for _, checkRun := range checkRunList.CheckRuns {
+	checkRunNodes := make([]CheckRunNode, 0, len(commitContexts.CheckRuns))
+	for _, checkRun := range commitContexts.CheckRuns {
 		checkRunNodes = append(checkRunNodes, CheckRunNode{CheckRun: CheckRun{
-			Name: githubql.String(checkRun.Name),
-			// They are uppercase in the V4 api and lowercase in the V3 api
-			Conclusion: githubql.String(strings.ToUpper(checkRun.Conclusion)),
-			Status:     githubql.String(strings.ToUpper(checkRun.Status)),
+			Name:       checkRun.Name,
+			Conclusion: checkRun.Conclusion,
+			Status:     checkRun.Status,
 		}})
 	}
 
-	contexts := make([]Context, 0, len(combined.Statuses)+len(checkRunNodes))
-	for _, status := range combined.Statuses {
+	contexts := make([]Context, 0, len(commitContexts.Statuses)+len(checkRunNodes))
+	for _, status := range commitContexts.Statuses {
 		contexts = append(contexts, Context{
-			Context:     githubql.String(status.Context),
-			Description: githubql.String(status.Description),
-			State:       githubql.StatusState(strings.ToUpper(status.State)),
+			Context:     status.Context,
+			Description: status.Description,
+			State:       status.State,
 		})
 	}
 	contexts = append(contexts, checkRunNodesToContexts(log, checkRunNodes)...)
@@ -376,6 +382,9 @@ func (gi *GitHubProvider) headContexts(pr *CodeReviewCommon) ([]Context, error)
 }
 
 func (gi *GitHubProvider) GetPresubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
+	if gi.inRepoConfigGetter != nil {
+		return gi.inRepoConfigGetter.GetPresubmits(identifier, baseBranch, baseSHAGetter, headSHAGetters...)
+	}
 	return gi.cfg().GetPresubmits(gi.gc, identifier, baseBranch, baseSHAGetter, headSHAGetters...)
 }
 
@@ -419,7 +428,7 @@ func (gi *GitHubProvider) labelsAndAnnotations(instance string, jobLabels, jobAn
 }
 
 func (gi *GitHubProvider) jobIsRequiredByTide(ps *config.Presubmit, pr *CodeReviewCommon) bool {
-	return ps.ContextRequired() || ps.RunBeforeMerge
+	return (ps.ContextRequired() || ps.RunBeforeMerge) && !gi.quarantine.IsQuarantined(pr.Org, pr.Repo, ps.Name)
 }
 
 // dateToken generates a GitHub search query token for the specified date range.
@@ -28,8 +28,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/flakiness"
 	"sigs.k8s.io/prow/pkg/git/types"
 	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/io/fakeopener"
 )
 
 func TestSearch(t *testing.T) {
@@ -370,3 +372,75 @@ func TestHeadContexts(t *testing.T) {
 		})
 	}
 }
+
+func TestQuarantinedPresubmits(t *testing.T) {
+	cfg := &config.Config{ProwConfig: config.ProwConfig{Flakiness: config.Flakiness{ReportPath: "gs://bucket/flakiness.json"}}}
+	cfgGetter := func() *config.Config { return cfg }
+	opener := &fakeopener.FakeOpener{}
+	report := &flakiness.Report{Jobs: []flakiness.JobFlakiness{
+		{Org: "org", Repo: "repo", Job: "pull-flaky", Context: "flaky", Quarantined: true},
+		{Org: "org", Repo: "repo", Job: "pull-unit", Context: "unit"},
+	}}
+	if err := flakiness.WriteReport(context.Background(), opener, cfg.Flakiness.ReportPath, report); err != nil {
+		t.Fatalf("failed to write flakiness report: %v", err)
+	}
+	gi := newGitHubProvider(logrus.WithField("test", t.Name()), nil, nil, cfgGetter, nil, false)
+	gi.quarantine = flakiness.NewReportCache(opener, cfgGetter)
+
+	pr := &CodeReviewCommon{Org: "org", Repo: "repo"}
+	flaky := &config.Presubmit{JobBase: config.JobBase{Name: "pull-flaky"}, Reporter: config.Reporter{Context: "flaky"}}
+	unit := &config.Presubmit{JobBase: config.JobBase{Name: "pull-unit"}, Reporter: config.Reporter{Context: "unit"}}
+	if gi.jobIsRequiredByTide(flaky, pr) {
+		t.Error("expected the quarantined presubmit to not be required")
+	}
+	if !gi.jobIsRequiredByTide(unit, pr) {
+		t.Error("expected the presubmit to be required")
+	}
+	if !gi.jobIsRequiredByTide(flaky, &CodeReviewCommon{Org: "org", Repo: "other"}) {
+		t.Error("expected the presubmit of another repo to be required")
+	}
+
+	contextPolicy := &config.TideContextPolicy{
+		RequiredContexts:          []string{"flaky", "unit"},
+		RequiredIfPresentContexts: []string{"flaky"},
+		OptionalContexts:          []string{"lint"},
+	}
+	quarantineContexts(contextPolicy, gi.quarantine.QuarantinedContexts("org", "repo"))
+	expected := &config.TideContextPolicy{
+		RequiredContexts:          []string{"unit"},
+		RequiredIfPresentContexts: []string{},
+		OptionalContexts:          []string{"flaky", "lint"},
+	}
+	if !equality.Semantic.DeepEqual(expected, contextPolicy) {
+		t.Errorf("unexpected context policy: %s", diff.ObjectReflectDiff(expected, contextPolicy))
+	}
+	if !contextPolicy.IsOptional("flaky") {
+		t.Error("expected the context of the quarantined presubmit to be optional")
+	}
+}
+
+type fakeInRepoConfigGetter struct {
+	config.InRepoConfigGetter
+	presubmits []config.Presubmit
+}
+
+func (f *fakeInRepoConfigGetter) GetPresubmits(identifier, baseBranch string, baseSHAGetter config.RefGetter, headSHAGetters ...config.RefGetter) ([]config.Presubmit, error) {
+	return f.presubmits, nil
+}
+
+func TestGetPresubmitsFromInRepoConfigGetter(t *testing.T) {
+	cfg := &config.Config{JobConfig: config.JobConfig{PresubmitsStatic: map[string][]config.Presubmit{
+		"org/repo": {{JobBase: config.JobBase{Name: "pull-static"}}},
+	}}}
+	gi := newGitHubProvider(logrus.WithField("test", t.Name()), nil, nil, func() *config.Config { return cfg }, nil, false)
+	gi.inRepoConfigGetter = &fakeInRepoConfigGetter{presubmits: []config.Presubmit{{JobBase: config.JobBase{Name: "pull-moonraker"}}}}
+
+	sha := func() (string, error) { return "sha", nil }
+	presubmits, err := gi.GetPresubmits("org/repo", "main", sha, sha)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(presubmits) != 1 || presubmits[0].Name != "pull-moonraker" {
+		t.Errorf("expected the presubmits of the InRepoConfigGetter, got %v", presubmits)
+	}
+}
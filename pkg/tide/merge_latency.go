@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"sync"
+	"time"
+)
+
+// poolEntryTimes remembers when the sync controller first saw PRs in the pool.
+// It is kept in memory only, so PRs are considered to enter the pool again when
+// Tide restarts.
+type poolEntryTimes struct {
+	sync.Mutex
+	times map[string]time.Time
+}
+
+// update records when PRs entered the pool and forgets the ones that left it.
+func (p *poolEntryTimes) update(pools map[string]*subpool, now time.Time) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	pooled := make(map[string]time.Time, len(p.times))
+	for _, sp := range pools {
+		for i := range sp.prs {
+			key := prKey(&sp.prs[i])
+			if entered, ok := p.times[key]; ok {
+				pooled[key] = entered
+			} else {
+				pooled[key] = now
+			}
+		}
+	}
+	p.times = pooled
+}
+
+// observeMerged observes how long merged PRs waited in the pool.
+func (p *poolEntryTimes) observeMerged(sp subpool, merged []CodeReviewCommon, now time.Time) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	for i := range merged {
+		key := prKey(&merged[i])
+		entered, ok := p.times[key]
+		if !ok {
+			continue
+		}
+		tideMetrics.mergeLatency.WithLabelValues(sp.org, sp.repo, sp.branch).Observe(now.Sub(entered).Seconds())
+		delete(p.times, key)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestPoolEntryTimes(t *testing.T) {
+	pr := func(number int) CodeReviewCommon {
+		return CodeReviewCommon{NameWithOwner: "org/latency", Org: "org", Repo: "latency", Number: number}
+	}
+	sp := func(prs ...CodeReviewCommon) map[string]*subpool {
+		return map[string]*subpool{"org/latency:main": {org: "org", repo: "latency", branch: "main", prs: prs}}
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := &poolEntryTimes{times: map[string]time.Time{}}
+
+	entries.update(sp(pr(1), pr(2)), start)
+	// PR 2 leaves the pool and PR 3 enters it.
+	entries.update(sp(pr(1), pr(3)), start.Add(time.Minute))
+	if _, ok := entries.times["org/latency#2"]; ok {
+		t.Error("expected the PR that left the pool to be forgotten")
+	}
+	if entered := entries.times["org/latency#1"]; !entered.Equal(start) {
+		t.Errorf("expected PR 1 to have entered the pool at %s, got %s", start, entered)
+	}
+
+	entries.observeMerged(*sp()["org/latency:main"], []CodeReviewCommon{pr(1), pr(3)}, start.Add(10*time.Minute))
+	if len(entries.times) != 0 {
+		t.Errorf("expected merged PRs to be forgotten, got %v", entries.times)
+	}
+	metric := &dto.Metric{}
+	if err := tideMetrics.mergeLatency.WithLabelValues("org", "latency", "main").(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatalf("failed to read the merge latency: %v", err)
+	}
+	if count, sum := metric.Histogram.GetSampleCount(), metric.Histogram.GetSampleSum(); count != 2 || sum != (10*time.Minute+9*time.Minute).Seconds() {
+		t.Errorf("expected two merges that waited 19m in total, got %d that waited %fs", count, sum)
+	}
+
+	// A nil tracker, as in tests that construct the controller, does nothing.
+	var none *poolEntryTimes
+	none.update(sp(pr(1)), start)
+	none.observeMerged(*sp()["org/latency:main"], []CodeReviewCommon{pr(1)}, start)
+}
@@ -429,7 +429,7 @@ func (sc *statusController) setStatuses(all []CodeReviewCommon, pool map[string]
 		baseSHA := baseSHAs[poolKey(org, repo, branch)]
 		baseSHAGetter := newBaseSHAGetter(baseSHAs, sc.ghc, org, repo, branch)
 
-		cr := contextCheckerGetterFactory(c, sc.gc, org, repo, branch, baseSHAGetter, headSHA, requiredContexts[prKey(pr)])
+		cr := contextCheckerGetterFactory(c, sc.gc, org, repo, branch, baseSHAGetter, headSHA, requiredContexts[prKey(pr)], sc.ghProvider.quarantine.QuarantinedContexts(org, repo))
 
 		wantState, wantDesc, err := sc.expectedStatus(log, queryMap, pr, pool, cr, blocks, baseSHA)
 		if err != nil {
@@ -766,13 +766,14 @@ func indexFuncPassingJobs(obj ctrlruntimeclient.Object) []string {
 
 type contextCheckerGetter = func() (contextChecker, error)
 
-func contextCheckerGetterFactory(cfg *config.Config, gc git.ClientFactory, org, repo, branch string, baseSHAGetter config.RefGetter, headSHA string, requiredContexts []string) contextCheckerGetter {
+func contextCheckerGetterFactory(cfg *config.Config, gc git.ClientFactory, org, repo, branch string, baseSHAGetter config.RefGetter, headSHA string, requiredContexts, quarantinedContexts []string) contextCheckerGetter {
 	return func() (contextChecker, error) {
 		contextPolicy, err := cfg.GetTideContextPolicy(gc, org, repo, branch, baseSHAGetter, headSHA)
 		if err != nil {
 			return nil, err
 		}
 		contextPolicy.RequiredContexts = requiredContexts
+		quarantineContexts(contextPolicy, quarantinedContexts)
 		return contextPolicy, nil
 	}
 }
@@ -41,6 +41,7 @@ import (
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/flakiness"
 	"sigs.k8s.io/prow/pkg/git/v2"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/io"
@@ -56,8 +57,6 @@ var sleep = time.Sleep
 
 type githubClient interface {
 	CreateStatus(string, string, string, github.Status) error
-	GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error)
-	ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error)
 	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
 	GetRef(string, string, string) (string, error)
 	GetRepo(owner, name string) (github.FullRepo, error)
@@ -88,6 +87,10 @@ type syncController struct {
 	// Cache entries expire if they are not used during a sync loop.
 	changedFiles *changedFilesAgent
 
+	// poolEntries remembers when PRs entered the pool, to observe how long
+	// they waited in it until they were merged.
+	poolEntries *poolEntryTimes
+
 	History *history.History
 
 	// Shared fields with status controller
@@ -204,6 +207,7 @@ var (
 		pooledPRs    *prometheus.GaugeVec
 		updateTime   *prometheus.GaugeVec
 		merges       *prometheus.HistogramVec
+		mergeLatency *prometheus.HistogramVec
 		poolErrors   *prometheus.CounterVec
 		queryResults *prometheus.CounterVec
 
@@ -241,6 +245,16 @@ var (
 			"branch",
 		}),
 
+		mergeLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tidemergelatency",
+			Help:    "Histogram of the time in seconds PRs spent in the pool until they were merged.",
+			Buckets: config.TideMergeLatencyBuckets,
+		}, []string{
+			"org",
+			"repo",
+			"branch",
+		}),
+
 		poolErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "tidepoolerrors",
 			Help: "Count of Tide pool sync errors.",
@@ -284,6 +298,7 @@ func init() {
 	prometheus.MustRegister(tideMetrics.pooledPRs)
 	prometheus.MustRegister(tideMetrics.updateTime)
 	prometheus.MustRegister(tideMetrics.merges)
+	prometheus.MustRegister(tideMetrics.mergeLatency)
 	prometheus.MustRegister(tideMetrics.syncDuration)
 	prometheus.MustRegister(tideMetrics.statusUpdateDuration)
 	prometheus.MustRegister(tideMetrics.syncHeartbeat)
@@ -321,13 +336,15 @@ func (c *Controller) History() *history.History {
 	return c.syncCtrl.History
 }
 
-// NewController makes a Controller out of the given clients.
+// NewController makes a Controller out of the given clients. ircg may be nil,
+// in which case the presubmits are read from clones of the repositories.
 func NewController(
 	ghcSync,
 	ghcStatus github.Client,
 	mgr manager,
 	cfg config.Getter,
 	gc git.ClientFactory,
+	ircg config.InRepoConfigGetter,
 	maxRecordsPerPool int,
 	opener io.Opener,
 	historyURI,
@@ -352,13 +369,21 @@ func NewController(
 		newPoolPending:   make(chan bool),
 	}
 
+	// Flaky presubmits quarantined by the flakiness controller are not
+	// required, neither when setting statuses nor when merging.
+	quarantine := flakiness.NewReportCache(opener, cfg)
+
 	sc, err := newStatusController(ctx, logger, ghcStatus, mgr, gc, cfg, opener, statusURI, mergeChecker, usesGitHubAppsAuth, statusUpdate)
 	if err != nil {
 		return nil, err
 	}
+	sc.ghProvider.quarantine = quarantine
+	sc.ghProvider.inRepoConfigGetter = ircg
 	go sc.run()
 
 	provider := newGitHubProvider(logger, ghcSync, gc, cfg, mergeChecker, usesGitHubAppsAuth)
+	provider.quarantine = quarantine
+	provider.inRepoConfigGetter = ircg
 	syncCtrl, err := newSyncController(ctx, logger, mgr, provider, cfg, gc, hist, usesGitHubAppsAuth, statusUpdate)
 	if err != nil {
 		return nil, err
@@ -436,6 +461,7 @@ func newSyncController(
 			provider:        provider,
 			nextChangeCache: make(map[changeCacheKey][]string),
 		},
+		poolEntries:  &poolEntryTimes{times: map[string]time.Time{}},
 		History:      hist,
 		statusUpdate: statusUpdate,
 	}, nil
@@ -502,6 +528,7 @@ func (c *syncController) Sync() error {
 		return err
 	}
 	filteredPools := c.filterSubpools(c.provider.isAllowedToMerge, rawPools)
+	c.poolEntries.update(filteredPools, time.Now())
 
 	// Notify statusController about the new pool.
 	c.statusUpdate.Lock()
@@ -1383,7 +1410,7 @@ func (c *syncController) trigger(sp subpool, presubmits []config.Presubmit, prs
 			pj.Labels = map[string]string{}
 		}
 		pj.Labels[kube.CreatedByTideLabel] = "true"
-		if err := c.prowJobClient.Create(c.ctx, &pj); err != nil {
+		if err := pjutil.CreateProwJobWithClient(c.ctx, c.prowJobClient, &pj); err != nil {
 			log.WithField("duration", time.Since(start).String()).Debug("Failed to create ProwJob on the cluster.")
 			return fmt.Errorf("failed to create a ProwJob for job: %q, PRs: %v: %w", spec.Job, prNumbers(prs), err)
 		}
@@ -1413,6 +1440,7 @@ func (c *syncController) takeAction(sp subpool, batchPending, successes, pending
 	defer func() {
 		if len(merged) > 0 {
 			tideMetrics.merges.WithLabelValues(sp.org, sp.repo, sp.branch).Observe(float64(len(merged)))
+			c.poolEntries.observeMerged(sp, merged, time.Now())
 		}
 	}()
 
@@ -1940,19 +1968,21 @@ type PRNode struct {
 }
 
 type searchQuery struct {
-	RateLimit struct {
-		Cost      githubql.Int
-		Remaining githubql.Int
-	}
-	Search struct {
-		PageInfo struct {
-			HasNextPage githubql.Boolean
-			EndCursor   githubql.String
-		}
-		Nodes []PRNode
+	RateLimit github.GraphQLRateLimit
+	Search    struct {
+		PageInfo github.GraphQLPageInfo
+		Nodes    []PRNode
 	} `graphql:"search(type: ISSUE, first: 37, after: $searchCursor, query: $query)"`
 }
 
+func (sq *searchQuery) GraphQLPageInfo() github.GraphQLPageInfo {
+	return sq.Search.PageInfo
+}
+
+func (sq *searchQuery) GraphQLRateLimit() github.GraphQLRateLimit {
+	return sq.RateLimit
+}
+
 // orgRepoQueryStrings returns the GitHub query strings for given orgs and
 // repos. Make sure that this is only used by GitHub interactor.
 func orgRepoQueryStrings(orgs, repos []string, orgExceptions map[string]sets.Set[string]) map[string]string {
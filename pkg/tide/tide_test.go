@@ -734,6 +734,9 @@ func (f *fgc) GetRef(o, r, ref string) (string, error) {
 }
 
 func (f *fgc) QueryWithGitHubAppsSupport(ctx context.Context, q interface{}, vars map[string]interface{}, org string) error {
+	if cq, ok := q.(*github.CommitContextsQuery); ok {
+		return f.queryCommitContexts(cq, vars)
+	}
 	sq, ok := q.(*searchQuery)
 	if !ok {
 		return errors.New("unexpected query type")
@@ -777,28 +780,30 @@ func (f *fgc) CreateStatus(org, repo, ref string, s github.Status) error {
 	return fmt.Errorf("invalid 'state' value: %q", s.State)
 }
 
-func (f *fgc) GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error) {
-	if !f.skipExpectedShaCheck && f.expectedSHA != ref {
-		return nil, errors.New("bad combined status request: incorrect sha")
+func (f *fgc) queryCommitContexts(q *github.CommitContextsQuery, vars map[string]interface{}) error {
+	if !f.skipExpectedShaCheck && githubql.String(f.expectedSHA) != vars["ref"] {
+		return errors.New("bad commit contexts query: incorrect sha")
 	}
-	var statuses []github.Status
+	contexts := &q.Repository.Object.Commit.StatusCheckRollup.Contexts
 	for c, s := range f.combinedStatus {
-		statuses = append(statuses, github.Status{Context: c, State: s})
-	}
-	return &github.CombinedStatus{
-			Statuses: statuses,
-		},
-		nil
-}
-
-func (f *fgc) ListCheckRuns(org, repo, ref string) (*github.CheckRunList, error) {
-	if !f.skipExpectedShaCheck && f.expectedSHA != ref {
-		return nil, errors.New("bad combined status request: incorrect sha")
+		contexts.Nodes = append(contexts.Nodes, github.CommitContextNode{
+			Typename:      "StatusContext",
+			StatusContext: github.StatusContextFragment{Context: githubql.String(c), State: githubql.StatusState(strings.ToUpper(s))},
+		})
 	}
 	if f.checkRuns != nil {
-		return f.checkRuns, nil
+		for _, checkRun := range f.checkRuns.CheckRuns {
+			contexts.Nodes = append(contexts.Nodes, github.CommitContextNode{
+				Typename: "CheckRun",
+				CheckRun: github.CheckRunFragment{
+					Name:       githubql.String(checkRun.Name),
+					Status:     githubql.String(strings.ToUpper(checkRun.Status)),
+					Conclusion: githubql.String(strings.ToUpper(checkRun.Conclusion)),
+				},
+			})
+		}
 	}
-	return &github.CheckRunList{}, nil
+	return nil
 }
 
 func (f *fgc) GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error) {
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	prowv1 "sigs.k8s.io/prow/pkg/client/clientset/versioned/typed/prowjobs/v1"
+)
+
+// ProwJobClient returns a client that creates ProwJobs in the trace of the
+// span of ctx, e.g. the one of the webhook that triggered them. The client is
+// returned as is when ctx has no span.
+func ProwJobClient(ctx context.Context, client prowv1.ProwJobInterface) prowv1.ProwJobInterface {
+	if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		return client
+	}
+	return &prowJobClient{ProwJobInterface: client, traceCtx: ctx}
+}
+
+type prowJobClient struct {
+	prowv1.ProwJobInterface
+	traceCtx context.Context
+}
+
+// Create creates the ProwJob in a span, whose context is stored in the
+// annotations of the job.
+func (c *prowJobClient) Create(ctx context.Context, pj *prowapi.ProwJob, opts metav1.CreateOptions) (*prowapi.ProwJob, error) {
+	traceCtx, span := Tracer().Start(c.traceCtx, "create prowjob", trace.WithAttributes(ProwJobAttributes(pj)...))
+	defer span.End()
+	pj = pj.DeepCopy()
+	InjectProwJob(traceCtx, pj)
+	created, err := c.ProwJobInterface.Create(ctx, pj, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return created, err
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing threads an OpenTelemetry trace through the life of a
+// ProwJob. The component creating a ProwJob stores the context of its span in
+// the annotations of the job, and the components handling the job later on
+// continue the trace from there.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/flagutil"
+)
+
+// AnnotationPrefix prefixes the keys of the trace context, e.g. traceparent,
+// in the annotations of a ProwJob.
+const AnnotationPrefix = "prow.k8s.io/"
+
+const instrumentationName = "sigs.k8s.io/prow"
+
+// propagator encodes the trace context as W3C Trace Context headers.
+var propagator = propagation.TraceContext{}
+
+// Setup exports the spans of the component to the OpenTelemetry collector of
+// the options. The returned function flushes the spans that weren't exported
+// yet, and should be called on shutdown. Spans are dropped when tracing isn't
+// enabled.
+func Setup(ctx context.Context, component string, o flagutil.TracingOptions) (func(context.Context) error, error) {
+	if o.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	endpoint, err := url.Parse(o.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tracing endpoint %q: %w", o.Endpoint, err)
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint.Host)}
+	if endpoint.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if path := strings.TrimSuffix(endpoint.Path, "/"); path != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(path))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", component))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of Prow.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// annotationCarrier stores the trace context in the annotations of a ProwJob.
+type annotationCarrier map[string]string
+
+func (c annotationCarrier) Get(key string) string {
+	return c[AnnotationPrefix+key]
+}
+
+func (c annotationCarrier) Set(key, value string) {
+	c[AnnotationPrefix+key] = value
+}
+
+func (c annotationCarrier) Keys() []string {
+	var keys []string
+	for key := range c {
+		if strings.HasPrefix(key, AnnotationPrefix) {
+			keys = append(keys, strings.TrimPrefix(key, AnnotationPrefix))
+		}
+	}
+	return keys
+}
+
+// InjectProwJob stores the context of the span of ctx in the annotations of
+// the ProwJob. It is a no-op when ctx has no span, e.g. if tracing is
+// disabled.
+func InjectProwJob(ctx context.Context, pj *prowapi.ProwJob) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	if pj.Annotations == nil {
+		pj.Annotations = map[string]string{}
+	}
+	propagator.Inject(ctx, annotationCarrier(pj.Annotations))
+}
+
+// ProwJobContext returns ctx with the span context stored in the annotations
+// of the ProwJob, if any, as the parent of new spans.
+func ProwJobContext(ctx context.Context, pj *prowapi.ProwJob) context.Context {
+	return propagator.Extract(ctx, annotationCarrier(pj.Annotations))
+}
+
+// ProwJobAttributes describe the ProwJob a span is about.
+func ProwJobAttributes(pj *prowapi.ProwJob) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("prow.job", pj.Spec.Job),
+		attribute.String("prow.prowjob", pj.Name),
+		attribute.String("prow.type", string(pj.Spec.Type)),
+	}
+}
+
+// StartProwJobSpan starts a span in the trace of the ProwJob. Jobs that
+// weren't created in a trace aren't traced, and get a span that isn't
+// recorded.
+func StartProwJobSpan(ctx context.Context, pj *prowapi.ProwJob, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx = ProwJobContext(ctx, pj)
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	opts = append([]trace.SpanStartOption{trace.WithAttributes(ProwJobAttributes(pj)...)}, opts...)
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// RecordProwJobSpan records a span in the trace of the ProwJob for a phase
+// of the job that already ended, e.g. the time it waited for its pod.
+func RecordProwJobSpan(ctx context.Context, pj *prowapi.ProwJob, name string, start, end time.Time, attrs ...attribute.KeyValue) {
+	_, span := StartProwJobSpan(ctx, pj, name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	span.End(trace.WithTimestamp(end))
+}
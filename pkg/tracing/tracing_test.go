@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	prowfake "sigs.k8s.io/prow/pkg/client/clientset/versioned/fake"
+)
+
+// recordSpans makes the global tracer provider record the spans of a test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestInjectProwJob(t *testing.T) {
+	recordSpans(t)
+	ctx, span := Tracer().Start(context.Background(), "webhook")
+	defer span.End()
+
+	pj := &prowapi.ProwJob{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"foo": "bar"}}}
+	InjectProwJob(ctx, pj)
+	if pj.Annotations[AnnotationPrefix+"traceparent"] == "" {
+		t.Fatalf("expected the trace context in the annotations, got %v", pj.Annotations)
+	}
+	if pj.Annotations["foo"] != "bar" {
+		t.Errorf("expected the other annotations to be kept, got %v", pj.Annotations)
+	}
+	got := trace.SpanContextFromContext(ProwJobContext(context.Background(), pj))
+	if got.TraceID() != span.SpanContext().TraceID() || got.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("expected span context %v, got %v", span.SpanContext(), got)
+	}
+
+	untraced := &prowapi.ProwJob{}
+	InjectProwJob(context.Background(), untraced)
+	if untraced.Annotations != nil {
+		t.Errorf("expected no annotations without a span, got %v", untraced.Annotations)
+	}
+}
+
+func TestRecordProwJobSpan(t *testing.T) {
+	recorder := recordSpans(t)
+	ctx, parent := Tracer().Start(context.Background(), "webhook")
+	parent.End()
+
+	traced := &prowapi.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: "traced"}, Spec: prowapi.ProwJobSpec{Job: "job", Type: prowapi.PresubmitJob}}
+	InjectProwJob(ctx, traced)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	RecordProwJobSpan(context.Background(), traced, "run", start, start.Add(time.Minute))
+	RecordProwJobSpan(context.Background(), &prowapi.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: "untraced"}}, "run", start, start.Add(time.Minute))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected the spans of the webhook and of the traced job, got %d spans", len(spans))
+	}
+	span := spans[1]
+	if span.Name() != "run" || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected span run with parent %v, got span %s with parent %v", parent.SpanContext().SpanID(), span.Name(), span.Parent().SpanID())
+	}
+	if !span.StartTime().Equal(start) || span.EndTime().Sub(span.StartTime()) != time.Minute {
+		t.Errorf("expected the span to last from %v for a minute, got %v to %v", start, span.StartTime(), span.EndTime())
+	}
+	var job string
+	for _, attr := range span.Attributes() {
+		if attr.Key == "prow.job" {
+			job = attr.Value.AsString()
+		}
+	}
+	if job != "job" {
+		t.Errorf("expected attribute prow.job=job, got %q", job)
+	}
+}
+
+func TestProwJobClient(t *testing.T) {
+	recorder := recordSpans(t)
+	ctx, span := Tracer().Start(context.Background(), "webhook")
+	defer span.End()
+
+	client := prowfake.NewSimpleClientset().ProwV1().ProwJobs("prowjobs")
+	if got := ProwJobClient(context.Background(), client); got != client {
+		t.Error("expected the client to be returned as is without a span")
+	}
+
+	pj := &prowapi.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: "job"}}
+	created, err := ProwJobClient(ctx, client).Create(context.Background(), pj, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create prowjob: %v", err)
+	}
+	if pj.Annotations != nil {
+		t.Errorf("expected the prowjob passed in to be left unchanged, got annotations %v", pj.Annotations)
+	}
+	got := trace.SpanContextFromContext(ProwJobContext(context.Background(), created))
+	if got.TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("expected the prowjob to be created in trace %v, got %v", span.SpanContext().TraceID(), got.TraceID())
+	}
+	if spans := recorder.Ended(); len(spans) != 1 || spans[0].Name() != "create prowjob" || spans[0].SpanContext().SpanID() != got.SpanID() {
+		t.Errorf("expected the prowjob to be annotated with the span that created it, got spans %v", spans)
+	}
+}